@@ -58,21 +58,70 @@ func MakeConfig(c *def.App) (out *nine.Config) {
 		SimNet:                   &sn,
 		AddCheckpoints:           C.Tags("chain", "addcheckpoints"),
 		DisableCheckpoints:       C.Bool("chain", "disablecheckpoints"),
+		AssumeValid:              C.Str("chain", "assumevalid"),
+		FullVerify:               C.Bool("chain", "fullverify"),
+		DumpInvalid:              C.Bool("chain", "dumpinvalid"),
+		AssumeUtxo:               C.Str("chain", "assumeutxo"),
+		LoadUtxoSet:              C.Str("chain", "loadutxoset"),
+		UtxoCache:                C.Int("chain", "dbcache"),
 		DbType:                   C.Str("chain", "dbtype"),
 		Profile:                  C.Int("app", "profile"),
 		CPUProfile:               C.Str("app", "cpuprofile"),
 		Upnp:                     C.Bool("app", "upnp"),
+		Theme:                    C.Str("app", "theme"),
+		Locale:                   C.Str("app", "locale"),
+		Unit:                     C.Str("app", "unit"),
+		MemLimit:                 C.Int("app", "memlimit"),
+		DBCache:                  C.Int("app", "dbcache"),
+		GCPercent:                C.Int("app", "gcpercent"),
+		Ballast:                  C.Int("app", "ballast"),
+		LazyParse:                C.Bool("app", "lazyparse"),
 		MinRelayTxFee:            C.Float("p2p", "minrelaytxfee"),
 		FreeTxRelayLimit:         C.Float("p2p", "freetxrelaylimit"),
+		DustRelayFee:             C.Float("p2p", "dustrelayfee"),
 		NoRelayPriority:          C.Bool("p2p", "norelaypriority"),
+		FeeRateOnly:              C.Bool("p2p", "feerateonly"),
 		TrickleInterval:          C.Duration("p2p", "trickleinterval"),
 		MaxOrphanTxs:             C.Int("p2p", "maxorphantxs"),
+		MaxOrphanTxsPerPeer:      C.Int("p2p", "maxorphantxsperpeer"),
+		OrphanExpiry:             C.Duration("p2p", "orphanexpiry"),
+		MempoolReplacement:       C.Bool("p2p", "mempoolreplacement"),
+		MempoolExpiry:            C.Int("mempool", "expiryhours"),
+		MaxMempool:               C.Int("mempool", "maxmegabytes"),
+		MaxAncestors:             C.Int("mempool", "maxancestors"),
+		MaxAncestorSize:          C.Int("mempool", "maxancestorsize"),
+		MaxDescendants:           C.Int("mempool", "maxdescendants"),
+		MaxDescendantSize:        C.Int("mempool", "maxdescendantsize"),
+		MempoolWebhooks:          C.Tags("mempool", "webhooks"),
+		MempoolWebhookSecret:     C.Str("mempool", "webhooksecret"),
+		MempoolWebhookRetries:    C.Int("mempool", "webhookretries"),
+		Telemetry:                C.Bool("telemetry", "enable"),
+		TelemetryEndpoint:        C.Str("telemetry", "endpoint"),
+		TelemetryInterval:        C.Duration("telemetry", "interval"),
+		Update:                   C.Bool("update", "enable"),
+		UpdateURL:                C.Str("update", "url"),
+		UpdateInterval:           C.Duration("update", "interval"),
 		Algo:                     C.Str("mining", "algo"),
 		Generate:                 C.Bool("mining", "generate"),
 		GenThreads:               C.Int("mining", "genthreads"),
 		MiningAddrs:              C.Tags("mining", "addresses"),
-		MinerListener:            C.Str("mining", "listener"),
+		MiningRotation:           C.Str("mining", "rotation"),
+		MinerListener:            C.Tags("mining", "listener"),
 		MinerPass:                C.Str("mining", "pass"),
+		MinerAPIKey:              C.Str("mining", "apikey"),
+		MinerAPIKeys:             C.Tags("mining", "apikeys"),
+		MinerEncrypt:             C.Bool("mining", "encrypt"),
+		MinerControllerKeys:      C.Tags("mining", "controllerkeys"),
+		MinerMulticast:           C.Str("mining", "multicast"),
+		MinerAdvertise:           C.Bool("mining", "advertise"),
+		MinerBias:                C.Float("mining", "bias"),
+		MinerSwitch:              C.Duration("mining", "switch"),
+		MinerCores:               C.Tags("mining", "cores"),
+		MinerNice:                C.Int("mining", "nice"),
+		CoinbaseTag:              C.Str("mining", "coinbasetag"),
+		StratumListeners:         C.Tags("mining", "stratum"),
+		StratumDifficulty:        C.Tags("mining", "stratumdiff"),
+		StratumShareTime:         C.Duration("mining", "stratumsharetime"),
 		BlockMinSize:             C.Int("block", "minsize"),
 		BlockMaxSize:             C.Int("block", "maxsize"),
 		BlockMinWeight:           C.Int("block", "minweight"),
@@ -92,6 +141,10 @@ func MakeConfig(c *def.App) (out *nine.Config) {
 		NoInitialLoad:            C.Bool("wallet", "noinitialload"),
 		WalletPass:               C.Str("wallet", "pass"),
 		WalletServer:             C.Str("wallet", "server"),
+		WalletGUI:                C.Str("wallet", "gui"),
+		WalletGUIViewOnly:        C.Bool("wallet", "guiviewonly"),
+		WalletSPV:                C.Bool("wallet", "spv"),
+		WalletRBF:                C.Bool("wallet", "rbf"),
 		CAFile:                   C.Str("tls", "cafile"),
 		OneTimeTLSKey:            C.Bool("tls", "onetime"),
 		ServerTLS:                C.Bool("tls", "server"),
@@ -0,0 +1,64 @@
+package app
+import (
+	"fmt"
+	"runtime"
+	"strconv"
+	"time"
+	"git.parallelcoin.io/dev/9/cmd/def"
+	"git.parallelcoin.io/dev/9/pkg/chain/fork"
+	cpuminer "git.parallelcoin.io/dev/9/pkg/chain/mining/cpu"
+)
+// DefaultBenchSeconds is how long each algorithm is benchmarked per thread count when no <integer> is given
+const DefaultBenchSeconds = 10
+// Bench runs each proof of work algorithm of the current hard fork for a fixed
+// time per thread count and prints the hash rates, so the best mining.algo for
+// the hardware can be picked
+func Bench(args []string, tokens def.Tokens, ap *def.App) int {
+	seconds := DefaultBenchSeconds
+	if t, ok := tokens["integer"]; ok {
+		n, err := strconv.Atoi(t.Value)
+		if err != nil || n < 1 {
+			fmt.Println("benchmark time must be a positive number of seconds")
+			return 1
+		}
+		seconds = n
+	}
+	d := time.Duration(seconds) * time.Second
+	height := fork.List[len(fork.List)-1].ActivationHeight + 1
+	algos := cpuminer.BenchAlgos(height)
+	threads := cpuminer.BenchThreads(runtime.NumCPU())
+	// the benchmark should be able to use every core regardless of the limit
+	// set for the node
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(runtime.NumCPU()))
+	fmt.Printf("benchmarking %d algorithms on %v threads, %v each\n",
+		len(algos), threads, d)
+	fmt.Printf("%-10s %8s %14s %10s\n", "algo", "threads", "hashes/s", "relative")
+	best := make(map[string]cpuminer.BenchResult)
+	for _, algo := range algos {
+		for _, n := range threads {
+			r := cpuminer.Benchmark(algo, height, n, d)
+			fmt.Printf("%-10s %8d %14.2f %10.3f\n",
+				r.Algo, r.Threads, r.HashesPerSec(), r.Relative(height))
+			if b, ok := best[algo]; !ok || r.HashesPerSec() > b.HashesPerSec() {
+				best[algo] = r
+			}
+		}
+	}
+	var pick string
+	var pickRel float64
+	fmt.Println("\nbest thread count per algorithm:")
+	for _, algo := range algos {
+		r := best[algo]
+		rel := r.Relative(height) * float64(r.Threads)
+		fmt.Printf("%-10s %8d %14.2f %10.3f\n",
+			algo, r.Threads, r.HashesPerSec(), rel)
+		if rel > pickRel {
+			pick, pickRel = algo, rel
+		}
+	}
+	if pick != "" {
+		fmt.Printf("\nsuggested: mining.algo=%s mining.genthreads=%d\n",
+			pick, best[pick].Threads)
+	}
+	return 0
+}
@@ -0,0 +1,38 @@
+package app
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"git.parallelcoin.io/dev/9/cmd/def"
+	"git.parallelcoin.io/dev/9/cmd/node"
+)
+// BenchVerify validates the blocks of the node's own chain between the heights after --from and --to again and prints how long each stage took, without running the node
+func BenchVerify(args []string, tokens def.Tokens, ap *def.App) int {
+	from, to := int32(1), int32(0)
+	for i, arg := range args {
+		var height *int32
+		switch arg {
+		case "--from":
+			height = &from
+		case "--to":
+			height = &to
+		default:
+			continue
+		}
+		if i+1 >= len(args) {
+			fmt.Println(arg, "needs a block height")
+			return 1
+		}
+		n, err := strconv.ParseInt(args[i+1], 10, 32)
+		if err != nil || n < 1 {
+			fmt.Println(arg, "needs a block height, not", args[i+1])
+			return 1
+		}
+		*height = int32(n)
+	}
+	if err := node.BenchVerify(os.Stdout, from, to); err != nil {
+		fmt.Println("could not benchmark block verification:", err)
+		return 1
+	}
+	return 0
+}
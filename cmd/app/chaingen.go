@@ -0,0 +1,37 @@
+package app
+import (
+	"fmt"
+	"os"
+	"strings"
+	"git.parallelcoin.io/dev/9/cmd/def"
+	"git.parallelcoin.io/dev/9/cmd/node"
+	"git.parallelcoin.io/dev/9/pkg/chain/chaingen"
+)
+// ChainGen builds the blocks of the scenario in the file after chaingen= into the node's block database, without running the node
+func ChainGen(args []string, tokens def.Tokens, ap *def.App) int {
+	value := tokens["chaingen"].Value
+	i := strings.Index(value, "=")
+	if i < 0 || i+1 == len(value) {
+		fmt.Printf("the scenario file must follow chaingen=, with a step on each line:\n\n%s\n", chaingen.Usage())
+		return 1
+	}
+	path := value[i+1:]
+	f, err := os.Open(path)
+	if err != nil {
+		fmt.Println("could not open scenario:", err)
+		return 1
+	}
+	scenario, err := chaingen.ParseScenario(f)
+	f.Close()
+	if err != nil {
+		fmt.Printf("could not read scenario %s: %v\n", path, err)
+		return 1
+	}
+	wif, err := node.GenerateChain(os.Stdout, scenario)
+	if err != nil {
+		fmt.Println("could not generate chain:", err)
+		return 1
+	}
+	fmt.Println("the blocks pay to the key", wif.String())
+	return 0
+}
@@ -0,0 +1,402 @@
+package app
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+	"git.parallelcoin.io/dev/9/cmd/ctl"
+	"git.parallelcoin.io/dev/9/cmd/def"
+	"git.parallelcoin.io/dev/9/pkg/util"
+	"git.parallelcoin.io/dev/9/pkg/util/interrupt"
+)
+// The files of a cluster are named after its basename, next to its data directories, and those of each node are in its data directory
+const (
+	clusterPidFile    = ".cluster.pid"
+	clusterStatusFile = ".cluster.json"
+	clusterLogFile    = ".cluster.log"
+	clusterNodePid    = "cluster.pid"
+	clusterNodeLog    = "cluster.log"
+)
+// clusterStartTimeout is how long cluster start waits for the supervisor to start the nodes
+const clusterStartTimeout = 15 * time.Second
+// clusterRestartDelay is the delay before a node that crashed is restarted. It doubles each time the node crashes again without having run for clusterStableTime, up to clusterMaxRestartDelay
+const (
+	clusterRestartDelay    = time.Second
+	clusterMaxRestartDelay = time.Minute
+	clusterStableTime      = time.Minute
+)
+// clusterStatus is written by the supervisor of a cluster to the status file each time one of its nodes starts or stops
+type clusterStatus struct {
+	PID      int                 `json:"pid"`
+	Started  time.Time           `json:"started"`
+	Topology string              `json:"topology"`
+	Nodes    []clusterNodeStatus `json:"nodes"`
+}
+// clusterNodeStatus is the state of a node of a cluster
+type clusterNodeStatus struct {
+	Name     string    `json:"name"`
+	Dir      string    `json:"dir"`
+	PID      int       `json:"pid"`
+	Running  bool      `json:"running"`
+	Started  time.Time `json:"started"`
+	Restarts int       `json:"restarts"`
+	LastExit string    `json:"lastexit,omitempty"`
+}
+// clusterNode is a node of a cluster, restarted by the supervisor when it exits until the cluster is stopped
+type clusterNode struct {
+	*testNode
+	mx       sync.Mutex
+	stopping bool
+	status   clusterNodeStatus
+}
+// Cluster starts the nodes of the data directories named after a basename as a cluster of long-running processes kept up by a supervisor in the background, stops them, or prints their status
+func Cluster(args []string, tokens def.Tokens, ap *def.App) int {
+	var action, base string
+	for i, x := range args {
+		if ap.Commands["cluster"].RE.Match([]byte(x)) && i+2 < len(args) {
+			action, base = args[i+1], args[i+2]
+			break
+		}
+	}
+	if base == "" {
+		fmt.Println("start, stop or status and the basename of the data directories of the cluster must follow cluster")
+		return 1
+	}
+	abs, err := filepath.Abs(base)
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	switch action {
+	case "start":
+		err = clusterStart(abs, tokens)
+	case "stop":
+		err = clusterStop(ap, abs)
+	case "status":
+		err = clusterPrintStatus(ap, abs)
+	case "supervise":
+		err = clusterSupervise(ap, abs, tokens)
+	default:
+		err = fmt.Errorf("unknown cluster action %s, not start, stop or status", action)
+	}
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	return 0
+}
+// clusterStart runs the supervisor of the cluster in a process of its own that outlives this one, and waits for it to start the nodes
+func clusterStart(base string, tokens def.Tokens) error {
+	if pid, alive := clusterSupervisor(base); alive {
+		return fmt.Errorf("the cluster %s is already running with the supervisor pid %d", base, pid)
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("could not find the executable to run the supervisor with: %v", err)
+	}
+	args := []string{"cluster", "supervise", base}
+	for _, name := range []string{"integer", "topology"} {
+		if t, ok := tokens[name]; ok {
+			args = append(args, t.Value)
+		}
+	}
+	logPath := base + clusterLogFile
+	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	defer logFile.Close()
+	// the status left by an earlier run would be mistaken for that of this one
+	os.Remove(base + clusterStatusFile)
+	cmd := exec.Command(exe, args...)
+	cmd.Stdout, cmd.Stderr = logFile, logFile
+	if err = cmd.Start(); err != nil {
+		return err
+	}
+	exited := make(chan struct{})
+	go func() {
+		cmd.Wait()
+		close(exited)
+	}()
+	deadline := time.After(clusterStartTimeout)
+	for {
+		select {
+		case <-exited:
+			return fmt.Errorf("the supervisor exited, see %s", logPath)
+		case <-deadline:
+			return fmt.Errorf("the supervisor did not start the nodes in %v, see %s", clusterStartTimeout, logPath)
+		case <-time.After(time.Second / 4):
+		}
+		if status, err := readClusterStatus(base); err == nil && len(status.Nodes) > 0 {
+			fmt.Printf("started %d nodes from %s in a %s with the supervisor pid %d, logging to %s\n",
+				len(status.Nodes), base, status.Topology, status.PID, logPath)
+			return nil
+		}
+	}
+}
+// clusterStop asks the supervisor of the cluster to stop its nodes and waits for it to exit. When the supervisor can not be signalled it is killed and the nodes are asked to stop over RPC
+func clusterStop(ap *def.App, base string) error {
+	pid, alive := clusterSupervisor(base)
+	if !alive {
+		return fmt.Errorf("the cluster %s is not running", base)
+	}
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	if err = p.Signal(interrupt.InterruptSignals[len(interrupt.InterruptSignals)-1]); err != nil {
+		fmt.Printf("could not signal the supervisor (%v), killing it and stopping the nodes over RPC\n", err)
+		p.Kill()
+		status, err := readClusterStatus(base)
+		if err != nil {
+			return err
+		}
+		for _, n := range status.Nodes {
+			if err := loadTestConfig(ap, n.Dir); err != nil {
+				fmt.Printf("%s: %v\n", n.Name, err)
+				continue
+			}
+			if err := ctl.Call(MakeConfig(ap), "stop", nil); err != nil {
+				fmt.Printf("%s: %v\n", n.Name, err)
+			}
+		}
+		os.Remove(base + clusterPidFile)
+		return nil
+	}
+	deadline := time.Now().Add(testStopTimeout + 10*time.Second)
+	for time.Now().Before(deadline) {
+		if _, alive := clusterSupervisor(base); !alive {
+			fmt.Println("stopped the cluster", base)
+			return nil
+		}
+		time.Sleep(time.Second / 4)
+	}
+	return fmt.Errorf("the supervisor with pid %d has not exited, see %s", pid, base+clusterLogFile)
+}
+// clusterPrintStatus prints whether the supervisor of the cluster is running, and for each node its pid, restarts and, when it answers over RPC, its height and peers, followed by the totals of the cluster
+func clusterPrintStatus(ap *def.App, base string) error {
+	status, err := readClusterStatus(base)
+	if err != nil {
+		return fmt.Errorf("the cluster %s has not been started: %v", base, err)
+	}
+	if pid, alive := clusterSupervisor(base); alive {
+		fmt.Printf("%s supervisor pid %d up %v, %s topology\n", base, pid,
+			time.Since(status.Started).Round(time.Second), status.Topology)
+	} else {
+		fmt.Printf("%s supervisor is not running\n", base)
+	}
+	var running, restarts int
+	var minHeight, maxHeight int64 = -1, -1
+	for _, n := range status.Nodes {
+		restarts += n.Restarts
+		state := "stopped"
+		pid, alive := readPid(filepath.Join(n.Dir, clusterNodePid))
+		if alive {
+			state = fmt.Sprintf("pid %d up %v", pid, time.Since(n.Started).Round(time.Second))
+		}
+		var height, peers int64
+		chain := "not answering"
+		if err := loadTestConfig(ap, n.Dir); err == nil {
+			cfg := MakeConfig(ap)
+			if ctl.Call(cfg, "getblockcount", &height) == nil &&
+				ctl.Call(cfg, "getconnectioncount", &peers) == nil {
+				chain = fmt.Sprintf("height %d peers %d", height, peers)
+				alive = true
+				if minHeight < 0 || height < minHeight {
+					minHeight = height
+				}
+				if height > maxHeight {
+					maxHeight = height
+				}
+			}
+		}
+		if alive {
+			running++
+		}
+		line := fmt.Sprintf("%s %s, %s, %d restarts", n.Name, state, chain, n.Restarts)
+		if n.LastExit != "" {
+			line += ", last exit " + n.LastExit
+		}
+		fmt.Println(line)
+	}
+	fmt.Printf("%d of %d nodes running, %d restarts", running, len(status.Nodes), restarts)
+	if maxHeight >= 0 {
+		fmt.Printf(", heights %d to %d", minHeight, maxHeight)
+	}
+	fmt.Println()
+	return nil
+}
+// clusterSupervise runs the nodes of the cluster, restarting each when it exits, writes the pid files and status of the cluster as they change, and stops the nodes when it is interrupted
+func clusterSupervise(ap *def.App, base string, tokens def.Tokens) error {
+	// the supervisor outlives the terminal it was started from
+	signal.Ignore(syscall.SIGHUP)
+	nodes, topology, err := testNetwork(ap, base, tokens)
+	if err != nil {
+		return err
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	pwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	if err = writePid(base+clusterPidFile, os.Getpid()); err != nil {
+		return err
+	}
+	defer os.Remove(base + clusterPidFile)
+	status := &clusterStatus{PID: os.Getpid(), Started: time.Now(), Topology: topology}
+	cluster := make([]*clusterNode, len(nodes))
+	for i, n := range nodes {
+		cluster[i] = &clusterNode{testNode: n, status: clusterNodeStatus{Name: n.name, Dir: n.dir}}
+	}
+	var statusMx sync.Mutex
+	update := func() {
+		statusMx.Lock()
+		defer statusMx.Unlock()
+		status.Nodes = status.Nodes[:0]
+		for _, n := range cluster {
+			n.mx.Lock()
+			status.Nodes = append(status.Nodes, n.status)
+			n.mx.Unlock()
+		}
+		if err := writeClusterStatus(base, status); err != nil {
+			fmt.Println("could not write the status of the cluster:", err)
+		}
+	}
+	ctx := interrupt.Context()
+	var wg sync.WaitGroup
+	for _, n := range cluster {
+		wg.Add(1)
+		go func(n *clusterNode) {
+			n.supervise(exe, pwd, update, ctx.Done())
+			wg.Done()
+		}(n)
+	}
+	fmt.Printf("supervising %d nodes from %s in a %s\n", len(cluster), base, topology)
+	<-ctx.Done()
+	fmt.Println("stopping the cluster")
+	var stops sync.WaitGroup
+	for _, n := range cluster {
+		stops.Add(1)
+		go func(n *clusterNode) {
+			n.mx.Lock()
+			n.stopping = true
+			n.mx.Unlock()
+			n.stop()
+			stops.Done()
+		}(n)
+	}
+	stops.Wait()
+	wg.Wait()
+	update()
+	return nil
+}
+// supervise runs the node until quit is closed, restarting it after a delay each time it exits, and calls update after each change of its status
+func (n *clusterNode) supervise(exe, pwd string, update func(), quit <-chan struct{}) {
+	delay := clusterRestartDelay
+	for {
+		n.mx.Lock()
+		if n.stopping {
+			n.mx.Unlock()
+			return
+		}
+		err := n.start(exe, pwd, clusterNodeLog, true)
+		var done chan struct{}
+		if err == nil {
+			done = n.done
+			n.status.PID, n.status.Running, n.status.Started = n.cmd.Process.Pid, true, time.Now()
+			writePid(filepath.Join(n.dir, clusterNodePid), n.status.PID)
+		} else {
+			n.status.LastExit = err.Error()
+		}
+		n.mx.Unlock()
+		update()
+		if err == nil {
+			<-done
+			os.Remove(filepath.Join(n.dir, clusterNodePid))
+			n.mx.Lock()
+			n.status.Running = false
+			if n.stopping {
+				n.mx.Unlock()
+				return
+			}
+			n.status.Restarts++
+			n.status.LastExit = n.cmd.ProcessState.String()
+			if time.Since(n.status.Started) > clusterStableTime {
+				delay = clusterRestartDelay
+			}
+			n.mx.Unlock()
+			update()
+		}
+		fmt.Printf("%s exited (%s), restarting it in %v\n", n.name, n.status.LastExit, delay)
+		select {
+		case <-time.After(delay):
+		case <-quit:
+			return
+		}
+		if delay *= 2; delay > clusterMaxRestartDelay {
+			delay = clusterMaxRestartDelay
+		}
+	}
+}
+// clusterSupervisor returns the pid of the supervisor of the cluster and whether it is running
+func clusterSupervisor(base string) (int, bool) {
+	return readPid(base + clusterPidFile)
+}
+// readClusterStatus reads the status of the cluster last written by its supervisor
+func readClusterStatus(base string) (*clusterStatus, error) {
+	b, err := ioutil.ReadFile(base + clusterStatusFile)
+	if err != nil {
+		return nil, err
+	}
+	status := &clusterStatus{}
+	if err = json.Unmarshal(b, status); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+// writeClusterStatus replaces the status file of the cluster, so it is never read half written
+func writeClusterStatus(base string, status *clusterStatus) error {
+	b, err := json.MarshalIndent(status, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := base + clusterStatusFile + ".tmp"
+	if err = ioutil.WriteFile(tmp, b, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, base+clusterStatusFile)
+}
+// writePid writes a pid file
+func writePid(path string, pid int) error {
+	return ioutil.WriteFile(path, []byte(strconv.Itoa(pid)+"\n"), 0600)
+}
+// readPid returns the pid in a pid file and whether a process with it is running
+func readPid(path string) (int, bool) {
+	if !util.FileExists(path) {
+		return 0, false
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(b)))
+	if err != nil {
+		return 0, false
+	}
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return pid, false
+	}
+	return pid, p.Signal(syscall.Signal(0)) == nil
+}
@@ -0,0 +1,60 @@
+package app
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"time"
+	"git.parallelcoin.io/dev/9/cmd/ctl"
+	"git.parallelcoin.io/dev/9/cmd/def"
+	"git.parallelcoin.io/dev/9/pkg/rpc/fuzz"
+	"git.parallelcoin.io/dev/9/pkg/util/cl"
+)
+// fuzzRequests is how many requests are sent when no number is given
+const fuzzRequests = 1000
+// fuzzTimeout is how long a request is waited on before it is reported as a hang
+const fuzzTimeout = 10 * time.Second
+// fuzzReportFile is the file in the data directory the findings are written to
+const fuzzReportFile = "fuzzrpc.json"
+// FuzzRPC sends adversarial requests to the node RPC server of the configuration, prints the crashes, hangs and inconsistent error codes it finds and writes them with their requests to fuzzrpc.json in the data directory
+func FuzzRPC(args []string, tokens def.Tokens, ap *def.App) int {
+	cl.Register.SetAllLevels(*ap.Config.LogLevel)
+	setAppDataDir(ap, "ctl")
+	n := fuzzRequests
+	if t, ok := tokens["integer"]; ok {
+		var err error
+		if n, err = strconv.Atoi(t.Value); err != nil || n < 1 {
+			fmt.Println("the number of requests must be at least 1")
+			return 1
+		}
+	}
+	*ap.Config.Wallet = false
+	post := func(body []byte) ([]byte, error) {
+		return ctl.PostRequest(body, ap.Config, fuzzTimeout)
+	}
+	fmt.Printf("sending %d requests to %s\n", n, *ap.Config.RPCConnect)
+	findings := []fuzz.Finding{}
+	err := fuzz.New(post, time.Now().UnixNano()).Run(n, func(f fuzz.Finding) {
+		fmt.Printf("%s %s: %s\n", f.Kind, f.Method, f.Detail)
+		findings = append(findings, f)
+	})
+	if err != nil {
+		fmt.Println(err)
+	}
+	report, jerr := json.MarshalIndent(findings, "", "  ")
+	if jerr == nil {
+		path := filepath.Join(*ap.Config.DataDir, fuzzReportFile)
+		if jerr = ioutil.WriteFile(path, report, 0600); jerr == nil {
+			fmt.Printf("%d findings written to %s\n", len(findings), path)
+		}
+	}
+	if jerr != nil {
+		fmt.Println("could not write the findings:", jerr)
+		return 1
+	}
+	if err != nil {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,25 @@
+package app
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"git.parallelcoin.io/dev/9/cmd/def"
+	"git.parallelcoin.io/dev/9/cmd/node"
+)
+// GenCheckpoints prints checkpoints picked from the node's own chain every <integer> blocks, for adding to chain.addcheckpoints, without running the node
+func GenCheckpoints(args []string, tokens def.Tokens, ap *def.App) int {
+	interval := int32(node.DefaultCheckpointInterval)
+	if t, ok := tokens["integer"]; ok {
+		n, err := strconv.ParseInt(t.Value, 10, 32)
+		if err != nil || n < 1 {
+			fmt.Println("checkpoint interval must be a positive number of blocks")
+			return 1
+		}
+		interval = int32(n)
+	}
+	if err := node.GenCheckpoints(os.Stdout, interval); err != nil {
+		fmt.Println("could not generate checkpoints:", err)
+		return 1
+	}
+	return 0
+}
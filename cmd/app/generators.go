@@ -7,6 +7,7 @@ import (
 	"regexp"
 	"strconv"
 	"time"
+	"git.parallelcoin.io/dev/9/cmd/conf"
 	"git.parallelcoin.io/dev/9/cmd/def"
 	"git.parallelcoin.io/dev/9/cmd/nine"
 	"git.parallelcoin.io/dev/9/pkg/ifc"
@@ -535,6 +536,61 @@ func Net(name string, g ...def.RowGenerator) def.CatGenerator {
 		(*ctx)[name] = c
 	}
 }
+// Theme is the color theme of the terminal user interfaces, one of the themes
+// of the configuration CLI
+func Theme(name string, g ...def.RowGenerator) def.CatGenerator {
+	G := def.RowGenerators(g)
+	return func(ctx *def.Cat) {
+		c := &def.Row{}
+		c.Init = func(cc *def.Row) {
+			cc.Name = name
+			cc.Type = "options"
+			cc.Opts = conf.ThemeNames()
+			cc.Get = func() interface{} {
+				return cc.Value.Get()
+			}
+			cc.Validate = Valid.Theme
+			cc.Value = ifc.NewIface()
+			cc.Put = func(in interface{}) bool {
+				valid := cc.Validate(cc, in)
+				if valid {
+					cc.Value = cc.Value.Put(in)
+				}
+				return valid
+			}
+			G.RunAll(cc)
+		}
+		c.Init(c)
+		(*ctx)[name] = c
+	}
+}
+// Unit is the unit amounts are shown in, one of the labels of the amount units
+func Unit(name string, g ...def.RowGenerator) def.CatGenerator {
+	G := def.RowGenerators(g)
+	return func(ctx *def.Cat) {
+		c := &def.Row{}
+		c.Init = func(cc *def.Row) {
+			cc.Name = name
+			cc.Type = "options"
+			cc.Opts = util.AmountUnitNames()
+			cc.Get = func() interface{} {
+				return cc.Value.Get()
+			}
+			cc.Validate = Valid.Unit
+			cc.Value = ifc.NewIface()
+			cc.Put = func(in interface{}) bool {
+				valid := cc.Validate(cc, in)
+				if valid {
+					cc.Value = cc.Value.Put(in)
+				}
+				return valid
+			}
+			G.RunAll(cc)
+		}
+		c.Init(c)
+		(*ctx)[name] = c
+	}
+}
 // which is populated by
 // Usage populates the usage field for information about a config item
 func Usage(usage string) def.RowGenerator {
@@ -669,6 +725,13 @@ func Max(max int) def.RowGenerator {
 		}
 	}
 }
+// Secret marks an item such as a password that editors other than the config
+// file don't show the value of
+func Secret() def.RowGenerator {
+	return func(ctx *def.Row) {
+		ctx.Secret = true
+	}
+}
 // RandomString generates a random number and converts to base32 for
 // a default random password of some number of characters
 func RandomString(n int) def.RowGenerator {
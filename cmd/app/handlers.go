@@ -1,17 +1,23 @@
 package app
 import (
 	"fmt"
+	"io/ioutil"
 	"path/filepath"
 	"sort"
+	"strconv"
+	"time"
 	"git.parallelcoin.io/dev/9/cmd/conf"
 	"git.parallelcoin.io/dev/9/cmd/ctl"
 	"git.parallelcoin.io/dev/9/cmd/def"
+	"git.parallelcoin.io/dev/9/cmd/gui"
 	"git.parallelcoin.io/dev/9/cmd/ll"
 	"git.parallelcoin.io/dev/9/cmd/nine"
 	"git.parallelcoin.io/dev/9/cmd/node"
+	"git.parallelcoin.io/dev/9/cmd/top"
 	"git.parallelcoin.io/dev/9/cmd/walletmain"
 	"git.parallelcoin.io/dev/9/pkg/util"
 	"git.parallelcoin.io/dev/9/pkg/util/cl"
+	"git.parallelcoin.io/dev/9/pkg/util/interrupt"
 )
 // Log is the logger for node
 var Log = cl.NewSubSystem("cmd/config", ll.DEFAULT)
@@ -139,7 +145,15 @@ func Ctl(args []string, tokens def.Tokens, ap *def.App) int {
 			break
 		}
 	}
-	ctl.Main(args[i:], ap.Config)
+	// the qr flag is not part of the RPC command
+	q, showQR := tokens["qr"]
+	var rpcArgs []string
+	for _, x = range args[i:] {
+		if !showQR || x != q.Value {
+			rpcArgs = append(rpcArgs, x)
+		}
+	}
+	ctl.Main(rpcArgs, ap.Config, showQR)
 	return 0
 }
 // Node launches the full node
@@ -161,9 +175,42 @@ func Node(args []string, tokens def.Tokens, ap *def.App) int {
 		validateDialers(ap) != 0 {
 		return 1
 	}
+	applyMemoryPlan(ap)
+	util.LazyParse = *ap.Config.LazyParse
+	if _, ok := tokens["gencheckpoints"]; ok {
+		return GenCheckpoints(args, tokens, ap)
+	}
+	if _, ok := tokens["benchverify"]; ok {
+		return BenchVerify(args, tokens, ap)
+	}
+	if _, ok := tokens["exportheaders"]; ok {
+		return ExportHeaders(args, tokens, ap)
+	}
+	if _, ok := tokens["importheaders"]; ok {
+		return ImportHeaders(args, tokens, ap)
+	}
+	if _, ok := tokens["chaingen"]; ok {
+		return ChainGen(args, tokens, ap)
+	}
+	if _, ok := tokens["migrate"]; ok {
+		return Migrate(args, tokens, ap)
+	}
 	// run the node!
 	ap.Started = make(chan struct{})
-	go node.Main(nil, ap.Started)
+	go func() {
+		// a node that fails to start shuts down the rest of the process with it
+		if err := node.Main(nil, ap.Started); err != nil && !interrupt.Requested() {
+			interrupt.Request()
+		}
+	}()
+	return 0
+}
+// RunNode runs the full node until it is interrupted or stopped over RPC
+func RunNode(args []string, tokens def.Tokens, ap *def.App) int {
+	if r := Node(args, tokens, ap); r != 0 || ap.Started == nil {
+		return r
+	}
+	<-interrupt.HandlersDone
 	return 0
 }
 // Wallet launches the wallet server
@@ -201,21 +248,23 @@ func Shell(args []string, tokens def.Tokens, ap *def.App) int {
 			panic("could not create wallet " + e.Error())
 		}
 	} else {
-		Node(args, tokens, ap)
-		<-ap.Started
-		log <- cl.Info{"starting wallet server"}
+		if *ap.Config.WalletSPV {
+			// in light mode the wallet syncs from peers, there is no full node
+			cl.Register.SetAllLevels(*ap.Config.LogLevel)
+			log <- cl.Info{"starting wallet server in light mode"}
+		} else {
+			Node(args, tokens, ap)
+			<-ap.Started
+			// the wallet uses the chain of the node directly rather than over RPC
+			walletmain.InProcessChain = node.InProcessChain()
+			log <- cl.Info{"starting wallet server"}
+		}
 		if e := walletmain.Main(ap.Config, ap.Config.ActiveNetParams, netDir); e != nil {
 			return 1
 		}
 	}
 	return 0
 }
-// Test runs a testnet based on a set of configuration directories
-func Test(args []string, tokens def.Tokens, ap *def.App) int {
-	cl.Register.SetAllLevels(*ap.Config.LogLevel)
-	fmt.Println("running Test", args, getTokens(tokens))
-	return 0
-}
 // Create generates a set of configurations that are set to connect to each other
 // in a testnet
 func Create(args []string, tokens def.Tokens, ap *def.App) int {
@@ -234,17 +283,78 @@ func Create(args []string, tokens def.Tokens, ap *def.App) int {
 	}
 	return 0
 }
-// // TestHandler ???
-// func TestHandler(args []string, tokens def.Tokens, ap *def.App) int {
-// 	return 0
-// }
-// GUI runs a shell in the background and a GUI interface for wallet and node
+// Top shows a live dashboard of the full node, running the node in the same
+// process if <node> is given, with the screens of the wallet server if <wallet>
+// is given
+func Top(args []string, tokens def.Tokens, ap *def.App) int {
+	interval := top.DefaultRefresh
+	if t, ok := tokens["integer"]; ok {
+		n, err := strconv.Atoi(t.Value)
+		if err != nil || n < 1 {
+			fmt.Println("refresh interval must be a positive number of seconds")
+			return 1
+		}
+		interval = time.Duration(n) * time.Second
+	}
+	*ap.Config.Wallet = false
+	_, wallet := tokens["wallet"]
+	if _, ok := tokens["node"]; !ok {
+		cl.Register.SetAllLevels(*ap.Config.LogLevel)
+		setAppDataDir(ap, "ctl")
+		return top.Run(ap.Config, interval, wallet)
+	}
+	// the dashboard owns the terminal, so the node only logs into the history
+	// the log panel shows
+	cl.Writer = ioutil.Discard
+	if r := Node(args, tokens, ap); r != 0 {
+		return r
+	}
+	<-ap.Started
+	r := top.Run(ap.Config, interval, wallet)
+	interrupt.Request()
+	<-interrupt.HandlersDone
+	return r
+}
+// GUI serves the web wallet and opens it in the browser, running the node in
+// the same process if <node> is given. When the page asks for a restart after
+// changing the configuration, everything is shut down and started again.
 func GUI(args []string, tokens def.Tokens, ap *def.App) int {
-	return 0
+	*ap.Config.Wallet = false
+	_, node := tokens["node"]
+	if node && !*ap.Config.WalletSPV {
+		if r := Node(args, tokens, ap); r != 0 {
+			return r
+		}
+		<-ap.Started
+	} else {
+		cl.Register.SetAllLevels(*ap.Config.LogLevel)
+		setAppDataDir(ap, "gui")
+		if node {
+			log <- cl.Warn{"not starting the full node, the wallet is in light mode"}
+		}
+	}
+	// the web wallet only stops by itself if it can't listen, otherwise it
+	// is stopped by an interrupt, which also stops the node
+	r := gui.Run(ap, true)
+	if !interrupt.Requested() {
+		interrupt.Request()
+	}
+	<-interrupt.HandlersDone
+	if r == 0 && gui.Restarting() {
+		if err := gui.Relaunch(); err != nil {
+			fmt.Println("could not restart:", err)
+			return 1
+		}
+	}
+	return r
 }
-// Mine runs the standalone miner
+// Mine runs the standalone miner, or benchmarks the mining algorithms if
+// <bench> is given
 func Mine(args []string, tokens def.Tokens, ap *def.App) int {
-	return 0
+	if _, ok := tokens["bench"]; ok {
+		return Bench(args, tokens, ap)
+	}
+	return RunMiner(args, tokens, ap)
 }
 // GenCerts generates TLS certificates
 func GenCerts(args []string, tokens def.Tokens, ap *def.App) int {
@@ -0,0 +1,47 @@
+package app
+import (
+	"fmt"
+	"os"
+	"strings"
+	"git.parallelcoin.io/dev/9/cmd/def"
+	"git.parallelcoin.io/dev/9/cmd/node"
+)
+// defaultHeadersFile is the file exportheaders writes and importheaders reads when no other is given after an =
+const defaultHeadersFile = "headers.dat"
+// headersFile returns the file given after the = of the command token, or the default one
+func headersFile(token def.Token) string {
+	if i := strings.Index(token.Value, "="); i >= 0 && i+1 < len(token.Value) {
+		return token.Value[i+1:]
+	}
+	return defaultHeadersFile
+}
+// ExportHeaders writes the headers of the node's own chain to the file after exportheaders=, without running the node
+func ExportHeaders(args []string, tokens def.Tokens, ap *def.App) int {
+	path := headersFile(tokens["exportheaders"])
+	f, err := os.Create(path)
+	if err != nil {
+		fmt.Println("could not create headers file:", err)
+		return 1
+	}
+	height, err := node.ExportHeaders(f)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		fmt.Println("could not export headers:", err)
+		return 1
+	}
+	fmt.Printf("exported the headers of blocks 1 to %d to %s\n", height, path)
+	return 0
+}
+// ImportHeaders checks the headers in the file after importheaders= and keeps them for the node to download their blocks when it next runs
+func ImportHeaders(args []string, tokens def.Tokens, ap *def.App) int {
+	path := headersFile(tokens["importheaders"])
+	count, err := node.ImportHeaders(path)
+	if err != nil {
+		fmt.Println("could not import headers:", err)
+		return 1
+	}
+	fmt.Printf("imported the headers of blocks 1 to %d from %s\n", count, path)
+	return 0
+}
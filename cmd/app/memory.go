@@ -0,0 +1,35 @@
+package app
+import (
+	"git.parallelcoin.io/dev/9/cmd/def"
+	"git.parallelcoin.io/dev/9/pkg/db/ffldb"
+	"git.parallelcoin.io/dev/9/pkg/util/cl"
+	"git.parallelcoin.io/dev/9/pkg/util/limits"
+)
+const megabyte = 1024 * 1024
+// applyMemoryPlan sizes the garbage collection target, the ballast, the block database cache and the chain's utxo cache to the memory limit, app.memlimit or the one detected, with the app group's settings and chain.dbcache overriding the plan
+func applyMemoryPlan(
+	ap *def.App) {
+	c := ap.Config
+	limit := limits.MemoryLimit()
+	if *c.MemLimit > 0 {
+		limit = uint64(*c.MemLimit) * megabyte
+	}
+	plan := limits.PlanMemory(limit)
+	if *c.DBCache > 0 {
+		plan.DBCache = uint64(*c.DBCache) * megabyte
+	}
+	if *c.UtxoCache > 0 {
+		plan.UtxoCache = uint64(*c.UtxoCache) * megabyte
+	}
+	if *c.GCPercent > 0 {
+		plan.GCPercent = *c.GCPercent
+	}
+	if *c.Ballast >= 0 {
+		plan.Ballast = uint64(*c.Ballast) * megabyte
+	}
+	plan.Apply()
+	ffldb.CacheSize = plan.DBCache
+	c.State.UtxoCacheSize = plan.UtxoCache
+	log <- cl.Infof{"memory limit %dMB: gc target %d%%, ballast %dMB, database cache %dMB, utxo cache %dMB",
+		plan.Limit / megabyte, plan.GCPercent, plan.Ballast / megabyte, plan.DBCache / megabyte, plan.UtxoCache / megabyte}
+}
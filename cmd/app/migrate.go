@@ -0,0 +1,31 @@
+package app
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"git.parallelcoin.io/dev/9/cmd/def"
+	"git.parallelcoin.io/dev/9/cmd/node"
+)
+// Migrate prints the plan of the migrations the node would apply to its data when it next starts without applying them, or with a version following migrate= undoes the migrations applied after it, without running the node
+func Migrate(args []string, tokens def.Tokens, ap *def.App) int {
+	value := tokens["migrate"].Value
+	i := strings.Index(value, "=")
+	if i < 0 {
+		if err := node.MigrationPlan(os.Stdout); err != nil {
+			fmt.Println("could not plan migrations:", err)
+			return 1
+		}
+		return 0
+	}
+	version, err := strconv.ParseUint(value[i+1:], 10, 32)
+	if err != nil {
+		fmt.Println("the version to roll back to must follow migrate=")
+		return 1
+	}
+	if err = node.RollbackMigrations(os.Stdout, uint32(version)); err != nil {
+		fmt.Println("could not roll back migrations:", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,82 @@
+package app
+import (
+	"encoding/hex"
+	"fmt"
+	"runtime"
+	"strings"
+	"git.parallelcoin.io/dev/9/cmd/def"
+	"git.parallelcoin.io/dev/9/pkg/chain/fork"
+	controller "git.parallelcoin.io/dev/9/pkg/chain/mining/dispatch"
+	"git.parallelcoin.io/dev/9/pkg/util/cl"
+	"git.parallelcoin.io/dev/9/pkg/util/interrupt"
+)
+// RunMiner runs the standalone miner as a worker of the nodes listed in
+// mining.listener, or of those advertising themselves on the LAN when it is
+// empty, authenticating with mining.pass, until interrupted
+func RunMiner(args []string, tokens def.Tokens, ap *def.App) int {
+	cl.Register.SetAllLevels(*ap.Config.LogLevel)
+	setAppDataDir(ap, "mine")
+	controllers := controller.WorkerAddrs(*ap.Config.MinerListener)
+	if len(controllers) == 0 {
+		log <- cl.Info{"looking for miner controllers on the LAN"}
+		addrs, err := controller.Discover(ap.Config.ActiveNetParams.Name, controller.DiscoverTimeout)
+		if err != nil {
+			log <- cl.Warn{"unable to look for miner controllers on the LAN:", err}
+		}
+		if len(addrs) == 0 {
+			fmt.Println("no miner controller found on the LAN, mining.listener must be set to the miner listener address of the node")
+			return 1
+		}
+		log <- cl.Info{"found miner controllers", addrs}
+		controllers = addrs
+	}
+	// An API key is used instead of the mining password when one is set.
+	key, keyName := []byte(nil), ""
+	if *ap.Config.MinerAPIKey != "" {
+		i := strings.Index(*ap.Config.MinerAPIKey, ":")
+		if i < 1 || i == len(*ap.Config.MinerAPIKey)-1 || i > 255 {
+			fmt.Println("mining.apikey must be name:secret as configured in mining.apikeys on the node")
+			return 1
+		}
+		keyName = (*ap.Config.MinerAPIKey)[:i]
+		key = fork.Argon2i([]byte((*ap.Config.MinerAPIKey)[i+1:]))
+	} else if *ap.Config.MinerPass == "" {
+		fmt.Println("mining.pass must be set to the same password as on the node")
+		return 1
+	} else {
+		key = fork.Argon2i([]byte(*ap.Config.MinerPass))
+	}
+	if ap.Config.ActiveNetParams.Name == "testnet" {
+		fork.IsTestnet = true
+	}
+	var controllerKeys [][]byte
+	for _, k := range *ap.Config.MinerControllerKeys {
+		key, err := hex.DecodeString(k)
+		if err != nil || len(key) != 32 {
+			fmt.Println("mining.controllerkeys must be the 64 character hex identity keys logged by the miner controllers")
+			return 1
+		}
+		controllerKeys = append(controllerKeys, key)
+	}
+	threads := *ap.Config.GenThreads
+	if threads < 1 {
+		threads = runtime.NumCPU()
+	}
+	w := controller.NewWorker(&controller.WorkerConfig{
+		Controllers:    controllers,
+		Key:            key,
+		KeyName:        keyName,
+		Algo:           *ap.Config.Algo,
+		Threads:        threads,
+		Encrypt:        *ap.Config.MinerEncrypt,
+		ControllerKeys: controllerKeys,
+		Multicast:      *ap.Config.MinerMulticast,
+	})
+	quit := make(chan struct{})
+	interrupt.AddHandler(func() {
+		close(quit)
+	})
+	log <- cl.Info{"starting miner with", threads, "threads mining", *ap.Config.Algo}
+	w.Run(quit)
+	return 0
+}
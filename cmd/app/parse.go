@@ -26,6 +26,11 @@ func Parse(ap *def.App, args []string) int {
 		ap.Cats["app"]["datadir"].Value.Put(*datadir)
 		DataDir = *datadir
 	} else {
+		if dir, err := util.MigrateAppDataDir("9"); err != nil {
+			log <- cl.Warn{"could not move the data directory to", dir, "-", err}
+		} else if dir != "" {
+			log <- cl.Info{"moved the data directory to", dir, "leaving a symlink in its old place"}
+		}
 		ddd := util.AppDataDir("9", false)
 		ap.Cats["app"]["datadir"].Put(ddd)
 		datadir = &ddd
@@ -45,8 +50,7 @@ func Parse(ap *def.App, args []string) int {
 	// 	cmd.Name),
 	// 	*datadir)
 	// ap.Config.AppDataDir, ap.Config.LogDir = &aa, &aa
-	configFile := util.CleanAndExpandPath(filepath.Join(
-		*datadir, "config"), *datadir)
+	configFile := util.CleanAndExpandPath(util.ConfigFile("9", *datadir), *datadir)
 	// *ap.Config.ConfigFile = configFile
 	if !util.FileExists(configFile) {
 		if util.EnsureDir(configFile) {
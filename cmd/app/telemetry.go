@@ -0,0 +1,67 @@
+package app
+import (
+	js "encoding/json"
+	"fmt"
+	"time"
+	"git.parallelcoin.io/dev/9/cmd/ctl"
+	"git.parallelcoin.io/dev/9/cmd/def"
+	"git.parallelcoin.io/dev/9/cmd/node"
+	"git.parallelcoin.io/dev/9/pkg/rpc/json"
+	"git.parallelcoin.io/dev/9/pkg/util/cl"
+)
+// telemetryTimeout is how long the node is waited on for its height and peer count
+const telemetryTimeout = 10 * time.Second
+// Telemetry prints whether telemetry is enabled, where it is sent and the report the node would send now, with the block height and peer count asked of the node over RPC when it is running
+func Telemetry(args []string, tokens def.Tokens, ap *def.App) int {
+	cl.Register.SetAllLevels(*ap.Config.LogLevel)
+	setAppDataDir(ap, "node")
+	switch {
+	case !*ap.Config.Telemetry:
+		fmt.Println("telemetry is disabled, nothing is sent, set telemetry.enable to opt in")
+	case *ap.Config.TelemetryEndpoint == "":
+		fmt.Println("telemetry is enabled but telemetry.endpoint is not set, nothing is sent")
+	default:
+		fmt.Printf("telemetry is enabled, a report is sent to %s every %v\n",
+			*ap.Config.TelemetryEndpoint, *ap.Config.TelemetryInterval)
+	}
+	var height, peers int32
+	*ap.Config.Wallet = false
+	info, err := telemetryInfo(ap)
+	if err != nil {
+		fmt.Println("could not get the height and peers from the node, showing them as 0:", err)
+	} else {
+		height, peers = info.Blocks, info.Connections
+	}
+	report := node.NewTelemetryReport(ap.Config.ActiveNetParams.Name, height, peers)
+	body, err := js.MarshalIndent(report, "", "  ")
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	fmt.Println("the report is POSTed as:")
+	fmt.Println(string(body))
+	return 0
+}
+// telemetryInfo asks the node of the configuration for getinfo
+func telemetryInfo(ap *def.App) (*json.InfoChainResult, error) {
+	request, err := json.MarshalCmd(1, json.NewGetInfoCmd())
+	if err != nil {
+		return nil, err
+	}
+	body, err := ctl.PostRequest(request, ap.Config, telemetryTimeout)
+	if err != nil {
+		return nil, err
+	}
+	var resp json.Response
+	if err = js.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+	var info json.InfoChainResult
+	if err = js.Unmarshal(resp.Result, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
@@ -0,0 +1,401 @@
+package app
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"git.parallelcoin.io/dev/9/cmd/ctl"
+	"git.parallelcoin.io/dev/9/cmd/def"
+	"git.parallelcoin.io/dev/9/cmd/nine"
+	"git.parallelcoin.io/dev/9/pkg/util"
+	ec "git.parallelcoin.io/dev/9/pkg/util/elliptic"
+	"git.parallelcoin.io/dev/9/pkg/util/interrupt"
+)
+// The nodes of a test network listen on consecutive ports from these, in the order of their data directories
+const (
+	testP2PPort = 31047
+	testRPCPort = 32048
+)
+// testLogFile is the file in the data directory of each node of a test network that its output is written to
+const testLogFile = "test.log"
+// testStopTimeout is how long a node of a test network is given to shut down after being asked to before it is killed
+const testStopTimeout = 30 * time.Second
+// Topologies are the ways the nodes of a test network can be wired to each other
+var Topologies = []string{"line", "star", "mesh"}
+// testNode is a node of a test network running in its own process
+type testNode struct {
+	name string
+	dir  string
+	cfg  *nine.Config
+	cmd  *exec.Cmd
+	log  *os.File
+	done chan struct{}
+}
+// Test runs a simnet of full nodes from the data directories named after the basename following the test keyword and a number, each in its own process, wired to each other in a topology, and mines blocks on them as asked on stdin until told to quit or interrupted
+func Test(args []string, tokens def.Tokens, ap *def.App) int {
+	var base string
+	for i, x := range args {
+		if ap.Commands["test"].RE.Match([]byte(x)) && i+1 < len(args) {
+			base = args[i+1]
+			break
+		}
+	}
+	if base == "" {
+		fmt.Println("a basename of the data directories of the test network must follow test")
+		return 1
+	}
+	nodes, topology, err := testNetwork(ap, base, tokens)
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		fmt.Println("could not find the executable to run the nodes with:", err)
+		return 1
+	}
+	pwd, err := os.Getwd()
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	_, toFiles := tokens["log"]
+	fmt.Printf("running %d nodes from %s in a %s\n", len(nodes), base, topology)
+	for i, n := range nodes {
+		if err := n.start(exe, pwd, testLogFile, toFiles); err != nil {
+			fmt.Printf("could not start %s: %v\n", n.name, err)
+			stopTestNodes(nodes)
+			return 1
+		}
+		fmt.Printf("%s p2p 127.0.0.1:%d rpc %s log %s\n", n.name,
+			testP2PPort+i, *n.cfg.RPCConnect, filepath.Join(n.dir, testLogFile))
+	}
+	fmt.Println(testHelp)
+	lines := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+	ctx := interrupt.Context()
+	for quit := false; !quit; {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				quit = true
+				break
+			}
+			quit = runTestCommand(nodes, strings.Fields(line))
+		case <-ctx.Done():
+			quit = true
+		}
+	}
+	stopTestNodes(nodes)
+	return 0
+}
+// testNetwork configures the nodes of a test network from the data directories named after the basename, as many as the integer token sets and wired to each other in the topology the topology token sets, and returns them with the name of the topology
+func testNetwork(ap *def.App, base string, tokens def.Tokens) ([]*testNode, string, error) {
+	dirs, err := testnetDirs(base)
+	if err != nil {
+		return nil, "", err
+	}
+	if t, ok := tokens["integer"]; ok {
+		n, err := strconv.Atoi(t.Value)
+		if err != nil || n < 1 || n > len(dirs) {
+			return nil, "", fmt.Errorf("can run 1 to %d nodes from %s", len(dirs), base)
+		}
+		dirs = dirs[:n]
+	}
+	topology := "line"
+	if t, ok := tokens["topology"]; ok {
+		topology = strings.TrimPrefix(t.Value, "topology=")
+	}
+	peers, err := topologyPeers(topology, len(dirs))
+	if err != nil {
+		return nil, "", err
+	}
+	nodes := make([]*testNode, len(dirs))
+	for i, dir := range dirs {
+		var addrs []string
+		for _, j := range peers[i] {
+			addrs = append(addrs, fmt.Sprintf("127.0.0.1:%d", testP2PPort+j))
+		}
+		cfg, err := configureTestNode(ap, dir, i, addrs)
+		if err != nil {
+			return nil, "", fmt.Errorf("could not configure %s: %v", dir, err)
+		}
+		nodes[i] = &testNode{name: filepath.Base(dir), dir: dir, cfg: cfg}
+	}
+	return nodes, topology, nil
+}
+// testHelp lists the commands Test reads from stdin
+const testHelp = `commands:
+	mine [blocks] [node]	mine blocks (default 1) on a node (default 1)
+	status			print the height and peer count of each node
+	quit			stop the nodes and exit`
+// runTestCommand runs a command read by Test on the nodes, and returns true if it was told to quit
+func runTestCommand(nodes []*testNode, fields []string) bool {
+	if len(fields) < 1 {
+		return false
+	}
+	switch fields[0] {
+	case "mine", "m":
+		blocks, node := 1, 1
+		var err error
+		if len(fields) > 1 {
+			if blocks, err = strconv.Atoi(fields[1]); err != nil || blocks < 1 {
+				fmt.Println("the number of blocks must be a positive number")
+				return false
+			}
+		}
+		if len(fields) > 2 {
+			if node, err = strconv.Atoi(fields[2]); err != nil || node < 1 || node > len(nodes) {
+				fmt.Printf("the node must be a number from 1 to %d\n", len(nodes))
+				return false
+			}
+		}
+		n := nodes[node-1]
+		var hashes []string
+		if err := ctl.Call(n.cfg, "generate", &hashes, uint32(blocks)); err != nil {
+			fmt.Printf("%s could not mine: %v\n", n.name, err)
+			return false
+		}
+		for _, h := range hashes {
+			fmt.Println(n.name, "mined", h)
+		}
+	case "status", "s":
+		for _, n := range nodes {
+			var height, peers int64
+			if err := ctl.Call(n.cfg, "getblockcount", &height); err != nil {
+				fmt.Printf("%s: %v\n", n.name, err)
+				continue
+			}
+			if err := ctl.Call(n.cfg, "getconnectioncount", &peers); err != nil {
+				fmt.Printf("%s: %v\n", n.name, err)
+				continue
+			}
+			fmt.Printf("%s height %d peers %d\n", n.name, height, peers)
+		}
+	case "quit", "exit", "q":
+		return true
+	default:
+		fmt.Println(testHelp)
+	}
+	return false
+}
+// testnetDirs returns the directories named after the basename followed by a number, in the order of their numbers
+func testnetDirs(base string) ([]string, error) {
+	matches, err := filepath.Glob(base + "*")
+	if err != nil {
+		return nil, err
+	}
+	nums := make(map[string]int)
+	var dirs []string
+	for _, m := range matches {
+		n, err := strconv.Atoi(strings.TrimPrefix(m, base))
+		if err != nil || n < 0 {
+			continue
+		}
+		if fi, err := os.Stat(m); err != nil || !fi.IsDir() {
+			continue
+		}
+		abs, err := filepath.Abs(m)
+		if err != nil {
+			return nil, err
+		}
+		nums[abs] = n
+		dirs = append(dirs, abs)
+	}
+	if len(dirs) < 1 {
+		return nil, fmt.Errorf("no data directories named %s followed by a number", base)
+	}
+	sort.Slice(dirs, func(i, j int) bool { return nums[dirs[i]] < nums[dirs[j]] })
+	return dirs, nil
+}
+// topologyPeers returns the indexes of the nodes each of n nodes connects to in the topology. In a line each node connects to the one before it, in a star all connect to the first, and in a mesh each connects to all those before it, so every pair of nodes is connected once
+func topologyPeers(topology string, n int) ([][]int, error) {
+	peers := make([][]int, n)
+	for i := 1; i < n; i++ {
+		switch topology {
+		case "line":
+			peers[i] = []int{i - 1}
+		case "star":
+			peers[i] = []int{0}
+		case "mesh":
+			for j := 0; j < i; j++ {
+				peers[i] = append(peers[i], j)
+			}
+		}
+	}
+	for _, t := range Topologies {
+		if t == topology {
+			return peers, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown topology %s, not one of %s", topology, strings.Join(Topologies, ", "))
+}
+// configureTestNode writes the configuration of the data directory of the node at index i of a test network, to run on simnet on its own ports, connecting to the given peers, with a mining address if it has none, and returns the configuration to reach it over RPC with
+func configureTestNode(ap *def.App, dir string, i int, peers []string) (*nine.Config, error) {
+	if err := loadTestConfig(ap, dir); err != nil {
+		return nil, err
+	}
+	p2p, rpc := fmt.Sprintf("127.0.0.1:%d", testP2PPort+i), fmt.Sprintf("127.0.0.1:%d", testRPCPort+i)
+	// lists of addresses are added to by Put, so they are emptied first
+	for _, x := range [][2]string{{"p2p", "listen"}, {"p2p", "connect"}, {"p2p", "addpeer"}, {"rpc", "listen"}} {
+		ap.Cats[x[0]][x[1]].Value.Put([]string{})
+	}
+	if !ap.Cats["p2p"]["network"].Put("simnet") ||
+		!ap.Cats["p2p"]["listen"].Put(p2p) ||
+		!ap.Cats["p2p"]["addpeer"].Put(peers) ||
+		!ap.Cats["p2p"]["nolisten"].Put(false) ||
+		!ap.Cats["p2p"]["nodns"].Put(true) ||
+		!ap.Cats["app"]["upnp"].Put(false) ||
+		!ap.Cats["rpc"]["listen"].Put(rpc) ||
+		!ap.Cats["rpc"]["connect"].Put(rpc) ||
+		!ap.Cats["rpc"]["disable"].Put(false) ||
+		!ap.Cats["tls"]["disable"].Put(true) ||
+		!ap.Cats["tls"]["server"].Put(false) ||
+		!ap.Cats["mining"]["generate"].Put(false) ||
+		!ap.Cats["wallet"]["enable"].Put(false) {
+		return nil, fmt.Errorf("could not set the test network options")
+	}
+	if addrs, _ := ap.Cats["mining"]["addresses"].Value.Get().([]string); len(addrs) < 1 {
+		addr, err := testMiningAddress()
+		if err != nil {
+			return nil, err
+		}
+		if !ap.Cats["mining"]["addresses"].Put([]string{addr}) {
+			return nil, fmt.Errorf("could not set the mining address")
+		}
+	}
+	ap.SaveConfig()
+	return MakeConfig(ap), nil
+}
+// loadTestConfig loads the configuration of the data directory of a node of a test network into the app, if it has one
+func loadTestConfig(ap *def.App, dir string) error {
+	ap.Cats["app"]["datadir"].Value.Put(dir)
+	configFile := util.CleanAndExpandPath(util.ConfigFile("9", dir), "")
+	if util.FileExists(configFile) {
+		conf, err := ioutil.ReadFile(configFile)
+		if err != nil {
+			return err
+		}
+		if err = json.Unmarshal(conf, ap); err != nil {
+			return err
+		}
+		// the configuration may have been copied from another data directory
+		ap.Cats["app"]["datadir"].Value.Put(dir)
+	}
+	return nil
+}
+// testMiningAddress returns a simnet address of a new key for a node of a test network to mine to. The key is not kept, so the coins are only good for the blocks they are in
+func testMiningAddress() (string, error) {
+	key, err := ec.NewPrivateKey(ec.S256())
+	if err != nil {
+		return "", err
+	}
+	addr, err := util.NewAddressPubKeyHash(
+		util.Hash160(key.PubKey().SerializeCompressed()), NetParams["simnet"].Params)
+	if err != nil {
+		return "", err
+	}
+	return addr.EncodeAddress(), nil
+}
+// start runs the node in its own process from the directory of the test, writing its output to the named log file in its data directory, and also to stdout with its name in front of each line unless toFiles is set
+func (n *testNode) start(exe, pwd, logFile string, toFiles bool) (err error) {
+	// the data directory is given relative to the working directory, which the node joins it to
+	dir, err := filepath.Rel(pwd, n.dir)
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(dir, ".") {
+		dir = "." + string(filepath.Separator) + dir
+	}
+	if n.log, err = os.OpenFile(filepath.Join(n.dir, logFile),
+		os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600); err != nil {
+		return err
+	}
+	var out io.Writer = n.log
+	if !toFiles {
+		out = io.MultiWriter(n.log, &prefixWriter{prefix: n.name + ": ", w: os.Stdout})
+	}
+	n.cmd = exec.Command(exe, dir, "node")
+	n.cmd.Dir, n.cmd.Stdout, n.cmd.Stderr = pwd, out, out
+	if err = n.cmd.Start(); err != nil {
+		n.log.Close()
+		return err
+	}
+	n.done = make(chan struct{})
+	go func() {
+		n.cmd.Wait()
+		n.log.Close()
+		close(n.done)
+	}()
+	return nil
+}
+// stop asks the node to shut down over RPC, and kills it if it has not within testStopTimeout. On an interrupt the nodes get the signal too, so the request may fail while the node is already shutting down
+func (n *testNode) stop() {
+	if n.done == nil {
+		return
+	}
+	ctl.Call(n.cfg, "stop", nil)
+	select {
+	case <-n.done:
+	case <-time.After(testStopTimeout):
+		fmt.Println(n.name, "did not stop, killing it")
+		n.cmd.Process.Kill()
+		<-n.done
+	}
+}
+// stopTestNodes stops all the nodes of a test network at once
+func stopTestNodes(nodes []*testNode) {
+	var wg sync.WaitGroup
+	for _, n := range nodes {
+		if n == nil {
+			continue
+		}
+		wg.Add(1)
+		go func(n *testNode) {
+			n.stop()
+			wg.Done()
+		}(n)
+	}
+	wg.Wait()
+}
+// stdoutLock keeps the lines of the nodes of a test network from being mixed up on stdout
+var stdoutLock sync.Mutex
+// prefixWriter writes whole lines to w with the prefix in front of each
+type prefixWriter struct {
+	prefix string
+	w      io.Writer
+	buf    []byte
+}
+// Write writes the complete lines in p with the prefix in front, keeping the rest until its line is complete
+func (p *prefixWriter) Write(b []byte) (int, error) {
+	p.buf = append(p.buf, b...)
+	for {
+		i := bytes.IndexByte(p.buf, '\n')
+		if i < 0 {
+			return len(b), nil
+		}
+		stdoutLock.Lock()
+		_, err := fmt.Fprintf(p.w, "%s%s", p.prefix, p.buf[:i+1])
+		stdoutLock.Unlock()
+		p.buf = p.buf[i+1:]
+		if err != nil {
+			return len(b), err
+		}
+	}
+}
@@ -11,10 +11,15 @@ import (
 	"strings"
 	"time"
 	"git.parallelcoin.io/dev/9/pkg/chain"
+	"git.parallelcoin.io/dev/9/cmd/conf"
 	"git.parallelcoin.io/dev/9/cmd/def"
 	"git.parallelcoin.io/dev/9/cmd/nine"
 	"git.parallelcoin.io/dev/9/cmd/node"
 	"git.parallelcoin.io/dev/9/pkg/chain/fork"
+	chainhash "git.parallelcoin.io/dev/9/pkg/chain/hash"
+	"git.parallelcoin.io/dev/9/pkg/chain/mining"
+	cpuminer "git.parallelcoin.io/dev/9/pkg/chain/mining/cpu"
+	controller "git.parallelcoin.io/dev/9/pkg/chain/mining/dispatch"
 	"git.parallelcoin.io/dev/9/pkg/ifc"
 	"git.parallelcoin.io/dev/9/pkg/peer/connmgr"
 	"git.parallelcoin.io/dev/9/pkg/util"
@@ -104,6 +109,11 @@ func GenAddrs(name string, port int) func(r *def.Row, in interface{}) bool {
 			return false
 		}
 		for _, sse := range s {
+			// An address may start with the scheme of the transport it is reached by, such as quic://, which is kept.
+			var scheme string
+			if i := strings.Index(sse, "://"); i >= 0 {
+				scheme, sse = sse[:i+3], sse[i+3:]
+			}
 			h, p, e := net.SplitHostPort(sse)
 			if e != nil {
 				sse = net.JoinHostPort(sse, fmt.Sprint(port))
@@ -123,7 +133,7 @@ func GenAddrs(name string, port int) func(r *def.Row, in interface{}) bool {
 				}
 				sse = net.JoinHostPort(h, p)
 			}
-			existing = append(existing, sse)
+			existing = append(existing, scheme+sse)
 		}
 		if r != nil {
 			// eliminate duplicates
@@ -145,10 +155,7 @@ func GenAddrs(name string, port int) func(r *def.Row, in interface{}) bool {
 }
 func getAlgoOptions() (options []string) {
 	var modernd = "random"
-	for _, x := range fork.P9AlgoVers {
-		options = append(options, x)
-	}
-	options = append(options, modernd)
+	options = append(fork.AllAlgoNames(), modernd)
 	sort.Strings(options)
 	return
 }
@@ -157,7 +164,7 @@ func getAlgoOptions() (options []string) {
 // this they assign the validated, parsed value into the Value slot.
 var Valid = struct {
 	File, Dir, Port, Bool, Int, Tag, Tags, Algo, Float, Duration, Net,
-	Level func(*def.Row, interface{}) bool
+	Level, Theme, Unit func(*def.Row, interface{}) bool
 }{}
 func init() {
 	Valid.File = func(r *def.Row, in interface{}) bool {
@@ -502,6 +509,47 @@ func init() {
 		}
 		return found
 	}
+	Valid.Theme = func(r *def.Row, in interface{}) bool {
+		var st string
+		switch I := in.(type) {
+		case string:
+			st = I
+		case *string:
+			st = *I
+		default:
+			return false
+		}
+		if _, found := conf.Themes[st]; !found {
+			return false
+		}
+		if r != nil {
+			r.String = fmt.Sprint(st)
+			r.Value.Put(st)
+			r.App.SaveConfig()
+		}
+		return true
+	}
+	Valid.Unit = func(r *def.Row, in interface{}) bool {
+		var su string
+		switch I := in.(type) {
+		case string:
+			su = I
+		case *string:
+			su = *I
+		default:
+			return false
+		}
+		u, err := util.ParseAmountUnit(su)
+		if err != nil {
+			return false
+		}
+		if r != nil {
+			r.String = u.String()
+			r.Value.Put(u.String())
+			r.App.SaveConfig()
+		}
+		return true
+	}
 	Valid.Level = func(r *def.Row, in interface{}) bool {
 		var sl string
 		switch I := in.(type) {
@@ -654,6 +702,15 @@ func validateBlockLimits(ap *def.App) int {
 		fmt.Println(err)
 		return 1
 	}
+	// Validate the the dustrelayfee.
+	ap.Config.State.ActiveDustRelayFee, err =
+		util.NewAmount(*ap.Config.DustRelayFee)
+	if err != nil || ap.Config.State.ActiveDustRelayFee < 0 {
+		str := "%s: invalid dustrelayfee: %v"
+		err := fmt.Errorf(str, "runNode", *ap.Config.DustRelayFee)
+		fmt.Println(err)
+		return 1
+	}
 	// Limit the block priority and minimum block sizes to max block size.
 	*ap.Config.BlockPrioritySize = int(util.MinUint32(
 		uint32(*ap.Config.BlockPrioritySize),
@@ -703,9 +760,23 @@ func validateMiner(ap *def.App) int {
 	if ap.Config.MiningAddrs != nil {
 		ap.Config.State.ActiveMiningAddrs =
 			make([]util.Address, 0, len(*ap.Config.MiningAddrs))
+		ap.Config.State.ActiveMiningWeights =
+			make([]float64, 0, len(*ap.Config.MiningAddrs))
 		if len(*ap.Config.MiningAddrs) > 0 {
 			for _, strAddr := range *ap.Config.MiningAddrs {
 				if len(strAddr) > 1 {
+					// An address may be followed by its weight for the weighted rotation.
+					weight := 1.0
+					if i := strings.LastIndex(strAddr, ":"); i >= 0 {
+						w, err := strconv.ParseFloat(strAddr[i+1:], 64)
+						if err != nil || w <= 0 {
+							str := "%s: mining address '%s' weight must be a positive number"
+							err := fmt.Errorf(str, "runNode", strAddr)
+							fmt.Fprintln(os.Stderr, err)
+							return 1
+						}
+						strAddr, weight = strAddr[:i], w
+					}
 					addr, err := util.DecodeAddress(strAddr,
 						ap.Config.ActiveNetParams.Params)
 					if err != nil {
@@ -722,6 +793,8 @@ func validateMiner(ap *def.App) int {
 					}
 					ap.Config.State.ActiveMiningAddrs =
 						append(ap.Config.State.ActiveMiningAddrs, addr)
+					ap.Config.State.ActiveMiningWeights =
+						append(ap.Config.State.ActiveMiningWeights, weight)
 				} else {
 					*ap.Config.MiningAddrs = []string{}
 				}
@@ -729,18 +802,47 @@ func validateMiner(ap *def.App) int {
 		}
 	}
 	// Ensure there is at least one mining address when the generate flag
-	// is set.
+	// is set or miner workers can connect.
 	if (*ap.Config.Generate ||
-		ap.Config.MinerListener != nil) &&
-		ap.Config.MiningAddrs != nil {
-		str := "%s: the generate flag is set, but there are no mining addresses specified "
+		len(*ap.Config.MinerListener) > 0) &&
+		len(ap.Config.State.ActiveMiningAddrs) == 0 {
+		str := "%s: the generate flag or a miner listener is set, but there are no mining addresses specified "
 		err := fmt.Errorf(str, "runNode")
 		fmt.Fprintln(os.Stderr, err)
 		return 1
 	}
+	if err := mining.CheckRotation(*ap.Config.MiningRotation); err != nil {
+		fmt.Fprintln(os.Stderr, "runNode: mining.rotation:", err)
+		return 1
+	}
+	if err := mining.CheckCoinbaseTag(*ap.Config.CoinbaseTag); err != nil {
+		fmt.Fprintln(os.Stderr, "runNode: mining.coinbasetag:", err)
+		return 1
+	}
+	ap.Config.State.ActiveMinerCores = nil
+	for _, core := range *ap.Config.MinerCores {
+		c, err := strconv.Atoi(core)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "runNode: mining.cores: '%s' is not a core number\n", core)
+			return 1
+		}
+		ap.Config.State.ActiveMinerCores =
+			append(ap.Config.State.ActiveMinerCores, c)
+	}
+	if err := cpuminer.CheckThreadPolicy(ap.Config.State.ActiveMinerCores,
+		*ap.Config.MinerNice); err != nil {
+		fmt.Fprintln(os.Stderr, "runNode: mining:", err)
+		return 1
+	}
 	if *ap.Config.MinerPass != "" {
 		ap.Config.State.ActiveMinerKey = fork.Argon2i([]byte(*ap.Config.MinerPass))
 	}
+	for _, key := range *ap.Config.MinerAPIKeys {
+		if err := controller.CheckAPIKey(key); err != nil {
+			fmt.Fprintln(os.Stderr, "runNode: mining.apikeys:", err)
+			return 1
+		}
+	}
 	return 0
 }
 func validateCheckpoints(ap *def.App) int {
@@ -757,6 +859,28 @@ func validateCheckpoints(ap *def.App) int {
 			return 1
 		}
 	}
+	ap.Config.State.AssumeValid = nil
+	if ap.Config.AssumeValid != nil && *ap.Config.AssumeValid != "" {
+		ap.Config.State.AssumeValid, err =
+			chainhash.NewHashFromStr(*ap.Config.AssumeValid)
+		if err != nil {
+			str := "%s: Error parsing assumevalid block hash: %v"
+			err := fmt.Errorf(str, "runNode", err)
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+	ap.Config.State.AssumeUtxo = nil
+	if ap.Config.AssumeUtxo != nil && *ap.Config.AssumeUtxo != "" {
+		ap.Config.State.AssumeUtxo, err =
+			chainhash.NewHashFromStr(*ap.Config.AssumeUtxo)
+		if err != nil {
+			str := "%s: Error parsing assumeutxo snapshot hash: %v"
+			err := fmt.Errorf(str, "runNode", err)
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
 	return 0
 }
 func validateDialers(ap *def.App) int {
@@ -0,0 +1,123 @@
+package app
+import (
+	"fmt"
+	"math"
+	"runtime"
+	"sync/atomic"
+	"time"
+	"git.parallelcoin.io/dev/9/cmd/ctl"
+	"git.parallelcoin.io/dev/9/cmd/def"
+	"git.parallelcoin.io/dev/9/pkg/util/cl"
+	"git.parallelcoin.io/dev/9/pkg/util/interrupt"
+	"git.parallelcoin.io/dev/9/pkg/util/prompt"
+	"git.parallelcoin.io/dev/9/pkg/util/vanity"
+	waddrmgr "git.parallelcoin.io/dev/9/pkg/wallet/addrmgr"
+)
+// VanityUnlockSeconds is how long the wallet is unlocked for to import a vanity key
+const VanityUnlockSeconds = 60
+// Vanity grinds keys on every core until one has an address starting with the
+// prefix following the vanity keyword, printing progress each second, and
+// imports the key into the wallet server when <wallet> is given
+func Vanity(args []string, tokens def.Tokens, ap *def.App) int {
+	cl.Register.SetAllLevels(*ap.Config.LogLevel)
+	setAppDataDir(ap, "ctl")
+	var prefix string
+	for i, x := range args {
+		if ap.Commands["vanity"].RE.Match([]byte(x)) && i+1 < len(args) {
+			prefix = args[i+1]
+			break
+		}
+	}
+	params := ap.Config.ActiveNetParams.Params
+	prefix = vanity.Normalize(prefix, params)
+	attempts, err := vanity.Attempts(prefix, params)
+	if err != nil {
+		fmt.Printf("unable to search for %q on %s: %v\n", prefix, params.Name, err)
+		return 1
+	}
+	// the wallet watches imported keys by their pay to public key hash
+	// address only, so a segwit address would never show its coins
+	_, importKey := tokens["wallet"]
+	if importKey && vanity.SegWit(prefix, params) {
+		fmt.Println("the wallet can only import keys for base58 addresses, search without <wallet> to import it elsewhere")
+		return 1
+	}
+	threads := runtime.NumCPU()
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(threads))
+	fmt.Printf("searching for an address starting with %s on %d threads, %.0f keys expected\n",
+		prefix, threads, attempts)
+	quit := make(chan struct{})
+	interrupt.AddHandler(func() {
+		close(quit)
+	})
+	var tried uint64
+	done := make(chan struct{})
+	go func() {
+		start := time.Now()
+		tick := time.NewTicker(time.Second)
+		defer tick.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-tick.C:
+				printVanityProgress(atomic.LoadUint64(&tried), attempts, time.Since(start))
+			}
+		}
+	}()
+	m, err := vanity.Search(prefix, params, threads, quit, &tried)
+	close(done)
+	fmt.Println()
+	switch {
+	case err != nil:
+		fmt.Println("search failed:", err)
+		return 1
+	case m == nil:
+		fmt.Println("search stopped after", atomic.LoadUint64(&tried), "keys")
+		return 1
+	}
+	fmt.Println("address:", m.Address.EncodeAddress())
+	fmt.Println("private key:", m.WIF.String())
+	if !importKey {
+		fmt.Println("keep the private key secret, anyone who has it can spend from the address")
+		return 0
+	}
+	return importVanityKey(ap, m)
+}
+// printVanityProgress prints the keys tried so far and their rate over the previous line. Each key is as likely as the last to match however many were tried, so the expected wait stays the expected attempts at the current rate, while the chance of having found one by now grows.
+func printVanityProgress(tried uint64, attempts float64, elapsed time.Duration) {
+	rate := float64(tried) / elapsed.Seconds()
+	if rate == 0 {
+		return
+	}
+	chance := 100 * (1 - math.Exp(-float64(tried)/attempts))
+	eta := time.Duration(attempts / rate * float64(time.Second)).Round(time.Second)
+	fmt.Printf("\r%d keys, %.0f keys/s, %.1f%% chance of a match by now, %v expected for one\x1b[K",
+		tried, rate, chance, eta)
+}
+// importVanityKey imports the key into the imported account of the wallet server, unlocking the wallet with the passphrase it prompts for. The key is new so the chain is not rescanned.
+func importVanityKey(ap *def.App, m *vanity.Match) int {
+	wallet := *ap.Config
+	on := true
+	wallet.Wallet = &on
+	pass, err := prompt.ProvidePrivPassphrase()
+	if err != nil {
+		fmt.Println("unable to read the passphrase:", err)
+		return 1
+	}
+	if err = ctl.Call(&wallet, "walletpassphrase", nil, string(pass), VanityUnlockSeconds); err != nil {
+		fmt.Println("unable to unlock the wallet:", err)
+		return 1
+	}
+	defer func() {
+		if err := ctl.Call(&wallet, "walletlock", nil); err != nil {
+			fmt.Println("unable to lock the wallet again:", err)
+		}
+	}()
+	if err = ctl.Call(&wallet, "importprivkey", nil, m.WIF.String(), waddrmgr.ImportedAddrAccountName, false); err != nil {
+		fmt.Println("unable to import the key:", err)
+		return 1
+	}
+	fmt.Println("imported into the", waddrmgr.ImportedAddrAccountName, "account of the wallet")
+	return 0
+}
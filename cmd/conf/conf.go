@@ -2,17 +2,18 @@ package conf
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
+	"io/ioutil"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"git.parallelcoin.io/dev/9/cmd/def"
 	"git.parallelcoin.io/dev/9/pkg/util"
-	"git.parallelcoin.io/dev/tcell"
-	"git.parallelcoin.io/dev/tview"
+	"git.parallelcoin.io/dev/9/pkg/util/tcell"
+	"git.parallelcoin.io/dev/9/pkg/util/tview"
 )
 
 const menutitle = "ⓟ parallelcoin 9 configuration CLI"
@@ -27,19 +28,33 @@ func Run(args []string, tokens def.Tokens, ap *def.App) int {
 	var activepage *tview.Flex
 	var inputhandler func(event *tcell.EventKey) *tcell.EventKey
 	var cat, itemname string
-	// tapp pulls everything together to create the configuration interface
-	tapp := tview.NewApplication()
-	// titlebar tells the user what app they are using
-	titlebar := tview.NewTextView().
-		SetTextColor(col.Text()).
-		SetText(menutitle)
-	titlebar.Box.SetBackgroundColor(col.Main())
+	// the theme is picked by app.theme
+	if theme, ok := ap.Cats["app"]["theme"].Get().(string); ok {
+		setTheme(theme)
+	}
+	// tapp pulls everything together to create the configuration interface,
+	// with the mouse enabled so the menus can be clicked and scrolled
+	tapp := tview.NewApplication().EnableMouse(true)
+	// titlebar tells the user what app they are using, and shows messages
+	// about what was just done
+	titlebar := tview.NewStatusBar().
+		SetTextColor(TextColor()).
+		SetLeft(menutitle).
+		SetApplication(tapp)
+	titlebar.Box.SetBackgroundColor(MainColor())
+	// spinner shows the configuration is being saved
+	spinner := tview.NewSpinner().
+		SetColors(TextColor(), TextColor())
+	spinner.Box.SetBackgroundColor(MainColor())
+	titleflex := tview.NewFlex().
+		AddItem(titlebar, 0, 1, false).
+		AddItem(spinner, 10, 0, false)
 	coverbox := tview.NewTextView()
 	coverbox.
-		SetTextColor(col.Text())
+		SetTextColor(TextColor())
 	coverbox.Box.
 		SetBorder(false).
-		SetBackgroundColor(col.Background())
+		SetBackgroundColor(BackgroundColor())
 	coverbox.SetBorderPadding(1, 1, 2, 2)
 	// coverbox.SetBorder(true)
 	roottable, roottablewidth := genMenu("launch", "configure", "reinitialize")
@@ -267,25 +282,44 @@ func Run(args []string, tokens def.Tokens, ap *def.App) int {
 		}
 		return event
 	})
+	// saveConfig writes the configuration in the background while the
+	// spinner runs. Each write takes the latest configuration, so the file
+	// ends up with the last one whatever order the writes run in. Saves
+	// still under way when the app is closed are waited for.
+	var saving sync.Mutex
+	var saves sync.WaitGroup
+	var latest []byte
 	saveConfig := func() {
 		ddir, ok := ap.Cats["app"]["datadir"].Get().(string)
 		if ok {
-			configFile := util.CleanAndExpandPath(filepath.Join(
-				ddir, "config"), "")
-			if util.EnsureDir(configFile) {
-			}
-			fh, err := os.Create(configFile)
-			if err != nil {
-				panic(err)
-			}
+			configFile := util.CleanAndExpandPath(
+				util.ConfigFile("9", ddir), "")
 			j, e := json.MarshalIndent(ap, "", "\t")
 			if e != nil {
 				panic(e)
 			}
-			_, err = fmt.Fprint(fh, string(j))
-			if err != nil {
-				panic(err)
-			}
+			saving.Lock()
+			latest = j
+			saving.Unlock()
+			spinner.SetLabel("saving").Start(tapp)
+			saves.Add(1)
+			go func() {
+				saving.Lock()
+				util.EnsureDir(configFile)
+				err := ioutil.WriteFile(configFile, latest, 0600)
+				saving.Unlock()
+				saves.Done()
+				tapp.QueueUpdateDraw(func() {
+					spinner.Stop()
+					if err != nil {
+						titlebar.ShowMessage(tview.Escape("could not save "+
+							configFile+": "+err.Error()), TextColor(), 0)
+						return
+					}
+					titlebar.ShowMessage(tview.Escape("saved "+configFile),
+						TextColor(), 2*time.Second)
+				})
+			}()
 		}
 	}
 	var genPage func(cat, item string, active bool, ap *def.App,
@@ -362,6 +396,7 @@ func Run(args []string, tokens def.Tokens, ap *def.App) int {
 			infostring =
 				"<ctrl-u>  to clear\n" +
 					"<ctrl-z>  to reset to default\n" +
+					"<ctrl-y>  to copy\n" +
 					infostring
 		default:
 		}
@@ -423,6 +458,9 @@ func Run(args []string, tokens def.Tokens, ap *def.App) int {
 						}
 					case event.Key() == tcell.KeyCtrlZ:
 						rw.Value.Put(rw.Default.Get())
+					case event.Key() == tcell.KeyCtrlY:
+						copyText(titlebar, cat+"."+item, iteminput.GetText())
+						return nil
 					default:
 						return editoreventhandler(event)
 					}
@@ -441,6 +479,17 @@ func Run(args []string, tokens def.Tokens, ap *def.App) int {
 				}
 			}
 			iteminput.SetInputCapture(canceller(currow))
+			// check input as it is typed; a nil row makes the row's validator
+			// test the value without storing it. An empty field resets the
+			// value and is always accepted.
+			if rw := currow; rw.Validate != nil {
+				iteminput.AddValidator(tview.ValidateOptional(func(text string) error {
+					if !rw.Validate(nil, &text) {
+						return errors.New("not valid for this field")
+					}
+					return nil
+				}))
+			}
 			snackbar := tview.NewTextView()
 			iteminput.SetDoneFunc(func(key tcell.Key) {
 				rrr := currow
@@ -462,8 +511,8 @@ func Run(args []string, tokens def.Tokens, ap *def.App) int {
 					} else {
 						isvalid := rw.Validate(rw, &s)
 						if !isvalid {
-							snackbar.SetBackgroundColor(tcell.ColorOrange)
-							snackbar.SetTextColor(tcell.ColorRed)
+							snackbar.SetBackgroundColor(col.Warning)
+							snackbar.SetTextColor(col.WarningText)
 							snackbar.SetText("input is not valid for this field")
 							out.RemoveItem(infoblock).RemoveItem(snackbar)
 							out.AddItem(snackbar, 1, 1, false)
@@ -663,8 +712,8 @@ func Run(args []string, tokens def.Tokens, ap *def.App) int {
 								// }
 								// rw.Value.Put(rwv)
 							} else {
-								snackbar.SetBackgroundColor(tcell.ColorOrange)
-								snackbar.SetTextColor(tcell.ColorRed)
+								snackbar.SetBackgroundColor(col.Warning)
+								snackbar.SetTextColor(col.WarningText)
 								snackbar.SetText("input is not valid for this field")
 								out.RemoveItem(infoblock).RemoveItem(snackbar)
 								out.AddItem(snackbar, 1, 1, false)
@@ -917,6 +966,21 @@ func Run(args []string, tokens def.Tokens, ap *def.App) int {
 				activepage = genPage(cat, itemname, true, ap, inputhandler, 0)
 				menuflex.AddItem(activepage, 0, 1, true)
 				tapp.SetFocus(activepage)
+			case tcell.KeyRune:
+				// y copies the value of the selected item
+				y, _ := cattable.GetSelection()
+				if event.Rune() != 'y' || y == 0 {
+					break
+				}
+				var catkeys []string
+				for _, x := range ap.Cats[cat].GetSortedKeys() {
+					if !(cat == "app" && x == "datadir") {
+						catkeys = append(catkeys, x)
+					}
+				}
+				copyText(titlebar, cat+"."+catkeys[y-1],
+					valueText(ap.Cats[cat][catkeys[y-1]]))
+				return nil
 			case tcell.KeyEsc, tcell.KeyLeft:
 				// pressed escape
 				menuflex.
@@ -995,10 +1059,11 @@ func Run(args []string, tokens def.Tokens, ap *def.App) int {
 	// root is the canvas (the whole current terminal view)
 	root := tview.NewFlex().
 		SetDirection(tview.FlexRow).
-		AddItem(titlebar, 1, 0, false).
+		AddItem(titleflex, 1, 0, false).
 		AddItem(menuflex, 0, 1, true)
 	if e := tapp.SetRoot(root, true).Run(); e != nil {
 		panic(e)
 	}
+	saves.Wait()
 	return 0
 }
@@ -0,0 +1,37 @@
+package conf
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"git.parallelcoin.io/dev/9/cmd/def"
+	"git.parallelcoin.io/dev/9/pkg/util/clipboard"
+	"git.parallelcoin.io/dev/9/pkg/util/tview"
+)
+
+// copyText puts text on the clipboard and says in the title bar for a few
+// seconds whether it worked
+func copyText(titlebar *tview.StatusBar, name, text string) {
+	msg := "copied " + name
+	if err := clipboard.Copy(text); err != nil {
+		msg = "could not copy " + name + ": " + err.Error()
+	}
+	titlebar.ShowMessage(tview.Escape(msg), TextColor(), 3*time.Second)
+}
+
+// valueText formats the value of a configuration item the way it is typed
+// on the command line
+func valueText(rw *def.Row) string {
+	if rw == nil {
+		return ""
+	}
+	switch v := rw.Value.Get().(type) {
+	case nil:
+		return ""
+	case []string:
+		return strings.Join(v, " ")
+	default:
+		return fmt.Sprint(v)
+	}
+}
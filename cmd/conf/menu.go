@@ -1,7 +1,7 @@
 package conf
 import (
 	"strings"
-	"git.parallelcoin.io/dev/tview"
+	"git.parallelcoin.io/dev/9/pkg/util/tview"
 )
 func getMaxWidth(ss []string) (maxwidth int) {
 	for _, x := range ss {
@@ -1,46 +1,117 @@
 package conf
 
 import (
+	"sort"
+
 	"git.parallelcoin.io/dev/9/pkg/util/tcell"
 	"git.parallelcoin.io/dev/9/pkg/util/tview"
 )
 
-type colors struct {
-	Main       func() tcell.Color
-	Dim        func() tcell.Color
-	Prelight   func() tcell.Color
-	Text       func() tcell.Color
-	Background func() tcell.Color
-}
-
-var col = colors{
+// Theme is the set of colors the configuration interface is drawn with
+type Theme struct {
 	// Main is the main background color for menu panels
-	Main: func() tcell.Color {
-		return tcell.NewRGBColor(64, 64, 64)
-	},
-
+	Main tcell.Color
 	// Dim is the colour of the most recently selected before current item
-	Dim: func() tcell.Color {
-		return tcell.NewRGBColor(48, 48, 48)
-	},
-
+	Dim tcell.Color
 	// Prelight is the background colour of the next item ahead that is rendered
 	// when each item that opens it is moved onto with the cursor
-	Prelight: func() tcell.Color {
-		return tcell.NewRGBColor(32, 32, 32)
-	},
+	Prelight tcell.Color
+	// Text is the color of normal text with Main as background
+	Text tcell.Color
+	// Background is the colour of all parts not containing any widgets
+	Background tcell.Color
+	// Warning is the background colour of the bar showing invalid input, and
+	// WarningText the colour of its text
+	Warning     tcell.Color
+	WarningText tcell.Color
+}
 
-	// Text is the color of normal text with MainColor as background
-	Text: func() tcell.Color {
-		return tcell.NewRGBColor(216, 216, 216)
-	},
+// DefaultTheme is the name of the theme used when app.theme is not set or
+// names no theme
+const DefaultTheme = "default"
 
-	// BackgroundColor is the colour of all parts not containing any widgets
-	Background: func() tcell.Color {
-		return tcell.NewRGBColor(16, 16, 16)
+// Themes are the themes that can be picked with app.theme. The high contrast
+// theme is for readability, and the monochrome theme only uses black, gray and
+// white, so it also works on terminals with few colours.
+var Themes = map[string]Theme{
+	DefaultTheme: {
+		Main:        tcell.NewRGBColor(64, 64, 64),
+		Dim:         tcell.NewRGBColor(48, 48, 48),
+		Prelight:    tcell.NewRGBColor(32, 32, 32),
+		Text:        tcell.NewRGBColor(216, 216, 216),
+		Background:  tcell.NewRGBColor(16, 16, 16),
+		Warning:     tcell.ColorOrange,
+		WarningText: tcell.ColorRed,
+	},
+	"highcontrast": {
+		Main:        tcell.ColorBlack,
+		Dim:         tcell.ColorYellow,
+		Prelight:    tcell.ColorBlue,
+		Text:        tcell.ColorWhite,
+		Background:  tcell.ColorBlack,
+		Warning:     tcell.ColorRed,
+		WarningText: tcell.ColorWhite,
+	},
+	"monochrome": {
+		Main:        tcell.ColorSilver,
+		Dim:         tcell.ColorBlack,
+		Prelight:    tcell.ColorGray,
+		Text:        tcell.ColorBlack,
+		Background:  tcell.ColorSilver,
+		Warning:     tcell.ColorBlack,
+		WarningText: tcell.ColorSilver,
 	},
 }
 
+// ThemeNames returns the names of the themes in Themes, sorted
+func ThemeNames() (names []string) {
+	for name := range Themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return
+}
+
+// col is the theme the interface is currently drawn with
+var col = Themes[DefaultTheme]
+
+// setTheme makes the named theme the current one, or the default theme if
+// there is none by that name
+func setTheme(name string) {
+	theme, ok := Themes[name]
+	if !ok {
+		theme = Themes[DefaultTheme]
+	}
+	col = theme
+}
+
+// MainColor is the main background color of the current theme
+func MainColor() tcell.Color {
+	return col.Main
+}
+
+// DimColor is the color of the most recently selected item of the current
+// theme
+func DimColor() tcell.Color {
+	return col.Dim
+}
+
+// PrelightColor is the color of the next item ahead of the current theme
+func PrelightColor() tcell.Color {
+	return col.Prelight
+}
+
+// TextColor is the color of normal text of the current theme
+func TextColor() tcell.Color {
+	return col.Text
+}
+
+// BackgroundColor is the color of the parts not containing any widgets of the
+// current theme
+func BackgroundColor() tcell.Color {
+	return col.Background
+}
+
 // This sets a menu to active attributes
 func activateTable(table *tview.Table) {
 	if table == nil {
@@ -2,7 +2,7 @@ package conf
 
 import (
 	"git.parallelcoin.io/dev/9/pkg/util/tview"
-	"git.parallelcoin.io/dev/tcell"
+	"git.parallelcoin.io/dev/9/pkg/util/tcell"
 )
 
 // Menu is the tview table and misc info for each menu panel
@@ -8,6 +8,7 @@ import (
 	"io/ioutil"
 	"net"
 	"net/http"
+	"time"
 	"git.parallelcoin.io/dev/9/cmd/nine"
 	"git.parallelcoin.io/dev/9/pkg/rpc/json"
 	"github.com/btcsuite/go-socks/socks"
@@ -53,8 +54,8 @@ func newHTTPClient(cfg *nine.Config) (*http.Client, error) {
 	}
 	return &client, nil
 }
-// sendPostRequest sends the marshalled JSON-RPC command using HTTP-POST mode to the server described in the passed config struct.  It also attempts to unmarshal the response as a JSON-RPC response and returns either the result field or the error field depending on whether or not there is an error.
-func sendPostRequest(marshalledJSON []byte, cfg *nine.Config) ([]byte, error) {
+// PostRequest sends the marshalled JSON-RPC request using HTTP-POST mode to the server described in the passed config struct and returns the body of the response without interpreting it. A timeout of zero waits for the response for as long as it takes.
+func PostRequest(marshalledJSON []byte, cfg *nine.Config, timeout time.Duration) ([]byte, error) {
 	// Generate a request to the configured RPC server.
 	protocol := "http"
 	if !*cfg.NoTLS {
@@ -79,6 +80,7 @@ func sendPostRequest(marshalledJSON []byte, cfg *nine.Config) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
+	httpClient.Timeout = timeout
 	httpResponse, err := httpClient.Do(httpRequest)
 	if err != nil {
 		return nil, err
@@ -99,6 +101,14 @@ func sendPostRequest(marshalledJSON []byte, cfg *nine.Config) ([]byte, error) {
 		}
 		return nil, fmt.Errorf("%s", respBytes)
 	}
+	return respBytes, nil
+}
+// sendPostRequest sends the marshalled JSON-RPC command using HTTP-POST mode to the server described in the passed config struct.  It also attempts to unmarshal the response as a JSON-RPC response and returns either the result field or the error field depending on whether or not there is an error.
+func sendPostRequest(marshalledJSON []byte, cfg *nine.Config) ([]byte, error) {
+	respBytes, err := PostRequest(marshalledJSON, cfg, 0)
+	if err != nil {
+		return nil, err
+	}
 	// Unmarshal the response.
 	var resp json.Response
 	if err := js.Unmarshal(respBytes, &resp); err != nil {
@@ -109,3 +119,19 @@ func sendPostRequest(marshalledJSON []byte, cfg *nine.Config) ([]byte, error) {
 	}
 	return resp.Result, nil
 }
+// Call sends an RPC request for method with the given parameters to the server the configuration points at and decodes the result into result, which may be nil to discard it.
+func Call(cfg *nine.Config, method string, result interface{}, params ...interface{}) error {
+	cmd, err := json.NewCmd(method, params...)
+	if err != nil {
+		return err
+	}
+	marshalledJSON, err := json.MarshalCmd(1, cmd)
+	if err != nil {
+		return err
+	}
+	res, err := sendPostRequest(marshalledJSON, cfg)
+	if err != nil || result == nil {
+		return err
+	}
+	return js.Unmarshal(res, result)
+}
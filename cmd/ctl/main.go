@@ -6,17 +6,28 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strconv"
 	"strings"
 	"git.parallelcoin.io/dev/9/cmd/nine"
 	"git.parallelcoin.io/dev/9/pkg/rpc/json"
+	"git.parallelcoin.io/dev/9/pkg/util"
+	"git.parallelcoin.io/dev/9/pkg/util/qr"
 )
+// amountMethods are the commands whose result is an amount of DUO, which is shown in the format of app.locale and app.unit
+var amountMethods = map[string]bool{
+	"getbalance":            true,
+	"getreceivedbyaccount":  true,
+	"getreceivedbyaddress":  true,
+	"getunconfirmedbalance": true,
+}
 var HelpPrint = func() {
 	fmt.Println("help has not been overridden")
 }
-// Main is the entry point for the pod.Ctl component
+// Main is the entry point for the pod.Ctl component, printing text results such as new addresses as QR codes as well if showQR is set
 func Main(
 	args []string,
 	cfg *nine.Config,
+	showQR bool,
 ) {
 	// Ensure the specified method identifies a valid registered command and is one of the usable types.
 	method := "help"
@@ -118,11 +129,46 @@ func Main(
 			fmt.Fprintf(os.Stderr, "Failed to unmarshal result: %v", err)
 			os.Exit(1)
 		}
+		if showQR {
+			printQR(str)
+		}
 		fmt.Println(str)
 	case strResult != "null":
+		if f := AmountFormat(cfg); amountMethods[method] && f != util.PlainFormat {
+			if v, err := strconv.ParseFloat(strResult, 64); err == nil {
+				if a, err := util.NewAmount(v); err == nil {
+					fmt.Println(a.FormatWith(f), f.Unit)
+					return
+				}
+			}
+		}
 		fmt.Println(strResult)
 	}
 }
+// AmountFormat is the format amounts are shown to people in, from app.locale and app.unit. With neither set it is util.PlainFormat, the way the RPC servers write them, so scripts reading the output are not affected.
+func AmountFormat(cfg *nine.Config) util.AmountFormat {
+	locale, unit := "", util.AmountDUO
+	if cfg.Locale != nil {
+		locale = *cfg.Locale
+	}
+	if cfg.Unit != nil {
+		if u, err := util.ParseAmountUnit(*cfg.Unit); err == nil {
+			unit = u
+		}
+	}
+	return util.LocaleFormat(locale, unit)
+}
+// printQR prints text as a QR code in black on white, whatever the colours of the terminal are, so it can be scanned
+func printQR(text string) {
+	code, err := qr.Encode(text)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to make QR code:", err)
+		return
+	}
+	for _, line := range code.HalfBlocks(2) {
+		fmt.Println("\x1b[30;47m" + line + "\x1b[0m")
+	}
+}
 // commandUsage display the usage for a specific command.
 func commandUsage(
 	method string,
@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"path/filepath"
 	"time"
 
 	"git.parallelcoin.io/dev/9/cmd/nine"
@@ -39,7 +38,7 @@ func (r *App) SaveConfig() {
 	if !ok {
 		return
 	}
-	configFile := util.CleanAndExpandPath(filepath.Join(datadir, "config"), "")
+	configFile := util.CleanAndExpandPath(util.ConfigFile("9", datadir), "")
 	// if util.EnsureDir(configFile) {
 	// }
 	fh, err := os.Create(configFile)
@@ -63,15 +62,7 @@ func (r *App) MarshalJSON() ([]byte, error) {
 	for i, x := range r.Cats {
 		out[i] = make(CatJSON)
 		for j, y := range x {
-			min, _ := y.Min.Get().(int)
-			max, _ := y.Max.Get().(int)
-			out[i][j] = Line{
-				Value:   y.Value.Get(),
-				Default: y.Default.Get(),
-				Min:     min,
-				Max:     max,
-				Usage:   y.Usage,
-			}
+			out[i][j] = y.line()
 		}
 	}
 	return json.Marshal(out)
@@ -22,6 +22,8 @@ type Row struct {
 	String   string
 	Usage    string
 	App      *App
+	// Secret items such as passwords are not shown by editors
+	Secret bool
 }
 
 // RowGenerator configures a Row
@@ -0,0 +1,75 @@
+package def
+
+import (
+	"fmt"
+	"time"
+)
+
+// Field describes a configuration item to an editor: the line the config file
+// holds with the item's type and choices. Secret items have their value left
+// and default left out, Set telling whether there is a value.
+type Field struct {
+	Line
+	Type   string   `json:"type"`
+	Opts   []string `json:"opts,omitempty"`
+	Secret bool     `json:"secret,omitempty"`
+	Set    bool     `json:"set,omitempty"`
+	// Text and DefaultText are the value and default as they are typed in
+	Text        string `json:"text"`
+	DefaultText string `json:"defaulttext"`
+}
+
+// Schema describes every configuration item by group and name
+type Schema map[string]map[string]Field
+
+// line is the config file line of a Row
+func (r *Row) line() Line {
+	min, _ := r.Min.Get().(int)
+	max, _ := r.Max.Get().(int)
+	return Line{
+		Value:   r.Value.Get(),
+		Default: r.Default.Get(),
+		Min:     min,
+		Max:     max,
+		Usage:   r.Usage,
+	}
+}
+
+// Schema exports the configuration for editors other than the conf TUI
+func (r *App) Schema() Schema {
+	out := make(Schema)
+	for i, x := range r.Cats {
+		out[i] = make(map[string]Field)
+		for j, y := range x {
+			f := Field{
+				Line:        y.line(),
+				Type:        y.Type,
+				Opts:        y.Opts,
+				Secret:      y.Secret,
+				Text:        text(y.Value.Get()),
+				DefaultText: text(y.Default.Get()),
+			}
+			if y.Secret {
+				f.Set = f.Value != nil && f.Text != ""
+				f.Value, f.Text = nil, ""
+				f.Default, f.DefaultText = nil, ""
+			}
+			out[i][j] = f
+		}
+	}
+	return out
+}
+
+// text formats a value the way the validators read it back
+func text(v interface{}) string {
+	switch V := v.(type) {
+	case nil:
+		return ""
+	case []string:
+		return fmt.Sprint(len(V), " items")
+	case time.Duration:
+		return V.String()
+	default:
+		return fmt.Sprint(V)
+	}
+}
@@ -0,0 +1,190 @@
+package gui
+import (
+	"encoding/base64"
+	"sync"
+	"git.parallelcoin.io/dev/9/cmd/ctl"
+	"git.parallelcoin.io/dev/9/cmd/nine"
+	rpc "git.parallelcoin.io/dev/9/pkg/rpc/json"
+	"git.parallelcoin.io/dev/9/pkg/util"
+	"git.parallelcoin.io/dev/9/pkg/util/cl"
+	"git.parallelcoin.io/dev/9/pkg/util/qr"
+)
+// UnlockSeconds is how long the wallet is unlocked for to sign a transaction, it is locked again as soon as the transaction is sent
+const UnlockSeconds = 30
+// backend makes the RPC calls for the page. It is the only part holding the RPC credentials, the browser only ever sees the results.
+type backend struct {
+	node *nine.Config
+	// wallet is a copy of the configuration with wallet set, so calls go to the wallet server
+	wallet *nine.Config
+	// sending keeps the unlock, send and lock of one payment from overlapping another
+	sending sync.Mutex
+	// format is how the page writes and reads amounts, from app.locale and app.unit
+	format util.AmountFormat
+}
+// amountFormat tells the page how to write amounts, which come from the RPC servers as DUO: counted in satoshi they have the decimal point put Decimals digits from the end, and the whole part grouped in threes by Group
+type amountFormat struct {
+	Unit     string `json:"unit"`
+	Group    string `json:"group"`
+	Point    string `json:"point"`
+	Decimals int    `json:"decimals"`
+}
+// status is the node's state shown on the node page, with any newer release the node found. In light mode there is no node to ask and only Sync is set.
+type status struct {
+	Light   bool                         `json:"light"`
+	Sync    *rpc.GetSyncStatusResult     `json:"sync,omitempty"`
+	Chain   *rpc.GetBlockChainInfoResult `json:"chain,omitempty"`
+	Peers   int                          `json:"peers"`
+	Mempool *rpc.GetMempoolInfoResult    `json:"mempool,omitempty"`
+	Update  *rpc.GetUpdateInfoResult     `json:"update,omitempty"`
+}
+// overview is the state of the wallet shown on the overview and history pages
+type overview struct {
+	Balance      float64                      `json:"balance"`
+	Unconfirmed  float64                      `json:"unconfirmed"`
+	Height       int32                        `json:"height"`
+	Transactions []rpc.ListTransactionsResult `json:"transactions"`
+}
+// address is a receiving address with the payment URI asking for a payment to it, and the QR code of the URI as an SVG image and a PNG data URI to save
+type address struct {
+	Address string `json:"address"`
+	URI     string `json:"uri"`
+	QR      string `json:"qr"`
+	PNG     string `json:"png"`
+}
+// payment is a send request from the page
+type payment struct {
+	Address    string `json:"address"`
+	Amount     string `json:"amount"`
+	Passphrase string `json:"passphrase"`
+}
+func newBackend(cfg *nine.Config) *backend {
+	w := *cfg
+	on := true
+	w.Wallet = &on
+	return &backend{node: cfg, wallet: &w, format: ctl.AmountFormat(cfg)}
+}
+// amountFormat is the format for the page
+func (b *backend) amountFormat() amountFormat {
+	d := int(b.format.Unit) + 8
+	if d < 0 {
+		d = 0
+	}
+	return amountFormat{b.format.Unit.String(), b.format.Group, b.format.Point, d}
+}
+// parseAmount reads an amount typed in the page, which is in the unit and written for the locale the page shows amounts in
+func (b *backend) parseAmount(s string) (util.Amount, error) {
+	a, err := util.ParseAmount(s, b.format)
+	if err != nil {
+		return 0, requestError(err.Error())
+	}
+	return a, nil
+}
+// status asks the wallet how it syncs, and the node for its state unless the wallet is in light mode. Only a failure to get the chain state is an error, the sync state and mempool are left out if their calls fail.
+func (b *backend) status() (st status, err error) {
+	if err := ctl.Call(b.wallet, "getsyncstatus", &st.Sync); err != nil {
+		if b.light() {
+			return st, err
+		}
+		log <- cl.Debug{"getsyncstatus failed:", err}
+		st.Sync = nil
+	}
+	if st.Light = b.light() || st.Sync != nil && st.Sync.Backend == "neutrino"; st.Light {
+		return
+	}
+	if err = ctl.Call(b.node, "getblockchaininfo", &st.Chain); err != nil {
+		return
+	}
+	var peers []rpc.GetPeerInfoResult
+	if err := ctl.Call(b.node, "getpeerinfo", &peers); err != nil {
+		log <- cl.Debug{"getpeerinfo failed:", err}
+	}
+	st.Peers = len(peers)
+	if err := ctl.Call(b.node, "getmempoolinfo", &st.Mempool); err != nil {
+		log <- cl.Debug{"getmempoolinfo failed:", err}
+		st.Mempool = nil
+	}
+	if err := ctl.Call(b.node, "getupdateinfo", &st.Update); err != nil {
+		log <- cl.Debug{"getupdateinfo failed:", err}
+		st.Update = nil
+	}
+	return
+}
+// light is true if the wallet is configured to sync in light mode, so there is no node
+func (b *backend) light() bool {
+	return b.node.WalletSPV != nil && *b.node.WalletSPV
+}
+// overview queries the wallet server, stopping at the first failure
+func (b *backend) overview() (o overview, err error) {
+	if err = ctl.Call(b.wallet, "getbalance", &o.Balance); err != nil {
+		return
+	}
+	if err = ctl.Call(b.wallet, "getunconfirmedbalance", &o.Unconfirmed); err != nil {
+		return
+	}
+	if err := ctl.Call(b.wallet, "getblockcount", &o.Height); err != nil {
+		log <- cl.Debug{"wallet getblockcount failed:", err}
+	}
+	err = ctl.Call(b.wallet, "listtransactions", &o.Transactions, "*", 100)
+	return
+}
+// newAddress makes a new address and encodes it as a QR code
+func (b *backend) newAddress() (a address, err error) {
+	var addr string
+	if err = ctl.Call(b.wallet, "getnewaddress", &addr); err != nil {
+		return
+	}
+	return paymentRequest(addr, 0, "", "")
+}
+// paymentRequest encodes a request for a payment to an address as a payment URI and its QR code, the amount, label and message being left out where not given
+func paymentRequest(addr string, amount float64, label, message string) (a address, err error) {
+	switch {
+	case !addressRE.MatchString(addr):
+		return a, requestError("not an address: " + addr)
+	case amount < 0:
+		return a, requestError("amount can't be less than zero")
+	}
+	a.Address = addr
+	a.URI = qr.PaymentURI(addr, amount, label, message)
+	code, err := qr.Encode(a.URI)
+	if err != nil {
+		return a, requestError("label and message are too long to fit in a QR code")
+	}
+	a.QR = code.SVG(4)
+	img, err := code.PNG(8, 4)
+	if err != nil {
+		return
+	}
+	a.PNG = "data:image/png;base64," + base64.StdEncoding.EncodeToString(img)
+	return
+}
+// send unlocks the wallet, pays the amount to the address and locks the wallet again
+func (b *backend) send(p payment) (txid string, err error) {
+	var amount util.Amount
+	if p.Amount != "" {
+		if amount, err = b.parseAmount(p.Amount); err != nil {
+			return
+		}
+	}
+	switch {
+	case p.Address == "":
+		return "", requestError("pay to address is required")
+	case amount <= 0:
+		return "", requestError("amount must be more than zero")
+	case p.Passphrase == "":
+		return "", requestError("passphrase is required")
+	}
+	b.sending.Lock()
+	defer b.sending.Unlock()
+	if err = ctl.Call(b.wallet, "walletpassphrase", nil, p.Passphrase, UnlockSeconds); err != nil {
+		return
+	}
+	defer func() {
+		if err := ctl.Call(b.wallet, "walletlock", nil); err != nil {
+			log <- cl.Warn{"walletlock failed:", err}
+		}
+	}()
+	if err = ctl.Call(b.wallet, "sendtoaddress", &txid, p.Address, amount.ToDUO()); err != nil {
+		log <- cl.Warn{"send failed:", err}
+	}
+	return
+}
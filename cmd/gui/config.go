@@ -0,0 +1,125 @@
+package gui
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"git.parallelcoin.io/dev/9/cmd/def"
+	"git.parallelcoin.io/dev/9/pkg/util"
+	"git.parallelcoin.io/dev/9/pkg/util/interrupt"
+)
+// config edits the configuration through the items of the app as the conf TUI does, and saves it to the config file. Running servers keep the configuration they started with until they are restarted.
+type config struct {
+	sync.Mutex
+	ap *def.App
+}
+// setting is a change to one item from the page. Value is the text typed in, or a list of texts for items holding several, and is ignored if Reset is set.
+type setting struct {
+	Cat   string          `json:"cat"`
+	Item  string          `json:"item"`
+	Value json.RawMessage `json:"value"`
+	Reset bool            `json:"reset"`
+}
+// restarting is set once the page asks for a restart
+var restarting int32
+var restartOnce sync.Once
+func (c *config) schema() def.Schema {
+	c.Lock()
+	defer c.Unlock()
+	return c.ap.Schema()
+}
+// set validates and stores a setting and saves the configuration, returning the config file written. An empty text clears the item as in the conf TUI, numbers going to zero.
+func (c *config) set(s setting) (string, error) {
+	c.Lock()
+	defer c.Unlock()
+	rw := c.ap.Cats[s.Cat][s.Item]
+	if rw == nil {
+		return "", requestError("no configuration item " + s.Cat + "." + s.Item)
+	}
+	invalid := requestError("not valid for " + s.Cat + "." + s.Item)
+	switch {
+	case s.Reset:
+		rw.Value.Put(rw.Default.Get())
+	case rw.Type == "stringslice":
+		var list []string
+		if err := json.Unmarshal(s.Value, &list); err != nil {
+			return "", requestError("expected a list for " + s.Cat + "." + s.Item)
+		}
+		if len(list) == 0 {
+			rw.Value.Put(nil)
+			break
+		}
+		// the validators add to the items already there
+		old := rw.Value.Get()
+		rw.Value.Put([]string{})
+		if !rw.Validate(rw, list) {
+			rw.Value.Put(old)
+			return "", invalid
+		}
+	default:
+		var text string
+		if err := json.Unmarshal(s.Value, &text); err != nil {
+			return "", requestError("expected text for " + s.Cat + "." + s.Item)
+		}
+		if text = strings.TrimSpace(text); text == "" {
+			switch rw.Type {
+			case "int":
+				rw.Value.Put(0)
+			case "float":
+				rw.Value.Put(0.0)
+			case "duration":
+				rw.Value.Put(rw.Default.Get())
+			case "bool", "options":
+				return "", invalid
+			default:
+				rw.Value.Put(nil)
+			}
+			break
+		}
+		if !rw.Validate(rw, &text) {
+			return "", invalid
+		}
+	}
+	return c.save()
+}
+// save writes the configuration to the config file in the data directory
+func (c *config) save() (string, error) {
+	ddir, ok := c.ap.Cats["app"]["datadir"].Value.Get().(string)
+	if !ok {
+		return "", errors.New("no data directory to save the configuration in")
+	}
+	configFile := util.CleanAndExpandPath(util.ConfigFile("9", ddir), "")
+	j, err := json.MarshalIndent(c.ap, "", "\t")
+	if err != nil {
+		return "", err
+	}
+	util.EnsureDir(configFile)
+	return configFile, ioutil.WriteFile(configFile, j, 0600)
+}
+// requestRestart shuts down the web wallet, and the node if it runs in the same process, so the gui command can start again with the saved configuration
+func requestRestart() {
+	restartOnce.Do(func() {
+		atomic.StoreInt32(&restarting, 1)
+		if !interrupt.Requested() {
+			interrupt.Request()
+		}
+	})
+}
+// Restarting is true if the page asked for a restart, to be done with Relaunch once everything has shut down
+func Restarting() bool {
+	return atomic.LoadInt32(&restarting) == 1
+}
+// Relaunch starts the program again with the arguments it was started with, sharing the terminal
+func Relaunch() error {
+	path, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(path, os.Args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	return cmd.Start()
+}
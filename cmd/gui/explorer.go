@@ -0,0 +1,113 @@
+package gui
+import (
+	"regexp"
+	"strconv"
+	"git.parallelcoin.io/dev/9/cmd/ctl"
+	rpc "git.parallelcoin.io/dev/9/pkg/rpc/json"
+	"git.parallelcoin.io/dev/9/pkg/util/cl"
+)
+// ExplorerPage is how many blocks or address transactions an explorer page lists
+const ExplorerPage = 20
+// MaxPrevOuts is how many inputs of a transaction get the output they spend looked up, each is another call to the node
+const MaxPrevOuts = 50
+var (
+	hashRE    = regexp.MustCompile("^[0-9a-fA-F]{64}$")
+	heightRE  = regexp.MustCompile("^[0-9]{1,10}$")
+	addressRE = regexp.MustCompile("^[1-9A-HJ-NP-Za-km-z]{25,40}$")
+)
+// errLight is the answer to explorer calls when the wallet is in light mode, there being no node to ask
+const errLight = requestError("the explorer needs a full node, the wallet is in light mode")
+// blockSummary is a row of the explorer's block list
+type blockSummary struct {
+	Height int64  `json:"height"`
+	Hash   string `json:"hash"`
+	Time   int64  `json:"time"`
+	Txs    int    `json:"txs"`
+	Size   int32  `json:"size"`
+	Algo   string `json:"algo"`
+}
+// txDetail is a transaction with the outputs its inputs spend, where they could be found
+type txDetail struct {
+	*rpc.TxRawResult
+	PrevOuts []*rpc.PrevOut `json:"prevouts"`
+}
+// blocks lists the blocks from the height down, the newest if from is negative
+func (b *backend) blocks(from int64) (list []blockSummary, err error) {
+	if b.light() {
+		return nil, errLight
+	}
+	if from < 0 {
+		var count int64
+		if err = ctl.Call(b.node, "getblockcount", &count); err != nil {
+			return
+		}
+		from = count
+	}
+	for h := from; h >= 0 && h > from-ExplorerPage; h-- {
+		var blk *rpc.GetBlockVerboseResult
+		if blk, err = b.block(strconv.FormatInt(h, 10)); err != nil {
+			return
+		}
+		list = append(list, blockSummary{blk.Height, blk.Hash, blk.Time, len(blk.Tx), blk.Size, blk.PowAlgo})
+	}
+	return
+}
+// block gets a block by its height or hash
+func (b *backend) block(id string) (blk *rpc.GetBlockVerboseResult, err error) {
+	switch {
+	case b.light():
+		return nil, errLight
+	case heightRE.MatchString(id):
+		height, _ := strconv.ParseInt(id, 10, 64)
+		if err = ctl.Call(b.node, "getblockhash", &id, height); err != nil {
+			return
+		}
+	case !hashRE.MatchString(id):
+		return nil, requestError("not a block height or hash: " + id)
+	}
+	err = ctl.Call(b.node, "getblock", &blk, id)
+	return
+}
+// transaction gets a transaction and looks up the outputs spent by its first MaxPrevOuts inputs. Transactions outside the mempool are only found if the node runs with txindex.
+func (b *backend) transaction(txid string) (tx txDetail, err error) {
+	switch {
+	case b.light():
+		return tx, errLight
+	case !hashRE.MatchString(txid):
+		return tx, requestError("not a transaction id: " + txid)
+	}
+	if err = ctl.Call(b.node, "getrawtransaction", &tx.TxRawResult, txid, 1); err != nil {
+		return
+	}
+	tx.PrevOuts = make([]*rpc.PrevOut, len(tx.Vin))
+	for i, in := range tx.Vin {
+		if in.IsCoinBase() || i >= MaxPrevOuts {
+			continue
+		}
+		var prev rpc.TxRawResult
+		if err := ctl.Call(b.node, "getrawtransaction", &prev, in.Txid, 1); err != nil {
+			log <- cl.Debug{"previous output", in.Txid, in.Vout, "not found:", err}
+			continue
+		}
+		if int(in.Vout) < len(prev.Vout) {
+			out := prev.Vout[in.Vout]
+			tx.PrevOuts[i] = &rpc.PrevOut{Addresses: out.ScriptPubKey.Addresses, Value: out.Value}
+		}
+	}
+	return
+}
+// addressHistory lists the transactions of an address newest first, skipping the first skip. It needs the node to run with addrindex. An address that was never used has an empty history rather than the node's error.
+func (b *backend) addressHistory(address string, skip int) (txs []rpc.SearchRawTransactionsResult, err error) {
+	switch {
+	case b.light():
+		return nil, errLight
+	case !addressRE.MatchString(address):
+		return nil, requestError("not an address: " + address)
+	}
+	txs = []rpc.SearchRawTransactionsResult{}
+	err = ctl.Call(b.node, "searchrawtransactions", &txs, address, 1, skip, ExplorerPage, 1, true)
+	if e, ok := err.(*rpc.RPCError); ok && e.Code == rpc.ErrRPCNoTxInfo {
+		err = nil
+	}
+	return
+}
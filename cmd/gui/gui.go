@@ -1,7 +1,51 @@
+// Package gui is the web wallet, a single page app served on the local machine showing the wallet's balance and history, sending and receiving payments and the node's status, talking to the wallet and node over their RPC through a backend so the browser never sees the RPC credentials
 package gui
 import (
-	"git.parallelcoin.io/dev/9/cmd"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+	"git.parallelcoin.io/dev/9/cmd/def"
+	"git.parallelcoin.io/dev/9/pkg/util/cl"
+	"git.parallelcoin.io/dev/9/pkg/util/interrupt"
 )
-func Start(args []string, tokens cmd.Tokens, app *cmd.App) int {
+// DefaultListen is where the web wallet is served when wallet.gui is not configured
+const DefaultListen = "127.0.0.1:11049"
+// Run serves the web wallet until an interrupt, opening it in the browser if open is set. The addresses printed carry random launch tokens a page must sign in with, one that can only view the wallet and, unless wallet.guiviewonly is set, one that can also spend, so other users and web sites can't use the wallet through it.
+func Run(ap *def.App, open bool) int {
+	cfg := ap.Config
+	listen := DefaultListen
+	if cfg.WalletGUI != nil && *cfg.WalletGUI != "" {
+		listen = *cfg.WalletGUI
+	}
+	viewOnly := cfg.WalletGUIViewOnly != nil && *cfg.WalletGUIViewOnly
+	s := newServer(ap, viewOnly)
+	srv := &http.Server{Handler: s.routes(), ReadHeaderTimeout: 10 * time.Second}
+	interrupt.AddHandler(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(ctx)
+	})
+	listener, err := net.Listen("tcp", listen)
+	if err != nil {
+		log <- cl.Error{"could not listen for the gui:", err}
+		return 1
+	}
+	url := fmt.Sprintf("http://%s/#%s", listener.Addr(), s.viewToken)
+	fmt.Println("view only web wallet at", url)
+	if !viewOnly {
+		url = fmt.Sprintf("http://%s/#%s", listener.Addr(), s.spendToken)
+		fmt.Println("web wallet at", url)
+	}
+	if open {
+		if err := openBrowser(url); err != nil {
+			fmt.Println("could not open a browser, open the address above:", err)
+		}
+	}
+	if err := srv.Serve(listener); err != http.ErrServerClosed {
+		log <- cl.Error{"gui server failed:", err}
+		return 1
+	}
 	return 0
 }
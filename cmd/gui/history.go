@@ -0,0 +1,77 @@
+package gui
+import (
+	"encoding/csv"
+	"io"
+	"net/url"
+	"strconv"
+	"time"
+	"git.parallelcoin.io/dev/9/cmd/ctl"
+	rpc "git.parallelcoin.io/dev/9/pkg/rpc/json"
+	"git.parallelcoin.io/dev/9/pkg/util"
+)
+// historyFilter picks the wallet transactions the history page shows, zero values matching every transaction. From and To are Unix times, the amounts are compared without their sign and Label matches part of an account name.
+type historyFilter struct {
+	From, To             int64
+	Label, Address       string
+	MinAmount, MaxAmount float64
+}
+// parseFilter reads a filter from the query of a history call, the amounts written in the format the page shows them in
+func parseFilter(q url.Values, format util.AmountFormat) (f historyFilter, err error) {
+	for _, n := range []struct {
+		name string
+		to   *int64
+	}{{"from", &f.From}, {"to", &f.To}} {
+		if v := q.Get(n.name); v != "" {
+			if *n.to, err = strconv.ParseInt(v, 10, 64); err != nil {
+				return f, requestError("invalid time for " + n.name + ": " + v)
+			}
+		}
+	}
+	for _, n := range []struct {
+		name string
+		to   *float64
+	}{{"min", &f.MinAmount}, {"max", &f.MaxAmount}} {
+		if v := q.Get(n.name); v != "" {
+			a, err := util.ParseAmount(v, format)
+			if err != nil || a < 0 {
+				return f, requestError("invalid amount for " + n.name + ": " + v)
+			}
+			*n.to = a.ToDUO()
+		}
+	}
+	f.Label, f.Address = q.Get("label"), q.Get("address")
+	if f.Address != "" && !addressRE.MatchString(f.Address) {
+		return f, requestError("not an address: " + f.Address)
+	}
+	return f, nil
+}
+// history lists the wallet transactions matching the filter, newest first
+func (b *backend) history(f historyFilter) (txs []rpc.ListTransactionsResult, err error) {
+	txs = []rpc.ListTransactionsResult{}
+	err = ctl.Call(b.wallet, "filtertransactions", &txs, f.From, f.To, f.Label, f.Address, f.MinAmount, f.MaxAmount)
+	return
+}
+// writeCSV writes transactions as CSV for spreadsheets and accounting programs, with times in UTC and amounts and fees in DUO to eight decimal places
+func writeCSV(w io.Writer, txs []rpc.ListTransactionsResult) error {
+	c := csv.NewWriter(w)
+	c.Write([]string{"time", "category", "amount", "fee", "confirmations", "address", "label", "transaction", "output"})
+	for _, t := range txs {
+		fee := ""
+		if t.Fee != nil {
+			fee = strconv.FormatFloat(*t.Fee, 'f', 8, 64)
+		}
+		c.Write([]string{
+			time.Unix(t.Time, 0).UTC().Format(time.RFC3339),
+			t.Category,
+			strconv.FormatFloat(t.Amount, 'f', 8, 64),
+			fee,
+			strconv.FormatInt(t.Confirmations, 10),
+			t.Address,
+			t.Account,
+			t.TxID,
+			strconv.FormatUint(uint64(t.Vout), 10),
+		})
+	}
+	c.Flush()
+	return c.Error()
+}
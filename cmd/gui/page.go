@@ -0,0 +1,758 @@
+package gui
+// page is the web wallet, kept in the binary so it needs nothing but the browser. It reads the API token from the fragment of its address, polls the node and wallet every few seconds and builds everything with the DOM so no text from the servers is ever parsed as markup, except the QR code SVG this server makes.
+const page = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>parallelcoin wallet</title>
+<style>
+body { margin: 0; font: 15px/1.4 sans-serif; background: #f4f5f7; color: #222; }
+header { display: flex; align-items: center; background: #1d2b3a; color: #fff; padding: 0 1em; }
+header h1 { font-size: 1.1em; margin: 0 1em 0 0; }
+#viewonly { margin-right: 1em; }
+#mode { margin-right: 1em; padding: .1em .5em; border-radius: 3px; font-size: .85em; cursor: help; }
+#mode.full { background: #27ae60; }
+#mode.light { background: #e67e22; }
+#lightnote { display: none; }
+progress { width: 100%; }
+nav button { background: none; border: 0; color: #aab; font: inherit; padding: 1em; cursor: pointer; }
+nav button.active { color: #fff; border-bottom: 3px solid #4a9eff; }
+main { max-width: 60em; margin: 1.5em auto; padding: 0 1em; }
+section { display: none; }
+section.active { display: block; }
+.card { background: #fff; border-radius: 4px; box-shadow: 0 1px 3px rgba(0,0,0,.15); padding: 1em 1.5em; margin-bottom: 1em; }
+.balance { font-size: 2em; font-weight: bold; }
+.muted { color: #778; }
+.error { color: #c0392b; }
+.ok { color: #27ae60; word-break: break-all; }
+table { width: 100%; border-collapse: collapse; }
+th, td { text-align: left; padding: .3em .5em; border-bottom: 1px solid #eee; }
+td.amount { text-align: right; font-family: monospace; }
+td.mono { font-family: monospace; word-break: break-all; }
+label { display: block; margin: .8em 0 .2em; }
+input { width: 100%; box-sizing: border-box; padding: .5em; font: inherit; }
+form button, #newaddress { margin-top: 1em; padding: .5em 1.5em; font: inherit; cursor: pointer; }
+#qr svg { width: 16em; height: 16em; }
+#address { font: 1.1em monospace; word-break: break-all; }
+#uri { font-family: monospace; word-break: break-all; }
+#savepng, #requestcard { display: none; }
+dl { display: grid; grid-template-columns: max-content auto; gap: .3em 1.5em; margin: 0; }
+dd { margin: 0; font-family: monospace; word-break: break-all; }
+a { color: #1f6fd1; text-decoration: none; }
+#explorerview dl { margin-bottom: 1em; }
+fieldset { border: 0; padding: 0; margin: 0 0 1.5em; }
+legend { font-weight: bold; font-size: 1.1em; margin-bottom: .3em; }
+.field { display: grid; grid-template-columns: 12em auto 5em; gap: .5em; align-items: start; padding: .4em 0; border-bottom: 1px solid #eee; }
+.field.changed { background: #fff8e1; }
+.field.invalid input, .field.invalid textarea { border-color: #c0392b; }
+.field .help { grid-column: 2 / 4; font-size: .85em; color: #778; }
+.field button { font: inherit; padding: .3em; }
+textarea { width: 100%; box-sizing: border-box; font: .9em monospace; }
+select { font: inherit; padding: .4em; }
+.filters { display: grid; grid-template-columns: repeat(auto-fill, minmax(14em, 1fr)); gap: 0 1em; }
+#exportcsv { margin-left: 1em; }
+#restartbar { display: none; position: sticky; top: 0; background: #1d2b3a; color: #fff; padding: .7em 1em; margin-bottom: 1em; }
+#restartbar button { margin-left: 1em; font: inherit; }
+</style>
+</head>
+<body>
+<header>
+<h1>parallelcoin</h1><span id="viewonly" class="muted" style="display: none">view only</span><span id="mode"></span>
+<nav>
+<button data-page="overview" class="active">overview</button>
+<button data-page="send">send</button>
+<button data-page="receive">receive</button>
+<button data-page="history">history</button>
+<button data-page="node">node</button>
+<button data-page="explorer">explorer</button>
+<button data-page="config">config</button>
+</nav>
+</header>
+<main>
+<p id="error" class="error"></p>
+<section id="overview" class="active">
+<div class="card">
+<div class="muted">balance</div>
+<div class="balance" id="balance">-</div>
+<div class="muted" id="pending"></div>
+<div class="error" id="rescan"></div>
+</div>
+<div class="card">
+<h3>recent transactions</h3>
+<table><tbody id="recent"></tbody></table>
+</div>
+</section>
+<section id="send">
+<div class="card">
+<form id="sendform">
+<label for="payto">pay to</label>
+<input id="payto" autocomplete="off" required>
+<label for="amount">amount in <span class="unit">DUO</span></label>
+<input id="amount" inputmode="decimal" autocomplete="off" required>
+<label for="passphrase">passphrase</label>
+<input id="passphrase" type="password" autocomplete="off" required>
+<button type="submit">send</button>
+</form>
+<p id="sendresult"></p>
+</div>
+</section>
+<section id="receive">
+<div class="card">
+<div id="qr"></div>
+<p id="address" class="muted">make an address to receive a payment at</p>
+<p id="uri" class="muted"></p>
+<a id="savepng" download="payment.png">save as PNG</a>
+<button id="newaddress">new address</button>
+</div>
+<div class="card" id="requestcard">
+<form id="requestform">
+<label for="reqamount">amount to ask for in <span class="unit">DUO</span></label>
+<input id="reqamount" inputmode="decimal" autocomplete="off">
+<label for="reqlabel">label</label>
+<input id="reqlabel" autocomplete="off">
+<label for="reqmessage">message</label>
+<input id="reqmessage" autocomplete="off">
+</form>
+</div>
+</section>
+<section id="history">
+<div class="card">
+<form id="historyform">
+<div class="filters">
+<label>from <input id="histfrom" type="date"></label>
+<label>to <input id="histto" type="date"></label>
+<label>label <input id="histlabel" autocomplete="off"></label>
+<label>address <input id="histaddress" autocomplete="off"></label>
+<label><span class="unit">DUO</span> at least <input id="histmin" inputmode="decimal" autocomplete="off"></label>
+<label><span class="unit">DUO</span> at most <input id="histmax" inputmode="decimal" autocomplete="off"></label>
+</div>
+<button type="submit">search</button>
+<button type="reset">clear</button>
+<a id="exportcsv" href="/api/history.csv" download>export CSV</a>
+</form>
+<p id="historycount" class="muted"></p>
+<table>
+<thead><tr><th>time</th><th>category</th><th>amount</th><th>confirmations</th><th>address</th><th>label</th></tr></thead>
+<tbody id="transactions"></tbody>
+</table>
+</div>
+</section>
+<section id="node">
+<div class="card" id="lightnote">
+<h3>light mode</h3>
+<p>The wallet has no full node of its own. It downloads block headers and compact block filters from peers and only fetches the blocks the filters say hold its transactions. The headers are checked for proof of work but the transactions in them are not validated, and peers can hide transactions from the wallet, so for large amounts wait for more confirmations or use a full node.</p>
+<progress id="filtersync" max="1" value="0"></progress>
+</div>
+<div class="card"><dl id="status"></dl></div>
+</section>
+<section id="config">
+<div id="restartbar"><span id="savedto"></span><button id="restart">restart to apply</button></div>
+<div class="card">
+<input id="configfilter" placeholder="filter by name or description" autocomplete="off">
+</div>
+<div class="card" id="configform"></div>
+</section>
+<section id="explorer">
+<div class="card">
+<form id="search"><input id="query" placeholder="block height or hash, transaction id or address" autocomplete="off"></form>
+</div>
+<div class="card" id="explorerview"></div>
+</section>
+</main>
+<script>
+"use strict";
+var launch = /^#[0-9a-f]{32}$/.test(location.hash) ? location.hash.slice(1) : "", csrf = "";
+// format is how amounts are written, set from app.locale and app.unit once signed in, and amounts typed in are read by the server the same way
+var format = {unit: "DUO", group: "", point: ".", decimals: 8};
+if (launch) {
+	history.replaceState(null, "", location.pathname);
+}
+function call(method, path, body) {
+	var opts = {method: method, headers: {"X-CSRF-Token": csrf}, credentials: "same-origin"};
+	if (body) {
+		opts.headers["Content-Type"] = "application/json";
+		opts.body = JSON.stringify(body);
+	}
+	return fetch(path, opts).then(function(r) {
+		return r.json().then(function(v) {
+			if (!r.ok) {
+				throw new Error(v.error || r.statusText);
+			}
+			return v;
+		});
+	});
+}
+function el(tag, text, cls) {
+	var e = document.createElement(tag);
+	if (text !== undefined) {
+		e.textContent = text;
+	}
+	if (cls) {
+		e.className = cls;
+	}
+	return e;
+}
+// coins writes an amount of DUO from the RPC servers in the format, counting it in satoshi so moving the decimal point to the unit is exact
+function coins(n) {
+	var sat = Math.round(n * 1e8), digits = String(Math.abs(sat));
+	while (digits.length <= format.decimals) {
+		digits = "0" + digits;
+	}
+	var whole = digits.slice(0, digits.length - format.decimals), frac = digits.slice(digits.length - format.decimals);
+	whole = whole.replace(/\B(?=(\d{3})+$)/g, format.group);
+	return (sat < 0 ? "-" : "") + whole + (frac ? format.point + frac : "");
+}
+function when(t) {
+	return new Date(t * 1000).toLocaleString();
+}
+function fill(id, rows) {
+	var body = document.getElementById(id);
+	body.textContent = "";
+	rows.forEach(function(r) {
+		body.appendChild(r);
+	});
+}
+function row(cells) {
+	var tr = el("tr");
+	cells.forEach(function(c) {
+		tr.appendChild(c);
+	});
+	return tr;
+}
+function showError(err) {
+	document.getElementById("error").textContent = err ? err.message : "";
+}
+function signIn() {
+	var session = launch ? call("POST", "/api/session", {token: launch}) : call("GET", "/api/session");
+	return session.then(function(s) {
+		csrf = s.csrf;
+		if (s.capability !== "spend") {
+			document.querySelector("nav button[data-page=send]").style.display = "none";
+			document.querySelector("nav button[data-page=config]").style.display = "none";
+			document.getElementById("viewonly").style.display = "";
+		}
+		return call("GET", "/api/format");
+	}).then(function(f) {
+		format = f;
+		document.querySelectorAll(".unit").forEach(function(u) {
+			u.textContent = f.unit;
+		});
+	});
+}
+function refresh() {
+	Promise.all([call("GET", "/api/status"), call("GET", "/api/wallet")]).then(function(r) {
+		showError();
+		showStatus(r[0]);
+		showWallet(r[0], r[1]);
+	}).catch(showError);
+}
+function showWallet(st, w) {
+	document.getElementById("balance").textContent = coins(w.balance);
+	document.getElementById("pending").textContent = w.unconfirmed ? "unconfirmed " + coins(w.unconfirmed) : "";
+	var rescan = "", blocks = st.light ? st.sync.headers : st.chain.blocks;
+	if (st.light && st.sync.filterheaders < st.sync.headers) {
+		rescan = "syncing block filters " + st.sync.filterheaders + " of " + st.sync.headers + ", the balance may be incomplete";
+	} else if (w.height && w.height < blocks) {
+		rescan = "rescanning block " + w.height + " of " + blocks + ", the balance may be incomplete";
+	}
+	document.getElementById("rescan").textContent = rescan;
+	var txs = (w.transactions || []).slice().reverse();
+	fill("recent", txs.slice(0, 5).map(function(t) {
+		return row([el("td", when(t.time)), el("td", t.category), el("td", coins(t.amount), "amount")]);
+	}));
+}
+// historyQuery is the query of the history calls for the filters in the form, the dates being whole days in local time
+function historyQuery() {
+	var q = [], v = function(id) {
+		return document.getElementById(id).value.trim();
+	};
+	if (v("histfrom")) {
+		q.push("from=" + Math.floor(new Date(v("histfrom") + "T00:00:00").getTime() / 1000));
+	}
+	if (v("histto")) {
+		q.push("to=" + Math.floor(new Date(v("histto") + "T23:59:59").getTime() / 1000));
+	}
+	[["label", "histlabel"], ["address", "histaddress"], ["min", "histmin"], ["max", "histmax"]].forEach(function(f) {
+		if (v(f[1])) {
+			q.push(f[0] + "=" + encodeURIComponent(v(f[1])));
+		}
+	});
+	return q.join("&");
+}
+function searchHistory() {
+	var q = historyQuery();
+	document.getElementById("exportcsv").href = "/api/history.csv" + (q ? "?" + q : "");
+	call("GET", "/api/history" + (q ? "?" + q : "")).then(function(txs) {
+		document.getElementById("historycount").textContent = txs.length + (txs.length === 1 ? " transaction" : " transactions");
+		fill("transactions", txs.map(function(t) {
+			return row([el("td", when(t.time)), el("td", t.category), el("td", coins(t.amount), "amount"),
+				el("td", String(t.confirmations)), td(t.address ? link(t.address, "address/" + t.address) : "", "mono"), el("td", t.account || "")]);
+		}));
+	}).catch(showError);
+}
+// showMode shows whether the wallet follows a full node or syncs in light mode, hiding the explorer which needs a full node
+function showMode(st) {
+	var mode = document.getElementById("mode");
+	mode.textContent = st.light ? "light mode" : "full node";
+	mode.className = st.light ? "light" : "full";
+	mode.title = st.light ? "the wallet trusts its peers to not hide transactions, see the node page" : "the wallet follows a full node that validates every block";
+	document.querySelector("nav button[data-page=explorer]").style.display = st.light ? "none" : "";
+	document.getElementById("lightnote").style.display = st.light ? "block" : "none";
+}
+function showStatus(st) {
+	showMode(st);
+	var items;
+	if (st.light) {
+		var s = st.sync;
+		items = [
+			["mode", "light, compact block filters from peers"],
+			["block headers", String(s.headers)],
+			["filter headers", s.filterheaders + " / " + s.headers],
+			["wallet synced to", String(s.height) + (s.synced ? "" : ", catching up")],
+			["peers", String(s.peers)]
+		];
+		var progress = document.getElementById("filtersync");
+		progress.max = Math.max(s.headers, 1);
+		progress.value = s.filterheaders;
+		fillStatus(items);
+		return;
+	}
+	var c = st.chain;
+	items = [
+		["mode", "full node"],
+		["network", c.chain],
+		["blocks", c.blocks + " / " + c.headers + " headers"],
+		["best block", c.bestblockhash],
+		["difficulty", String(c.difficulty)],
+		["median time", when(c.mediantime)],
+		["peers", String(st.peers)]
+	];
+	if (st.mempool) {
+		items.push(["mempool", st.mempool.size + " transactions, " + st.mempool.bytes + " bytes"]);
+	}
+	if (st.update && st.update.available) {
+		items.push(["update", "version " + st.update.available + " is available, this is " + st.update.version +
+			(st.update.staged ? ", downloaded to " + st.update.staged : ", run stageupdate to download it")]);
+	}
+	fillStatus(items);
+}
+function fillStatus(items) {
+	var dl = document.getElementById("status");
+	dl.textContent = "";
+	items.forEach(function(i) {
+		dl.appendChild(el("dt", i[0]));
+		dl.appendChild(el("dd", i[1]));
+	});
+}
+function showPage(name) {
+	document.querySelectorAll("nav button, section").forEach(function(e) {
+		e.classList.remove("active");
+	});
+	document.querySelector("nav button[data-page=" + name + "]").classList.add("active");
+	document.getElementById(name).classList.add("active");
+}
+document.querySelectorAll("nav button").forEach(function(b) {
+	b.addEventListener("click", function() {
+		if (b.dataset.page === "explorer") {
+			location.hash = "explorer/blocks";
+			return;
+		}
+		showPage(b.dataset.page);
+		if (b.dataset.page === "history") {
+			searchHistory();
+		}
+	});
+});
+document.getElementById("historyform").addEventListener("submit", function(e) {
+	e.preventDefault();
+	searchHistory();
+});
+document.getElementById("historyform").addEventListener("reset", function() {
+	setTimeout(searchHistory);
+});
+// the explorer's views are addressed by the fragment, explorer/<view>/<id>, so
+// links, back and forward move between them
+function link(text, route) {
+	var a = el("a", text);
+	a.href = "#explorer/" + route;
+	return a;
+}
+function td(child, cls) {
+	var c = el("td", undefined, cls);
+	c.appendChild(typeof child === "string" ? document.createTextNode(child) : child);
+	return c;
+}
+function explorerTable(heads, rows) {
+	var t = el("table"), tr = el("tr"), body = el("tbody");
+	heads.forEach(function(h) {
+		tr.appendChild(el("th", h));
+	});
+	t.appendChild(el("thead")).appendChild(tr);
+	rows.forEach(function(r) {
+		body.appendChild(row(r));
+	});
+	t.appendChild(body);
+	return t;
+}
+function facts(items) {
+	var dl = el("dl");
+	items.forEach(function(i) {
+		dl.appendChild(el("dt", i[0]));
+		var dd = el("dd");
+		dd.appendChild(typeof i[1] === "string" ? document.createTextNode(i[1]) : i[1]);
+		dl.appendChild(dd);
+	});
+	return dl;
+}
+function addresses(list) {
+	var span = el("span");
+	(list || []).forEach(function(a, i) {
+		if (i) {
+			span.appendChild(document.createTextNode(" "));
+		}
+		span.appendChild(link(a, "address/" + a));
+	});
+	return span;
+}
+var explorerViews = {
+	blocks: function(from) {
+		return call("GET", "/api/blocks" + (from ? "?from=" + from : "")).then(function(blocks) {
+			var view = [explorerTable(["height", "time", "algorithm", "transactions", "size", "hash"], blocks.map(function(b) {
+				return [td(link(String(b.height), "block/" + b.hash)), td(when(b.time)), td(b.algo),
+					td(String(b.txs), "amount"), td(String(b.size), "amount"), td(b.hash, "mono")];
+			}))];
+			var last = blocks[blocks.length - 1];
+			if (last && last.height > 0) {
+				view.push(link("older blocks", "blocks/" + (last.height - 1)));
+			}
+			return view;
+		});
+	},
+	block: function(id) {
+		return call("GET", "/api/block?id=" + encodeURIComponent(id)).then(function(b) {
+			var nav = el("p");
+			if (b.previousblockhash) {
+				nav.appendChild(link("previous block", "block/" + b.previousblockhash));
+				nav.appendChild(document.createTextNode("  "));
+			}
+			if (b.nextblockhash) {
+				nav.appendChild(link("next block", "block/" + b.nextblockhash));
+			}
+			return [el("h3", "block " + b.height), facts([
+				["hash", b.hash],
+				["time", when(b.time)],
+				["confirmations", String(b.confirmations)],
+				["algorithm", b.pow_algo],
+				["difficulty", String(b.difficulty)],
+				["size", b.size + " bytes"],
+				["merkle root", b.merkleroot]
+			]), nav, explorerTable(["transaction"], (b.tx || []).map(function(txid) {
+				return [td(link(txid, "tx/" + txid), "mono")];
+			}))];
+		});
+	},
+	tx: function(id) {
+		return call("GET", "/api/tx?id=" + encodeURIComponent(id)).then(function(tx) {
+			var inputs = tx.vin.map(function(vin, i) {
+				if (vin.coinbase) {
+					return [td("coinbase"), td(""), td("")];
+				}
+				var prev = tx.prevouts[i];
+				return [td(link(vin.txid + ":" + vin.vout, "tx/" + vin.txid), "mono"),
+					td(prev ? addresses(prev.addresses) : "", "mono"), td(prev ? coins(prev.value) : "", "amount")];
+			});
+			var outputs = tx.vout.map(function(out) {
+				return [td(String(out.n)), td(addresses(out.scriptPubKey.addresses), "mono"), td(coins(out.value), "amount")];
+			});
+			return [el("h3", "transaction"), facts([
+				["id", tx.txid],
+				["block", tx.blockhash ? link(tx.blockhash, "block/" + tx.blockhash) : "in the mempool"],
+				["confirmations", String(tx.confirmations || 0)],
+				["time", tx.time ? when(tx.time) : ""],
+				["size", tx.size + " bytes"]
+			]), el("h4", "inputs"), explorerTable(["spends", "address", "amount"], inputs),
+				el("h4", "outputs"), explorerTable(["output", "address", "amount"], outputs)];
+		});
+	},
+	address: function(id, skip) {
+		skip = parseInt(skip || "0", 10);
+		return call("GET", "/api/address?id=" + encodeURIComponent(id) + "&skip=" + skip).then(function(txs) {
+			var view = [el("h3", "address " + id), explorerTable(["time", "transaction", "received", "sent"], txs.map(function(tx) {
+				var received = 0, sent = 0;
+				tx.vout.forEach(function(out) {
+					if ((out.scriptPubKey.addresses || []).indexOf(id) >= 0) {
+						received += out.value;
+					}
+				});
+				tx.vin.forEach(function(vin) {
+					if (vin.prevOut && (vin.prevOut.addresses || []).indexOf(id) >= 0) {
+						sent += vin.prevOut.value;
+					}
+				});
+				return [td(tx.time ? when(tx.time) : ""), td(link(tx.txid, "tx/" + tx.txid), "mono"),
+					td(received ? coins(received) : "", "amount"), td(sent ? coins(sent) : "", "amount")];
+			}))];
+			if (!txs.length && !skip) {
+				view.push(el("p", "no transactions", "muted"));
+			}
+			// a full page, ExplorerPage on the server, may have more after it
+			if (txs.length === 20) {
+				view.push(link("older transactions", "address/" + id + "/" + (skip + 20)));
+			}
+			return view;
+		});
+	}
+};
+function showExplorer() {
+	var parts = location.hash.slice(1).split("/"), view = explorerViews[parts[1]] || explorerViews.blocks,
+		out = document.getElementById("explorerview");
+	showPage("explorer");
+	out.textContent = "loading...";
+	view(parts[2], parts[3]).then(function(nodes) {
+		out.textContent = "";
+		nodes.forEach(function(n) {
+			out.appendChild(n);
+		});
+	}).catch(function(err) {
+		out.textContent = "";
+		out.appendChild(el("p", err.message, "error"));
+	});
+}
+// the config page has a field for every item of every group, marked when it
+// differs from the default. Each change is checked here as far as the type
+// goes, then by the item's own validator when it is saved.
+var durationRE = /^([0-9]+(\.[0-9]+)?(ns|us|\u00b5s|ms|s|m|h))+$/;
+function checkField(f, text) {
+	if (text === "") {
+		return "";
+	}
+	switch (f.type) {
+	case "int":
+	case "port":
+		if (!/^-?[0-9]+$/.test(text)) {
+			return "a whole number";
+		}
+		var n = parseInt(text, 10);
+		if (f.type === "port" && (n < 1025 || n > 65535)) {
+			return "a port from 1025 to 65535";
+		}
+		if (f.min && n < f.min || f.max && n > f.max) {
+			return "a number from " + (f.min || 0) + " to " + f.max;
+		}
+		return "";
+	case "float":
+		return isNaN(parseFloat(text)) || !isFinite(text) ? "a number" : "";
+	case "duration":
+		return durationRE.test(text) ? "" : "a duration such as 30s, 5m or 1h30m";
+	}
+	return "";
+}
+function changed(f) {
+	if (f.secret) {
+		return f.set;
+	}
+	if (f.type === "stringslice") {
+		return JSON.stringify((f.value || []).slice().sort()) !== JSON.stringify((f.default || []).slice().sort());
+	}
+	return f.text !== f.defaulttext;
+}
+function fieldInput(f) {
+	var input;
+	if (f.type === "bool" || f.type === "options") {
+		input = el("select");
+		(f.type === "bool" ? ["false", "true"] : f.opts.slice().sort()).forEach(function(o) {
+			var opt = el("option", o + (o === f.defaulttext ? " (default)" : ""));
+			opt.value = o;
+			opt.selected = o === f.text;
+			input.appendChild(opt);
+		});
+	} else if (f.type === "stringslice") {
+		input = el("textarea");
+		input.rows = Math.max(2, Math.min(8, (f.value || []).length + 1));
+		input.value = (f.value || []).join("\n");
+		input.placeholder = f.secret ? (f.set ? "set, one per line to replace" : "not set, one per line") : "one per line";
+	} else {
+		input = el("input");
+		input.type = f.secret ? "password" : "text";
+		input.value = f.text;
+		input.placeholder = f.secret ? (f.set ? "set, type to replace" : "not set") : f.defaulttext;
+	}
+	input.autocomplete = "off";
+	return input;
+}
+function configValue(f, input) {
+	if (f.type === "stringslice") {
+		return input.value.split("\n").map(function(s) {
+			return s.trim();
+		}).filter(function(s) {
+			return s !== "";
+		});
+	}
+	return input.value.trim();
+}
+function saveSetting(cat, item, body, box, help) {
+	body.cat = cat;
+	body.item = item;
+	return call("POST", "/api/config", body).then(function(r) {
+		box.classList.remove("invalid");
+		document.getElementById("savedto").textContent = "saved to " + r.file + ", the node and wallet use the new settings when they are restarted";
+		document.getElementById("restartbar").style.display = "block";
+		return loadConfig();
+	}).catch(function(err) {
+		box.classList.add("invalid");
+		help.textContent = err.message;
+	});
+}
+function configField(cat, item, f) {
+	var box = el("div", undefined, "field" + (changed(f) ? " changed" : "")),
+		input = fieldInput(f), help = el("div", f.usage.trim(), "help"), reset = el("button", "default");
+	box.dataset.search = (cat + "." + item + " " + f.usage).toLowerCase();
+	box.appendChild(el("label", item));
+	box.appendChild(input);
+	reset.type = "button";
+	reset.title = f.secret ? "reset to the default" : "reset to " + (f.type === "stringslice" ? JSON.stringify(f.default || []) : (f.defaulttext || "nothing"));
+	reset.disabled = !changed(f);
+	reset.addEventListener("click", function() {
+		saveSetting(cat, item, {reset: true}, box, help);
+	});
+	box.appendChild(reset);
+	box.appendChild(help);
+	input.addEventListener("change", function() {
+		var problem = f.type === "stringslice" ? "" : checkField(f, input.value.trim());
+		if (problem) {
+			box.classList.add("invalid");
+			help.textContent = "should be " + problem;
+			return;
+		}
+		saveSetting(cat, item, {value: configValue(f, input)}, box, help);
+	});
+	return box;
+}
+function loadConfig() {
+	return call("GET", "/api/config").then(function(schema) {
+		var form = document.getElementById("configform");
+		form.textContent = "";
+		Object.keys(schema).sort().forEach(function(cat) {
+			var set = el("fieldset");
+			set.appendChild(el("legend", cat));
+			Object.keys(schema[cat]).sort().forEach(function(item) {
+				set.appendChild(configField(cat, item, schema[cat][item]));
+			});
+			form.appendChild(set);
+		});
+		filterConfig();
+	}).catch(showError);
+}
+function filterConfig() {
+	var q = document.getElementById("configfilter").value.trim().toLowerCase();
+	document.querySelectorAll("#configform .field").forEach(function(f) {
+		f.style.display = f.dataset.search.indexOf(q) >= 0 ? "" : "none";
+	});
+	document.querySelectorAll("#configform fieldset").forEach(function(s) {
+		s.style.display = s.querySelector(".field:not([style*=none])") ? "" : "none";
+	});
+}
+document.getElementById("configfilter").addEventListener("input", filterConfig);
+document.querySelector("nav button[data-page=config]").addEventListener("click", loadConfig);
+document.getElementById("restart").addEventListener("click", function() {
+	if (!confirm("restart the web wallet, and the node if it runs with it?")) {
+		return;
+	}
+	call("POST", "/api/restart").then(function() {
+		document.getElementById("savedto").textContent = "restarting, the wallet opens again in a new window";
+		document.getElementById("restart").style.display = "none";
+	}).catch(showError);
+});
+window.addEventListener("hashchange", function() {
+	if (location.hash.indexOf("#explorer") === 0) {
+		showExplorer();
+	}
+});
+document.getElementById("search").addEventListener("submit", function(e) {
+	e.preventDefault();
+	var q = document.getElementById("query").value.trim();
+	if (/^[0-9]+$/.test(q)) {
+		location.hash = "explorer/block/" + q;
+	} else if (/^[0-9a-fA-F]{64}$/.test(q)) {
+		// a hash is a block or a transaction, try the block first
+		call("GET", "/api/block?id=" + q).then(function() {
+			location.hash = "explorer/block/" + q;
+		}, function() {
+			location.hash = "explorer/tx/" + q;
+		});
+	} else if (q) {
+		location.hash = "explorer/address/" + q;
+	}
+});
+// showAddress shows the QR code of the payment URI for an address, with a link to save it
+function showAddress(a) {
+	document.getElementById("qr").innerHTML = a.qr;
+	var p = document.getElementById("address");
+	p.textContent = a.address;
+	p.className = "";
+	document.getElementById("uri").textContent = a.uri;
+	var png = document.getElementById("savepng");
+	png.href = a.png;
+	png.style.display = "inline-block";
+	document.getElementById("requestcard").style.display = "block";
+}
+document.getElementById("newaddress").addEventListener("click", function() {
+	call("POST", "/api/receive").then(function(a) {
+		document.getElementById("requestform").reset();
+		showAddress(a);
+	}).catch(showError);
+});
+// the QR code is made again with the amount, label and message asked for as they are typed, answers to earlier edits being dropped
+var requests = 0;
+document.getElementById("requestform").addEventListener("input", function() {
+	var n = ++requests;
+	var q = ["address", "reqamount", "reqlabel", "reqmessage"].map(function(id, i) {
+		var v = i ? document.getElementById(id).value.trim() : document.getElementById("address").textContent;
+		return id.replace(/^req/, "") + "=" + encodeURIComponent(v);
+	});
+	call("GET", "/api/request?" + q.join("&")).then(function(a) {
+		if (n !== requests) {
+			return;
+		}
+		showError();
+		showAddress(a);
+	}).catch(showError);
+});
+document.getElementById("sendform").addEventListener("submit", function(e) {
+	e.preventDefault();
+	var address = document.getElementById("payto").value.trim(),
+		amount = document.getElementById("amount").value.trim(),
+		result = document.getElementById("sendresult");
+	if (!confirm("send " + amount + " " + format.unit + " to " + address + "?")) {
+		return;
+	}
+	result.className = "muted";
+	result.textContent = "sending...";
+	call("POST", "/api/send", {
+		address: address,
+		amount: amount,
+		passphrase: document.getElementById("passphrase").value
+	}).then(function(r) {
+		document.getElementById("passphrase").value = "";
+		result.className = "ok";
+		result.textContent = "sent " + r.txid;
+		refresh();
+	}).catch(function(err) {
+		result.className = "error";
+		result.textContent = err.message;
+	});
+});
+signIn().then(function() {
+	if (location.hash.indexOf("#explorer") === 0) {
+		showExplorer();
+	}
+	refresh();
+	setInterval(refresh, 5000);
+}).catch(function() {
+	showError(new Error("open the address printed by the gui command, it carries the key to the wallet"));
+});
+</script>
+</body>
+</html>
+`
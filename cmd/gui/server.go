@@ -0,0 +1,283 @@
+package gui
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"git.parallelcoin.io/dev/9/cmd/def"
+	"git.parallelcoin.io/dev/9/pkg/util"
+	"git.parallelcoin.io/dev/9/pkg/util/cl"
+)
+// CSRFHeader is the request header the page sends its session's csrf token in
+const CSRFHeader = "X-CSRF-Token"
+// SessionCookie is the cookie holding the session id
+const SessionCookie = "session"
+// server signs pages in and answers their API calls through the backend. A page signs in by posting one of the launch tokens from the address it was opened with, and gets a session with the capability of that token.
+type server struct {
+	backend  *backend
+	config   *config
+	sessions sessions
+	// viewToken and spendToken are the launch tokens, spendToken is empty if the web wallet is view only
+	viewToken  string
+	spendToken string
+}
+// requestError is a call the page got wrong, answered with status 400 rather than as a failure of the RPC servers
+type requestError string
+func (e requestError) Error() string {
+	return string(e)
+}
+func newServer(ap *def.App, viewOnly bool) *server {
+	s := &server{backend: newBackend(ap.Config), config: &config{ap: ap}, viewToken: newToken()}
+	if !viewOnly {
+		s.spendToken = newToken()
+	}
+	return s
+}
+func (s *server) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.index)
+	mux.HandleFunc("/api/session", s.session)
+	mux.HandleFunc("/api/status", s.api(http.MethodGet, capView, func(r *http.Request) (interface{}, error) {
+		return s.backend.status()
+	}))
+	mux.HandleFunc("/api/wallet", s.api(http.MethodGet, capView, func(r *http.Request) (interface{}, error) {
+		return s.backend.overview()
+	}))
+	mux.HandleFunc("/api/receive", s.api(http.MethodPost, capView, func(r *http.Request) (interface{}, error) {
+		return s.backend.newAddress()
+	}))
+	mux.HandleFunc("/api/request", s.api(http.MethodGet, capView, func(r *http.Request) (interface{}, error) {
+		q := r.URL.Query()
+		var amount util.Amount
+		if a := q.Get("amount"); a != "" {
+			var err error
+			if amount, err = s.backend.parseAmount(a); err != nil {
+				return nil, err
+			}
+		}
+		return paymentRequest(q.Get("address"), amount.ToDUO(), q.Get("label"), q.Get("message"))
+	}))
+	mux.HandleFunc("/api/format", s.api(http.MethodGet, capView, func(r *http.Request) (interface{}, error) {
+		return s.backend.amountFormat(), nil
+	}))
+	mux.HandleFunc("/api/history", s.api(http.MethodGet, capView, func(r *http.Request) (interface{}, error) {
+		f, err := parseFilter(r.URL.Query(), s.backend.format)
+		if err != nil {
+			return nil, err
+		}
+		return s.backend.history(f)
+	}))
+	// the export is a link the browser downloads, so it is written as CSV rather than JSON
+	mux.HandleFunc("/api/history.csv", func(w http.ResponseWriter, r *http.Request) {
+		if !s.allowed(w, r, http.MethodGet, capView) {
+			return
+		}
+		f, err := parseFilter(r.URL.Query(), s.backend.format)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		txs, err := s.backend.history(f)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="transactions.csv"`)
+		w.Header().Set("Cache-Control", "no-store")
+		if err := writeCSV(w, txs); err != nil {
+			log <- cl.Debug{"writing transactions failed:", err}
+		}
+	})
+	mux.HandleFunc("/api/blocks", s.api(http.MethodGet, capView, func(r *http.Request) (interface{}, error) {
+		from := int64(-1)
+		if f := r.URL.Query().Get("from"); f != "" {
+			var err error
+			if from, err = strconv.ParseInt(f, 10, 64); err != nil {
+				return nil, requestError("invalid height: " + f)
+			}
+		}
+		return s.backend.blocks(from)
+	}))
+	mux.HandleFunc("/api/block", s.api(http.MethodGet, capView, func(r *http.Request) (interface{}, error) {
+		return s.backend.block(r.URL.Query().Get("id"))
+	}))
+	mux.HandleFunc("/api/tx", s.api(http.MethodGet, capView, func(r *http.Request) (interface{}, error) {
+		return s.backend.transaction(r.URL.Query().Get("id"))
+	}))
+	mux.HandleFunc("/api/address", s.api(http.MethodGet, capView, func(r *http.Request) (interface{}, error) {
+		skip, _ := strconv.Atoi(r.URL.Query().Get("skip"))
+		if skip < 0 {
+			skip = 0
+		}
+		return s.backend.addressHistory(r.URL.Query().Get("id"), skip)
+	}))
+	mux.HandleFunc("/api/send", s.api(http.MethodPost, capSpend, func(r *http.Request) (interface{}, error) {
+		var p payment
+		if err := json.NewDecoder(io.LimitReader(r.Body, 4096)).Decode(&p); err != nil {
+			return nil, requestError("invalid payment: " + err.Error())
+		}
+		txid, err := s.backend.send(p)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]string{"txid": txid}, nil
+	}))
+	// the configuration holds the RPC credentials and decides what the node
+	// and wallet do, so only spending sessions see and change it
+	mux.HandleFunc("/api/config", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			s.api(http.MethodGet, capSpend, func(r *http.Request) (interface{}, error) {
+				return s.config.schema(), nil
+			})(w, r)
+			return
+		}
+		s.api(http.MethodPost, capSpend, func(r *http.Request) (interface{}, error) {
+			var set setting
+			if err := json.NewDecoder(io.LimitReader(r.Body, 65536)).Decode(&set); err != nil {
+				return nil, requestError("invalid setting: " + err.Error())
+			}
+			file, err := s.config.set(set)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]string{"file": file}, nil
+		})(w, r)
+	})
+	mux.HandleFunc("/api/restart", s.api(http.MethodPost, capSpend, func(r *http.Request) (interface{}, error) {
+		// the shutdown waits for this response to be written
+		go requestRestart()
+		return map[string]string{}, nil
+	}))
+	return mux
+}
+// index serves the page. It holds no secrets, the launch token is in the fragment of the address the user opened, which browsers don't send.
+func (s *server) index(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Content-Security-Policy", "default-src 'self'; script-src 'unsafe-inline'; style-src 'unsafe-inline'; img-src 'self' data:")
+	w.Header().Set("X-Frame-Options", "DENY")
+	w.Write([]byte(page))
+}
+// session signs in with a launch token on POST, tells the page its csrf token and capability on GET so a reloaded page carries on, and signs out on DELETE
+func (s *server) session(w http.ResponseWriter, r *http.Request) {
+	if !sameOrigin(r) {
+		writeJSON(w, http.StatusForbidden, map[string]string{"error": "cross origin request"})
+		return
+	}
+	switch r.Method {
+	case http.MethodPost:
+		var launch struct {
+			Token string `json:"token"`
+		}
+		if err := json.NewDecoder(io.LimitReader(r.Body, 1024)).Decode(&launch); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid sign in: " + err.Error()})
+			return
+		}
+		var can capability
+		switch {
+		case equal(launch.Token, s.spendToken):
+			can = capSpend
+		case equal(launch.Token, s.viewToken):
+			can = capView
+		default:
+			log <- cl.Warn{"gui sign in with an invalid token from", r.RemoteAddr}
+			writeJSON(w, http.StatusForbidden, map[string]string{"error": "invalid token"})
+			return
+		}
+		id, ss := s.sessions.start(can)
+		http.SetCookie(w, &http.Cookie{Name: SessionCookie, Value: id, Path: "/", HttpOnly: true, SameSite: http.SameSiteStrictMode})
+		writeJSON(w, http.StatusOK, map[string]string{"csrf": ss.csrf, "capability": can.String()})
+	case http.MethodGet:
+		_, ss := s.current(r)
+		if ss == nil {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "not signed in"})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"csrf": ss.csrf, "capability": ss.can.String()})
+	case http.MethodDelete:
+		id, ss := s.current(r)
+		if ss == nil || !equal(r.Header.Get(CSRFHeader), ss.csrf) {
+			writeJSON(w, http.StatusForbidden, map[string]string{"error": "invalid csrf token"})
+			return
+		}
+		s.sessions.end(id)
+		http.SetCookie(w, &http.Cookie{Name: SessionCookie, Path: "/", MaxAge: -1, HttpOnly: true, SameSite: http.SameSiteStrictMode})
+		writeJSON(w, http.StatusOK, map[string]string{})
+	default:
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "use GET, POST or DELETE"})
+	}
+}
+// current returns the session of the request's cookie, nil if there is none or it expired
+func (s *server) current(r *http.Request) (string, *session) {
+	c, err := r.Cookie(SessionCookie)
+	if err != nil {
+		return "", nil
+	}
+	return c.Value, s.sessions.get(c.Value)
+}
+// api checks the call with allowed and writes the result of the handler as JSON, or its error
+func (s *server) api(method string, need capability, handler func(r *http.Request) (interface{}, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.allowed(w, r, method, need) {
+			return
+		}
+		result, err := handler(r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, result)
+	}
+}
+// allowed checks the session, capability and method of a call, writing the refusal if it is not allowed. Calls other than GET must carry the session's csrf token, which other sites can't read.
+func (s *server) allowed(w http.ResponseWriter, r *http.Request, method string, need capability) bool {
+	_, ss := s.current(r)
+	switch {
+	case ss == nil:
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "not signed in"})
+	case r.Method != method:
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "use " + method})
+	case method != http.MethodGet && (!sameOrigin(r) || !equal(r.Header.Get(CSRFHeader), ss.csrf)):
+		writeJSON(w, http.StatusForbidden, map[string]string{"error": "invalid csrf token"})
+	case ss.can < need:
+		writeJSON(w, http.StatusForbidden, map[string]string{"error": "this session can only view the wallet"})
+	default:
+		return true
+	}
+	return false
+}
+// writeError writes an error with status 502 as the RPC servers are the ones that failed, unless it is a requestError
+func writeError(w http.ResponseWriter, err error) {
+	code := http.StatusBadGateway
+	if _, ok := err.(requestError); ok {
+		code = http.StatusBadRequest
+	}
+	writeJSON(w, code, map[string]string{"error": err.Error()})
+}
+// sameOrigin is false if the request says it comes from a page on another host. Requests without an Origin are from the page itself or not from a browser.
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && u.Host == r.Host
+}
+// equal compares tokens in constant time, an empty token never matching
+func equal(a, b string) bool {
+	return a != "" && b != "" && subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log <- cl.Debug{"writing response failed:", err}
+	}
+}
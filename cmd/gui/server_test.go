@@ -0,0 +1,243 @@
+package gui
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"git.parallelcoin.io/dev/9/cmd/def"
+	"git.parallelcoin.io/dev/9/cmd/nine"
+	"git.parallelcoin.io/dev/9/pkg/ifc"
+	rpc "git.parallelcoin.io/dev/9/pkg/rpc/json"
+)
+// call makes a request to the server with the session cookie and csrf token if given, returning the status and decoded body
+func call(t *testing.T, h http.Handler, method, path, body string, cookie *http.Cookie, csrf string) (int, map[string]string, *httptest.ResponseRecorder) {
+	t.Helper()
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	if cookie != nil {
+		r.AddCookie(cookie)
+	}
+	if csrf != "" {
+		r.Header.Set(CSRFHeader, csrf)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	var v map[string]string
+	json.Unmarshal(w.Body.Bytes(), &v)
+	return w.Code, v, w
+}
+// signIn posts the launch token and returns the session cookie and csrf token
+func signIn(t *testing.T, h http.Handler, token string) (*http.Cookie, string) {
+	t.Helper()
+	code, v, w := call(t, h, "POST", "/api/session", `{"token":"`+token+`"}`, nil, "")
+	if code != http.StatusOK {
+		t.Fatalf("sign in got %d %v", code, v)
+	}
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != SessionCookie || !cookies[0].HttpOnly {
+		t.Fatalf("sign in set cookies %v, want one http only session cookie", cookies)
+	}
+	return cookies[0], v["csrf"]
+}
+func TestSessions(t *testing.T) {
+	s := newServer(&def.App{Config: &nine.Config{}}, false)
+	h := s.routes()
+	if code, _, _ := call(t, h, "POST", "/api/session", `{"token":"wrong"}`, nil, ""); code != http.StatusForbidden {
+		t.Errorf("sign in with a wrong token got %d", code)
+	}
+	if code, _, _ := call(t, h, "POST", "/api/session", `{"token":""}`, nil, ""); code != http.StatusForbidden {
+		t.Errorf("sign in with no token got %d", code)
+	}
+	if code, _, _ := call(t, h, "POST", "/api/send", `{}`, nil, ""); code != http.StatusUnauthorized {
+		t.Errorf("send without a session got %d", code)
+	}
+	view, viewCSRF := signIn(t, h, s.viewToken)
+	if _, v, _ := call(t, h, "GET", "/api/session", "", view, ""); v["capability"] != "view" || v["csrf"] != viewCSRF {
+		t.Errorf("view session is %v", v)
+	}
+	if code, _, _ := call(t, h, "POST", "/api/send", `{}`, view, viewCSRF); code != http.StatusForbidden {
+		t.Errorf("send with a view session got %d", code)
+	}
+	spend, spendCSRF := signIn(t, h, s.spendToken)
+	if code, _, _ := call(t, h, "POST", "/api/send", `{}`, spend, ""); code != http.StatusForbidden {
+		t.Errorf("send without the csrf token got %d", code)
+	}
+	if code, _, _ := call(t, h, "POST", "/api/send", `{}`, spend, viewCSRF); code != http.StatusForbidden {
+		t.Errorf("send with another session's csrf token got %d", code)
+	}
+	// the payment is checked before any RPC call is made
+	if code, v, _ := call(t, h, "POST", "/api/send", `{}`, spend, spendCSRF); code != http.StatusBadRequest {
+		t.Errorf("send of an empty payment got %d %v", code, v)
+	}
+	if code, _, _ := call(t, h, "GET", "/api/send", "", spend, spendCSRF); code != http.StatusMethodNotAllowed {
+		t.Errorf("send with GET got %d", code)
+	}
+	if code, _, _ := call(t, h, "DELETE", "/api/session", "", spend, spendCSRF); code != http.StatusOK {
+		t.Errorf("sign out got %d", code)
+	}
+	if code, _, _ := call(t, h, "GET", "/api/session", "", spend, ""); code != http.StatusUnauthorized {
+		t.Errorf("signed out session got %d", code)
+	}
+}
+func TestViewOnly(t *testing.T) {
+	s := newServer(&def.App{Config: &nine.Config{}}, true)
+	if s.spendToken != "" {
+		t.Fatal("view only server has a spend token")
+	}
+	if code, _, _ := call(t, s.routes(), "POST", "/api/session", `{"token":""}`, nil, ""); code != http.StatusForbidden {
+		t.Errorf("sign in with the empty spend token got %d", code)
+	}
+}
+// testApp is an app with a data directory and a number item only taking values up to 10
+func testApp(dir string) *def.App {
+	row := func(typ string, value, dflt interface{}, validate func(*def.Row, interface{}) bool) *def.Row {
+		return &def.Row{Type: typ, Value: ifc.NewIface().Put(value), Default: ifc.NewIface().Put(dflt), Validate: validate}
+	}
+	return &def.App{Config: &nine.Config{}, Cats: def.Cats{
+		"app": {"datadir": row("string", dir, dir, nil)},
+		"test": {"number": row("int", 5, 5, func(r *def.Row, in interface{}) bool {
+			n, err := strconv.Atoi(*in.(*string))
+			if err != nil || n > 10 {
+				return false
+			}
+			r.Value.Put(n)
+			return true
+		})},
+	}}
+}
+func TestConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gui")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ap := testApp(dir)
+	s := newServer(ap, false)
+	h := s.routes()
+	view, viewCSRF := signIn(t, h, s.viewToken)
+	if code, _, _ := call(t, h, "GET", "/api/config", "", view, viewCSRF); code != http.StatusForbidden {
+		t.Errorf("config with a view session got %d", code)
+	}
+	spend, csrf := signIn(t, h, s.spendToken)
+	if code, v, _ := call(t, h, "POST", "/api/config", `{"cat":"test","item":"number","value":"11"}`, spend, csrf); code != http.StatusBadRequest {
+		t.Errorf("invalid setting got %d %v", code, v)
+	}
+	if code, v, _ := call(t, h, "POST", "/api/config", `{"cat":"test","item":"missing","value":"1"}`, spend, csrf); code != http.StatusBadRequest {
+		t.Errorf("setting of a missing item got %d %v", code, v)
+	}
+	code, v, _ := call(t, h, "POST", "/api/config", `{"cat":"test","item":"number","value":"7"}`, spend, csrf)
+	if code != http.StatusOK || ap.Cats["test"]["number"].Value.Get() != 7 {
+		t.Fatalf("setting got %d %v, value %v", code, v, ap.Cats["test"]["number"].Value.Get())
+	}
+	saved, err := ioutil.ReadFile(filepath.Join(dir, "config"))
+	if err != nil || !strings.Contains(string(saved), `"value": 7`) {
+		t.Errorf("config file %s %v does not have the new value", saved, err)
+	}
+	_, _, w := call(t, h, "GET", "/api/config", "", spend, csrf)
+	var schema def.Schema
+	if err := json.Unmarshal(w.Body.Bytes(), &schema); err != nil || schema["test"]["number"].Text != "7" || schema["test"]["number"].DefaultText != "5" {
+		t.Errorf("schema %s %v", w.Body.Bytes(), err)
+	}
+	if code, v, _ := call(t, h, "POST", "/api/config", `{"cat":"test","item":"number","reset":true}`, spend, csrf); code != http.StatusOK || ap.Cats["test"]["number"].Value.Get() != 5 {
+		t.Errorf("reset got %d %v", code, v)
+	}
+}
+func TestCrossOrigin(t *testing.T) {
+	s := newServer(&def.App{Config: &nine.Config{}}, false)
+	h := s.routes()
+	cookie, csrf := signIn(t, h, s.spendToken)
+	r := httptest.NewRequest("POST", "http://127.0.0.1:11049/api/send", strings.NewReader(`{}`))
+	r.AddCookie(cookie)
+	r.Header.Set(CSRFHeader, csrf)
+	r.Header.Set("Origin", "http://evil.example")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("cross origin send got %d", w.Code)
+	}
+}
+func TestPaymentRequest(t *testing.T) {
+	s := newServer(&def.App{Config: &nine.Config{}}, true)
+	h := s.routes()
+	view, csrf := signIn(t, h, s.viewToken)
+	if code, _, _ := call(t, h, "GET", "/api/request?address=not-an-address", "", view, csrf); code != http.StatusBadRequest {
+		t.Errorf("request for an invalid address got %d", code)
+	}
+	if code, _, _ := call(t, h, "GET", "/api/request?address=aT8WpZr3UJoTzaGW2NqdPq9kZRXNQ4pPYU&amount=-1", "", view, csrf); code != http.StatusBadRequest {
+		t.Errorf("request for a negative amount got %d", code)
+	}
+	code, v, _ := call(t, h, "GET", "/api/request?address=aT8WpZr3UJoTzaGW2NqdPq9kZRXNQ4pPYU&amount=2.5&label=shop", "", view, csrf)
+	if code != http.StatusOK || v["uri"] != "parallelcoin:aT8WpZr3UJoTzaGW2NqdPq9kZRXNQ4pPYU?amount=2.5&label=shop" {
+		t.Fatalf("request got %d %v", code, v["uri"])
+	}
+	if !strings.HasPrefix(v["qr"], "<svg") || !strings.HasPrefix(v["png"], "data:image/png;base64,") {
+		t.Errorf("request has no QR code images")
+	}
+}
+func TestHistory(t *testing.T) {
+	s := newServer(&def.App{Config: &nine.Config{}}, true)
+	h := s.routes()
+	if code, _, _ := call(t, h, "GET", "/api/history.csv", "", nil, ""); code != http.StatusUnauthorized {
+		t.Errorf("export without a session got %d", code)
+	}
+	view, csrf := signIn(t, h, s.viewToken)
+	for _, q := range []string{"from=yesterday", "min=-1", "max=lots", "address=not-an-address"} {
+		if code, v, _ := call(t, h, "GET", "/api/history?"+q, "", view, csrf); code != http.StatusBadRequest {
+			t.Errorf("history with %s got %d %v", q, code, v)
+		}
+		if code, _, _ := call(t, h, "GET", "/api/history.csv?"+q, "", view, csrf); code != http.StatusBadRequest {
+			t.Errorf("export with %s got %d", q, code)
+		}
+	}
+	fee := -0.0001
+	var b strings.Builder
+	err := writeCSV(&b, []rpc.ListTransactionsResult{
+		{Time: 1500000000, Category: "send", Amount: -1.5, Fee: &fee, Confirmations: 3, Address: "aT8WpZr3UJoTzaGW2NqdPq9kZRXNQ4pPYU", TxID: "ab", Vout: 1},
+		{Time: 1500000060, Category: "receive", Amount: 2, Account: "shop, online", TxID: "cd"},
+	})
+	want := "time,category,amount,fee,confirmations,address,label,transaction,output\n" +
+		"2017-07-14T02:40:00Z,send,-1.50000000,-0.00010000,3,aT8WpZr3UJoTzaGW2NqdPq9kZRXNQ4pPYU,,ab,1\n" +
+		"2017-07-14T02:41:00Z,receive,2.00000000,,0,,\"shop, online\",cd,0\n"
+	if err != nil || b.String() != want {
+		t.Errorf("csv is\n%s%v\nwant\n%s", b.String(), err, want)
+	}
+}
+func TestLightMode(t *testing.T) {
+	light := true
+	s := newServer(&def.App{Config: &nine.Config{WalletSPV: &light}}, true)
+	h := s.routes()
+	view, csrf := signIn(t, h, s.viewToken)
+	for _, path := range []string{"/api/blocks", "/api/block?id=1", "/api/tx?id=" + strings.Repeat("ab", 32), "/api/address?id=aT8WpZr3UJoTzaGW2NqdPq9kZRXNQ4pPYU"} {
+		if code, v, _ := call(t, h, "GET", path, "", view, csrf); code != http.StatusBadRequest || v["error"] != string(errLight) {
+			t.Errorf("%s in light mode got %d %v", path, code, v)
+		}
+	}
+}
+func TestAmountFormat(t *testing.T) {
+	locale, unit := "de_DE", "mDUO"
+	s := newServer(&def.App{Config: &nine.Config{Locale: &locale, Unit: &unit}}, false)
+	h := s.routes()
+	spend, csrf := signIn(t, h, s.spendToken)
+	_, _, w := call(t, h, "GET", "/api/format", "", spend, csrf)
+	var f amountFormat
+	if err := json.Unmarshal(w.Body.Bytes(), &f); err != nil || f != (amountFormat{"mDUO", ".", ",", 5}) {
+		t.Errorf("format is %s %v", w.Body.Bytes(), err)
+	}
+	code, v, _ := call(t, h, "GET", "/api/request?address=aT8WpZr3UJoTzaGW2NqdPq9kZRXNQ4pPYU&amount=1.500,5", "", spend, csrf)
+	if code != http.StatusOK || v["uri"] != "parallelcoin:aT8WpZr3UJoTzaGW2NqdPq9kZRXNQ4pPYU?amount=1.5005" {
+		t.Errorf("request for 1.500,5 mDUO got %d %v", code, v["uri"])
+	}
+	for _, amount := range []string{"1.5", "1,000001", "0"} {
+		body := `{"address":"aT8WpZr3UJoTzaGW2NqdPq9kZRXNQ4pPYU","amount":"` + amount + `","passphrase":"x"}`
+		if code, v, _ := call(t, h, "POST", "/api/send", body, spend, csrf); code != http.StatusBadRequest {
+			t.Errorf("send of %s mDUO got %d %v", amount, code, v)
+		}
+	}
+	if code, _, _ := call(t, h, "GET", "/api/history?min=1.5", "", spend, csrf); code != http.StatusBadRequest {
+		t.Errorf("history with an amount written for another locale got %d", code)
+	}
+}
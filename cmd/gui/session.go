@@ -0,0 +1,75 @@
+package gui
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+// SessionIdle is how long a session lasts without a call from its page
+const SessionIdle = 30 * time.Minute
+// capability is what a session may do with the wallet
+type capability int
+const (
+	// capView sees the balance, history and node and makes receiving addresses
+	capView capability = iota
+	// capSpend can also send payments
+	capSpend
+)
+func (c capability) String() string {
+	if c == capSpend {
+		return "spend"
+	}
+	return "view"
+}
+// session is a page signed in with a launch token. The id is kept in a cookie the page's scripts can't read, the csrf token is given to the page and must come back in a header with every call that changes something.
+type session struct {
+	csrf string
+	can  capability
+	seen time.Time
+}
+// sessions are the signed in pages, forgotten after SessionIdle without a call
+type sessions struct {
+	sync.Mutex
+	m map[string]*session
+}
+// newToken makes a random hex token that can't be guessed
+func newToken() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic("no randomness for tokens: " + err.Error())
+	}
+	return hex.EncodeToString(b)
+}
+// start makes a new session with the capability, returning its id
+func (s *sessions) start(can capability) (id string, ss *session) {
+	s.Lock()
+	defer s.Unlock()
+	if s.m == nil {
+		s.m = make(map[string]*session)
+	}
+	id, ss = newToken(), &session{csrf: newToken(), can: can, seen: time.Now()}
+	s.m[id] = ss
+	return
+}
+// get returns the session with the id if it has not expired, marking it as used. Expired sessions are dropped as they are found.
+func (s *sessions) get(id string) *session {
+	s.Lock()
+	defer s.Unlock()
+	now := time.Now()
+	for i, ss := range s.m {
+		if now.Sub(ss.seen) > SessionIdle {
+			delete(s.m, i)
+		}
+	}
+	ss := s.m[id]
+	if ss != nil {
+		ss.seen = now
+	}
+	return ss
+}
+// end forgets the session with the id
+func (s *sessions) end(id string) {
+	s.Lock()
+	defer s.Unlock()
+	delete(s.m, id)
+}
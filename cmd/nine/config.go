@@ -4,6 +4,7 @@ import (
 	"strings"
 	"time"
 	chaincfg "git.parallelcoin.io/dev/9/pkg/chain/config"
+	chainhash "git.parallelcoin.io/dev/9/pkg/chain/hash"
 	"git.parallelcoin.io/dev/9/pkg/util"
 )
 type Mapstringstring map[string]*string
@@ -61,21 +62,70 @@ type Config struct {
 	SimNet                   *bool
 	AddCheckpoints           *[]string
 	DisableCheckpoints       *bool
+	AssumeValid              *string
+	FullVerify               *bool
+	DumpInvalid              *bool
+	AssumeUtxo               *string
+	LoadUtxoSet              *string
+	UtxoCache                *int
 	DbType                   *string
 	Profile                  *int
 	CPUProfile               *string
 	Upnp                     *bool
+	Theme                    *string
+	Locale                   *string
+	Unit                     *string
+	MemLimit                 *int
+	DBCache                  *int
+	GCPercent                *int
+	Ballast                  *int
+	LazyParse                *bool
 	MinRelayTxFee            *float64
 	FreeTxRelayLimit         *float64
+	DustRelayFee             *float64
 	NoRelayPriority          *bool
+	FeeRateOnly              *bool
 	TrickleInterval          *time.Duration
 	MaxOrphanTxs             *int
+	MaxOrphanTxsPerPeer      *int
+	OrphanExpiry             *time.Duration
+	MempoolReplacement       *bool
+	MempoolExpiry            *int
+	MaxMempool               *int
+	MaxAncestors             *int
+	MaxAncestorSize          *int
+	MaxDescendants           *int
+	MaxDescendantSize        *int
+	MempoolWebhooks          *[]string
+	MempoolWebhookSecret     *string
+	MempoolWebhookRetries    *int
+	Telemetry                *bool
+	TelemetryEndpoint        *string
+	TelemetryInterval        *time.Duration
+	Update                   *bool
+	UpdateURL                *string
+	UpdateInterval           *time.Duration
 	Algo                     *string
 	Generate                 *bool
 	GenThreads               *int
 	MiningAddrs              *[]string
-	MinerListener            *string
+	MiningRotation           *string
+	MinerListener            *[]string
 	MinerPass                *string
+	MinerAPIKey              *string
+	MinerAPIKeys             *[]string
+	MinerEncrypt             *bool
+	MinerControllerKeys      *[]string
+	MinerMulticast           *string
+	MinerAdvertise           *bool
+	MinerBias                *float64
+	MinerSwitch              *time.Duration
+	MinerCores               *[]string
+	MinerNice                *int
+	CoinbaseTag              *string
+	StratumListeners         *[]string
+	StratumDifficulty        *[]string
+	StratumShareTime         *time.Duration
 	BlockMinSize             *int
 	BlockMaxSize             *int
 	BlockMinWeight           *int
@@ -95,6 +145,10 @@ type Config struct {
 	NoInitialLoad            *bool
 	WalletPass               *string
 	WalletServer             *string
+	WalletGUI                *string
+	WalletGUIViewOnly        *bool
+	WalletSPV                *bool
+	WalletRBF                *bool
 	CAFile                   *string
 	OneTimeTLSKey            *bool
 	ServerTLS                *bool
@@ -111,9 +165,15 @@ type StateConfig struct {
 	Oniondial           func(string, string, time.Duration) (net.Conn, error)
 	Dial                func(string, string, time.Duration) (net.Conn, error)
 	AddedCheckpoints    []chaincfg.Checkpoint
+	AssumeValid         *chainhash.Hash
+	AssumeUtxo          *chainhash.Hash
+	UtxoCacheSize       uint64
 	ActiveMiningAddrs   []util.Address
+	ActiveMiningWeights []float64
 	ActiveMinerKey      []byte
+	ActiveMinerCores    []int
 	ActiveMinRelayTxFee util.Amount
+	ActiveDustRelayFee  util.Amount
 	ActiveWhitelists    []*net.IPNet
 	DropAddrIndex       bool
 	DropTxIndex         bool
@@ -0,0 +1,46 @@
+package node
+import (
+	"fmt"
+	"io"
+	"time"
+	blockchain "git.parallelcoin.io/dev/9/pkg/chain"
+	"git.parallelcoin.io/dev/9/pkg/util/interrupt"
+)
+// BenchVerify opens the block database of the node without starting it, validates the blocks of its chain from height from to height to again and writes how long each stage of validating them took to w, to compare the script validation on one goroutine, on many and with the signature cache
+func BenchVerify(
+	w io.Writer, from, to int32) error {
+	db, err := loadBlockDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	chain, err := blockchain.New(&blockchain.Config{
+		DB:          db,
+		Interrupt:   interrupt.ShutdownRequestChan,
+		ChainParams: ActiveNetParams.Params,
+		TimeSource:  blockchain.NewMedianTime(),
+	})
+	if err != nil {
+		return err
+	}
+	if to == 0 {
+		to = chain.BestSnapshot().Height
+	}
+	bench, err := chain.BenchVerify(from, to, uint(*Cfg.SigCacheMaxSize), interrupt.ShutdownRequestChan)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "verified %d blocks from height %d to %d of the %s chain with %d transactions spending %d inputs\n",
+		bench.Blocks, from, to, NetName(ActiveNetParams), bench.Transactions, bench.Inputs)
+	fmt.Fprintf(w, "%-34s %14s %14s %14s\n", "stage", "total", "per block", "per input")
+	for _, stage := range blockchain.BenchStages {
+		elapsed := bench.Stages[stage]
+		perInput := "-"
+		if bench.Inputs > 0 {
+			perInput = (elapsed / time.Duration(bench.Inputs)).String()
+		}
+		fmt.Fprintf(w, "%-34s %14v %14v %14s\n",
+			stage, elapsed.Round(time.Microsecond), elapsed/time.Duration(bench.Blocks), perInput)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package node
+import (
+	js "encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"git.parallelcoin.io/dev/9/cmd/node/mempool"
+	blockchain "git.parallelcoin.io/dev/9/pkg/chain"
+	chainhash "git.parallelcoin.io/dev/9/pkg/chain/hash"
+	"git.parallelcoin.io/dev/9/pkg/chain/wire"
+	"git.parallelcoin.io/dev/9/pkg/rpc/json"
+	"git.parallelcoin.io/dev/9/pkg/util"
+	cl "git.parallelcoin.io/dev/9/pkg/util/cl"
+	walletchain "git.parallelcoin.io/dev/9/pkg/wallet/chain"
+)
+// inProcessChain is the chain of the server started by Main, for a wallet running in the same process.
+var inProcessChain *chainBackend
+// InProcessChain returns the chain of the node started by Main for a wallet in the same process to use without RPC, or nil if the node has not started.
+func InProcessChain() walletchain.Backend {
+	if inProcessChain == nil {
+		return nil
+	}
+	return inProcessChain
+}
+// chainBackend gives a wallet in the same process the chain, mempool and RPC commands of the server directly.
+type chainBackend struct {
+	s             *server
+	txMtx         sync.Mutex
+	txSubscribers []func(*wire.MsgTx)
+}
+// Enforce chainBackend satisfies the walletchain.Backend interface.
+var _ walletchain.Backend = (*chainBackend)(nil)
+// newChainBackend returns the backend of the server.
+func newChainBackend(
+	s *server) *chainBackend {
+	return &chainBackend{s: s}
+}
+// BestBlock returns the hash and height of the tip of the main chain.
+func (b *chainBackend) BestBlock() (*chainhash.Hash, int32) {
+	best := b.s.chain.BestSnapshot()
+	hash := best.Hash
+	return &hash, best.Height
+}
+// BlockHash returns the hash of the block at the height in the main chain.
+func (b *chainBackend) BlockHash(height int32) (*chainhash.Hash, error) {
+	return b.s.chain.BlockHashByHeight(height)
+}
+// BlockHeight returns the height of the block with the hash in the main chain.
+func (b *chainBackend) BlockHeight(hash *chainhash.Hash) (int32, error) {
+	return b.s.chain.BlockHeightByHash(hash)
+}
+// Block returns the block with the hash.
+func (b *chainBackend) Block(hash *chainhash.Hash) (*wire.MsgBlock, error) {
+	block, err := b.s.chain.BlockByHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	return block.MsgBlock(), nil
+}
+// BlockHeader returns the header of the block with the hash.
+func (b *chainBackend) BlockHeader(hash *chainhash.Hash) (*wire.BlockHeader, error) {
+	header, err := b.s.chain.HeaderByHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	return &header, nil
+}
+// SendTransaction adds the transaction to the mempool and relays it, as sendrawtransaction does. High fees are allowed like there, so allowHighFees is ignored.
+func (b *chainBackend) SendTransaction(msgTx *wire.MsgTx, allowHighFees bool) (*chainhash.Hash, error) {
+	tx := util.NewTx(msgTx)
+	acceptedTxs, err := b.s.txMemPool.ProcessTransaction(tx, false, false, 0)
+	if err != nil {
+		if _, ok := err.(mempool.RuleError); ok {
+			log <- cl.Debugf{"rejected transaction %v: %v", tx.Hash(), err}
+		} else {
+			log <- cl.Errorf{"failed to process transaction %v: %v", tx.Hash(), err}
+		}
+		return nil, err
+	}
+	if len(acceptedTxs) == 0 || !acceptedTxs[0].Tx.Hash().IsEqual(tx.Hash()) {
+		b.s.txMemPool.RemoveTransaction(tx, true)
+		return nil, fmt.Errorf("transaction %v is not in accepted list", tx.Hash())
+	}
+	b.s.AnnounceNewTransactions(acceptedTxs)
+	// Keep track of the transaction so it is rebroadcast until it makes its way into a block.
+	b.s.AddRebroadcastInventory(wire.NewInvVect(wire.InvTypeTx, tx.Hash()), acceptedTxs[0])
+	return tx.Hash(), nil
+}
+// SubscribeBlocks calls the callback with each block connected to or disconnected from the main chain.
+func (b *chainBackend) SubscribeBlocks(callback func(block *wire.MsgBlock, height int32, connected bool)) {
+	b.s.chain.Subscribe(func(n *blockchain.Notification) {
+		if n.Type != blockchain.NTBlockConnected && n.Type != blockchain.NTBlockDisconnected {
+			return
+		}
+		if block, ok := n.Data.(*util.Block); ok {
+			callback(block.MsgBlock(), block.Height(), n.Type == blockchain.NTBlockConnected)
+		}
+	})
+}
+// SubscribeTransactions calls the callback with each transaction accepted to the mempool.
+func (b *chainBackend) SubscribeTransactions(callback func(tx *wire.MsgTx)) {
+	b.txMtx.Lock()
+	b.txSubscribers = append(b.txSubscribers, callback)
+	b.txMtx.Unlock()
+}
+// notifyEvent passes the transactions accepted to the mempool to the subscribers. It is called by the mempool with its lock held.
+func (b *chainBackend) notifyEvent(ev *mempool.Event) {
+	if ev.Type != mempool.EventAccepted {
+		return
+	}
+	b.txMtx.Lock()
+	subscribers := b.txSubscribers
+	b.txMtx.Unlock()
+	for _, callback := range subscribers {
+		callback(ev.Tx.MsgTx())
+	}
+}
+// RawRequest runs a JSON-RPC command with the handlers of the RPC server, without its listeners or authentication, and returns the marshalled result.
+func (b *chainBackend) RawRequest(method string, params []js.RawMessage) (js.RawMessage, error) {
+	if len(b.s.rpcServers) < 1 {
+		return nil, errors.New("there is no rpc server to run commands with")
+	}
+	parsedCmd := parseCmd(&json.Request{Jsonrpc: "1.0", Method: method, Params: params, ID: 1})
+	if parsedCmd.err != nil {
+		return nil, parsedCmd.err
+	}
+	result, err := b.s.rpcServers[0].standardCmdResult(parsedCmd, b.s.quit)
+	if err != nil {
+		return nil, err
+	}
+	return js.Marshal(result)
+}
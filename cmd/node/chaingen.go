@@ -0,0 +1,50 @@
+package node
+import (
+	"fmt"
+	"io"
+	blockchain "git.parallelcoin.io/dev/9/pkg/chain"
+	"git.parallelcoin.io/dev/9/pkg/chain/chaingen"
+	chainhash "git.parallelcoin.io/dev/9/pkg/chain/hash"
+	txscript "git.parallelcoin.io/dev/9/pkg/chain/tx/script"
+	"git.parallelcoin.io/dev/9/pkg/chain/wire"
+	"git.parallelcoin.io/dev/9/pkg/util"
+	"git.parallelcoin.io/dev/9/pkg/util/interrupt"
+)
+// ChainGenSeed is the seed of the key the blocks GenerateChain builds pay to, so the wallet of every test can import the same key
+const ChainGenSeed = "chaingen"
+// GenerateChain opens the block database of the node without starting it and builds the blocks of the scenario into it, writing the height and hash of each to w. The chain must have only its genesis block and not be on mainnet. It returns the key the blocks pay to in wallet import format. Indexes are brought up to date when the node next starts
+func GenerateChain(
+	w io.Writer, s chaingen.Scenario) (*util.WIF, error) {
+	if ActiveNetParams.Net == wire.MainNet {
+		return nil, fmt.Errorf("chains can only be generated on test networks")
+	}
+	db, err := loadBlockDB()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	chain, err := blockchain.New(&blockchain.Config{
+		DB:          db,
+		Interrupt:   interrupt.ShutdownRequestChan,
+		ChainParams: ActiveNetParams.Params,
+		TimeSource:  blockchain.NewMedianTime(),
+		SigCache:    txscript.NewSigCache(1000),
+	})
+	if err != nil {
+		return nil, err
+	}
+	g, err := chaingen.New(chain, ActiveNetParams.Params, []byte(ChainGenSeed))
+	if err != nil {
+		return nil, err
+	}
+	g.OnBlock = func(hash *chainhash.Hash, height int32) {
+		fmt.Fprintf(w, "%d:%s\n", height, hash)
+	}
+	if err = g.Run(s, interrupt.ShutdownRequestChan); err != nil {
+		return nil, err
+	}
+	if err = chain.FlushUtxoCache(); err != nil {
+		return nil, err
+	}
+	return util.NewWIF(g.Key(), ActiveNetParams.Params, true)
+}
@@ -0,0 +1,46 @@
+package node
+import (
+	"fmt"
+	"io"
+	blockchain "git.parallelcoin.io/dev/9/pkg/chain"
+	chaincfg "git.parallelcoin.io/dev/9/pkg/chain/config"
+	"git.parallelcoin.io/dev/9/pkg/util/interrupt"
+)
+// DefaultCheckpointInterval is the number of blocks between the checkpoints GenCheckpoints picks when no interval is given
+const DefaultCheckpointInterval = 10000
+// CheckpointForkDistance is how many blocks away from any known fork a generated checkpoint must be, so that it is not on a part of the chain peers may not agree on
+const CheckpointForkDistance = 100
+// GenCheckpoints opens the block database of the node without starting it, picks checkpoints about interval blocks apart after the latest one from the chain the node has validated, and writes them to w in the chain.addcheckpoints format, one <height>:<hash> per line
+func GenCheckpoints(
+	w io.Writer, interval int32) error {
+	db, err := loadBlockDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	var checkpoints []chaincfg.Checkpoint
+	if !*Cfg.DisableCheckpoints {
+		checkpoints = mergeCheckpoints(ActiveNetParams.Params.Checkpoints, StateCfg.AddedCheckpoints)
+	}
+	chain, err := blockchain.New(&blockchain.Config{
+		DB:          db,
+		Interrupt:   interrupt.ShutdownRequestChan,
+		ChainParams: ActiveNetParams.Params,
+		Checkpoints: checkpoints,
+		TimeSource:  blockchain.NewMedianTime(),
+	})
+	if err != nil {
+		return err
+	}
+	candidates, err := chain.CheckpointCandidates(interval, CheckpointForkDistance)
+	if err != nil {
+		return err
+	}
+	best := chain.BestSnapshot()
+	fmt.Fprintf(w, "# %d checkpoints from the %s chain up to height %d, at least %d blocks deep and %d from any fork\n",
+		len(candidates), NetName(ActiveNetParams), best.Height, blockchain.CheckpointConfirmations, CheckpointForkDistance)
+	for _, c := range candidates {
+		fmt.Fprintf(w, "%d:%s\n", c.Height, c.Hash)
+	}
+	return nil
+}
@@ -5,10 +5,9 @@ import (
 	"encoding/hex"
 	"fmt"
 	"math/big"
-	"math/rand"
+	"sync"
 	"time"
 	blockchain "git.parallelcoin.io/dev/9/pkg/chain"
-	"git.parallelcoin.io/dev/9/pkg/chain/fork"
 	chainhash "git.parallelcoin.io/dev/9/pkg/chain/hash"
 	"git.parallelcoin.io/dev/9/pkg/chain/wire"
 	"git.parallelcoin.io/dev/9/pkg/rpc/json"
@@ -43,6 +42,51 @@ func bigToLEUint256(
 	}
 	return buf
 }
+// getworkMaxBlockInfos is the number of work variations remembered for submissions before the oldest are forgotten.
+const getworkMaxBlockInfos = 1000
+// getworkBlockInfo houses information about how to reconstruct a block given its template and signature script.
+type getworkBlockInfo struct {
+	msgBlock        *wire.MsgBlock
+	signatureScript []byte
+	height          int32
+}
+// getworkState houses state that is used in between multiple RPC invocations to getwork. Every getwork reply is a variation of the current block template with its own extra nonce, so the merkle root of a submitted header identifies the variation to rebuild the full block from.
+type getworkState struct {
+	sync.Mutex
+	lastTxUpdate  time.Time
+	lastGenerated time.Time
+	prevHash      *chainhash.Hash
+	msgBlock      *wire.MsgBlock
+	height        int32
+	extraNonce    uint64
+	blockInfo     map[chainhash.Hash]*getworkBlockInfo
+	order         []chainhash.Hash
+}
+// newGetworkState returns a new instance of a getworkState with all internal fields initialized and ready to use.
+func newGetworkState() *getworkState {
+	return &getworkState{
+		blockInfo: make(map[chainhash.Hash]*getworkBlockInfo),
+	}
+}
+// remember stores the current variation of the block template keyed by its merkle root, forgetting the oldest variation once too many are stored. This function MUST be called with the state locked.
+func (
+	state *getworkState,
+) remember() {
+	merkleRoot := state.msgBlock.Header.MerkleRoot
+	if _, ok := state.blockInfo[merkleRoot]; !ok {
+		state.order = append(state.order, merkleRoot)
+	}
+	state.blockInfo[merkleRoot] = &getworkBlockInfo{
+		msgBlock:        state.msgBlock,
+		signatureScript: state.msgBlock.Transactions[0].TxIn[0].SignatureScript,
+		height:          state.height,
+	}
+	if len(state.order) > getworkMaxBlockInfos {
+		delete(state.blockInfo, state.order[0])
+		state.order = state.order[1:]
+	}
+}
+// handleGetWork implements the getwork command. Without data it returns a block header to solve, derived from the current block template, padded and byte swapped as legacy getwork miners expect it. With data it rebuilds the full block from the submitted header and submits it.
 func handleGetWork(
 	s *rpcServer,
 	cmd interface{},
@@ -52,7 +96,7 @@ func handleGetWork(
 	error,
 ) {
 	c := cmd.(*json.GetWorkCmd)
-	if len(StateCfg.ActiveMiningAddrs) == 0 {
+	if s.Cfg.PayAddrs.Len() == 0 {
 		return nil, &json.RPCError{
 			Code: json.ErrRPCInternal.Code,
 			Message: "No payment addresses specified " +
@@ -74,44 +118,38 @@ func handleGetWork(
 			Message: "Pod is not yet synchronised...",
 		}
 	}
-	state := s.gbtWorkState
+	state := s.getworkState
 	state.Lock()
 	defer state.Unlock()
 	if c.Data != nil {
 		return handleGetWorkSubmission(s, *c.Data)
 	}
-	// Choose a payment address at random.
-	rand.Seed(time.Now().UnixNano())
-	payToAddr := StateCfg.ActiveMiningAddrs[rand.Intn(len(StateCfg.ActiveMiningAddrs))]
-	lastTxUpdate := s.gbtWorkState.lastTxUpdate
-	latestHash := &s.Cfg.Chain.BestSnapshot().Hash
-	generator := s.Cfg.Generator
-	if state.template == nil {
-		var err error
-		state.template, err = generator.NewBlockTemplate(payToAddr, s.Cfg.Algo)
-		if err != nil {
-			return nil, err
+	// Choose the next payment address. It can only be missing if the last one was removed since the check above.
+	payToAddr := s.Cfg.PayAddrs.Next()
+	if payToAddr == nil {
+		return nil, &json.RPCError{
+			Code: json.ErrRPCInternal.Code,
+			Message: "No payment addresses specified " +
+				"via --miningaddr",
 		}
 	}
-	msgBlock := state.template.Block
-	if msgBlock == nil || state.prevHash == nil ||
+	lastTxUpdate := s.Cfg.TxMemPool.LastUpdated()
+	latestHash := &s.Cfg.Chain.BestSnapshot().Hash
+	generator := s.Cfg.Generator
+	// Generate a new block template when a new block has been connected to the main chain, or when the transactions in the memory pool have changed and it has been at least a minute since the last template was generated.
+	if state.msgBlock == nil || state.prevHash == nil ||
 		!state.prevHash.IsEqual(latestHash) ||
 		(state.lastTxUpdate != lastTxUpdate &&
 			time.Now().After(state.lastGenerated.Add(time.Minute))) {
-		/*	Reset the extra nonce and clear all cached template
-			variations if the best block changed. */
+		// Reset the extra nonce and forget all variations of the previous template if the best block changed, as work on them can no longer be submitted.
 		if state.prevHash != nil && !state.prevHash.IsEqual(latestHash) {
-			e := state.updateBlockTemplate(s, false)
-			if e != nil {
-				log <- cl.Warn{"failed to update block template", e}
-			}
+			state.extraNonce = 0
+			state.blockInfo = make(map[chainhash.Hash]*getworkBlockInfo)
+			state.order = nil
 		}
-		/*	Reset the previous best hash the block template was generated
-			against so any errors below cause the next invocation to try
-			again. */
+		// Reset the previous best hash the block template was generated against so any errors below cause the next invocation to try again.
 		state.prevHash = nil
-		var err error
-		state.template, err = generator.NewBlockTemplate(payToAddr, s.Cfg.Algo)
+		template, err := generator.NewBlockTemplate(payToAddr, s.Cfg.Algo)
 		if err != nil {
 			errStr := fmt.Sprintf("Failed to create new block template: %v", err)
 			log <- cl.Err(errStr)
@@ -120,45 +158,47 @@ func handleGetWork(
 				Message: errStr,
 			}
 		}
-		msgBlock = state.template.Block
-		// Update work state to ensure another block template isn't generated until needed.
-		state.template.Block = msgBlock
+		state.msgBlock = template.Block
+		state.height = template.Height
 		state.lastGenerated = time.Now()
 		state.lastTxUpdate = lastTxUpdate
 		state.prevHash = latestHash
 		Log.Dbgc(func() string {
 			return fmt.Sprintf(
-				"generated block template (timestamp %v, target %064x, merkle root %s, signature script %x)",
-				msgBlock.Header.Timestamp,
-				blockchain.CompactToBig(msgBlock.Header.Bits),
-				msgBlock.Header.MerkleRoot,
-				msgBlock.Transactions[0].TxIn[0].SignatureScript,
+				"generated getwork block template (timestamp %v, target %064x, merkle root %s, signature script %x)",
+				state.msgBlock.Header.Timestamp,
+				blockchain.CompactToBig(state.msgBlock.Header.Bits),
+				state.msgBlock.Header.MerkleRoot,
+				state.msgBlock.Transactions[0].TxIn[0].SignatureScript,
 			)
 		})
 	} else {
-		//	At this point, there is a saved block template and a new request for work was made, but either the available transactions haven't change or it hasn't been long enough to trigger a new block template to be generated.
-		// So, update the existing block template and track the variations so each variation can be regenerated if a caller finds an answer and makes a submission against it. Update the time of the block template to the current time while accounting for the median time of the past several blocks per the chain consensus rules.
-		e := generator.UpdateBlockTime(msgBlock)
+		// At this point, there is a saved block template and a new request for work was made, but either the available transactions haven't changed or it hasn't been long enough to trigger a new block template to be generated. So, update the time of the existing block template and increment the extra nonce so every caller gets a different variation to work on.
+		e := generator.UpdateBlockTime(state.msgBlock)
 		if e != nil {
 			log <- cl.Warn{"failed to update block time", e}
 		}
-		// Increment the extra nonce and update the block template with the new value by regenerating the coinbase script and setting the merkle root to the new value.
+		state.extraNonce++
+		e = generator.UpdateExtraNonce(state.msgBlock, state.height, state.extraNonce)
+		if e != nil {
+			errStr := fmt.Sprintf("Failed to update extra nonce: %v", e)
+			log <- cl.Wrn(errStr)
+			return nil, &json.RPCError{
+				Code:    json.ErrRPCInternal.Code,
+				Message: errStr,
+			}
+		}
 		log <- cl.Debugf{
-			"updated block template (timestamp %v, target %064x, merkle root %s, signature script %x)",
-			msgBlock.Header.Timestamp,
-			blockchain.CompactToBig(msgBlock.Header.Bits),
-			msgBlock.Header.MerkleRoot,
-			msgBlock.Transactions[0].TxIn[0].SignatureScript,
+			"updated getwork block template (timestamp %v, target %064x, merkle root %s, signature script %x)",
+			state.msgBlock.Header.Timestamp,
+			blockchain.CompactToBig(state.msgBlock.Header.Bits),
+			state.msgBlock.Header.MerkleRoot,
+			state.msgBlock.Transactions[0].TxIn[0].SignatureScript,
 		}
 	}
-	//	In order to efficiently store the variations of block templates that have been provided to callers, save a pointer to the block as well as the modified signature script keyed by the merkle root.  This information, along with the data that is included in a work submission, is used to rebuild the block before checking the submitted solution.
-	/*
-		coinbaseTx := msgBlock.Transactions[0]
-		state.blockInfo[msgBlock.Header.MerkleRoot] = &workStateBlockInfo{
-			msgBlock:        msgBlock,
-			signatureScript: coinbaseTx.TxIn[0].SignatureScript,
-		}
-	*/
+	// In order to efficiently store the variations of block templates that have been provided to callers, save a pointer to the block as well as the modified signature script keyed by the merkle root.  This information, along with the data that is included in a work submission, is used to rebuild the block before checking the submitted solution.
+	state.remember()
+	msgBlock := state.msgBlock
 	// Serialize the block header into a buffer large enough to hold the the block header and the internal sha256 padding that is added and returned as part of the data below.
 	data := make([]byte, 0, getworkDataLen)
 	buf := bytes.NewBuffer(data)
@@ -196,7 +236,7 @@ func handleGetWork(
 	}
 	return reply, nil
 }
-//	handleGetWorkSubmission is a helper for handleGetWork which deals with the calling submitting work to be verified and processed. This function MUST be called with the RPC workstate locked.
+//	handleGetWorkSubmission is a helper for handleGetWork which deals with the calling submitting work to be verified and processed. The data is either the padded data returned by getwork or just the 80 byte block header, in both cases byte swapped the same way. This function MUST be called with the getwork state locked.
 func handleGetWorkSubmission(
 	s *rpcServer,
 	hexData string,
@@ -216,12 +256,12 @@ func handleGetWorkSubmission(
 				"hexadecimal string (not %q)", hexData),
 		}
 	}
-	if len(data) != getworkDataLen {
+	if len(data) != getworkDataLen && len(data) != wire.MaxBlockHeaderPayload {
 		return false, &json.RPCError{
 			Code: json.ErrRPCInvalidParameter,
 			Message: fmt.Sprintf("argument must be "+
-				"%d bytes (not %d)", getworkDataLen,
-				len(data)),
+				"%d or %d bytes (not %d)", getworkDataLen,
+				wire.MaxBlockHeaderPayload, len(data)),
 		}
 	}
 	// Reverse the data as if it were an array of 32-bit unsigned integers. The fact the getwork request and submission data is reversed in this way is rather odd and likey an artifact of some legacy internal state in the reference implementation, but it is required for compatibility.
@@ -238,36 +278,29 @@ func handleGetWorkSubmission(
 		}
 	}
 	// Look up the full block for the provided data based on the merkle root.  Return false to indicate the solve failed if it's not available.
-	state := s.gbtWorkState
-	if state.template.Block.Header.MerkleRoot.String() == "" {
+	state := s.getworkState
+	blockInfo, ok := state.blockInfo[submittedHeader.MerkleRoot]
+	if !ok {
 		log <- cl.Debug{
-			"Block submitted via getwork has no matching template for merkle root",
+			"block submitted via getwork has no matching template for merkle root",
 			submittedHeader.MerkleRoot,
 		}
 		return false, nil
 	}
 	// Reconstruct the block using the submitted header stored block info.
-	msgBlock := state.template.Block
+	msgBlock := blockInfo.msgBlock
 	block := util.NewBlock(msgBlock)
+	block.SetHeight(blockInfo.height)
 	msgBlock.Header.Timestamp = submittedHeader.Timestamp
 	msgBlock.Header.Nonce = submittedHeader.Nonce
-	msgBlock.Transactions[0].TxIn[0].SignatureScript = state.template.Block.Transactions[0].TxIn[0].SignatureScript
+	msgBlock.Transactions[0].TxIn[0].SignatureScript = blockInfo.signatureScript
 	merkles := blockchain.BuildMerkleTreeStore(block.Transactions(), false)
 	msgBlock.Header.MerkleRoot = *merkles[len(merkles)-1]
 	// Ensure the submitted block hash is less than the target difficulty.
-	pl := fork.GetMinDiff(s.Cfg.Algo, s.Cfg.Chain.BestSnapshot().Height)
-	log <- cl.Info{"powlimit", pl}
-	err = blockchain.CheckProofOfWork(block, pl, s.Cfg.Chain.BestSnapshot().Height)
-	if err != nil {
-		// Anything other than a rule violation is an unexpected error, so return that error as an internal error.
-		if _, ok := err.(blockchain.RuleError); !ok {
-			return nil, &json.RPCError{
-				Code:    json.ErrRPCInternal.Code,
-				Message: fmt.Sprintf("Unexpected error while checking proof of work: %v", err),
-			}
-		}
+	hash := msgBlock.Header.BlockHashWithAlgos(blockInfo.height)
+	if blockchain.HashToBig(&hash).Cmp(blockchain.CompactToBig(msgBlock.Header.Bits)) > 0 {
 		log <- cl.Debug{
-			"block submitted via getwork does not meet the required proof of work:", err,
+			"block submitted via getwork does not meet the required proof of work:", hash,
 		}
 		return false, nil
 	}
@@ -280,8 +313,8 @@ func handleGetWorkSubmission(
 		return false, nil
 	}
 	// Process this block using the same rules as blocks coming from other nodes.  This will in turn relay it to the network like normal.
-	_, isOrphan, err := s.Cfg.Chain.ProcessBlock(block, 0, s.Cfg.Chain.BestSnapshot().Height)
-	if err != nil || isOrphan {
+	isOrphan, err := s.Cfg.SyncMgr.SubmitBlock(block, blockchain.BFNone)
+	if err != nil {
 		// Anything other than a rule violation is an unexpected error, so return that error as an internal error.
 		if _, ok := err.(blockchain.RuleError); !ok {
 			return nil, &json.RPCError{
@@ -292,9 +325,12 @@ func handleGetWorkSubmission(
 		log <- cl.Info{"block submitted via getwork rejected:", err}
 		return false, nil
 	}
+	if isOrphan {
+		log <- cl.Info{"block submitted via getwork is an orphan"}
+		return false, nil
+	}
 	// The block was accepted.
-	blockSha := block.Hash()
-	log <- cl.Info{"block submitted via getwork accepted:", blockSha}
+	log <- cl.Info{"block submitted via getwork accepted:", block.Hash()}
 	return true, nil
 }
 // reverseUint32Array treats the passed bytes as a series of uint32s and reverses the byte order of each uint32.  The passed byte slice must be a multiple of 4 for a correct result.  The passed bytes slice is modified.
@@ -0,0 +1,88 @@
+package node
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	blockchain "git.parallelcoin.io/dev/9/pkg/chain"
+	chaincfg "git.parallelcoin.io/dev/9/pkg/chain/config"
+	chainhash "git.parallelcoin.io/dev/9/pkg/chain/hash"
+	"git.parallelcoin.io/dev/9/pkg/util/cl"
+	"git.parallelcoin.io/dev/9/pkg/util/interrupt"
+)
+// importedHeadersFile is the name of the file in the network directory of the node that ImportHeaders copies checked headers to, for the node to download their blocks when it syncs
+const importedHeadersFile = "headers.dat"
+// importedHeadersPath returns the path of the imported headers file of the node
+func importedHeadersPath() string {
+	return filepath.Join(*Cfg.AppDataDir, NetName(ActiveNetParams), importedHeadersFile)
+}
+// ExportHeaders opens the block database of the node without starting it and writes the headers of its chain to w, for importing on a new node with ImportHeaders
+func ExportHeaders(
+	w io.Writer) (int32, error) {
+	db, err := loadBlockDB()
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+	chain, err := blockchain.New(&blockchain.Config{
+		DB:          db,
+		Interrupt:   interrupt.ShutdownRequestChan,
+		ChainParams: ActiveNetParams.Params,
+		TimeSource:  blockchain.NewMedianTime(),
+	})
+	if err != nil {
+		return 0, err
+	}
+	return chain.ExportHeaders(w)
+}
+// ImportHeaders reads the headers in the file at path, checks their proof of work and that they pass through the checkpoints, and copies them to the network directory of the node, for the node to download the blocks of the chain they form without downloading their headers first. The blocks are still fully validated, apart from those up to the last checkpoint among them, as with headers downloaded from peers
+func ImportHeaders(
+	path string) (int, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	headers, err := blockchain.ReadHeaders(bytes.NewReader(data), ActiveNetParams.Params)
+	if err != nil {
+		return 0, err
+	}
+	var checkpoints []chaincfg.Checkpoint
+	if !*Cfg.DisableCheckpoints {
+		checkpoints = mergeCheckpoints(ActiveNetParams.Params.Checkpoints, StateCfg.AddedCheckpoints)
+	}
+	if err = blockchain.CheckHeaders(headers, checkpoints, interrupt.ShutdownRequestChan); err != nil {
+		return 0, err
+	}
+	dest := importedHeadersPath()
+	if err = os.MkdirAll(filepath.Dir(dest), 0700); err != nil {
+		return 0, err
+	}
+	// The headers are written to a temporary file first so a node never finds a partly written one.
+	tmp := dest + ".tmp"
+	if err = ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return 0, err
+	}
+	return len(headers), os.Rename(tmp, dest)
+}
+// loadImportedHeaders returns the hashes of the blocks of the imported headers file of the node, that at index i being the block at height i+1, or nil when there is none or it can not be read
+func loadImportedHeaders() []chainhash.Hash {
+	f, err := os.Open(importedHeadersPath())
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log <- cl.Warn{"could not open imported headers:", err}
+		}
+		return nil
+	}
+	defer f.Close()
+	headers, err := blockchain.ReadHeaders(f, ActiveNetParams.Params)
+	if err != nil {
+		log <- cl.Warn{"could not read imported headers:", err}
+		return nil
+	}
+	hashes := make([]chainhash.Hash, len(headers))
+	for i := range headers {
+		hashes[i] = headers[i].BlockHash()
+	}
+	return hashes
+}
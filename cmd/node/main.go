@@ -142,10 +142,18 @@ func Main(serverChan chan<- *server, started chan struct{}) (err error) {
 				log <- cl.Warn{"failed to stop server", e}
 			}
 			server.WaitForShutdown()
+			if e := server.chain.FlushUtxoCache(); e != nil {
+				log <- cl.Warn{"failed to flush the utxo cache", e}
+			}
 			log <- cl.Inf("server shutdown complete")
 		},
 	)
+	// Serve the mempool and miner worker metrics alongside the profiling endpoints.
+	if Cfg.Profile != nil {
+		http.Handle(metricsPath, metricsHandler(server.txMemPool, server.minerController))
+	}
 	server.Start()
+	inProcessChain = server.backend
 	if serverChan != nil {
 		serverChan <- server
 	}
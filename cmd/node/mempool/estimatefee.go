@@ -43,6 +43,8 @@ type FeeEstimator struct {
 	cached []SatoshiPerByte
 	// Transactions that have been removed from the bins. This allows us to revert in case of an orphaned block.
 	dropped []*registeredBlock
+	// The decayed confirmation time histogram of all observed transactions that were mined or expired. It is not affected by Rollback.
+	history feeHistory
 }
 
 // FeeEstimatorState represents a saved FeeEstimator that can be restored with data from an earlier session of the program.
@@ -102,7 +104,8 @@ const (
 )
 
 // In case the format for the serialized version of the FeeEstimator changes, we use a version number. If the version number changes, it does not make sense to try to upgrade a previous version to a new version. Instead, just start fee estimation over.
-const estimateFeeSaveVersion = 1
+// Version 2 appends the confirmation time histogram, version 1 states are still restored with an empty history.
+const estimateFeeSaveVersion = 2
 
 var (
 	// EstimateFeeDatabaseKey is the key that we use to store the fee estimator in the database.
@@ -201,6 +204,7 @@ func (
 	// Update the last known height.
 	ef.lastKnownHeight = height
 	ef.numBlocksRegistered++
+	ef.history.decay()
 	// Randomly order txs in block.
 	transactions := make(map[*util.Tx]struct{})
 
@@ -247,6 +251,7 @@ func (
 
 			continue
 		}
+		ef.history.recordConfirmed(o.feeRate, blocksToConfirm)
 		// Make sure we do not replace too many transactions per min.
 
 		if replacementCounts[blocksToConfirm] == int(ef.maxReplacements) {
@@ -277,6 +282,7 @@ func (
 
 		if o.mined == mining.UnminedHeight && height-o.observed >= estimateFeeDepth {
 
+			ef.history.recordUnconfirmed(o.feeRate)
 			delete(ef.observed, hash)
 		}
 	}
@@ -327,6 +333,27 @@ func (
 	return nil
 }
 
+// Resync moves the FeeEstimator to the given chain height without registering the blocks in between, such as after a restart where the saved state is behind or ahead of the chain tip.  Transactions still waiting to be mined and the rollback history are discarded since they can no longer be matched against blocks, while the bins and the confirmation time history are kept so estimates remain available.
+func (
+	ef *FeeEstimator,
+) Resync(
+	height int32) {
+
+	ef.mtx.Lock()
+	defer ef.mtx.Unlock()
+
+	for hash, o := range ef.observed {
+
+		if o.mined == mining.UnminedHeight {
+
+			delete(ef.observed, hash)
+		}
+	}
+	ef.dropped = ef.dropped[:0]
+	ef.cached = nil
+	ef.lastKnownHeight = height
+}
+
 // Save records the current state of the FeeEstimator to a []byte that can be restored later.
 func (
 	ef *FeeEstimator,
@@ -449,6 +476,14 @@ func (
 
 		registered.serialize(w, observed)
 	}
+	// Confirmation time history.
+	e = ef.history.serialize(w)
+
+	if e != nil {
+
+		log <- cl.Warn{"failed to write:", e}
+
+	}
 	// Commit the tx and return.
 	return FeeEstimatorState(w.Bytes())
 }
@@ -784,7 +819,7 @@ func RestoreFeeEstimator(
 		return nil, err
 	}
 
-	if version != estimateFeeSaveVersion {
+	if version != 1 && version != estimateFeeSaveVersion {
 
 		return nil, fmt.Errorf("Incorrect version: expected %d found %d", estimateFeeSaveVersion, version)
 	}
@@ -911,6 +946,17 @@ func RestoreFeeEstimator(
 			return nil, err
 		}
 	}
+	// Read the confirmation time history, which older versions don't have.
+
+	if version >= 2 {
+
+		ef.history, err = deserializeFeeHistory(r)
+
+		if err != nil {
+
+			return nil, err
+		}
+	}
 	return ef, nil
 }
 func deserializeObservedTransaction(
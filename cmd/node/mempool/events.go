@@ -0,0 +1,91 @@
+package mempool
+
+import (
+	"time"
+
+	chainhash "git.parallelcoin.io/dev/9/pkg/chain/hash"
+	"git.parallelcoin.io/dev/9/pkg/util"
+)
+
+// EventType identifies the kind of change to the main pool an Event describes.
+type EventType int
+
+const (
+	// EventAccepted indicates a transaction was accepted into the main pool.
+	EventAccepted EventType = iota
+	// EventReplaced indicates a transaction was removed from the main pool because a conflicting transaction paying a higher fee replaced it.
+	EventReplaced
+	// EventEvicted indicates a transaction was removed from the main pool without being mined, because it expired, the pool was full or it conflicts with a mined transaction.
+	EventEvicted
+	// EventMined indicates a transaction was removed from the main pool because it was included in a block connected to the main chain.
+	EventMined
+)
+
+// eventTypeStrings is a map of event types back to their constant names for pretty printing.
+var eventTypeStrings = map[EventType]string{
+	EventAccepted: "accepted",
+	EventReplaced: "replaced",
+	EventEvicted:  "evicted",
+	EventMined:    "mined",
+}
+
+// String returns the EventType in human-readable form.
+func (
+	t EventType,
+) String() string {
+
+	if s, ok := eventTypeStrings[t]; ok {
+
+		return s
+	}
+	return "unknown"
+}
+
+// Reasons a transaction is evicted from the main pool, carried in the Reason field of an EventEvicted.
+const (
+	EvictExpired   = "expired"
+	EvictSizeLimit = "sizelimit"
+	EvictConflict  = "conflict"
+)
+
+// Event describes a change to the main pool. Events are passed to the NotifyEvent function of the pool configuration.
+type Event struct {
+	// Type is the kind of change.
+	Type EventType
+	// Tx is the transaction the event is about.
+	Tx *util.Tx
+	// Fee is the fee in satoshi the transaction pays.
+	Fee int64
+	// FeePerKB is the fee rate in satoshi per kB the transaction pays.
+	FeePerKB int64
+	// Time is when the event happened.
+	Time time.Time
+	// ReplacedBy is the hash of the replacing transaction of an EventReplaced.
+	ReplacedBy *chainhash.Hash
+	// Reason is one of the Evict constants for an EventEvicted.
+	Reason string
+	// BlockHash is the hash of the block that included the transaction of an EventMined.
+	BlockHash *chainhash.Hash
+}
+
+// notifyEvent passes a copy of the event template for the passed descriptor to the configured NotifyEvent function, if any. This function MUST be called with the mempool lock held (for reads).
+func (
+	mp *TxPool,
+) notifyEvent(
+	ev *Event, txD *TxDesc) {
+
+	if ev == nil || mp.cfg.NotifyEvent == nil {
+
+		return
+	}
+	e := *ev
+	e.Tx = txD.Tx
+	e.Fee = txD.Fee
+	e.FeePerKB = txD.FeePerKB
+
+	if e.Time.IsZero() {
+
+		e.Time = time.Now()
+	}
+	mp.cfg.NotifyEvent(&e)
+}
@@ -0,0 +1,87 @@
+package mempool
+
+import (
+	"testing"
+
+	chaincfg "git.parallelcoin.io/dev/9/pkg/chain/config"
+	chainhash "git.parallelcoin.io/dev/9/pkg/chain/hash"
+)
+
+// TestNotifyEvent ensures transactions entering and leaving the main pool are notified with the right event type.
+func TestNotifyEvent(
+	t *testing.T) {
+
+	t.Parallel()
+	harness, spendableOuts, err := newPoolHarness(&chaincfg.MainNetParams)
+
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	var events []*Event
+	harness.txPool.cfg.NotifyEvent = func(ev *Event) {
+
+		events = append(events, ev)
+	}
+	chainedTxns, err := harness.CreateTxChain(spendableOuts[0], 3)
+
+	if err != nil {
+		t.Fatalf("unable to create transaction chain: %v", err)
+	}
+
+	for _, tx := range chainedTxns {
+		_, err := harness.txPool.ProcessTransaction(tx, false, false, 0)
+
+		if err != nil {
+			t.Fatalf("ProcessTransaction: failed to accept tx: %v", err)
+		}
+	}
+
+	if len(events) != len(chainedTxns) {
+		t.Fatalf("want %d events, got %d", len(chainedTxns), len(events))
+	}
+
+	for i, ev := range events {
+
+		if ev.Type != EventAccepted || ev.Tx != chainedTxns[i] {
+			t.Fatalf("event %d: want %v for tx %d, got %v", i,
+				EventAccepted, i, ev.Type)
+		}
+	}
+	// Mining the first transaction must leave its redeemers in the pool.
+	events = nil
+	blockHash := &chainhash.Hash{0x01}
+	harness.txPool.RemoveMinedTransaction(chainedTxns[0], blockHash)
+
+	if len(events) != 1 || events[0].Type != EventMined ||
+		events[0].Tx != chainedTxns[0] || events[0].BlockHash != blockHash {
+
+		t.Fatalf("unexpected events after mining: %v", events)
+	}
+	testPoolMembership(&testContext{t, harness}, chainedTxns[1], false, true)
+	// A mined transaction double spending the second one evicts it along with its descendant.
+	doubleSpend, err := harness.CreateSignedTx(
+		[]spendableOutput{txOutToSpendableOut(chainedTxns[0], 0)}, 2)
+
+	if err != nil {
+		t.Fatalf("unable to create double spend: %v", err)
+	}
+	events = nil
+	harness.txPool.RemoveDoubleSpends(doubleSpend)
+
+	if len(events) != 2 {
+		t.Fatalf("want 2 events after double spend, got %d", len(events))
+	}
+
+	for _, ev := range events {
+
+		if ev.Type != EventEvicted || ev.Reason != EvictConflict {
+			t.Fatalf("unexpected event %v (%s) for %v", ev.Type,
+				ev.Reason, ev.Tx.Hash())
+		}
+	}
+
+	if harness.txPool.Count() != 0 {
+		t.Fatalf("want empty pool, got %d transactions",
+			harness.txPool.Count())
+	}
+}
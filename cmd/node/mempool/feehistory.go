@@ -0,0 +1,220 @@
+package mempool
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+)
+
+const (
+	// feeHistoryBuckets is the number of fee rate buckets the confirmed transaction history is divided into.
+	feeHistoryBuckets = 40
+	// feeHistorySpacing is the ratio between the lower bounds of two adjacent fee rate buckets. The first bucket holds everything below one satoshi per byte and the last one is open ended.
+	feeHistorySpacing = 1.25
+	// feeHistoryDecay is the factor all the history counters are multiplied by every time a block is registered, so old data gradually loses weight and the half life is roughly 350 blocks.
+	feeHistoryDecay = 0.998
+	// feeHistoryMinSamples is the minimum decayed number of transactions a group of buckets must contain before its confirmation rate is trusted.
+	feeHistoryMinSamples = 10.0
+	// feeHistorySuccessRate is the fraction of transactions in a group of buckets that must have confirmed within the target for the group to be considered sufficient.
+	feeHistorySuccessRate = 0.85
+)
+
+// FeeHistoryBucket is a snapshot of one fee rate bucket of the confirmed transaction history kept by the FeeEstimator.
+type FeeHistoryBucket struct {
+	// MinFeeRate is the lower bound of the bucket in satoshi per byte, inclusive.
+	MinFeeRate SatoshiPerByte
+	// MaxFeeRate is the upper bound of the bucket in satoshi per byte, exclusive. It is infinite for the last bucket.
+	MaxFeeRate SatoshiPerByte
+	// Confirmed holds the decayed number of transactions in this bucket which were mined the given number of blocks, minus one, after they were first seen.
+	Confirmed [estimateFeeDepth]float64
+	// Unconfirmed is the decayed number of transactions in this bucket that were not mined within the tracked depth.
+	Unconfirmed float64
+}
+
+// feeHistory is the decayed histogram of confirmation times of observed transactions, indexed by fee rate bucket.
+type feeHistory struct {
+	confirmed   [feeHistoryBuckets][estimateFeeDepth]float64
+	unconfirmed [feeHistoryBuckets]float64
+}
+
+// FeeHistory returns a snapshot of the confirmed transaction history, ordered from the lowest to the highest fee rate bucket.
+func (
+	ef *FeeEstimator,
+) FeeHistory() []FeeHistoryBucket {
+
+	ef.mtx.RLock()
+	defer ef.mtx.RUnlock()
+	buckets := make([]FeeHistoryBucket, feeHistoryBuckets)
+
+	for i := range buckets {
+
+		buckets[i].MinFeeRate = feeHistoryBucketMin(i)
+		buckets[i].MaxFeeRate = feeHistoryBucketMin(i + 1)
+		buckets[i].Confirmed = ef.history.confirmed[i]
+		buckets[i].Unconfirmed = ef.history.unconfirmed[i]
+	}
+	return buckets
+}
+
+// EstimateSmartFee estimates the fee rate needed for a transaction to be confirmed within the given number of blocks using the confirmed transaction history. Unlike EstimateFee it does not need any blocks to be registered in the current session, so it can answer immediately after a restored estimator is loaded. If there is not enough data for the requested target, longer targets are tried and the target the estimate is valid for is returned alongside it.
+func (
+	ef *FeeEstimator,
+) EstimateSmartFee(
+	numBlocks uint32) (BtcPerKilobyte, uint32, error) {
+
+	if numBlocks == 0 {
+
+		return -1, 0, errors.New("cannot confirm transaction in zero blocks")
+	}
+
+	if numBlocks > estimateFeeDepth {
+
+		numBlocks = estimateFeeDepth
+	}
+	ef.mtx.RLock()
+	defer ef.mtx.RUnlock()
+
+	for target := numBlocks; target <= estimateFeeDepth; target++ {
+
+		if feeRate, ok := ef.history.estimate(target); ok {
+
+			return feeRate.ToBtcPerKb(), target, nil
+		}
+	}
+	return -1, 0, errors.New("insufficient fee history data")
+}
+
+// estimate scans the buckets from the highest fee rate downwards, grouping adjacent buckets until they hold enough samples, and returns the lower bound of the cheapest group in which enough transactions confirmed within the target. The scan stops at the first sufficiently populated group that fails the success rate.
+func (
+	h *feeHistory,
+) estimate(
+	target uint32) (SatoshiPerByte, bool) {
+
+	var (
+		found         bool
+		best          SatoshiPerByte
+		total, within float64
+	)
+
+	for i := feeHistoryBuckets - 1; i >= 0; i-- {
+
+		for j := 0; j < estimateFeeDepth; j++ {
+
+			total += h.confirmed[i][j]
+
+			if uint32(j) < target {
+
+				within += h.confirmed[i][j]
+			}
+		}
+		total += h.unconfirmed[i]
+
+		if total < feeHistoryMinSamples {
+
+			continue
+		}
+
+		if within/total < feeHistorySuccessRate {
+
+			break
+		}
+		found = true
+		best = feeHistoryBucketMin(i)
+		total, within = 0, 0
+	}
+	return best, found
+}
+
+// recordConfirmed adds a transaction that was mined the given number of blocks after it was observed to the history.
+func (
+	h *feeHistory,
+) recordConfirmed(
+	feeRate SatoshiPerByte, blocksToConfirm int32) {
+
+	h.confirmed[feeHistoryBucket(feeRate)][blocksToConfirm]++
+}
+
+// recordUnconfirmed adds a transaction that was not mined within the tracked depth to the history.
+func (
+	h *feeHistory,
+) recordUnconfirmed(
+	feeRate SatoshiPerByte) {
+
+	h.unconfirmed[feeHistoryBucket(feeRate)]++
+}
+
+// decay reduces the weight of all the data in the history by feeHistoryDecay.
+func (
+	h *feeHistory,
+) decay() {
+
+	for i := range h.confirmed {
+
+		for j := range h.confirmed[i] {
+
+			h.confirmed[i][j] *= feeHistoryDecay
+		}
+		h.unconfirmed[i] *= feeHistoryDecay
+	}
+}
+
+// serialize writes the history to w.
+func (
+	h *feeHistory,
+) serialize(
+	w io.Writer) error {
+
+	e := binary.Write(w, binary.BigEndian, &h.confirmed)
+
+	if e != nil {
+
+		return e
+	}
+	return binary.Write(w, binary.BigEndian, &h.unconfirmed)
+}
+
+// deserializeFeeHistory reads a history previously written by serialize.
+func deserializeFeeHistory(
+	r io.Reader) (feeHistory, error) {
+
+	var h feeHistory
+	e := binary.Read(r, binary.BigEndian, &h.confirmed)
+
+	if e != nil {
+
+		return h, e
+	}
+	e = binary.Read(r, binary.BigEndian, &h.unconfirmed)
+	return h, e
+}
+
+// feeHistoryBucket returns the index of the bucket the fee rate falls into.
+func feeHistoryBucket(
+	feeRate SatoshiPerByte) int {
+
+	if feeRate < 1 {
+
+		return 0
+	}
+	i := int(math.Log(float64(feeRate))/math.Log(feeHistorySpacing)) + 1
+
+	if i >= feeHistoryBuckets {
+
+		return feeHistoryBuckets - 1
+	}
+	return i
+}
+
+// feeHistoryBucketMin returns the lower bound of the bucket with the given index, or positive infinity for the index past the last bucket.
+func feeHistoryBucketMin(
+	i int) SatoshiPerByte {
+
+	switch {
+	case i <= 0:
+		return 0
+	case i >= feeHistoryBuckets:
+		return SatoshiPerByte(math.Inf(1))
+	}
+	return SatoshiPerByte(math.Pow(feeHistorySpacing, float64(i-1)))
+}
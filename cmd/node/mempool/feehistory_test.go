@@ -0,0 +1,66 @@
+package mempool
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestFeeHistoryBuckets ensures fee rates are placed in the bucket whose bounds contain them.
+func TestFeeHistoryBuckets(
+	t *testing.T) {
+
+	for _, feeRate := range []SatoshiPerByte{0, 0.5, 1, 1.3, 10, 250, 1e9} {
+
+		i := feeHistoryBucket(feeRate)
+
+		if feeRate < feeHistoryBucketMin(i) || feeRate >= feeHistoryBucketMin(i+1) {
+			t.Errorf("fee rate %v placed in bucket %d [%v, %v)", feeRate, i,
+				feeHistoryBucketMin(i), feeHistoryBucketMin(i+1))
+		}
+	}
+}
+
+// TestEstimateSmartFee ensures the history based estimate picks the cheapest sufficiently confirmed bucket and survives a save and restore.
+func TestEstimateSmartFee(
+	t *testing.T) {
+
+	ef := newTestFeeEstimator(5, 3, 1)
+
+	if _, _, err := ef.EstimateSmartFee(2); err == nil {
+		t.Fatalf("EstimateSmartFee: expected error with empty history")
+	}
+
+	for i := 0; i < 20; i++ {
+
+		ef.history.recordConfirmed(100, 0)
+		ef.history.recordConfirmed(10, 4)
+	}
+	feeRate, target, err := ef.EstimateSmartFee(1)
+
+	if err != nil {
+		t.Fatalf("EstimateSmartFee: %v", err)
+	}
+
+	if target != 1 || feeRate != feeHistoryBucketMin(feeHistoryBucket(100)).ToBtcPerKb() {
+		t.Fatalf("EstimateSmartFee(1): got %v for target %d", feeRate, target)
+	}
+	feeRate, _, err = ef.EstimateSmartFee(5)
+
+	if err != nil {
+		t.Fatalf("EstimateSmartFee: %v", err)
+	}
+
+	if feeRate != feeHistoryBucketMin(feeHistoryBucket(10)).ToBtcPerKb() {
+		t.Fatalf("EstimateSmartFee(5): got %v", feeRate)
+	}
+	save := ef.Save()
+	restored, err := RestoreFeeEstimator(save)
+
+	if err != nil {
+		t.Fatalf("RestoreFeeEstimator: %v", err)
+	}
+
+	if !bytes.Equal(save, restored.Save()) {
+		t.Fatalf("restored fee history does not match")
+	}
+}
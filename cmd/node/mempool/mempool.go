@@ -46,6 +46,8 @@ type Config struct {
 	AddrIndex *indexers.AddrIndex
 	// FeeEstimatator provides a feeEstimator. If it is not nil, the mempool records all new transactions it observes into the feeEstimator.
 	FeeEstimator *FeeEstimator
+	// NotifyEvent, if not nil, is called for every transaction entering or leaving the main pool. It is called with the mempool lock held, so it must not block or call back into the pool.
+	NotifyEvent func(*Event)
 }
 
 // Policy houses the policy (configuration parameters) which is used to control the mempool.
@@ -61,14 +63,36 @@ type Policy struct {
 	AcceptNonStd bool
 	// FreeTxRelayLimit defines the given amount in thousands of bytes per minute that transactions with no fee are rate limited to.
 	FreeTxRelayLimit float64
+	// FeeRateOnly disables the free/high-priority transaction space entirely.  Every new transaction must pay at least the minimum relay fee regardless of its size or priority, which also makes DisableRelayPriority and FreeTxRelayLimit irrelevant.
+	FeeRateOnly bool
 	// MaxOrphanTxs is the maximum number of orphan transactions that can be queued.
 	MaxOrphanTxs int
+	// MaxOrphanTxsPerPeer is the maximum number of orphan transactions relayed by a single peer that can be queued. When it is reached the oldest orphan from that peer is evicted. Zero disables the limit.
+	MaxOrphanTxsPerPeer int
+	// OrphanTTL is the maximum amount of time an orphan is allowed to stay in the orphan pool before it expires. Zero selects the default.
+	OrphanTTL time.Duration
 	// MaxOrphanTxSize is the maximum size allowed for orphan transactions. This helps prevent memory exhaustion attacks from sending a lot of of big orphans.
 	MaxOrphanTxSize int
 	// MaxSigOpCostPerTx is the cumulative maximum cost of all the signature operations in a single transaction we will relay or mine.  It is a fraction of the max signature operations for a block.
 	MaxSigOpCostPerTx int
 	// MinRelayTxFee defines the minimum transaction fee in DUO/kB to be considered a non-zero fee.
 	MinRelayTxFee util.Amount
+	// DustRelayFee defines the fee rate in DUO/kB used to decide whether a transaction output is dust.  Transactions with dust outputs are non-standard.
+	DustRelayFee util.Amount
+	// MaxAncestorCount is the maximum number of in-mempool ancestors, including itself, a transaction may have to be accepted. Zero disables the limit.
+	MaxAncestorCount int
+	// MaxAncestorSize is the maximum total virtual size in bytes of a transaction and its in-mempool ancestors. Zero disables the limit.
+	MaxAncestorSize int
+	// MaxDescendantCount is the maximum number of in-mempool descendants, including itself, any transaction in the mempool may have. Zero disables the limit.
+	MaxDescendantCount int
+	// MaxDescendantSize is the maximum total virtual size in bytes of any transaction in the mempool and its in-mempool descendants. Zero disables the limit.
+	MaxDescendantSize int
+	// AcceptReplacement, if true, allows transactions that signal replaceability according to BIP125 to be replaced in the mempool by conflicting transactions paying a higher fee.
+	AcceptReplacement bool
+	// MaxPoolSize is the maximum total virtual size in bytes of all transactions in the main pool. When it is exceeded the packages paying the lowest fee rate are evicted. Zero disables the limit.
+	MaxPoolSize int64
+	// Expiry is the maximum amount of time a transaction may stay in the main pool before it is evicted along with its descendants. Zero disables expiry.
+	Expiry time.Duration
 }
 
 // Tag represents an identifier to use for tagging orphan transactions.  The caller may choose any scheme it desires, however it is common to use peer IDs so that orphans can be identified by which peer first relayed them.
@@ -92,13 +116,28 @@ type TxPool struct {
 	mtx           sync.RWMutex
 	cfg           Config
 	pool          map[chainhash.Hash]*TxDesc
+	packages      map[chainhash.Hash]*PackageInfo
 	orphans       map[chainhash.Hash]*orphanTx
 	orphansByPrev map[wire.OutPoint]map[chainhash.Hash]*util.Tx
 	outpoints     map[wire.OutPoint]*util.Tx
+	orphansByTag  map[Tag]int
+	orphanStats   OrphanStats
 	pennyTotal    float64 // exponentially decaying total for penny spends.
 	lastPennyUnix int64   // unix time of last ``penny spend''
 	// nextExpireScan is the time after which the orphan pool will be scanned in order to evict orphans.  This is NOT a hard deadline as the scan will only run when an orphan is added to the pool as opposed to on an unconditional timer.
 	nextExpireScan time.Time
+	// poolSize is the total virtual size in bytes of all transactions in the main pool.
+	poolSize int64
+	// rollingMinFee is the minimum fee rate in satoshi per kB raised by evicting transactions to keep the pool within its size limit. It decays over time starting from lastRollingFeeUpdate.
+	rollingMinFee        int64
+	lastRollingFeeUpdate time.Time
+	// nextPoolExpireScan is the time after which the main pool will be scanned in order to evict expired transactions.
+	nextPoolExpireScan time.Time
+	// stats holds the incrementally updated histograms of the main pool.
+	stats *poolStats
+	// evictQueue orders the transactions of the main pool by the fee rate of their descendant packages so the one to evict when the pool exceeds its size limit is found without scanning the pool. evictEntries indexes its entries by transaction hash.
+	evictQueue   evictionQueue
+	evictEntries map[chainhash.Hash]*evictionEntry
 }
 
 // orphanTx is normal transaction that references an ancestor transaction that is not yet available.  It also contains additional information related to it such as an expiration time to help prevent caching the orphan forever.
@@ -106,7 +145,9 @@ type TxPool struct {
 type orphanTx struct {
 	tx         *util.Tx
 	tag        Tag
+	added      time.Time
 	expiration time.Time
+	missing    []wire.OutPoint
 }
 
 const (
@@ -216,6 +257,60 @@ func (
 	return hashes, txD, err
 }
 
+// MempoolEntry returns the details of a single transaction in the main pool, including the aggregate statistics of its in-mempool ancestors and descendants, as a fully populated json result. This function is safe for concurrent access.
+func (
+	mp *TxPool,
+) MempoolEntry(
+	hash *chainhash.Hash) (*json.GetMempoolEntryResult, error) {
+
+	mp.mtx.RLock()
+	defer mp.mtx.RUnlock()
+	desc, exists := mp.pool[*hash]
+
+	if !exists {
+
+		return nil, fmt.Errorf("transaction is not in the pool")
+	}
+	// Calculate the current priority based on the inputs to the transaction.  Use zero if one or more of the input transactions can't be found for some reason.
+	tx := desc.Tx
+	var currentPriority float64
+	utxos, err := mp.fetchInputUtxos(tx)
+
+	if err == nil {
+
+		currentPriority = mining.CalcPriority(tx.MsgTx(), utxos,
+			mp.cfg.BestHeight()+1)
+	}
+	pkg := mp.packages[*hash]
+	entry := &json.GetMempoolEntryResult{
+		Size:             int32(tx.MsgTx().SerializeSize()),
+		Fee:              util.Amount(desc.Fee).ToDUO(),
+		ModifiedFee:      util.Amount(desc.Fee).ToDUO(),
+		Time:             desc.Added.Unix(),
+		Height:           int64(desc.Height),
+		StartingPriority: desc.StartingPriority,
+		CurrentPriority:  currentPriority,
+		DescendantCount:  pkg.DescendantCount,
+		DescendantSize:   pkg.DescendantSize,
+		DescendantFees:   util.Amount(pkg.DescendantFees).ToDUO(),
+		AncestorCount:    pkg.AncestorCount,
+		AncestorSize:     pkg.AncestorSize,
+		AncestorFees:     util.Amount(pkg.AncestorFees).ToDUO(),
+		Depends:          make([]string, 0),
+	}
+
+	for _, txIn := range tx.MsgTx().TxIn {
+
+		parentHash := &txIn.PreviousOutPoint.Hash
+
+		if mp.haveTransaction(parentHash) {
+
+			entry.Depends = append(entry.Depends, parentHash.String())
+		}
+	}
+	return entry, nil
+}
+
 // MiningDescs returns a slice of mining descriptors for all the transactions in the pool. This is part of the mining.TxSource interface implementation and is safe for concurrent access as required by the interface contract.
 func (
 	mp *TxPool,
@@ -224,9 +319,12 @@ func (
 	descs := make([]*mining.TxDesc, len(mp.pool))
 	i := 0
 
-	for _, desc := range mp.pool {
+	for hash, desc := range mp.pool {
 
-		descs[i] = &desc.TxDesc
+		// The descriptors are copied so the ancestor fee rate of the current package of each transaction can be set without racing with other callers.
+		miningDesc := desc.TxDesc
+		miningDesc.AncestorFeePerKB = mp.packages[hash].AncestorFeePerKB()
+		descs[i] = &miningDesc
 		i++
 	}
 	mp.mtx.RUnlock()
@@ -287,7 +385,7 @@ func (
 		return nil, txRuleError(wire.RejectDuplicate, str)
 	}
 	// Potentially add the orphan transaction to the orphan pool.
-	err = mp.maybeAddOrphan(tx, tag)
+	err = mp.maybeAddOrphan(tx, tag, missingParents)
 	return nil, err
 }
 
@@ -303,40 +401,59 @@ func (
 
 	for _, desc := range mp.pool {
 
-		// Calculate the current priority based on the inputs to the transaction.  Use zero if one or more of the input transactions can't be found for some reason.
-		tx := desc.Tx
-		var currentPriority float64
-		utxos, err := mp.fetchInputUtxos(tx)
+		result[desc.Tx.Hash().String()] = mp.verboseEntry(desc, bestHeight)
+	}
+	return result
+}
 
-		if err == nil {
+// verboseEntry returns the fully populated json result for the passed mempool entry. This function MUST be called with the mempool lock held (for reads).
+func (
+	mp *TxPool,
+) verboseEntry(
+	desc *TxDesc, bestHeight int32) *json.GetRawMempoolVerboseResult {
 
-			currentPriority = mining.CalcPriority(tx.MsgTx(), utxos,
-				bestHeight+1)
-		}
-		mpd := &json.GetRawMempoolVerboseResult{
-			Size:             int32(tx.MsgTx().SerializeSize()),
-			Vsize:            int32(GetTxVirtualSize(tx)),
-			Fee:              util.Amount(desc.Fee).ToDUO(),
-			Time:             desc.Added.Unix(),
-			Height:           int64(desc.Height),
-			StartingPriority: desc.StartingPriority,
-			CurrentPriority:  currentPriority,
-			Depends:          make([]string, 0),
-		}
+	// Calculate the current priority based on the inputs to the transaction.  Use zero if one or more of the input transactions can't be found for some reason.
+	tx := desc.Tx
+	var currentPriority float64
+	utxos, err := mp.fetchInputUtxos(tx)
 
-		for _, txIn := range tx.MsgTx().TxIn {
+	if err == nil {
 
-			hash := &txIn.PreviousOutPoint.Hash
+		currentPriority = mining.CalcPriority(tx.MsgTx(), utxos,
+			bestHeight+1)
+	}
+	mpd := &json.GetRawMempoolVerboseResult{
+		Size:             int32(tx.MsgTx().SerializeSize()),
+		Vsize:            int32(GetTxVirtualSize(tx)),
+		Fee:              util.Amount(desc.Fee).ToDUO(),
+		Time:             desc.Added.Unix(),
+		Height:           int64(desc.Height),
+		StartingPriority: desc.StartingPriority,
+		CurrentPriority:  currentPriority,
+		Depends:          make([]string, 0),
+	}
 
-			if mp.haveTransaction(hash) {
+	if pkg, ok := mp.packages[*tx.Hash()]; ok {
 
-				mpd.Depends = append(mpd.Depends,
-					hash.String())
-			}
+		mpd.AncestorCount = pkg.AncestorCount
+		mpd.AncestorSize = pkg.AncestorSize
+		mpd.AncestorFees = util.Amount(pkg.AncestorFees).ToDUO()
+		mpd.DescendantCount = pkg.DescendantCount
+		mpd.DescendantSize = pkg.DescendantSize
+		mpd.DescendantFees = util.Amount(pkg.DescendantFees).ToDUO()
+	}
+
+	for _, txIn := range tx.MsgTx().TxIn {
+
+		hash := &txIn.PreviousOutPoint.Hash
+
+		if mp.haveTransaction(hash) {
+
+			mpd.Depends = append(mpd.Depends,
+				hash.String())
 		}
-		result[tx.Hash().String()] = mpd
 	}
-	return result
+	return mpd
 }
 
 // RemoveDoubleSpends removes all transactions which spend outputs spent by the passed transaction from the memory pool.  Removing those transactions then leads to removing all transactions which rely on them, recursively.  This is necessary when a block is connected to the main chain because the block may contain transactions which were previously unknown to the memory pool. This function is safe for concurrent access.
@@ -354,7 +471,10 @@ func (
 
 			if !txRedeemer.Hash().IsEqual(tx.Hash()) {
 
-				mp.removeTransaction(txRedeemer, true)
+				mp.removeTransactionEvent(txRedeemer, true, &Event{
+					Type:   EventEvicted,
+					Reason: EvictConflict,
+				})
 			}
 		}
 	}
@@ -384,7 +504,7 @@ func (
 
 		if otx.tag == tag {
 
-			mp.removeOrphan(otx.tx, true)
+			mp.removeOrphanCounted(otx.tx, true, &mp.orphanStats.Removed)
 			numEvicted++
 		}
 	}
@@ -404,6 +524,20 @@ func (
 	mp.mtx.Unlock()
 }
 
+// RemoveMinedTransaction removes the passed transaction, which was included in the block with the passed hash, from the mempool. Transactions which redeem its outputs are left in the pool since they are still valid. This function is safe for concurrent access.
+func (
+	mp *TxPool,
+) RemoveMinedTransaction(
+	tx *util.Tx, blockHash *chainhash.Hash) {
+
+	mp.mtx.Lock()
+	mp.removeTransactionEvent(tx, false, &Event{
+		Type:      EventMined,
+		BlockHash: blockHash,
+	})
+	mp.mtx.Unlock()
+}
+
 // TxDescs returns a slice of descriptors for all the transactions in the pool. The descriptors are to be treated as read only. This function is safe for concurrent access.
 func (
 	mp *TxPool,
@@ -443,7 +577,7 @@ func (
 func (
 	mp *TxPool,
 ) addOrphan(
-	tx *util.Tx, tag Tag) {
+	tx *util.Tx, tag Tag, missingParents []*chainhash.Hash) {
 
 	// Nothing to do if no orphans are allowed.
 
@@ -459,11 +593,23 @@ func (
 		log <- cl.Warn{"failed to set orphan limit", e}
 
 	}
+	mp.limitOrphansPerTag(tag)
+	ttl := mp.cfg.Policy.OrphanTTL
+
+	if ttl <= 0 {
+
+		ttl = orphanTTL
+	}
+	now := time.Now()
 	mp.orphans[*tx.Hash()] = &orphanTx{
 		tx:         tx,
 		tag:        tag,
-		expiration: time.Now().Add(orphanTTL),
+		added:      now,
+		expiration: now.Add(ttl),
+		missing:    missingOutpoints(tx, missingParents),
 	}
+	mp.orphansByTag[tag]++
+	mp.orphanStats.Added++
 
 	for _, txIn := range tx.MsgTx().TxIn {
 
@@ -503,6 +649,9 @@ func (
 
 		mp.outpoints[txIn.PreviousOutPoint] = tx
 	}
+	mp.addPackageEntry(txD)
+	mp.poolSize += GetTxVirtualSize(tx)
+	mp.stats.add(txD, GetTxVirtualSize(tx))
 	atomic.StoreInt64(&mp.lastUpdated, time.Now().Unix())
 	// Add unconfirmed address index entries associated with the transaction if enabled.
 
@@ -516,26 +665,39 @@ func (
 
 		mp.cfg.FeeEstimator.ObserveTransaction(txD)
 	}
+	mp.notifyEvent(&Event{Type: EventAccepted}, txD)
 	return txD
 }
 
-// checkPoolDoubleSpend checks whether or not the passed transaction is attempting to spend coins already spent by other transactions in the pool. Note it does not check for double spends against transactions already in the main chain. This function MUST be called with the mempool lock held (for reads).
+// checkPoolDoubleSpend checks whether or not the passed transaction is attempting to spend coins already spent by other transactions in the pool. If it does, we'll check whether each of those transactions are signaling for replacement. If just one of them isn't, an error is returned. Otherwise, a boolean is returned signaling that the transaction is a replacement. Note it does not check for double spends against transactions already in the main chain. This function MUST be called with the mempool lock held (for reads).
 func (
 	mp *TxPool,
 ) checkPoolDoubleSpend(
-	tx *util.Tx) error {
+	tx *util.Tx) (bool, error) {
+
+	var isReplacement bool
 
 	for _, txIn := range tx.MsgTx().TxIn {
 
-		if txR, exists := mp.outpoints[txIn.PreviousOutPoint]; exists {
+		conflict, ok := mp.outpoints[txIn.PreviousOutPoint]
+
+		if !ok {
+
+			continue
+		}
+		// Reject the transaction if we don't accept replacement transactions or if it doesn't signal replacement.
+
+		if !mp.cfg.Policy.AcceptReplacement ||
+			!mp.signalsReplacement(conflict, nil) {
 
 			str := fmt.Sprintf("output %v already spent by "+
 				"transaction %v in the memory pool",
-				txIn.PreviousOutPoint, txR.Hash())
-			return txRuleError(wire.RejectDuplicate, str)
+				txIn.PreviousOutPoint, conflict.Hash())
+			return false, txRuleError(wire.RejectDuplicate, str)
 		}
+		isReplacement = true
 	}
-	return nil
+	return isReplacement, nil
 }
 
 // fetchInputUtxos loads utxo details about the input transactions referenced by the passed transaction.  First, it loads the details form the viewpoint of the main chain, then it adjusts them based upon the contents of the transaction pool. This function MUST be called with the mempool lock held (for reads).
@@ -621,7 +783,7 @@ func (
 			if now.After(otx.expiration) {
 
 				// Remove redeemers too because the missing parents are very unlikely to ever materialize since the orphan has already been around more than long enough for them to be delivered.
-				mp.removeOrphan(otx.tx, true)
+				mp.removeOrphanCounted(otx.tx, true, &mp.orphanStats.Expired)
 			}
 		}
 		// Set next expiration scan to occur after the scan interval.
@@ -650,7 +812,7 @@ func (
 	for _, otx := range mp.orphans {
 
 		// Don't remove redeemers in the case of a random eviction since it is quite possible it might be needed again shortly.
-		mp.removeOrphan(otx.tx, false)
+		mp.removeOrphanCounted(otx.tx, false, &mp.orphanStats.Evicted)
 		break
 	}
 	return nil
@@ -717,7 +879,7 @@ func (
 	if !mp.cfg.Policy.AcceptNonStd {
 
 		err = checkTransactionStandard(tx, nextBlockHeight,
-			medianTimePast, mp.cfg.Policy.MinRelayTxFee,
+			medianTimePast, mp.cfg.Policy.DustRelayFee,
 			mp.cfg.Policy.MaxTxVersion)
 
 		if err != nil {
@@ -735,7 +897,7 @@ func (
 		}
 	}
 	// The transaction may not use any of the same outputs as other transactions already in the pool as that would ultimately result in a double spend.  This check is intended to be quick and therefore only detects double spends within the transaction pool itself.  The transaction could still be double spending coins from the main chain at this point.  There is a more in-depth check that happens later after fetching the referenced transaction inputs from the main chain which examines the actual spend data and prevents double spends.
-	err = mp.checkPoolDoubleSpend(tx)
+	isReplacement, err := mp.checkPoolDoubleSpend(tx)
 
 	if err != nil {
 
@@ -834,6 +996,13 @@ func (
 			return nil, nil, txRuleError(rejectCode, str)
 		}
 	}
+	// Don't allow the transaction to create overly long or large chains of unconfirmed transactions, as these are expensive to track and to evaluate when building block templates.
+	err = mp.checkPackageLimits(tx, mp.txAncestors(tx, nil))
+
+	if err != nil {
+
+		return nil, nil, err
+	}
 	// NOTE: if you modify this code to accept non-standard transactions, you should add code here to check that the transaction does a reasonable number of ECDSA signature verifications. Don't allow transactions with an excessive number of signature operations which would result in making it impossible to mine.  Since the coinbase address itself can contain signature operations, the maximum allowed signature operations per transaction is less than the maximum allowed signature operations per block. TODO(roasbeef): last bool should be conditional on segwit activation
 	sigOpCost, err := blockchain.GetSigOpCost(tx, false, utxoView, true, true)
 
@@ -858,13 +1027,26 @@ func (
 	minFee := calcMinRequiredTxRelayFee(serializedSize,
 		mp.cfg.Policy.MinRelayTxFee)
 
-	if serializedSize >= (DefaultBlockPrioritySize-1000) && txFee < minFee {
+	// In fee rate only mode there is no free transaction area at all, so new transactions must always pay the minimum fee.  Transactions added back from disconnected blocks are exempted.
+	if (mp.cfg.Policy.FeeRateOnly && isNew ||
+		serializedSize >= (DefaultBlockPrioritySize-1000)) && txFee < minFee {
 
 		str := fmt.Sprintf("transaction %v has %d fees which is under "+
 			"the required amount of %d", txHash, txFee,
 			minFee)
 		return nil, nil, txRuleError(wire.RejectInsufficientFee, str)
 	}
+	// Don't allow new transactions paying less than the fee rate of the packages recently evicted to keep the pool within its size limit, as they would most likely be evicted again right away.
+
+	if isNew {
+
+		err = mp.checkPoolMinFee(tx, txFee, serializedSize)
+
+		if err != nil {
+
+			return nil, nil, err
+		}
+	}
 	// Require that free transactions have sufficient priority to be mined in the next block.  Transactions which are being added back to the memory pool from blocks that have been disconnected during a reorg are exempted.
 
 	if isNew && !mp.cfg.Policy.DisableRelayPriority && txFee < minFee {
@@ -908,6 +1090,18 @@ func (
 			mp.cfg.Policy.FreeTxRelayLimit * 10 * 1000,
 		}
 	}
+	// If the transaction has any conflicts and we've made it this far, then we're processing a potential replacement.
+	var conflicts map[chainhash.Hash]*util.Tx
+
+	if isReplacement {
+
+		conflicts, err = mp.validateReplacement(tx, txFee)
+
+		if err != nil {
+
+			return nil, nil, err
+		}
+	}
 	// Verify crypto signatures for each input and reject the transaction if any don't verify.
 	err = blockchain.ValidateTransactionScripts(tx, utxoView,
 		txscript.StandardVerifyFlags, mp.cfg.SigCache,
@@ -921,8 +1115,34 @@ func (
 		}
 		return nil, nil, err
 	}
+	// Now that we've deemed the transaction as valid, we can add it to the mempool. If it ended up replacing any transactions, we'll remove them first.
+
+	for _, conflict := range conflicts {
+
+		log <- cl.Debugf{
+			"replacing transaction %v (fee_rate=%v sat/kb) with %v (fee_rate=%v sat/kb)",
+			conflict.Hash(),
+			mp.pool[*conflict.Hash()].FeePerKB,
+			txHash,
+			txFee * 1000 / GetTxVirtualSize(tx),
+		}
+		// The conflict set should already include the descendants for each one, so we don't need to remove the redeemers within this call as they'll be removed eventually.
+		mp.removeTransactionEvent(conflict, false, &Event{
+			Type:       EventReplaced,
+			ReplacedBy: txHash,
+		})
+	}
 	// Add to transaction pool.
 	txD := mp.addTransaction(utxoView, tx, bestHeight, txFee)
+	// Expire old transactions and trim the pool to its size limit. The new transaction itself may be evicted if it pays the lowest fee rate.
+	mp.limitPoolSize()
+
+	if _, exists := mp.pool[*txHash]; !exists {
+
+		str := fmt.Sprintf("transaction %v was evicted because the "+
+			"mempool is full", txHash)
+		return nil, nil, txRuleError(wire.RejectInsufficientFee, str)
+	}
 
 	log <- cl.Debugf{
 
@@ -937,7 +1157,7 @@ func (
 func (
 	mp *TxPool,
 ) maybeAddOrphan(
-	tx *util.Tx, tag Tag) error {
+	tx *util.Tx, tag Tag, missingParents []*chainhash.Hash) error {
 	// Ignore orphan transactions that are too large.  This helps avoid a memory exhaustion attack based on sending a lot of really large orphans.  In the case there is a valid transaction larger than this, it will ultimtely be rebroadcast after the parent transactions have been mined or otherwise received.
 	// Note that the number of orphan transactions in the orphan pool is also limited, so this equates to a maximum memory used of mp.cfg.Policy.MaxOrphanTxSize * mp.cfg.Policy.MaxOrphanTxs (which is ~5MB using the default values at the time this comment was written).
 	serializedLen := tx.MsgTx().SerializeSize()
@@ -949,7 +1169,7 @@ func (
 		return txRuleError(wire.RejectNonstandard, str)
 	}
 	// Add the orphan if the none of the above disqualified it.
-	mp.addOrphan(tx, tag)
+	mp.addOrphan(tx, tag, missingParents)
 	return nil
 }
 
@@ -991,7 +1211,7 @@ func (
 				if err != nil {
 
 					// The orphan is now invalid, so there is no way any other orphans which redeem any of its outputs can be accepted.  Remove them.
-					mp.removeOrphan(tx, true)
+					mp.removeOrphanCounted(tx, true, &mp.orphanStats.Invalid)
 					break
 				}
 				// Transaction is still an orphan.  Try the next orphan which redeems this output.
@@ -1004,6 +1224,7 @@ func (
 				// Add it to the list of accepted transactions that are no longer orphans, remove it from the orphan pool, and add it to the list of transactions to process so any orphans that depend on it are handled too.
 				acceptedTxns = append(acceptedTxns, txD)
 				mp.removeOrphan(tx, false)
+				mp.orphanStats.Resolved++
 				processList.PushBack(tx)
 				// Only one transaction for this outpoint can be accepted, so the rest are now double spends and are removed later.
 				break
@@ -1069,6 +1290,11 @@ func (
 	}
 	// Remove the transaction from the orphan pool.
 	delete(mp.orphans, *txHash)
+
+	if mp.orphansByTag[otx.tag]--; mp.orphansByTag[otx.tag] <= 0 {
+
+		delete(mp.orphansByTag, otx.tag)
+	}
 }
 
 // removeOrphanDoubleSpends removes all orphans which spend outputs spent by the passed transaction from the orphan pool.  Removing those orphans then leads to removing all orphans which rely on them, recursively.  This is necessary when a transaction is added to the main pool because it may spend outputs orphans also spend. This function MUST be called with the mempool lock held (for writes).
@@ -1083,7 +1309,7 @@ func (
 
 		for _, orphan := range mp.orphansByPrev[txIn.PreviousOutPoint] {
 
-			mp.removeOrphan(orphan, true)
+			mp.removeOrphanCounted(orphan, true, &mp.orphanStats.Conflicted)
 		}
 	}
 }
@@ -1094,6 +1320,15 @@ func (
 ) removeTransaction(
 	tx *util.Tx, removeRedeemers bool) {
 
+	mp.removeTransactionEvent(tx, removeRedeemers, nil)
+}
+
+// removeTransactionEvent removes the passed transaction like removeTransaction and, if ev is not nil, notifies a copy of ev for it and for every redeemer removed along with it. This function MUST be called with the mempool lock held (for writes).
+func (
+	mp *TxPool,
+) removeTransactionEvent(
+	tx *util.Tx, removeRedeemers bool, ev *Event) {
+
 	txHash := tx.Hash()
 
 	if removeRedeemers {
@@ -1106,7 +1341,7 @@ func (
 
 			if txRedeemer, exists := mp.outpoints[prevOut]; exists {
 
-				mp.removeTransaction(txRedeemer, true)
+				mp.removeTransactionEvent(txRedeemer, true, ev)
 			}
 		}
 	}
@@ -1114,6 +1349,8 @@ func (
 
 	if txDesc, exists := mp.pool[*txHash]; exists {
 
+		// Update the aggregates of any remaining relatives while the transaction can still be located in the pool.
+		mp.removePackageEntry(txDesc)
 		// Remove unconfirmed address index entries associated with the transaction if enabled.
 
 		if mp.cfg.AddrIndex != nil {
@@ -1127,7 +1364,10 @@ func (
 			delete(mp.outpoints, txIn.PreviousOutPoint)
 		}
 		delete(mp.pool, *txHash)
+		mp.poolSize -= GetTxVirtualSize(txDesc.Tx)
+		mp.stats.remove(txDesc, GetTxVirtualSize(txDesc.Tx))
 		atomic.StoreInt64(&mp.lastUpdated, time.Now().Unix())
+		mp.notifyEvent(ev, txDesc)
 	}
 }
 
@@ -1135,11 +1375,16 @@ func (
 func New(
 	cfg *Config) *TxPool {
 	return &TxPool{
-		cfg:            *cfg,
-		pool:           make(map[chainhash.Hash]*TxDesc),
-		orphans:        make(map[chainhash.Hash]*orphanTx),
-		orphansByPrev:  make(map[wire.OutPoint]map[chainhash.Hash]*util.Tx),
-		nextExpireScan: time.Now().Add(orphanExpireScanInterval),
-		outpoints:      make(map[wire.OutPoint]*util.Tx),
+		cfg:                *cfg,
+		pool:               make(map[chainhash.Hash]*TxDesc),
+		packages:           make(map[chainhash.Hash]*PackageInfo),
+		orphans:            make(map[chainhash.Hash]*orphanTx),
+		orphansByTag:       make(map[Tag]int),
+		orphansByPrev:      make(map[wire.OutPoint]map[chainhash.Hash]*util.Tx),
+		nextExpireScan:     time.Now().Add(orphanExpireScanInterval),
+		nextPoolExpireScan: time.Now().Add(poolExpireScanInterval),
+		outpoints:          make(map[wire.OutPoint]*util.Tx),
+		stats:              newPoolStats(),
+		evictEntries:       make(map[chainhash.Hash]*evictionEntry),
 	}
 }
@@ -286,6 +286,7 @@ func newPoolHarness(
 				MaxOrphanTxSize:      1000,
 				MaxSigOpCostPerTx:    blockchain.MaxBlockSigOpsCost / 4,
 				MinRelayTxFee:        1000, // 1 Satoshi per byte
+				DustRelayFee:         1000,
 				MaxTxVersion:         1,
 			},
 			ChainParams:      chainParams,
@@ -826,3 +827,37 @@ func TestCheckSpend(
 		t.Fatalf("Unexpeced spend found in pool: %v", spend)
 	}
 }
+
+// TestFeeRateOnly ensures that transactions paying less than the minimum relay fee are rejected regardless of their size when the pool relays by fee rate only, and accepted otherwise.
+func TestFeeRateOnly(
+	t *testing.T) {
+
+	t.Parallel()
+	harness, spendableOuts, err := newPoolHarness(&chaincfg.MainNetParams)
+
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	tc := &testContext{t, harness}
+	freeTx, err := harness.CreateReplaceableTx(spendableOuts[0:1], 0,
+		wire.MaxTxInSequenceNum)
+
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+	harness.txPool.cfg.Policy.FeeRateOnly = true
+	_, err = harness.txPool.ProcessTransaction(freeTx, false, false, 0)
+
+	if err == nil {
+		t.Fatalf("ProcessTransaction: accepted free tx in fee rate " +
+			"only mode")
+	}
+	testPoolMembership(tc, freeTx, false, false)
+	harness.txPool.cfg.Policy.FeeRateOnly = false
+	_, err = harness.txPool.ProcessTransaction(freeTx, false, false, 0)
+
+	if err != nil {
+		t.Fatalf("ProcessTransaction: failed to accept free tx: %v", err)
+	}
+	testPoolMembership(tc, freeTx, false, true)
+}
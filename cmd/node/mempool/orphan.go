@@ -0,0 +1,142 @@
+package mempool
+
+import (
+	"time"
+
+	chainhash "git.parallelcoin.io/dev/9/pkg/chain/hash"
+	"git.parallelcoin.io/dev/9/pkg/chain/wire"
+	"git.parallelcoin.io/dev/9/pkg/util"
+	cl "git.parallelcoin.io/dev/9/pkg/util/cl"
+)
+
+const (
+	// DefaultMaxOrphanTxsPerPeer is the default maximum number of orphan transactions relayed by a single peer that are kept in the orphan pool.
+	DefaultMaxOrphanTxsPerPeer = 25
+	// DefaultOrphanTTL is the default amount of time an orphan is allowed to stay in the orphan pool before it expires.
+	DefaultOrphanTTL = orphanTTL
+)
+
+// OrphanStats houses the counters of what happened to the transactions that have been added to the orphan pool since the mempool was created.
+type OrphanStats struct {
+	// Added is the number of orphans added to the pool.
+	Added int64
+	// Resolved is the number of orphans accepted into the main pool after their missing parents arrived.
+	Resolved int64
+	// Expired is the number of orphans removed because they stayed in the pool for longer than the orphan expiry.
+	Expired int64
+	// Evicted is the number of orphans removed to make room for new ones, either because the pool or the relaying peer's share of it was full.
+	Evicted int64
+	// Invalid is the number of orphans removed because they were rejected once their parents were available.
+	Invalid int64
+	// Conflicted is the number of orphans removed because they double spend a transaction accepted into the main pool.
+	Conflicted int64
+	// Removed is the number of orphans removed because the peer that relayed them disconnected.
+	Removed int64
+}
+
+// OrphanInfo describes a transaction in the orphan pool.
+type OrphanInfo struct {
+	Tx         *util.Tx
+	Tag        Tag
+	Added      time.Time
+	Expiration time.Time
+	// Missing holds the outpoints spent by the orphan whose transactions were not available when it was added to the pool.
+	Missing []wire.OutPoint
+}
+
+// Orphans returns a description of every transaction in the orphan pool. This function is safe for concurrent access.
+func (
+	mp *TxPool,
+) Orphans() []OrphanInfo {
+
+	mp.mtx.RLock()
+	defer mp.mtx.RUnlock()
+	orphans := make([]OrphanInfo, 0, len(mp.orphans))
+
+	for _, otx := range mp.orphans {
+
+		orphans = append(orphans, OrphanInfo{
+			Tx:         otx.tx,
+			Tag:        otx.tag,
+			Added:      otx.added,
+			Expiration: otx.expiration,
+			Missing:    otx.missing,
+		})
+	}
+	return orphans
+}
+
+// OrphanStats returns the counters of what happened to the transactions added to the orphan pool. This function is safe for concurrent access.
+func (
+	mp *TxPool,
+) OrphanStats() OrphanStats {
+
+	mp.mtx.RLock()
+	stats := mp.orphanStats
+	mp.mtx.RUnlock()
+	return stats
+}
+
+// limitOrphansPerTag evicts the oldest orphan relayed with the passed tag if adding another one would cause the tag to exceed its share of the orphan pool. This keeps a single peer from filling the whole pool and pushing out the orphans relayed by everyone else. This function MUST be called with the mempool lock held (for writes).
+func (
+	mp *TxPool,
+) limitOrphansPerTag(
+	tag Tag) {
+
+	maxPerTag := mp.cfg.Policy.MaxOrphanTxsPerPeer
+
+	if maxPerTag <= 0 || mp.orphansByTag[tag]+1 <= maxPerTag {
+
+		return
+	}
+	var oldest *orphanTx
+
+	for _, otx := range mp.orphans {
+
+		if otx.tag == tag && (oldest == nil || otx.added.Before(oldest.added)) {
+
+			oldest = otx
+		}
+	}
+
+	if oldest != nil {
+
+		log <- cl.Debugf{
+			"evicting orphan %v, peer %d exceeded its limit of %d orphans",
+			oldest.tx.Hash(), tag, maxPerTag,
+		}
+		// Don't remove redeemers since they may be relayed by other peers and the parent might still show up.
+		mp.removeOrphanCounted(oldest.tx, false, &mp.orphanStats.Evicted)
+	}
+}
+
+// removeOrphanCounted removes the passed orphan, and its redeemers if requested, from the orphan pool and adds the number of orphans removed to the passed counter. This function MUST be called with the mempool lock held (for writes).
+func (
+	mp *TxPool,
+) removeOrphanCounted(
+	tx *util.Tx, removeRedeemers bool, counter *int64) {
+
+	numOrphans := len(mp.orphans)
+	mp.removeOrphan(tx, removeRedeemers)
+	*counter += int64(numOrphans - len(mp.orphans))
+}
+
+// missingOutpoints returns the outpoints spent by the passed transaction which reference one of the passed missing parents.
+func missingOutpoints(
+	tx *util.Tx, missingParents []*chainhash.Hash) []wire.OutPoint {
+
+	var missing []wire.OutPoint
+
+	for _, txIn := range tx.MsgTx().TxIn {
+
+		for _, parent := range missingParents {
+
+			if txIn.PreviousOutPoint.Hash == *parent {
+
+				missing = append(missing, txIn.PreviousOutPoint)
+				break
+			}
+		}
+	}
+	return missing
+}
@@ -0,0 +1,82 @@
+package mempool
+
+import (
+	"testing"
+
+	chaincfg "git.parallelcoin.io/dev/9/pkg/chain/config"
+)
+
+// TestOrphanPerPeerLimit ensures a single peer cannot hold more than its share of the orphan pool, and that the orphan statistics and missing outpoints are tracked.
+func TestOrphanPerPeerLimit(
+	t *testing.T) {
+
+	t.Parallel()
+	harness, spendableOuts, err := newPoolHarness(&chaincfg.MainNetParams)
+
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	harness.txPool.cfg.Policy.MaxOrphanTxsPerPeer = 2
+	spendableOuts, err = harness.CreateConfirmedOutputs(spendableOuts[0], 2)
+
+	if err != nil {
+		t.Fatalf("unable to create confirmed outputs: %v", err)
+	}
+	chainedTxns, err := harness.CreateTxChain(spendableOuts[0], 4)
+
+	if err != nil {
+		t.Fatalf("unable to create transaction chain: %v", err)
+	}
+	// Every transaction but the first is an orphan as long as the first is not in the pool.
+	for _, tx := range chainedTxns[1:] {
+		acceptedTxns, err := harness.txPool.ProcessTransaction(tx, true,
+			false, 1)
+
+		if err != nil {
+			t.Fatalf("ProcessTransaction: failed to accept orphan: %v",
+				err)
+		}
+
+		if len(acceptedTxns) != 0 {
+			t.Fatalf("ProcessTransaction: orphan accepted into the pool")
+		}
+	}
+	orphans := harness.txPool.Orphans()
+
+	if len(orphans) != 2 {
+		t.Fatalf("Orphans: want 2 orphans for the peer, got %d",
+			len(orphans))
+	}
+
+	for _, orphan := range orphans {
+		prevOut := orphan.Tx.MsgTx().TxIn[0].PreviousOutPoint
+
+		if len(orphan.Missing) != 1 || orphan.Missing[0] != prevOut {
+			t.Fatalf("Orphans: want missing outpoint %v, got %v",
+				prevOut, orphan.Missing)
+		}
+	}
+	// Orphans relayed by another peer are not affected by the first peer's limit.
+	otherTxns, err := harness.CreateTxChain(spendableOuts[1], 2)
+
+	if err != nil {
+		t.Fatalf("unable to create transaction chain: %v", err)
+	}
+	_, err = harness.txPool.ProcessTransaction(otherTxns[1], true, false, 2)
+
+	if err != nil {
+		t.Fatalf("ProcessTransaction: failed to accept orphan: %v", err)
+	}
+	stats := harness.txPool.OrphanStats()
+
+	if stats.Added != 4 || stats.Evicted != 1 {
+		t.Fatalf("OrphanStats: want 4 added and 1 evicted, got %+v", stats)
+	}
+	harness.txPool.RemoveOrphansByTag(1)
+	stats = harness.txPool.OrphanStats()
+
+	if stats.Removed == 0 {
+		t.Fatalf("OrphanStats: orphans removed by tag not counted: %+v",
+			stats)
+	}
+}
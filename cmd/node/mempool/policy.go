@@ -21,6 +21,8 @@ const (
 	maxStandardSigScriptSize = 1650
 	// DefaultMinRelayTxFee is the minimum fee in satoshi that is required for a transaction to be treated as free for relay and mining purposes.  It is also used to help determine if a transaction is considered dust and as a base for calculating minimum required fees for larger transactions.  This value is in Satoshi/1000 bytes.
 	DefaultMinRelayTxFee = util.Amount(1000)
+	// DefaultDustRelayFee is the default fee rate in Satoshi/1000 bytes used to decide whether an output is dust.  It matches the default minimum relay fee of the node so the dust threshold is unchanged unless it is configured.
+	DefaultDustRelayFee = util.Amount(10000)
 	// maxStandardMultiSigKeys is the maximum number of public keys allowed in a multi-signature transaction output script for it to be considered standard.
 	maxStandardMultiSigKeys = 3
 )
@@ -168,6 +170,16 @@ func isDust(
 	// The most common scripts are pay-to-pubkey-hash, and as per the above breakdown, the minimum size of a p2pkh input script is 148 bytes.  So that figure is used. If the output being spent is a witness program, then we apply the witness discount to the size of the signature.
 	// The segwit analogue to p2pkh is a p2wkh output. This is the smallest output possible using the new segwit features. The 107 bytes of witness data is discounted by a factor of 4, leading to a computed value of 67 bytes of witness data.
 	// Both cases share a 41 byte preamble required to reference the input being spent and the sequence number of the input.
+	totalSize := dustSpendSize(txOut)
+	// The output is considered dust if the cost to the network to spend the coins is more than 1/3 of the minimum free transaction relay fee. minFreeTxRelayFee is in Satoshi/KB, so multiply by 1000 to convert to bytes.
+	// Using the typical values for a pay-to-pubkey-hash transaction from the breakdown above and the default minimum free transaction relay fee of 1000, this equates to values less than 546 satoshi being considered dust.
+	// The following is equivalent to (value/totalSize) * (1/3) * 1000 without needing to do floating point math.
+	return txOut.Value*1000/(3*totalSize) < int64(minRelayTxFee)
+}
+
+// dustSpendSize returns the size of the passed output plus the typical size of the input which spends it, as used by isDust.
+func dustSpendSize(
+	txOut *wire.TxOut) int64 {
 	totalSize := txOut.SerializeSize() + 41
 
 	if txscript.IsWitnessProgram(txOut.PkScript) {
@@ -176,16 +188,21 @@ func isDust(
 	} else {
 		totalSize += 107
 	}
-	// The output is considered dust if the cost to the network to spend the coins is more than 1/3 of the minimum free transaction relay fee. minFreeTxRelayFee is in Satoshi/KB, so multiply by 1000 to convert to bytes.
-	// Using the typical values for a pay-to-pubkey-hash transaction from the breakdown above and the default minimum free transaction relay fee of 1000, this equates to values less than 546 satoshi being considered dust.
-	// The following is equivalent to (value/totalSize) * (1/3) * 1000 without needing to do floating point math.
-	return txOut.Value*1000/(3*int64(totalSize)) < int64(minRelayTxFee)
+	return int64(totalSize)
+}
+
+// GetDustThreshold returns the smallest value an output paying to the passed public key script may have without being considered dust at the passed dust relay fee.
+func GetDustThreshold(
+	pkScript []byte, dustRelayFee util.Amount) util.Amount {
+	totalSize := dustSpendSize(&wire.TxOut{PkScript: pkScript})
+	// Round up, the inverse of the integer division in isDust.
+	return util.Amount((int64(dustRelayFee)*3*totalSize + 999) / 1000)
 }
 
 // checkTransactionStandard performs a series of checks on a transaction to ensure it is a "standard" transaction.  A standard transaction is one that conforms to several additional limiting cases over what is considered a "sane" transaction such as having a version in the supported range, being finalized, conforming to more stringent size constraints, having scripts of recognized forms, and not containing "dust" outputs (those that are so small it costs more to process them than they are worth).
 func checkTransactionStandard(
 	tx *util.Tx, height int32,
-	medianTimePast time.Time, minRelayTxFee util.Amount,
+	medianTimePast time.Time, dustRelayFee util.Amount,
 	maxTxVersion int32) error {
 	// The transaction must be a currently supported version.
 	msgTx := tx.MsgTx()
@@ -257,7 +274,7 @@ func checkTransactionStandard(
 		if scriptClass == txscript.NullDataTy {
 
 			numNullDataOutputs++
-		} else if isDust(txOut, minRelayTxFee) {
+		} else if isDust(txOut, dustRelayFee) {
 
 			str := fmt.Sprintf("transaction output %d: payment "+
 				"of %d is dust", i, txOut.Value)
@@ -287,6 +287,50 @@ func TestDust(
 	}
 }
 
+// TestGetDustThreshold ensures the dust threshold is the smallest value which isDust does not consider dust.
+func TestGetDustThreshold(
+	t *testing.T) {
+
+	// Pay-to-pubkey-hash and pay-to-witness-pubkey-hash scripts.
+	p2pkhScript := make([]byte, 25)
+	p2wpkhScript := append([]byte{txscript.OpZero, txscript.OpData20},
+		make([]byte, 20)...)
+	tests := []struct {
+		name         string
+		pkScript     []byte
+		dustRelayFee util.Amount
+		threshold    util.Amount
+	}{
+		{"p2pkh", p2pkhScript, 1000, 546},
+		{"p2pkh higher fee", p2pkhScript, 10000, 5460},
+		{"p2wpkh", p2wpkhScript, 1000, 294},
+		{"zero fee", p2pkhScript, 0, 0},
+	}
+
+	for _, test := range tests {
+		threshold := GetDustThreshold(test.pkScript, test.dustRelayFee)
+
+		if threshold != test.threshold {
+			t.Fatalf("%s: want threshold %v, got %v", test.name,
+				test.threshold, threshold)
+		}
+
+		if test.threshold == 0 {
+			continue
+		}
+		txOut := wire.TxOut{Value: int64(threshold), PkScript: test.pkScript}
+
+		if isDust(&txOut, test.dustRelayFee) {
+			t.Fatalf("%s: threshold %v is dust", test.name, threshold)
+		}
+		txOut.Value--
+
+		if !isDust(&txOut, test.dustRelayFee) {
+			t.Fatalf("%s: %v is not dust", test.name, txOut.Value)
+		}
+	}
+}
+
 // TestCheckTransactionStandard tests the checkTransactionStandard API.
 func TestCheckTransactionStandard(
 	t *testing.T) {
@@ -0,0 +1,273 @@
+package mempool
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+	"time"
+
+	chainhash "git.parallelcoin.io/dev/9/pkg/chain/hash"
+	"git.parallelcoin.io/dev/9/pkg/chain/wire"
+	"git.parallelcoin.io/dev/9/pkg/util"
+	cl "git.parallelcoin.io/dev/9/pkg/util/cl"
+)
+
+const (
+	// DefaultMaxPoolSizeMB is the default maximum total virtual size of the main pool in megabytes.
+	DefaultMaxPoolSizeMB = 300
+	// DefaultExpiryHours is the default number of hours a transaction may stay in the main pool before it is expired.
+	DefaultExpiryHours = 336
+	// poolExpireScanInterval is the minimum amount of time in between scans of the main pool to evict expired transactions.
+	poolExpireScanInterval = time.Minute * 10
+	// rollingFeeHalfLife is the time it takes for the minimum fee rate raised by size based evictions to halve while the pool is at least half full. It decays two and four times faster when the pool is below a half and a quarter of its limit respectively.
+	rollingFeeHalfLife = time.Hour * 12
+)
+
+// MinFeeRate returns the effective minimum fee rate in satoshi per kB a transaction must pay to be accepted into the main pool. It is the higher of the configured minimum relay fee and the rate raised by evicting transactions to keep the pool within its size limit. This function is safe for concurrent access.
+func (
+	mp *TxPool,
+) MinFeeRate() util.Amount {
+
+	mp.mtx.Lock()
+	rollingFee := mp.rollingMinFeeRate()
+	mp.mtx.Unlock()
+
+	if rollingFee < int64(mp.cfg.Policy.MinRelayTxFee) {
+
+		return mp.cfg.Policy.MinRelayTxFee
+	}
+	return util.Amount(rollingFee)
+}
+
+// rollingMinFeeRate decays and returns the minimum fee rate in satoshi per kB raised by size based evictions, which is zero when no eviction has happened recently. This function MUST be called with the mempool lock held (for writes).
+func (
+	mp *TxPool,
+) rollingMinFeeRate() int64 {
+
+	if mp.rollingMinFee == 0 {
+
+		return 0
+	}
+	now := time.Now()
+	halfLife := rollingFeeHalfLife
+	maxSize := mp.cfg.Policy.MaxPoolSize
+
+	if mp.poolSize < maxSize/4 {
+
+		halfLife /= 4
+	} else if mp.poolSize < maxSize/2 {
+
+		halfLife /= 2
+	}
+	elapsed := now.Sub(mp.lastRollingFeeUpdate)
+	mp.rollingMinFee = int64(float64(mp.rollingMinFee) *
+		math.Pow(0.5, float64(elapsed)/float64(halfLife)))
+	mp.lastRollingFeeUpdate = now
+
+	if mp.rollingMinFee < int64(mp.cfg.Policy.MinRelayTxFee)/2 {
+
+		mp.rollingMinFee = 0
+	}
+	return mp.rollingMinFee
+}
+
+// expireTransactions removes all transactions, along with their descendants, which have been in the main pool for longer than the configured expiry. This function MUST be called with the mempool lock held (for writes).
+func (
+	mp *TxPool,
+) expireTransactions(
+	now time.Time) int {
+
+	cutoff := now.Add(-mp.cfg.Policy.Expiry)
+	origNumTxns := len(mp.pool)
+
+	for _, txD := range mp.pool {
+
+		if txD.Added.Before(cutoff) {
+
+			mp.removeTransactionEvent(txD.Tx, true, &Event{
+				Type:   EventEvicted,
+				Reason: EvictExpired,
+			})
+		}
+	}
+	return origNumTxns - len(mp.pool)
+}
+
+// limitPoolSize expires old transactions when it is time to scan for them and then evicts the packages with the lowest fee rate until the main pool is within its configured size limit. The fee rate of the package evicted last, plus the minimum relay fee, becomes the new rolling minimum fee rate so transactions which would just be evicted again are not accepted. This function MUST be called with the mempool lock held (for writes).
+func (
+	mp *TxPool,
+) limitPoolSize() {
+
+	if now := time.Now(); mp.cfg.Policy.Expiry > 0 &&
+		now.After(mp.nextPoolExpireScan) {
+
+		numExpired := mp.expireTransactions(now)
+		mp.nextPoolExpireScan = now.Add(poolExpireScanInterval)
+
+		if numExpired > 0 {
+
+			log <- cl.Debugf{
+				"expired %d %s from the mempool (remaining: %d)",
+				numExpired,
+				pickNoun(numExpired, "transaction", "transactions"),
+				len(mp.pool),
+			}
+		}
+	}
+	maxSize := mp.cfg.Policy.MaxPoolSize
+
+	if maxSize <= 0 || mp.poolSize <= maxSize {
+
+		return
+	}
+	var numEvicted int
+
+	for mp.poolSize > maxSize && len(mp.evictQueue) > 0 {
+
+		// Evict the transaction whose package of itself and all of its descendants pays the lowest fee rate, since removing it removes the descendants as well.
+		worst := mp.evictQueue[0]
+		newMinFee := worst.feeRate() + int64(mp.cfg.Policy.MinRelayTxFee)
+
+		if newMinFee > mp.rollingMinFeeRate() {
+
+			mp.rollingMinFee = newMinFee
+			mp.lastRollingFeeUpdate = time.Now()
+		}
+		before := len(mp.pool)
+		mp.removeTransactionEvent(worst.txD.Tx, true, &Event{
+			Type:   EventEvicted,
+			Reason: EvictSizeLimit,
+		})
+		numEvicted += before - len(mp.pool)
+	}
+	log <- cl.Debugf{
+		"evicted %d %s to limit the mempool size, minimum fee rate is now %v sat/kB",
+		numEvicted,
+		pickNoun(numEvicted, "transaction", "transactions"),
+		mp.rollingMinFee,
+	}
+}
+
+// evictionEntry is an entry of the eviction queue of the main pool, which tracks the package of a transaction so its position follows the changes to its descendant aggregates.
+type evictionEntry struct {
+	txD   *TxDesc
+	pkg   *PackageInfo
+	index int
+}
+
+// feeRate returns the fee rate in satoshi per kB of the transaction of the entry together with all of its in-mempool descendants.
+func (
+	e *evictionEntry,
+) feeRate() int64 {
+
+	return e.pkg.DescendantFees * 1000 / e.pkg.DescendantSize
+}
+
+// evictionQueue is a min-heap of the transactions in the main pool ordered by the fee rate of their descendant packages. It keeps the index of every entry up to date so entries can be fixed and removed in place.
+type evictionQueue []*evictionEntry
+
+// Len returns the number of entries in the queue.  It is part of the heap.Interface implementation.
+func (
+	q evictionQueue,
+) Len() int {
+
+	return len(q)
+}
+
+// Less returns whether the entry with index i pays a lower fee rate than the entry with index j.  It is part of the heap.Interface implementation.
+func (
+	q evictionQueue,
+) Less(
+	i, j int) bool {
+
+	return q[i].feeRate() < q[j].feeRate()
+}
+
+// Swap swaps the entries at the passed indices.  It is part of the heap.Interface implementation.
+func (
+	q evictionQueue,
+) Swap(
+	i, j int) {
+
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+// Push appends the passed entry to the queue.  It is part of the heap.Interface implementation.
+func (
+	q *evictionQueue,
+) Push(
+	x interface{}) {
+
+	entry := x.(*evictionEntry)
+	entry.index = len(*q)
+	*q = append(*q, entry)
+}
+
+// Pop removes and returns the last entry of the queue.  It is part of the heap.Interface implementation.
+func (
+	q *evictionQueue,
+) Pop() interface{} {
+
+	old := *q
+	n := len(old)
+	entry := old[n-1]
+	old[n-1] = nil
+	*q = old[:n-1]
+	return entry
+}
+
+// pushEvictionEntry adds a transaction which has just been added to the main pool to the eviction queue. This function MUST be called with the mempool lock held (for writes).
+func (
+	mp *TxPool,
+) pushEvictionEntry(
+	txD *TxDesc, pkg *PackageInfo) {
+
+	entry := &evictionEntry{txD: txD, pkg: pkg}
+	heap.Push(&mp.evictQueue, entry)
+	mp.evictEntries[*txD.Tx.Hash()] = entry
+}
+
+// fixEvictionEntry restores the order of the eviction queue after the descendant aggregates of the passed transaction have changed. This function MUST be called with the mempool lock held (for writes).
+func (
+	mp *TxPool,
+) fixEvictionEntry(
+	hash chainhash.Hash) {
+
+	if entry, ok := mp.evictEntries[hash]; ok {
+
+		heap.Fix(&mp.evictQueue, entry.index)
+	}
+}
+
+// removeEvictionEntry removes a transaction which is leaving the main pool from the eviction queue. This function MUST be called with the mempool lock held (for writes).
+func (
+	mp *TxPool,
+) removeEvictionEntry(
+	hash chainhash.Hash) {
+
+	if entry, ok := mp.evictEntries[hash]; ok {
+
+		heap.Remove(&mp.evictQueue, entry.index)
+		delete(mp.evictEntries, hash)
+	}
+}
+
+// checkPoolMinFee ensures the passed transaction pays at least the rolling minimum fee rate raised by size based evictions. This function MUST be called with the mempool lock held (for writes).
+func (
+	mp *TxPool,
+) checkPoolMinFee(
+	tx *util.Tx, txFee, txSize int64) error {
+
+	minFee := mp.rollingMinFeeRate()
+
+	if minFee == 0 || txFee*1000/txSize >= minFee {
+
+		return nil
+	}
+	str := fmt.Sprintf("transaction %v fee rate of %d sat/kB is below "+
+		"the mempool minimum fee rate of %d sat/kB", tx.Hash(),
+		txFee*1000/txSize, minFee)
+	return txRuleError(wire.RejectInsufficientFee, str)
+}
@@ -0,0 +1,203 @@
+package mempool
+
+import (
+	"testing"
+	"time"
+
+	chaincfg "git.parallelcoin.io/dev/9/pkg/chain/config"
+	"git.parallelcoin.io/dev/9/pkg/chain/wire"
+	"git.parallelcoin.io/dev/9/pkg/util"
+)
+
+// CreateConfirmedOutputs splits the provided output into the given number of outputs with a transaction that is added to the harness chain's utxo set, so the outputs can be spent by independent transactions.
+func (p *poolHarness) CreateConfirmedOutputs(output spendableOutput, numOutputs uint32) ([]spendableOutput, error) {
+
+	tx, err := p.CreateSignedTx([]spendableOutput{output}, numOutputs)
+
+	if err != nil {
+
+		return nil, err
+	}
+	p.chain.utxos.AddTxOuts(tx, p.chain.BestHeight())
+	outputs := make([]spendableOutput, 0, numOutputs)
+
+	for i := uint32(0); i < numOutputs; i++ {
+		outputs = append(outputs, txOutToSpendableOut(tx, i))
+	}
+	return outputs, nil
+}
+
+// TestPoolSizeLimit ensures the transaction paying the lowest fee rate is evicted when the pool exceeds its size limit and that the minimum fee rate is raised accordingly.
+func TestPoolSizeLimit(
+	t *testing.T) {
+
+	t.Parallel()
+	harness, spendableOuts, err := newPoolHarness(&chaincfg.MainNetParams)
+
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	tc := &testContext{t, harness}
+	spendableOuts, err = harness.CreateConfirmedOutputs(spendableOuts[0], 2)
+
+	if err != nil {
+		t.Fatalf("unable to create confirmed outputs: %v", err)
+	}
+	lowFeeTx, err := harness.CreateReplaceableTx(spendableOuts[0:1], 1000,
+		wire.MaxTxInSequenceNum)
+
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+	highFeeTx, err := harness.CreateReplaceableTx(spendableOuts[1:2], 10000,
+		wire.MaxTxInSequenceNum)
+
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+	harness.txPool.cfg.Policy.MaxPoolSize = GetTxVirtualSize(lowFeeTx) +
+		GetTxVirtualSize(highFeeTx) - 1
+	_, err = harness.txPool.ProcessTransaction(lowFeeTx, false, false, 0)
+
+	if err != nil {
+		t.Fatalf("ProcessTransaction: failed to accept tx: %v", err)
+	}
+	_, err = harness.txPool.ProcessTransaction(highFeeTx, false, false, 0)
+
+	if err != nil {
+		t.Fatalf("ProcessTransaction: failed to accept tx: %v", err)
+	}
+	testPoolMembership(tc, lowFeeTx, false, false)
+	testPoolMembership(tc, highFeeTx, false, true)
+	minFee := harness.txPool.MinFeeRate()
+
+	if minFee <= harness.txPool.cfg.Policy.MinRelayTxFee {
+		t.Fatalf("MinFeeRate: not raised after eviction, got %v", minFee)
+	}
+	// The evicted transaction must not be accepted again while the raised minimum fee rate is in effect.
+	_, err = harness.txPool.ProcessTransaction(lowFeeTx, false, false, 0)
+
+	if err == nil {
+		t.Fatalf("ProcessTransaction: accepted tx below the mempool " +
+			"minimum fee rate")
+	}
+}
+
+// TestPoolSizeLimitPackage ensures a transaction whose own fee rate is low is not evicted ahead of others while a descendant pays enough for the package of both to have the higher fee rate, and that it is evicted once the descendant is gone.
+func TestPoolSizeLimitPackage(
+	t *testing.T) {
+
+	t.Parallel()
+	harness, spendableOuts, err := newPoolHarness(&chaincfg.MainNetParams)
+
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	tc := &testContext{t, harness}
+	spendableOuts, err = harness.CreateConfirmedOutputs(spendableOuts[0], 3)
+
+	if err != nil {
+		t.Fatalf("unable to create confirmed outputs: %v", err)
+	}
+	parentTx, err := harness.CreateReplaceableTx(spendableOuts[0:1], 1000,
+		wire.MaxTxInSequenceNum)
+
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+	childTx, err := harness.CreateReplaceableTx(
+		[]spendableOutput{txOutToSpendableOut(parentTx, 0)}, 20000,
+		wire.MaxTxInSequenceNum)
+
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+	midFeeTx, err := harness.CreateReplaceableTx(spendableOuts[1:2], 5000,
+		wire.MaxTxInSequenceNum)
+
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+	lateTx, err := harness.CreateReplaceableTx(spendableOuts[2:3], 50000,
+		wire.MaxTxInSequenceNum)
+
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+
+	for _, tx := range []*util.Tx{parentTx, childTx, midFeeTx} {
+		_, err = harness.txPool.ProcessTransaction(tx, false, false, 0)
+
+		if err != nil {
+			t.Fatalf("ProcessTransaction: failed to accept tx: %v", err)
+		}
+	}
+	// Make room for all but one of the transactions, so the one paying the lowest package fee rate is evicted when the next is accepted.
+	harness.txPool.cfg.Policy.MaxPoolSize = GetTxVirtualSize(parentTx) +
+		GetTxVirtualSize(childTx) + GetTxVirtualSize(lateTx)
+	_, err = harness.txPool.ProcessTransaction(lateTx, false, false, 0)
+
+	if err != nil {
+		t.Fatalf("ProcessTransaction: failed to accept tx: %v", err)
+	}
+	testPoolMembership(tc, parentTx, false, true)
+	testPoolMembership(tc, childTx, false, true)
+	testPoolMembership(tc, midFeeTx, false, false)
+	testPoolMembership(tc, lateTx, false, true)
+	// Without the child the parent is the cheapest package left and is evicted first.
+	harness.txPool.RemoveTransaction(childTx, false)
+	harness.txPool.cfg.Policy.MaxPoolSize = GetTxVirtualSize(lateTx)
+	harness.txPool.mtx.Lock()
+	harness.txPool.limitPoolSize()
+	harness.txPool.mtx.Unlock()
+	testPoolMembership(tc, parentTx, false, false)
+	testPoolMembership(tc, lateTx, false, true)
+}
+
+// TestPoolExpiry ensures transactions which have been in the pool for longer than the configured expiry are evicted along with their descendants.
+func TestPoolExpiry(
+	t *testing.T) {
+
+	t.Parallel()
+	harness, spendableOuts, err := newPoolHarness(&chaincfg.MainNetParams)
+
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	harness.txPool.cfg.Policy.Expiry = time.Hour
+	chainedTxns, err := harness.CreateTxChain(spendableOuts[0], 2)
+
+	if err != nil {
+		t.Fatalf("unable to create transaction chain: %v", err)
+	}
+
+	for _, tx := range chainedTxns {
+		_, err := harness.txPool.ProcessTransaction(tx, false, false, 0)
+
+		if err != nil {
+			t.Fatalf("ProcessTransaction: failed to accept tx: %v", err)
+		}
+	}
+	harness.txPool.mtx.Lock()
+	numExpired := harness.txPool.expireTransactions(time.Now())
+	harness.txPool.mtx.Unlock()
+
+	if numExpired != 0 {
+		t.Fatalf("expireTransactions: expired %d fresh transactions",
+			numExpired)
+	}
+	harness.txPool.mtx.Lock()
+	numExpired = harness.txPool.expireTransactions(time.Now().Add(2 *
+		time.Hour))
+	harness.txPool.mtx.Unlock()
+
+	if numExpired != len(chainedTxns) {
+		t.Fatalf("expireTransactions: want %d expired, got %d",
+			len(chainedTxns), numExpired)
+	}
+
+	if harness.txPool.poolSize != 0 {
+		t.Fatalf("pool size after expiry want 0, got %d",
+			harness.txPool.poolSize)
+	}
+}
@@ -0,0 +1,120 @@
+package mempool
+
+import (
+	"bytes"
+	"sort"
+	"time"
+
+	"git.parallelcoin.io/dev/9/pkg/rpc/json"
+	"git.parallelcoin.io/dev/9/pkg/util"
+)
+
+// RawMempoolFilter selects and pages the entries of the main pool returned to RPC clients. The zero value selects every entry.
+type RawMempoolFilter struct {
+	// Skip is the number of matching entries to leave out of the start of the result.
+	Skip int
+	// Count is the maximum number of entries to return. Zero disables the limit.
+	Count int
+	// MinFeeRate is the minimum fee rate in satoshi per kB an entry must pay to be selected.
+	MinFeeRate int64
+	// MinAge is the minimum amount of time an entry must have been in the pool to be selected.
+	MinAge time.Duration
+	// MaxAge is the maximum amount of time an entry may have been in the pool to be selected. Zero disables the limit.
+	MaxAge time.Duration
+}
+
+// filteredDescs returns the entries of the main pool matching the passed filter, ordered by the time they entered the pool, oldest first, and then by hash, so consecutive pages are stable while the pool only grows. This function MUST be called with the mempool lock held (for reads).
+func (
+	mp *TxPool,
+) filteredDescs(
+	f *RawMempoolFilter) []*TxDesc {
+
+	now := time.Now()
+	descs := make([]*TxDesc, 0, len(mp.pool))
+
+	for _, desc := range mp.pool {
+
+		age := now.Sub(desc.Added)
+
+		if desc.FeePerKB < f.MinFeeRate || age < f.MinAge ||
+			(f.MaxAge > 0 && age > f.MaxAge) {
+
+			continue
+		}
+		descs = append(descs, desc)
+	}
+	sort.Slice(descs, func(i, j int) bool {
+
+		if !descs[i].Added.Equal(descs[j].Added) {
+
+			return descs[i].Added.Before(descs[j].Added)
+		}
+		return bytes.Compare(descs[i].Tx.Hash()[:],
+			descs[j].Tx.Hash()[:]) < 0
+	})
+
+	if f.Skip >= len(descs) {
+
+		return descs[:0]
+	}
+
+	if f.Skip > 0 {
+
+		descs = descs[f.Skip:]
+	}
+
+	if f.Count > 0 && f.Count < len(descs) {
+
+		descs = descs[:f.Count]
+	}
+	return descs
+}
+
+// FilteredTxDescs returns a page of the descriptors of the entries in the main pool matching the passed filter. This function is safe for concurrent access.
+func (
+	mp *TxPool,
+) FilteredTxDescs(
+	f *RawMempoolFilter) []*TxDesc {
+
+	mp.mtx.RLock()
+	defer mp.mtx.RUnlock()
+	return mp.filteredDescs(f)
+}
+
+// RawMempoolVerboseFiltered returns a page of the entries in the mempool matching the passed filter as fully populated json results. This function is safe for concurrent access.
+func (
+	mp *TxPool,
+) RawMempoolVerboseFiltered(
+	f *RawMempoolFilter) map[string]*json.GetRawMempoolVerboseResult {
+
+	mp.mtx.RLock()
+	defer mp.mtx.RUnlock()
+	descs := mp.filteredDescs(f)
+	result := make(map[string]*json.GetRawMempoolVerboseResult, len(descs))
+	bestHeight := mp.cfg.BestHeight()
+
+	for _, desc := range descs {
+
+		result[desc.Tx.Hash().String()] = mp.verboseEntry(desc, bestHeight)
+	}
+	return result
+}
+
+// RawMempoolCompact returns a page of the entries in the mempool matching the passed filter as their hash and fee rate only, in the order of the page. This function is safe for concurrent access.
+func (
+	mp *TxPool,
+) RawMempoolCompact(
+	f *RawMempoolFilter) []json.GetRawMempoolCompactResult {
+
+	descs := mp.FilteredTxDescs(f)
+	result := make([]json.GetRawMempoolCompactResult, len(descs))
+
+	for i, desc := range descs {
+
+		result[i] = json.GetRawMempoolCompactResult{
+			TxID:    desc.Tx.Hash().String(),
+			FeeRate: util.Amount(desc.FeePerKB).ToDUO(),
+		}
+	}
+	return result
+}
@@ -0,0 +1,78 @@
+package mempool
+
+import (
+	"testing"
+	"time"
+
+	chaincfg "git.parallelcoin.io/dev/9/pkg/chain/config"
+)
+
+// TestFilteredTxDescs ensures the main pool entries are paged in the order they entered the pool and filtered by fee rate and age.
+func TestFilteredTxDescs(
+	t *testing.T) {
+
+	t.Parallel()
+	harness, spendableOuts, err := newPoolHarness(&chaincfg.MainNetParams)
+
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	chainedTxns, err := harness.CreateTxChain(spendableOuts[0], 4)
+
+	if err != nil {
+		t.Fatalf("unable to create transaction chain: %v", err)
+	}
+
+	for _, tx := range chainedTxns {
+		_, err := harness.txPool.ProcessTransaction(tx, false, false, 0)
+
+		if err != nil {
+			t.Fatalf("ProcessTransaction: failed to accept tx: %v", err)
+		}
+	}
+	// Give each entry a distinct age and fee rate, the oldest paying the least.
+	now := time.Now()
+
+	for i, tx := range chainedTxns {
+		desc := harness.txPool.pool[*tx.Hash()]
+		desc.Added = now.Add(-time.Duration(len(chainedTxns)-i) * time.Hour)
+		desc.FeePerKB = int64(i) * 1000
+	}
+	tests := []struct {
+		name   string
+		filter RawMempoolFilter
+		want   []int
+	}{
+		{"all", RawMempoolFilter{}, []int{0, 1, 2, 3}},
+		{"page", RawMempoolFilter{Skip: 1, Count: 2}, []int{1, 2}},
+		{"skip all", RawMempoolFilter{Skip: 4}, []int{}},
+		{"fee rate", RawMempoolFilter{MinFeeRate: 2000}, []int{2, 3}},
+		{"min age", RawMempoolFilter{MinAge: 150 * time.Minute}, []int{0, 1}},
+		{"max age", RawMempoolFilter{MaxAge: 150 * time.Minute}, []int{2, 3}},
+		{"fee rate page", RawMempoolFilter{MinFeeRate: 1000, Skip: 1,
+			Count: 1}, []int{2}},
+	}
+
+	for _, test := range tests {
+		descs := harness.txPool.FilteredTxDescs(&test.filter)
+
+		if len(descs) != len(test.want) {
+			t.Fatalf("%s: want %d entries, got %d", test.name,
+				len(test.want), len(descs))
+		}
+
+		for i, desc := range descs {
+
+			if desc.Tx != chainedTxns[test.want[i]] {
+				t.Fatalf("%s: entry %d want tx %d", test.name, i,
+					test.want[i])
+			}
+		}
+	}
+	compact := harness.txPool.RawMempoolCompact(&RawMempoolFilter{
+		MinFeeRate: 3000})
+
+	if len(compact) != 1 || compact[0].TxID != chainedTxns[3].Hash().String() {
+		t.Fatalf("RawMempoolCompact: unexpected result %v", compact)
+	}
+}
@@ -0,0 +1,261 @@
+package mempool
+
+import (
+	"fmt"
+
+	chainhash "git.parallelcoin.io/dev/9/pkg/chain/hash"
+	"git.parallelcoin.io/dev/9/pkg/chain/wire"
+	"git.parallelcoin.io/dev/9/pkg/util"
+)
+
+const (
+	// MaxRBFSequence is the maximum sequence number an input can use to signal that the transaction spending it can be replaced using the Replace-By-Fee (RBF) policy described in BIP125.
+	MaxRBFSequence = 0xfffffffd
+	// MaxReplacementEvictions is the maximum number of transactions that can be evicted from the mempool when accepting a transaction replacement.
+	MaxReplacementEvictions = 100
+)
+
+// signalsReplacement determines if a transaction is signaling that it can be replaced using the Replace-By-Fee (RBF) policy.  This policy specifies two ways a transaction can signal that it is replaceable:
+// Explicit signaling: A transaction is considered to have opted in to allowing replacement of itself if any of its inputs have a sequence number less than 0xfffffffe.
+// Inherited signaling: Transactions that don't explicitly signal replaceability are replaceable under this policy for as long as any one of their ancestors signals replaceability and remains unconfirmed.
+// The cache is optional and serves as an optimization to avoid visiting transactions already known not to signal replacement. This function MUST be called with the mempool lock held (for reads).
+func (
+	mp *TxPool,
+) signalsReplacement(
+	tx *util.Tx, cache map[chainhash.Hash]struct{}) bool {
+
+	if cache == nil {
+
+		cache = make(map[chainhash.Hash]struct{})
+	}
+
+	for _, txIn := range tx.MsgTx().TxIn {
+
+		if txIn.Sequence <= MaxRBFSequence {
+
+			return true
+		}
+		hash := txIn.PreviousOutPoint.Hash
+		unconfirmedAncestor, ok := mp.pool[hash]
+
+		if !ok {
+
+			continue
+		}
+		// If we've already determined the transaction doesn't signal replacement, we can avoid visiting it again.
+
+		if _, ok := cache[hash]; ok {
+
+			continue
+		}
+
+		if mp.signalsReplacement(unconfirmedAncestor.Tx, cache) {
+
+			return true
+		}
+		// Since the transaction doesn't signal replacement, we'll cache its result to ensure we don't attempt to determine so again.
+		cache[hash] = struct{}{}
+	}
+	return false
+}
+
+// txAncestors returns all of the unconfirmed ancestors of the given transaction. Given transactions A, B, and C where C spends B and B spends A, A and B are considered ancestors of C. The cache is optional and serves as an optimization to avoid visiting transactions we've already determined ancestors of. This function MUST be called with the mempool lock held (for reads).
+func (
+	mp *TxPool,
+) txAncestors(
+	tx *util.Tx, cache map[chainhash.Hash]map[chainhash.Hash]*util.Tx) map[chainhash.Hash]*util.Tx {
+
+	if cache == nil {
+
+		cache = make(map[chainhash.Hash]map[chainhash.Hash]*util.Tx)
+	}
+	ancestors := make(map[chainhash.Hash]*util.Tx)
+
+	for _, txIn := range tx.MsgTx().TxIn {
+
+		parent, ok := mp.pool[txIn.PreviousOutPoint.Hash]
+
+		if !ok {
+
+			continue
+		}
+		ancestors[*parent.Tx.Hash()] = parent.Tx
+		// Determine if the ancestors of this ancestor have already been computed. If they haven't, we'll do so now and cache them to use them later on if necessary.
+		moreAncestors, ok := cache[*parent.Tx.Hash()]
+
+		if !ok {
+
+			moreAncestors = mp.txAncestors(parent.Tx, cache)
+			cache[*parent.Tx.Hash()] = moreAncestors
+		}
+
+		for hash, ancestor := range moreAncestors {
+
+			ancestors[hash] = ancestor
+		}
+	}
+	return ancestors
+}
+
+// txDescendants returns all of the unconfirmed descendants of the given transaction. Given transactions A, B, and C where C spends B and B spends A, B and C are considered descendants of A. A cache can be provided in order to easily retrieve the descendants of transactions we've already determined the descendants of. This function MUST be called with the mempool lock held (for reads).
+func (
+	mp *TxPool,
+) txDescendants(
+	tx *util.Tx, cache map[chainhash.Hash]map[chainhash.Hash]*util.Tx) map[chainhash.Hash]*util.Tx {
+
+	if cache == nil {
+
+		cache = make(map[chainhash.Hash]map[chainhash.Hash]*util.Tx)
+	}
+	// We'll go through all of the outputs of the transaction to determine if they are spent by any other mempool transactions.
+	descendants := make(map[chainhash.Hash]*util.Tx)
+	op := wire.OutPoint{Hash: *tx.Hash()}
+
+	for i := range tx.MsgTx().TxOut {
+
+		op.Index = uint32(i)
+		descendant, ok := mp.outpoints[op]
+
+		if !ok {
+
+			continue
+		}
+		descendants[*descendant.Hash()] = descendant
+		// Determine if the descendants of this descendant have already been computed. If they haven't, we'll do so now and cache them to use them later on if necessary.
+		moreDescendants, ok := cache[*descendant.Hash()]
+
+		if !ok {
+
+			moreDescendants = mp.txDescendants(descendant, cache)
+			cache[*descendant.Hash()] = moreDescendants
+		}
+
+		for _, moreDescendant := range moreDescendants {
+
+			descendants[*moreDescendant.Hash()] = moreDescendant
+		}
+	}
+	return descendants
+}
+
+// txConflicts returns all of the unconfirmed transactions that would become conflicts if the given transaction were to be accepted into the mempool. An unconfirmed conflict is known as a transaction that spends an output already spent by a different transaction within the mempool. Any descendants of these transactions are also considered conflicts as they would no longer exist. These are generally not allowed except for transactions that signal RBF support. This function MUST be called with the mempool lock held (for reads).
+func (
+	mp *TxPool,
+) txConflicts(
+	tx *util.Tx) map[chainhash.Hash]*util.Tx {
+
+	conflicts := make(map[chainhash.Hash]*util.Tx)
+
+	for _, txIn := range tx.MsgTx().TxIn {
+
+		conflict, ok := mp.outpoints[txIn.PreviousOutPoint]
+
+		if !ok {
+
+			continue
+		}
+		conflicts[*conflict.Hash()] = conflict
+
+		for hash, descendant := range mp.txDescendants(conflict, nil) {
+
+			conflicts[hash] = descendant
+		}
+	}
+	return conflicts
+}
+
+// validateReplacement determines whether a transaction is deemed as a valid replacement of all of its conflicts according to the RBF policy. If it is valid, no error is returned. Otherwise, an error is returned indicating what went wrong. This function MUST be called with the mempool lock held (for reads).
+func (
+	mp *TxPool,
+) validateReplacement(
+	tx *util.Tx, txFee int64) (map[chainhash.Hash]*util.Tx, error) {
+
+	// First, we'll make sure the set of conflicting transactions doesn't exceed the maximum allowed.
+	conflicts := mp.txConflicts(tx)
+
+	if len(conflicts) > MaxReplacementEvictions {
+
+		str := fmt.Sprintf("replacement transaction %v evicts more "+
+			"transactions than permitted: max is %v, evicts %v",
+			tx.Hash(), MaxReplacementEvictions, len(conflicts))
+		return nil, txRuleError(wire.RejectNonstandard, str)
+	}
+	// The set of conflicts (transactions we'll replace) and ancestors should not overlap, otherwise the replacement would be spending an output that no longer exists.
+
+	for ancestorHash := range mp.txAncestors(tx, nil) {
+
+		if _, ok := conflicts[ancestorHash]; !ok {
+
+			continue
+		}
+		str := fmt.Sprintf("replacement transaction %v spends parent "+
+			"transaction %v", tx.Hash(), ancestorHash)
+		return nil, txRuleError(wire.RejectInvalid, str)
+	}
+	// The replacement should have a higher fee rate than each of the conflicting transactions and a higher absolute fee than the fee sum of all the conflicting transactions.
+	// We usually don't want to accept replacements with lower fee rates than what they replaced even if they have a higher absolute fee. It's also possible for a replacement to have a higher fee rate but a lower absolute fee, so we'll make sure to check that as well.
+	var (
+		txSize           = GetTxVirtualSize(tx)
+		txFeeRate        = txFee * 1000 / txSize
+		conflictsFee     int64
+		conflictsParents = make(map[chainhash.Hash]struct{})
+	)
+
+	for hash, conflict := range conflicts {
+
+		desc := mp.pool[hash]
+
+		if txFeeRate <= desc.FeePerKB {
+
+			str := fmt.Sprintf("replacement transaction %v has an "+
+				"insufficient fee rate: needs more than %v, "+
+				"has %v", tx.Hash(), desc.FeePerKB, txFeeRate)
+			return nil, txRuleError(wire.RejectInsufficientFee, str)
+		}
+		conflictsFee += desc.Fee
+		// We'll track each conflict's parents to ensure the replacement isn't spending any new unconfirmed inputs.
+
+		for _, txIn := range conflict.MsgTx().TxIn {
+
+			conflictsParents[txIn.PreviousOutPoint.Hash] = struct{}{}
+		}
+	}
+	// It should also have an absolute fee greater than all of the transactions it intends to replace and pay for its own bandwidth, which is determined by applying the minimum relay fee to the replacement's size.
+
+	if txFee < conflictsFee {
+
+		str := fmt.Sprintf("replacement transaction %v has an "+
+			"insufficient absolute fee: needs %v, has %v",
+			tx.Hash(), conflictsFee, txFee)
+		return nil, txRuleError(wire.RejectInsufficientFee, str)
+	}
+	minFee := calcMinRequiredTxRelayFee(txSize, mp.cfg.Policy.MinRelayTxFee)
+
+	if txFee-conflictsFee < minFee {
+
+		str := fmt.Sprintf("replacement transaction %v has an "+
+			"insufficient fee for its bandwidth: needs %v, has %v",
+			tx.Hash(), conflictsFee+minFee, txFee)
+		return nil, txRuleError(wire.RejectInsufficientFee, str)
+	}
+	// Finally, it should not spend any new unconfirmed outputs, other than the ones already included in the parents of the conflicting transactions it'll replace.
+
+	for _, txIn := range tx.MsgTx().TxIn {
+
+		if _, ok := conflictsParents[txIn.PreviousOutPoint.Hash]; ok {
+
+			continue
+		}
+		// Confirmed outputs are valid to spend in the replacement.
+
+		if _, ok := mp.pool[txIn.PreviousOutPoint.Hash]; !ok {
+
+			continue
+		}
+		str := fmt.Sprintf("replacement transaction spends new "+
+			"unconfirmed input %v not found in conflicting "+
+			"transactions", txIn.PreviousOutPoint)
+		return nil, txRuleError(wire.RejectInvalid, str)
+	}
+	return conflicts, nil
+}
@@ -0,0 +1,178 @@
+package mempool
+
+import (
+	"testing"
+
+	chaincfg "git.parallelcoin.io/dev/9/pkg/chain/config"
+	txscript "git.parallelcoin.io/dev/9/pkg/chain/tx/script"
+	"git.parallelcoin.io/dev/9/pkg/chain/wire"
+	"git.parallelcoin.io/dev/9/pkg/util"
+)
+
+// CreateReplaceableTx creates a new signed transaction that consumes the provided inputs with the given sequence number and pays the provided fee, sending the remainder to a single output paying to the harness payment script.
+func (p *poolHarness) CreateReplaceableTx(inputs []spendableOutput, fee util.Amount, sequence uint32) (*util.Tx, error) {
+
+	var totalInput util.Amount
+
+	for _, input := range inputs {
+		totalInput += input.amount
+	}
+	tx := wire.NewMsgTx(wire.TxVersion)
+
+	for _, input := range inputs {
+		tx.AddTxIn(&wire.TxIn{
+			PreviousOutPoint: input.outPoint,
+			SignatureScript:  nil,
+			Sequence:         sequence,
+		})
+	}
+	tx.AddTxOut(&wire.TxOut{
+		PkScript: p.payScript,
+		Value:    int64(totalInput - fee),
+	})
+	// Sign the new transaction.
+
+	for i := range tx.TxIn {
+		sigScript, err := txscript.SignatureScript(tx, i, p.payScript,
+			txscript.SigHashAll, p.signKey, true)
+
+		if err != nil {
+
+			return nil, err
+		}
+		tx.TxIn[i].SignatureScript = sigScript
+	}
+	return util.NewTx(tx), nil
+}
+
+// TestSignalsReplacement ensures that explicit and inherited replacement signaling is detected correctly.
+func TestSignalsReplacement(
+	t *testing.T) {
+
+	t.Parallel()
+	harness, outputs, err := newPoolHarness(&chaincfg.MainNetParams)
+
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	harness.txPool.cfg.Policy.AcceptReplacement = true
+	parent, err := harness.CreateReplaceableTx(outputs[0:1], 1000,
+		MaxRBFSequence)
+
+	if err != nil {
+		t.Fatalf("unable to create parent transaction: %v", err)
+	}
+	_, err = harness.txPool.ProcessTransaction(parent, false, false, 0)
+
+	if err != nil {
+		t.Fatalf("ProcessTransaction: failed to accept parent: %v", err)
+	}
+	child, err := harness.CreateReplaceableTx(
+		[]spendableOutput{txOutToSpendableOut(parent, 0)}, 1000,
+		wire.MaxTxInSequenceNum)
+
+	if err != nil {
+		t.Fatalf("unable to create child transaction: %v", err)
+	}
+	_, err = harness.txPool.ProcessTransaction(child, false, false, 0)
+
+	if err != nil {
+		t.Fatalf("ProcessTransaction: failed to accept child: %v", err)
+	}
+	harness.txPool.mtx.RLock()
+	defer harness.txPool.mtx.RUnlock()
+
+	if !harness.txPool.signalsReplacement(parent, nil) {
+		t.Fatalf("expected parent to explicitly signal replacement")
+	}
+
+	if !harness.txPool.signalsReplacement(child, nil) {
+		t.Fatalf("expected child to inherit replacement signaling")
+	}
+}
+
+// TestReplacement ensures that replacement transactions are only accepted when the policy allows them, the original signals replaceability, and the replacement pays a higher fee.
+func TestReplacement(
+	t *testing.T) {
+
+	t.Parallel()
+	tests := []struct {
+		name           string
+		accept         bool
+		sequence       uint32
+		replacementFee util.Amount
+		wantReplaced   bool
+	}{
+		{
+			name:           "policy disabled",
+			accept:         false,
+			sequence:       MaxRBFSequence,
+			replacementFee: 10000,
+			wantReplaced:   false,
+		},
+		{
+			name:           "original does not signal",
+			accept:         true,
+			sequence:       wire.MaxTxInSequenceNum,
+			replacementFee: 10000,
+			wantReplaced:   false,
+		},
+		{
+			name:           "insufficient fee",
+			accept:         true,
+			sequence:       MaxRBFSequence,
+			replacementFee: 1000,
+			wantReplaced:   false,
+		},
+		{
+			name:           "valid replacement",
+			accept:         true,
+			sequence:       MaxRBFSequence,
+			replacementFee: 10000,
+			wantReplaced:   true,
+		},
+	}
+
+	for _, test := range tests {
+		harness, outputs, err := newPoolHarness(&chaincfg.MainNetParams)
+
+		if err != nil {
+			t.Fatalf("unable to create test pool: %v", err)
+		}
+		harness.txPool.cfg.Policy.AcceptReplacement = test.accept
+		original, err := harness.CreateReplaceableTx(outputs[0:1], 1000,
+			test.sequence)
+
+		if err != nil {
+			t.Fatalf("%s: unable to create original: %v", test.name, err)
+		}
+		_, err = harness.txPool.ProcessTransaction(original, false,
+			false, 0)
+
+		if err != nil {
+			t.Fatalf("%s: failed to accept original: %v", test.name, err)
+		}
+		// Use a different fee so the replacement has a distinct hash from the original.
+		replacement, err := harness.CreateReplaceableTx(outputs[0:1],
+			test.replacementFee+1, wire.MaxTxInSequenceNum)
+
+		if err != nil {
+			t.Fatalf("%s: unable to create replacement: %v", test.name,
+				err)
+		}
+		_, err = harness.txPool.ProcessTransaction(replacement, false,
+			false, 0)
+
+		if test.wantReplaced && err != nil {
+			t.Fatalf("%s: failed to accept replacement: %v", test.name,
+				err)
+		}
+
+		if !test.wantReplaced && err == nil {
+			t.Fatalf("%s: replacement unexpectedly accepted", test.name)
+		}
+		tc := &testContext{t, harness}
+		testPoolMembership(tc, original, false, !test.wantReplaced)
+		testPoolMembership(tc, replacement, false, test.wantReplaced)
+	}
+}
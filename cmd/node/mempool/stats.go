@@ -0,0 +1,164 @@
+package mempool
+
+import (
+	"sort"
+	"time"
+)
+
+// feeRateBuckets are the lower bounds in satoshi per virtual byte of the buckets of the mempool fee rate histogram. The last bucket is open ended.
+var feeRateBuckets = [...]int64{
+	0, 1, 2, 3, 4, 5, 6, 7, 8, 10, 12, 14, 17, 20, 25, 30, 40, 50, 60, 70,
+	80, 100, 120, 140, 170, 200, 250, 300, 400, 500, 600, 700, 800, 1000,
+	1200, 1400, 1700, 2000, 2500, 3000, 4000, 5000, 10000,
+}
+
+// ageBuckets are the upper bounds of the buckets of the mempool entry age histogram. The last bucket is open ended.
+var ageBuckets = []time.Duration{
+	time.Minute * 10,
+	time.Hour,
+	time.Hour * 6,
+	time.Hour * 24,
+	time.Hour * 72,
+	time.Hour * 168,
+}
+
+// FeeRateBucket is one bucket of the mempool fee rate histogram.
+type FeeRateBucket struct {
+	// MinFeeRate is the lower bound of the bucket in satoshi per virtual byte, inclusive.
+	MinFeeRate int64
+	// Count is the number of transactions in the bucket.
+	Count int64
+	// VSize is the total virtual size of the transactions in the bucket.
+	VSize int64
+	// Fees is the total fees of the transactions in the bucket in satoshi.
+	Fees int64
+}
+
+// AgeBucket is one bucket of the mempool entry age histogram.
+type AgeBucket struct {
+	// MaxAge is the upper bound of the age of the transactions in the bucket, exclusive. It is zero for the last, open ended, bucket.
+	MaxAge time.Duration
+	// Count is the number of transactions in the bucket.
+	Count int64
+}
+
+// Stats houses a snapshot of the statistics of the main pool.
+type Stats struct {
+	// Count is the number of transactions in the main pool.
+	Count int64
+	// VSize is the total virtual size of the transactions in the main pool.
+	VSize int64
+	// Orphans is the number of transactions in the orphan pool.
+	Orphans int64
+	// FeeHistogram is the distribution of the transactions in the main pool by fee rate, ordered from the lowest fee rate.
+	FeeHistogram []FeeRateBucket
+	// AgeHistogram is the distribution of the transactions in the main pool by the time since they were added, ordered from the youngest.
+	AgeHistogram []AgeBucket
+}
+
+// poolStats keeps the histograms of the main pool up to date as transactions are added and removed so they never require a full scan of the pool. The entries are counted by the minute they were added in so the age distribution only needs to walk the distinct minutes.
+type poolStats struct {
+	fees     [len(feeRateBuckets)]FeeRateBucket
+	byMinute map[int64]int64
+}
+
+// newPoolStats returns an empty set of mempool statistics.
+func newPoolStats() *poolStats {
+
+	ps := &poolStats{byMinute: make(map[int64]int64)}
+
+	for i, min := range feeRateBuckets {
+
+		ps.fees[i].MinFeeRate = min
+	}
+	return ps
+}
+
+// add records a transaction that has been added to the main pool.
+func (
+	ps *poolStats,
+) add(
+	txD *TxDesc, size int64) {
+
+	b := &ps.fees[feeRateBucket(txD.Fee, size)]
+	b.Count++
+	b.VSize += size
+	b.Fees += txD.Fee
+	ps.byMinute[txD.Added.Unix()/60]++
+}
+
+// remove records a transaction that has been removed from the main pool.
+func (
+	ps *poolStats,
+) remove(
+	txD *TxDesc, size int64) {
+
+	b := &ps.fees[feeRateBucket(txD.Fee, size)]
+	b.Count--
+	b.VSize -= size
+	b.Fees -= txD.Fee
+	minute := txD.Added.Unix() / 60
+
+	if ps.byMinute[minute]--; ps.byMinute[minute] <= 0 {
+
+		delete(ps.byMinute, minute)
+	}
+}
+
+// ageHistogram returns the age distribution of the main pool at the given time.
+func (
+	ps *poolStats,
+) ageHistogram(
+	now time.Time) []AgeBucket {
+
+	buckets := make([]AgeBucket, len(ageBuckets)+1)
+
+	for i, max := range ageBuckets {
+
+		buckets[i].MaxAge = max
+	}
+	nowMinute := now.Unix() / 60
+
+	for minute, count := range ps.byMinute {
+
+		age := time.Duration(nowMinute-minute) * time.Minute
+		i := sort.Search(len(ageBuckets), func(i int) bool {
+			return age < ageBuckets[i]
+		})
+		buckets[i].Count += count
+	}
+	return buckets
+}
+
+// Stats returns a snapshot of the statistics of the main and orphan pools. This function is safe for concurrent access.
+func (
+	mp *TxPool,
+) Stats() Stats {
+
+	mp.mtx.RLock()
+	defer mp.mtx.RUnlock()
+	stats := Stats{
+		Count:        int64(len(mp.pool)),
+		VSize:        mp.poolSize,
+		Orphans:      int64(len(mp.orphans)),
+		FeeHistogram: make([]FeeRateBucket, len(feeRateBuckets)),
+		AgeHistogram: mp.stats.ageHistogram(time.Now()),
+	}
+	copy(stats.FeeHistogram, mp.stats.fees[:])
+	return stats
+}
+
+// feeRateBucket returns the index of the fee rate histogram bucket a transaction paying the given fee for the given virtual size falls into.
+func feeRateBucket(
+	fee, size int64) int {
+
+	var feeRate int64
+
+	if size > 0 {
+
+		feeRate = fee / size
+	}
+	return sort.Search(len(feeRateBuckets), func(i int) bool {
+		return feeRateBuckets[i] > feeRate
+	}) - 1
+}
@@ -0,0 +1,63 @@
+package mempool
+
+import (
+	"testing"
+	"time"
+
+	chaincfg "git.parallelcoin.io/dev/9/pkg/chain/config"
+)
+
+// TestStats ensures the mempool statistics are kept up to date as transactions are added and removed.
+func TestStats(
+	t *testing.T) {
+
+	t.Parallel()
+	harness, spendableOuts, err := newPoolHarness(&chaincfg.MainNetParams)
+
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	chainedTxns, err := harness.CreateTxChain(spendableOuts[0], 3)
+
+	if err != nil {
+		t.Fatalf("unable to create transaction chain: %v", err)
+	}
+	var vsize int64
+
+	for _, tx := range chainedTxns {
+		_, err := harness.txPool.ProcessTransaction(tx, false, false, 0)
+
+		if err != nil {
+			t.Fatalf("ProcessTransaction: failed to accept tx: %v", err)
+		}
+		vsize += GetTxVirtualSize(tx)
+	}
+	stats := harness.txPool.Stats()
+
+	if stats.Count != 3 || stats.VSize != vsize {
+		t.Fatalf("Stats: want 3 transactions of %d vbytes, got %d of %d",
+			vsize, stats.Count, stats.VSize)
+	}
+	// The chain pays no fees, so all transactions must be in the lowest fee rate bucket and, having just been added, the youngest age bucket.
+	if stats.FeeHistogram[0].Count != 3 {
+		t.Fatalf("Stats: want 3 transactions in the lowest fee rate "+
+			"bucket, got %d", stats.FeeHistogram[0].Count)
+	}
+
+	if stats.AgeHistogram[0].Count != 3 {
+		t.Fatalf("Stats: want 3 transactions in the youngest age "+
+			"bucket, got %d", stats.AgeHistogram[0].Count)
+	}
+	harness.txPool.RemoveTransaction(chainedTxns[0], true)
+	stats = harness.txPool.Stats()
+
+	if stats.Count != 0 || stats.VSize != 0 ||
+		stats.FeeHistogram[0].Count != 0 {
+		t.Fatalf("Stats: pool not empty after removal: %+v", stats)
+	}
+	age := harness.txPool.stats.ageHistogram(time.Now())
+
+	if age[0].Count != 0 {
+		t.Fatalf("age histogram not empty after removal: %+v", age)
+	}
+}
@@ -0,0 +1,182 @@
+package mempool
+
+import (
+	"fmt"
+
+	chainhash "git.parallelcoin.io/dev/9/pkg/chain/hash"
+	"git.parallelcoin.io/dev/9/pkg/chain/wire"
+	"git.parallelcoin.io/dev/9/pkg/util"
+)
+
+const (
+	// DefaultMaxAncestorCount is the default maximum number of in-mempool ancestors, including the transaction itself, a transaction may have to be accepted.
+	DefaultMaxAncestorCount = 25
+	// DefaultMaxAncestorSize is the default maximum total virtual size in bytes of a transaction and all of its in-mempool ancestors.
+	DefaultMaxAncestorSize = 101000
+	// DefaultMaxDescendantCount is the default maximum number of in-mempool descendants, including the transaction itself, any transaction in the mempool may have.
+	DefaultMaxDescendantCount = 25
+	// DefaultMaxDescendantSize is the default maximum total virtual size in bytes of any in-mempool transaction and all of its descendants.
+	DefaultMaxDescendantSize = 101000
+)
+
+// PackageInfo houses the aggregate statistics of a mempool transaction and its in-mempool ancestors and descendants.  Both the ancestor and the descendant aggregates include the transaction itself, so a transaction without any unconfirmed relatives has counts of one and sizes and fees equal to its own.
+type PackageInfo struct {
+	AncestorCount   int64
+	AncestorSize    int64
+	AncestorFees    int64
+	DescendantCount int64
+	DescendantSize  int64
+	DescendantFees  int64
+}
+
+// AncestorFeePerKB returns the fee rate in satoshi per kB of the transaction together with all of its in-mempool ancestors, which is the rate a miner actually gets when including the whole package in a block.
+func (
+	p *PackageInfo,
+) AncestorFeePerKB() int64 {
+
+	if p.AncestorSize == 0 {
+
+		return 0
+	}
+	return p.AncestorFees * 1000 / p.AncestorSize
+}
+
+// PackageInfo returns the aggregate ancestor and descendant statistics for the passed transaction.  The second return value is false if the transaction is not in the main pool. This function is safe for concurrent access.
+func (
+	mp *TxPool,
+) PackageInfo(
+	hash *chainhash.Hash) (PackageInfo, bool) {
+
+	mp.mtx.RLock()
+	pkg, ok := mp.packages[*hash]
+	mp.mtx.RUnlock()
+
+	if !ok {
+
+		return PackageInfo{}, false
+	}
+	return *pkg, true
+}
+
+// checkPackageLimits ensures that accepting the passed transaction would not cause it to exceed the configured ancestor limits, nor cause any of its in-mempool ancestors to exceed the configured descendant limits.  A limit of zero disables the corresponding check. This function MUST be called with the mempool lock held (for reads).
+func (
+	mp *TxPool,
+) checkPackageLimits(
+	tx *util.Tx, ancestors map[chainhash.Hash]*util.Tx) error {
+
+	policy := &mp.cfg.Policy
+	txSize := GetTxVirtualSize(tx)
+	ancestorCount := int64(len(ancestors)) + 1
+	ancestorSize := txSize
+
+	if policy.MaxAncestorCount > 0 &&
+		ancestorCount > int64(policy.MaxAncestorCount) {
+
+		str := fmt.Sprintf("transaction %v has too many unconfirmed "+
+			"ancestors: %d > %d", tx.Hash(), ancestorCount,
+			policy.MaxAncestorCount)
+		return txRuleError(wire.RejectNonstandard, str)
+	}
+
+	for hash := range ancestors {
+
+		ancestorSize += GetTxVirtualSize(mp.pool[hash].Tx)
+		pkg := mp.packages[hash]
+
+		if policy.MaxDescendantCount > 0 &&
+			pkg.DescendantCount+1 > int64(policy.MaxDescendantCount) {
+
+			str := fmt.Sprintf("transaction %v would exceed the "+
+				"descendant count limit of ancestor %v: %d > %d",
+				tx.Hash(), hash, pkg.DescendantCount+1,
+				policy.MaxDescendantCount)
+			return txRuleError(wire.RejectNonstandard, str)
+		}
+
+		if policy.MaxDescendantSize > 0 &&
+			pkg.DescendantSize+txSize > int64(policy.MaxDescendantSize) {
+
+			str := fmt.Sprintf("transaction %v would exceed the "+
+				"descendant size limit of ancestor %v: %d > %d",
+				tx.Hash(), hash, pkg.DescendantSize+txSize,
+				policy.MaxDescendantSize)
+			return txRuleError(wire.RejectNonstandard, str)
+		}
+	}
+
+	if policy.MaxAncestorSize > 0 &&
+		ancestorSize > int64(policy.MaxAncestorSize) {
+
+		str := fmt.Sprintf("transaction %v exceeds the unconfirmed "+
+			"ancestor size limit: %d > %d", tx.Hash(), ancestorSize,
+			policy.MaxAncestorSize)
+		return txRuleError(wire.RejectNonstandard, str)
+	}
+	return nil
+}
+
+// addPackageEntry records the aggregate statistics for a transaction that has just been added to the main pool and adds it to the descendant aggregates of all of its in-mempool ancestors.  Since a newly added transaction cannot yet have any descendants in the main pool, only the ancestor side needs to be computed. This function MUST be called with the mempool lock held (for writes).
+func (
+	mp *TxPool,
+) addPackageEntry(
+	txD *TxDesc) {
+
+	tx := txD.Tx
+	size := GetTxVirtualSize(tx)
+	pkg := &PackageInfo{
+		AncestorCount:   1,
+		AncestorSize:    size,
+		AncestorFees:    txD.Fee,
+		DescendantCount: 1,
+		DescendantSize:  size,
+		DescendantFees:  txD.Fee,
+	}
+
+	for hash, ancestor := range mp.txAncestors(tx, nil) {
+
+		desc := mp.pool[hash]
+		pkg.AncestorCount++
+		pkg.AncestorSize += GetTxVirtualSize(ancestor)
+		pkg.AncestorFees += desc.Fee
+		ancestorPkg := mp.packages[hash]
+		ancestorPkg.DescendantCount++
+		ancestorPkg.DescendantSize += size
+		ancestorPkg.DescendantFees += txD.Fee
+		mp.fixEvictionEntry(hash)
+	}
+	mp.packages[*tx.Hash()] = pkg
+	mp.pushEvictionEntry(txD, pkg)
+}
+
+// removePackageEntry removes the aggregate statistics for a transaction that is about to be removed from the main pool and subtracts it from the aggregates of all of its remaining in-mempool relatives.  It must be called while the transaction is still present in the pool so its relatives can be located. This function MUST be called with the mempool lock held (for writes).
+func (
+	mp *TxPool,
+) removePackageEntry(
+	txD *TxDesc) {
+
+	tx := txD.Tx
+	size := GetTxVirtualSize(tx)
+
+	for hash := range mp.txAncestors(tx, nil) {
+
+		if pkg, ok := mp.packages[hash]; ok {
+
+			pkg.DescendantCount--
+			pkg.DescendantSize -= size
+			pkg.DescendantFees -= txD.Fee
+			mp.fixEvictionEntry(hash)
+		}
+	}
+
+	for hash := range mp.txDescendants(tx, nil) {
+
+		if pkg, ok := mp.packages[hash]; ok {
+
+			pkg.AncestorCount--
+			pkg.AncestorSize -= size
+			pkg.AncestorFees -= txD.Fee
+		}
+	}
+	delete(mp.packages, *tx.Hash())
+	mp.removeEvictionEntry(*tx.Hash())
+}
@@ -0,0 +1,97 @@
+package mempool
+
+import (
+	"testing"
+
+	chaincfg "git.parallelcoin.io/dev/9/pkg/chain/config"
+)
+
+// TestPackageTracking ensures the ancestor and descendant aggregates of mempool entries are kept up to date as transactions are added and removed.
+func TestPackageTracking(
+	t *testing.T) {
+
+	t.Parallel()
+	harness, spendableOuts, err := newPoolHarness(&chaincfg.MainNetParams)
+
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	const txChainLength = 3
+	chainedTxns, err := harness.CreateTxChain(spendableOuts[0], txChainLength)
+
+	if err != nil {
+		t.Fatalf("unable to create transaction chain: %v", err)
+	}
+
+	for _, tx := range chainedTxns {
+		_, err := harness.txPool.ProcessTransaction(tx, false, false, 0)
+
+		if err != nil {
+			t.Fatalf("ProcessTransaction: failed to accept tx: %v", err)
+		}
+	}
+
+	for i, tx := range chainedTxns {
+		pkg, ok := harness.txPool.PackageInfo(tx.Hash())
+
+		if !ok {
+			t.Fatalf("PackageInfo: no entry for tx %d", i)
+		}
+
+		if pkg.AncestorCount != int64(i+1) {
+			t.Fatalf("tx %d: ancestor count want %d, got %d", i, i+1,
+				pkg.AncestorCount)
+		}
+
+		if pkg.DescendantCount != int64(txChainLength-i) {
+			t.Fatalf("tx %d: descendant count want %d, got %d", i,
+				txChainLength-i, pkg.DescendantCount)
+		}
+	}
+	// Removing the root as though it was mined must reduce the ancestor counts of the remaining transactions.
+	harness.txPool.RemoveTransaction(chainedTxns[0], false)
+	pkg, _ := harness.txPool.PackageInfo(chainedTxns[2].Hash())
+
+	if pkg.AncestorCount != 2 {
+		t.Fatalf("ancestor count after removal want 2, got %d",
+			pkg.AncestorCount)
+	}
+
+	if _, ok := harness.txPool.PackageInfo(chainedTxns[0].Hash()); ok {
+		t.Fatalf("PackageInfo: removed tx still has an entry")
+	}
+}
+
+// TestPackageLimits ensures transactions that would exceed the configured ancestor limit are rejected.
+func TestPackageLimits(
+	t *testing.T) {
+
+	t.Parallel()
+	harness, spendableOuts, err := newPoolHarness(&chaincfg.MainNetParams)
+
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	harness.txPool.cfg.Policy.MaxAncestorCount = 2
+	chainedTxns, err := harness.CreateTxChain(spendableOuts[0], 3)
+
+	if err != nil {
+		t.Fatalf("unable to create transaction chain: %v", err)
+	}
+
+	for _, tx := range chainedTxns[:2] {
+		_, err := harness.txPool.ProcessTransaction(tx, false, false, 0)
+
+		if err != nil {
+			t.Fatalf("ProcessTransaction: failed to accept tx: %v", err)
+		}
+	}
+	_, err = harness.txPool.ProcessTransaction(chainedTxns[2], false,
+		false, 0)
+
+	if err == nil {
+		t.Fatalf("ProcessTransaction: accepted tx exceeding ancestor limit")
+	}
+	testPoolMembership(&testContext{t, harness}, chainedTxns[2], false,
+		false)
+}
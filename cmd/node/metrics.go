@@ -0,0 +1,78 @@
+package node
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+	"git.parallelcoin.io/dev/9/cmd/node/mempool"
+	controller "git.parallelcoin.io/dev/9/pkg/chain/mining/dispatch"
+)
+// metricsPath is the path on the profile server the Prometheus metrics are served at.
+const metricsPath = "/metrics"
+// metricsHandler returns a http.Handler that writes the mempool statistics, and the transport metrics of the miner workers when the miner controller is not nil, in the Prometheus text exposition format. The statistics are kept up to date incrementally by the mempool so scraping does not scan the pool.
+func metricsHandler(txPool *mempool.TxPool, miner *controller.Controller) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		writeMempoolMetrics(w, txPool.Stats(), txPool.MinFeeRate().ToDUO())
+		if miner != nil {
+			writeWorkerMetrics(w, miner.Peers())
+		}
+	})
+}
+// writeMempoolMetrics writes the passed mempool statistics to w in the Prometheus text exposition format.
+func writeMempoolMetrics(w io.Writer, stats mempool.Stats, minFee float64) {
+	gauge := func(name, help string, value interface{}) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n%s %v\n", name, help, name, name, value)
+	}
+	gauge("pod_mempool_transactions", "Number of transactions in the mempool.", stats.Count)
+	gauge("pod_mempool_vsize_bytes", "Total virtual size of the transactions in the mempool.", stats.VSize)
+	gauge("pod_mempool_orphans", "Number of transactions in the orphan pool.", stats.Orphans)
+	gauge("pod_mempool_min_fee_rate", "Minimum fee rate in DUO/kB for a transaction to be accepted.", minFee)
+	fmt.Fprint(w, "# HELP pod_mempool_fee_rate_transactions Number of transactions in the mempool by fee rate bucket lower bound in satoshi per virtual byte.\n# TYPE pod_mempool_fee_rate_transactions gauge\n")
+	for _, b := range stats.FeeHistogram {
+		fmt.Fprintf(w, "pod_mempool_fee_rate_transactions{min=\"%d\"} %d\n", b.MinFeeRate, b.Count)
+	}
+	fmt.Fprint(w, "# HELP pod_mempool_fee_rate_vsize_bytes Total virtual size of the transactions in the mempool by fee rate bucket lower bound in satoshi per virtual byte.\n# TYPE pod_mempool_fee_rate_vsize_bytes gauge\n")
+	for _, b := range stats.FeeHistogram {
+		fmt.Fprintf(w, "pod_mempool_fee_rate_vsize_bytes{min=\"%d\"} %d\n", b.MinFeeRate, b.VSize)
+	}
+	fmt.Fprint(w, "# HELP pod_mempool_age_transactions Number of transactions in the mempool by age bucket upper bound in seconds.\n# TYPE pod_mempool_age_transactions gauge\n")
+	for _, b := range stats.AgeHistogram {
+		max := "+Inf"
+		if b.MaxAge > 0 {
+			max = fmt.Sprint(int64(b.MaxAge / time.Second))
+		}
+		fmt.Fprintf(w, "pod_mempool_age_transactions{max=\"%s\"} %d\n", max, b.Count)
+	}
+}
+// writeWorkerMetrics writes the transport metrics of the passed miner workers to w in the Prometheus text exposition format, labelled with the address and transport of each.
+func writeWorkerMetrics(w io.Writer, peers []controller.Peer) {
+	fmt.Fprintf(w, "# HELP pod_miner_workers Number of subscribed miner workers.\n# TYPE pod_miner_workers gauge\npod_miner_workers %d\n", len(peers))
+	metric := func(name, typ, help string, value func(controller.Peer) interface{}) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+		for _, p := range peers {
+			fmt.Fprintf(w, "%s{addr=%q,transport=%q} %v\n", name, p.Addr, p.Transport, value(p))
+		}
+	}
+	metric("pod_miner_worker_rtt_seconds", "gauge", "Round trip time of the last ping the worker answered.", func(p controller.Peer) interface{} {
+		return p.RTT.Seconds()
+	})
+	metric("pod_miner_worker_lost_messages_total", "counter", "Number of messages from the worker that never arrived or were rejected.", func(p controller.Peer) interface{} {
+		return p.Lost
+	})
+	metric("pod_miner_worker_rejected_messages_total", "counter", "Number of messages from the worker that failed HHMAC authentication.", func(p controller.Peer) interface{} {
+		return p.Rejected
+	})
+	metric("pod_miner_worker_replayed_messages_total", "counter", "Number of messages from the worker dropped as replayed or duplicated.", func(p controller.Peer) interface{} {
+		return p.Replayed
+	})
+	metric("pod_miner_worker_reconstructed_groups_total", "counter", "Number of FEC groups from the worker rebuilt from parity shards.", func(p controller.Peer) interface{} {
+		return p.Reconstructed
+	})
+	metric("pod_miner_worker_corrupt_shards_total", "counter", "Number of FEC shards from the worker that failed their checksum.", func(p controller.Peer) interface{} {
+		return p.Corrupt
+	})
+	metric("pod_miner_worker_loss_rate", "gauge", "Moving average of the share of FEC groups sent to the worker that had to be sent again.", func(p controller.Peer) interface{} {
+		return p.LossRate
+	})
+}
@@ -10,11 +10,13 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"math"
 	"math/big"
 	"math/rand"
 	"net"
 	"net/http"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -28,6 +30,7 @@ import (
 	indexers "git.parallelcoin.io/dev/9/pkg/chain/index"
 	"git.parallelcoin.io/dev/9/pkg/chain/mining"
 	cpuminer "git.parallelcoin.io/dev/9/pkg/chain/mining/cpu"
+	controller "git.parallelcoin.io/dev/9/pkg/chain/mining/dispatch"
 	txscript "git.parallelcoin.io/dev/9/pkg/chain/tx/script"
 	"git.parallelcoin.io/dev/9/pkg/chain/wire"
 	database "git.parallelcoin.io/dev/9/pkg/db"
@@ -50,6 +53,8 @@ type gbtWorkState struct {
 	notifyMap     map[chainhash.Hash]map[int64]chan struct{}
 	timeSource    blockchain.MedianTimeSource
 	algo          string
+	coinbaseAux   *json.GetBlockTemplateResultAux
+	controls      *mining.TxControls
 }
 // parsedRPCCmd represents a JSON-RPC request object that has been parsed into a known concrete command along with any error that might have happened while parsing it.
 type parsedRPCCmd struct {
@@ -77,6 +82,7 @@ type rpcServer struct {
 	statusLock             sync.RWMutex
 	wg                     sync.WaitGroup
 	gbtWorkState           *gbtWorkState
+	getworkState           *getworkState
 	helpCacher             *helpCacher
 	requestProcessShutdown chan struct{}
 	quit                   chan int
@@ -101,12 +107,19 @@ type rpcserverConfig struct {
 	// These fields allow the RPC server to interface with mining. Generator produces block templates and the CPUMiner solves them using the CPU.  CPU mining is typically only useful for test purposes when doing regression or simulation testing.
 	Generator *mining.BlkTmplGenerator
 	CPUMiner  *cpuminer.CPUMiner
+	// PayAddrs is the set of payment addresses shared by the miners, which the RPC server can change while they run.
+	PayAddrs *mining.PayAddrs
+	// MinerKeys are the API keys of the miner workers and MinerController the controller they are subscribed to, which are nil when the miner listener is not enabled.
+	MinerKeys       *controller.APIKeys
+	MinerController *controller.Controller
 	// These fields define any optional indexes the RPC server can make use of to provide additional data when queried.
 	TxIndex   *indexers.TxIndex
 	AddrIndex *indexers.AddrIndex
 	CfIndex   *indexers.CfIndex
 	// The fee estimator keeps track of how long transactions are left in the mempool before they are mined into blocks.
 	FeeEstimator *mempool.FeeEstimator
+	// Updates checks for new releases, and is nil when update checking is not enabled.
+	Updates *updateChecker
 	// Algo sets the algorithm expected from the RPC endpoint. This allows multiple ports to serve multiple types of miners with one main node per algorithm. Currently 514 for scrypt and anything else passes for sha256d. After hard fork 1 there is 9, and may be expanded in the future (equihash, cuckoo and cryptonight all require substantial block header/tx formatting changes)
 	Algo string
 }
@@ -262,13 +275,19 @@ var rpcAskWallet = map[string]struct{}{
 // rpcHandlers maps RPC command strings to appropriate handler functions. This is set by init because help references rpcHandlers and thus causes a dependency loop.
 var rpcHandlers map[string]commandHandler
 var rpcHandlersBeforeInit = map[string]commandHandler{
+	"addminerkey":          handleAddMinerKey,
+	"addminingaddress":     handleAddMiningAddress,
 	"addnode":              handleAddNode,
 	"createrawtransaction": handleCreateRawTransaction,
 	// "debuglevel":            handleDebugLevel,
 	"decoderawtransaction":  handleDecodeRawTransaction,
 	"decodescript":          handleDecodeScript,
+	"dumputxoset":           handleDumpUtxoSet,
 	"estimatefee":           handleEstimateFee,
+	"estimaterawfee":        handleEstimateRawFee,
+	"estimatesmartfee":      handleEstimateSmartFee,
 	"generate":              handleGenerate,
+	"generatetoaddress":     handleGenerateToAddress,
 	"getaddednodeinfo":      handleGetAddedNodeInfo,
 	"getbestblock":          handleGetBestBlock,
 	"getbestblockhash":      handleGetBestBlockHash,
@@ -278,30 +297,50 @@ var rpcHandlersBeforeInit = map[string]commandHandler{
 	"getblockhash":          handleGetBlockHash,
 	"getblockheader":        handleGetBlockHeader,
 	"getblocktemplate":      handleGetBlockTemplate,
+	"getchaintips":          handleGetChainTips,
+	"getchaintxstats":       handleGetChainTxStats,
 	"getcfilter":            handleGetCFilter,
 	"getcfilterheader":      handleGetCFilterHeader,
 	"getconnectioncount":    handleGetConnectionCount,
 	"getcurrentnet":         handleGetCurrentNet,
+	"getdifficulties":       handleGetDifficulties,
 	"getdifficulty":         handleGetDifficulty,
+	"getdustinfo":           handleGetDustInfo,
 	"getgenerate":           handleGetGenerate,
 	"gethashespersec":       handleGetHashesPerSec,
 	"getheaders":            handleGetHeaders,
 	"getinfo":               handleGetInfo,
+	"getmempoolentry":       handleGetMempoolEntry,
 	"getmempoolinfo":        handleGetMempoolInfo,
+	"getminerkeys":          handleGetMinerKeys,
+	"getminingaddresses":    handleGetMiningAddresses,
+	"getminingbias":         handleGetMiningBias,
 	"getmininginfo":         handleGetMiningInfo,
+	"getminingstats":        handleGetMiningStats,
 	"getnettotals":          handleGetNetTotals,
 	"getnetworkhashps":      handleGetNetworkHashPS,
+	"getorphaninfo":         handleGetOrphanInfo,
 	"getpeerinfo":           handleGetPeerInfo,
 	"getrawmempool":         handleGetRawMempool,
 	"getrawtransaction":     handleGetRawTransaction,
+	"gettemplatecontrols":   handleGetTemplateControls,
 	"gettxout":              handleGetTxOut,
+	"getupdateinfo":         handleGetUpdateInfo,
 	"getwork":               handleGetWork,
+	"getworkerinfo":         handleGetWorkerInfo,
 	"help":                  handleHelp,
+	"loadutxoset":           handleLoadUtxoSet,
 	"node":                  handleNode,
 	"ping":                  handlePing,
 	"searchrawtransactions": handleSearchRawTransactions,
 	"sendrawtransaction":    handleSendRawTransaction,
+	"removeminerkey":        handleRemoveMinerKey,
+	"removeminingaddress":   handleRemoveMiningAddress,
+	"setcoinbasetag":        handleSetCoinbaseTag,
 	"setgenerate":           handleSetGenerate,
+	"setminingbias":         handleSetMiningBias,
+	"settemplatecontrols":   handleSetTemplateControls,
+	"stageupdate":           handleStageUpdate,
 	"stop":                  handleStop,
 	"submitblock":           handleSubmitBlock,
 	"uptime":                handleUptime,
@@ -328,23 +367,31 @@ var rpcLimited = map[string]struct{}{
 	"decoderawtransaction":  {},
 	"decodescript":          {},
 	"estimatefee":           {},
+	"estimaterawfee":        {},
+	"estimatesmartfee":      {},
 	"getbestblock":          {},
 	"getbestblockhash":      {},
 	"getblock":              {},
 	"getblockcount":         {},
 	"getblockhash":          {},
 	"getblockheader":        {},
+	"getchaintips":          {},
+	"getchaintxstats":       {},
 	"getcfilter":            {},
 	"getcfilterheader":      {},
 	"getcurrentnet":         {},
+	"getdifficulties":       {},
 	"getdifficulty":         {},
+	"getdustinfo":           {},
 	"getheaders":            {},
 	"getinfo":               {},
+	"getmempoolentry":       {},
 	"getnettotals":          {},
 	"getnetworkhashps":      {},
 	"getrawmempool":         {},
 	"getrawtransaction":     {},
 	"gettxout":              {},
+	"getupdateinfo":         {},
 	"searchrawtransactions": {},
 	"sendrawtransaction":    {},
 	"submitblock":           {},
@@ -356,10 +403,7 @@ var rpcLimited = map[string]struct{}{
 // Commands that are currently unimplemented, but should ultimately be.
 var rpcUnimplemented = map[string]struct{}{
 	"estimatepriority": {},
-	"getchaintips":     {},
-	"getmempoolentry":  {},
 	"getnetworkinfo":   {},
-	"getwork":          {},
 	"invalidateblock":  {},
 	"preciousblock":    {},
 	"reconsiderblock":  {},
@@ -486,7 +530,7 @@ func (
 		reply.DefaultWitnessCommitment = hex.EncodeToString(template.WitnessCommitment)
 	}
 	if useCoinbaseValue {
-		reply.CoinbaseAux = gbtCoinbaseAux
+		reply.CoinbaseAux = state.coinbaseAux
 		reply.CoinbaseValue = &msgBlock.Transactions[0].TxOut[0].Value
 	} else {
 		// Ensure the template has a valid payment address associated with it when a full coinbase is requested.
@@ -577,6 +621,18 @@ func (
 	}
 	return c
 }
+// setTxControls sets the transaction controls of the getblocktemplate caller, which apply to the templates of every caller until they are changed. A template generated with other controls is dropped so the next update generates a new one. This function MUST be called with the state locked.
+func (
+	state *gbtWorkState,
+) setTxControls(
+	controls *mining.TxControls,
+) {
+	if state.controls.Equal(controls) {
+		return
+	}
+	state.controls = controls
+	state.template = nil
+}
 // updateBlockTemplate creates or updates a block template for the work state. A new block template will be generated when the current best block has changed or the transactions in the memory pool have been updated and it has been long enough since the last template was generated.  Otherwise, the timestamp for the existing block template is updated (and possibly the difficulty on testnet per the consesus rules).  Finally, if the useCoinbaseValue flag is false and the existing block template does not already contain a valid payment address, the block template will be updated with a randomly selected payment address from the list of configured addresses. This function MUST be called with the state locked.
 func (
 	state *gbtWorkState,
@@ -589,6 +645,17 @@ func (
 	if lastTxUpdate.IsZero() {
 		lastTxUpdate = time.Now()
 	}
+	// Keep the data miners are asked to include in the coinbase in step with the coinbase tag, which can be changed at runtime.
+	if tag := generator.CoinbaseTag(); tag == "" {
+		state.coinbaseAux = gbtCoinbaseAux
+	} else {
+		state.coinbaseAux = &json.GetBlockTemplateResultAux{
+			Flags: hex.EncodeToString(builderScript(txscript.
+				NewScriptBuilder().
+				AddData([]byte(mining.CoinbaseFlags)).
+				AddData([]byte(tag)))),
+		}
+	}
 	// Generate a new block template when the current best block has changed or the transactions in the memory pool have been updated and it has been at least gbtRegenerateSecond since the last template was generated.
 	var msgBlock *wire.MsgBlock
 	var targetDifficulty string
@@ -601,13 +668,13 @@ func (
 				gbtRegenerateSeconds))) {
 		// Reset the previous best hash the block template was generated against so any errors below cause the next invocation to try again.
 		state.prevHash = nil
-		// Choose a payment address at random if the caller requests a full coinbase as opposed to only the pertinent details needed to create their own coinbase.
+		// Choose the next payment address if the caller requests a full coinbase as opposed to only the pertinent details needed to create their own coinbase.
 		var payAddr util.Address
 		if !useCoinbaseValue {
-			payAddr = StateCfg.ActiveMiningAddrs[rand.Intn(len(StateCfg.ActiveMiningAddrs))]
+			payAddr = s.Cfg.PayAddrs.Next()
 		}
 		// Create a new block template that has a coinbase which anyone can redeem.  This is only acceptable because the returned block template doesn't include the coinbase, so the caller will ultimately create their own coinbase which pays to the appropriate address(es).
-		blkTemplate, err := generator.NewBlockTemplate(payAddr, state.algo)
+		blkTemplate, err := generator.NewControlledBlockTemplate(payAddr, state.algo, state.controls)
 		if err != nil {
 			return internalRPCError("(rpcserver.go) Failed to create new block "+
 				"template: "+err.Error(), "")
@@ -636,9 +703,15 @@ func (
 	} else {
 		// At this point, there is a saved block template and another request for a template was made, but either the available transactions haven't change or it hasn't been long enough to trigger a new block template to be generated.  So, update the existing block template. When the caller requires a full coinbase as opposed to only the pertinent details needed to create their own coinbase, add a payment address to the output of the coinbase of the template if it doesn't already have one.  Since this requires mining addresses to be specified via the config, an error is returned if none have been specified.
 		if !useCoinbaseValue && !template.ValidPayAddress {
-			// Choose a payment address at random.
-			payToAddr := StateCfg.ActiveMiningAddrs[rand.Intn(len(StateCfg.ActiveMiningAddrs))]
-			// Update the block coinbase output of the template to pay to the randomly selected payment address.
+			// Choose the next payment address.
+			payToAddr := s.Cfg.PayAddrs.Next()
+			if payToAddr == nil {
+				return &json.RPCError{
+					Code:    json.ErrRPCInternal.Code,
+					Message: "No payment addresses specified via --miningaddr",
+				}
+			}
+			// Update the block coinbase output of the template to pay to the selected payment address.
 			pkScript, err := txscript.PayToAddrScript(payToAddr)
 			if err != nil {
 				context := "Failed to create pay-to-addr script"
@@ -840,6 +913,14 @@ func (
 		}
 		// Notify registered websocket clients.
 		s.ntfnMgr.NotifyBlockDisconnected(block)
+	case blockchain.NTChainReorganized:
+		reorg, ok := notification.Data.(*blockchain.Reorganization)
+		if !ok {
+			log <- cl.Wrn("chain reorganized notification is not a reorganization")
+			break
+		}
+		// Notify registered websocket clients.
+		s.ntfnMgr.NotifyChainReorganized(reorg)
 	}
 }
 // httpStatusLine returns a response Status-Line (RFC 2616 Section 6.1) for the given request and response status code.  This function was lifted and adapted from the standard library HTTP server code since it's not exported.
@@ -1038,6 +1119,47 @@ func (
 handled:
 	return handler(s, cmd.cmd, closeChan)
 }
+// txCountBlocks returns the number of transactions in the count blocks of the main chain that end with the passed block, reading only the transaction count that follows the header of each.
+func (
+	s *rpcServer,
+) txCountBlocks(
+	hash chainhash.Hash,
+	count int32,
+) (int64, error) {
+	var total int64
+	err := s.Cfg.DB.View(func(dbTx database.Tx) error {
+		for ; count > 0; count-- {
+			region := &database.BlockRegion{
+				Hash:   &hash,
+				Offset: wire.MaxBlockHeaderPayload,
+				Len:    1,
+			}
+			b, err := dbTx.FetchBlockRegion(region)
+			if err != nil {
+				return err
+			}
+			// A count above 0xfc is followed by its value in the next 2, 4 or 8 bytes.
+			if b[0] > 0xfc {
+				region.Len = 1 + 1<<(b[0]-0xfc)
+				if b, err = dbTx.FetchBlockRegion(region); err != nil {
+					return err
+				}
+			}
+			n, err := wire.ReadVarInt(bytes.NewReader(b), 0)
+			if err != nil {
+				return err
+			}
+			total += int64(n)
+			header, err := s.Cfg.Chain.HeaderByHash(&hash)
+			if err != nil {
+				return err
+			}
+			hash = header.PrevBlock
+		}
+		return nil
+	})
+	return total, err
+}
 // writeHTTPResponseHeaders writes the necessary response headers prior to writing an HTTP body given a request to use for protocol negotiation, headers to write, a status code, and a writer.
 func (
 	s *rpcServer,
@@ -1544,6 +1666,94 @@ func getDifficultyRatio(
 	}
 	return diff
 }
+// minerAlgoStats converts the per algorithm statistics of the CPU miner for the getmininginfo result.
+func minerAlgoStats(
+	m *cpuminer.CPUMiner,
+) []json.GetMiningInfoAlgoStats {
+	stats := m.AlgoStats()
+	out := make([]json.GetMiningInfoAlgoStats, 0, len(stats))
+	for _, st := range stats {
+		out = append(out, json.GetMiningInfoAlgoStats{
+			Algo:       st.Algo,
+			Difficulty: st.Difficulty,
+			Hashes:     st.Hashes,
+			Blocks:     st.Blocks,
+			Rounds:     st.Rounds,
+			Seconds:    st.Mined.Seconds(),
+		})
+	}
+	return out
+}
+// errNoMinerListener is returned by the API key commands when miner workers can not connect.
+var errNoMinerListener = &json.RPCError{
+	Code:    json.ErrRPCMisc,
+	Message: "The miner listener is not enabled, set mining.listener to use API keys",
+}
+// handleAddMinerKey implements the addminerkey command. Adding a key with the name of an existing one replaces it and disconnects the workers using the old secret.
+func handleAddMinerKey(
+	s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*json.AddMinerKeyCmd)
+	if s.Cfg.MinerKeys == nil {
+		return nil, errNoMinerListener
+	}
+	var allowed []string
+	if c.Allowed != nil {
+		allowed = *c.Allowed
+	}
+	key, err := controller.NewAPIKey(c.Name, c.Secret, allowed)
+	if err != nil {
+		return nil, &json.RPCError{
+			Code:    json.ErrRPCInvalidParameter,
+			Message: err.Error(),
+		}
+	}
+	s.Cfg.MinerKeys.Add(key)
+	return nil, nil
+}
+// decodeMiningAddress decodes an address passed to the mining address commands, which must be a pay to pubkey hash or script hash address for the active network.
+func decodeMiningAddress(
+	s *rpcServer, encodedAddr string) (util.Address, error) {
+	addr, err := util.DecodeAddress(encodedAddr, s.Cfg.ChainParams)
+	if err != nil {
+		return nil, &json.RPCError{
+			Code:    json.ErrRPCInvalidAddressOrKey,
+			Message: "Invalid address or key: " + err.Error(),
+		}
+	}
+	switch addr.(type) {
+	case *util.AddressPubKeyHash:
+	case *util.AddressScriptHash:
+	default:
+		return nil, &json.RPCError{
+			Code:    json.ErrRPCInvalidAddressOrKey,
+			Message: "Invalid address or key",
+		}
+	}
+	if !addr.IsForNet(s.Cfg.ChainParams) {
+		return nil, &json.RPCError{
+			Code: json.ErrRPCInvalidAddressOrKey,
+			Message: "Invalid address: " + encodedAddr +
+				" is for the wrong network",
+		}
+	}
+	return addr, nil
+}
+// handleAddMiningAddress implements the addminingaddress command.
+func handleAddMiningAddress(
+	s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*json.AddMiningAddressCmd)
+	addr, err := decodeMiningAddress(s, c.Address)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.Cfg.PayAddrs.Add(addr, *c.Weight); err != nil {
+		return nil, &json.RPCError{
+			Code:    json.ErrRPCInvalidParameter,
+			Message: err.Error(),
+		}
+	}
+	return nil, nil
+}
 // handleAddNode handles addnode commands.
 func handleAddNode(
 	s *rpcServer,
@@ -1769,11 +1979,55 @@ func handleEstimateFee(
 	// Convert to satoshis per kb.
 	return float64(feeRate), nil
 }
+// handleEstimateRawFee handles estimaterawfee commands.
+func handleEstimateRawFee(
+	s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	if s.Cfg.FeeEstimator == nil {
+		return nil, errors.New("Fee estimation disabled")
+	}
+	history := s.Cfg.FeeEstimator.FeeHistory()
+	result := make([]json.EstimateRawFeeBucket, len(history))
+	for i, bucket := range history {
+		result[i] = json.EstimateRawFeeBucket{
+			MinFeeRate:  float64(bucket.MinFeeRate) * 1000,
+			MaxFeeRate:  float64(bucket.MaxFeeRate) * 1000,
+			Confirmed:   bucket.Confirmed[:],
+			Unconfirmed: bucket.Unconfirmed,
+		}
+		// JSON has no representation for infinity, so the open ended top bucket reports -1 as its upper bound.
+		if math.IsInf(result[i].MaxFeeRate, 1) {
+			result[i].MaxFeeRate = -1
+		}
+	}
+	return result, nil
+}
+// handleEstimateSmartFee handles estimatesmartfee commands.
+func handleEstimateSmartFee(
+	s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*json.EstimateSmartFeeCmd)
+	if s.Cfg.FeeEstimator == nil {
+		return nil, errors.New("Fee estimation disabled")
+	}
+	if c.ConfTarget <= 0 {
+		return nil, errors.New("Parameter ConfTarget must be positive")
+	}
+	feeRate, blocks, err := s.Cfg.FeeEstimator.EstimateSmartFee(uint32(c.ConfTarget))
+	if err != nil {
+		return &json.EstimateSmartFeeResult{
+			Errors: []string{err.Error()},
+			Blocks: c.ConfTarget,
+		}, nil
+	}
+	return &json.EstimateSmartFeeResult{
+		FeeRate: float64(feeRate),
+		Blocks:  int64(blocks),
+	}, nil
+}
 // handleGenerate handles generate commands.
 func handleGenerate(
 	s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	// Respond with an error if there are no addresses to pay the created blocks to.
-	if len(StateCfg.ActiveMiningAddrs) == 0 {
+	if s.Cfg.PayAddrs.Len() == 0 {
 		return nil, &json.RPCError{
 			Code:    json.ErrRPCInternal.Code,
 			Message: "No payment addresses specified via --miningaddr",
@@ -1812,6 +2066,40 @@ func handleGenerate(
 	}
 	return reply, nil
 }
+// handleGenerateToAddress handles generatetoaddress commands, which mine blocks at once on the regression and simulation test networks.
+func handleGenerateToAddress(
+	s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*json.GenerateToAddressCmd)
+	if !(*Cfg.RegressionTest || *Cfg.SimNet) {
+		return nil, &json.RPCError{
+			Code:    json.ErrRPCDifficulty,
+			Message: fmt.Sprintf("No support for `generatetoaddress` on the current network, %s, it is only available on regtest and simnet.", s.Cfg.ChainParams.Net),
+		}
+	}
+	if c.NumBlocks == 0 {
+		return nil, &json.RPCError{
+			Code:    json.ErrRPCInvalidParameter,
+			Message: "Please request a nonzero number of blocks to generate.",
+		}
+	}
+	addr, err := decodeMiningAddress(s, c.Address)
+	if err != nil {
+		return nil, err
+	}
+	blockHashes, err := s.Cfg.CPUMiner.GenerateToAddress(c.NumBlocks, s.Cfg.Algo,
+		addr, *c.MaxTries)
+	if err != nil {
+		return nil, &json.RPCError{
+			Code:    json.ErrRPCInternal.Code,
+			Message: err.Error(),
+		}
+	}
+	reply := make([]string, len(blockHashes))
+	for i, hash := range blockHashes {
+		reply[i] = hash.String()
+	}
+	return reply, nil
+}
 // handleGetAddedNodeInfo handles getaddednodeinfo commands.
 func handleGetAddedNodeInfo(
 	s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
@@ -2019,6 +2307,8 @@ func handleGetBlockChainInfo(
 		MedianTime:    chainSnapshot.MedianTime.Unix(),
 		Pruned:        false,
 		Bip9SoftForks: make(map[string]*json.Bip9SoftForkDescription),
+		// The progress is estimated from the transactions in the chain, as the node does not know the height of the chain before it has its headers.
+		VerificationProgress: chain.VerificationProgress(time.Now()),
 	}
 	// Next, populate the response with information describing the current status of soft-forks deployed via the super-majority block signalling mechanism.
 	height := chainSnapshot.Height
@@ -2070,27 +2360,39 @@ func handleGetBlockChainInfo(
 			}
 		}
 		// Query the chain for the current status of the deployment as identified by its deployment ID.
-		deploymentStatus, err := chain.ThresholdState(uint32(deployment))
+		deploymentStatus, err := chain.DeploymentStatus(uint32(deployment))
 		if err != nil {
 			context := "Failed to obtain deployment status"
 			return nil, internalRPCError(err.Error(), context)
 		}
 		// Attempt to convert the current deployment status into a human readable string. If the status is unrecognized, then a non-nil error is returned.
-		statusString, err := softForkStatus(deploymentStatus)
+		statusString, err := softForkStatus(deploymentStatus.State)
 		if err != nil {
 			return nil, &json.RPCError{
 				Code: json.ErrRPCInternal.Code,
 				Message: fmt.Sprintf("unknown deployment status: %v",
-					deploymentStatus),
+					deploymentStatus.State),
 			}
 		}
 		// Finally, populate the soft-fork description with all the information gathered above.
-		chainInfo.Bip9SoftForks[forkName] = &json.Bip9SoftForkDescription{
+		desc := &json.Bip9SoftForkDescription{
 			Status:    strings.ToLower(statusString),
 			Bit:       deploymentDetails.BitNumber,
 			StartTime: int64(deploymentDetails.StartTime),
 			Timeout:   int64(deploymentDetails.ExpireTime),
+			Since:     deploymentStatus.Since,
+		}
+		// While the deployment is being voted on, also show the votes in the current window.
+		if deploymentStatus.State == blockchain.ThresholdStarted {
+			desc.Statistics = &json.Bip9SoftForkStatistics{
+				Period:    deploymentStatus.Period,
+				Threshold: deploymentStatus.Threshold,
+				Elapsed:   deploymentStatus.Elapsed,
+				Count:     deploymentStatus.Count,
+				Possible:  deploymentStatus.Possible,
+			}
 		}
+		chainInfo.Bip9SoftForks[forkName] = desc
 	}
 	return chainInfo, nil
 }
@@ -2200,9 +2502,10 @@ func handleGetBlockTemplate(
 }
 // handleGetBlockTemplateLongPoll is a helper for handleGetBlockTemplateRequest which deals with handling long polling for block templates.  When a caller sends a request with a long poll ID that was previously returned, a response is not sent until the caller should stop working on the previous block template in favor of the new one.  In particular, this is the case when the old block template is no longer valid due to a solution already being found and added to the block chain, or new transactions have shown up and some time has passed without finding a solution. See https://en.bitcoin.it/wiki/BIP_0022 for more details.
 func handleGetBlockTemplateLongPoll(
-	s *rpcServer, longPollID string, useCoinbaseValue bool, closeChan <-chan struct{}) (interface{}, error) {
+	s *rpcServer, longPollID string, useCoinbaseValue bool, controls *mining.TxControls, closeChan <-chan struct{}) (interface{}, error) {
 	state := s.gbtWorkState
 	state.Lock()
+	state.setTxControls(controls)
 	// The state unlock is intentionally not deferred here since it needs to be manually unlocked before waiting for a notification about block template changes.
 	if err := state.updateBlockTemplate(s, useCoinbaseValue); err != nil {
 		state.Unlock()
@@ -2332,7 +2635,7 @@ func handleGetBlockTemplateRequest(
 		}
 	}
 	// When a coinbase transaction has been requested, respond with an error if there are no addresses to pay the created block template to.
-	if !useCoinbaseValue && len(StateCfg.ActiveMiningAddrs) == 0 {
+	if !useCoinbaseValue && s.Cfg.PayAddrs.Len() == 0 {
 		return nil, &json.RPCError{
 			Code: json.ErrRPCInternal.Code,
 			Message: "A coinbase transaction has been requested, " +
@@ -2356,21 +2659,142 @@ func handleGetBlockTemplateRequest(
 			Message: "Pod is not yet synchronised...",
 		}
 	}
+	// Transactions the caller wants included or excluded and a lower maximum weight apply on top of the controls of the node.
+	var controls *mining.TxControls
+	if request != nil {
+		var err error
+		controls, err = decodeTxControls(request.Include, request.Exclude,
+			request.MaxWeight)
+		if err != nil {
+			return nil, err
+		}
+	}
 	// When a long poll ID was provided, this is a long poll request by the client to be notified when block template referenced by the ID should be replaced with a new one.
 	if request != nil && request.LongPollID != "" {
 		return handleGetBlockTemplateLongPoll(s, request.LongPollID,
-			useCoinbaseValue, closeChan)
+			useCoinbaseValue, controls, closeChan)
 	}
 	// Protect concurrent access when updating block templates.
 	state := s.gbtWorkState
 	state.Lock()
 	defer state.Unlock()
+	state.setTxControls(controls)
 	// Get and return a block template.  A new block template will be generated when the current best block has changed or the transactions in the memory pool have been updated and it has been at least five seconds since the last template was generated.  Otherwise, the timestamp for the existing block template is updated (and possibly the difficulty on testnet per the consesus rules).
 	if err := state.updateBlockTemplate(s, useCoinbaseValue); err != nil {
 		return nil, err
 	}
 	return state.blockTemplateResult(useCoinbaseValue, nil)
 }
+// decodeTxControls decodes the transactions to include and exclude and the maximum weight passed to the block template commands, returning nil when they leave the template to the mining policy.
+func decodeTxControls(
+	include, exclude []string, maxWeight uint32) (*mining.TxControls, error) {
+	controls := &mining.TxControls{MaxWeight: maxWeight}
+	for _, txid := range include {
+		hash, err := chainhash.NewHashFromStr(txid)
+		if err != nil {
+			return nil, rpcDecodeHexError(txid)
+		}
+		controls.Include = append(controls.Include, *hash)
+	}
+	for _, txid := range exclude {
+		hash, err := chainhash.NewHashFromStr(txid)
+		if err != nil {
+			return nil, rpcDecodeHexError(txid)
+		}
+		controls.Exclude = append(controls.Exclude, *hash)
+	}
+	if err := mining.CheckTxControls(controls); err != nil {
+		return nil, &json.RPCError{
+			Code:    json.ErrRPCInvalidParameter,
+			Message: err.Error(),
+		}
+	}
+	if controls.Empty() {
+		return nil, nil
+	}
+	return controls, nil
+}
+// handleGetChainTips implements the getchaintips command.
+func handleGetChainTips(
+	s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	tips := s.Cfg.Chain.ChainTips()
+	result := make([]json.GetChainTipsResult, len(tips))
+	for i, tip := range tips {
+		result[i] = json.GetChainTipsResult{
+			Height:    tip.Height,
+			Hash:      tip.Hash.String(),
+			BranchLen: tip.BranchLen,
+			Status:    tip.Status,
+		}
+	}
+	return result, nil
+}
+// handleGetChainTxStats implements the getchaintxstats command.
+func handleGetChainTxStats(
+	s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*json.GetChainTxStatsCmd)
+	best := s.Cfg.Chain.BestSnapshot()
+	finalHash, finalHeight := best.Hash, best.Height
+	if c.BlockHash != nil {
+		hash, err := chainhash.NewHashFromStr(*c.BlockHash)
+		if err != nil {
+			return nil, rpcDecodeHexError(*c.BlockHash)
+		}
+		height, err := s.Cfg.Chain.BlockHeightByHash(hash)
+		if err != nil {
+			return nil, &json.RPCError{
+				Code:    json.ErrRPCBlockNotFound,
+				Message: "Block is not in main chain",
+			}
+		}
+		finalHash, finalHeight = *hash, height
+	}
+	// The window defaults to about a month of blocks, or all of them on a younger chain.
+	var nBlocks int32
+	if c.NBlocks == nil {
+		nBlocks = int32(30 * 24 * time.Hour /
+			fork.List[fork.GetCurrent(finalHeight)].TargetTimePerBlock)
+		if nBlocks > finalHeight-1 {
+			nBlocks = finalHeight - 1
+		}
+		if nBlocks < 0 {
+			nBlocks = 0
+		}
+	} else {
+		nBlocks = *c.NBlocks
+		if nBlocks < 0 || (nBlocks > 0 && nBlocks >= finalHeight) {
+			return nil, &json.RPCError{
+				Code:    json.ErrRPCInvalidParameter,
+				Message: "Invalid block count: should be between 0 and the block's height - 1",
+			}
+		}
+	}
+	// The total is only kept for the best block, so the blocks after the final block of the window are taken off it.
+	after, err := s.txCountBlocks(best.Hash, best.Height-finalHeight)
+	if err != nil {
+		return nil, internalRPCError(err.Error(), "Failed to count transactions")
+	}
+	final := s.Cfg.Chain.Index.LookupNode(&finalHash)
+	result := &json.GetChainTxStatsResult{
+		Time:                   final.Header().Timestamp.Unix(),
+		TxCount:                int64(best.TotalTxns) - after,
+		WindowFinalBlockHash:   finalHash.String(),
+		WindowFinalBlockHeight: finalHeight,
+		WindowBlockCount:       nBlocks,
+	}
+	if nBlocks > 0 {
+		result.WindowTxCount, err = s.txCountBlocks(finalHash, nBlocks)
+		if err != nil {
+			return nil, internalRPCError(err.Error(), "Failed to count transactions")
+		}
+		result.WindowInterval = final.CalcPastMedianTime().Unix() -
+			final.RelativeAncestor(nBlocks).CalcPastMedianTime().Unix()
+		if result.WindowInterval > 0 {
+			result.TxRate = float64(result.WindowTxCount) / float64(result.WindowInterval)
+		}
+	}
+	return result, nil
+}
 // handleGetCFilter implements the getcfilter command.
 func handleGetCFilter(
 	s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
@@ -2441,6 +2865,44 @@ func handleGetCurrentNet(
 	s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	return s.Cfg.ChainParams.Net, nil
 }
+// handleGetDifficulties implements the getdifficulties command.
+func handleGetDifficulties(
+	s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	best := s.Cfg.Chain.BestSnapshot()
+	current := fork.GetCurrent(best.Height + 1)
+	algos := fork.List[current].Algos
+	names := fork.AlgoNames(best.Height + 1)
+	result := make([]json.GetDifficultiesResult, len(names))
+	byVersion := make(map[int32]*json.GetDifficultiesResult, len(names))
+	for i, name := range names {
+		bits, err := s.Cfg.Chain.CalcNextRequiredDifficulty(time.Now(), name)
+		if err != nil {
+			return nil, internalRPCError(err.Error(), "Unable to calculate the next difficulty")
+		}
+		version := algos[name].Version
+		result[i] = json.GetDifficultiesResult{
+			Algo:           name,
+			Version:        version,
+			NextBits:       fmt.Sprintf("%08x", bits),
+			NextDifficulty: getDifficultyRatio(bits, s.Cfg.ChainParams, version),
+		}
+		byVersion[version] = &result[i]
+	}
+	// The current difficulty of an algorithm is that of the last block mined with it since the hard fork that made it valid.
+	node := s.Cfg.Chain.Index.LookupNode(&best.Hash)
+	for height := best.Height; len(byVersion) > 0 && height > 0 &&
+		fork.GetCurrent(height) == current; height-- {
+		header := node.Header()
+		if r, ok := byVersion[header.Version]; ok {
+			r.Height = height
+			r.Bits = fmt.Sprintf("%08x", header.Bits)
+			r.Difficulty = getDifficultyRatio(header.Bits, s.Cfg.ChainParams, header.Version)
+			delete(byVersion, header.Version)
+		}
+		node = node.RelativeAncestor(1)
+	}
+	return result, nil
+}
 // handleGetDifficulty implements the getdifficulty command. TODO: This command should default to the configured algo for cpu mining and take an optional parameter to query by algo
 func handleGetDifficulty(
 	s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
@@ -2487,6 +2949,34 @@ func handleGetDifficulty(
 	}
 	return getDifficultyRatio(bestbits, s.Cfg.ChainParams, algo), nil
 }
+// handleGetDustInfo implements the getdustinfo command.
+func handleGetDustInfo(
+	s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	dustRelayFee := StateCfg.ActiveDustRelayFee
+	// The thresholds only depend on the size of the scripts, so any valid hash will do.
+	hash := make([]byte, 20)
+	p2pkh, err := util.NewAddressPubKeyHash(hash, s.Cfg.ChainParams)
+	if err != nil {
+		return nil, internalRPCError(err.Error(), "")
+	}
+	p2pkhScript, err := txscript.PayToAddrScript(p2pkh)
+	if err != nil {
+		return nil, internalRPCError(err.Error(), "")
+	}
+	p2wpkh, err := util.NewAddressWitnessPubKeyHash(hash, s.Cfg.ChainParams)
+	if err != nil {
+		return nil, internalRPCError(err.Error(), "")
+	}
+	p2wpkhScript, err := txscript.PayToAddrScript(p2wpkh)
+	if err != nil {
+		return nil, internalRPCError(err.Error(), "")
+	}
+	return &json.GetDustInfoResult{
+		DustRelayFee: dustRelayFee.ToDUO(),
+		P2PKH:        mempool.GetDustThreshold(p2pkhScript, dustRelayFee).ToDUO(),
+		P2WPKH:       mempool.GetDustThreshold(p2wpkhScript, dustRelayFee).ToDUO(),
+	}, nil
+}
 // handleGetGenerate implements the getgenerate command.
 func handleGetGenerate(
 	s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
@@ -2696,6 +3186,20 @@ func handleGetInfo(
 	}
 	return ret, nil
 }
+// handleGetMempoolEntry implements the getmempoolentry command.
+func handleGetMempoolEntry(
+	s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*json.GetMempoolEntryCmd)
+	txHash, err := chainhash.NewHashFromStr(c.TxID)
+	if err != nil {
+		return nil, rpcDecodeHexError(c.TxID)
+	}
+	entry, err := s.Cfg.TxMemPool.MempoolEntry(txHash)
+	if err != nil {
+		return nil, rpcNoTxInfoError(txHash)
+	}
+	return entry, nil
+}
 // handleGetMempoolInfo implements the getmempoolinfo command.
 func handleGetMempoolInfo(
 	s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
@@ -2704,17 +3208,96 @@ func handleGetMempoolInfo(
 	for _, txD := range mempoolTxns {
 		numBytes += int64(txD.Tx.MsgTx().SerializeSize())
 	}
+	stats := s.Cfg.TxMemPool.Stats()
 	ret := &json.GetMempoolInfoResult{
-		Size:  int64(len(mempoolTxns)),
-		Bytes: numBytes,
+		Size:          int64(len(mempoolTxns)),
+		Bytes:         numBytes,
+		VSize:         stats.VSize,
+		Orphans:       stats.Orphans,
+		MaxMempool:    int64(*Cfg.MaxMempool) * 1000000,
+		MempoolMinFee: s.Cfg.TxMemPool.MinFeeRate().ToDUO(),
+		MinRelayTxFee: StateCfg.ActiveMinRelayTxFee.ToDUO(),
+		FeeHistogram:  make([]json.GetMempoolInfoFeeBucket, 0, len(stats.FeeHistogram)),
+		AgeHistogram:  make([]json.GetMempoolInfoAgeBucket, 0, len(stats.AgeHistogram)),
+	}
+	for _, b := range stats.FeeHistogram {
+		ret.FeeHistogram = append(ret.FeeHistogram, json.GetMempoolInfoFeeBucket{
+			MinFeeRate: b.MinFeeRate,
+			Count:      b.Count,
+			VSize:      b.VSize,
+			Fees:       util.Amount(b.Fees).ToDUO(),
+		})
+	}
+	for _, b := range stats.AgeHistogram {
+		ret.AgeHistogram = append(ret.AgeHistogram, json.GetMempoolInfoAgeBucket{
+			MaxAge: int64(b.MaxAge / time.Second),
+			Count:  b.Count,
+		})
 	}
 	return ret, nil
 }
+// handleGetMinerKeys implements the getminerkeys command.
+func handleGetMinerKeys(
+	s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	result := []json.GetMinerKeysResult{}
+	if s.Cfg.MinerKeys == nil {
+		return result, nil
+	}
+	for _, st := range s.Cfg.MinerKeys.Stats() {
+		var lastSeen int64
+		if !st.LastSeen.IsZero() {
+			lastSeen = st.LastSeen.Unix()
+		}
+		result = append(result, json.GetMinerKeysResult{
+			Name:     st.Name,
+			Allowed:  st.Allowed,
+			Workers:  st.Workers,
+			Sessions: st.Sessions,
+			Jobs:     st.Jobs,
+			Accepted: st.Accepted,
+			Rejected: st.Rejected,
+			LastSeen: lastSeen,
+		})
+	}
+	return result, nil
+}
+// handleGetMiningAddresses implements the getminingaddresses command.
+func handleGetMiningAddresses(
+	s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	result := json.GetMiningAddressesResult{
+		Rotation:  s.Cfg.PayAddrs.Rotation(),
+		Addresses: []json.GetMiningAddressesAddress{},
+	}
+	for _, a := range s.Cfg.PayAddrs.List() {
+		result.Addresses = append(result.Addresses, json.GetMiningAddressesAddress{
+			Address: a.Addr.EncodeAddress(),
+			Weight:  a.Weight,
+		})
+	}
+	return result, nil
+}
+// handleGetMiningBias implements the getminingbias command.
+func handleGetMiningBias(
+	s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	bias, chances := s.Cfg.CPUMiner.AlgoChances()
+	result := json.GetMiningBiasResult{
+		Bias:  bias,
+		Algos: make([]json.GetMiningBiasAlgo, 0, len(chances)),
+	}
+	for _, c := range chances {
+		result.Algos = append(result.Algos, json.GetMiningBiasAlgo{
+			Algo:       c.Algo,
+			Difficulty: c.Difficulty,
+			Chance:     c.Chance,
+		})
+	}
+	return result, nil
+}
 // handleGetMiningInfo implements the getmininginfo command. We only return the fields that are not related to wallet functionality. This function returns more information than parallelcoind.
 func handleGetMiningInfo(
 	s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (ret interface{}, err error) {
 	// Create a default getnetworkhashps command to use defaults and make use of the existing getnetworkhashps handler.
-	gnhpsCmd := json.NewGetNetworkHashPSCmd(nil, nil)
+	gnhpsCmd := json.NewGetNetworkHashPSCmd(nil, nil, nil)
 	networkHashesPerSecIface, err := handleGetNetworkHashPS(s, gnhpsCmd, closeChan)
 	if err != nil {
 		return nil, err
@@ -2879,15 +3462,49 @@ func handleGetMiningInfo(
 			DifficultyX11:       dX11,
 			Generate:            s.Cfg.CPUMiner.IsMining(),
 			GenAlgo:             s.Cfg.CPUMiner.GetAlgo(),
+			GenAlgoCurrent:      s.Cfg.CPUMiner.CurrentAlgo(),
+			CoinbaseTag:         s.Cfg.Generator.CoinbaseTag(),
 			GenProcLimit:        s.Cfg.CPUMiner.NumWorkers(),
 			HashesPerSec:        int64(s.Cfg.CPUMiner.HashesPerSecond()),
 			NetworkHashPS:       networkHashesPerSec,
 			PooledTx:            uint64(s.Cfg.TxMemPool.Count()),
 			TestNet:             *Cfg.TestNet3,
+			AlgoStats:           minerAlgoStats(s.Cfg.CPUMiner),
 		}
 	}
 	return ret, nil
 }
+// handleGetMiningStats implements the getminingstats command, reporting the blocks found by the built-in miner and its hash rates for dashboards.
+func handleGetMiningStats(
+	s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	m := s.Cfg.CPUMiner
+	stats := m.AlgoStats()
+	result := json.GetMiningStatsResult{
+		Generate:        m.IsMining(),
+		GenAlgo:         m.GetAlgo(),
+		GenAlgoCurrent:  m.CurrentAlgo(),
+		ExpectedSeconds: cpuminer.TimeToBlock(stats).Seconds(),
+		Algos:           make([]json.GetMiningStatsAlgo, 0, len(stats)),
+	}
+	for _, st := range stats {
+		result.Accepted += st.Blocks
+		result.Stale += st.Stale
+		result.HashRate1m += st.HashRates[0]
+		result.HashRate5m += st.HashRates[1]
+		result.HashRate15m += st.HashRates[2]
+		result.Algos = append(result.Algos, json.GetMiningStatsAlgo{
+			Algo:            st.Algo,
+			Difficulty:      st.Difficulty,
+			Accepted:        st.Blocks,
+			Stale:           st.Stale,
+			HashRate1m:      st.HashRates[0],
+			HashRate5m:      st.HashRates[1],
+			HashRate15m:     st.HashRates[2],
+			ExpectedSeconds: st.TimeToBlock.Seconds(),
+		})
+	}
+	return result, nil
+}
 // handleGetNetTotals implements the getnettotals command.
 func handleGetNetTotals(
 	s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
@@ -2916,6 +3533,17 @@ func handleGetNetworkHashPS(
 	if endHeight < 0 {
 		endHeight = best.Height
 	}
+	// When an algorithm is passed only the work of its blocks is counted, over the time of all the blocks, so the estimate is the hash rate of that algorithm.
+	algo := ""
+	if c.Algo != nil && *c.Algo != "" {
+		algo = *c.Algo
+		if _, ok := fork.List[fork.GetCurrent(endHeight)].Algos[algo]; !ok {
+			return nil, &json.RPCError{
+				Code:    json.ErrRPCInvalidParameter,
+				Message: fmt.Sprintf("unknown algorithm %q at height %d", algo, endHeight),
+			}
+		}
+	}
 	// Calculate the number of blocks per retarget interval based on the chain parameters.
 	blocksPerRetarget := int32(s.Cfg.ChainParams.TargetTimespan / s.Cfg.ChainParams.TargetTimePerBlock)
 	// Calculate the starting block height based on the passed number of blocks.  When the passed value is negative, use the last block the difficulty changed as the starting height.  Also make sure the starting height is not before the beginning of the chain.
@@ -2956,7 +3584,9 @@ func handleGetNetworkHashPS(
 			minTimestamp = header.Timestamp
 			maxTimestamp = minTimestamp
 		} else {
-			totalWork.Add(totalWork, blockchain.CalcWork(header.Bits, best.Height+1, header.Version))
+			if algo == "" || fork.GetAlgoName(header.Version, curHeight) == algo {
+				totalWork.Add(totalWork, blockchain.CalcWork(header.Bits, best.Height+1, header.Version))
+			}
 			if minTimestamp.After(header.Timestamp) {
 				minTimestamp = header.Timestamp
 			}
@@ -2973,6 +3603,38 @@ func handleGetNetworkHashPS(
 	hashesPerSec := new(big.Int).Div(totalWork, big.NewInt(timeDiff))
 	return hashesPerSec.Int64(), nil
 }
+// handleGetOrphanInfo implements the getorphaninfo command.
+func handleGetOrphanInfo(
+	s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	orphans := s.Cfg.TxMemPool.Orphans()
+	stats := s.Cfg.TxMemPool.OrphanStats()
+	result := &json.GetOrphanInfoResult{
+		Size:       int64(len(orphans)),
+		Added:      stats.Added,
+		Resolved:   stats.Resolved,
+		Expired:    stats.Expired,
+		Evicted:    stats.Evicted,
+		Invalid:    stats.Invalid,
+		Conflicted: stats.Conflicted,
+		Removed:    stats.Removed,
+		Orphans:    make([]json.GetOrphanInfoEntry, 0, len(orphans)),
+	}
+	for _, orphan := range orphans {
+		missing := make([]string, 0, len(orphan.Missing))
+		for _, outpoint := range orphan.Missing {
+			missing = append(missing, outpoint.String())
+		}
+		result.Orphans = append(result.Orphans, json.GetOrphanInfoEntry{
+			TxID:    orphan.Tx.Hash().String(),
+			Size:    int32(orphan.Tx.MsgTx().SerializeSize()),
+			PeerID:  uint64(orphan.Tag),
+			Time:    orphan.Added.Unix(),
+			Expires: orphan.Expiration.Unix(),
+			Missing: missing,
+		})
+	}
+	return result, nil
+}
 // handleGetPeerInfo implements the getpeerinfo command.
 func handleGetPeerInfo(
 	s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
@@ -3017,17 +3679,57 @@ func handleGetRawMempool(
 	s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	c := cmd.(*json.GetRawMempoolCmd)
 	mp := s.Cfg.TxMemPool
-	if c.Verbose != nil && *c.Verbose {
+	filter, err := rawMempoolFilter(c)
+	if err != nil {
+		return nil, err
+	}
+	verbose := c.Verbose != nil && *c.Verbose
+	if verbose && c.Compact != nil && *c.Compact {
+		return mp.RawMempoolCompact(filter), nil
+	}
+	// Skip sorting the whole pool when the full unfiltered verbose result is requested.
+	if verbose && *filter == (mempool.RawMempoolFilter{}) {
 		return mp.RawMempoolVerbose(), nil
 	}
+	if verbose {
+		return mp.RawMempoolVerboseFiltered(filter), nil
+	}
 	// The response is simply an array of the transaction hashes if the verbose flag is not set.
-	descs := mp.TxDescs()
+	descs := mp.FilteredTxDescs(filter)
 	hashStrings := make([]string, len(descs))
 	for i := range hashStrings {
 		hashStrings[i] = descs[i].Tx.Hash().String()
 	}
 	return hashStrings, nil
 }
+// rawMempoolFilter converts the paging and filter parameters of the getrawmempool command to a mempool filter.
+func rawMempoolFilter(
+	c *json.GetRawMempoolCmd) (*mempool.RawMempoolFilter, error) {
+	filter := &mempool.RawMempoolFilter{}
+	if c.Skip != nil && *c.Skip > 0 {
+		filter.Skip = *c.Skip
+	}
+	if c.Count != nil && *c.Count > 0 {
+		filter.Count = *c.Count
+	}
+	if c.MinFeeRate != nil && *c.MinFeeRate != 0 {
+		minFeeRate, err := util.NewAmount(*c.MinFeeRate)
+		if err != nil || minFeeRate < 0 {
+			return nil, &json.RPCError{
+				Code:    json.ErrRPCInvalidParameter,
+				Message: "minfeerate must be a non-negative amount",
+			}
+		}
+		filter.MinFeeRate = int64(minFeeRate)
+	}
+	if c.MinAge != nil && *c.MinAge > 0 {
+		filter.MinAge = time.Duration(*c.MinAge) * time.Second
+	}
+	if c.MaxAge != nil && *c.MaxAge > 0 {
+		filter.MaxAge = time.Duration(*c.MaxAge) * time.Second
+	}
+	return filter, nil
+}
 // handleGetRawTransaction implements the getrawtransaction command.
 func handleGetRawTransaction(
 	s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
@@ -3127,6 +3829,26 @@ func handleGetRawTransaction(
 	}
 	return *rawTxn, nil
 }
+// handleGetTemplateControls implements the gettemplatecontrols command.
+func handleGetTemplateControls(
+	s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	result := json.GetTemplateControlsResult{
+		Include: []string{},
+		Exclude: []string{},
+	}
+	controls := s.Cfg.Generator.TxControls()
+	if controls == nil {
+		return result, nil
+	}
+	for _, h := range controls.Include {
+		result.Include = append(result.Include, h.String())
+	}
+	for _, h := range controls.Exclude {
+		result.Exclude = append(result.Exclude, h.String())
+	}
+	result.MaxWeight = controls.MaxWeight
+	return result, nil
+}
 // handleGetTxOut handles gettxout commands.
 func handleGetTxOut(
 	s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
@@ -3212,6 +3934,36 @@ func handleGetTxOut(
 	}
 	return txOutReply, nil
 }
+// handleGetWorkerInfo implements the getworkerinfo command.
+func handleGetWorkerInfo(
+	s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	result := []json.GetWorkerInfoResult{}
+	if s.Cfg.MinerController == nil {
+		return result, nil
+	}
+	peers := s.Cfg.MinerController.Peers()
+	sort.Slice(peers, func(i, j int) bool {
+		return peers[i].Joined.Before(peers[j].Joined)
+	})
+	for _, p := range peers {
+		result = append(result, json.GetWorkerInfoResult{
+			Addr:          p.Addr,
+			Transport:     p.Transport,
+			Identity:      hex.EncodeToString(p.Identity),
+			Algo:          p.Algo,
+			ConnTime:      p.Joined.Unix(),
+			LastRecv:      p.Seen.Unix(),
+			PingTime:      float64(p.RTT) / float64(time.Millisecond),
+			Lost:          p.Lost,
+			Rejected:      p.Rejected,
+			Replayed:      p.Replayed,
+			Reconstructed: p.Reconstructed,
+			Corrupt:       p.Corrupt,
+			LossRate:      p.LossRate,
+		})
+	}
+	return result, nil
+}
 // handleHelp implements the help command.
 func handleHelp(
 	s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
@@ -3337,6 +4089,37 @@ func handlePing(
 	s.Cfg.ConnMgr.BroadcastMessage(wire.NewMsgPing(nonce))
 	return nil, nil
 }
+// handleRemoveMinerKey implements the removeminerkey command, which revokes the key and disconnects the workers using it.
+func handleRemoveMinerKey(
+	s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*json.RemoveMinerKeyCmd)
+	if s.Cfg.MinerKeys == nil {
+		return nil, errNoMinerListener
+	}
+	if err := s.Cfg.MinerKeys.Remove(c.Name); err != nil {
+		return nil, &json.RPCError{
+			Code:    json.ErrRPCInvalidParameter,
+			Message: err.Error(),
+		}
+	}
+	return nil, nil
+}
+// handleRemoveMiningAddress implements the removeminingaddress command.
+func handleRemoveMiningAddress(
+	s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*json.RemoveMiningAddressCmd)
+	addr, err := decodeMiningAddress(s, c.Address)
+	if err != nil {
+		return nil, err
+	}
+	if !s.Cfg.PayAddrs.Remove(addr) {
+		return nil, &json.RPCError{
+			Code:    json.ErrRPCInvalidParameter,
+			Message: "Address " + c.Address + " is not a mining address",
+		}
+	}
+	return nil, nil
+}
 // handleSearchRawTransactions implements the searchrawtransactions command.
 func handleSearchRawTransactions(
 	s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
@@ -3592,6 +4375,18 @@ func handleSendRawTransaction(
 	s.Cfg.ConnMgr.AddRebroadcastInventory(iv, txD)
 	return tx.Hash().String(), nil
 }
+// handleSetCoinbaseTag implements the setcoinbasetag command.
+func handleSetCoinbaseTag(
+	s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*json.SetCoinbaseTagCmd)
+	if err := s.Cfg.Generator.SetCoinbaseTag(c.Tag); err != nil {
+		return nil, &json.RPCError{
+			Code:    json.ErrRPCInvalidParameter,
+			Message: err.Error(),
+		}
+	}
+	return nil, nil
+}
 // handleSetGenerate implements the setgenerate command.
 func handleSetGenerate(
 	s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
@@ -3606,6 +4401,22 @@ func handleSetGenerate(
 	if genProcLimit == 0 {
 		generate = false
 	}
+	// Change the thread affinity and priority before the miner is restarted below so the new workers use them.
+	if c.Cores != nil || c.Nice != nil {
+		cores, nice := s.Cfg.CPUMiner.ThreadPolicy()
+		if c.Cores != nil {
+			cores = *c.Cores
+		}
+		if c.Nice != nil {
+			nice = *c.Nice
+		}
+		if err := s.Cfg.CPUMiner.SetThreadPolicy(cores, nice); err != nil {
+			return nil, &json.RPCError{
+				Code:    json.ErrRPCInvalidParameter,
+				Message: err.Error(),
+			}
+		}
+	}
 	if s.Cfg.CPUMiner.IsMining() {
 		// if s.Cfg.CPUMiner.GetAlgo() != s.Cfg.Algo {
 		s.Cfg.CPUMiner.Stop()
@@ -3616,7 +4427,7 @@ func handleSetGenerate(
 		s.Cfg.CPUMiner.Stop()
 	} else {
 		// Respond with an error if there are no addresses to pay the created blocks to.
-		if len(StateCfg.ActiveMiningAddrs) == 0 {
+		if s.Cfg.PayAddrs.Len() == 0 {
 			return nil, &json.RPCError{
 				Code:    json.ErrRPCInternal.Code,
 				Message: "no payment addresses specified via --miningaddr",
@@ -3630,6 +4441,53 @@ func handleSetGenerate(
 	}
 	return nil, nil
 }
+// handleSetMiningBias implements the setminingbias command. The bias can only be changed on the test networks, where it is used for difficulty experiments, and every change is logged.
+func handleSetMiningBias(
+	s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*json.SetMiningBiasCmd)
+	if !(*Cfg.TestNet3 || *Cfg.RegressionTest || *Cfg.SimNet) {
+		return nil, &json.RPCError{
+			Code:    json.ErrRPCMisc,
+			Message: "The mining bias can only be changed on test networks",
+		}
+	}
+	old, err := s.Cfg.CPUMiner.SetBias(c.Bias)
+	if err != nil {
+		return nil, &json.RPCError{
+			Code:    json.ErrRPCInvalidParameter,
+			Message: err.Error(),
+		}
+	}
+	log <- cl.Warnf{"mining bias changed from %v to %v by RPC", old, c.Bias}
+	return nil, nil
+}
+// handleSetTemplateControls implements the settemplatecontrols command. The controls replace those set before and apply to the templates of the built-in miner, the miner workers, getwork and stratum, and along with those of the caller to getblocktemplate.
+func handleSetTemplateControls(
+	s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*json.SetTemplateControlsCmd)
+	var include, exclude []string
+	var maxWeight uint32
+	if c.Include != nil {
+		include = *c.Include
+	}
+	if c.Exclude != nil {
+		exclude = *c.Exclude
+	}
+	if c.MaxWeight != nil {
+		maxWeight = *c.MaxWeight
+	}
+	controls, err := decodeTxControls(include, exclude, maxWeight)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Cfg.Generator.SetTxControls(controls); err != nil {
+		return nil, &json.RPCError{
+			Code:    json.ErrRPCInvalidParameter,
+			Message: err.Error(),
+		}
+	}
+	return nil, nil
+}
 // handleStop implements the stop command.
 func handleStop(
 	s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
@@ -3685,15 +4543,35 @@ func handleValidateAddress(
 	s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	c := cmd.(*json.ValidateAddressCmd)
 	result := json.ValidateAddressChainResult{}
-	addr, err := util.DecodeAddress(c.Address, s.Cfg.ChainParams)
+	info, err := util.InspectAddress(c.Address, s.Cfg.ChainParams)
 	if err != nil {
 		// Return the default value (false) for IsValid.
 		return result, nil
 	}
-	result.Address = addr.EncodeAddress()
+	result.Address = info.Address.EncodeAddress()
 	result.IsValid = true
+	result.AddressDetails = addressDetails(info)
 	return result, nil
 }
+// addressDetails describes an address for the validateaddress result.
+func addressDetails(
+	info *util.AddressInfo) json.AddressDetails {
+	d := json.AddressDetails{
+		Type:        info.Type,
+		Network:     info.Network,
+		ScriptClass: info.ScriptClass,
+		IsWitness:   info.IsWitness,
+	}
+	if script, err := txscript.PayToAddrScript(info.Address); err == nil {
+		d.ScriptPubKey = hex.EncodeToString(script)
+	}
+	if info.IsWitness {
+		version := int32(info.WitnessVersion)
+		d.WitnessVersion = &version
+		d.WitnessProgram = hex.EncodeToString(info.WitnessProgram)
+	}
+	return d
+}
 // handleVerifyChain implements the verifychain command.
 func handleVerifyChain(
 	s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
@@ -3822,9 +4700,10 @@ func newGbtWorkState(
 	algoname string,
 ) *gbtWorkState {
 	return &gbtWorkState{
-		notifyMap:  make(map[chainhash.Hash]map[int64]chan struct{}),
-		timeSource: timeSource,
-		algo:       algoname,
+		notifyMap:   make(map[chainhash.Hash]map[int64]chan struct{}),
+		timeSource:  timeSource,
+		algo:        algoname,
+		coinbaseAux: gbtCoinbaseAux,
 	}
 }
 // newRPCServer returns a new instance of the rpcServer struct.
@@ -3838,6 +4717,7 @@ func newRPCServer(
 		Cfg:                    *config,
 		statusLines:            make(map[int]string),
 		gbtWorkState:           newGbtWorkState(config.TimeSource, config.Algo),
+		getworkState:           newGetworkState(),
 		helpCacher:             newHelpCacher(),
 		requestProcessShutdown: make(chan struct{}),
 		quit:                   make(chan int),
@@ -20,6 +20,15 @@ var helpDescsEnUS = map[string]string{
 	"debuglevel--condition1": "levelspec=show",
 	"debuglevel--result0":    "The string 'Done.'",
 	"debuglevel--result1":    "The list of subsystems",
+	// AddMinerKeyCmd help.
+	"addminerkey--synopsis": "Adds an API key miner workers can connect with instead of the mining password, replacing and disconnecting the workers of any key with the same name.",
+	"addminerkey-name":      "The name the worker sends to identify the key",
+	"addminerkey-secret":    "The secret the key is derived from",
+	"addminerkey-allowed":   "IP addresses or networks in CIDR notation workers may connect from with the key, or omitted for any",
+	// AddMiningAddressCmd help.
+	"addminingaddress--synopsis": "Adds an address to the set of addresses mined blocks pay to, or sets its weight if it is already in the set.",
+	"addminingaddress-address":   "The pay to pubkey hash or script hash address to add",
+	"addminingaddress-weight":    "The relative chance of the address being picked when the mining.rotation is 'weighted'",
 	// AddNodeCmd help.
 	"addnode--synopsis": "Attempts to add or remove a persistent peer.",
 	"addnode-addr":      "IP address and port of the peer to operate on",
@@ -92,6 +101,16 @@ var helpDescsEnUS = map[string]string{
 	// DecodeScriptCmd help.
 	"decodescript--synopsis": "Returns a JSON object with information about the provided hex-encoded script.",
 	"decodescript-hexscript": "Hex-encoded script",
+	// DumpUtxoSetCmd help.
+	"dumputxoset--synopsis": "Writes a snapshot of the utxo set at the tip of the chain to a new file, for a new node to start from with loadutxoset. Blocks are not connected while it is written.",
+	"dumputxoset-path":      "The path of the file to write, relative to the network directory of the node, which must not exist",
+	// UtxoSnapshotResult help.
+	"utxosnapshotresult-path":       "The path of the snapshot file",
+	"utxosnapshotresult-basehash":   "The hash of the block the snapshot was taken at",
+	"utxosnapshotresult-baseheight": "The height of the block the snapshot was taken at",
+	"utxosnapshotresult-txcount":    "The number of transactions in the chain up to that block",
+	"utxosnapshotresult-coins":      "The number of unspent outputs in the snapshot",
+	"utxosnapshotresult-hash":       "The hash of the snapshot file, which chain.assumeutxo pins and the maintainers sign",
 	// EstimateFeeCmd help.
 	"estimatefee--synopsis": "Estimate the fee per kilobyte in satoshis " +
 		"required for a transaction to be mined before a certain number of " +
@@ -100,11 +119,32 @@ var helpDescsEnUS = map[string]string{
 		"generated before the transaction is mined.",
 	"estimatefee--result0": "Estimated fee per kilobyte in satoshis for a block to " +
 		"be mined in the next NumBlocks blocks.",
+	// EstimateRawFeeCmd help.
+	"estimaterawfee--synopsis": "Returns the raw confirmation time histogram the fee estimator keeps of observed transactions, which persists across restarts.",
+	// EstimateRawFeeBucket help.
+	"estimaterawfeebucket-minfeerate":  "Lower bound of the bucket in satoshi per kilobyte",
+	"estimaterawfeebucket-maxfeerate":  "Upper bound of the bucket in satoshi per kilobyte, -1 for the open ended top bucket",
+	"estimaterawfeebucket-confirmed":   "Decayed number of transactions mined after each number of blocks, starting from the next block",
+	"estimaterawfeebucket-unconfirmed": "Decayed number of transactions that were not mined within the tracked number of blocks",
+	// EstimateSmartFeeCmd help.
+	"estimatesmartfee--synopsis": "Estimate the fee per kilobyte required for a transaction to be mined within a number of blocks, using the confirmation history of the fee estimator.",
+	"estimatesmartfee-conftarget": "The number of blocks within which the transaction should be mined",
+	// EstimateSmartFeeResult help.
+	"estimatesmartfeeresult-feerate": "Estimated fee rate in DUO per kilobyte",
+	"estimatesmartfeeresult-errors":  "Errors encountered while estimating, if any",
+	"estimatesmartfeeresult-blocks":  "The number of blocks the estimate is valid for, which may be higher than requested when there is not enough data",
 	// GenerateCmd help
 	"generate--synopsis": "Generates a set number of blocks (simnet or regtest only) and returns a JSON\n" +
 		" array of their hashes.",
 	"generate-numblocks": "Number of blocks to generate",
 	"generate--result0":  "The hashes, in order, of blocks generated by the call",
+	// GenerateToAddressCmd help
+	"generatetoaddress--synopsis": "Mines a set number of blocks paying to an address at once (simnet or regtest only) and returns a JSON\n" +
+		" array of their hashes.",
+	"generatetoaddress-numblocks": "Number of blocks to generate",
+	"generatetoaddress-address":   "The address the coinbase of each block pays to",
+	"generatetoaddress-maxtries":  "How many nonces to try for each block before giving up because the difficulty is too high",
+	"generatetoaddress--result0":  "The hashes, in order, of blocks generated by the call",
 	// GetAddedNodeInfoResultAddr help.
 	"getaddednodeinforesultaddr-address":   "The ip address for this DNS entry",
 	"getaddednodeinforesultaddr-connected": "The connection 'direction' (inbound/outbound/false)",
@@ -242,6 +282,9 @@ var helpDescsEnUS = map[string]string{
 	"templaterequest-target":       "The desired target for the block template (this parameter is ignored)",
 	"templaterequest-data":         "Hex-encoded block data (only for mode=proposal)",
 	"templaterequest-workid":       "The server provided workid if provided in block template (not applicable)",
+	"templaterequest-include":      "Transactions to select before all others regardless of their fees, along with those set with settemplatecontrols",
+	"templaterequest-exclude":      "Transactions never to select, along with any spending their outputs and those set with settemplatecontrols",
+	"templaterequest-maxweight":    "Maximum weight of the block template below the consensus limit, or 0 for the node policy",
 	// GetBlockTemplateResultTx help.
 	"getblocktemplateresulttx-data":    "Hex-encoded transaction data (byte-for-byte)",
 	"getblocktemplateresulttx-hash":    "Hex-encoded transaction hash (little endian if treated as a 256-bit number)",
@@ -295,18 +338,54 @@ var helpDescsEnUS = map[string]string{
 	"getcfilterheader-filtertype": "The type of filter header to return (0=regular)",
 	"getcfilterheader-hash":       "The hash of the block",
 	"getcfilterheader--result0":   "The block's gcs filter header",
+	// GetChainTipsCmd help.
+	"getchaintips--synopsis": "Returns the tips of the main chain and of every branch off it in the block index.",
+	// GetChainTipsResult help.
+	"getchaintipsresult-height":    "The height of the tip",
+	"getchaintipsresult-hash":      "The hash of the tip",
+	"getchaintipsresult-branchlen": "The number of blocks from the tip down to where its branch forks from the main chain, zero for the main chain",
+	"getchaintipsresult-status":    "The status of the branch (active, valid-fork, valid-headers, headers-only or invalid)",
+	// GetChainTxStatsCmd help.
+	"getchaintxstats--synopsis": "Returns statistics about the rate of transactions in the main chain over a window of blocks.",
+	"getchaintxstats-nblocks":   "The number of blocks in the window, which defaults to about a month of blocks",
+	"getchaintxstats-blockhash": "The hash of the block that ends the window, which defaults to the best block",
+	// GetChainTxStatsResult help.
+	"getchaintxstatsresult-time":                      "The timestamp of the final block of the window in seconds since 1 Jan 1970 GMT",
+	"getchaintxstatsresult-txcount":                   "The number of transactions in the chain up to and including the final block of the window",
+	"getchaintxstatsresult-window_final_block_hash":   "The hash of the final block of the window",
+	"getchaintxstatsresult-window_final_block_height": "The height of the final block of the window",
+	"getchaintxstatsresult-window_block_count":        "The number of blocks in the window",
+	"getchaintxstatsresult-window_tx_count":           "The number of transactions in the window, omitted when the window is empty",
+	"getchaintxstatsresult-window_interval":           "The number of seconds between the median times of the blocks that start and end the window, omitted when the window is empty",
+	"getchaintxstatsresult-txrate":                    "The average number of transactions per second in the window, omitted when the interval is not positive",
 	// GetConnectionCountCmd help.
 	"getconnectioncount--synopsis": "Returns the number of active connections to other peers.",
 	"getconnectioncount--result0":  "The number of connections",
 	// GetCurrentNetCmd help.
 	"getcurrentnet--synopsis": "Get bitcoin network the server is running on.",
 	"getcurrentnet--result0":  "The network identifer",
+	// GetDifficultiesCmd help.
+	"getdifficulties--synopsis": "Returns the current and next proof-of-work difficulty of each algorithm that is valid for the next block, as multiples of the minimum difficulty.",
+	// GetDifficultiesResult help.
+	"getdifficultiesresult-algo":           "The name of the algorithm",
+	"getdifficultiesresult-version":        "The block version that identifies the algorithm",
+	"getdifficultiesresult-height":         "The height of the last block mined with the algorithm, omitted when none has been since the last hard fork",
+	"getdifficultiesresult-bits":           "The bits of the last block mined with the algorithm",
+	"getdifficultiesresult-difficulty":     "The difficulty of the last block mined with the algorithm",
+	"getdifficultiesresult-nextbits":       "The bits required of the next block mined with the algorithm",
+	"getdifficultiesresult-nextdifficulty": "The difficulty required of the next block mined with the algorithm",
 	// GetDifficultyCmd help.
 	"getdifficulty--synopsis":   "Returns the proof-of-work difficulty as a multiple of the minimum difficulty, according to the currently configured cpu mining algorithm.",
 	"getdifficulty-algo":        "Defaults to the configured --algo for the CPU miner, can be set to sha256 or scrypt",
 	"getdifficulty--condition0": "algo=sha256d or scrypt",
 	"getdifficulty--result0":    "The difficulty of the requested algorithm",
 	// GetGenerateCmd help.
+	// GetDustInfoCmd help.
+	"getdustinfo--synopsis": "Returns the fee rate currently used to decide whether a transaction output is dust, and the resulting dust thresholds for common output types.",
+	// GetDustInfoResult help.
+	"getdustinforesult-dustrelayfee": "The dust relay fee rate in DUO per kB",
+	"getdustinforesult-p2pkh":        "The smallest amount in DUO a pay-to-pubkey-hash output may have without being dust",
+	"getdustinforesult-p2wpkh":       "The smallest amount in DUO a pay-to-witness-pubkey-hash output may have without being dust",
 	"getgenerate--synopsis": "Returns if the server is set to generate coins (mine) or not.",
 	"getgenerate--result0":  "True if mining, false if not",
 	// GetHashesPerSecCmd help.
@@ -347,11 +426,44 @@ var helpDescsEnUS = map[string]string{
 	"getheaders--result0":      "Serialized block headers of all located blocks, limited to some arbitrary maximum number of hashes (currently 2000, which matches the wire protocol headers message, but this is not guaranteed)",
 	// GetInfoCmd help.
 	"getinfo--synopsis": "Returns a JSON object containing various state info.",
+	// GetMempoolEntryCmd help.
+	"getmempoolentry--synopsis": "Returns mempool data for the given transaction, including the aggregates of its unconfirmed ancestors and descendants.",
+	"getmempoolentry-txid":      "The hash of the transaction",
+	// GetMempoolEntryResult help.
+	"getmempoolentryresult-size":             "Transaction size in bytes",
+	"getmempoolentryresult-fee":              "Transaction fee in DUO",
+	"getmempoolentryresult-modifiedfee":      "Transaction fee in DUO with fee deltas used for mining priority",
+	"getmempoolentryresult-time":             "Local time transaction entered pool in seconds since 1 Jan 1970 GMT",
+	"getmempoolentryresult-height":           "Block height when transaction entered the pool",
+	"getmempoolentryresult-startingpriority": "Priority when transaction entered the pool",
+	"getmempoolentryresult-currentpriority":  "Current priority",
+	"getmempoolentryresult-descendantcount":  "Number of in-mempool descendant transactions, including this one",
+	"getmempoolentryresult-descendantsize":   "Virtual size of in-mempool descendants, including this one",
+	"getmempoolentryresult-descendantfees":   "Fees of in-mempool descendants in DUO, including this one",
+	"getmempoolentryresult-ancestorcount":    "Number of in-mempool ancestor transactions, including this one",
+	"getmempoolentryresult-ancestorsize":     "Virtual size of in-mempool ancestors, including this one",
+	"getmempoolentryresult-ancestorfees":     "Fees of in-mempool ancestors in DUO, including this one",
+	"getmempoolentryresult-depends":          "Unconfirmed transactions used as inputs for this transaction",
 	// GetMempoolInfoCmd help.
 	"getmempoolinfo--synopsis": "Returns memory pool information",
 	// GetMempoolInfoResult help.
-	"getmempoolinforesult-bytes": "Size in bytes of the mempool",
-	"getmempoolinforesult-size":  "Number of transactions in the mempool",
+	"getmempoolinforesult-bytes":         "Size in bytes of the mempool",
+	"getmempoolinforesult-size":          "Number of transactions in the mempool",
+	"getmempoolinforesult-maxmempool":    "Maximum total virtual size of the mempool in bytes, 0 if unlimited",
+	"getmempoolinforesult-mempoolminfee": "Minimum fee rate in DUO/kB for a transaction to be accepted, raised above minrelaytxfee while the mempool is full",
+	"getmempoolinforesult-minrelaytxfee": "Minimum relay fee rate in DUO/kB for transactions",
+	"getmempoolinforesult-vsize":         "Total virtual size in bytes of the transactions in the mempool",
+	"getmempoolinforesult-orphans":       "Number of transactions in the orphan pool",
+	"getmempoolinforesult-feehistogram":  "Distribution of the transactions in the mempool by fee rate, from the lowest fee rate",
+	"getmempoolinforesult-agehistogram":  "Distribution of the transactions in the mempool by time since they were added, from the youngest",
+	// GetMempoolInfoFeeBucket help.
+	"getmempoolinfofeebucket-minfeerate": "Lower bound of the bucket in satoshi per virtual byte",
+	"getmempoolinfofeebucket-count":      "Number of transactions in the bucket",
+	"getmempoolinfofeebucket-vsize":      "Total virtual size in bytes of the transactions in the bucket",
+	"getmempoolinfofeebucket-fees":       "Total fees in DUO of the transactions in the bucket",
+	// GetMempoolInfoAgeBucket help.
+	"getmempoolinfoagebucket-maxage": "Upper bound of the age of the transactions in the bucket in seconds, 0 for the last bucket",
+	"getmempoolinfoagebucket-count":  "Number of transactions in the bucket",
 	// GetMiningInfoResult help.
 	"getmininginforesult-blocks":             "Height of the latest best block",
 	"getmininginforesult-currentblocksize":   "Size of the latest best block",
@@ -365,12 +477,68 @@ var helpDescsEnUS = map[string]string{
 	"getmininginforesult-networkhashps":      "Estimated network hashes per second for the most recent blocks",
 	"getmininginforesult-pooledtx":           "Number of transactions in the memory pool",
 	"getmininginforesult-testnet":            "Whether or not server is using testnet",
+	"getmininginforesult-coinbasetag":        "Text added to the coinbase of new blocks, if any",
+	"getmininginforesult-genalgocurrent":     "Algorithm the built-in miner is working on, picked by difficulty and bias when genalgo is random",
+	"getmininginforesult-algostats":          "Statistics the built-in miner keeps for each algorithm",
+	// GetMiningInfoAlgoStats help.
+	"getmininginfoalgostats-algo":       "Name of the algorithm",
+	"getmininginfoalgostats-difficulty": "Difficulty of the next block for the algorithm relative to its minimum difficulty",
+	"getmininginfoalgostats-hashes":     "Number of hashes computed with the algorithm",
+	"getmininginfoalgostats-blocks":     "Number of accepted blocks found with the algorithm",
+	"getmininginfoalgostats-rounds":     "Number of times the algorithm was picked to be mined",
+	"getmininginfoalgostats-seconds":    "Total time spent mining the algorithm in seconds",
+	// GetMinerKeysCmd help.
+	"getminerkeys--synopsis":    "Returns the API keys miner workers can connect with and the work done with each.",
+	"getminerkeysresult-name":     "The name of the key",
+	"getminerkeysresult-allowed":  "The networks workers may connect from with the key, or empty for any",
+	"getminerkeysresult-workers":  "The number of workers connected with the key",
+	"getminerkeysresult-sessions": "The number of sessions workers have started with the key",
+	"getminerkeysresult-jobs":     "The number of jobs sent to the workers using the key",
+	"getminerkeysresult-accepted": "The number of blocks found by the workers using the key that were accepted",
+	"getminerkeysresult-rejected": "The number of solutions from the workers using the key that were rejected",
+	"getminerkeysresult-lastseen": "The time a worker last subscribed or sent a solution with the key in seconds since 1 Jan 1970 GMT, or 0 if never",
+	// GetMiningAddressesCmd help.
+	"getminingaddresses--synopsis":     "Returns the addresses mined blocks pay to and how they are rotated.",
+	"getminingaddressesresult-rotation":  "The policy for picking the address of each block: 'random', 'roundrobin' or 'weighted'",
+	"getminingaddressesresult-addresses": "The addresses mined blocks pay to",
+	"getminingaddressesaddress-address":  "The payment address",
+	"getminingaddressesaddress-weight":   "The relative chance of the address being picked by the weighted rotation",
+	// GetMiningBiasCmd help.
+	"getminingbias--synopsis":       "Returns the bias the built-in miner picks algorithms with when mining random and the chance of each algorithm being picked for the next block.",
+	"getminingbiasresult-bias":      "The bias, from -1 to always pick the easiest algorithm to 1 to always pick the hardest",
+	"getminingbiasresult-algos":     "The algorithms from the easiest to the hardest relative difficulty",
+	"getminingbiasalgo-algo":        "Name of the algorithm",
+	"getminingbiasalgo-difficulty":  "Difficulty of the next block for the algorithm relative to its minimum difficulty",
+	"getminingbiasalgo-chance":      "Probability from 0 to 1 of the algorithm being picked for a round",
 	// GetMiningInfoCmd help.
 	"getmininginfo--synopsis": "Returns a JSON object containing mining-related information.",
+	// GetMiningStatsResult help.
+	"getminingstatsresult-generate":        "Whether the built-in miner is running",
+	"getminingstatsresult-genalgo":         "Algorithm the built-in miner is set to mine",
+	"getminingstatsresult-genalgocurrent":  "Algorithm the built-in miner is working on, picked by difficulty and bias when genalgo is random",
+	"getminingstatsresult-accepted":        "Number of blocks found by the built-in miner that were accepted",
+	"getminingstatsresult-stale":           "Number of blocks found by the built-in miner that were stale because another block was connected first",
+	"getminingstatsresult-hashrate1m":      "Hashes per second computed by the built-in miner over the last minute",
+	"getminingstatsresult-hashrate5m":      "Hashes per second computed by the built-in miner over the last 5 minutes",
+	"getminingstatsresult-hashrate15m":     "Hashes per second computed by the built-in miner over the last 15 minutes",
+	"getminingstatsresult-expectedseconds": "Average number of seconds to find a block with any of the algorithms at the current difficulties and 5 minute hash rates, or 0 if none are being mined",
+	"getminingstatsresult-algos":           "Statistics of the built-in miner for each algorithm",
+	// GetMiningStatsAlgo help.
+	"getminingstatsalgo-algo":            "Name of the algorithm",
+	"getminingstatsalgo-difficulty":      "Difficulty of the next block for the algorithm relative to its minimum difficulty",
+	"getminingstatsalgo-accepted":        "Number of accepted blocks found with the algorithm",
+	"getminingstatsalgo-stale":           "Number of stale blocks found with the algorithm",
+	"getminingstatsalgo-hashrate1m":      "Hashes per second computed with the algorithm over the last minute",
+	"getminingstatsalgo-hashrate5m":      "Hashes per second computed with the algorithm over the last 5 minutes",
+	"getminingstatsalgo-hashrate15m":     "Hashes per second computed with the algorithm over the last 15 minutes",
+	"getminingstatsalgo-expectedseconds": "Average number of seconds to find a block with the algorithm at its current difficulty and 5 minute hash rate, or 0 if it is not being mined",
+	// GetMiningStatsCmd help.
+	"getminingstats--synopsis": "Returns the blocks found by the built-in miner for each algorithm, its hash rates over sliding windows of 1, 5 and 15 minutes and the expected time to find a block.",
 	// GetNetworkHashPSCmd help.
 	"getnetworkhashps--synopsis": "Returns the estimated network hashes per second for the block heights provided by the parameters.",
 	"getnetworkhashps-blocks":    "The number of blocks, or -1 for blocks since last difficulty change",
 	"getnetworkhashps-height":    "Perform estimate ending with this height or -1 for current best chain block height",
+	"getnetworkhashps-algo":      "Estimate the hashes of only this proof of work algorithm, from the work of its blocks over the time of all the blocks, or omit for all algorithms together",
 	"getnetworkhashps--result0":  "Estimated hashes per second",
 	// GetNetTotalsCmd help.
 	"getnettotals--synopsis": "Returns a JSON object containing network traffic statistics.",
@@ -400,6 +568,25 @@ var helpDescsEnUS = map[string]string{
 	"getpeerinforesult-banscore":       "The ban score",
 	"getpeerinforesult-feefilter":      "The requested minimum fee a transaction must have to be announced to the peer",
 	"getpeerinforesult-syncnode":       "Whether or not the peer is the sync peer",
+	// GetOrphanInfoCmd help.
+	"getorphaninfo--synopsis": "Returns statistics about the orphan pool and the orphan transactions it currently holds, along with the outpoints they are waiting for.",
+	// GetOrphanInfoResult help.
+	"getorphaninforesult-size":       "Number of transactions in the orphan pool",
+	"getorphaninforesult-added":      "Number of orphans added to the pool since startup",
+	"getorphaninforesult-resolved":   "Number of orphans accepted into the mempool after their parents arrived",
+	"getorphaninforesult-expired":    "Number of orphans removed after waiting too long for their parents",
+	"getorphaninforesult-evicted":    "Number of orphans removed to make room for new ones",
+	"getorphaninforesult-invalid":    "Number of orphans rejected once their parents arrived",
+	"getorphaninforesult-conflicted": "Number of orphans removed for double spending a transaction accepted into the mempool",
+	"getorphaninforesult-removed":    "Number of orphans removed because the peer that relayed them disconnected",
+	"getorphaninforesult-orphans":    "The orphan transactions currently in the pool",
+	// GetOrphanInfoEntry help.
+	"getorphaninfoentry-txid":    "The hash of the orphan transaction",
+	"getorphaninfoentry-size":    "Transaction size in bytes",
+	"getorphaninfoentry-peerid":  "The id of the peer that relayed the orphan",
+	"getorphaninfoentry-time":    "Local time the orphan was added to the pool in seconds since 1 Jan 1970 GMT",
+	"getorphaninfoentry-expires": "Local time the orphan expires in seconds since 1 Jan 1970 GMT",
+	"getorphaninfoentry-missing": "The outpoints spent by the orphan whose transactions were not available when it was added",
 	// GetPeerInfoCmd help.
 	"getpeerinfo--synopsis": "Returns data about each connected network peer as an array of json objects.",
 	// GetRawMempoolVerboseResult help.
@@ -411,11 +598,28 @@ var helpDescsEnUS = map[string]string{
 	"getrawmempoolverboseresult-currentpriority":  "Current priority",
 	"getrawmempoolverboseresult-depends":          "Unconfirmed transactions used as inputs for this transaction",
 	"getrawmempoolverboseresult-vsize":            "The virtual size of a transaction",
+	"getrawmempoolverboseresult-descendantcount":  "Number of in-mempool descendant transactions, including this one",
+	"getrawmempoolverboseresult-descendantsize":   "Virtual size of in-mempool descendants, including this one",
+	"getrawmempoolverboseresult-descendantfees":   "Fees of in-mempool descendants in DUO, including this one",
+	"getrawmempoolverboseresult-ancestorcount":    "Number of in-mempool ancestor transactions, including this one",
+	"getrawmempoolverboseresult-ancestorsize":     "Virtual size of in-mempool ancestors, including this one",
+	"getrawmempoolverboseresult-ancestorfees":     "Fees of in-mempool ancestors in DUO, including this one",
+	// GetRawMempoolCompactResult help.
+	"getrawmempoolcompactresult-txid":    "The hash of the transaction",
+	"getrawmempoolcompactresult-feerate": "Fee rate of the transaction in DUO per kB",
 	// GetRawMempoolCmd help.
-	"getrawmempool--synopsis":   "Returns information about all of the transactions currently in the memory pool.",
+	"getrawmempool--synopsis": "Returns information about the transactions currently in the memory pool.\n" +
+		"Transactions are ordered by the time they entered the pool, oldest first, so the pool can be retrieved in pages using skip and count.",
 	"getrawmempool-verbose":     "Returns JSON object when true or an array of transaction hashes when false",
+	"getrawmempool-skip":        "The number of leading matching transactions to leave out of the response",
+	"getrawmempool-count":       "The maximum number of transactions to return, 0 for all",
+	"getrawmempool-minfeerate":  "Only return transactions paying at least this fee rate in DUO per kB",
+	"getrawmempool-minage":      "Only return transactions which entered the pool at least this many seconds ago",
+	"getrawmempool-maxage":      "Only return transactions which entered the pool at most this many seconds ago, 0 for no limit",
+	"getrawmempool-compact":     "Returns an array of transaction hashes and fee rates instead of a JSON object when verbose is true",
 	"getrawmempool--condition0": "verbose=false",
 	"getrawmempool--condition1": "verbose=true",
+	"getrawmempool--condition2": "verbose=true, compact=true",
 	"getrawmempool--result0":    "Array of transaction hashes",
 	// GetRawTransactionCmd help.
 	"getrawtransaction--synopsis":   "Returns information about a transaction given its hash.",
@@ -431,11 +635,53 @@ var helpDescsEnUS = map[string]string{
 	"gettxoutresult-scriptPubKey":  "The public key script used to pay coins as a JSON object",
 	"gettxoutresult-version":       "The transaction version",
 	"gettxoutresult-coinbase":      "Whether or not the transaction is a coinbase",
+	// GetTemplateControlsCmd help.
+	"gettemplatecontrols--synopsis":       "Returns the transactions included in and excluded from every new block template and its maximum weight, as set with settemplatecontrols.",
+	"gettemplatecontrolsresult-include":   "Transactions selected before all others regardless of their fees",
+	"gettemplatecontrolsresult-exclude":   "Transactions never selected, along with any spending their outputs",
+	"gettemplatecontrolsresult-maxweight": "Maximum weight of the block templates, or 0 for the node policy",
 	// GetTxOutCmd help.
 	"gettxout--synopsis":      "Returns information about an unspent transaction output..",
 	"gettxout-txid":           "The hash of the transaction",
 	"gettxout-vout":           "The index of the output",
 	"gettxout-includemempool": "Include the mempool when true",
+	// GetUpdateInfoCmd help.
+	"getupdateinfo--synopsis": "Returns whether a newer release signed by the maintainers is available, as found by checking update.url.",
+	// GetUpdateInfoResult help.
+	"getupdateinforesult-enabled":   "Whether checking for new releases is enabled",
+	"getupdateinforesult-version":   "The version of the running node",
+	"getupdateinforesult-url":       "The URL the signed release manifest is fetched from",
+	"getupdateinforesult-checked":   "When the manifest was last fetched, in seconds since 1 Jan 1970 GMT, omitted before the first check",
+	"getupdateinforesult-error":     "Why the last check failed, omitted if it succeeded",
+	"getupdateinforesult-available": "The version of the newer release, omitted if there is none",
+	"getupdateinforesult-notes":     "The release notes of the newer release",
+	"getupdateinforesult-staged":    "The path the binary of the newer release was downloaded to by stageupdate, omitted until then",
+	// GetWorkCmd help.
+	"getwork--synopsis":   "Returns a block header to solve, derived from the current block template, or submits a solved one for legacy miners.",
+	"getwork-data":        "The padded data returned by a previous call or just the 80 byte block header, both with each 4 bytes byte swapped, with the nonce and time of the solution",
+	"getwork--condition0": "no data provided",
+	"getwork--condition1": "data provided",
+	"getwork--result1":    "Whether or not the solved block was accepted",
+	// GetWorkResult help.
+	"getworkresult-data":     "The padded block header to solve, with each 4 bytes byte swapped",
+	"getworkresult-hash1":    "The padded zero hash, for compatibility only",
+	"getworkresult-midstate": "The sha256 midstate of the first 64 bytes of the block header",
+	"getworkresult-target":   "The target the block hash must not exceed as a little endian 256 bit number",
+	// GetWorkerInfoCmd help.
+	"getworkerinfo--synopsis":         "Returns the miner workers subscribed to the miner controller and the metrics of their transport.",
+	"getworkerinforesult-addr":          "The address of the worker",
+	"getworkerinforesult-transport":     "The transport of the session: 'tcp', 'quic' or 'fec'",
+	"getworkerinforesult-identity":      "The identity key of the worker in hex",
+	"getworkerinforesult-algo":          "The algorithm the worker mines",
+	"getworkerinforesult-conntime":      "The time the worker subscribed in seconds since 1 Jan 1970 GMT",
+	"getworkerinforesult-lastrecv":      "The time a message was last received from the worker in seconds since 1 Jan 1970 GMT",
+	"getworkerinforesult-pingtime":      "The round trip time of the last ping the worker answered in milliseconds, or 0 if it has not answered one",
+	"getworkerinforesult-lost":          "The number of messages from the worker that never arrived or were rejected",
+	"getworkerinforesult-rejected":      "The number of messages from the worker that failed HHMAC authentication",
+	"getworkerinforesult-replayed":      "The number of messages from the worker dropped because their sequence number was already received, which suggests the session is under attack",
+	"getworkerinforesult-reconstructed": "The number of FEC groups from the worker rebuilt from parity shards, on the fec transport",
+	"getworkerinforesult-corrupt":       "The number of FEC shards from the worker that failed their checksum, on the fec transport",
+	"getworkerinforesult-lossrate":      "The moving average of the share of FEC groups sent to the worker that had to be sent again, on the fec transport",
 	// HelpCmd help.
 	"help--synopsis":   "Returns a list of all commands or help for a specified command.",
 	"help-command":     "The command to retrieve help for",
@@ -443,9 +689,18 @@ var helpDescsEnUS = map[string]string{
 	"help--condition1": "command specified",
 	"help--result0":    "List of commands",
 	"help--result1":    "Help for specified command",
+	// LoadUtxoSetCmd help.
+	"loadutxoset--synopsis": "Starts a node with no blocks after the genesis block from a snapshot of the utxo set written by dumputxoset, if its hash is chain.assumeutxo or the maintainers signed it in the file of the same path ending in .sig. The blocks before the snapshot are assumed valid and never downloaded, and the indexes must be disabled.",
+	"loadutxoset-path":      "The path of the snapshot file, relative to the network directory of the node",
 	// PingCmd help.
 	"ping--synopsis": "Queues a ping to be sent to each connected peer.\n" +
 		"Ping times are provided by getpeerinfo via the pingtime and pingwait fields.",
+	// RemoveMinerKeyCmd help.
+	"removeminerkey--synopsis": "Revokes an API key of the miner workers, disconnecting the workers using it.",
+	"removeminerkey-name":      "The name of the key to revoke",
+	// RemoveMiningAddressCmd help.
+	"removeminingaddress--synopsis": "Removes an address from the set of addresses mined blocks pay to.",
+	"removeminingaddress-address":   "The address to remove",
 	// SearchRawTransactionsCmd help.
 	"searchrawtransactions--synopsis": "Returns raw data for transactions involving the passed address.\n" +
 		"Returned transactions are pulled from both the database, and transactions currently in the mempool.\n" +
@@ -467,10 +722,26 @@ var helpDescsEnUS = map[string]string{
 	"sendrawtransaction-hextx":         "Serialized, hex-encoded signed transaction",
 	"sendrawtransaction-allowhighfees": "Whether or not to allow insanely high fees (pod does not yet implement this parameter, so it has no effect)",
 	"sendrawtransaction--result0":      "The hash of the transaction",
+	// SetCoinbaseTagCmd help.
+	"setcoinbasetag--synopsis": "Set the text added to the coinbase of new blocks to mark them, replacing the mining.coinbasetag option until restart.",
+	"setcoinbasetag-tag":       "The tag, at most 74 bytes, or an empty string to remove it",
 	// SetGenerateCmd help.
 	"setgenerate--synopsis":    "Set the server to generate coins (mine) or not.",
 	"setgenerate-generate":     "Use true to enable generation, false to disable it",
 	"setgenerate-genproclimit": "The number of processors (cores) to limit generation to or -1 for default",
+	"setgenerate-cores":        "The cores to pin the miner threads to in turn, empty to let them run on any core, or omitted to leave them as they are (linux only)",
+	"setgenerate-nice":         "How much to lower the scheduling priority of the miner threads from 0 to 19, or omitted to leave it as it is (linux only)",
+	// SetMiningBiasCmd help.
+	"setminingbias--synopsis": "Sets the bias the built-in miner picks algorithms with when mining random, from the next round on. Only available on test networks.",
+	"setminingbias-bias":      "The bias, from -1 to always pick the easiest algorithm through 0 for any to 1 to always pick the hardest",
+	// SetTemplateControlsCmd help.
+	"settemplatecontrols--synopsis": "Sets the transactions to include in and exclude from every new block template and its maximum weight, replacing the controls set before. Included transactions are selected first if they are valid and fit in the block.",
+	"settemplatecontrols-include":   "Transactions to select before all others regardless of their fees",
+	"settemplatecontrols-exclude":   "Transactions never to select, along with any spending their outputs",
+	"settemplatecontrols-maxweight": "Maximum weight of the block templates below the consensus limit, or 0 or omitted for the node policy",
+	// StageUpdateCmd help.
+	"stageupdate--synopsis": "Downloads the binary of the newer release for this platform next to the node's data and checks its hash against the signed release manifest. The running binary is never replaced, stop the node and install the staged one to update.",
+	"stageupdate--result0":  "The path of the staged binary",
 	// StopCmd help.
 	"stop--synopsis": "Shutdown pod.",
 	"stop--result0":  "The string 'pod stopping.'",
@@ -484,8 +755,15 @@ var helpDescsEnUS = map[string]string{
 	"submitblock--condition1": "Block rejected",
 	"submitblock--result1":    "The reason the block was rejected",
 	// ValidateAddressResult help.
-	"validateaddresschainresult-isvalid": "Whether or not the address is valid",
-	"validateaddresschainresult-address": "The bitcoin address (only when isvalid is true)",
+	"validateaddresschainresult-isvalid":         "Whether or not the address is valid",
+	"validateaddresschainresult-address":         "The bitcoin address (only when isvalid is true)",
+	"validateaddresschainresult-type":            "The kind of address: p2pkh, p2sh, p2wpkh, p2wsh, p2tr or p2pk (only when isvalid is true)",
+	"validateaddresschainresult-network":         "The network the address is for, if it is for a known network (only when isvalid is true)",
+	"validateaddresschainresult-scriptclass":     "The class of the output script paying to the address (only when isvalid is true)",
+	"validateaddresschainresult-scriptPubKey":    "The hex-encoded output script paying to the address (only when isvalid is true)",
+	"validateaddresschainresult-iswitness":       "Whether the address is a segwit address",
+	"validateaddresschainresult-witness_version": "The witness version of a segwit address",
+	"validateaddresschainresult-witness_program": "The hex-encoded witness program of a segwit address",
 	// ValidateAddressCmd help.
 	"validateaddress--synopsis": "Verify an address is valid.",
 	"validateaddress-address":   "Bitcoin address to validate",
@@ -512,6 +790,10 @@ var helpDescsEnUS = map[string]string{
 	"notifyblocks--synopsis": "Request notifications for whenever a block is connected or disconnected from the main (best) chain.",
 	// StopNotifyBlocksCmd help.
 	"stopnotifyblocks--synopsis": "Cancel registered notifications for whenever a block is connected or disconnected from the main (best) chain.",
+	// NotifyWorkCmd help.
+	"notifywork--synopsis": "Request a work notification carrying a new block template, in the same form as getblocktemplate returns, whenever the previous one becomes stale because the best chain changed or new transactions arrived in the mempool. The current template is sent right away.",
+	// StopNotifyWorkCmd help.
+	"stopnotifywork--synopsis": "Cancel registered notifications of new block templates.",
 	// NotifyNewTransactionsCmd help.
 	"notifynewtransactions--synopsis": "Send either a txaccepted or a txacceptedverbose notification when a new transaction is accepted into the mempool.",
 	"notifynewtransactions-verbose":   "Specifies which type of notification to receive. If verbose is true, then the caller receives txacceptedverbose, otherwise the caller receives txaccepted",
@@ -572,13 +854,19 @@ var helpDescsEnUS = map[string]string{
 }
 // rpcResultTypes specifies the result types that each RPC command can return. This information is used to generate the help.  Each result type must be a pointer to the type (or nil to indicate no return value).
 var rpcResultTypes = map[string][]interface{}{
+	"addminerkey":           nil,
+	"addminingaddress":      nil,
 	"addnode":               nil,
 	"createrawtransaction":  {(*string)(nil)},
 	"debuglevel":            {(*string)(nil), (*string)(nil)},
 	"decoderawtransaction":  {(*json.TxRawDecodeResult)(nil)},
 	"decodescript":          {(*json.DecodeScriptResult)(nil)},
+	"dumputxoset":           {(*json.UtxoSnapshotResult)(nil)},
 	"estimatefee":           {(*float64)(nil)},
+	"estimaterawfee":        {(*[]json.EstimateRawFeeBucket)(nil)},
+	"estimatesmartfee":      {(*json.EstimateSmartFeeResult)(nil)},
 	"generate":              {(*[]string)(nil)},
+	"generatetoaddress":     {(*[]string)(nil)},
 	"getaddednodeinfo":      {(*[]string)(nil), (*[]json.GetAddedNodeInfoResult)(nil)},
 	"getbestblock":          {(*json.GetBestBlockResult)(nil)},
 	"getbestblockhash":      {(*string)(nil)},
@@ -587,30 +875,51 @@ var rpcResultTypes = map[string][]interface{}{
 	"getblockhash":          {(*string)(nil)},
 	"getblockheader":        {(*string)(nil), (*json.GetBlockHeaderVerboseResult)(nil)},
 	"getblocktemplate":      {(*json.GetBlockTemplateResult)(nil), (*string)(nil), nil},
+	"getchaintips":          {(*[]json.GetChainTipsResult)(nil)},
+	"getchaintxstats":       {(*json.GetChainTxStatsResult)(nil)},
 	"getblockchaininfo":     {(*json.GetBlockChainInfoResult)(nil)},
 	"getcfilter":            {(*string)(nil)},
 	"getcfilterheader":      {(*string)(nil)},
 	"getconnectioncount":    {(*int32)(nil)},
 	"getcurrentnet":         {(*uint32)(nil)},
+	"getdifficulties":       {(*[]json.GetDifficultiesResult)(nil)},
 	"getdifficulty":         {(*float64)(nil)},
+	"getdustinfo":           {(*json.GetDustInfoResult)(nil)},
 	"getgenerate":           {(*bool)(nil)},
 	"gethashespersec":       {(*float64)(nil)},
 	"getheaders":            {(*[]string)(nil)},
 	"getinfo":               {(*json.InfoChainResult)(nil)},
+	"getmempoolentry":       {(*json.GetMempoolEntryResult)(nil)},
 	"getmempoolinfo":        {(*json.GetMempoolInfoResult)(nil)},
+	"getminerkeys":          {(*[]json.GetMinerKeysResult)(nil)},
+	"getminingaddresses":    {(*json.GetMiningAddressesResult)(nil)},
+	"getminingbias":         {(*json.GetMiningBiasResult)(nil)},
 	"getmininginfo":         {(*json.GetMiningInfoResult)(nil)},
+	"getminingstats":        {(*json.GetMiningStatsResult)(nil)},
 	"getnettotals":          {(*json.GetNetTotalsResult)(nil)},
 	"getnetworkhashps":      {(*int64)(nil)},
+	"getorphaninfo":         {(*json.GetOrphanInfoResult)(nil)},
 	"getpeerinfo":           {(*[]json.GetPeerInfoResult)(nil)},
-	"getrawmempool":         {(*[]string)(nil), (*json.GetRawMempoolVerboseResult)(nil)},
+	"getrawmempool":         {(*[]string)(nil), (*json.GetRawMempoolVerboseResult)(nil), (*[]json.GetRawMempoolCompactResult)(nil)},
 	"getrawtransaction":     {(*string)(nil), (*json.TxRawResult)(nil)},
+	"gettemplatecontrols":   {(*json.GetTemplateControlsResult)(nil)},
 	"gettxout":              {(*json.GetTxOutResult)(nil)},
+	"getupdateinfo":         {(*json.GetUpdateInfoResult)(nil)},
+	"getwork":               {(*json.GetWorkResult)(nil), (*bool)(nil)},
+	"getworkerinfo":         {(*[]json.GetWorkerInfoResult)(nil)},
 	"node":                  nil,
 	"help":                  {(*string)(nil), (*string)(nil)},
+	"loadutxoset":           {(*json.UtxoSnapshotResult)(nil)},
 	"ping":                  nil,
+	"removeminerkey":        nil,
+	"removeminingaddress":   nil,
 	"searchrawtransactions": {(*string)(nil), (*[]json.SearchRawTransactionsResult)(nil)},
 	"sendrawtransaction":    {(*string)(nil)},
+	"setcoinbasetag":        nil,
 	"setgenerate":           nil,
+	"setminingbias":         nil,
+	"settemplatecontrols":   nil,
+	"stageupdate":           {(*string)(nil)},
 	"stop":                  {(*string)(nil)},
 	"submitblock":           {nil, (*string)(nil)},
 	"uptime":                {(*int64)(nil)},
@@ -629,6 +938,8 @@ var rpcResultTypes = map[string][]interface{}{
 	"stopnotifyreceived":        nil,
 	"notifyspent":               nil,
 	"stopnotifyspent":           nil,
+	"notifywork":                nil,
+	"stopnotifywork":            nil,
 	"rescan":                    nil,
 	"rescanblocks":              {(*[]json.RescannedBlock)(nil)},
 }
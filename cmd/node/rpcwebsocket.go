@@ -28,6 +28,7 @@ import (
 // Notification types
 type notificationBlockConnected util.Block
 type notificationBlockDisconnected util.Block
+type notificationChainReorganized blockchain.Reorganization
 type notificationRegisterAddr struct {
 	wsc   *wsClient
 	addrs []string
@@ -40,6 +41,7 @@ type notificationRegisterSpent struct {
 	wsc *wsClient
 	ops []*wire.OutPoint
 }
+type notificationRegisterWork wsClient
 type notificationTxAcceptedByMempool struct {
 	isNew bool
 	tx    *util.Tx
@@ -55,6 +57,7 @@ type notificationUnregisterSpent struct {
 	wsc *wsClient
 	op  *wire.OutPoint
 }
+type notificationUnregisterWork wsClient
 type rescanKeys struct {
 	fallbacks           map[string]struct{}
 	pubKeyHashes        map[[ripemd160.Size]byte]struct{}
@@ -153,11 +156,13 @@ var wsHandlersBeforeInit = map[string]wsCommandHandler{
 	"notifynewtransactions":     handleNotifyNewTransactions,
 	"notifyreceived":            handleNotifyReceived,
 	"notifyspent":               handleNotifySpent,
+	"notifywork":                handleNotifyWork,
 	"session":                   handleSession,
 	"stopnotifyblocks":          handleStopNotifyBlocks,
 	"stopnotifynewtransactions": handleStopNotifyNewTransactions,
 	"stopnotifyspent":           handleStopNotifySpent,
 	"stopnotifyreceived":        handleStopNotifyReceived,
+	"stopnotifywork":            handleStopNotifyWork,
 	"rescan":                    handleRescan,
 	"rescanblocks":              handleRescanBlocks,
 }
@@ -719,6 +724,17 @@ func (
 	case <-m.quit:
 	}
 }
+// NotifyChainReorganized passes a reorganization of the best chain to the notification manager for block notification processing.
+func (
+	m *wsNotificationManager,
+) NotifyChainReorganized(
+	reorg *blockchain.Reorganization) {
+	// As NotifyChainReorganized will be called by the block manager and the RPC server may no longer be running, use a select statement to unblock enqueuing the notification once the RPC server has begun shutting down.
+	select {
+	case m.queueNotification <- (*notificationChainReorganized)(reorg):
+	case <-m.quit:
+	}
+}
 // NotifyMempoolTx passes a transaction accepted by mempool to the notification manager for transaction notification processing.  If isNew is true, the tx is is a new transaction, rather than one added to the mempool during a reorg.
 func (
 	m *wsNotificationManager,
@@ -791,6 +807,14 @@ func (
 		addrs: addrs,
 	}
 }
+// RegisterWorkUpdates requests new block templates to be sent to the passed websocket client whenever the previous one becomes stale, starting with the current one.
+func (
+	m *wsNotificationManager,
+) RegisterWorkUpdates(
+	wsc *wsClient,
+) {
+	m.queueNotification <- (*notificationRegisterWork)(wsc)
+}
 // RemoveClient removes the passed websocket client and all notifications registered for it.
 func (
 	m *wsNotificationManager,
@@ -852,6 +876,14 @@ func (
 		addr: addr,
 	}
 }
+// UnregisterWorkUpdates removes block template notifications for the passed websocket client.
+func (
+	m *wsNotificationManager,
+) UnregisterWorkUpdates(
+	wsc *wsClient,
+) {
+	m.queueNotification <- (*notificationUnregisterWork)(wsc)
+}
 // WaitForShutdown blocks until all notification manager goroutines have finished.
 func (
 	m *wsNotificationManager,
@@ -922,6 +954,9 @@ func (
 	txNotifications := make(map[chan struct{}]*wsClient)
 	watchedOutPoints := make(map[wire.OutPoint]map[chan struct{}]*wsClient)
 	watchedAddrs := make(map[string]map[chan struct{}]*wsClient)
+	workNotifications := make(map[chan struct{}]*wsClient)
+	// lastWork is when work was last sent to the clients registered for it, so changes to the mempool do not cause a new template to be built for every transaction.
+	var lastWork time.Time
 out:
 	for {
 		select {
@@ -947,6 +982,10 @@ out:
 					m.notifyFilteredBlockConnected(blockNotifications,
 						block)
 				}
+				if len(workNotifications) != 0 {
+					m.notifyWork(workNotifications, json.WorkReasonConnected)
+					lastWork = time.Now()
+				}
 			case *notificationBlockDisconnected:
 				block := (*util.Block)(n)
 				if len(blockNotifications) != 0 {
@@ -955,12 +994,27 @@ out:
 					m.notifyFilteredBlockDisconnected(blockNotifications,
 						block)
 				}
+				if len(workNotifications) != 0 {
+					m.notifyWork(workNotifications, json.WorkReasonDisconnected)
+					lastWork = time.Now()
+				}
+			case *notificationChainReorganized:
+				if len(blockNotifications) != 0 {
+					m.notifyChainReorganized(blockNotifications,
+						(*blockchain.Reorganization)(n))
+				}
 			case *notificationTxAcceptedByMempool:
 				if n.isNew && len(txNotifications) != 0 {
 					m.notifyForNewTx(txNotifications, n.tx)
 				}
 				m.notifyForTx(watchedOutPoints, watchedAddrs, n.tx, nil)
 				m.notifyRelevantTxAccepted(n.tx, clients)
+				// New transactions are only worth new work once the block template would be regenerated to include them.
+				if n.isNew && len(workNotifications) != 0 &&
+					time.Since(lastWork) > time.Second*gbtRegenerateSeconds {
+					m.notifyWork(workNotifications, json.WorkReasonMempool)
+					lastWork = time.Now()
+				}
 			case *notificationRegisterBlocks:
 				wsc := (*wsClient)(n)
 				blockNotifications[wsc.quit] = wsc
@@ -975,6 +1029,7 @@ out:
 				// Remove any requests made by the client as well as the client itself.
 				delete(blockNotifications, wsc.quit)
 				delete(txNotifications, wsc.quit)
+				delete(workNotifications, wsc.quit)
 				for k := range wsc.spentRequests {
 					op := k
 					m.removeSpentRequest(watchedOutPoints, wsc, &op)
@@ -997,6 +1052,15 @@ out:
 			case *notificationUnregisterNewMempoolTxs:
 				wsc := (*wsClient)(n)
 				delete(txNotifications, wsc.quit)
+			case *notificationRegisterWork:
+				wsc := (*wsClient)(n)
+				workNotifications[wsc.quit] = wsc
+				// Send the current work right away so the client does not have to wait for the next block.
+				m.notifyWork(map[chan struct{}]*wsClient{wsc.quit: wsc},
+					json.WorkReasonSubscribed)
+			case *notificationUnregisterWork:
+				wsc := (*wsClient)(n)
+				delete(workNotifications, wsc.quit)
 			default:
 				log <- cl.Wrn("unhandled notification type")
 			}
@@ -1032,6 +1096,47 @@ func (
 		wsc.QueueNotification(marshalledJSON)
 	}
 }
+// notifyWork sends a new block template to the websocket clients that have registered for work updates. The template is built in its own goroutine so the notification handler is not held up while the block is assembled.
+func (
+	m *wsNotificationManager,
+) notifyWork(
+	clients map[chan struct{}]*wsClient,
+	reason string,
+) {
+	// Copy the clients as the map belongs to the notification handler.
+	workClients := make([]*wsClient, 0, len(clients))
+	for _, wsc := range clients {
+		workClients = append(workClients, wsc)
+	}
+	go func() {
+		s := m.server
+		// No point in handing out work before the chain is synced.
+		if s.Cfg.Chain.BestSnapshot().Height != 0 && !s.Cfg.SyncMgr.IsCurrent() {
+			return
+		}
+		state := s.gbtWorkState
+		state.Lock()
+		err := state.updateBlockTemplate(s, true)
+		var template *json.GetBlockTemplateResult
+		if err == nil {
+			template, err = state.blockTemplateResult(true, nil)
+		}
+		state.Unlock()
+		if err != nil {
+			log <- cl.Warn{"failed to create block template for work notification:", err}
+			return
+		}
+		ntfn := json.NewWorkNtfn(reason, *template)
+		marshalledJSON, err := json.MarshalCmd(nil, ntfn)
+		if err != nil {
+			log <- cl.Error{"failed to marshal work notification:", err}
+			return
+		}
+		for _, wsc := range workClients {
+			wsc.QueueNotification(marshalledJSON)
+		}
+	}()
+}
 // notifyBlockDisconnected notifies websocket clients that have registered for block updates when a block is disconnected from the main chain (due to a reorganize).
 func (
 	_ *wsNotificationManager,
@@ -1055,6 +1160,45 @@ func (
 		wsc.QueueNotification(marshalledJSON)
 	}
 }
+// notifyChainReorganized notifies websocket clients that have registered for block updates when the main chain is reorganized onto another branch.
+func (
+	_ *wsNotificationManager,
+) notifyChainReorganized(
+	clients map[chan struct{}]*wsClient,
+	reorg *blockchain.Reorganization,
+) {
+	ntfn := json.NewChainReorganizedNtfn(reorgDetails(reorg))
+	marshalledJSON, err := json.MarshalCmd(nil, ntfn)
+	if err != nil {
+		log <- cl.Error{"failed to marshal chain reorganized notification:", err}
+		return
+	}
+	for _, wsc := range clients {
+		wsc.QueueNotification(marshalledJSON)
+	}
+}
+// reorgDetails converts a reorganization of the chain to its JSON representation.
+func reorgDetails(
+	reorg *blockchain.Reorganization) json.ReorgDetails {
+	details := json.ReorgDetails{
+		OldTip:       reorg.OldTip.String(),
+		OldHeight:    reorg.OldHeight,
+		NewTip:       reorg.NewTip.String(),
+		NewHeight:    reorg.NewHeight,
+		Fork:         reorg.Fork.String(),
+		ForkHeight:   reorg.ForkHeight,
+		Depth:        reorg.Depth(),
+		Disconnected: make([]string, len(reorg.Disconnected)),
+		Connected:    make([]string, len(reorg.Connected)),
+	}
+	for i := range reorg.Disconnected {
+		details.Disconnected[i] = reorg.Disconnected[i].String()
+	}
+	for i := range reorg.Connected {
+		details.Connected[i] = reorg.Connected[i].String()
+	}
+	return details
+}
 // notifyFilteredBlockConnected notifies websocket clients that have registered for block updates when a block is connected to the main chain.
 func (
 	m *wsNotificationManager,
@@ -1512,6 +1656,12 @@ func handleNotifyBlocks(
 	wsc.server.ntfnMgr.RegisterBlockUpdates(wsc)
 	return nil, nil
 }
+// handleNotifyWork implements the notifywork command extension for websocket connections.
+func handleNotifyWork(
+	wsc *wsClient, icmd interface{}) (interface{}, error) {
+	wsc.server.ntfnMgr.RegisterWorkUpdates(wsc)
+	return nil, nil
+}
 // handleNotifyNewTransations implements the notifynewtransactions command extension for websocket connections.
 func handleNotifyNewTransactions(
 	wsc *wsClient, icmd interface{}) (interface{}, error) {
@@ -1871,6 +2021,12 @@ func handleStopNotifyBlocks(
 	wsc.server.ntfnMgr.UnregisterBlockUpdates(wsc)
 	return nil, nil
 }
+// handleStopNotifyWork implements the stopnotifywork command extension for websocket connections.
+func handleStopNotifyWork(
+	wsc *wsClient, icmd interface{}) (interface{}, error) {
+	wsc.server.ntfnMgr.UnregisterWorkUpdates(wsc)
+	return nil, nil
+}
 // handleStopNotifyNewTransations implements the stopnotifynewtransactions command extension for websocket connections.
 func handleStopNotifyNewTransactions(
 	wsc *wsClient, icmd interface{}) (interface{}, error) {
@@ -23,6 +23,8 @@ import (
 	indexers "git.parallelcoin.io/dev/9/pkg/chain/index"
 	"git.parallelcoin.io/dev/9/pkg/chain/mining"
 	cpuminer "git.parallelcoin.io/dev/9/pkg/chain/mining/cpu"
+	controller "git.parallelcoin.io/dev/9/pkg/chain/mining/dispatch"
+	"git.parallelcoin.io/dev/9/pkg/chain/mining/stratum"
 	netsync "git.parallelcoin.io/dev/9/pkg/chain/sync"
 	txscript "git.parallelcoin.io/dev/9/pkg/chain/tx/script"
 	"git.parallelcoin.io/dev/9/pkg/chain/wire"
@@ -112,8 +114,15 @@ type server struct {
 	syncManager   *netsync.SyncManager
 	chain         *blockchain.BlockChain
 	txMemPool     *mempool.TxPool
+	webhooks      *webhookNotifier
+	telemetry     *telemetryReporter
+	updates       *updateChecker
+	backend       *chainBackend
 	cpuMiner      *cpuminer.CPUMiner
-	// minerController      *controller.Controller
+	stratum       *stratum.Server
+	payAddrs      *mining.PayAddrs
+	minerController      *controller.Controller
+	minerKeys            *controller.APIKeys
 	modifyRebroadcastInv chan interface{}
 	newPeers             chan *serverPeer
 	donePeers            chan *serverPeer
@@ -178,6 +187,8 @@ const defaultServices = wire.SFNodeNetwork | wire.SFNodeBloom |
 const defaultRequiredServices = wire.SFNodeNetwork
 // defaultTargetOutbound is the default number of outbound peers to target.
 const defaultTargetOutbound = 9
+// feeEstimatorSaveInterval is the number of blocks between saves of the fee estimator state to the database, so the confirmation history survives an unclean shutdown.
+const feeEstimatorSaveInterval = 6
 // connectionRetryInterval is the base amount of time to wait in between retries when connecting to persistent peers.  It is adjusted by the number of retries such that there is a retry backoff.
 const connectionRetryInterval = time.Second
 // Ensure simpleAddr implements the net.Addr interface.
@@ -403,13 +414,30 @@ func (s *server) Start() {
 	} else {
 		panic("cannot run without RPC")
 	}
+	// Start delivering mempool events if webhooks are configured.
+	if s.webhooks != nil {
+		s.webhooks.Start()
+	}
+	// Start reporting telemetry if it was opted into.
+	if s.telemetry != nil {
+		s.telemetry.Start()
+	}
+	// Start checking for new releases if it is enabled.
+	if s.updates != nil {
+		s.updates.Start()
+	}
 	// Start the CPU miner if generation is enabled.
 	if *Cfg.Generate {
 		s.cpuMiner.Start()
 	}
-	// if *Cfg.MinerListener != "" {
-	// 	s.minerController.Start()
-	// }
+	// Start serving stratum miners if listeners are configured.
+	if s.stratum != nil {
+		s.stratum.Start()
+	}
+	// Start the miner controller if workers can connect
+	if s.minerController != nil {
+		s.minerController.Start()
+	}
 }
 // Stop gracefully shuts down the server by stopping and disconnecting all peers and the main listener.
 func (
@@ -423,24 +451,50 @@ func (
 	log <- cl.Wrn("server shutting down")
 	// Stop the CPU miner if needed
 	s.cpuMiner.Stop()
+	// Stop the stratum server if needed
+	if s.stratum != nil {
+		s.stratum.Stop()
+	}
 	// Stop miner controller if needed
-	// s.minerController.Stop()
+	if s.minerController != nil {
+		s.minerController.Stop()
+	}
 	// Shutdown the RPC server if it's not disabled.
 	if !*Cfg.DisableRPC {
 		for i := range s.rpcServers {
 			s.rpcServers[i].Stop()
 		}
 	}
+	// Stop delivering mempool events.
+	if s.webhooks != nil {
+		s.webhooks.Stop()
+	}
+	// Stop reporting telemetry.
+	if s.telemetry != nil {
+		s.telemetry.Stop()
+	}
+	// Stop checking for new releases.
+	if s.updates != nil {
+		s.updates.Stop()
+	}
 	// Save fee estimator state in the database.
-	s.db.Update(func(tx database.Tx) error {
-		metadata := tx.Metadata()
-		metadata.Put(mempool.EstimateFeeDatabaseKey, s.feeEstimator.Save())
-		return nil
-	})
+	s.saveFeeEstimator()
 	// Signal the remaining goroutines to quit.
 	close(s.quit)
 	return nil
 }
+// saveFeeEstimator writes the current fee estimator state to the database.
+func (
+	s *server,
+) saveFeeEstimator() {
+	e := s.db.Update(func(tx database.Tx) error {
+		metadata := tx.Metadata()
+		return metadata.Put(mempool.EstimateFeeDatabaseKey, s.feeEstimator.Save())
+	})
+	if e != nil {
+		log <- cl.Warn{"failed to save fee estimator state:", e}
+	}
+}
 // Transaction has one confirmation on the main chain. Now we can mark it as no longer needing rebroadcasting.
 func (
 	s *server,
@@ -1658,8 +1712,9 @@ func (
 	sp *serverPeer,
 ) OnGetHeaders(
 	_ *peer.Peer, msg *wire.MsgGetHeaders) {
-	// Ignore getheaders requests if not in sync.
-	if !sp.server.syncManager.IsCurrent() {
+	// Ignore getheaders requests if not in sync, unless the peer has fewer blocks, so that new peers can start downloading the chain from a node that is still syncing itself.
+	if !sp.server.syncManager.IsCurrent() &&
+		sp.LastBlock() >= sp.server.chain.BestSnapshot().Height {
 		return
 	}
 	// Find the most recent known block in the best chain based on the block locator and fetch all of the headers after it until either wire.MaxBlockHeadersPerMsg have been fetched or the provided stop hash is encountered. Use the block after the genesis block if no other blocks in the provided locator are known.  This does mean the client will start over with the genesis block if unknown block locators are provided. This mirrors the behavior in the reference implementation.
@@ -2412,24 +2467,39 @@ func newServer(
 			s.chainParams.Checkpoints,
 			StateCfg.AddedCheckpoints)
 	}
+	var invalidBlockDir string
+	if *Cfg.DumpInvalid {
+		invalidBlockDir = filepath.Join(*Cfg.AppDataDir, NetName(ActiveNetParams), "invalid")
+	}
 	// Create a new block chain instance with the appropriate configuration.
 	var err error
 	s.chain, err = blockchain.New(
 		&blockchain.Config{
-			DB:           s.db,
-			Interrupt:    interruptChan,
-			ChainParams:  s.chainParams,
-			Checkpoints:  checkpoints,
-			TimeSource:   s.timeSource,
-			SigCache:     s.sigCache,
-			IndexManager: indexManager,
-			HashCache:    s.hashCache,
+			DB:              s.db,
+			Interrupt:       interruptChan,
+			ChainParams:     s.chainParams,
+			Checkpoints:     checkpoints,
+			TimeSource:      s.timeSource,
+			SigCache:        s.sigCache,
+			IndexManager:    indexManager,
+			HashCache:       s.hashCache,
+			AssumeValid:     StateCfg.AssumeValid,
+			FullVerify:      *Cfg.FullVerify,
+			UtxoCacheSize:   StateCfg.UtxoCacheSize,
+			InvalidBlockDir: invalidBlockDir,
 		},
 	)
 	if err != nil {
 		return nil, err
 	}
 	s.chain.DifficultyAdjustments = make(map[string]float64)
+	if *Cfg.LoadUtxoSet != "" {
+		if best := s.chain.BestSnapshot(); best.Height != 0 {
+			log <- cl.Warnf{"not loading utxo snapshot %s, the chain already has blocks up to height %d", *Cfg.LoadUtxoSet, best.Height}
+		} else if _, err = loadUtxoSnapshot(s.chain, *Cfg.LoadUtxoSet); err != nil {
+			return nil, err
+		}
+	}
 	// Search for a FeeEstimator state in the database. If none can be found or if it cannot be loaded, create a new one.
 	e := db.Update(func(tx database.Tx) error {
 		metadata := tx.Metadata()
@@ -2452,23 +2522,52 @@ func newServer(
 	if e != nil {
 		log <- cl.Error{e}
 	}
-	// If no feeEstimator has been found, or if the one that has been found is behind somehow, create a new one and start over.
-	if s.feeEstimator == nil || s.feeEstimator.LastKnownHeight() != s.chain.BestSnapshot().Height {
+	// If no feeEstimator has been found create a new one. If the one that has been found is not at the chain tip, keep its history and move it to the current height so estimates are available straight away.
+	if s.feeEstimator == nil {
 		s.feeEstimator = mempool.NewFeeEstimator(
 			mempool.DefaultEstimateFeeMaxRollback,
 			mempool.DefaultEstimateFeeMinRegisteredBlocks,
 		)
+	} else if best := s.chain.BestSnapshot().Height; s.feeEstimator.LastKnownHeight() != best {
+		log <- cl.Infof{
+			"fee estimator state is at height %d, resyncing to %d",
+			s.feeEstimator.LastKnownHeight(), best,
+		}
+		s.feeEstimator.Resync(best)
 	}
+	// Periodically persist the fee estimator so its history is not lost if the node is not shut down cleanly.
+	s.chain.Subscribe(func(n *blockchain.Notification) {
+		if n.Type != blockchain.NTBlockConnected {
+			return
+		}
+		if block, ok := n.Data.(*util.Block); ok &&
+			block.Height()%feeEstimatorSaveInterval == 0 {
+			s.saveFeeEstimator()
+		}
+	})
+	// Networks may require fee rate only relay, otherwise it is up to the configuration.
+	feeRateOnly := *Cfg.FeeRateOnly || chainParams.FeeRateOnly
 	txC := mempool.Config{
 		Policy: mempool.Policy{
-			DisableRelayPriority: *Cfg.NoRelayPriority,
+			DisableRelayPriority: *Cfg.NoRelayPriority || feeRateOnly,
 			AcceptNonStd:         *Cfg.RelayNonStd,
 			FreeTxRelayLimit:     *Cfg.FreeTxRelayLimit,
+			FeeRateOnly:          feeRateOnly,
 			MaxOrphanTxs:         *Cfg.MaxOrphanTxs,
+			MaxOrphanTxsPerPeer:  *Cfg.MaxOrphanTxsPerPeer,
+			OrphanTTL:            *Cfg.OrphanExpiry,
 			MaxOrphanTxSize:      DefaultMaxOrphanTxSize,
 			MaxSigOpCostPerTx:    blockchain.MaxBlockSigOpsCost / 4,
 			MinRelayTxFee:        StateCfg.ActiveMinRelayTxFee,
+			DustRelayFee:         StateCfg.ActiveDustRelayFee,
 			MaxTxVersion:         2,
+			MaxAncestorCount:     *Cfg.MaxAncestors,
+			MaxAncestorSize:      *Cfg.MaxAncestorSize,
+			MaxDescendantCount:   *Cfg.MaxDescendants,
+			MaxDescendantSize:    *Cfg.MaxDescendantSize,
+			AcceptReplacement:    *Cfg.MempoolReplacement,
+			MaxPoolSize:          int64(*Cfg.MaxMempool) * 1000000,
+			Expiry:               time.Duration(*Cfg.MempoolExpiry) * time.Hour,
 		},
 		ChainParams:   chainParams,
 		FetchUtxoView: s.chain.FetchUtxoView,
@@ -2487,7 +2586,57 @@ func newServer(
 		AddrIndex:          s.addrIndex,
 		FeeEstimator:       s.feeEstimator,
 	}
+	if len(*Cfg.MempoolWebhooks) > 0 {
+		s.webhooks = newWebhookNotifier(*Cfg.MempoolWebhooks,
+			*Cfg.MempoolWebhookSecret, *Cfg.MempoolWebhookRetries)
+		txC.NotifyEvent = s.webhooks.NotifyEvent
+		// Reorganizations of the chain go to the same webhooks, so services watching transactions learn of blocks that were undone.
+		s.chain.Subscribe(func(n *blockchain.Notification) {
+			if n.Type != blockchain.NTChainReorganized {
+				return
+			}
+			if reorg, ok := n.Data.(*blockchain.Reorganization); ok {
+				s.webhooks.NotifyReorg(reorg)
+			}
+		})
+	}
+	// Transactions accepted to the mempool also go to a wallet using the chain in the same process.
+	s.backend = newChainBackend(&s)
+	notifyWebhooks := txC.NotifyEvent
+	txC.NotifyEvent = func(ev *mempool.Event) {
+		if notifyWebhooks != nil {
+			notifyWebhooks(ev)
+		}
+		s.backend.notifyEvent(ev)
+	}
 	s.txMemPool = mempool.New(&txC)
+	// Telemetry is only ever reported when it is enabled and an endpoint is set.
+	if *Cfg.Telemetry {
+		if *Cfg.TelemetryEndpoint == "" {
+			log <- cl.Wrn("telemetry is enabled but telemetry.endpoint is not set, not reporting")
+		} else {
+			s.telemetry = newTelemetryReporter(*Cfg.TelemetryEndpoint, *Cfg.TelemetryInterval,
+				func() *TelemetryReport {
+					return NewTelemetryReport(ActiveNetParams.Params.Name,
+						s.chain.BestSnapshot().Height, s.ConnectedCount())
+				})
+		}
+	}
+	// New releases are only looked for when it is enabled, and only trusted when signed with the maintainer keys built in.
+	if *Cfg.Update {
+		keys, err := ParseUpdateKeys(updateKeys)
+		switch {
+		case err != nil:
+			log <- cl.Warn{"not checking for updates:", err}
+		case len(keys) == 0:
+			log <- cl.Wrn("update is enabled but this build has no maintainer keys to verify releases with, not checking")
+		case *Cfg.UpdateURL == "":
+			log <- cl.Wrn("update is enabled but update.url is not set, not checking")
+		default:
+			s.updates = newUpdateChecker(*Cfg.UpdateURL, *Cfg.UpdateInterval, keys,
+				filepath.Join(*Cfg.AppDataDir, "update"))
+		}
+	}
 	s.syncManager, err =
 		netsync.New(
 			&netsync.Config{
@@ -2498,6 +2647,7 @@ func newServer(
 				DisableCheckpoints: *Cfg.DisableCheckpoints,
 				MaxPeers:           *Cfg.MaxPeers,
 				FeeEstimator:       s.feeEstimator,
+				ImportedHeaders:    loadImportedHeaders(),
 			},
 		)
 	if err != nil {
@@ -2511,33 +2661,90 @@ func newServer(
 		BlockMinSize:      uint32(*Cfg.BlockMinSize),
 		BlockMaxSize:      uint32(*Cfg.BlockMaxSize),
 		BlockPrioritySize: uint32(*Cfg.BlockPrioritySize),
+		FeeRateOnly:       feeRateOnly,
 		TxMinFreeFee:      StateCfg.ActiveMinRelayTxFee,
+		CoinbaseTag:       *Cfg.CoinbaseTag,
+	}
+	if feeRateOnly {
+		policy.BlockPrioritySize = 0
 	}
 	blockTemplateGenerator := mining.NewBlkTmplGenerator(&policy,
 		s.chainParams, s.txMemPool, s.chain, s.timeSource,
 		s.sigCache, s.hashCache, s.algo)
+	// The miners share one set of payment addresses so changes made through the RPC server reach all of them.
+	payAddrs := make([]mining.PayAddr, len(StateCfg.ActiveMiningAddrs))
+	for i, addr := range StateCfg.ActiveMiningAddrs {
+		payAddrs[i] = mining.PayAddr{Addr: addr, Weight: 1}
+		if i < len(StateCfg.ActiveMiningWeights) {
+			payAddrs[i].Weight = StateCfg.ActiveMiningWeights[i]
+		}
+	}
+	s.payAddrs, err = mining.NewPayAddrs(*Cfg.MiningRotation, payAddrs)
+	if err != nil {
+		return nil, err
+	}
 	s.cpuMiner = cpuminer.New(&cpuminer.Config{
 		Blockchain:             s.chain,
 		ChainParams:            chainParams,
 		BlockTemplateGenerator: blockTemplateGenerator,
-		MiningAddrs:            StateCfg.ActiveMiningAddrs,
+		PayAddrs:               s.payAddrs,
 		ProcessBlock:           s.syncManager.ProcessBlock,
 		ConnectedCount:         s.ConnectedCount,
 		IsCurrent:              s.syncManager.IsCurrent,
 		NumThreads:             s.numthreads,
 		Algo:                   s.algo,
+		Bias:                   *Cfg.MinerBias,
+		Switch:                 *Cfg.MinerSwitch,
+		Cores:                  StateCfg.ActiveMinerCores,
+		Nice:                   *Cfg.MinerNice,
 	})
-	// s.minerController = controller.New(&controller.Config{
-	// 	Blockchain:             s.chain,
-	// 	ChainParams:            chainParams,
-	// 	BlockTemplateGenerator: blockTemplateGenerator,
-	// 	MiningAddrs:            StateCfg.ActiveMiningAddrs,
-	// 	ProcessBlock:           s.syncManager.ProcessBlock,
-	// 	MinerListener:          *Cfg.MinerListener,
-	// 	MinerKey:               StateCfg.ActiveMinerKey,
-	// 	ConnectedCount:         s.ConnectedCount,
-	// 	IsCurrent:              s.syncManager.IsCurrent,
-	// })
+	if len(*Cfg.StratumListeners) > 0 {
+		if s.payAddrs.Len() == 0 {
+			return nil, stratum.ErrNoPayAddrs
+		}
+		listeners, err := stratum.ParseListeners(*Cfg.StratumListeners, s.algo)
+		if err != nil {
+			return nil, err
+		}
+		difficulty, err := stratum.ParseDifficulties(*Cfg.StratumDifficulty)
+		if err != nil {
+			return nil, err
+		}
+		s.stratum, err = stratum.New(&stratum.Config{
+			Listeners:    listeners,
+			Difficulty:   difficulty,
+			ShareTime:    *Cfg.StratumShareTime,
+			Pass:         *Cfg.MinerPass,
+			Generator:    blockTemplateGenerator,
+			PayAddrs:     s.payAddrs,
+			ProcessBlock: s.syncManager.ProcessBlock,
+			IsCurrent:    s.syncManager.IsCurrent,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(*Cfg.MinerListener) > 0 {
+		s.minerKeys, err = controller.NewAPIKeys(*Cfg.MinerAPIKeys)
+		if err != nil {
+			return nil, err
+		}
+		s.minerController = controller.New(&controller.Config{
+			Blockchain:             s.chain,
+			ChainParams:            chainParams,
+			BlockTemplateGenerator: blockTemplateGenerator,
+			PayAddrs:               s.payAddrs,
+			ProcessBlock:           s.syncManager.ProcessBlock,
+			MinerListeners:         *Cfg.MinerListener,
+			MinerKey:               StateCfg.ActiveMinerKey,
+			APIKeys:                s.minerKeys,
+			Encrypt:                *Cfg.MinerEncrypt,
+			Multicast:              *Cfg.MinerMulticast,
+			Advertise:              *Cfg.MinerAdvertise,
+			ConnectedCount:         s.ConnectedCount,
+			IsCurrent:              s.syncManager.IsCurrent,
+		})
+	}
 	/*	Only setup a function to return new addresses to connect to when
 		not running in connect-only mode.  The simulation network is always
 		in connect-only mode since it is only intended to connect to
@@ -2632,22 +2839,26 @@ func newServer(
 				return nil, errors.New("RPCS: No valid listen address")
 			}
 			rp, err := newRPCServer(&rpcserverConfig{
-				Listeners:    rpcListeners,
-				StartupTime:  s.startupTime,
-				ConnMgr:      &rpcConnManager{&s},
-				SyncMgr:      &rpcSyncMgr{&s, s.syncManager},
-				TimeSource:   s.timeSource,
-				Chain:        s.chain,
-				ChainParams:  chainParams,
-				DB:           db,
-				TxMemPool:    s.txMemPool,
-				Generator:    blockTemplateGenerator,
-				CPUMiner:     s.cpuMiner,
-				TxIndex:      s.txIndex,
-				AddrIndex:    s.addrIndex,
-				CfIndex:      s.cfIndex,
-				FeeEstimator: s.feeEstimator,
-				Algo:         l,
+				Listeners:       rpcListeners,
+				StartupTime:     s.startupTime,
+				ConnMgr:         &rpcConnManager{&s},
+				SyncMgr:         &rpcSyncMgr{&s, s.syncManager},
+				TimeSource:      s.timeSource,
+				Chain:           s.chain,
+				ChainParams:     chainParams,
+				DB:              db,
+				TxMemPool:       s.txMemPool,
+				Generator:       blockTemplateGenerator,
+				CPUMiner:        s.cpuMiner,
+				PayAddrs:        s.payAddrs,
+				MinerKeys:       s.minerKeys,
+				MinerController: s.minerController,
+				TxIndex:         s.txIndex,
+				AddrIndex:       s.addrIndex,
+				CfIndex:         s.cfIndex,
+				FeeEstimator:    s.feeEstimator,
+				Updates:         s.updates,
+				Algo:            l,
 			})
 			if err != nil {
 				return nil, err
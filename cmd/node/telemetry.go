@@ -0,0 +1,105 @@
+package node
+import (
+	"bytes"
+	js "encoding/json"
+	"fmt"
+	"net/http"
+	"runtime"
+	"sync"
+	"time"
+	cl "git.parallelcoin.io/dev/9/pkg/util/cl"
+)
+const (
+	// DefaultTelemetryInterval is the default time between telemetry reports.
+	DefaultTelemetryInterval = 24 * time.Hour
+	// minTelemetryInterval is the shortest time allowed between telemetry reports, so a misconfigured node does not flood the endpoint.
+	minTelemetryInterval = time.Minute
+	// telemetryTimeout is the time allowed for sending a single report.
+	telemetryTimeout = 30 * time.Second
+)
+// TelemetryReport is the JSON body POSTed to the telemetry endpoint. It holds nothing that identifies the node or its operator: no addresses, keys or identifiers, though the endpoint sees the address the report is sent from like any other request.
+type TelemetryReport struct {
+	Version string `json:"version"`
+	Network string `json:"network"`
+	Height  int32  `json:"height"`
+	Peers   int32  `json:"peers"`
+	OS      string `json:"os"`
+	Arch    string `json:"arch"`
+}
+// NewTelemetryReport returns the report of a node of this build on the named network with the passed block height and number of connected peers.
+func NewTelemetryReport(network string, height, peers int32) *TelemetryReport {
+	return &TelemetryReport{
+		Version: Version(),
+		Network: network,
+		Height:  height,
+		Peers:   peers,
+		OS:      runtime.GOOS,
+		Arch:    runtime.GOARCH,
+	}
+}
+// telemetryReporter POSTs a TelemetryReport to an endpoint from a background goroutine every interval. It is only created when telemetry is enabled in the configuration, and failed reports are not retried, the next one is sent at the following interval.
+type telemetryReporter struct {
+	endpoint string
+	interval time.Duration
+	report   func() *TelemetryReport
+	client   *http.Client
+	quit     chan struct{}
+	wg       sync.WaitGroup
+}
+// newTelemetryReporter returns a telemetryReporter sending the reports returned by report to endpoint. Intervals shorter than minTelemetryInterval are raised to it.
+func newTelemetryReporter(endpoint string, interval time.Duration, report func() *TelemetryReport) *telemetryReporter {
+	if interval < minTelemetryInterval {
+		interval = minTelemetryInterval
+	}
+	return &telemetryReporter{
+		endpoint: endpoint,
+		interval: interval,
+		report:   report,
+		client:   &http.Client{Timeout: telemetryTimeout},
+		quit:     make(chan struct{}),
+	}
+}
+// Start starts the reporting goroutine.
+func (t *telemetryReporter) Start() {
+	log <- cl.Infof{"telemetry is enabled, reporting to %s every %v", t.endpoint, t.interval}
+	t.wg.Add(1)
+	go t.reportHandler()
+}
+// Stop stops the reporting goroutine and waits for it to exit.
+func (t *telemetryReporter) Stop() {
+	close(t.quit)
+	t.wg.Wait()
+}
+// reportHandler sends a report every interval, the first one interval after it starts so a node that is restarted repeatedly does not report each time, until the reporter is stopped. It must be run as a goroutine.
+func (t *telemetryReporter) reportHandler() {
+	defer t.wg.Done()
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := t.send(t.report()); err != nil {
+				log <- cl.Debugf{"telemetry report to %s failed: %v", t.endpoint, err}
+			}
+		case <-t.quit:
+			return
+		}
+	}
+}
+// send makes a single attempt to POST report to the endpoint. Any response status other than 2xx is an error.
+func (t *telemetryReporter) send(report *TelemetryReport) error {
+	body, err := js.Marshal(report)
+	if err != nil {
+		return err
+	}
+	log <- cl.Tracef{"sending telemetry report %s", body}
+	resp, err := t.client.Post(t.endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected response status %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package node
+import (
+	js "encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+)
+// TestTelemetrySend ensures a report is POSTed to the endpoint as it was made, and that a failing endpoint is reported as an error
+func TestTelemetrySend(
+	t *testing.T) {
+	var got TelemetryReport
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := js.NewDecoder(r.Body).Decode(&got); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+	want := NewTelemetryReport("testnet", 1234, 8)
+	if want.OS != runtime.GOOS || want.Arch != runtime.GOARCH || want.Version != Version() {
+		t.Fatalf("the report %+v is not of this build", want)
+	}
+	reporter := newTelemetryReporter(server.URL, time.Second, nil)
+	if reporter.interval != minTelemetryInterval {
+		t.Errorf("the interval %v was not raised to %v", reporter.interval, minTelemetryInterval)
+	}
+	if err := reporter.send(want); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(&got, want) {
+		t.Errorf("the endpoint got %+v, not %+v", got, want)
+	}
+	failing := httptest.NewServer(http.NotFoundHandler())
+	defer failing.Close()
+	if err := newTelemetryReporter(failing.URL, time.Hour, nil).send(want); err == nil {
+		t.Error("a report the endpoint did not accept was not an error")
+	}
+}
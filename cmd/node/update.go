@@ -0,0 +1,415 @@
+package node
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	js "encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	chainhash "git.parallelcoin.io/dev/9/pkg/chain/hash"
+	"git.parallelcoin.io/dev/9/pkg/rpc/json"
+	cl "git.parallelcoin.io/dev/9/pkg/util/cl"
+	ec "git.parallelcoin.io/dev/9/pkg/util/elliptic"
+)
+const (
+	// DefaultUpdateInterval is the default time between checks for a new release.
+	DefaultUpdateInterval = 24 * time.Hour
+	// minUpdateInterval is the shortest time allowed between checks for a new release, so a misconfigured node does not flood the release server.
+	minUpdateInterval = time.Minute
+	// updateTimeout is the time allowed for fetching a release manifest.
+	updateTimeout = 30 * time.Second
+	// updateDownloadTimeout is the time allowed for downloading a release binary.
+	updateDownloadTimeout = 30 * time.Minute
+	// maxManifestSize is the largest release manifest that is read, anything longer is not a manifest.
+	maxManifestSize = 1 << 20
+)
+// updateKeys are the compressed secp256k1 public keys of the maintainers who sign release manifests, hex encoded and separated by commas. They are embedded by the release build with '-ldflags "-X git.parallelcoin.io/dev/9/cmd/node.updateKeys=..."', and a build without them cannot check for updates.
+var updateKeys string
+// updateSignatures is the number of different maintainer keys that must have signed a release manifest for it to be trusted.
+var updateSignatures = 1
+// ReleaseManifest describes a release: its version, what changed, and where to download the binary for each platform.
+type ReleaseManifest struct {
+	Version string `json:"version"`
+	Notes   string `json:"notes,omitempty"`
+	// Binaries are keyed by the GOOS/GOARCH of the platform they run on, for example linux/amd64.
+	Binaries map[string]ReleaseBinary `json:"binaries"`
+}
+// ReleaseBinary is where the binary of a release for one platform is downloaded from and the SHA256 hash it must have, hex encoded.
+type ReleaseBinary struct {
+	URL    string `json:"url"`
+	SHA256 string `json:"sha256"`
+}
+// SignedManifest is the document served at the update URL. Manifest is the ReleaseManifest exactly as it was signed, and each signature is a DER encoded signature of its double SHA256 hash by one of the maintainer keys, hex encoded.
+type SignedManifest struct {
+	Manifest   js.RawMessage `json:"manifest"`
+	Signatures []string      `json:"signatures"`
+}
+// ParseUpdateKeys parses a comma separated list of hex encoded public keys as updateKeys holds them.
+func ParseUpdateKeys(
+	list string) (keys []*ec.PublicKey, err error) {
+	for _, s := range strings.Split(list, ",") {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		b, err := hex.DecodeString(s)
+		if err != nil {
+			return nil, fmt.Errorf("update key %s is not hex: %v", s, err)
+		}
+		key, err := ec.ParsePubKey(b, ec.S256())
+		if err != nil {
+			return nil, fmt.Errorf("update key %s is not a public key: %v", s, err)
+		}
+		keys = append(keys, key)
+	}
+	return
+}
+// VerifyManifest returns the release manifest if at least required of the keys each signed it, and an error otherwise. A key that signed more than once is only counted once.
+func VerifyManifest(
+	signed *SignedManifest, keys []*ec.PublicKey, required int) (*ReleaseManifest, error) {
+	if required < 1 {
+		required = 1
+	}
+	if signers := countSigners(chainhash.DoubleHashB(signed.Manifest), signed.Signatures, keys); signers < required {
+		return nil, fmt.Errorf("release manifest is signed by %d of the %d maintainer keys needed", signers, required)
+	}
+	manifest := new(ReleaseManifest)
+	if err := js.Unmarshal(signed.Manifest, manifest); err != nil {
+		return nil, fmt.Errorf("release manifest is malformed: %v", err)
+	}
+	if _, _, _, _, err := parseVersion(manifest.Version); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+// countSigners returns the number of different keys that made one of the hex encoded DER signatures of hash, ignoring signatures that can not be parsed.
+func countSigners(
+	hash []byte, signatures []string, keys []*ec.PublicKey) int {
+	signers := make(map[int]struct{})
+	for _, s := range signatures {
+		b, err := hex.DecodeString(s)
+		if err != nil {
+			continue
+		}
+		sig, err := ec.ParseDERSignature(b, ec.S256())
+		if err != nil {
+			continue
+		}
+		for i, key := range keys {
+			if _, ok := signers[i]; !ok && sig.Verify(hash, key) {
+				signers[i] = struct{}{}
+				break
+			}
+		}
+	}
+	return len(signers)
+}
+// parseVersion splits a semantic version into its major, minor and patch numbers and its pre-release, dropping any build metadata.
+func parseVersion(
+	version string) (major, minor, patch int, pre string, err error) {
+	v := strings.TrimPrefix(version, "v")
+	if i := strings.IndexByte(v, '+'); i >= 0 {
+		v = v[:i]
+	}
+	if i := strings.IndexByte(v, '-'); i >= 0 {
+		v, pre = v[:i], v[i+1:]
+	}
+	parts := strings.Split(v, ".")
+	if len(parts) != 3 {
+		return 0, 0, 0, "", fmt.Errorf("version %q is not major.minor.patch", version)
+	}
+	n := make([]int, 3)
+	for i, p := range parts {
+		if n[i], err = strconv.Atoi(p); err != nil || n[i] < 0 {
+			return 0, 0, 0, "", fmt.Errorf("version %q is not major.minor.patch", version)
+		}
+	}
+	return n[0], n[1], n[2], pre, nil
+}
+// CompareVersions returns -1, 0 or 1 as the semantic version a is lower than, the same as or higher than b. Versions that do not parse are lower than those that do.
+func CompareVersions(
+	a, b string) int {
+	aMajor, aMinor, aPatch, aPre, aErr := parseVersion(a)
+	bMajor, bMinor, bPatch, bPre, bErr := parseVersion(b)
+	switch {
+	case aErr != nil && bErr != nil:
+		return 0
+	case aErr != nil:
+		return -1
+	case bErr != nil:
+		return 1
+	}
+	for _, c := range [][2]int{{aMajor, bMajor}, {aMinor, bMinor}, {aPatch, bPatch}} {
+		if c[0] != c[1] {
+			return compareInts(c[0], c[1])
+		}
+	}
+	// A pre-release is lower than the release, and pre-releases compare by their dot separated identifiers, numerically when both are numbers.
+	switch {
+	case aPre == bPre:
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	}
+	aIDs, bIDs := strings.Split(aPre, "."), strings.Split(bPre, ".")
+	for i := 0; i < len(aIDs) && i < len(bIDs); i++ {
+		if aIDs[i] == bIDs[i] {
+			continue
+		}
+		aN, aErr := strconv.Atoi(aIDs[i])
+		bN, bErr := strconv.Atoi(bIDs[i])
+		switch {
+		case aErr == nil && bErr == nil:
+			return compareInts(aN, bN)
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		case aIDs[i] < bIDs[i]:
+			return -1
+		default:
+			return 1
+		}
+	}
+	return compareInts(len(aIDs), len(bIDs))
+}
+// compareInts returns -1, 0 or 1 as a is lower than, equal to or higher than b.
+func compareInts(
+	a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+// updateChecker fetches the signed release manifest from a URL when it starts and every interval after, and remembers the newest release that is signed by the maintainers and newer than the running version. It never replaces the running binary: Stage only downloads the new one next to the data for the user to install.
+type updateChecker struct {
+	url      string
+	interval time.Duration
+	keys     []*ec.PublicKey
+	dir      string
+	client   *http.Client
+	mtx      sync.Mutex
+	// checked is when the manifest was last fetched, and err why that failed
+	checked time.Time
+	err     error
+	// available is the release found newer than this one, and staged the path its binary was downloaded to
+	available *ReleaseManifest
+	staged    string
+	quit      chan struct{}
+	wg        sync.WaitGroup
+}
+// newUpdateChecker returns an updateChecker fetching the manifest from url and staging binaries in dir. Intervals shorter than minUpdateInterval are raised to it.
+func newUpdateChecker(
+	url string, interval time.Duration, keys []*ec.PublicKey, dir string) *updateChecker {
+	if interval < minUpdateInterval {
+		interval = minUpdateInterval
+	}
+	return &updateChecker{
+		url:      url,
+		interval: interval,
+		keys:     keys,
+		dir:      dir,
+		client:   &http.Client{Timeout: updateTimeout},
+		quit:     make(chan struct{}),
+	}
+}
+// Start starts the checking goroutine.
+func (u *updateChecker) Start() {
+	log <- cl.Infof{"checking %s for new releases every %v", u.url, u.interval}
+	u.wg.Add(1)
+	go u.checkHandler()
+}
+// Stop stops the checking goroutine and waits for it to exit.
+func (u *updateChecker) Stop() {
+	close(u.quit)
+	u.wg.Wait()
+}
+// checkHandler checks for a new release right away and then every interval until the checker is stopped. It must be run as a goroutine.
+func (u *updateChecker) checkHandler() {
+	defer u.wg.Done()
+	ticker := time.NewTicker(u.interval)
+	defer ticker.Stop()
+	for {
+		if err := u.check(); err != nil {
+			log <- cl.Debugf{"checking %s for new releases failed: %v", u.url, err}
+		}
+		select {
+		case <-ticker.C:
+		case <-u.quit:
+			return
+		}
+	}
+}
+// check fetches and verifies the release manifest, and logs a warning the first time it names a release newer than the running one.
+func (u *updateChecker) check() error {
+	manifest, err := u.fetch()
+	u.mtx.Lock()
+	defer u.mtx.Unlock()
+	u.checked, u.err = time.Now(), err
+	if err != nil {
+		return err
+	}
+	if CompareVersions(manifest.Version, Version()) <= 0 {
+		return nil
+	}
+	if u.available == nil || CompareVersions(manifest.Version, u.available.Version) > 0 {
+		log <- cl.Warnf{"version %s is available, this is %s, run the stageupdate command to download it", manifest.Version, Version()}
+		u.available, u.staged = manifest, ""
+	}
+	return nil
+}
+// fetch gets the signed manifest from the URL and verifies it.
+func (u *updateChecker) fetch() (*ReleaseManifest, error) {
+	resp, err := u.client.Get(u.url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("unexpected response status %s", resp.Status)
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxManifestSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > maxManifestSize {
+		return nil, errors.New("release manifest is too large")
+	}
+	var signed SignedManifest
+	if err = js.Unmarshal(body, &signed); err != nil {
+		return nil, fmt.Errorf("release manifest is malformed: %v", err)
+	}
+	return VerifyManifest(&signed, u.keys, updateSignatures)
+}
+// Info returns what the checker knows about updates for the getupdateinfo command.
+func (u *updateChecker) Info() *json.GetUpdateInfoResult {
+	u.mtx.Lock()
+	defer u.mtx.Unlock()
+	info := &json.GetUpdateInfoResult{Enabled: true, Version: Version(), URL: u.url, Staged: u.staged}
+	if !u.checked.IsZero() {
+		info.Checked = u.checked.Unix()
+	}
+	if u.err != nil {
+		info.Error = u.err.Error()
+	}
+	if u.available != nil {
+		info.Available = u.available.Version
+		info.Notes = u.available.Notes
+	}
+	return info
+}
+// Stage downloads the binary of the available release for this platform into a directory named after its version, checks it has the hash the manifest gives, and returns its path. The running binary is left alone, installing the new one is up to the user.
+func (u *updateChecker) Stage() (string, error) {
+	u.mtx.Lock()
+	manifest, staged := u.available, u.staged
+	u.mtx.Unlock()
+	if manifest == nil {
+		return "", errors.New("no newer release is available")
+	}
+	if staged != "" {
+		return staged, nil
+	}
+	platform := runtime.GOOS + "/" + runtime.GOARCH
+	binary, ok := manifest.Binaries[platform]
+	if !ok {
+		return "", fmt.Errorf("release %s has no binary for %s", manifest.Version, platform)
+	}
+	want, err := hex.DecodeString(binary.SHA256)
+	if err != nil || len(want) != sha256.Size {
+		return "", fmt.Errorf("release %s has a malformed hash for %s", manifest.Version, platform)
+	}
+	src, err := url.Parse(binary.URL)
+	if err != nil {
+		return "", err
+	}
+	name := path.Base(src.Path)
+	if name == "." || name == "/" {
+		return "", fmt.Errorf("release %s has no file name in its URL for %s", manifest.Version, platform)
+	}
+	dir := filepath.Join(u.dir, manifest.Version)
+	if err = os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	client := *u.client
+	client.Timeout = updateDownloadTimeout
+	resp, err := client.Get(src.String())
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", fmt.Errorf("unexpected response status %s", resp.Status)
+	}
+	tmp, err := ioutil.TempFile(dir, name+".download")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	h := sha256.New()
+	_, err = io.Copy(io.MultiWriter(tmp, h), resp.Body)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return "", err
+	}
+	if got := h.Sum(nil); !bytes.Equal(got, want) {
+		return "", fmt.Errorf("downloaded binary has hash %x, the release manifest gives %x", got, want)
+	}
+	if err = os.Chmod(tmp.Name(), 0755); err != nil {
+		return "", err
+	}
+	dst := filepath.Join(dir, name)
+	if err = os.Rename(tmp.Name(), dst); err != nil {
+		return "", err
+	}
+	log <- cl.Infof{"release %s is staged at %s, stop the node and replace the binary with it to install it", manifest.Version, dst}
+	u.mtx.Lock()
+	if u.available == manifest {
+		u.staged = dst
+	}
+	u.mtx.Unlock()
+	return dst, nil
+}
+// handleGetUpdateInfo implements the getupdateinfo command.
+func handleGetUpdateInfo(
+	s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	if s.Cfg.Updates == nil {
+		return &json.GetUpdateInfoResult{Version: Version()}, nil
+	}
+	return s.Cfg.Updates.Info(), nil
+}
+// handleStageUpdate implements the stageupdate command.
+func handleStageUpdate(
+	s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	if s.Cfg.Updates == nil {
+		return nil, &json.RPCError{
+			Code:    json.ErrRPCMisc,
+			Message: "Update checking must be enabled (update.enable)",
+		}
+	}
+	staged, err := s.Cfg.Updates.Stage()
+	if err != nil {
+		return nil, &json.RPCError{
+			Code:    json.ErrRPCMisc,
+			Message: err.Error(),
+		}
+	}
+	return staged, nil
+}
@@ -0,0 +1,146 @@
+package node
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	js "encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+	chainhash "git.parallelcoin.io/dev/9/pkg/chain/hash"
+	ec "git.parallelcoin.io/dev/9/pkg/util/elliptic"
+)
+// TestCompareVersions ensures versions are ordered as semantic versioning orders them, with build metadata ignored
+func TestCompareVersions(
+	t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"0.1.4", "0.1.4", 0},
+		{"0.1.5", "0.1.4", 1},
+		{"0.2.0", "0.1.10", 1},
+		{"1.0.0", "0.9.9", 1},
+		{"0.1.4-beta", "0.1.4", -1},
+		{"0.1.4-beta", "0.1.4-alpha", 1},
+		{"0.1.4-beta.2", "0.1.4-beta.10", -1},
+		{"0.1.4-beta.1", "0.1.4-beta", 1},
+		{"0.1.4-1", "0.1.4-beta", -1},
+		{"v0.1.4+abc", "0.1.4+def", 0},
+		{"0.1", "0.0.1", -1},
+	}
+	for _, test := range tests {
+		if got := CompareVersions(test.a, test.b); got != test.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", test.a, test.b, got, test.want)
+		}
+	}
+}
+// signManifest returns the manifest signed by each of the keys
+func signManifest(t *testing.T, manifest *ReleaseManifest, keys ...*ec.PrivateKey) *SignedManifest {
+	raw, err := js.Marshal(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed := &SignedManifest{Manifest: raw}
+	for _, key := range keys {
+		sig, err := key.Sign(chainhash.DoubleHashB(raw))
+		if err != nil {
+			t.Fatal(err)
+		}
+		signed.Signatures = append(signed.Signatures, hex.EncodeToString(sig.Serialize()))
+	}
+	return signed
+}
+// TestUpdateChecker ensures only a newer release signed by enough maintainer keys is offered, and that staging it checks the hash of the binary and leaves it in the staging directory
+func TestUpdateChecker(
+	t *testing.T) {
+	maintainer, err := ec.NewPrivateKey(ec.S256())
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := ec.NewPrivateKey(ec.S256())
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, err := ParseUpdateKeys(hex.EncodeToString(maintainer.PubKey().SerializeCompressed()) + ", ")
+	if err != nil || len(keys) != 1 {
+		t.Fatalf("parsing the maintainer key gave %v, %v", keys, err)
+	}
+	binary := []byte("the new release")
+	hash := sha256.Sum256(binary)
+	manifest := &ReleaseManifest{Version: "99.0.0", Notes: "notes"}
+	var signed *SignedManifest
+	mux := http.NewServeMux()
+	mux.HandleFunc("/manifest", func(w http.ResponseWriter, r *http.Request) {
+		js.NewEncoder(w).Encode(signed)
+	})
+	mux.HandleFunc("/download/9", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(binary)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	manifest.Binaries = map[string]ReleaseBinary{
+		runtime.GOOS + "/" + runtime.GOARCH: {URL: server.URL + "/download/9", SHA256: hex.EncodeToString(hash[:])},
+	}
+	dir, err := ioutil.TempDir("", "update")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	u := newUpdateChecker(server.URL+"/manifest", time.Hour, keys, dir)
+	// a release signed by someone else is not trusted
+	signed = signManifest(t, manifest, other)
+	if err = u.check(); err == nil {
+		t.Fatal("a manifest not signed by a maintainer was accepted")
+	}
+	if info := u.Info(); info.Available != "" || info.Error == "" {
+		t.Fatalf("the info %+v does not report the failed check", info)
+	}
+	if _, err = u.Stage(); err == nil {
+		t.Fatal("staged an update when none is available")
+	}
+	// a release signed by a maintainer and changed after is not trusted either
+	signed = signManifest(t, manifest, maintainer)
+	signed.Manifest = js.RawMessage(strings.Replace(string(signed.Manifest), "99.0.0", "99.0.1", 1))
+	if err = u.check(); err == nil {
+		t.Fatal("a manifest changed after it was signed was accepted")
+	}
+	signed = signManifest(t, manifest, other, maintainer)
+	if err = u.check(); err != nil {
+		t.Fatal(err)
+	}
+	info := u.Info()
+	if info.Available != "99.0.0" || info.Notes != "notes" || info.Error != "" || info.Checked == 0 {
+		t.Fatalf("the info %+v does not report the release", info)
+	}
+	staged, err := u.Stage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(dir, "99.0.0", "9"); staged != want {
+		t.Errorf("the binary was staged at %s, not %s", staged, want)
+	}
+	if got, err := ioutil.ReadFile(staged); err != nil || string(got) != string(binary) {
+		t.Errorf("the staged binary is %q, %v", got, err)
+	}
+	if u.Info().Staged != staged {
+		t.Error("the info does not report the staged binary")
+	}
+	// a binary that does not match the manifest is not staged
+	binary = []byte("something else")
+	u.mtx.Lock()
+	u.staged = ""
+	u.mtx.Unlock()
+	os.RemoveAll(dir)
+	if _, err = u.Stage(); err == nil {
+		t.Error("a binary with the wrong hash was staged")
+	}
+	if files, _ := ioutil.ReadDir(filepath.Join(dir, "99.0.0")); len(files) != 0 {
+		t.Errorf("the binary with the wrong hash was left in the staging directory")
+	}
+}
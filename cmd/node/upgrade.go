@@ -1,9 +1,18 @@
 package node
 import (
+	"encoding/json"
+	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"git.parallelcoin.io/dev/9/cmd/nine"
+	blockchain "git.parallelcoin.io/dev/9/pkg/chain"
+	"git.parallelcoin.io/dev/9/pkg/chain/wire"
+	database "git.parallelcoin.io/dev/9/pkg/db"
 	cl "git.parallelcoin.io/dev/9/pkg/util/cl"
+	"git.parallelcoin.io/dev/9/pkg/util/interrupt"
+	"git.parallelcoin.io/dev/9/pkg/wallet"
 )
 // dirEmpty returns whether or not the specified directory path is empty.
 func dirEmpty(
@@ -20,14 +29,6 @@ func dirEmpty(
 	}
 	return len(names) == 0, nil
 }
-// doUpgrades performs upgrades to pod as new versions require it.
-func doUpgrades() error {
-	err := upgradeDBPaths()
-	if err != nil {
-		return err
-	}
-	return upgradeDataPaths()
-}
 // oldPodHomeDir returns the OS specific home directory pod used prior to version 0.3.3.  This has since been replaced with util.AppDataDir, but this function is still provided for the automatic upgrade path.
 func oldPodHomeDir() string {
 	// Search for Windows APPDATA first.  This won't exist on POSIX OSes.
@@ -43,98 +44,441 @@ func oldPodHomeDir() string {
 	// In the worst case, use the current directory.
 	return "."
 }
-// upgradeDBPathNet moves the database for a specific network from its location prior to pod version 0.2.0 and uses heuristics to ascertain the old database type to rename to the new format.
-func upgradeDBPathNet(
-	oldDbPath, netName string) error {
-	// Prior to version 0.2.0, the database was named the same thing for both sqlite and leveldb.  Use heuristics to figure out the type of the database and move it to the new path and name introduced with version 0.2.0 accordingly.
-	fi, err := os.Stat(oldDbPath)
-	if err == nil {
-		oldDbType := "sqlite"
-		if fi.IsDir() {
-			oldDbType = "leveldb"
-		}
-		// The new database name is based on the database type and resides in a directory named after the network type.
-		newDbRoot := filepath.Join(filepath.Dir(*Cfg.DataDir), netName)
-		newDbName := blockDbNamePrefix + "_" + oldDbType
-		if oldDbType == "sqlite" {
-			newDbName = newDbName + ".db"
+// migrationsFile is the file in the data directory of the node that records the migrations applied to it
+const migrationsFile = "migrations.json"
+// The operations of the steps of a migration
+const (
+	// opMove renames From to To, creating the directory To is in
+	opMove = "move"
+	// opRemove removes From and everything in it, which can not be undone
+	opRemove = "remove"
+	// opRemoveEmpty removes the directory From if it is empty and warns that it was left otherwise
+	opRemoveEmpty = "removeempty"
+	// opUpgradeUtxoSet upgrades the utxo set of the block database From of the network Net, which can not be undone
+	opUpgradeUtxoSet = "upgradeutxoset"
+	// opUpgradeBlockIndex upgrades the block index of the block database From of the network Net, and is undone by downgrading it
+	opUpgradeBlockIndex = "upgradeblockindex"
+	// opBackup copies the file From to To, and is undone by setting aside From and putting the copy back in its place
+	opBackup = "backup"
+)
+// setAsideSuffix is added to the name of a file replaced by its backup when a migration is rolled back, so what was in it is not lost
+const setAsideSuffix = ".rolledback"
+// step is one change to the files of the node made by a migration
+type step struct {
+	Op   string          `json:"op"`
+	From string          `json:"from"`
+	To   string          `json:"to,omitempty"`
+	Net  wire.BitcoinNet `json:"net,omitempty"`
+}
+// migration is a change to the data of the node that brings it to its version, applied once and in the order of the versions
+type migration struct {
+	version     uint32
+	description string
+	// plan returns the steps the migration takes with the files as they are, none when there is nothing to change
+	plan func() ([]step, error)
+}
+// appliedMigration is a migration with the steps it takes or took, as recorded in the migrations file for rolling it back
+type appliedMigration struct {
+	Version     uint32 `json:"version"`
+	Description string `json:"description"`
+	Steps       []step `json:"steps"`
+}
+// migrationRecord is the content of the migrations file, the version the data of the node is at and the migrations that brought it there
+type migrationRecord struct {
+	Version uint32             `json:"version"`
+	Applied []appliedMigration `json:"applied"`
+}
+// migrations are the migrations of the data of the node in the order of their versions, a new one being added at the end with the next version. The block databases are upgraded here so the upgrades can be planned and rolled back, though the chain still makes any left undone itself when it opens one. The wallet upgrades its formats itself when it is opened with its passphrase, so it is backed up here for rolling back to. The move of the data and configuration to the XDG base directories is made by the app before the configuration is read, so it can not be one of these
+var migrations = []migration{
+	{1, "move the databases from their locations prior to pod version 0.2.0", planDBPaths},
+	{2, "move the application data and configuration from its location prior to pod version 0.3.3", planDataPaths},
+	{3, "upgrade the utxo sets of the block databases to version 2", planUtxoSetUpgrades},
+	{4, "upgrade the block indexes of the block databases to version 2, which store the version bits signalled in the coinbase of each block", planBlockIndexUpgrades},
+	{5, "back up the wallets before they upgrade their own formats when they are next opened", planWalletBackups},
+}
+// networks are the parameters of the networks the node keeps separate data for
+var networks = []*nine.Params{&nine.MainNetParams, &nine.TestNet3Params, &nine.RegressionNetParams, &nine.SimNetParams}
+// doUpgrades logs the plan of the migrations that have not been applied to the data of the node and applies them.
+func doUpgrades() error {
+	path := filepath.Join(*Cfg.AppDataDir, migrationsFile)
+	plan, err := planMigrations(path, migrations)
+	if err != nil || len(plan) < 1 {
+		return err
+	}
+	for _, line := range describeMigrations(plan) {
+		log <- cl.Info{line}
+	}
+	return applyMigrations(path, plan)
+}
+// MigrationPlan writes the plan of the migrations that have not been applied to the data of the node to w without applying them
+func MigrationPlan(
+	w io.Writer) error {
+	plan, err := planMigrations(filepath.Join(*Cfg.AppDataDir, migrationsFile), migrations)
+	if err != nil {
+		return err
+	}
+	if len(plan) < 1 {
+		fmt.Fprintln(w, "the data is up to date, there are no migrations to apply")
+		return nil
+	}
+	for _, line := range describeMigrations(plan) {
+		fmt.Fprintln(w, line)
+	}
+	return nil
+}
+// RollbackMigrations undoes the migrations applied to the data of the node with versions above version, newest first, writing its plan to w before it starts. It undoes none of them if any can not be undone
+func RollbackMigrations(
+	w io.Writer, version uint32) error {
+	return rollbackMigrations(w, filepath.Join(*Cfg.AppDataDir, migrationsFile), version)
+}
+// readMigrations reads the migrations file at path, which records no migrations when it does not exist
+func readMigrations(
+	path string) (*migrationRecord, error) {
+	record := &migrationRecord{}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return record, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, record); err != nil {
+		return nil, fmt.Errorf("could not read %s: %v", path, err)
+	}
+	return record, nil
+}
+// writeMigrations replaces the migrations file at path with the record
+func writeMigrations(
+	path string, record *migrationRecord) error {
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err = ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+// planMigrations returns the migrations newer than the version recorded in the migrations file at path with the steps they take with the files as they are
+func planMigrations(
+	path string, ms []migration) (plan []appliedMigration, err error) {
+	record, err := readMigrations(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range ms {
+		if m.version <= record.Version {
+			continue
 		}
-		newDbPath := filepath.Join(newDbRoot, newDbName)
-		// Create the new path if needed.
-		err = os.MkdirAll(newDbRoot, 0700)
+		steps, err := m.plan()
 		if err != nil {
+			return nil, fmt.Errorf("could not plan migration %d: %v", m.version, err)
+		}
+		plan = append(plan, appliedMigration{Version: m.version, Description: m.description, Steps: steps})
+	}
+	return
+}
+// describeMigrations returns a line for each migration and each of its steps
+func describeMigrations(
+	plan []appliedMigration) (lines []string) {
+	for _, m := range plan {
+		lines = append(lines, fmt.Sprintf("migration %d: %s", m.Version, m.Description))
+		if len(m.Steps) < 1 {
+			lines = append(lines, "\tnothing to change")
+		}
+		for _, s := range m.Steps {
+			lines = append(lines, "\t"+s.String())
+		}
+	}
+	return
+}
+// applyMigrations takes the steps of the planned migrations in order, recording each in the migrations file at path as it is done
+func applyMigrations(
+	path string, plan []appliedMigration) error {
+	record, err := readMigrations(path)
+	if err != nil {
+		return err
+	}
+	for _, m := range plan {
+		for _, s := range m.Steps {
+			if err := s.apply(); err != nil {
+				return fmt.Errorf("migration %d failed to %v: %v", m.Version, s, err)
+			}
+		}
+		record.Version = m.Version
+		record.Applied = append(record.Applied, m)
+		if err := writeMigrations(path, record); err != nil {
 			return err
 		}
-		// Move and rename the old database.
-		err := os.Rename(oldDbPath, newDbPath)
-		if err != nil {
+	}
+	return nil
+}
+// rollbackMigrations undoes the migrations recorded in the migrations file at path with versions above version, newest first and each of their steps in reverse, writing the plan to w first
+func rollbackMigrations(
+	w io.Writer, path string, version uint32) error {
+	record, err := readMigrations(path)
+	if err != nil {
+		return err
+	}
+	if version >= record.Version {
+		fmt.Fprintf(w, "the data is at version %d, there are no migrations to roll back\n", record.Version)
+		return nil
+	}
+	var undo []appliedMigration
+	for i := len(record.Applied) - 1; i >= 0 && record.Applied[i].Version > version; i-- {
+		m := record.Applied[i]
+		for _, s := range m.Steps {
+			if !s.reversible() {
+				return fmt.Errorf("migration %d can not be rolled back, as it had to %v", m.Version, s)
+			}
+		}
+		undo = append(undo, m)
+	}
+	fmt.Fprintf(w, "rolling back to version %d, newest first:\n", version)
+	for _, line := range describeMigrations(undo) {
+		fmt.Fprintln(w, line)
+	}
+	for _, m := range undo {
+		for i := len(m.Steps) - 1; i >= 0; i-- {
+			if err := m.Steps[i].undo(); err != nil {
+				return fmt.Errorf("could not undo %v of migration %d: %v", m.Steps[i], m.Version, err)
+			}
+		}
+		record.Applied = record.Applied[:len(record.Applied)-1]
+		record.Version = version
+		if len(record.Applied) > 0 && record.Applied[len(record.Applied)-1].Version > version {
+			record.Version = record.Applied[len(record.Applied)-1].Version
+		}
+		if err := writeMigrations(path, record); err != nil {
 			return err
 		}
 	}
 	return nil
 }
-// upgradeDBPaths moves the databases from their locations prior to pod version 0.2.0 to their new locations.
-func upgradeDBPaths() error {
+// String describes the step
+func (s step) String() string {
+	if s.To != "" {
+		return fmt.Sprintf("%s %s to %s", s.Op, s.From, s.To)
+	}
+	return fmt.Sprintf("%s %s", s.Op, s.From)
+}
+// apply makes the change of the step
+func (s step) apply() error {
+	switch s.Op {
+	case opUpgradeUtxoSet:
+		return s.withBlockDB(func(db database.DB) error {
+			return blockchain.UpgradeUtxoSet(db, interrupt.ShutdownRequestChan)
+		})
+	case opUpgradeBlockIndex:
+		return s.withBlockDB(func(db database.DB) error {
+			return blockchain.UpgradeBlockIndex(db, interrupt.ShutdownRequestChan)
+		})
+	case opBackup:
+		return copyFile(s.From, s.To)
+	case opMove:
+		if err := os.MkdirAll(filepath.Dir(s.To), 0700); err != nil {
+			return err
+		}
+		return os.Rename(s.From, s.To)
+	case opRemove:
+		return os.RemoveAll(s.From)
+	case opRemoveEmpty:
+		empty, err := dirEmpty(s.From)
+		if err != nil {
+			return err
+		}
+		if !empty {
+			log <- cl.Warnf{
+				"not removing '%s' since it contains files not created by this application," +
+					"you may want to manually move them or delete them.", s.From}
+			return nil
+		}
+		return os.Remove(s.From)
+	}
+	return fmt.Errorf("unknown step %q", s.Op)
+}
+// reversible returns whether the change of the step can be undone
+func (s step) reversible() bool {
+	switch s.Op {
+	case opMove, opRemoveEmpty, opUpgradeBlockIndex, opBackup:
+		return true
+	}
+	return false
+}
+// undo reverses the change of the step
+func (s step) undo() error {
+	switch s.Op {
+	case opMove:
+		if err := os.MkdirAll(filepath.Dir(s.From), 0700); err != nil {
+			return err
+		}
+		return os.Rename(s.To, s.From)
+	case opRemoveEmpty:
+		return os.MkdirAll(s.From, 0700)
+	case opUpgradeBlockIndex:
+		return s.withBlockDB(func(db database.DB) error {
+			return blockchain.DowngradeBlockIndex(db, interrupt.ShutdownRequestChan)
+		})
+	case opBackup:
+		if FileExists(s.From) {
+			aside := s.From + setAsideSuffix
+			log <- cl.Warnf{"setting aside '%s' as '%s' to restore the backup made before the migration", s.From, aside}
+			if err := os.Rename(s.From, aside); err != nil {
+				return err
+			}
+		}
+		return os.Rename(s.To, s.From)
+	}
+	return fmt.Errorf("%v can not be undone", s)
+}
+// withBlockDB opens the block database of the step and calls fn with it, doing nothing if it no longer exists, as the regression test database is removed each time the node starts
+func (s step) withBlockDB(
+	fn func(db database.DB) error) error {
+	if !FileExists(s.From) {
+		return nil
+	}
+	db, err := database.Open(*Cfg.DbType, s.From, s.Net)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return fn(db)
+}
+// copyFile copies the file from to to with the same permissions, replacing to only once the copy is complete, so a migration that failed part way through can be applied again
+func copyFile(
+	from, to string) error {
+	src, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	fi, err := src.Stat()
+	if err != nil {
+		return err
+	}
+	tmp := to + ".tmp"
+	dst, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, fi.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(dst, src); err != nil {
+		dst.Close()
+		return err
+	}
+	if err = dst.Sync(); err != nil {
+		dst.Close()
+		return err
+	}
+	if err = dst.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, to)
+}
+// planDBPaths returns the steps to move the databases from their locations prior to pod version 0.2.0 to their new locations.
+func planDBPaths() (steps []step, err error) {
 	// Prior to version 0.2.0, the databases were in the "db" directory and their names were suffixed by "testnet" and "regtest" for their respective networks.  Check for the old database and update it to the new path introduced with version 0.2.0 accordingly.
 	oldDbRoot := filepath.Join(oldPodHomeDir(), "db")
-	upgradeDBPathNet(filepath.Join(oldDbRoot, "pod.db"), "mainnet")
-	upgradeDBPathNet(filepath.Join(oldDbRoot, "pod_testnet.db"), "testnet")
-	upgradeDBPathNet(filepath.Join(oldDbRoot, "pod_regtest.db"), "regtest")
+	for _, old := range []struct{ name, netName string }{
+		{"pod.db", "mainnet"},
+		{"pod_testnet.db", "testnet"},
+		{"pod_regtest.db", "regtest"},
+	} {
+		oldDbPath := filepath.Join(oldDbRoot, old.name)
+		// Prior to version 0.2.0, the database was named the same thing for both sqlite and leveldb.  Use heuristics to figure out the type of the database and move it to the new path and name introduced with version 0.2.0 accordingly.
+		fi, err := os.Stat(oldDbPath)
+		if err != nil {
+			continue
+		}
+		oldDbType := "sqlite"
+		if fi.IsDir() {
+			oldDbType = "leveldb"
+		}
+		// The new database name is based on the database type and resides in a directory named after the network type.
+		newDbName := blockDbNamePrefix + "_" + oldDbType
+		if oldDbType == "sqlite" {
+			newDbName = newDbName + ".db"
+		}
+		newDbPath := filepath.Join(filepath.Dir(*Cfg.DataDir), old.netName, newDbName)
+		steps = append(steps, step{Op: opMove, From: oldDbPath, To: newDbPath})
+	}
 	// Remove the old db directory.
-	return os.RemoveAll(oldDbRoot)
+	if FileExists(oldDbRoot) {
+		steps = append(steps, step{Op: opRemove, From: oldDbRoot})
+	}
+	return
 }
-// upgradeDataPaths moves the application data from its location prior to pod version 0.3.3 to its new location.
-func upgradeDataPaths() error {
+// planDataPaths returns the steps to move the application data from its location prior to pod version 0.3.3 to its new location.
+func planDataPaths() (steps []step, err error) {
 	// No need to migrate if the old and new home paths are the same.
 	oldHomePath := oldPodHomeDir()
 	newHomePath := DefaultHomeDir
 	if oldHomePath == newHomePath {
-		return nil
+		return nil, nil
 	}
 	// Only migrate if the old path exists and the new one doesn't.
-	if FileExists(oldHomePath) && !FileExists(newHomePath) {
-		// Create the new path.
-		log <- cl.Infof{
-			"migrating application home path from '%s' to '%s'",
-			oldHomePath, newHomePath,
-		}
-		err := os.MkdirAll(newHomePath, 0700)
-		if err != nil {
-			return err
+	if !FileExists(oldHomePath) || FileExists(newHomePath) {
+		return nil, nil
+	}
+	// Move old pod.conf and the old data directory into the new location, then remove the old home if it is empty.
+	for _, name := range []string{DefaultConfigFilename, DefaultDataDirname} {
+		if oldPath := filepath.Join(oldHomePath, name); FileExists(oldPath) {
+			steps = append(steps, step{Op: opMove, From: oldPath, To: filepath.Join(newHomePath, name)})
 		}
-		// Move old pod.conf into new location if needed.
-		oldConfPath := filepath.Join(oldHomePath, DefaultConfigFilename)
-		newConfPath := filepath.Join(newHomePath, DefaultConfigFilename)
-		if FileExists(oldConfPath) && !FileExists(newConfPath) {
-			err := os.Rename(oldConfPath, newConfPath)
-			if err != nil {
-				return err
-			}
+	}
+	steps = append(steps, step{Op: opRemoveEmpty, From: oldHomePath})
+	return
+}
+// planBlockDBs returns a step taking op on the block database of each network that exists and for which need returns true given the versions of its block index and utxo set
+func planBlockDBs(
+	op string, need func(blockIndex, utxoSet uint32) bool) (steps []step, err error) {
+	// The memory database is created afresh each time the node starts.
+	if *Cfg.DbType == "memdb" {
+		return nil, nil
+	}
+	dbName := filepath.Base(blockDbPath(*Cfg.DbType))
+	for _, params := range networks {
+		path := filepath.Join(*Cfg.AppDataDir, NetName(params), dbName)
+		if !FileExists(path) {
+			continue
 		}
-		// Move old data directory into new location if needed.
-		oldDataPath := filepath.Join(oldHomePath, DefaultDataDirname)
-		newDataPath := filepath.Join(newHomePath, DefaultDataDirname)
-		if FileExists(oldDataPath) && !FileExists(newDataPath) {
-			err := os.Rename(oldDataPath, newDataPath)
-			if err != nil {
-				return err
-			}
+		db, err := database.Open(*Cfg.DbType, path, params.Net)
+		if err != nil {
+			return nil, err
 		}
-		// Remove the old home if it is empty or show a warning if not.
-		ohpEmpty, err := dirEmpty(oldHomePath)
+		blockIndex, utxoSet, err := blockchain.DatabaseVersions(db)
+		db.Close()
 		if err != nil {
-			return err
+			return nil, err
 		}
-		if ohpEmpty {
-			err := os.Remove(oldHomePath)
-			if err != nil {
-				return err
-			}
-		} else {
-			log <- cl.Warnf{
-				"not removing '%s' since it contains files not created by this application," +
-					"you may want to manually move them or delete them.", oldHomePath}
+		if need(blockIndex, utxoSet) {
+			steps = append(steps, step{Op: op, From: path, Net: params.Net})
 		}
 	}
-	return nil
+	return
+}
+// planUtxoSetUpgrades returns the steps to upgrade the utxo sets of the block databases that are older than version 2.
+func planUtxoSetUpgrades() ([]step, error) {
+	return planBlockDBs(opUpgradeUtxoSet, func(blockIndex, utxoSet uint32) bool {
+		return utxoSet == 1
+	})
+}
+// planBlockIndexUpgrades returns the steps to upgrade the block indexes of the block databases that are older than version 2.
+func planBlockIndexUpgrades() ([]step, error) {
+	return planBlockDBs(opUpgradeBlockIndex, func(blockIndex, utxoSet uint32) bool {
+		return blockIndex == 1
+	})
+}
+// planWalletBackups returns the steps to copy the wallet database of each network next to it, to be put back when the migration is rolled back.
+func planWalletBackups() (steps []step, err error) {
+	for _, params := range networks {
+		path := filepath.Join(*Cfg.DataDir, "wallet", NetName(params), wallet.WalletDbName)
+		if FileExists(path) {
+			steps = append(steps, step{Op: opBackup, From: path, To: path + ".migration5"})
+		}
+	}
+	return
 }
@@ -0,0 +1,106 @@
+package node
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+// TestMigrations ensures migrations are planned and applied once in order of their versions, recorded in the migrations file, and rolled back only when all their steps can be undone
+func TestMigrations(
+	t *testing.T) {
+	dir, err := ioutil.TempDir("", "migrations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, migrationsFile)
+	old, moved, gone := filepath.Join(dir, "old"), filepath.Join(dir, "new", "moved"), filepath.Join(dir, "gone")
+	for _, d := range []string{old, gone} {
+		if err := os.Mkdir(d, 0700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ms := []migration{
+		{1, "move", func() ([]step, error) { return []step{{Op: opMove, From: old, To: moved}}, nil }},
+		{2, "remove", func() ([]step, error) { return []step{{Op: opRemove, From: gone}}, nil }},
+	}
+	plan, err := planMigrations(path, ms[:1])
+	if err != nil || len(plan) != 1 {
+		t.Fatalf("planned %v, %v", plan, err)
+	}
+	if err = applyMigrations(path, plan); err != nil {
+		t.Fatal(err)
+	}
+	if FileExists(old) || !FileExists(moved) {
+		t.Fatal("migration 1 did not move the directory")
+	}
+	if plan, err = planMigrations(path, ms); err != nil || len(plan) != 1 || plan[0].Version != 2 {
+		t.Fatalf("planned %v, %v after applying migration 1", plan, err)
+	}
+	if err = applyMigrations(path, plan); err != nil {
+		t.Fatal(err)
+	}
+	if FileExists(gone) {
+		t.Fatal("migration 2 did not remove the directory")
+	}
+	if err = rollbackMigrations(ioutil.Discard, path, 0); err == nil {
+		t.Fatal("migration 2 was rolled back though it removed a directory")
+	}
+	if !FileExists(moved) {
+		t.Fatal("migration 1 was undone though the rollback failed")
+	}
+	// leave out migration 2 from the record as if it had never been applied
+	record, err := readMigrations(path)
+	if err != nil || record.Version != 2 || len(record.Applied) != 2 {
+		t.Fatalf("recorded %+v, %v", record, err)
+	}
+	record.Version, record.Applied = 1, record.Applied[:1]
+	if err = writeMigrations(path, record); err != nil {
+		t.Fatal(err)
+	}
+	if err = rollbackMigrations(ioutil.Discard, path, 0); err != nil {
+		t.Fatal(err)
+	}
+	if !FileExists(old) || FileExists(moved) {
+		t.Fatal("migration 1 was not undone")
+	}
+	if record, err = readMigrations(path); err != nil || record.Version != 0 || len(record.Applied) != 0 {
+		t.Fatalf("recorded %+v, %v after rolling back", record, err)
+	}
+}
+// TestBackupStep ensures a backup can be made again when a migration is retried, and that rolling it back puts the copy back in place of the file and sets aside what the file held since
+func TestBackupStep(
+	t *testing.T) {
+	dir, err := ioutil.TempDir("", "backup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "wallet.db")
+	if err = ioutil.WriteFile(path, []byte("before"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	s := step{Op: opBackup, From: path, To: path + ".migration5"}
+	for i := 0; i < 2; i++ {
+		if err = s.apply(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = ioutil.WriteFile(path, []byte("after"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if !s.reversible() {
+		t.Fatal("a backup can not be undone")
+	}
+	if err = s.undo(); err != nil {
+		t.Fatal(err)
+	}
+	for file, want := range map[string]string{path: "before", path + setAsideSuffix: "after"} {
+		if got, err := ioutil.ReadFile(file); err != nil || string(got) != want {
+			t.Errorf("%s holds %q, %v, want %q", file, got, err, want)
+		}
+	}
+	if FileExists(s.To) {
+		t.Error("the backup was left after it was put back")
+	}
+}
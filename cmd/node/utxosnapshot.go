@@ -0,0 +1,135 @@
+package node
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	blockchain "git.parallelcoin.io/dev/9/pkg/chain"
+	chainhash "git.parallelcoin.io/dev/9/pkg/chain/hash"
+	"git.parallelcoin.io/dev/9/pkg/rpc/json"
+	"git.parallelcoin.io/dev/9/pkg/util/interrupt"
+)
+// utxoSnapshotSigExt is appended to the path of a utxo snapshot for the file holding the maintainer signatures of its hash, one hex encoded DER signature per line
+const utxoSnapshotSigExt = ".sig"
+// utxoSnapshotPath returns the path of a utxo snapshot named by an RPC client, relative paths being in the network directory of the node as the client may not share its working directory
+func utxoSnapshotPath(
+	path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(*Cfg.AppDataDir, NetName(ActiveNetParams), path)
+}
+// utxoSnapshotTrusted returns an error unless the snapshot at path with the hash is the one pinned by assumeutxo or enough of the maintainer keys that sign releases signed its hash in the signature file next to it
+func utxoSnapshotTrusted(
+	path string, hash *chainhash.Hash) error {
+	if StateCfg.AssumeUtxo != nil && StateCfg.AssumeUtxo.IsEqual(hash) {
+		return nil
+	}
+	keys, err := ParseUpdateKeys(updateKeys)
+	if err != nil {
+		return err
+	}
+	data, err := ioutil.ReadFile(path + utxoSnapshotSigExt)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("utxo snapshot %v is not the assumeutxo snapshot and has no signatures", hash)
+		}
+		return err
+	}
+	required := updateSignatures
+	if required < 1 {
+		required = 1
+	}
+	if signers := countSigners(hash[:], strings.Fields(string(data)), keys); signers < required {
+		return fmt.Errorf("utxo snapshot %v is signed by %d of the %d maintainer keys needed", hash, signers, required)
+	}
+	return nil
+}
+// loadUtxoSnapshot loads the utxo snapshot at path into a chain that has no blocks after the genesis block, if it is trusted
+func loadUtxoSnapshot(
+	chain *blockchain.BlockChain, path string) (*blockchain.UtxoSnapshot, error) {
+	if *Cfg.TxIndex || *Cfg.AddrIndex || !*Cfg.NoCFilters {
+		return nil, errors.New("a utxo snapshot can only be loaded with chain.txindex and chain.addrindex disabled and p2p.nocfilters enabled, as the indexes need every block")
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	hash, err := blockchain.HashUtxoSnapshot(f)
+	if err != nil {
+		return nil, err
+	}
+	if err = utxoSnapshotTrusted(path, hash); err != nil {
+		return nil, err
+	}
+	// The snapshot is read again to load it, and the chain checks it still has the hash that was trusted.
+	if _, err = f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	snapshot, err := chain.LoadUtxoSnapshot(f, hash, interrupt.ShutdownRequestChan)
+	if err != nil {
+		return nil, fmt.Errorf("loading utxo snapshot %s: %v", path, err)
+	}
+	return snapshot, nil
+}
+// dumpUtxoSet writes a snapshot of the utxo set at the tip of the chain to a new file at path, removing what was written if it fails
+func dumpUtxoSet(
+	chain *blockchain.BlockChain, path string) (*blockchain.UtxoSnapshot, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return nil, err
+	}
+	snapshot, err := chain.DumpUtxoSet(f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+	return snapshot, nil
+}
+// utxoSnapshotResult returns the RPC result describing the utxo snapshot at path
+func utxoSnapshotResult(
+	path string, snapshot *blockchain.UtxoSnapshot) *json.UtxoSnapshotResult {
+	return &json.UtxoSnapshotResult{
+		Path:       path,
+		BaseHash:   snapshot.BaseHash.String(),
+		BaseHeight: snapshot.BaseHeight,
+		TxCount:    snapshot.TotalTxns,
+		Coins:      snapshot.Coins,
+		Hash:       snapshot.Hash.String(),
+	}
+}
+// handleDumpUtxoSet implements the dumputxoset command.
+func handleDumpUtxoSet(
+	s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*json.DumpUtxoSetCmd)
+	path := utxoSnapshotPath(c.Path)
+	snapshot, err := dumpUtxoSet(s.Cfg.Chain, path)
+	if err != nil {
+		return nil, &json.RPCError{
+			Code:    json.ErrRPCMisc,
+			Message: err.Error(),
+		}
+	}
+	return utxoSnapshotResult(path, snapshot), nil
+}
+// handleLoadUtxoSet implements the loadutxoset command.
+func handleLoadUtxoSet(
+	s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*json.LoadUtxoSetCmd)
+	path := utxoSnapshotPath(c.Path)
+	snapshot, err := loadUtxoSnapshot(s.Cfg.Chain, path)
+	if err != nil {
+		return nil, &json.RPCError{
+			Code:    json.ErrRPCMisc,
+			Message: err.Error(),
+		}
+	}
+	return utxoSnapshotResult(path, snapshot), nil
+}
@@ -0,0 +1,72 @@
+package node
+import (
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	chainhash "git.parallelcoin.io/dev/9/pkg/chain/hash"
+	ec "git.parallelcoin.io/dev/9/pkg/util/elliptic"
+)
+// TestUtxoSnapshotTrusted ensures a utxo snapshot is only trusted when its hash is the one pinned by assumeutxo or enough maintainer keys signed it
+func TestUtxoSnapshotTrusted(
+	t *testing.T) {
+	maintainer, err := ec.NewPrivateKey(ec.S256())
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := ec.NewPrivateKey(ec.S256())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(keys string, assume *chainhash.Hash) {
+		updateKeys, StateCfg.AssumeUtxo = keys, assume
+	}(updateKeys, StateCfg.AssumeUtxo)
+	updateKeys = hex.EncodeToString(maintainer.PubKey().SerializeCompressed())
+	StateCfg.AssumeUtxo = nil
+	dir, err := ioutil.TempDir("", "utxosnapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "utxo.dat")
+	hash := chainhash.DoubleHashH([]byte("snapshot"))
+	sign := func(keys ...*ec.PrivateKey) {
+		var sigs string
+		for _, key := range keys {
+			sig, err := key.Sign(hash[:])
+			if err != nil {
+				t.Fatal(err)
+			}
+			sigs += hex.EncodeToString(sig.Serialize()) + "\n"
+		}
+		if err := ioutil.WriteFile(path+utxoSnapshotSigExt, []byte(sigs), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = utxoSnapshotTrusted(path, &hash); err == nil {
+		t.Error("a snapshot without signatures was trusted")
+	}
+	sign(other)
+	if err = utxoSnapshotTrusted(path, &hash); err == nil {
+		t.Error("a snapshot not signed by a maintainer was trusted")
+	}
+	sign(other, maintainer)
+	if err = utxoSnapshotTrusted(path, &hash); err != nil {
+		t.Errorf("a snapshot signed by a maintainer was not trusted: %v", err)
+	}
+	// the maintainer signature does not cover another snapshot
+	otherHash := chainhash.DoubleHashH([]byte("other snapshot"))
+	if err = utxoSnapshotTrusted(path, &otherHash); err == nil {
+		t.Error("a signature of another snapshot was trusted")
+	}
+	// a pinned snapshot needs no signatures
+	os.Remove(path + utxoSnapshotSigExt)
+	StateCfg.AssumeUtxo = &otherHash
+	if err = utxoSnapshotTrusted(path, &otherHash); err != nil {
+		t.Errorf("the assumeutxo snapshot was not trusted: %v", err)
+	}
+	if err = utxoSnapshotTrusted(path, &hash); err == nil {
+		t.Error("a snapshot other than the assumeutxo one was trusted without signatures")
+	}
+}
@@ -0,0 +1,192 @@
+package node
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	js "encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+	"git.parallelcoin.io/dev/9/cmd/node/mempool"
+	blockchain "git.parallelcoin.io/dev/9/pkg/chain"
+	"git.parallelcoin.io/dev/9/pkg/rpc/json"
+	"git.parallelcoin.io/dev/9/pkg/util"
+	"git.parallelcoin.io/dev/9/pkg/util/chanwg"
+	cl "git.parallelcoin.io/dev/9/pkg/util/cl"
+)
+const (
+	// DefaultWebhookRetries is the default number of times a failed webhook delivery is retried.
+	DefaultWebhookRetries = 5
+	// webhookQueueSize is the number of events buffered for delivery before new events are dropped.
+	webhookQueueSize = 1000
+	// webhookTimeout is the time allowed for a single delivery attempt.
+	webhookTimeout = 10 * time.Second
+	// webhookStopTimeout is how long stopping waits for a delivery attempt in progress before abandoning it, so a slow URL does not hold up shutdown.
+	webhookStopTimeout = 2 * time.Second
+	// webhookRetryDelay is the delay before the first retry of a failed delivery. It doubles on each further retry.
+	webhookRetryDelay = time.Second
+	// webhookSignatureHeader is the header carrying the hex encoded HMAC-SHA256 of the request body.
+	webhookSignatureHeader = "X-Webhook-Signature"
+	// webhookReorgEvent is the event name of a reorganization of the chain.
+	webhookReorgEvent = "reorg"
+)
+// webhookEvent is the JSON body POSTed to webhook URLs for a mempool event.
+type webhookEvent struct {
+	Event      string  `json:"event"`
+	TxID       string  `json:"txid"`
+	Fee        float64 `json:"fee"`
+	FeeRate    float64 `json:"feerate"`
+	Time       int64   `json:"time"`
+	ReplacedBy string  `json:"replacedby,omitempty"`
+	Reason     string  `json:"reason,omitempty"`
+	BlockHash  string  `json:"blockhash,omitempty"`
+}
+// webhookReorg is the JSON body POSTed to webhook URLs for a reorganization of the chain.
+type webhookReorg struct {
+	Event string `json:"event"`
+	Time  int64  `json:"time"`
+	json.ReorgDetails
+}
+// webhookNotifier POSTs mempool and chain reorganization events to a set of URLs from a background goroutine, retrying failed deliveries with exponential backoff and signing each request body with an HMAC-SHA256 key when one is configured.
+type webhookNotifier struct {
+	urls    []string
+	secret  []byte
+	retries int
+	client  *http.Client
+	queue   chan []byte
+	quit    chan struct{}
+	wg      chanwg.WaitGroup
+}
+// newWebhookNotifier returns a webhookNotifier delivering to the passed URLs. An empty secret disables signing.
+func newWebhookNotifier(urls []string, secret string, retries int) *webhookNotifier {
+	return &webhookNotifier{
+		urls:    urls,
+		secret:  []byte(secret),
+		retries: retries,
+		client:  &http.Client{Timeout: webhookTimeout},
+		queue:   make(chan []byte, webhookQueueSize),
+		quit:    make(chan struct{}),
+	}
+}
+// Start starts the delivery goroutine.
+func (w *webhookNotifier) Start() {
+	w.wg.Add(1)
+	go w.deliveryHandler()
+}
+// Stop stops the delivery goroutine, abandoning any queued events, and waits up to webhookStopTimeout for it to exit.
+func (w *webhookNotifier) Stop() {
+	close(w.quit)
+	if !w.wg.WaitTimeout(webhookStopTimeout) {
+		log <- cl.Warn{"abandoning a webhook delivery in progress after waiting", webhookStopTimeout}
+	}
+}
+// NotifyEvent queues the passed mempool event for delivery. It never blocks, so it is safe to use as the NotifyEvent function of the mempool configuration; events are dropped when the queue is full.
+func (w *webhookNotifier) NotifyEvent(ev *mempool.Event) {
+	body, err := js.Marshal(newWebhookEvent(ev))
+	if err != nil {
+		log <- cl.Error{"failed to marshal mempool webhook event:", err}
+		return
+	}
+	select {
+	case w.queue <- body:
+	default:
+		log <- cl.Warn{"mempool webhook queue is full, dropping", ev.Type, "event for", ev.Tx.Hash()}
+	}
+}
+// NotifyReorg queues the passed reorganization of the chain for delivery. Like NotifyEvent it never blocks, and drops the event when the queue is full.
+func (w *webhookNotifier) NotifyReorg(reorg *blockchain.Reorganization) {
+	body, err := js.Marshal(&webhookReorg{
+		Event:        webhookReorgEvent,
+		Time:         time.Now().Unix(),
+		ReorgDetails: reorgDetails(reorg),
+	})
+	if err != nil {
+		log <- cl.Error{"failed to marshal reorg webhook event:", err}
+		return
+	}
+	select {
+	case w.queue <- body:
+	default:
+		log <- cl.Warn{"webhook queue is full, dropping reorg event for", reorg.NewTip}
+	}
+}
+// newWebhookEvent converts a mempool event to its JSON representation.
+func newWebhookEvent(ev *mempool.Event) *webhookEvent {
+	e := &webhookEvent{
+		Event:   ev.Type.String(),
+		TxID:    ev.Tx.Hash().String(),
+		Fee:     util.Amount(ev.Fee).ToDUO(),
+		FeeRate: util.Amount(ev.FeePerKB).ToDUO(),
+		Time:    ev.Time.Unix(),
+		Reason:  ev.Reason,
+	}
+	if ev.ReplacedBy != nil {
+		e.ReplacedBy = ev.ReplacedBy.String()
+	}
+	if ev.BlockHash != nil {
+		e.BlockHash = ev.BlockHash.String()
+	}
+	return e
+}
+// deliveryHandler delivers queued events to every URL in turn until the notifier is stopped. It must be run as a goroutine.
+func (w *webhookNotifier) deliveryHandler() {
+	defer w.wg.Done()
+	for {
+		select {
+		case body := <-w.queue:
+			for _, url := range w.urls {
+				w.deliver(url, body)
+			}
+		case <-w.quit:
+			return
+		}
+	}
+}
+// deliver POSTs body to url, retrying with exponential backoff until it succeeds, the retries are used up or the notifier is stopped.
+func (w *webhookNotifier) deliver(url string, body []byte) {
+	delay := webhookRetryDelay
+	for attempt := 0; ; attempt++ {
+		err := w.post(url, body)
+		if err == nil {
+			return
+		}
+		if attempt >= w.retries {
+			log <- cl.Warn{"giving up on webhook", url, "after", attempt + 1, "attempts:", err}
+			return
+		}
+		log <- cl.Debugf{"webhook %s failed, retrying in %v: %v", url, delay, err}
+		select {
+		case <-time.After(delay):
+		case <-w.quit:
+			return
+		}
+		delay *= 2
+	}
+}
+// post makes a single signed delivery attempt of body to url. Any response status other than 2xx is an error.
+func (w *webhookNotifier) post(url string, body []byte) error {
+	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(w.secret) > 0 {
+		req.Header.Set(webhookSignatureHeader, "sha256="+webhookSignature(w.secret, body))
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected response status %s", resp.Status)
+	}
+	return nil
+}
+// webhookSignature returns the hex encoded HMAC-SHA256 of body keyed with secret.
+func webhookSignature(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -479,6 +479,8 @@ func testStartRescan(
 		},
 		// Fee rate is satoshis per kilobyte
 		1024000,
+		// Dust relay fee rate is satoshis per kilobyte
+		1024000,
 		inSrc(*tx1),
 		func() ([]byte, error) {
 			return script3, nil
@@ -519,6 +521,8 @@ func testStartRescan(
 		},
 		// Fee rate is satoshis per kilobyte
 		1024000,
+		// Dust relay fee rate is satoshis per kilobyte
+		1024000,
 		inSrc(*tx2),
 		func() ([]byte, error) {
 			return script3, nil
@@ -0,0 +1,62 @@
+package top
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"git.parallelcoin.io/dev/9/pkg/util/cl"
+	"git.parallelcoin.io/dev/9/pkg/util/tview"
+)
+// LayoutFile is the file in the data directory the divider positions of the dashboard are kept in
+const LayoutFile = "top-layout.json"
+// layout restores and saves the divider positions of the named split panes of the dashboard
+type layout struct {
+	path      string
+	positions map[string]float64
+}
+// loadLayout reads the saved layout from the data directory. A missing or unreadable file leaves every pane at its default position.
+func loadLayout(dataDir string) *layout {
+	l := &layout{positions: make(map[string]float64)}
+	if dataDir == "" {
+		return l
+	}
+	l.path = filepath.Join(dataDir, LayoutFile)
+	b, err := ioutil.ReadFile(l.path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log <- cl.Warn{"could not read dashboard layout:", err}
+		}
+		return l
+	}
+	if err := json.Unmarshal(b, &l.positions); err != nil {
+		log <- cl.Warn{"ignoring broken dashboard layout", l.path, err}
+	}
+	return l
+}
+// split returns a split pane of the two panels at its saved position, or the given one if it was never moved, and saves the layout whenever the user moves the divider
+func (l *layout) split(name string, direction int, position float64, first, second tview.Primitive) *tview.SplitPane {
+	if saved, ok := l.positions[name]; ok {
+		position = saved
+	}
+	return tview.NewSplitPane(first, second).
+		SetDirection(direction).
+		SetPosition(position).
+		SetMinSize(3).
+		SetChangedFunc(func(position float64) {
+			l.positions[name] = position
+			l.save()
+		})
+}
+// save writes the positions to the layout file
+func (l *layout) save() {
+	if l.path == "" {
+		return
+	}
+	b, err := json.MarshalIndent(l.positions, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := ioutil.WriteFile(l.path, b, 0600); err != nil {
+		log <- cl.Warn{"could not save dashboard layout:", err}
+	}
+}
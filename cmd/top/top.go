@@ -0,0 +1,393 @@
+// Package top is a live terminal dashboard of a full node showing its sync progress, peers, mempool, mining hash rates and recent log entries, refreshed over RPC
+package top
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"git.parallelcoin.io/dev/9/cmd/ctl"
+	"git.parallelcoin.io/dev/9/cmd/nine"
+	"git.parallelcoin.io/dev/9/pkg/rpc/json"
+	"git.parallelcoin.io/dev/9/pkg/util/cl"
+	"git.parallelcoin.io/dev/9/pkg/util/clipboard"
+	"git.parallelcoin.io/dev/9/pkg/util/tcell"
+	"git.parallelcoin.io/dev/9/pkg/util/tview"
+)
+// DefaultRefresh is how often the dashboard polls the node when no interval is given
+const DefaultRefresh = 2 * time.Second
+const helpText = "[::b]q[::-] quit  [::b]tab[::-] next panel  [::b]alt-arrows[::-] resize  [::b]c[::-] copy  [::b]+/-[::-] log level  [::b]f[::-] follow log  [::b]r[::-] refresh"
+const walletHelpText = "[::b]F1[::-] node  [::b]F2[::-] balance  [::b]F3[::-] receive  [::b]F4[::-] history  [::b]F5[::-] send  "
+// snapshot is the state of the node gathered in one refresh. Sections whose call failed are nil.
+type snapshot struct {
+	time    time.Time
+	chain   *json.GetBlockChainInfoResult
+	peers   []json.GetPeerInfoResult
+	mempool *json.GetMempoolInfoResult
+	mining  *json.GetMiningStatsResult
+	// network is the estimated network hash rate of each algorithm
+	network map[string]float64
+	err     error
+}
+// page is a screen of the dashboard and the panels tab moves the focus between. Alt and the arrow keys move the dividers of split, if the page has one.
+type page struct {
+	name   string
+	root   tview.Primitive
+	panels []tview.Primitive
+	split  *tview.SplitPane
+}
+type dashboard struct {
+	cfg      *nine.Config
+	app      *tview.Application
+	pages    *tview.Pages
+	layout   *layout
+	screens  []page
+	current  int
+	wallet   *walletScreens
+	status   *tview.TextView
+	sync     *tview.ProgressBar
+	peers    *tview.Table
+	mempool  *tview.TextView
+	hashrate *tview.Table
+	logs     *tview.LogView
+	// help shows the keys, and messages about what was just done
+	help *tview.StatusBar
+	// busy shows long running operations beside the help line
+	busy   *tview.Spinner
+	bottom *tview.Flex
+	focused  int
+	refresh  chan struct{}
+}
+// Run shows the dashboard of the node the RPC settings of the configuration point at, polling it every interval until the user quits. If wallet is set the screens of the wallet server are added.
+func Run(cfg *nine.Config, interval time.Duration, wallet bool) int {
+	if interval <= 0 {
+		interval = DefaultRefresh
+	}
+	d := newDashboard(cfg, wallet)
+	d.logs.Subscribe(d.app, cl.History)
+	defer d.logs.Unsubscribe()
+	quit := make(chan struct{})
+	defer close(quit)
+	go d.poll(interval, quit)
+	if err := d.app.Run(); err != nil {
+		log <- cl.Error{"dashboard failed:", err}
+		return 1
+	}
+	return 0
+}
+func newDashboard(cfg *nine.Config, wallet bool) *dashboard {
+	dataDir := ""
+	if cfg.DataDir != nil {
+		dataDir = *cfg.DataDir
+	}
+	d := &dashboard{
+		cfg:      cfg,
+		layout:   loadLayout(dataDir),
+		app:      tview.NewApplication().EnableMouse(true),
+		pages:    tview.NewPages(),
+		status:   tview.NewTextView().SetDynamicColors(true),
+		sync:     tview.NewProgressBar().SetLabel("sync ").SetMax(1),
+		peers:    tview.NewTable().SetFixed(1, 0).SetSelectable(true, false).SetSortable(true).SetFilterable(true),
+		mempool:  tview.NewTextView().SetDynamicColors(true),
+		hashrate: tview.NewTable().SetFixed(1, 1),
+		logs:     tview.NewLogView().SetLevel("info"),
+		help:     tview.NewStatusBar(),
+		busy:     tview.NewSpinner(),
+		refresh:  make(chan struct{}, 1),
+	}
+	d.sync.AddThreshold(0.999, tcell.ColorGreen)
+	d.peers.SetBorder(true).SetTitle(" peers ")
+	d.mempool.SetBorder(true).SetTitle(" mempool ")
+	d.hashrate.SetBorder(true).SetTitle(" hash rates ")
+	d.logs.SetBorder(true).SetTitle(" log ")
+	d.peers.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyRune && event.Rune() == 'c' && !d.peers.IsFiltering() {
+			if row, _ := d.peers.GetSelection(); row > 0 {
+				d.copy("peer address", strings.TrimSuffix(d.peers.GetCell(row, 1).Text, " *"))
+			}
+			return nil
+		}
+		return event
+	})
+	side := d.layout.split("node.side", tview.FlexRow, 0.3, d.mempool, d.hashrate)
+	middle := d.layout.split("node.middle", tview.FlexColumn, 0.67, d.peers, side)
+	panes := d.layout.split("node", tview.FlexRow, 0.6, middle, d.logs)
+	node := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(d.status, 1, 0, false).
+		AddItem(d.sync, 1, 0, false).
+		AddItem(panes, 0, 1, true)
+	d.addScreen(page{"node", node, []tview.Primitive{d.peers, d.hashrate, d.logs}, panes})
+	if wallet {
+		d.wallet = newWalletScreens(d)
+		d.help.SetLeft(walletHelpText + helpText)
+	} else {
+		d.help.SetLeft(helpText)
+	}
+	d.help.SetApplication(d.app)
+	d.bottom = tview.NewFlex().
+		AddItem(d.busy, 0, 0, false).
+		AddItem(d.help, 0, 1, false)
+	root := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(d.pages, 0, 1, true).
+		AddItem(d.bottom, 1, 0, false)
+	d.app.SetRoot(root, true).SetInputCapture(d.keys)
+	d.status.SetText("connecting to " + *cfg.RPCConnect + "...")
+	return d
+}
+// addScreen adds a page, showing it if it is the first
+func (d *dashboard) addScreen(p page) {
+	d.pages.AddPage(p.name, p.root, true, len(d.screens) == 0)
+	d.screens = append(d.screens, p)
+}
+// showScreen switches to the page with the given index
+func (d *dashboard) showScreen(i int) {
+	if i < 0 || i >= len(d.screens) {
+		return
+	}
+	d.current, d.focused = i, 0
+	d.pages.SwitchToPage(d.screens[i].name)
+	if panels := d.screens[i].panels; len(panels) > 0 {
+		d.app.SetFocus(panels[0])
+	} else {
+		d.app.SetFocus(d.screens[i].root)
+	}
+}
+// keys handles the dashboard wide keys before the focused panel sees them. While text is being entered only the function keys are taken.
+func (d *dashboard) keys(event *tcell.EventKey) *tcell.EventKey {
+	if key := event.Key(); key >= tcell.KeyF1 && key <= tcell.KeyF12 {
+		d.showScreen(int(key - tcell.KeyF1))
+		return nil
+	}
+	switch focus := d.app.GetFocus().(type) {
+	case *tview.InputField, *tview.DropDown, *tview.Button, *tview.Modal:
+		return event
+	case *tview.Table:
+		if focus.IsFiltering() {
+			return event
+		}
+	}
+	if split := d.screens[d.current].split; split != nil && split.ResizeKey(event) {
+		return nil
+	}
+	panels := d.screens[d.current].panels
+	switch event.Key() {
+	case tcell.KeyEscape:
+		d.app.Stop()
+		return nil
+	case tcell.KeyTab, tcell.KeyBacktab:
+		if len(panels) == 0 {
+			return event
+		}
+		step := 1
+		if event.Key() == tcell.KeyBacktab {
+			step = len(panels) - 1
+		}
+		d.focused = (d.focused + step) % len(panels)
+		d.app.SetFocus(panels[d.focused])
+		return nil
+	case tcell.KeyRune:
+		switch event.Rune() {
+		case 'q':
+			d.app.Stop()
+			return nil
+		case 'r':
+			d.requestRefresh()
+			return nil
+		case '+', '-', 'f':
+			// the log keys work whichever panel has focus
+			d.logs.InputHandler()(event, func(p tview.Primitive) { d.app.SetFocus(p) })
+			return nil
+		}
+	}
+	return event
+}
+// copy puts text on the clipboard and says so in the help line for a few seconds
+func (d *dashboard) copy(what, text string) {
+	if err := clipboard.Copy(text); err != nil {
+		d.help.ShowMessage(tview.Escape("could not copy "+what+": "+err.Error()), tcell.ColorRed, 3*time.Second)
+		return
+	}
+	d.help.ShowMessage(tview.Escape("copied "+what+" "+text), tcell.ColorGreen, 3*time.Second)
+}
+// setBusy shows the spinner with the label in front of the help line, or hides it if the label is empty
+func (d *dashboard) setBusy(label string) {
+	if label == "" {
+		d.busy.Stop()
+		d.bottom.ResizeItem(d.busy, 0, 0)
+		return
+	}
+	d.busy.SetLabel(label).Start(d.app)
+	d.bottom.ResizeItem(d.busy, tview.TaggedStringWidth(label)+3, 0)
+}
+// requestRefresh makes the poller fetch a new snapshot without waiting for the interval
+func (d *dashboard) requestRefresh() {
+	select {
+	case d.refresh <- struct{}{}:
+	default:
+	}
+}
+// poll fetches a snapshot every interval, or when a refresh is asked for, and shows it
+func (d *dashboard) poll(interval time.Duration, quit chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		s := d.fetch()
+		var w walletSnapshot
+		if d.wallet != nil {
+			w = d.wallet.fetch()
+		}
+		d.app.QueueUpdateDraw(func() {
+			d.show(s)
+			if d.wallet != nil {
+				d.wallet.show(w, s.chain)
+			}
+		})
+		select {
+		case <-quit:
+			return
+		case <-ticker.C:
+		case <-d.refresh:
+		}
+	}
+}
+// fetch queries the node. Only a failure to get the chain state is reported as an error, as the other calls may be unavailable on nodes without mining or with an older version.
+func (d *dashboard) fetch() (s snapshot) {
+	s.time = time.Now()
+	var chain json.GetBlockChainInfoResult
+	if s.err = ctl.Call(d.cfg, "getblockchaininfo", &chain); s.err != nil {
+		return
+	}
+	s.chain = &chain
+	if err := ctl.Call(d.cfg, "getpeerinfo", &s.peers); err != nil {
+		log <- cl.Debug{"getpeerinfo failed:", err}
+	}
+	var mempool json.GetMempoolInfoResult
+	if err := ctl.Call(d.cfg, "getmempoolinfo", &mempool); err != nil {
+		log <- cl.Debug{"getmempoolinfo failed:", err}
+	} else {
+		s.mempool = &mempool
+	}
+	var mining json.GetMiningStatsResult
+	if err := ctl.Call(d.cfg, "getminingstats", &mining); err != nil {
+		log <- cl.Debug{"getminingstats failed:", err}
+		return
+	}
+	s.mining = &mining
+	s.network = make(map[string]float64)
+	for _, a := range mining.Algos {
+		var hps float64
+		if err := ctl.Call(d.cfg, "getnetworkhashps", &hps, 120, -1, a.Algo); err != nil {
+			log <- cl.Debug{"getnetworkhashps failed:", a.Algo, err}
+			continue
+		}
+		s.network[a.Algo] = hps
+	}
+	return
+}
+// show updates the panels from a snapshot, keeping the last values of sections that could not be fetched
+func (d *dashboard) show(s snapshot) {
+	if s.err != nil {
+		d.status.SetText(fmt.Sprintf("[red]%s: %v[-] (retrying, last attempt %s)",
+			*d.cfg.RPCConnect, tview.Escape(s.err.Error()), s.time.Format("15:04:05")))
+		return
+	}
+	c := s.chain
+	d.sync.SetValue(c.VerificationProgress)
+	d.status.SetText(fmt.Sprintf(
+		"[::b]%s[::-]  height [::b]%d[::-]  verified %.2f%%  difficulty %s  peers %d  best %s  %s",
+		c.Chain, c.Blocks, c.VerificationProgress*100, si(c.Difficulty, ""), len(s.peers),
+		shortHash(c.BestBlockHash), s.time.Format("15:04:05")))
+	d.showPeers(s.peers)
+	if s.mempool != nil {
+		d.showMempool(s.mempool)
+	}
+	if s.mining != nil {
+		d.showHashRates(s.mining, s.network)
+	}
+}
+func (d *dashboard) showPeers(peers []json.GetPeerInfoResult) {
+	sort.Slice(peers, func(i, j int) bool { return peers[i].ID < peers[j].ID })
+	d.peers.Clear()
+	header(d.peers, "id", "address", "dir", "height", "ping", "recv", "sent", "version")
+	for i, p := range peers {
+		dir := "out"
+		if p.Inbound {
+			dir = "in"
+		}
+		height := p.CurrentHeight
+		if height == 0 {
+			height = p.StartingHeight
+		}
+		addr := p.Addr
+		if p.SyncNode {
+			addr += " *"
+		}
+		row(d.peers, i+1,
+			fmt.Sprint(p.ID), addr, dir, fmt.Sprint(height),
+			fmt.Sprintf("%.0fms", p.PingTime/1000),
+			si(float64(p.BytesRecv), "B"), si(float64(p.BytesSent), "B"), p.SubVer)
+		// the raw numbers keep the sort order right whatever the units
+		d.peers.GetCell(i+1, 4).SetReference(p.PingTime)
+		d.peers.GetCell(i+1, 5).SetReference(p.BytesRecv)
+		d.peers.GetCell(i+1, 6).SetReference(p.BytesSent)
+	}
+}
+func (d *dashboard) showMempool(m *json.GetMempoolInfoResult) {
+	d.mempool.SetText(fmt.Sprintf(
+		"transactions  %d\nsize          %s\nvirtual size  %s\norphans       %d\nmin fee       %.8f\nrelay fee     %.8f",
+		m.Size, si(float64(m.Bytes), "B"), si(float64(m.VSize), "vB"), m.Orphans,
+		m.MempoolMinFee, m.MinRelayTxFee))
+}
+func (d *dashboard) showHashRates(m *json.GetMiningStatsResult, network map[string]float64) {
+	d.hashrate.Clear()
+	header(d.hashrate, "algo", "local 1m", "local 15m", "network")
+	for i, a := range m.Algos {
+		net := "-"
+		if hps, ok := network[a.Algo]; ok {
+			net = si(hps, "H/s")
+		}
+		name := a.Algo
+		if a.Algo == m.GenAlgoCurrent {
+			name = "*" + name
+		}
+		row(d.hashrate, i+1, name, si(a.HashRate1m, "H/s"), si(a.HashRate15m, "H/s"), net)
+	}
+	title := " hash rates "
+	if !m.Generate {
+		title = " hash rates (not mining) "
+	}
+	d.hashrate.SetTitle(title)
+}
+// header fills the first row of a table with bold column names
+func header(t *tview.Table, names ...string) {
+	for i, name := range names {
+		t.SetCell(0, i, tview.NewTableCell(name).
+			SetAttributes(tcell.AttrBold).
+			SetSelectable(false))
+	}
+}
+// row fills a row of a table with cells sharing the width of the table
+func row(t *tview.Table, r int, cells ...string) {
+	for i, text := range cells {
+		t.SetCell(r, i, tview.NewTableCell(tview.Escape(text)).SetExpansion(1))
+	}
+}
+// si formats a value with an SI prefix and unit, such as 12.3 MH/s
+func si(v float64, unit string) string {
+	prefixes := []string{"", "k", "M", "G", "T", "P", "E"}
+	i := 0
+	for v >= 1000 && i < len(prefixes)-1 {
+		v /= 1000
+		i++
+	}
+	if i == 0 {
+		return strings.TrimSpace(fmt.Sprintf("%.0f %s", v, unit))
+	}
+	return strings.TrimSpace(fmt.Sprintf("%.1f %s%s", v, prefixes[i], unit))
+}
+// shortHash abbreviates a block hash to its first and last digits
+func shortHash(h string) string {
+	if len(h) <= 16 {
+		return h
+	}
+	return h[:8] + ".." + h[len(h)-8:]
+}
@@ -0,0 +1,339 @@
+package top
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"git.parallelcoin.io/dev/9/cmd/ctl"
+	"git.parallelcoin.io/dev/9/cmd/nine"
+	"git.parallelcoin.io/dev/9/pkg/rpc/json"
+	"git.parallelcoin.io/dev/9/pkg/util"
+	"git.parallelcoin.io/dev/9/pkg/util/cl"
+	"git.parallelcoin.io/dev/9/pkg/util/qr"
+	"git.parallelcoin.io/dev/9/pkg/util/tcell"
+	"git.parallelcoin.io/dev/9/pkg/util/tview"
+)
+// UnlockSeconds is how long the wallet is unlocked for to sign a transaction from the send screen
+const UnlockSeconds = 30
+// feeTargets are the fee choices of the send screen and the number of blocks the transaction should confirm within, 0 leaving the fee to the wallet
+var feeTargets = []struct {
+	name   string
+	blocks int64
+}{
+	{"wallet default", 0},
+	{"priority (2 blocks)", 2},
+	{"normal (6 blocks)", 6},
+	{"economy (25 blocks)", 25},
+}
+var addressRE = regexp.MustCompile("^[1-9A-HJ-NP-Za-km-z]{25,40}$")
+// walletSnapshot is the state of the wallet gathered in one refresh
+type walletSnapshot struct {
+	// height is the block the wallet has scanned the chain up to
+	height       int32
+	balance      float64
+	unconfirmed  float64
+	addresses    []string
+	received     map[string]json.ListReceivedByAddressResult
+	transactions []json.ListTransactionsResult
+	err          error
+}
+// walletScreens are the dashboard pages showing and using the wallet server
+type walletScreens struct {
+	d *dashboard
+	// cfg is a copy of the configuration with wallet set, so calls go to the wallet server
+	cfg       *nine.Config
+	overview  *tview.TextView
+	addresses *tview.Table
+	qrcode    *tview.TextView
+	history   *tview.Table
+	send      *tview.Form
+	result    *tview.TextView
+	// selected is the address shown as a QR code, kept across refreshes
+	selected string
+}
+func newWalletScreens(d *dashboard) *walletScreens {
+	cfg := *d.cfg
+	on := true
+	cfg.Wallet = &on
+	w := &walletScreens{
+		d:         d,
+		cfg:       &cfg,
+		overview:  tview.NewTextView().SetDynamicColors(true),
+		addresses: tview.NewTable().SetFixed(1, 0).SetSelectable(true, false).SetSortable(true).SetFilterable(true),
+		qrcode:    tview.NewTextView().SetDynamicColors(true),
+		history:   tview.NewTable().SetFixed(1, 0).SetSelectable(true, false).SetSortable(true).SetFilterable(true),
+		send:      tview.NewForm(),
+		result:    tview.NewTextView().SetDynamicColors(true),
+	}
+	w.overview.SetBorder(true).SetTitle(" balance ")
+	w.overview.SetText("connecting to " + *cfg.WalletServer + "...")
+	w.addresses.SetBorder(true).SetTitle(" addresses (n: new address, c: copy) ")
+	w.qrcode.SetBorder(true).SetTitle(" scan to pay ")
+	w.history.SetBorder(true).SetTitle(" transactions (c: copy transaction id) ")
+	w.send.SetBorder(true).SetTitle(" send ")
+	w.addresses.SetSelectionChangedFunc(func(row, column int) {
+		if cell := w.addresses.GetCell(row, 0); row > 0 && cell != nil {
+			w.showQR(cell.Text)
+		}
+	})
+	w.addresses.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() != tcell.KeyRune || w.addresses.IsFiltering() {
+			return event
+		}
+		switch event.Rune() {
+		case 'n':
+			go w.newAddress()
+			return nil
+		case 'c':
+			if row, _ := w.addresses.GetSelection(); row > 0 {
+				w.d.copy("address", w.addresses.GetCell(row, 0).Text)
+			}
+			return nil
+		}
+		return event
+	})
+	w.history.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyRune && event.Rune() == 'c' && !w.history.IsFiltering() {
+			if row, _ := w.history.GetSelection(); row > 0 {
+				if txid, ok := w.history.GetCell(row, 6).GetReference().(string); ok {
+					w.d.copy("transaction id", txid)
+				}
+			}
+			return nil
+		}
+		return event
+	})
+	w.buildSendForm()
+	receive := d.layout.split("receive", tview.FlexColumn, 0.5, w.addresses, w.qrcode)
+	send := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(w.send, 0, 1, true).
+		AddItem(w.result, 2, 0, false)
+	d.addScreen(page{"balance", w.overview, nil, nil})
+	d.addScreen(page{"receive", receive, []tview.Primitive{w.addresses}, receive})
+	d.addScreen(page{"history", w.history, []tview.Primitive{w.history}, nil})
+	d.addScreen(page{"send", send, nil, nil})
+	return w
+}
+func (w *walletScreens) buildSendForm() {
+	options := make([]string, len(feeTargets))
+	for i, f := range feeTargets {
+		options[i] = f.name
+	}
+	maxAmount := util.MaxSatoshi / util.SatoshiPerBitcoin
+	w.send.
+		AddInputField("pay to", "", 42, nil, nil).
+		AddInputField("amount", "", 20, nil, nil).
+		AddDropDown("fee", options, 2, nil).
+		AddPasswordField("passphrase", "", 32, '*', nil).
+		AddButton("send", w.confirm).
+		AddButton("clear", w.clearForm)
+	// empty fields are not marked while the form is being filled in, confirm
+	// checks that they are given
+	w.field("pay to").AddValidator(tview.ValidateOptional(
+		tview.ValidateRegexp(addressRE, "not an address")))
+	w.field("amount").AddValidator(tview.ValidateOptional(
+		tview.ValidateFloatRange(1/util.SatoshiPerBitcoin, maxAmount)))
+}
+func (w *walletScreens) field(label string) *tview.InputField {
+	return w.send.GetFormItemByLabel(label).(*tview.InputField)
+}
+func (w *walletScreens) clearForm() {
+	for _, label := range []string{"pay to", "amount", "passphrase"} {
+		w.field(label).SetText("")
+	}
+	w.result.SetText("")
+}
+// confirm checks the send form and asks before sending
+func (w *walletScreens) confirm() {
+	if err := w.send.Validate(); err != nil {
+		w.result.SetText("[red]" + tview.Escape(err.Error()))
+		w.d.app.SetFocus(w.send)
+		return
+	}
+	for _, label := range []string{"pay to", "amount", "passphrase"} {
+		if w.field(label).GetText() == "" {
+			w.result.SetText("[red]" + label + " is required")
+			return
+		}
+	}
+	address := w.field("pay to").GetText()
+	amount, _ := strconv.ParseFloat(strings.TrimSpace(w.field("amount").GetText()), 64)
+	fee, _ := w.send.GetFormItemByLabel("fee").(*tview.DropDown).GetCurrentOption()
+	if fee < 0 {
+		fee = 0
+	}
+	modal := tview.NewModal().
+		SetText(fmt.Sprintf("send %.8f to %s with %s fee?", amount, address, feeTargets[fee].name)).
+		AddButtons([]string{"send", "cancel"})
+	modal.SetDoneFunc(func(index int, label string) {
+		w.d.pages.RemovePage("confirm")
+		w.d.app.SetFocus(w.send)
+		if label == "send" {
+			w.result.SetText("sending...")
+			go w.sendTo(address, amount, feeTargets[fee].blocks, w.field("passphrase").GetText())
+		}
+	})
+	w.d.pages.AddPage("confirm", modal, false, true)
+	w.d.app.SetFocus(modal)
+}
+// sendTo sets the fee rate estimated by the node for the target, unlocks the wallet and sends, then shows the transaction or the error
+func (w *walletScreens) sendTo(address string, amount float64, blocks int64, passphrase string) {
+	var note string
+	err := func() error {
+		if blocks > 0 {
+			var fee json.EstimateSmartFeeResult
+			switch err := ctl.Call(w.d.cfg, "estimatesmartfee", &fee, blocks); {
+			case err != nil:
+				note = "fee estimate failed, using wallet fee: " + err.Error()
+			case fee.FeeRate <= 0:
+				note = "no fee estimate yet, using wallet fee"
+			default:
+				if err := ctl.Call(w.cfg, "settxfee", nil, fee.FeeRate); err != nil {
+					return err
+				}
+				note = fmt.Sprintf("fee rate %.8f/kB", fee.FeeRate)
+			}
+		}
+		if err := ctl.Call(w.cfg, "walletpassphrase", nil, passphrase, UnlockSeconds); err != nil {
+			return err
+		}
+		var txid string
+		if err := ctl.Call(w.cfg, "sendtoaddress", &txid, address, amount); err != nil {
+			return err
+		}
+		note = "sent " + txid + "\n" + note
+		return nil
+	}()
+	if err != nil {
+		log <- cl.Warn{"send failed:", err}
+	}
+	w.d.app.QueueUpdateDraw(func() {
+		if err != nil {
+			w.result.SetText("[red]" + tview.Escape(err.Error()) + "[-]\n" + tview.Escape(note))
+			return
+		}
+		w.field("passphrase").SetText("")
+		w.result.SetText("[green]" + tview.Escape(note))
+	})
+	w.d.requestRefresh()
+}
+func (w *walletScreens) newAddress() {
+	var address string
+	if err := ctl.Call(w.cfg, "getnewaddress", &address); err != nil {
+		log <- cl.Warn{"getnewaddress failed:", err}
+		return
+	}
+	w.d.app.QueueUpdateDraw(func() {
+		w.selected = address
+	})
+	w.d.requestRefresh()
+}
+// fetch queries the wallet server, stopping at the first failure
+func (w *walletScreens) fetch() (s walletSnapshot) {
+	if s.err = ctl.Call(w.cfg, "getbalance", &s.balance); s.err != nil {
+		return
+	}
+	if err := ctl.Call(w.cfg, "getblockcount", &s.height); err != nil {
+		log <- cl.Debug{"wallet getblockcount failed:", err}
+	}
+	if s.err = ctl.Call(w.cfg, "getunconfirmedbalance", &s.unconfirmed); s.err != nil {
+		return
+	}
+	if s.err = ctl.Call(w.cfg, "getaddressesbyaccount", &s.addresses, "default"); s.err != nil {
+		return
+	}
+	var received []json.ListReceivedByAddressResult
+	if s.err = ctl.Call(w.cfg, "listreceivedbyaddress", &received, 0); s.err != nil {
+		return
+	}
+	s.received = make(map[string]json.ListReceivedByAddressResult)
+	for _, r := range received {
+		s.received[r.Address] = r
+	}
+	s.err = ctl.Call(w.cfg, "listtransactions", &s.transactions, "*", 100)
+	return
+}
+// show updates the wallet screens. While the wallet is behind the node's chain it is rescanning blocks for its transactions, which after a new wallet or a key import takes a while, and the spinner says so.
+func (w *walletScreens) show(s walletSnapshot, chain *json.GetBlockChainInfoResult) {
+	if s.err != nil {
+		w.d.setBusy("")
+		w.overview.SetText(fmt.Sprintf("[red]%s: %s[-]",
+			*w.cfg.WalletServer, tview.Escape(s.err.Error())))
+		return
+	}
+	rescanning := chain != nil && s.height < chain.Blocks
+	if rescanning {
+		w.d.setBusy(fmt.Sprintf("wallet rescanning %d/%d", s.height, chain.Blocks))
+	} else {
+		w.d.setBusy("")
+	}
+	var b strings.Builder
+	if rescanning {
+		fmt.Fprintf(&b, "[yellow]rescanning block %d of %d, the balance may be incomplete[-]\n\n", s.height, chain.Blocks)
+	}
+	fmt.Fprintf(&b, "balance      [::b]%.8f[::-]\n", s.balance)
+	fmt.Fprintf(&b, "unconfirmed  %.8f\n", s.unconfirmed)
+	fmt.Fprintf(&b, "total        %.8f\n\n", s.balance+s.unconfirmed)
+	fmt.Fprintf(&b, "addresses    %d\n\nrecent transactions\n", len(s.addresses))
+	for i := len(s.transactions) - 1; i >= 0 && i >= len(s.transactions)-5; i-- {
+		t := s.transactions[i]
+		fmt.Fprintf(&b, "  %s  %-8s %16.8f  %s\n", txTime(t), t.Category, t.Amount, tview.Escape(t.Address))
+	}
+	w.overview.SetText(b.String())
+	w.showAddresses(s)
+	w.showHistory(s.transactions)
+}
+func (w *walletScreens) showAddresses(s walletSnapshot) {
+	w.addresses.Clear()
+	header(w.addresses, "address", "received", "confirmations")
+	selectedRow := 0
+	for i, a := range s.addresses {
+		r := s.received[a]
+		row(w.addresses, i+1, a, fmt.Sprintf("%.8f", r.Amount), fmt.Sprint(r.Confirmations))
+		if a == w.selected {
+			selectedRow = i + 1
+		}
+	}
+	if selectedRow == 0 && len(s.addresses) > 0 {
+		selectedRow = 1
+	}
+	if selectedRow > 0 {
+		w.addresses.Select(selectedRow, 0)
+		w.showQR(w.addresses.GetCell(selectedRow, 0).Text)
+	}
+}
+// showQR draws the QR code of an address dark on light whatever the terminal colours are
+func (w *walletScreens) showQR(address string) {
+	w.selected = address
+	code, err := qr.Encode(address)
+	if err != nil {
+		w.qrcode.SetText(tview.Escape(err.Error()))
+		return
+	}
+	var b strings.Builder
+	for _, line := range code.HalfBlocks(2) {
+		b.WriteString("[black:white]" + line + "[-:-]\n")
+	}
+	b.WriteString("\n" + tview.Escape(address))
+	w.qrcode.SetText(b.String())
+}
+func (w *walletScreens) showHistory(transactions []json.ListTransactionsResult) {
+	w.history.Clear()
+	header(w.history, "time", "category", "amount", "fee", "confirmations", "address", "transaction")
+	for i := range transactions {
+		// newest first
+		t := transactions[len(transactions)-1-i]
+		fee := ""
+		if t.Fee != nil {
+			fee = fmt.Sprintf("%.8f", *t.Fee)
+		}
+		row(w.history, i+1, txTime(t), t.Category, fmt.Sprintf("%.8f", t.Amount),
+			fee, fmt.Sprint(t.Confirmations), t.Address, shortHash(t.TxID))
+		// the full id is kept for copying
+		w.history.GetCell(i+1, 6).SetReference(t.TxID)
+	}
+}
+func txTime(t json.ListTransactionsResult) string {
+	return time.Unix(t.Time, 0).Format("2006-01-02 15:04")
+}
@@ -5,17 +5,23 @@ import (
 	"net"
 	"net/http"
 	_ "net/http/pprof"
+	"path/filepath"
 	"sync"
 	"git.parallelcoin.io/dev/9/cmd/nine"
+	sac "git.parallelcoin.io/dev/9/cmd/spv"
 	"git.parallelcoin.io/dev/9/pkg/chain/fork"
 	legacyrpc "git.parallelcoin.io/dev/9/pkg/rpc/legacy"
+	"git.parallelcoin.io/dev/9/pkg/util"
 	cl "git.parallelcoin.io/dev/9/pkg/util/cl"
 	"git.parallelcoin.io/dev/9/pkg/util/interrupt"
 	"git.parallelcoin.io/dev/9/pkg/wallet"
 	chain "git.parallelcoin.io/dev/9/pkg/wallet/chain"
+	walletdb "git.parallelcoin.io/dev/9/pkg/wallet/db"
 )
 var (
 	cfg *nine.Config
+	// InProcessChain is the chain of a full node running in the same process, which the wallet uses directly instead of connecting to a node over RPC when it is set
+	InProcessChain chain.Backend
 )
 // Main is a work-around main function that is required since deferred functions (such as log flushing) are not called with calls to os.Exit.
 // Instead, main runs this function and checks for a non-nil error, at point any defers have already run, and if the error is non-nil, the program can be exited with an error exit status.
@@ -55,7 +61,23 @@ func Main(c *nine.Config, activeNet *nine.Params, path string) error {
 	// the wallet when loaded later.
 	if !*cfg.NoInitialLoad {
 		log <- cl.Trc("starting rpcClientConnectLoop")
-		go rpcClientConnectLoop(legacyRPCServer, loader)
+		go rpcClientConnectLoop(legacyRPCServer, loader, path)
+	}
+	// Use the same dust relay fee as the node for the outputs the wallet creates so they are not rejected as dust.
+	if cfg.DustRelayFee != nil {
+		dustRelayFee, err := util.NewAmount(*cfg.DustRelayFee)
+		if err != nil || dustRelayFee < 0 {
+			log <- cl.Error{"invalid dustrelayfee:", *cfg.DustRelayFee}
+			return fmt.Errorf("invalid dustrelayfee: %v", *cfg.DustRelayFee)
+		}
+		loader.RunAfterLoad(func(w *wallet.Wallet) {
+			w.SetDustRelayFee(dustRelayFee)
+		})
+	}
+	if cfg.WalletRBF != nil && *cfg.WalletRBF {
+		loader.RunAfterLoad(func(w *wallet.Wallet) {
+			w.SetReplaceable(true)
+		})
 	}
 	loader.RunAfterLoad(func(w *wallet.Wallet) {
 		log <- cl.Trc("starting startWalletRPCServices")
@@ -139,54 +161,46 @@ func readCAFile() []byte {
 // The legacy RPC is optional.  If set, the connected RPC client will be
 // associated with the server for RPC passthrough and to enable additional
 // methods.
-func rpcClientConnectLoop(legacyRPCServer *legacyrpc.Server, loader *wallet.Loader) {
+func rpcClientConnectLoop(legacyRPCServer *legacyrpc.Server, loader *wallet.Loader, netDir string) {
 	var certs []byte
-	// if !cfg.UseSPV {
-	certs = readCAFile()
-	// }
+	if !*cfg.WalletSPV && InProcessChain == nil {
+		certs = readCAFile()
+	}
+	var chainService *sac.ChainService
 	for {
 		var (
 			chainClient chain.Interface
 			err         error
 		)
-		// if cfg.UseSPV {
-		// 	var (
-		// 		chainService *neutrino.ChainService
-		// 		spvdb        walletdb.DB
-		// 	)
-		// 	netDir := networkDir(cfg.AppDataDir.Value, ActiveNet.Params)
-		// 	spvdb, err = walletdb.Create("bdb",
-		// 		filepath.Join(netDir, "neutrino.db"))
-		// 	defer spvdb.Close()
-		// 	if err != nil {
-		// 		log<-cl.Errorf{"unable to create Neutrino DB: %s", err)
-		// 		continue
-		// 	}
-		// 	chainService, err = neutrino.NewChainService(
-		// 		neutrino.Config{
-		// 			DataDir:      netDir,
-		// 			Database:     spvdb,
-		// 			ChainParams:  *ActiveNet.Params,
-		// 			ConnectPeers: cfg.ConnectPeers,
-		// 			AddPeers:     cfg.AddPeers,
-		// 		})
-		// 	if err != nil {
-		// 		log<-cl.Errorf{"couldn't create Neutrino ChainService: %s", err)
-		// 		continue
-		// 	}
-		// 	chainClient = chain.NewNeutrinoClient(ActiveNet.Params, chainService)
-		// 	err = chainClient.Start()
-		// 	if err != nil {
-		// 		log<-cl.Errorf{"couldn't start Neutrino client: %s", err)
-		// 	}
-		// } else {
-		chainClient, err = startChainRPC(certs)
-		if err != nil {
-			log <- cl.Error{
-				"unable to open connection to consensus RPC server:", err}
-			continue
+		if InProcessChain != nil {
+			// the node is in this process, so it can not go away while the wallet runs
+			log <- cl.Info{"wallet using the chain of the node in the same process"}
+			chainClient = chain.NewInProcessClient(ActiveNet.Params, InProcessChain)
+			if err = chainClient.Start(); err != nil {
+				log <- cl.Error{"couldn't start in-process chain client:", err}
+				return
+			}
+		} else if *cfg.WalletSPV {
+			// the headers and filters are kept across reconnections
+			if chainService == nil {
+				if chainService, err = startChainService(netDir); err != nil {
+					log <- cl.Error{"unable to start light client:", err}
+					return
+				}
+			}
+			chainClient = chain.NewNeutrinoClient(ActiveNet.Params, chainService)
+			if err = chainClient.Start(); err != nil {
+				log <- cl.Error{"couldn't start light client:", err}
+				continue
+			}
+		} else {
+			chainClient, err = startChainRPC(certs)
+			if err != nil {
+				log <- cl.Error{
+					"unable to open connection to consensus RPC server:", err}
+				continue
+			}
 		}
-		// }
 		// Rather than inlining this logic directly into the loader
 		// callback, a function variable is used to avoid running any of
 		// this after the client disconnects by setting it to nil.  This
@@ -226,6 +240,38 @@ func rpcClientConnectLoop(legacyRPCServer *legacyrpc.Server, loader *wallet.Load
 		}
 	}
 }
+// startChainService opens the block header and compact filter stores in the wallet's network directory and starts syncing them from the peers in p2p.connect and p2p.addpeer, or the ones it finds, for the wallet to run in light mode without a full node
+func startChainService(netDir string) (*sac.ChainService, error) {
+	spvdb, err := walletdb.Create("bdb", filepath.Join(netDir, "neutrino.db"))
+	if err != nil {
+		return nil, err
+	}
+	var connect, add []string
+	if cfg.ConnectPeers != nil {
+		connect = *cfg.ConnectPeers
+	}
+	if cfg.AddPeers != nil {
+		add = *cfg.AddPeers
+	}
+	cs, err := sac.NewChainService(sac.Config{
+		DataDir:      netDir,
+		Database:     spvdb,
+		ChainParams:  *ActiveNet.Params,
+		ConnectPeers: connect,
+		AddPeers:     add,
+	})
+	if err != nil {
+		spvdb.Close()
+		return nil, err
+	}
+	interrupt.AddHandler(func() {
+		log <- cl.Wrn("stopping light client...")
+		cs.Stop()
+		spvdb.Close()
+	})
+	log <- cl.Info{"wallet syncing in light mode from peers with compact block filters"}
+	return cs, nil
+}
 // startChainRPC opens a RPC client connection to a pod server for blockchain
 // services.  This function uses the RPC options from the global config and
 // there is no recovery in case the server is not available or if there is an
@@ -1,6 +1,5 @@
 package walletmain
 import (
-	"bufio"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -74,7 +73,11 @@ func CreateWallet(cfg *nine.Config, activeNet *nine.Params, path string) error {
 		}
 	}
 	// Start by prompting for the private passphrase.  When there is an existing keystore, the user will be promped for that passphrase, otherwise they will be prompted for a new one.
-	reader := bufio.NewReader(os.Stdin)
+	// The answers come from stdin, or from a file descriptor or the environment when prompt.AnswersEnv opts in, so wallets can be created without a terminal.
+	reader, err := prompt.Input()
+	if err != nil {
+		return err
+	}
 	privPass, err := prompt.PrivatePass(reader, legacyKeyStore)
 	if err != nil {
 		log <- cl.Debug{err}
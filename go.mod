@@ -1,6 +1,6 @@
 module git.parallelcoin.io/dev/9
 
-go 1.12
+go 1.23
 
 require (
 	ekyu.moe/cryptonight v0.3.0
@@ -20,7 +20,6 @@ require (
 	github.com/dchest/blake256 v1.0.0
 	github.com/ebfe/keccak v0.0.0-20150115210727-5cc570678d1b
 	github.com/gdamore/encoding v1.0.0
-	github.com/gdamore/tcell v1.1.2 // indirect
 	github.com/golang/protobuf v1.3.1
 	github.com/jessevdk/go-flags v1.4.0
 	github.com/kkdai/bstream v1.0.0
@@ -29,12 +28,22 @@ require (
 	github.com/mattn/go-runewidth v0.0.4
 	github.com/mitchellh/colorstring v0.0.0-20190213212951-d06e56a500db
 	github.com/programmer10110/gostreebog v0.0.0-20170704145444-a3e1d28291b2
+	github.com/quic-go/quic-go v0.54.0
 	github.com/rivo/uniseg v0.0.0-20190513083848-b9f5b9457d44
 	github.com/smartystreets/goconvey v0.0.0-20190330032615-68dc04aab96a
 	go.uber.org/atomic v1.4.0
-	golang.org/x/crypto v0.0.0-20190513172903-22d7a77e9e5f
-	golang.org/x/net v0.0.0-20190522155817-f3200d17e092
-	golang.org/x/text v0.3.0
+	golang.org/x/crypto v0.26.0
+	golang.org/x/net v0.28.0
+	golang.org/x/text v0.17.0
 	google.golang.org/grpc v1.21.0
 	gopkg.in/urfave/cli.v1 v1.20.0
 )
+
+require (
+	github.com/btcsuite/snappy-go v1.0.0 // indirect
+	github.com/gdamore/tcell v1.1.2 // indirect
+	github.com/jtolds/gls v4.20.0+incompatible // indirect
+	github.com/smartystreets/assertions v0.0.0-20180927180507-b2de0cb4f26d // indirect
+	golang.org/x/sys v0.23.0 // indirect
+	google.golang.org/genproto v0.0.0-20180817151627-c66870c02cf8 // indirect
+)
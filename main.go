@@ -10,6 +10,7 @@ import (
 	"git.parallelcoin.io/dev/9/cmd/def"
 	"git.parallelcoin.io/dev/9/cmd/node"
 	"git.parallelcoin.io/dev/9/cmd/node/mempool"
+	"git.parallelcoin.io/dev/9/pkg/chain/mining/stratum"
 	"git.parallelcoin.io/dev/9/pkg/util/limits"
 )
 func main() {
@@ -97,18 +98,51 @@ var nineApp = func() *def.App {
 		<node> indicates we are connecting to a full node RPC (overrides wallet and is default)
 		<wallet> indicates we are connecting to a wallet RPC
 		<word>, <float> and <integer> just cover the items that follow in RPC
+		<qr> prints a text result such as the address from getnewaddress as a QR code too
+		balances and received amounts are shown in app.unit written for app.locale when either is set
 		commands the RPC command is expected to be everything after the ctl keyword`),
-			Opts("datadir", "node", "wallet", "word", "integer", "float"),
+			Opts("datadir", "node", "wallet", "word", "integer", "float", "qr"),
 			Precs("help", "list"),
 			Handler(Ctl),
 		),
+		Cmd("fuzzrpc",
+			Pattern("^(fuzzrpc)$"),
+			Short("sends adversarial rpc requests to a full node and reports crashes, hangs and inconsistent error codes"),
+			Detail(`	<datadir> sets the data directory to read the configuration of the node RPC to send requests to from
+		<integer> sets the number of requests to send (default 1000)
+		requests are shaped after the parameters of each command with values at the edges of their types, with a parameter too few or too many, with a parameter of the wrong type, or for a method that does not exist
+		wallet, websocket and notification commands and stop are not sent
+		the findings are printed and written with their requests to fuzzrpc.json in the data directory
+		only run it against a node for testing, as it sends every command including those that change its state`),
+			Opts("datadir", "integer"),
+			Precs("help"),
+			Handler(FuzzRPC),
+		),
+		Cmd("telemetry",
+			Pattern("^(telemetry)$"),
+			Short("shows whether telemetry is reported and the data that is sent"),
+			Detail(`	<datadir> sets the data directory to read the configuration of the node from
+		telemetry is off unless telemetry.enable is set, and is only sent when telemetry.endpoint is also set
+		a report holds the version, network, block height, peer count, OS and architecture of the node and nothing that identifies it
+		the height and peer count are asked of the running node, and shown as 0 when it is not running`),
+			Opts("datadir"),
+			Precs("help"),
+			Handler(Telemetry),
+		),
 		Cmd("node",
 			Pattern("^(n|node)$"),
 			Short("runs a full node"),
-			Detail(`	<datadir> sets the data directory to read configuration and store data`),
-			Opts("datadir"),
-			Precs("help", "ctl"),
-			Handler(Node),
+			Detail(`	<datadir> sets the data directory to read configuration and store data
+		<gencheckpoints> prints checkpoints from the node's own chain for chain.addcheckpoints instead of running it
+		<integer> sets the blocks between generated checkpoints (default 10000)
+		<benchverify> times validating blocks from the node's own chain again instead of running it
+		<exportheaders> writes the headers of the node's own chain to a file instead of running it
+		<importheaders> checks the headers in a file and keeps them for the next sync instead of running it
+		<chaingen> builds the blocks of a scenario into the node's empty chain instead of running it
+		<migrate> prints the migrations the node would apply to its data or rolls them back instead of running it`),
+			Opts("datadir", "gencheckpoints", "integer", "benchverify", "exportheaders", "importheaders", "chaingen", "migrate"),
+			Precs("help", "ctl", "top"),
+			Handler(RunNode),
 		),
 		Cmd("wallet",
 			Pattern("^(w|wallet)$"),
@@ -116,14 +150,15 @@ var nineApp = func() *def.App {
 			Detail(`	<datadir> sets the data directory to read configuration and store data
 		<create> runs the wallet create prompt`),
 			Opts("datadir", "create"),
-			Precs("help", "ctl", "list"),
+			Precs("help", "ctl", "list", "top", "vanity"),
 			Handler(Wallet),
 		),
 		Cmd("shell",
 			Pattern("^(s|shell)$"),
 			Short("runs a combined node/wallet server"),
 			Detail(`	<datadir> sets the data directory to read configuration and store data
-		<create> runs the wallet create prompt`),
+		<create> runs the wallet create prompt
+		with wallet.spv only the wallet runs, syncing from peers in light mode`),
 			Opts("datadir", "create"),
 			Precs("help"),
 			Handler(Shell),
@@ -131,28 +166,117 @@ var nineApp = func() *def.App {
 		Cmd("mine",
 			Pattern("^(m|mine)$"),
 			Short("run the standalone miner"),
-			Detail(``),
-			Opts("datadir"),
+			Detail(`	<datadir> sets the data directory to read configuration from
+		the miner connects to the node at mining.listener using the same mining.pass
+		<bench> measures the hash rate of each algorithm instead of mining
+		<integer> sets the seconds each benchmark runs for (default 10)`),
+			Opts("datadir", "bench", "integer"),
 			Precs("help"),
 			Handler(Mine),
 		),
+		Cmd("bench",
+			Pattern("^(-{0,2}bench)$"),
+			Short("benchmark each mining algorithm on each thread count"),
+			Detail(`	<integer> sets the seconds each benchmark runs for (default 10)`),
+			Opts("integer"),
+			Precs("help", "mine"),
+			Handler(func(args []string, tokens def.Tokens, app *def.App) int { return 0 }),
+		),
+		Cmd("gencheckpoints",
+			Pattern("^(gencheckpoints)$"),
+			Short("print checkpoints for chain.addcheckpoints from the node's own chain"),
+			Detail(`	<integer> sets the blocks between checkpoints (default 10000)
+		checkpoints are picked after the latest one, deep in the chain and away from any forks the node has seen`),
+			Opts("integer"),
+			Precs("help", "node"),
+			Handler(func(args []string, tokens def.Tokens, app *def.App) int { return 0 }),
+		),
+		Cmd("benchverify",
+			Pattern("^(benchverify)$"),
+			Short("time each stage of validating blocks from the node's own chain"),
+			Detail(`	<from> followed by a height sets the first block (default 1)
+		<to> followed by a height sets the last block (default the best block)
+		scripts are validated on one goroutine, on the default three per core and with the signature cache before and after it holds the block's signatures
+		the inputs are restored from the spend journal, so the chain is not changed`),
+			Opts("from", "to"),
+			Precs("help", "node"),
+			Handler(func(args []string, tokens def.Tokens, app *def.App) int { return 0 }),
+		),
+		Cmd("vanity",
+			Pattern("^(vanity)$"),
+			Short("search for a key with an address starting with a prefix"),
+			Detail(`	the prefix follows the vanity keyword, starting as the network's base58 or bech32 addresses do
+		keys are tried on every core, printing the expected time and chance of a match so far
+		<datadir> sets the data directory to read configuration from
+		<wallet> imports the key found into the imported account of the wallet server`),
+			Opts("datadir", "wallet"),
+			Precs("help"),
+			Handler(Vanity),
+		),
+		Cmd("top",
+			Pattern("^(t|top)$"),
+			Short("show a live dashboard of the full node"),
+			Detail(`	<datadir> sets the data directory to read configuration from
+		the dashboard polls the node at rpcconnect over RPC
+		<node> runs the full node in the same process and shows its log
+		<wallet> adds balance, receive, history and send screens using the wallet server
+		<integer> sets the seconds between refreshes (default 2)`),
+			Opts("datadir", "node", "wallet", "integer"),
+			Precs("help"),
+			Handler(Top),
+		),
 		Cmd("gui",
-			Pattern("(^g|gui)$"),
+			Pattern("^(g|gui)$"),
 			Short("run the GUI wallet"),
-			Detail(``),
-			Opts("datadir"),
+			Detail(`	<datadir> sets the data directory to read configuration from
+		serves the web wallet at wallet.gui and opens it in the browser
+		a view only address is printed too, and the only one with wallet.guiviewonly
+		the wallet and node are reached at wallet.server and rpcconnect over RPC
+		the explorer finds any transaction with chain.txindex and address histories with chain.addrindex
+		the config page edits and saves the configuration and restarts to apply it
+		the status page shows whether the wallet uses a full node or light mode (wallet.spv)
+		<node> runs the full node in the same process, unless the wallet is in light mode`),
+			Opts("datadir", "node"),
 			Precs("help"),
 			Handler(GUI),
 		),
-		// Cmd("test",
-		// 	Pattern("^(t|test)$"),
-		// 	Short("run multiple full nodes from given <datadir> logging optionally to <datadir>"),
-		// 	Detail(`	<datadir> indicates the basename to search for as the path to the test configurations
-		// 		<log> indicates to write logs to the individual data directories instead of print to stdout`),
-		// 	Opts("word", "log"),
-		// 	Precs("help"),
-		// 	Handler(TestHandler),
-		// ),
+		Cmd("test",
+			Pattern("^(t|test)$"),
+			Short("run a simnet of full nodes from the data directories named after the basename following test"),
+			Detail(`	the basename is followed by a number in the name of each data directory, such as tn1 tn2 tn3 for tn
+		each node runs in its own process on simnet, listening on 127.0.0.1 on its own p2p and rpc ports
+		the configuration of each data directory is changed to do so, and a mining address is added if it has none
+		<integer> sets the number of nodes to run (default all the data directories found)
+		<topology> sets how the nodes connect to each other, topology=line, topology=star or topology=mesh (default line)
+		<log> indicates to only write the output of each node to test.log in its data directory instead of also printing it
+		blocks are mined on the nodes by typing mine [blocks] [node], status prints their heights and peers and quit stops them`),
+			Opts("integer", "topology", "log"),
+			Precs("help"),
+			Handler(Test),
+		),
+		Cmd("cluster",
+			Pattern("^(cluster)$"),
+			Short("start, stop or print the status of the data directories named after the basename following cluster start, stop or status as a supervised cluster"),
+			Detail(`	the data directories are found and configured as for test, so a cluster and a test network of the same ports can not run at once
+		start runs a supervisor in the background that runs each node in its own process and restarts it when it exits, and returns once the nodes are started
+		the supervisor writes its pid to <basename>.cluster.pid, its output to <basename>.cluster.log and the state of the nodes to <basename>.cluster.json
+		each node writes its pid to cluster.pid and its output to cluster.log in its data directory
+		stop asks the supervisor to stop the nodes and waits for it to exit
+		status prints the pid, uptime, restarts, height and peers of each node and the totals of the cluster
+		<integer> sets the number of nodes to start (default all the data directories found)
+		<topology> sets how the nodes connect to each other, topology=line, topology=star or topology=mesh (default line)`),
+			Opts("integer", "topology"),
+			Precs("help"),
+			Handler(Cluster),
+		),
+		Cmd("topology",
+			Pattern("^(topology=(line|star|mesh))$"),
+			Short("how the nodes of the test and cluster commands connect to each other"),
+			Detail(""),
+			Opts(),
+			Precs("help", "test", "cluster"),
+			Handler(func(args []string, tokens def.Tokens, app *def.App) int { return 0 }),
+		),
 		Cmd("create",
 			Pattern("^(cr|create)$"),
 			Short("runs the create new wallet prompt"),
@@ -191,7 +315,7 @@ var nineApp = func() *def.App {
 			Short("directory to look for configuration or write logs etc"),
 			Detail(`	<datadir> sets the data directory where the wallet will be stored`),
 			Opts(),
-			Precs("help", "node", "ctl", "wallet", "conf", "test", "new", "copy", "shell", "create"),
+			Precs("help", "node", "ctl", "wallet", "conf", "test", "new", "copy", "shell", "create", "vanity"),
 			Handler(func(args []string, tokens def.Tokens, app *def.App) int { return 0 }),
 		),
 		Cmd("integer",
@@ -199,7 +323,7 @@ var nineApp = func() *def.App {
 			Short("number of items to create"),
 			Detail(""),
 			Opts(),
-			Precs("help"),
+			Precs("help", "test", "cluster", "fuzzrpc"),
 			Handler(func(args []string, tokens def.Tokens, app *def.App) int { return 0 }),
 		),
 		Cmd("float",
@@ -210,6 +334,79 @@ var nineApp = func() *def.App {
 			Precs("help"),
 			Handler(func(args []string, tokens def.Tokens, app *def.App) int { return 0 }),
 		),
+		Cmd("migrate",
+			Pattern("^(migrate(=[0-9]+)?)$"),
+			Short("print the plan of the migrations of the node's data, or roll them back"),
+			Detail(`	alone it prints the migrations not yet applied to the data and their steps, which the node applies and logs when it next starts
+		the version of the data is recorded in migrations.json in the data directory
+		with a version following an = it undoes the migrations applied after that version, newest first
+		migrations that removed files or upgraded the utxo sets can not be rolled back, and then none are
+		rolling back the block index upgrade downgrades the block indexes, and rolling back the wallet backup puts it back, setting aside the wallet as it is then`),
+			Opts(),
+			Precs("help", "node"),
+			Handler(func(args []string, tokens def.Tokens, app *def.App) int { return 0 }),
+		),
+		Cmd("exportheaders",
+			Pattern("^(exportheaders(=.+)?)$"),
+			Short("write the headers of the node's own chain to a file for importheaders"),
+			Detail(`	the file follows an = (default headers.dat in the current directory)
+		each header is 48 bytes, leaving out the hash of the block before it`),
+			Opts(),
+			Precs("help", "node"),
+			Handler(func(args []string, tokens def.Tokens, app *def.App) int { return 0 }),
+		),
+		Cmd("importheaders",
+			Pattern("^(importheaders(=.+)?)$"),
+			Short("check the headers in a file from exportheaders and sync the blocks of their chain"),
+			Detail(`	the file follows an = (default headers.dat in the current directory)
+		the proof of work of each header and the checkpoints are checked before the headers are kept
+		the node then downloads the blocks of the headers without downloading the headers from peers first
+		blocks after the last checkpoint among the headers are still fully validated`),
+			Opts(),
+			Precs("help", "node"),
+			Handler(func(args []string, tokens def.Tokens, app *def.App) int { return 0 }),
+		),
+		Cmd("chaingen",
+			Pattern("^(chaingen(=.+)?)$"),
+			Short("build the same blocks from a scenario into the empty chain of a test network node every time"),
+			Detail(`	the scenario file follows an =, with a step on each line:
+		blocks [n] mines n blocks (default 1)
+		mature mines enough blocks for a coinbase to be spendable
+		spend [n] mines a block with n transactions spending mature outputs (default 1)
+		large size mines a block of about size bytes
+		nonstandard [n] mines a block with n pairs of nonstandard transactions (default 1)
+		fork depth length mines a side chain of length blocks on the block depth blocks below the tip, which stays a side chain however long it is, so reorganizations can not be scripted
+		lines starting with # are skipped
+		the blocks pay to a key printed at the end for importing into a wallet
+		indexes are built from the blocks when the node next starts`),
+			Opts("datadir"),
+			Precs("help", "node"),
+			Handler(Node),
+		),
+		Cmd("qr",
+			Pattern("^--qr$"),
+			Short("print the result of a ctl command as a QR code"),
+			Detail(""),
+			Opts(),
+			Precs("help", "ctl"),
+			Handler(func(args []string, tokens def.Tokens, app *def.App) int { return 0 }),
+		),
+		Cmd("from",
+			Pattern("^--from$"),
+			Short("the height of the first block node benchverify validates"),
+			Detail(""),
+			Opts(),
+			Precs("help", "benchverify"),
+			Handler(func(args []string, tokens def.Tokens, app *def.App) int { return 0 }),
+		),
+		Cmd("to",
+			Pattern("^--to$"),
+			Short("the height of the last block node benchverify validates"),
+			Detail(""),
+			Opts(),
+			Precs("help", "benchverify"),
+			Handler(func(args []string, tokens def.Tokens, app *def.App) int { return 0 }),
+		),
 		// Cmd("word",
 		// 	Pattern("^([a-zA-Z0-9][a-zA-Z0-9._-]+)$"),
 		// 	Short("mostly used for testnet datadir basenames"),
@@ -222,6 +419,12 @@ var nineApp = func() *def.App {
 			Dir("appdatadir",
 				Usage("subcommand data directory, sets to datadir/appname if unset"),
 			),
+			Int("ballast",
+				Default(-1),
+				Min(-1),
+				Max(1<<20),
+				Usage("megabytes of ballast making the garbage collector run less often, -1 sizes it from app.memlimit, 0 disables"),
+			),
 			File("cpuprofile",
 				Usage("write cpu profile to this file, empty disables cpu profiling"),
 			),
@@ -229,12 +432,44 @@ var nineApp = func() *def.App {
 				Default("~/.9"),
 				Usage("base folder to keep data for an instance of 9"),
 			),
+			Int("dbcache",
+				Default(0),
+				Min(0),
+				Max(1<<20),
+				Usage("megabytes of block database write cache, 0 sizes it from app.memlimit"),
+			),
+			Int("gcpercent",
+				Default(0),
+				Min(0),
+				Max(1000),
+				Usage("garbage collection target percentage, 0 sets it from app.memlimit"),
+			),
+			Enable("lazyparse",
+				Usage("keep blocks and transactions read from bytes serialized until they are needed, sharing the bytes for hashes and scripts"),
+			),
 			Dir("logdir",
 				Usage("where logs are written, defaults to the appdatadir if unset"),
 			),
+			Int("memlimit",
+				Default(0),
+				Min(0),
+				Max(1<<30),
+				Usage("megabytes of memory the node may use, 0 detects the container or system limit (linux only)"),
+			),
 			Port("profile",
 				Usage("http profiling on specified port (1025-65535)"),
 			),
+			Theme("theme",
+				Default("default"),
+				Usage("color theme of the terminal interfaces: default, highcontrast or monochrome"),
+			),
+			Tag("locale",
+				Usage("language of the locale amounts are written for, such as en or de_DE, grouping their digits and with its decimal mark, unset writes them as the RPC servers do"),
+			),
+			Unit("unit",
+				Default("DUO"),
+				Usage("unit amounts are shown in: MDUO, kDUO, DUO, mDUO, μDUO or Satoshi"),
+			),
 			Enable("upnp",
 				Usage("enable port forwarding via UPNP"),
 			),
@@ -276,6 +511,27 @@ var nineApp = func() *def.App {
 			Enable("disablecheckpoints",
 				Usage("disables checkpoints (danger!)"),
 			),
+			Tag("assumevalid",
+				Usage("hash of a block known to be good, signatures in the blocks up to it are not checked, empty = check all after the checkpoints"),
+			),
+			Enable("fullverify",
+				Usage("check the signatures in all blocks, ignoring assumevalid and checkpoints"),
+			),
+			Enable("dumpinvalid",
+				Usage("write a report of each block that fails validation, with a trace of its failing script, to the invalid directory of the network"),
+			),
+			Tag("assumeutxo",
+				Usage("hash of a utxo snapshot known to be good, which is loaded without maintainer signatures, empty = only load snapshots signed by the maintainers"),
+			),
+			Tag("loadutxoset",
+				Usage("path of a utxo snapshot to start the chain from while it has no blocks, trusted if its hash is assumeutxo or the maintainers signed it in the file of the same path ending in .sig"),
+			),
+			Int("dbcache",
+				Default(0),
+				Min(0),
+				Max(1<<20),
+				Usage("megabytes of unspent outputs kept in memory between database writes, 0 sizes it from app.memlimit"),
+			),
 			Tag("dbtype",
 				Default("ffldb"),
 				Usage("set database backend to use for chain"),
@@ -304,6 +560,7 @@ var nineApp = func() *def.App {
 			),
 		), Group("limit",
 			Tag("pass",
+				Secret(),
 				RandomString(32),
 				Usage("password for limited user"),
 			),
@@ -322,17 +579,90 @@ var nineApp = func() *def.App {
 			Enable("nowrite",
 				Usage("disable writing to log file"),
 			),
+		), Group("mempool",
+			Int("expiryhours",
+				Default(mempool.DefaultExpiryHours),
+				Min(0),
+				Max(100000),
+				Usage("hours a transaction may stay in the mempool before it is expired, 0 to disable"),
+			),
+			Int("maxancestors",
+				Default(mempool.DefaultMaxAncestorCount),
+				Min(1),
+				Max(1000),
+				Usage("max number of unconfirmed ancestors of a transaction including itself"),
+			),
+			Int("maxancestorsize",
+				Default(mempool.DefaultMaxAncestorSize),
+				Min(1000),
+				Max(node.BlockWeightMax),
+				Usage("max total virtual size in bytes of a transaction and its unconfirmed ancestors"),
+			),
+			Int("maxdescendants",
+				Default(mempool.DefaultMaxDescendantCount),
+				Min(1),
+				Max(1000),
+				Usage("max number of unconfirmed descendants of a transaction including itself"),
+			),
+			Int("maxdescendantsize",
+				Default(mempool.DefaultMaxDescendantSize),
+				Min(1000),
+				Max(node.BlockWeightMax),
+				Usage("max total virtual size in bytes of a transaction and its unconfirmed descendants"),
+			),
+			Int("maxmegabytes",
+				Default(mempool.DefaultMaxPoolSizeMB),
+				Min(0),
+				Max(100000),
+				Usage("max total virtual size of the mempool in megabytes, lowest fee rate transactions are evicted above it, 0 to disable"),
+			),
+			Int("webhookretries",
+				Default(node.DefaultWebhookRetries),
+				Min(0),
+				Max(100),
+				Usage("number of times a failed webhook delivery is retried with exponential backoff"),
+			),
+			Tags("webhooks",
+				Usage("URLs to POST mempool transaction events (accepted, replaced, evicted, mined) and chain reorganizations to as JSON, space separated"),
+			),
+			Tag("webhooksecret",
+				Secret(),
+				Usage("key used to sign webhook requests with HMAC-SHA256 in the X-Webhook-Signature header"),
+			),
 		), Group("mining",
 			Tags("addresses",
-				Usage("set mining addresses, space separated"),
+				Usage("set mining addresses as address or address:weight, space separated"),
+			),
+			Enabled("advertise",
+				Usage("advertise the miner listeners by multicast DNS so miners on the LAN find them without mining.listener set"),
 			),
 			Algo("algo",
 				Default("random"),
 				Usage("select from available mining algorithms"),
 			),
+			Tag("apikey",
+				Secret(),
+				Usage("API key the miner connects to the node with as name:secret, instead of the mining password"),
+			),
+			Tags("apikeys",
+				Secret(),
+				Usage("API keys miner workers can connect with as name:secret or name:secret:address,address to restrict where from, space separated"),
+			),
 			Float("bias",
 				Default(-0.5),
-				Usage("bias for difficulties -1 = always easy, 1 always hardest"),
+				Usage("bias for difficulties when algo is random, -1 = always easy, 0 = any, 1 always hardest"),
+			),
+			Tag("coinbasetag",
+				Usage("text added to the coinbase of mined blocks to mark them, at most 74 bytes"),
+			),
+			Tags("controllerkeys",
+				Usage("identity keys of the nodes a miner trusts in hex as logged by their miner controller, space separated, empty = the node of the mining password"),
+			),
+			Tags("cores",
+				Usage("processor cores to pin builtin CPU miner threads to in turn, space separated, empty = any (linux only)"),
+			),
+			Enable("encrypt",
+				Usage("encrypt mining dispatch messages, on the node for all workers or on a miner for its own connection"),
 			),
 			Enable("generate",
 				Usage("enable builtin CPU miner"),
@@ -344,15 +674,39 @@ var nineApp = func() *def.App {
 				Usage("set number of threads, -1 = all"),
 			),
 			Addrs("listener", 11045,
-				Usage("set listener address for mining dispatcher"),
+				Usage("set listener address for mining dispatcher, prefixed with quic:// to accept miners over QUIC or fec:// over FEC coded UDP instead of TCP"),
+			),
+			Tag("multicast",
+				Usage("multicast group or broadcast address the node announces new work on to miners on its LAN, set on miners to receive work from it instead of their own connection"),
+			),
+			Int("nice",
+				Default(0),
+				Min(0),
+				Max(19),
+				Usage("how much to lower the scheduling priority of builtin CPU miner threads, 0-19 (linux only)"),
 			),
 			Tag("pass",
+				Secret(),
 				RandomString(32),
 				Usage("password to secure mining dispatch connections"),
 			),
+			Tags("stratum",
+				Usage("stratum v1 listeners for standard miners as algo:address, or address for miners of mining.algo, space separated"),
+			),
+			Tags("stratumdiff",
+				Usage("initial stratum share difficulty per algorithm as algo:difficulty, space separated"),
+			),
+			Tag("rotation",
+				Default("random"),
+				Usage("how the address each block pays to is chosen from the mining addresses: random, roundrobin or weighted"),
+			),
+			Duration("stratumsharetime",
+				Default(stratum.DefaultShareTime),
+				Usage("time between shares stratum variable difficulty aims for, 0 to disable"),
+			),
 			Duration("switch",
 				Default(time.Second*2),
-				Usage("maximum time to mine per round"),
+				Usage("maximum time to mine one algorithm per round when algo is random"),
 			),
 		), Group("p2p",
 			Addrs("addpeer", 11047,
@@ -369,6 +723,10 @@ var nineApp = func() *def.App {
 			Enable("disableban",
 				Usage("disables banning peers"),
 			),
+			Float("dustrelayfee",
+				Default(0.0001),
+				Usage("fee rate in DUO/kB used to decide whether a transaction output is dust, for relay and for outputs created by the wallet"),
+			),
 			Enable("blocksonly",
 				Usage("relay only blocks"),
 			),
@@ -381,6 +739,9 @@ var nineApp = func() *def.App {
 			Addrs("externalips", 11047,
 				Usage("additional external IP addresses to bind to"),
 			),
+			Enable("feerateonly",
+				Usage("relay and mine transactions by fee rate only, without free or high priority transaction space (always on for networks that require it)"),
+			),
 			Float("freetxrelaylimit",
 				Default(15.0),
 				Usage("limit of 'free' relay in thousand bytes per minute"),
@@ -389,12 +750,21 @@ var nineApp = func() *def.App {
 				Default("127.0.0.1:11047"),
 				Usage("addresss to listen on for p2p connections"),
 			),
+			Enable("mempoolreplacement",
+				Usage("accept transactions signalling BIP125 replace-by-fee in place of the ones they conflict with"),
+			),
 			Int("maxorphantxs",
 				Default(node.DefaultMaxOrphanTransactions),
 				Min(0),
 				Max(10000),
 				Usage("maximum number of orphan transactions to keep in memory"),
 			),
+			Int("maxorphantxsperpeer",
+				Default(mempool.DefaultMaxOrphanTxsPerPeer),
+				Min(0),
+				Max(10000),
+				Usage("maximum number of orphan transactions relayed by a single peer to keep in memory, 0 for no limit"),
+			),
 			Int("maxpeers",
 				Default(node.DefaultMaxPeers),
 				Min(2),
@@ -424,6 +794,10 @@ var nineApp = func() *def.App {
 			Enable("norelaypriority",
 				Usage("disables prioritisation of relayed transactions"),
 			),
+			Duration("orphanexpiry",
+				Default(mempool.DefaultOrphanTTL),
+				Usage("how long an orphan transaction is kept while waiting for its parents"),
+			),
 			Duration("trickleinterval",
 				Default(time.Second*27),
 				Usage("minimum time between attempts to send new inventory to a connected peer"),
@@ -443,6 +817,7 @@ var nineApp = func() *def.App {
 				Usage("enable randomisation of tor login to separate streams"),
 			),
 			Tag("pass",
+				Secret(),
 				RandomString(32),
 				Usage("password for proxy"),
 			),
@@ -484,6 +859,7 @@ var nineApp = func() *def.App {
 				Usage("maximum websockets clients"),
 			),
 			Tag("pass",
+				Secret(),
 				RandomString(32),
 				Usage("password for rpc services"),
 			),
@@ -495,6 +871,18 @@ var nineApp = func() *def.App {
 				Usage("username for rpc services"),
 			),
 		),
+		Group("telemetry",
+			Enable("enable",
+				Usage("opt in to periodically reporting the version, network, block height, peer count and OS/arch of the node to telemetry.endpoint, see the telemetry command for what is sent"),
+			),
+			Tag("endpoint",
+				Usage("URL to POST telemetry reports to as JSON"),
+			),
+			Duration("interval",
+				Default(node.DefaultTelemetryInterval),
+				Usage("time between telemetry reports, at least a minute"),
+			),
+		),
 		Group("tls",
 			File("key",
 				Default("tls.key"),
@@ -521,21 +909,47 @@ var nineApp = func() *def.App {
 				Usage("skip verifying tls certificates with CAFile"),
 			),
 		),
+		Group("update",
+			Enable("enable",
+				Usage("periodically check update.url for a release manifest signed by the maintainers, and log and report by getupdateinfo when there is a newer release"),
+			),
+			Tag("url",
+				Usage("URL of the signed release manifest"),
+			),
+			Duration("interval",
+				Default(node.DefaultUpdateInterval),
+				Usage("time between checks for a new release, at least a minute"),
+			),
+		),
 		Group("wallet",
 			Addr("server", 11046,
 				Default("127.0.0.1:11046"),
 				Usage("address of wallet rpc to connect to"),
 			),
+			Addr("gui", 11049,
+				Default("127.0.0.1:11049"),
+				Usage("address the gui command serves the web wallet on"),
+			),
+			Enable("guiviewonly",
+				Usage("the web wallet can only show the wallet and make addresses, not send"),
+			),
 			Enable("noinitialload",
 				Usage("disable automatic opening of the wallet at startup"),
 			),
 			Tag("pass",
+				Secret(),
 				// RandomString(32),
 				Usage("password for the non-own transaction data in the wallet"),
 			),
 			Enable("enable",
 				Usage("use configured wallet rpc instead of full node"),
 			),
+			Enable("spv",
+				Usage("light mode: sync the wallet from peers with compact block filters instead of a full node, trusting them not to hide transactions"),
+			),
+			Enable("rbf",
+				Usage("signal BIP125 replace-by-fee in the transactions the wallet sends, so bumpfee can raise their fee while they are unconfirmed"),
+			),
 		),
 	)
 }
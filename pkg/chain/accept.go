@@ -58,6 +58,7 @@ func (b *BlockChain) maybeAcceptBlock(block *util.Block, flags BehaviorFlags) (b
 	// Create a new block node for the block and add it to the node index. Even if the block ultimately gets connected to the main chain, it starts out on a side chain.
 	blockHeader := &block.MsgBlock().Header
 	newNode := newBlockNode(blockHeader, prevNode)
+	newNode.versionBits = CoinbaseVersionBits(block.Transactions()[0])
 	newNode.status = statusDataStored
 	b.Index.AddNode(newNode)
 	err = b.Index.flushToDB()
@@ -0,0 +1,37 @@
+package chain
+import (
+	chainhash "git.parallelcoin.io/dev/9/pkg/chain/hash"
+)
+// AssumeValid returns the hash of the block whose ancestors' scripts are not run, or nil when there is none or full verification is on. This function is safe for concurrent access.
+func (b *BlockChain) AssumeValid() *chainhash.Hash {
+	if b.fullVerify {
+		return nil
+	}
+	return b.assumeValid
+}
+// AssumeValidChain records the hashes of blocks that are ancestors of the assume valid block, from headers that were checked to link up to it, so that their scripts are not run when they are connected before the assume valid block itself is known. This function is safe for concurrent access.
+func (b *BlockChain) AssumeValidChain(hashes []*chainhash.Hash) {
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+	if b.assumeValidChain == nil {
+		b.assumeValidChain = make(map[chainhash.Hash]struct{}, len(hashes))
+	}
+	for _, hash := range hashes {
+		b.assumeValidChain[*hash] = struct{}{}
+	}
+}
+// assumedValid returns whether the node is the assume valid block or one of its ancestors, so that its scripts need not be run. Once the assume valid block is in the index its ancestors are found from it, and the hashes from AssumeValidChain are no longer needed. This function MUST be called with the chain state lock held (for writes).
+func (b *BlockChain) assumedValid(
+	node *blockNode) bool {
+	if b.AssumeValid() == nil {
+		return false
+	}
+	if _, ok := b.assumeValidChain[node.hash]; ok {
+		if node.hash.IsEqual(b.assumeValid) {
+			b.assumeValidChain = nil
+		}
+		return true
+	}
+	avNode := b.Index.LookupNode(b.assumeValid)
+	return avNode != nil && avNode.Ancestor(node.height) == node
+}
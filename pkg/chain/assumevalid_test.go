@@ -0,0 +1,49 @@
+package chain
+import (
+	"testing"
+	chaincfg "git.parallelcoin.io/dev/9/pkg/chain/config"
+	chainhash "git.parallelcoin.io/dev/9/pkg/chain/hash"
+)
+// TestAssumedValid ensures the scripts are skipped for the assume valid block and its ancestors only, whether they are found from the block index or from the hashes of its headers.
+func TestAssumedValid(
+	t *testing.T) {
+	chain := newFakeChain(&chaincfg.MainNetParams)
+	mainNodes := chainedNodes(chain.bestChain.Genesis(), 10)
+	sideNodes := chainedNodes(mainNodes[3], 5)
+	for _, node := range append(mainNodes, sideNodes...) {
+		chain.Index.AddNode(node)
+	}
+	if chain.assumedValid(mainNodes[5]) {
+		t.Fatal("block assumed valid without an assume valid block")
+	}
+	chain.assumeValid = &mainNodes[7].hash
+	for i, want := range []bool{true, true, true, true, true, true, true, true, false, false} {
+		if got := chain.assumedValid(mainNodes[i]); got != want {
+			t.Errorf("main chain block %d assumed valid %v, want %v", i, got, want)
+		}
+	}
+	if chain.assumedValid(sideNodes[0]) {
+		t.Error("side chain block assumed valid")
+	}
+	chain.fullVerify = true
+	if chain.assumedValid(mainNodes[5]) || chain.AssumeValid() != nil {
+		t.Error("block assumed valid with full verification")
+	}
+	chain.fullVerify = false
+	// Blocks after the tip of the index are known to be ancestors only from the headers up to the assume valid block.
+	aheadNodes := chainedNodes(tstTip(mainNodes), 3)
+	chain.assumeValid = &aheadNodes[1].hash
+	if chain.assumedValid(aheadNodes[0]) {
+		t.Error("block assumed valid before its headers were known")
+	}
+	chain.AssumeValidChain([]*chainhash.Hash{&aheadNodes[0].hash, &aheadNodes[1].hash})
+	if !chain.assumedValid(aheadNodes[0]) || !chain.assumedValid(aheadNodes[1]) {
+		t.Error("block with known headers not assumed valid")
+	}
+	if chain.assumeValidChain != nil {
+		t.Error("headers kept after the assume valid block")
+	}
+	if chain.assumedValid(aheadNodes[2]) {
+		t.Error("block after the assume valid block assumed valid")
+	}
+}
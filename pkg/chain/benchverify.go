@@ -0,0 +1,204 @@
+package chain
+import (
+	"fmt"
+	"time"
+	"git.parallelcoin.io/dev/9/pkg/chain/fork"
+	txscript "git.parallelcoin.io/dev/9/pkg/chain/tx/script"
+	database "git.parallelcoin.io/dev/9/pkg/db"
+	"git.parallelcoin.io/dev/9/pkg/util"
+)
+// The stages of block validation BenchVerify times. The script stages validate the same scripts in different ways, and the sigcache stages share one signature cache, which the first of them fills as validating blocks does on a node that has not seen their transactions before and which the second hits as it does on a node that accepted them to its mempool.
+const (
+	BenchStageLoad             = "load"
+	BenchStageSanity           = "sanity"
+	BenchStageContext          = "context"
+	BenchStageInputs           = "inputs"
+	BenchStageScriptsSerial    = "scripts serial"
+	BenchStageScriptsParallel  = "scripts parallel"
+	BenchStageScriptsSigCache  = "scripts parallel, new sigcache"
+	BenchStageScriptsSigCached = "scripts parallel, filled sigcache"
+)
+// BenchStages are the stages of block validation BenchVerify times, in the order they run for each block.
+var BenchStages = []string{
+	BenchStageLoad,
+	BenchStageSanity,
+	BenchStageContext,
+	BenchStageInputs,
+	BenchStageScriptsSerial,
+	BenchStageScriptsParallel,
+	BenchStageScriptsSigCache,
+	BenchStageScriptsSigCached,
+}
+// VerifyBenchmark is how long validating a range of blocks of the main chain took in each stage.
+type VerifyBenchmark struct {
+	Blocks       int
+	Transactions int
+	Inputs       int
+	Stages       map[string]time.Duration
+}
+// BenchVerify validates the blocks of the main chain from height from to height to again, with their proof of work and all of their scripts whatever the checkpoints and the assumed valid block, and returns how long each stage of it took.  The inputs of each block are restored from the spend journal, so the utxo set is neither read nor changed, and the scripts of each block are validated serially and in parallel without a signature cache, and in parallel with one sigCacheSize entries large before and after it holds the signatures of the block. It holds the chain lock for the whole range, so it is meant for a chain that is not running. This function is safe for concurrent access.
+func (
+	b *BlockChain,
+) BenchVerify(
+	from, to int32, sigCacheSize uint, interrupt <-chan struct{}) (*VerifyBenchmark, error) {
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+	if from < 1 || to < from || to > b.bestChain.Height() {
+		return nil, fmt.Errorf("block range %d to %d is not within the main chain heights 1 to %d",
+			from, to, b.bestChain.Height())
+	}
+	bench := &VerifyBenchmark{Stages: make(map[string]time.Duration, len(BenchStages))}
+	sigCache := txscript.NewSigCache(sigCacheSize)
+	hashCache := txscript.NewHashCache(sigCacheSize)
+	for height := from; height <= to; height++ {
+		if interruptRequested(interrupt) {
+			return nil, errInterruptRequested
+		}
+		node := b.bestChain.NodeByHeight(height)
+		var block *util.Block
+		var stxos []SpentTxOut
+		start := time.Now()
+		err := b.db.View(func(dbTx database.Tx) error {
+			var err error
+			if block, err = dbFetchBlockByNode(dbTx, node); err != nil {
+				return err
+			}
+			stxos, err = dbFetchSpendJournalEntry(dbTx, block)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		bench.Stages[BenchStageLoad] += time.Since(start)
+		start = time.Now()
+		powLimit := fork.GetMinDiff(fork.GetAlgoName(node.version, height), height)
+		err = checkBlockSanity(block, powLimit, b.timeSource, BFNone, false, height)
+		if err != nil {
+			return nil, err
+		}
+		bench.Stages[BenchStageSanity] += time.Since(start)
+		start = time.Now()
+		if err = b.checkBlockContext(block, node.parent, BFNone, false); err != nil {
+			return nil, err
+		}
+		bench.Stages[BenchStageContext] += time.Since(start)
+		start = time.Now()
+		view, err := b.benchConnectBlock(node, block, stxos)
+		if err != nil {
+			return nil, err
+		}
+		bench.Stages[BenchStageInputs] += time.Since(start)
+		scriptFlags, err := b.blockScriptFlags(node)
+		if err != nil {
+			return nil, err
+		}
+		scriptRuns := []struct {
+			stage     string
+			workers   int
+			sigCache  *txscript.SigCache
+			hashCache *txscript.HashCache
+		}{
+			{BenchStageScriptsSerial, 1, nil, nil},
+			{BenchStageScriptsParallel, 0, nil, nil},
+			{BenchStageScriptsSigCache, 0, sigCache, hashCache},
+			{BenchStageScriptsSigCached, 0, sigCache, hashCache},
+		}
+		for _, run := range scriptRuns {
+			start = time.Now()
+			err = checkBlockScriptsWorkers(block, view, scriptFlags, run.sigCache, run.hashCache, run.workers)
+			if err != nil {
+				return nil, err
+			}
+			bench.Stages[run.stage] += time.Since(start)
+		}
+		bench.Blocks++
+		bench.Transactions += len(block.Transactions())
+		bench.Inputs += len(stxos)
+	}
+	return bench, nil
+}
+// benchConnectBlock performs the checks checkConnectBlock does on the inputs of the block of the node against the outputs it spent, restored from its spend journal entry, and returns the view they were connected in, for the scripts of the block to be validated with. This function MUST be called with the chain state lock held (for writes).
+func (
+	b *BlockChain,
+) benchConnectBlock(
+	node *blockNode, block *util.Block, stxos []SpentTxOut) (*UtxoViewpoint, error) {
+	view := NewUtxoViewpoint()
+	view.SetBestHash(&node.parent.hash)
+	// The spend journal holds the outputs spent by the inputs of the transactions after the coinbase in order.
+	stxoIdx := 0
+	for _, tx := range block.Transactions()[1:] {
+		for _, txIn := range tx.MsgTx().TxIn {
+			if stxoIdx >= len(stxos) {
+				return nil, AssertError(fmt.Sprintf(
+					"spend journal entry of block %v has %d outputs, fewer than its inputs",
+					node.hash, len(stxos),
+				))
+			}
+			stxo := &stxos[stxoIdx]
+			stxoIdx++
+			entry := &UtxoEntry{
+				amount:      stxo.Amount,
+				pkScript:    stxo.PkScript,
+				blockHeight: stxo.Height,
+			}
+			if stxo.IsCoinBase {
+				entry.packedFlags |= tfCoinBase
+			}
+			view.entries[txIn.PreviousOutPoint] = entry
+		}
+	}
+	enforceBIP0016 := node.timestamp >= txscript.Bip16Activation.Unix()
+	scriptFlags, err := b.blockScriptFlags(node)
+	if err != nil {
+		return nil, err
+	}
+	enforceSegWit := scriptFlags&txscript.ScriptVerifyWitness == txscript.ScriptVerifyWitness
+	totalSigOpCost := 0
+	for i, tx := range block.Transactions() {
+		sigOpCost, err := GetSigOpCost(tx, i == 0, view, enforceBIP0016, enforceSegWit)
+		if err != nil {
+			return nil, err
+		}
+		totalSigOpCost += sigOpCost
+		if totalSigOpCost > MaxBlockSigOpsCost {
+			str := fmt.Sprintf("block contains too many signature operations - got %v, max %v",
+				totalSigOpCost, MaxBlockSigOpsCost)
+			return nil, ruleError(ErrTooManySigOps, str)
+		}
+	}
+	var totalFees int64
+	for _, tx := range block.Transactions() {
+		txFee, err := CheckTransactionInputs(tx, node.height, view, b.chainParams)
+		if err != nil {
+			return nil, err
+		}
+		totalFees += txFee
+		if err = view.connectTransaction(tx, node.height, nil); err != nil {
+			return nil, err
+		}
+	}
+	if scriptFlags&txscript.ScriptVerifyCheckSequenceVerify == txscript.ScriptVerifyCheckSequenceVerify {
+		medianTime := node.parent.CalcPastMedianTime()
+		for _, tx := range block.Transactions() {
+			sequenceLock, err := b.calcSequenceLock(node, tx, view, false)
+			if err != nil {
+				return nil, err
+			}
+			if !SequenceLockActive(sequenceLock, node.height, medianTime) {
+				return nil, ruleError(ErrUnfinalizedTx,
+					"block contains transaction whose input sequence locks are not met")
+			}
+		}
+	}
+	var totalSatoshiOut int64
+	for _, txOut := range block.Transactions()[0].MsgTx().TxOut {
+		totalSatoshiOut += txOut.Value
+	}
+	expectedSatoshiOut := CalcBlockSubsidy(node.height, b.chainParams) + totalFees
+	if totalSatoshiOut > expectedSatoshiOut {
+		str := fmt.Sprintf("coinbase transaction for block pays %v which is more than expected value of %v",
+			totalSatoshiOut, expectedSatoshiOut)
+		return nil, ruleError(ErrBadCoinbaseValue, str)
+	}
+	return view, nil
+}
@@ -55,8 +55,10 @@ type blockNode struct {
 	version    int32
 	bits       uint32
 	nonce      uint32
-	timestamp  int64
-	merkleRoot chainhash.Hash
+	// versionBits are the version bits the block signals in its coinbase, as its version identifies its proof of work algorithm, which count instead of those of its version from the CoinbaseBitsHeight of the chain parameters. They are zero for a block whose data is not stored, such as those below a utxo snapshot.
+	versionBits uint32
+	timestamp   int64
+	merkleRoot  chainhash.Hash
 	// status is a bitfield representing the validation state of the block. The status field, unlike the other fields, may be written to and so should only be accessed using the concurrent-safe NodeStatus method on blockIndex once the node has been added to the global index.
 	status blockStatus
 }
@@ -66,6 +66,10 @@ type BlockChain struct {
 	sigCache            *txscript.SigCache
 	indexManager        IndexManager
 	hashCache           *txscript.HashCache
+	assumeValid         *chainhash.Hash
+	fullVerify          bool
+	utxoCache           *utxoCache
+	invalidBlockDir     string
 	// The following fields are calculated based upon the provided chain parameters.  They are also set when the instance is created and can't be changed afterwards, so there is no need to protect them with
 	// a separate mutex.
 	minRetargetTimespan int64 // target timespan / adjustment factor
@@ -86,6 +90,12 @@ type BlockChain struct {
 	// These fields are related to checkpoint handling.  They are protected by the chain lock.
 	nextCheckpoint *chaincfg.Checkpoint
 	checkpointNode *blockNode
+	// utxoSnapshot describes the utxo snapshot the chain state was loaded from, or is nil if it was built from every block.  It is protected by the chain lock.
+	utxoSnapshot *UtxoSnapshot
+	// snapshotValidation is how far the blocks before the utxo snapshot have been validated, or is nil when there is no snapshot or the utxo set built from its blocks was found to match it.  It is protected by the chain lock.
+	snapshotValidation *snapshotValidation
+	// assumeValidChain holds the hashes of ancestors of the assume valid block learned from its headers before it is in the index.  It is protected by the chain lock.
+	assumeValidChain map[chainhash.Hash]struct{}
 	// The state is used as a fairly efficient way to cache information about the current best chain state that is returned to callers when requested.  It operates on the principle of MVCC such that any time a new block becomes the best block, the state pointer is replaced with a new struct and the old state is left untouched.  In this way, multiple callers can be pointing to different best chain states. This is acceptable for most callers because the state is only being queried at a specific point in time. In addition, some of the fields are stored in the database so the chain state can be quickly reconstructed on load.
 	stateLock     sync.RWMutex
 	stateSnapshot *BestState
@@ -382,12 +392,6 @@ func (b *BlockChain) connectBlock(node *blockNode, block *util.Block,
 			log <- cl.Trace{"dbPutBlockIndex", err}
 			return err
 		}
-		// update the utxo set using the state of the utxo view.  This entails removing all of the utxos spent and adding the new ones created by the block.
-		err = dbPutUtxoView(dbTx, view)
-		if err != nil {
-			log <- cl.Trace{"dbPutUtxoView", err}
-			return err
-		}
 		// Update the transaction spend journal by adding a record for the block that contains all txos spent by it.
 		err = dbPutSpendJournalEntry(dbTx, block.Hash(), stxos)
 		if err != nil {
@@ -408,7 +412,9 @@ func (b *BlockChain) connectBlock(node *blockNode, block *util.Block,
 		log <- cl.Trace{"error updating database ", err}
 		return err
 	}
-	// Prune fully spent entries and mark all entries in the view unmodified now that the modifications have been committed to the database.
+	// Update the utxo set using the state of the utxo view.  This entails removing all of the utxos spent and adding the new ones created by the block, in the utxo cache, which writes them to the database later.
+	b.utxoCache.commit(view)
+	// Prune fully spent entries and mark all entries in the view unmodified now that the modifications have been committed.
 	view.commit()
 	// This node is now the end of the best chain.
 	b.bestChain.SetTip(node)
@@ -416,6 +422,10 @@ func (b *BlockChain) connectBlock(node *blockNode, block *util.Block,
 	b.stateLock.Lock()
 	b.stateSnapshot = state
 	b.stateLock.Unlock()
+	// A failed flush leaves the changes in the cache for the next one, and the blocks after the utxo set in the database are replayed at startup, so the block stays connected.
+	if err := b.utxoCache.maybeFlush(); err != nil {
+		log <- cl.Error{"failed to flush the utxo cache:", err}
+	}
 	// Notify the caller that the block was connected to the main chain. The caller would typically want to react with actions such as updating wallets.
 	b.chainLock.Unlock()
 	b.sendNotification(NTBlockConnected, block)
@@ -430,6 +440,10 @@ func (b *BlockChain) disconnectBlock(node *blockNode, block *util.Block, view *U
 			"disconnectBlock must be called with the block at the end of the main chain",
 		)
 	}
+	// The utxo set in the database must be at this block, as the changes are written to it directly along with the new best state.
+	if err := b.utxoCache.flush(false); err != nil {
+		return err
+	}
 	// Load the previous block since some details for it are needed below.
 	prevNode := node.parent
 	var prevBlock *util.Block
@@ -468,7 +482,11 @@ func (b *BlockChain) disconnectBlock(node *blockNode, block *util.Block, view *U
 			return err
 		}
 		// Update the utxo set using the state of the utxo view.  This entails restoring all of the utxos spent and removing the new ones created by the block.
-		err = dbPutUtxoView(dbTx, view)
+		err = dbPutUtxoView(dbTx, utxoSetBucketName, view)
+		if err != nil {
+			return err
+		}
+		err = dbPutUtxoState(dbTx, &prevNode.hash)
 		if err != nil {
 			return err
 		}
@@ -494,6 +512,7 @@ func (b *BlockChain) disconnectBlock(node *blockNode, block *util.Block, view *U
 	if err != nil {
 		return err
 	}
+	b.utxoCache.commitFlushed(view)
 	// Prune fully spent entries and mark all entries in the view unmodified now that the modifications have been committed to the database.
 	view.commit()
 	// This node's parent is now the end of the best chain.
@@ -544,6 +563,10 @@ func (b *BlockChain) reorganizeChain(detachNodes, attachNodes *list.List) error
 				&firstAttachNode.parent.hash, &lastDetachNode.parent.hash))
 		}
 	}
+	// Write the utxo cache to the database first, as disconnecting blocks looks up the outputs of spent transactions there.
+	if err := b.utxoCache.flush(false); err != nil {
+		return err
+	}
 	// Track the old and new best chains heads.
 	oldBest := tip
 	newBest := tip
@@ -572,7 +595,7 @@ func (b *BlockChain) reorganizeChain(detachNodes, attachNodes *list.List) error
 			))
 		}
 		// Load all of the utxos referenced by the block that aren't already in the view.
-		err = view.fetchInputUtxos(b.db, block)
+		err = view.fetchInputUtxos(b.utxoCache, block)
 		if err != nil {
 			return err
 		}
@@ -618,7 +641,7 @@ func (b *BlockChain) reorganizeChain(detachNodes, attachNodes *list.List) error
 		// checkConnectBlock gets skipped, we still need to update the UTXO
 		// view.
 		if b.Index.NodeStatus(n).KnownValid() {
-			err = view.fetchInputUtxos(b.db, block)
+			err = view.fetchInputUtxos(b.utxoCache, block)
 			if err != nil {
 				return err
 			}
@@ -645,6 +668,13 @@ func (b *BlockChain) reorganizeChain(detachNodes, attachNodes *list.List) error
 		b.Index.SetStatusFlags(n, statusValid)
 		newBest = n
 	}
+	// Journal the reorganization before the chain state is changed, so that it is finished or undone when the chain is next loaded if it stops partway through.
+	err := b.db.Update(func(dbTx database.Tx) error {
+		return dbPutChainJournal(dbTx, chainJournalKeyName, &chainJournal{from: oldBest.hash, to: newBest.hash})
+	})
+	if err != nil {
+		return err
+	}
 	// Reset the view for the actual connection code below.  This is required because the view was previously modified when checking if the reorg would be successful and the connection code requires the view to be valid from the viewpoint of each block being connected or disconnected.
 	view = NewUtxoViewpoint()
 	view.SetBestHash(&b.bestChain.Tip().hash)
@@ -653,7 +683,7 @@ func (b *BlockChain) reorganizeChain(detachNodes, attachNodes *list.List) error
 		n := e.Value.(*blockNode)
 		block := detachBlocks[i]
 		// Load all of the utxos referenced by the block that aren't already in the view.
-		err := view.fetchInputUtxos(b.db, block)
+		err := view.fetchInputUtxos(b.utxoCache, block)
 		if err != nil {
 			return err
 		}
@@ -674,7 +704,7 @@ func (b *BlockChain) reorganizeChain(detachNodes, attachNodes *list.List) error
 		n := e.Value.(*blockNode)
 		block := attachBlocks[i]
 		// Load all of the utxos referenced by the block that aren't already in the view.
-		err := view.fetchInputUtxos(b.db, block)
+		err := view.fetchInputUtxos(b.utxoCache, block)
 		if err != nil {
 			return err
 		}
@@ -690,6 +720,12 @@ func (b *BlockChain) reorganizeChain(detachNodes, attachNodes *list.List) error
 			return err
 		}
 	}
+	err = b.db.Update(func(dbTx database.Tx) error {
+		return dbRemoveChainJournal(dbTx, chainJournalKeyName)
+	})
+	if err != nil {
+		return err
+	}
 	// Log the point where the chain forked and old and new best chain
 	// heads.
 	if forkNode != nil {
@@ -709,6 +745,29 @@ func (b *BlockChain) reorganizeChain(detachNodes, attachNodes *list.List) error
 		newBest.hash,
 		newBest.height,
 	}
+	if forkNode == nil {
+		return nil
+	}
+	// Notify the caller of the reorganization as a whole, so that it need not be pieced together from the blocks that were disconnected and connected.
+	reorg := &Reorganization{
+		OldTip:       oldBest.hash,
+		OldHeight:    oldBest.height,
+		NewTip:       newBest.hash,
+		NewHeight:    newBest.height,
+		Fork:         forkNode.hash,
+		ForkHeight:   forkNode.height,
+		Disconnected: make([]chainhash.Hash, 0, detachNodes.Len()),
+		Connected:    make([]chainhash.Hash, 0, attachNodes.Len()),
+	}
+	for e := detachNodes.Front(); e != nil; e = e.Next() {
+		reorg.Disconnected = append(reorg.Disconnected, e.Value.(*blockNode).hash)
+	}
+	for e := attachNodes.Front(); e != nil; e = e.Next() {
+		reorg.Connected = append(reorg.Connected, e.Value.(*blockNode).hash)
+	}
+	b.chainLock.Unlock()
+	b.sendNotification(NTChainReorganized, reorg)
+	b.chainLock.Lock()
 	return nil
 }
 // connectBestChain handles connecting the passed block to the chain while respecting proper chain selection according to the chain with the most proof of work.  In the typical case, the new block simply extends the main chain.  However, it may also be extending (or creating) a side chain (fork) which may or may not end up becoming the main chain depending on which fork cumulatively has the most proof of work.  It returns whether or not the block ended up on the main chain (either due to extending the main chain or causing a reorganization to become the main chain). The flags modify the behavior of this function as follows:
@@ -751,7 +810,7 @@ func (b *BlockChain) connectBestChain(node *blockNode, block *util.Block, flags
 		}
 		// In the fast add case the code to check the block connection was skipped, so the utxo view needs to load the referenced utxos, spend them, and add the new utxos being created by this block.
 		if fastAdd {
-			err := view.fetchInputUtxos(b.db, block)
+			err := view.fetchInputUtxos(b.utxoCache, block)
 			if err != nil {
 				return false, err
 			}
@@ -1115,6 +1174,14 @@ type Config struct {
 	IndexManager IndexManager
 	// HashCache defines a transaction hash mid-state cache to use when validating transactions. This cache has the potential to greatly speed up transaction validation as re-using the pre-calculated mid-state eliminates the O(N^2) validation complexity due to the SigHashAll flag. This field can be nil if the caller is not interested in using a signature cache.
 	HashCache *txscript.HashCache
+	// AssumeValid is the hash of a block known to be good, whose ancestors have their scripts not run when they are connected, as is done for blocks before the latest checkpoint.  Everything else in those blocks is still checked. This field can be nil to run the scripts of all blocks after the latest checkpoint.
+	AssumeValid *chainhash.Hash
+	// FullVerify runs the scripts of every block, ignoring AssumeValid and the checkpoints.
+	FullVerify bool
+	// UtxoCacheSize is about how many bytes of utxos are held in memory, and of changes to them, before they are written to the database.  Zero writes the changes of every block as it is connected.
+	UtxoCacheSize uint64
+	// InvalidBlockDir is the directory a report of each block that fails validation is written to, for diagnosing consensus failures. This field can be empty to write none.
+	InvalidBlockDir string
 }
 // New returns a BlockChain instance using the provided configuration details.
 func New(
@@ -1161,6 +1228,10 @@ func New(
 		blocksPerRetarget:     int32(targetTimespan / targetTimePerBlock),
 		Index:                 newBlockIndex(config.DB, params),
 		hashCache:             config.HashCache,
+		assumeValid:           config.AssumeValid,
+		fullVerify:            config.FullVerify,
+		invalidBlockDir:       config.InvalidBlockDir,
+		utxoCache:             newUtxoCache(config.DB, config.UtxoCacheSize),
 		bestChain:             newChainView(nil),
 		orphans:               make(map[chainhash.Hash]*orphanBlock),
 		prevOrphans:           make(map[chainhash.Hash][]*orphanBlock),
@@ -1169,13 +1240,31 @@ func New(
 		DifficultyAdjustments: make(map[string]float64),
 	}
 	// Initialize the chain state from the passed database.  When the db does not yet contain any chain state, both it and the chain state will be initialized to contain only the genesis block.
-	if err := b.initChainState(); err != nil {
+	if err := b.initChainState(config.Interrupt); err != nil {
 		return nil, err
 	}
+	// Remember the utxo snapshot the chain state was loaded from, if it was, and how far its blocks have been validated.
+	if err := b.db.View(func(dbTx database.Tx) error {
+		b.utxoSnapshot = dbFetchUtxoSnapshot(dbTx)
+		b.snapshotValidation = dbFetchSnapshotValidation(dbTx)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	if b.utxoSnapshot != nil && b.snapshotValidation != nil && b.snapshotValidation.height >= b.utxoSnapshot.BaseHeight {
+		log <- cl.Errorf{
+			"the utxo set built from the blocks up to the utxo snapshot at block %v does not match it, the chain state loaded from it is not valid",
+			b.utxoSnapshot.BaseHash,
+		}
+	}
 	// Perform any upgrades to the various chain-specific buckets as needed.
 	if err := b.maybeUpgradeDbBuckets(config.Interrupt); err != nil {
 		return nil, err
 	}
+	// Bring the utxo set up to the best block if the utxo cache was not flushed at the last shutdown.
+	if err := b.initUtxoCache(config.Interrupt); err != nil {
+		return nil, err
+	}
 	// Initialize and catch up all of the currently active optional indexes as needed.
 	if config.IndexManager != nil {
 		err := config.IndexManager.Init(&b, config.Interrupt)
@@ -1187,6 +1276,10 @@ func New(
 	if err := b.initThresholdCaches(); err != nil {
 		return nil, err
 	}
+	// Finish or undo a reorganization that was interrupted at the last shutdown.
+	if err := b.recoverChainJournal(); err != nil {
+		return nil, err
+	}
 	bestNode := b.bestChain.Tip()
 	log <- cl.Infof{
 		"chain state (height %d, hash %v, totaltx %d, work %v)",
@@ -96,9 +96,9 @@ func TestHaveBlock(
 func TestCalcSequenceLock(
 	t *testing.T) {
 	netParams := &chaincfg.SimNetParams
-	// We need to activate CSV in order to test the processing logic, so manually craft the block version that's used to signal the soft-fork activation.
+	// We need to activate CSV in order to test the processing logic, so manually craft the version bits the coinbase signals the soft-fork activation with, as the block version is that of the algorithm.
 	csvBit := netParams.Deployments[chaincfg.DeploymentCSV].BitNumber
-	blockVersion := int32(0x20000000 | (uint32(1) << csvBit))
+	versionBits := uint32(0x20000000 | (uint32(1) << csvBit))
 	// Generate enough synthetic blocks to activate CSV.
 	chain := newFakeChain(netParams)
 	node := chain.bestChain.Tip()
@@ -106,7 +106,8 @@ func TestCalcSequenceLock(
 	numBlocksToActivate := (netParams.MinerConfirmationWindow * 3)
 	for i := uint32(0); i < numBlocksToActivate; i++ {
 		blockTime = blockTime.Add(time.Second)
-		node = newFakeNode(node, blockVersion, 0, blockTime)
+		node = newFakeNode(node, 2, 0, blockTime)
+		node.versionBits = versionBits
 		chain.Index.AddNode(node)
 		chain.bestChain.SetTip(node)
 	}
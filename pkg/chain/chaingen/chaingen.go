@@ -0,0 +1,442 @@
+// Package chaingen builds deterministic block chains from scripted scenarios into a block chain, for reproducible integration tests of the wallet, indexers and RPC. The same scenario and seed on the same network always build the same blocks, as the key the blocks pay to comes from the seed, the timestamps step by fixed spacings from the genesis block and the nonce of each block is the lowest that solves it. Reorganizations can not be scripted: below the height the Plan 9 hard fork activates at, every block of an algorithm requires the minimum bits of the algorithm and so has the same work by CalcWork, and the chain only reorganizes onto a side chain whose tip has more work than its own tip. Making the tip of the main chain lighter would take blocks of scrypt, which take too long to solve at its minimum bits, so side chains built by the fork step stay side chains.
+package chaingen
+import (
+	"crypto/sha256"
+	"fmt"
+	"math"
+	"runtime"
+	"sync"
+	"time"
+	blockchain "git.parallelcoin.io/dev/9/pkg/chain"
+	chaincfg "git.parallelcoin.io/dev/9/pkg/chain/config"
+	"git.parallelcoin.io/dev/9/pkg/chain/fork"
+	chainhash "git.parallelcoin.io/dev/9/pkg/chain/hash"
+	txscript "git.parallelcoin.io/dev/9/pkg/chain/tx/script"
+	"git.parallelcoin.io/dev/9/pkg/chain/wire"
+	"git.parallelcoin.io/dev/9/pkg/util"
+	ec "git.parallelcoin.io/dev/9/pkg/util/elliptic"
+)
+// Algo is the algorithm the blocks are mined with, the cheapest to solve
+const Algo = "sha256d"
+// txFee is the fee each transaction pays, taken by the coinbase of its block
+const txFee = 10000
+// nonceBatch is the number of nonces tried at once by all the cores while solving a block
+const nonceBatch = 1 << 16
+// How an output is spent
+const (
+	// payToKey is a pay to public key hash output of the key of the generator
+	payToKey = iota
+	// payToScriptTrue is a pay to script hash output of a redeem script of OP_TRUE
+	payToScriptTrue
+)
+// trueScript is the script anyone can spend
+var trueScript = []byte{txscript.OpTrue}
+// spendable is an output of a generated block the generator can spend
+type spendable struct {
+	outpoint wire.OutPoint
+	amount   int64
+	pkScript []byte
+	kind     int
+	height   int32
+	coinbase bool
+}
+// genBlock is a block the generator built, with the outputs it created and spent for working out what can be spent on the chain it is the tip of
+type genBlock struct {
+	hash      chainhash.Hash
+	height    int32
+	timestamp time.Time
+	parent    *genBlock
+	created   []spendable
+	spent     []wire.OutPoint
+}
+// Generator builds blocks from the steps of a scenario and processes them into a block chain
+type Generator struct {
+	chain      *blockchain.BlockChain
+	params     *chaincfg.Params
+	key        *ec.PrivateKey
+	pkScript   []byte
+	scriptTrue []byte
+	tip        *genBlock
+	blocks     map[chainhash.Hash]*genBlock
+	branch     int64
+	interrupt  <-chan struct{}
+	// OnBlock is called with each block after it is processed, if it is set
+	OnBlock func(hash *chainhash.Hash, height int32)
+}
+// New returns a generator of blocks for a chain that has only its genesis block, paying to a key made from the seed
+func New(chain *blockchain.BlockChain, params *chaincfg.Params, seed []byte) (*Generator, error) {
+	best := chain.BestSnapshot()
+	if best.Height != 0 {
+		return nil, fmt.Errorf("the chain already has %d blocks after the genesis block", best.Height)
+	}
+	secret := sha256.Sum256(seed)
+	key, _ := ec.PrivKeyFromBytes(ec.S256(), secret[:])
+	addr, err := util.NewAddressPubKeyHash(util.Hash160(key.PubKey().SerializeCompressed()), params)
+	if err != nil {
+		return nil, err
+	}
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		return nil, err
+	}
+	p2sh, err := util.NewAddressScriptHash(trueScript, params)
+	if err != nil {
+		return nil, err
+	}
+	scriptTrue, err := txscript.PayToAddrScript(p2sh)
+	if err != nil {
+		return nil, err
+	}
+	genesis := &genBlock{
+		hash:      best.Hash,
+		timestamp: params.GenesisBlock.Header.Timestamp,
+	}
+	return &Generator{
+		chain:      chain,
+		params:     params,
+		key:        key,
+		pkScript:   pkScript,
+		scriptTrue: scriptTrue,
+		tip:        genesis,
+		blocks:     map[chainhash.Hash]*genBlock{genesis.hash: genesis},
+	}, nil
+}
+// Key returns the key the coinbases and most other outputs of the blocks pay to
+func (g *Generator) Key() *ec.PrivateKey {
+	return g.key
+}
+// Tip returns the hash and height of the tip of the main chain of the generated blocks
+func (g *Generator) Tip() (chainhash.Hash, int32) {
+	return g.tip.hash, g.tip.height
+}
+// Run builds the blocks of the steps of the scenario in order, stopping early if the interrupt channel is closed
+func (g *Generator) Run(s Scenario, interrupt <-chan struct{}) error {
+	g.interrupt = interrupt
+	for i, step := range s {
+		if err := g.step(step); err != nil {
+			return fmt.Errorf("step %d %s: %v", i+1, step.Op, err)
+		}
+	}
+	return nil
+}
+// step builds the blocks of a step of a scenario
+func (g *Generator) step(s Step) (err error) {
+	switch s.Op {
+	case "blocks":
+		_, err = g.mine(g.tip, g.spacing(), s.Args[0])
+	case "mature":
+		_, err = g.mine(g.tip, g.spacing(), int(g.params.CoinbaseMaturity))
+	case "spend":
+		err = g.spend(s.Args[0])
+	case "large":
+		err = g.large(s.Args[0])
+	case "nonstandard":
+		err = g.nonstandard(s.Args[0])
+	case "fork":
+		err = g.fork(s.Args[0], s.Args[1])
+	default:
+		err = fmt.Errorf("unknown step")
+	}
+	return
+}
+// spacing returns the time between the timestamps of blocks, the target time per block of the network
+func (g *Generator) spacing() time.Duration {
+	return time.Duration(g.params.TargetTimePerBlock) * time.Second
+}
+// mine builds n blocks with only their coinbase on top of the parent, their timestamps spaced apart as given, and returns the last
+func (g *Generator) mine(parent *genBlock, spacing time.Duration, n int) (*genBlock, error) {
+	for i := 0; i < n; i++ {
+		var err error
+		if parent, err = g.addBlock(parent, spacing, nil, nil, nil); err != nil {
+			return nil, err
+		}
+	}
+	return parent, nil
+}
+// spend builds a block with n transactions each spending a mature output back to the key
+func (g *Generator) spend(n int) error {
+	outs, err := g.mature(g.tip, n)
+	if err != nil {
+		return err
+	}
+	var txs []*wire.MsgTx
+	var created []spendable
+	var spent []wire.OutPoint
+	for _, out := range outs {
+		if out.amount <= txFee {
+			return fmt.Errorf("output %v is too small to pay the fee", out.outpoint)
+		}
+		tx := wire.NewMsgTx(wire.TxVersion)
+		tx.AddTxIn(wire.NewTxIn(&out.outpoint, nil, nil))
+		tx.AddTxOut(wire.NewTxOut(out.amount-txFee, g.pkScript))
+		if err := g.sign(tx, 0, out); err != nil {
+			return err
+		}
+		txs = append(txs, tx)
+		created = append(created, g.output(tx, 0, payToKey))
+		spent = append(spent, out.outpoint)
+	}
+	_, err = g.addBlock(g.tip, g.spacing(), txs, created, spent)
+	return err
+}
+// large builds a block of about size bytes from a transaction spending a mature output to as many pay to script hash outputs as fit, which unlike pay to public key hash outputs do not count towards the limit of signature operations in a block
+func (g *Generator) large(size int) error {
+	outs, err := g.mature(g.tip, 1)
+	if err != nil {
+		return err
+	}
+	out := outs[0]
+	// the header, coinbase and the input and count of outputs of the transaction take up about this much
+	const overhead = 400
+	outputSize := wire.NewTxOut(0, g.scriptTrue).SerializeSize()
+	count := (size - overhead) / outputSize
+	if count < 1 {
+		count = 1
+	}
+	value := (out.amount - txFee) / int64(count)
+	if value < 1 {
+		return fmt.Errorf("output %v is too small to split into %d outputs", out.outpoint, count)
+	}
+	tx := wire.NewMsgTx(wire.TxVersion)
+	tx.AddTxIn(wire.NewTxIn(&out.outpoint, nil, nil))
+	for i := 0; i < count; i++ {
+		tx.AddTxOut(wire.NewTxOut(value, g.scriptTrue))
+	}
+	// the last output gets what is left over from splitting the amount evenly
+	tx.TxOut[count-1].Value += out.amount - txFee - value*int64(count)
+	if err := g.sign(tx, 0, out); err != nil {
+		return err
+	}
+	var created []spendable
+	for i := range tx.TxOut {
+		created = append(created, g.output(tx, uint32(i), payToScriptTrue))
+	}
+	_, err = g.addBlock(g.tip, g.spacing(), []*wire.MsgTx{tx}, created, []wire.OutPoint{out.outpoint})
+	return err
+}
+// nonstandard builds a block with n pairs of transactions, the first spending a mature output to a bare OP_TRUE script and the second spending that with an empty signature script back to the key, neither of which the mempool accepts
+func (g *Generator) nonstandard(n int) error {
+	outs, err := g.mature(g.tip, n)
+	if err != nil {
+		return err
+	}
+	var txs []*wire.MsgTx
+	var created []spendable
+	var spent []wire.OutPoint
+	for _, out := range outs {
+		if out.amount <= 2*txFee {
+			return fmt.Errorf("output %v is too small to pay the fees", out.outpoint)
+		}
+		bare := wire.NewMsgTx(wire.TxVersion)
+		bare.AddTxIn(wire.NewTxIn(&out.outpoint, nil, nil))
+		bare.AddTxOut(wire.NewTxOut(out.amount-txFee, trueScript))
+		if err := g.sign(bare, 0, out); err != nil {
+			return err
+		}
+		spend := wire.NewMsgTx(wire.TxVersion)
+		spend.AddTxIn(wire.NewTxIn(wire.NewOutPoint(ptrHash(bare.TxHash()), 0), nil, nil))
+		spend.AddTxOut(wire.NewTxOut(out.amount-2*txFee, g.pkScript))
+		txs = append(txs, bare, spend)
+		created = append(created, g.output(spend, 0, payToKey))
+		spent = append(spent, out.outpoint)
+	}
+	_, err = g.addBlock(g.tip, g.spacing(), txs, created, spent)
+	return err
+}
+// below returns the block depth blocks below the tip, for building a branch on it
+func (g *Generator) below(depth int) (*genBlock, error) {
+	if depth > int(g.tip.height) {
+		return nil, fmt.Errorf("the chain has only %d blocks", g.tip.height)
+	}
+	base := g.tip
+	for i := 0; i < depth; i++ {
+		base = base.parent
+	}
+	// the blocks of each branch have a different extra nonce in their coinbase so they differ from those at the same heights on other branches
+	g.branch++
+	return base, nil
+}
+// fork builds a side chain of length blocks on the block depth blocks below the tip. Its blocks have the bits the chain requires of them, the same as those of the main chain, so it never replaces the main chain however long it is
+func (g *Generator) fork(depth, length int) error {
+	base, err := g.below(depth)
+	if err != nil {
+		return err
+	}
+	_, err = g.mine(base, g.spacing(), length)
+	return err
+}
+// mature returns the first n outputs that can be spent in the block after the tip, oldest first
+func (g *Generator) mature(tip *genBlock, n int) ([]spendable, error) {
+	var chain []*genBlock
+	spent := make(map[wire.OutPoint]struct{})
+	for b := tip; b != nil; b = b.parent {
+		chain = append(chain, b)
+		for _, op := range b.spent {
+			spent[op] = struct{}{}
+		}
+	}
+	next := tip.height + 1
+	var outs []spendable
+	for i := len(chain) - 1; i >= 0 && len(outs) < n; i-- {
+		for _, out := range chain[i].created {
+			if _, ok := spent[out.outpoint]; ok {
+				continue
+			}
+			if out.coinbase && next-out.height < int32(g.params.CoinbaseMaturity) {
+				continue
+			}
+			outs = append(outs, out)
+			if len(outs) == n {
+				break
+			}
+		}
+	}
+	if len(outs) < n {
+		return nil, fmt.Errorf("only %d of %d outputs can be spent, mine more blocks first", len(outs), n)
+	}
+	return outs, nil
+}
+// output returns the output of a transaction as one the generator can spend, with its height set when its block is added
+func (g *Generator) output(tx *wire.MsgTx, index uint32, kind int) spendable {
+	return spendable{
+		outpoint: *wire.NewOutPoint(ptrHash(tx.TxHash()), index),
+		amount:   tx.TxOut[index].Value,
+		pkScript: tx.TxOut[index].PkScript,
+		kind:     kind,
+	}
+}
+// sign sets the signature script of the input of the transaction at index to spend the output
+func (g *Generator) sign(tx *wire.MsgTx, index int, out spendable) (err error) {
+	var script []byte
+	switch out.kind {
+	case payToKey:
+		script, err = txscript.SignatureScript(tx, index, out.pkScript, txscript.SigHashAll, g.key, true)
+	case payToScriptTrue:
+		script, err = txscript.NewScriptBuilder().AddData(trueScript).Script()
+	}
+	tx.TxIn[index].SignatureScript = script
+	return
+}
+// addBlock builds a block on the parent with the transactions after its coinbase and its timestamp the spacing after that of the parent, solves it and processes it into the chain. The tip becomes whichever generated block is the tip of the main chain after it
+func (g *Generator) addBlock(parent *genBlock, spacing time.Duration, txs []*wire.MsgTx, created []spendable, spent []wire.OutPoint) (*genBlock, error) {
+	select {
+	case <-g.interrupt:
+		return nil, fmt.Errorf("interrupted")
+	default:
+	}
+	height := parent.height + 1
+	timestamp := parent.timestamp.Add(spacing)
+	coinbaseScript, err := txscript.NewScriptBuilder().AddInt64(int64(height)).AddInt64(g.branch).Script()
+	if err != nil {
+		return nil, err
+	}
+	coinbase := wire.NewMsgTx(wire.TxVersion)
+	coinbase.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: *wire.NewOutPoint(&chainhash.Hash{}, wire.MaxPrevOutIndex),
+		SignatureScript:  coinbaseScript,
+		Sequence:         wire.MaxTxInSequenceNum,
+	})
+	value := blockchain.CalcBlockSubsidy(height, g.params) + int64(len(txs))*txFee
+	coinbase.AddTxOut(wire.NewTxOut(value, g.pkScript))
+	blockTxs := []*util.Tx{util.NewTx(coinbase)}
+	for _, tx := range txs {
+		blockTxs = append(blockTxs, util.NewTx(tx))
+	}
+	bits, err := g.chain.CalcNextRequiredDifficultyAfter(&parent.hash, timestamp, Algo)
+	if err != nil {
+		return nil, err
+	}
+	merkles := blockchain.BuildMerkleTreeStore(blockTxs, false)
+	var msg wire.MsgBlock
+	msg.Header = wire.BlockHeader{
+		Version:    fork.GetAlgoVer(Algo, height),
+		PrevBlock:  parent.hash,
+		MerkleRoot: *merkles[len(merkles)-1],
+		Timestamp:  timestamp,
+		Bits:       bits,
+	}
+	for _, tx := range blockTxs {
+		if err := msg.AddTransaction(tx.MsgTx()); err != nil {
+			return nil, err
+		}
+	}
+	if !solve(&msg.Header, height) {
+		return nil, fmt.Errorf("no nonce solves block %d", height)
+	}
+	block := util.NewBlock(&msg)
+	block.SetHeight(height)
+	_, isOrphan, err := g.chain.ProcessBlock(block, blockchain.BFNone, height)
+	if err != nil {
+		return nil, fmt.Errorf("block %d was rejected: %v", height, err)
+	}
+	if isOrphan {
+		return nil, fmt.Errorf("block %d is an orphan", height)
+	}
+	b := &genBlock{
+		hash:      *block.Hash(),
+		height:    height,
+		timestamp: timestamp,
+		parent:    parent,
+		created:   append([]spendable{g.output(coinbase, 0, payToKey)}, created...),
+		spent:     spent,
+	}
+	for i := range b.created {
+		b.created[i].height = height
+	}
+	b.created[0].coinbase = true
+	g.blocks[b.hash] = b
+	if best := g.chain.BestSnapshot(); best != nil {
+		if tip, ok := g.blocks[best.Hash]; ok {
+			g.tip = tip
+		}
+	}
+	if g.OnBlock != nil {
+		g.OnBlock(&b.hash, height)
+	}
+	return b, nil
+}
+// solve sets the nonce of the header to the lowest that makes its hash meet its target, whatever the number of cores, and returns false if there is none
+func solve(header *wire.BlockHeader, height int32) bool {
+	target := blockchain.CompactToBig(header.Bits)
+	workers := uint64(runtime.NumCPU())
+	for start := uint64(0); start <= math.MaxUint32; start += nonceBatch {
+		end := start + nonceBatch
+		if end > math.MaxUint32+1 {
+			end = math.MaxUint32 + 1
+		}
+		found := make([]uint64, workers)
+		var wg sync.WaitGroup
+		for w := uint64(0); w < workers; w++ {
+			wg.Add(1)
+			go func(w uint64) {
+				defer wg.Done()
+				found[w] = math.MaxUint64
+				h := *header
+				for nonce := start + w; nonce < end; nonce += workers {
+					h.Nonce = uint32(nonce)
+					hash := h.BlockHashWithAlgos(height)
+					if blockchain.HashToBig(&hash).Cmp(target) <= 0 {
+						found[w] = nonce
+						return
+					}
+				}
+			}(w)
+		}
+		wg.Wait()
+		lowest := uint64(math.MaxUint64)
+		for _, nonce := range found {
+			if nonce < lowest {
+				lowest = nonce
+			}
+		}
+		if lowest != math.MaxUint64 {
+			header.Nonce = uint32(lowest)
+			return true
+		}
+	}
+	return false
+}
+// ptrHash returns a pointer to a copy of the hash
+func ptrHash(h chainhash.Hash) *chainhash.Hash {
+	return &h
+}
@@ -0,0 +1,163 @@
+package chaingen
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	blockchain "git.parallelcoin.io/dev/9/pkg/chain"
+	chaincfg "git.parallelcoin.io/dev/9/pkg/chain/config"
+	chainhash "git.parallelcoin.io/dev/9/pkg/chain/hash"
+	txscript "git.parallelcoin.io/dev/9/pkg/chain/tx/script"
+	database "git.parallelcoin.io/dev/9/pkg/db"
+	_ "git.parallelcoin.io/dev/9/pkg/db/ffldb"
+)
+// testScenario uses every step, with a short coinbase maturity so it runs quickly
+const testScenario = `# every step once
+blocks 2
+mature
+spend 2
+nonstandard
+large 20000
+fork 1 2
+blocks
+`
+// newGenerator returns a generator for a new chain in dir with a short coinbase maturity, and a function that closes its database
+func newGenerator(
+	t *testing.T, dir string) (*Generator, *blockchain.BlockChain, func()) {
+	params := chaincfg.SimNetParams
+	params.CoinbaseMaturity = 5
+	db, err := database.Create("ffldb", dir, params.Net)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain, err := blockchain.New(&blockchain.Config{
+		DB:          db,
+		ChainParams: &params,
+		TimeSource:  blockchain.NewMedianTime(),
+		SigCache:    txscript.NewSigCache(1000),
+	})
+	if err != nil {
+		db.Close()
+		t.Fatal(err)
+	}
+	g, err := New(chain, &params, []byte("chaingen"))
+	if err != nil {
+		db.Close()
+		t.Fatal(err)
+	}
+	return g, chain, func() { db.Close() }
+}
+// run builds the scenario with the generator
+func run(
+	t *testing.T, g *Generator, scenario string) {
+	s, err := ParseScenario(strings.NewReader(scenario))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Run(s, nil); err != nil {
+		t.Fatal(err)
+	}
+}
+// generate builds the test scenario into a new chain in dir and returns the hash and height of its tip
+func generate(
+	t *testing.T, dir string) (chainhash.Hash, int32) {
+	g, chain, closeDB := newGenerator(t, dir)
+	defer closeDB()
+	run(t, g, testScenario)
+	hash, height := g.Tip()
+	if best := chain.BestSnapshot(); best.Hash != hash || best.Height != height {
+		t.Fatalf("generated tip %v at %d, chain tip %v at %d", hash, height, best.Hash, best.Height)
+	}
+	return hash, height
+}
+// TestGenerate ensures the steps of a scenario build the blocks they describe and that building it again builds the same chain
+func TestGenerate(
+	t *testing.T) {
+	dir, err := ioutil.TempDir("", "chaingen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	hash, height := generate(t, filepath.Join(dir, "1"))
+	// 2 blocks, 5 to mature, 3 with transactions and 1 more on the main chain past the side chain
+	if want := int32(2 + 5 + 3 + 1); height != want {
+		t.Errorf("tip at height %d, want %d", height, want)
+	}
+	again, _ := generate(t, filepath.Join(dir, "2"))
+	if again != hash {
+		t.Errorf("second chain has tip %v, first %v", again, hash)
+	}
+}
+// TestForkStaysSideChain ensures a side chain longer than the main chain does not replace it, as its blocks have the same bits and so the same work as those of the main chain
+func TestForkStaysSideChain(
+	t *testing.T) {
+	dir, err := ioutil.TempDir("", "chaingen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	g, chain, closeDB := newGenerator(t, dir)
+	defer closeDB()
+	run(t, g, "blocks 2\n")
+	mainTip := g.tip
+	run(t, g, "fork 1 3\n")
+	if g.tip != mainTip {
+		t.Fatalf("the tip moved from block %d to %d", mainTip.height, g.tip.height)
+	}
+	if best := chain.BestSnapshot(); best.Hash != mainTip.hash {
+		t.Fatalf("the chain tip is %v at %d, want the main chain tip %v", best.Hash, best.Height, mainTip.hash)
+	}
+	var sideTip *genBlock
+	for _, b := range g.blocks {
+		if sideTip == nil || b.height > sideTip.height {
+			sideTip = b
+		}
+	}
+	if sideTip.height != mainTip.height+2 {
+		t.Fatalf("the side chain ends at height %d, want %d", sideTip.height, mainTip.height+2)
+	}
+	if have, err := chain.HaveBlock(&sideTip.hash); err != nil || !have {
+		t.Fatalf("the chain does not have the side chain tip, %v", err)
+	}
+	mainHeader, err := chain.HeaderByHash(&mainTip.hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sideHeader, err := chain.HeaderByHash(&sideTip.hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mainWork := blockchain.CalcWork(mainHeader.Bits, mainTip.height, mainHeader.Version)
+	sideWork := blockchain.CalcWork(sideHeader.Bits, sideTip.height, sideHeader.Version)
+	if sideWork.Cmp(mainWork) != 0 {
+		t.Errorf("the side chain tip has work %v, the main chain tip %v", sideWork, mainWork)
+	}
+}
+// TestParseScenario ensures scenarios are parsed with the defaults of the numbers left out and bad steps are rejected
+func TestParseScenario(
+	t *testing.T) {
+	s, err := ParseScenario(strings.NewReader("blocks\n\n# comment\nfork 1 2\nspend 3\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Scenario{{"blocks", []int{1}}, {"fork", []int{1, 2}}, {"spend", []int{3}}}
+	if len(s) != len(want) {
+		t.Fatalf("parsed %v, want %v", s, want)
+	}
+	for i := range want {
+		if s[i].Op != want[i].Op || len(s[i].Args) != len(want[i].Args) {
+			t.Fatalf("parsed %v, want %v", s, want)
+		}
+		for j := range want[i].Args {
+			if s[i].Args[j] != want[i].Args[j] {
+				t.Fatalf("parsed %v, want %v", s, want)
+			}
+		}
+	}
+	for _, bad := range []string{"fly 1", "fork 1", "blocks 0", "blocks x", "large 2000000", "spend 1 2"} {
+		if _, err := ParseScenario(strings.NewReader(bad)); err == nil {
+			t.Errorf("%q parsed", bad)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package chaingen
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	blockchain "git.parallelcoin.io/dev/9/pkg/chain"
+)
+// Step is one line of a scenario, the name of what to do followed by its numbers
+type Step struct {
+	Op   string
+	Args []int
+}
+// Scenario is the steps to build a chain with, in order
+type Scenario []Step
+// op is what a step does and the numbers it takes, those past min being optional with the defaults in defaults
+type op struct {
+	min, max int
+	defaults []int
+	usage    string
+}
+// ops are the steps a scenario can have by their names
+var ops = map[string]op{
+	"blocks":      {0, 1, []int{1}, "blocks [n]: mine n blocks with only their coinbase on the tip"},
+	"mature":      {0, 0, nil, "mature: mine as many blocks as it takes for a coinbase to be spendable"},
+	"spend":       {0, 1, []int{1}, "spend [n]: mine a block with n transactions each spending a mature output back to the key"},
+	"large":       {1, 1, nil, "large size: mine a block of about size bytes from a transaction with as many outputs as fit"},
+	"nonstandard": {0, 1, []int{1}, "nonstandard [n]: mine a block with n pairs of transactions, one paying to a bare OP_TRUE script and one spending it with an empty signature script"},
+	"fork":        {2, 2, nil, "fork depth length: mine a side chain of length blocks on the block depth blocks below the tip, which stays a side chain however long it is"},
+}
+// Usage returns the description of each step a scenario can have
+func Usage() string {
+	var names []string
+	for name := range ops {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var lines []string
+	for _, name := range names {
+		lines = append(lines, ops[name].usage)
+	}
+	return strings.Join(lines, "\n")
+}
+// ParseScenario reads a scenario with a step on each line, a name followed by numbers separated by spaces. Blank lines and those starting with # are skipped, and numbers left out take their defaults
+func ParseScenario(r io.Reader) (Scenario, error) {
+	var s Scenario
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 1 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		o, ok := ops[fields[0]]
+		if !ok {
+			return nil, fmt.Errorf("line %d: unknown step %q", line, fields[0])
+		}
+		args := fields[1:]
+		if len(args) < o.min || len(args) > o.max {
+			return nil, fmt.Errorf("line %d: %s", line, o.usage)
+		}
+		step := Step{Op: fields[0]}
+		for _, a := range args {
+			n, err := strconv.Atoi(a)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("line %d: %q is not a positive number", line, a)
+			}
+			step.Args = append(step.Args, n)
+		}
+		step.Args = append(step.Args, o.defaults[len(args)-o.min:]...)
+		if step.Op == "large" && step.Args[0] > blockchain.MaxBlockBaseSize {
+			return nil, fmt.Errorf("line %d: a block can not be larger than %d bytes", line, blockchain.MaxBlockBaseSize)
+		}
+		s = append(s, step)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
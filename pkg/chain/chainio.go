@@ -20,10 +20,14 @@ const (
 	latestUtxoSetBucketVersion = 2
 	// latestSpendJournalBucketVersion is the current version of the spend journal bucket that is used to track all spent transactions for use in reorgs.
 	latestSpendJournalBucketVersion = 1
+	// latestBlockIndexBucketVersion is the current version of the block index bucket, whose entries end with the version bits signalled in the coinbase of the block from version 2.
+	latestBlockIndexBucketVersion = 2
 )
 var (
 	// blockIndexBucketName is the name of the db bucket used to house to the block headers and contextual information.
 	blockIndexBucketName = []byte("blockheaderidx")
+	// blockIndexVersionKeyName is the name of the db key used to store the version of the block index currently in the database.
+	blockIndexVersionKeyName = []byte("blockindexversion")
 	// hashIndexBucketName is the name of the db bucket used to house to the block hash -> block height index.
 	hashIndexBucketName = []byte("hashidx")
 	// heightIndexBucketName is the name of the db bucket used to house to the block height -> block hash index.
@@ -516,12 +520,12 @@ func dbFetchUtxoEntryByHash(
 	}
 	return deserializeUtxoEntry(cursor.Value())
 }
-// dbFetchUtxoEntry uses an existing database transaction to fetch the specified transaction output from the utxo set. When there is no entry for the provided output, nil will be returned for both the entry and the error.
+// dbFetchUtxoEntry uses an existing database transaction to fetch the specified transaction output from the utxo set in the bucket with the passed name. When there is no entry for the provided output, nil will be returned for both the entry and the error.
 func dbFetchUtxoEntry(
-	dbTx database.Tx, outpoint wire.OutPoint) (*UtxoEntry, error) {
+	dbTx database.Tx, bucketName []byte, outpoint wire.OutPoint) (*UtxoEntry, error) {
 	// Fetch the unspent transaction output information for the passed transaction output.  Return now when there is no entry.
 	key := outpointKey(outpoint)
-	utxoBucket := dbTx.Metadata().Bucket(utxoSetBucketName)
+	utxoBucket := dbTx.Metadata().Bucket(bucketName)
 	serializedUtxo := utxoBucket.Get(*key)
 	recycleOutpointKey(key)
 	if serializedUtxo == nil {
@@ -551,10 +555,10 @@ func dbFetchUtxoEntry(
 	}
 	return entry, nil
 }
-// dbPutUtxoView uses an existing database transaction to update the utxo set in the database based on the provided utxo view contents and state.  In particular, only the entries that have been marked as modified are written to the database.
+// dbPutUtxoView uses an existing database transaction to update the utxo set in the bucket with the passed name, the utxo set bucket unless the set is being rebuilt to validate a utxo snapshot, based on the provided utxo view contents and state.  In particular, only the entries that have been marked as modified are written to the database.
 func dbPutUtxoView(
-	dbTx database.Tx, view *UtxoViewpoint) error {
-	utxoBucket := dbTx.Metadata().Bucket(utxoSetBucketName)
+	dbTx database.Tx, bucketName []byte, view *UtxoViewpoint) error {
+	utxoBucket := dbTx.Metadata().Bucket(bucketName)
 	for outpoint, entry := range view.entries {
 		// No need to update the database if the entry was not modified.
 		if entry == nil || !entry.isModified() {
@@ -761,11 +765,16 @@ func (b *BlockChain) createChainState() error {
 	// Create the initial the database chain state including creating the necessary index buckets and inserting the genesis block.
 	err := b.db.Update(func(dbTx database.Tx) error {
 		meta := dbTx.Metadata()
-		// Create the bucket that houses the block index data.
+		// Create the bucket that houses the block index data and store its version.
 		_, err := meta.CreateBucket(blockIndexBucketName)
 		if err != nil {
 			return err
 		}
+		err = dbPutVersion(dbTx, blockIndexVersionKeyName,
+			latestBlockIndexBucketVersion)
+		if err != nil {
+			return err
+		}
 		// Create the bucket that houses the chain block hash to height index.
 		_, err = meta.CreateBucket(hashIndexBucketName)
 		if err != nil {
@@ -812,13 +821,19 @@ func (b *BlockChain) createChainState() error {
 		if err != nil {
 			return err
 		}
+		// Store the block the utxo set is at, so it is replayed from the genesis block when the utxo cache is not flushed before a shutdown.
+		err = dbPutUtxoState(dbTx, &node.hash)
+		if err != nil {
+			return err
+		}
 		// Store the genesis block into the database.
 		return dbStoreBlock(dbTx, genesisBlock)
 	})
 	return err
 }
 // initChainState attempts to load and initialize the chain state from the database.  When the db does not yet contain any chain state, both it and the chain state are initialized to the genesis block.
-func (b *BlockChain) initChainState() error {
+func (b *BlockChain) initChainState(
+	interrupt <-chan struct{}) error {
 	// Determine the state of the chain database. We may need to initialize everything from scratch or upgrade certain buckets.
 	var initialized, hasBlockIndex bool
 	err := b.db.View(func(dbTx database.Tx) error {
@@ -839,6 +854,10 @@ func (b *BlockChain) initChainState() error {
 			return nil
 		}
 	}
+	// Add the version bits signalled in the coinbases of the blocks to the block index before it is loaded if it is from before they were stored.
+	if err := maybeUpgradeBlockIndex(b.db, interrupt); err != nil {
+		return err
+	}
 	// Attempt to load the chain state from the database.
 	err = b.db.View(func(dbTx database.Tx) error {
 		// Fetch the stored chain state from the database metadata. When it doesn't exist, it means the database hasn't been initialized for use with chain yet, so break out now to allow that to happen under a writable database transaction.
@@ -862,7 +881,7 @@ func (b *BlockChain) initChainState() error {
 		var lastNode *blockNode
 		cursor = blockIndexBucket.Cursor()
 		for ok := cursor.First(); ok; ok = cursor.Next() {
-			header, status, err := deserializeBlockRow(cursor.Value())
+			header, status, versionBits, err := deserializeBlockRow(cursor.Value())
 			if err != nil {
 				return err
 			}
@@ -892,6 +911,7 @@ func (b *BlockChain) initChainState() error {
 			node := &blockNodes[i]
 			initBlockNode(node, header, parent)
 			node.status = status
+			node.versionBits = versionBits
 			b.Index.addNode(node)
 			lastNode = node
 			i++
@@ -941,20 +961,27 @@ func (b *BlockChain) initChainState() error {
 	// As we might have updated the index after it was loaded, we'll attempt to flush the index to the DB. This will only result in a write if the elements are dirty, so it'll usually be a noop.
 	return b.Index.flushToDB()
 }
-// deserializeBlockRow parses a value in the block index bucket into a block header and block status bitfield.
+// deserializeBlockRow parses a value in the block index bucket into a block header, block status bitfield and the version bits signalled in the coinbase of the block.
 func deserializeBlockRow(
-	blockRow []byte) (*wire.BlockHeader, blockStatus, error) {
+	blockRow []byte) (*wire.BlockHeader, blockStatus, uint32, error) {
 	buffer := bytes.NewReader(blockRow)
 	var header wire.BlockHeader
 	err := header.Deserialize(buffer)
 	if err != nil {
-		return nil, statusNone, err
+		return nil, statusNone, 0, err
 	}
 	statusByte, err := buffer.ReadByte()
 	if err != nil {
-		return nil, statusNone, err
+		return nil, statusNone, 0, err
+	}
+	// The version bits follow the status from version 2 of the block index.
+	var versionBits uint32
+	if buffer.Len() >= 4 {
+		var vb [4]byte
+		buffer.Read(vb[:])
+		versionBits = binary.LittleEndian.Uint32(vb[:])
 	}
-	return &header, blockStatus(statusByte), nil
+	return &header, blockStatus(statusByte), versionBits, nil
 }
 // dbFetchHeaderByHash uses an existing database transaction to retrieve the block header for the provided hash.
 func dbFetchHeaderByHash(
@@ -995,11 +1022,11 @@ func dbFetchBlockByNode(
 	block.SetHeight(node.height)
 	return block, nil
 }
-// dbStoreBlockNode stores the block header and validation status to the block index bucket. This overwrites the current entry if there exists one.
+// dbStoreBlockNode stores the block header, validation status and the version bits the block signals in its coinbase to the block index bucket. This overwrites the current entry if there exists one.
 func dbStoreBlockNode(
 	dbTx database.Tx, node *blockNode) error {
 	// Serialize block data to be stored.
-	w := bytes.NewBuffer(make([]byte, 0, blockHdrSize+1))
+	w := bytes.NewBuffer(make([]byte, 0, blockHdrSize+5))
 	header := node.Header()
 	err := header.Serialize(w)
 	if err != nil {
@@ -1009,6 +1036,9 @@ func dbStoreBlockNode(
 	if err != nil {
 		return err
 	}
+	var vb [4]byte
+	binary.LittleEndian.PutUint32(vb[:], node.versionBits)
+	w.Write(vb[:])
 	value := w.Bytes()
 	// Write block header data to block index bucket.
 	blockIndexBucket := dbTx.Metadata().Bucket(blockIndexBucketName)
@@ -0,0 +1,72 @@
+package chain
+import (
+	"sort"
+	chainhash "git.parallelcoin.io/dev/9/pkg/chain/hash"
+)
+// The statuses of a chain tip, named as getchaintips reports them.
+const (
+	// ChainTipActive is the tip of the main chain.
+	ChainTipActive = "active"
+	// ChainTipValidFork is a branch whose blocks were all fully validated but which has less work than the main chain.
+	ChainTipValidFork = "valid-fork"
+	// ChainTipValidHeaders is a branch whose blocks are all stored but not all validated.
+	ChainTipValidHeaders = "valid-headers"
+	// ChainTipHeadersOnly is a branch with blocks whose data is not stored.
+	ChainTipHeadersOnly = "headers-only"
+	// ChainTipInvalid is a branch with a block that failed validation.
+	ChainTipInvalid = "invalid"
+)
+// ChainTip is a block in the block index that no other block builds on, the tip of the main chain or of a branch off it.
+type ChainTip struct {
+	Hash   chainhash.Hash
+	Height int32
+	// BranchLen is the number of blocks from the tip down to where its branch forks from the main chain, which is zero for the main chain.
+	BranchLen int32
+	Status    string
+}
+// ChainTips returns the tip of the main chain followed by the tips of the branches off it, highest first. This function is safe for concurrent access.
+func (b *BlockChain) ChainTips() []ChainTip {
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+	b.Index.RLock()
+	defer b.Index.RUnlock()
+	hasChild := make(map[*blockNode]struct{}, len(b.Index.index))
+	for _, node := range b.Index.index {
+		if node.parent != nil {
+			hasChild[node.parent] = struct{}{}
+		}
+	}
+	tip := b.bestChain.Tip()
+	tips := []ChainTip{{Hash: tip.hash, Height: tip.height, Status: ChainTipActive}}
+	for _, node := range b.Index.index {
+		if _, ok := hasChild[node]; ok || node == tip {
+			continue
+		}
+		fork := b.bestChain.FindFork(node)
+		status := ChainTipValidFork
+		for n := node; n != nil && n != fork; n = n.parent {
+			switch {
+			case n.status.KnownInvalid():
+				status = ChainTipInvalid
+			case !n.status.HaveData() && status != ChainTipInvalid:
+				status = ChainTipHeadersOnly
+			case !n.status.KnownValid() && status == ChainTipValidFork:
+				status = ChainTipValidHeaders
+			}
+		}
+		branchLen := node.height + 1
+		if fork != nil {
+			branchLen = node.height - fork.height
+		}
+		tips = append(tips, ChainTip{
+			Hash:      node.hash,
+			Height:    node.height,
+			BranchLen: branchLen,
+			Status:    status,
+		})
+	}
+	sort.Slice(tips[1:], func(i, j int) bool {
+		return tips[i+1].Height > tips[j+1].Height
+	})
+	return tips
+}
@@ -0,0 +1,39 @@
+package chain
+import (
+	"testing"
+	chaincfg "git.parallelcoin.io/dev/9/pkg/chain/config"
+)
+// TestChainTips ensures the tips of the main chain and of the branches off it are found with their branch lengths and statuses.
+func TestChainTips(
+	t *testing.T) {
+	chain := newFakeChain(&chaincfg.MainNetParams)
+	mainNodes := chainedNodes(chain.bestChain.Genesis(), 10)
+	validFork := chainedNodes(mainNodes[3], 3)
+	validHeaders := chainedNodes(mainNodes[5], 2)
+	invalid := chainedNodes(mainNodes[1], 2)
+	for _, nodes := range [][]*blockNode{mainNodes, validFork, validHeaders, invalid} {
+		for _, node := range nodes {
+			node.status = statusDataStored | statusValid
+			chain.Index.AddNode(node)
+		}
+	}
+	validHeaders[1].status = statusDataStored
+	invalid[0].status = statusDataStored | statusValidateFailed
+	invalid[1].status = statusDataStored | statusInvalidAncestor
+	chain.bestChain.SetTip(tstTip(mainNodes))
+	tests := []ChainTip{
+		{Hash: tstTip(mainNodes).hash, Height: 10, BranchLen: 0, Status: ChainTipActive},
+		{Hash: tstTip(validHeaders).hash, Height: 8, BranchLen: 2, Status: ChainTipValidHeaders},
+		{Hash: tstTip(validFork).hash, Height: 7, BranchLen: 3, Status: ChainTipValidFork},
+		{Hash: tstTip(invalid).hash, Height: 4, BranchLen: 2, Status: ChainTipInvalid},
+	}
+	tips := chain.ChainTips()
+	if len(tips) != len(tests) {
+		t.Fatalf("got %d chain tips, want %d: %v", len(tips), len(tests), tips)
+	}
+	for i, want := range tests {
+		if tips[i] != want {
+			t.Errorf("chain tip %d is %+v, want %+v", i, tips[i], want)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package chain
 import (
 	"fmt"
+	"sort"
 	"time"
 	chaincfg "git.parallelcoin.io/dev/9/pkg/chain/config"
 	chainhash "git.parallelcoin.io/dev/9/pkg/chain/hash"
@@ -108,6 +109,60 @@ func (b *BlockChain) findPreviousCheckpoint() (*blockNode, error) {
 	}
 	return b.checkpointNode, nil
 }
+// sideChainHeights returns the sorted heights of the blocks in the index that are not in the main chain, which are where the chain has forked.
+func (b *BlockChain) sideChainHeights() []int32 {
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+	b.Index.RLock()
+	defer b.Index.RUnlock()
+	var heights []int32
+	for _, node := range b.Index.index {
+		if !b.bestChain.Contains(node) {
+			heights = append(heights, node.height)
+		}
+	}
+	sort.Slice(heights, func(i, j int) bool { return heights[i] < heights[j] })
+	return heights
+}
+// CheckpointCandidates returns new checkpoints for the main chain, for operators to add to their own checkpoints from a chain their node has fully validated. The first is about interval blocks after the latest checkpoint, or the genesis block without any, and each following one about interval blocks after the one before. Each is the first block from where it is due that IsCheckpointCandidate accepts and that has no side chain blocks known to the block index within forkDistance blocks of it. Blocks before the latest checkpoint are not considered as their scripts are not validated. This function is safe for concurrent access.
+func (b *BlockChain) CheckpointCandidates(interval, forkDistance int32) ([]chaincfg.Checkpoint, error) {
+	if interval < 1 {
+		return nil, fmt.Errorf("checkpoint interval must be at least 1, not %d", interval)
+	}
+	var start int32
+	if latest := b.LatestCheckpoint(); latest != nil {
+		start = latest.Height
+	}
+	forks := b.sideChainHeights()
+	// forkNear returns whether there is a side chain block within forkDistance of the height.
+	forkNear := func(height int32) bool {
+		i := sort.Search(len(forks), func(i int) bool { return forks[i] >= height-forkDistance })
+		return i < len(forks) && forks[i] <= height+forkDistance
+	}
+	last := b.BestSnapshot().Height - CheckpointConfirmations
+	var candidates []chaincfg.Checkpoint
+	for height := start + interval; height <= last; {
+		if forkNear(height) {
+			height++
+			continue
+		}
+		block, err := b.BlockByHeight(height)
+		if err != nil {
+			return nil, err
+		}
+		ok, err := b.IsCheckpointCandidate(block)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			height++
+			continue
+		}
+		candidates = append(candidates, chaincfg.Checkpoint{Height: height, Hash: block.Hash()})
+		height += interval
+	}
+	return candidates, nil
+}
 // isNonstandardTransaction determines whether a transaction contains any scripts which are not one of the standard types.
 func isNonstandardTransaction(
 	tx *util.Tx) bool {
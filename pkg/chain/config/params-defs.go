@@ -84,6 +84,15 @@ type Checkpoint struct {
 	Height int32
 	Hash   *chainhash.Hash
 }
+// ChainTxData is the number of transactions in the chain up to a block and the rate they were added at before it, as getchaintxstats reports them, for estimating how much of the chain a node that is syncing has verified.
+type ChainTxData struct {
+	// Time is the unix time of the block.
+	Time int64
+	// TxCount is the number of transactions in the chain up to and including the block.
+	TxCount uint64
+	// TxRate is the number of transactions per second in the window before the block.
+	TxRate float64
+}
 // DNSSeed identifies a DNS seed.
 type DNSSeed struct {
 	// Host defines the hostname of the seed.
@@ -134,6 +143,8 @@ type Params struct {
 	BIP0034Height int32
 	BIP0065Height int32
 	BIP0066Height int32
+	// CoinbaseBitsHeight is the height from which blocks signal the rule change deployments in an output of their coinbase, whose script VersionBitsScript in the chain package makes, instead of in their version, which identifies their proof of work algorithm and so can not carry them.
+	CoinbaseBitsHeight int32
 	// CoinbaseMaturity is the number of blocks required before newly mined coins (coinbase transactions) can be spent.
 	CoinbaseMaturity uint16
 	// SubsidyReductionInterval is the interval of blocks before the subsidy is reduced.
@@ -152,6 +163,8 @@ type Params struct {
 	GenerateSupported bool
 	// Checkpoints ordered from oldest to newest.
 	Checkpoints []Checkpoint
+	// ChainTxData is a recent count of the transactions in the chain, left zero while there is none and the rate is taken from the chain itself.
+	ChainTxData ChainTxData
 	// These fields are related to voting on consensus rule changes as defined by BIP0009.
 	//
 	// RuleChangeActivationThreshold is the number of blocks in a threshold state retarget window for which a positive vote for a rule change must be cast in order to lock in a rule change. It should typically be 95% for the main network and 75% for test networks.
@@ -162,7 +175,9 @@ type Params struct {
 	Deployments [DefinedDeployments]ConsensusDeployment
 	// Mempool parameters
 	RelayNonStdTxs bool
-	// Human-readable part for Bech32 encoded segwit addresses, as defined in BIP 173.
+	// FeeRateOnly disables the legacy free/high-priority transaction space, so transactions are relayed and mined by fee rate alone and every transaction must pay at least the minimum relay fee.
+	FeeRateOnly bool
+	// Human-readable part for Bech32 encoded segwit addresses, as defined in BIP 173. Taproot addresses use the same part with the bech32m checksum of BIP 350.
 	Bech32HRPSegwit string
 	// Address encoding magics
 	PubKeyHashAddrID        byte // First byte of a P2PKH address
@@ -1,5 +1,8 @@
 package chaincfg
-import "git.parallelcoin.io/dev/9/pkg/chain/wire"
+import (
+	"math"
+	"git.parallelcoin.io/dev/9/pkg/chain/wire"
+)
 // MainNetParams defines the network parameters for the main Bitcoin network.
 var MainNetParams = Params{
 	Name:        "mainnet",
@@ -20,6 +23,7 @@ var MainNetParams = Params{
 	BIP0034Height:            1000000,          // Reserved for future change
 	BIP0065Height:            1000000,
 	BIP0066Height:            1000000,
+	CoinbaseBitsHeight:       math.MaxInt32, // Not yet scheduled
 	CoinbaseMaturity:         100,
 	SubsidyReductionInterval: 250000,
 	TargetTimespan:           TargetTimespan,
@@ -57,6 +61,7 @@ var MainNetParams = Params{
 	},
 	// Mempool parameters
 	RelayNonStdTxs: false,
+	FeeRateOnly:    true,
 	// Human-readable part for Bech32 encoded segwit addresses, as defined in
 	// BIP 173.
 	Bech32HRPSegwit: "bc", // always bc for main net
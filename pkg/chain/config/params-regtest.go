@@ -18,6 +18,7 @@ var RegressionNetParams = Params{
 	BIP0034Height:            100000000, // Not active - Permit ver 1 blocks
 	BIP0065Height:            100000000, // Used by regression tests
 	BIP0066Height:            100000000, // Used by regression tests
+	CoinbaseBitsHeight:       0,         // Always active, as the block versions are algorithm IDs
 	SubsidyReductionInterval: 150,
 	TargetTimespan:           30000, // 14 days
 	TargetTimePerBlock:       300,   // 5 minutes
@@ -52,6 +53,7 @@ var RegressionNetParams = Params{
 	},
 	// Mempool parameters
 	RelayNonStdTxs: true,
+	FeeRateOnly:    false,
 	// Human-readable part for Bech32 encoded segwit addresses, as defined in
 	// BIP 173.
 	Bech32HRPSegwit: "bcrt", // always bcrt for reg test net
@@ -18,6 +18,7 @@ var SimNetParams = Params{
 	BIP0034Height:            0, // Always active on simnet
 	BIP0065Height:            0, // Always active on simnet
 	BIP0066Height:            0, // Always active on simnet
+	CoinbaseBitsHeight:       0, // Always active on simnet
 	CoinbaseMaturity:         100,
 	SubsidyReductionInterval: 210000,
 	TargetTimespan:           30000, // 14 days
@@ -53,6 +54,7 @@ var SimNetParams = Params{
 	},
 	// Mempool parameters
 	RelayNonStdTxs: true,
+	FeeRateOnly:    false,
 	// Human-readable part for Bech32 encoded segwit addresses, as defined in
 	// BIP 173.
 	Bech32HRPSegwit: "sb", // always sb for sim net
@@ -1,5 +1,6 @@
 package chaincfg
 import (
+	"math"
 	"git.parallelcoin.io/dev/9/pkg/chain/fork"
 	"git.parallelcoin.io/dev/9/pkg/chain/wire"
 )
@@ -19,6 +20,7 @@ var TestNet3Params = Params{
 	BIP0034Height:            1000000,                // 0000000023b3a96d3484e5abb3755c413e7d41500f8e2a5c3f0dd01299cd8ef8
 	BIP0065Height:            1000000,                // 00000000007f6655f22f98e72ed80d8b06dc761d5da09df0fa1dc4be4f861eb6
 	BIP0066Height:            1000000,                // 000000002104c8c45e99a8853285a3b592602a3ccde2b832481da85e9e4ba182
+	CoinbaseBitsHeight:       math.MaxInt32,          // Not yet scheduled
 	CoinbaseMaturity:         100,
 	SubsidyReductionInterval: 250000,
 	TargetTimespan:           TestnetTargetTimespan,
@@ -56,6 +58,7 @@ var TestNet3Params = Params{
 	},
 	// Mempool parameters
 	RelayNonStdTxs: true,
+	FeeRateOnly:    true,
 	// Human-readable part for Bech32 encoded segwit addresses, as defined in BIP 173.
 	Bech32HRPSegwit: "tb", // always tb for test net
 	// Address encoding magics
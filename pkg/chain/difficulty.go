@@ -34,6 +34,26 @@ func (
 	b.chainLock.Unlock()
 	return
 }
+// CalcNextRequiredDifficultyAfter calculates the required difficulty for the block after the block with the passed hash, which need not be on the main chain, for building blocks on side chains. This function is safe for concurrent access.
+func (
+	b *BlockChain,
+) CalcNextRequiredDifficultyAfter(
+	hash *chainhash.Hash,
+	timestamp time.Time,
+	algo string,
+) (
+	difficulty uint32,
+	err error,
+) {
+	node := b.Index.LookupNode(hash)
+	if node == nil {
+		return 0, fmt.Errorf("block %v is not known", hash)
+	}
+	b.chainLock.Lock()
+	difficulty, err = b.calcNextRequiredDifficulty(node, timestamp, algo, true)
+	b.chainLock.Unlock()
+	return
+}
 // calcEasiestDifficulty calculates the easiest possible difficulty that a block can have given starting difficulty bits and a duration.  It is mainly used to verify that claimed proof of work by a block is sane as compared to a known good checkpoint.
 func (
 	b *BlockChain,
@@ -56,6 +76,13 @@ func (
 	}
 	return BigToCompact(newTarget)
 }
+// retargetFunc calculates the required difficulty for a block of an algorithm after the passed previous block node by the difficulty adjustment rules of a hard fork.
+type retargetFunc func(b *BlockChain, lastNode *blockNode, newBlockTime time.Time, algoname string, l bool) (uint32, error)
+// retargetFuncs are the difficulty adjustments of the hard forks by their number, so the rules of a new hard fork are added here along with its entry in fork.List. A hard fork without one requires the minimum difficulty of each of its algorithms.
+var retargetFuncs = map[uint32]retargetFunc{
+	0: (*BlockChain).calcLegacyRequiredDifficulty,
+	1: (*BlockChain).calcPlan9RequiredDifficulty,
+}
 // calcNextRequiredDifficulty calculates the required difficulty for the block after the passed previous block node based on the difficulty retarget rules. This function differs from the exported  CalcNextRequiredDifficulty in that the exported version uses the current best chain as the previous block node while this function accepts any block node.
 func (
 	b *BlockChain,
@@ -69,274 +96,297 @@ func (
 	err error,
 ) {
 	nH := lastNode.height + 1
-	switch fork.GetCurrent(nH) {
-	// Legacy difficulty adjustment
-	case 0:
-		log <- cl.Debug{"on pre-hardfork"}
-		if lastNode == nil {
-			return newTargetBits, nil
-		}
-		algo := fork.GetAlgoVer(algoname, nH)
-		algoName := fork.GetAlgoName(algo, nH)
-		newTargetBits = fork.GetMinBits(algoName, nH)
-		log <- cl.Debugc(func() string {
-			return fmt.Sprintf("last %d %d %8x",
-				lastNode.height, lastNode.version, lastNode.bits)
-		})
-		prevNode := lastNode.GetLastWithAlgo(algo)
-		if prevNode == nil {
-			return newTargetBits, nil
-		}
-		firstNode := prevNode
-		for i := int64(0); firstNode != nil &&
-			i < fork.GetAveragingInterval(nH)-1; i++ {
-			log <- cl.Debugc(func() string {
-				return fmt.Sprintf("%d: prev %d %d %8x",
-					i, firstNode.height, firstNode.version, firstNode.bits)
-			})
-			firstNode = firstNode.RelativeAncestor(1)
-			firstNode = firstNode.GetLastWithAlgo(algo)
-		}
-		if firstNode == nil {
-			return newTargetBits, nil
-		}
-		log <- cl.Debugc(func() string {
-			return fmt.Sprintf("9: first %d %d %8x",
-				firstNode.height, firstNode.version, firstNode.bits)
-		})
-		actualTimespan := prevNode.timestamp - firstNode.timestamp
-		adjustedTimespan := actualTimespan
-		log <- cl.Debug{"actual %d", actualTimespan}
-		if actualTimespan < b.chainParams.MinActualTimespan {
-			adjustedTimespan = b.chainParams.MinActualTimespan
-		} else if actualTimespan > b.chainParams.MaxActualTimespan {
-			adjustedTimespan = b.chainParams.MaxActualTimespan
-		}
-		log <- cl.Debug{"adjusted %d", adjustedTimespan}
-		oldTarget := CompactToBig(prevNode.bits)
-		newTarget := new(big.Int).
-			Mul(oldTarget, big.NewInt(adjustedTimespan))
-		newTarget = newTarget.
-			Div(newTarget, big.NewInt(b.chainParams.AveragingTargetTimespan))
-		if newTarget.Cmp(CompactToBig(newTargetBits)) > 0 {
-			newTarget.Set(CompactToBig(newTargetBits))
-		}
-		newTargetBits = BigToCompact(newTarget)
+	if retarget, ok := retargetFuncs[fork.List[fork.GetCurrent(nH)].Number]; ok {
+		return retarget(b, lastNode, newBlockTime, algoname, l)
+	}
+	return fork.GetMinBits(algoname, nH), nil
+}
+// calcLegacyRequiredDifficulty calculates the required difficulty for a block before the Plan 9 hard fork from the blocks of the same algorithm in the averaging interval.
+func (
+	b *BlockChain,
+) calcLegacyRequiredDifficulty(
+	lastNode *blockNode,
+	newBlockTime time.Time,
+	algoname string,
+	l bool,
+) (
+	newTargetBits uint32,
+	err error,
+) {
+	nH := lastNode.height + 1
+	log <- cl.Debug{"on pre-hardfork"}
+	if lastNode == nil {
+		return newTargetBits, nil
+	}
+	algo := fork.GetAlgoVer(algoname, nH)
+	algoName := fork.GetAlgoName(algo, nH)
+	newTargetBits = fork.GetMinBits(algoName, nH)
+	log <- cl.Debugc(func() string {
+		return fmt.Sprintf("last %d %d %8x",
+			lastNode.height, lastNode.version, lastNode.bits)
+	})
+	prevNode := lastNode.GetLastWithAlgo(algo)
+	if prevNode == nil {
+		return newTargetBits, nil
+	}
+	firstNode := prevNode
+	for i := int64(0); firstNode != nil &&
+		i < fork.GetAveragingInterval(nH)-1; i++ {
 		log <- cl.Debugc(func() string {
-			return fmt.Sprintf(
-				"difficulty retarget at block height %d, old %08x new %08x",
-				lastNode.height+1, prevNode.bits, newTargetBits)
-		})
-		log <- cl.Tracec(func() string {
-			return fmt.Sprintf(
-				"actual timespan %v, adjusted timespan %v, target timespan %v"+
-					"\nOld %064x\nNew %064x",
-				actualTimespan,
-				adjustedTimespan,
-				b.chainParams.AveragingTargetTimespan,
-				oldTarget,
-				CompactToBig(newTargetBits),
-			)
+			return fmt.Sprintf("%d: prev %d %d %8x",
+				i, firstNode.height, firstNode.version, firstNode.bits)
 		})
+		firstNode = firstNode.RelativeAncestor(1)
+		firstNode = firstNode.GetLastWithAlgo(algo)
+	}
+	if firstNode == nil {
 		return newTargetBits, nil
-	case 1: // Plan 9 from Crypto Space
-		log <- cl.Debug{"on plan 9 hardfork"}
-		if lastNode.height == 0 {
-			return fork.FirstPowLimitBits, nil
+	}
+	log <- cl.Debugc(func() string {
+		return fmt.Sprintf("9: first %d %d %8x",
+			firstNode.height, firstNode.version, firstNode.bits)
+	})
+	actualTimespan := prevNode.timestamp - firstNode.timestamp
+	adjustedTimespan := actualTimespan
+	log <- cl.Debug{"actual %d", actualTimespan}
+	if actualTimespan < b.chainParams.MinActualTimespan {
+		adjustedTimespan = b.chainParams.MinActualTimespan
+	} else if actualTimespan > b.chainParams.MaxActualTimespan {
+		adjustedTimespan = b.chainParams.MaxActualTimespan
+	}
+	log <- cl.Debug{"adjusted %d", adjustedTimespan}
+	oldTarget := CompactToBig(prevNode.bits)
+	newTarget := new(big.Int).
+		Mul(oldTarget, big.NewInt(adjustedTimespan))
+	newTarget = newTarget.
+		Div(newTarget, big.NewInt(b.chainParams.AveragingTargetTimespan))
+	if newTarget.Cmp(CompactToBig(newTargetBits)) > 0 {
+		newTarget.Set(CompactToBig(newTargetBits))
+	}
+	newTargetBits = BigToCompact(newTarget)
+	log <- cl.Debugc(func() string {
+		return fmt.Sprintf(
+			"difficulty retarget at block height %d, old %08x new %08x",
+			lastNode.height+1, prevNode.bits, newTargetBits)
+	})
+	log <- cl.Tracec(func() string {
+		return fmt.Sprintf(
+			"actual timespan %v, adjusted timespan %v, target timespan %v"+
+				"\nOld %064x\nNew %064x",
+			actualTimespan,
+			adjustedTimespan,
+			b.chainParams.AveragingTargetTimespan,
+			oldTarget,
+			CompactToBig(newTargetBits),
+		)
+	})
+	return newTargetBits, nil
+}
+// calcPlan9RequiredDifficulty calculates the required difficulty for a block after the Plan 9 hard fork from the recent blocks of the same algorithm and the average block times of the whole chain since the hard fork and of its trailing blocks.
+func (
+	b *BlockChain,
+) calcPlan9RequiredDifficulty(
+	lastNode *blockNode,
+	newBlockTime time.Time,
+	algoname string,
+	l bool,
+) (
+	newTargetBits uint32,
+	err error,
+) {
+	nH := lastNode.height + 1
+	log <- cl.Debug{"on plan 9 hardfork"}
+	if lastNode.height == 0 {
+		return fork.FirstPowLimitBits, nil
+	}
+	algo := fork.GetAlgoVer(algoname, nH)
+	newTargetBits = fork.GetMinBits(algoname, nH)
+	last := lastNode
+	// find the most recent block of the same algo
+	if last.version != algo {
+		ln := last.RelativeAncestor(1)
+		ln = ln.GetLastWithAlgo(algo)
+		// ignore the first block as its time is not a normal timestamp
+		if ln.height < 1 {
+			return fork.GetMinBits(algoname, nH), nil
 		}
-		nH := lastNode.height + 1
-		algo := fork.GetAlgoVer(algoname, nH)
-		newTargetBits = fork.GetMinBits(algoname, nH)
-		last := lastNode
-		// find the most recent block of the same algo
-		if last.version != algo {
-			ln := last.RelativeAncestor(1)
-			ln = ln.GetLastWithAlgo(algo)
-			// ignore the first block as its time is not a normal timestamp
-			if ln.height < 1 {
-				break
+		last = ln
+	}
+	counter := 1
+	var timestamps []float64
+	timestamps = append(timestamps, float64(last.timestamp))
+	pb := last
+	// collect the timestamps of all the blocks of the same algo until we pass genesis block or get AveragingInterval blocks
+	for ; counter < int(fork.GetAveragingInterval(nH)) && pb.height > 2; counter++ {
+		p := pb.RelativeAncestor(1)
+		if p != nil {
+			if p.height == 0 {
+				return fork.SecondPowLimitBits, nil
 			}
-			last = ln
+			pb = p.GetLastWithAlgo(algo)
+		} else {
+			break
 		}
-		counter := 1
-		var timestamps []float64
-		timestamps = append(timestamps, float64(last.timestamp))
-		pb := last
-		// collect the timestamps of all the blocks of the same algo until we pass genesis block or get AveragingInterval blocks
-		for ; counter < int(fork.GetAveragingInterval(nH)) && pb.height > 2; counter++ {
-			p := pb.RelativeAncestor(1)
-			if p != nil {
-				if p.height == 0 {
-					return fork.SecondPowLimitBits, nil
+		if pb != nil && pb.height > 0 {
+			// only add the timestamp if is not the same as the previous
+			timestamps = append(timestamps, float64(pb.timestamp))
+		} else {
+			break
+		}
+	}
+	allTimeAverage, trailTimeAverage := float64(fork.GetTargetTimePerBlock(nH)), float64(fork.GetTargetTimePerBlock(nH))
+	startHeight := fork.List[1].ActivationHeight
+	if b.chainParams.Name == "testnet" {
+		startHeight = 1
+	}
+	trailHeight := int32(int64(lastNode.height) -
+		fork.GetAveragingInterval(nH)*int64(len(fork.List[1].Algos)))
+	if trailHeight < 0 {
+		trailHeight = 1
+	}
+	firstBlock, _ := b.BlockByHeight(startHeight)
+	trailBlock, _ := b.BlockByHeight(trailHeight)
+	lastTime := lastNode.timestamp
+	if firstBlock != nil {
+		firstTime := firstBlock.MsgBlock().Header.Timestamp.Unix()
+		allTimeAverage = (float64(lastTime) - float64(firstTime)) / (float64(lastNode.height) - float64(firstBlock.Height()))
+	}
+	if trailBlock != nil {
+		trailTime := trailBlock.MsgBlock().Header.Timestamp.Unix()
+		trailTimeAverage = (float64(lastTime) - float64(trailTime)) / (float64(lastNode.height) - float64(trailBlock.Height()))
+	}
+	if len(timestamps) < 2 {
+		return fork.SecondPowLimitBits, nil
+	}
+	var adjusted, targetAdjusted, adjustment float64
+	if len(timestamps) > 1 {
+		numalgos := int64(len(fork.List[1].Algos))
+		target := fork.GetTargetTimePerBlock(nH) * numalgos
+		counter = 0
+		for i := 0; i < len(timestamps)-1; i++ {
+			factor := 0.75
+			if i == 0 {
+				f := factor
+				for j := 0; j < i; j++ {
+					f *= factor
 				}
-				pb = p.GetLastWithAlgo(algo)
+				factor = f
 			} else {
-				break
+				factor = 1.0
 			}
-			if pb != nil && pb.height > 0 {
-				// only add the timestamp if is not the same as the previous
-				timestamps = append(timestamps, float64(pb.timestamp))
-			} else {
+			adjustment = timestamps[i] - timestamps[i+1]
+			adjustment *= factor
+			switch {
+			case math.IsNaN(adjustment):
+				break
+			case adjustment == 0.0:
 				break
 			}
+			adjusted += adjustment
+			targetAdjusted += float64(target) * factor
+			counter++
 		}
-		allTimeAverage, trailTimeAverage := float64(fork.GetTargetTimePerBlock(nH)), float64(fork.GetTargetTimePerBlock(nH))
-		startHeight := fork.List[1].ActivationHeight
-		if b.chainParams.Name == "testnet" {
-			startHeight = 1
-		}
-		trailHeight := int32(int64(lastNode.height) -
-			fork.GetAveragingInterval(nH)*int64(len(fork.List[1].Algos)))
-		if trailHeight < 0 {
-			trailHeight = 1
-		}
-		firstBlock, _ := b.BlockByHeight(startHeight)
-		trailBlock, _ := b.BlockByHeight(trailHeight)
-		lastTime := lastNode.timestamp
-		if firstBlock != nil {
-			firstTime := firstBlock.MsgBlock().Header.Timestamp.Unix()
-			allTimeAverage = (float64(lastTime) - float64(firstTime)) / (float64(lastNode.height) - float64(firstBlock.Height()))
-		}
-		if trailBlock != nil {
-			trailTime := trailBlock.MsgBlock().Header.Timestamp.Unix()
-			trailTimeAverage = (float64(lastTime) - float64(trailTime)) / (float64(lastNode.height) - float64(trailBlock.Height()))
-		}
-		if len(timestamps) < 2 {
-			return fork.SecondPowLimitBits, nil
-		}
-		var adjusted, targetAdjusted, adjustment float64
-		if len(timestamps) > 1 {
-			numalgos := int64(len(fork.List[1].Algos))
-			target := fork.GetTargetTimePerBlock(nH) * numalgos
-			counter = 0
-			for i := 0; i < len(timestamps)-1; i++ {
-				factor := 0.75
-				if i == 0 {
-					f := factor
-					for j := 0; j < i; j++ {
-						f *= factor
-					}
-					factor = f
-				} else {
-					factor = 1.0
-				}
-				adjustment = timestamps[i] - timestamps[i+1]
-				adjustment *= factor
-				switch {
-				case math.IsNaN(adjustment):
-					break
-				case adjustment == 0.0:
-					break
-				}
-				adjusted += adjustment
-				targetAdjusted += float64(target) * factor
-				counter++
-			}
-		} else {
-			targetAdjusted = 100
-			adjusted = 100
-		}
-		var trailingTimestamps []float64
-		pb = lastNode
+	} else {
+		targetAdjusted = 100
+		adjusted = 100
+	}
+	var trailingTimestamps []float64
+	pb = lastNode
+	trailingTimestamps = append(
+		trailingTimestamps, float64(pb.timestamp))
+	counter = 1
+	for ; counter < int(fork.GetAveragingInterval(nH)) &&
+		pb.height > 2; counter++ {
+		pb = pb.RelativeAncestor(1)
 		trailingTimestamps = append(
 			trailingTimestamps, float64(pb.timestamp))
-		counter = 1
-		for ; counter < int(fork.GetAveragingInterval(nH)) &&
-			pb.height > 2; counter++ {
-			pb = pb.RelativeAncestor(1)
-			trailingTimestamps = append(
-				trailingTimestamps, float64(pb.timestamp))
-			counter++
-		}
-		var trailingAdjusted,
-			trailingTargetAdjusted,
-			trailingAdjustment float64
-		if len(trailingTimestamps) > 1 {
-			target := fork.GetTargetTimePerBlock(nH)
-			counter = 0
-			for i := 0; i < len(trailingTimestamps)-1; i++ {
-				factor := 0.81
-				if i == 0 {
-					f := factor
-					for j := 0; j < i; j++ {
-						f *= factor
-					}
-					factor = f
-				} else {
-					factor = 1.0
-				}
-				trailingAdjustment = trailingTimestamps[i] - trailingTimestamps[i+1]
-				trailingAdjustment *= factor
-				switch {
-				case math.IsNaN(trailingAdjustment):
-					break
-				case trailingAdjustment == 0.0:
-					break
+		counter++
+	}
+	var trailingAdjusted,
+		trailingTargetAdjusted,
+		trailingAdjustment float64
+	if len(trailingTimestamps) > 1 {
+		target := fork.GetTargetTimePerBlock(nH)
+		counter = 0
+		for i := 0; i < len(trailingTimestamps)-1; i++ {
+			factor := 0.81
+			if i == 0 {
+				f := factor
+				for j := 0; j < i; j++ {
+					f *= factor
 				}
-				trailingAdjusted += trailingAdjustment
-				trailingTargetAdjusted += float64(target) * factor
-				counter++
+				factor = f
+			} else {
+				factor = 1.0
 			}
-		} else {
-			trailingTargetAdjusted = 100
-			trailingAdjusted = 100
-		}
-		ttpb := float64(fork.GetTargetTimePerBlock(nH))
-		allTimeDivergence := allTimeAverage / ttpb
-		trailTimeDivergence := trailTimeAverage / ttpb
-		trailingTimeDivergence := trailingAdjusted / trailingTargetAdjusted
-		log <- cl.Trace{
-			"trailingtimedivergence",
-			trailingTimeDivergence,
-			trailingAdjusted,
-			trailingTargetAdjusted}
-		weighted := adjusted / targetAdjusted
-		adjustment = (weighted*weighted*weighted +
-			trailingTimeDivergence*trailingTimeDivergence*trailingTimeDivergence +
-			trailTimeDivergence*trailTimeDivergence*trailTimeDivergence +
-			allTimeDivergence*allTimeDivergence*allTimeDivergence) / 4.0
-		if adjustment < 0 {
-			fmt.Println("negative weight adjustment")
-			adjustment = allTimeDivergence
-		}
-		if math.IsNaN(adjustment) {
-			return lastNode.bits, nil
-		}
-		// Bias adjustment for difficulty reductions to reduce incidence of sub 1 second blocks
-		if adjustment < 0 {
-			adjustment = (1 - adjustment) * adjustment
-		}
-		bigadjustment := big.NewFloat(adjustment)
-		bigoldtarget := big.NewFloat(1.0).SetInt(CompactToBig(last.bits))
-		bigfnewtarget := big.NewFloat(1.0).Mul(bigadjustment, bigoldtarget)
-		newtarget, _ := bigfnewtarget.Int(nil)
-		if newtarget == nil {
-			return newTargetBits, nil
-		}
-		mintarget := CompactToBig(newTargetBits)
-		if newtarget.Cmp(mintarget) < 0 {
-			newTargetBits = BigToCompact(newtarget)
-			b.DifficultyAdjustments[algoname] = adjustment
-			if l {
-				log <- cl.Infof{
-					"%d: old %08x, new %08x, av %3.2f, tr %3.2f, tr wgtd %3.2f, alg wgtd %3.2f, blks %d, adj %0.1f%%, alg %s",
-					lastNode.height + 1, last.bits,
-					newTargetBits,
-					allTimeAverage,
-					trailTimeAverage,
-					trailingTimeDivergence * ttpb,
-					weighted * ttpb,
-					counter,
-					(1 - adjustment) * 100,
-					fork.List[1].AlgoVers[algo],
-				}
+			trailingAdjustment = trailingTimestamps[i] - trailingTimestamps[i+1]
+			trailingAdjustment *= factor
+			switch {
+			case math.IsNaN(trailingAdjustment):
+				break
+			case trailingAdjustment == 0.0:
+				break
 			}
+			trailingAdjusted += trailingAdjustment
+			trailingTargetAdjusted += float64(target) * factor
+			counter++
 		}
+	} else {
+		trailingTargetAdjusted = 100
+		trailingAdjusted = 100
+	}
+	ttpb := float64(fork.GetTargetTimePerBlock(nH))
+	allTimeDivergence := allTimeAverage / ttpb
+	trailTimeDivergence := trailTimeAverage / ttpb
+	trailingTimeDivergence := trailingAdjusted / trailingTargetAdjusted
+	log <- cl.Trace{
+		"trailingtimedivergence",
+		trailingTimeDivergence,
+		trailingAdjusted,
+		trailingTargetAdjusted}
+	weighted := adjusted / targetAdjusted
+	adjustment = (weighted*weighted*weighted +
+		trailingTimeDivergence*trailingTimeDivergence*trailingTimeDivergence +
+		trailTimeDivergence*trailTimeDivergence*trailTimeDivergence +
+		allTimeDivergence*allTimeDivergence*allTimeDivergence) / 4.0
+	if adjustment < 0 {
+		fmt.Println("negative weight adjustment")
+		adjustment = allTimeDivergence
+	}
+	if math.IsNaN(adjustment) {
+		return lastNode.bits, nil
+	}
+	// Bias adjustment for difficulty reductions to reduce incidence of sub 1 second blocks
+	if adjustment < 0 {
+		adjustment = (1 - adjustment) * adjustment
+	}
+	bigadjustment := big.NewFloat(adjustment)
+	bigoldtarget := big.NewFloat(1.0).SetInt(CompactToBig(last.bits))
+	bigfnewtarget := big.NewFloat(1.0).Mul(bigadjustment, bigoldtarget)
+	newtarget, _ := bigfnewtarget.Int(nil)
+	if newtarget == nil {
 		return newTargetBits, nil
 	}
-	// nH := lastNode.height + 1
-	// algo := fork.GetAlgoVer(algoname, nH)
-	return fork.GetMinBits(algoname, nH), nil
+	mintarget := CompactToBig(newTargetBits)
+	if newtarget.Cmp(mintarget) < 0 {
+		newTargetBits = BigToCompact(newtarget)
+		b.DifficultyAdjustments[algoname] = adjustment
+		if l {
+			log <- cl.Infof{
+				"%d: old %08x, new %08x, av %3.2f, tr %3.2f, tr wgtd %3.2f, alg wgtd %3.2f, blks %d, adj %0.1f%%, alg %s",
+				lastNode.height + 1, last.bits,
+				newTargetBits,
+				allTimeAverage,
+				trailTimeAverage,
+				trailingTimeDivergence * ttpb,
+				weighted * ttpb,
+				counter,
+				(1 - adjustment) * 100,
+				fork.List[1].AlgoVers[algo],
+			}
+		}
+	}
+	return newTargetBits, nil
 }
 // BigToCompact converts a whole number N to a compact representation using an unsigned 32-bit number.  The compact representation only provides 23 bits of precision, so values larger than (2^23 - 1) only encode the most significant digits of the number.  See CompactToBig for details.
 func BigToCompact(n *big.Int) uint32 {
@@ -0,0 +1,179 @@
+package chain
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+	txscript "git.parallelcoin.io/dev/9/pkg/chain/tx/script"
+	"git.parallelcoin.io/dev/9/pkg/chain/wire"
+	"git.parallelcoin.io/dev/9/pkg/util"
+	cl "git.parallelcoin.io/dev/9/pkg/util/cl"
+)
+// maxScriptTraceSteps is the most opcodes the script trace of an invalid block report holds, past which the scripts are still run but not traced.
+const maxScriptTraceSteps = 1000
+// InvalidBlockReport is the forensic bundle written for a block that failed validation, with what is needed to find out why from the block alone.
+type InvalidBlockReport struct {
+	Hash      string `json:"hash"`
+	Height    int32  `json:"height"`
+	PrevBlock string `json:"prevblock"`
+	Time      int64  `json:"time"`
+	Error     string `json:"error"`
+	ErrorCode string `json:"errorcode,omitempty"`
+	// Block is the serialized block in hex.
+	Block string `json:"block"`
+	// Tip is the best block the outputs spent by the block were looked up at, which is only its parent when the block extended the main chain.
+	Tip string `json:"tip"`
+	// FailingTx and FailingInput are the first transaction and input of the block whose scripts failed or whose output is missing, -1 when there is none.
+	FailingTx    int `json:"failingtx"`
+	FailingInput int `json:"failinginput"`
+	// ScriptError is the error the scripts of the failing input failed with, and ScriptTrace the opcodes they ran with the stack after each.
+	ScriptError string   `json:"scripterror,omitempty"`
+	ScriptTrace []string `json:"scripttrace,omitempty"`
+	// Utxos are the outputs spent by the failing transaction.
+	Utxos []InvalidBlockUtxo `json:"utxos,omitempty"`
+}
+// InvalidBlockUtxo is an output spent by a transaction of an invalid block, as the utxo set held it.
+type InvalidBlockUtxo struct {
+	Outpoint string `json:"outpoint"`
+	Missing  bool   `json:"missing,omitempty"`
+	Amount   int64  `json:"amount,omitempty"`
+	PkScript string `json:"pkscript,omitempty"`
+	Height   int32  `json:"height,omitempty"`
+	Coinbase bool   `json:"coinbase,omitempty"`
+}
+// dumpInvalidBlock writes the forensic report of a block that failed validation with err to the invalid block directory and logs where it is. Blocks that are only rejected as duplicates, for building on an invalid block or for their time being ahead of the node's are not dumped, since they say nothing about the rules the node runs. This function is safe for concurrent access.
+func (
+	b *BlockChain,
+) dumpInvalidBlock(
+	block *util.Block, err error) {
+	ruleErr, ok := err.(RuleError)
+	if !ok {
+		return
+	}
+	switch ruleErr.ErrorCode {
+	case ErrDuplicateBlock, ErrInvalidAncestorBlock, ErrTimeTooNew, ErrPrevBlockNotBest:
+		return
+	}
+	b.chainLock.RLock()
+	report, reportErr := b.invalidBlockReport(block, ruleErr)
+	b.chainLock.RUnlock()
+	if reportErr != nil {
+		log <- cl.Warn{"could not write report of invalid block", block.Hash(), ":", reportErr}
+		return
+	}
+	data, reportErr := json.MarshalIndent(report, "", "\t")
+	if reportErr == nil {
+		reportErr = os.MkdirAll(b.invalidBlockDir, 0700)
+	}
+	path := filepath.Join(b.invalidBlockDir, report.Hash+".json")
+	if reportErr == nil {
+		reportErr = ioutil.WriteFile(path, data, 0600)
+	}
+	if reportErr != nil {
+		log <- cl.Warn{"could not write report of invalid block", block.Hash(), ":", reportErr}
+		return
+	}
+	log <- cl.Warnf{"block %v failed validation, report written to %s", block.Hash(), path}
+}
+// invalidBlockReport collects the forensic report of a block that failed validation. The outputs the block spends are only known when its parent is in the block index, and are those of the utxo set at the tip of the main chain. This function MUST be called with the chain state lock held (for reads).
+func (
+	b *BlockChain,
+) invalidBlockReport(
+	block *util.Block, ruleErr RuleError) (*InvalidBlockReport, error) {
+	blockBytes, err := block.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	header := &block.MsgBlock().Header
+	tip := b.bestChain.Tip()
+	report := &InvalidBlockReport{
+		Hash:         block.Hash().String(),
+		Height:       block.Height(),
+		PrevBlock:    header.PrevBlock.String(),
+		Time:         time.Now().Unix(),
+		Error:        ruleErr.Error(),
+		ErrorCode:    ruleErr.ErrorCode.String(),
+		Block:        hex.EncodeToString(blockBytes),
+		Tip:          tip.hash.String(),
+		FailingTx:    -1,
+		FailingInput: -1,
+	}
+	parent := b.Index.LookupNode(&header.PrevBlock)
+	if parent == nil || len(block.Transactions()) == 0 {
+		return report, nil
+	}
+	node := newBlockNode(header, parent)
+	report.Height = node.height
+	block.SetHeight(node.height)
+	view := NewUtxoViewpoint()
+	view.SetBestHash(&tip.hash)
+	if err = view.fetchInputUtxos(b.utxoCache, block); err != nil {
+		return nil, err
+	}
+	scriptFlags, err := b.blockScriptFlags(node)
+	if err != nil {
+		return nil, err
+	}
+	// The first input that is missing its output or fails its scripts is the one reported.
+	for txIdx, tx := range block.Transactions()[1:] {
+		msgTx := tx.MsgTx()
+		for inIdx, txIn := range msgTx.TxIn {
+			entry := view.LookupEntry(txIn.PreviousOutPoint)
+			if entry == nil || entry.IsSpent() {
+				report.FailingTx, report.FailingInput = txIdx+1, inIdx
+				break
+			}
+			trace, scriptErr := traceScript(msgTx, inIdx, entry.PkScript(), entry.Amount(), scriptFlags)
+			if scriptErr != nil {
+				report.FailingTx, report.FailingInput = txIdx+1, inIdx
+				report.ScriptError = scriptErr.Error()
+				report.ScriptTrace = trace
+				break
+			}
+		}
+		if report.FailingTx < 0 {
+			continue
+		}
+		for _, txIn := range msgTx.TxIn {
+			utxo := InvalidBlockUtxo{Outpoint: txIn.PreviousOutPoint.String()}
+			entry := view.LookupEntry(txIn.PreviousOutPoint)
+			if entry == nil || entry.IsSpent() {
+				utxo.Missing = true
+			} else {
+				utxo.Amount = entry.Amount()
+				utxo.PkScript = hex.EncodeToString(entry.PkScript())
+				utxo.Height = entry.BlockHeight()
+				utxo.Coinbase = entry.IsCoinBase()
+			}
+			report.Utxos = append(report.Utxos, utxo)
+		}
+		break
+	}
+	return report, nil
+}
+// traceScript runs the scripts of an input of a transaction one opcode at a time and returns the opcodes run, each with the stack after it, along with the error the scripts failed with, if any.
+func traceScript(
+	tx *wire.MsgTx, txIdx int, pkScript []byte, amount int64, flags txscript.ScriptFlags) ([]string, error) {
+	vm, err := txscript.NewEngine(pkScript, tx, txIdx, flags, nil, txscript.NewTxSigHashes(tx), amount)
+	if err != nil {
+		return nil, err
+	}
+	var trace []string
+	for done := false; !done; {
+		dis, disErr := vm.DisasmPC()
+		if disErr != nil {
+			dis = fmt.Sprintf("(%v)", disErr)
+		}
+		done, err = vm.Step()
+		if len(trace) < maxScriptTraceSteps {
+			trace = append(trace, fmt.Sprintf("%s stack %x", dis, vm.GetStack()))
+		}
+		if err != nil {
+			return trace, err
+		}
+	}
+	return trace, vm.CheckErrorCondition(true)
+}
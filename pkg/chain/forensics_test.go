@@ -0,0 +1,33 @@
+package chain
+import (
+	"strings"
+	"testing"
+	txscript "git.parallelcoin.io/dev/9/pkg/chain/tx/script"
+	"git.parallelcoin.io/dev/9/pkg/chain/wire"
+)
+// TestTraceScript ensures the trace of the scripts of an input has each opcode run with the stack after it and ends with the error the scripts failed with.
+func TestTraceScript(
+	t *testing.T) {
+	tx := wire.NewMsgTx(wire.TxVersion)
+	tx.AddTxIn(&wire.TxIn{SignatureScript: []byte{txscript.Op2}})
+	tx.AddTxOut(&wire.TxOut{Value: 1, PkScript: []byte{txscript.OpTrue}})
+	tests := []struct {
+		pkScript []byte
+		fails    bool
+	}{
+		{[]byte{txscript.Op2, txscript.OpEqual}, false},
+		{[]byte{txscript.Op3, txscript.OpEqual}, true},
+	}
+	for _, test := range tests {
+		trace, err := traceScript(tx, 0, test.pkScript, 1, txscript.ScriptBip16)
+		if (err != nil) != test.fails {
+			t.Errorf("script %x failed with %v", test.pkScript, err)
+		}
+		if len(trace) != 3 {
+			t.Fatalf("script %x traced %d opcodes, want 3: %v", test.pkScript, len(trace), trace)
+		}
+		if !strings.HasPrefix(trace[2], "01:0001: OpEqual") {
+			t.Errorf("last opcode of script %x traced as %q", test.pkScript, trace[2])
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package fork
+import (
+	"fmt"
+	"sort"
+	chainhash "git.parallelcoin.io/dev/9/pkg/chain/hash"
+)
+// PowAlgo is a proof of work algorithm the hard forks can use by its name in their Algos
+type PowAlgo struct {
+	Name string
+	// Hash returns the proof of work hash of a serialized block header at a height
+	Hash func(bytes []byte, height int32) chainhash.Hash
+}
+// powAlgos are the registered proof of work algorithms by name
+var powAlgos = make(map[string]*PowAlgo)
+// RegisterAlgo adds a proof of work algorithm to the registry for the hard forks to use. Adding an algorithm to the chain takes registering it here and giving its AlgoParams in the Algos and AlgoVers of the hard fork it activates with, and the difficulty adjustment and the list of algorithms that can be configured for mining follow. It panics when an algorithm of the same name is already registered.
+func RegisterAlgo(algo *PowAlgo) {
+	if _, ok := powAlgos[algo.Name]; ok {
+		panic(fmt.Sprintf("proof of work algorithm %s is already registered", algo.Name))
+	}
+	powAlgos[algo.Name] = algo
+}
+// LookupAlgo returns the registered proof of work algorithm of a name, or nil when there is none
+func LookupAlgo(name string) *PowAlgo {
+	return powAlgos[name]
+}
+// AlgoNames returns the names of the algorithms blocks at a height can be mined with, sorted
+func AlgoNames(height int32) (names []string) {
+	for name := range List[GetCurrent(height)].Algos {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return
+}
+// AllAlgoNames returns the names of the algorithms of all of the hard forks, sorted
+func AllAlgoNames() (names []string) {
+	for name := range powAlgos {
+		if IsAlgo(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return
+}
+// IsAlgo returns whether an algorithm is used by any of the hard forks
+func IsAlgo(name string) bool {
+	for i := range List {
+		if _, ok := List[i].Algos[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+func init() {
+	for _, algo := range []*PowAlgo{
+		{"blake2b", plan9Hash(Blake2b)},
+		{"blake14lr", plan9Hash(Blake14lr)},
+		{"blake2s", plan9Hash(Blake2s)},
+		{"keccak", plan9Hash(Keccak)},
+		{"lyra2rev2", plan9Hash(Lyra2REv2)},
+		{"scrypt", forkedHash(Scrypt)},
+		{"sha256d", forkedHash(chainhash.DoubleHashB)},
+		{"skein", plan9Hash(Skein)},
+		{"stribog", plan9Hash(Stribog)},
+		{"x11", plan9Hash(X11)},
+	} {
+		RegisterAlgo(algo)
+	}
+	// Every algorithm of a hard fork must be registered and found by its version.
+	for _, hf := range List {
+		for name, params := range hf.Algos {
+			if LookupAlgo(name) == nil {
+				panic(fmt.Sprintf("hard fork %s uses unregistered proof of work algorithm %s", hf.Name, name))
+			}
+			if hf.AlgoVers[params.Version] != name {
+				panic(fmt.Sprintf("hard fork %s has no version %d for algorithm %s", hf.Name, params.Version, name))
+			}
+		}
+	}
+}
@@ -44,48 +44,11 @@ func Cryptonight7v2(
 	bytes []byte) []byte {
 	return cryptonight.Sum(bytes, 2)
 }
-// Hash computes the hash of bytes using the named hash
+// Hash computes the hash of bytes using the named hash, which is zero for a name no algorithm is registered with
 func Hash(
 	bytes []byte, name string, height int32) (out chainhash.Hash) {
-	switch name {
-	case "blake2b":
-		b := Argon2i(Cryptonight7v2(Blake2b(bytes)))
-		_ = out.SetBytes(rightShift(Blake2b(b)))
-	case "blake14lr":
-		b := Argon2i(Cryptonight7v2(Blake14lr(bytes)))
-		_ = out.SetBytes(rightShift(Blake14lr(b)))
-	case "blake2s":
-		b := Argon2i(Cryptonight7v2(Blake2s(bytes)))
-		_ = out.SetBytes(rightShift(Blake2s(b)))
-	case "lyra2rev2":
-		b := Argon2i(Cryptonight7v2(Lyra2REv2(bytes)))
-		_ = out.SetBytes(rightShift(Lyra2REv2(b)))
-	case "scrypt":
-		if GetCurrent(height) > 0 {
-			b := Argon2i(Cryptonight7v2(Scrypt(bytes)))
-			_ = out.SetBytes(rightShift(Scrypt(b)))
-		} else {
-			_ = out.SetBytes(Scrypt(bytes))
-		}
-	case "sha256d": // sha256d
-		if GetCurrent(height) > 0 {
-			b := Argon2i(Cryptonight7v2(chainhash.DoubleHashB(bytes)))
-			_ = out.SetBytes(rightShift(chainhash.DoubleHashB(b)))
-		} else {
-			_ = out.SetBytes(chainhash.DoubleHashB(bytes))
-		}
-	case "stribog":
-		b := Argon2i(Cryptonight7v2(Stribog(bytes)))
-		_ = out.SetBytes(rightShift(Stribog(b)))
-	case "skein":
-		b := Argon2i(Cryptonight7v2(Skein(bytes)))
-		_ = out.SetBytes(rightShift(Skein(b)))
-	case "x11":
-		b := Argon2i(Cryptonight7v2(X11(bytes)))
-		_ = out.SetBytes(rightShift(X11(b)))
-	case "keccak":
-		b := Argon2i(Cryptonight7v2(Keccak(bytes)))
-		_ = out.SetBytes(rightShift(Keccak(b)))
+	if algo := LookupAlgo(name); algo != nil {
+		out = algo.Hash(bytes, height)
 	}
 	return
 }
@@ -146,3 +109,24 @@ func rightShift(
 	copy(out, b[1:])
 	return
 }
+// plan9Hash returns the hash the Plan 9 hard fork uses for an algorithm, which hashes the result of Argon2i over Cryptonight7v2 over the algorithm with the algorithm again, less its first byte
+func plan9Hash(
+	algo func([]byte) []byte) func([]byte, int32) chainhash.Hash {
+	return func(bytes []byte, height int32) (out chainhash.Hash) {
+		b := Argon2i(Cryptonight7v2(algo(bytes)))
+		_ = out.SetBytes(rightShift(algo(b)))
+		return
+	}
+}
+// forkedHash returns the hash of an algorithm that was used before the Plan 9 hard fork, which is the plain algorithm before it and plan9Hash after
+func forkedHash(
+	algo func([]byte) []byte) func([]byte, int32) chainhash.Hash {
+	hash := plan9Hash(algo)
+	return func(bytes []byte, height int32) (out chainhash.Hash) {
+		if GetCurrent(height) > 0 {
+			return hash(bytes, height)
+		}
+		_ = out.SetBytes(algo(bytes))
+		return
+	}
+}
@@ -0,0 +1,14 @@
+package chainhash
+// DoubleHashBatch sets out[i] to hash(hash(in[i])) for each slice in in, as DoubleHashH does for one slice. On CPUs with AVX2 it hashes eight slices at once in the lanes of the vector registers, which is much faster for the many short slices of merkle trees: transactions, and the pairs of hashes their nodes are made from. CPUs with the SHA extensions, and other architectures, hash the slices one by one with crypto/sha256, which uses the SHA extensions where it can. out must be at least as long as in.
+func DoubleHashBatch(out []Hash, in [][]byte) {
+	if len(out) < len(in) {
+		panic("chainhash: DoubleHashBatch output shorter than input")
+	}
+	doubleHashBatch(out[:len(in)], in)
+}
+// doubleHashBatchGeneric is DoubleHashBatch in pure Go, hashing one slice at a time.
+func doubleHashBatchGeneric(out []Hash, in [][]byte) {
+	for i, b := range in {
+		out[i] = DoubleHashH(b)
+	}
+}
@@ -0,0 +1,154 @@
+package chainhash
+import (
+	"encoding/binary"
+	"sort"
+)
+// lanes is the number of slices blockAVX2 hashes at once, one in each 32 bit lane of the AVX2 registers.
+const lanes = 8
+// blockAVX2 runs the SHA-256 compression function on one block of each of eight messages. state[i] holds word i of the state of every lane, and msg[i] word i of every lane's block, already read as big endian.
+//go:noescape
+func blockAVX2(state *[8][lanes]uint32, msg *[16][lanes]uint32)
+// cpuid returns the registers the CPUID instruction sets for the given EAX and ECX.
+func cpuid(eaxArg, ecxArg uint32) (eax, ebx, ecx, edx uint32)
+// xgetbv returns which register states the operating system saves, from the XCR0 register.
+func xgetbv() (eax, edx uint32)
+// useAVX2 is whether to hash batches with blockAVX2. CPUs with the SHA extensions hash single slices with crypto/sha256 about as fast as eight at once with AVX2, so they do without it.
+var useAVX2 = hasAVX2() && !hasSHA()
+// hasSHA checks the CPU has the SHA extensions.
+func hasSHA() bool {
+	if maxID, _, _, _ := cpuid(0, 0); maxID < 7 {
+		return false
+	}
+	_, ebx7, _, _ := cpuid(7, 0)
+	return ebx7&(1<<29) != 0
+}
+// hasAVX2 checks the CPU and operating system support AVX2.
+func hasAVX2() bool {
+	maxID, _, _, _ := cpuid(0, 0)
+	if maxID < 7 {
+		return false
+	}
+	_, _, ecx1, _ := cpuid(1, 0)
+	const osxsave, avx = 1 << 27, 1 << 28
+	if ecx1&osxsave == 0 || ecx1&avx == 0 {
+		return false
+	}
+	// The XMM and YMM register states must both be saved.
+	if xcr0, _ := xgetbv(); xcr0&6 != 6 {
+		return false
+	}
+	_, ebx7, _, _ := cpuid(7, 0)
+	return ebx7&(1<<5) != 0
+}
+// sha256IV is the initial state of SHA-256.
+var sha256IV = [8]uint32{
+	0x6a09e667, 0xbb67ae85, 0x3c6ef372, 0xa54ff53a,
+	0x510e527f, 0x9b05688c, 0x1f83d9ab, 0x5be0cd19,
+}
+func doubleHashBatch(out []Hash, in [][]byte) {
+	if !useAVX2 || len(in) < 2 {
+		doubleHashBatchGeneric(out, in)
+		return
+	}
+	doubleHashBatchAVX2(out, in)
+}
+// doubleHashBatchAVX2 is DoubleHashBatch with blockAVX2, eight slices at a time.
+func doubleHashBatchAVX2(out []Hash, in [][]byte) {
+	// Slices of the same length are hashed together, so no lane is left idle while the others go through more blocks.
+	order := make([]int, len(in))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return len(in[order[i]]) < len(in[order[j]])
+	})
+	for len(order) > 0 {
+		n := lanes
+		if len(order) < n {
+			n = len(order)
+		}
+		doubleHashLanes(out, in, order[:n])
+		order = order[n:]
+	}
+}
+// doubleHashLanes double hashes the slices of in at the indexes in idx, at most eight of them, each in its own lane, writing the hashes to out at the same indexes.
+func doubleHashLanes(out []Hash, in [][]byte, idx []int) {
+	var (
+		state, first [8][lanes]uint32
+		msg          [16][lanes]uint32
+		tails        [lanes][128]byte
+		blocks       [lanes]int
+		maxBlocks    int
+	)
+	// The last one or two blocks of each slice are its remaining bytes followed by the padding: a one bit, zeros and the length in bits.
+	for l, i := range idx {
+		full := len(in[i]) / 64
+		rest := copy(tails[l][:], in[i][full*64:])
+		tails[l][rest] = 0x80
+		tailLen := 64
+		if rest >= 56 {
+			tailLen = 128
+		}
+		binary.BigEndian.PutUint64(tails[l][tailLen-8:], uint64(len(in[i]))*8)
+		blocks[l] = full + tailLen/64
+		if blocks[l] > maxBlocks {
+			maxBlocks = blocks[l]
+		}
+	}
+	for w := range state {
+		for l := range state[w] {
+			state[w][l] = sha256IV[w]
+		}
+	}
+	for j := 0; j < maxBlocks; j++ {
+		for l, i := range idx {
+			if j >= blocks[l] {
+				continue
+			}
+			var block []byte
+			if full := len(in[i]) / 64; j < full {
+				block = in[i][j*64:]
+			} else {
+				block = tails[l][(j-full)*64:]
+			}
+			for w := range msg {
+				msg[w][l] = binary.BigEndian.Uint32(block[w*4:])
+			}
+		}
+		blockAVX2(&state, &msg)
+		// The state of a lane whose slice has no more blocks is its first hash.
+		for l := range idx {
+			if blocks[l] == j+1 {
+				for w := range state {
+					first[w][l] = state[w][l]
+				}
+			}
+		}
+	}
+	// The second hash is of the 32 byte first hash, which fits in one block with its padding.
+	for w := range msg {
+		for l := range msg[w] {
+			switch {
+			case w < 8:
+				msg[w][l] = first[w][l]
+			case w == 8:
+				msg[w][l] = 0x80000000
+			case w == 15:
+				msg[w][l] = 256
+			default:
+				msg[w][l] = 0
+			}
+		}
+	}
+	for w := range state {
+		for l := range state[w] {
+			state[w][l] = sha256IV[w]
+		}
+	}
+	blockAVX2(&state, &msg)
+	for l, i := range idx {
+		for w := range state {
+			binary.BigEndian.PutUint32(out[i][w*4:], state[w][l])
+		}
+	}
+}
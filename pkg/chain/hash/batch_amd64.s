@@ -0,0 +1,314 @@
+#include "textflag.h"
+
+// The SHA-256 compression function on eight messages at once, one in each
+// 32 bit lane of the AVX2 registers. The state words a to h of the eight
+// lanes are kept in Y0 to Y7, and the sixteen message words the schedule
+// needs at any time in a ring on the stack. Rather than moving the state
+// along each round, the rounds take the registers rotated by one.
+
+// ROTR sets dst to x rotated right by n bits, using t.
+#define ROTR(x, n, t, dst) \
+	VPSRLD $(n), x, dst; \
+	VPSLLD $(32-(n)), x, t; \
+	VPOR t, dst, dst
+
+// LOADW puts message word i of the block in Y8 and the schedule ring.
+#define LOADW(i) \
+	VMOVDQU ((i)*32)(SI), Y8; \
+	VMOVDQU Y8, ((i)*32)(SP)
+
+// SCHEDW computes the next message word in Y8 from the words two, seven,
+// fifteen and sixteen before it in the ring, and stores it in place of the
+// last, which is in the same slot.
+#define SCHEDW(slot, w2, w7, w15) \
+	VMOVDQU ((w2)*32)(SP), Y9; \
+	ROTR(Y9, 17, Y11, Y8); \
+	ROTR(Y9, 19, Y11, Y10); \
+	VPXOR Y10, Y8, Y8; \
+	VPSRLD $10, Y9, Y10; \
+	VPXOR Y10, Y8, Y8; \
+	VPADDD ((w7)*32)(SP), Y8, Y8; \
+	VMOVDQU ((w15)*32)(SP), Y9; \
+	ROTR(Y9, 7, Y11, Y10); \
+	ROTR(Y9, 18, Y11, Y12); \
+	VPXOR Y12, Y10, Y10; \
+	VPSRLD $3, Y9, Y12; \
+	VPXOR Y12, Y10, Y10; \
+	VPADDD Y10, Y8, Y8; \
+	VPADDD ((slot)*32)(SP), Y8, Y8; \
+	VMOVDQU Y8, ((slot)*32)(SP)
+
+// ROUND does round i with the message word in Y8, leaving the new e in d
+// and the new a in h.
+#define ROUND(a, b, c, d, e, f, g, h, i) \
+	VPBROADCASTD ((i)*4)(DX), Y9; \
+	VPADDD Y9, Y8, Y8; \
+	VPADDD h, Y8, Y8; \
+	ROTR(e, 6, Y11, Y9); \
+	ROTR(e, 11, Y11, Y10); \
+	VPXOR Y10, Y9, Y9; \
+	ROTR(e, 25, Y11, Y10); \
+	VPXOR Y10, Y9, Y9; \
+	VPADDD Y9, Y8, Y8; \
+	VPAND f, e, Y9; \
+	VPANDN g, e, Y10; \
+	VPXOR Y10, Y9, Y9; \
+	VPADDD Y9, Y8, Y8; \
+	VPADDD Y8, d, d; \
+	ROTR(a, 2, Y11, Y9); \
+	ROTR(a, 13, Y11, Y10); \
+	VPXOR Y10, Y9, Y9; \
+	ROTR(a, 22, Y11, Y10); \
+	VPXOR Y10, Y9, Y9; \
+	VPOR b, a, Y10; \
+	VPAND c, Y10, Y10; \
+	VPAND b, a, Y11; \
+	VPOR Y11, Y10, Y10; \
+	VPADDD Y10, Y9, Y9; \
+	VPADDD Y9, Y8, h
+
+// func blockAVX2(state *[8][lanes]uint32, msg *[16][lanes]uint32)
+TEXT ·blockAVX2(SB), NOSPLIT, $512-16
+	MOVQ state+0(FP), DI
+	MOVQ msg+8(FP), SI
+	LEAQ k256<>(SB), DX
+	VMOVDQU 0(DI), Y0
+	VMOVDQU 32(DI), Y1
+	VMOVDQU 64(DI), Y2
+	VMOVDQU 96(DI), Y3
+	VMOVDQU 128(DI), Y4
+	VMOVDQU 160(DI), Y5
+	VMOVDQU 192(DI), Y6
+	VMOVDQU 224(DI), Y7
+	LOADW(0)
+	ROUND(Y0, Y1, Y2, Y3, Y4, Y5, Y6, Y7, 0)
+	LOADW(1)
+	ROUND(Y7, Y0, Y1, Y2, Y3, Y4, Y5, Y6, 1)
+	LOADW(2)
+	ROUND(Y6, Y7, Y0, Y1, Y2, Y3, Y4, Y5, 2)
+	LOADW(3)
+	ROUND(Y5, Y6, Y7, Y0, Y1, Y2, Y3, Y4, 3)
+	LOADW(4)
+	ROUND(Y4, Y5, Y6, Y7, Y0, Y1, Y2, Y3, 4)
+	LOADW(5)
+	ROUND(Y3, Y4, Y5, Y6, Y7, Y0, Y1, Y2, 5)
+	LOADW(6)
+	ROUND(Y2, Y3, Y4, Y5, Y6, Y7, Y0, Y1, 6)
+	LOADW(7)
+	ROUND(Y1, Y2, Y3, Y4, Y5, Y6, Y7, Y0, 7)
+	LOADW(8)
+	ROUND(Y0, Y1, Y2, Y3, Y4, Y5, Y6, Y7, 8)
+	LOADW(9)
+	ROUND(Y7, Y0, Y1, Y2, Y3, Y4, Y5, Y6, 9)
+	LOADW(10)
+	ROUND(Y6, Y7, Y0, Y1, Y2, Y3, Y4, Y5, 10)
+	LOADW(11)
+	ROUND(Y5, Y6, Y7, Y0, Y1, Y2, Y3, Y4, 11)
+	LOADW(12)
+	ROUND(Y4, Y5, Y6, Y7, Y0, Y1, Y2, Y3, 12)
+	LOADW(13)
+	ROUND(Y3, Y4, Y5, Y6, Y7, Y0, Y1, Y2, 13)
+	LOADW(14)
+	ROUND(Y2, Y3, Y4, Y5, Y6, Y7, Y0, Y1, 14)
+	LOADW(15)
+	ROUND(Y1, Y2, Y3, Y4, Y5, Y6, Y7, Y0, 15)
+	SCHEDW(0, 14, 9, 1)
+	ROUND(Y0, Y1, Y2, Y3, Y4, Y5, Y6, Y7, 16)
+	SCHEDW(1, 15, 10, 2)
+	ROUND(Y7, Y0, Y1, Y2, Y3, Y4, Y5, Y6, 17)
+	SCHEDW(2, 0, 11, 3)
+	ROUND(Y6, Y7, Y0, Y1, Y2, Y3, Y4, Y5, 18)
+	SCHEDW(3, 1, 12, 4)
+	ROUND(Y5, Y6, Y7, Y0, Y1, Y2, Y3, Y4, 19)
+	SCHEDW(4, 2, 13, 5)
+	ROUND(Y4, Y5, Y6, Y7, Y0, Y1, Y2, Y3, 20)
+	SCHEDW(5, 3, 14, 6)
+	ROUND(Y3, Y4, Y5, Y6, Y7, Y0, Y1, Y2, 21)
+	SCHEDW(6, 4, 15, 7)
+	ROUND(Y2, Y3, Y4, Y5, Y6, Y7, Y0, Y1, 22)
+	SCHEDW(7, 5, 0, 8)
+	ROUND(Y1, Y2, Y3, Y4, Y5, Y6, Y7, Y0, 23)
+	SCHEDW(8, 6, 1, 9)
+	ROUND(Y0, Y1, Y2, Y3, Y4, Y5, Y6, Y7, 24)
+	SCHEDW(9, 7, 2, 10)
+	ROUND(Y7, Y0, Y1, Y2, Y3, Y4, Y5, Y6, 25)
+	SCHEDW(10, 8, 3, 11)
+	ROUND(Y6, Y7, Y0, Y1, Y2, Y3, Y4, Y5, 26)
+	SCHEDW(11, 9, 4, 12)
+	ROUND(Y5, Y6, Y7, Y0, Y1, Y2, Y3, Y4, 27)
+	SCHEDW(12, 10, 5, 13)
+	ROUND(Y4, Y5, Y6, Y7, Y0, Y1, Y2, Y3, 28)
+	SCHEDW(13, 11, 6, 14)
+	ROUND(Y3, Y4, Y5, Y6, Y7, Y0, Y1, Y2, 29)
+	SCHEDW(14, 12, 7, 15)
+	ROUND(Y2, Y3, Y4, Y5, Y6, Y7, Y0, Y1, 30)
+	SCHEDW(15, 13, 8, 0)
+	ROUND(Y1, Y2, Y3, Y4, Y5, Y6, Y7, Y0, 31)
+	SCHEDW(0, 14, 9, 1)
+	ROUND(Y0, Y1, Y2, Y3, Y4, Y5, Y6, Y7, 32)
+	SCHEDW(1, 15, 10, 2)
+	ROUND(Y7, Y0, Y1, Y2, Y3, Y4, Y5, Y6, 33)
+	SCHEDW(2, 0, 11, 3)
+	ROUND(Y6, Y7, Y0, Y1, Y2, Y3, Y4, Y5, 34)
+	SCHEDW(3, 1, 12, 4)
+	ROUND(Y5, Y6, Y7, Y0, Y1, Y2, Y3, Y4, 35)
+	SCHEDW(4, 2, 13, 5)
+	ROUND(Y4, Y5, Y6, Y7, Y0, Y1, Y2, Y3, 36)
+	SCHEDW(5, 3, 14, 6)
+	ROUND(Y3, Y4, Y5, Y6, Y7, Y0, Y1, Y2, 37)
+	SCHEDW(6, 4, 15, 7)
+	ROUND(Y2, Y3, Y4, Y5, Y6, Y7, Y0, Y1, 38)
+	SCHEDW(7, 5, 0, 8)
+	ROUND(Y1, Y2, Y3, Y4, Y5, Y6, Y7, Y0, 39)
+	SCHEDW(8, 6, 1, 9)
+	ROUND(Y0, Y1, Y2, Y3, Y4, Y5, Y6, Y7, 40)
+	SCHEDW(9, 7, 2, 10)
+	ROUND(Y7, Y0, Y1, Y2, Y3, Y4, Y5, Y6, 41)
+	SCHEDW(10, 8, 3, 11)
+	ROUND(Y6, Y7, Y0, Y1, Y2, Y3, Y4, Y5, 42)
+	SCHEDW(11, 9, 4, 12)
+	ROUND(Y5, Y6, Y7, Y0, Y1, Y2, Y3, Y4, 43)
+	SCHEDW(12, 10, 5, 13)
+	ROUND(Y4, Y5, Y6, Y7, Y0, Y1, Y2, Y3, 44)
+	SCHEDW(13, 11, 6, 14)
+	ROUND(Y3, Y4, Y5, Y6, Y7, Y0, Y1, Y2, 45)
+	SCHEDW(14, 12, 7, 15)
+	ROUND(Y2, Y3, Y4, Y5, Y6, Y7, Y0, Y1, 46)
+	SCHEDW(15, 13, 8, 0)
+	ROUND(Y1, Y2, Y3, Y4, Y5, Y6, Y7, Y0, 47)
+	SCHEDW(0, 14, 9, 1)
+	ROUND(Y0, Y1, Y2, Y3, Y4, Y5, Y6, Y7, 48)
+	SCHEDW(1, 15, 10, 2)
+	ROUND(Y7, Y0, Y1, Y2, Y3, Y4, Y5, Y6, 49)
+	SCHEDW(2, 0, 11, 3)
+	ROUND(Y6, Y7, Y0, Y1, Y2, Y3, Y4, Y5, 50)
+	SCHEDW(3, 1, 12, 4)
+	ROUND(Y5, Y6, Y7, Y0, Y1, Y2, Y3, Y4, 51)
+	SCHEDW(4, 2, 13, 5)
+	ROUND(Y4, Y5, Y6, Y7, Y0, Y1, Y2, Y3, 52)
+	SCHEDW(5, 3, 14, 6)
+	ROUND(Y3, Y4, Y5, Y6, Y7, Y0, Y1, Y2, 53)
+	SCHEDW(6, 4, 15, 7)
+	ROUND(Y2, Y3, Y4, Y5, Y6, Y7, Y0, Y1, 54)
+	SCHEDW(7, 5, 0, 8)
+	ROUND(Y1, Y2, Y3, Y4, Y5, Y6, Y7, Y0, 55)
+	SCHEDW(8, 6, 1, 9)
+	ROUND(Y0, Y1, Y2, Y3, Y4, Y5, Y6, Y7, 56)
+	SCHEDW(9, 7, 2, 10)
+	ROUND(Y7, Y0, Y1, Y2, Y3, Y4, Y5, Y6, 57)
+	SCHEDW(10, 8, 3, 11)
+	ROUND(Y6, Y7, Y0, Y1, Y2, Y3, Y4, Y5, 58)
+	SCHEDW(11, 9, 4, 12)
+	ROUND(Y5, Y6, Y7, Y0, Y1, Y2, Y3, Y4, 59)
+	SCHEDW(12, 10, 5, 13)
+	ROUND(Y4, Y5, Y6, Y7, Y0, Y1, Y2, Y3, 60)
+	SCHEDW(13, 11, 6, 14)
+	ROUND(Y3, Y4, Y5, Y6, Y7, Y0, Y1, Y2, 61)
+	SCHEDW(14, 12, 7, 15)
+	ROUND(Y2, Y3, Y4, Y5, Y6, Y7, Y0, Y1, 62)
+	SCHEDW(15, 13, 8, 0)
+	ROUND(Y1, Y2, Y3, Y4, Y5, Y6, Y7, Y0, 63)
+	VPADDD 0(DI), Y0, Y0
+	VPADDD 32(DI), Y1, Y1
+	VPADDD 64(DI), Y2, Y2
+	VPADDD 96(DI), Y3, Y3
+	VPADDD 128(DI), Y4, Y4
+	VPADDD 160(DI), Y5, Y5
+	VPADDD 192(DI), Y6, Y6
+	VPADDD 224(DI), Y7, Y7
+	VMOVDQU Y0, 0(DI)
+	VMOVDQU Y1, 32(DI)
+	VMOVDQU Y2, 64(DI)
+	VMOVDQU Y3, 96(DI)
+	VMOVDQU Y4, 128(DI)
+	VMOVDQU Y5, 160(DI)
+	VMOVDQU Y6, 192(DI)
+	VMOVDQU Y7, 224(DI)
+	VZEROUPPER
+	RET
+
+// func cpuid(eaxArg, ecxArg uint32) (eax, ebx, ecx, edx uint32)
+TEXT ·cpuid(SB), NOSPLIT, $0-24
+	MOVL eaxArg+0(FP), AX
+	MOVL ecxArg+4(FP), CX
+	CPUID
+	MOVL AX, eax+8(FP)
+	MOVL BX, ebx+12(FP)
+	MOVL CX, ecx+16(FP)
+	MOVL DX, edx+20(FP)
+	RET
+
+// func xgetbv() (eax, edx uint32)
+TEXT ·xgetbv(SB), NOSPLIT, $0-8
+	MOVL $0, CX
+	XGETBV
+	MOVL AX, eax+0(FP)
+	MOVL DX, edx+4(FP)
+	RET
+
+// k256 holds the round constants.
+DATA k256<>+0x00(SB)/4, $0x428a2f98
+DATA k256<>+0x04(SB)/4, $0x71374491
+DATA k256<>+0x08(SB)/4, $0xb5c0fbcf
+DATA k256<>+0x0c(SB)/4, $0xe9b5dba5
+DATA k256<>+0x10(SB)/4, $0x3956c25b
+DATA k256<>+0x14(SB)/4, $0x59f111f1
+DATA k256<>+0x18(SB)/4, $0x923f82a4
+DATA k256<>+0x1c(SB)/4, $0xab1c5ed5
+DATA k256<>+0x20(SB)/4, $0xd807aa98
+DATA k256<>+0x24(SB)/4, $0x12835b01
+DATA k256<>+0x28(SB)/4, $0x243185be
+DATA k256<>+0x2c(SB)/4, $0x550c7dc3
+DATA k256<>+0x30(SB)/4, $0x72be5d74
+DATA k256<>+0x34(SB)/4, $0x80deb1fe
+DATA k256<>+0x38(SB)/4, $0x9bdc06a7
+DATA k256<>+0x3c(SB)/4, $0xc19bf174
+DATA k256<>+0x40(SB)/4, $0xe49b69c1
+DATA k256<>+0x44(SB)/4, $0xefbe4786
+DATA k256<>+0x48(SB)/4, $0x0fc19dc6
+DATA k256<>+0x4c(SB)/4, $0x240ca1cc
+DATA k256<>+0x50(SB)/4, $0x2de92c6f
+DATA k256<>+0x54(SB)/4, $0x4a7484aa
+DATA k256<>+0x58(SB)/4, $0x5cb0a9dc
+DATA k256<>+0x5c(SB)/4, $0x76f988da
+DATA k256<>+0x60(SB)/4, $0x983e5152
+DATA k256<>+0x64(SB)/4, $0xa831c66d
+DATA k256<>+0x68(SB)/4, $0xb00327c8
+DATA k256<>+0x6c(SB)/4, $0xbf597fc7
+DATA k256<>+0x70(SB)/4, $0xc6e00bf3
+DATA k256<>+0x74(SB)/4, $0xd5a79147
+DATA k256<>+0x78(SB)/4, $0x06ca6351
+DATA k256<>+0x7c(SB)/4, $0x14292967
+DATA k256<>+0x80(SB)/4, $0x27b70a85
+DATA k256<>+0x84(SB)/4, $0x2e1b2138
+DATA k256<>+0x88(SB)/4, $0x4d2c6dfc
+DATA k256<>+0x8c(SB)/4, $0x53380d13
+DATA k256<>+0x90(SB)/4, $0x650a7354
+DATA k256<>+0x94(SB)/4, $0x766a0abb
+DATA k256<>+0x98(SB)/4, $0x81c2c92e
+DATA k256<>+0x9c(SB)/4, $0x92722c85
+DATA k256<>+0xa0(SB)/4, $0xa2bfe8a1
+DATA k256<>+0xa4(SB)/4, $0xa81a664b
+DATA k256<>+0xa8(SB)/4, $0xc24b8b70
+DATA k256<>+0xac(SB)/4, $0xc76c51a3
+DATA k256<>+0xb0(SB)/4, $0xd192e819
+DATA k256<>+0xb4(SB)/4, $0xd6990624
+DATA k256<>+0xb8(SB)/4, $0xf40e3585
+DATA k256<>+0xbc(SB)/4, $0x106aa070
+DATA k256<>+0xc0(SB)/4, $0x19a4c116
+DATA k256<>+0xc4(SB)/4, $0x1e376c08
+DATA k256<>+0xc8(SB)/4, $0x2748774c
+DATA k256<>+0xcc(SB)/4, $0x34b0bcb5
+DATA k256<>+0xd0(SB)/4, $0x391c0cb3
+DATA k256<>+0xd4(SB)/4, $0x4ed8aa4a
+DATA k256<>+0xd8(SB)/4, $0x5b9cca4f
+DATA k256<>+0xdc(SB)/4, $0x682e6ff3
+DATA k256<>+0xe0(SB)/4, $0x748f82ee
+DATA k256<>+0xe4(SB)/4, $0x78a5636f
+DATA k256<>+0xe8(SB)/4, $0x84c87814
+DATA k256<>+0xec(SB)/4, $0x8cc70208
+DATA k256<>+0xf0(SB)/4, $0x90befffa
+DATA k256<>+0xf4(SB)/4, $0xa4506ceb
+DATA k256<>+0xf8(SB)/4, $0xbef9a3f7
+DATA k256<>+0xfc(SB)/4, $0xc67178f2
+GLOBL k256<>(SB), RODATA|NOPTR, $256
@@ -0,0 +1,18 @@
+package chainhash
+import "testing"
+// TestDoubleHashBatchAVX2 tests the AVX2 implementation wherever the CPU can run it, including where DoubleHashBatch does not use it.
+func TestDoubleHashBatchAVX2(
+	t *testing.T) {
+	if !hasAVX2() {
+		t.Skip("no AVX2")
+	}
+	testDoubleHashBatch(t, doubleHashBatchAVX2)
+}
+// BenchmarkDoubleHashBatchAVX2 hashes the pairs of BenchmarkDoubleHashBatch with the AVX2 implementation.
+func BenchmarkDoubleHashBatchAVX2(
+	b *testing.B) {
+	if !hasAVX2() {
+		b.Skip("no AVX2")
+	}
+	benchmarkDoubleHashBatch(b, doubleHashBatchAVX2)
+}
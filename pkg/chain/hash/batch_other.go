@@ -0,0 +1,7 @@
+// +build !amd64
+
+package chainhash
+// doubleHashBatch has no vector implementation on this architecture.
+func doubleHashBatch(out []Hash, in [][]byte) {
+	doubleHashBatchGeneric(out, in)
+}
@@ -0,0 +1,54 @@
+package chainhash
+import (
+	"math/rand"
+	"testing"
+)
+// TestDoubleHashBatch ensures DoubleHashBatch gives the same hashes as DoubleHashH.
+func TestDoubleHashBatch(
+	t *testing.T) {
+	testDoubleHashBatch(t, DoubleHashBatch)
+}
+// testDoubleHashBatch checks a batch implementation against DoubleHashH for slices of lengths around the block and padding boundaries, in batches of sizes that do and don't fill the lanes.
+func testDoubleHashBatch(
+	t *testing.T, batch func([]Hash, [][]byte)) {
+	r := rand.New(rand.NewSource(1))
+	for _, n := range []int{0, 1, 2, 7, 8, 9, 17, 100} {
+		in := make([][]byte, n)
+		for i := range in {
+			lengths := []int{0, 1, 32, 55, 56, 63, 64, 65, 119, 120, 128, 200, 1000}
+			in[i] = make([]byte, lengths[r.Intn(len(lengths))]+r.Intn(2))
+			r.Read(in[i])
+		}
+		out := make([]Hash, n)
+		batch(out, in)
+		for i, b := range in {
+			if want := DoubleHashH(b); out[i] != want {
+				t.Errorf("batch of %d: hash of %d bytes is %v, want %v", n, len(b), out[i], want)
+			}
+		}
+	}
+}
+func benchmarkDoubleHashBatch(
+	b *testing.B, batch func([]Hash, [][]byte)) {
+	in := make([][]byte, 1024)
+	for i := range in {
+		in[i] = make([]byte, 64)
+		in[i][0] = byte(i)
+	}
+	out := make([]Hash, len(in))
+	b.SetBytes(int64(len(in) * 64))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		batch(out, in)
+	}
+}
+// BenchmarkDoubleHashBatch hashes the 64 byte pairs of hashes of a merkle tree level with DoubleHashBatch.
+func BenchmarkDoubleHashBatch(
+	b *testing.B) {
+	benchmarkDoubleHashBatch(b, DoubleHashBatch)
+}
+// BenchmarkDoubleHashBatchGeneric hashes the same pairs one at a time.
+func BenchmarkDoubleHashBatchGeneric(
+	b *testing.B) {
+	benchmarkDoubleHashBatch(b, doubleHashBatchGeneric)
+}
@@ -0,0 +1,125 @@
+package chain
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"time"
+	chaincfg "git.parallelcoin.io/dev/9/pkg/chain/config"
+	"git.parallelcoin.io/dev/9/pkg/chain/fork"
+	"git.parallelcoin.io/dev/9/pkg/chain/wire"
+)
+// headerFileMagic starts a file of block headers written by ExportHeaders, followed by the network and the number of headers in it.
+var headerFileMagic = [4]byte{'9', 'h', 'd', 'r'}
+// headerFileEntrySize is the size of each header in a headers file, which leaves out the hash of the previous block since it is the hash of the header before it.
+const headerFileEntrySize = 48
+// ExportHeaders writes the headers of the main chain after the genesis block to w, for ReadHeaders to read on another node, and returns the height of the last one. This function is safe for concurrent access.
+func (
+	b *BlockChain,
+) ExportHeaders(
+	w io.Writer) (int32, error) {
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+	tip := b.bestChain.Tip()
+	bw := bufio.NewWriter(w)
+	if err := b.writeHeaders(bw, tip); err != nil {
+		return 0, err
+	}
+	return tip.height, bw.Flush()
+}
+// writeHeaders writes the headers of the main chain after the genesis block up to tip to bw as ExportHeaders does. This function MUST be called with the chain lock held (for reads).
+func (
+	b *BlockChain,
+) writeHeaders(
+	bw *bufio.Writer, tip *blockNode) error {
+	var prefix [12]byte
+	copy(prefix[:4], headerFileMagic[:])
+	byteOrder.PutUint32(prefix[4:8], uint32(b.chainParams.Net))
+	byteOrder.PutUint32(prefix[8:12], uint32(tip.height))
+	if _, err := bw.Write(prefix[:]); err != nil {
+		return err
+	}
+	var entry [headerFileEntrySize]byte
+	for height := int32(1); height <= tip.height; height++ {
+		node := b.bestChain.NodeByHeight(height)
+		byteOrder.PutUint32(entry[0:4], uint32(node.version))
+		copy(entry[4:36], node.merkleRoot[:])
+		byteOrder.PutUint32(entry[36:40], uint32(node.timestamp))
+		byteOrder.PutUint32(entry[40:44], node.bits)
+		byteOrder.PutUint32(entry[44:48], node.nonce)
+		if _, err := bw.Write(entry[:]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+// ReadHeaders reads the headers ExportHeaders wrote for the network of params from r. Each header is linked to the one before it, the first to the genesis block, by its hash, so the headers returned always form a chain, the header at index i being that of the block at height i+1. Their proof of work is not checked, which CheckHeaders does.
+func ReadHeaders(
+	r io.Reader, params *chaincfg.Params) ([]wire.BlockHeader, error) {
+	return readHeaders(bufio.NewReader(r), params)
+}
+// readHeaders reads the headers ExportHeaders wrote from br as ReadHeaders does, leaving what follows them in br.
+func readHeaders(
+	br *bufio.Reader, params *chaincfg.Params) ([]wire.BlockHeader, error) {
+	var prefix [12]byte
+	if _, err := io.ReadFull(br, prefix[:]); err != nil {
+		return nil, fmt.Errorf("reading headers file: %v", err)
+	}
+	if string(prefix[:4]) != string(headerFileMagic[:]) {
+		return nil, fmt.Errorf("not a headers file")
+	}
+	if net := wire.BitcoinNet(byteOrder.Uint32(prefix[4:8])); net != params.Net {
+		return nil, fmt.Errorf("headers file is for network %v, not %v", net, params.Net)
+	}
+	count := byteOrder.Uint32(prefix[8:12])
+	// The count is only a hint for the size of the slice, so a corrupt one does not allocate more than a chain of a few million blocks would.
+	sizeHint := count
+	if sizeHint > 1<<22 {
+		sizeHint = 1 << 22
+	}
+	headers := make([]wire.BlockHeader, 0, sizeHint)
+	// The genesis hash of some of the test networks is not the hash of their genesis header, which is what the chain links the first block to.
+	prevHash := params.GenesisBlock.BlockHash()
+	var entry [headerFileEntrySize]byte
+	for i := uint32(0); i < count; i++ {
+		if _, err := io.ReadFull(br, entry[:]); err != nil {
+			return nil, fmt.Errorf("reading header %d of %d: %v", i+1, count, err)
+		}
+		header := wire.BlockHeader{
+			Version:   int32(byteOrder.Uint32(entry[0:4])),
+			PrevBlock: prevHash,
+			Timestamp: time.Unix(int64(byteOrder.Uint32(entry[36:40])), 0),
+			Bits:      byteOrder.Uint32(entry[40:44]),
+			Nonce:     byteOrder.Uint32(entry[44:48]),
+		}
+		copy(header.MerkleRoot[:], entry[4:36])
+		headers = append(headers, header)
+		prevHash = header.BlockHash()
+	}
+	return headers, nil
+}
+// CheckHeaders checks the proof of work of each of the headers ReadHeaders returned against the target in its bits and the minimum difficulty of its algorithm, and that the chain they form passes through the checkpoints at or below its tip. Whether the bits are those the difficulty adjustment asks for is only known when the blocks are connected, so the headers are good for finding the blocks of the chain, not for skipping their validation.
+func CheckHeaders(
+	headers []wire.BlockHeader, checkpoints []chaincfg.Checkpoint, interrupt <-chan struct{}) error {
+	for i := range headers {
+		if i%1000 == 0 && interruptRequested(interrupt) {
+			return errInterruptRequested
+		}
+		height := int32(i + 1)
+		header := &headers[i]
+		powLimit := fork.GetMinDiff(fork.GetAlgoName(header.Version, height), height)
+		if err := checkProofOfWork(header, powLimit, BFNone, height); err != nil {
+			return fmt.Errorf("header at height %d: %v", height, err)
+		}
+	}
+	for _, checkpoint := range checkpoints {
+		if checkpoint.Height < 1 || int(checkpoint.Height) > len(headers) {
+			continue
+		}
+		hash := headers[checkpoint.Height-1].BlockHash()
+		if !hash.IsEqual(checkpoint.Hash) {
+			return fmt.Errorf("header at height %d is %v, not checkpoint %v",
+				checkpoint.Height, hash, checkpoint.Hash)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package chain
+import (
+	"bytes"
+	"testing"
+	"time"
+	chaincfg "git.parallelcoin.io/dev/9/pkg/chain/config"
+)
+// TestHeadersFile ensures the headers ExportHeaders writes are read back by ReadHeaders as the headers of the main chain, and that a file for another network or a truncated one is rejected.
+func TestHeadersFile(
+	t *testing.T) {
+	chain := newFakeChain(&chaincfg.MainNetParams)
+	node := chain.bestChain.Genesis()
+	nodes := make([]*blockNode, 20)
+	for i := range nodes {
+		node = newFakeNode(node, 2, 0x1e0fffff, time.Unix(int64(i+1)*60, 0))
+		chain.Index.AddNode(node)
+		nodes[i] = node
+	}
+	chain.bestChain.SetTip(tstTip(nodes))
+	var buf bytes.Buffer
+	height, err := chain.ExportHeaders(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if height != 20 || buf.Len() != 12+20*headerFileEntrySize {
+		t.Fatalf("exported %d headers in %d bytes", height, buf.Len())
+	}
+	headers, err := ReadHeaders(bytes.NewReader(buf.Bytes()), &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(headers) != len(nodes) {
+		t.Fatalf("read %d headers, want %d", len(headers), len(nodes))
+	}
+	for i, node := range nodes {
+		if headers[i] != node.Header() {
+			t.Errorf("header %d is %+v, want %+v", i+1, headers[i], node.Header())
+		}
+	}
+	if _, err = ReadHeaders(bytes.NewReader(buf.Bytes()), &chaincfg.TestNet3Params); err == nil {
+		t.Error("headers file for another network was read")
+	}
+	if _, err = ReadHeaders(bytes.NewReader(buf.Bytes()[:buf.Len()-1]), &chaincfg.MainNetParams); err == nil {
+		t.Error("truncated headers file was read")
+	}
+}
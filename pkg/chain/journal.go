@@ -0,0 +1,171 @@
+package chain
+import (
+	"fmt"
+	chainhash "git.parallelcoin.io/dev/9/pkg/chain/hash"
+	database "git.parallelcoin.io/dev/9/pkg/db"
+	"git.parallelcoin.io/dev/9/pkg/util"
+	cl "git.parallelcoin.io/dev/9/pkg/util/cl"
+)
+// chainJournalKeyName is the name of the db key used to store the journal of reorganizations.  Each block disconnected or connected by a reorganization is written in its own database transaction, which leaves the chain state consistent but possibly at a block between the old and new tips when the node stops partway through, so the journal is written before the first of them and removed after the last.  Finding it when the chain is loaded means a reorganization was interrupted.
+var chainJournalKeyName = []byte("chainjournal")
+// utxoFlushJournalKeyName is the name of the db key used to store the journal of utxo cache flushes.  A flush with more changes than fit in one database transaction writes them in batches, which leave the utxo set partly at the block it was flushed to when the node stops partway through, so the journal is written before the first of them and removed with the last.  Finding it when the chain is loaded means a flush was interrupted.
+var utxoFlushJournalKeyName = []byte("utxoflushjournal")
+// chainJournal records a change of the chain state that takes more than one database transaction while it is underway, a reorganization of the main chain or a flush of the utxo cache, from the block it started at to the one it was going to.
+type chainJournal struct {
+	from chainhash.Hash
+	to   chainhash.Hash
+}
+// dbPutChainJournal stores the journal of a change that is about to be made to the chain state under the key.
+func dbPutChainJournal(
+	dbTx database.Tx, key []byte, journal *chainJournal) error {
+	serialized := make([]byte, 2*chainhash.HashSize)
+	copy(serialized, journal.from[:])
+	copy(serialized[chainhash.HashSize:], journal.to[:])
+	return dbTx.Metadata().Put(key, serialized)
+}
+// dbFetchChainJournal returns the journal under the key of an interrupted change, or nil when there is none.
+func dbFetchChainJournal(
+	dbTx database.Tx, key []byte) (*chainJournal, error) {
+	serialized := dbTx.Metadata().Get(key)
+	if serialized == nil {
+		return nil, nil
+	}
+	if len(serialized) != 2*chainhash.HashSize {
+		return nil, database.Error{
+			ErrorCode:   database.ErrCorruption,
+			Description: fmt.Sprintf("corrupt journal %s", key),
+		}
+	}
+	var journal chainJournal
+	copy(journal.from[:], serialized)
+	copy(journal.to[:], serialized[chainhash.HashSize:])
+	return &journal, nil
+}
+// dbRemoveChainJournal removes the journal under the key once the change it records is done.
+func dbRemoveChainJournal(
+	dbTx database.Tx, key []byte) error {
+	return dbTx.Metadata().Delete(key)
+}
+// finishUtxoFlush finishes a flush of the utxo cache that was interrupted by a crash or power loss, and logs what it found and did.  The changes of the flush are those of the blocks after the one the utxo set was at up to the one it was flushed to, which only depend on the blocks, so they are worked out from them again and written over those of the batches that were written before.
+func (b *BlockChain) finishUtxoFlush() error {
+	var journal *chainJournal
+	err := b.db.View(func(dbTx database.Tx) error {
+		var err error
+		journal, err = dbFetchChainJournal(dbTx, utxoFlushJournalKeyName)
+		return err
+	})
+	if err != nil || journal == nil {
+		return err
+	}
+	log <- cl.Warnf{
+		"the chain was stopped while flushing the utxo cache from %v to %v",
+		journal.from, journal.to,
+	}
+	from := b.Index.LookupNode(&journal.from)
+	to := b.Index.LookupNode(&journal.to)
+	if from == nil || to == nil || to.Ancestor(from.height) != from {
+		return AssertError(fmt.Sprintf(
+			"finishUtxoFlush: utxo cache was flushed from %v to %v, which is not after it", journal.from, journal.to,
+		))
+	}
+	// Outputs spent by the blocks are deleted, so a placeholder is spent for those the view does not have, and those they create are added, spent again if a later block spends them.
+	view := NewUtxoViewpoint()
+	for height := from.height + 1; height <= to.height; height++ {
+		var block *util.Block
+		err := b.db.View(func(dbTx database.Tx) error {
+			var err error
+			block, err = dbFetchBlockByNode(dbTx, to.Ancestor(height))
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		for _, tx := range block.Transactions() {
+			if !IsCoinBase(tx) {
+				for _, txIn := range tx.MsgTx().TxIn {
+					entry := view.entries[txIn.PreviousOutPoint]
+					if entry == nil {
+						entry = new(UtxoEntry)
+						view.entries[txIn.PreviousOutPoint] = entry
+					}
+					entry.Spend()
+				}
+			}
+			view.AddTxOuts(tx, height)
+		}
+	}
+	if err = dbPutUtxoChanges(b.db, view.entries, &journal.from, &journal.to); err != nil {
+		return err
+	}
+	log <- cl.Infof{
+		"finished the interrupted flush of the utxo cache, the utxo set is at %v (height %d)",
+		to.hash, to.height,
+	}
+	return nil
+}
+// recoverChainJournal finishes a reorganization that was interrupted by a crash or power loss, or when the new tip can not be reached, returns the chain to the old one, and logs what it found and did.  The utxo set must have been brought up to the best block first.
+func (b *BlockChain) recoverChainJournal() error {
+	var journal *chainJournal
+	err := b.db.View(func(dbTx database.Tx) error {
+		var err error
+		journal, err = dbFetchChainJournal(dbTx, chainJournalKeyName)
+		return err
+	})
+	if err != nil || journal == nil {
+		return err
+	}
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+	tip := b.bestChain.Tip()
+	log <- cl.Warnf{
+		"the chain was stopped while reorganizing from %v to %v, it is at %v (height %d)",
+		journal.from, journal.to, tip.hash, tip.height,
+	}
+	outcome := "had already been done"
+	if tip.hash != journal.to {
+		outcome = "has been finished"
+		if err = b.reorganizeTo(&journal.to); err != nil {
+			log <- cl.Warnf{
+				"could not finish reorganizing to %v, returning to %v: %v",
+				journal.to, journal.from, err,
+			}
+			outcome = "has been rolled back"
+			if b.bestChain.Tip().hash != journal.from {
+				if err = b.reorganizeTo(&journal.from); err != nil {
+					return fmt.Errorf("could not recover from the interrupted reorganization: %v", err)
+				}
+			}
+		}
+	}
+	err = b.db.Update(func(dbTx database.Tx) error {
+		return dbRemoveChainJournal(dbTx, chainJournalKeyName)
+	})
+	if err != nil {
+		return err
+	}
+	tip = b.bestChain.Tip()
+	log <- cl.Infof{
+		"recovered from the interrupted reorganization, which %s, the chain is at %v (height %d)",
+		outcome, tip.hash, tip.height,
+	}
+	return nil
+}
+// reorganizeTo makes the block with the hash the tip of the main chain, whatever the work of its chain. This function MUST be called with the chain state lock held (for writes).
+func (b *BlockChain) reorganizeTo(
+	hash *chainhash.Hash) error {
+	node := b.Index.LookupNode(hash)
+	if node == nil {
+		return fmt.Errorf("block %v is not known", hash)
+	}
+	if b.Index.NodeStatus(node).KnownInvalid() {
+		return fmt.Errorf("block %v is invalid", hash)
+	}
+	detachNodes, attachNodes := b.getReorganizeNodes(node)
+	if err := b.reorganizeChain(detachNodes, attachNodes); err != nil {
+		return err
+	}
+	if b.bestChain.Tip() != node {
+		return fmt.Errorf("block %v has an invalid ancestor", hash)
+	}
+	return nil
+}
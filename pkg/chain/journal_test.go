@@ -0,0 +1,145 @@
+package chain
+import (
+	"testing"
+	"time"
+	chaincfg "git.parallelcoin.io/dev/9/pkg/chain/config"
+	chainhash "git.parallelcoin.io/dev/9/pkg/chain/hash"
+	"git.parallelcoin.io/dev/9/pkg/chain/wire"
+	database "git.parallelcoin.io/dev/9/pkg/db"
+	"git.parallelcoin.io/dev/9/pkg/util"
+)
+// TestRecoverChainJournal ensures an interrupted reorganization whose new tip can not be reached leaves the chain at its old tip with the journal removed, and that a corrupt journal is reported.
+func TestRecoverChainJournal(
+	t *testing.T) {
+	chain, teardownFunc, err := chainSetup("recoverchainjournal",
+		&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc()
+	tip := chain.bestChain.Tip()
+	journal := &chainJournal{from: tip.hash, to: chainhash.Hash{1}}
+	err = chain.db.Update(func(dbTx database.Tx) error {
+		return dbPutChainJournal(dbTx, chainJournalKeyName, journal)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = chain.recoverChainJournal(); err != nil {
+		t.Fatal(err)
+	}
+	if chain.bestChain.Tip() != tip {
+		t.Fatalf("chain moved to %v", chain.bestChain.Tip().hash)
+	}
+	err = chain.db.View(func(dbTx database.Tx) error {
+		journal, err = dbFetchChainJournal(dbTx, chainJournalKeyName)
+		return err
+	})
+	if err != nil || journal != nil {
+		t.Fatalf("journal %v, %v left after recovering", journal, err)
+	}
+	err = chain.db.Update(func(dbTx database.Tx) error {
+		return dbTx.Metadata().Put(chainJournalKeyName, []byte{1})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = chain.recoverChainJournal(); err == nil {
+		t.Fatal("corrupt journal was not reported")
+	}
+}
+// TestFinishUtxoFlush ensures a flush of the utxo cache interrupted after some of its batches were written is finished from the blocks it covers, leaving the utxo set at the block it was flushed to with the journal removed, and outputs the blocks do not touch as they were.
+func TestFinishUtxoFlush(
+	t *testing.T) {
+	chain, teardownFunc, err := chainSetup("finishutxoflush",
+		&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc()
+	// The first block creates two outputs in its coinbase, the first of which the second block spends.
+	txOut := &wire.TxOut{Value: 1, PkScript: []byte{0x51}}
+	newCoinbase := func(height byte, outputs int) *wire.MsgTx {
+		tx := wire.NewMsgTx(wire.TxVersion)
+		tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Index: wire.MaxPrevOutIndex}, []byte{height, 0x51}, nil))
+		for i := 0; i < outputs; i++ {
+			tx.AddTxOut(txOut)
+		}
+		return tx
+	}
+	coinbase1 := newCoinbase(1, 2)
+	coinbase2 := newCoinbase(2, 1)
+	spend := wire.NewMsgTx(wire.TxVersion)
+	spend.AddTxIn(wire.NewTxIn(&wire.OutPoint{Hash: coinbase1.TxHash()}, nil, nil))
+	spend.AddTxOut(txOut)
+	genesis := chain.bestChain.Tip()
+	parent := genesis
+	for _, txs := range [][]*wire.MsgTx{{coinbase1}, {coinbase2, spend}} {
+		block := &wire.MsgBlock{
+			Header: wire.BlockHeader{
+				Version:   2,
+				PrevBlock: parent.hash,
+				Timestamp: time.Unix(parent.timestamp+1, 0),
+			},
+			Transactions: txs,
+		}
+		node := newBlockNode(&block.Header, parent)
+		node.status = statusDataStored
+		chain.Index.AddNode(node)
+		err = chain.db.Update(func(dbTx database.Tx) error {
+			return dbStoreBlock(dbTx, util.NewBlock(block))
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		parent = node
+	}
+	// The flush from the genesis block to the second block wrote the output of the second coinbase before it was interrupted, and an output from before it is untouched.
+	untouched := wire.OutPoint{Hash: chainhash.Hash{9}}
+	written := wire.OutPoint{Hash: coinbase2.TxHash()}
+	view := NewUtxoViewpoint()
+	view.addTxOut(untouched, txOut, false, 0)
+	view.addTxOut(written, txOut, true, 2)
+	err = chain.db.Update(func(dbTx database.Tx) error {
+		err := dbPutChainJournal(dbTx, utxoFlushJournalKeyName, &chainJournal{from: genesis.hash, to: parent.hash})
+		if err != nil {
+			return err
+		}
+		return dbPutUtxoView(dbTx, utxoSetBucketName, view)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = chain.finishUtxoFlush(); err != nil {
+		t.Fatal(err)
+	}
+	want := map[wire.OutPoint]bool{
+		untouched: true,
+		written:   true,
+		{Hash: coinbase1.TxHash()}:           false,
+		{Hash: coinbase1.TxHash(), Index: 1}: true,
+		{Hash: spend.TxHash()}:               true,
+	}
+	err = chain.db.View(func(dbTx database.Tx) error {
+		for outpoint, unspent := range want {
+			entry, err := dbFetchUtxoEntry(dbTx, utxoSetBucketName, outpoint)
+			if err != nil {
+				return err
+			}
+			if (entry != nil) != unspent {
+				t.Errorf("output %v is %+v after finishing the flush", outpoint, entry)
+			}
+		}
+		if state := dbFetchUtxoState(dbTx); state == nil || *state != parent.hash {
+			t.Errorf("utxo set is at block %v, not %v", state, parent.hash)
+		}
+		journal, err := dbFetchChainJournal(dbTx, utxoFlushJournalKeyName)
+		if err != nil || journal != nil {
+			t.Errorf("journal %v, %v left after finishing the flush", journal, err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -63,6 +63,12 @@ func BuildMerkleTreeStore(
 	nextPoT := nextPowerOfTwo(len(transactions))
 	arraySize := nextPoT*2 - 1
 	merkles := make([]*chainhash.Hash, arraySize)
+	// Work out the hashes of all the transactions at once, which is faster than one by one on CPUs with vector instructions. The coinbase's wtxid is not hashed.
+	if witness && len(transactions) > 0 {
+		util.HashTxs(transactions[1:], true)
+	} else {
+		util.HashTxs(transactions, false)
+	}
 	// Create the base transaction hashes and populate the array with them.
 	for i, tx := range transactions {
 		// If we're computing a witness merkle root, instead of the regular txid, we use the modified wtxid which includes a transaction's witness data within the digest. Additionally, the coinbase's wtxid is all zeroes.
@@ -71,29 +77,39 @@ func BuildMerkleTreeStore(
 			var zeroHash chainhash.Hash
 			merkles[i] = &zeroHash
 		case witness:
-			wSha := tx.MsgTx().WitnessHash()
-			merkles[i] = &wSha
+			merkles[i] = tx.WitnessHash()
 		default:
 			merkles[i] = tx.Hash()
 		}
 	}
-	// Start the array offset after the last transaction and adjusted to the next power of two.
-	offset := nextPoT
-	for i := 0; i < arraySize-1; i += 2 {
-		switch {
-		// When there is no left child node, the parent is nil too.
-		case merkles[i] == nil:
-			merkles[offset] = nil
-		// When there is no right child, the parent is generated by hashing the concatenation of the left child with itself.
-		case merkles[i+1] == nil:
-			newHash := HashMerkleBranches(merkles[i], merkles[i])
-			merkles[offset] = newHash
-		// The normal case sets the parent node to the double sha256 of the concatentation of the left and right children.
-		default:
-			newHash := HashMerkleBranches(merkles[i], merkles[i+1])
-			merkles[offset] = newHash
+	// Each level of the tree is hashed in one batch, from the concatenations of the pairs of children of its nodes.
+	for start, width := 0, nextPoT; width > 1; start, width = start+width, width/2 {
+		parents := merkles[start+width : start+width+width/2]
+		pairs := make([][]byte, 0, len(parents))
+		buf := make([]byte, 0, len(parents)*chainhash.HashSize*2)
+		for j := range parents {
+			left, right := merkles[start+2*j], merkles[start+2*j+1]
+			switch {
+			// When there is no left child node, the parent is nil too.
+			case left == nil:
+				continue
+			// When there is no right child, the parent is generated by hashing the concatenation of the left child with itself.
+			case right == nil:
+				right = left
+			}
+			// The normal case sets the parent node to the double sha256 of the concatentation of the left and right children.
+			buf = append(append(buf, left[:]...), right[:]...)
+			pairs = append(pairs, buf[len(buf)-chainhash.HashSize*2:])
+		}
+		hashes := make([]chainhash.Hash, len(pairs))
+		chainhash.DoubleHashBatch(hashes, pairs)
+		// The pairs were made for the parents in order, leaving out those with no left child.
+		for j := range parents {
+			if merkles[start+2*j] != nil {
+				parents[j] = &hashes[0]
+				hashes = hashes[1:]
+			}
 		}
-		offset++
 	}
 	return merkles
 }
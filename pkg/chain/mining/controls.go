@@ -0,0 +1,110 @@
+package mining
+import (
+	"fmt"
+	blockchain "git.parallelcoin.io/dev/9/pkg/chain"
+	chainhash "git.parallelcoin.io/dev/9/pkg/chain/hash"
+)
+// TxControls are the choices of a miner about the transactions of its block templates on top of the mining policy. Transactions to include are selected before all others regardless of their fees, as long as they are valid and fit in the block, and transactions to exclude are never selected, along with any transactions that spend their outputs.
+type TxControls struct {
+	// Include are the transactions to select first.
+	Include []chainhash.Hash
+	// Exclude are the transactions never to select.
+	Exclude []chainhash.Hash
+	// MaxWeight lowers the maximum weight of the block templates below the policy when it is not zero.
+	MaxWeight uint32
+}
+// CheckTxControls returns an error if a transaction is both included and excluded or the maximum weight is not below the consensus limit.
+func CheckTxControls(
+	c *TxControls) error {
+	if c == nil {
+		return nil
+	}
+	if c.MaxWeight >= blockchain.MaxBlockWeight {
+		return fmt.Errorf("maximum template weight %d must be below the consensus limit of %d",
+			c.MaxWeight, blockchain.MaxBlockWeight)
+	}
+	exclude := hashSet(c.Exclude)
+	for _, h := range c.Include {
+		if _, ok := exclude[h]; ok {
+			return fmt.Errorf("transaction %v is both included and excluded", h)
+		}
+	}
+	return nil
+}
+// Empty returns whether the controls leave the transactions to the mining policy.
+func (c *TxControls) Empty() bool {
+	return c == nil ||
+		(len(c.Include) == 0 && len(c.Exclude) == 0 && c.MaxWeight == 0)
+}
+// Equal returns whether the controls select the same transactions as the passed ones.
+func (c *TxControls) Equal(o *TxControls) bool {
+	if c.Empty() || o.Empty() {
+		return c.Empty() == o.Empty()
+	}
+	return c.MaxWeight == o.MaxWeight &&
+		sameHashes(c.Include, o.Include) && sameHashes(c.Exclude, o.Exclude)
+}
+// Copy returns a copy of the controls that shares nothing with them.
+func (c *TxControls) Copy() *TxControls {
+	if c == nil {
+		return nil
+	}
+	return &TxControls{
+		Include:   append([]chainhash.Hash(nil), c.Include...),
+		Exclude:   append([]chainhash.Hash(nil), c.Exclude...),
+		MaxWeight: c.MaxWeight,
+	}
+}
+// txSelection is the combination of the controls of the generator and of a single template, with the transactions in sets.
+type txSelection struct {
+	include   map[chainhash.Hash]struct{}
+	exclude   map[chainhash.Hash]struct{}
+	maxWeight uint32
+}
+// newTxSelection combines the passed controls with the maximum weight of the policy, so the lowest weight applies and all the transactions in the controls are included or excluded.
+func newTxSelection(
+	maxWeight uint32, controls ...*TxControls) *txSelection {
+	sel := &txSelection{
+		include:   make(map[chainhash.Hash]struct{}),
+		exclude:   make(map[chainhash.Hash]struct{}),
+		maxWeight: maxWeight,
+	}
+	for _, c := range controls {
+		if c == nil {
+			continue
+		}
+		for _, h := range c.Include {
+			sel.include[h] = struct{}{}
+		}
+		for _, h := range c.Exclude {
+			sel.exclude[h] = struct{}{}
+		}
+		if c.MaxWeight != 0 && c.MaxWeight < sel.maxWeight {
+			sel.maxWeight = c.MaxWeight
+		}
+	}
+	return sel
+}
+// hashSet returns the passed hashes as a set.
+func hashSet(
+	hashes []chainhash.Hash) map[chainhash.Hash]struct{} {
+	set := make(map[chainhash.Hash]struct{}, len(hashes))
+	for _, h := range hashes {
+		set[h] = struct{}{}
+	}
+	return set
+}
+// sameHashes returns whether the passed lists hold the same hashes, in any order.
+func sameHashes(
+	a, b []chainhash.Hash) bool {
+	as, bs := hashSet(a), hashSet(b)
+	if len(as) != len(bs) {
+		return false
+	}
+	for h := range as {
+		if _, ok := bs[h]; !ok {
+			return false
+		}
+	}
+	return true
+}
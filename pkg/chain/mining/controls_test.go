@@ -0,0 +1,52 @@
+package mining
+import (
+	"container/heap"
+	"testing"
+	blockchain "git.parallelcoin.io/dev/9/pkg/chain"
+	chainhash "git.parallelcoin.io/dev/9/pkg/chain/hash"
+)
+// TestTxControls ensures transaction controls are checked and combined as expected, and that forced transactions are popped first from the priority queue.
+func TestTxControls(
+	t *testing.T) {
+	a, b, c := chainhash.Hash{1}, chainhash.Hash{2}, chainhash.Hash{3}
+	if err := CheckTxControls(&TxControls{Include: []chainhash.Hash{a}, Exclude: []chainhash.Hash{b, a}}); err == nil {
+		t.Fatal("CheckTxControls: expected error for a transaction both included and excluded")
+	}
+	if err := CheckTxControls(&TxControls{MaxWeight: blockchain.MaxBlockWeight}); err == nil {
+		t.Fatal("CheckTxControls: expected error for a weight at the consensus limit")
+	}
+	if err := CheckTxControls(nil); err != nil {
+		t.Fatalf("CheckTxControls: unexpected error for no controls: %v", err)
+	}
+	x := &TxControls{Include: []chainhash.Hash{a, b}, MaxWeight: 1000}
+	y := &TxControls{Include: []chainhash.Hash{b, a}, MaxWeight: 1000}
+	if !x.Equal(y) || x.Equal(&TxControls{Include: []chainhash.Hash{a}, MaxWeight: 1000}) {
+		t.Fatal("Equal: expected controls to be compared regardless of order")
+	}
+	if !(*TxControls)(nil).Equal(&TxControls{}) {
+		t.Fatal("Equal: expected empty controls to equal no controls")
+	}
+	// The lowest weight applies and the transactions of all the controls are included and excluded.
+	sel := newTxSelection(4000, x, &TxControls{Exclude: []chainhash.Hash{c}, MaxWeight: 2000}, nil)
+	if sel.maxWeight != 1000 {
+		t.Errorf("selection max weight is %d, want 1000", sel.maxWeight)
+	}
+	if _, ok := sel.include[b]; !ok {
+		t.Error("selection does not include an included transaction")
+	}
+	if _, ok := sel.exclude[c]; !ok {
+		t.Error("selection does not exclude an excluded transaction")
+	}
+	if sel := newTxSelection(4000); sel.maxWeight != 4000 {
+		t.Errorf("selection without controls has max weight %d, want the policy 4000", sel.maxWeight)
+	}
+	for _, sortByFee := range []bool{false, true} {
+		pq := newTxPriorityQueue(3, sortByFee)
+		heap.Push(pq, &txPrioItem{feePerKB: 5000, priority: 5000})
+		heap.Push(pq, &txPrioItem{feePerKB: 0, priority: 0, forced: true})
+		heap.Push(pq, &txPrioItem{feePerKB: 3000, priority: 3000})
+		if item := heap.Pop(pq).(*txPrioItem); !item.forced {
+			t.Errorf("forced transaction not popped first when sorting by fee is %v", sortByFee)
+		}
+	}
+}
@@ -0,0 +1,360 @@
+package cpuminer
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+	blockchain "git.parallelcoin.io/dev/9/pkg/chain"
+	"git.parallelcoin.io/dev/9/pkg/chain/fork"
+	chainhash "git.parallelcoin.io/dev/9/pkg/chain/hash"
+	cl "git.parallelcoin.io/dev/9/pkg/util/cl"
+)
+// DefaultSwitchInterval is the longest the miner works on one algorithm before picking again when mining "random" and no interval is configured.
+const DefaultSwitchInterval = time.Second * 2
+// biasSharpness scales how strongly a bias of magnitude 0.5 favours the easiest or hardest algorithm over the others.
+const biasSharpness = 4.0
+// AlgoStats is the record the CPU miner keeps of its work on one algorithm.
+type AlgoStats struct {
+	// Algo is the name of the algorithm.
+	Algo string
+	// Difficulty is the difficulty of the next block for the algorithm relative to the minimum difficulty of the algorithm.
+	Difficulty float64
+	// Hashes is the number of hashes computed with the algorithm.
+	Hashes uint64
+	// Blocks is the number of blocks found with the algorithm that were accepted.
+	Blocks uint32
+	// Stale is the number of blocks found with the algorithm that were not accepted because another block was connected to the chain first.
+	Stale uint32
+	// Rounds is the number of times the algorithm was picked to be mined.
+	Rounds uint32
+	// Mined is the total time spent mining the algorithm.
+	Mined time.Duration
+	// HashRates are the hashes per second computed with the algorithm over each of the HashRateWindows.
+	HashRates [len(HashRateWindows)]float64
+	// TimeToBlock is the average time to find a block with the algorithm at the current difficulty and the hash rate over the middle window, or zero if it is not being mined.
+	TimeToBlock time.Duration
+}
+// AlgoChance is the chance of an algorithm being picked for a round when the miner mines "random".
+type AlgoChance struct {
+	// Algo is the name of the algorithm.
+	Algo string
+	// Difficulty is the difficulty of the next block for the algorithm relative to the minimum difficulty of the algorithm.
+	Difficulty float64
+	// Chance is the probability from 0 to 1 of the algorithm being picked under the current bias.
+	Chance float64
+}
+// algoSwitcher picks the algorithm the workers of the CPU miner solve blocks with and keeps the per algorithm statistics. When the miner is set to mine "random" the algorithm is picked by the current difficulties, weighted by the bias, and is kept until the switch interval has passed or a new block arrives.
+type algoSwitcher struct {
+	sync.Mutex
+	b          *blockchain.BlockChain
+	bias       float64
+	interval   time.Duration
+	current    string
+	roundStart time.Time
+	tip        chainhash.Hash
+	difficulty map[string]float64
+	expected   map[string]float64
+	stats      map[string]*AlgoStats
+	windows    map[string]*hashWindow
+}
+// CheckBias returns an error if the bias is outside the range -1 to 1.
+func CheckBias(
+	bias float64) error {
+	if math.IsNaN(bias) || bias < -1 || bias > 1 {
+		return fmt.Errorf("bias %v must be between -1 and 1", bias)
+	}
+	return nil
+}
+// newAlgoSwitcher returns an algoSwitcher for the passed chain. The bias is clamped to the range -1 to 1.
+func newAlgoSwitcher(
+	b *blockchain.BlockChain, bias float64, interval time.Duration) *algoSwitcher {
+	if bias < -1 {
+		bias = -1
+	}
+	if bias > 1 {
+		bias = 1
+	}
+	if interval <= 0 {
+		interval = DefaultSwitchInterval
+	}
+	return &algoSwitcher{
+		b:          b,
+		bias:       bias,
+		interval:   interval,
+		difficulty: make(map[string]float64),
+		expected:   make(map[string]float64),
+		stats:      make(map[string]*AlgoStats),
+		windows:    make(map[string]*hashWindow),
+	}
+}
+// pick returns the name of the algorithm to build the next block template with. The configured algorithm is returned as is unless it is "random", in which case a new algorithm is only picked once the current round is over.
+func (
+	s *algoSwitcher,
+) pick(
+	algo string, height int32, tip chainhash.Hash) string {
+	s.Lock()
+	defer s.Unlock()
+	now := time.Now()
+	newTip := s.tip != tip
+	if newTip {
+		s.updateDifficulty(height + 1)
+		s.tip = tip
+	}
+	if algo != "random" {
+		name := fork.GetAlgoName(fork.GetAlgoVer(algo, height), height)
+		if name != s.current {
+			s.startRound(name, now)
+		}
+		return name
+	}
+	_, valid := s.difficulty[s.current]
+	if valid && !newTip && !s.roundStart.IsZero() &&
+		now.Sub(s.roundStart) < s.interval {
+		return s.current
+	}
+	name := pickAlgo(s.difficulty, s.bias, rand.Float64())
+	if name == "" {
+		name = fork.GetAlgoName(fork.GetAlgoVer(algo, height), height)
+	}
+	s.startRound(name, now)
+	return name
+}
+// setBias changes the bias used for the following rounds and returns the previous one.
+func (
+	s *algoSwitcher,
+) setBias(
+	bias float64) (old float64) {
+	s.Lock()
+	defer s.Unlock()
+	old, s.bias = s.bias, bias
+	return
+}
+// chances returns the bias and the chance of each algorithm valid at the passed height being picked with it, from the easiest to the hardest.
+func (
+	s *algoSwitcher,
+) chances(
+	height int32, tip chainhash.Hash) (bias float64, chances []AlgoChance) {
+	s.Lock()
+	defer s.Unlock()
+	// The tip is left for pick to notice, so a new block still starts a new round.
+	if s.tip != tip || len(s.difficulty) == 0 {
+		s.updateDifficulty(height)
+	}
+	algos := rankAlgos(s.difficulty)
+	weights := algoWeights(len(algos), s.bias)
+	var total float64
+	for _, w := range weights {
+		total += w
+	}
+	for i, algo := range algos {
+		chances = append(chances, AlgoChance{
+			Algo:       algo,
+			Difficulty: s.difficulty[algo],
+			Chance:     weights[i] / total,
+		})
+	}
+	return s.bias, chances
+}
+// expired returns whether the current round has lasted the switch interval.
+func (
+	s *algoSwitcher,
+) expired() bool {
+	s.Lock()
+	defer s.Unlock()
+	return time.Since(s.roundStart) >= s.interval
+}
+// startRound closes the current round and starts one for the passed algorithm. The lock must be held.
+func (
+	s *algoSwitcher,
+) startRound(
+	algo string, now time.Time) {
+	s.endRound(now)
+	if algo != s.current {
+		log <- cl.Debugf{"miner switching algorithm from %s to %s", s.current, algo}
+	}
+	s.current = algo
+	s.roundStart = now
+	s.stat(algo).Rounds++
+}
+// endRound adds the time spent in the current round to the statistics of its algorithm. The lock must be held.
+func (
+	s *algoSwitcher,
+) endRound(
+	now time.Time) {
+	if s.current == "" || s.roundStart.IsZero() {
+		return
+	}
+	s.stat(s.current).Mined += now.Sub(s.roundStart)
+	s.roundStart = now
+}
+// stop closes the current round when the miner stops.
+func (
+	s *algoSwitcher,
+) stop() {
+	s.Lock()
+	defer s.Unlock()
+	s.endRound(time.Now())
+	s.current = ""
+	s.roundStart = time.Time{}
+}
+// addHashes records hashes computed with the passed algorithm.
+func (
+	s *algoSwitcher,
+) addHashes(
+	algo string, hashes uint64) {
+	s.Lock()
+	defer s.Unlock()
+	s.stat(algo).Hashes += hashes
+	w, ok := s.windows[algo]
+	if !ok {
+		w = &hashWindow{}
+		s.windows[algo] = w
+	}
+	w.add(time.Now(), hashes)
+}
+// found records an accepted block found with the passed algorithm.
+func (
+	s *algoSwitcher,
+) found(
+	algo string) {
+	s.Lock()
+	defer s.Unlock()
+	s.stat(algo).Blocks++
+}
+// stale records a block found with the passed algorithm that was beaten to the chain by another block.
+func (
+	s *algoSwitcher,
+) stale(
+	algo string) {
+	s.Lock()
+	defer s.Unlock()
+	s.stat(algo).Stale++
+}
+// stat returns the statistics of the passed algorithm, creating them if needed. The lock must be held.
+func (
+	s *algoSwitcher,
+) stat(
+	algo string) *AlgoStats {
+	st, ok := s.stats[algo]
+	if !ok {
+		st = &AlgoStats{Algo: algo}
+		s.stats[algo] = st
+	}
+	return st
+}
+// updateDifficulty recalculates the relative difficulty of the next block for each algorithm valid at the passed height. The lock must be held.
+func (
+	s *algoSwitcher,
+) updateDifficulty(
+	height int32) {
+	s.difficulty = make(map[string]float64)
+	s.expected = make(map[string]float64)
+	for algo := range fork.List[fork.GetCurrent(height)].Algos {
+		bits, err := s.b.CalcNextRequiredDifficulty(time.Now(), algo)
+		if err != nil {
+			log <- cl.Debug{"unable to calculate difficulty for", algo, err}
+			continue
+		}
+		target := new(big.Float).SetInt(blockchain.CompactToBig(bits))
+		if target.Sign() <= 0 {
+			continue
+		}
+		min := new(big.Float).SetInt(fork.GetMinDiff(algo, height))
+		d, _ := new(big.Float).Quo(min, target).Float64()
+		s.difficulty[algo] = d
+		s.expected[algo] = expectedHashes(bits)
+	}
+}
+// snapshot returns a copy of the statistics of every algorithm valid at the passed height along with any others that were mined, sorted by name.
+func (
+	s *algoSwitcher,
+) snapshot(
+	height int32) (stats []AlgoStats) {
+	s.Lock()
+	defer s.Unlock()
+	for algo := range fork.List[fork.GetCurrent(height)].Algos {
+		s.stat(algo)
+	}
+	now := time.Now()
+	for algo, st := range s.stats {
+		c := *st
+		c.Difficulty = s.difficulty[algo]
+		if algo == s.current && !s.roundStart.IsZero() {
+			c.Mined += now.Sub(s.roundStart)
+		}
+		if w, ok := s.windows[algo]; ok {
+			c.HashRates = w.rates(now)
+		}
+		c.TimeToBlock = timeToBlock(s.expected[algo], c.HashRates[expectedWindow])
+		stats = append(stats, c)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Algo < stats[j].Algo
+	})
+	return
+}
+// pickAlgo ranks the algorithms from the easiest to the hardest relative difficulty and picks one with the weights given by algoWeights, using r from the range [0,1) as the random draw.
+func pickAlgo(
+	difficulty map[string]float64, bias, r float64) string {
+	if len(difficulty) == 0 {
+		return ""
+	}
+	algos := rankAlgos(difficulty)
+	weights := algoWeights(len(algos), bias)
+	var total float64
+	for _, w := range weights {
+		total += w
+	}
+	r *= total
+	for i, w := range weights {
+		if r < w {
+			return algos[i]
+		}
+		r -= w
+	}
+	return algos[len(algos)-1]
+}
+// rankAlgos returns the algorithms ordered from the easiest to the hardest relative difficulty, by name when they are equal.
+func rankAlgos(
+	difficulty map[string]float64) []string {
+	algos := make([]string, 0, len(difficulty))
+	for algo := range difficulty {
+		algos = append(algos, algo)
+	}
+	sort.Slice(algos, func(i, j int) bool {
+		di, dj := difficulty[algos[i]], difficulty[algos[j]]
+		if di == dj {
+			return algos[i] < algos[j]
+		}
+		return di < dj
+	})
+	return algos
+}
+// algoWeights returns the weights of n algorithms ranked from easiest to hardest. A bias of 0 weights them all equally, a negative bias favours the easiest and a positive bias the hardest, and at -1 and 1 only the easiest or hardest is ever picked.
+func algoWeights(
+	n int, bias float64) []float64 {
+	w := make([]float64, n)
+	switch {
+	case n == 0:
+		return w
+	case n == 1 || bias <= -1:
+		w[0] = 1
+		return w
+	case bias >= 1:
+		w[n-1] = 1
+		return w
+	}
+	favoured := 0
+	if bias > 0 {
+		favoured = n - 1
+	}
+	sharpness := math.Abs(bias) / (1 - math.Abs(bias)) * biasSharpness
+	for i := range w {
+		distance := math.Abs(float64(i-favoured)) / float64(n-1)
+		w[i] = math.Exp(-sharpness * distance)
+	}
+	return w
+}
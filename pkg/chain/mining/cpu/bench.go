@@ -0,0 +1,98 @@
+package cpuminer
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+	"git.parallelcoin.io/dev/9/pkg/chain/fork"
+	"git.parallelcoin.io/dev/9/pkg/chain/wire"
+)
+// BenchResult is the hash rate measured for one algorithm on a number of threads.
+type BenchResult struct {
+	// Algo is the name of the algorithm.
+	Algo string
+	// Threads is the number of threads that were hashing.
+	Threads int
+	// Hashes is the total number of hashes computed by all threads.
+	Hashes uint64
+	// Elapsed is how long the threads were hashing.
+	Elapsed time.Duration
+}
+// HashesPerSec returns the measured hash rate.
+func (
+	r *BenchResult,
+) HashesPerSec() float64 {
+	if r.Elapsed <= 0 {
+		return 0
+	}
+	return float64(r.Hashes) / r.Elapsed.Seconds()
+}
+// Relative returns the measured hash rate of a single thread relative to the reference rate the algorithm is weighted by in the hard fork active at the passed height. Algorithms with a higher value are a better choice for the benchmarked hardware.
+func (
+	r *BenchResult,
+) Relative(
+	height int32) float64 {
+	nsPerOp := fork.List[fork.GetCurrent(height)].Algos[r.Algo].NSperOp
+	if nsPerOp == 0 || r.Threads == 0 {
+		return 0
+	}
+	return r.HashesPerSec() / float64(r.Threads) * float64(nsPerOp) / 1e9
+}
+// BenchAlgos returns the sorted names of the algorithms valid at the passed height.
+func BenchAlgos(
+	height int32) (algos []string) {
+	for name := range fork.List[fork.GetCurrent(height)].Algos {
+		algos = append(algos, name)
+	}
+	sort.Strings(algos)
+	return
+}
+// BenchThreads returns the thread counts worth benchmarking up to max: the powers of two below it and max itself.
+func BenchThreads(
+	max int) (threads []int) {
+	for n := 1; n < max; n *= 2 {
+		threads = append(threads, n)
+	}
+	return append(threads, max)
+}
+// Benchmark hashes block headers with the named algorithm, as it is used at the passed height, on the passed number of threads for the passed duration and returns the measured hash rate.
+func Benchmark(
+	algo string, height int32, threads int, d time.Duration) BenchResult {
+	var hashes uint64
+	var wg sync.WaitGroup
+	quit := make(chan struct{})
+	version := fork.GetAlgoVer(algo, height)
+	start := time.Now()
+	for i := 0; i < threads; i++ {
+		wg.Add(1)
+		go func(thread int) {
+			defer wg.Done()
+			header := wire.BlockHeader{
+				Version:   version,
+				Timestamp: time.Unix(start.Unix(), 0),
+				Bits:      fork.FirstPowLimitBits,
+			}
+			// Give each thread its own part of the header space so no two threads hash the same header.
+			header.MerkleRoot[0] = byte(thread)
+			for {
+				select {
+				case <-quit:
+					return
+				default:
+				}
+				header.BlockHashWithAlgos(height)
+				header.Nonce++
+				atomic.AddUint64(&hashes, 1)
+			}
+		}(i)
+	}
+	time.Sleep(d)
+	close(quit)
+	wg.Wait()
+	return BenchResult{
+		Algo:    algo,
+		Threads: threads,
+		Hashes:  atomic.LoadUint64(&hashes),
+		Elapsed: time.Since(start),
+	}
+}
@@ -2,7 +2,6 @@ package cpuminer
 import (
 	"errors"
 	"fmt"
-	"math/rand"
 	"runtime"
 	"sync"
 	"time"
@@ -21,6 +20,8 @@ type CPUMiner struct {
 	b                 *blockchain.BlockChain
 	g                 *mining.BlkTmplGenerator
 	cfg               Config
+	switcher          *algoSwitcher
+	policyMtx         sync.Mutex
 	numWorkers        uint32
 	started           bool
 	discreteMining    bool
@@ -41,8 +42,8 @@ type Config struct {
 	ChainParams *chaincfg.Params
 	// BlockTemplateGenerator identifies the instance to use in order to generate block templates that the miner will attempt to solve.
 	BlockTemplateGenerator *mining.BlkTmplGenerator
-	// MiningAddrs is a list of payment addresses to use for the generated blocks.  Each generated block will randomly choose one of them.
-	MiningAddrs []util.Address
+	// PayAddrs is the set of payment addresses to use for the generated blocks.  Each generated block pays to the next one its rotation policy chooses.
+	PayAddrs *mining.PayAddrs
 	// ProcessBlock defines the function to call with any solved blocks. It typically must run the provided block through the same set of rules and handling as any other block coming from the network.
 	ProcessBlock func(*util.Block, blockchain.BehaviorFlags) (bool, error)
 	// ConnectedCount defines the function to use to obtain how many other peers the server is connected to.  This is used by the automatic persistent mining routine to determine whether or it should attempt mining.  This is useful because there is no point in mining when not connected to any peers since there would no be anyone to send any found blocks to.
@@ -53,14 +54,28 @@ type Config struct {
 	Algo string
 	// NumThreads is the number of threads set in the configuration for the CPUMiner
 	NumThreads uint32
+	// Bias sets how the algorithm is picked when Algo is "random": -1 always picks the one with the easiest current difficulty, 1 the hardest and 0 picks any with equal chance.
+	Bias float64
+	// Switch is the longest time an algorithm is mined before another is picked when Algo is "random".
+	Switch time.Duration
+	// Cores are the processor cores the worker threads are pinned to in turn. When empty the workers run on any core.
+	Cores []int
+	// Nice is how much the scheduling priority of the worker threads is lowered, from 0 to MaxNice, so a node that also mines keeps validating blocks and answering RPC requests promptly.
+	Nice int
 }
 const (
 	// maxNonce is the maximum value a nonce can be in a block header.
 	maxNonce = 100 // ^uint32(0) // 2^32 - 1
+	// maxNonceMask covers every value a nonce in a block header can have.
+	maxNonceMask = 1<<32 - 1
 	// maxExtraNonce is the maximum value an extra nonce used in a coinbase transaction can be.
 	maxExtraNonce = 1 //^uint64(0) // 2^64 - 1
 	// hpsUpdateSecs is the number of seconds to wait in between each update to the hashes per second monitor.
 	hpsUpdateSecs = 15
+	// maxCores is the number of processor cores a worker thread can be pinned to.
+	maxCores = 1024
+	// MaxNice is the most the scheduling priority of the worker threads can be lowered.
+	MaxNice = 19
 	// hashUpdateSec is the number of seconds each worker waits in between notifying the speed monitor with how many hashes have been completed while they are actively searching for a solution.  This is done to reduce the amount of syncs between the workers that must be done to keep track of the hashes per second.
 	hashUpdateSecs = 1
 )
@@ -103,9 +118,18 @@ func (
 		// Grab the lock used for block submission, since the current block will be changing and this would otherwise end up building a new block template on a block that is in the process of becoming stale.
 		m.submitBlockLock.Lock()
 		curHeight := m.g.BestSnapshot().Height
-		// Choose a payment address at random.
-		rand.Seed(time.Now().UnixNano())
-		payToAddr := m.cfg.MiningAddrs[rand.Intn(len(m.cfg.MiningAddrs))]
+		// Choose the next payment address.
+		payToAddr := m.cfg.PayAddrs.Next()
+		if payToAddr == nil {
+			m.submitBlockLock.Unlock()
+			m.Lock()
+			close(m.speedMonitorQuit)
+			m.wg.Wait()
+			m.started = false
+			m.discreteMining = false
+			m.Unlock()
+			return blockHashes[:i], errors.New("no payment addresses to generate blocks to")
+		}
 		// Create a new block template using the available transactions in the memory pool as a source of transactions to potentially include in the block.
 		template, err := m.g.NewBlockTemplate(payToAddr, algo)
 		m.submitBlockLock.Unlock()
@@ -132,6 +156,142 @@ func (
 		}
 	}
 }
+// AlgoStats returns the statistics the miner keeps for each algorithm, sorted by name. This function is safe for concurrent access.
+func (
+	m *CPUMiner,
+) AlgoStats() []AlgoStats {
+	return m.switcher.snapshot(m.b.BestSnapshot().Height + 1)
+}
+// CurrentAlgo returns the algorithm the miner is working on, which differs from the configured one when it is "random". An empty string is returned when the miner is not running. This function is safe for concurrent access.
+func (
+	m *CPUMiner,
+) CurrentAlgo() string {
+	m.switcher.Lock()
+	defer m.switcher.Unlock()
+	return m.switcher.current
+}
+// GenerateToAddress mines the requested number of blocks paying to the passed address on the calling goroutine and returns their hashes. It is meant for the regression and simulation test networks, where the minimum difficulty lets nearly any nonce solve a block, so instead of starting the workers each block is solved by a plain search of at most maxTries nonces, and an error is returned if the difficulty is too high for that.
+func (
+	m *CPUMiner,
+) GenerateToAddress(
+	n uint32, algo string, addr util.Address, maxTries uint64) ([]*chainhash.Hash, error) {
+	m.Lock()
+	if m.started || m.discreteMining {
+		m.Unlock()
+		return nil, errors.New("server is already CPU mining; call " +
+			"`setgenerate 0` before calling discrete `generatetoaddress` commands.")
+	}
+	m.discreteMining = true
+	m.Unlock()
+	defer func() {
+		m.Lock()
+		m.discreteMining = false
+		m.Unlock()
+	}()
+	blockHashes := make([]*chainhash.Hash, 0, n)
+	for uint32(len(blockHashes)) < n {
+		m.submitBlockLock.Lock()
+		height := m.g.BestSnapshot().Height + 1
+		template, err := m.g.NewBlockTemplate(addr, algo)
+		m.submitBlockLock.Unlock()
+		if err != nil {
+			return blockHashes, err
+		}
+		msgBlock := template.Block
+		target := blockchain.CompactToBig(msgBlock.Header.Bits)
+		solved := false
+		for tries := uint64(0); tries < maxTries && !solved; tries++ {
+			// Move to the next extra nonce whenever the nonces run out.
+			if tries&maxNonceMask == 0 {
+				err = m.g.UpdateExtraNonce(msgBlock, height, tries>>32)
+				if err != nil {
+					return blockHashes, err
+				}
+			}
+			msgBlock.Header.Nonce = uint32(tries)
+			hash := msgBlock.Header.BlockHashWithAlgos(height)
+			solved = blockchain.HashToBig(&hash).Cmp(target) <= 0
+		}
+		if !solved {
+			return blockHashes, fmt.Errorf("no solution for block %d found in %d "+
+				"tries, the difficulty is too high to generate blocks at once",
+				height, maxTries)
+		}
+		block := util.NewBlock(msgBlock)
+		block.SetHeight(height)
+		if !m.submitBlock(block) {
+			return blockHashes, fmt.Errorf("generated block %d was rejected", height)
+		}
+		blockHashes = append(blockHashes, block.Hash())
+	}
+	return blockHashes, nil
+}
+// AlgoChances returns the bias the miner picks algorithms with when mining "random" and the chance of each algorithm being picked for the next block, from the easiest to the hardest. This function is safe for concurrent access.
+func (
+	m *CPUMiner,
+) AlgoChances() (bias float64, chances []AlgoChance) {
+	best := m.b.BestSnapshot()
+	return m.switcher.chances(best.Height+1, best.Hash)
+}
+// SetBias changes how the miner picks algorithms when mining "random" from the next round on, returning the previous bias. The bias must be between -1, which always picks the algorithm with the easiest current difficulty, and 1, which always picks the hardest. This function is safe for concurrent access.
+func (
+	m *CPUMiner,
+) SetBias(
+	bias float64) (old float64, err error) {
+	if err = CheckBias(bias); err != nil {
+		return
+	}
+	return m.switcher.setBias(bias), nil
+}
+// CheckThreadPolicy returns an error if a core the worker threads are to be pinned to does not exist or nice is out of range.
+func CheckThreadPolicy(
+	cores []int, nice int) error {
+	for _, core := range cores {
+		if core < 0 || core >= runtime.NumCPU() || core >= maxCores {
+			return fmt.Errorf("core %d does not exist, there are %d cores",
+				core, runtime.NumCPU())
+		}
+	}
+	if nice < 0 || nice > MaxNice {
+		return fmt.Errorf("nice %d must be between 0 and %d", nice, MaxNice)
+	}
+	return nil
+}
+// ThreadPolicy returns the cores the worker threads are pinned to and how much their scheduling priority is lowered. This function is safe for concurrent access.
+func (
+	m *CPUMiner,
+) ThreadPolicy() (cores []int, nice int) {
+	m.policyMtx.Lock()
+	defer m.policyMtx.Unlock()
+	return append([]int(nil), m.cfg.Cores...), m.cfg.Nice
+}
+// SetThreadPolicy sets the cores the worker threads are pinned to and how much their scheduling priority is lowered. Workers that are already running keep their threads as they are, so the miner must be restarted for the change to apply to all of them. This function is safe for concurrent access.
+func (
+	m *CPUMiner,
+) SetThreadPolicy(
+	cores []int, nice int) error {
+	if err := CheckThreadPolicy(cores, nice); err != nil {
+		return err
+	}
+	m.policyMtx.Lock()
+	defer m.policyMtx.Unlock()
+	m.cfg.Cores = append([]int(nil), cores...)
+	m.cfg.Nice = nice
+	return nil
+}
+// threadPolicy returns the core the passed worker thread is pinned to, or -1 for any core, and how much its scheduling priority is lowered.
+func (
+	m *CPUMiner,
+) threadPolicy(
+	thread int) (core, nice int) {
+	m.policyMtx.Lock()
+	defer m.policyMtx.Unlock()
+	core = -1
+	if len(m.cfg.Cores) > 0 {
+		core = m.cfg.Cores[thread%len(m.cfg.Cores)]
+	}
+	return core, m.cfg.Nice
+}
 // GetAlgo returns the algorithm currently configured for the miner
 func (
 	m *CPUMiner,
@@ -227,14 +387,23 @@ func (
 	}
 	close(m.quit)
 	m.wg.Wait()
+	m.switcher.stop()
 	m.started = false
 	log <- cl.Inf("CPU miner stopped")
 }
-// generateBlocks is a worker that is controlled by the miningWorkerController. It is self contained in that it creates block templates and attempts to solve them while detecting when it is performing stale work and reacting accordingly by generating a new block template.  When a block is solved, it is submitted. It must be run as a goroutine.
+// generateBlocks is a worker that is controlled by the miningWorkerController. It is self contained in that it creates block templates and attempts to solve them while detecting when it is performing stale work and reacting accordingly by generating a new block template.  When a block is solved, it is submitted. The thread number picks the core the worker is pinned to. It must be run as a goroutine.
 func (
 	m *CPUMiner,
 ) generateBlocks(
-	quit chan struct{}) {
+	quit chan struct{}, thread int) {
+	if core, nice := m.threadPolicy(thread); core >= 0 || nice > 0 {
+		// The thread is never unlocked, so it exits along with the worker instead of going back to the scheduler with its affinity and priority changed.
+		runtime.LockOSThread()
+		if err := setThreadPolicy(core, nice); err != nil {
+			log <- cl.Warn{"unable to set core", core, "and nice", nice,
+				"of miner thread", thread, ":", err}
+		}
+	}
 	// Start a ticker which is used to signal checks for stale work and updates to the speed monitor.
 	ticker := time.NewTicker(time.Second * hashUpdateSecs)
 	defer ticker.Stop()
@@ -261,12 +430,16 @@ out:
 			time.Sleep(time.Second)
 			continue
 		}
-		// Choose a payment address at random.
-		rand.Seed(time.Now().UnixNano())
-		payToAddr := m.cfg.MiningAddrs[rand.Intn(len(m.cfg.MiningAddrs))]
-		// Create a new block template using the available transactions in the memory pool as a source of transactions to potentially include in the block.
-		algo := fork.GetAlgoVer(m.cfg.Algo, m.b.BestSnapshot().Height)
-		algoname := fork.GetAlgoName(algo, m.b.BestSnapshot().Height)
+		// Choose the next payment address. Wait for one to be added if they have all been removed.
+		payToAddr := m.cfg.PayAddrs.Next()
+		if payToAddr == nil {
+			m.submitBlockLock.Unlock()
+			time.Sleep(time.Second)
+			continue
+		}
+		// Create a new block template using the available transactions in the memory pool as a source of transactions to potentially include in the block. When mining "random" the switcher keeps the algorithm of the current round.
+		best := m.b.BestSnapshot()
+		algoname := m.switcher.pick(m.cfg.Algo, best.Height, best.Hash)
 		template, err := m.g.NewBlockTemplate(payToAddr, algoname)
 		m.submitBlockLock.Unlock()
 		if err != nil {
@@ -296,9 +469,10 @@ func (
 	launchWorkers := func(numWorkers uint32) {
 		for i := uint32(0); i < numWorkers; i++ {
 			quit := make(chan struct{})
+			thread := len(runningWorkers)
 			runningWorkers = append(runningWorkers, quit)
 			m.workerWg.Add(1)
-			go m.generateBlocks(quit)
+			go m.generateBlocks(quit, thread)
 		}
 	}
 	log <- cl.Debugf{"spawning %d worker(s)", m.numWorkers}
@@ -382,6 +556,7 @@ func (
 				return false
 			case <-ticker.C:
 				// fmt.Println("chan:<-ticker.C")
+				m.switcher.addHashes(algoName, hashesCompleted)
 				m.updateHashes <- hashesCompleted
 				hashesCompleted = 0
 				// The current block is stale if the best block has changed.
@@ -389,6 +564,10 @@ func (
 				if !header.PrevBlock.IsEqual(&best.Hash) {
 					return false
 				}
+				// Give up the block when the round for a randomly picked algorithm is over so the next one can be picked.
+				if m.cfg.Algo == "random" && quit != nil && m.switcher.expired() {
+					return false
+				}
 				// The current block is stale if the memory pool has been updated since the block template was generated and it has been at least one minute.
 				if lastTxUpdate != m.g.TxSource().LastUpdated() &&
 					time.Now().After(lastGenerated.Add(time.Minute)) {
@@ -404,6 +583,7 @@ func (
 			hashesCompleted += incr
 			// The block is solved when the new block hash is less than the target difficulty.  Yay!
 			if blockchain.HashToBig(&hash).Cmp(targetDifficulty) <= 0 {
+				m.switcher.addHashes(algoName, hashesCompleted)
 				m.updateHashes <- hashesCompleted
 				return true
 			}
@@ -464,11 +644,13 @@ func (
 	defer m.submitBlockLock.Unlock()
 	// Ensure the block is not stale since a new block could have shown up while the solution was being found.  Typically that condition is detected and all work on the stale block is halted to start work on a new block, but the check only happens periodically, so it is possible a block was found and submitted in between.
 	msgBlock := block.MsgBlock()
+	algoName := fork.GetAlgoName(msgBlock.Header.Version, block.Height())
 	if !msgBlock.Header.PrevBlock.IsEqual(&m.g.BestSnapshot().Hash) {
 		log <- cl.Debugf{
 			"Block submitted via CPU miner with previous block %s is stale",
 			msgBlock.Header.PrevBlock,
 		}
+		m.switcher.stale(algoName)
 		return false
 	}
 	// Process this block using the same rules as blocks coming from other nodes.  This will in turn relay it to the network like normal.
@@ -485,9 +667,11 @@ func (
 		return false
 	}
 	if isOrphan {
+		m.switcher.stale(algoName)
 		return false
 	}
 	// The block was accepted.
+	m.switcher.found(algoName)
 	coinbaseTx := block.MsgBlock().Transactions[0].TxOut[0]
 	prevHeight := block.Height() - 1
 	prevBlock, _ := m.b.BlockByHeight(prevHeight)
@@ -526,6 +710,7 @@ func New(
 		b:                 cfg.Blockchain,
 		g:                 cfg.BlockTemplateGenerator,
 		cfg:               *cfg,
+		switcher:          newAlgoSwitcher(cfg.Blockchain, cfg.Bias, cfg.Switch),
 		numWorkers:        cfg.NumThreads,
 		updateNumWorkers:  make(chan struct{}),
 		queryHashesPerSec: make(chan float64),
@@ -0,0 +1,111 @@
+package cpuminer
+import (
+	"math"
+	"math/big"
+	"time"
+	blockchain "git.parallelcoin.io/dev/9/pkg/chain"
+)
+// HashRateWindows are the lengths of the sliding windows the effective hash rate of the miner is measured over, from the shortest to the longest.
+var HashRateWindows = [3]time.Duration{time.Minute, time.Minute * 5, time.Minute * 15}
+// expectedWindow is the index of the window whose hash rate is used for the expected time to find a block.
+const expectedWindow = 1
+// hashBucket is the granularity of the hash counts kept for the sliding windows.
+const hashBucket = time.Second * 10
+// hashSample is the number of hashes computed in the bucket starting at the time.
+type hashSample struct {
+	at     time.Time
+	hashes uint64
+}
+// hashWindow keeps the hashes computed over the longest of the HashRateWindows. It is not safe for concurrent access.
+type hashWindow struct {
+	start   time.Time
+	samples []hashSample
+}
+// add records hashes computed at the passed time and drops the samples that have left the longest window.
+func (
+	w *hashWindow,
+) add(
+	now time.Time, hashes uint64) {
+	if w.start.IsZero() {
+		w.start = now
+	}
+	at := now.Truncate(hashBucket)
+	if n := len(w.samples); n > 0 && w.samples[n-1].at.Equal(at) {
+		w.samples[n-1].hashes += hashes
+	} else {
+		w.samples = append(w.samples, hashSample{at: at, hashes: hashes})
+	}
+	oldest := now.Add(-HashRateWindows[len(HashRateWindows)-1])
+	drop := 0
+	for drop < len(w.samples) && w.samples[drop].at.Before(oldest) {
+		drop++
+	}
+	w.samples = w.samples[drop:]
+}
+// rates returns the hashes per second computed over each of the HashRateWindows. A window longer than the time since the first hashes were recorded is shortened to it, so the rates are not understated after the miner starts.
+func (
+	w *hashWindow,
+) rates(
+	now time.Time) (r [len(HashRateWindows)]float64) {
+	if w.start.IsZero() {
+		return
+	}
+	for i, window := range HashRateWindows {
+		from := now.Add(-window)
+		var hashes uint64
+		for _, s := range w.samples {
+			if !s.at.Before(from) {
+				hashes += s.hashes
+			}
+		}
+		if since := now.Sub(w.start); since < window {
+			window = since
+		}
+		if window < hashBucket {
+			window = hashBucket
+		}
+		r[i] = float64(hashes) / window.Seconds()
+	}
+	return
+}
+// expectedHashes returns the average number of hashes needed to find a block with the passed target difficulty bits, which is 2^256 / (target + 1).
+func expectedHashes(
+	bits uint32) float64 {
+	target := blockchain.CompactToBig(bits)
+	if target.Sign() <= 0 {
+		return 0
+	}
+	denominator := new(big.Int).Add(target, big.NewInt(1))
+	work := new(big.Float).Quo(
+		new(big.Float).SetInt(new(big.Int).Lsh(big.NewInt(1), 256)),
+		new(big.Float).SetInt(denominator),
+	)
+	h, _ := work.Float64()
+	return h
+}
+// timeToBlock returns the average time it takes to find a block at the passed hash rate when a block needs the passed number of hashes, or zero if no block is expected to be found.
+func timeToBlock(
+	hashes, rate float64) time.Duration {
+	if hashes <= 0 || rate <= 0 {
+		return 0
+	}
+	seconds := hashes / rate
+	if seconds*float64(time.Second) >= math.MaxInt64 {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+// TimeToBlock returns the average time the miner takes to find a block with any of the algorithms in the passed statistics, mining them at their current hash rates, or zero if no block is expected to be found.
+func TimeToBlock(
+	stats []AlgoStats) time.Duration {
+	var blocksPerSecond float64
+	for _, st := range stats {
+		if st.TimeToBlock > 0 {
+			blocksPerSecond += 1 / st.TimeToBlock.Seconds()
+		}
+	}
+	if blocksPerSecond == 0 {
+		return 0
+	}
+	return timeToBlock(1, blocksPerSecond)
+}
@@ -0,0 +1,25 @@
+// +build linux
+
+package cpuminer
+import (
+	"syscall"
+	"unsafe"
+)
+// setThreadPolicy pins the calling thread to the passed core, unless it is negative, and lowers its scheduling priority by nice. The calling goroutine must be locked to its thread.
+func setThreadPolicy(
+	core, nice int) error {
+	if core >= 0 {
+		var mask [maxCores / 64]uint64
+		mask[core/64] |= 1 << uint(core%64)
+		_, _, errno := syscall.RawSyscall(syscall.SYS_SCHED_SETAFFINITY, 0,
+			uintptr(len(mask)*8), uintptr(unsafe.Pointer(&mask[0])))
+		if errno != 0 {
+			return errno
+		}
+	}
+	if nice > 0 {
+		// On Linux the priority set for a thread ID only applies to that thread.
+		return syscall.Setpriority(syscall.PRIO_PROCESS, syscall.Gettid(), nice)
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+// +build !linux
+
+package cpuminer
+import (
+	"errors"
+)
+// setThreadPolicy is only supported on Linux, so it returns an error whenever a core or priority is set.
+func setThreadPolicy(
+	core, nice int) error {
+	if core >= 0 || nice > 0 {
+		return errors.New("miner thread affinity and priority are only supported on linux")
+	}
+	return nil
+}
@@ -0,0 +1,257 @@
+package controller
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"git.parallelcoin.io/dev/9/pkg/chain/fork"
+)
+// maxKeyName is the longest name of an API key, which is sent with its length in one byte.
+const maxKeyName = 255
+// APIKey is a key a miner worker can authenticate with instead of the shared miner password. Each key can be limited to some addresses, keeps its own statistics and can be revoked without changing the password of every other worker.
+type APIKey struct {
+	sync.Mutex
+	name     string
+	key      []byte
+	allowed  []*net.IPNet
+	revoked  chan struct{}
+	workers  int
+	sessions uint32
+	jobs     uint64
+	accepted uint32
+	rejected uint32
+	lastSeen time.Time
+}
+// APIKeyStats is a snapshot of an API key and the work done by the workers using it.
+type APIKeyStats struct {
+	// Name identifies the key.
+	Name string
+	// Allowed are the networks workers may connect from with the key, or empty for any.
+	Allowed []string
+	// Workers is the number of workers connected with the key.
+	Workers int
+	// Sessions is the number of sessions workers have started with the key.
+	Sessions uint32
+	// Jobs is the number of jobs sent to the workers using the key.
+	Jobs uint64
+	// Accepted is the number of blocks found by the workers using the key that were accepted.
+	Accepted uint32
+	// Rejected is the number of solutions from the workers using the key that were rejected.
+	Rejected uint32
+	// LastSeen is when a worker last subscribed or sent a solution with the key.
+	LastSeen time.Time
+}
+// NewAPIKey returns an API key with the passed name, derived from the secret the same way as the miner password. The allowed addresses are IP addresses or networks in CIDR notation, and when there are none workers can connect from anywhere.
+func NewAPIKey(name, secret string, allowed []string) (*APIKey, error) {
+	if name == "" || len(name) > maxKeyName {
+		return nil, fmt.Errorf("API key name must be 1 to %d bytes", maxKeyName)
+	}
+	if secret == "" {
+		return nil, fmt.Errorf("API key %s has no secret", name)
+	}
+	nets, err := parseAllowed(allowed)
+	if err != nil {
+		return nil, fmt.Errorf("API key %s: %v", name, err)
+	}
+	return &APIKey{
+		name:    name,
+		key:     fork.Argon2i([]byte(secret)),
+		allowed: nets,
+		revoked: make(chan struct{}),
+	}, nil
+}
+// ParseAPIKey returns the API key configured as name:secret, optionally followed by a colon and a comma separated list of the addresses workers may connect from.
+func ParseAPIKey(spec string) (*APIKey, error) {
+	name, secret, allowed, err := splitAPIKey(spec)
+	if err != nil {
+		return nil, err
+	}
+	return NewAPIKey(name, secret, allowed)
+}
+// CheckAPIKey returns an error if the API key configuration is not valid, without deriving the key.
+func CheckAPIKey(spec string) error {
+	name, secret, allowed, err := splitAPIKey(spec)
+	if err != nil {
+		return err
+	}
+	if name == "" || len(name) > maxKeyName || secret == "" {
+		return fmt.Errorf("API key '%s' must have a name of 1 to %d bytes and a secret", spec, maxKeyName)
+	}
+	_, err = parseAllowed(allowed)
+	return err
+}
+// splitAPIKey splits an API key configuration into its name, secret and allowed addresses.
+func splitAPIKey(spec string) (name, secret string, allowed []string, err error) {
+	parts := strings.SplitN(spec, ":", 3)
+	if len(parts) < 2 {
+		err = fmt.Errorf("API key '%s' must be name:secret or name:secret:address,address", spec)
+		return
+	}
+	name, secret = parts[0], parts[1]
+	if len(parts) == 3 && parts[2] != "" {
+		allowed = strings.Split(parts[2], ",")
+	}
+	return
+}
+// parseAllowed parses IP addresses and CIDR networks into networks, treating an address as a network of just itself.
+func parseAllowed(allowed []string) (nets []*net.IPNet, err error) {
+	for _, a := range allowed {
+		if !strings.Contains(a, "/") {
+			ip := net.ParseIP(a)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid allowed address '%s'", a)
+			}
+			bits := 8 * net.IPv4len
+			if ip.To4() == nil {
+				bits = 8 * net.IPv6len
+			}
+			a = fmt.Sprintf("%s/%d", a, bits)
+		}
+		_, n, err := net.ParseCIDR(a)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowed network '%s'", a)
+		}
+		nets = append(nets, n)
+	}
+	return
+}
+// Name returns the name of the key.
+func (k *APIKey) Name() string {
+	return k.name
+}
+// Allows returns whether a worker may connect with the key from the passed address.
+func (k *APIKey) Allows(addr net.Addr) bool {
+	if len(k.allowed) == 0 {
+		return true
+	}
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, n := range k.allowed {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+// Stats returns a snapshot of the key and the work done with it.
+func (k *APIKey) Stats() APIKeyStats {
+	k.Lock()
+	defer k.Unlock()
+	st := APIKeyStats{
+		Name:     k.name,
+		Allowed:  make([]string, 0, len(k.allowed)),
+		Workers:  k.workers,
+		Sessions: k.sessions,
+		Jobs:     k.jobs,
+		Accepted: k.accepted,
+		Rejected: k.rejected,
+		LastSeen: k.lastSeen,
+	}
+	for _, n := range k.allowed {
+		st.Allowed = append(st.Allowed, n.String())
+	}
+	return st
+}
+// connected records a worker starting a session with the key.
+func (k *APIKey) connected() {
+	k.Lock()
+	defer k.Unlock()
+	k.workers++
+	k.sessions++
+	k.lastSeen = time.Now()
+}
+// disconnected records the end of a session with the key.
+func (k *APIKey) disconnected() {
+	k.Lock()
+	defer k.Unlock()
+	k.workers--
+}
+// sentJob records a job sent to a worker using the key.
+func (k *APIKey) sentJob() {
+	k.Lock()
+	defer k.Unlock()
+	k.jobs++
+}
+// solved records whether a solution from a worker using the key was accepted.
+func (k *APIKey) solved(accepted bool) {
+	k.Lock()
+	defer k.Unlock()
+	if accepted {
+		k.accepted++
+	} else {
+		k.rejected++
+	}
+	k.lastSeen = time.Now()
+}
+// APIKeys is the set of API keys workers can authenticate with. It is safe for concurrent access.
+type APIKeys struct {
+	sync.Mutex
+	keys map[string]*APIKey
+}
+// NewAPIKeys returns the set of API keys in the passed configurations, which are parsed with ParseAPIKey.
+func NewAPIKeys(specs []string) (*APIKeys, error) {
+	a := &APIKeys{keys: make(map[string]*APIKey)}
+	for _, spec := range specs {
+		k, err := ParseAPIKey(spec)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := a.keys[k.name]; ok {
+			return nil, fmt.Errorf("API key %s is configured more than once", k.name)
+		}
+		a.keys[k.name] = k
+	}
+	return a, nil
+}
+// Add adds a key to the set, revoking any key with the same name so the workers using the old secret are disconnected.
+func (a *APIKeys) Add(k *APIKey) {
+	a.Lock()
+	defer a.Unlock()
+	if old, ok := a.keys[k.name]; ok {
+		close(old.revoked)
+	}
+	a.keys[k.name] = k
+}
+// Remove revokes the key with the passed name, disconnecting the workers using it. It returns an error if there is no such key.
+func (a *APIKeys) Remove(name string) error {
+	a.Lock()
+	defer a.Unlock()
+	k, ok := a.keys[name]
+	if !ok {
+		return errors.New("no API key named " + name)
+	}
+	close(k.revoked)
+	delete(a.keys, name)
+	return nil
+}
+// Get returns the key with the passed name, or nil if there is none.
+func (a *APIKeys) Get(name string) *APIKey {
+	a.Lock()
+	defer a.Unlock()
+	return a.keys[name]
+}
+// Stats returns a snapshot of every key, sorted by name.
+func (a *APIKeys) Stats() (stats []APIKeyStats) {
+	a.Lock()
+	keys := make([]*APIKey, 0, len(a.keys))
+	for _, k := range a.keys {
+		keys = append(keys, k)
+	}
+	a.Unlock()
+	for _, k := range keys {
+		stats = append(stats, k.Stats())
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Name < stats[j].Name
+	})
+	return
+}
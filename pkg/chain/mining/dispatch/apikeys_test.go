@@ -0,0 +1,66 @@
+package controller
+import (
+	"net"
+	"testing"
+)
+// TestAPIKeys ensures API keys are parsed with their allowed networks, limit where workers connect from and are revoked when removed or replaced.
+func TestAPIKeys(t *testing.T) {
+	for _, spec := range []string{"rig1", ":secret", "rig1:", "rig1:secret:10.0.0.300"} {
+		if CheckAPIKey(spec) == nil {
+			t.Errorf("CheckAPIKey(%q): expected error", spec)
+		}
+	}
+	if err := CheckAPIKey("rig1:secret:10.0.0.0/24,::1"); err != nil {
+		t.Fatalf("CheckAPIKey: unexpected error: %v", err)
+	}
+	keys, err := NewAPIKeys([]string{"rig1:secret:10.0.0.0/24,192.168.1.5", "rig2:other"})
+	if err != nil {
+		t.Fatalf("NewAPIKeys: unexpected error: %v", err)
+	}
+	rig1 := keys.Get("rig1")
+	if rig1 == nil || keys.Get("rig2") == nil || keys.Get("rig3") != nil {
+		t.Fatal("Get: keys not found as configured")
+	}
+	tests := []struct {
+		addr  string
+		allow bool
+	}{
+		{"10.0.0.7:1234", true},
+		{"192.168.1.5:1234", true},
+		{"192.168.1.6:1234", false},
+		{"[::1]:1234", false},
+	}
+	for _, test := range tests {
+		addr, err := net.ResolveTCPAddr("tcp", test.addr)
+		if err != nil {
+			t.Fatalf("ResolveTCPAddr: %v", err)
+		}
+		if rig1.Allows(addr) != test.allow {
+			t.Errorf("Allows(%s) = %v, want %v", test.addr, !test.allow, test.allow)
+		}
+	}
+	replacement, err := NewAPIKey("rig1", "new secret", nil)
+	if err != nil {
+		t.Fatalf("NewAPIKey: unexpected error: %v", err)
+	}
+	keys.Add(replacement)
+	select {
+	case <-rig1.revoked:
+	default:
+		t.Fatal("replaced key was not revoked")
+	}
+	if err := keys.Remove("rig1"); err != nil {
+		t.Fatalf("Remove: unexpected error: %v", err)
+	}
+	select {
+	case <-replacement.revoked:
+	default:
+		t.Fatal("removed key was not revoked")
+	}
+	if keys.Remove("rig1") == nil {
+		t.Fatal("Remove: expected error removing a key twice")
+	}
+	if stats := keys.Stats(); len(stats) != 1 || stats[0].Name != "rig2" {
+		t.Fatalf("Stats: got %+v, want only rig2", stats)
+	}
+}
@@ -0,0 +1,61 @@
+package controller
+import (
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/binary"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+// cipherLabel separates the encryption keys of a session from its HHMAC chains, which are seeded from the same secret.
+var cipherLabel = []byte("chacha20poly1305")
+// Cipher encrypts the payloads of one direction of a session with ChaCha20-Poly1305, so work and solutions can not be read by observers of the connection. The nonce is the count of messages encrypted so far, which both ends keep in step like the HHMAC chain, so it is never sent or reused. A nil Cipher leaves payloads in the clear.
+type Cipher struct {
+	aead    cipher.AEAD
+	counter uint64
+}
+// NewCipher returns a cipher with a key derived from the shared secret and the passed labels, which are the session nonces and direction of the cipher.
+func NewCipher(secret []byte, labels ...[]byte) (*Cipher, error) {
+	h := sha256.New()
+	h.Write(secret)
+	for _, l := range labels {
+		h.Write(l)
+	}
+	h.Write(cipherLabel)
+	aead, err := chacha20poly1305.New(h.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+	return &Cipher{aead: aead}, nil
+}
+// Seal returns the payload of a message of the passed type encrypted, binding the type to it, and advances the nonce.
+func (c *Cipher) Seal(typ byte, payload []byte) []byte {
+	if c == nil {
+		return payload
+	}
+	sealed := c.aead.Seal(nil, c.nonce(), payload, []byte{typ})
+	c.counter++
+	return sealed
+}
+// Open returns the decrypted payload of a message of the passed type. The nonce is only advanced when it decrypts, so a forged message does not desynchronise the session.
+func (c *Cipher) Open(typ byte, sealed []byte) ([]byte, error) {
+	if c == nil {
+		return sealed, nil
+	}
+	payload, err := c.aead.Open(nil, c.nonce(), sealed, []byte{typ})
+	if err != nil {
+		return nil, errAuth
+	}
+	c.counter++
+	return payload, nil
+}
+// skip advances the nonce past a message that was lost or was not encrypted, so the cipher stays in step with the other end.
+func (c *Cipher) skip() {
+	if c != nil {
+		c.counter++
+	}
+}
+// nonce returns the nonce of the next message.
+func (c *Cipher) nonce() []byte {
+	nonce := make([]byte, chacha20poly1305.NonceSize)
+	binary.BigEndian.PutUint64(nonce[chacha20poly1305.NonceSize-8:], c.counter)
+	return nonce
+}
@@ -1,26 +1,31 @@
-// +build test
-
-
 package controller
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
 	"fmt"
-	"math/rand"
+	"net"
 	"sync"
+	"sync/atomic"
 	"time"
-	"git.parallelcoin.io/dev/9/pkg/util/cl"
 	blockchain "git.parallelcoin.io/dev/9/pkg/chain"
 	chaincfg "git.parallelcoin.io/dev/9/pkg/chain/config"
 	"git.parallelcoin.io/dev/9/pkg/chain/fork"
+	chainhash "git.parallelcoin.io/dev/9/pkg/chain/hash"
 	"git.parallelcoin.io/dev/9/pkg/chain/mining"
 	"git.parallelcoin.io/dev/9/pkg/chain/wire"
+	"git.parallelcoin.io/dev/9/pkg/rpc/sub"
 	"git.parallelcoin.io/dev/9/pkg/util"
-	"github.com/xtaci/kcp-go"
+	"git.parallelcoin.io/dev/9/pkg/util/cl"
 )
 const (
-	// maxNonce is the maximum value a nonce can be in a block header.
-	maxNonce = ^uint32(0) // 2^32 - 1
-	// maxExtraNonce is the maximum value an extra nonce used in a coinbase transaction can be.
-	maxExtraNonce = ^uint64(0) // 2^64 - 1
+	// maxJobs is the number of recent jobs kept for each worker, so a solution for work sent just before a newer job still counts.
+	maxJobs = 16
+	// workCheckInterval is how often the controller checks whether the work of its workers is stale.
+	workCheckInterval = time.Second / 2
+	// templateRefresh is how long a block template is kept after the memory pool has changed.
+	templateRefresh = time.Minute
 )
 // Config is a descriptor containing the controller configuration.
 type Config struct {
@@ -30,33 +35,75 @@ type Config struct {
 	ChainParams *chaincfg.Params
 	// BlockTemplateGenerator identifies the instance to use in order to generate block templates that the miner will attempt to solve.
 	BlockTemplateGenerator *mining.BlkTmplGenerator
-	// MiningAddrs is a list of payment addresses to use for the generated blocks.  Each generated block will randomly choose one of thec.
-	MiningAddrs []util.Address
+	// PayAddrs is the set of payment addresses to use for the generated blocks.  Each generated block pays to the next one its rotation policy chooses.
+	PayAddrs *mining.PayAddrs
 	// ProcessBlock defines the function to call with any solved blocks. It typically must run the provided block through the same set of rules and handling as any other block coming from the network.
 	ProcessBlock func(*util.Block, blockchain.BehaviorFlags) (bool, error)
-	// MinerListener is the listener that will accept miner subscriptions and such
-	MinerListener string
-	// MinerKey is generated from the password specified in the main configuration for miner port using Stribog hash to derive the nonce, Argon2i to expand the password, and a final pass of Keccak
+	// MinerListeners are the addresses that accept miner worker connections
+	MinerListeners []string
+	// MinerKey is derived from the password specified in the main configuration for the miner listener, and seeds the HHMAC chains that authenticate each worker session
 	MinerKey []byte
+	// APIKeys are the per-worker keys workers can authenticate with instead of MinerKey, which may be nil when there are none
+	APIKeys *APIKeys
+	// Encrypt encrypts the sessions with every worker, rather than only those of the workers that ask for it
+	Encrypt bool
+	// Multicast is the multicast group or broadcast address new jobs are announced on to the workers on the LAN that ask for them, which then return their solutions on their own connection. When it is empty every worker is sent its own jobs.
+	Multicast string
+	// Identity is the key pair the controller proves it holds to workers. When it is nil the identity is derived from MinerKey, so workers using the miner password can check it without being told it.
+	Identity *Identity
+	// OnJoin and OnLeave, when set, are called when a worker subscribes and when its session ends, which is as soon as it stops answering pings, so its work can be given to others promptly.
+	OnJoin  func(Peer)
+	OnLeave func(Peer)
+	// Advertise answers multicast DNS queries on the LAN for the miner service with the listeners, so workers without controller addresses configured can find them
+	Advertise bool
 	// ConnectedCount defines the function to use to obtain how many other peers the server is connected to.  This is used by the automatic persistent mining routine to determine whether or it should attempt mining.  This is useful because there is no point in mining when not connected to any peers since there would no be anyone to send any found blocks to.
 	ConnectedCount func() int32
 	// IsCurrent defines the function to use to obtain whether or not the block chain is current.  This is used by the automatic persistent mining routine to determine whether or it should attempt mining. This is useful because there is no point in mining if the chain is not current since any solved blocks would be on a side chain and and up orphaned anyways.
 	IsCurrent func() bool
 }
-// Controller delivers new work to miner clients
+// Controller delivers new work to miner workers connected to its listeners and submits the blocks they solve
 type Controller struct {
 	sync.Mutex
-	b                *blockchain.BlockChain
-	g                *mining.BlkTmplGenerator
-	cfg              Config
-	started          bool
-	submitBlockLock  sync.Mutex
-	wg               sync.WaitGroup
-	workerWg         sync.WaitGroup
-	updateNumWorkers chan struct{}
-	quit             chan struct{}
-	listener         *kcp.Listener
-	workers          []*kcp.UDPSession
+	b               *blockchain.BlockChain
+	g               *mining.BlkTmplGenerator
+	cfg             Config
+	identity        *Identity
+	started         bool
+	submitBlockLock sync.Mutex
+	wg              sync.WaitGroup
+	quit            chan struct{}
+	listeners       []net.Listener
+	sessions        map[*session]struct{}
+	newSession      chan *session
+	peers           *Peers
+	announcer       *sub.Announcer
+	advertiser      *Advertiser
+	announceKey     []byte
+	nextSlot        uint32
+	freeSlots       []uint32
+	nextJob         uint32
+	extraNonce      uint64
+}
+// session is the state of the connection with one worker.
+type session struct {
+	conn     net.Conn
+	algo     string
+	apiKey   *APIKey
+	identity []byte
+	// multicast is set for workers that are announced jobs rather than sent them, and slot is the part of the nonces of the announced jobs the worker searches.
+	multicast bool
+	slot      uint32
+	send      *channel
+	recv      *channel
+	queue     *sendQueue
+	jobMtx    sync.Mutex
+	jobs      map[uint32]*sessionJob
+	order     []uint32
+}
+// sessionJob is a block sent to a worker to solve, with the extra nonce of the worker in its coinbase.
+type sessionJob struct {
+	block  *wire.MsgBlock
+	height int32
 }
 // submitBlock submits the passed block to network after ensuring it passes all of the consensus validation rules.
 func (c *Controller) submitBlock(block *util.Block) bool {
@@ -110,117 +157,381 @@ func (c *Controller) submitBlock(block *util.Block) bool {
 	})
 	return true
 }
-// solveBlock attempts to find some combination of a nonce, extra nonce, and current timestamp which makes the passed block hash to a value less than the target difficulty.  The timestamp is updated periodically and the passed block is modified with all tweaks during this process.  This means that when the function returns true, the block is ready for submission. This function will return early with false when conditions that trigger a stale block such as a new block showing up or periodically when there are new transactions and enough time has elapsed without finding a solution.
-func (c *Controller) solveBlock(msgBlock *wire.MsgBlock, blockHeight int32, testnet bool, ticker *time.Ticker, submissionReceived chan *wire.MsgBlock, quit chan struct{}) bool {
-	// Choose a random extra nonce offset for this block template and worker.
-	enOffset, err := wire.RandomUint64()
-	if err != nil {
-		log <- cl.Error{"unexpected error while generating random extra nonce offset:", err}
-		enOffset = 0
-	}
-	header := &msgBlock.Header
-	targetDifficulty := blockchain.CompactToBig(header.Bits)
-	lastGenerated := time.Now()
-	lastTxUpdate := c.g.TxSource().LastUpdated()
-	// Note that the entire extra nonce range is iterated and the offset is added relying on the fact that overflow will wrap around 0 as provided by the Go spec.
-	for extraNonce := uint64(0); extraNonce < maxExtraNonce; extraNonce++ {
-		// Update the extra nonce in the block template with the new value by regenerating the coinbase script and setting the merkle root to the new value.
-		c.g.UpdateExtraNonce(msgBlock, blockHeight, extraNonce+enOffset)
-		// Search through the entire nonce range for a solution while periodically checking for early quit and stale block conditions along with updates to the speed monitor.
-		for i := uint32(0); i <= maxNonce; i++ {
+// acceptWorkers accepts worker connections on the passed listener until it is closed. It must be run as a goroutine.
+func (c *Controller) acceptWorkers(l net.Listener) {
+	defer c.wg.Done()
+	for {
+		conn, err := l.Accept()
+		if err != nil {
 			select {
-			case <-quit:
-				// fmt.Println("chan:<-quit")
-				return false
-			case <-ticker.C:
-				// fmt.Println("chan:<-ticker.C")
-				// The current block is stale if the best block has changed.
-				best := c.g.BestSnapshot()
-				if !header.PrevBlock.IsEqual(&best.Hash) {
-					return false
-				}
-				// The current block is stale if the memory pool has been updated since the block template was generated and it has been at least one minute.
-				if lastTxUpdate != c.g.TxSource().LastUpdated() &&
-					time.Now().After(lastGenerated.Add(time.Minute)) {
-					return false
-				}
-				c.g.UpdateBlockTime(msgBlock)
-			case <-submissionReceived:
-				// fmt.Println("chan:<-submissionReceived")
-				// Here we will send out the updated block to subscribed client workers
+			case <-c.quit:
+				return
 			default:
 			}
-			// Instead of directly attempting to solve blocks, here we send out the new block data to subscribed miner clients and process submissions they return when they find a solution
-			header.Nonce = i
-			hash := header.BlockHashWithAlgos(int32(fork.GetCurrent(blockHeight)))
-			// The block is solved when the new block hash is less than the target difficulty.  Yay!
-			if blockchain.HashToBig(&hash).Cmp(targetDifficulty) <= 0 {
-				return true
+			log <- cl.Warn{"miner listener", l.Addr(), "failed to accept:", err}
+			if ne, ok := err.(net.Error); ok && ne.Temporary() {
+				time.Sleep(time.Second)
+				continue
 			}
+			return
 		}
+		c.wg.Add(1)
+		go c.handleWorker(conn)
 	}
-	return false
 }
-// generateBlocks is a worker that is controlled by the miningWorkerController. It is self contained in that it creates block templates and attempts to solve them while detecting when it is performing stale work and reacting accordingly by generating a new block template.  When a block is solved, it is submitted. It must be run as a goroutine.
-func (c *Controller) generateBlocks(quit chan struct{}) {
-	// Start a ticker which is used to signal checks for stale work and updates to the speed monitor.
-	ticker := time.NewTicker(time.Second / 2)
-	defer ticker.Stop()
-	// Create a channel to receive block submissions
-	var submission chan *wire.MsgBlock
-out:
-	for {
-		select {
-		case <-quit: // Quit when the miner is stopped.
-			// fmt.Println("chan:<-quit")
-			break out
-		default: // Non-blocking select to fall through
+// handshake authenticates a new worker connection. The hello of the worker names the API key it uses as the preshared key of the handshake, or none for the miner password, and subscribe only decrypts if the worker holds that key. The session is encrypted when the controller or the worker asks for it.
+func (c *Controller) handshake(conn net.Conn) (*session, error) {
+	typ, hello, err := readMsg(conn, nil)
+	if err != nil {
+		return nil, err
+	}
+	if typ != msgHello {
+		return nil, errors.New("expected hello message")
+	}
+	hs := newHandshake(c.identity, nil)
+	if hello, err = hs.readHello(hello); err != nil {
+		return nil, err
+	}
+	if len(hello) < 2 || len(hello) != 2+int(hello[1]) {
+		return nil, errors.New("invalid hello message")
+	}
+	flags := hello[0]
+	hs.psk = c.cfg.MinerKey
+	var apiKey *APIKey
+	if name := string(hello[2:]); name != "" {
+		if c.cfg.APIKeys != nil {
+			apiKey = c.cfg.APIKeys.Get(name)
 		}
-		// Wait until there is a connection to at least one other peer since there is no way to relay a found block or receive transactions to work on when there are no connected peers.
-		if c.cfg.ConnectedCount() == 0 {
-			time.Sleep(time.Second)
-			continue
+		if apiKey == nil {
+			return nil, fmt.Errorf("unknown API key %q", name)
+		}
+		if !apiKey.Allows(conn.RemoteAddr()) {
+			return nil, fmt.Errorf("API key %s is not allowed from this address", name)
+		}
+		hs.psk = apiKey.key
+	}
+	var ourFlags byte
+	if c.cfg.Encrypt {
+		ourFlags |= flagEncrypt
+	}
+	if c.announcer != nil {
+		ourFlags |= flagMulticast
+	}
+	welcome, err := hs.writeWelcome([]byte{ourFlags})
+	if err != nil {
+		return nil, err
+	}
+	if err = writeMsg(conn, nil, 0, msgWelcome, welcome); err != nil {
+		return nil, err
+	}
+	typ, subscribe, err := readMsg(conn, nil)
+	if err != nil {
+		return nil, err
+	}
+	if typ != msgSubscribe {
+		return nil, errors.New("expected subscribe message")
+	}
+	algo, err := hs.readSubscribe(subscribe)
+	if err != nil {
+		return nil, errAuth
+	}
+	if !fork.IsAlgo(string(algo)) && string(algo) != "random" {
+		return nil, fmt.Errorf("unknown algorithm %q", algo)
+	}
+	fromWorker, toWorker := hs.split()
+	encrypt := (flags|ourFlags)&flagEncrypt != 0
+	s := &session{
+		conn:     conn,
+		algo:     string(algo),
+		apiKey:   apiKey,
+		identity: hs.rs,
+		jobs:     make(map[uint32]*sessionJob),
+	}
+	if s.send, err = newChannel(toWorker, encrypt); err != nil {
+		return nil, err
+	}
+	if s.recv, err = newChannel(fromWorker, encrypt); err != nil {
+		return nil, err
+	}
+	s.queue = newSendQueue(conn, s.send)
+	if flags&ourFlags&flagMulticast != 0 {
+		s.multicast = true
+		s.slot = c.assignSlot()
+	}
+	return s, nil
+}
+// handleWorker runs the session with a worker until the connection fails or the controller stops. It must be run as a goroutine.
+func (c *Controller) handleWorker(conn net.Conn) {
+	defer c.wg.Done()
+	defer conn.Close()
+	s, err := c.handshake(conn)
+	if err != nil {
+		log <- cl.Warn{"miner worker", conn.RemoteAddr(), "failed to subscribe:", err}
+		return
+	}
+	go s.queue.run()
+	defer s.queue.close()
+	c.Lock()
+	c.sessions[s] = struct{}{}
+	c.Unlock()
+	defer func() {
+		c.Lock()
+		delete(c.sessions, s)
+		// The nonces of the slot of a multicast worker that left are searched by the next one to subscribe.
+		if s.multicast {
+			c.freeSlots = append(c.freeSlots, s.slot)
 		}
-		// No point in searching for a solution before the chain is synced.  Also, grab the same lock as used for block submission, since the current block will be changing and this would otherwise end up building a new block template on a block that is in the process of becoming stale.
-		c.submitBlockLock.Lock()
-		curHeight := c.g.BestSnapshot().Height
-		if curHeight != 0 && !c.cfg.IsCurrent() {
-			c.submitBlockLock.Unlock()
-			time.Sleep(time.Second)
+		c.Unlock()
+		log <- cl.Info{"miner worker", conn.RemoteAddr(), "disconnected"}
+	}()
+	if s.apiKey != nil {
+		s.apiKey.connected()
+		defer s.apiKey.disconnected()
+		log <- cl.Infof{"miner worker %s subscribed for %s with API key %s and identity %x",
+			conn.RemoteAddr(), s.algo, s.apiKey.name, s.identity}
+	} else {
+		log <- cl.Infof{"miner worker %s subscribed for %s with identity %x",
+			conn.RemoteAddr(), s.algo, s.identity}
+	}
+	if s.multicast {
+		payload := make([]byte, 4, multicastSize)
+		binary.BigEndian.PutUint32(payload, s.slot)
+		s.write(msgMulticast, append(payload, c.announceKey...))
+	}
+	select {
+	case c.newSession <- s:
+	case <-c.quit:
+		return
+	}
+	c.peers.join(conn, Peer{Addr: conn.RemoteAddr().String(), Transport: transportOf(conn),
+		Identity: s.identity, Algo: s.algo}, s.recv,
+		func() error {
+			return s.write(msgPing, nil)
+		})
+	defer c.peers.leave(conn)
+	if s.apiKey != nil {
+		done := make(chan struct{})
+		defer close(done)
+		c.wg.Add(1)
+		go c.watchKey(s, done)
+	}
+	for {
+		typ, payload, err := s.recv.read(conn)
+		if err == errResync {
+			log <- cl.Debug{"miner worker", conn.RemoteAddr(), err}
+			s.write(msgResync, []byte{resyncRequest})
 			continue
 		}
-		// Choose a payment address at random
-		rand.Seed(time.Now().UnixNano())
-		payToAddr := c.cfg.MiningAddrs[rand.Intn(len(c.cfg.MiningAddrs))]
-		// Create a new block template using the available transactions in the memory pool as a source of transactions to potentially include in the block.
-		template, err := c.g.NewBlockTemplate(payToAddr, "")
-		c.submitBlockLock.Unlock()
 		if err != nil {
-			log <- cl.Error{"failed to create new block template: %v", err}
-			continue
+			log <- cl.Debug{"miner worker", conn.RemoteAddr(), err}
+			return
 		}
-		// Attempt to solve the block.  The function will exit early with false when conditions that trigger a stale block, so a new block template can be generated.  When the return is true a solution was found, so submit the solved block.
-		if c.solveBlock(template.Block, curHeight+1, c.cfg.ChainParams.Name == "testnet", ticker, submission, quit) {
-			block := util.NewBlock(template.Block)
-			c.submitBlock(block)
+		c.peers.seen(conn)
+		switch typ {
+		case msgResync:
+			if payload[0]&resyncRequest != 0 {
+				s.write(msgResync, []byte{0})
+			}
+		case msgPing:
+			s.write(msgPong, nil)
+		case msgPong:
+			c.peers.pong(conn)
+		case msgSolution:
+			c.handleSolution(s, payload)
+		default:
+			log <- cl.Warn{"miner worker", conn.RemoteAddr(), "sent unexpected message", typ}
+			return
 		}
 	}
-	c.workerWg.Done()
 }
-func (c *Controller) minerController() {
-	c.workerWg.Add(1)
-	quit := make(chan struct{})
-	go c.generateBlocks(quit)
-out:
+// watchKey disconnects the worker when its API key is revoked, until the session ends. It must be run as a goroutine.
+func (c *Controller) watchKey(s *session, done chan struct{}) {
+	defer c.wg.Done()
+	select {
+	case <-s.apiKey.revoked:
+		log <- cl.Info{"API key", s.apiKey.name, "revoked, disconnecting miner worker",
+			s.conn.RemoteAddr()}
+		s.conn.Close()
+	case <-done:
+	case <-c.quit:
+	}
+}
+// handleSolution checks the solution of a worker against its job and submits the solved block.
+func (c *Controller) handleSolution(s *session, payload []byte) {
+	var sol solution
+	if err := sol.deserialize(payload); err != nil {
+		log <- cl.Warn{"miner worker", s.conn.RemoteAddr(), err}
+		return
+	}
+	s.jobMtx.Lock()
+	sj, ok := s.jobs[sol.ID]
+	s.jobMtx.Unlock()
+	if !ok {
+		s.result(false, "unknown or stale job")
+		return
+	}
+	msgBlock := *sj.block
+	msgBlock.Header.Nonce = sol.Nonce
+	msgBlock.Header.Timestamp = time.Unix(int64(sol.Timestamp), 0)
+	hash := msgBlock.Header.BlockHashWithAlgos(sj.height)
+	if blockchain.HashToBig(&hash).Cmp(
+		blockchain.CompactToBig(msgBlock.Header.Bits)) > 0 {
+		s.result(false, "hash does not meet the target")
+		return
+	}
+	block := util.NewBlock(&msgBlock)
+	block.SetHeight(sj.height)
+	if !c.submitBlock(block) {
+		s.result(false, "block rejected")
+		return
+	}
+	s.result(true, block.Hash().String())
+}
+// workLoop sends new jobs to workers when they subscribe, when the best block changes and when the memory pool has changed and the jobs are old enough. It must be run as a goroutine.
+func (c *Controller) workLoop() {
+	defer c.wg.Done()
+	ticker := time.NewTicker(workCheckInterval)
+	defer ticker.Stop()
+	var tip chainhash.Hash
+	var lastTxUpdate, generated time.Time
 	for {
 		select {
 		case <-c.quit:
-			// fmt.Println("chan:<-c.quit")
-			close(quit)
-			break out
+			return
+		case s := <-c.newSession:
+			// The first job of a worker is sent to it, so it does not wait for the next announcement.
+			c.sendWork([]*session{s}, false)
+		case <-ticker.C:
+			best := c.g.BestSnapshot()
+			txUpdate := c.g.TxSource().LastUpdated()
+			if best.Hash == tip && (txUpdate == lastTxUpdate ||
+				time.Since(generated) < templateRefresh) {
+				continue
+			}
+			c.Lock()
+			sessions := make([]*session, 0, len(c.sessions))
+			for s := range c.sessions {
+				sessions = append(sessions, s)
+			}
+			c.Unlock()
+			if c.sendWork(sessions, true) {
+				tip, lastTxUpdate, generated = best.Hash, txUpdate, time.Now()
+			}
 		}
 	}
 }
+// sendWork generates a block template for each algorithm the passed workers mine and sends each worker a job with its own extra nonce. When announce is set the multicast workers are instead announced one job for each algorithm they subscribed for, which they share by searching their own slot of the nonces. It returns false if no work could be generated.
+func (c *Controller) sendWork(sessions []*session, announce bool) bool {
+	if len(sessions) == 0 {
+		return true
+	}
+	// There is no point in handing out work when found blocks can not be relayed or would end up on a side chain.
+	if c.cfg.ConnectedCount() == 0 {
+		return false
+	}
+	c.submitBlockLock.Lock()
+	best := c.g.BestSnapshot()
+	if best.Height != 0 && !c.cfg.IsCurrent() {
+		c.submitBlockLock.Unlock()
+		return false
+	}
+	height := best.Height + 1
+	templates := make(map[string]*mining.BlockTemplate)
+	jobs := make(map[*session]*job)
+	announced := make(map[string]*job)
+	blocks := make(map[uint32]*wire.MsgBlock)
+	for _, s := range sessions {
+		multicast := announce && s.multicast
+		if j, ok := announced[s.algo]; multicast && ok {
+			s.addJob(j.ID, blocks[j.ID], height)
+			if s.apiKey != nil {
+				s.apiKey.sentJob()
+			}
+			continue
+		}
+		algo := fork.GetAlgoName(fork.GetAlgoVer(s.algo, best.Height), best.Height)
+		template, ok := templates[algo]
+		if !ok {
+			// Wait for a payment address to be added if they have all been removed.
+			payToAddr := c.cfg.PayAddrs.Next()
+			if payToAddr == nil {
+				log <- cl.Warn{"no payment addresses to generate blocks to"}
+				break
+			}
+			var err error
+			template, err = c.g.NewBlockTemplate(payToAddr, algo)
+			if err != nil {
+				log <- cl.Error{"failed to create new block template:", err}
+				continue
+			}
+			templates[algo] = template
+		}
+		msgBlock := copyBlock(template.Block)
+		err := c.g.UpdateExtraNonce(msgBlock, height,
+			atomic.AddUint64(&c.extraNonce, 1))
+		if err != nil {
+			log <- cl.Error{"failed to update extra nonce:", err}
+			continue
+		}
+		j := s.addJob(atomic.AddUint32(&c.nextJob, 1), msgBlock, height)
+		if multicast {
+			announced[s.algo] = j
+			blocks[j.ID] = msgBlock
+		} else {
+			jobs[s] = j
+		}
+		if s.apiKey != nil {
+			s.apiKey.sentJob()
+		}
+	}
+	c.submitBlockLock.Unlock()
+	for s, j := range jobs {
+		s.write(msgJob, j.serialize())
+	}
+	for algo, j := range announced {
+		if err := c.announcer.Announce(announcement(c.announceKey, algo, j)); err != nil {
+			log <- cl.Warn{"failed to announce miner job:", err}
+		}
+	}
+	return len(templates) > 0
+}
+// addJob stores the block sent to the worker, dropping the oldest if there are too many, and returns the job to send.
+func (s *session) addJob(id uint32, msgBlock *wire.MsgBlock, height int32) *job {
+	s.jobMtx.Lock()
+	defer s.jobMtx.Unlock()
+	s.jobs[id] = &sessionJob{block: msgBlock, height: height}
+	s.order = append(s.order, id)
+	if len(s.order) > maxJobs {
+		delete(s.jobs, s.order[0])
+		s.order = s.order[1:]
+	}
+	return &job{ID: id, Height: height, Header: msgBlock.Header}
+}
+// write queues an authenticated message to the worker, encrypted if the session is. The connection is closed if it fails to send, and later writes return the error.
+func (s *session) write(typ byte, payload []byte) error {
+	return s.queue.push(typ, payload)
+}
+// result tells the worker whether its solution was accepted and counts it for the API key of the worker.
+func (s *session) result(accepted bool, message string) {
+	if s.apiKey != nil {
+		s.apiKey.solved(accepted)
+	}
+	flag := byte(0)
+	if accepted {
+		flag = 1
+	}
+	s.write(msgResult, append([]byte{flag}, message...))
+}
+// copyBlock returns a copy of the block with its own coinbase so the extra nonce can be changed without affecting other copies.
+func copyBlock(msgBlock *wire.MsgBlock) *wire.MsgBlock {
+	c := &wire.MsgBlock{
+		Header:       msgBlock.Header,
+		Transactions: make([]*wire.MsgTx, len(msgBlock.Transactions)),
+	}
+	copy(c.Transactions, msgBlock.Transactions)
+	c.Transactions[0] = msgBlock.Transactions[0].Copy()
+	return c
+}
 // Start begins the miner controller process. Calling this function when the miner controller has already been started will have no effect.
 func (c *Controller) Start() {
 	c.Lock()
@@ -229,35 +540,132 @@ func (c *Controller) Start() {
 		return
 	}
 	c.quit = make(chan struct{})
-	c.wg.Add(1)
-	go c.minerController()
+	c.listeners = c.listeners[:0]
+	var advertised []string
+	if c.cfg.Multicast != "" {
+		if err := c.startAnnouncer(); err != nil {
+			log <- cl.Error{"unable to announce miner jobs on", c.cfg.Multicast, err}
+		} else {
+			log <- cl.Info{"miner controller announcing jobs on", c.cfg.Multicast}
+		}
+	}
+	for _, addr := range c.cfg.MinerListeners {
+		l, err := listen(addr)
+		if err != nil {
+			log <- cl.Error{"unable to listen for miner workers on", addr, err}
+			continue
+		}
+		log <- cl.Info{"miner controller listening on", l.Addr()}
+		c.listeners = append(c.listeners, l)
+		_, hostPort := splitAddr(addr)
+		advertised = append(advertised, addr[:len(addr)-len(hostPort)]+l.Addr().String())
+		c.wg.Add(1)
+		go c.acceptWorkers(l)
+	}
+	if c.cfg.Advertise && len(advertised) > 0 {
+		a, err := Advertise(advertised, c.cfg.ChainParams.Name)
+		if err != nil {
+			log <- cl.Error{"unable to advertise the miner listeners on the LAN", err}
+		} else {
+			log <- cl.Info{"miner controller advertising", advertised, "on the LAN"}
+			c.advertiser = a
+		}
+	}
+	c.wg.Add(2)
+	go c.workLoop()
+	go func() {
+		defer c.wg.Done()
+		c.peers.run(c.quit)
+	}()
 	c.started = true
 	log <- cl.Inf("Miner controller started")
+	log <- cl.Infof{"miner controller identity %x", c.identity.public}
 }
-// Stop gracefully stops the mining process by signalling all workers, and the speed monitor to quit.  Calling this function when the miner controller has not already been started will have no effect.
+// Stop gracefully stops the controller by closing its listeners and the connections of all workers.  Calling this function when the miner controller has not already been started will have no effect.
 func (c *Controller) Stop() {
 	c.Lock()
-	defer c.Unlock()
 	if !c.started {
+		c.Unlock()
 		return
 	}
 	close(c.quit)
-	c.wg.Wait()
+	if c.advertiser != nil {
+		c.advertiser.Close()
+		c.advertiser = nil
+	}
+	for _, l := range c.listeners {
+		l.Close()
+	}
+	for s := range c.sessions {
+		s.conn.Close()
+	}
 	c.started = false
+	c.Unlock()
+	c.wg.Wait()
+	if c.announcer != nil {
+		c.announcer.Close()
+		c.announcer = nil
+	}
 	log <- cl.Inf("Miner controller stopped")
 }
+// assignSlot returns the slot of the nonces of announced jobs for a new multicast worker, which is the slot of a worker that left if there is one.
+func (c *Controller) assignSlot() (slot uint32) {
+	c.Lock()
+	defer c.Unlock()
+	if n := len(c.freeSlots); n > 0 {
+		slot = c.freeSlots[n-1]
+		c.freeSlots = c.freeSlots[:n-1]
+		return
+	}
+	slot = c.nextSlot
+	c.nextSlot++
+	return
+}
+// startAnnouncer opens the announcer of the multicast address with a new key to authenticate announcements, which is sent to the multicast workers on their own connection.
+func (c *Controller) startAnnouncer() (err error) {
+	c.announceKey = make([]byte, sha256.Size)
+	if _, err = rand.Read(c.announceKey); err != nil {
+		return
+	}
+	c.announcer, err = sub.NewAnnouncer(c.cfg.Multicast)
+	return
+}
 // IsMining returns whether or not the miner controller has been started and is therefore currenting mining. This function is safe for concurrent access.
 func (c *Controller) IsMining() bool {
 	c.Lock()
 	defer c.Unlock()
 	return c.started
 }
-// New returns a new instance of a CPU miner for the provided configuration. Use Start to begin the mining process.  See the documentation for Controller type for more details.
+// Peers returns the workers that are subscribed. This function is safe for concurrent access.
+func (c *Controller) Peers() []Peer {
+	return c.peers.List()
+}
+// Workers returns the number of subscribed workers. This function is safe for concurrent access.
+func (c *Controller) Workers() int {
+	c.Lock()
+	defer c.Unlock()
+	return len(c.sessions)
+}
+// New returns a new instance of a miner controller for the provided configuration. Use Start to begin the mining process.  See the documentation for Controller type for more details.
 func New(
 	cfg *Config) *Controller {
+	// Start the extra nonces at a random offset so restarts of the controller do not hand out work that was already searched.
+	extraNonce, err := wire.RandomUint64()
+	if err != nil {
+		log <- cl.Error{"unexpected error while generating random extra nonce offset:", err}
+	}
+	identity := cfg.Identity
+	if identity == nil {
+		identity = IdentityFromSeed(cfg.MinerKey)
+	}
 	return &Controller{
-		b:   cfg.Blockchain,
-		g:   cfg.BlockTemplateGenerator,
-		cfg: *cfg,
+		b:          cfg.Blockchain,
+		g:          cfg.BlockTemplateGenerator,
+		cfg:        *cfg,
+		identity:   identity,
+		sessions:   make(map[*session]struct{}),
+		newSession: make(chan *session),
+		peers:      NewPeers(HeartbeatInterval, cfg.OnJoin, cfg.OnLeave),
+		extraNonce: extraNonce,
 	}
 }
@@ -0,0 +1,260 @@
+package controller
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"golang.org/x/net/dns/dnsmessage"
+	"git.parallelcoin.io/dev/9/pkg/util/cl"
+)
+// Discovery of controllers on the LAN by multicast DNS service discovery (RFC 6762 and RFC 6763). A controller answers queries for the miner service with a record for each of its listeners, naming its port and in its text the transport and the chain it mines, and a worker without addresses configured queries for the service and connects to the listeners that answer for its chain.
+const (
+	// mdnsService is the DNS-SD service type of miner listeners.
+	mdnsService = "_parallelcoin-miner._tcp.local."
+	// mdnsTTL is the time to live of the records of an advertised listener, in seconds.
+	mdnsTTL = 120
+	// DiscoverTimeout is how long a worker waits for answers when it discovers controllers.
+	DiscoverTimeout = time.Second * 3
+)
+var (
+	// mdnsGroup is the multicast group and port of mDNS.
+	mdnsGroup = &net.UDPAddr{IP: net.IPv4(224, 0, 0, 251), Port: 5353}
+)
+// Advertiser answers the mDNS queries for the miner service on the LAN with the listeners of a controller.
+type Advertiser struct {
+	conn     *net.UDPConn
+	network  string
+	host     dnsmessage.Name
+	services []advertised
+}
+// advertised is a listener as it is advertised, with the name of its service instance.
+type advertised struct {
+	instance  dnsmessage.Name
+	transport string
+	port      uint16
+}
+// Advertise starts answering queries for the miner service with the passed listener addresses, which keep the scheme of their transport, for the chain with the passed network name.
+func Advertise(listeners []string, network string) (*Advertiser, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, err
+	}
+	hostname = strings.Split(hostname, ".")[0]
+	a := &Advertiser{network: network}
+	if a.host, err = dnsmessage.NewName(hostname + ".local."); err != nil {
+		return nil, err
+	}
+	for _, l := range listeners {
+		transport, hostPort := splitAddr(l)
+		_, p, err := net.SplitHostPort(hostPort)
+		if err != nil {
+			return nil, err
+		}
+		port, err := strconv.ParseUint(p, 10, 16)
+		if err != nil {
+			return nil, err
+		}
+		instance, err := dnsmessage.NewName(fmt.Sprintf("%s-%s-%d.%s", hostname, transport, port, mdnsService))
+		if err != nil {
+			return nil, err
+		}
+		a.services = append(a.services, advertised{instance: instance, transport: transport, port: uint16(port)})
+	}
+	if a.conn, err = net.ListenMulticastUDP("udp4", nil, mdnsGroup); err != nil {
+		return nil, err
+	}
+	go a.serve()
+	return a, nil
+}
+// Close stops answering queries.
+func (a *Advertiser) Close() error {
+	return a.conn.Close()
+}
+// serve answers the queries for the miner service until the advertiser is closed. Queries from the mDNS port are answered to the group, and queries from any other port, which is how workers ask, to their sender. It must be run as a goroutine.
+func (a *Advertiser) serve() {
+	buf := make([]byte, 9000)
+	for {
+		n, from, err := a.conn.ReadFromUDP(buf)
+		if err != nil {
+			return
+		}
+		var p dnsmessage.Parser
+		h, err := p.Start(buf[:n])
+		if err != nil || h.Response {
+			continue
+		}
+		questions, err := p.AllQuestions()
+		if err != nil {
+			continue
+		}
+		for _, q := range questions {
+			if (q.Type != dnsmessage.TypePTR && q.Type != dnsmessage.TypeALL) ||
+				!strings.EqualFold(q.Name.String(), mdnsService) {
+				continue
+			}
+			to := mdnsGroup
+			if from.Port != mdnsGroup.Port {
+				to = from
+			}
+			answer, err := a.answer(h.ID, q)
+			if err != nil {
+				log <- cl.Debug{"unable to answer mDNS query:", err}
+				continue
+			}
+			if _, err = a.conn.WriteToUDP(answer, to); err != nil {
+				log <- cl.Debug{"unable to answer mDNS query from", from, err}
+			}
+		}
+	}
+}
+// answer returns the answer to a query for the miner service, with the pointer, service and text records of each listener and the addresses of the host.
+func (a *Advertiser) answer(id uint16, q dnsmessage.Question) ([]byte, error) {
+	b := dnsmessage.NewBuilder(nil, dnsmessage.Header{ID: id, Response: true, Authoritative: true})
+	b.EnableCompression()
+	// The question is repeated for queriers that are not on the mDNS port, which expect a unicast DNS answer.
+	if err := b.StartQuestions(); err != nil {
+		return nil, err
+	}
+	if err := b.Question(q); err != nil {
+		return nil, err
+	}
+	if err := b.StartAnswers(); err != nil {
+		return nil, err
+	}
+	header := func(name dnsmessage.Name, typ dnsmessage.Type) dnsmessage.ResourceHeader {
+		return dnsmessage.ResourceHeader{Name: name, Type: typ, Class: dnsmessage.ClassINET, TTL: mdnsTTL}
+	}
+	for _, s := range a.services {
+		if err := b.PTRResource(header(q.Name, dnsmessage.TypePTR), dnsmessage.PTRResource{PTR: s.instance}); err != nil {
+			return nil, err
+		}
+	}
+	if err := b.StartAdditionals(); err != nil {
+		return nil, err
+	}
+	for _, s := range a.services {
+		if err := b.SRVResource(header(s.instance, dnsmessage.TypeSRV),
+			dnsmessage.SRVResource{Port: s.port, Target: a.host}); err != nil {
+			return nil, err
+		}
+		if err := b.TXTResource(header(s.instance, dnsmessage.TypeTXT),
+			dnsmessage.TXTResource{TXT: []string{"transport=" + s.transport, "net=" + a.network}}); err != nil {
+			return nil, err
+		}
+	}
+	addrs, _ := net.InterfaceAddrs()
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok && !ipNet.IP.IsLoopback() && ipNet.IP.To4() != nil {
+			var ip [4]byte
+			copy(ip[:], ipNet.IP.To4())
+			if err := b.AResource(header(a.host, dnsmessage.TypeA), dnsmessage.AResource{A: ip}); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return b.Finish()
+}
+// Discover queries the LAN for the listeners of controllers mining the chain with the passed network name and returns their addresses, with the scheme of their transport, once the timeout has passed. Each listener is dialled at the address its answer came from, which is on the LAN of the worker.
+func Discover(network string, timeout time.Duration) (addrs []string, err error) {
+	conn, err := net.ListenUDP("udp4", nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	name, err := dnsmessage.NewName(mdnsService)
+	if err != nil {
+		return
+	}
+	b := dnsmessage.NewBuilder(nil, dnsmessage.Header{})
+	if err = b.StartQuestions(); err != nil {
+		return
+	}
+	if err = b.Question(dnsmessage.Question{Name: name, Type: dnsmessage.TypePTR, Class: dnsmessage.ClassINET}); err != nil {
+		return
+	}
+	query, err := b.Finish()
+	if err != nil {
+		return
+	}
+	if _, err = conn.WriteToUDP(query, mdnsGroup); err != nil {
+		return
+	}
+	conn.SetReadDeadline(time.Now().Add(timeout))
+	seen := make(map[string]struct{})
+	buf := make([]byte, 9000)
+	for {
+		n, from, e := conn.ReadFromUDP(buf)
+		if e != nil {
+			// The deadline ends the discovery.
+			return addrs, nil
+		}
+		for _, addr := range parseAnswer(buf[:n], from.IP, network) {
+			if _, ok := seen[addr]; !ok {
+				seen[addr] = struct{}{}
+				addrs = append(addrs, addr)
+			}
+		}
+	}
+}
+// parseAnswer returns the addresses of the listeners for the chain with the passed network name in an answer for the miner service from the passed host.
+func parseAnswer(msg []byte, host net.IP, network string) (addrs []string) {
+	var p dnsmessage.Parser
+	h, err := p.Start(msg)
+	if err != nil || !h.Response {
+		return
+	}
+	if err = p.SkipAllQuestions(); err != nil {
+		return
+	}
+	var records []dnsmessage.Resource
+	for _, section := range []func() ([]dnsmessage.Resource, error){p.AllAnswers, p.AllAuthorities, p.AllAdditionals} {
+		rs, err := section()
+		if err != nil {
+			return
+		}
+		records = append(records, rs...)
+	}
+	ports := make(map[string]uint16)
+	texts := make(map[string][]string)
+	for _, r := range records {
+		name := strings.ToLower(r.Header.Name.String())
+		if !strings.HasSuffix(name, mdnsService) {
+			continue
+		}
+		switch body := r.Body.(type) {
+		case *dnsmessage.SRVResource:
+			ports[name] = body.Port
+		case *dnsmessage.TXTResource:
+			texts[name] = body.TXT
+		}
+	}
+	for name, port := range ports {
+		transport, chain := TransportTCP, ""
+		for _, kv := range texts[name] {
+			switch {
+			case strings.HasPrefix(kv, "transport="):
+				transport = kv[len("transport="):]
+			case strings.HasPrefix(kv, "net="):
+				chain = kv[len("net="):]
+			}
+		}
+		if chain != network {
+			continue
+		}
+		scheme := ""
+		switch transport {
+		case TransportQUIC:
+			scheme = quicScheme
+		case TransportFEC:
+			scheme = fecScheme
+		}
+		addrs = append(addrs, scheme+joinHostPort(host, port))
+	}
+	return
+}
+// joinHostPort returns the address of the port on the host.
+func joinHostPort(host net.IP, port uint16) string {
+	return net.JoinHostPort(host.String(), strconv.Itoa(int(port)))
+}
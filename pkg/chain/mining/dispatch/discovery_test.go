@@ -0,0 +1,48 @@
+package controller
+import (
+	"net"
+	"sort"
+	"testing"
+	"golang.org/x/net/dns/dnsmessage"
+)
+// TestDiscoveryAnswer ensures the answer of an advertiser gives back the listeners it advertises with the scheme of their transport at the address it came from, and only for the network they mine.
+func TestDiscoveryAnswer(t *testing.T) {
+	a := &Advertiser{network: "mainnet"}
+	var err error
+	if a.host, err = dnsmessage.NewName("host.local."); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []struct {
+		transport string
+		port      uint16
+	}{{TransportTCP, 11045}, {TransportQUIC, 11046}, {TransportFEC, 11047}} {
+		instance, err := dnsmessage.NewName("host-" + s.transport + "." + mdnsService)
+		if err != nil {
+			t.Fatal(err)
+		}
+		a.services = append(a.services, advertised{instance: instance, transport: s.transport, port: s.port})
+	}
+	name, err := dnsmessage.NewName(mdnsService)
+	if err != nil {
+		t.Fatal(err)
+	}
+	answer, err := a.answer(1, dnsmessage.Question{Name: name, Type: dnsmessage.TypePTR, Class: dnsmessage.ClassINET})
+	if err != nil {
+		t.Fatal(err)
+	}
+	host := net.IPv4(192, 168, 1, 2)
+	got := parseAnswer(answer, host, "mainnet")
+	sort.Strings(got)
+	want := []string{"192.168.1.2:11045", "fec://192.168.1.2:11047", "quic://192.168.1.2:11046"}
+	if len(got) != len(want) {
+		t.Fatalf("discovered %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("discovered %v, want %v", got, want)
+		}
+	}
+	if got := parseAnswer(answer, host, "testnet"); len(got) != 0 {
+		t.Fatalf("discovered %v for another network", got)
+	}
+}
@@ -0,0 +1,54 @@
+package controller
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+)
+// HHMAC is a hash chain HMAC. Each message is authenticated with HMAC-SHA256 under a key that is replaced by its own SHA256 hash once used, so both ends of a session walk the same chain of keys and a key learned from one message can not authenticate any later one. Each direction of a session uses its own chain.
+type HHMAC struct {
+	key     [sha256.Size]byte
+	counter uint64
+}
+// NewHHMAC returns a hash chain seeded from the shared secret and the passed labels, which are the session nonces and direction of the chain.
+func NewHHMAC(secret []byte, labels ...[]byte) *HHMAC {
+	h := sha256.New()
+	h.Write(secret)
+	for _, l := range labels {
+		h.Write(l)
+	}
+	c := &HHMAC{}
+	copy(c.key[:], h.Sum(nil))
+	return c
+}
+// Counter returns the number of messages authenticated by the chain so far.
+func (c *HHMAC) Counter() uint64 {
+	return c.counter
+}
+// Sign returns the authentication code of the passed message and advances the chain.
+func (c *HHMAC) Sign(msg []byte) []byte {
+	mac := c.sum(msg)
+	c.next()
+	return mac
+}
+// Verify returns whether the passed code authenticates the message with the current key of the chain. The chain is only advanced when it does, so a forged message does not desynchronise the session.
+func (c *HHMAC) Verify(msg, mac []byte) bool {
+	if !hmac.Equal(c.sum(msg), mac) {
+		return false
+	}
+	c.next()
+	return true
+}
+// sum computes the HMAC of the message count and the message under the current key.
+func (c *HHMAC) sum(msg []byte) []byte {
+	var count [8]byte
+	binary.BigEndian.PutUint64(count[:], c.counter)
+	m := hmac.New(sha256.New, c.key[:])
+	m.Write(count[:])
+	m.Write(msg)
+	return m.Sum(nil)
+}
+// next replaces the key with its hash.
+func (c *HHMAC) next() {
+	c.key = sha256.Sum256(c.key[:])
+	c.counter++
+}
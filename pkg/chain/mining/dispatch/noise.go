@@ -0,0 +1,267 @@
+package controller
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/curve25519"
+)
+// The session handshake is Noise_XXpsk3_25519_ChaChaPoly_SHA256. The worker is the initiator: hello carries its ephemeral key, welcome the ephemeral and encrypted static key of the controller, and subscribe the encrypted static key of the worker, after which the key of the worker, the miner key or its API key, is mixed in as the preshared key. Both sides prove their static keys and the preshared key before any work is accepted, and the handshake yields fresh keys for both directions of the session.
+const (
+	// noiseProtocol is the Noise protocol name, which initialises the handshake hash.
+	noiseProtocol = "Noise_XXpsk3_25519_ChaChaPoly_SHA256"
+	// noisePrologue binds the handshake to the miner protocol.
+	noisePrologue = "parallelcoin miner dispatch 1"
+	// keySize is the size of Curve25519 keys and of the symmetric keys of the handshake.
+	keySize = 32
+	// tagSize is the size of the authentication tag of an encrypted handshake field.
+	tagSize = 16
+)
+var (
+	// identityLabel separates identities derived from a seed from the other keys derived from it.
+	identityLabel = []byte("identity")
+	// errHandshake is returned when a handshake message is malformed or fails to decrypt.
+	errHandshake = errors.New("handshake failed")
+)
+// Identity is the static Curve25519 key pair a controller or worker proves it holds in the handshake.
+type Identity struct {
+	private [keySize]byte
+	public  [keySize]byte
+}
+// NewIdentity returns a random identity.
+func NewIdentity() (*Identity, error) {
+	seed := make([]byte, keySize)
+	if _, err := rand.Read(seed); err != nil {
+		return nil, err
+	}
+	return IdentityFromSeed(seed), nil
+}
+// IdentityFromSeed returns the identity derived from the passed secret, so the same secret always gives the same identity.
+func IdentityFromSeed(seed []byte) *Identity {
+	id := &Identity{}
+	h := sha256.New()
+	h.Write(seed)
+	h.Write(identityLabel)
+	copy(id.private[:], h.Sum(nil))
+	curve25519.ScalarBaseMult(&id.public, &id.private)
+	return id
+}
+// PublicKey returns the public key of the identity.
+func (id *Identity) PublicKey() []byte {
+	return append([]byte{}, id.public[:]...)
+}
+// dh returns the shared secret of the identity and the passed public key, failing for keys of low order that give no secret.
+func (id *Identity) dh(public []byte) ([]byte, error) {
+	var pub, shared [keySize]byte
+	copy(pub[:], public)
+	curve25519.ScalarMult(&shared, &id.private, &pub)
+	if subtle.ConstantTimeCompare(shared[:], make([]byte, keySize)) == 1 {
+		return nil, errHandshake
+	}
+	return shared[:], nil
+}
+// handshake is the state of one side of a session handshake.
+type handshake struct {
+	ck  [sha256.Size]byte
+	h   [sha256.Size]byte
+	k   []byte
+	n   uint64
+	s   *Identity
+	e   *Identity
+	rs  []byte
+	re  []byte
+	psk []byte
+}
+// newHandshake starts a handshake with the passed static identity and preshared key.
+func newHandshake(s *Identity, psk []byte) *handshake {
+	hs := &handshake{s: s, psk: psk}
+	// The protocol name is longer than a hash, so it is hashed.
+	hs.h = sha256.Sum256([]byte(noiseProtocol))
+	hs.ck = hs.h
+	hs.mixHash([]byte(noisePrologue))
+	return hs
+}
+// hkdf derives the passed number of keys from the chaining key and the input key material.
+func (hs *handshake) hkdf(ikm []byte, outputs int) (out [][]byte) {
+	m := hmac.New(sha256.New, hs.ck[:])
+	m.Write(ikm)
+	temp := m.Sum(nil)
+	var prev []byte
+	for i := 1; i <= outputs; i++ {
+		m = hmac.New(sha256.New, temp)
+		m.Write(prev)
+		m.Write([]byte{byte(i)})
+		prev = m.Sum(nil)
+		out = append(out, prev)
+	}
+	return
+}
+// mixHash adds data to the handshake hash.
+func (hs *handshake) mixHash(data []byte) {
+	h := sha256.New()
+	h.Write(hs.h[:])
+	h.Write(data)
+	copy(hs.h[:], h.Sum(nil))
+}
+// mixKey adds key material to the chaining key and replaces the key encrypting handshake fields.
+func (hs *handshake) mixKey(ikm []byte) {
+	out := hs.hkdf(ikm, 2)
+	copy(hs.ck[:], out[0])
+	hs.k, hs.n = out[1], 0
+}
+// mixDH mixes the shared secret of a local and a remote key into the chaining key.
+func (hs *handshake) mixDH(local *Identity, remote []byte) error {
+	shared, err := local.dh(remote)
+	if err != nil {
+		return err
+	}
+	hs.mixKey(shared)
+	return nil
+}
+// mixPSK mixes the preshared key into the chaining key, the handshake hash and the key encrypting handshake fields.
+func (hs *handshake) mixPSK() {
+	out := hs.hkdf(hs.psk, 3)
+	copy(hs.ck[:], out[0])
+	hs.mixHash(out[1])
+	hs.k, hs.n = out[2], 0
+}
+// nonce returns the nonce of the next handshake field.
+func (hs *handshake) nonce() []byte {
+	nonce := make([]byte, chacha20poly1305.NonceSize)
+	binary.LittleEndian.PutUint64(nonce[4:], hs.n)
+	return nonce
+}
+// encryptAndHash encrypts a handshake field once there is a key and adds it to the handshake hash.
+func (hs *handshake) encryptAndHash(plain []byte) []byte {
+	out := plain
+	if hs.k != nil {
+		aead, _ := chacha20poly1305.New(hs.k)
+		out = aead.Seal(nil, hs.nonce(), plain, hs.h[:])
+		hs.n++
+	}
+	hs.mixHash(out)
+	return out
+}
+// decryptAndHash decrypts a handshake field once there is a key and adds it to the handshake hash.
+func (hs *handshake) decryptAndHash(sealed []byte) ([]byte, error) {
+	plain := sealed
+	if hs.k != nil {
+		aead, _ := chacha20poly1305.New(hs.k)
+		var err error
+		if plain, err = aead.Open(nil, hs.nonce(), sealed, hs.h[:]); err != nil {
+			return nil, errHandshake
+		}
+		hs.n++
+	}
+	hs.mixHash(sealed)
+	return plain, nil
+}
+// writeE generates the ephemeral key and returns its public key for the message.
+func (hs *handshake) writeE() ([]byte, error) {
+	var err error
+	if hs.e, err = NewIdentity(); err != nil {
+		return nil, err
+	}
+	hs.mixHash(hs.e.public[:])
+	// With a preshared key the ephemeral key is also mixed into the chaining key.
+	hs.mixKey(hs.e.public[:])
+	return hs.e.PublicKey(), nil
+}
+// readE reads the ephemeral key of the other side from the start of the message and returns the rest.
+func (hs *handshake) readE(msg []byte) ([]byte, error) {
+	if len(msg) < keySize {
+		return nil, errHandshake
+	}
+	hs.re = append([]byte{}, msg[:keySize]...)
+	hs.mixHash(hs.re)
+	hs.mixKey(hs.re)
+	return msg[keySize:], nil
+}
+// readS decrypts the static key of the other side from the start of the message and returns the rest.
+func (hs *handshake) readS(msg []byte) ([]byte, error) {
+	if len(msg) < keySize+tagSize {
+		return nil, errHandshake
+	}
+	var err error
+	if hs.rs, err = hs.decryptAndHash(msg[:keySize+tagSize]); err != nil {
+		return nil, err
+	}
+	return msg[keySize+tagSize:], nil
+}
+// split returns the keys of the session from the worker and from the controller once the handshake is done.
+func (hs *handshake) split() (worker, controller []byte) {
+	out := hs.hkdf(nil, 2)
+	return out[0], out[1]
+}
+// writeHello returns the first message of the worker, carrying its ephemeral key and the payload, which anyone can read as no secret is mixed in yet.
+func (hs *handshake) writeHello(payload []byte) ([]byte, error) {
+	msg, err := hs.writeE()
+	if err != nil {
+		return nil, err
+	}
+	return append(msg, hs.encryptAndHash(payload)...), nil
+}
+// readHello reads the first message of the worker and returns its payload. The preshared key is not needed until subscribe, so the payload can name the key.
+func (hs *handshake) readHello(msg []byte) ([]byte, error) {
+	msg, err := hs.readE(msg)
+	if err != nil {
+		return nil, err
+	}
+	return hs.decryptAndHash(msg)
+}
+// writeWelcome returns the reply of the controller, carrying its ephemeral key and its static key and the payload encrypted.
+func (hs *handshake) writeWelcome(payload []byte) ([]byte, error) {
+	msg, err := hs.writeE()
+	if err != nil {
+		return nil, err
+	}
+	if err = hs.mixDH(hs.e, hs.re); err != nil {
+		return nil, err
+	}
+	msg = append(msg, hs.encryptAndHash(hs.s.public[:])...)
+	if err = hs.mixDH(hs.s, hs.re); err != nil {
+		return nil, err
+	}
+	return append(msg, hs.encryptAndHash(payload)...), nil
+}
+// readWelcome reads the reply of the controller and returns its payload. The static key of the controller is then in rs.
+func (hs *handshake) readWelcome(msg []byte) ([]byte, error) {
+	msg, err := hs.readE(msg)
+	if err != nil {
+		return nil, err
+	}
+	if err = hs.mixDH(hs.e, hs.re); err != nil {
+		return nil, err
+	}
+	if msg, err = hs.readS(msg); err != nil {
+		return nil, err
+	}
+	if err = hs.mixDH(hs.e, hs.rs); err != nil {
+		return nil, err
+	}
+	return hs.decryptAndHash(msg)
+}
+// writeSubscribe returns the last message of the worker, carrying its static key and the payload encrypted under keys that need the preshared key.
+func (hs *handshake) writeSubscribe(payload []byte) ([]byte, error) {
+	msg := hs.encryptAndHash(hs.s.public[:])
+	if err := hs.mixDH(hs.s, hs.re); err != nil {
+		return nil, err
+	}
+	hs.mixPSK()
+	return append(msg, hs.encryptAndHash(payload)...), nil
+}
+// readSubscribe reads the last message of the worker and returns its payload, failing if the worker does not hold the preshared key. The static key of the worker is then in rs.
+func (hs *handshake) readSubscribe(msg []byte) ([]byte, error) {
+	msg, err := hs.readS(msg)
+	if err != nil {
+		return nil, err
+	}
+	if err = hs.mixDH(hs.e, hs.rs); err != nil {
+		return nil, err
+	}
+	hs.mixPSK()
+	return hs.decryptAndHash(msg)
+}
@@ -0,0 +1,163 @@
+package controller
+import (
+	"net"
+	"sync"
+	"time"
+	"git.parallelcoin.io/dev/9/pkg/rpc/sub"
+	"git.parallelcoin.io/dev/9/pkg/util/cl"
+)
+// Peer is a worker or controller at the other end of a session.
+type Peer struct {
+	// Addr is the address of the peer, Transport the transport of the session, one of TransportTCP, TransportQUIC and TransportFEC, and Identity the public key the peer proved in the handshake.
+	Addr      string
+	Transport string
+	Identity  []byte
+	// Algo is the algorithm a worker mines, which is empty for controllers.
+	Algo string
+	// Joined is when the session started and Seen when a message was last received from the peer.
+	Joined time.Time
+	Seen   time.Time
+	// RTT is the round trip time of the last ping the peer answered, which is zero until it answers one.
+	RTT time.Duration
+	// Lost is the number of messages from the peer missing from its HHMAC chain, which never arrived or were rejected, Rejected the number that failed authentication and Replayed the number dropped because their sequence number was already received, which a peer does not send unless the session is under attack.
+	Lost     uint64
+	Rejected uint64
+	Replayed uint64
+	// On sessions over FEC, Reconstructed is the number of groups from the peer rebuilt from parity shards, Corrupt the number of its shards failing their checksum and LossRate the moving average of the share of groups sent to it that had to be sent again.
+	Reconstructed uint64
+	Corrupt       uint64
+	LossRate      float64
+}
+// Peers tracks the peers of the sessions of a controller or worker. It sends each an authenticated ping every heartbeat, and a peer that nothing is received from for its timeout is dropped and its connection closed. OnJoin and OnLeave are called as peers are added and dropped, so the work of a peer that is gone can be given to others without waiting for its connection to fail.
+type Peers struct {
+	sync.Mutex
+	heartbeat time.Duration
+	timeout   time.Duration
+	peers     map[net.Conn]*peerEntry
+	onJoin    func(Peer)
+	onLeave   func(Peer)
+}
+// peerEntry is a peer with the connection of its session, the channel its messages are received on and the function sending it a ping, and when the last ping that was not answered was sent.
+type peerEntry struct {
+	Peer
+	conn   net.Conn
+	recv   *channel
+	ping   func() error
+	pinged time.Time
+}
+// current returns the peer with the counts of its channel and connection.
+func (e *peerEntry) current() Peer {
+	peer := e.Peer
+	if e.recv != nil {
+		peer.Lost, peer.Rejected, peer.Replayed = e.recv.Lost(), e.recv.Rejected(), e.recv.Replayed()
+	}
+	if s, ok := e.conn.(*sub.Session); ok {
+		r := s.Received()
+		peer.Reconstructed, peer.Corrupt = r.Reconstructed, r.Corrupt
+		peer.LossRate = s.Link().FailureRate
+	}
+	return peer
+}
+// NewPeers returns a manager sending pings every heartbeat and dropping the peers not heard from for three heartbeats, calling onJoin and onLeave, which may be nil, as peers are added and dropped.
+func NewPeers(heartbeat time.Duration, onJoin, onLeave func(Peer)) *Peers {
+	return &Peers{
+		heartbeat: heartbeat,
+		timeout:   heartbeat * 3,
+		peers:     make(map[net.Conn]*peerEntry),
+		onJoin:    onJoin,
+		onLeave:   onLeave,
+	}
+}
+// join adds the peer of the session on the passed connection, whose messages are received on recv and which is pinged by calling ping.
+func (p *Peers) join(conn net.Conn, peer Peer, recv *channel, ping func() error) {
+	now := time.Now()
+	peer.Joined, peer.Seen = now, now
+	p.Lock()
+	p.peers[conn] = &peerEntry{Peer: peer, conn: conn, recv: recv, ping: ping}
+	p.Unlock()
+	if p.onJoin != nil {
+		p.onJoin(peer)
+	}
+}
+// seen records that a message was received from the peer on the passed connection.
+func (p *Peers) seen(conn net.Conn) {
+	p.Lock()
+	defer p.Unlock()
+	if e, ok := p.peers[conn]; ok {
+		e.Seen = time.Now()
+	}
+}
+// pong records that the peer on the passed connection answered the last ping it was sent.
+func (p *Peers) pong(conn net.Conn) {
+	p.Lock()
+	defer p.Unlock()
+	if e, ok := p.peers[conn]; ok && !e.pinged.IsZero() {
+		e.RTT = time.Since(e.pinged)
+		e.pinged = time.Time{}
+	}
+}
+// leave drops the peer on the passed connection, if it was not already dropped.
+func (p *Peers) leave(conn net.Conn) {
+	p.Lock()
+	e, ok := p.peers[conn]
+	delete(p.peers, conn)
+	p.Unlock()
+	if ok && p.onLeave != nil {
+		p.onLeave(e.current())
+	}
+}
+// List returns the peers of the sessions that are running.
+func (p *Peers) List() (peers []Peer) {
+	p.Lock()
+	defer p.Unlock()
+	for _, e := range p.peers {
+		peers = append(peers, e.current())
+	}
+	return
+}
+// Len returns the number of peers.
+func (p *Peers) Len() int {
+	p.Lock()
+	defer p.Unlock()
+	return len(p.peers)
+}
+// tick drops and disconnects the peers not heard from since the timeout before now, and pings the rest.
+func (p *Peers) tick(now time.Time) {
+	var dead, alive []*peerEntry
+	p.Lock()
+	for conn, e := range p.peers {
+		if now.Sub(e.Seen) >= p.timeout {
+			delete(p.peers, conn)
+			dead = append(dead, e)
+		} else {
+			e.pinged = now
+			alive = append(alive, e)
+		}
+	}
+	p.Unlock()
+	for _, e := range dead {
+		log <- cl.Debug{"miner peer", e.Addr, "not heard from for", p.timeout, "- disconnecting"}
+		e.conn.Close()
+		if p.onLeave != nil {
+			p.onLeave(e.current())
+		}
+	}
+	for _, e := range alive {
+		if err := e.ping(); err != nil {
+			log <- cl.Debug{"miner peer", e.Addr, err}
+		}
+	}
+}
+// run pings the peers and drops the dead ones every heartbeat until quit is closed. It must be run as a goroutine.
+func (p *Peers) run(quit chan struct{}) {
+	ticker := time.NewTicker(p.heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			p.tick(now)
+		case <-quit:
+			return
+		}
+	}
+}
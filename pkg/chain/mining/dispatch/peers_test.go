@@ -0,0 +1,89 @@
+package controller
+import (
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+// TestPeers ensures peers are announced as they join and leave, the live ones are pinged each heartbeat and the ones not heard from for three heartbeats are dropped and disconnected.
+func TestPeers(t *testing.T) {
+	var joined, left []string
+	p := NewPeers(time.Second, func(peer Peer) {
+		joined = append(joined, peer.Addr)
+	}, func(peer Peer) {
+		left = append(left, peer.Addr)
+	})
+	live, liveEnd := net.Pipe()
+	defer liveEnd.Close()
+	dead, deadEnd := net.Pipe()
+	defer deadEnd.Close()
+	pings := 0
+	p.join(live, Peer{Addr: "live"}, nil, func() error {
+		pings++
+		return nil
+	})
+	p.join(dead, Peer{Addr: "dead"}, nil, func() error {
+		t.Fatal("a dropped peer was pinged")
+		return nil
+	})
+	if len(joined) != 2 || p.Len() != 2 {
+		t.Fatalf("%d peers joined and %d are known, want 2", len(joined), p.Len())
+	}
+	// Mark the live peer as heard from three heartbeats on, when the other has timed out.
+	later := time.Now().Add(time.Second * 3)
+	p.Lock()
+	p.peers[live].Seen = later
+	p.Unlock()
+	p.tick(later)
+	if pings != 1 {
+		t.Fatalf("live peer pinged %d times, want 1", pings)
+	}
+	if len(left) != 1 || left[0] != "dead" {
+		t.Fatalf("peers that left %v, want the dead one", left)
+	}
+	if _, err := dead.Write([]byte{0}); err == nil {
+		t.Fatal("connection of the dropped peer was not closed")
+	}
+	p.leave(dead)
+	p.leave(live)
+	if len(left) != 2 || p.Len() != 0 {
+		t.Fatalf("peers that left %v and %d are still known, want both gone", left, p.Len())
+	}
+	if peers := p.List(); len(peers) != 0 {
+		t.Fatalf("List returned %d peers after all left", len(peers))
+	}
+}
+// TestPeerMetrics ensures the peers listed carry the round trip time of their last answered ping and the counts of the channel they are received on.
+func TestPeerMetrics(t *testing.T) {
+	p := NewPeers(time.Second, nil, nil)
+	conn, end := net.Pipe()
+	defer conn.Close()
+	defer end.Close()
+	recv, err := newChannel(make([]byte, 32), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.join(conn, Peer{Addr: "peer"}, recv, func() error {
+		return nil
+	})
+	atomic.AddUint64(&recv.metrics.lost, 3)
+	atomic.AddUint64(&recv.metrics.rejected, 2)
+	atomic.AddUint64(&recv.metrics.replayed, 1)
+	p.pong(conn)
+	if peers := p.List(); peers[0].RTT != 0 {
+		t.Fatalf("RTT %v before a ping was sent, want 0", peers[0].RTT)
+	}
+	p.tick(time.Now().Add(-time.Millisecond * 10))
+	p.pong(conn)
+	peers := p.List()
+	if len(peers) != 1 {
+		t.Fatalf("%d peers listed, want 1", len(peers))
+	}
+	if peers[0].RTT < time.Millisecond*10 {
+		t.Fatalf("RTT %v, want at least 10ms", peers[0].RTT)
+	}
+	if peers[0].Lost != 3 || peers[0].Rejected != 2 || peers[0].Replayed != 1 {
+		t.Fatalf("lost %d, rejected %d and replayed %d, want 3, 2 and 1",
+			peers[0].Lost, peers[0].Rejected, peers[0].Replayed)
+	}
+}
@@ -0,0 +1,353 @@
+package controller
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync/atomic"
+	"time"
+	"git.parallelcoin.io/dev/9/pkg/chain/wire"
+	"git.parallelcoin.io/dev/9/pkg/util/cl"
+)
+// The messages of the miner worker protocol. A session starts with the handshake of hello, welcome and subscribe, after which every message in both directions is authenticated by the HHMAC chain of its channel. When either side sets flagEncrypt the payloads after subscribe are also encrypted by the Cipher of their channel. Every message carries the sequence number of its channel, which is bound into its authentication code, so a replayed or duplicated message is recognised by its number being behind the channel and dropped, and when messages are lost the receiver skips ahead to the number of the next. When more are lost than it skips on its own, msgResync brings it back in step without a new handshake.
+const (
+	// msgHello starts the handshake of the worker, with its flags and the length and name of the API key it authenticates with, which is empty when it uses the miner password. The handshake messages are not framed with an authentication code, as the handshake authenticates them.
+	msgHello byte = iota + 1
+	// msgWelcome is the handshake reply of the controller, with its flags.
+	msgWelcome
+	// msgSubscribe completes the handshake of the worker, with the name of the algorithm it wants work for.
+	msgSubscribe
+	// msgJob carries a block header for the worker to solve.
+	msgJob
+	// msgSolution carries the nonce and timestamp that solve a job.
+	msgSolution
+	// msgResult tells the worker whether a solution was accepted.
+	msgResult
+	// msgPing is sent by either side every heartbeat to check the other is alive.
+	msgPing
+	// msgPong is the reply to msgPing.
+	msgPong
+	// msgMulticast tells a worker that asked for multicast work its slot and the key that authenticates announcements. It is sent before any job.
+	msgMulticast
+	// msgResync advertises the count of messages the sender has sent on its channel in its sequence number, so a receiver whose chain fell behind by more than resyncWindow can skip ahead to it. Its payload is flags, which are not encrypted so they can be read before the receiver is in step. With resyncRequest set it asks the other side to advertise its count in reply.
+	msgResync
+)
+const (
+	// flagEncrypt in the flags of hello or welcome asks for the payloads of the session to be encrypted.
+	flagEncrypt byte = 1 << iota
+	// flagMulticast in the flags of hello asks for jobs to be announced by multicast, and in welcome tells the worker they will be.
+	flagMulticast
+)
+const (
+	// resyncRequest in the flags of msgResync asks for a msgResync in reply.
+	resyncRequest byte = 1 << iota
+)
+const (
+	// HeartbeatInterval is the time between pings sent by both sides of a session. A session where nothing is received for three intervals is considered dead.
+	HeartbeatInterval = time.Second * 5
+	// rekeyEvery is the number of messages sent on a channel before its keys are replaced.
+	rekeyEvery = 1 << 16
+	// macSize is the size of the authentication code of a message.
+	macSize = sha256.Size
+	// maxFrameSize is the largest message either side accepts.
+	maxFrameSize = 1 << 12
+	// seqSize is the size of the sequence number that follows the type of each message.
+	seqSize = 8
+	// jobSize is the size of a serialized job.
+	jobSize = 8 + wire.MaxBlockHeaderPayload
+	// solutionSize is the size of a serialized solution.
+	solutionSize = 12
+	// multicastLanes is the number of parts the nonces of an announced job are split into, one for each slot. Workers with slots beyond the lanes also start their timestamp a second later for each time round.
+	multicastLanes = 256
+	// multicastSize is the size of the payload of msgMulticast, the slot and the announcement key.
+	multicastSize = 4 + sha256.Size
+	// resyncSize is the size of the payload of msgResync, the flags.
+	resyncSize = 1
+	// resyncWindow is the furthest ahead of its own count a channel skips to the sequence number of a message, and resyncLimit the furthest it skips to the count advertised by a msgResync, which bound how many lost messages a session recovers from and how much work a forged sequence number costs.
+	resyncWindow = 256
+	resyncLimit  = rekeyEvery
+	// resyncAttempts is the number of messages in a row that may fail authentication while a channel waits to be brought back in step, after which the session is ended.
+	resyncAttempts = 16
+)
+var (
+	// errAuth is returned when a message does not carry a valid authentication code.
+	errAuth = errors.New("message failed authentication")
+	// errResync is returned by a channel when a message fails authentication or its sequence number is further ahead than the channel skips. The message is dropped, and the reader should send a msgResync with resyncRequest so the other side advertises its count.
+	errResync = errors.New("message failed authentication, channel out of step")
+	// macLabel and rekeyLabel separate the HHMAC chain of a channel and its next key from its cipher, which are all derived from the key of the channel.
+	macLabel   = []byte("hhmac")
+	rekeyLabel = []byte("rekey")
+)
+// job is the work sent to a worker: a block header at a height, identified by the controller with an id.
+type job struct {
+	ID     uint32
+	Height int32
+	Header wire.BlockHeader
+}
+// solution is the nonce and timestamp a worker found to solve a job.
+type solution struct {
+	ID        uint32
+	Nonce     uint32
+	Timestamp uint32
+}
+// channel is one direction of a session. Its messages are authenticated by an HHMAC chain and, if the session is encrypted, encrypted by a Cipher, both derived from a key the handshake gives the channel. Every rekeyEvery messages the key is replaced by one derived from it and the chain and cipher start again from the new key, so no key is used for long and old keys can not be recovered from later ones.
+type channel struct {
+	key      []byte
+	encrypt  bool
+	mac      *HHMAC
+	cipher   *Cipher
+	count    uint64
+	failures int
+	metrics  *channelMetrics
+}
+// channelMetrics counts the messages a channel lost and rejected. It is shared by the copies of a channel and read atomically, so the counts can be read while the session runs.
+type channelMetrics struct {
+	// lost is the number of messages the chain skipped, which never arrived or were rejected, rejected the number that failed authentication and replayed the number dropped because their sequence number was behind the channel, which were replayed or duplicated.
+	lost     uint64
+	rejected uint64
+	replayed uint64
+}
+// newChannel returns a channel with the passed key from the handshake.
+func newChannel(key []byte, encrypt bool) (*channel, error) {
+	c := &channel{key: key, encrypt: encrypt, metrics: &channelMetrics{}}
+	return c, c.start()
+}
+// start derives the chain and cipher of the channel from its key.
+func (c *channel) start() (err error) {
+	c.mac = NewHHMAC(c.key, macLabel)
+	if c.encrypt {
+		c.cipher, err = NewCipher(c.key)
+	}
+	return
+}
+// advance counts a message and replaces the key of the channel when it is due.
+func (c *channel) advance() error {
+	c.count++
+	if c.count%rekeyEvery != 0 {
+		return nil
+	}
+	h := sha256.New()
+	h.Write(c.key)
+	h.Write(rekeyLabel)
+	c.key = h.Sum(nil)
+	return c.start()
+}
+// skip advances the channel past a message that was lost.
+func (c *channel) skip() error {
+	c.mac.next()
+	c.cipher.skip()
+	return c.advance()
+}
+// clone returns a copy of the channel that can be advanced without changing it.
+func (c *channel) clone() *channel {
+	t := *c
+	mac := *c.mac
+	t.mac = &mac
+	if c.cipher != nil {
+		cipher := *c.cipher
+		t.cipher = &cipher
+	}
+	return &t
+}
+// verify checks the authentication code of a message with the passed sequence number, which is not behind the channel, against the chain and advances it when it matches. When the number is ahead of the channel, up to resyncWindow or resyncLimit for a msgResync, the message is checked with the key of that number and the channel skips the messages before it that were lost.
+func (c *channel) verify(body []byte, seq uint64, mac []byte) bool {
+	ahead := seq - c.count
+	if ahead == 0 {
+		return c.mac.Verify(body, mac)
+	}
+	limit := uint64(resyncWindow)
+	if body[0] == msgResync {
+		limit = resyncLimit
+	}
+	if ahead > limit {
+		return false
+	}
+	t := c.clone()
+	for i := uint64(0); i < ahead; i++ {
+		if t.skip() != nil {
+			return false
+		}
+	}
+	if !t.mac.Verify(body, mac) {
+		return false
+	}
+	*c = *t
+	atomic.AddUint64(&c.metrics.lost, ahead)
+	return true
+}
+// Lost returns the number of messages the channel skipped because they never arrived or were rejected. It is safe for concurrent access.
+func (c *channel) Lost() uint64 {
+	return atomic.LoadUint64(&c.metrics.lost)
+}
+// Rejected returns the number of messages received on the channel that failed authentication. It is safe for concurrent access.
+func (c *channel) Rejected() uint64 {
+	return atomic.LoadUint64(&c.metrics.rejected)
+}
+// Replayed returns the number of messages received on the channel that were dropped as replayed or duplicated. It is safe for concurrent access.
+func (c *channel) Replayed() uint64 {
+	return atomic.LoadUint64(&c.metrics.replayed)
+}
+// Count returns the number of messages sent or received on the channel.
+func (c *channel) Count() uint64 {
+	return c.count
+}
+// write sends a message on the channel with the count of the channel as its sequence number. The payload of msgResync is its flags, which are sent in the clear.
+func (c *channel) write(conn net.Conn, typ byte, payload []byte) error {
+	if typ == msgResync {
+		c.cipher.skip()
+	} else {
+		payload = c.cipher.Seal(typ, payload)
+	}
+	if err := writeMsg(conn, c.mac, c.count, typ, payload); err != nil {
+		return err
+	}
+	return c.advance()
+}
+// read receives a message from the channel. Messages with a sequence number behind the channel are dropped and counted as replayed. The first message that fails authentication after one that passed returns errResync, and the ones after it are dropped until one passes, or the session is ended with errAuth after resyncAttempts of them.
+func (c *channel) read(conn net.Conn) (typ byte, payload []byte, err error) {
+	for {
+		var body, mac []byte
+		if body, mac, err = readFrame(conn); err != nil {
+			return
+		}
+		seq := binary.BigEndian.Uint64(body[1:])
+		if seq < c.count {
+			atomic.AddUint64(&c.metrics.replayed, 1)
+			log <- cl.Debug{"dropped replayed message", seq, "at", c.count}
+			continue
+		}
+		if c.verify(body, seq, mac) {
+			c.failures = 0
+			typ, payload = body[0], body[1+seqSize:]
+			break
+		}
+		c.failures++
+		atomic.AddUint64(&c.metrics.rejected, 1)
+		switch {
+		case c.failures > resyncAttempts:
+			return 0, nil, errAuth
+		case c.failures == 1:
+			return 0, nil, errResync
+		}
+	}
+	if typ == msgResync {
+		if len(payload) != resyncSize {
+			return 0, nil, fmt.Errorf("invalid resync size %d", len(payload))
+		}
+		c.cipher.skip()
+	} else if payload, err = c.cipher.Open(typ, payload); err != nil {
+		return
+	}
+	err = c.advance()
+	return
+}
+// writeMsg frames a message as its length, type, sequence number, payload and the authentication code of the type, sequence number and payload under the passed chain, or zeroes when the chain is nil.
+func writeMsg(conn net.Conn, c *HHMAC, seq uint64, typ byte, payload []byte) error {
+	body := make([]byte, 1+seqSize, 1+seqSize+len(payload))
+	body[0] = typ
+	binary.BigEndian.PutUint64(body[1:], seq)
+	body = append(body, payload...)
+	mac := make([]byte, macSize)
+	if c != nil {
+		mac = c.Sign(body)
+	}
+	frame := make([]byte, 4, 4+len(body)+macSize)
+	binary.BigEndian.PutUint32(frame, uint32(len(body)+macSize))
+	frame = append(append(frame, body...), mac...)
+	conn.SetWriteDeadline(time.Now().Add(HeartbeatInterval))
+	_, err := conn.Write(frame)
+	return err
+}
+// readFrame reads a message, returning its type, sequence number and payload and its authentication code separately. The read fails if nothing arrives for three heartbeat intervals.
+func readFrame(conn net.Conn) (body, mac []byte, err error) {
+	conn.SetReadDeadline(time.Now().Add(HeartbeatInterval * 3))
+	var length [4]byte
+	if _, err = io.ReadFull(conn, length[:]); err != nil {
+		return
+	}
+	n := binary.BigEndian.Uint32(length[:])
+	if n < 1+seqSize+macSize || n > maxFrameSize {
+		err = fmt.Errorf("invalid message size %d", n)
+		return
+	}
+	frame := make([]byte, n)
+	if _, err = io.ReadFull(conn, frame); err != nil {
+		return
+	}
+	return frame[:n-macSize], frame[n-macSize:], nil
+}
+// readMsg reads a message and checks its authentication code against the passed chain, unless it is nil.
+func readMsg(conn net.Conn, c *HHMAC) (typ byte, payload []byte, err error) {
+	body, mac, err := readFrame(conn)
+	if err != nil {
+		return
+	}
+	if c != nil && !c.Verify(body, mac) {
+		err = errAuth
+		return
+	}
+	return body[0], body[1+seqSize:], nil
+}
+// announcement encodes a job announced to the workers mining the passed algorithm, authenticated with the announcement key.
+func announcement(key []byte, algo string, j *job) []byte {
+	msg := append(append([]byte{byte(len(algo))}, algo...), j.serialize()...)
+	m := hmac.New(sha256.New, key)
+	m.Write(msg)
+	return m.Sum(msg)
+}
+// parseAnnouncement decodes an announced job and the algorithm it is for, failing if it is not authenticated by the announcement key.
+func parseAnnouncement(key, msg []byte) (algo string, j job, err error) {
+	if len(msg) < 1+macSize || len(msg) < 1+int(msg[0])+macSize {
+		err = fmt.Errorf("invalid announcement size %d", len(msg))
+		return
+	}
+	body, mac := msg[:len(msg)-macSize], msg[len(msg)-macSize:]
+	m := hmac.New(sha256.New, key)
+	m.Write(body)
+	if !hmac.Equal(m.Sum(nil), mac) {
+		err = errAuth
+		return
+	}
+	algo = string(body[1 : 1+int(body[0])])
+	err = j.deserialize(body[1+int(body[0]):])
+	return
+}
+// serialize encodes the job.
+func (j *job) serialize() []byte {
+	var b bytes.Buffer
+	binary.Write(&b, binary.BigEndian, j.ID)
+	binary.Write(&b, binary.BigEndian, j.Height)
+	j.Header.Serialize(&b)
+	return b.Bytes()
+}
+// deserialize decodes a job.
+func (j *job) deserialize(payload []byte) error {
+	if len(payload) != jobSize {
+		return fmt.Errorf("invalid job size %d", len(payload))
+	}
+	j.ID = binary.BigEndian.Uint32(payload[0:4])
+	j.Height = int32(binary.BigEndian.Uint32(payload[4:8]))
+	return j.Header.Deserialize(bytes.NewReader(payload[8:]))
+}
+// serialize encodes the solution.
+func (s *solution) serialize() []byte {
+	b := make([]byte, solutionSize)
+	binary.BigEndian.PutUint32(b[0:4], s.ID)
+	binary.BigEndian.PutUint32(b[4:8], s.Nonce)
+	binary.BigEndian.PutUint32(b[8:12], s.Timestamp)
+	return b
+}
+// deserialize decodes a solution.
+func (s *solution) deserialize(payload []byte) error {
+	if len(payload) != solutionSize {
+		return fmt.Errorf("invalid solution size %d", len(payload))
+	}
+	s.ID = binary.BigEndian.Uint32(payload[0:4])
+	s.Nonce = binary.BigEndian.Uint32(payload[4:8])
+	s.Timestamp = binary.BigEndian.Uint32(payload[8:12])
+	return nil
+}
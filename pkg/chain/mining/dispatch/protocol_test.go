@@ -0,0 +1,351 @@
+package controller
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"testing"
+	"time"
+	"git.parallelcoin.io/dev/9/pkg/chain/wire"
+)
+// TestHHMAC ensures the chains of both ends of a session stay in step, a key is never reused and a forged message does not advance the chain.
+func TestHHMAC(t *testing.T) {
+	key := []byte("miner key")
+	signer, verifier := NewHHMAC(key, []byte("nonce")), NewHHMAC(key, []byte("nonce"))
+	first := signer.Sign([]byte("message"))
+	if !verifier.Verify([]byte("message"), first) {
+		t.Fatal("first message failed to verify")
+	}
+	second := signer.Sign([]byte("message"))
+	if string(first) == string(second) {
+		t.Fatal("the same message was authenticated with the same code twice")
+	}
+	if verifier.Verify([]byte("forged"), second) {
+		t.Fatal("forged message verified")
+	}
+	if !verifier.Verify([]byte("message"), second) {
+		t.Fatal("second message failed to verify after a forgery")
+	}
+	if signer.Counter() != 2 || verifier.Counter() != 2 {
+		t.Fatalf("counters %d and %d, want 2", signer.Counter(), verifier.Counter())
+	}
+	other := NewHHMAC([]byte("wrong key"), []byte("nonce"))
+	if NewHHMAC(key, []byte("nonce")).Verify([]byte("message"),
+		other.Sign([]byte("message"))) {
+		t.Fatal("message signed with the wrong key verified")
+	}
+}
+// TestCipher ensures payloads encrypted by one end of a session decrypt at the other, the same payload never encrypts the same way twice, and tampered messages fail without desynchronising the ciphers.
+func TestCipher(t *testing.T) {
+	sealer, err := NewCipher([]byte("key"), []byte("controller"))
+	if err != nil {
+		t.Fatalf("NewCipher: unexpected error: %v", err)
+	}
+	opener, _ := NewCipher([]byte("key"), []byte("controller"))
+	worker, _ := NewCipher([]byte("key"), []byte("worker"))
+	first := sealer.Seal(msgJob, []byte("work"))
+	if string(first) == "work" {
+		t.Fatal("payload was not encrypted")
+	}
+	if _, err := worker.Open(msgJob, first); err == nil {
+		t.Fatal("message opened with the cipher of the other direction")
+	}
+	if _, err := opener.Open(msgSolution, first); err == nil {
+		t.Fatal("message opened as another type")
+	}
+	tampered := append([]byte{}, first...)
+	tampered[0] ^= 1
+	if _, err := opener.Open(msgJob, tampered); err == nil {
+		t.Fatal("tampered message opened")
+	}
+	if got, err := opener.Open(msgJob, first); err != nil || string(got) != "work" {
+		t.Fatalf("first message opened as %q, %v", got, err)
+	}
+	second := sealer.Seal(msgJob, []byte("work"))
+	if string(first) == string(second) {
+		t.Fatal("the same payload was encrypted the same way twice")
+	}
+	if got, err := opener.Open(msgJob, second); err != nil || string(got) != "work" {
+		t.Fatalf("second message opened as %q, %v", got, err)
+	}
+	var none *Cipher
+	if string(none.Seal(msgJob, []byte("work"))) != "work" {
+		t.Fatal("a nil cipher changed the payload")
+	}
+}
+// testHandshake runs a handshake between a worker and a controller holding the passed preshared keys, returning the keys each derives for the session.
+func testHandshake(
+	worker, controller *Identity, workerKey, controllerKey []byte) (w, c *handshake, err error) {
+	w, c = newHandshake(worker, workerKey), newHandshake(controller, nil)
+	hello, err := w.writeHello([]byte("name"))
+	if err != nil {
+		return
+	}
+	name, err := c.readHello(hello)
+	if err != nil {
+		return
+	}
+	if string(name) != "name" {
+		return nil, nil, errors.New("hello payload changed")
+	}
+	c.psk = controllerKey
+	welcome, err := c.writeWelcome([]byte{flagEncrypt})
+	if err != nil {
+		return
+	}
+	if _, err = w.readWelcome(welcome); err != nil {
+		return
+	}
+	subscribe, err := w.writeSubscribe([]byte("sha256d"))
+	if err != nil {
+		return
+	}
+	_, err = c.readSubscribe(subscribe)
+	return
+}
+// TestHandshake ensures both sides of a handshake learn the identity of the other and derive the same session keys, that a worker without the preshared key is refused, and that channels replace their keys in step.
+func TestHandshake(t *testing.T) {
+	worker, controller := IdentityFromSeed([]byte("worker")), IdentityFromSeed([]byte("controller"))
+	w, c, err := testHandshake(worker, controller, []byte("key"), []byte("key"))
+	if err != nil {
+		t.Fatalf("handshake: unexpected error: %v", err)
+	}
+	if !bytes.Equal(w.rs, controller.public[:]) || !bytes.Equal(c.rs, worker.public[:]) {
+		t.Fatal("identities not learned in the handshake")
+	}
+	wSend, wRecv := w.split()
+	cRecv, cSend := c.split()
+	if !bytes.Equal(wSend, cRecv) || !bytes.Equal(wRecv, cSend) || bytes.Equal(wSend, wRecv) {
+		t.Fatal("session keys do not match or are the same in both directions")
+	}
+	// Another handshake with the same identities gives other keys.
+	w2, _, _ := testHandshake(worker, controller, []byte("key"), []byte("key"))
+	if again, _ := w2.split(); bytes.Equal(again, wSend) {
+		t.Fatal("session keys repeated in a new handshake")
+	}
+	if _, _, err := testHandshake(worker, controller, []byte("wrong"), []byte("key")); err == nil {
+		t.Fatal("handshake succeeded with the wrong preshared key")
+	}
+	send, _ := newChannel(wSend, true)
+	recv, _ := newChannel(cRecv, true)
+	for i := 0; i < rekeyEvery; i++ {
+		send.advance()
+		recv.advance()
+	}
+	if bytes.Equal(send.key, wSend) || !bytes.Equal(send.key, recv.key) {
+		t.Fatal("channels did not replace their keys in step")
+	}
+	if !send.mac.Verify([]byte("message"), recv.mac.Sign([]byte("message"))) {
+		t.Fatal("chains of the replaced keys do not match")
+	}
+}
+// TestMessages ensures jobs and solutions survive framing, authentication and serialization.
+func TestMessages(t *testing.T) {
+	a, b := net.Pipe()
+	defer a.Close()
+	defer b.Close()
+	send, _ := newChannel([]byte("key"), true)
+	recv, _ := newChannel([]byte("key"), true)
+	want := job{
+		ID:     7,
+		Height: 300000,
+		Header: wire.BlockHeader{
+			Version:   2,
+			Timestamp: time.Unix(1558000000, 0),
+			Bits:      0x1d00ffff,
+			Nonce:     42,
+		},
+	}
+	sol := solution{ID: 7, Nonce: 99, Timestamp: 1558000001}
+	errs := make(chan error, 1)
+	go func() {
+		if err := send.write(a, msgJob, want.serialize()); err != nil {
+			errs <- err
+			return
+		}
+		errs <- send.write(a, msgSolution, sol.serialize())
+	}()
+	typ, payload, err := recv.read(b)
+	if err != nil || typ != msgJob {
+		t.Fatalf("readMsg: type %d, %v", typ, err)
+	}
+	var got job
+	if err := got.deserialize(payload); err != nil {
+		t.Fatalf("deserialize job: %v", err)
+	}
+	if got.ID != want.ID || got.Height != want.Height ||
+		got.Header.BlockHash() != want.Header.BlockHash() {
+		t.Fatalf("job %+v, want %+v", got, want)
+	}
+	typ, payload, err = recv.read(b)
+	if err != nil || typ != msgSolution {
+		t.Fatalf("readMsg: type %d, %v", typ, err)
+	}
+	var gotSol solution
+	if err := gotSol.deserialize(payload); err != nil || gotSol != sol {
+		t.Fatalf("solution %+v, want %+v (%v)", gotSol, sol, err)
+	}
+	if err := <-errs; err != nil {
+		t.Fatalf("writeMsg: %v", err)
+	}
+}
+// TestResync ensures a channel recovers from lost messages by skipping ahead to the sequence number of the next, skips to the count advertised by msgResync when more are lost than it skips on its own, and ends the session when messages keep failing authentication.
+func TestResync(t *testing.T) {
+	a, b := net.Pipe()
+	defer a.Close()
+	defer b.Close()
+	lost, drain := net.Pipe()
+	defer lost.Close()
+	go io.Copy(ioutil.Discard, drain)
+	send, _ := newChannel([]byte("key"), true)
+	recv, _ := newChannel([]byte("key"), true)
+	forger, _ := newChannel([]byte("other key"), true)
+	errs := make(chan error, 1)
+	go func() {
+		errs <- func() error {
+			for i := 0; i < 5; i++ {
+				send.write(lost, msgPing, nil)
+			}
+			if err := send.write(a, msgPong, []byte("after loss")); err != nil {
+				return err
+			}
+			for i := 0; i < resyncWindow+1; i++ {
+				send.write(lost, msgPing, nil)
+			}
+			for i := 0; i < 2; i++ {
+				if err := send.write(a, msgPing, nil); err != nil {
+					return err
+				}
+			}
+			if err := send.write(a, msgResync, []byte{0}); err != nil {
+				return err
+			}
+			if err := send.write(a, msgPong, []byte("after resync")); err != nil {
+				return err
+			}
+			// The forger sends at the sequence numbers the receiver expects, so its messages are not dropped as replays.
+			forger.count = send.count
+			for i := 0; i <= resyncAttempts; i++ {
+				if err := forger.write(a, msgPing, nil); err != nil {
+					return err
+				}
+			}
+			return nil
+		}()
+	}()
+	typ, payload, err := recv.read(b)
+	if err != nil || typ != msgPong || string(payload) != "after loss" {
+		t.Fatalf("message after lost ones read as type %d %q, %v", typ, payload, err)
+	}
+	if _, _, err = recv.read(b); err != errResync {
+		t.Fatalf("message beyond the window: got %v, want errResync", err)
+	}
+	typ, payload, err = recv.read(b)
+	if err != nil || typ != msgResync || payload[0] != 0 {
+		t.Fatalf("resync read as type %d %x, %v", typ, payload, err)
+	}
+	if want := uint64(5 + 1 + resyncWindow + 1 + 2 + 1); recv.Count() != want {
+		t.Fatalf("receiving count %d after resync, want %d", recv.Count(), want)
+	}
+	if recv.Lost() != 5+resyncWindow+1+2 || recv.Rejected() != 2 {
+		t.Fatalf("%d messages lost and %d rejected, want %d and 2", recv.Lost(), recv.Rejected(), 5+resyncWindow+1+2)
+	}
+	typ, payload, err = recv.read(b)
+	if err != nil || typ != msgPong || string(payload) != "after resync" {
+		t.Fatalf("message after resync read as type %d %q, %v", typ, payload, err)
+	}
+	if _, _, err = recv.read(b); err != errResync {
+		t.Fatalf("forged message: got %v, want errResync", err)
+	}
+	if _, _, err = recv.read(b); err != errAuth {
+		t.Fatalf("forged messages: got %v, want errAuth", err)
+	}
+	if err := <-errs; err != nil {
+		t.Fatalf("write: %v", err)
+	}
+}
+// TestReplay ensures messages sent again or with the sequence number of one already received are dropped and counted without failing authentication or ending the session.
+func TestReplay(t *testing.T) {
+	a, b := net.Pipe()
+	defer a.Close()
+	defer b.Close()
+	send, _ := newChannel([]byte("key"), true)
+	recv, _ := newChannel([]byte("key"), true)
+	// Frames are captured so they can be sent again.
+	capture := func(typ byte, payload []byte) []byte {
+		c, d := net.Pipe()
+		defer c.Close()
+		defer d.Close()
+		frame := make(chan []byte)
+		go func() {
+			body, mac, _ := readFrame(d)
+			frame <- append(body, mac...)
+		}()
+		if err := send.write(c, typ, payload); err != nil {
+			t.Fatal(err)
+		}
+		return <-frame
+	}
+	first, second, last := capture(msgPong, []byte("first")), capture(msgPong, []byte("second")), capture(msgPong, []byte("last"))
+	errs := make(chan error, 1)
+	go func() {
+		errs <- func() error {
+			for _, f := range [][]byte{first, second, first, second, second, last} {
+				frame := make([]byte, 4, 4+len(f))
+				binary.BigEndian.PutUint32(frame, uint32(len(f)))
+				if _, err := a.Write(append(frame, f...)); err != nil {
+					return err
+				}
+			}
+			return nil
+		}()
+	}()
+	for _, want := range []string{"first", "second", "last"} {
+		typ, payload, err := recv.read(b)
+		if err != nil || typ != msgPong || string(payload) != want {
+			t.Fatalf("read type %d %q, %v, want %q", typ, payload, err, want)
+		}
+	}
+	if recv.Replayed() != 3 || recv.Rejected() != 0 || recv.Lost() != 0 {
+		t.Fatalf("replayed %d, rejected %d and lost %d, want 3, 0 and 0",
+			recv.Replayed(), recv.Rejected(), recv.Lost())
+	}
+	if err := <-errs; err != nil {
+		t.Fatalf("write: %v", err)
+	}
+}
+// TestWorkerAddrs ensures listeners on every interface are dialled on the local host by the transport they listen with.
+func TestWorkerAddrs(t *testing.T) {
+	got := WorkerAddrs([]string{":11045", "0.0.0.0:11045", "10.0.0.2:11045", "quic://0.0.0.0:11046"})
+	want := []string{"127.0.0.1:11045", "127.0.0.1:11045", "10.0.0.2:11045", "quic://127.0.0.1:11046"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("address %d: %s, want %s", i, got[i], want[i])
+		}
+	}
+}
+// TestAnnouncement ensures announced jobs decode with the algorithm they are for and are refused when altered or authenticated with another key.
+func TestAnnouncement(t *testing.T) {
+	key := []byte("announce key")
+	want := job{ID: 9, Height: 1000, Header: wire.BlockHeader{Version: 2, Nonce: 5}}
+	msg := announcement(key, "scrypt", &want)
+	algo, got, err := parseAnnouncement(key, msg)
+	if err != nil {
+		t.Fatalf("parseAnnouncement: unexpected error: %v", err)
+	}
+	if algo != "scrypt" || got.ID != want.ID || got.Header.BlockHash() != want.Header.BlockHash() {
+		t.Fatalf("announcement of %s job %+v, want scrypt job %+v", algo, got, want)
+	}
+	if _, _, err := parseAnnouncement([]byte("other key"), msg); err != errAuth {
+		t.Fatalf("parseAnnouncement with another key: got %v, want errAuth", err)
+	}
+	msg[3] ^= 1
+	if _, _, err := parseAnnouncement(key, msg); err != errAuth {
+		t.Fatalf("parseAnnouncement of an altered announcement: got %v, want errAuth", err)
+	}
+	if _, _, err := parseAnnouncement(key, msg[:10]); err == nil {
+		t.Fatal("parseAnnouncement: expected error for a short announcement")
+	}
+}
@@ -0,0 +1,209 @@
+package controller
+import (
+	"errors"
+	"net"
+	"sync"
+	"time"
+	"git.parallelcoin.io/dev/9/pkg/util/cl"
+)
+// The priorities of messages in the send queue of a session, highest first. Solutions and their results go before everything else so a flood of new jobs can not hold up a block that was found, jobs go before heartbeats, and bulk messages go last.
+const (
+	prioritySolution = iota
+	priorityJob
+	priorityHeartbeat
+	priorityBulk
+	priorities
+)
+// queueClass is the limit and rate of the messages of a priority. A class holds at most limit messages, and when it is full the oldest job is dropped as it is stale once a newer one is queued. Messages of the class are sent at most rate a second, with bursts of up to burst of them, or without limit when rate is zero.
+type queueClass struct {
+	limit int
+	rate  float64
+	burst float64
+}
+var (
+	// queueClasses are the limits and rates of each priority.
+	queueClasses = [priorities]queueClass{
+		prioritySolution:  {limit: 64},
+		priorityJob:       {limit: 4, rate: 20, burst: 4},
+		priorityHeartbeat: {limit: 8, rate: 10, burst: 4},
+		priorityBulk:      {limit: 64, rate: 50, burst: 16},
+	}
+	// errQueueClosed is returned when a message is queued on a session that has ended.
+	errQueueClosed = errors.New("send queue closed")
+)
+// priorityOf returns the priority of a message type.
+func priorityOf(typ byte) int {
+	switch typ {
+	case msgSolution, msgResult:
+		return prioritySolution
+	// msgMulticast goes with the jobs so it stays ahead of the first one.
+	case msgJob, msgMulticast:
+		return priorityJob
+	case msgPing, msgPong, msgResync:
+		return priorityHeartbeat
+	}
+	return priorityBulk
+}
+// queuedMsg is a message waiting in a send queue.
+type queuedMsg struct {
+	typ     byte
+	payload []byte
+}
+// classQueue is the messages of one priority waiting to be sent and the tokens of its rate.
+type classQueue struct {
+	queueClass
+	msgs    []queuedMsg
+	tokens  float64
+	filled  time.Time
+	dropped uint64
+}
+// refill adds the tokens earned since the last refill, up to the burst of the class.
+func (c *classQueue) refill(now time.Time) {
+	c.tokens += now.Sub(c.filled).Seconds() * c.rate
+	if c.tokens > c.burst {
+		c.tokens = c.burst
+	}
+	c.filled = now
+}
+// wait returns how long until the class may send its next message, which is zero when it may now.
+func (c *classQueue) wait() time.Duration {
+	if c.rate == 0 || c.tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - c.tokens) / c.rate * float64(time.Second))
+}
+// sendQueue sends the messages of a session on its channel from one goroutine, highest priority first and each priority within its rate, so the messages queued by the parts of a controller or worker never wait on a slow connection and a solution never waits behind jobs. The channel numbers messages as it sends them, so they leave in the order the queue picks.
+type sendQueue struct {
+	sync.Mutex
+	conn    net.Conn
+	send    *channel
+	classes [priorities]classQueue
+	ready   chan struct{}
+	done    chan struct{}
+	err     error
+}
+// newSendQueue returns a queue sending on the passed channel and connection. Use run to start sending.
+func newSendQueue(conn net.Conn, send *channel) *sendQueue {
+	q := &sendQueue{
+		conn:  conn,
+		send:  send,
+		ready: make(chan struct{}, 1),
+		done:  make(chan struct{}),
+	}
+	now := time.Now()
+	for i := range q.classes {
+		q.classes[i].queueClass = queueClasses[i]
+		q.classes[i].tokens = queueClasses[i].burst
+		q.classes[i].filled = now
+	}
+	return q
+}
+// push queues a message to be sent. It returns the error that ended the queue if it has ended.
+func (q *sendQueue) push(typ byte, payload []byte) error {
+	q.Lock()
+	defer q.Unlock()
+	select {
+	case <-q.done:
+		if q.err != nil {
+			return q.err
+		}
+		return errQueueClosed
+	default:
+	}
+	c := &q.classes[priorityOf(typ)]
+	if len(c.msgs) >= c.limit {
+		for i, m := range c.msgs {
+			if m.typ == msgJob {
+				c.msgs = append(c.msgs[:i], c.msgs[i+1:]...)
+				c.dropped++
+				break
+			}
+		}
+	}
+	c.msgs = append(c.msgs, queuedMsg{typ: typ, payload: payload})
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+	return nil
+}
+// next takes the message of the highest priority whose class may send now. When none may, it returns how long until one may, or zero when the queue is empty.
+func (q *sendQueue) next(now time.Time) (m queuedMsg, ok bool, wait time.Duration) {
+	q.Lock()
+	defer q.Unlock()
+	for i := range q.classes {
+		c := &q.classes[i]
+		if len(c.msgs) == 0 {
+			continue
+		}
+		c.refill(now)
+		if w := c.wait(); w > 0 {
+			if wait == 0 || w < wait {
+				wait = w
+			}
+			continue
+		}
+		if c.rate > 0 {
+			c.tokens--
+		}
+		m = c.msgs[0]
+		c.msgs = c.msgs[1:]
+		return m, true, 0
+	}
+	return
+}
+// droppedJobs returns the number of stale jobs dropped from the queue because newer ones were queued before they could be sent.
+func (q *sendQueue) droppedJobs() uint64 {
+	q.Lock()
+	defer q.Unlock()
+	return q.classes[priorityJob].dropped
+}
+// run sends the queued messages until the queue is closed or a message fails to send, in which case the connection is closed. It must be run as a goroutine.
+func (q *sendQueue) run() {
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+	for {
+		m, ok, wait := q.next(time.Now())
+		if ok {
+			if err := q.send.write(q.conn, m.typ, m.payload); err != nil {
+				log <- cl.Debug{"miner peer", q.conn.RemoteAddr(), err}
+				q.stop(err)
+				q.conn.Close()
+				return
+			}
+			continue
+		}
+		var tick <-chan time.Time
+		if wait > 0 {
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+			timer.Reset(wait)
+			tick = timer.C
+		}
+		select {
+		case <-q.ready:
+		case <-tick:
+		case <-q.done:
+			return
+		}
+	}
+}
+// stop ends the queue with the passed error, which is nil when the session ended.
+func (q *sendQueue) stop(err error) {
+	q.Lock()
+	defer q.Unlock()
+	select {
+	case <-q.done:
+	default:
+		q.err = err
+		close(q.done)
+	}
+}
+// close ends the queue, dropping the messages not yet sent.
+func (q *sendQueue) close() {
+	q.stop(nil)
+}
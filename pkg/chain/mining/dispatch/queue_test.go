@@ -0,0 +1,41 @@
+package controller
+import (
+	"net"
+	"testing"
+)
+// TestSendQueue ensures a solution queued behind a flood of jobs is sent first, the stale jobs beyond the limit of their class are dropped leaving the newest, and nothing is queued once the queue is closed.
+func TestSendQueue(t *testing.T) {
+	a, b := net.Pipe()
+	defer a.Close()
+	defer b.Close()
+	send, _ := newChannel([]byte("key"), false)
+	recv, _ := newChannel([]byte("key"), false)
+	q := newSendQueue(a, send)
+	jobs := queueClasses[priorityJob].limit + 3
+	for i := 0; i < jobs; i++ {
+		if err := q.push(msgJob, []byte{byte(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := q.push(msgSolution, []byte("found")); err != nil {
+		t.Fatal(err)
+	}
+	go q.run()
+	typ, payload, err := recv.read(b)
+	if err != nil || typ != msgSolution || string(payload) != "found" {
+		t.Fatalf("first message read as type %d %q, %v, want the solution", typ, payload, err)
+	}
+	for i := jobs - queueClasses[priorityJob].limit; i < jobs; i++ {
+		typ, payload, err = recv.read(b)
+		if err != nil || typ != msgJob || payload[0] != byte(i) {
+			t.Fatalf("job read as type %d %x, %v, want job %d", typ, payload, err, i)
+		}
+	}
+	if q.droppedJobs() != 3 {
+		t.Fatalf("%d jobs dropped, want 3", q.droppedJobs())
+	}
+	q.close()
+	if err := q.push(msgPing, nil); err != errQueueClosed {
+		t.Fatalf("push after close: got %v, want errQueueClosed", err)
+	}
+}
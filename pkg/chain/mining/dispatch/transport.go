@@ -0,0 +1,221 @@
+package controller
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"strings"
+	"time"
+	"github.com/quic-go/quic-go"
+	"git.parallelcoin.io/dev/9/pkg/rpc/sub"
+)
+// The transports sessions run over. The address of a listener or controller picks the transport of the sessions on it by its scheme, so each worker can reach each controller the way that suits the network between them: TCP by default, QUIC for connections over the internet where a stream with congestion control and no head of line blocking between connections is wanted, and FEC coded UDP on a LAN, where its latency is lowest. The session handshake is the same on all of them, so the identities and keys of the peers are checked the same way whichever is used.
+const (
+	// quicScheme starts the addresses reached over QUIC, and fecScheme those reached over FEC coded UDP sessions.
+	quicScheme = "quic://"
+	fecScheme  = "fec://"
+	// quicProtocol is the ALPN protocol of sessions over QUIC.
+	quicProtocol = "parallelcoin-miner"
+	// quicLinger is how long a closed session waits for the peer to close its QUIC connection before closing it.
+	quicLinger = time.Second * 5
+)
+// Transport names for Peer.
+const (
+	TransportTCP  = "tcp"
+	TransportQUIC = "quic"
+	TransportFEC  = "fec"
+)
+// splitAddr returns the transport named by the scheme of an address and the address without it.
+func splitAddr(addr string) (transport, hostPort string) {
+	switch {
+	case strings.HasPrefix(addr, quicScheme):
+		return TransportQUIC, addr[len(quicScheme):]
+	case strings.HasPrefix(addr, fecScheme):
+		return TransportFEC, addr[len(fecScheme):]
+	}
+	return TransportTCP, addr
+}
+// listen listens for sessions on the address by the transport its scheme names.
+func listen(addr string) (net.Listener, error) {
+	transport, hostPort := splitAddr(addr)
+	switch transport {
+	case TransportQUIC:
+		return listenQUIC(hostPort)
+	case TransportFEC:
+		l, err := sub.ListenSession(hostPort)
+		if err != nil {
+			return nil, err
+		}
+		return l, nil
+	}
+	return net.Listen("tcp", hostPort)
+}
+// dial connects to the controller at the address by the transport its scheme names.
+func dial(addr string, timeout time.Duration) (net.Conn, error) {
+	transport, hostPort := splitAddr(addr)
+	switch transport {
+	case TransportQUIC:
+		return dialQUIC(hostPort, timeout)
+	case TransportFEC:
+		s, err := sub.DialSession(hostPort, timeout)
+		if err != nil {
+			return nil, err
+		}
+		return s, nil
+	}
+	return net.DialTimeout("tcp", hostPort, timeout)
+}
+// transportOf returns the transport of a connection made by listen or dial.
+func transportOf(conn net.Conn) string {
+	switch conn.(type) {
+	case *quicConn:
+		return TransportQUIC
+	case *sub.Session:
+		return TransportFEC
+	}
+	return TransportTCP
+}
+// quicConn is the stream of a QUIC connection carrying a session.
+type quicConn struct {
+	*quic.Stream
+	session *quic.Conn
+}
+// LocalAddr returns the local address of the QUIC connection.
+func (c *quicConn) LocalAddr() net.Addr {
+	return c.session.LocalAddr()
+}
+// RemoteAddr returns the remote address of the QUIC connection.
+func (c *quicConn) RemoteAddr() net.Addr {
+	return c.session.RemoteAddr()
+}
+// Close closes the stream and then the QUIC connection, as there is one session on each. Closing a QUIC connection drops what was not yet sent on its streams, so the connection is only closed once the peer closes it too or after quicLinger, which lets the last messages written reach the peer.
+func (c *quicConn) Close() error {
+	err := c.Stream.Close()
+	go func() {
+		select {
+		case <-c.session.Context().Done():
+		case <-time.After(quicLinger):
+		}
+		c.session.CloseWithError(0, "")
+	}()
+	return err
+}
+// quicListener accepts the first stream of each QUIC connection as a session.
+type quicListener struct {
+	*quic.Listener
+	conns chan net.Conn
+	done  chan struct{}
+	err   error
+}
+// quicConfig returns the QUIC configuration of sessions, which gives up on a connection that does not complete its handshake within the timeout. The heartbeats of sessions keep connections from idling out.
+func quicConfig(timeout time.Duration) *quic.Config {
+	return &quic.Config{
+		HandshakeIdleTimeout: timeout,
+		MaxIdleTimeout:       HeartbeatInterval * 3,
+	}
+}
+// listenQUIC listens for sessions over QUIC on the address. The TLS certificate of the listener is made for it and not checked by workers, as the session handshake authenticates the controller.
+func listenQUIC(addr string) (net.Listener, error) {
+	cert, err := quicCertificate()
+	if err != nil {
+		return nil, err
+	}
+	tlsConf := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		NextProtos:   []string{quicProtocol},
+	}
+	l, err := quic.ListenAddr(addr, tlsConf, quicConfig(dialTimeout))
+	if err != nil {
+		return nil, err
+	}
+	ql := &quicListener{
+		Listener: l,
+		conns:    make(chan net.Conn),
+		done:     make(chan struct{}),
+	}
+	go ql.accept()
+	return ql, nil
+}
+// accept accepts QUIC connections until the listener is closed, waiting for the stream of each on its own so a connection that opens none does not hold up the others. A stream is only seen once the worker sends on it, which it does first in the session handshake. It must be run as a goroutine.
+func (l *quicListener) accept() {
+	for {
+		session, err := l.Listener.Accept(context.Background())
+		if err != nil {
+			l.err = err
+			close(l.done)
+			return
+		}
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), dialTimeout)
+			stream, err := session.AcceptStream(ctx)
+			cancel()
+			if err != nil {
+				session.CloseWithError(0, "")
+				return
+			}
+			select {
+			case l.conns <- &quicConn{Stream: stream, session: session}:
+			case <-l.done:
+				session.CloseWithError(0, "")
+			}
+		}()
+	}
+}
+// Accept returns the session of the next QUIC connection.
+func (l *quicListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.done:
+		return nil, l.err
+	}
+}
+// dialQUIC opens a session over QUIC to the address.
+func dialQUIC(addr string, timeout time.Duration) (net.Conn, error) {
+	tlsConf := &tls.Config{
+		// The session handshake checks the identity of the controller, so the certificate is not.
+		InsecureSkipVerify: true,
+		NextProtos:         []string{quicProtocol},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	session, err := quic.DialAddr(ctx, addr, tlsConf, quicConfig(timeout))
+	if err != nil {
+		return nil, err
+	}
+	stream, err := session.OpenStreamSync(ctx)
+	if err != nil {
+		session.CloseWithError(0, "")
+		return nil, err
+	}
+	return &quicConn{Stream: stream, session: session}, nil
+}
+// quicCertificate returns a new self signed certificate for a QUIC listener.
+func quicCertificate() (cert tls.Certificate, err error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return
+	}
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: quicProtocol},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour * 24 * 365 * 10),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, nil
+}
@@ -0,0 +1,51 @@
+package controller
+import (
+	"io"
+	"testing"
+)
+// TestTransports ensures a session connects and carries data both ways over each transport, which is picked by the scheme of the address.
+func TestTransports(t *testing.T) {
+	for _, scheme := range []string{"", quicScheme, fecScheme} {
+		l, err := listen(scheme + "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("listen %q: unexpected error: %v", scheme, err)
+		}
+		want, _ := splitAddr(scheme)
+		errs := make(chan error, 1)
+		go func() {
+			conn, err := l.Accept()
+			if err != nil {
+				errs <- err
+				return
+			}
+			defer conn.Close()
+			if got := transportOf(conn); got != want {
+				t.Errorf("accepted %s connection, want %s", got, want)
+			}
+			buf := make([]byte, 5)
+			if _, err = io.ReadFull(conn, buf); err == nil {
+				_, err = conn.Write(buf)
+			}
+			errs <- err
+		}()
+		conn, err := dial(scheme+l.Addr().String(), dialTimeout)
+		if err != nil {
+			t.Fatalf("dial %q: unexpected error: %v", scheme, err)
+		}
+		if got := transportOf(conn); got != want {
+			t.Errorf("dialled %s connection, want %s", got, want)
+		}
+		if _, err = conn.Write([]byte("hello")); err != nil {
+			t.Fatalf("%s write: unexpected error: %v", want, err)
+		}
+		buf := make([]byte, 5)
+		if _, err = io.ReadFull(conn, buf); err != nil || string(buf) != "hello" {
+			t.Fatalf("%s read %q, %v", want, buf, err)
+		}
+		if err = <-errs; err != nil {
+			t.Fatalf("%s accept: unexpected error: %v", want, err)
+		}
+		conn.Close()
+		l.Close()
+	}
+}
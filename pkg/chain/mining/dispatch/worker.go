@@ -0,0 +1,390 @@
+package controller
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+	blockchain "git.parallelcoin.io/dev/9/pkg/chain"
+	"git.parallelcoin.io/dev/9/pkg/rpc/sub"
+	"git.parallelcoin.io/dev/9/pkg/util/cl"
+)
+const (
+	// dialTimeout is how long a worker waits for a connection to a controller.
+	dialTimeout = time.Second * 10
+	// minReconnect and maxReconnect bound the delay between attempts to reconnect to a controller, which doubles after each failure.
+	minReconnect = time.Second
+	maxReconnect = time.Second * 30
+	// hashRateInterval is the time between reports of the hash rate of a worker.
+	hashRateInterval = time.Second * 15
+)
+// WorkerConfig is a descriptor containing the configuration of a standalone miner worker.
+type WorkerConfig struct {
+	// Controllers are the addresses of the miner listeners of the nodes to get work from. They are tried in turn whenever a connection fails.
+	Controllers []string
+	// Key is derived from the miner password and must match the MinerKey of the controller, or from the secret of the API key named by KeyName.
+	Key []byte
+	// KeyName is the name of the API key of the worker, or empty to use the miner password.
+	KeyName string
+	// Algo is the name of the algorithm to ask for work for, or "random" to have the controller pick one for each block.
+	Algo string
+	// Threads is the number of threads hashing the work.
+	Threads int
+	// Encrypt asks the controller to encrypt the session, which it also does when it is configured to encrypt all of them.
+	Encrypt bool
+	// Multicast is the multicast group or broadcast address the controller announces jobs on, to receive them from there instead of on the connection, or empty to not ask for it.
+	Multicast string
+	// Identity is the key pair the worker proves it holds to the controller, or nil for a new one each time the worker is started.
+	Identity *Identity
+	// ControllerKeys are the public keys of the identities of the controllers the worker trusts. When there are none, a worker using the miner password trusts the identity derived from it and a worker using an API key trusts any controller that holds the key.
+	ControllerKeys [][]byte
+	// OnJoin and OnLeave, when set, are called when a session with a controller starts and when it ends, which is as soon as the controller stops answering pings.
+	OnJoin  func(Peer)
+	OnLeave func(Peer)
+}
+// Worker solves the jobs a Controller sends it and returns the solutions, reconnecting when the connection is lost
+type Worker struct {
+	cfg      WorkerConfig
+	hashes   uint64
+	identity *Identity
+	peers    *Peers
+}
+// Run connects to the controllers and mines until quit is closed, reconnecting with an increasing delay whenever a session fails.
+func (w *Worker) Run(quit chan struct{}) {
+	done := make(chan struct{})
+	defer close(done)
+	go w.speedMonitor(done)
+	go w.peers.run(done)
+	delay := minReconnect
+	for i := 0; ; i++ {
+		addr := w.cfg.Controllers[i%len(w.cfg.Controllers)]
+		start := time.Now()
+		err := w.session(addr, quit)
+		select {
+		case <-quit:
+			return
+		default:
+		}
+		// A session that lasted a while was working, so the next attempt does not need to wait long.
+		if time.Since(start) > maxReconnect {
+			delay = minReconnect
+		}
+		log <- cl.Warn{"miner session with", addr, "ended:", err, "- reconnecting in", delay}
+		select {
+		case <-quit:
+			return
+		case <-time.After(delay):
+		}
+		if delay *= 2; delay > maxReconnect {
+			delay = maxReconnect
+		}
+	}
+}
+// session subscribes to the controller at the passed address and mines the jobs it sends until the connection fails or quit is closed.
+func (w *Worker) session(addr string, quit chan struct{}) error {
+	conn, err := dial(addr, dialTimeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	// Closing the connection on quit unblocks the reads below.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-quit:
+			conn.Close()
+		case <-done:
+		}
+	}()
+	var flags byte
+	if w.cfg.Encrypt {
+		flags |= flagEncrypt
+	}
+	if w.cfg.Multicast != "" {
+		flags |= flagMulticast
+	}
+	hs := newHandshake(w.identity, w.cfg.Key)
+	hello, err := hs.writeHello(append([]byte{flags, byte(len(w.cfg.KeyName))}, w.cfg.KeyName...))
+	if err != nil {
+		return err
+	}
+	if err = writeMsg(conn, nil, 0, msgHello, hello); err != nil {
+		return err
+	}
+	typ, welcome, err := readMsg(conn, nil)
+	if err != nil {
+		return err
+	}
+	if typ != msgWelcome {
+		return errors.New("controller did not send welcome")
+	}
+	if welcome, err = hs.readWelcome(welcome); err != nil {
+		return err
+	}
+	if len(welcome) != 1 {
+		return errors.New("invalid welcome message")
+	}
+	if !w.trusts(hs.rs) {
+		return fmt.Errorf("controller identity %x is not trusted", hs.rs)
+	}
+	subscribe, err := hs.writeSubscribe([]byte(w.cfg.Algo))
+	if err != nil {
+		return err
+	}
+	if err = writeMsg(conn, nil, 0, msgSubscribe, subscribe); err != nil {
+		return err
+	}
+	toController, fromController := hs.split()
+	encrypt := (flags|welcome[0])&flagEncrypt != 0
+	send, err := newChannel(toController, encrypt)
+	if err != nil {
+		return err
+	}
+	recv, err := newChannel(fromController, encrypt)
+	if err != nil {
+		return err
+	}
+	queue := newSendQueue(conn, send)
+	go queue.run()
+	defer queue.close()
+	write := queue.push
+	defer w.peers.leave(conn)
+	// Jobs arrive on the connection and, for multicast workers, from announcements, so starting them is guarded and jobs older than the newest one are not mined.
+	var jobMtx sync.Mutex
+	var stop chan struct{}
+	var lastJob uint32
+	ended := false
+	startJob := func(j job, first, last uint64) {
+		jobMtx.Lock()
+		defer jobMtx.Unlock()
+		if ended || (lastJob != 0 && j.ID <= lastJob) {
+			return
+		}
+		lastJob = j.ID
+		log <- cl.Debugf{"miner received job %d for height %d", j.ID, j.Height}
+		if stop != nil {
+			close(stop)
+		}
+		stop = make(chan struct{})
+		for t := 0; t < w.cfg.Threads; t++ {
+			go w.mine(j, t, first, last, stop, write)
+		}
+	}
+	defer func() {
+		jobMtx.Lock()
+		ended = true
+		if stop != nil {
+			close(stop)
+		}
+		jobMtx.Unlock()
+	}()
+	var announcements *sub.Announcements
+	defer func() {
+		if announcements != nil {
+			announcements.Close()
+		}
+	}()
+	for {
+		typ, payload, err := recv.read(conn)
+		if err == errResync {
+			log <- cl.Debug{"miner", err}
+			if err = write(msgResync, []byte{resyncRequest}); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			// The controller drops a worker that fails authentication without a reply.
+			if err == io.EOF && recv.Count() == 0 {
+				return errors.New("connection closed by the controller, check mining.pass is the same as on the node or mining.apikey is allowed")
+			}
+			return err
+		}
+		// The first message from the controller shows it accepted the subscription.
+		if recv.Count() == 1 {
+			log <- cl.Infof{"miner subscribed to %s for %s, controller identity %x", addr, w.cfg.Algo, hs.rs}
+			w.peers.join(conn, Peer{Addr: addr, Transport: transportOf(conn), Identity: hs.rs}, recv, func() error {
+				return write(msgPing, nil)
+			})
+		}
+		w.peers.seen(conn)
+		switch typ {
+		case msgJob:
+			var j job
+			if err = j.deserialize(payload); err != nil {
+				return err
+			}
+			startJob(j, 0, math.MaxUint32)
+		case msgMulticast:
+			if len(payload) != multicastSize || announcements != nil {
+				return errors.New("invalid multicast message")
+			}
+			if announcements, err = sub.ListenAnnouncements(w.cfg.Multicast); err != nil {
+				log <- cl.Warn{"miner unable to receive announced jobs on", w.cfg.Multicast, err}
+				announcements = nil
+				continue
+			}
+			log <- cl.Info{"miner receiving announced jobs on", w.cfg.Multicast}
+			go w.receiveAnnouncements(announcements, payload, startJob)
+		case msgPing:
+			if err = write(msgPong, nil); err != nil {
+				return err
+			}
+		case msgPong:
+			w.peers.pong(conn)
+		case msgResync:
+			if payload[0]&resyncRequest != 0 {
+				if err = write(msgResync, []byte{0}); err != nil {
+					return err
+				}
+			}
+		case msgResult:
+			if len(payload) > 0 && payload[0] == 1 {
+				log <- cl.Info{"miner block accepted", string(payload[1:])}
+			} else if len(payload) > 0 {
+				log <- cl.Warn{"miner solution rejected:", string(payload[1:])}
+			}
+		default:
+			return fmt.Errorf("unexpected message type %d", typ)
+		}
+	}
+}
+// receiveAnnouncements starts the jobs announced for the algorithm of the worker until the announcements are closed, searching the lane of the nonces of the slot in the passed msgMulticast payload. Workers with slots beyond the lanes start each round of them a second later. It must be run as a goroutine.
+func (w *Worker) receiveAnnouncements(
+	announcements *sub.Announcements, multicast []byte, startJob func(job, uint64, uint64)) {
+	slot := binary.BigEndian.Uint32(multicast)
+	key := multicast[4:]
+	laneSize := uint64(math.MaxUint32+1) / multicastLanes
+	first := uint64(slot%multicastLanes) * laneSize
+	offset := time.Second * time.Duration(slot/multicastLanes)
+	for {
+		msg, from, err := announcements.Receive()
+		if err != nil {
+			return
+		}
+		algo, j, err := parseAnnouncement(key, msg)
+		if err != nil {
+			log <- cl.Debug{"miner ignored announcement from", from, err}
+			continue
+		}
+		if algo != w.cfg.Algo {
+			continue
+		}
+		j.Header.Timestamp = j.Header.Timestamp.Add(offset)
+		startJob(j, first, first+laneSize-1)
+	}
+}
+// mine searches the nonces from first to last that belong to the passed thread, moving the timestamp forward whenever they are exhausted, until stop is closed or a solution is found. It must be run as a goroutine.
+func (w *Worker) mine(j job, thread int, first, last uint64, stop chan struct{}, write func(byte, []byte) error) {
+	header := j.Header
+	target := blockchain.CompactToBig(header.Bits)
+	var hashes uint64
+	defer func() {
+		atomic.AddUint64(&w.hashes, hashes)
+	}()
+	for {
+		for nonce := first + uint64(thread); nonce <= last; nonce += uint64(w.cfg.Threads) {
+			if hashes&0xff == 0 {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				atomic.AddUint64(&w.hashes, hashes)
+				hashes = 0
+			}
+			header.Nonce = uint32(nonce)
+			hash := header.BlockHashWithAlgos(j.Height)
+			hashes++
+			if blockchain.HashToBig(&hash).Cmp(target) <= 0 {
+				log <- cl.Info{"miner found a solution for job", j.ID, "at height", j.Height}
+				sol := solution{
+					ID:        j.ID,
+					Nonce:     header.Nonce,
+					Timestamp: uint32(header.Timestamp.Unix()),
+				}
+				if err := write(msgSolution, sol.serialize()); err != nil {
+					log <- cl.Warn{"miner failed to submit solution:", err}
+				}
+				return
+			}
+		}
+		header.Timestamp = header.Timestamp.Add(time.Second)
+	}
+}
+// speedMonitor periodically logs the hash rate of the worker. It must be run as a goroutine.
+func (w *Worker) speedMonitor(done chan struct{}) {
+	ticker := time.NewTicker(hashRateInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			hashesPerSec := float64(atomic.SwapUint64(&w.hashes, 0)) /
+				hashRateInterval.Seconds()
+			if hashesPerSec != 0 {
+				log <- cl.Infof{
+					"%s Hash speed: %6.4f Kh/s %0.2f h/s",
+					w.cfg.Algo,
+					hashesPerSec / 1000,
+					hashesPerSec,
+				}
+			}
+		case <-done:
+			return
+		}
+	}
+}
+// WorkerAddrs returns the addresses a worker can dial for the passed listener addresses. Listeners on an unspecified host, which accept connections on every interface, are reached on the local host.
+func WorkerAddrs(listeners []string) (addrs []string) {
+	for _, l := range listeners {
+		// The scheme of the transport is kept, so workers reach the listener the way it accepts them.
+		_, hostPort := splitAddr(l)
+		scheme := l[:len(l)-len(hostPort)]
+		host, port, err := net.SplitHostPort(hostPort)
+		if err != nil {
+			addrs = append(addrs, l)
+			continue
+		}
+		if ip := net.ParseIP(host); host == "" || ip != nil && ip.IsUnspecified() {
+			host = "127.0.0.1"
+		}
+		addrs = append(addrs, scheme+net.JoinHostPort(host, port))
+	}
+	return
+}
+// NewWorker returns a new miner worker for the provided configuration. Use Run to start mining.
+func NewWorker(
+	cfg *WorkerConfig) *Worker {
+	w := &Worker{cfg: *cfg, identity: cfg.Identity}
+	w.peers = NewPeers(HeartbeatInterval, w.cfg.OnJoin, w.cfg.OnLeave)
+	if w.cfg.Threads < 1 {
+		w.cfg.Threads = 1
+	}
+	if w.identity == nil {
+		var err error
+		if w.identity, err = NewIdentity(); err != nil {
+			log <- cl.Error{"unable to generate miner worker identity:", err}
+			w.identity = IdentityFromSeed(append([]byte(w.cfg.KeyName), w.cfg.Key...))
+		}
+	}
+	return w
+}
+// trusts returns whether the worker trusts the controller with the passed identity.
+func (w *Worker) trusts(controller []byte) bool {
+	if len(w.cfg.ControllerKeys) == 0 {
+		return w.cfg.KeyName != "" ||
+			bytes.Equal(controller, IdentityFromSeed(w.cfg.Key).public[:])
+	}
+	for _, key := range w.cfg.ControllerKeys {
+		if bytes.Equal(controller, key) {
+			return true
+		}
+	}
+	return false
+}
@@ -3,6 +3,7 @@ import (
 	"bytes"
 	"container/heap"
 	"fmt"
+	"sync"
 	"time"
 	cl "git.parallelcoin.io/dev/9/pkg/util/cl"
 	blockchain "git.parallelcoin.io/dev/9/pkg/chain"
@@ -20,6 +21,9 @@ const (
 	blockHeaderOverhead = wire.MaxBlockHeaderPayload + wire.MaxVarIntPayload
 	// CoinbaseFlags is added to the coinbase script of a generated block and is used to monitor BIP16 support as well as blocks that are generated via pod.
 	CoinbaseFlags = "/P2SH/9/"
+	// MaxCoinbaseTagLen is the longest coinbase tag that fits in the coinbase script along with the largest block height and extra nonce and the coinbase flags, each of them and the tag taking one byte more to push.
+	MaxCoinbaseTagLen = blockchain.MaxCoinbaseScriptLen - (1 + 5) - (1 + 9) -
+		(1 + len(CoinbaseFlags)) - 1
 )
 // TxDesc is a descriptor about a transaction in a transaction source along with additional metadata.
 type TxDesc struct {
@@ -33,6 +37,8 @@ type TxDesc struct {
 	Fee int64
 	// FeePerKB is the fee the transaction pays in Satoshi per 1000 bytes.
 	FeePerKB int64
+	// AncestorFeePerKB is the fee in Satoshi per 1000 bytes the transaction pays together with all of its ancestors in the source pool, which is what mining it is worth when its ancestors have to be mined to include it.  It is zero when the source does not track ancestors.
+	AncestorFeePerKB int64
 }
 // TxSource represents a source of transactions to consider for inclusion in new blocks. The interface contract requires that all of these methods are safe for concurrent access with respect to the source.
 type TxSource interface {
@@ -49,6 +55,10 @@ type txPrioItem struct {
 	fee      int64
 	priority float64
 	feePerKB int64
+	// ancestorFeePerKB is the fee rate of the transaction together with its ancestors in the source pool, which raises the fee rate the ancestors are selected at so a child paying for its parents gets them mined.
+	ancestorFeePerKB int64
+	// forced is set for transactions the miner asked to include, which are selected before all others.
+	forced bool
 	// dependsOn holds a map of transaction hashes which this one depends on.  It will only be set when the transaction references other transactions in the source pool and hence must come after them in a block.
 	dependsOn map[chainhash.Hash]struct{}
 }
@@ -89,10 +99,35 @@ func (pq *txPriorityQueue) SetLessFunc(lessFunc txPriorityQueueLessFunc) {
 	pq.lessFunc = lessFunc
 	heap.Init(pq)
 }
+// raiseAncestorFeePerKB raises the fee rate of every ancestor of the passed item in prioItems that pays less than feePerKB to feePerKB.
+func raiseAncestorFeePerKB(
+	prioItems map[chainhash.Hash]*txPrioItem, prioItem *txPrioItem,
+	feePerKB int64) {
+	visited := make(map[chainhash.Hash]struct{})
+	stack := []*txPrioItem{prioItem}
+	for len(stack) > 0 {
+		item := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for hash := range item.dependsOn {
+			parent, ok := prioItems[hash]
+			if _, seen := visited[hash]; !ok || seen {
+				continue
+			}
+			visited[hash] = struct{}{}
+			if parent.feePerKB < feePerKB {
+				parent.feePerKB = feePerKB
+			}
+			stack = append(stack, parent)
+		}
+	}
+}
 // txPQByPriority sorts a txPriorityQueue by transaction priority and then fees per kilobyte.
 func txPQByPriority(
 	pq *txPriorityQueue, i, j int) bool {
-	// Using > here so that pop gives the highest priority item as opposed to the lowest.  Sort by priority first, then fee.
+	// Using > here so that pop gives the highest priority item as opposed to the lowest.  Sort by priority first, then fee, after the forced transactions.
+	if pq.items[i].forced != pq.items[j].forced {
+		return pq.items[i].forced
+	}
 	if pq.items[i].priority == pq.items[j].priority {
 		return pq.items[i].feePerKB > pq.items[j].feePerKB
 	}
@@ -101,7 +136,10 @@ func txPQByPriority(
 // txPQByFee sorts a txPriorityQueue by fees per kilobyte and then transaction priority.
 func txPQByFee(
 	pq *txPriorityQueue, i, j int) bool {
-	// Using > here so that pop gives the highest fee item as opposed to the lowest.  Sort by fee first, then priority.
+	// Using > here so that pop gives the highest fee item as opposed to the lowest.  Sort by fee first, then priority, after the forced transactions.
+	if pq.items[i].forced != pq.items[j].forced {
+		return pq.items[i].forced
+	}
 	if pq.items[i].feePerKB == pq.items[j].feePerKB {
 		return pq.items[i].priority > pq.items[j].priority
 	}
@@ -148,10 +186,23 @@ func mergeUtxoView(
 }
 // standardCoinbaseScript returns a standard script suitable for use as the signature script of the coinbase transaction of a new block.  In particular, it starts with the block height that is required by version 2 blocks and adds the extra nonce as well as additional coinbase flags.
 func standardCoinbaseScript(
-	nextBlockHeight int32, extraNonce uint64) ([]byte, error) {
-	return txscript.NewScriptBuilder().AddInt64(int64(nextBlockHeight)).
-		AddInt64(int64(extraNonce)).AddData([]byte(CoinbaseFlags)).
-		Script()
+	nextBlockHeight int32, extraNonce uint64, tag string) ([]byte, error) {
+	builder := txscript.NewScriptBuilder().AddInt64(int64(nextBlockHeight)).
+		AddInt64(int64(extraNonce)).AddData([]byte(CoinbaseFlags))
+	if tag != "" {
+		builder.AddData([]byte(tag))
+	}
+	return builder.Script()
+}
+// CheckCoinbaseTag returns an error if the passed coinbase tag does not fit in the coinbase script.
+func CheckCoinbaseTag(
+	tag string) error {
+	if len(tag) > MaxCoinbaseTagLen {
+		return fmt.Errorf(
+			"coinbase tag length of %d is too long (max: %d)",
+			len(tag), MaxCoinbaseTagLen)
+	}
+	return nil
 }
 // createCoinbaseTx returns a coinbase transaction paying an appropriate subsidy based on the passed block height to the provided address.  When the address is nil, the coinbase transaction will instead be redeemable by anyone. See the comment for NewBlockTemplate for more information about why the nil address handling is useful.
 func createCoinbaseTx(
@@ -238,6 +289,10 @@ type BlkTmplGenerator struct {
 	sigCache    *txscript.SigCache
 	hashCache   *txscript.HashCache
 	algo        string
+	tagMtx      sync.RWMutex
+	coinbaseTag string
+	ctlMtx      sync.RWMutex
+	controls    *TxControls
 }
 // NewBlkTmplGenerator returns a new block template generator for the given policy using transactions from the provided transaction source. The additional state-related fields are required in order to ensure the templates are built on top of the current best chain and adhere to the consensus rules.
 func NewBlkTmplGenerator(
@@ -255,6 +310,7 @@ func NewBlkTmplGenerator(
 		sigCache:    sigCache,
 		hashCache:   hashCache,
 		algo:        algo,
+		coinbaseTag: policy.CoinbaseTag,
 	}
 }
 // NewBlockTemplate returns a new block template that is ready to be solved using the transactions from the passed transaction source pool and a coinbase that either pays to the passed address if it is not nil, or a coinbase that is redeemable by anyone if the passed address is nil.  The nil address functionality is useful since there are cases such as the getblocktemplate RPC where external mining software is responsible for creating their own coinbase which will replace the one generated for the block template.  Thus the need to have configured address can be avoided. The transactions selected and included are prioritized according to several factors.  First, each transaction has a priority calculated based on its value, age of inputs, and size.
@@ -283,7 +339,16 @@ func NewBlkTmplGenerator(
 //  |  transactions (while block size   |   |
 //  |  <= policy.BlockMinSize)          |   |
 //   -----------------------------------  --
+// The transaction controls of the generator are applied on top of the policy, see TxControls.
 func (g *BlkTmplGenerator) NewBlockTemplate(payToAddress util.Address, algo string) (*BlockTemplate, error) {
+	return g.NewControlledBlockTemplate(payToAddress, algo, nil)
+}
+// NewControlledBlockTemplate returns a new block template like NewBlockTemplate, applying the passed transaction controls along with those of the generator. Transactions in either are included or excluded, and the lowest maximum weight applies.
+func (g *BlkTmplGenerator) NewControlledBlockTemplate(payToAddress util.Address, algo string, controls *TxControls) (*BlockTemplate, error) {
+	if err := CheckTxControls(controls); err != nil {
+		return nil, err
+	}
+	sel := newTxSelection(g.policy.BlockMaxWeight, g.TxControls(), controls)
 	if algo == "" {
 		algo = "random"
 	}
@@ -296,7 +361,8 @@ func (g *BlkTmplGenerator) NewBlockTemplate(payToAddress util.Address, algo stri
 	nextBlockHeight := best.Height + 1
 	// Create a standard coinbase transaction paying to the provided address.  NOTE: The coinbase value will be updated to include the fees from the selected transactions later after they have actually been selected.  It is created here to detect any errors early before potentially doing a lot of work below.  The extra nonce helps ensure the transaction is not a duplicate transaction (paying the same value to the same public key address would otherwise be an identical transaction for block version 1).
 	extraNonce := uint64(0)
-	coinbaseScript, err := standardCoinbaseScript(nextBlockHeight, extraNonce)
+	coinbaseScript, err := standardCoinbaseScript(nextBlockHeight, extraNonce,
+		g.CoinbaseTag())
 	if err != nil {
 		return nil, err
 	}
@@ -305,10 +371,21 @@ func (g *BlkTmplGenerator) NewBlockTemplate(payToAddress util.Address, algo stri
 	if err != nil {
 		return nil, err
 	}
+	// Signal the soft-fork deployments that are being voted on in an output of the coinbase, as the block version identifies the proof of work algorithm.
+	versionBitsScript, err := g.chain.CalcNextVersionBitsScript()
+	if err != nil {
+		return nil, err
+	}
+	if versionBitsScript != nil {
+		coinbaseTx.MsgTx().AddTxOut(&wire.TxOut{
+			Value:    0,
+			PkScript: versionBitsScript,
+		})
+	}
 	coinbaseSigOpCost := int64(blockchain.CountSigOps(coinbaseTx)) * blockchain.WitnessScaleFactor
 	// Get the current source transactions and create a priority queue to hold the transactions which are ready for inclusion into a block along with some priority related and fee metadata.  Reserve the same number of items that are available for the priority queue.  Also, choose the initial sort order for the priority queue based on whether or not there is an area allocated for high-priority transactions.
 	sourceTxns := g.txSource.MiningDescs()
-	sortedByFee := g.policy.BlockPrioritySize == 0
+	sortedByFee := g.policy.FeeRateOnly || g.policy.BlockPrioritySize == 0
 	priorityQueue := newTxPriorityQueue(len(sourceTxns), sortedByFee)
 	// Create a slice to hold the transactions to be included in the generated block with reserved space.  Also create a utxo view to house all of the input transactions so multiple lookups can be avoided.
 	blockTxns := make([]*util.Tx, 0, len(sourceTxns))
@@ -316,6 +393,8 @@ func (g *BlkTmplGenerator) NewBlockTemplate(payToAddress util.Address, algo stri
 	blockUtxos := blockchain.NewUtxoViewpoint()
 	// dependers is used to track transactions which depend on another transaction in the source pool.  This, in conjunction with the dependsOn map kept with each dependent transaction helps quickly determine which dependent transactions are now eligible for inclusion in the block once each transaction has been included.
 	dependers := make(map[chainhash.Hash]map[chainhash.Hash]*txPrioItem)
+	// prioItems holds the items of all the transactions considered for inclusion so the fee rates of ancestors can be raised by their descendants once all of them are known.
+	prioItems := make(map[chainhash.Hash]*txPrioItem, len(sourceTxns))
 	// Create slices to hold the fees and number of signature operations for each of the selected transactions and add an entry for the coinbase.  This allows the code below to simply append details about a transaction as it is selected for inclusion in the final block. However, since the total fees aren't known yet, use a dummy value for the coinbase fee which will be updated later.
 	txFees := make([]int64, 0, len(sourceTxns))
 	txSigOpCosts := make([]int64, 0, len(sourceTxns))
@@ -335,6 +414,13 @@ mempoolLoop:
 			})
 			continue
 		}
+		// Transactions the miner excluded are left out, and so are those spending their outputs, as their dependency is never met.
+		if _, ok := sel.exclude[*tx.Hash()]; ok {
+			log <- cl.Tracec(func() string {
+				return "skipping excluded tx " + tx.Hash().String()
+			})
+			continue
+		}
 		if !blockchain.IsFinalizedTransaction(tx, nextBlockHeight,
 			g.timeSource.AdjustedTime()) {
 			log <- cl.Tracec(func() string {
@@ -352,6 +438,7 @@ mempoolLoop:
 		}
 		// Setup dependencies for any transactions which reference other transactions in the mempool so they can be properly ordered below.
 		prioItem := &txPrioItem{tx: tx}
+		_, prioItem.forced = sel.include[*tx.Hash()]
 		for _, txIn := range tx.MsgTx().TxIn {
 			originHash := &txIn.PreviousOutPoint.Hash
 			entry := utxos.LookupEntry(txIn.PreviousOutPoint)
@@ -385,13 +472,23 @@ mempoolLoop:
 			nextBlockHeight)
 		// Calculate the fee in Satoshi/kB.
 		prioItem.feePerKB = txDesc.FeePerKB
+		prioItem.ancestorFeePerKB = txDesc.AncestorFeePerKB
 		prioItem.fee = txDesc.Fee
-		// Add the transaction to the priority queue to mark it ready for inclusion in the block unless it has dependencies.
+		prioItems[*tx.Hash()] = prioItem
+		// Merge the referenced outputs from the input transactions to this transaction into the block utxo view.  This allows the code below to avoid a second lookup.
+		mergeUtxoView(blockUtxos, utxos)
+	}
+	// Select the ancestors of a transaction at no less than the fee rate of the transaction together with its ancestors, as that is what including them earns once it is included after them, and then add the transactions without dependencies to the priority queue to mark them ready for inclusion in the block.
+	for _, prioItem := range prioItems {
+		if prioItem.dependsOn != nil {
+			raiseAncestorFeePerKB(prioItems, prioItem,
+				prioItem.ancestorFeePerKB)
+		}
+	}
+	for _, prioItem := range prioItems {
 		if prioItem.dependsOn == nil {
 			heap.Push(priorityQueue, prioItem)
 		}
-		// Merge the referenced outputs from the input transactions to this transaction into the block utxo view.  This allows the code below to avoid a second lookup.
-		mergeUtxoView(blockUtxos, utxos)
 	}
 	log <- cl.Tracec(func() string {
 		return fmt.Sprintf(
@@ -445,7 +542,7 @@ mempoolLoop:
 		txWeight := uint32(blockchain.GetTransactionWeight(tx))
 		blockPlusTxWeight := blockWeight + txWeight
 		if blockPlusTxWeight < blockWeight ||
-			blockPlusTxWeight >= g.policy.BlockMaxWeight {
+			blockPlusTxWeight >= sel.maxWeight {
 			log <- cl.Tracef{
 				"skipping tx %s because it would exceed the max block weight", tx.Hash(),
 			}
@@ -472,10 +569,11 @@ mempoolLoop:
 			logSkippedDeps(tx, deps)
 			continue
 		}
-		// Skip free transactions once the block is larger than the minimum block size.
-		if sortedByFee &&
+		// Skip free transactions once the block is larger than the minimum block size, or always when mining by fee rate only, unless the miner asked to include them.
+		if sortedByFee && !prioItem.forced &&
 			prioItem.feePerKB < int64(g.policy.TxMinFreeFee) &&
-			blockPlusTxWeight >= g.policy.BlockMinWeight {
+			(g.policy.FeeRateOnly ||
+				blockPlusTxWeight >= g.policy.BlockMinWeight) {
 			log <- cl.Tracec(func() string {
 				return fmt.Sprint(
 					"skipping tx ", tx.Hash(),
@@ -489,7 +587,7 @@ mempoolLoop:
 			continue
 		}
 		// Prioritize by fee per kilobyte once the block is larger than the priority size or there are no more high-priority transactions.
-		if !sortedByFee && (blockPlusTxWeight >= g.policy.BlockPrioritySize ||
+		if !sortedByFee && !prioItem.forced && (blockPlusTxWeight >= g.policy.BlockPrioritySize ||
 			prioItem.priority <= MinHighPriority) {
 			log <- cl.Tracef{
 				"switching to sort by fees per kilobyte " +
@@ -658,7 +756,8 @@ func (g *BlkTmplGenerator) UpdateBlockTime(msgBlock *wire.MsgBlock) error {
 }
 // UpdateExtraNonce updates the extra nonce in the coinbase script of the passed block by regenerating the coinbase script with the passed value and block height.  It also recalculates and updates the new merkle root that results from changing the coinbase script.
 func (g *BlkTmplGenerator) UpdateExtraNonce(msgBlock *wire.MsgBlock, blockHeight int32, extraNonce uint64) error {
-	coinbaseScript, err := standardCoinbaseScript(blockHeight, extraNonce)
+	coinbaseScript, err := standardCoinbaseScript(blockHeight, extraNonce,
+		g.CoinbaseTag())
 	if err != nil {
 		return err
 	}
@@ -676,6 +775,41 @@ func (g *BlkTmplGenerator) UpdateExtraNonce(msgBlock *wire.MsgBlock, blockHeight
 	msgBlock.Header.MerkleRoot = *merkles[len(merkles)-1]
 	return nil
 }
+// CoinbaseTag returns the tag added to the coinbase script of new blocks after the coinbase flags. This function is safe for concurrent access.
+func (g *BlkTmplGenerator) CoinbaseTag() string {
+	g.tagMtx.RLock()
+	defer g.tagMtx.RUnlock()
+	return g.coinbaseTag
+}
+// SetCoinbaseTag changes the tag added to the coinbase script of new blocks, or removes it when empty.  Templates generated before the change keep the old tag until their extra nonce is updated. This function is safe for concurrent access.
+func (g *BlkTmplGenerator) SetCoinbaseTag(tag string) error {
+	if err := CheckCoinbaseTag(tag); err != nil {
+		return err
+	}
+	g.tagMtx.Lock()
+	g.coinbaseTag = tag
+	g.tagMtx.Unlock()
+	return nil
+}
+// TxControls returns a copy of the transaction controls applied to every new block template, or nil if there are none. This function is safe for concurrent access.
+func (g *BlkTmplGenerator) TxControls() *TxControls {
+	g.ctlMtx.RLock()
+	defer g.ctlMtx.RUnlock()
+	return g.controls.Copy()
+}
+// SetTxControls changes the transaction controls applied to every new block template, or removes them when nil or empty. This function is safe for concurrent access.
+func (g *BlkTmplGenerator) SetTxControls(controls *TxControls) error {
+	if err := CheckTxControls(controls); err != nil {
+		return err
+	}
+	if controls.Empty() {
+		controls = nil
+	}
+	g.ctlMtx.Lock()
+	g.controls = controls.Copy()
+	g.ctlMtx.Unlock()
+	return nil
+}
 // BestSnapshot returns information about the current best chain block and related state as of the current point in time using the chain instance associated with the block template generator.  The returned state must be treated as immutable since it is shared by all callers. This function is safe for concurrent access.
 func (g *BlkTmplGenerator) BestSnapshot() *blockchain.BestState {
 	return g.chain.BestSnapshot()
@@ -1,8 +1,12 @@
 package mining
 import (
 	"container/heap"
+	"math"
 	"math/rand"
+	"strings"
 	"testing"
+	blockchain "git.parallelcoin.io/dev/9/pkg/chain"
+	chainhash "git.parallelcoin.io/dev/9/pkg/chain/hash"
 	"git.parallelcoin.io/dev/9/pkg/util"
 )
 // TestTxFeePrioHeap ensures the priority queue for transaction fees and priorities works as expected.
@@ -90,3 +94,59 @@ func TestTxFeePrioHeap(
 		highest = prioItem
 	}
 }
+// TestCoinbaseTag ensures the longest allowed coinbase tag fits in the coinbase script with the largest height and extra nonce and that longer ones are rejected.
+func TestCoinbaseTag(
+	t *testing.T) {
+	tag := strings.Repeat("x", MaxCoinbaseTagLen)
+	if err := CheckCoinbaseTag(tag); err != nil {
+		t.Fatalf("CheckCoinbaseTag: unexpected error for maximum length tag: %v", err)
+	}
+	if err := CheckCoinbaseTag(tag + "x"); err == nil {
+		t.Fatal("CheckCoinbaseTag: expected error for too long tag")
+	}
+	for _, extraNonce := range []uint64{0, math.MaxInt64, 1 << 63, math.MaxUint64} {
+		script, err := standardCoinbaseScript(math.MaxInt32, extraNonce, tag)
+		if err != nil {
+			t.Fatalf("standardCoinbaseScript: unexpected error: %v", err)
+		}
+		if len(script) > blockchain.MaxCoinbaseScriptLen {
+			t.Errorf("coinbase script with extra nonce %d is %d bytes, max %d",
+				extraNonce, len(script), blockchain.MaxCoinbaseScriptLen)
+		}
+	}
+	script, err := standardCoinbaseScript(1, 0, "")
+	if err != nil {
+		t.Fatalf("standardCoinbaseScript: unexpected error: %v", err)
+	}
+	if want := 1 + 1 + 1 + len(CoinbaseFlags); len(script) != want {
+		t.Errorf("coinbase script without tag is %d bytes, want %d", len(script), want)
+	}
+}
+// TestRaiseAncestorFeePerKB ensures the fee rate of a transaction together with its ancestors raises the fee rates of all of its ancestors that pay less, and no others.
+func TestRaiseAncestorFeePerKB(
+	t *testing.T) {
+	grandparent, parent, child, other := chainhash.Hash{1}, chainhash.Hash{2},
+		chainhash.Hash{3}, chainhash.Hash{4}
+	prioItems := map[chainhash.Hash]*txPrioItem{
+		grandparent: {feePerKB: 100},
+		parent: {feePerKB: 5000,
+			dependsOn: map[chainhash.Hash]struct{}{grandparent: {}}},
+		child: {feePerKB: 4000, ancestorFeePerKB: 3000,
+			dependsOn: map[chainhash.Hash]struct{}{parent: {}}},
+		other: {feePerKB: 200},
+	}
+	raiseAncestorFeePerKB(prioItems, prioItems[child],
+		prioItems[child].ancestorFeePerKB)
+	want := map[chainhash.Hash]int64{
+		grandparent: 3000,
+		parent:      5000,
+		child:       4000,
+		other:       200,
+	}
+	for hash, feePerKB := range want {
+		if prioItems[hash].feePerKB != feePerKB {
+			t.Errorf("fee rate of %v: got %d, want %d", hash,
+				prioItems[hash].feePerKB, feePerKB)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package mining
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+	"git.parallelcoin.io/dev/9/pkg/util"
+)
+// The rotation policies for choosing the payment address of each new block.
+const (
+	// RotateRandom picks any of the addresses with the same chance.
+	RotateRandom = "random"
+	// RotateRoundRobin picks the addresses in turn.
+	RotateRoundRobin = "roundrobin"
+	// RotateWeighted picks an address with a chance proportional to its weight.
+	RotateWeighted = "weighted"
+)
+// Rotations lists the names of the rotation policies.
+var Rotations = []string{RotateRandom, RotateRoundRobin, RotateWeighted}
+// PayAddr is a payment address along with its weight for the weighted rotation.
+type PayAddr struct {
+	Addr   util.Address
+	Weight float64
+}
+// PayAddrs is the set of payment addresses blocks are generated to, which can be changed while mining, and the policy for rotating between them. It is safe for concurrent access.
+type PayAddrs struct {
+	sync.Mutex
+	rotation string
+	addrs    []PayAddr
+	next     int
+	rand     *rand.Rand
+}
+// NewPayAddrs returns a set of payment addresses rotated with the named policy. Weights must be positive.
+func NewPayAddrs(
+	rotation string, addrs []PayAddr) (*PayAddrs, error) {
+	if err := CheckRotation(rotation); err != nil {
+		return nil, err
+	}
+	p := &PayAddrs{
+		rotation: rotation,
+		rand:     rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	for _, a := range addrs {
+		if err := p.Add(a.Addr, a.Weight); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+// CheckRotation returns an error if the passed name is not a rotation policy.
+func CheckRotation(
+	rotation string) error {
+	for _, r := range Rotations {
+		if rotation == r {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown payment address rotation '%s', must be one of %v",
+		rotation, Rotations)
+}
+// Rotation returns the name of the rotation policy.
+func (p *PayAddrs) Rotation() string {
+	return p.rotation
+}
+// Len returns the number of payment addresses.
+func (p *PayAddrs) Len() int {
+	p.Lock()
+	defer p.Unlock()
+	return len(p.addrs)
+}
+// List returns a copy of the payment addresses and their weights.
+func (p *PayAddrs) List() []PayAddr {
+	p.Lock()
+	defer p.Unlock()
+	return append([]PayAddr(nil), p.addrs...)
+}
+// Add adds a payment address with the passed weight, or sets the weight if the address is already in the set.
+func (p *PayAddrs) Add(addr util.Address, weight float64) error {
+	if weight <= 0 {
+		return fmt.Errorf("weight of payment address %s must be positive, not %v",
+			addr.EncodeAddress(), weight)
+	}
+	p.Lock()
+	defer p.Unlock()
+	if i := p.index(addr); i >= 0 {
+		p.addrs[i].Weight = weight
+		return nil
+	}
+	p.addrs = append(p.addrs, PayAddr{Addr: addr, Weight: weight})
+	return nil
+}
+// Remove removes a payment address, returning false if it was not in the set.
+func (p *PayAddrs) Remove(addr util.Address) bool {
+	p.Lock()
+	defer p.Unlock()
+	i := p.index(addr)
+	if i < 0 {
+		return false
+	}
+	p.addrs = append(p.addrs[:i], p.addrs[i+1:]...)
+	// Keep the round robin on the address that was due next.
+	if i < p.next {
+		p.next--
+	}
+	if p.next >= len(p.addrs) {
+		p.next = 0
+	}
+	return true
+}
+// Next returns the address to pay the next block to according to the rotation policy, or nil if there are none.
+func (p *PayAddrs) Next() util.Address {
+	p.Lock()
+	defer p.Unlock()
+	if len(p.addrs) == 0 {
+		return nil
+	}
+	switch p.rotation {
+	case RotateRoundRobin:
+		addr := p.addrs[p.next].Addr
+		p.next = (p.next + 1) % len(p.addrs)
+		return addr
+	case RotateWeighted:
+		var total float64
+		for _, a := range p.addrs {
+			total += a.Weight
+		}
+		r := p.rand.Float64() * total
+		for _, a := range p.addrs {
+			if r < a.Weight {
+				return a.Addr
+			}
+			r -= a.Weight
+		}
+		// Rounding can leave a sliver past the last address.
+		return p.addrs[len(p.addrs)-1].Addr
+	default:
+		return p.addrs[p.rand.Intn(len(p.addrs))].Addr
+	}
+}
+// index returns the position of the passed address in the set, or -1. It must be called with the lock held.
+func (p *PayAddrs) index(addr util.Address) int {
+	for i, a := range p.addrs {
+		if a.Addr.EncodeAddress() == addr.EncodeAddress() {
+			return i
+		}
+	}
+	return -1
+}
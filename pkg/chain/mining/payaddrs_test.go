@@ -0,0 +1,70 @@
+package mining
+import (
+	"testing"
+	chaincfg "git.parallelcoin.io/dev/9/pkg/chain/config"
+	"git.parallelcoin.io/dev/9/pkg/util"
+)
+// testPayAddr returns a distinct pay to pubkey hash address for each passed byte.
+func testPayAddr(
+	t *testing.T, b byte) util.Address {
+	pkHash := make([]byte, 20)
+	pkHash[0] = b
+	addr, err := util.NewAddressPubKeyHash(pkHash, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("NewAddressPubKeyHash: unexpected error: %v", err)
+	}
+	return addr
+}
+// TestPayAddrsRotation ensures each rotation policy picks the addresses as expected, including after addresses are added and removed.
+func TestPayAddrsRotation(
+	t *testing.T) {
+	a, b, c := testPayAddr(t, 1), testPayAddr(t, 2), testPayAddr(t, 3)
+	if _, err := NewPayAddrs("sometimes", nil); err == nil {
+		t.Fatal("NewPayAddrs: expected error for unknown rotation")
+	}
+	if _, err := NewPayAddrs(RotateRandom, []PayAddr{{a, 0}}); err == nil {
+		t.Fatal("NewPayAddrs: expected error for zero weight")
+	}
+	// Round robin visits the addresses in turn and keeps its place when the address before it is removed.
+	p, err := NewPayAddrs(RotateRoundRobin, []PayAddr{{a, 1}, {b, 1}, {c, 1}})
+	if err != nil {
+		t.Fatalf("NewPayAddrs: unexpected error: %v", err)
+	}
+	for i, want := range []util.Address{a, b, c, a} {
+		if got := p.Next(); got.EncodeAddress() != want.EncodeAddress() {
+			t.Fatalf("round robin pick %d is %v, want %v", i, got, want)
+		}
+	}
+	if !p.Remove(a) || p.Remove(a) {
+		t.Fatal("Remove: expected to remove the address only once")
+	}
+	for i, want := range []util.Address{b, c, b} {
+		if got := p.Next(); got.EncodeAddress() != want.EncodeAddress() {
+			t.Fatalf("round robin pick %d after remove is %v, want %v", i, got, want)
+		}
+	}
+	// Weighted picks follow the weights, and adding an address again only changes its weight.
+	p, err = NewPayAddrs(RotateWeighted, []PayAddr{{a, 1}, {b, 1}})
+	if err != nil {
+		t.Fatalf("NewPayAddrs: unexpected error: %v", err)
+	}
+	if err := p.Add(b, 9); err != nil {
+		t.Fatalf("Add: unexpected error: %v", err)
+	}
+	if p.Len() != 2 {
+		t.Fatalf("Len is %d after setting a weight, want 2", p.Len())
+	}
+	picks := make(map[string]int)
+	for i := 0; i < 10000; i++ {
+		picks[p.Next().EncodeAddress()]++
+	}
+	if n := picks[b.EncodeAddress()]; n < 8500 || n > 9500 {
+		t.Errorf("address with weight 9 of 10 picked %d times of 10000", n)
+	}
+	// An empty set has no address to pay to.
+	p.Remove(a)
+	p.Remove(b)
+	if p.Next() != nil {
+		t.Error("Next: expected nil from an empty set")
+	}
+}
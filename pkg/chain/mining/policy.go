@@ -20,8 +20,12 @@ type Policy struct {
 	BlockMaxSize uint32
 	// BlockPrioritySize is the size in bytes for high-priority / low-fee transactions to be used when generating a block template.
 	BlockPrioritySize uint32
+	// FeeRateOnly disables the high-priority area and the free transaction allowance below the minimum block size, so transactions are selected by fee per kilobyte alone and those paying less than TxMinFreeFee are never included.
+	FeeRateOnly bool
 	// TxMinFreeFee is the minimum fee in Satoshi/1000 bytes that is required for a transaction to be treated as free for mining purposes (block template generation).
 	TxMinFreeFee util.Amount
+	// CoinbaseTag is added to the coinbase script of generated blocks after the coinbase flags so the miner of a block can be identified.
+	CoinbaseTag string
 }
 // minInt is a helper function to return the minimum of two ints.  This avoids a math import and the need to cast to floats.
 func minInt(
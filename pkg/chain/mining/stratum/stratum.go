@@ -0,0 +1,877 @@
+package stratum
+import (
+	"bufio"
+	"bytes"
+	"crypto/subtle"
+	"encoding/binary"
+	"encoding/hex"
+	js "encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	blockchain "git.parallelcoin.io/dev/9/pkg/chain"
+	"git.parallelcoin.io/dev/9/pkg/chain/fork"
+	chainhash "git.parallelcoin.io/dev/9/pkg/chain/hash"
+	"git.parallelcoin.io/dev/9/pkg/chain/mining"
+	txscript "git.parallelcoin.io/dev/9/pkg/chain/tx/script"
+	"git.parallelcoin.io/dev/9/pkg/chain/wire"
+	"git.parallelcoin.io/dev/9/pkg/util"
+	cl "git.parallelcoin.io/dev/9/pkg/util/cl"
+)
+const (
+	// DefaultDifficulty is the share difficulty new stratum workers start at when none is configured for their algorithm.
+	DefaultDifficulty = 1.0
+	// DefaultShareTime is the default time between shares variable difficulty aims for.
+	DefaultShareTime = 10 * time.Second
+	// extraNonce1Size is the number of bytes of the coinbase extra nonce assigned to each session by the server.
+	extraNonce1Size = 4
+	// extraNonce2Size is the number of bytes of the coinbase extra nonce rolled by the miner.
+	extraNonce2Size = 4
+	// maxJobs is the number of recent jobs per algorithm that shares are still accepted for.
+	maxJobs = 8
+	// refreshInterval is how often the current jobs are checked for a new best block or stale transactions.
+	refreshInterval = time.Second
+	// retargetShares is the number of shares after which the difficulty of a worker is adjusted.
+	retargetShares = 8
+	// minDifficulty is the lowest share difficulty variable difficulty will set.
+	minDifficulty = 0.001
+	// idleTimeout is how long a session may stay silent before it is disconnected.
+	idleTimeout = 10 * time.Minute
+	// maxLineLen is the maximum length of a request line.
+	maxLineLen = 16384
+)
+// Stratum error codes as used by the common pool implementations.
+const (
+	errOther         = 20
+	errJobNotFound   = 21
+	errDuplicate     = 22
+	errLowDiff       = 23
+	errUnauthorized  = 24
+	errNotSubscribed = 25
+)
+// diff1Target is the share target at difficulty 1, following the convention of the standard sha256d miners.
+var diff1Target = func() *big.Int {
+	n, _ := new(big.Int).SetString(
+		"00000000ffff0000000000000000000000000000000000000000000000000000", 16)
+	return n
+}()
+// ErrNoPayAddrs is returned when stratum listeners are configured without mining addresses to pay the blocks to.
+var ErrNoPayAddrs = errors.New("stratum listeners are configured but there are no mining addresses")
+// Config is a descriptor containing the stratum server configuration.
+type Config struct {
+	// Listeners maps each algorithm to the addresses stratum miners for it connect to.
+	Listeners map[string][]string
+	// Difficulty maps each algorithm to the share difficulty new workers start at.
+	Difficulty map[string]float64
+	// ShareTime is the time between shares variable difficulty aims for. Zero disables variable difficulty.
+	ShareTime time.Duration
+	// Pass is the password workers must authorize with. An empty password authorizes every worker.
+	Pass string
+	// Generator creates the block templates jobs are made from.
+	Generator *mining.BlkTmplGenerator
+	// PayAddrs is the set of payment addresses the generated blocks pay to, one chosen by its rotation policy per job.
+	PayAddrs *mining.PayAddrs
+	// ProcessBlock is called with every block solved by a worker.
+	ProcessBlock func(*util.Block, blockchain.BehaviorFlags) (bool, error)
+	// IsCurrent reports whether the chain is synced. No jobs are handed out while it is not.
+	IsCurrent func() bool
+}
+// miningJob is a unit of work derived from a block template and handed out to the workers of one algorithm. The coinbase is split around the extra nonce so workers can roll it themselves.
+type miningJob struct {
+	id      string
+	algo    string
+	height  int32
+	block   *wire.MsgBlock
+	coinb1  []byte
+	coinb2  []byte
+	branch  []chainhash.Hash
+	target  *big.Int
+	created time.Time
+	shares  map[string]struct{}
+}
+// Server converts block templates into stratum v1 jobs for standard miners and turns their solutions back into blocks.
+type Server struct {
+	sync.Mutex
+	cfg          Config
+	listeners    []net.Listener
+	listenAlgo   map[net.Listener]string
+	jobs         map[string][]*miningJob
+	clients      map[*client]struct{}
+	lastTxUpdate map[string]time.Time
+	nextJobID    uint64
+	nextSession  uint32
+	quit         chan struct{}
+	wg           sync.WaitGroup
+}
+// client is a single connected stratum session.
+type client struct {
+	sync.Mutex
+	server      *Server
+	conn        net.Conn
+	algo        string
+	extraNonce1 []byte
+	subscribed  bool
+	authorized  bool
+	worker      string
+	difficulty  float64
+	prevDiff    float64
+	shares      int
+	retargeted  time.Time
+}
+// request is a request or notification received from a stratum client.
+type request struct {
+	ID     interface{}   `json:"id"`
+	Method string        `json:"method"`
+	Params []interface{} `json:"params"`
+}
+// response is the reply to a request.
+type response struct {
+	ID     interface{} `json:"id"`
+	Result interface{} `json:"result"`
+	Error  interface{} `json:"error"`
+}
+// notification is a message sent to a stratum client that is not a reply.
+type notification struct {
+	ID     interface{}   `json:"id"`
+	Method string        `json:"method"`
+	Params []interface{} `json:"params"`
+}
+// rpcError is an error returned to a stratum client.
+type rpcError struct {
+	code    int
+	message string
+}
+func (e *rpcError) Error() string {
+	return e.message
+}
+// ParseListeners parses listener entries of the form algo:address into a map of addresses by algorithm. An entry that does not start with an algorithm is an address for the miners of the default algorithm, which must then name one rather than a way of choosing between them such as random.
+func ParseListeners(entries []string, algo string) (map[string][]string, error) {
+	out := make(map[string][]string)
+	for _, entry := range entries {
+		parts := strings.SplitN(entry, ":", 2)
+		if len(parts) == 2 && fork.IsAlgo(parts[0]) {
+			if parts[1] == "" {
+				return nil, fmt.Errorf("stratum listener %q has no address", entry)
+			}
+			out[parts[0]] = append(out[parts[0]], parts[1])
+			continue
+		}
+		if !fork.IsAlgo(algo) {
+			return nil, fmt.Errorf("stratum listener %q is not of the form algo:address and the default algorithm %q is not an algorithm", entry, algo)
+		}
+		out[algo] = append(out[algo], entry)
+	}
+	return out, nil
+}
+// ParseDifficulties parses difficulty entries of the form algo:difficulty into a map of difficulties by algorithm.
+func ParseDifficulties(entries []string) (map[string]float64, error) {
+	out := make(map[string]float64)
+	for _, entry := range entries {
+		parts := strings.SplitN(entry, ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("stratum difficulty %q is not of the form algo:difficulty", entry)
+		}
+		if !fork.IsAlgo(parts[0]) {
+			return nil, fmt.Errorf("stratum difficulty %q has unknown algorithm %q", entry, parts[0])
+		}
+		diff, err := strconv.ParseFloat(parts[1], 64)
+		if err != nil || diff < minDifficulty {
+			return nil, fmt.Errorf("stratum difficulty %q must be a number of at least %v", entry, minDifficulty)
+		}
+		out[parts[0]] = diff
+	}
+	return out, nil
+}
+// New returns a stratum server listening on the configured addresses.
+func New(cfg *Config) (*Server, error) {
+	s := &Server{
+		cfg:          *cfg,
+		listenAlgo:   make(map[net.Listener]string),
+		jobs:         make(map[string][]*miningJob),
+		clients:      make(map[*client]struct{}),
+		lastTxUpdate: make(map[string]time.Time),
+		quit:         make(chan struct{}),
+	}
+	for algo, addrs := range cfg.Listeners {
+		for _, addr := range addrs {
+			listener, err := net.Listen("tcp", addr)
+			if err != nil {
+				for _, l := range s.listeners {
+					l.Close()
+				}
+				return nil, fmt.Errorf("unable to listen for stratum on %s: %v", addr, err)
+			}
+			s.listeners = append(s.listeners, listener)
+			s.listenAlgo[listener] = algo
+		}
+	}
+	return s, nil
+}
+// Start begins accepting stratum connections and generating jobs.
+func (
+	s *Server,
+) Start() {
+	for _, listener := range s.listeners {
+		log <- cl.Info{"stratum server listening for", s.listenAlgo[listener], "miners on", listener.Addr()}
+		s.wg.Add(1)
+		go s.listenHandler(listener)
+	}
+	s.wg.Add(1)
+	go s.jobHandler()
+}
+// Stop closes the listeners and every session and waits for the stratum goroutines to exit.
+func (
+	s *Server,
+) Stop() {
+	close(s.quit)
+	for _, listener := range s.listeners {
+		listener.Close()
+	}
+	s.Lock()
+	for c := range s.clients {
+		c.conn.Close()
+	}
+	s.Unlock()
+	s.wg.Wait()
+}
+// listenHandler accepts connections on the passed listener until it is closed. It must be run as a goroutine.
+func (
+	s *Server,
+) listenHandler(
+	listener net.Listener) {
+	defer s.wg.Done()
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			select {
+			case <-s.quit:
+			default:
+				log <- cl.Error{"stratum listener", listener.Addr(), "failed:", err}
+			}
+			return
+		}
+		s.Lock()
+		s.nextSession++
+		c := &client{
+			server:      s,
+			conn:        conn,
+			algo:        s.listenAlgo[listener],
+			extraNonce1: make([]byte, extraNonce1Size),
+			difficulty:  s.initialDifficulty(s.listenAlgo[listener]),
+			retargeted:  time.Now(),
+		}
+		binary.BigEndian.PutUint32(c.extraNonce1, s.nextSession)
+		c.prevDiff = c.difficulty
+		s.clients[c] = struct{}{}
+		s.Unlock()
+		s.wg.Add(1)
+		go c.inHandler()
+	}
+}
+// initialDifficulty returns the share difficulty new workers of the passed algorithm start at.
+func (
+	s *Server,
+) initialDifficulty(
+	algo string) float64 {
+	if diff, ok := s.cfg.Difficulty[algo]; ok {
+		return diff
+	}
+	return DefaultDifficulty
+}
+// jobHandler periodically replaces the jobs of every algorithm that became stale and sends them to the workers. It must be run as a goroutine.
+func (
+	s *Server,
+) jobHandler() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+	for {
+		s.refreshJobs()
+		select {
+		case <-ticker.C:
+		case <-s.quit:
+			return
+		}
+	}
+}
+// refreshJobs creates a new job for every algorithm whose current job builds on a stale block or whose transactions have been outdated for a minute, and lowers the difficulty of idle workers.
+func (
+	s *Server,
+) refreshJobs() {
+	if !s.cfg.IsCurrent() {
+		return
+	}
+	best := s.cfg.Generator.BestSnapshot()
+	lastTxUpdate := s.cfg.Generator.TxSource().LastUpdated()
+	algos := make(map[string]struct{})
+	for _, algo := range s.listenAlgo {
+		algos[algo] = struct{}{}
+	}
+	for algo := range algos {
+		s.Lock()
+		current := s.currentJob(algo)
+		txUpdated := s.lastTxUpdate[algo]
+		s.Unlock()
+		clean := current == nil || !current.block.Header.PrevBlock.IsEqual(&best.Hash)
+		if !clean && (lastTxUpdate.Equal(txUpdated) ||
+			time.Since(current.created) < time.Minute) {
+			continue
+		}
+		job, err := s.newJob(algo)
+		if err != nil {
+			log <- cl.Error{"failed to create stratum job for", algo, ":", err}
+			continue
+		}
+		s.Lock()
+		if clean {
+			s.jobs[algo] = nil
+		}
+		s.jobs[algo] = append(s.jobs[algo], job)
+		if len(s.jobs[algo]) > maxJobs {
+			s.jobs[algo] = s.jobs[algo][1:]
+		}
+		s.lastTxUpdate[algo] = lastTxUpdate
+		clients := s.clientsFor(algo)
+		s.Unlock()
+		for _, c := range clients {
+			c.sendJob(job, clean)
+		}
+	}
+	if s.cfg.ShareTime > 0 {
+		s.Lock()
+		clients := make([]*client, 0, len(s.clients))
+		for c := range s.clients {
+			clients = append(clients, c)
+		}
+		s.Unlock()
+		for _, c := range clients {
+			c.retargetIdle()
+		}
+	}
+}
+// currentJob returns the newest job of the passed algorithm or nil if there is none. This function MUST be called with the server lock held.
+func (
+	s *Server,
+) currentJob(
+	algo string) *miningJob {
+	jobs := s.jobs[algo]
+	if len(jobs) == 0 {
+		return nil
+	}
+	return jobs[len(jobs)-1]
+}
+// findJob returns the job with the passed id of the passed algorithm or nil if it is no longer current. This function MUST be called with the server lock held.
+func (
+	s *Server,
+) findJob(
+	algo, id string) *miningJob {
+	for _, job := range s.jobs[algo] {
+		if job.id == id {
+			return job
+		}
+	}
+	return nil
+}
+// clientsFor returns the authorized sessions mining the passed algorithm. This function MUST be called with the server lock held.
+func (
+	s *Server,
+) clientsFor(
+	algo string) (clients []*client) {
+	for c := range s.clients {
+		c.Lock()
+		ready := c.authorized && c.algo == algo
+		c.Unlock()
+		if ready {
+			clients = append(clients, c)
+		}
+	}
+	return
+}
+// newJob creates a job for the passed algorithm from a new block template paying to the next mining address.
+func (
+	s *Server,
+) newJob(
+	algo string) (*miningJob, error) {
+	payToAddr := s.cfg.PayAddrs.Next()
+	if payToAddr == nil {
+		return nil, ErrNoPayAddrs
+	}
+	template, err := s.cfg.Generator.NewBlockTemplate(payToAddr, algo)
+	if err != nil {
+		return nil, err
+	}
+	msgBlock := template.Block
+	coinb1, coinb2, err := splitCoinbase(msgBlock.Transactions[0], template.Height,
+		s.cfg.Generator.CoinbaseTag())
+	if err != nil {
+		return nil, err
+	}
+	s.Lock()
+	s.nextJobID++
+	id := strconv.FormatUint(s.nextJobID, 16)
+	s.Unlock()
+	return &miningJob{
+		id:      id,
+		algo:    algo,
+		height:  template.Height,
+		block:   msgBlock,
+		coinb1:  coinb1,
+		coinb2:  coinb2,
+		branch:  merkleBranch(msgBlock.Transactions[1:]),
+		target:  blockchain.CompactToBig(msgBlock.Header.Bits),
+		created: time.Now(),
+		shares:  make(map[string]struct{}),
+	}, nil
+}
+// splitCoinbase returns the serialization of the passed coinbase transaction, without witness data, split around a coinbase script extra nonce of the size stratum sessions fill in, with the coinbase tag after the coinbase flags.
+func splitCoinbase(coinbase *wire.MsgTx, height int32, tag string) (coinb1, coinb2 []byte, err error) {
+	heightScript, err := txscript.NewScriptBuilder().AddInt64(int64(height)).Script()
+	if err != nil {
+		return nil, nil, err
+	}
+	extraNonce := make([]byte, extraNonce1Size+extraNonce2Size)
+	builder := txscript.NewScriptBuilder().AddInt64(int64(height)).
+		AddData(extraNonce).AddData([]byte(mining.CoinbaseFlags))
+	if tag != "" {
+		builder.AddData([]byte(tag))
+	}
+	script, err := builder.Script()
+	if err != nil {
+		return nil, nil, err
+	}
+	tx := coinbase.Copy()
+	tx.TxIn[0].SignatureScript = script
+	var buf bytes.Buffer
+	if err := tx.SerializeNoWitness(&buf); err != nil {
+		return nil, nil, err
+	}
+	// The extra nonce follows the version, the input count, the previous outpoint, the script length, the height and the push opcode of the extra nonce.
+	offset := 4 + wire.VarIntSerializeSize(uint64(len(tx.TxIn))) +
+		chainhash.HashSize + 4 + wire.VarIntSerializeSize(uint64(len(script))) +
+		len(heightScript) + 1
+	serialized := buf.Bytes()
+	return serialized[:offset], serialized[offset+len(extraNonce):], nil
+}
+// merkleBranch returns the hashes the coinbase hash has to be combined with, in order, to compute the merkle root of a block with the passed transactions after the coinbase.
+func merkleBranch(txs []*wire.MsgTx) []chainhash.Hash {
+	level := make([]*chainhash.Hash, len(txs))
+	for i, tx := range txs {
+		hash := tx.TxHash()
+		level[i] = &hash
+	}
+	var branch []chainhash.Hash
+	for len(level) > 0 {
+		branch = append(branch, *level[0])
+		rest := level[1:]
+		next := make([]*chainhash.Hash, 0, (len(rest)+1)/2)
+		for i := 0; i < len(rest); i += 2 {
+			right := rest[i]
+			if i+1 < len(rest) {
+				right = rest[i+1]
+			}
+			next = append(next, blockchain.HashMerkleBranches(rest[i], right))
+		}
+		level = next
+	}
+	return branch
+}
+// merkleRoot returns the merkle root of a block with the passed coinbase hash and merkle branch.
+func merkleRoot(coinbaseHash chainhash.Hash, branch []chainhash.Hash) chainhash.Hash {
+	root := &coinbaseHash
+	for i := range branch {
+		root = blockchain.HashMerkleBranches(root, &branch[i])
+	}
+	return *root
+}
+// shareTarget returns the share target for the passed algorithm and difficulty. The scrypt miners count difficulty 65536 times lower than the others.
+func shareTarget(algo string, diff float64) *big.Int {
+	diff1 := new(big.Int).Set(diff1Target)
+	if algo == "scrypt" {
+		diff1.Lsh(diff1, 16)
+	}
+	target, _ := new(big.Float).Quo(new(big.Float).SetInt(diff1),
+		big.NewFloat(diff)).Int(nil)
+	return target
+}
+// prevHash encodes a previous block hash the way stratum miners expect it, with the byte order of each 32 bit word swapped.
+func prevHash(hash *chainhash.Hash) string {
+	var swapped chainhash.Hash
+	for i := 0; i < chainhash.HashSize; i += 4 {
+		binary.BigEndian.PutUint32(swapped[i:], binary.LittleEndian.Uint32(hash[i:]))
+	}
+	return hex.EncodeToString(swapped[:])
+}
+// inHandler reads and handles the requests of the session until it disconnects. It must be run as a goroutine.
+func (
+	c *client,
+) inHandler() {
+	s := c.server
+	defer s.wg.Done()
+	defer func() {
+		c.conn.Close()
+		s.Lock()
+		delete(s.clients, c)
+		s.Unlock()
+	}()
+	scanner := bufio.NewScanner(c.conn)
+	scanner.Buffer(make([]byte, 0, 1024), maxLineLen)
+	for {
+		c.conn.SetReadDeadline(time.Now().Add(idleTimeout))
+		if !scanner.Scan() {
+			return
+		}
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var req request
+		if err := js.Unmarshal(line, &req); err != nil {
+			log <- cl.Debug{"malformed stratum request from", c.conn.RemoteAddr(), ":", err}
+			return
+		}
+		result, err := c.handleRequest(&req)
+		resp := &response{ID: req.ID, Result: result}
+		if err != nil {
+			code := errOther
+			if serr, ok := err.(*rpcError); ok {
+				code = serr.code
+			}
+			resp.Result = nil
+			resp.Error = []interface{}{code, err.Error(), nil}
+		}
+		if err := c.send(resp); err != nil {
+			return
+		}
+		if req.Method == "mining.authorize" && err == nil && result == true {
+			c.sendDifficulty()
+			s.Lock()
+			job := s.currentJob(c.algo)
+			s.Unlock()
+			if job != nil {
+				c.sendJob(job, true)
+			}
+		}
+	}
+}
+// handleRequest dispatches a request to its handler.
+func (
+	c *client,
+) handleRequest(
+	req *request) (interface{}, error) {
+	switch req.Method {
+	case "mining.subscribe":
+		return c.handleSubscribe()
+	case "mining.authorize":
+		return c.handleAuthorize(req.Params)
+	case "mining.submit":
+		return c.handleSubmit(req.Params)
+	case "mining.extranonce.subscribe", "mining.suggest_difficulty":
+		return true, nil
+	default:
+		return nil, &rpcError{errOther, "unknown method " + req.Method}
+	}
+}
+// handleSubscribe handles mining.subscribe, returning the session extra nonce and the size of the extra nonce the miner rolls.
+func (
+	c *client,
+) handleSubscribe() (interface{}, error) {
+	c.Lock()
+	c.subscribed = true
+	c.Unlock()
+	session := hex.EncodeToString(c.extraNonce1)
+	return []interface{}{
+		[][]string{
+			{"mining.set_difficulty", session},
+			{"mining.notify", session},
+		},
+		session,
+		extraNonce2Size,
+	}, nil
+}
+// handleAuthorize handles mining.authorize, checking the password against the mining password.
+func (
+	c *client,
+) handleAuthorize(
+	params []interface{}) (interface{}, error) {
+	args, err := stringParams(params, 1)
+	if err != nil {
+		return nil, err
+	}
+	pass := ""
+	if len(args) > 1 {
+		pass = args[1]
+	}
+	expected := c.server.cfg.Pass
+	if expected != "" &&
+		subtle.ConstantTimeCompare([]byte(pass), []byte(expected)) != 1 {
+		log <- cl.Warn{"stratum worker", args[0], "from", c.conn.RemoteAddr(), "failed to authorize"}
+		return false, nil
+	}
+	c.Lock()
+	c.authorized = true
+	c.worker = args[0]
+	c.Unlock()
+	log <- cl.Debug{"stratum worker", args[0], "authorized for", c.algo, "from", c.conn.RemoteAddr()}
+	return true, nil
+}
+// handleSubmit handles mining.submit, checking the share against the session difficulty and submitting the block when it also meets the network target.
+func (
+	c *client,
+) handleSubmit(
+	params []interface{}) (interface{}, error) {
+	args, err := stringParams(params, 5)
+	if err != nil {
+		return nil, err
+	}
+	c.Lock()
+	subscribed, authorized := c.subscribed, c.authorized
+	diff := c.difficulty
+	if c.prevDiff < diff {
+		diff = c.prevDiff
+	}
+	c.Unlock()
+	if !subscribed {
+		return nil, &rpcError{errNotSubscribed, "not subscribed"}
+	}
+	if !authorized {
+		return nil, &rpcError{errUnauthorized, "unauthorized worker"}
+	}
+	extraNonce2, err := hex.DecodeString(args[2])
+	if err != nil || len(extraNonce2) != extraNonce2Size {
+		return nil, &rpcError{errOther, "invalid extranonce2"}
+	}
+	ntime, err := strconv.ParseUint(args[3], 16, 32)
+	if err != nil {
+		return nil, &rpcError{errOther, "invalid ntime"}
+	}
+	nonce, err := strconv.ParseUint(args[4], 16, 32)
+	if err != nil {
+		return nil, &rpcError{errOther, "invalid nonce"}
+	}
+	s := c.server
+	s.Lock()
+	job := s.findJob(c.algo, args[1])
+	if job == nil {
+		s.Unlock()
+		return nil, &rpcError{errJobNotFound, "job not found"}
+	}
+	key := hex.EncodeToString(c.extraNonce1) + args[2] + args[3] + args[4]
+	if _, ok := job.shares[key]; ok {
+		s.Unlock()
+		return nil, &rpcError{errDuplicate, "duplicate share"}
+	}
+	job.shares[key] = struct{}{}
+	s.Unlock()
+	timestamp := time.Unix(int64(ntime), 0)
+	if timestamp.Before(job.block.Header.Timestamp) ||
+		timestamp.After(time.Now().Add(blockchain.MaxTimeOffsetSeconds*time.Second)) {
+		return nil, &rpcError{errOther, "ntime out of range"}
+	}
+	block, err := c.buildBlock(job, extraNonce2, timestamp, uint32(nonce))
+	if err != nil {
+		return nil, err
+	}
+	hash := block.Header.BlockHashWithAlgos(job.height)
+	hashNum := blockchain.HashToBig(&hash)
+	if hashNum.Cmp(shareTarget(job.algo, diff)) > 0 {
+		return nil, &rpcError{errLowDiff, "low difficulty share"}
+	}
+	c.recordShare()
+	if hashNum.Cmp(job.target) <= 0 {
+		c.submitBlock(job, block)
+	}
+	return true, nil
+}
+// buildBlock returns the block of the passed job with the coinbase and header completed by a share.
+func (
+	c *client,
+) buildBlock(
+	job *miningJob, extraNonce2 []byte, timestamp time.Time,
+	nonce uint32) (*wire.MsgBlock, error) {
+	serialized := make([]byte, 0, len(job.coinb1)+extraNonce1Size+
+		extraNonce2Size+len(job.coinb2))
+	serialized = append(serialized, job.coinb1...)
+	serialized = append(serialized, c.extraNonce1...)
+	serialized = append(serialized, extraNonce2...)
+	serialized = append(serialized, job.coinb2...)
+	coinbase := new(wire.MsgTx)
+	if err := coinbase.DeserializeNoWitness(bytes.NewReader(serialized)); err != nil {
+		return nil, &rpcError{errOther, "invalid coinbase"}
+	}
+	// The witness nonce of the template is kept as the witness commitment depends on it.
+	coinbase.TxIn[0].Witness = job.block.Transactions[0].TxIn[0].Witness
+	block := &wire.MsgBlock{
+		Header:       job.block.Header,
+		Transactions: make([]*wire.MsgTx, len(job.block.Transactions)),
+	}
+	copy(block.Transactions, job.block.Transactions)
+	block.Transactions[0] = coinbase
+	block.Header.MerkleRoot = merkleRoot(coinbase.TxHash(), job.branch)
+	block.Header.Timestamp = timestamp
+	block.Header.Nonce = nonce
+	return block, nil
+}
+// submitBlock processes a block solved by the session like any block from the network, which relays it if it is accepted.
+func (
+	c *client,
+) submitBlock(
+	job *miningJob, msgBlock *wire.MsgBlock) {
+	block := util.NewBlock(msgBlock)
+	block.SetHeight(job.height)
+	isOrphan, err := c.server.cfg.ProcessBlock(block, blockchain.BFNone)
+	if err != nil {
+		log <- cl.Warn{"block from stratum worker", c.worker, "rejected:", err}
+		return
+	}
+	if isOrphan {
+		log <- cl.Debug{"block from stratum worker", c.worker, "is an orphan"}
+		return
+	}
+	log <- cl.Infof{
+		"stratum worker %s found %s block %d %s",
+		c.worker, job.algo, job.height, block.Hash(),
+	}
+}
+// recordShare counts an accepted share and adjusts the difficulty once enough shares have been counted to measure the share rate.
+func (
+	c *client,
+) recordShare() {
+	if c.server.cfg.ShareTime <= 0 {
+		return
+	}
+	c.Lock()
+	now := time.Now()
+	c.shares++
+	if c.shares < retargetShares {
+		c.Unlock()
+		return
+	}
+	average := now.Sub(c.retargeted) / time.Duration(c.shares)
+	changed := c.retarget(float64(c.server.cfg.ShareTime)/float64(average), now)
+	c.Unlock()
+	if changed {
+		c.sendDifficulty()
+	}
+}
+// retargetIdle lowers the difficulty of a session that has not found enough shares to be retargeted for a long time.
+func (
+	c *client,
+) retargetIdle() {
+	c.Lock()
+	now := time.Now()
+	idle := now.Sub(c.retargeted)
+	if !c.authorized || idle < 4*retargetShares*c.server.cfg.ShareTime {
+		c.Unlock()
+		return
+	}
+	changed := c.retarget(0.5, now)
+	c.Unlock()
+	if changed {
+		c.sendDifficulty()
+	}
+}
+// retarget scales the session difficulty by the passed ratio, limited to a factor of four per step, and starts a new measurement. It returns whether the difficulty changed. This function MUST be called with the session lock held.
+func (
+	c *client,
+) retarget(
+	ratio float64, now time.Time) bool {
+	c.shares = 0
+	c.retargeted = now
+	if ratio > 0.8 && ratio < 1.25 {
+		return false
+	}
+	if ratio > 4 {
+		ratio = 4
+	} else if ratio < 0.25 {
+		ratio = 0.25
+	}
+	diff := c.difficulty * ratio
+	if diff < minDifficulty {
+		diff = minDifficulty
+	}
+	if diff == c.difficulty {
+		return false
+	}
+	c.prevDiff = c.difficulty
+	c.difficulty = diff
+	return true
+}
+// sendDifficulty sends the current session difficulty.
+func (
+	c *client,
+) sendDifficulty() {
+	c.Lock()
+	diff := c.difficulty
+	c.Unlock()
+	c.send(&notification{
+		Method: "mining.set_difficulty",
+		Params: []interface{}{diff},
+	})
+}
+// sendJob sends the passed job, telling the miner to drop its current work if clean is set. Once a job is sent the previous difficulty no longer applies.
+func (
+	c *client,
+) sendJob(
+	job *miningJob, clean bool) {
+	c.Lock()
+	c.prevDiff = c.difficulty
+	c.Unlock()
+	header := &job.block.Header
+	branch := make([]string, len(job.branch))
+	for i := range job.branch {
+		branch[i] = hex.EncodeToString(job.branch[i][:])
+	}
+	c.send(&notification{
+		Method: "mining.notify",
+		Params: []interface{}{
+			job.id,
+			prevHash(&header.PrevBlock),
+			hex.EncodeToString(job.coinb1),
+			hex.EncodeToString(job.coinb2),
+			branch,
+			fmt.Sprintf("%08x", uint32(header.Version)),
+			fmt.Sprintf("%08x", header.Bits),
+			fmt.Sprintf("%08x", uint32(header.Timestamp.Unix())),
+			clean,
+		},
+	})
+}
+// send writes a message to the session as a line of JSON.
+func (
+	c *client,
+) send(
+	msg interface{}) error {
+	b, err := js.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	c.Lock()
+	defer c.Unlock()
+	c.conn.SetWriteDeadline(time.Now().Add(idleTimeout))
+	_, err = c.conn.Write(append(b, '\n'))
+	return err
+}
+// stringParams returns the passed request parameters as strings, requiring at least min of them.
+func stringParams(params []interface{}, min int) ([]string, error) {
+	if len(params) < min {
+		return nil, &rpcError{errOther, "not enough parameters"}
+	}
+	out := make([]string, len(params))
+	for i, p := range params {
+		switch v := p.(type) {
+		case string:
+			out[i] = v
+		case nil:
+		default:
+			if i < min {
+				return nil, &rpcError{errOther, fmt.Sprintf("parameter %d is not a string", i)}
+			}
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,107 @@
+package stratum
+import (
+	"bytes"
+	"testing"
+	blockchain "git.parallelcoin.io/dev/9/pkg/chain"
+	chainhash "git.parallelcoin.io/dev/9/pkg/chain/hash"
+	"git.parallelcoin.io/dev/9/pkg/chain/wire"
+	"git.parallelcoin.io/dev/9/pkg/util"
+)
+// testCoinbase returns a coinbase transaction for use by the stratum tests.
+func testCoinbase() *wire.MsgTx {
+	tx := wire.NewMsgTx(wire.TxVersion)
+	tx.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: *wire.NewOutPoint(&chainhash.Hash{}, wire.MaxPrevOutIndex),
+		SignatureScript:  []byte{0x51},
+		Sequence:         wire.MaxTxInSequenceNum,
+	})
+	tx.AddTxOut(&wire.TxOut{Value: 5000000000, PkScript: []byte{0x51}})
+	return tx
+}
+// TestStratumMerkleBranch ensures the merkle root computed from the coinbase hash and the stratum merkle branch matches the merkle root of the whole block.
+func TestStratumMerkleBranch(
+	t *testing.T,
+) {
+	for numTxs := 1; numTxs <= 7; numTxs++ {
+		txs := []*wire.MsgTx{testCoinbase()}
+		for i := 1; i < numTxs; i++ {
+			tx := wire.NewMsgTx(wire.TxVersion)
+			tx.AddTxIn(&wire.TxIn{
+				PreviousOutPoint: *wire.NewOutPoint(&chainhash.Hash{byte(i)}, 0),
+			})
+			tx.AddTxOut(&wire.TxOut{Value: int64(i)})
+			txs = append(txs, tx)
+		}
+		block := util.NewBlock(&wire.MsgBlock{Transactions: txs})
+		merkles := blockchain.BuildMerkleTreeStore(block.Transactions(), false)
+		want := *merkles[len(merkles)-1]
+		got := merkleRoot(txs[0].TxHash(), merkleBranch(txs[1:]))
+		if got != want {
+			t.Errorf("%d transactions: merkle root %v, want %v", numTxs, got, want)
+		}
+	}
+}
+// TestStratumCoinbase ensures the split coinbase joined with the extra nonces deserializes to the coinbase with the extra nonces in its script.
+func TestStratumCoinbase(
+	t *testing.T,
+) {
+	coinb1, coinb2, err := splitCoinbase(testCoinbase(), 300000, "tag")
+	if err != nil {
+		t.Fatalf("splitCoinbase: %v", err)
+	}
+	extraNonce := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+	serialized := append(append(append([]byte{}, coinb1...), extraNonce...), coinb2...)
+	var tx wire.MsgTx
+	if err := tx.DeserializeNoWitness(bytes.NewReader(serialized)); err != nil {
+		t.Fatalf("DeserializeNoWitness: %v", err)
+	}
+	if !bytes.Contains(tx.TxIn[0].SignatureScript, append([]byte{8}, extraNonce...)) {
+		t.Fatalf("coinbase script %x does not push the extra nonce",
+			tx.TxIn[0].SignatureScript)
+	}
+	if !bytes.HasSuffix(tx.TxIn[0].SignatureScript, []byte("\x03tag")) {
+		t.Fatalf("coinbase script %x does not end with the coinbase tag",
+			tx.TxIn[0].SignatureScript)
+	}
+	if tx.TxOut[0].Value != 5000000000 {
+		t.Fatalf("coinbase output value %d, want 5000000000", tx.TxOut[0].Value)
+	}
+}
+// TestStratumTarget ensures share targets scale inversely with difficulty and follow the scrypt miner convention.
+func TestStratumTarget(
+	t *testing.T,
+) {
+	if shareTarget("sha256d", 1).Cmp(diff1Target) != 0 {
+		t.Errorf("sha256d difficulty 1 target %x, want %x",
+			shareTarget("sha256d", 1), diff1Target)
+	}
+	half := shareTarget("sha256d", 2)
+	if half.Lsh(half, 1).Cmp(diff1Target) != 0 {
+		t.Errorf("sha256d difficulty 2 target is not half of difficulty 1")
+	}
+	scrypt := shareTarget("scrypt", 65536)
+	if scrypt.Cmp(diff1Target) != 0 {
+		t.Errorf("scrypt difficulty 65536 target %x, want %x", scrypt, diff1Target)
+	}
+}
+// TestParseListeners ensures listeners are served for the algorithm they name, and those that name none for the default algorithm if it is one.
+func TestParseListeners(
+	t *testing.T,
+) {
+	listeners, err := ParseListeners([]string{"scrypt:127.0.0.1:3333", "127.0.0.1:3334", ":3335", "sha256d::3336"}, "sha256d")
+	if err != nil {
+		t.Fatalf("ParseListeners: %v", err)
+	}
+	if got := listeners["scrypt"]; len(got) != 1 || got[0] != "127.0.0.1:3333" {
+		t.Errorf("scrypt listeners %v, want [127.0.0.1:3333]", got)
+	}
+	if got := listeners["sha256d"]; len(got) != 3 || got[0] != "127.0.0.1:3334" || got[1] != ":3335" || got[2] != ":3336" {
+		t.Errorf("sha256d listeners %v, want [127.0.0.1:3334 :3335 :3336]", got)
+	}
+	if _, err := ParseListeners([]string{"127.0.0.1:3333"}, "random"); err == nil {
+		t.Error("a listener without an algorithm was accepted when the default is random")
+	}
+	if _, err := ParseListeners([]string{"scrypt:"}, "sha256d"); err == nil {
+		t.Error("a listener without an address was accepted")
+	}
+}
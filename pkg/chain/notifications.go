@@ -1,6 +1,7 @@
 package chain
 import (
 	"fmt"
+	chainhash "git.parallelcoin.io/dev/9/pkg/chain/hash"
 )
 // NotificationType represents the type of a notification message.
 type NotificationType int
@@ -14,12 +15,15 @@ const (
 	NTBlockConnected
 	// NTBlockDisconnected indicates the associated block was disconnected from the main chain.
 	NTBlockDisconnected
+	// NTChainReorganized indicates the main chain was reorganized onto another branch, after each block that was disconnected and connected was notified.
+	NTChainReorganized
 )
 // notificationTypeStrings is a map of notification types back to their constant names for pretty printing.
 var notificationTypeStrings = map[NotificationType]string{
 	NTBlockAccepted:     "NTBlockAccepted",
 	NTBlockConnected:    "NTBlockConnected",
 	NTBlockDisconnected: "NTBlockDisconnected",
+	NTChainReorganized:  "NTChainReorganized",
 }
 // String returns the NotificationType in human-readable form.
 func (n NotificationType) String() string {
@@ -32,10 +36,29 @@ func (n NotificationType) String() string {
 // 	- NTBlockAccepted:     *util.Block
 // 	- NTBlockConnected:    *util.Block
 // 	- NTBlockDisconnected: *util.Block
+// 	- NTChainReorganized:  *Reorganization
 type Notification struct {
 	Type NotificationType
 	Data interface{}
 }
+// Reorganization describes a reorganization of the main chain from the old tip to the new one through the block where their branches fork.
+type Reorganization struct {
+	OldTip    chainhash.Hash
+	OldHeight int32
+	NewTip    chainhash.Hash
+	NewHeight int32
+	Fork      chainhash.Hash
+	// ForkHeight is the height of the last block the branches have in common.
+	ForkHeight int32
+	// Disconnected is the blocks taken off the main chain, from the old tip down.
+	Disconnected []chainhash.Hash
+	// Connected is the blocks put on the main chain, up to the new tip.
+	Connected []chainhash.Hash
+}
+// Depth returns how many blocks were taken off the main chain.
+func (r *Reorganization) Depth() int32 {
+	return int32(len(r.Disconnected))
+}
 // Subscribe to block chain notifications. Registers a callback to be executed when various events take place. See the documentation on Notification and NotificationType for details on the types and contents of notifications.
 func (b *BlockChain) Subscribe(callback NotificationCallback) {
 	b.notificationsLock.Lock()
@@ -19,7 +19,7 @@ const (
 	// BFNone is a convenience value to specifically indicate no flags.
 	BFNone BehaviorFlags = 0
 )
-// ProcessBlock is the main workhorse for handling insertion of new blocks into the block chain.  It includes functionality such as rejecting duplicate blocks, ensuring blocks follow all rules, orphan handling, and insertion into the block chain along with best chain selection and reorganization. When no errors occurred during processing, the first return value indicates whether or not the block is on the main chain and the second indicates whether or not the block is an orphan. This function is safe for concurrent access.
+// ProcessBlock is the main workhorse for handling insertion of new blocks into the block chain.  It includes functionality such as rejecting duplicate blocks, ensuring blocks follow all rules, orphan handling, and insertion into the block chain along with best chain selection and reorganization. When no errors occurred during processing, the first return value indicates whether or not the block is on the main chain and the second indicates whether or not the block is an orphan. When the block fails validation and there is an invalid block directory a report of it is written there. This function is safe for concurrent access.
 func (
 	b *BlockChain,
 ) ProcessBlock(
@@ -30,6 +30,24 @@ func (
 	bool,
 	bool,
 	error,
+) {
+	isMainChain, isOrphan, err := b.processBlock(block, flags, height)
+	if err != nil && b.invalidBlockDir != "" {
+		b.dumpInvalidBlock(block, err)
+	}
+	return isMainChain, isOrphan, err
+}
+// processBlock does the work of ProcessBlock. This function is safe for concurrent access.
+func (
+	b *BlockChain,
+) processBlock(
+	block *util.Block,
+	flags BehaviorFlags,
+	height int32,
+) (
+	bool,
+	bool,
+	error,
 ) {
 	blockHeight := height
 	bb, _ := b.BlockByHash(&block.MsgBlock().Header.PrevBlock)
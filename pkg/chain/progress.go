@@ -0,0 +1,31 @@
+package chain
+import (
+	"time"
+	"git.parallelcoin.io/dev/9/pkg/chain/fork"
+)
+// VerificationProgress estimates how much of the chain the node has verified at the given time, as the share of the transactions up to its best block in all the transactions expected by then. Unlike comparing heights it does not depend on knowing the headers of the chain, and it weighs blocks by the transactions that make up most of the work of verifying them. The expected total is extrapolated at the rate of the chain tx data of the network when the best block is before it, and otherwise from the best block at that rate, or when the network has no chain tx data at the rate of the chain so far, with blocks at the target spacing of the latest hard fork. This function is safe for concurrent access.
+func (b *BlockChain) VerificationProgress(now time.Time) float64 {
+	best := b.BestSnapshot()
+	node := b.Index.LookupNode(&best.Hash)
+	if node == nil || best.TotalTxns == 0 {
+		return 0
+	}
+	data := b.chainParams.ChainTxData
+	txRate := data.TxRate
+	if txRate == 0 {
+		txPerBlock := float64(best.TotalTxns) / float64(best.Height+1)
+		txRate = txPerBlock / fork.List[len(fork.List)-1].TargetTimePerBlock.Seconds()
+	}
+	total, since := float64(best.TotalTxns), node.timestamp
+	if best.TotalTxns <= data.TxCount {
+		total, since = float64(data.TxCount), data.Time
+	}
+	if elapsed := now.Unix() - since; elapsed > 0 {
+		total += float64(elapsed) * txRate
+	}
+	progress := float64(best.TotalTxns) / total
+	if progress > 1 {
+		progress = 1
+	}
+	return progress
+}
@@ -0,0 +1,38 @@
+package chain
+import (
+	"math"
+	"testing"
+	"time"
+	chaincfg "git.parallelcoin.io/dev/9/pkg/chain/config"
+	"git.parallelcoin.io/dev/9/pkg/chain/fork"
+)
+// TestVerificationProgress ensures the progress is the share of the transactions up to the best block in those expected by the given time, at the rate of the chain tx data of the network or of the chain itself when there is none.
+func TestVerificationProgress(
+	t *testing.T) {
+	params := chaincfg.MainNetParams
+	chain := newFakeChain(&params)
+	tip := chain.bestChain.Tip()
+	tipTime := time.Unix(tip.timestamp, 0)
+	// The chain so far has 10 transactions a block, so at the target spacing another 10 blocks are expected by the time they take.
+	spacing := fork.List[len(fork.List)-1].TargetTimePerBlock
+	chain.stateSnapshot = &BestState{Hash: tip.hash, Height: 0, TotalTxns: 10}
+	tests := []struct {
+		data     chaincfg.ChainTxData
+		now      time.Time
+		progress float64
+	}{
+		{chaincfg.ChainTxData{}, tipTime, 1},
+		{chaincfg.ChainTxData{}, tipTime.Add(spacing), 0.5},
+		{chaincfg.ChainTxData{}, tipTime.Add(-spacing), 1},
+		{chaincfg.ChainTxData{Time: tip.timestamp + 100, TxCount: 30, TxRate: 0.1}, tipTime.Add(100 * time.Second), 10.0 / 30},
+		{chaincfg.ChainTxData{Time: tip.timestamp + 100, TxCount: 30, TxRate: 0.1}, tipTime.Add(300 * time.Second), 10.0 / 50},
+		{chaincfg.ChainTxData{Time: tip.timestamp - 100, TxCount: 5, TxRate: 0.1}, tipTime.Add(100 * time.Second), 10.0 / 20},
+	}
+	for i, test := range tests {
+		params.ChainTxData = test.data
+		progress := chain.VerificationProgress(test.now)
+		if math.Abs(progress-test.progress) > 1e-9 {
+			t.Errorf("test %d: progress is %v, want %v", i, progress, test.progress)
+		}
+	}
+}
@@ -25,6 +25,8 @@ type txValidator struct {
 	flags        txscript.ScriptFlags
 	sigCache     *txscript.SigCache
 	hashCache    *txscript.HashCache
+	// workers is how many goroutines validate the inputs, zero for the default of three per processor core.
+	workers int
 }
 // sendResult sends the result of a script pair validation on the internal result channel while respecting the quit channel.  This allows orderly shutdown when the validation process is aborted early due to a validation error in one of the other goroutines.
 func (v *txValidator) sendResult(result error) {
@@ -104,6 +106,9 @@ func (v *txValidator) Validate(items []*txValidateItem) error {
 	}
 	// Limit the number of goroutines to do script validation based on the number of processor cores.  This helps ensure the system stays reasonably responsive under heavy load.
 	maxGoRoutines := runtime.NumCPU() * 3
+	if v.workers > 0 {
+		maxGoRoutines = v.workers
+	}
 	if maxGoRoutines <= 0 {
 		maxGoRoutines = 1
 	}
@@ -198,6 +203,13 @@ func checkBlockScripts(
 	block *util.Block, utxoView *UtxoViewpoint,
 	scriptFlags txscript.ScriptFlags, sigCache *txscript.SigCache,
 	hashCache *txscript.HashCache) error {
+	return checkBlockScriptsWorkers(block, utxoView, scriptFlags, sigCache, hashCache, 0)
+}
+// checkBlockScriptsWorkers is checkBlockScripts with the inputs validated by the given number of goroutines, or the default number for zero.
+func checkBlockScriptsWorkers(
+	block *util.Block, utxoView *UtxoViewpoint,
+	scriptFlags txscript.ScriptFlags, sigCache *txscript.SigCache,
+	hashCache *txscript.HashCache, workers int) error {
 	// First determine if segwit is active according to the scriptFlags. If it isn't then we don't need to interact with the HashCache.
 	segwitActive := scriptFlags&txscript.ScriptVerifyWitness == txscript.ScriptVerifyWitness
 	// Collect all of the transaction inputs and required information for validation for all transactions in the block into a single slice.
@@ -236,8 +248,14 @@ func checkBlockScripts(
 			txValItems = append(txValItems, txVI)
 		}
 	}
+	// Check the signatures of the standard single signature inputs in one batch, so the script engines find them in the signature cache.  A cache for the block alone is used when there is no shared one.
+	if sigCache == nil {
+		sigCache = txscript.NewSigCache(uint(numInputs))
+	}
+	batchVerifySigs(txValItems, utxoView, scriptFlags, sigCache)
 	// Validate all of the inputs.
 	validator := newTxValidator(utxoView, scriptFlags, sigCache, hashCache)
+	validator.workers = workers
 	start := time.Now()
 	if err := validator.Validate(txValItems); err != nil {
 		return err
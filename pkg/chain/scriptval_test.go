@@ -3,7 +3,12 @@ import (
 	"fmt"
 	"runtime"
 	"testing"
+	chaincfg "git.parallelcoin.io/dev/9/pkg/chain/config"
+	chainhash "git.parallelcoin.io/dev/9/pkg/chain/hash"
 	txscript "git.parallelcoin.io/dev/9/pkg/chain/tx/script"
+	"git.parallelcoin.io/dev/9/pkg/chain/wire"
+	"git.parallelcoin.io/dev/9/pkg/util"
+	ec "git.parallelcoin.io/dev/9/pkg/util/elliptic"
 )
 // TestCheckBlockScripts ensures that validating the all of the scripts in a known-good block doesn't return an error.
 func TestCheckBlockScripts(
@@ -37,3 +42,93 @@ func TestCheckBlockScripts(
 		return
 	}
 }
+// TestBatchVerifySigs ensures the signatures checked in a batch are cached when they are valid and not when they are not, and that a block with an invalid signature still fails script validation.
+func TestBatchVerifySigs(
+	t *testing.T) {
+	privKey, err := ec.NewPrivateKey(ec.S256())
+	if err != nil {
+		t.Fatalf("NewPrivateKey: %v", err)
+	}
+	addr, err := util.NewAddressPubKeyHash(
+		util.Hash160(privKey.PubKey().SerializeCompressed()),
+		&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("NewAddressPubKeyHash: %v", err)
+	}
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		t.Fatalf("PayToAddrScript: %v", err)
+	}
+	// Spend enough outputs for the batch to be spread across cores.
+	const numInputs = 40
+	prevTx := wire.NewMsgTx(wire.TxVersion)
+	prevTx.AddTxIn(wire.NewTxIn(&wire.OutPoint{}, nil, nil))
+	for i := 0; i < numInputs; i++ {
+		prevTx.AddTxOut(wire.NewTxOut(1000, pkScript))
+	}
+	view := NewUtxoViewpoint()
+	view.AddTxOuts(util.NewTx(prevTx), 1)
+	tx := wire.NewMsgTx(wire.TxVersion)
+	for i := 0; i < numInputs; i++ {
+		tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Hash: prevTx.TxHash(),
+			Index: uint32(i)}, nil, nil))
+	}
+	tx.AddTxOut(wire.NewTxOut(numInputs*1000, pkScript))
+	for i := range tx.TxIn {
+		tx.TxIn[i].SignatureScript, err = txscript.SignatureScript(tx, i,
+			pkScript, txscript.SigHashAll, privKey, true)
+		if err != nil {
+			t.Fatalf("SignatureScript: %v", err)
+		}
+	}
+	// Corrupt one signature, keeping its encoding valid.
+	const corrupted = 25
+	pushes, _ := txscript.PushedData(tx.TxIn[corrupted].SignatureScript)
+	sig := pushes[0]
+	sig[len(sig)-2] ^= 1
+	tx.TxIn[corrupted].SignatureScript, _ = txscript.NewScriptBuilder().
+		AddData(sig).AddData(pushes[1]).Script()
+	block := util.NewBlock(&wire.MsgBlock{Transactions: []*wire.MsgTx{tx}})
+	items := make([]*txValidateItem, numInputs)
+	for i, txIn := range tx.TxIn {
+		items[i] = &txValidateItem{txInIndex: i, txIn: txIn,
+			tx: block.Transactions()[0]}
+	}
+	scriptFlags := txscript.ScriptBip16 | txscript.ScriptVerifyDERSignatures
+	sigCache := txscript.NewSigCache(numInputs)
+	batchVerifySigs(items, view, scriptFlags, sigCache)
+	for i, txVI := range items {
+		item, ok := batchItem(txVI, view, scriptFlags)
+		if !ok {
+			t.Fatalf("input %d is not checked in a batch", i)
+		}
+		var sigHash chainhash.Hash
+		copy(sigHash[:], item.Hash)
+		if cached := sigCache.Exists(sigHash, item.Signature,
+			item.PubKey); cached == (i == corrupted) {
+			t.Errorf("input %d: cached %v", i, cached)
+		}
+	}
+	err = checkBlockScripts(block, view, scriptFlags, sigCache, nil)
+	if err == nil {
+		t.Errorf("block with an invalid signature passed validation")
+	}
+	// Without the corrupted input the block passes, with or without a shared signature cache.
+	tx.TxIn = append(tx.TxIn[:corrupted], tx.TxIn[corrupted+1:]...)
+	tx.TxOut[0].Value -= 1000
+	for i := range tx.TxIn {
+		tx.TxIn[i].SignatureScript, err = txscript.SignatureScript(tx, i,
+			pkScript, txscript.SigHashAll, privKey, true)
+		if err != nil {
+			t.Fatalf("SignatureScript: %v", err)
+		}
+	}
+	block = util.NewBlock(&wire.MsgBlock{Transactions: []*wire.MsgTx{tx}})
+	for _, cache := range []*txscript.SigCache{sigCache, nil} {
+		err = checkBlockScripts(block, view, scriptFlags, cache, nil)
+		if err != nil {
+			t.Errorf("block with valid signatures failed validation: %v",
+				err)
+		}
+	}
+}
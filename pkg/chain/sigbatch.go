@@ -0,0 +1,109 @@
+package chain
+import (
+	chainhash "git.parallelcoin.io/dev/9/pkg/chain/hash"
+	txscript "git.parallelcoin.io/dev/9/pkg/chain/tx/script"
+	ec "git.parallelcoin.io/dev/9/pkg/util/elliptic"
+)
+// batchVerifySigs checks the signatures of the single signature pay to pubkey hash and pay to witness pubkey hash inputs of the passed items together with ec.VerifyBatch, spread across every core, and adds those that are valid to the signature cache, so the script engines validating the inputs find them there instead of checking them one at a time. Inputs of other kinds, and signatures that are already cached, malformed or invalid, are left to the script engines, which reject the inputs that are not valid.
+func batchVerifySigs(
+	items []*txValidateItem, utxoView *UtxoViewpoint,
+	flags txscript.ScriptFlags, sigCache *txscript.SigCache) {
+	batch := make([]ec.BatchItem, 0, len(items))
+	for _, txVI := range items {
+		item, ok := batchItem(txVI, utxoView, flags)
+		if !ok {
+			continue
+		}
+		var sigHash chainhash.Hash
+		copy(sigHash[:], item.Hash)
+		if sigCache.Exists(sigHash, item.Signature, item.PubKey) {
+			continue
+		}
+		batch = append(batch, item)
+	}
+	for len(batch) > 0 {
+		invalid := ec.VerifyBatch(batch)
+		valid := batch
+		if invalid != -1 {
+			valid = batch[:invalid]
+		}
+		for i := range valid {
+			var sigHash chainhash.Hash
+			copy(sigHash[:], valid[i].Hash)
+			sigCache.Add(sigHash, valid[i].Signature, valid[i].PubKey)
+		}
+		if invalid == -1 {
+			break
+		}
+		batch = batch[invalid+1:]
+	}
+}
+// batchItem returns the signature check of the input of the passed item when it spends a pay to pubkey hash output with a signature script, or a pay to witness pubkey hash output with a witness, of a signature and a public key. The signature hash is computed over the same script as the script engine computes it over.
+func batchItem(
+	txVI *txValidateItem, utxoView *UtxoViewpoint,
+	flags txscript.ScriptFlags) (ec.BatchItem, bool) {
+	utxo := utxoView.LookupEntry(txVI.txIn.PreviousOutPoint)
+	if utxo == nil || utxo.IsSpent() {
+		return ec.BatchItem{}, false
+	}
+	pkScript := utxo.PkScript()
+	msgTx := txVI.tx.MsgTx()
+	var sigBytes, pkBytes, hash []byte
+	var err error
+	switch {
+	case txscript.GetScriptClass(pkScript) == txscript.PubKeyHashTy:
+		pushes, err := txscript.PushedData(txVI.txIn.SignatureScript)
+		if err != nil || len(pushes) != 2 || len(pushes[0]) == 0 {
+			return ec.BatchItem{}, false
+		}
+		sigBytes, pkBytes = pushes[0], pushes[1]
+		hashType := txscript.SigHashType(sigBytes[len(sigBytes)-1])
+		hash, err = txscript.CalcSignatureHash(pkScript, hashType, msgTx,
+			txVI.txInIndex)
+		if err != nil {
+			return ec.BatchItem{}, false
+		}
+	case txVI.sigHashes != nil && txscript.IsPayToWitnessPubKeyHash(pkScript):
+		witness := txVI.txIn.Witness
+		if len(txVI.txIn.SignatureScript) != 0 || len(witness) != 2 ||
+			len(witness[0]) == 0 {
+			return ec.BatchItem{}, false
+		}
+		sigBytes, pkBytes = witness[0], witness[1]
+		_, program, err := txscript.ExtractWitnessProgramInfo(pkScript)
+		if err != nil {
+			return ec.BatchItem{}, false
+		}
+		// The script code of a pay to witness pubkey hash input is the pay to pubkey hash script of its program.
+		script, err := txscript.NewScriptBuilder().AddOp(txscript.OpDup).
+			AddOp(txscript.OpHash160).AddData(program).
+			AddOp(txscript.OpEqualVerify).AddOp(txscript.OpCheckSig).Script()
+		if err != nil {
+			return ec.BatchItem{}, false
+		}
+		hashType := txscript.SigHashType(sigBytes[len(sigBytes)-1])
+		hash, err = txscript.CalcWitnessSigHash(script, txVI.sigHashes,
+			hashType, msgTx, txVI.txInIndex, utxo.Amount())
+		if err != nil {
+			return ec.BatchItem{}, false
+		}
+	default:
+		return ec.BatchItem{}, false
+	}
+	pubKey, err := ec.ParsePubKey(pkBytes, ec.S256())
+	if err != nil {
+		return ec.BatchItem{}, false
+	}
+	var signature *ec.Signature
+	sigBytes = sigBytes[:len(sigBytes)-1]
+	if flags&(txscript.ScriptVerifyStrictEncoding|
+		txscript.ScriptVerifyDERSignatures) != 0 {
+		signature, err = ec.ParseDERSignature(sigBytes, ec.S256())
+	} else {
+		signature, err = ec.ParseSignature(sigBytes, ec.S256())
+	}
+	if err != nil {
+		return ec.BatchItem{}, false
+	}
+	return ec.BatchItem{Signature: signature, Hash: hash, PubKey: pubKey}, true
+}
@@ -24,4 +24,6 @@ type Config struct {
 	DisableCheckpoints bool
 	MaxPeers           int
 	FeeEstimator       *mempool.FeeEstimator
+	// ImportedHeaders are the hashes of the blocks of a chain imported from a headers file, that at index i being the block at height i+1, which are downloaded without downloading their headers first.
+	ImportedHeaders []chainhash.Hash
 }
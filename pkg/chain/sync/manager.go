@@ -39,6 +39,18 @@ type SyncManager struct {
 	headerList       *list.List
 	startHeader      *list.Element
 	nextCheckpoint   *chaincfg.Checkpoint
+	// The following fields are used for downloading the headers up to the assume valid block after the final checkpoint, so the chain knows which blocks are its ancestors before it has the block itself.
+	assumeValidMode    bool
+	assumeValidKnown   bool
+	assumeValidHeaders []*chainhash.Hash
+	assumeValidLocator blockchain.BlockLocator
+	// The following fields are used for downloading the blocks of headers imported from a file in headers-first mode, with the last of them the sync peer has standing in for a checkpoint, instead of downloading their headers first.
+	importedHeaders    []chainhash.Hash
+	importedMode       bool
+	importedFastHeight int32
+	// The following fields are used for downloading the blocks before the utxo snapshot the chain state was loaded from once the chain is current, for the chain to validate them in the background.
+	snapshotBlocks  map[chainhash.Hash]struct{}
+	snapshotInvalid bool
 	// An optional fee estimator.
 	feeEstimator *mempool.FeeEstimator
 }
@@ -116,6 +128,8 @@ const (
 	maxRequestedBlocks = wire.MaxInvPerMsg
 	// maxRequestedTxns is the maximum number of requested transactions hashes to store in memory.
 	maxRequestedTxns = wire.MaxInvPerMsg
+	// snapshotBlocksInFlight is the number of blocks before the utxo snapshot the chain state was loaded from that are requested at once for the chain to validate.
+	snapshotBlocksInFlight = 16
 )
 // zeroHash is the zero value hash (all zeros)
 var zeroHash chainhash.Hash
@@ -433,6 +447,14 @@ func (
 			return
 		}
 	}
+	// Blocks before the utxo snapshot are validated by the chain apart from the blocks it connects.
+	if _, exists = sm.snapshotBlocks[*blockHash]; exists {
+		delete(state.requestedBlocks, *blockHash)
+		delete(sm.requestedBlocks, *blockHash)
+		delete(sm.snapshotBlocks, *blockHash)
+		sm.handleSnapshotBlock(peer, bmsg.block)
+		return
+	}
 	// When in headers-first mode, if the block matches the hash of the first header in the list of headers that are being fetched, it's eligible for less validation since the headers have already been verified to link together and are valid up to the next checkpoint. Also, remove the list entry for all blocks except the checkpoint since it is needed to verify the next round of headers links properly.
 	isCheckpointBlock := false
 	behaviorFlags := blockchain.BFNone
@@ -441,7 +463,10 @@ func (
 		if firstNodeEl != nil {
 			firstNode := firstNodeEl.Value.(*headerNode)
 			if blockHash.IsEqual(firstNode.hash) {
-				behaviorFlags |= blockchain.BFFastAdd
+				// Imported headers only show the blocks are a chain with enough proof of work, so only the blocks up to the last checkpoint among them get less validation.
+				if !sm.importedMode || firstNode.height <= sm.importedFastHeight {
+					behaviorFlags |= blockchain.BFFastAdd
+				}
 				if firstNode.hash.IsEqual(sm.nextCheckpoint.Hash) {
 					isCheckpointBlock = true
 				} else {
@@ -531,6 +556,8 @@ func (
 				peer)
 		}
 	}
+	// Once the chain is current, the blocks before the utxo snapshot it was loaded from are downloaded for it to validate.
+	sm.fetchSnapshotBlocks()
 	// Nothing more to do if we aren't in headers-first mode.
 	if !sm.headersFirstMode {
 		return
@@ -544,6 +571,7 @@ func (
 		return
 	}
 	// This is headers-first mode and the block is a checkpoint.  When there is a next checkpoint, get the next round of headers by asking for headers starting from the block after this one up to the next checkpoint.
+	sm.importedMode = false
 	prevHeight := sm.nextCheckpoint.Height
 	prevHash := sm.nextCheckpoint.Hash
 	sm.nextCheckpoint = sm.findNextHeaderCheckpoint(prevHeight)
@@ -569,7 +597,7 @@ func (
 		"reached the final checkpoint -- switching to normal mode",
 	)
 	locator := blockchain.BlockLocator([]*chainhash.Hash{blockHash})
-	err = peer.PushGetBlocksMsg(locator, &zeroHash)
+	err = sm.fetchBlocks(peer, locator)
 	if err != nil {
 		log <- cl.Warn{
 			"failed to send getblocks message to peer", peer, ":", err,
@@ -577,6 +605,136 @@ func (
 		return
 	}
 }
+// handleSnapshotBlock has the chain validate a block before the utxo snapshot its chain state was loaded from, and requests the next blocks once those requested have arrived. A block that is not valid means the chain state loaded from the snapshot is not valid, so no more are requested, and a block that fails for other reasons, such as arriving out of order, is requested again.
+func (
+	sm *SyncManager,
+) handleSnapshotBlock(
+	peer *peerpkg.Peer, block *util.Block) {
+	if err := sm.chain.ValidateSnapshotBlock(block); err != nil {
+		if _, ok := err.(blockchain.RuleError); ok {
+			log <- cl.Errorf{
+				"block %v before the utxo snapshot from %s is not valid, the chain state loaded from the snapshot is not valid: %v",
+				block.Hash(), peer, err,
+			}
+			sm.snapshotInvalid = true
+		} else {
+			log <- cl.Warnf{
+				"failed to validate block %v before the utxo snapshot from %s: %v", block.Hash(), peer, err,
+			}
+		}
+	}
+	sm.fetchSnapshotBlocks()
+}
+// fetchSnapshotBlocks requests the next blocks before the utxo snapshot the chain state was loaded from for the chain to validate from the sync peer, when the chain is current and none of them are in flight.
+func (
+	sm *SyncManager,
+) fetchSnapshotBlocks() {
+	if sm.snapshotInvalid || len(sm.snapshotBlocks) > 0 || sm.syncPeer == nil || !sm.current() {
+		return
+	}
+	hashes := sm.chain.NextSnapshotBlocks(snapshotBlocksInFlight)
+	if len(hashes) == 0 {
+		return
+	}
+	syncPeerState := sm.peerStates[sm.syncPeer]
+	gdmsg := wire.NewMsgGetDataSizeHint(uint(len(hashes)))
+	for _, hash := range hashes {
+		iv := wire.NewInvVect(wire.InvTypeBlock, hash)
+		if sm.syncPeer.IsWitnessEnabled() {
+			iv.Type = wire.InvTypeWitnessBlock
+		}
+		sm.requestedBlocks[*hash] = struct{}{}
+		syncPeerState.requestedBlocks[*hash] = struct{}{}
+		sm.snapshotBlocks[*hash] = struct{}{}
+		gdmsg.AddInvVect(iv)
+	}
+	sm.syncPeer.QueueMessage(gdmsg, nil)
+}
+// fetchBlocks starts downloading the blocks after the locator in normal mode. When there is an assume valid block the chain does not have and whose ancestors it has not been told of, the headers up to it are downloaded first, and the blocks after they are handed to the chain.
+func (
+	sm *SyncManager,
+) fetchBlocks(
+	peer *peerpkg.Peer, locator blockchain.BlockLocator) error {
+	assumeValid := sm.chain.AssumeValid()
+	if assumeValid != nil && !sm.assumeValidKnown &&
+		sm.chainParams != &chaincfg.RegressionNetParams {
+		if have, err := sm.chain.HaveBlock(assumeValid); err == nil && !have {
+			sm.assumeValidMode = true
+			sm.assumeValidHeaders = nil
+			sm.assumeValidLocator = locator
+			log <- cl.Infof{
+				"downloading headers up to assume valid block %s from peer %s",
+				assumeValid, peer.Addr(),
+			}
+			return peer.PushGetHeadersMsg(locator, assumeValid)
+		}
+	}
+	return peer.PushGetBlocksMsg(locator, &zeroHash)
+}
+// handleAssumeValidHeaders handles the headers requested by fetchBlocks, checking that each links to the one before, the first to a block the chain has. When the assume valid block is among them the hashes of all of them are handed to the chain, and when the peer runs out of headers before it the blocks are downloaded with their scripts run as usual.
+func (
+	sm *SyncManager,
+) handleAssumeValidHeaders(
+	peer *peerpkg.Peer, headers []*wire.BlockHeader) {
+	assumeValid := sm.chain.AssumeValid()
+	for _, header := range headers {
+		blockHash := header.BlockHash()
+		linked := false
+		if n := len(sm.assumeValidHeaders); n > 0 {
+			linked = sm.assumeValidHeaders[n-1].IsEqual(&header.PrevBlock)
+		} else {
+			linked, _ = sm.chain.HaveBlock(&header.PrevBlock)
+		}
+		if !linked {
+			log <- cl.Warn{
+				"received block header that does not properly connect to the chain from peer",
+				peer,
+				"-- disconnecting",
+			}
+			peer.Disconnect()
+			return
+		}
+		sm.assumeValidHeaders = append(sm.assumeValidHeaders, &blockHash)
+		if blockHash.IsEqual(assumeValid) {
+			sm.chain.AssumeValidChain(sm.assumeValidHeaders)
+			sm.assumeValidKnown = true
+			log <- cl.Infof{
+				"assume valid block %s is %d blocks ahead, not running the scripts of the blocks up to it",
+				assumeValid, len(sm.assumeValidHeaders),
+			}
+			sm.endAssumeValidHeaders(peer)
+			return
+		}
+	}
+	if len(headers) < wire.MaxBlockHeadersPerMsg {
+		log <- cl.Warnf{
+			"peer %s does not have assume valid block %s on its chain, running the scripts of all blocks",
+			peer.Addr(), assumeValid,
+		}
+		sm.endAssumeValidHeaders(peer)
+		return
+	}
+	locator := blockchain.BlockLocator(
+		[]*chainhash.Hash{sm.assumeValidHeaders[len(sm.assumeValidHeaders)-1]})
+	err := peer.PushGetHeadersMsg(locator, assumeValid)
+	if err != nil {
+		log <- cl.Warnf{"failed to send getheaders message to peer %s: %v", peer, err}
+	}
+}
+// endAssumeValidHeaders leaves the download of the headers up to the assume valid block and starts downloading the blocks from where it started.
+func (
+	sm *SyncManager,
+) endAssumeValidHeaders(
+	peer *peerpkg.Peer) {
+	sm.assumeValidMode = false
+	sm.assumeValidHeaders = nil
+	err := peer.PushGetBlocksMsg(sm.assumeValidLocator, &zeroHash)
+	if err != nil {
+		log <- cl.Warn{
+			"failed to send getblocks message to peer", peer, ":", err,
+		}
+	}
+}
 // handleBlockchainNotification handles notifications from blockchain.  It does things such as request orphan block parents and relay accepted blocks to connected peers.
 func (
 	sm *SyncManager,
@@ -611,7 +769,7 @@ func (
 		// Remove all of the transactions (except the coinbase) in the connected block from the transaction pool.  Secondly, remove any transactions which are now double spends as a result of these new transactions.  Finally, remove any transaction that is no longer an orphan. Transactions which depend on a confirmed transaction are NOT removed recursively because they are still valid.
 		for _, tx := range block.Transactions()[1:] {
 			// log<-cl.Debugf{"removing tx %d", i}
-			sm.txMemPool.RemoveTransaction(tx, false)
+			sm.txMemPool.RemoveMinedTransaction(tx, block.Hash())
 			// log<-cl.Debug{emoved transaction}
 			sm.txMemPool.RemoveDoubleSpends(tx)
 			// log<-cl.Debug{emoved double spends}
@@ -680,10 +838,13 @@ func (
 	// TODO: we could possibly here check which peers have these blocks and request them now to speed things up a little.
 	for blockHash := range state.requestedBlocks {
 		delete(sm.requestedBlocks, blockHash)
+		delete(sm.snapshotBlocks, blockHash)
 	}
 	// Attempt to find a new peer to sync from if the quitting peer is the sync peer.  Also, reset the headers-first state if in headers-first mode so
 	if sm.syncPeer == peer {
 		sm.syncPeer = nil
+		sm.assumeValidMode = false
+		sm.assumeValidHeaders = nil
 		if sm.headersFirstMode {
 			best := sm.chain.BestSnapshot()
 			sm.resetHeaderState(&best.Hash, best.Height)
@@ -705,6 +866,10 @@ func (
 	// The remote peer is misbehaving if we didn't request headers.
 	msg := hmsg.headers
 	numHeaders := len(msg.Headers)
+	if sm.assumeValidMode && peer == sm.syncPeer {
+		sm.handleAssumeValidHeaders(peer, msg.Headers)
+		return
+	}
 	if !sm.headersFirstMode {
 		log <- cl.Warnf{
 			"got %d unrequested headers from %s -- disconnecting",
@@ -1104,6 +1269,10 @@ func (
 ) resetHeaderState(
 	newestHash *chainhash.Hash, newestHeight int32) {
 	sm.headersFirstMode = false
+	if sm.importedMode {
+		sm.importedMode = false
+		sm.nextCheckpoint = sm.findNextHeaderCheckpoint(newestHeight)
+	}
 	sm.headerList.Init()
 	sm.startHeader = nil
 	// When there is a next checkpoint, add an entry for the latest known block into the header pool.  This allows the next downloaded header to prove it links to the chain properly.
@@ -1165,7 +1334,17 @@ func (
 		// When the current height is less than a known checkpoint we can use block headers to learn about which blocks comprise the chain up to the checkpoint and perform less validation for them.  This is possible since each header contains the hash of the previous header and a merkle root.
 		// Therefore if we validate all of the received headers link together properly and the checkpoint hashes match, we can be sure the hashes for the blocks in between are accurate.  Further, once the full blocks are downloaded, the merkle root is computed and compared against the value in the header which proves the full block hasn't been tampered with.
 		// Once we have passed the final checkpoint, or checkpoints are disabled, use standard inv messages learn about the blocks and fully validate them.  Finally, regression test mode does not support the headers-first approach so do normal block downloads when in regression test mode.
-		if sm.nextCheckpoint != nil &&
+		if sm.startImportedHeaders(bestPeer, best) {
+			sm.syncPeer = bestPeer
+			log <- cl.Infof{
+				"downloading blocks %d to %d of the imported headers from peer %s",
+				best.Height + 1,
+				sm.nextCheckpoint.Height,
+				bestPeer.Addr(),
+			}
+			sm.progressLogger.SetLastLogTime(time.Now())
+			sm.fetchHeaderBlocks()
+		} else if sm.nextCheckpoint != nil &&
 			best.Height < sm.nextCheckpoint.Height &&
 			sm.chainParams != &chaincfg.RegressionNetParams {
 			bestPeer.PushGetHeadersMsg(locator, sm.nextCheckpoint.Hash)
@@ -1177,13 +1356,80 @@ func (
 				bestPeer.Addr(),
 			}
 		} else {
-			bestPeer.PushGetBlocksMsg(locator, &zeroHash)
+			sm.fetchBlocks(bestPeer, locator)
 		}
 		sm.syncPeer = bestPeer
+		sm.fetchSnapshotBlocks()
 	} else {
 		log <- cl.Wrn("no sync peer candidates available")
 	}
 }
+// startImportedHeaders sets up headers-first mode for downloading the blocks of the imported headers after the best block, which must be one of them, up to the last of them the peer has, which stands in for the next checkpoint. It returns false when there are no such blocks.
+func (
+	sm *SyncManager,
+) startImportedHeaders(
+	peer *peerpkg.Peer, best *blockchain.BestState) bool {
+	if sm.chainParams == &chaincfg.RegressionNetParams {
+		return false
+	}
+	last := int32(len(sm.importedHeaders))
+	if peer.LastBlock() < last {
+		last = peer.LastBlock()
+	}
+	if best.Height >= last ||
+		best.Height > 0 && !sm.importedHeaders[best.Height-1].IsEqual(&best.Hash) {
+		return false
+	}
+	sm.headerList.Init()
+	sm.startHeader = nil
+	for height := best.Height + 1; height <= last; height++ {
+		node := headerNode{height: height, hash: &sm.importedHeaders[height-1]}
+		e := sm.headerList.PushBack(&node)
+		if sm.startHeader == nil {
+			sm.startHeader = e
+		}
+	}
+	sm.nextCheckpoint = &chaincfg.Checkpoint{Height: last, Hash: &sm.importedHeaders[last-1]}
+	sm.headersFirstMode = true
+	sm.importedMode = true
+	return true
+}
+// useImportedHeaders keeps the hashes of the imported headers for startSync when they pass through the checkpoints of the chain, and when the assume valid block is among them tells the chain its ancestors, so their headers need not be downloaded.
+func (
+	sm *SyncManager,
+) useImportedHeaders(
+	hashes []chainhash.Hash) {
+	for _, checkpoint := range sm.chain.Checkpoints() {
+		if checkpoint.Height < 1 || int(checkpoint.Height) > len(hashes) {
+			continue
+		}
+		if !hashes[checkpoint.Height-1].IsEqual(checkpoint.Hash) {
+			log <- cl.Warnf{
+				"imported header at height %d is not checkpoint %s -- not using imported headers",
+				checkpoint.Height, checkpoint.Hash,
+			}
+			return
+		}
+		sm.importedFastHeight = checkpoint.Height
+	}
+	sm.importedHeaders = hashes
+	log <- cl.Infof{"using %d imported headers", len(hashes)}
+	assumeValid := sm.chain.AssumeValid()
+	if assumeValid == nil {
+		return
+	}
+	for i := range hashes {
+		if hashes[i].IsEqual(assumeValid) {
+			ancestors := make([]*chainhash.Hash, i+1)
+			for j := range ancestors {
+				ancestors[j] = &hashes[j]
+			}
+			sm.chain.AssumeValidChain(ancestors)
+			sm.assumeValidKnown = true
+			break
+		}
+	}
+}
 // New constructs a new SyncManager. Use Start to begin processing asynchronous block, tx, and inv updates.
 func New(
 	config *Config) (*SyncManager, error) {
@@ -1195,6 +1441,7 @@ func New(
 		rejectedTxns:    make(map[chainhash.Hash]struct{}),
 		requestedTxns:   make(map[chainhash.Hash]struct{}),
 		requestedBlocks: make(map[chainhash.Hash]struct{}),
+		snapshotBlocks:  make(map[chainhash.Hash]struct{}),
 		peerStates:      make(map[*peerpkg.Peer]*peerSyncState),
 		progressLogger:  newBlockProgressLogger("processed", Log),
 		msgChan:         make(chan interface{}, config.MaxPeers*3),
@@ -1212,6 +1459,9 @@ func New(
 	} else {
 		log <- cl.Inf("checkpoints are disabled")
 	}
+	if len(config.ImportedHeaders) > 0 {
+		sm.useImportedHeaders(config.ImportedHeaders)
+	}
 	sm.chain.Subscribe(sm.handleBlockchainNotification)
 	return &sm, nil
 }
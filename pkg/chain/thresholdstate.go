@@ -171,6 +171,66 @@ func (b *BlockChain) ThresholdState(deploymentID uint32) (ThresholdState, error)
 	b.chainLock.Unlock()
 	return state, err
 }
+// DeploymentStatus is the state of a deployment for the block after the end of the current best chain, with the height of the first block in that state and, while the deployment is being voted on, the votes in the current window.
+type DeploymentStatus struct {
+	State ThresholdState
+	Since int32
+	// Period is the number of blocks in each window and Threshold how many of them must signal for the deployment to lock in.
+	Period    uint32
+	Threshold uint32
+	// Elapsed is how many blocks of the current window there are and Count how many of those signal.
+	Elapsed uint32
+	Count   uint32
+	// Possible is whether enough blocks can still signal in the current window to lock the deployment in.
+	Possible bool
+}
+// DeploymentStatus returns the status of the given deployment ID for the block AFTER the end of the current best chain. This function is safe for concurrent access.
+func (b *BlockChain) DeploymentStatus(deploymentID uint32) (*DeploymentStatus, error) {
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+	tip := b.bestChain.Tip()
+	state, err := b.deploymentState(tip, deploymentID)
+	if err != nil {
+		return nil, err
+	}
+	deployment := &b.chainParams.Deployments[deploymentID]
+	checker := deploymentChecker{deployment: deployment, chain: b}
+	window := int32(checker.MinerConfirmationWindow())
+	status := &DeploymentStatus{
+		State:     state,
+		Period:    uint32(window),
+		Threshold: checker.RuleChangeActivationThreshold(),
+	}
+	// The state only changes at the start of a window, so walk back a window at a time to the first one in this state.
+	windowStart := tip.height + 1 - (tip.height+1)%window
+	status.Since = windowStart
+	for status.Since >= window {
+		prevState, err := b.deploymentState(b.bestChain.NodeByHeight(status.Since-window-1), deploymentID)
+		if err != nil {
+			return nil, err
+		}
+		if prevState != state {
+			break
+		}
+		status.Since -= window
+	}
+	if state != ThresholdStarted {
+		return status, nil
+	}
+	for node := tip; node != nil && node.height >= windowStart; node = node.parent {
+		status.Elapsed++
+		condition, err := checker.Condition(node)
+		if err != nil {
+			return nil, err
+		}
+		if condition {
+			status.Count++
+		}
+	}
+	status.Possible = status.Count >= status.Threshold ||
+		status.Period-status.Elapsed >= status.Threshold-status.Count
+	return status, nil
+}
 // IsDeploymentActive returns true if the target deploymentID is active, and false otherwise. This function is safe for concurrent access.
 func (b *BlockChain) IsDeploymentActive(deploymentID uint32) (bool, error) {
 	b.chainLock.Lock()
@@ -72,9 +72,10 @@ type ChangeSource func() ([]byte, error)
 // increasing targets amounts.
 //
 // If any remaining output value can be returned to the wallet via a change
-// output without violating mempool dust rules, a P2WPKH change output is
-// appended to the transaction outputs.  Since the change output may not be
-// necessary, fetchChange is called zero or one times to generate this script.
+// output without violating mempool dust rules at dustRelayFeePerKb, a P2WPKH
+// change output is appended to the transaction outputs.  Since the change
+// output may not be necessary, fetchChange is called zero or one times to
+// generate this script.
 // This function must return a P2WPKH script or smaller, otherwise fee estimation
 // will be incorrect.
 //
@@ -85,7 +86,7 @@ type ChangeSource func() ([]byte, error)
 //
 // BUGS: Fee estimation may be off when redeeming non-compressed P2PKH outputs.
 func NewUnsignedTransaction(
-	outputs []*wire.TxOut, relayFeePerKb util.Amount,
+	outputs []*wire.TxOut, relayFeePerKb, dustRelayFeePerKb util.Amount,
 	fetchInputs InputSource, fetchChange ChangeSource) (*AuthoredTx, error) {
 
 	targetAmount := h.SumOutputValues(outputs)
@@ -147,7 +148,7 @@ func NewUnsignedTransaction(
 
 		if changeAmount != 0 && !txrules.IsDustAmount(changeAmount,
 
-			txsizes.P2WPKHPkScriptSize, relayFeePerKb) {
+			txsizes.P2WPKHPkScriptSize, dustRelayFeePerKb) {
 
 			changeScript, err := fetchChange()
 
@@ -202,7 +202,7 @@ func TestNewUnsignedTransaction(
 	for i, test := range tests {
 
 		inputSource := makeInputSource(test.UnspentOutputs)
-		tx, err := NewUnsignedTransaction(test.Outputs, test.RelayFee, inputSource, changeSource)
+		tx, err := NewUnsignedTransaction(test.Outputs, test.RelayFee, test.RelayFee, inputSource, changeSource)
 
 		switch e := err.(type) {
 
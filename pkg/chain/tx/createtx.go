@@ -121,7 +121,7 @@ func (w *Wallet) txToOutputs(outputs []*wire.TxOut, account uint32,
 			return txscript.PayToAddrScript(changeAddr)
 		}
 		tx, err = txauthor.NewUnsignedTransaction(outputs, feeSatPerKb,
-			inputSource, changeSource)
+			feeSatPerKb, inputSource, changeSource)
 		if err != nil {
 			return err
 		}
@@ -15,6 +15,10 @@ import (
 // DefaultRelayFeePerKb is the default minimum relay fee policy for a mempool.
 const DefaultRelayFeePerKb util.Amount = 1e3
 
+// DefaultDustRelayFeePerKb is the default fee rate used by a node to decide
+// whether an output is dust.
+const DefaultDustRelayFeePerKb util.Amount = 1e4
+
 // GetDustThreshold is used to define the amount below which output will be
 // determined as dust. Threshold is determined as 3 times the relay fee.
 func GetDustThreshold(
@@ -342,15 +342,12 @@ func (vm *Engine) verifyWitnessProgram(witness [][]byte) error {
 // DisasmPC returns the string for the disassembly of the opcode that will be next to execute when Step() is called.
 func (vm *Engine) DisasmPC() (string, error) {
 
-	fmt.Println("before vm.curPC()")
 	scriptIdx, scriptOff, err := vm.curPC()
-	fmt.Println("after vm.curPC()")
 
 	if err != nil {
 
 		return "", err
 	}
-	fmt.Println("calling vm.disasm()")
 	return vm.disasm(scriptIdx, scriptOff), nil
 }
 
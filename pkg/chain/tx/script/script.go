@@ -77,6 +77,15 @@ func isWitnessScriptHash(
 		pops[1].opcode.value == OpData32
 }
 
+// isWitnessTaproot returns true if the passed script is a pay-to-taproot transaction, which is a version 1 witness program of 32 bytes, false otherwise.
+func isWitnessTaproot(
+	pops []parsedOpcode) bool {
+
+	return len(pops) == 2 &&
+		pops[0].opcode.value == Op1 &&
+		pops[1].opcode.value == OpData32
+}
+
 // IsPayToWitnessScriptHash returns true if the is in the standard pay-to-witness-script-hash (P2WSH) format, false otherwise.
 func IsPayToWitnessScriptHash(
 	script []byte) bool {
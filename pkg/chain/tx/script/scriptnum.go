@@ -67,20 +67,21 @@ func (n scriptNum) Bytes() []byte {
 		return nil
 	}
 
-	// Take the absolute value and keep track of whether it was originally negative.
+	// Take the absolute value and keep track of whether it was originally negative.  The absolute value is taken as unsigned so that of the smallest int64, which has no positive int64, is encoded too.
 	isNegative := n < 0
+	abs := uint64(n)
 	if isNegative {
 
-		n = -n
+		abs = -abs
 	}
 
 	// Encode to little endian.  The maximum number of encoded bytes is 9 (8 bytes for max int64 plus a potential byte for sign extension).
 	result := make([]byte, 0, 9)
 
-	for n > 0 {
+	for abs > 0 {
 
-		result = append(result, byte(n&0xff))
-		n >>= 8
+		result = append(result, byte(abs&0xff))
+		abs >>= 8
 	}
 
 	// When the most significant byte already has the high bit set, an additional high byte is required to indicate whether the number is negative or positive.  The additional byte is removed when converting back to an integral and its high bit is used to denote the sign.
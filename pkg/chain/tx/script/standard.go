@@ -46,6 +46,7 @@ const (
 	WitnessV0ScriptHashTy                    // Pay to witness script hash.
 	MultiSigTy                               // Multi signature.
 	NullDataTy                               // Empty data-only (provably prunable).
+	WitnessV1TaprootTy                       // Pay to taproot output key.
 )
 
 // scriptClassToName houses the human-readable strings which describe each script class.
@@ -58,6 +59,7 @@ var scriptClassToName = []string{
 	WitnessV0ScriptHashTy: "witness_v0_scripthash",
 	MultiSigTy:            "multisig",
 	NullDataTy:            "nulldata",
+	WitnessV1TaprootTy:    "witness_v1_taproot",
 }
 
 // String implements the Stringer interface by returning the name of the enum script class. If the enum is invalid then "Invalid" will be returned.
@@ -171,6 +173,9 @@ func typeOfScript(
 	} else if isWitnessScriptHash(pops) {
 
 		return WitnessV0ScriptHashTy
+	} else if isWitnessTaproot(pops) {
+
+		return WitnessV1TaprootTy
 	} else if isMultiSig(pops) {
 
 		return MultiSigTy
@@ -211,6 +216,9 @@ func expectedInputs(
 	case WitnessV0ScriptHashTy:
 		// Not including script.  That is handled by the caller.
 		return 1
+	case WitnessV1TaprootTy:
+		// A key path spend is a single signature, script path spends are handled by the caller.
+		return 1
 	case MultiSigTy:
 		// Standard multisig has a push a small number for the number of sigs and number of keys.  Check the first push instruction to see how many arguments are expected. typeOfScript already checked this so we know it'll be a small int.  Also, due to the original bitcoind bug where OpCheckMultiSig pops an additional item from the stack, add an extra expected input for the extra push that is required to compensate.
 		return asSmallInt(pops[0].opcode) + 1
@@ -389,6 +397,13 @@ func payToWitnessScriptHashScript(
 	return NewScriptBuilder().AddOp(OpZero).AddData(scriptHash).Script()
 }
 
+// payToWitnessTaprootScript creates a new script to pay to a version 1 witness program, the taproot output key. The passed key is expected to be valid.
+func payToWitnessTaprootScript(
+	outputKey []byte) ([]byte, error) {
+
+	return NewScriptBuilder().AddOp(Op1).AddData(outputKey).Script()
+}
+
 // payToPubkeyScript creates a new script to pay a transaction output to a public key. It is expected that the input is a valid pubkey.
 func payToPubKeyScript(
 	serializedPubKey []byte) ([]byte, error) {
@@ -445,6 +460,14 @@ func PayToAddrScript(
 				nilAddrErrStr)
 		}
 		return payToWitnessScriptHashScript(addr.ScriptAddress())
+	case *util.AddressWitnessTaproot:
+
+		if addr == nil {
+
+			return nil, scriptError(ErrUnsupportedAddress,
+				nilAddrErrStr)
+		}
+		return payToWitnessTaprootScript(addr.ScriptAddress())
 	}
 	str := fmt.Sprintf("unable to generate payment script for unsupported "+
 		"address type %T", addr)
@@ -572,6 +595,16 @@ func ExtractPkScriptAddrs(
 		addr, err := util.NewAddressWitnessScriptHash(pops[1].data,
 			chainParams)
 
+		if err == nil {
+
+			addrs = append(addrs, addr)
+		}
+	case WitnessV1TaprootTy:
+		// A pay-to-taproot script is of the form:  Op1 <32-byte output key> Therefore, the output key is the second item on the stack. Skip the output key if it's invalid for some reason.
+		requiredSigs = 1
+		addr, err := util.NewAddressWitnessTaproot(pops[1].data,
+			chainParams)
+
 		if err == nil {
 
 			addrs = append(addrs, addr)
@@ -62,6 +62,19 @@ func newAddressScriptHash(
 	return addr
 }
 
+// newAddressWitnessTaproot returns a new util.AddressWitnessTaproot from the provided output key.  It panics if an error occurs.  This is only used in the tests as a helper since the only way it can fail is if there is an error in the test source code.
+func newAddressWitnessTaproot(
+	outputKey []byte) util.Address {
+
+	addr, err := util.NewAddressWitnessTaproot(outputKey,
+		&chaincfg.MainNetParams)
+	if err != nil {
+
+		panic("invalid taproot output key in test source")
+	}
+	return addr
+}
+
 // TestExtractPkScriptAddrs ensures that extracting the type, addresses, and number of required signatures from PkScripts works as intended.
 func TestExtractPkScriptAddrs(
 	t *testing.T) {
@@ -184,6 +197,18 @@ func TestExtractPkScriptAddrs(
 			reqSigs: 1,
 			class:   ScriptHashTy,
 		},
+		{
+			name: "standard p2tr",
+			script: hexToBytes("512079be667ef9dcbbac55a06295ce870" +
+				"b07029bfcdb2dce28d959f2815b16f81798"),
+			addrs: []util.Address{
+				newAddressWitnessTaproot(hexToBytes("79be667ef9dc" +
+					"bbac55a06295ce870b07029bfcdb2dce28d959f2815b" +
+					"16f81798")),
+			},
+			reqSigs: 1,
+			class:   WitnessV1TaprootTy,
+		},
 		// from real tx 60a20bd93aa49ab4b28d514ec10b06e1829ce6818ec06cd3aabd013ebcdc4bb1, vout 0
 		{
 			name: "standard 1 of 2 multisig",
@@ -361,6 +386,67 @@ func TestExtractPkScriptAddrs(
 	}
 }
 
+// TestTaprootScript ensures pay-to-taproot scripts are classified, created from and parsed into taproot addresses, that version 1 witness programs of other lengths are not taken for them, and that nil taproot addresses are refused.
+func TestTaprootScript(
+	t *testing.T) {
+
+	t.Parallel()
+	outputKey := hexToBytes("79be667ef9dcbbac55a06295ce870b07029bfcdb2dce" +
+		"28d959f2815b16f81798")
+	script := hexToBytes("5120" + "79be667ef9dcbbac55a06295ce870b07029bfcd" +
+		"b2dce28d959f2815b16f81798")
+
+	if class := GetScriptClass(script); class != WitnessV1TaprootTy {
+
+		t.Errorf("GetScriptClass: got %v, want %v", class,
+			WitnessV1TaprootTy)
+	}
+
+	if s := WitnessV1TaprootTy.String(); s != "witness_v1_taproot" {
+
+		t.Errorf("String: got %q, want %q", s, "witness_v1_taproot")
+	}
+	addr, err := util.NewAddressWitnessTaproot(outputKey,
+		&chaincfg.MainNetParams)
+
+	if err != nil {
+
+		t.Fatalf("Unable to create taproot address: %v", err)
+	}
+	pkScript, err := PayToAddrScript(addr)
+
+	if err != nil || !bytes.Equal(pkScript, script) {
+
+		t.Errorf("PayToAddrScript: got %x, %v, want %x", pkScript, err,
+			script)
+	}
+	class, addrs, reqSigs, err := ExtractPkScriptAddrs(script,
+		&chaincfg.MainNetParams)
+
+	if err != nil || class != WitnessV1TaprootTy || reqSigs != 1 ||
+		len(addrs) != 1 || addrs[0].EncodeAddress() !=
+		"bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqzk5jj0" {
+
+		t.Errorf("ExtractPkScriptAddrs: got %v, %v, %d, %v", class, addrs,
+			reqSigs, err)
+	}
+	// A version 1 witness program of 31 bytes is not a taproot output key.
+	short := hexToBytes("511f" + "79be667ef9dcbbac55a06295ce870b07029bfcdb" +
+		"2dce28d959f2815b16f817")
+
+	if class := GetScriptClass(short); class == WitnessV1TaprootTy {
+
+		t.Errorf("GetScriptClass: 31 byte version 1 program is %v", class)
+	}
+	_, err = PayToAddrScript((*util.AddressWitnessTaproot)(nil))
+
+	if e := tstCheckScriptError(err, scriptError(ErrUnsupportedAddress,
+		"")); e != nil {
+
+		t.Errorf("PayToAddrScript: nil taproot address: %v", e)
+	}
+}
+
 // TestCalcScriptInfo ensures the CalcScriptInfo provides the expected results for various valid and invalid script pairs.
 func TestCalcScriptInfo(
 	t *testing.T) {
@@ -632,6 +718,7 @@ func TestPayToAddrScript(
 			err)
 	}
 
+
 	// Errors used in the tests below defined here for convenience and to keep the horizontal test size shorter.
 	errUnsupportedAddress := scriptError(ErrUnsupportedAddress, "")
 	tests := []struct {
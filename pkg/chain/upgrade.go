@@ -8,6 +8,7 @@ import (
 	chainhash "git.parallelcoin.io/dev/9/pkg/chain/hash"
 	"git.parallelcoin.io/dev/9/pkg/chain/wire"
 	database "git.parallelcoin.io/dev/9/pkg/db"
+	"git.parallelcoin.io/dev/9/pkg/util"
 	cl "git.parallelcoin.io/dev/9/pkg/util/cl"
 )
 const (
@@ -500,16 +501,181 @@ func upgradeUtxoSetToV2(
 	}
 	return nil
 }
-// maybeUpgradeDbBuckets checks the database version of the buckets used by this
-// package and performs any needed upgrades to bring them to the latest version.
-// All buckets used by this package are guaranteed to be the latest version if
-// this function returns without error.
-func (b *BlockChain) maybeUpgradeDbBuckets(interrupt <-chan struct{}) error {
-	// Load or create bucket versions as needed.
+// blockIndexV1RowSize is the size of the entries of version 1 block indexes,
+// which are the header followed by the status.  Version 2 entries end with the
+// version bits signalled in the coinbase of the block after that.
+const blockIndexV1RowSize = blockHdrSize + 1
+// rewriteBlockIndex replaces the entries of the block index that rewrite
+// returns a new entry for in batches, and then sets the version of the block
+// index.  It returns the number of entries replaced, and is guaranteed to have
+// replaced all of them if this returns without failure.
+func rewriteBlockIndex(
+	db database.DB, interrupt <-chan struct{}, version uint32,
+	rewrite func(dbTx database.Tx, key, row []byte) ([]byte, error)) (uint64, error) {
+	const batchSize = 10000
+	// doBatch rewrites up to batchSize entries from the key resume on, and
+	// returns the key to carry on from, or nil when there are none left.  The
+	// entries are only written once the cursor is done with them, as the
+	// bucket must not be modified while it is iterated.
+	doBatch := func(dbTx database.Tx, resume []byte) ([]byte, uint32, error) {
+		bucket := dbTx.Metadata().Bucket(blockIndexBucketName)
+		var keys, rows [][]byte
+		var next []byte
+		cursor := bucket.Cursor()
+		ok := cursor.First()
+		if resume != nil {
+			ok = cursor.Seek(resume)
+		}
+		for ; ok; ok = cursor.Next() {
+			if len(keys) == batchSize {
+				next = append([]byte(nil), cursor.Key()...)
+				break
+			}
+			row, err := rewrite(dbTx, cursor.Key(), cursor.Value())
+			if err != nil {
+				return nil, 0, err
+			}
+			if row == nil {
+				continue
+			}
+			keys = append(keys, append([]byte(nil), cursor.Key()...))
+			rows = append(rows, row)
+		}
+		for i := range keys {
+			if err := bucket.Put(keys[i], rows[i]); err != nil {
+				return nil, 0, err
+			}
+		}
+		return next, uint32(len(keys)), nil
+	}
+	var resume []byte
+	var totalRows uint64
+	for {
+		var numRows uint32
+		err := db.Update(func(dbTx database.Tx) error {
+			var err error
+			resume, numRows, err = doBatch(dbTx, resume)
+			return err
+		})
+		if err != nil {
+			return totalRows, err
+		}
+		totalRows += uint64(numRows)
+		log <- cl.Infof{"rewrote %d block index entries (%d total)", numRows,
+			totalRows}
+		if resume == nil {
+			break
+		}
+		if interruptRequested(interrupt) {
+			return totalRows, errInterruptRequested
+		}
+	}
+	// Update the block index version once every entry has been rewritten.
+	err := db.Update(func(dbTx database.Tx) error {
+		return dbPutVersion(dbTx, blockIndexVersionKeyName, version)
+	})
+	return totalRows, err
+}
+// upgradeBlockIndexToV2 adds the version bits signalled in the coinbase of each
+// block to its entry in the block index, which version 2 entries end with, in
+// batches.  Blocks whose data is not stored signal none.  It is guaranteed to
+// be updated if this returns without failure.
+func upgradeBlockIndexToV2(
+	db database.DB, interrupt <-chan struct{}) error {
+	log <- cl.Inf("Upgrading block index to v2.  This will take a while...")
+	start := time.Now()
+	// Entries that already end with version bits were written before the
+	// version was stored and are left as they are.
+	totalRows, err := rewriteBlockIndex(db, interrupt, 2,
+		func(dbTx database.Tx, key, row []byte) ([]byte, error) {
+			if len(row) != blockIndexV1RowSize {
+				return nil, nil
+			}
+			var versionBits uint32
+			if blockStatus(row[blockHdrSize]).HaveData() {
+				var hash chainhash.Hash
+				copy(hash[:], key[4:])
+				serialized, err := dbTx.FetchBlock(&hash)
+				if err != nil {
+					return nil, err
+				}
+				block, err := util.NewBlockFromBytes(serialized)
+				if err != nil {
+					return nil, err
+				}
+				versionBits = CoinbaseVersionBits(block.Transactions()[0])
+			}
+			upgraded := make([]byte, blockIndexV1RowSize+4)
+			copy(upgraded, row)
+			byteOrder.PutUint32(upgraded[blockIndexV1RowSize:], versionBits)
+			return upgraded, nil
+		})
+	if err != nil {
+		return err
+	}
+	seconds := int64(time.Since(start) / time.Second)
+	log <- cl.Infof{
+		"Done upgrading block index.  Total entries: %d in %d seconds",
+		totalRows,
+		seconds,
+	}
+	return nil
+}
+// downgradeBlockIndexToV1 removes the version bits from the end of the entries
+// of the block index in batches, so that it can be read by versions that
+// predate them.  It is guaranteed to be downgraded if this returns without
+// failure.
+func downgradeBlockIndexToV1(
+	db database.DB, interrupt <-chan struct{}) error {
+	log <- cl.Inf("Downgrading block index to v1.  This will take a while...")
+	start := time.Now()
+	totalRows, err := rewriteBlockIndex(db, interrupt, 1,
+		func(dbTx database.Tx, key, row []byte) ([]byte, error) {
+			if len(row) != blockIndexV1RowSize+4 {
+				return nil, nil
+			}
+			return append([]byte(nil), row[:blockIndexV1RowSize]...), nil
+		})
+	if err != nil {
+		return err
+	}
+	seconds := int64(time.Since(start) / time.Second)
+	log <- cl.Infof{
+		"Done downgrading block index.  Total entries: %d in %d seconds",
+		totalRows,
+		seconds,
+	}
+	return nil
+}
+// maybeUpgradeBlockIndex upgrades the block index to the latest version if it
+// is older.  It must be done before the block index is loaded, so unlike the
+// other buckets it is not upgraded by maybeUpgradeDbBuckets.
+func maybeUpgradeBlockIndex(
+	db database.DB, interrupt <-chan struct{}) error {
+	var version uint32
+	err := db.Update(func(dbTx database.Tx) error {
+		// Block indexes from before the version was stored are version 1.
+		var err error
+		version, err = dbFetchOrCreateVersion(dbTx,
+			blockIndexVersionKeyName, 1)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if version < 2 {
+		return upgradeBlockIndexToV2(db, interrupt)
+	}
+	return nil
+}
+// maybeUpgradeUtxoSet upgrades the utxo set to the latest version if it is
+// older.
+func maybeUpgradeUtxoSet(
+	db database.DB, interrupt <-chan struct{}) error {
+	// Load the utxo set version from the database or create it and
+	// initialize it to version 1 if it doesn't exist.
 	var utxoSetVersion uint32
-	err := b.db.Update(func(dbTx database.Tx) error {
-		// Load the utxo set version from the database or create it and
-		// initialize it to version 1 if it doesn't exist.
+	err := db.Update(func(dbTx database.Tx) error {
 		var err error
 		utxoSetVersion, err = dbFetchOrCreateVersion(dbTx,
 			utxoSetVersionKeyName, 1)
@@ -520,9 +686,78 @@ func (b *BlockChain) maybeUpgradeDbBuckets(interrupt <-chan struct{}) error {
 	}
 	// Update the utxo set to v2 if needed.
 	if utxoSetVersion < 2 {
-		if err := upgradeUtxoSetToV2(b.db, interrupt); err != nil {
+		return upgradeUtxoSetToV2(db, interrupt)
+	}
+	return nil
+}
+// DatabaseVersions returns the versions of the block index and the utxo set in
+// the database.  Both are 0 when the chain state has not been created in it, as
+// it is then created at the latest versions, and buckets from before their
+// versions were stored are version 1.
+func DatabaseVersions(
+	db database.DB) (blockIndex, utxoSet uint32, err error) {
+	err = db.View(func(dbTx database.Tx) error {
+		if dbTx.Metadata().Get(chainStateKeyName) == nil {
+			return nil
+		}
+		blockIndex, utxoSet = 1, 1
+		if dbTx.Metadata().Bucket(blockIndexBucketName) == nil {
+			return nil
+		}
+		if version := dbFetchVersion(dbTx, blockIndexVersionKeyName); version > 0 {
+			blockIndex = version
+		}
+		if version := dbFetchVersion(dbTx, utxoSetVersionKeyName); version > 0 {
+			utxoSet = version
+		}
+		return nil
+	})
+	return
+}
+// UpgradeBlockIndex brings the block index in the database to the latest
+// version, re-indexing it first if it predates the index keyed by height.  The
+// chain does this itself when it is created, so it only needs to be called to
+// upgrade the database before then.
+func UpgradeBlockIndex(
+	db database.DB, interrupt <-chan struct{}) error {
+	var hasBlockIndex bool
+	err := db.View(func(dbTx database.Tx) error {
+		hasBlockIndex = dbTx.Metadata().Bucket(blockIndexBucketName) != nil
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if !hasBlockIndex {
+		if err := migrateBlockIndex(db); err != nil {
 			return err
 		}
 	}
-	return nil
+	return maybeUpgradeBlockIndex(db, interrupt)
+}
+// DowngradeBlockIndex brings the block index in the database back to version 1,
+// without the version bits signalled in the coinbases of the blocks, for
+// versions that predate them.  The chain upgrades it again when it is next
+// created.
+func DowngradeBlockIndex(
+	db database.DB, interrupt <-chan struct{}) error {
+	blockIndex, _, err := DatabaseVersions(db)
+	if err != nil || blockIndex < 2 {
+		return err
+	}
+	return downgradeBlockIndexToV1(db, interrupt)
+}
+// UpgradeUtxoSet brings the utxo set in the database to the latest version,
+// which can not be undone.  The chain does this itself when it is created, so
+// it only needs to be called to upgrade the database before then.
+func UpgradeUtxoSet(
+	db database.DB, interrupt <-chan struct{}) error {
+	return maybeUpgradeUtxoSet(db, interrupt)
+}
+// maybeUpgradeDbBuckets checks the database version of the buckets used by this
+// package and performs any needed upgrades to bring them to the latest version.
+// All buckets used by this package are guaranteed to be the latest version if
+// this function returns without error.
+func (b *BlockChain) maybeUpgradeDbBuckets(interrupt <-chan struct{}) error {
+	return maybeUpgradeUtxoSet(b.db, interrupt)
 }
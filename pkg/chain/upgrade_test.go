@@ -2,6 +2,11 @@ package chain
 import (
 	"reflect"
 	"testing"
+	"time"
+	chaincfg "git.parallelcoin.io/dev/9/pkg/chain/config"
+	"git.parallelcoin.io/dev/9/pkg/chain/wire"
+	database "git.parallelcoin.io/dev/9/pkg/db"
+	"git.parallelcoin.io/dev/9/pkg/util"
 )
 // TestDeserializeUtxoEntryV0 ensures deserializing unspent trasaction output
 // entries from the legacy version 0 format works as expected.
@@ -107,3 +112,116 @@ func TestDeserializeUtxoEntryV0(
 		}
 	}
 }
+// TestUpgradeBlockIndexToV2 ensures upgrading the block index adds the version
+// bits signalled in the coinbase of each block whose data is stored to its
+// entry, and none to the entries of blocks whose data is not, and that
+// downgrading it removes them again.
+func TestUpgradeBlockIndexToV2(
+	t *testing.T) {
+	chain, teardownFunc, err := chainSetup("upgradeblockindex",
+		&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc()
+	// Store a block signalling version bits in its coinbase, and a header
+	// after it without its block, with entries in the version 1 format.
+	versionBits := uint32(vbTopBits | 1<<5)
+	coinbase := wire.NewMsgTx(wire.TxVersion)
+	coinbase.AddTxIn(wire.NewTxIn(&wire.OutPoint{Index: wire.MaxPrevOutIndex},
+		[]byte{0x51, 0x51}, nil))
+	coinbase.AddTxOut(&wire.TxOut{PkScript: VersionBitsScript(versionBits)})
+	genesis := chain.bestChain.Tip()
+	msgBlock := &wire.MsgBlock{
+		Header: wire.BlockHeader{
+			Version:   2,
+			PrevBlock: genesis.hash,
+			Timestamp: time.Unix(genesis.timestamp+1, 0),
+		},
+		Transactions: []*wire.MsgTx{coinbase},
+	}
+	stored := newBlockNode(&msgBlock.Header, genesis)
+	stored.status = statusDataStored
+	headerOnly := newFakeNode(stored, 2, 0, time.Unix(genesis.timestamp+2, 0))
+	nodes := []*blockNode{genesis, stored, headerOnly}
+	err = chain.db.Update(func(dbTx database.Tx) error {
+		if err := dbStoreBlock(dbTx, util.NewBlock(msgBlock)); err != nil {
+			return err
+		}
+		bucket := dbTx.Metadata().Bucket(blockIndexBucketName)
+		for _, node := range nodes {
+			if err := dbStoreBlockNode(dbTx, node); err != nil {
+				return err
+			}
+			key := blockIndexKey(&node.hash, uint32(node.height))
+			row := bucket.Get(key)
+			if err := bucket.Put(key, row[:blockHdrSize+1]); err != nil {
+				return err
+			}
+		}
+		return dbPutVersion(dbTx, blockIndexVersionKeyName, 1)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = maybeUpgradeBlockIndex(chain.db, nil); err != nil {
+		t.Fatal(err)
+	}
+	err = chain.db.View(func(dbTx database.Tx) error {
+		if version := dbFetchVersion(dbTx, blockIndexVersionKeyName); version != 2 {
+			t.Errorf("block index is version %d after upgrading", version)
+		}
+		bucket := dbTx.Metadata().Bucket(blockIndexBucketName)
+		for i, want := range []uint32{0, versionBits, 0} {
+			node := nodes[i]
+			row := bucket.Get(blockIndexKey(&node.hash, uint32(node.height)))
+			if len(row) != blockHdrSize+5 {
+				t.Errorf("entry of block %d is %d bytes", node.height, len(row))
+				continue
+			}
+			_, status, got, err := deserializeBlockRow(row)
+			if err != nil {
+				return err
+			}
+			if got != want || status != node.status {
+				t.Errorf("block %d has status %v and version bits %08x, want %v and %08x",
+					node.height, status, got, node.status, want)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Downgrading strips the version bits again, and the versions read back
+	// follow the block index.
+	if err = DowngradeBlockIndex(chain.db, nil); err != nil {
+		t.Fatal(err)
+	}
+	blockIndex, utxoSet, err := DatabaseVersions(chain.db)
+	if err != nil || blockIndex != 1 || utxoSet != 2 {
+		t.Fatalf("versions are %d and %d after downgrading, %v", blockIndex,
+			utxoSet, err)
+	}
+	err = chain.db.View(func(dbTx database.Tx) error {
+		bucket := dbTx.Metadata().Bucket(blockIndexBucketName)
+		for _, node := range nodes {
+			row := bucket.Get(blockIndexKey(&node.hash, uint32(node.height)))
+			if len(row) != blockIndexV1RowSize {
+				t.Errorf("entry of block %d is %d bytes after downgrading",
+					node.height, len(row))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = UpgradeBlockIndex(chain.db, nil); err != nil {
+		t.Fatal(err)
+	}
+	if blockIndex, _, err = DatabaseVersions(chain.db); err != nil || blockIndex != 2 {
+		t.Fatalf("block index is version %d after upgrading again, %v",
+			blockIndex, err)
+	}
+}
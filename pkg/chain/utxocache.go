@@ -0,0 +1,344 @@
+package chain
+import (
+	"fmt"
+	"sync"
+	"time"
+	chainhash "git.parallelcoin.io/dev/9/pkg/chain/hash"
+	"git.parallelcoin.io/dev/9/pkg/chain/wire"
+	database "git.parallelcoin.io/dev/9/pkg/db"
+	"git.parallelcoin.io/dev/9/pkg/util"
+	cl "git.parallelcoin.io/dev/9/pkg/util/cl"
+)
+const (
+	// utxoFlushInterval is the longest the utxo cache holds changes before writing them to the database, which bounds how many blocks are replayed after a crash.
+	utxoFlushInterval = 10 * time.Minute
+	// utxoEntryOverhead is about how many bytes an entry in the utxo cache takes besides its public key script: its outpoint, the UtxoEntry and its share of the map.
+	utxoEntryOverhead = 36 + 48 + 64
+	// utxoFlushBatchSize is the most utxo changes a flush writes in one database transaction, which bounds the memory the database takes to write them.
+	utxoFlushBatchSize = 100000
+)
+// utxoStateKeyName is the name of the db key used to store the hash of the block the utxo set in the database is at.  The utxo cache writes it with the utxo set, so when the cache was not flushed before a shutdown it is behind the best chain state and the blocks after it are replayed.
+var utxoStateKeyName = []byte("utxostate")
+// utxoCache holds the utxos that blocks are validated against in memory between chain validation and the database, so that during the initial block download most inputs are found without a random read of the database, and outputs that are created and spent between flushes never reach it. Changes are written in batches when the cache outgrows its size, every utxoFlushInterval and at shutdown, each batch together with the hash of the block the utxo set is then at.
+type utxoCache struct {
+	db      database.DB
+	maxSize uint64
+	// mtx protects the fields below, as entries are also fetched into the cache by callers holding the chain lock only for reads.
+	mtx         sync.Mutex
+	entries     map[wire.OutPoint]*UtxoEntry
+	size        uint64
+	bestHash    chainhash.Hash
+	flushedHash chainhash.Hash
+	lastFlush   time.Time
+}
+// newUtxoCache returns an empty utxo cache for the database that holds about maxSize bytes of entries before it is flushed and emptied.
+func newUtxoCache(
+	db database.DB, maxSize uint64) *utxoCache {
+	return &utxoCache{
+		db:        db,
+		maxSize:   maxSize,
+		entries:   make(map[wire.OutPoint]*UtxoEntry),
+		lastFlush: time.Now(),
+	}
+}
+// entrySize is about how much memory the entry takes in the cache.
+func entrySize(
+	entry *UtxoEntry) uint64 {
+	return utxoEntryOverhead + uint64(len(entry.pkScript))
+}
+// put adds the entry to the cache, replacing any entry for the outpoint. This function MUST be called with the cache lock held.
+func (c *utxoCache) put(outpoint wire.OutPoint, entry *UtxoEntry) {
+	c.remove(outpoint)
+	c.entries[outpoint] = entry
+	c.size += entrySize(entry)
+}
+// remove takes the entry for the outpoint out of the cache. This function MUST be called with the cache lock held.
+func (c *utxoCache) remove(outpoint wire.OutPoint) {
+	if entry, ok := c.entries[outpoint]; ok {
+		c.size -= entrySize(entry)
+		delete(c.entries, outpoint)
+	}
+}
+// fetchEntries loads the outpoints into the view from the cache, reading those that are not in it from the database and keeping them.  The view gets copies of the entries so that spending them in the view does not change the cache, and like the database leaves a nil entry for an output that is spent or does not exist.
+func (c *utxoCache) fetchEntries(
+	view *UtxoViewpoint, outpoints map[wire.OutPoint]struct{}) error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	var missing []wire.OutPoint
+	for outpoint := range outpoints {
+		entry, ok := c.entries[outpoint]
+		if !ok {
+			missing = append(missing, outpoint)
+			continue
+		}
+		if entry.IsSpent() {
+			view.entries[outpoint] = nil
+			continue
+		}
+		entry = entry.Clone()
+		entry.packedFlags &^= tfModified | tfFresh
+		view.entries[outpoint] = entry
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return c.db.View(func(dbTx database.Tx) error {
+		for _, outpoint := range missing {
+			entry, err := dbFetchUtxoEntry(dbTx, utxoSetBucketName, outpoint)
+			if err != nil {
+				return err
+			}
+			view.entries[outpoint] = entry
+			if entry != nil {
+				c.put(outpoint, entry.Clone())
+			}
+		}
+		return nil
+	})
+}
+// commit takes the changes in the view into the cache, moving it to the view's best block.  Outputs that were created since the last flush are fresh, not in the database, so when they are spent they are dropped rather than kept to be deleted from the database.
+func (c *utxoCache) commit(
+	view *UtxoViewpoint) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	for outpoint, entry := range view.entries {
+		if entry == nil || !entry.isModified() {
+			continue
+		}
+		// Every entry the view was given came through the cache, so one that is not in it was created by the view.
+		cached, ok := c.entries[outpoint]
+		fresh := !ok || cached.packedFlags&tfFresh == tfFresh
+		if entry.IsSpent() && fresh {
+			c.remove(outpoint)
+			continue
+		}
+		entry = entry.Clone()
+		entry.packedFlags |= tfModified
+		if fresh {
+			entry.packedFlags |= tfFresh
+		} else {
+			entry.packedFlags &^= tfFresh
+		}
+		c.put(outpoint, entry)
+	}
+	c.bestHash = *view.BestHash()
+}
+// commitFlushed takes the changes in the view into the cache after they were written to the database along with the view's best block, as disconnecting blocks does.  The cache must have been flushed before.
+func (c *utxoCache) commitFlushed(
+	view *UtxoViewpoint) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	for outpoint, entry := range view.entries {
+		if entry == nil || !entry.isModified() {
+			continue
+		}
+		if entry.IsSpent() {
+			c.remove(outpoint)
+			continue
+		}
+		entry = entry.Clone()
+		entry.packedFlags &^= tfModified | tfFresh
+		c.put(outpoint, entry)
+	}
+	c.bestHash = *view.BestHash()
+	c.flushedHash = c.bestHash
+}
+// flush writes the changes in the cache to the database with the hash of the block the cache is at, journaled when they take more than one transaction, so the utxo set in the database is always that of a block in the chain or is finished at startup.  The cache is emptied when evict is set, and otherwise keeps its entries, now unmodified.
+func (c *utxoCache) flush(
+	evict bool) error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	var changes int
+	for _, entry := range c.entries {
+		if entry.isModified() {
+			changes++
+		}
+	}
+	if changes > 0 || c.bestHash != c.flushedHash {
+		err := dbPutUtxoChanges(c.db, c.entries, &c.flushedHash, &c.bestHash)
+		if err != nil {
+			return err
+		}
+		log <- cl.Debugf{
+			"flushed %d utxo changes to the database at block %v", changes, c.bestHash,
+		}
+	}
+	for outpoint, entry := range c.entries {
+		if entry.IsSpent() {
+			c.remove(outpoint)
+			continue
+		}
+		entry.packedFlags &^= tfModified | tfFresh
+	}
+	if evict {
+		c.entries = make(map[wire.OutPoint]*UtxoEntry)
+		c.size = 0
+	}
+	c.flushedHash = c.bestHash
+	c.lastFlush = time.Now()
+	return nil
+}
+// dbPutUtxoChanges writes the modified entries to the utxo set in the database, moving it from the block with hash from to the one with hash to, in database transactions of up to utxoFlushBatchSize changes.  When there are more changes than that, the flush is journaled before the first batch, so it is finished at startup if the node stops before the last.  The last batch stores the block the utxo set is at and removes the journal of the flush, if there is one.
+func dbPutUtxoChanges(
+	db database.DB, entries map[wire.OutPoint]*UtxoEntry, from, to *chainhash.Hash) error {
+	var outpoints []wire.OutPoint
+	for outpoint, entry := range entries {
+		if entry != nil && entry.isModified() {
+			outpoints = append(outpoints, outpoint)
+		}
+	}
+	journaled := len(outpoints) > utxoFlushBatchSize
+	if journaled {
+		err := db.Update(func(dbTx database.Tx) error {
+			return dbPutChainJournal(dbTx, utxoFlushJournalKeyName, &chainJournal{from: *from, to: *to})
+		})
+		if err != nil {
+			return err
+		}
+	}
+	for {
+		batch := outpoints
+		if len(batch) > utxoFlushBatchSize {
+			batch = batch[:utxoFlushBatchSize]
+		}
+		outpoints = outpoints[len(batch):]
+		err := db.Update(func(dbTx database.Tx) error {
+			utxoBucket := dbTx.Metadata().Bucket(utxoSetBucketName)
+			for _, outpoint := range batch {
+				entry := entries[outpoint]
+				key := outpointKey(outpoint)
+				if entry.IsSpent() {
+					err := utxoBucket.Delete(*key)
+					recycleOutpointKey(key)
+					if err != nil {
+						return err
+					}
+					continue
+				}
+				serialized, err := serializeUtxoEntry(entry)
+				if err != nil {
+					return err
+				}
+				// NOTE: The key is intentionally not recycled here since the database interface contract prohibits modifications.
+				if err = utxoBucket.Put(*key, serialized); err != nil {
+					return err
+				}
+			}
+			if len(outpoints) != 0 {
+				return nil
+			}
+			if err := dbRemoveChainJournal(dbTx, utxoFlushJournalKeyName); err != nil {
+				return err
+			}
+			return dbPutUtxoState(dbTx, to)
+		})
+		if err != nil || len(outpoints) == 0 {
+			return err
+		}
+	}
+}
+// maybeFlush flushes the cache and empties it when it has outgrown its size, or flushes it keeping its entries when the last flush was more than utxoFlushInterval ago.
+func (c *utxoCache) maybeFlush() error {
+	c.mtx.Lock()
+	full := c.size >= c.maxSize
+	due := time.Since(c.lastFlush) >= utxoFlushInterval
+	c.mtx.Unlock()
+	switch {
+	case full:
+		return c.flush(true)
+	case due:
+		return c.flush(false)
+	}
+	return nil
+}
+// dbFetchUtxoState returns the hash of the block the utxo set in the database is at, or nil for a database from before the utxo cache, whose utxo set is always at the best block.
+func dbFetchUtxoState(
+	dbTx database.Tx) *chainhash.Hash {
+	serialized := dbTx.Metadata().Get(utxoStateKeyName)
+	if len(serialized) != chainhash.HashSize {
+		return nil
+	}
+	var hash chainhash.Hash
+	copy(hash[:], serialized)
+	return &hash
+}
+// dbPutUtxoState stores the hash of the block the utxo set in the database is at.
+func dbPutUtxoState(
+	dbTx database.Tx, hash *chainhash.Hash) error {
+	return dbTx.Metadata().Put(utxoStateKeyName, hash[:])
+}
+// initUtxoCache brings the utxo set up to the best block after a shutdown that did not flush the utxo cache, by connecting the transactions of the blocks after the one the utxo set in the database is at again.  The spend journal and indexes were written with each block, so only the utxo set needs them.
+func (b *BlockChain) initUtxoCache(
+	interrupt <-chan struct{}) error {
+	// A flush that was interrupted is finished first, which leaves the utxo set at the block it was flushed to.
+	if err := b.finishUtxoFlush(); err != nil {
+		return err
+	}
+	var state *chainhash.Hash
+	err := b.db.View(func(dbTx database.Tx) error {
+		state = dbFetchUtxoState(dbTx)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	tip := b.bestChain.Tip()
+	if state == nil {
+		// The utxo set of a database from before the utxo cache is at the best block, which is stored before any blocks are connected through the cache, or a shutdown before its first flush would leave nothing to replay them from.
+		state = &tip.hash
+		err = b.db.Update(func(dbTx database.Tx) error {
+			return dbPutUtxoState(dbTx, state)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	b.utxoCache.bestHash = *state
+	b.utxoCache.flushedHash = *state
+	node := b.Index.LookupNode(state)
+	if node == nil || !b.bestChain.Contains(node) {
+		return AssertError(fmt.Sprintf(
+			"initUtxoCache: utxo set is at block %v which is not in the main chain", state,
+		))
+	}
+	if node == tip {
+		return nil
+	}
+	log <- cl.Infof{
+		"replaying %d blocks to bring the utxo set up to the best block",
+		tip.height - node.height,
+	}
+	for n := b.bestChain.Next(node); n != nil; n = b.bestChain.Next(n) {
+		if interruptRequested(interrupt) {
+			return errInterruptRequested
+		}
+		var block *util.Block
+		err := b.db.View(func(dbTx database.Tx) error {
+			var err error
+			block, err = dbFetchBlockByNode(dbTx, n)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		view := NewUtxoViewpoint()
+		view.SetBestHash(&n.parent.hash)
+		if err = view.fetchInputUtxos(b.utxoCache, block); err != nil {
+			return err
+		}
+		if err = view.connectTransactions(block, nil); err != nil {
+			return err
+		}
+		b.utxoCache.commit(view)
+		if err = b.utxoCache.maybeFlush(); err != nil {
+			return err
+		}
+	}
+	return b.utxoCache.flush(false)
+}
+// FlushUtxoCache writes the changes in the utxo cache to the database, for when the chain is shut down. This function is safe for concurrent access.
+func (b *BlockChain) FlushUtxoCache() error {
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+	return b.utxoCache.flush(false)
+}
@@ -0,0 +1,68 @@
+package chain_test
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	blockchain "git.parallelcoin.io/dev/9/pkg/chain"
+	"git.parallelcoin.io/dev/9/pkg/chain/chaingen"
+	chaincfg "git.parallelcoin.io/dev/9/pkg/chain/config"
+	"git.parallelcoin.io/dev/9/pkg/chain/wire"
+	txscript "git.parallelcoin.io/dev/9/pkg/chain/tx/script"
+	database "git.parallelcoin.io/dev/9/pkg/db"
+)
+// TestUtxoCacheRestart ensures a chain that is stopped before its utxo cache is first flushed, as when the process is killed, replays the blocks the cache held when it is started again
+func TestUtxoCacheRestart(
+	t *testing.T) {
+	dir, err := ioutil.TempDir("", "utxocacherestart")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	params := chaincfg.SimNetParams
+	// reopening the database checks the genesis block hashes to the genesis hash, which that of simnet does not
+	genesisHash := params.GenesisBlock.BlockHash()
+	params.GenesisHash = &genesisHash
+	db, err := database.Create("ffldb", dir, params.Net)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	// the cache is big enough to hold the blocks, so it is not flushed while they are connected
+	newChain := func() (*blockchain.BlockChain, error) {
+		return blockchain.New(&blockchain.Config{
+			DB:            db,
+			ChainParams:   &params,
+			TimeSource:    blockchain.NewMedianTime(),
+			SigCache:      txscript.NewSigCache(1000),
+			UtxoCacheSize: 1 << 20,
+		})
+	}
+	chain, err := newChain()
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := chaingen.New(chain, &params, []byte("utxocacherestart"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	runScenario(t, g, "blocks 3\n")
+	best := chain.BestSnapshot()
+	// the chain is dropped without flushing its utxo cache, and a new one is started on the same database
+	restarted, err := newChain()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := restarted.BestSnapshot(); got.Hash != best.Hash || got.TotalTxns != best.TotalTxns {
+		t.Fatalf("the restarted chain is at %+v, not %+v", got, best)
+	}
+	for height := int32(1); height <= best.Height; height++ {
+		block, err := restarted.BlockByHeight(height)
+		if err != nil {
+			t.Fatal(err)
+		}
+		coinbase := wire.OutPoint{Hash: *block.Transactions()[0].Hash()}
+		if entry, err := restarted.FetchUtxoEntry(coinbase); err != nil || entry == nil || entry.BlockHeight() != height {
+			t.Errorf("the coinbase of block %d is %+v, %v after restarting", height, entry, err)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package chain
+import (
+	"testing"
+	chaincfg "git.parallelcoin.io/dev/9/pkg/chain/config"
+	chainhash "git.parallelcoin.io/dev/9/pkg/chain/hash"
+	"git.parallelcoin.io/dev/9/pkg/chain/wire"
+	database "git.parallelcoin.io/dev/9/pkg/db"
+)
+// TestUtxoCache ensures the utxo cache only writes its changes to the database when it is flushed, along with the block it is at, and that outputs created and spent between flushes never reach the database.
+func TestUtxoCache(
+	t *testing.T) {
+	chain, teardownFunc, err := chainSetup("utxocache",
+		&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc()
+	cache := newUtxoCache(chain.db, 1<<20)
+	txOut := &wire.TxOut{Value: 1, PkScript: []byte{0x51}}
+	outpoints := make([]wire.OutPoint, 3)
+	for i := range outpoints {
+		outpoints[i] = wire.OutPoint{Hash: chainhash.Hash{byte(i + 1)}}
+	}
+	dbEntries := func() []*UtxoEntry {
+		entries := make([]*UtxoEntry, len(outpoints))
+		err := chain.db.View(func(dbTx database.Tx) error {
+			for i, outpoint := range outpoints {
+				var err error
+				if entries[i], err = dbFetchUtxoEntry(dbTx, utxoSetBucketName, outpoint); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return entries
+	}
+	// The first block creates the first two outputs, which are only in the database after a flush.
+	view := NewUtxoViewpoint()
+	view.addTxOut(outpoints[0], txOut, false, 1)
+	view.addTxOut(outpoints[1], txOut, false, 1)
+	view.SetBestHash(&chainhash.Hash{1})
+	cache.commit(view)
+	if entries := dbEntries(); entries[0] != nil || entries[1] != nil {
+		t.Fatal("outputs written to the database before a flush")
+	}
+	if err = cache.flush(false); err != nil {
+		t.Fatal(err)
+	}
+	if entries := dbEntries(); entries[0] == nil || entries[1] == nil {
+		t.Fatal("outputs not written to the database by a flush")
+	}
+	// The second block spends the first output and creates the third, which the third block spends.
+	view = NewUtxoViewpoint()
+	err = cache.fetchEntries(view, map[wire.OutPoint]struct{}{outpoints[0]: {}})
+	if err != nil || view.LookupEntry(outpoints[0]) == nil {
+		t.Fatalf("fetched output %v, %v", view.LookupEntry(outpoints[0]), err)
+	}
+	view.LookupEntry(outpoints[0]).Spend()
+	view.addTxOut(outpoints[2], txOut, false, 2)
+	view.SetBestHash(&chainhash.Hash{2})
+	cache.commit(view)
+	view = NewUtxoViewpoint()
+	err = cache.fetchEntries(view, map[wire.OutPoint]struct{}{outpoints[0]: {}, outpoints[2]: {}})
+	if err != nil || view.LookupEntry(outpoints[0]) != nil || view.LookupEntry(outpoints[2]) == nil {
+		t.Fatalf("fetched spent output %v and new output %v, %v",
+			view.LookupEntry(outpoints[0]), view.LookupEntry(outpoints[2]), err)
+	}
+	view.LookupEntry(outpoints[2]).Spend()
+	view.SetBestHash(&chainhash.Hash{3})
+	cache.commit(view)
+	if _, ok := cache.entries[outpoints[2]]; ok {
+		t.Error("output created and spent between flushes kept in the cache")
+	}
+	if err = cache.flush(true); err != nil {
+		t.Fatal(err)
+	}
+	if entries := dbEntries(); entries[0] != nil || entries[1] == nil || entries[2] != nil {
+		t.Errorf("database holds outputs %v after the flush", entries)
+	}
+	if len(cache.entries) != 0 || cache.size != 0 {
+		t.Errorf("cache holds %d entries of %d bytes after evicting", len(cache.entries), cache.size)
+	}
+	var state *chainhash.Hash
+	chain.db.View(func(dbTx database.Tx) error {
+		state = dbFetchUtxoState(dbTx)
+		return nil
+	})
+	if state == nil || *state != (chainhash.Hash{3}) {
+		t.Errorf("utxo set in the database is at block %v", state)
+	}
+}
@@ -0,0 +1,477 @@
+package chain
+import (
+	"bufio"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	chainhash "git.parallelcoin.io/dev/9/pkg/chain/hash"
+	"git.parallelcoin.io/dev/9/pkg/chain/fork"
+	"git.parallelcoin.io/dev/9/pkg/chain/wire"
+	database "git.parallelcoin.io/dev/9/pkg/db"
+	"git.parallelcoin.io/dev/9/pkg/util"
+	cl "git.parallelcoin.io/dev/9/pkg/util/cl"
+)
+// utxoSnapshotMagic starts a snapshot of the utxo set written by DumpUtxoSet, followed by the network, the height of the block it was taken at, the number of transactions up to that block and the number of coins in it. Then come the headers of the chain up to the block as ExportHeaders writes them, the block itself, and each coin as its key and value in the utxo set bucket.
+var utxoSnapshotMagic = [4]byte{'9', 'u', 't', 'x'}
+// utxoSnapshotKeyName is the name of the db key used to store the block and hash of the utxo snapshot the chain state was loaded from, if it was.
+var utxoSnapshotKeyName = []byte("utxosnapshot")
+// snapshotValidationKeyName is the name of the db key used to store how far the blocks before the utxo snapshot the chain state was loaded from have been validated, until the utxo set built from them is found to match the snapshot.
+var snapshotValidationKeyName = []byte("utxosnapshotvalidation")
+// snapshotValidationBucketName is the name of the db bucket holding the utxo set built from the blocks before the utxo snapshot the chain state was loaded from as they are validated, in the same format as the utxo set bucket.
+var snapshotValidationBucketName = []byte("utxosnapshotvalidationset")
+const (
+	// utxoSnapshotPrefixSize is the size of the fields after the magic at the start of a utxo snapshot.
+	utxoSnapshotPrefixSize = 4 + 4 + 4 + 8 + 8
+	// maxUtxoSnapshotValueSize is the largest serialized utxo entry read from a snapshot, as an output script is only limited by the size of the block it is in.
+	maxUtxoSnapshotValueSize = wire.MaxBlockPayload
+)
+// UtxoSnapshot describes a snapshot of the utxo set: the block it was taken at, the number of transactions in the chain up to it, the number of coins in it, and the double SHA256 hash of the whole snapshot, which is what is signed or pinned to trust it.
+type UtxoSnapshot struct {
+	BaseHash   chainhash.Hash
+	BaseHeight int32
+	TotalTxns  uint64
+	Coins      uint64
+	Hash       chainhash.Hash
+}
+// snapshotValidation is how far the blocks before a utxo snapshot have been validated: the height of the last block validated and the number of transactions in the chain up to it.
+type snapshotValidation struct {
+	height    int32
+	totalTxns uint64
+}
+// snapshotHash returns the double SHA256 hash of what was written to or read through h.
+func snapshotHash(
+	h hash.Hash) chainhash.Hash {
+	return chainhash.Hash(sha256.Sum256(h.Sum(nil)))
+}
+// DumpUtxoSet writes a snapshot of the utxo set at the tip of the main chain to w, for LoadUtxoSnapshot to start a new node from, and returns its description. Blocks are not connected while it is written. This function is safe for concurrent access.
+func (
+	b *BlockChain,
+) DumpUtxoSet(
+	w io.Writer) (*UtxoSnapshot, error) {
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+	// The snapshot is read from the utxo set in the database, so the changes held in the cache are written to it first.
+	if err := b.utxoCache.flush(false); err != nil {
+		return nil, err
+	}
+	tip := b.bestChain.Tip()
+	if tip.height < 1 {
+		return nil, errors.New("there are no blocks after the genesis block to snapshot the utxo set at")
+	}
+	var snapshot *UtxoSnapshot
+	err := b.db.View(func(dbTx database.Tx) error {
+		var err error
+		snapshot, err = b.writeUtxoSnapshot(w, dbTx, utxoSetBucketName, tip, b.stateSnapshot.TotalTxns)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+// writeUtxoSnapshot writes a snapshot of the utxo set in the bucket with the passed name, which is at the block of the node with totalTxns transactions in the chain up to it, to w and returns its description.
+func (
+	b *BlockChain,
+) writeUtxoSnapshot(
+	w io.Writer, dbTx database.Tx, bucketName []byte, node *blockNode, totalTxns uint64) (*UtxoSnapshot, error) {
+	snapshot := &UtxoSnapshot{
+		BaseHash:   node.hash,
+		BaseHeight: node.height,
+		TotalTxns:  totalTxns,
+	}
+	block, err := dbFetchBlockByNode(dbTx, node)
+	if err != nil {
+		return nil, err
+	}
+	cursor := dbTx.Metadata().Bucket(bucketName).Cursor()
+	for ok := cursor.First(); ok; ok = cursor.Next() {
+		snapshot.Coins++
+	}
+	h := sha256.New()
+	bw := bufio.NewWriter(io.MultiWriter(w, h))
+	var prefix [4 + utxoSnapshotPrefixSize]byte
+	copy(prefix[:4], utxoSnapshotMagic[:])
+	byteOrder.PutUint32(prefix[4:8], uint32(b.chainParams.Net))
+	byteOrder.PutUint32(prefix[8:12], uint32(node.height))
+	byteOrder.PutUint64(prefix[12:20], snapshot.TotalTxns)
+	byteOrder.PutUint64(prefix[20:28], snapshot.Coins)
+	if _, err = bw.Write(prefix[:]); err != nil {
+		return nil, err
+	}
+	if err = b.writeHeaders(bw, node); err != nil {
+		return nil, err
+	}
+	if err = block.MsgBlock().Serialize(bw); err != nil {
+		return nil, err
+	}
+	cursor = dbTx.Metadata().Bucket(bucketName).Cursor()
+	for ok := cursor.First(); ok; ok = cursor.Next() {
+		if err = wire.WriteVarBytes(bw, 0, cursor.Key()); err != nil {
+			return nil, err
+		}
+		if err = wire.WriteVarBytes(bw, 0, cursor.Value()); err != nil {
+			return nil, err
+		}
+	}
+	if err = bw.Flush(); err != nil {
+		return nil, err
+	}
+	snapshot.Hash = snapshotHash(h)
+	return snapshot, nil
+}
+// HashUtxoSnapshot returns the double SHA256 hash of the snapshot read from r, to check it is trusted before loading it.
+func HashUtxoSnapshot(
+	r io.Reader) (*chainhash.Hash, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return nil, err
+	}
+	hash := snapshotHash(h)
+	return &hash, nil
+}
+// LoadUtxoSnapshot replaces the chain state of a chain that has no blocks after the genesis block with the snapshot DumpUtxoSet wrote to r, which must hash to hash, and returns its description. The headers in it must have valid proof of work and pass through the checkpoints, but the blocks before the one it was taken at are assumed valid and are not stored until ValidateSnapshotBlock validates them in the background, so the chain can not be reorganized below it. Indexes need every block and must not be enabled. This function is safe for concurrent access.
+func (
+	b *BlockChain,
+) LoadUtxoSnapshot(
+	r io.Reader, hash *chainhash.Hash, interrupt <-chan struct{}) (*UtxoSnapshot, error) {
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+	genesis := b.bestChain.Tip()
+	if genesis.height != 0 {
+		return nil, fmt.Errorf("a utxo snapshot can only be loaded by a node with no blocks after the genesis block, this one has %d", genesis.height)
+	}
+	if b.indexManager != nil {
+		return nil, errors.New("a utxo snapshot can not be loaded with indexes enabled, as they need every block")
+	}
+	h := sha256.New()
+	br := bufio.NewReader(io.TeeReader(r, h))
+	var prefix [4 + utxoSnapshotPrefixSize]byte
+	if _, err := io.ReadFull(br, prefix[:]); err != nil {
+		return nil, fmt.Errorf("reading utxo snapshot: %v", err)
+	}
+	if string(prefix[:4]) != string(utxoSnapshotMagic[:]) {
+		return nil, errors.New("not a utxo snapshot")
+	}
+	if net := wire.BitcoinNet(byteOrder.Uint32(prefix[4:8])); net != b.chainParams.Net {
+		return nil, fmt.Errorf("utxo snapshot is for network %v, not %v", net, b.chainParams.Net)
+	}
+	snapshot := &UtxoSnapshot{
+		BaseHeight: int32(byteOrder.Uint32(prefix[8:12])),
+		TotalTxns:  byteOrder.Uint64(prefix[12:20]),
+		Coins:      byteOrder.Uint64(prefix[20:28]),
+	}
+	headers, err := readHeaders(br, b.chainParams)
+	if err != nil {
+		return nil, err
+	}
+	if snapshot.BaseHeight < 1 || len(headers) != int(snapshot.BaseHeight) {
+		return nil, fmt.Errorf("utxo snapshot at height %d has %d headers", snapshot.BaseHeight, len(headers))
+	}
+	if err = CheckHeaders(headers, b.checkpoints, interrupt); err != nil {
+		return nil, err
+	}
+	var msgBlock wire.MsgBlock
+	if err = msgBlock.Deserialize(br); err != nil {
+		return nil, fmt.Errorf("reading the block of the utxo snapshot: %v", err)
+	}
+	block := util.NewBlock(&msgBlock)
+	block.SetHeight(snapshot.BaseHeight)
+	snapshot.BaseHash = *block.Hash()
+	if want := headers[len(headers)-1].BlockHash(); !snapshot.BaseHash.IsEqual(&want) {
+		return nil, fmt.Errorf("utxo snapshot block %v is not the last of its headers, %v", snapshot.BaseHash, want)
+	}
+	powLimit := fork.GetMinDiff(fork.GetAlgoName(msgBlock.Header.Version, snapshot.BaseHeight), snapshot.BaseHeight)
+	if err = checkBlockSanity(block, powLimit, b.timeSource, BFNone, false, snapshot.BaseHeight); err != nil {
+		return nil, err
+	}
+	// The blocks before the snapshot are only known by their headers, and are taken as valid as the snapshot is trusted.
+	nodes := make([]*blockNode, len(headers))
+	parent := genesis
+	for i := range headers {
+		nodes[i] = newBlockNode(&headers[i], parent)
+		nodes[i].status = statusValid
+		parent = nodes[i]
+	}
+	base := parent
+	base.status = statusDataStored | statusValid
+	validation := &snapshotValidation{totalTxns: b.stateSnapshot.TotalTxns}
+	state := newBestState(base, uint64(msgBlock.SerializeSize()), uint64(GetBlockWeight(block)),
+		uint64(len(msgBlock.Transactions)), snapshot.TotalTxns, base.CalcPastMedianTime())
+	err = b.db.Update(func(dbTx database.Tx) error {
+		utxoBucket := dbTx.Metadata().Bucket(utxoSetBucketName)
+		for i := uint64(0); i < snapshot.Coins; i++ {
+			if i%100000 == 0 && interruptRequested(interrupt) {
+				return errInterruptRequested
+			}
+			key, err := wire.ReadVarBytes(br, 0, uint32(chainhash.HashSize+maxUint32VLQSerializeSize), "outpoint")
+			if err != nil {
+				return fmt.Errorf("reading coin %d of %d: %v", i+1, snapshot.Coins, err)
+			}
+			value, err := wire.ReadVarBytes(br, 0, maxUtxoSnapshotValueSize, "utxo")
+			if err != nil {
+				return fmt.Errorf("reading coin %d of %d: %v", i+1, snapshot.Coins, err)
+			}
+			if len(key) <= chainhash.HashSize {
+				return fmt.Errorf("coin %d of %d has no output index", i+1, snapshot.Coins)
+			}
+			if _, err = deserializeUtxoEntry(value); err != nil {
+				return fmt.Errorf("coin %d of %d: %v", i+1, snapshot.Coins, err)
+			}
+			if err = utxoBucket.Put(key, value); err != nil {
+				return err
+			}
+		}
+		// Reading to the end makes sure everything after the coins is hashed too.
+		if _, err := br.Peek(1); err != io.EOF {
+			return errors.New("utxo snapshot has data after its last coin")
+		}
+		if snapshot.Hash = snapshotHash(h); !snapshot.Hash.IsEqual(hash) {
+			return fmt.Errorf("utxo snapshot hashes to %v, not %v", snapshot.Hash, hash)
+		}
+		for _, node := range nodes {
+			if err := dbStoreBlockNode(dbTx, node); err != nil {
+				return err
+			}
+			if err := dbPutBlockIndex(dbTx, &node.hash, node.height); err != nil {
+				return err
+			}
+		}
+		if err := dbStoreBlock(dbTx, block); err != nil {
+			return err
+		}
+		if err := dbPutBestState(dbTx, state, base.workSum); err != nil {
+			return err
+		}
+		if err := dbPutUtxoState(dbTx, &base.hash); err != nil {
+			return err
+		}
+		// The utxo set is built again from the genesis block as the blocks before the snapshot are validated.
+		if _, err := dbTx.Metadata().CreateBucketIfNotExists(snapshotValidationBucketName); err != nil {
+			return err
+		}
+		if err := dbPutSnapshotValidation(dbTx, validation); err != nil {
+			return err
+		}
+		return dbPutUtxoSnapshot(dbTx, snapshot)
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, node := range nodes {
+		b.Index.addNode(node)
+	}
+	b.bestChain.SetTip(base)
+	// The latest checkpoint is searched for again from the new tip.
+	b.checkpointNode, b.nextCheckpoint = nil, nil
+	b.utxoCache.mtx.Lock()
+	b.utxoCache.bestHash = base.hash
+	b.utxoCache.flushedHash = base.hash
+	b.utxoCache.mtx.Unlock()
+	b.utxoSnapshot = snapshot
+	b.snapshotValidation = validation
+	b.stateLock.Lock()
+	b.stateSnapshot = state
+	b.stateLock.Unlock()
+	log <- cl.Infof{
+		"loaded %d coins of the utxo set at block %v (height %d), the blocks before it are assumed valid until they are validated in the background",
+		snapshot.Coins, snapshot.BaseHash, snapshot.BaseHeight,
+	}
+	return snapshot, nil
+}
+// UtxoSnapshot returns the snapshot the chain state was loaded from, or nil if it was built from every block. This function is safe for concurrent access.
+func (b *BlockChain) UtxoSnapshot() *UtxoSnapshot {
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+	return b.utxoSnapshot
+}
+// NextSnapshotBlocks returns the hashes of up to max of the blocks before the utxo snapshot the chain state was loaded from that are next to be validated by ValidateSnapshotBlock, in order, or none when there is no snapshot or there are no more of its blocks to validate. This function is safe for concurrent access.
+func (
+	b *BlockChain,
+) NextSnapshotBlocks(
+	max int) []*chainhash.Hash {
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+	if b.utxoSnapshot == nil || b.snapshotValidation == nil {
+		return nil
+	}
+	var hashes []*chainhash.Hash
+	for height := b.snapshotValidation.height + 1; height <= b.utxoSnapshot.BaseHeight && len(hashes) < max; height++ {
+		hashes = append(hashes, &b.bestChain.NodeByHeight(height).hash)
+	}
+	return hashes
+}
+// ValidateSnapshotBlock fully validates the block before the utxo snapshot the chain state was loaded from that is next to be validated, connecting it to a utxo set built again from the genesis block, and stores it. When it is the block the snapshot was taken at, the utxo set built from the blocks must hash to the snapshot in the form DumpUtxoSet writes it, or the snapshot, and the chain state loaded from it, are not valid and an error is returned. A block that is not valid is not stored and its rule error is returned. This function is safe for concurrent access.
+func (
+	b *BlockChain,
+) ValidateSnapshotBlock(
+	block *util.Block) error {
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+	validation := b.snapshotValidation
+	if b.utxoSnapshot == nil || validation == nil || validation.height >= b.utxoSnapshot.BaseHeight {
+		return errors.New("there are no blocks before a utxo snapshot to validate")
+	}
+	node := b.bestChain.NodeByHeight(validation.height + 1)
+	if !node.hash.IsEqual(block.Hash()) {
+		return fmt.Errorf("block %v is not block %d before the utxo snapshot, %v, which is the next to validate",
+			block.Hash(), node.height, node.hash)
+	}
+	block.SetHeight(node.height)
+	powLimit := fork.GetMinDiff(fork.GetAlgoName(node.version, node.height), node.height)
+	// Its header was checked with the others in the snapshot when it was loaded.
+	if err := checkBlockSanity(block, powLimit, b.timeSource, BFNone, false, node.height); err != nil {
+		return err
+	}
+	// The view is given every output the block spends or creates from the utxo set being built, so checking the block does not read the utxo set of the chain.
+	view := NewUtxoViewpoint()
+	view.SetBestHash(&node.parent.hash)
+	err := b.db.View(func(dbTx database.Tx) error {
+		for i, tx := range block.Transactions() {
+			if i > 0 {
+				for _, txIn := range tx.MsgTx().TxIn {
+					if err := fetchSnapshotValidationEntry(dbTx, view, txIn.PreviousOutPoint); err != nil {
+						return err
+					}
+				}
+			}
+			prevOut := wire.OutPoint{Hash: *tx.Hash()}
+			for txOutIdx := range tx.MsgTx().TxOut {
+				prevOut.Index = uint32(txOutIdx)
+				if err := fetchSnapshotValidationEntry(dbTx, view, prevOut); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if err = b.checkConnectBlock(node, block, view, nil); err != nil {
+		return err
+	}
+	next := &snapshotValidation{
+		height:    node.height,
+		totalTxns: validation.totalTxns + uint64(len(block.Transactions())),
+	}
+	err = b.db.Update(func(dbTx database.Tx) error {
+		if err := dbPutUtxoView(dbTx, snapshotValidationBucketName, view); err != nil {
+			return err
+		}
+		if err := dbStoreBlock(dbTx, block); err != nil {
+			return err
+		}
+		return dbPutSnapshotValidation(dbTx, next)
+	})
+	if err != nil {
+		return err
+	}
+	b.snapshotValidation = next
+	b.Index.SetStatusFlags(node, statusDataStored)
+	if err = b.Index.flushToDB(); err != nil {
+		return err
+	}
+	if node.height < b.utxoSnapshot.BaseHeight {
+		return nil
+	}
+	return b.finishSnapshotValidation(node)
+}
+// finishSnapshotValidation compares the utxo set built from the blocks up to the one the utxo snapshot was taken at, the block of the node, with the snapshot, and when they match drops it and stops validating. Otherwise the snapshot is not valid, which is logged and returned as an error, and the utxo set built is kept. This function MUST be called with the chain lock held (for writes).
+func (
+	b *BlockChain,
+) finishSnapshotValidation(
+	node *blockNode) error {
+	var built *UtxoSnapshot
+	err := b.db.View(func(dbTx database.Tx) error {
+		var err error
+		built, err = b.writeUtxoSnapshot(ioutil.Discard, dbTx, snapshotValidationBucketName, node, b.snapshotValidation.totalTxns)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if !built.Hash.IsEqual(&b.utxoSnapshot.Hash) {
+		err = fmt.Errorf("the utxo set built from the blocks up to the utxo snapshot at block %v hashes to %v with %d coins, not %v with %d coins as the snapshot the chain state was loaded from does",
+			node.hash, built.Hash, built.Coins, b.utxoSnapshot.Hash, b.utxoSnapshot.Coins)
+		log <- cl.Error{err}
+		return err
+	}
+	err = b.db.Update(func(dbTx database.Tx) error {
+		if err := dbTx.Metadata().DeleteBucket(snapshotValidationBucketName); err != nil {
+			return err
+		}
+		return dbTx.Metadata().Delete(snapshotValidationKeyName)
+	})
+	if err != nil {
+		return err
+	}
+	b.snapshotValidation = nil
+	log <- cl.Infof{
+		"validated the %d blocks up to the utxo snapshot at block %v, the utxo set built from them matches it",
+		node.height, node.hash,
+	}
+	return nil
+}
+// fetchSnapshotValidationEntry loads the outpoint into the view from the utxo set being built from the blocks before the utxo snapshot, unless the view has it, leaving a nil entry when it is not in the set.
+func fetchSnapshotValidationEntry(
+	dbTx database.Tx, view *UtxoViewpoint, outpoint wire.OutPoint) error {
+	if _, ok := view.entries[outpoint]; ok {
+		return nil
+	}
+	entry, err := dbFetchUtxoEntry(dbTx, snapshotValidationBucketName, outpoint)
+	if err != nil {
+		return err
+	}
+	view.entries[outpoint] = entry
+	return nil
+}
+// dbPutUtxoSnapshot stores the description of the snapshot the chain state was loaded from.
+func dbPutUtxoSnapshot(
+	dbTx database.Tx, snapshot *UtxoSnapshot) error {
+	serialized := make([]byte, chainhash.HashSize*2+4+8+8)
+	copy(serialized, snapshot.BaseHash[:])
+	byteOrder.PutUint32(serialized[32:36], uint32(snapshot.BaseHeight))
+	byteOrder.PutUint64(serialized[36:44], snapshot.TotalTxns)
+	byteOrder.PutUint64(serialized[44:52], snapshot.Coins)
+	copy(serialized[52:], snapshot.Hash[:])
+	return dbTx.Metadata().Put(utxoSnapshotKeyName, serialized)
+}
+// dbFetchUtxoSnapshot returns the description of the snapshot the chain state was loaded from, or nil if it was not.
+func dbFetchUtxoSnapshot(
+	dbTx database.Tx) *UtxoSnapshot {
+	serialized := dbTx.Metadata().Get(utxoSnapshotKeyName)
+	if len(serialized) != chainhash.HashSize*2+4+8+8 {
+		return nil
+	}
+	snapshot := &UtxoSnapshot{
+		BaseHeight: int32(byteOrder.Uint32(serialized[32:36])),
+		TotalTxns:  byteOrder.Uint64(serialized[36:44]),
+		Coins:      byteOrder.Uint64(serialized[44:52]),
+	}
+	copy(snapshot.BaseHash[:], serialized[:32])
+	copy(snapshot.Hash[:], serialized[52:])
+	return snapshot
+}
+// dbPutSnapshotValidation stores how far the blocks before the utxo snapshot the chain state was loaded from have been validated.
+func dbPutSnapshotValidation(
+	dbTx database.Tx, validation *snapshotValidation) error {
+	serialized := make([]byte, 4+8)
+	byteOrder.PutUint32(serialized[0:4], uint32(validation.height))
+	byteOrder.PutUint64(serialized[4:12], validation.totalTxns)
+	return dbTx.Metadata().Put(snapshotValidationKeyName, serialized)
+}
+// dbFetchSnapshotValidation returns how far the blocks before the utxo snapshot the chain state was loaded from have been validated, or nil if there is no snapshot or the utxo set built from them was found to match it.
+func dbFetchSnapshotValidation(
+	dbTx database.Tx) *snapshotValidation {
+	serialized := dbTx.Metadata().Get(snapshotValidationKeyName)
+	if len(serialized) != 4+8 {
+		return nil
+	}
+	return &snapshotValidation{
+		height:    int32(byteOrder.Uint32(serialized[0:4])),
+		totalTxns: byteOrder.Uint64(serialized[4:12]),
+	}
+}
@@ -0,0 +1,175 @@
+package chain_test
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	blockchain "git.parallelcoin.io/dev/9/pkg/chain"
+	"git.parallelcoin.io/dev/9/pkg/chain/chaingen"
+	chaincfg "git.parallelcoin.io/dev/9/pkg/chain/config"
+	chainhash "git.parallelcoin.io/dev/9/pkg/chain/hash"
+	txscript "git.parallelcoin.io/dev/9/pkg/chain/tx/script"
+	"git.parallelcoin.io/dev/9/pkg/chain/wire"
+	database "git.parallelcoin.io/dev/9/pkg/db"
+	_ "git.parallelcoin.io/dev/9/pkg/db/ffldb"
+)
+// newSnapshotChain returns a chain in a new database in dir
+func newSnapshotChain(
+	t *testing.T, dir string, params *chaincfg.Params) (*blockchain.BlockChain, database.DB) {
+	db, err := database.Create("ffldb", dir, params.Net)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain, err := blockchain.New(&blockchain.Config{
+		DB:          db,
+		ChainParams: params,
+		TimeSource:  blockchain.NewMedianTime(),
+		SigCache:    txscript.NewSigCache(1000),
+	})
+	if err != nil {
+		db.Close()
+		t.Fatal(err)
+	}
+	return chain, db
+}
+// runScenario builds the blocks of the scenario into the chain of the generator
+func runScenario(
+	t *testing.T, g *chaingen.Generator, scenario string) {
+	s, err := chaingen.ParseScenario(strings.NewReader(scenario))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = g.Run(s, nil); err != nil {
+		t.Fatal(err)
+	}
+}
+// validateSnapshotBlocks validates the blocks of the source chain before the utxo snapshot loaded by the chain, in order, and returns the error of the last
+func validateSnapshotBlocks(
+	t *testing.T, source, chain *blockchain.BlockChain) error {
+	hashes := chain.NextSnapshotBlocks(100)
+	if len(hashes) != int(chain.UtxoSnapshot().BaseHeight) {
+		t.Fatalf("%d blocks before the snapshot at height %d are to be validated", len(hashes), chain.UtxoSnapshot().BaseHeight)
+	}
+	for _, hash := range hashes {
+		block, err := source.BlockByHash(hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = chain.ValidateSnapshotBlock(block); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+// TestUtxoSnapshot ensures a node loading the utxo snapshot of another has its chain state and utxo set, connects the blocks after it, validates the blocks before it against it, and that a snapshot with another hash than the one trusted is not loaded
+func TestUtxoSnapshot(
+	t *testing.T) {
+	dir, err := ioutil.TempDir("", "utxosnapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	params := chaincfg.SimNetParams
+	params.CoinbaseMaturity = 1
+	source, sourceDB := newSnapshotChain(t, filepath.Join(dir, "source"), &params)
+	defer sourceDB.Close()
+	g, err := chaingen.New(source, &params, []byte("utxosnapshot"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	runScenario(t, g, "blocks 1\nspend 1\n")
+	var buf bytes.Buffer
+	snapshot, err := source.DumpUtxoSet(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	best := source.BestSnapshot()
+	if snapshot.BaseHash != best.Hash || snapshot.BaseHeight != best.Height || snapshot.TotalTxns != best.TotalTxns || snapshot.Coins == 0 {
+		t.Fatalf("the snapshot %+v is not of the tip %+v", snapshot, best)
+	}
+	hash, err := blockchain.HashUtxoSnapshot(bytes.NewReader(buf.Bytes()))
+	if err != nil || *hash != snapshot.Hash {
+		t.Fatalf("the snapshot hashes to %v, %v, not %v", hash, err, snapshot.Hash)
+	}
+	// a snapshot that is not the one trusted is not loaded, and leaves the chain as it was
+	dest, destDB := newSnapshotChain(t, filepath.Join(dir, "dest"), &params)
+	defer destDB.Close()
+	if _, err = dest.LoadUtxoSnapshot(bytes.NewReader(buf.Bytes()), &chainhash.Hash{1}, nil); err == nil {
+		t.Fatal("a snapshot with another hash was loaded")
+	}
+	if dest.BestSnapshot().Height != 0 || dest.UtxoSnapshot() != nil {
+		t.Fatal("the snapshot that was not loaded changed the chain")
+	}
+	loaded, err := dest.LoadUtxoSnapshot(bytes.NewReader(buf.Bytes()), hash, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *loaded != *snapshot {
+		t.Fatalf("loaded snapshot %+v, dumped %+v", loaded, snapshot)
+	}
+	if got := dest.BestSnapshot(); got.Hash != best.Hash || got.Height != best.Height || got.TotalTxns != best.TotalTxns {
+		t.Fatalf("the chain is at %+v after loading the snapshot, not %+v", got, best)
+	}
+	block, err := source.BlockByHash(&best.Hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	coinbase := wire.OutPoint{Hash: *block.Transactions()[0].Hash()}
+	if entry, err := dest.FetchUtxoEntry(coinbase); err != nil || entry == nil || entry.BlockHeight() != best.Height {
+		t.Fatalf("the coinbase of the tip is %+v, %v after loading the snapshot", entry, err)
+	}
+	if _, err = dest.LoadUtxoSnapshot(bytes.NewReader(buf.Bytes()), hash, nil); err == nil {
+		t.Error("a snapshot was loaded over blocks")
+	}
+	// the blocks after the snapshot, spending coins from before it, connect to it
+	runScenario(t, g, "spend 1\n")
+	for height := best.Height + 1; height <= source.BestSnapshot().Height; height++ {
+		block, err := source.BlockByHeight(height)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if isMainChain, isOrphan, err := dest.ProcessBlock(block, blockchain.BFNone, height); err != nil || !isMainChain || isOrphan {
+			t.Fatalf("block %d after the snapshot: main chain %v, orphan %v, %v", height, isMainChain, isOrphan, err)
+		}
+	}
+	if got, want := dest.BestSnapshot(), source.BestSnapshot(); got.Hash != want.Hash || got.TotalTxns != want.TotalTxns {
+		t.Fatalf("the chain is at %+v after the blocks after the snapshot, not %+v", got, want)
+	}
+	// the blocks before the snapshot are validated in order, and are then stored
+	first, err := source.BlockByHeight(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = dest.ValidateSnapshotBlock(block); err == nil {
+		t.Fatal("the block of the snapshot was validated before the blocks before it")
+	}
+	if err = validateSnapshotBlocks(t, source, dest); err != nil {
+		t.Fatal(err)
+	}
+	if hashes := dest.NextSnapshotBlocks(100); len(hashes) != 0 {
+		t.Errorf("blocks %v are to be validated after the utxo set built from the blocks matched the snapshot", hashes)
+	}
+	if stored, err := dest.BlockByHeight(1); err != nil || *stored.Hash() != *first.Hash() {
+		t.Errorf("the first block is %v, %v after it was validated", stored, err)
+	}
+	// the utxo set built from the blocks does not match a snapshot that was changed and trusted with its new hash
+	changed := append([]byte(nil), buf.Bytes()...)
+	changed[12]++
+	hash, err = blockchain.HashUtxoSnapshot(bytes.NewReader(changed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, otherDB := newSnapshotChain(t, filepath.Join(dir, "other"), &params)
+	defer otherDB.Close()
+	if _, err = other.LoadUtxoSnapshot(bytes.NewReader(changed), hash, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err = validateSnapshotBlocks(t, source, other); err == nil {
+		t.Error("the utxo set built from the blocks matched a changed snapshot")
+	}
+	if hashes := other.NextSnapshotBlocks(100); len(hashes) != 0 {
+		t.Errorf("blocks %v are to be validated after the utxo set built from the blocks did not match the snapshot", hashes)
+	}
+}
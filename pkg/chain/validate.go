@@ -77,6 +77,7 @@ func (
 	view := NewUtxoViewpoint()
 	view.SetBestHash(&tip.hash)
 	newNode := newBlockNode(&header, tip)
+	newNode.versionBits = CoinbaseVersionBits(block.Transactions()[0])
 	return b.checkConnectBlock(newNode, block, view, nil)
 }
 // checkBIP0030 ensures blocks do not contain duplicate transactions which 'overwrite' older transactions that are not fully spent.  This prevents an attack where a coinbase and all of its dependent transactions could be duplicated to effectively revert the overwritten transactions to a single confirmation thereby making them vulnerable to a double spend.
@@ -95,7 +96,7 @@ func (
 			fetchSet[prevOut] = struct{}{}
 		}
 	}
-	err := view.fetchUtxos(b.db, fetchSet)
+	err := view.fetchUtxos(b.utxoCache, fetchSet)
 	if err != nil {
 		return err
 	}
@@ -280,7 +281,7 @@ func (
 		}
 	}
 	// Load all of the utxos referenced by the inputs for all transactions in the block don't already exist in the utxo view from the database. These utxo entries are needed for verification of things such as transaction inputs, counting pay-to-script-hashes, and scripts.
-	err := view.fetchInputUtxos(b.db, block)
+	err := view.fetchInputUtxos(b.utxoCache, block)
 	if err != nil {
 		return err
 	}
@@ -352,28 +353,22 @@ func (
 	if checkpoint != nil && node.height <= checkpoint.Height {
 		runScripts = false
 	}
-	// Blocks created after the BIP0016 activation time need to have the pay-to-script-hash checks enabled.
-	var scriptFlags txscript.ScriptFlags
-	if enforceBIP0016 {
-		scriptFlags |= txscript.ScriptBip16
+	// Likewise the scripts of the assume valid block and its ancestors are not run, as the block was configured as known to be good and commits to all of them, unless full verification was asked for.
+	if b.assumedValid(node) {
+		runScripts = false
 	}
-	// Enforce DER signatures for block versions 3+ once the historical activation threshold has been reached.  This is part of BIP0066.
-	blockHeader := &block.MsgBlock().Header
-	if blockHeader.Version >= 3 && node.height >= b.chainParams.BIP0066Height {
-		scriptFlags |= txscript.ScriptVerifyDERSignatures
+	if b.fullVerify {
+		runScripts = true
 	}
-	// Enforce CHECKLOCKTIMEVERIFY for block versions 4+ once the historical activation threshold has been reached.  This is part of BIP0065.
-	if blockHeader.Version >= 4 && node.height >= b.chainParams.BIP0065Height {
-		scriptFlags |= txscript.ScriptVerifyCheckLockTimeVerify
+	scriptFlags, err := b.blockScriptFlags(node)
+	if err != nil {
+		return err
 	}
-	// Enforce CHECKSEQUENCEVERIFY during all block validation checks once the soft-fork deployment is fully active.
 	csvState, err := b.deploymentState(node.parent, chaincfg.DeploymentCSV)
 	if err != nil {
 		return err
 	}
 	if csvState == ThresholdActive {
-		// If the CSV soft-fork is now active, then modify the scriptFlags to ensure that the CSV op code is properly validated during the script checks bleow.
-		scriptFlags |= txscript.ScriptVerifyCheckSequenceVerify
 		// We obtain the MTP of the *previous* block in order to determine if transactions in the current block are final.
 		medianTime := node.parent.CalcPastMedianTime()
 		// Additionally, if the CSV soft-fork package is now active, then we also enforce the relative sequence number based
@@ -394,11 +389,6 @@ func (
 			}
 		}
 	}
-	// Enforce the segwit soft-fork package once the soft-fork has shifted into the "active" version bits state.
-	if enforceSegWit {
-		scriptFlags |= txscript.ScriptVerifyWitness
-		scriptFlags |= txscript.ScriptStrictMultiSig
-	}
 	// Now that the inexpensive checks are done and have passed, verify the transactions are actually allowed to spend the coins by running the expensive ECDSA signature check scripts.  Doing this last helps prevent CPU exhaustion attacks.
 	if runScripts {
 		err := checkBlockScripts(block, view, scriptFlags, b.sigCache,
@@ -412,6 +402,43 @@ func (
 	view.SetBestHash(&node.hash)
 	return nil
 }
+// blockScriptFlags returns the flags the scripts of the block of the node are validated with, which depend on its time, its version and its height and on the soft-forks that are active after its parent. This function MUST be called with the chain state lock held (for writes).
+func (
+	b *BlockChain,
+) blockScriptFlags(
+	node *blockNode) (txscript.ScriptFlags, error) {
+	// Blocks created after the BIP0016 activation time need to have the pay-to-script-hash checks enabled.
+	var scriptFlags txscript.ScriptFlags
+	if node.timestamp >= txscript.Bip16Activation.Unix() {
+		scriptFlags |= txscript.ScriptBip16
+	}
+	// Enforce DER signatures for block versions 3+ once the historical activation threshold has been reached.  This is part of BIP0066.
+	if node.version >= 3 && node.height >= b.chainParams.BIP0066Height {
+		scriptFlags |= txscript.ScriptVerifyDERSignatures
+	}
+	// Enforce CHECKLOCKTIMEVERIFY for block versions 4+ once the historical activation threshold has been reached.  This is part of BIP0065.
+	if node.version >= 4 && node.height >= b.chainParams.BIP0065Height {
+		scriptFlags |= txscript.ScriptVerifyCheckLockTimeVerify
+	}
+	// Enforce CHECKSEQUENCEVERIFY during all block validation checks once the soft-fork deployment is fully active.
+	csvState, err := b.deploymentState(node.parent, chaincfg.DeploymentCSV)
+	if err != nil {
+		return 0, err
+	}
+	if csvState == ThresholdActive {
+		scriptFlags |= txscript.ScriptVerifyCheckSequenceVerify
+	}
+	// Enforce the segwit soft-fork package once the soft-fork has shifted into the "active" version bits state.
+	segwitState, err := b.deploymentState(node.parent, chaincfg.DeploymentSegwit)
+	if err != nil {
+		return 0, err
+	}
+	if segwitState == ThresholdActive {
+		scriptFlags |= txscript.ScriptVerifyWitness
+		scriptFlags |= txscript.ScriptStrictMultiSig
+	}
+	return scriptFlags, nil
+}
 // CalcBlockSubsidy returns the subsidy amount a block at the provided height should have. This is mainly used for determining how much the coinbase for newly generated blocks awards as well as validating the coinbase for blocks has the expected value. The subsidy is halved every SubsidyReductionInterval blocks.  Mathematically this is: baseSubsidy / 2^(height/SubsidyReductionInterval) At the target block generation rate for the main network, this is approximately every 4 years.
 // TODO: Add an exponential decay that returns the point on a precise 1/x^2 curve that creates exactly 5% reward at 1 year (31536000 seconds)
 func CalcBlockSubsidy(
@@ -1,7 +1,11 @@
 package chain
 import (
+	"bytes"
+	"encoding/binary"
 	"math"
 	chaincfg "git.parallelcoin.io/dev/9/pkg/chain/config"
+	txscript "git.parallelcoin.io/dev/9/pkg/chain/tx/script"
+	"git.parallelcoin.io/dev/9/pkg/util"
 	cl "git.parallelcoin.io/dev/9/pkg/util/cl"
 )
 const (
@@ -17,7 +21,39 @@ const (
 	unknownVerNumToCheck = 100
 	// unknownVerWarnNum is the threshold of previous blocks that have an unknown version to use for the purposes of warning the user.
 	unknownVerWarnNum = unknownVerNumToCheck / 2
+	// CoinbaseVersionBitsPkScriptLength is the length of the public key script containing an OpReturn, the VersionBitsMagicBytes and the version bits themselves.
+	CoinbaseVersionBitsPkScriptLength = 10
 )
+// VersionBitsMagicBytes is the prefix marker within the public key script of a coinbase output to indicate that this output holds the version bits of the block. The version of a block header identifies the proof of work algorithm of the block, so the deployments the miner of a block votes for are signalled in its coinbase instead, which nodes that do not know about it ignore.
+var VersionBitsMagicBytes = []byte{
+	txscript.OpReturn,
+	txscript.OpData8,
+	0x39,
+	0x76,
+	0x62,
+	0x73,
+}
+// VersionBitsScript returns the public key script of the coinbase output signalling the version bits.
+func VersionBitsScript(
+	version uint32) []byte {
+	pkScript := make([]byte, CoinbaseVersionBitsPkScriptLength)
+	copy(pkScript, VersionBitsMagicBytes)
+	binary.LittleEndian.PutUint32(pkScript[len(VersionBitsMagicBytes):], version)
+	return pkScript
+}
+// CoinbaseVersionBits returns the version bits signalled by the last coinbase output holding them, or zero when the coinbase has none. They are only counted from the CoinbaseBitsHeight of the chain parameters.
+func CoinbaseVersionBits(
+	coinbase *util.Tx) uint32 {
+	txOuts := coinbase.MsgTx().TxOut
+	for i := len(txOuts) - 1; i >= 0; i-- {
+		pkScript := txOuts[i].PkScript
+		if len(pkScript) == CoinbaseVersionBitsPkScriptLength &&
+			bytes.HasPrefix(pkScript, VersionBitsMagicBytes) {
+			return binary.LittleEndian.Uint32(pkScript[len(VersionBitsMagicBytes):])
+		}
+	}
+	return 0
+}
 // bitConditionChecker provides a thresholdConditionChecker which can be used to test whether or not a specific bit is set when it's not supposed to be according to the expected version based on the known deployments and the current state of the chain.  This is useful for detecting and warning about unknown rule activations.
 type bitConditionChecker struct {
 	bit   uint32
@@ -44,7 +80,7 @@ func (c bitConditionChecker) MinerConfirmationWindow() uint32 {
 // Condition returns true when the specific bit associated with the checker is set and it's not supposed to be according to the expected version based on the known deployments and the current state of the chain. This function MUST be called with the chain state lock held (for writes). This is part of the thresholdConditionChecker interface implementation.
 func (c bitConditionChecker) Condition(node *blockNode) (bool, error) {
 	conditionMask := uint32(1) << c.bit
-	version := uint32(node.version)
+	version := c.chain.signalledVersion(node)
 	if version&vbTopMask != vbTopBits {
 		return false, nil
 	}
@@ -80,13 +116,21 @@ func (c deploymentChecker) RuleChangeActivationThreshold() uint32 {
 func (c deploymentChecker) MinerConfirmationWindow() uint32 {
 	return c.chain.chainParams.MinerConfirmationWindow
 }
-// Condition returns true when the specific bit defined by the deployment associated with the checker is set. This is part of the thresholdConditionChecker interface implementation.
+// Condition returns true when the specific bit defined by the deployment associated with the checker is set in the version bits the block signals. This function MUST be called with the chain state lock held (for writes). This is part of the thresholdConditionChecker interface implementation.
 func (c deploymentChecker) Condition(node *blockNode) (bool, error) {
 	conditionMask := uint32(1) << c.deployment.BitNumber
-	version := uint32(node.version)
+	version := c.chain.signalledVersion(node)
 	return (version&vbTopMask == vbTopBits) && (version&conditionMask != 0),
 		nil
 }
+// signalledVersion returns the version bits the block signals, which are those of its version below the CoinbaseBitsHeight of the chain parameters and those of its coinbase from there on.
+func (b *BlockChain) signalledVersion(
+	node *blockNode) uint32 {
+	if node.height >= b.chainParams.CoinbaseBitsHeight {
+		return node.versionBits
+	}
+	return uint32(node.version)
+}
 // calcNextBlockVersion calculates the expected version of the block after the passed previous block node based on the state of started and locked in rule change deployments. This function differs from the exported CalcNextBlockVersion in that the exported version uses the current best chain as the previous block node while this function accepts any block node. This function MUST be called with the chain state lock held (for writes).
 func (b *BlockChain) calcNextBlockVersion(prevNode *blockNode) (uint32, error) {
 	// Set the appropriate bits for each actively defined rule deployment that is either in the process of being voted on, or locked in for the/ activation at the next threshold window change.
@@ -112,6 +156,20 @@ func (b *BlockChain) CalcNextBlockVersion() (uint32, error) {
 	b.chainLock.Unlock()
 	return version, err
 }
+// CalcNextVersionBitsScript returns the public key script of the coinbase output signalling the deployments that are being voted on or are locked in for the block after the end of the current best chain, or nil when there are none, or the block is below the CoinbaseBitsHeight of the chain parameters, and so the coinbase needs no such output. This function is safe for concurrent access.
+func (b *BlockChain) CalcNextVersionBitsScript() ([]byte, error) {
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+	tip := b.bestChain.Tip()
+	if tip.height+1 < b.chainParams.CoinbaseBitsHeight {
+		return nil, nil
+	}
+	version, err := b.calcNextBlockVersion(tip)
+	if err != nil || version == vbTopBits {
+		return nil, err
+	}
+	return VersionBitsScript(version), nil
+}
 // warnUnknownRuleActivations displays a warning when any unknown new rules are either about to activate or have been activated.  This will only happen once when new rules have been activated and every block for those about to be activated. This function MUST be called with the chain state lock held (for writes)
 func (b *BlockChain) warnUnknownRuleActivations(node *blockNode) error {
 	// Warn if any unknown new rules are either about to activate or have already been activated.
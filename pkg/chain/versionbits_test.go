@@ -0,0 +1,127 @@
+package chain
+import (
+	"math"
+	"testing"
+	"time"
+	chaincfg "git.parallelcoin.io/dev/9/pkg/chain/config"
+	"git.parallelcoin.io/dev/9/pkg/chain/wire"
+	"git.parallelcoin.io/dev/9/pkg/util"
+)
+// TestCoinbaseVersionBits ensures the version bits a coinbase signals are read back from the output VersionBitsScript makes, and that a coinbase without one signals none.
+func TestCoinbaseVersionBits(
+	t *testing.T) {
+	msgTx := wire.NewMsgTx(wire.TxVersion)
+	msgTx.AddTxOut(&wire.TxOut{Value: 1, PkScript: []byte{0x51}})
+	if bits := CoinbaseVersionBits(util.NewTx(msgTx)); bits != 0 {
+		t.Errorf("coinbase without a version bits output signals %08x", bits)
+	}
+	version := uint32(vbTopBits | 1<<5)
+	msgTx.AddTxOut(&wire.TxOut{PkScript: VersionBitsScript(version)})
+	if bits := CoinbaseVersionBits(util.NewTx(msgTx)); bits != version {
+		t.Errorf("coinbase signals %08x, want %08x", bits, version)
+	}
+}
+// TestDeploymentStatus ensures a deployment is started, counts the blocks signalling it in their coinbase and locks in once enough of a window did.
+func TestDeploymentStatus(
+	t *testing.T) {
+	params := chaincfg.SimNetParams
+	params.Deployments[chaincfg.DeploymentTestDummy] = chaincfg.ConsensusDeployment{
+		BitNumber:  28,
+		StartTime:  0,
+		ExpireTime: math.MaxUint64,
+	}
+	chain := newFakeChain(&params)
+	window := int32(params.MinerConfirmationWindow)
+	node := chain.bestChain.Tip()
+	nodes := make([]*blockNode, 0, 3*window)
+	for height := int32(1); height <= 3*window; height++ {
+		// One block more of the second window than the threshold signals.
+		var versionBits uint32
+		if height >= window && height <= window+int32(params.RuleChangeActivationThreshold) {
+			versionBits = vbTopBits | 1<<28
+		}
+		node = newFakeNode(node, 2, 0, time.Unix(int64(height)*60, 0))
+		node.versionBits = versionBits
+		chain.Index.AddNode(node)
+		nodes = append(nodes, node)
+	}
+	tests := []struct {
+		tip      int32
+		state    ThresholdState
+		since    int32
+		elapsed  uint32
+		count    uint32
+		possible bool
+	}{
+		{window / 2, ThresholdDefined, 0, 0, 0, false},
+		{window + window/2, ThresholdStarted, window, uint32(window/2 + 1), uint32(window/2 + 1), true},
+		{2*window + 10, ThresholdLockedIn, 2 * window, 0, 0, false},
+	}
+	for _, test := range tests {
+		chain.bestChain.SetTip(nodes[test.tip-1])
+		status, err := chain.DeploymentStatus(chaincfg.DeploymentTestDummy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status.State != test.state || status.Since != test.since {
+			t.Errorf("at height %d the deployment is %v since %d, want %v since %d",
+				test.tip, status.State, status.Since, test.state, test.since)
+		}
+		if status.Elapsed != test.elapsed || status.Count != test.count || status.Possible != test.possible {
+			t.Errorf("at height %d %d of %d blocks signal, possible %v, want %d of %d, possible %v",
+				test.tip, status.Count, status.Elapsed, status.Possible, test.count, test.elapsed, test.possible)
+		}
+	}
+}
+// TestCoinbaseVersionBitsDeployment ensures the version bits blocks signal in their coinbase are only counted from the CoinbaseBitsHeight of the chain parameters, with the block versions the algorithm IDs of valid blocks, which signal nothing.
+func TestCoinbaseVersionBitsDeployment(
+	t *testing.T) {
+	params := chaincfg.SimNetParams
+	params.Deployments[chaincfg.DeploymentTestDummy] = chaincfg.ConsensusDeployment{
+		BitNumber:  28,
+		StartTime:  0,
+		ExpireTime: math.MaxUint64,
+	}
+	window := int32(params.MinerConfirmationWindow)
+	params.CoinbaseBitsHeight = 2 * window
+	chain := newFakeChain(&params)
+	node := chain.bestChain.Tip()
+	for height := int32(1); height <= 4*window; height++ {
+		// The blocks alternate between the sha256d and scrypt versions. The dummy deployment is signalled in the coinbase in the second window, below the height, which does not count, and in the fourth, which does.
+		version := int32(2)
+		if height%2 == 1 {
+			version = 514
+		}
+		var versionBits uint32
+		if height/window == 1 || height/window == 3 {
+			versionBits = vbTopBits | 1<<28
+		}
+		node = newFakeNode(node, version, 0, time.Unix(int64(height)*60, 0))
+		node.versionBits = versionBits
+		chain.Index.AddNode(node)
+		chain.bestChain.SetTip(node)
+		script, err := chain.CalcNextVersionBitsScript()
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The states are those of the block after the tip.
+		active := height+1 >= params.CoinbaseBitsHeight
+		if (script != nil) != active {
+			t.Fatalf("at height %d the coinbase of the next block signals %x", height, script)
+		}
+		state, err := chain.ThresholdState(chaincfg.DeploymentTestDummy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := ThresholdStarted
+		switch {
+		case height < window-1:
+			want = ThresholdDefined
+		case height >= 4*window-1:
+			want = ThresholdLockedIn
+		}
+		if state != want {
+			t.Fatalf("at height %d the dummy deployment is %v, want %v", height, state, want)
+		}
+	}
+}
@@ -1370,7 +1370,7 @@ func openDB(
 	}
 	// Create the block store which includes scanning the existing flat block files to find what the current write cursor position is according to the data that is actually on disk.  Also create the database cache which wraps the underlying leveldb database to provide write caching.
 	store := newBlockStore(dbPath, network)
-	cache := newDbCache(ldb, store, defaultCacheSize, defaultFlushSecs)
+	cache := newDbCache(ldb, store, CacheSize, defaultFlushSecs)
 	pdb := &db{store: store, cache: cache}
 	// Perform any reconciliation needed between the block and metadata as well as database initialization, if needed.
 	return reconcileDB(pdb, create)
@@ -20,6 +20,8 @@ const (
 	ldbRecordIKeySize = 8
 	// These are used to help preallocate space needed for a batch in one allocation instead of letting leveldb itself constantly grow it. This results in far less pressure on the GC and consequently helps prevent the GC from allocating a lot of extra unneeded space.
 )
+// CacheSize is the size of the cache of databases opened after it is set, which the node sizes to its memory limit.
+var CacheSize uint64 = defaultCacheSize
 // ldbCacheIter wraps a treap iterator to provide the additional functionality needed to satisfy the leveldb iterator.Iterator interface.
 type ldbCacheIter struct {
 	*treap.Iterator
@@ -315,7 +315,7 @@ func (cm *ConnManager) Connect(c *ConnReq) {
 	}
 	log <- cl.Debugf{"attempting to connect to '%s'", c.Addr}
 	conn, err := cm.cfg.Dial(c.Addr)
-	log <- cl.Trace{cl.Ine(), err, c.Addr}
+	log <- cl.Trace{err, c.Addr, cl.Here("trace")}
 	if err != nil {
 		select {
 		case cm.requests <- handleFailed{c, err}:
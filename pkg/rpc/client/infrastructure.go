@@ -141,6 +141,8 @@ func (c *Client) trackRegisteredNtfns(cmd interface{}) {
 	switch bcmd := cmd.(type) {
 	case *json.NotifyBlocksCmd:
 		c.ntfnState.notifyBlocks = true
+	case *json.NotifyWorkCmd:
+		c.ntfnState.notifyWork = true
 	case *json.NotifyNewTransactionsCmd:
 		if bcmd.Verbose != nil && *bcmd.Verbose {
 			c.ntfnState.notifyNewTxVerbose = true
@@ -353,6 +355,13 @@ func (c *Client) reregisterNtfns() error {
 			return err
 		}
 	}
+	// Reregister notifywork if needed.
+	if stateCopy.notifyWork {
+		log <- cl.Dbg("reregistering [notifywork]")
+		if err := c.NotifyWork(); err != nil {
+			return err
+		}
+	}
 	// Reregister notifynewtransactions if needed.
 	if stateCopy.notifyNewTx || stateCopy.notifyNewTxVerbose {
 		log <- cl.Debugf{
@@ -40,6 +40,15 @@ func (c *Client) GenerateAsync(numBlocks uint32) FutureGenerateResult {
 func (c *Client) Generate(numBlocks uint32) ([]*chainhash.Hash, error) {
 	return c.GenerateAsync(numBlocks).Receive()
 }
+// GenerateToAddressAsync returns an instance of a type that can be used to get the result of the RPC at some future time by invoking the Receive function on the returned instance. See GenerateToAddress for the blocking version and more details.
+func (c *Client) GenerateToAddressAsync(numBlocks uint32, address util.Address, maxTries *uint64) FutureGenerateResult {
+	cmd := json.NewGenerateToAddressCmd(numBlocks, address.EncodeAddress(), maxTries)
+	return c.sendCmd(cmd)
+}
+// GenerateToAddress generates numBlocks blocks paying to the passed address at once on the regression and simulation test networks and returns their hashes.
+func (c *Client) GenerateToAddress(numBlocks uint32, address util.Address, maxTries *uint64) ([]*chainhash.Hash, error) {
+	return c.GenerateToAddressAsync(numBlocks, address, maxTries).Receive()
+}
 // FutureGetGenerateResult is a future promise to deliver the result of a GetGenerateAsync RPC invocation (or an applicable error).
 type FutureGetGenerateResult chan *response
 // Receive waits for the response promised by the future and returns true if the server is set to mine, otherwise false.
@@ -74,7 +83,7 @@ func (r FutureSetGenerateResult) Receive() error {
 }
 // SetGenerateAsync returns an instance of a type that can be used to get the result of the RPC at some future time by invoking the Receive function on the returned instance. See SetGenerate for the blocking version and more details.
 func (c *Client) SetGenerateAsync(enable bool, numCPUs int) FutureSetGenerateResult {
-	cmd := json.NewSetGenerateCmd(enable, &numCPUs)
+	cmd := json.NewSetGenerateCmd(enable, &numCPUs, nil, nil)
 	return c.sendCmd(cmd)
 }
 // SetGenerate sets the server to generate coins (mine) or not.
@@ -149,7 +158,7 @@ func (r FutureGetNetworkHashPS) Receive() (int64, error) {
 }
 // GetNetworkHashPSAsync returns an instance of a type that can be used to get the result of the RPC at some future time by invoking the Receive function on the returned instance. See GetNetworkHashPS for the blocking version and more details.
 func (c *Client) GetNetworkHashPSAsync() FutureGetNetworkHashPS {
-	cmd := json.NewGetNetworkHashPSCmd(nil, nil)
+	cmd := json.NewGetNetworkHashPSCmd(nil, nil, nil)
 	return c.sendCmd(cmd)
 }
 // GetNetworkHashPS returns the estimated network hashes per second using the default number of blocks and the most recent block height. GetNetworkHashPS2 to override the number of blocks to use and GetNetworkHashPS3 to override the height at which to calculate the estimate.
@@ -158,7 +167,7 @@ func (c *Client) GetNetworkHashPS() (int64, error) {
 }
 // GetNetworkHashPS2Async returns an instance of a type that can be used to get the result of the RPC at some future time by invoking the Receive function on the returned instance. See GetNetworkHashPS2 for the blocking version and more details.
 func (c *Client) GetNetworkHashPS2Async(blocks int) FutureGetNetworkHashPS {
-	cmd := json.NewGetNetworkHashPSCmd(&blocks, nil)
+	cmd := json.NewGetNetworkHashPSCmd(&blocks, nil, nil)
 	return c.sendCmd(cmd)
 }
 // GetNetworkHashPS2 returns the estimated network hashes per second for the specified previous number of blocks working backwards from the most recent block height.  The blocks parameter can also be -1 in which case the number of blocks since the last difficulty change will be used. See GetNetworkHashPS to use defaults and GetNetworkHashPS3 to override the height at which to calculate the estimate.
@@ -167,13 +176,22 @@ func (c *Client) GetNetworkHashPS2(blocks int) (int64, error) {
 }
 // GetNetworkHashPS3Async returns an instance of a type that can be used to get the result of the RPC at some future time by invoking the Receive function on the returned instance. See GetNetworkHashPS3 for the blocking version and more details.
 func (c *Client) GetNetworkHashPS3Async(blocks, height int) FutureGetNetworkHashPS {
-	cmd := json.NewGetNetworkHashPSCmd(&blocks, &height)
+	cmd := json.NewGetNetworkHashPSCmd(&blocks, &height, nil)
 	return c.sendCmd(cmd)
 }
 // GetNetworkHashPS3 returns the estimated network hashes per second for the specified previous number of blocks working backwards from the specified block height.  The blocks parameter can also be -1 in which case the number of blocks since the last difficulty change will be used. See GetNetworkHashPS and GetNetworkHashPS2 to use defaults.
 func (c *Client) GetNetworkHashPS3(blocks, height int) (int64, error) {
 	return c.GetNetworkHashPS3Async(blocks, height).Receive()
 }
+// GetNetworkHashPS4Async returns an instance of a type that can be used to get the result of the RPC at some future time by invoking the Receive function on the returned instance. See GetNetworkHashPS4 for the blocking version and more details.
+func (c *Client) GetNetworkHashPS4Async(blocks, height int, algo string) FutureGetNetworkHashPS {
+	cmd := json.NewGetNetworkHashPSCmd(&blocks, &height, &algo)
+	return c.sendCmd(cmd)
+}
+// GetNetworkHashPS4 returns the estimated network hashes per second of one proof of work algorithm for the specified previous number of blocks working backwards from the specified block height.  Only the blocks mined with the algorithm count, but the time is that of all the blocks. See GetNetworkHashPS3 to estimate the hashes of all the algorithms together.
+func (c *Client) GetNetworkHashPS4(blocks, height int, algo string) (int64, error) {
+	return c.GetNetworkHashPS4Async(blocks, height, algo).Receive()
+}
 // FutureGetWork is a future promise to deliver the result of a GetWorkAsync RPC invocation (or an applicable error).
 type FutureGetWork chan *response
 // Receive waits for the response promised by the future and returns the hash data to work on.
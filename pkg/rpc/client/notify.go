@@ -22,6 +22,7 @@ type notificationState struct {
 	notifyBlocks       bool
 	notifyNewTx        bool
 	notifyNewTxVerbose bool
+	notifyWork         bool
 	notifyReceived     map[string]struct{}
 	notifySpent        map[json.OutPoint]struct{}
 }
@@ -31,6 +32,7 @@ func (s *notificationState) Copy() *notificationState {
 	stateCopy.notifyBlocks = s.notifyBlocks
 	stateCopy.notifyNewTx = s.notifyNewTx
 	stateCopy.notifyNewTxVerbose = s.notifyNewTxVerbose
+	stateCopy.notifyWork = s.notifyWork
 	stateCopy.notifyReceived = make(map[string]struct{})
 	for addr := range s.notifyReceived {
 		stateCopy.notifyReceived[addr] = struct{}{}
@@ -68,6 +70,8 @@ type NotificationHandlers struct {
 	OnBlockDisconnected func(hash *chainhash.Hash, height int32, t time.Time)
 	// OnFilteredBlockDisconnected is invoked when a block is disconnected from the longest (best) chain.  It will only be invoked if a preceding NotifyBlocks has been made to register for the notification and the call to function is non-nil.  Its parameters differ from OnBlockDisconnected: it receives the block's height and header.
 	OnFilteredBlockDisconnected func(height int32, header *wire.BlockHeader)
+	// OnChainReorganized is invoked when the longest (best) chain is reorganized onto another branch, after the blocks that were disconnected and connected were each notified.  It will only be invoked if a preceding call to NotifyBlocks has been made to register for the notification and the function is non-nil.
+	OnChainReorganized func(reorg *json.ReorgDetails)
 	// OnRecvTx is invoked when a transaction that receives funds to a registered address is received into the memory pool and also connected to the longest (best) chain.  It will only be invoked if a preceding call to NotifyReceived, Rescan, or RescanEndHeight has been made to register for the notification and the function is non-nil. NOTE: Deprecated. Use OnRelevantTxAccepted instead.
 	OnRecvTx func(transaction *util.Tx, details *json.BlockDetails)
 	// OnRedeemingTx is invoked when a transaction that spends a registered outpoint is received into the memory pool and also connected to the longest (best) chain.  It will only be invoked if a preceding call to NotifySpent, Rescan, or RescanEndHeight has been made to register for the notification and the function is non-nil.
@@ -84,6 +88,8 @@ type NotificationHandlers struct {
 	OnTxAccepted func(hash *chainhash.Hash, amount util.Amount)
 	// OnTxAccepted is invoked when a transaction is accepted into the memory pool.  It will only be invoked if a preceding call to NotifyNewTransactions with the verbose flag set to true has been made to register for the notification and the function is non-nil.
 	OnTxAcceptedVerbose func(txDetails *json.TxRawResult)
+	// OnWork is invoked with a new block template whenever the previous one becomes stale.  It will only be invoked if a preceding call to NotifyWork has been made to register for the notification and the function is non-nil.
+	OnWork func(reason string, template *json.GetBlockTemplateResult)
 	// OnPodConnected is invoked when a wallet connects or disconnects from pod.
 	// This will only be available when client is connected to a wallet server such as btcwallet.
 	OnPodConnected func(connected bool)
@@ -155,6 +161,18 @@ func (c *Client) handleNotification(ntfn *rawNotification) {
 		}
 		c.ntfnHandlers.OnFilteredBlockDisconnected(blockHeight,
 			blockHeader)
+	// OnChainReorganized
+	case json.ChainReorganizedNtfnMethod:
+		// Ignore the notification if the client is not interested in it.
+		if c.ntfnHandlers.OnChainReorganized == nil {
+			return
+		}
+		reorg, err := parseChainReorganizedNtfnParams(ntfn.Params)
+		if err != nil {
+			log <- cl.Warn{"received invalid chain reorganized notification:", err}
+			return
+		}
+		c.ntfnHandlers.OnChainReorganized(reorg)
 	// OnRecvTx
 	case json.RecvTxNtfnMethod:
 		// Ignore the notification if the client is not interested in it.
@@ -239,6 +257,18 @@ func (c *Client) handleNotification(ntfn *rawNotification) {
 			return
 		}
 		c.ntfnHandlers.OnTxAcceptedVerbose(rawTx)
+	// OnWork
+	case json.WorkNtfnMethod:
+		// Ignore the notification if the client is not interested in it.
+		if c.ntfnHandlers.OnWork == nil {
+			return
+		}
+		reason, template, err := parseWorkNtfnParams(ntfn.Params)
+		if err != nil {
+			log <- cl.Warn{"received invalid work notification:", err}
+			return
+		}
+		c.ntfnHandlers.OnWork(reason, template)
 	// OnPodConnected
 	case json.PodConnectedNtfnMethod:
 		// Ignore the notification if the client is not interested in it.
@@ -523,6 +553,40 @@ func parseTxAcceptedVerboseNtfnParams(
 	// TODO: change txacceptedverbose notification callbacks to use nicer types for all details about the transaction (i.e. decoding hashes from their string encoding).
 	return &rawTx, nil
 }
+// parseChainReorganizedNtfnParams parses out the details of the reorganization from the parameters of a chainreorganized notification.
+func parseChainReorganizedNtfnParams(
+	params []js.RawMessage) (*json.ReorgDetails, error) {
+	if len(params) != 1 {
+		return nil, wrongNumParams(len(params))
+	}
+	// Unmarshal first parameter as a reorganization details object.
+	var reorg json.ReorgDetails
+	err := js.Unmarshal(params[0], &reorg)
+	if err != nil {
+		return nil, err
+	}
+	return &reorg, nil
+}
+// parseWorkNtfnParams parses out the reason and the block template from the parameters of a work notification.
+func parseWorkNtfnParams(
+	params []js.RawMessage) (string, *json.GetBlockTemplateResult, error) {
+	if len(params) != 2 {
+		return "", nil, wrongNumParams(len(params))
+	}
+	// Unmarshal first parameter as a string.
+	var reason string
+	err := js.Unmarshal(params[0], &reason)
+	if err != nil {
+		return "", nil, err
+	}
+	// Unmarshal second parameter as a block template result object.
+	var template json.GetBlockTemplateResult
+	err = js.Unmarshal(params[1], &template)
+	if err != nil {
+		return "", nil, err
+	}
+	return reason, &template, nil
+}
 // parsePodConnectedNtfnParams parses out the connection status of pod and btcwallet from the parameters of a podconnected notification.
 func parsePodConnectedNtfnParams(
 	params []js.RawMessage) (bool, error) {
@@ -610,6 +674,30 @@ func (c *Client) NotifyBlocksAsync() FutureNotifyBlocksResult {
 func (c *Client) NotifyBlocks() error {
 	return c.NotifyBlocksAsync().Receive()
 }
+// FutureNotifyWorkResult is a future promise to deliver the result of a NotifyWorkAsync RPC invocation (or an applicable error).
+type FutureNotifyWorkResult chan *response
+// Receive waits for the response promised by the future and returns an error if the registration was not successful.
+func (r FutureNotifyWorkResult) Receive() error {
+	_, err := receiveFuture(r)
+	return err
+}
+// NotifyWorkAsync returns an instance of a type that can be used to get the result of the RPC at some future time by invoking the Receive function on the returned instance. See NotifyWork for the blocking version and more details. NOTE: This is a pod extension and requires a websocket connection.
+func (c *Client) NotifyWorkAsync() FutureNotifyWorkResult {
+	// Not supported in HTTP POST mode.
+	if c.config.HTTPPostMode {
+		return newFutureError(ErrWebsocketsRequired)
+	}
+	// Ignore the notification if the client is not interested in notifications.
+	if c.ntfnHandlers == nil {
+		return newNilFutureResult()
+	}
+	cmd := json.NewNotifyWorkCmd()
+	return c.sendCmd(cmd)
+}
+// NotifyWork registers the client to receive a new block template, starting with the current one, whenever the previous one becomes stale, so miners do not need to poll getblocktemplate.  The notifications are delivered to the notification handlers associated with the client.  Calling this function has no effect if there are no notification handlers and will result in an error if the client is configured to run in HTTP POST mode. The notifications delivered as a result of this call will be via OnWork. NOTE: This is a pod extension and requires a websocket connection.
+func (c *Client) NotifyWork() error {
+	return c.NotifyWorkAsync().Receive()
+}
 // FutureNotifySpentResult is a future promise to deliver the result of a NotifySpentAsync RPC invocation (or an applicable error). NOTE: Deprecated. Use FutureLoadTxFilterResult instead.
 type FutureNotifySpentResult chan *response
 // Receive waits for the response promised by the future and returns an error if the registration was not successful.
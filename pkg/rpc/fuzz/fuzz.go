@@ -0,0 +1,166 @@
+// Package fuzz sends structurally valid but adversarial JSON-RPC requests to a server, shaped after the parameters of the commands registered in the json package, and records the requests that crash or hang it and those it answers with a malformed response or an error code that does not fit the request.
+package fuzz
+import (
+	js "encoding/json"
+	"fmt"
+	"math/rand"
+	"net"
+	"reflect"
+	"git.parallelcoin.io/dev/9/pkg/rpc/json"
+)
+// Kinds of findings
+const (
+	// Crash is a request after which the server no longer answers
+	Crash = "crash"
+	// Dropped is a request whose connection was closed without a response while the server went on answering, as when its handler panics
+	Dropped = "dropped"
+	// Hang is a request that was not answered in time
+	Hang = "hang"
+	// Inconsistent is a request answered with a malformed response or an error code that does not fit it
+	Inconsistent = "inconsistent"
+)
+// Finding is a request that the server did not handle as it should
+type Finding struct {
+	Kind    string `json:"kind"`
+	Method  string `json:"method"`
+	Request string `json:"request"`
+	Detail  string `json:"detail"`
+}
+// skipped are the methods requests are never sent for, as they stop the server
+var skipped = map[string]struct{}{"stop": {}}
+// probe is the request sent after one goes unanswered to find out whether the server still answers
+var probe = []byte(`{"jsonrpc":"1.0","method":"getblockcount","params":[],"id":0}`)
+// knownCodes are the error codes defined in the json package, any other code in a response is inconsistent
+var knownCodes = map[json.RPCErrorCode]struct{}{
+	json.ErrRPCMisc:                      {},
+	json.ErrRPCForbiddenBySafeMode:       {},
+	json.ErrRPCType:                      {},
+	json.ErrRPCInvalidAddressOrKey:       {},
+	json.ErrRPCOutOfMemory:               {},
+	json.ErrRPCInvalidParameter:          {},
+	json.ErrRPCDatabase:                  {},
+	json.ErrRPCDeserialization:           {},
+	json.ErrRPCVerify:                    {},
+	json.ErrRPCClientNotConnected:        {},
+	json.ErrRPCClientInInitialDownload:   {},
+	json.ErrRPCClientNodeNotAdded:        {},
+	json.ErrRPCWallet:                    {},
+	json.ErrRPCWalletInsufficientFunds:   {},
+	json.ErrRPCWalletInvalidAccountName:  {},
+	json.ErrRPCWalletKeypoolRanOut:       {},
+	json.ErrRPCWalletUnlockNeeded:        {},
+	json.ErrRPCWalletPassphraseIncorrect: {},
+	json.ErrRPCWalletWrongEncState:       {},
+	json.ErrRPCWalletEncryptionFailed:    {},
+	json.ErrRPCWalletAlreadyUnlocked:     {},
+	json.ErrRPCInternal.Code:             {},
+	json.ErrRPCInvalidParams.Code:        {},
+	json.ErrRPCInvalidRequest.Code:       {},
+	json.ErrRPCMethodNotFound.Code:       {},
+	json.ErrRPCParse.Code:                {},
+}
+// Fuzzer sends requests to a server and checks its responses
+type Fuzzer struct {
+	// Methods are the methods requests are sent for
+	Methods []string
+	post    func([]byte) ([]byte, error)
+	rand    *rand.Rand
+}
+// New returns a fuzzer that sends requests with post, which returns the body of the response, for the methods a node answers over HTTP. The same seed sends the same requests
+func New(post func(body []byte) ([]byte, error), seed int64) *Fuzzer {
+	return &Fuzzer{
+		Methods: Methods(),
+		post:    post,
+		rand:    rand.New(rand.NewSource(seed)),
+	}
+}
+// Methods returns the registered methods that are not only for wallets, websockets or notifications, except those that stop the server
+func Methods() (methods []string) {
+	for _, method := range json.RegisteredCmdMethods() {
+		flags, err := json.MethodUsageFlags(method)
+		if err != nil || flags&(json.UFWalletOnly|json.UFWebsocketOnly|json.UFNotification) != 0 {
+			continue
+		}
+		if _, ok := skipped[method]; !ok {
+			methods = append(methods, method)
+		}
+	}
+	return
+}
+// Run sends n requests, passing each finding to report as it is found. It stops early with an error when the server no longer answers
+func (f *Fuzzer) Run(n int, report func(Finding)) error {
+	if len(f.Methods) < 1 {
+		return fmt.Errorf("there are no methods to send requests for")
+	}
+	for i := 0; i < n; i++ {
+		r, err := f.request()
+		if err != nil {
+			return fmt.Errorf("could not build a request: %v", err)
+		}
+		finding := Finding{Method: r.method, Request: string(r.body)}
+		resp, err := f.post(r.body)
+		if err != nil {
+			if nerr, ok := err.(net.Error); ok && nerr.Timeout() {
+				finding.Kind, finding.Detail = Hang, err.Error()
+				report(finding)
+				continue
+			}
+			if _, perr := f.post(probe); perr != nil {
+				finding.Kind, finding.Detail = Crash, fmt.Sprintf("%v, then %v", err, perr)
+				report(finding)
+				return fmt.Errorf("the server stopped answering after %d requests", i+1)
+			}
+			finding.Kind, finding.Detail = Dropped, err.Error()
+			report(finding)
+			continue
+		}
+		if detail := check(r, resp); detail != "" {
+			finding.Kind, finding.Detail = Inconsistent, detail
+			report(finding)
+		}
+	}
+	return nil
+}
+// check returns what is wrong with the response to the request, or nothing if it is consistent with it
+func check(r *request, body []byte) string {
+	var resp struct {
+		Result js.RawMessage  `json:"result"`
+		Error  *json.RPCError `json:"error"`
+		ID     interface{}    `json:"id"`
+	}
+	if err := js.Unmarshal(body, &resp); err != nil {
+		return fmt.Sprintf("the response %q is not a JSON-RPC response: %v", body, err)
+	}
+	// the id is compared as it was decoded from JSON, as the server also decodes it
+	var id interface{}
+	sent, _ := js.Marshal(r.id)
+	js.Unmarshal(sent, &id)
+	if !reflect.DeepEqual(resp.ID, id) {
+		return fmt.Sprintf("the response has the id %v, not %v", resp.ID, id)
+	}
+	if resp.Error != nil && len(resp.Result) > 0 && string(resp.Result) != "null" {
+		return fmt.Sprintf("the response has both the result %s and the error %v", resp.Result, resp.Error)
+	}
+	var want json.RPCErrorCode
+	switch r.shape {
+	case arity, mistyped:
+		want = json.ErrRPCInvalidParams.Code
+	case unknown:
+		want = json.ErrRPCMethodNotFound.Code
+	default:
+		if resp.Error == nil {
+			return ""
+		}
+		if _, ok := knownCodes[resp.Error.Code]; !ok {
+			return fmt.Sprintf("the error %v has an unknown code", resp.Error)
+		}
+		return ""
+	}
+	if resp.Error == nil {
+		return fmt.Sprintf("the response has the result %s instead of the error code %d", resp.Result, want)
+	}
+	if resp.Error.Code != want {
+		return fmt.Sprintf("the error %v does not have the code %d", resp.Error, want)
+	}
+	return ""
+}
@@ -0,0 +1,91 @@
+package fuzz
+import (
+	"bytes"
+	js "encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"git.parallelcoin.io/dev/9/pkg/rpc/json"
+)
+// serve answers requests as the node does, except that getbestblockhash panics and getdifficulty fails with an error code that is not defined
+func serve(
+	w http.ResponseWriter, r *http.Request) {
+	var request json.Request
+	body, _ := ioutil.ReadAll(r.Body)
+	var result interface{}
+	var rpcErr *json.RPCError
+	if err := js.Unmarshal(body, &request); err != nil {
+		rpcErr = json.NewRPCError(json.ErrRPCParse.Code, err.Error())
+	} else if _, err := json.UnmarshalCmd(&request); err != nil {
+		if jerr, ok := err.(json.Error); ok && jerr.ErrorCode == json.ErrUnregisteredMethod {
+			rpcErr = json.ErrRPCMethodNotFound
+		} else {
+			rpcErr = json.NewRPCError(json.ErrRPCInvalidParams.Code, err.Error())
+		}
+	} else {
+		switch request.Method {
+		case "getbestblockhash":
+			panic("getbestblockhash")
+		case "getdifficulty":
+			rpcErr = json.NewRPCError(7, "odd")
+		default:
+			result = 1
+		}
+	}
+	reply, _ := json.MarshalResponse(request.ID, result, rpcErr)
+	w.Write(reply)
+}
+// TestRun ensures requests that are answered as they should be are not reported, and those that are not are reported with the kind of their failure
+func TestRun(
+	t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(serve))
+	defer server.Close()
+	post := func(body []byte) ([]byte, error) {
+		resp, err := http.Post(server.URL, "application/json", bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		return ioutil.ReadAll(resp.Body)
+	}
+	f := New(post, 1)
+	f.Methods = []string{"getblock", "getblockcount", "getbestblockhash", "getdifficulty"}
+	found := make(map[string]map[string]int)
+	err := f.Run(200, func(finding Finding) {
+		if found[finding.Method] == nil {
+			found[finding.Method] = make(map[string]int)
+		}
+		found[finding.Method][finding.Kind]++
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, method := range []string{"getblock", "getblockcount"} {
+		if len(found[method]) > 0 {
+			t.Errorf("%s reported %v", method, found[method])
+		}
+	}
+	if found["getbestblockhash"][Dropped] < 1 {
+		t.Errorf("the panic of getbestblockhash was not reported, found %v", found["getbestblockhash"])
+	}
+	if found["getdifficulty"][Inconsistent] < 1 {
+		t.Errorf("the error code of getdifficulty was not reported, found %v", found["getdifficulty"])
+	}
+}
+// TestMethods ensures the methods requests are sent for by default leave out those of wallets and those that stop the server
+func TestMethods(
+	t *testing.T) {
+	methods := make(map[string]bool)
+	for _, method := range Methods() {
+		methods[method] = true
+	}
+	if !methods["getblock"] {
+		t.Error("getblock is not fuzzed")
+	}
+	for _, method := range []string{"stop", "walletpassphrase", "notifyblocks"} {
+		if methods[method] {
+			t.Errorf("%s is fuzzed", method)
+		}
+	}
+}
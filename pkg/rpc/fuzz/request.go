@@ -0,0 +1,195 @@
+package fuzz
+import (
+	js "encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"git.parallelcoin.io/dev/9/pkg/rpc/json"
+)
+// Shapes of the requests that are sent
+const (
+	// valid requests have the parameters of their method, of the right types but with adversarial values
+	valid = iota
+	// arity requests have one parameter too few or one too many
+	arity
+	// mistyped requests have a parameter of a type that can not be decoded into it
+	mistyped
+	// unknown requests are for a method that is not registered
+	unknown
+	// shapes is the number of shapes
+	shapes
+)
+// maxDepth is how deep values are nested in the parameters, below which containers are left empty
+const maxDepth = 3
+// The adversarial values of each type, those that do not fit the type of a parameter being skipped
+var (
+	ints    = []int64{0, 1, -1, math.MaxInt8, math.MaxInt16, math.MaxInt32, math.MinInt32, math.MaxInt64, math.MinInt64}
+	uints   = []uint64{0, 1, math.MaxUint8, math.MaxUint16, math.MaxUint32, math.MaxUint64}
+	floats  = []float64{0, -1, 1e-8, 21e14, math.MaxFloat32, math.MaxFloat64, -math.MaxFloat64, math.SmallestNonzeroFloat64}
+	strs    = []string{"", "0", "00", "zz", "-1", "null", "\x00", "\u202e", strings.Repeat("0", 64), strings.Repeat("f", 64), strings.Repeat("a", 1<<16)}
+	lengths = []int{0, 1, 2, 100}
+)
+// request is a request that was built and what is needed to check the response to it
+type request struct {
+	method string
+	shape  int
+	id     interface{}
+	body   []byte
+}
+// request builds a request of a random shape for a random one of the methods
+func (f *Fuzzer) request() (*request, error) {
+	r := &request{
+		method: f.Methods[f.rand.Intn(len(f.Methods))],
+		shape:  f.rand.Intn(shapes),
+	}
+	if f.rand.Intn(2) == 0 {
+		r.id = f.rand.Int31()
+	} else {
+		r.id = fmt.Sprintf("fuzz-%d", f.rand.Int31())
+	}
+	types, required, err := json.MethodParamTypes(r.method)
+	if err != nil {
+		return nil, err
+	}
+	var params []interface{}
+	switch r.shape {
+	case valid, unknown:
+		n := required + f.rand.Intn(len(types)-required+1)
+		for _, t := range types[:n] {
+			params = append(params, f.value(t, 0))
+		}
+		if r.shape == unknown {
+			r.method += "x"
+		}
+	case arity:
+		n := len(types) + 1
+		if required > 0 && f.rand.Intn(2) == 0 {
+			n = required - 1
+		}
+		for i := 0; i < n; i++ {
+			if i < len(types) {
+				params = append(params, f.value(types[i], 0))
+			} else {
+				params = append(params, f.value(reflect.TypeOf(""), 1))
+			}
+		}
+	case mistyped:
+		if len(types) == 0 {
+			// there is nothing to give the wrong type, so this is a request with too many parameters
+			r.shape = arity
+			params = append(params, wrong(reflect.TypeOf("")))
+			break
+		}
+		n := 1 + f.rand.Intn(len(types))
+		bad := f.rand.Intn(n)
+		for i, t := range types[:n] {
+			if i == bad {
+				params = append(params, wrong(t))
+			} else {
+				params = append(params, f.value(t, 0))
+			}
+		}
+	}
+	req, err := json.NewRequest(r.id, r.method, params)
+	if err != nil {
+		return nil, err
+	}
+	if r.body, err = js.Marshal(req); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+// value returns a random value that decodes into the type, picked from values at the edges of what it can hold
+func (f *Fuzzer) value(t reflect.Type, depth int) interface{} {
+	if t.Kind() == reflect.Ptr {
+		// a missing optional parameter
+		if f.rand.Intn(8) == 0 {
+			return nil
+		}
+		t = t.Elem()
+	}
+	v := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.Bool:
+		return f.rand.Intn(2) == 0
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		for {
+			if n := ints[f.rand.Intn(len(ints))]; !v.OverflowInt(n) {
+				return n
+			}
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		for {
+			if n := uints[f.rand.Intn(len(uints))]; !v.OverflowUint(n) {
+				return n
+			}
+		}
+	case reflect.Float32, reflect.Float64:
+		for {
+			if n := floats[f.rand.Intn(len(floats))]; !v.OverflowFloat(n) {
+				return n
+			}
+		}
+	case reflect.String:
+		if f.rand.Intn(4) == 0 {
+			b := make([]byte, f.rand.Intn(80))
+			f.rand.Read(b)
+			return fmt.Sprintf("%x", b)
+		}
+		// the longest string only goes in the top level so nested values do not multiply it
+		if depth > 0 {
+			return strs[f.rand.Intn(len(strs)-1)]
+		}
+		return strs[f.rand.Intn(len(strs))]
+	case reflect.Slice, reflect.Array:
+		n := lengths[f.rand.Intn(len(lengths))]
+		if t.Kind() == reflect.Array {
+			n = t.Len()
+		} else if depth >= maxDepth {
+			n = 0
+		}
+		s := make([]interface{}, n)
+		for i := range s {
+			s[i] = f.value(t.Elem(), depth+1)
+		}
+		return s
+	case reflect.Map:
+		m := make(map[string]interface{})
+		if depth < maxDepth {
+			for i := f.rand.Intn(4); i > 0; i-- {
+				m[strs[f.rand.Intn(len(strs)-1)]] = f.value(t.Elem(), depth+1)
+			}
+		}
+		return m
+	case reflect.Struct:
+		m := make(map[string]interface{})
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name := strings.Split(field.Tag.Get("json"), ",")[0]
+			if field.PkgPath != "" || name == "-" || f.rand.Intn(4) == 0 {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			m[name] = f.value(field.Type, depth+1)
+		}
+		return m
+	case reflect.Interface:
+		scalars := []reflect.Type{reflect.TypeOf(false), reflect.TypeOf(int64(0)), reflect.TypeOf(0.0), reflect.TypeOf("")}
+		return f.value(scalars[f.rand.Intn(len(scalars))], depth)
+	}
+	return nil
+}
+// wrong returns a value that can not be decoded into the type
+func wrong(t reflect.Type) interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		return 1
+	}
+	return map[string]interface{}{}
+}
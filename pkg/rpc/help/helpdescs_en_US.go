@@ -8,6 +8,15 @@ var helpDescsEnUS = map[string]string{
 	"addmultisigaddress-keys":      "Pubkeys and/or pay-to-pubkey-hash addresses to partially control the multisig address",
 	"addmultisigaddress-nrequired": "The number of signatures required to redeem outputs paid to this address",
 	"addmultisigaddress--result0":  "The imported pay-to-script-hash address",
+	// BumpFeeCmd help.
+	"bumpfee--synopsis": "Replaces an unconfirmed transaction of the wallet that signals BIP125 replace-by-fee with one paying a higher fee rate out of its change output.\n" +
+		"Every input of the transaction must be the wallet's and no unconfirmed transaction of the wallet may spend its outputs.",
+	"bumpfee-txid":          "The hash of the transaction to replace",
+	"bumpfee-options":       "Options for the replacement",
+	"bumpfeeopts-feeRate":   "The fee rate of the replacement valued in bitcoin per kilobyte (default=the fee rate of the transaction plus the minimum relay fee)",
+	"bumpfeeresult-txid":    "The hash of the replacement",
+	"bumpfeeresult-origfee": "The fee of the replaced transaction valued in bitcoin",
+	"bumpfeeresult-fee":     "The fee of the replacement valued in bitcoin",
 	// CreateMultisigCmd help.
 	"createmultisig--synopsis": "Generate a multisig address and redeem script.",
 	"createmultisig-keys":      "Pubkeys and/or pay-to-pubkey-hash addresses to partially control the multisig address",
@@ -195,6 +204,14 @@ var helpDescsEnUS = map[string]string{
 	"listtransactions-count":            "Maximum number of transactions to create results from",
 	"listtransactions-from":             "Number of transactions to skip before results are created",
 	"listtransactions-includewatchonly": "Unused",
+	// FilterTransactionsCmd help.
+	"filtertransactions--synopsis": "Returns a JSON array of objects in the same format as 'listtransactions' for the wallet transactions matching every filter given, newest first.",
+	"filtertransactions-from":      "Unix time of the earliest transactions to include, 0 for the first",
+	"filtertransactions-to":        "Unix time of the latest transactions to include, 0 for the newest",
+	"filtertransactions-label":     "Only include transactions received at addresses of an account with this text in its name, ignoring case",
+	"filtertransactions-address":   "Only include transaction outputs paying to this address",
+	"filtertransactions-minamount": "Smallest amount valued in bitcoin, ignoring its sign, of the transactions to include",
+	"filtertransactions-maxamount": "Largest amount valued in bitcoin, ignoring its sign, of the transactions to include, 0 for no limit",
 	// ListUnspentCmd help.
 	"listunspent--synopsis": "Returns a JSON array of objects representing unlocked unspent outputs controlled by wallet keys.",
 	"listunspent-minconf":   "Minimum number of block confirmations required before a transaction output is considered",
@@ -274,6 +291,105 @@ var helpDescsEnUS = map[string]string{
 	"signrawtransactionerror-scriptSig": "The hex-encoded signature script",
 	"signrawtransactionerror-txid":      "The transaction hash of the referenced previous output",
 	"signrawtransactionerror-vout":      "The output index of the referenced previous output",
+	// WalletCreateFundedPsbtCmd help.
+	"walletcreatefundedpsbt--synopsis": "Creates a partially signed transaction (BIP174) paying to the amounts, spending the inputs given and more unspent outputs of the account when they are not enough, with change to a new change address of the account.\n" +
+		"The wallet does not need to be unlocked, so a watching-only wallet can create transactions for an offline signer.",
+	"walletcreatefundedpsbt-inputs":           "Unspent outputs of the wallet the transaction must spend, which may be empty",
+	"walletcreatefundedpsbt-amounts":          "JSON object with the destination addresses as keys and amounts as values",
+	"walletcreatefundedpsbt-amounts--key":     "address",
+	"walletcreatefundedpsbt-amounts--value":   "n.nnn",
+	"walletcreatefundedpsbt-amounts--desc":    "The destination address as the key and the amount valued in bitcoin as the value",
+	"walletcreatefundedpsbt-locktime":         "Locktime value; a non-zero value will also locktime-activate the inputs",
+	"walletcreatefundedpsbt-options":          "Options for choosing the inputs and fee",
+	"walletcreatefundedpsbtopts-account":      "The account to spend from and send change to (default=\"default\")",
+	"walletcreatefundedpsbtopts-minconf":      "Minimum number of block confirmations of the outputs chosen to spend (default=1)",
+	"walletcreatefundedpsbtopts-lockUnspents": "Lock the outputs spent so the wallet does not spend them again before the transaction is published (default=false)",
+	"walletcreatefundedpsbtopts-feeRate":      "The fee rate valued in bitcoin per kilobyte (default=the minimum relay fee)",
+	"walletcreatefundedpsbtresult-psbt":       "The partially signed transaction encoded as a base64 string",
+	"walletcreatefundedpsbtresult-fee":        "The fee of the transaction valued in bitcoin",
+	"walletcreatefundedpsbtresult-changepos":  "The index of the change output, or -1 if there is none",
+	// WalletProcessPsbtCmd help.
+	"walletprocesspsbt--synopsis": "Adds what the wallet knows of the outputs spent by the inputs of a partially signed transaction (BIP174) and the signatures of its keys, finalizing the inputs that then have all of their signatures.\n" +
+		"The valid sighashtype options are ALL, NONE, SINGLE, ALL|ANYONECANPAY, NONE|ANYONECANPAY, and SINGLE|ANYONECANPAY.",
+	"walletprocesspsbt-psbt":           "The partially signed transaction encoded as a base64 string",
+	"walletprocesspsbt-sign":           "Sign the inputs the wallet has keys for, which requires the wallet to be unlocked",
+	"walletprocesspsbt-sighashtype":    "Sighash flags of the signatures",
+	"walletprocesspsbtresult-psbt":     "The partially signed transaction encoded as a base64 string",
+	"walletprocesspsbtresult-complete": "Whether every input of the transaction is finalized",
+	// FinalizePsbtCmd help.
+	"finalizepsbt--synopsis":      "Finalizes the inputs of a partially signed transaction (BIP174) that have all of their signatures, and returns the transaction if every input is finalized, or else the partially signed transaction.",
+	"finalizepsbt-psbt":           "The partially signed transaction encoded as a base64 string",
+	"finalizepsbt-extract":        "Return the transaction rather than the partially signed transaction when every input is finalized",
+	"finalizepsbtresult-psbt":     "The partially signed transaction encoded as a base64 string, unless the transaction was extracted",
+	"finalizepsbtresult-hex":      "The transaction encoded as a hexadecimal string, if it was extracted",
+	"finalizepsbtresult-complete": "Whether every input of the transaction is finalized",
+	// DecodePsbtCmd help.
+	"decodepsbt--synopsis":            "Returns a JSON object describing a partially signed transaction (BIP174), its inputs and its outputs.",
+	"decodepsbt-psbt":                 "The partially signed transaction encoded as a base64 string",
+	"decodepsbtresult-tx":             "The unsigned transaction as a JSON object",
+	"decodepsbtresult-unknown":        "The global keys of types that are not known",
+	"decodepsbtresult-unknown--key":   "key",
+	"decodepsbtresult-unknown--value": "value",
+	"decodepsbtresult-unknown--desc":  "The hex-encoded key as the key and the hex-encoded value as the value",
+	"decodepsbtresult-inputs":         "The inputs of the transaction as JSON objects",
+	"decodepsbtresult-outputs":        "The outputs of the transaction as JSON objects",
+	"decodepsbtresult-fee":            "The fee of the transaction valued in bitcoin, if the outputs spent by every input are known",
+	// PsbtInputResult help.
+	"psbtinputresult-non_witness_utxo":          "The transaction an input that is not segwit spends an output of, as a JSON object",
+	"psbtinputresult-witness_utxo":              "The output a segwit input spends",
+	"psbtinputresult-partial_signatures":        "The signatures of the input",
+	"psbtinputresult-partial_signatures--key":   "pubkey",
+	"psbtinputresult-partial_signatures--value": "signature",
+	"psbtinputresult-partial_signatures--desc":  "The hex-encoded public key as the key and the hex-encoded signature as the value",
+	"psbtinputresult-sighash":                   "The sighash type the input must be signed with",
+	"psbtinputresult-redeem_script":             "The redeem script of a pay-to-script-hash output",
+	"psbtinputresult-witness_script":            "The witness script of a pay-to-witness-script-hash output",
+	"psbtinputresult-bip32_derivs":              "The derivations of the keys of the input",
+	"psbtinputresult-final_scriptSig":           "The final signature script of the input",
+	"psbtinputresult-final_scriptwitness":       "The hex-encoded items of the final witness of the input",
+	"psbtinputresult-unknown":                   "The keys of the input of types that are not known",
+	"psbtinputresult-unknown--key":              "key",
+	"psbtinputresult-unknown--value":            "value",
+	"psbtinputresult-unknown--desc":             "The hex-encoded key as the key and the hex-encoded value as the value",
+	"psbtwitnessutxoresult-amount":              "The value of the output valued in bitcoin",
+	"psbtwitnessutxoresult-scriptPubKey":        "The public key script of the output",
+	"psbtbip32derivresult-pubkey":               "The hex-encoded public key",
+	"psbtbip32derivresult-master_fingerprint":   "The hex-encoded fingerprint of the master key the key is derived from",
+	"psbtbip32derivresult-path":                 "The path the key is derived along",
+	// PsbtOutputResult help.
+	"psbtoutputresult-redeem_script":  "The redeem script of a pay-to-script-hash output",
+	"psbtoutputresult-witness_script": "The witness script of a pay-to-witness-script-hash output",
+	"psbtoutputresult-bip32_derivs":   "The derivations of the keys of the output",
+	"psbtoutputresult-unknown":        "The keys of the output of types that are not known",
+	"psbtoutputresult-unknown--key":   "key",
+	"psbtoutputresult-unknown--value": "value",
+	"psbtoutputresult-unknown--desc":  "The hex-encoded key as the key and the hex-encoded value as the value",
+	// TxRawDecodeResult help.
+	"txrawdecoderesult-txid":     "The hash of the transaction",
+	"txrawdecoderesult-version":  "The transaction version",
+	"txrawdecoderesult-locktime": "The transaction lock time",
+	"txrawdecoderesult-vin":      "The transaction inputs as JSON objects",
+	"txrawdecoderesult-vout":     "The transaction outputs as JSON objects",
+	// Vin help.
+	"vin-coinbase":    "The hex-encoded bytes of the signature script (coinbase txns only)",
+	"vin-txid":        "The hash of the origin transaction (non-coinbase txns only)",
+	"vin-vout":        "The index of the output being redeemed from the origin transaction (non-coinbase txns only)",
+	"vin-scriptSig":   "The signature script used to redeem the origin transaction as a JSON object (non-coinbase txns only)",
+	"vin-txinwitness": "The witness used to redeem the input encoded as a string array of its items",
+	"vin-sequence":    "The script sequence number",
+	// ScriptSig help.
+	"scriptsig-asm": "Disassembly of the script",
+	"scriptsig-hex": "Hex-encoded bytes of the script",
+	// Vout help.
+	"vout-value":        "The amount valued in bitcoin",
+	"vout-n":            "The index of this transaction output",
+	"vout-scriptPubKey": "The public key script used to pay coins as a JSON object",
+	// ScriptPubKeyResult help.
+	"scriptpubkeyresult-asm":       "Disassembly of the script",
+	"scriptpubkeyresult-hex":       "Hex-encoded bytes of the script",
+	"scriptpubkeyresult-reqSigs":   "The number of required signatures",
+	"scriptpubkeyresult-type":      "The type of the script (e.g. 'pubkeyhash')",
+	"scriptpubkeyresult-addresses": "The bitcoin addresses associated with this script",
 	// ValidateAddressCmd help.
 	"validateaddress--synopsis": "Verify that an address is valid.\n" +
 		"Extra details are returned if the address is controlled by this wallet.\n" +
@@ -283,18 +399,25 @@ var helpDescsEnUS = map[string]string{
 		"If the address is a multisig address controlled by this wallet, the multisig fields will be left unset if the wallet is locked since the redeem script cannot be decrypted.",
 	"validateaddress-address": "Address to validate",
 	// ValidateAddressWalletResult help.
-	"validateaddresswalletresult-isvalid":      "Whether or not the address is valid",
-	"validateaddresswalletresult-address":      "The payment address (only when isvalid is true)",
-	"validateaddresswalletresult-ismine":       "Whether this address is controlled by the wallet (only when isvalid is true)",
-	"validateaddresswalletresult-iswatchonly":  "Unset",
-	"validateaddresswalletresult-isscript":     "Whether the payment address is a pay-to-script-hash address (only when isvalid is true)",
-	"validateaddresswalletresult-pubkey":       "The associated public key of the payment address, if any (only when isvalid is true)",
-	"validateaddresswalletresult-iscompressed": "Whether the address was created by hashing a compressed public key, if any (only when isvalid is true)",
-	"validateaddresswalletresult-account":      "The account this payment address belongs to (only when isvalid is true)",
-	"validateaddresswalletresult-addresses":    "All associated payment addresses of the script if address is a multisig address (only when isvalid is true)",
-	"validateaddresswalletresult-hex":          "The redeem script ",
-	"validateaddresswalletresult-script":       "The class of redeem script for a multisig address",
-	"validateaddresswalletresult-sigsrequired": "The number of required signatures to redeem outputs to the multisig address",
+	"validateaddresswalletresult-isvalid":         "Whether or not the address is valid",
+	"validateaddresswalletresult-address":         "The payment address (only when isvalid is true)",
+	"validateaddresswalletresult-ismine":          "Whether this address is controlled by the wallet (only when isvalid is true)",
+	"validateaddresswalletresult-iswatchonly":     "Unset",
+	"validateaddresswalletresult-isscript":        "Whether the payment address is a pay-to-script-hash address (only when isvalid is true)",
+	"validateaddresswalletresult-pubkey":          "The associated public key of the payment address, if any (only when isvalid is true)",
+	"validateaddresswalletresult-iscompressed":    "Whether the address was created by hashing a compressed public key, if any (only when isvalid is true)",
+	"validateaddresswalletresult-account":         "The account this payment address belongs to (only when isvalid is true)",
+	"validateaddresswalletresult-addresses":       "All associated payment addresses of the script if address is a multisig address (only when isvalid is true)",
+	"validateaddresswalletresult-hex":             "The redeem script ",
+	"validateaddresswalletresult-script":          "The class of redeem script for a multisig address",
+	"validateaddresswalletresult-sigsrequired":    "The number of required signatures to redeem outputs to the multisig address",
+	"validateaddresswalletresult-type":            "The kind of address: p2pkh, p2sh, p2wpkh, p2wsh, p2tr or p2pk (only when isvalid is true)",
+	"validateaddresswalletresult-network":         "The network the address is for, if it is for a known network (only when isvalid is true)",
+	"validateaddresswalletresult-scriptclass":     "The class of the output script paying to the address (only when isvalid is true)",
+	"validateaddresswalletresult-scriptPubKey":    "The hex-encoded output script paying to the address (only when isvalid is true)",
+	"validateaddresswalletresult-iswitness":       "Whether the address is a segwit address",
+	"validateaddresswalletresult-witness_version": "The witness version of a segwit address",
+	"validateaddresswalletresult-witness_program": "The hex-encoded witness program of a segwit address",
 	// VerifyMessageCmd help.
 	"verifymessage--synopsis": "Verify a message was signed with the associated private key of some address.",
 	"verifymessage-address":   "Address used to sign message",
@@ -325,6 +448,15 @@ var helpDescsEnUS = map[string]string{
 	// GetBestBlockResult help.
 	"getbestblockresult-hash":   "The hash of the block",
 	"getbestblockresult-height": "The blockchain height of the block",
+	// GetSyncStatusCmd help.
+	"getsyncstatus--synopsis": "Returns how the wallet follows the chain and how far it has synced.",
+	// GetSyncStatusResult help.
+	"getsyncstatusresult-backend":       `The chain backend: "9" for a full node over RPC, "neutrino" for light mode using compact block filters from peers, or "none" before connecting`,
+	"getsyncstatusresult-synced":        "Whether the wallet has caught up with the chain backend",
+	"getsyncstatusresult-height":        "The blockchain height of the newest block the wallet has processed",
+	"getsyncstatusresult-headers":       "The height of the newest block header the backend has",
+	"getsyncstatusresult-filterheaders": "The height of the newest compact filter header in light mode, the same as headers otherwise",
+	"getsyncstatusresult-peers":         "The number of peers in light mode, 0 otherwise",
 	// GetUnconfirmedBalanceCmd help.
 	"getunconfirmedbalance--synopsis": "Calculates the unspent output value of all unmined transaction outputs for an account.",
 	"getunconfirmedbalance-account":   "The account to query the unconfirmed balance for (default=\"default\")",
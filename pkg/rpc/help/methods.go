@@ -18,8 +18,11 @@ var Methods = []struct {
 	ResultTypes []interface{}
 }{
 	{"addmultisigaddress", returnsString},
+	{"bumpfee", []interface{}{(*json.BumpFeeResult)(nil)}},
 	{"createmultisig", []interface{}{(*json.CreateMultiSigResult)(nil)}},
+	{"decodepsbt", []interface{}{(*json.DecodePsbtResult)(nil)}},
 	{"dumpprivkey", returnsString},
+	{"finalizepsbt", []interface{}{(*json.FinalizePsbtResult)(nil)}},
 	{"getaccount", returnsString},
 	{"getaccountaddress", returnsString},
 	{"getaddressesbyaccount", returnsStringArray},
@@ -51,12 +54,16 @@ var Methods = []struct {
 	{"signrawtransaction", []interface{}{(*json.SignRawTransactionResult)(nil)}},
 	{"validateaddress", []interface{}{(*json.ValidateAddressWalletResult)(nil)}},
 	{"verifymessage", returnsBool},
+	{"walletcreatefundedpsbt", []interface{}{(*json.WalletCreateFundedPsbtResult)(nil)}},
 	{"walletlock", nil},
 	{"walletpassphrase", nil},
 	{"walletpassphrasechange", nil},
+	{"walletprocesspsbt", []interface{}{(*json.WalletProcessPsbtResult)(nil)}},
 	{"createnewaccount", nil},
 	{"exportwatchingwallet", returnsString},
+	{"filtertransactions", returnsLTRArray},
 	{"getbestblock", []interface{}{(*json.GetBestBlockResult)(nil)}},
+	{"getsyncstatus", []interface{}{(*json.GetSyncStatusResult)(nil)}},
 	{"getunconfirmedbalance", returnsNumber},
 	{"listaddresstransactions", returnsLTRArray},
 	{"listalltransactions", returnsLTRArray},
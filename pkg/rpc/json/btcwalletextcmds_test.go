@@ -45,6 +45,17 @@ func TestBtcWalletExtCmds(
 				Filename: "filename",
 			},
 		},
+		{
+			name: "getsyncstatus",
+			newCmd: func() (interface{}, error) {
+				return json.NewCmd("getsyncstatus")
+			},
+			staticCmd: func() interface{} {
+				return json.NewGetSyncStatusCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getsyncstatus","params":[],"id":1}`,
+			unmarshalled: &json.GetSyncStatusCmd{},
+		},
 		{
 			name: "importaddress",
 			newCmd: func() (interface{}, error) {
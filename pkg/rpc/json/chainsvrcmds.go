@@ -27,6 +27,34 @@ func NewAddNodeCmd(
 		SubCmd: subCmd,
 	}
 }
+// AddMinerKeyCmd defines the addminerkey JSON-RPC command.
+type AddMinerKeyCmd struct {
+	Name    string
+	Secret  string
+	Allowed *[]string
+}
+// NewAddMinerKeyCmd returns a new instance which can be used to issue an addminerkey JSON-RPC command. The parameters which are pointers indicate they are optional.  Passing nil for allowed lets workers use the key from any address.
+func NewAddMinerKeyCmd(
+	name, secret string, allowed *[]string) *AddMinerKeyCmd {
+	return &AddMinerKeyCmd{
+		Name:    name,
+		Secret:  secret,
+		Allowed: allowed,
+	}
+}
+// AddMiningAddressCmd defines the addminingaddress JSON-RPC command.
+type AddMiningAddressCmd struct {
+	Address string
+	Weight  *float64 `jsonrpcdefault:"1"`
+}
+// NewAddMiningAddressCmd returns a new instance which can be used to issue an addminingaddress JSON-RPC command. The parameters which are pointers indicate they are optional.  Passing nil for optional parameters will use the default value.
+func NewAddMiningAddressCmd(
+	address string, weight *float64) *AddMiningAddressCmd {
+	return &AddMiningAddressCmd{
+		Address: address,
+		Weight:  weight,
+	}
+}
 // TransactionInput represents the inputs to a transaction.  Specifically a transaction hash and output number pair.
 type TransactionInput struct {
 	Txid string `json:"txid"`
@@ -70,6 +98,49 @@ func NewDecodeScriptCmd(
 		HexScript: hexScript,
 	}
 }
+// DumpUtxoSetCmd defines the dumputxoset JSON-RPC command.
+type DumpUtxoSetCmd struct {
+	Path string
+}
+// NewDumpUtxoSetCmd returns a new instance which can be used to issue a dumputxoset JSON-RPC command.
+func NewDumpUtxoSetCmd(
+	path string) *DumpUtxoSetCmd {
+	return &DumpUtxoSetCmd{
+		Path: path,
+	}
+}
+// EstimateRawFeeCmd defines the estimaterawfee JSON-RPC command.
+type EstimateRawFeeCmd struct{}
+// NewEstimateRawFeeCmd returns a new instance which can be used to issue an estimaterawfee JSON-RPC command.
+func NewEstimateRawFeeCmd() *EstimateRawFeeCmd {
+	return &EstimateRawFeeCmd{}
+}
+// EstimateSmartFeeCmd defines the estimatesmartfee JSON-RPC command.
+type EstimateSmartFeeCmd struct {
+	ConfTarget int64
+}
+// NewEstimateSmartFeeCmd returns a new instance which can be used to issue an estimatesmartfee JSON-RPC command.
+func NewEstimateSmartFeeCmd(
+	confTarget int64) *EstimateSmartFeeCmd {
+	return &EstimateSmartFeeCmd{
+		ConfTarget: confTarget,
+	}
+}
+// GenerateToAddressCmd defines the generatetoaddress JSON-RPC command.
+type GenerateToAddressCmd struct {
+	NumBlocks uint32
+	Address   string
+	MaxTries  *uint64 `jsonrpcdefault:"1000000"`
+}
+// NewGenerateToAddressCmd returns a new instance which can be used to issue a generatetoaddress JSON-RPC command. The parameters which are pointers indicate they are optional.  Passing nil for optional parameters will use the default value.
+func NewGenerateToAddressCmd(
+	numBlocks uint32, address string, maxTries *uint64) *GenerateToAddressCmd {
+	return &GenerateToAddressCmd{
+		NumBlocks: numBlocks,
+		Address:   address,
+		MaxTries:  maxTries,
+	}
+}
 // GetAddedNodeInfoCmd defines the getaddednodeinfo JSON-RPC command.
 type GetAddedNodeInfoCmd struct {
 	DNS  bool
@@ -155,6 +226,10 @@ type TemplateRequest struct {
 	// Block proposal from BIP 0023.  Data is only provided when Mode is "proposal".
 	Data   string `json:"data,omitempty"`
 	WorkID string `json:"workid,omitempty"`
+	// Optional transaction controls of the miner, applied along with those of the node.
+	Include   []string `json:"include,omitempty"`
+	Exclude   []string `json:"exclude,omitempty"`
+	MaxWeight uint32   `json:"maxweight,omitempty"`
 }
 // convertTemplateRequestField potentially converts the provided value as
 // needed.
@@ -239,12 +314,31 @@ type GetChainTipsCmd struct{}
 func NewGetChainTipsCmd() *GetChainTipsCmd {
 	return &GetChainTipsCmd{}
 }
+// GetChainTxStatsCmd defines the getchaintxstats JSON-RPC command.
+type GetChainTxStatsCmd struct {
+	NBlocks   *int32
+	BlockHash *string
+}
+// NewGetChainTxStatsCmd returns a new instance which can be used to issue a getchaintxstats JSON-RPC command. The parameters which are pointers indicate they are optional.  Passing nil for optional parameters will use the default value.
+func NewGetChainTxStatsCmd(
+	nBlocks *int32, blockHash *string) *GetChainTxStatsCmd {
+	return &GetChainTxStatsCmd{
+		NBlocks:   nBlocks,
+		BlockHash: blockHash,
+	}
+}
 // GetConnectionCountCmd defines the getconnectioncount JSON-RPC command.
 type GetConnectionCountCmd struct{}
 // NewGetConnectionCountCmd returns a new instance which can be used to issue a getconnectioncount JSON-RPC command.
 func NewGetConnectionCountCmd() *GetConnectionCountCmd {
 	return &GetConnectionCountCmd{}
 }
+// GetDifficultiesCmd defines the getdifficulties JSON-RPC command.
+type GetDifficultiesCmd struct{}
+// NewGetDifficultiesCmd returns a new instance which can be used to issue a getdifficulties JSON-RPC command.
+func NewGetDifficultiesCmd() *GetDifficultiesCmd {
+	return &GetDifficultiesCmd{}
+}
 // GetDifficultyCmd defines the getdifficulty JSON-RPC command.
 type GetDifficultyCmd struct {
 	Algo string
@@ -256,6 +350,12 @@ func NewGetDifficultyCmd(
 		Algo: algo,
 	}
 }
+// GetDustInfoCmd defines the getdustinfo JSON-RPC command.
+type GetDustInfoCmd struct{}
+// NewGetDustInfoCmd returns a new instance which can be used to issue a getdustinfo JSON-RPC command.
+func NewGetDustInfoCmd() *GetDustInfoCmd {
+	return &GetDustInfoCmd{}
+}
 // GetGenerateCmd defines the getgenerate JSON-RPC command.
 type GetGenerateCmd struct{}
 // NewGetGenerateCmd returns a new instance which can be used to issue a getgenerate JSON-RPC command.
@@ -291,12 +391,36 @@ type GetMempoolInfoCmd struct{}
 func NewGetMempoolInfoCmd() *GetMempoolInfoCmd {
 	return &GetMempoolInfoCmd{}
 }
+// GetMinerKeysCmd defines the getminerkeys JSON-RPC command.
+type GetMinerKeysCmd struct{}
+// NewGetMinerKeysCmd returns a new instance which can be used to issue a getminerkeys JSON-RPC command.
+func NewGetMinerKeysCmd() *GetMinerKeysCmd {
+	return &GetMinerKeysCmd{}
+}
+// GetMiningAddressesCmd defines the getminingaddresses JSON-RPC command.
+type GetMiningAddressesCmd struct{}
+// NewGetMiningAddressesCmd returns a new instance which can be used to issue a getminingaddresses JSON-RPC command.
+func NewGetMiningAddressesCmd() *GetMiningAddressesCmd {
+	return &GetMiningAddressesCmd{}
+}
+// GetMiningBiasCmd defines the getminingbias JSON-RPC command.
+type GetMiningBiasCmd struct{}
+// NewGetMiningBiasCmd returns a new instance which can be used to issue a getminingbias JSON-RPC command.
+func NewGetMiningBiasCmd() *GetMiningBiasCmd {
+	return &GetMiningBiasCmd{}
+}
 // GetMiningInfoCmd defines the getmininginfo JSON-RPC command.
 type GetMiningInfoCmd struct{}
 // NewGetMiningInfoCmd returns a new instance which can be used to issue a getmininginfo JSON-RPC command.
 func NewGetMiningInfoCmd() *GetMiningInfoCmd {
 	return &GetMiningInfoCmd{}
 }
+// GetMiningStatsCmd defines the getminingstats JSON-RPC command.
+type GetMiningStatsCmd struct{}
+// NewGetMiningStatsCmd returns a new instance which can be used to issue a getminingstats JSON-RPC command.
+func NewGetMiningStatsCmd() *GetMiningStatsCmd {
+	return &GetMiningStatsCmd{}
+}
 // GetNetworkInfoCmd defines the getnetworkinfo JSON-RPC command.
 type GetNetworkInfoCmd struct{}
 // NewGetNetworkInfoCmd returns a new instance which can be used to issue a getnetworkinfo JSON-RPC command.
@@ -313,15 +437,23 @@ func NewGetNetTotalsCmd() *GetNetTotalsCmd {
 type GetNetworkHashPSCmd struct {
 	Blocks *int `jsonrpcdefault:"120"`
 	Height *int `jsonrpcdefault:"-1"`
+	Algo   *string
 }
-// NewGetNetworkHashPSCmd returns a new instance which can be used to issue a getnetworkhashps JSON-RPC command. The parameters which are pointers indicate they are optional.  Passing nil for optional parameters will use the default value.
+// NewGetNetworkHashPSCmd returns a new instance which can be used to issue a getnetworkhashps JSON-RPC command. The parameters which are pointers indicate they are optional.  Passing nil for optional parameters will use the default value, and a nil algo estimates the hashes of all the algorithms together.
 func NewGetNetworkHashPSCmd(
-	numBlocks, height *int) *GetNetworkHashPSCmd {
+	numBlocks, height *int, algo *string) *GetNetworkHashPSCmd {
 	return &GetNetworkHashPSCmd{
 		Blocks: numBlocks,
 		Height: height,
+		Algo:   algo,
 	}
 }
+// GetOrphanInfoCmd defines the getorphaninfo JSON-RPC command.
+type GetOrphanInfoCmd struct{}
+// NewGetOrphanInfoCmd returns a new instance which can be used to issue a getorphaninfo JSON-RPC command.
+func NewGetOrphanInfoCmd() *GetOrphanInfoCmd {
+	return &GetOrphanInfoCmd{}
+}
 // GetPeerInfoCmd defines the getpeerinfo JSON-RPC command.
 type GetPeerInfoCmd struct{}
 // NewGetPeerInfoCmd returns a new instance which can be used to issue a getpeer JSON-RPC command.
@@ -330,7 +462,13 @@ func NewGetPeerInfoCmd() *GetPeerInfoCmd {
 }
 // GetRawMempoolCmd defines the getmempool JSON-RPC command.
 type GetRawMempoolCmd struct {
-	Verbose *bool `jsonrpcdefault:"false"`
+	Verbose    *bool    `jsonrpcdefault:"false"`
+	Skip       *int     `jsonrpcdefault:"0"`
+	Count      *int     `jsonrpcdefault:"0"`
+	MinFeeRate *float64 `jsonrpcdefault:"0"`
+	MinAge     *int64   `jsonrpcdefault:"0"`
+	MaxAge     *int64   `jsonrpcdefault:"0"`
+	Compact    *bool    `jsonrpcdefault:"false"`
 }
 // NewGetRawMempoolCmd returns a new instance which can be used to issue a getrawmempool JSON-RPC command. The parameters which are pointers indicate they are optional.  Passing nil for optional parameters will use the default value.
 func NewGetRawMempoolCmd(
@@ -339,6 +477,19 @@ func NewGetRawMempoolCmd(
 		Verbose: verbose,
 	}
 }
+// NewGetRawMempoolPagedCmd returns a new instance which can be used to issue a getrawmempool JSON-RPC command that only returns a filtered page of the memory pool. The parameters which are pointers indicate they are optional.  Passing nil for optional parameters will use the default value.
+func NewGetRawMempoolPagedCmd(
+	verbose *bool, skip, count *int, minFeeRate *float64, minAge, maxAge *int64, compact *bool) *GetRawMempoolCmd {
+	return &GetRawMempoolCmd{
+		Verbose:    verbose,
+		Skip:       skip,
+		Count:      count,
+		MinFeeRate: minFeeRate,
+		MinAge:     minAge,
+		MaxAge:     maxAge,
+		Compact:    compact,
+	}
+}
 // GetRawTransactionCmd defines the getrawtransaction JSON-RPC command. NOTE: This field is an int versus a bool to remain compatible with Bitcoin Core even though it really should be a bool.
 type GetRawTransactionCmd struct {
 	Txid    string
@@ -352,6 +503,12 @@ func NewGetRawTransactionCmd(
 		Verbose: verbose,
 	}
 }
+// GetTemplateControlsCmd defines the gettemplatecontrols JSON-RPC command.
+type GetTemplateControlsCmd struct{}
+// NewGetTemplateControlsCmd returns a new instance which can be used to issue a gettemplatecontrols JSON-RPC command.
+func NewGetTemplateControlsCmd() *GetTemplateControlsCmd {
+	return &GetTemplateControlsCmd{}
+}
 // GetTxOutCmd defines the gettxout JSON-RPC command.
 type GetTxOutCmd struct {
 	Txid           string
@@ -386,6 +543,12 @@ type GetTxOutSetInfoCmd struct{}
 func NewGetTxOutSetInfoCmd() *GetTxOutSetInfoCmd {
 	return &GetTxOutSetInfoCmd{}
 }
+// GetUpdateInfoCmd defines the getupdateinfo JSON-RPC command.
+type GetUpdateInfoCmd struct{}
+// NewGetUpdateInfoCmd returns a new instance which can be used to issue a getupdateinfo JSON-RPC command.
+func NewGetUpdateInfoCmd() *GetUpdateInfoCmd {
+	return &GetUpdateInfoCmd{}
+}
 // GetWorkCmd defines the getwork JSON-RPC command.
 type GetWorkCmd struct {
 	Data *string
@@ -397,6 +560,12 @@ func NewGetWorkCmd(
 		Data: data,
 	}
 }
+// GetWorkerInfoCmd defines the getworkerinfo JSON-RPC command.
+type GetWorkerInfoCmd struct{}
+// NewGetWorkerInfoCmd returns a new instance which can be used to issue a getworkerinfo JSON-RPC command.
+func NewGetWorkerInfoCmd() *GetWorkerInfoCmd {
+	return &GetWorkerInfoCmd{}
+}
 // HelpCmd defines the help JSON-RPC command.
 type HelpCmd struct {
 	Command *string
@@ -419,6 +588,17 @@ func NewInvalidateBlockCmd(
 		BlockHash: blockHash,
 	}
 }
+// LoadUtxoSetCmd defines the loadutxoset JSON-RPC command.
+type LoadUtxoSetCmd struct {
+	Path string
+}
+// NewLoadUtxoSetCmd returns a new instance which can be used to issue a loadutxoset JSON-RPC command.
+func NewLoadUtxoSetCmd(
+	path string) *LoadUtxoSetCmd {
+	return &LoadUtxoSetCmd{
+		Path: path,
+	}
+}
 // PingCmd defines the ping JSON-RPC command.
 type PingCmd struct{}
 // NewPingCmd returns a new instance which can be used to issue a ping JSON-RPC command.
@@ -447,6 +627,28 @@ func NewReconsiderBlockCmd(
 		BlockHash: blockHash,
 	}
 }
+// RemoveMinerKeyCmd defines the removeminerkey JSON-RPC command.
+type RemoveMinerKeyCmd struct {
+	Name string
+}
+// NewRemoveMinerKeyCmd returns a new instance which can be used to issue a removeminerkey JSON-RPC command.
+func NewRemoveMinerKeyCmd(
+	name string) *RemoveMinerKeyCmd {
+	return &RemoveMinerKeyCmd{
+		Name: name,
+	}
+}
+// RemoveMiningAddressCmd defines the removeminingaddress JSON-RPC command.
+type RemoveMiningAddressCmd struct {
+	Address string
+}
+// NewRemoveMiningAddressCmd returns a new instance which can be used to issue a removeminingaddress JSON-RPC command.
+func NewRemoveMiningAddressCmd(
+	address string) *RemoveMiningAddressCmd {
+	return &RemoveMiningAddressCmd{
+		Address: address,
+	}
+}
 // SearchRawTransactionsCmd defines the searchrawtransactions JSON-RPC command.
 type SearchRawTransactionsCmd struct {
 	Address     string
@@ -483,19 +685,66 @@ func NewSendRawTransactionCmd(
 		AllowHighFees: allowHighFees,
 	}
 }
+// SetCoinbaseTagCmd defines the setcoinbasetag JSON-RPC command.
+type SetCoinbaseTagCmd struct {
+	Tag string
+}
+// NewSetCoinbaseTagCmd returns a new instance which can be used to issue a setcoinbasetag JSON-RPC command.
+func NewSetCoinbaseTagCmd(
+	tag string) *SetCoinbaseTagCmd {
+	return &SetCoinbaseTagCmd{
+		Tag: tag,
+	}
+}
 // SetGenerateCmd defines the setgenerate JSON-RPC command.
 type SetGenerateCmd struct {
 	Generate     bool
 	GenProcLimit *int `jsonrpcdefault:"-1"`
+	Cores        *[]int
+	Nice         *int
 }
-// NewSetGenerateCmd returns a new instance which can be used to issue a setgenerate JSON-RPC command. The parameters which are pointers indicate they are optional.  Passing nil for optional parameters will use the default value.
+// NewSetGenerateCmd returns a new instance which can be used to issue a setgenerate JSON-RPC command. The parameters which are pointers indicate they are optional.  Passing nil for optional parameters will use the default value, and nil cores or nice leaves the thread affinity and priority of the miner as they are.
 func NewSetGenerateCmd(
-	generate bool, genProcLimit *int) *SetGenerateCmd {
+	generate bool, genProcLimit *int, cores *[]int, nice *int) *SetGenerateCmd {
 	return &SetGenerateCmd{
 		Generate:     generate,
 		GenProcLimit: genProcLimit,
+		Cores:        cores,
+		Nice:         nice,
 	}
 }
+// SetMiningBiasCmd defines the setminingbias JSON-RPC command.
+type SetMiningBiasCmd struct {
+	Bias float64
+}
+// NewSetMiningBiasCmd returns a new instance which can be used to issue a setminingbias JSON-RPC command.
+func NewSetMiningBiasCmd(
+	bias float64) *SetMiningBiasCmd {
+	return &SetMiningBiasCmd{
+		Bias: bias,
+	}
+}
+// SetTemplateControlsCmd defines the settemplatecontrols JSON-RPC command.
+type SetTemplateControlsCmd struct {
+	Include   *[]string
+	Exclude   *[]string
+	MaxWeight *uint32
+}
+// NewSetTemplateControlsCmd returns a new instance which can be used to issue a settemplatecontrols JSON-RPC command. The parameters which are pointers indicate they are optional, and the controls left out are removed.
+func NewSetTemplateControlsCmd(
+	include, exclude *[]string, maxWeight *uint32) *SetTemplateControlsCmd {
+	return &SetTemplateControlsCmd{
+		Include:   include,
+		Exclude:   exclude,
+		MaxWeight: maxWeight,
+	}
+}
+// StageUpdateCmd defines the stageupdate JSON-RPC command.
+type StageUpdateCmd struct{}
+// NewStageUpdateCmd returns a new instance which can be used to issue a stageupdate JSON-RPC command.
+func NewStageUpdateCmd() *StageUpdateCmd {
+	return &StageUpdateCmd{}
+}
 // StopCmd defines the stop JSON-RPC command.
 type StopCmd struct{}
 // NewStopCmd returns a new instance which can be used to issue a stop JSON-RPC command.
@@ -579,10 +828,16 @@ func NewVerifyTxOutProofCmd(
 func init() {
 	// No special flags for commands in this file.
 	flags := UsageFlag(0)
+	MustRegisterCmd("addminerkey", (*AddMinerKeyCmd)(nil), flags)
+	MustRegisterCmd("addminingaddress", (*AddMiningAddressCmd)(nil), flags)
 	MustRegisterCmd("addnode", (*AddNodeCmd)(nil), flags)
 	MustRegisterCmd("createrawtransaction", (*CreateRawTransactionCmd)(nil), flags)
 	MustRegisterCmd("decoderawtransaction", (*DecodeRawTransactionCmd)(nil), flags)
 	MustRegisterCmd("decodescript", (*DecodeScriptCmd)(nil), flags)
+	MustRegisterCmd("dumputxoset", (*DumpUtxoSetCmd)(nil), flags)
+	MustRegisterCmd("estimaterawfee", (*EstimateRawFeeCmd)(nil), flags)
+	MustRegisterCmd("estimatesmartfee", (*EstimateSmartFeeCmd)(nil), flags)
+	MustRegisterCmd("generatetoaddress", (*GenerateToAddressCmd)(nil), flags)
 	MustRegisterCmd("getaddednodeinfo", (*GetAddedNodeInfoCmd)(nil), flags)
 	MustRegisterCmd("getbestblockhash", (*GetBestBlockHashCmd)(nil), flags)
 	MustRegisterCmd("getblock", (*GetBlockCmd)(nil), flags)
@@ -594,32 +849,50 @@ func init() {
 	MustRegisterCmd("getcfilter", (*GetCFilterCmd)(nil), flags)
 	MustRegisterCmd("getcfilterheader", (*GetCFilterHeaderCmd)(nil), flags)
 	MustRegisterCmd("getchaintips", (*GetChainTipsCmd)(nil), flags)
+	MustRegisterCmd("getchaintxstats", (*GetChainTxStatsCmd)(nil), flags)
 	MustRegisterCmd("getconnectioncount", (*GetConnectionCountCmd)(nil), flags)
+	MustRegisterCmd("getdifficulties", (*GetDifficultiesCmd)(nil), flags)
 	MustRegisterCmd("getdifficulty", (*GetDifficultyCmd)(nil), flags)
+	MustRegisterCmd("getdustinfo", (*GetDustInfoCmd)(nil), flags)
 	MustRegisterCmd("getgenerate", (*GetGenerateCmd)(nil), flags)
 	MustRegisterCmd("gethashespersec", (*GetHashesPerSecCmd)(nil), flags)
 	MustRegisterCmd("getinfo", (*GetInfoCmd)(nil), flags)
 	MustRegisterCmd("getmempoolentry", (*GetMempoolEntryCmd)(nil), flags)
 	MustRegisterCmd("getmempoolinfo", (*GetMempoolInfoCmd)(nil), flags)
+	MustRegisterCmd("getminerkeys", (*GetMinerKeysCmd)(nil), flags)
+	MustRegisterCmd("getminingaddresses", (*GetMiningAddressesCmd)(nil), flags)
+	MustRegisterCmd("getminingbias", (*GetMiningBiasCmd)(nil), flags)
 	MustRegisterCmd("getmininginfo", (*GetMiningInfoCmd)(nil), flags)
+	MustRegisterCmd("getminingstats", (*GetMiningStatsCmd)(nil), flags)
 	MustRegisterCmd("getnetworkinfo", (*GetNetworkInfoCmd)(nil), flags)
 	MustRegisterCmd("getnettotals", (*GetNetTotalsCmd)(nil), flags)
 	MustRegisterCmd("getnetworkhashps", (*GetNetworkHashPSCmd)(nil), flags)
+	MustRegisterCmd("getorphaninfo", (*GetOrphanInfoCmd)(nil), flags)
 	MustRegisterCmd("getpeerinfo", (*GetPeerInfoCmd)(nil), flags)
 	MustRegisterCmd("getrawmempool", (*GetRawMempoolCmd)(nil), flags)
 	MustRegisterCmd("getrawtransaction", (*GetRawTransactionCmd)(nil), flags)
+	MustRegisterCmd("gettemplatecontrols", (*GetTemplateControlsCmd)(nil), flags)
 	MustRegisterCmd("gettxout", (*GetTxOutCmd)(nil), flags)
 	MustRegisterCmd("gettxoutproof", (*GetTxOutProofCmd)(nil), flags)
 	MustRegisterCmd("gettxoutsetinfo", (*GetTxOutSetInfoCmd)(nil), flags)
+	MustRegisterCmd("getupdateinfo", (*GetUpdateInfoCmd)(nil), flags)
 	MustRegisterCmd("getwork", (*GetWorkCmd)(nil), flags)
+	MustRegisterCmd("getworkerinfo", (*GetWorkerInfoCmd)(nil), flags)
 	MustRegisterCmd("help", (*HelpCmd)(nil), flags)
 	MustRegisterCmd("invalidateblock", (*InvalidateBlockCmd)(nil), flags)
+	MustRegisterCmd("loadutxoset", (*LoadUtxoSetCmd)(nil), flags)
 	MustRegisterCmd("ping", (*PingCmd)(nil), flags)
 	MustRegisterCmd("preciousblock", (*PreciousBlockCmd)(nil), flags)
 	MustRegisterCmd("reconsiderblock", (*ReconsiderBlockCmd)(nil), flags)
+	MustRegisterCmd("removeminerkey", (*RemoveMinerKeyCmd)(nil), flags)
+	MustRegisterCmd("removeminingaddress", (*RemoveMiningAddressCmd)(nil), flags)
 	MustRegisterCmd("searchrawtransactions", (*SearchRawTransactionsCmd)(nil), flags)
 	MustRegisterCmd("sendrawtransaction", (*SendRawTransactionCmd)(nil), flags)
+	MustRegisterCmd("setcoinbasetag", (*SetCoinbaseTagCmd)(nil), flags)
 	MustRegisterCmd("setgenerate", (*SetGenerateCmd)(nil), flags)
+	MustRegisterCmd("setminingbias", (*SetMiningBiasCmd)(nil), flags)
+	MustRegisterCmd("settemplatecontrols", (*SetTemplateControlsCmd)(nil), flags)
+	MustRegisterCmd("stageupdate", (*StageUpdateCmd)(nil), flags)
 	MustRegisterCmd("stop", (*StopCmd)(nil), flags)
 	MustRegisterCmd("submitblock", (*SubmitBlockCmd)(nil), flags)
 	MustRegisterCmd("uptime", (*UptimeCmd)(nil), flags)
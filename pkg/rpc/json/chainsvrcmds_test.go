@@ -24,6 +24,71 @@ func TestChainSvrCmds(
 		marshalled   string
 		unmarshalled interface{}
 	}{
+		{
+			name: "addminerkey",
+			newCmd: func() (interface{}, error) {
+
+				return json.NewCmd("addminerkey", "rig1", "secret")
+			},
+			staticCmd: func() interface{} {
+
+				return json.NewAddMinerKeyCmd("rig1", "secret", nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"addminerkey","params":["rig1","secret"],"id":1}`,
+			unmarshalled: &json.AddMinerKeyCmd{
+				Name:   "rig1",
+				Secret: "secret",
+			},
+		},
+		{
+			name: "addminerkey optional",
+			newCmd: func() (interface{}, error) {
+
+				return json.NewCmd("addminerkey", "rig1", "secret", []string{"10.0.0.0/24"})
+			},
+			staticCmd: func() interface{} {
+
+				return json.NewAddMinerKeyCmd("rig1", "secret", &[]string{"10.0.0.0/24"})
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"addminerkey","params":["rig1","secret",["10.0.0.0/24"]],"id":1}`,
+			unmarshalled: &json.AddMinerKeyCmd{
+				Name:    "rig1",
+				Secret:  "secret",
+				Allowed: &[]string{"10.0.0.0/24"},
+			},
+		},
+		{
+			name: "addminingaddress",
+			newCmd: func() (interface{}, error) {
+
+				return json.NewCmd("addminingaddress", "1Address")
+			},
+			staticCmd: func() interface{} {
+
+				return json.NewAddMiningAddressCmd("1Address", nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"addminingaddress","params":["1Address"],"id":1}`,
+			unmarshalled: &json.AddMiningAddressCmd{
+				Address: "1Address",
+				Weight:  json.Float64(1),
+			},
+		},
+		{
+			name: "addminingaddress optional",
+			newCmd: func() (interface{}, error) {
+
+				return json.NewCmd("addminingaddress", "1Address", 2.5)
+			},
+			staticCmd: func() interface{} {
+
+				return json.NewAddMiningAddressCmd("1Address", json.Float64(2.5))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"addminingaddress","params":["1Address",2.5],"id":1}`,
+			unmarshalled: &json.AddMiningAddressCmd{
+				Address: "1Address",
+				Weight:  json.Float64(2.5),
+			},
+		},
 		{
 			name: "addnode",
 			newCmd: func() (interface{}, error) {
@@ -106,6 +171,79 @@ func TestChainSvrCmds(
 			marshalled:   `{"jsonrpc":"1.0","method":"decodescript","params":["00"],"id":1}`,
 			unmarshalled: &json.DecodeScriptCmd{HexScript: "00"},
 		},
+		{
+			name: "dumputxoset",
+			newCmd: func() (interface{}, error) {
+
+				return json.NewCmd("dumputxoset", "utxo.dat")
+			},
+			staticCmd: func() interface{} {
+
+				return json.NewDumpUtxoSetCmd("utxo.dat")
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"dumputxoset","params":["utxo.dat"],"id":1}`,
+			unmarshalled: &json.DumpUtxoSetCmd{Path: "utxo.dat"},
+		},
+		{
+			name: "estimaterawfee",
+			newCmd: func() (interface{}, error) {
+
+				return json.NewCmd("estimaterawfee")
+			},
+			staticCmd: func() interface{} {
+
+				return json.NewEstimateRawFeeCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"estimaterawfee","params":[],"id":1}`,
+			unmarshalled: &json.EstimateRawFeeCmd{},
+		},
+		{
+			name: "estimatesmartfee",
+			newCmd: func() (interface{}, error) {
+
+				return json.NewCmd("estimatesmartfee", 6)
+			},
+			staticCmd: func() interface{} {
+
+				return json.NewEstimateSmartFeeCmd(6)
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"estimatesmartfee","params":[6],"id":1}`,
+			unmarshalled: &json.EstimateSmartFeeCmd{ConfTarget: 6},
+		},
+		{
+			name: "generatetoaddress",
+			newCmd: func() (interface{}, error) {
+
+				return json.NewCmd("generatetoaddress", 10, "1Address")
+			},
+			staticCmd: func() interface{} {
+
+				return json.NewGenerateToAddressCmd(10, "1Address", nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"generatetoaddress","params":[10,"1Address"],"id":1}`,
+			unmarshalled: &json.GenerateToAddressCmd{
+				NumBlocks: 10,
+				Address:   "1Address",
+				MaxTries:  json.Uint64(1000000),
+			},
+		},
+		{
+			name: "generatetoaddress optional",
+			newCmd: func() (interface{}, error) {
+
+				return json.NewCmd("generatetoaddress", 10, "1Address", 500)
+			},
+			staticCmd: func() interface{} {
+
+				return json.NewGenerateToAddressCmd(10, "1Address", json.Uint64(500))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"generatetoaddress","params":[10,"1Address",500],"id":1}`,
+			unmarshalled: &json.GenerateToAddressCmd{
+				NumBlocks: 10,
+				Address:   "1Address",
+				MaxTries:  json.Uint64(500),
+			},
+		},
 		{
 			name: "getaddednodeinfo",
 			newCmd: func() (interface{}, error) {
@@ -319,6 +457,32 @@ func TestChainSvrCmds(
 				},
 			},
 		},
+		{
+			name: "getblocktemplate optional - template request with controls",
+			newCmd: func() (interface{}, error) {
+
+				return json.NewCmd("getblocktemplate", `{"mode":"template","include":["123"],"exclude":["456"],"maxweight":1000000}`)
+			},
+			staticCmd: func() interface{} {
+
+				template := json.TemplateRequest{
+					Mode:      "template",
+					Include:   []string{"123"},
+					Exclude:   []string{"456"},
+					MaxWeight: 1000000,
+				}
+				return json.NewGetBlockTemplateCmd(&template)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getblocktemplate","params":[{"mode":"template","include":["123"],"exclude":["456"],"maxweight":1000000}],"id":1}`,
+			unmarshalled: &json.GetBlockTemplateCmd{
+				Request: &json.TemplateRequest{
+					Mode:      "template",
+					Include:   []string{"123"},
+					Exclude:   []string{"456"},
+					MaxWeight: 1000000,
+				},
+			},
+		},
 		{
 			name: "getblocktemplate optional - template request with tweaks 2",
 			newCmd: func() (interface{}, error) {
@@ -396,6 +560,38 @@ func TestChainSvrCmds(
 			marshalled:   `{"jsonrpc":"1.0","method":"getchaintips","params":[],"id":1}`,
 			unmarshalled: &json.GetChainTipsCmd{},
 		},
+		{
+			name: "getchaintxstats",
+			newCmd: func() (interface{}, error) {
+
+				return json.NewCmd("getchaintxstats")
+			},
+			staticCmd: func() interface{} {
+
+				return json.NewGetChainTxStatsCmd(nil, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getchaintxstats","params":[],"id":1}`,
+			unmarshalled: &json.GetChainTxStatsCmd{
+				NBlocks:   nil,
+				BlockHash: nil,
+			},
+		},
+		{
+			name: "getchaintxstats optional",
+			newCmd: func() (interface{}, error) {
+
+				return json.NewCmd("getchaintxstats", 1000, "123")
+			},
+			staticCmd: func() interface{} {
+
+				return json.NewGetChainTxStatsCmd(json.Int32(1000), json.String("123"))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getchaintxstats","params":[1000,"123"],"id":1}`,
+			unmarshalled: &json.GetChainTxStatsCmd{
+				NBlocks:   json.Int32(1000),
+				BlockHash: json.String("123"),
+			},
+		},
 		{
 			name: "getconnectioncount",
 			newCmd: func() (interface{}, error) {
@@ -409,6 +605,19 @@ func TestChainSvrCmds(
 			marshalled:   `{"jsonrpc":"1.0","method":"getconnectioncount","params":[],"id":1}`,
 			unmarshalled: &json.GetConnectionCountCmd{},
 		},
+		{
+			name: "getdifficulties",
+			newCmd: func() (interface{}, error) {
+
+				return json.NewCmd("getdifficulties")
+			},
+			staticCmd: func() interface{} {
+
+				return json.NewGetDifficultiesCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getdifficulties","params":[],"id":1}`,
+			unmarshalled: &json.GetDifficultiesCmd{},
+		},
 		{
 			name: "getdifficulty",
 			newCmd: func() (interface{}, error) {
@@ -422,6 +631,19 @@ func TestChainSvrCmds(
 			marshalled:   `{"jsonrpc":"1.0","method":"getdifficulty","params":["123"],"id":1}`,
 			unmarshalled: &json.GetDifficultyCmd{Algo: "123"},
 		},
+		{
+			name: "getdustinfo",
+			newCmd: func() (interface{}, error) {
+
+				return json.NewCmd("getdustinfo")
+			},
+			staticCmd: func() interface{} {
+
+				return json.NewGetDustInfoCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getdustinfo","params":[],"id":1}`,
+			unmarshalled: &json.GetDustInfoCmd{},
+		},
 		{
 			name: "getgenerate",
 			newCmd: func() (interface{}, error) {
@@ -489,6 +711,45 @@ func TestChainSvrCmds(
 			marshalled:   `{"jsonrpc":"1.0","method":"getmempoolinfo","params":[],"id":1}`,
 			unmarshalled: &json.GetMempoolInfoCmd{},
 		},
+		{
+			name: "getminerkeys",
+			newCmd: func() (interface{}, error) {
+
+				return json.NewCmd("getminerkeys")
+			},
+			staticCmd: func() interface{} {
+
+				return json.NewGetMinerKeysCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getminerkeys","params":[],"id":1}`,
+			unmarshalled: &json.GetMinerKeysCmd{},
+		},
+		{
+			name: "getminingaddresses",
+			newCmd: func() (interface{}, error) {
+
+				return json.NewCmd("getminingaddresses")
+			},
+			staticCmd: func() interface{} {
+
+				return json.NewGetMiningAddressesCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getminingaddresses","params":[],"id":1}`,
+			unmarshalled: &json.GetMiningAddressesCmd{},
+		},
+		{
+			name: "getminingbias",
+			newCmd: func() (interface{}, error) {
+
+				return json.NewCmd("getminingbias")
+			},
+			staticCmd: func() interface{} {
+
+				return json.NewGetMiningBiasCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getminingbias","params":[],"id":1}`,
+			unmarshalled: &json.GetMiningBiasCmd{},
+		},
 		{
 			name: "getmininginfo",
 			newCmd: func() (interface{}, error) {
@@ -502,6 +763,19 @@ func TestChainSvrCmds(
 			marshalled:   `{"jsonrpc":"1.0","method":"getmininginfo","params":[],"id":1}`,
 			unmarshalled: &json.GetMiningInfoCmd{},
 		},
+		{
+			name: "getminingstats",
+			newCmd: func() (interface{}, error) {
+
+				return json.NewCmd("getminingstats")
+			},
+			staticCmd: func() interface{} {
+
+				return json.NewGetMiningStatsCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getminingstats","params":[],"id":1}`,
+			unmarshalled: &json.GetMiningStatsCmd{},
+		},
 		{
 			name: "getnetworkinfo",
 			newCmd: func() (interface{}, error) {
@@ -536,7 +810,7 @@ func TestChainSvrCmds(
 			},
 			staticCmd: func() interface{} {
 
-				return json.NewGetNetworkHashPSCmd(nil, nil)
+				return json.NewGetNetworkHashPSCmd(nil, nil, nil)
 			},
 			marshalled: `{"jsonrpc":"1.0","method":"getnetworkhashps","params":[],"id":1}`,
 			unmarshalled: &json.GetNetworkHashPSCmd{
@@ -552,7 +826,7 @@ func TestChainSvrCmds(
 			},
 			staticCmd: func() interface{} {
 
-				return json.NewGetNetworkHashPSCmd(json.Int(200), nil)
+				return json.NewGetNetworkHashPSCmd(json.Int(200), nil, nil)
 			},
 			marshalled: `{"jsonrpc":"1.0","method":"getnetworkhashps","params":[200],"id":1}`,
 			unmarshalled: &json.GetNetworkHashPSCmd{
@@ -568,7 +842,7 @@ func TestChainSvrCmds(
 			},
 			staticCmd: func() interface{} {
 
-				return json.NewGetNetworkHashPSCmd(json.Int(200), json.Int(123))
+				return json.NewGetNetworkHashPSCmd(json.Int(200), json.Int(123), nil)
 			},
 			marshalled: `{"jsonrpc":"1.0","method":"getnetworkhashps","params":[200,123],"id":1}`,
 			unmarshalled: &json.GetNetworkHashPSCmd{
@@ -576,6 +850,36 @@ func TestChainSvrCmds(
 				Height: json.Int(123),
 			},
 		},
+		{
+			name: "getnetworkhashps optional3",
+			newCmd: func() (interface{}, error) {
+
+				return json.NewCmd("getnetworkhashps", 200, -1, "scrypt")
+			},
+			staticCmd: func() interface{} {
+
+				return json.NewGetNetworkHashPSCmd(json.Int(200), json.Int(-1), json.String("scrypt"))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getnetworkhashps","params":[200,-1,"scrypt"],"id":1}`,
+			unmarshalled: &json.GetNetworkHashPSCmd{
+				Blocks: json.Int(200),
+				Height: json.Int(-1),
+				Algo:   json.String("scrypt"),
+			},
+		},
+		{
+			name: "getorphaninfo",
+			newCmd: func() (interface{}, error) {
+
+				return json.NewCmd("getorphaninfo")
+			},
+			staticCmd: func() interface{} {
+
+				return json.NewGetOrphanInfoCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getorphaninfo","params":[],"id":1}`,
+			unmarshalled: &json.GetOrphanInfoCmd{},
+		},
 		{
 			name: "getpeerinfo",
 			newCmd: func() (interface{}, error) {
@@ -601,7 +905,13 @@ func TestChainSvrCmds(
 			},
 			marshalled: `{"jsonrpc":"1.0","method":"getrawmempool","params":[],"id":1}`,
 			unmarshalled: &json.GetRawMempoolCmd{
-				Verbose: json.Bool(false),
+				Verbose:    json.Bool(false),
+				Skip:       json.Int(0),
+				Count:      json.Int(0),
+				MinFeeRate: json.Float64(0),
+				MinAge:     json.Int64(0),
+				MaxAge:     json.Int64(0),
+				Compact:    json.Bool(false),
 			},
 		},
 		{
@@ -616,7 +926,36 @@ func TestChainSvrCmds(
 			},
 			marshalled: `{"jsonrpc":"1.0","method":"getrawmempool","params":[false],"id":1}`,
 			unmarshalled: &json.GetRawMempoolCmd{
-				Verbose: json.Bool(false),
+				Verbose:    json.Bool(false),
+				Skip:       json.Int(0),
+				Count:      json.Int(0),
+				MinFeeRate: json.Float64(0),
+				MinAge:     json.Int64(0),
+				MaxAge:     json.Int64(0),
+				Compact:    json.Bool(false),
+			},
+		},
+		{
+			name: "getrawmempool paged",
+			newCmd: func() (interface{}, error) {
+
+				return json.NewCmd("getrawmempool", true, 10, 100, 0.0001, 60, 3600, true)
+			},
+			staticCmd: func() interface{} {
+
+				return json.NewGetRawMempoolPagedCmd(json.Bool(true),
+					json.Int(10), json.Int(100), json.Float64(0.0001),
+					json.Int64(60), json.Int64(3600), json.Bool(true))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getrawmempool","params":[true,10,100,0.0001,60,3600,true],"id":1}`,
+			unmarshalled: &json.GetRawMempoolCmd{
+				Verbose:    json.Bool(true),
+				Skip:       json.Int(10),
+				Count:      json.Int(100),
+				MinFeeRate: json.Float64(0.0001),
+				MinAge:     json.Int64(60),
+				MaxAge:     json.Int64(3600),
+				Compact:    json.Bool(true),
 			},
 		},
 		{
@@ -651,6 +990,19 @@ func TestChainSvrCmds(
 				Verbose: json.Int(1),
 			},
 		},
+		{
+			name: "gettemplatecontrols",
+			newCmd: func() (interface{}, error) {
+
+				return json.NewCmd("gettemplatecontrols")
+			},
+			staticCmd: func() interface{} {
+
+				return json.NewGetTemplateControlsCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"gettemplatecontrols","params":[],"id":1}`,
+			unmarshalled: &json.GetTemplateControlsCmd{},
+		},
 		{
 			name: "gettxout",
 			newCmd: func() (interface{}, error) {
@@ -732,6 +1084,19 @@ func TestChainSvrCmds(
 			marshalled:   `{"jsonrpc":"1.0","method":"gettxoutsetinfo","params":[],"id":1}`,
 			unmarshalled: &json.GetTxOutSetInfoCmd{},
 		},
+		{
+			name: "getupdateinfo",
+			newCmd: func() (interface{}, error) {
+
+				return json.NewCmd("getupdateinfo")
+			},
+			staticCmd: func() interface{} {
+
+				return json.NewGetUpdateInfoCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getupdateinfo","params":[],"id":1}`,
+			unmarshalled: &json.GetUpdateInfoCmd{},
+		},
 		{
 			name: "getwork",
 			newCmd: func() (interface{}, error) {
@@ -762,6 +1127,19 @@ func TestChainSvrCmds(
 				Data: json.String("00112233"),
 			},
 		},
+		{
+			name: "getworkerinfo",
+			newCmd: func() (interface{}, error) {
+
+				return json.NewCmd("getworkerinfo")
+			},
+			staticCmd: func() interface{} {
+
+				return json.NewGetWorkerInfoCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getworkerinfo","params":[],"id":1}`,
+			unmarshalled: &json.GetWorkerInfoCmd{},
+		},
 		{
 			name: "help",
 			newCmd: func() (interface{}, error) {
@@ -807,6 +1185,19 @@ func TestChainSvrCmds(
 				BlockHash: "123",
 			},
 		},
+		{
+			name: "loadutxoset",
+			newCmd: func() (interface{}, error) {
+
+				return json.NewCmd("loadutxoset", "utxo.dat")
+			},
+			staticCmd: func() interface{} {
+
+				return json.NewLoadUtxoSetCmd("utxo.dat")
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"loadutxoset","params":["utxo.dat"],"id":1}`,
+			unmarshalled: &json.LoadUtxoSetCmd{Path: "utxo.dat"},
+		},
 		{
 			name: "ping",
 			newCmd: func() (interface{}, error) {
@@ -850,6 +1241,36 @@ func TestChainSvrCmds(
 				BlockHash: "123",
 			},
 		},
+		{
+			name: "removeminerkey",
+			newCmd: func() (interface{}, error) {
+
+				return json.NewCmd("removeminerkey", "rig1")
+			},
+			staticCmd: func() interface{} {
+
+				return json.NewRemoveMinerKeyCmd("rig1")
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"removeminerkey","params":["rig1"],"id":1}`,
+			unmarshalled: &json.RemoveMinerKeyCmd{
+				Name: "rig1",
+			},
+		},
+		{
+			name: "removeminingaddress",
+			newCmd: func() (interface{}, error) {
+
+				return json.NewCmd("removeminingaddress", "1Address")
+			},
+			staticCmd: func() interface{} {
+
+				return json.NewRemoveMiningAddressCmd("1Address")
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"removeminingaddress","params":["1Address"],"id":1}`,
+			unmarshalled: &json.RemoveMiningAddressCmd{
+				Address: "1Address",
+			},
+		},
 		{
 			name: "searchrawtransactions",
 			newCmd: func() (interface{}, error) {
@@ -1035,6 +1456,21 @@ func TestChainSvrCmds(
 				AllowHighFees: json.Bool(false),
 			},
 		},
+		{
+			name: "setcoinbasetag",
+			newCmd: func() (interface{}, error) {
+
+				return json.NewCmd("setcoinbasetag", "/pool/")
+			},
+			staticCmd: func() interface{} {
+
+				return json.NewSetCoinbaseTagCmd("/pool/")
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"setcoinbasetag","params":["/pool/"],"id":1}`,
+			unmarshalled: &json.SetCoinbaseTagCmd{
+				Tag: "/pool/",
+			},
+		},
 		{
 			name: "setgenerate",
 			newCmd: func() (interface{}, error) {
@@ -1043,7 +1479,7 @@ func TestChainSvrCmds(
 			},
 			staticCmd: func() interface{} {
 
-				return json.NewSetGenerateCmd(true, nil)
+				return json.NewSetGenerateCmd(true, nil, nil, nil)
 			},
 			marshalled: `{"jsonrpc":"1.0","method":"setgenerate","params":[true],"id":1}`,
 			unmarshalled: &json.SetGenerateCmd{
@@ -1059,7 +1495,7 @@ func TestChainSvrCmds(
 			},
 			staticCmd: func() interface{} {
 
-				return json.NewSetGenerateCmd(true, json.Int(6))
+				return json.NewSetGenerateCmd(true, json.Int(6), nil, nil)
 			},
 			marshalled: `{"jsonrpc":"1.0","method":"setgenerate","params":[true,6],"id":1}`,
 			unmarshalled: &json.SetGenerateCmd{
@@ -1067,6 +1503,82 @@ func TestChainSvrCmds(
 				GenProcLimit: json.Int(6),
 			},
 		},
+		{
+			name: "setgenerate threads",
+			newCmd: func() (interface{}, error) {
+
+				return json.NewCmd("setgenerate", true, 2, []int{0, 2}, 10)
+			},
+			staticCmd: func() interface{} {
+
+				return json.NewSetGenerateCmd(true, json.Int(2), &[]int{0, 2}, json.Int(10))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"setgenerate","params":[true,2,[0,2],10],"id":1}`,
+			unmarshalled: &json.SetGenerateCmd{
+				Generate:     true,
+				GenProcLimit: json.Int(2),
+				Cores:        &[]int{0, 2},
+				Nice:         json.Int(10),
+			},
+		},
+		{
+			name: "setminingbias",
+			newCmd: func() (interface{}, error) {
+
+				return json.NewCmd("setminingbias", -0.25)
+			},
+			staticCmd: func() interface{} {
+
+				return json.NewSetMiningBiasCmd(-0.25)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"setminingbias","params":[-0.25],"id":1}`,
+			unmarshalled: &json.SetMiningBiasCmd{
+				Bias: -0.25,
+			},
+		},
+		{
+			name: "settemplatecontrols",
+			newCmd: func() (interface{}, error) {
+
+				return json.NewCmd("settemplatecontrols")
+			},
+			staticCmd: func() interface{} {
+
+				return json.NewSetTemplateControlsCmd(nil, nil, nil)
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"settemplatecontrols","params":[],"id":1}`,
+			unmarshalled: &json.SetTemplateControlsCmd{},
+		},
+		{
+			name: "settemplatecontrols optional",
+			newCmd: func() (interface{}, error) {
+
+				return json.NewCmd("settemplatecontrols", []string{"123"}, []string{"456"}, 1000000)
+			},
+			staticCmd: func() interface{} {
+
+				return json.NewSetTemplateControlsCmd(&[]string{"123"}, &[]string{"456"}, json.Uint32(1000000))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"settemplatecontrols","params":[["123"],["456"],1000000],"id":1}`,
+			unmarshalled: &json.SetTemplateControlsCmd{
+				Include:   &[]string{"123"},
+				Exclude:   &[]string{"456"},
+				MaxWeight: json.Uint32(1000000),
+			},
+		},
+		{
+			name: "stageupdate",
+			newCmd: func() (interface{}, error) {
+
+				return json.NewCmd("stageupdate")
+			},
+			staticCmd: func() interface{} {
+
+				return json.NewStageUpdateCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"stageupdate","params":[],"id":1}`,
+			unmarshalled: &json.StageUpdateCmd{},
+		},
 		{
 			name: "stop",
 			newCmd: func() (interface{}, error) {
@@ -4,11 +4,20 @@ import (
 )
 // Bip9SoftForkDescription describes the current state of a defined BIP0009 version bits soft-fork.
 type Bip9SoftForkDescription struct {
-	Status    string `json:"status"`
-	Bit       uint8  `json:"bit"`
-	StartTime int64  `json:"startTime"`
-	Timeout   int64  `json:"timeout"`
-	Since     int32  `json:"since"`
+	Status     string                  `json:"status"`
+	Bit        uint8                   `json:"bit"`
+	StartTime  int64                   `json:"startTime"`
+	Timeout    int64                   `json:"timeout"`
+	Since      int32                   `json:"since"`
+	Statistics *Bip9SoftForkStatistics `json:"statistics,omitempty"`
+}
+// Bip9SoftForkStatistics describes the votes for a BIP0009 soft-fork deployment in the current window while it is being voted on.
+type Bip9SoftForkStatistics struct {
+	Period    uint32 `json:"period"`
+	Threshold uint32 `json:"threshold"`
+	Elapsed   uint32 `json:"elapsed"`
+	Count     uint32 `json:"count"`
+	Possible  bool   `json:"possible"`
 }
 // CreateMultiSigResult models the data returned from the createmultisig command.
 type CreateMultiSigResult struct {
@@ -23,6 +32,19 @@ type DecodeScriptResult struct {
 	Addresses []string `json:"addresses,omitempty"`
 	P2sh      string   `json:"p2sh,omitempty"`
 }
+// EstimateRawFeeBucket models a single fee rate bucket of the confirmation history returned by the estimaterawfee command.
+type EstimateRawFeeBucket struct {
+	MinFeeRate  float64   `json:"minfeerate"`
+	MaxFeeRate  float64   `json:"maxfeerate"`
+	Confirmed   []float64 `json:"confirmed"`
+	Unconfirmed float64   `json:"unconfirmed"`
+}
+// EstimateSmartFeeResult models the data returned from the estimatesmartfee command.
+type EstimateSmartFeeResult struct {
+	FeeRate float64  `json:"feerate,omitempty"`
+	Errors  []string `json:"errors,omitempty"`
+	Blocks  int64    `json:"blocks"`
+}
 // GetAddedNodeInfoResult models the data from the getaddednodeinfo command.
 type GetAddedNodeInfoResult struct {
 	AddedNode string                        `json:"addednode"`
@@ -64,6 +86,34 @@ type GetBlockHeaderVerboseResult struct {
 	PreviousHash  string  `json:"previousblockhash,omitempty"`
 	NextHash      string  `json:"nextblockhash,omitempty"`
 }
+// GetChainTipsResult models the data returned from the getchaintips command.
+type GetChainTipsResult struct {
+	Height    int32  `json:"height"`
+	Hash      string `json:"hash"`
+	BranchLen int32  `json:"branchlen"`
+	Status    string `json:"status"`
+}
+// GetChainTxStatsResult models the data returned from the getchaintxstats command.
+type GetChainTxStatsResult struct {
+	Time                   int64   `json:"time"`
+	TxCount                int64   `json:"txcount"`
+	WindowFinalBlockHash   string  `json:"window_final_block_hash"`
+	WindowFinalBlockHeight int32   `json:"window_final_block_height"`
+	WindowBlockCount       int32   `json:"window_block_count"`
+	WindowTxCount          int64   `json:"window_tx_count,omitempty"`
+	WindowInterval         int64   `json:"window_interval,omitempty"`
+	TxRate                 float64 `json:"txrate,omitempty"`
+}
+// GetDifficultiesResult models the data returned for each algorithm from the getdifficulties command.
+type GetDifficultiesResult struct {
+	Algo           string  `json:"algo"`
+	Version        int32   `json:"version"`
+	Height         int32   `json:"height,omitempty"`
+	Bits           string  `json:"bits,omitempty"`
+	Difficulty     float64 `json:"difficulty,omitempty"`
+	NextBits       string  `json:"nextbits"`
+	NextDifficulty float64 `json:"nextdifficulty"`
+}
 // GetBlockTemplateResult models the data returned from the getblocktemplate command.
 type GetBlockTemplateResult struct {
 	// Base fields from BIP 0022.  CoinbaseAux is optional.  One of CoinbaseTxn or CoinbaseValue must be specified, but not both.
@@ -134,6 +184,12 @@ type GetBlockVerboseResult struct {
 	PreviousHash  string        `json:"previousblockhash"`
 	NextHash      string        `json:"nextblockhash,omitempty"`
 }
+// GetDustInfoResult models the data returned from the getdustinfo command.
+type GetDustInfoResult struct {
+	DustRelayFee float64 `json:"dustrelayfee"`
+	P2PKH        float64 `json:"p2pkh"`
+	P2WPKH       float64 `json:"p2wpkh"`
+}
 // GetMempoolEntryResult models the data returned from the getmempoolentry command.
 type GetMempoolEntryResult struct {
 	Size             int32    `json:"size"`
@@ -151,37 +207,124 @@ type GetMempoolEntryResult struct {
 	AncestorFees     float64  `json:"ancestorfees"`
 	Depends          []string `json:"depends"`
 }
+// GetMempoolInfoFeeBucket models one bucket of the fee rate histogram returned from the getmempoolinfo command.
+type GetMempoolInfoFeeBucket struct {
+	MinFeeRate int64   `json:"minfeerate"`
+	Count      int64   `json:"count"`
+	VSize      int64   `json:"vsize"`
+	Fees       float64 `json:"fees"`
+}
+// GetMempoolInfoAgeBucket models one bucket of the entry age histogram returned from the getmempoolinfo command.
+type GetMempoolInfoAgeBucket struct {
+	MaxAge int64 `json:"maxage"`
+	Count  int64 `json:"count"`
+}
 // GetMempoolInfoResult models the data returned from the getmempoolinfo command.
 type GetMempoolInfoResult struct {
-	Size  int64 `json:"size"`
-	Bytes int64 `json:"bytes"`
+	Size          int64                     `json:"size"`
+	Bytes         int64                     `json:"bytes"`
+	VSize         int64                     `json:"vsize"`
+	Orphans       int64                     `json:"orphans"`
+	MaxMempool    int64                     `json:"maxmempool"`
+	MempoolMinFee float64                   `json:"mempoolminfee"`
+	MinRelayTxFee float64                   `json:"minrelaytxfee"`
+	FeeHistogram  []GetMempoolInfoFeeBucket `json:"feehistogram"`
+	AgeHistogram  []GetMempoolInfoAgeBucket `json:"agehistogram"`
+}
+// GetMinerKeysResult models an API key of the miner workers and its statistics in the getminerkeys result.
+type GetMinerKeysResult struct {
+	Name     string   `json:"name"`
+	Allowed  []string `json:"allowed"`
+	Workers  int      `json:"workers"`
+	Sessions uint32   `json:"sessions"`
+	Jobs     uint64   `json:"jobs"`
+	Accepted uint32   `json:"accepted"`
+	Rejected uint32   `json:"rejected"`
+	LastSeen int64    `json:"lastseen"`
+}
+// GetMiningAddressesResult models the data from the getminingaddresses command.
+type GetMiningAddressesResult struct {
+	Rotation  string                      `json:"rotation"`
+	Addresses []GetMiningAddressesAddress `json:"addresses"`
+}
+// GetMiningAddressesAddress models a payment address and its weight in the getminingaddresses result.
+type GetMiningAddressesAddress struct {
+	Address string  `json:"address"`
+	Weight  float64 `json:"weight"`
+}
+// GetMiningBiasResult models the data from the getminingbias command.
+type GetMiningBiasResult struct {
+	Bias  float64             `json:"bias"`
+	Algos []GetMiningBiasAlgo `json:"algos"`
+}
+// GetMiningBiasAlgo models the chance of an algorithm being picked in the getminingbias result.
+type GetMiningBiasAlgo struct {
+	Algo       string  `json:"algo"`
+	Difficulty float64 `json:"difficulty"`
+	Chance     float64 `json:"chance"`
 }
 // GetMiningInfoResult models the data from the getmininginfo command.
 type GetMiningInfoResult struct {
-	Blocks              int64   `json:"blocks"`
-	CurrentBlockSize    uint64  `json:"currentblocksize"`
-	CurrentBlockWeight  uint64  `json:"currentblockweight"`
-	CurrentBlockTx      uint64  `json:"currentblocktx"`
-	PowAlgoID           uint32  `json:"pow_algo_id"`
-	PowAlgo             string  `json:"pow_algo"`
-	Difficulty          float64 `json:"difficulty"`
-	DifficultyBlake2b   float64 `json:"difficulty_blake2b"`
-	DifficultyBlake14lr float64 `json:"difficulty_blake14lr"`
-	DifficultyBlake2s   float64 `json:"difficulty_blake2s"`
-	DifficultyKeccak    float64 `json:"difficulty_keccak"`
-	DifficultyScrypt    float64 `json:"difficulty_scrypt"`
-	DifficultySHA256D   float64 `json:"difficulty_sha256d"`
-	DifficultySkein     float64 `json:"difficulty_skein"`
-	DifficultyStribog   float64 `json:"difficulty_stribog"`
-	DifficultyX11       float64 `json:"difficulty_x11"`
-	Errors              string  `json:"errors"`
-	Generate            bool    `json:"generate"`
-	GenAlgo             string  `json:"genalgo"`
-	GenProcLimit        int32   `json:"genproclimit"`
-	HashesPerSec        int64   `json:"hashespersec"`
-	NetworkHashPS       int64   `json:"networkhashps"`
-	PooledTx            uint64  `json:"pooledtx"`
-	TestNet             bool    `json:"testnet"`
+	Blocks              int64                    `json:"blocks"`
+	CurrentBlockSize    uint64                   `json:"currentblocksize"`
+	CurrentBlockWeight  uint64                   `json:"currentblockweight"`
+	CurrentBlockTx      uint64                   `json:"currentblocktx"`
+	PowAlgoID           uint32                   `json:"pow_algo_id"`
+	PowAlgo             string                   `json:"pow_algo"`
+	Difficulty          float64                  `json:"difficulty"`
+	DifficultyBlake2b   float64                  `json:"difficulty_blake2b"`
+	DifficultyBlake14lr float64                  `json:"difficulty_blake14lr"`
+	DifficultyBlake2s   float64                  `json:"difficulty_blake2s"`
+	DifficultyKeccak    float64                  `json:"difficulty_keccak"`
+	DifficultyScrypt    float64                  `json:"difficulty_scrypt"`
+	DifficultySHA256D   float64                  `json:"difficulty_sha256d"`
+	DifficultySkein     float64                  `json:"difficulty_skein"`
+	DifficultyStribog   float64                  `json:"difficulty_stribog"`
+	DifficultyX11       float64                  `json:"difficulty_x11"`
+	Errors              string                   `json:"errors"`
+	Generate            bool                     `json:"generate"`
+	GenAlgo             string                   `json:"genalgo"`
+	GenAlgoCurrent      string                   `json:"genalgocurrent,omitempty"`
+	CoinbaseTag         string                   `json:"coinbasetag,omitempty"`
+	GenProcLimit        int32                    `json:"genproclimit"`
+	HashesPerSec        int64                    `json:"hashespersec"`
+	NetworkHashPS       int64                    `json:"networkhashps"`
+	PooledTx            uint64                   `json:"pooledtx"`
+	TestNet             bool                     `json:"testnet"`
+	AlgoStats           []GetMiningInfoAlgoStats `json:"algostats"`
+}
+// GetMiningInfoAlgoStats models the statistics the built-in miner keeps for one algorithm in the getmininginfo result.
+type GetMiningInfoAlgoStats struct {
+	Algo       string  `json:"algo"`
+	Difficulty float64 `json:"difficulty"`
+	Hashes     uint64  `json:"hashes"`
+	Blocks     uint32  `json:"blocks"`
+	Rounds     uint32  `json:"rounds"`
+	Seconds    float64 `json:"seconds"`
+}
+// GetMiningStatsResult models the data from the getminingstats command.
+type GetMiningStatsResult struct {
+	Generate        bool                 `json:"generate"`
+	GenAlgo         string               `json:"genalgo"`
+	GenAlgoCurrent  string               `json:"genalgocurrent,omitempty"`
+	Accepted        uint32               `json:"accepted"`
+	Stale           uint32               `json:"stale"`
+	HashRate1m      float64              `json:"hashrate1m"`
+	HashRate5m      float64              `json:"hashrate5m"`
+	HashRate15m     float64              `json:"hashrate15m"`
+	ExpectedSeconds float64              `json:"expectedseconds"`
+	Algos           []GetMiningStatsAlgo `json:"algos"`
+}
+// GetMiningStatsAlgo models the statistics of the built-in miner for one algorithm in the getminingstats result.
+type GetMiningStatsAlgo struct {
+	Algo            string  `json:"algo"`
+	Difficulty      float64 `json:"difficulty"`
+	Accepted        uint32  `json:"accepted"`
+	Stale           uint32  `json:"stale"`
+	HashRate1m      float64 `json:"hashrate1m"`
+	HashRate5m      float64 `json:"hashrate5m"`
+	HashRate15m     float64 `json:"hashrate15m"`
+	ExpectedSeconds float64 `json:"expectedseconds"`
 }
 type GetMiningInfoResult0 struct {
 	Blocks             int64   `json:"blocks"`
@@ -223,6 +366,27 @@ type GetNetworkInfoResult struct {
 	LocalAddresses  []LocalAddressesResult `json:"localaddresses"`
 	Warnings        string                 `json:"warnings"`
 }
+// GetOrphanInfoEntry models a single orphan transaction returned from the getorphaninfo command.
+type GetOrphanInfoEntry struct {
+	TxID    string   `json:"txid"`
+	Size    int32    `json:"size"`
+	PeerID  uint64   `json:"peerid"`
+	Time    int64    `json:"time"`
+	Expires int64    `json:"expires"`
+	Missing []string `json:"missing"`
+}
+// GetOrphanInfoResult models the data returned from the getorphaninfo command.
+type GetOrphanInfoResult struct {
+	Size       int64                `json:"size"`
+	Added      int64                `json:"added"`
+	Resolved   int64                `json:"resolved"`
+	Expired    int64                `json:"expired"`
+	Evicted    int64                `json:"evicted"`
+	Invalid    int64                `json:"invalid"`
+	Conflicted int64                `json:"conflicted"`
+	Removed    int64                `json:"removed"`
+	Orphans    []GetOrphanInfoEntry `json:"orphans"`
+}
 // GetPeerInfoResult models the data returned from the getpeerinfo command.
 type GetPeerInfoResult struct {
 	ID             int32   `json:"id"`
@@ -256,8 +420,25 @@ type GetRawMempoolVerboseResult struct {
 	Height           int64    `json:"height"`
 	StartingPriority float64  `json:"startingpriority"`
 	CurrentPriority  float64  `json:"currentpriority"`
+	DescendantCount  int64    `json:"descendantcount"`
+	DescendantSize   int64    `json:"descendantsize"`
+	DescendantFees   float64  `json:"descendantfees"`
+	AncestorCount    int64    `json:"ancestorcount"`
+	AncestorSize     int64    `json:"ancestorsize"`
+	AncestorFees     float64  `json:"ancestorfees"`
 	Depends          []string `json:"depends"`
 }
+// GetRawMempoolCompactResult models the data returned from the getrawmempool command when both the verbose and compact flags are set.
+type GetRawMempoolCompactResult struct {
+	TxID    string  `json:"txid"`
+	FeeRate float64 `json:"feerate"`
+}
+// GetTemplateControlsResult models the data from the gettemplatecontrols command.
+type GetTemplateControlsResult struct {
+	Include   []string `json:"include"`
+	Exclude   []string `json:"exclude"`
+	MaxWeight uint32   `json:"maxweight"`
+}
 // GetTxOutResult models the data from the gettxout command.
 type GetTxOutResult struct {
 	BestBlock     string             `json:"bestblock"`
@@ -266,6 +447,17 @@ type GetTxOutResult struct {
 	ScriptPubKey  ScriptPubKeyResult `json:"scriptPubKey"`
 	Coinbase      bool               `json:"coinbase"`
 }
+// GetUpdateInfoResult models the data from the getupdateinfo command.
+type GetUpdateInfoResult struct {
+	Enabled   bool   `json:"enabled"`
+	Version   string `json:"version"`
+	URL       string `json:"url,omitempty"`
+	Checked   int64  `json:"checked,omitempty"`
+	Error     string `json:"error,omitempty"`
+	Available string `json:"available,omitempty"`
+	Notes     string `json:"notes,omitempty"`
+	Staged    string `json:"staged,omitempty"`
+}
 // GetWorkResult models the data from the getwork command.
 type GetWorkResult struct {
 	Data     string `json:"data"`
@@ -273,6 +465,22 @@ type GetWorkResult struct {
 	Midstate string `json:"midstate"`
 	Target   string `json:"target"`
 }
+// GetWorkerInfoResult models a miner worker and the metrics of its transport in the getworkerinfo result.
+type GetWorkerInfoResult struct {
+	Addr          string  `json:"addr"`
+	Transport     string  `json:"transport"`
+	Identity      string  `json:"identity"`
+	Algo          string  `json:"algo"`
+	ConnTime      int64   `json:"conntime"`
+	LastRecv      int64   `json:"lastrecv"`
+	PingTime      float64 `json:"pingtime"`
+	Lost          uint64  `json:"lost"`
+	Rejected      uint64  `json:"rejected"`
+	Replayed      uint64  `json:"replayed"`
+	Reconstructed uint64  `json:"reconstructed"`
+	Corrupt       uint64  `json:"corrupt"`
+	LossRate      float64 `json:"lossrate"`
+}
 // InfoChainResult models the data returned by the chain server getinfo command.
 type InfoChainResult struct {
 	Version             int32   `json:"version"`
@@ -393,10 +601,30 @@ type TxRawResult struct {
 	Time          int64  `json:"time,omitempty"`
 	Blocktime     int64  `json:"blocktime,omitempty"`
 }
+// UtxoSnapshotResult models the utxo snapshot the dumputxoset and loadutxoset commands write and load.
+type UtxoSnapshotResult struct {
+	Path       string `json:"path"`
+	BaseHash   string `json:"basehash"`
+	BaseHeight int32  `json:"baseheight"`
+	TxCount    uint64 `json:"txcount"`
+	Coins      uint64 `json:"coins"`
+	Hash       string `json:"hash"`
+}
 // ValidateAddressChainResult models the data returned by the chain server validateaddress command.
 type ValidateAddressChainResult struct {
 	IsValid bool   `json:"isvalid"`
 	Address string `json:"address,omitempty"`
+	AddressDetails
+}
+// AddressDetails models the description of a valid address in the results of the validateaddress command of the chain and wallet servers.
+type AddressDetails struct {
+	Type           string `json:"type,omitempty"`
+	Network        string `json:"network,omitempty"`
+	ScriptClass    string `json:"scriptclass,omitempty"`
+	ScriptPubKey   string `json:"scriptPubKey,omitempty"`
+	IsWitness      bool   `json:"iswitness,omitempty"`
+	WitnessVersion *int32 `json:"witness_version,omitempty"`
+	WitnessProgram string `json:"witness_program,omitempty"`
 }
 // Vin models parts of the tx data.  It is defined separately since getrawtransaction, decoderawtransaction, and searchrawtransaction use the same structure.
 type Vin struct {
@@ -167,6 +167,32 @@ func TestChainSvrWsCmds(
 				OutPoints: []json.OutPoint{{Hash: "123", Index: 0}},
 			},
 		},
+		{
+			name: "notifywork",
+			newCmd: func() (interface{}, error) {
+
+				return json.NewCmd("notifywork")
+			},
+			staticCmd: func() interface{} {
+
+				return json.NewNotifyWorkCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"notifywork","params":[],"id":1}`,
+			unmarshalled: &json.NotifyWorkCmd{},
+		},
+		{
+			name: "stopnotifywork",
+			newCmd: func() (interface{}, error) {
+
+				return json.NewCmd("stopnotifywork")
+			},
+			staticCmd: func() interface{} {
+
+				return json.NewStopNotifyWorkCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"stopnotifywork","params":[],"id":1}`,
+			unmarshalled: &json.StopNotifyWorkCmd{},
+		},
 		{
 			name: "rescan",
 			newCmd: func() (interface{}, error) {
@@ -5,6 +5,8 @@ const (
 	BlockConnectedNtfnMethod = "blockconnected"
 	// BlockDisconnectedNtfnMethod is the legacy, deprecated method used for notifications from the chain server that a block has been disconnected. NOTE: Deprecated. Use FilteredBlockDisconnectedNtfnMethod instead.
 	BlockDisconnectedNtfnMethod = "blockdisconnected"
+	// ChainReorganizedNtfnMethod is the method used for notifications from the chain server that the main chain was reorganized onto another branch, after the notifications for each block that was disconnected and connected.
+	ChainReorganizedNtfnMethod = "chainreorganized"
 	// FilteredBlockConnectedNtfnMethod is the new method used for notifications from the chain server that a block has been connected.
 	FilteredBlockConnectedNtfnMethod = "filteredblockconnected"
 	// FilteredBlockDisconnectedNtfnMethod is the new method used for notifications from the chain server that a block has been disconnected.
@@ -23,6 +25,8 @@ const (
 	TxAcceptedVerboseNtfnMethod = "txacceptedverbose"
 	// RelevantTxAcceptedNtfnMethod is the new method used for notifications from the chain server that inform a client that a transaction that matches the loaded filter was accepted by the mempool.
 	RelevantTxAcceptedNtfnMethod = "relevanttxaccepted"
+	// WorkNtfnMethod is the method used for notifications from the chain server that carry a new block template to miners that registered with notifywork.
+	WorkNtfnMethod = "work"
 )
 // BlockConnectedNtfn defines the blockconnected JSON-RPC notification. NOTE: Deprecated. Use FilteredBlockConnectedNtfn instead.
 type BlockConnectedNtfn struct {
@@ -54,6 +58,29 @@ func NewBlockDisconnectedNtfn(
 		Time:   time,
 	}
 }
+// ReorgDetails describes a reorganization of the main chain from the old tip to the new one through the block where their branches fork.
+type ReorgDetails struct {
+	OldTip       string   `json:"oldtip"`
+	OldHeight    int32    `json:"oldheight"`
+	NewTip       string   `json:"newtip"`
+	NewHeight    int32    `json:"newheight"`
+	Fork         string   `json:"fork"`
+	ForkHeight   int32    `json:"forkheight"`
+	Depth        int32    `json:"depth"`
+	Disconnected []string `json:"disconnected"`
+	Connected    []string `json:"connected"`
+}
+// ChainReorganizedNtfn defines the chainreorganized JSON-RPC notification.
+type ChainReorganizedNtfn struct {
+	Reorg ReorgDetails
+}
+// NewChainReorganizedNtfn returns a new instance which can be used to issue a chainreorganized JSON-RPC notification.
+func NewChainReorganizedNtfn(
+	reorg ReorgDetails) *ChainReorganizedNtfn {
+	return &ChainReorganizedNtfn{
+		Reorg: reorg,
+	}
+}
 // FilteredBlockConnectedNtfn defines the filteredblockconnected JSON-RPC notification.
 type FilteredBlockConnectedNtfn struct {
 	Height        int32
@@ -178,11 +205,36 @@ func NewRelevantTxAcceptedNtfn(
 	txHex string) *RelevantTxAcceptedNtfn {
 	return &RelevantTxAcceptedNtfn{Transaction: txHex}
 }
+// Reasons given in a work notification for the new block template.
+const (
+	// WorkReasonSubscribed is the reason given for the template sent when a client registers for work notifications.
+	WorkReasonSubscribed = "subscribed"
+	// WorkReasonConnected is the reason given when a block connected to the best chain made the previous template stale.
+	WorkReasonConnected = "connected"
+	// WorkReasonDisconnected is the reason given when a block disconnected from the best chain made the previous template stale.
+	WorkReasonDisconnected = "disconnected"
+	// WorkReasonMempool is the reason given when the template was regenerated to include new transactions from the mempool.
+	WorkReasonMempool = "mempool"
+)
+// WorkNtfn defines the work JSON-RPC notification.
+type WorkNtfn struct {
+	Reason   string
+	Template GetBlockTemplateResult
+}
+// NewWorkNtfn returns a new instance which can be used to issue a work JSON-RPC notification.
+func NewWorkNtfn(
+	reason string, template GetBlockTemplateResult) *WorkNtfn {
+	return &WorkNtfn{
+		Reason:   reason,
+		Template: template,
+	}
+}
 func init() {
 	// The commands in this file are only usable by websockets and are notifications.
 	flags := UFWebsocketOnly | UFNotification
 	MustRegisterCmd(BlockConnectedNtfnMethod, (*BlockConnectedNtfn)(nil), flags)
 	MustRegisterCmd(BlockDisconnectedNtfnMethod, (*BlockDisconnectedNtfn)(nil), flags)
+	MustRegisterCmd(ChainReorganizedNtfnMethod, (*ChainReorganizedNtfn)(nil), flags)
 	MustRegisterCmd(FilteredBlockConnectedNtfnMethod, (*FilteredBlockConnectedNtfn)(nil), flags)
 	MustRegisterCmd(FilteredBlockDisconnectedNtfnMethod, (*FilteredBlockDisconnectedNtfn)(nil), flags)
 	MustRegisterCmd(RecvTxNtfnMethod, (*RecvTxNtfn)(nil), flags)
@@ -192,4 +244,5 @@ func init() {
 	MustRegisterCmd(TxAcceptedNtfnMethod, (*TxAcceptedNtfn)(nil), flags)
 	MustRegisterCmd(TxAcceptedVerboseNtfnMethod, (*TxAcceptedVerboseNtfn)(nil), flags)
 	MustRegisterCmd(RelevantTxAcceptedNtfnMethod, (*RelevantTxAcceptedNtfn)(nil), flags)
+	MustRegisterCmd(WorkNtfnMethod, (*WorkNtfn)(nil), flags)
 }
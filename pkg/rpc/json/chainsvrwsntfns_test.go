@@ -240,6 +240,72 @@ func TestChainSvrWsNtfns(
 				Transaction: "001122",
 			},
 		},
+		{
+			name: "chainreorganized",
+			newNtfn: func() (interface{}, error) {
+
+				return json.NewCmd("chainreorganized", `{"oldtip":"123","oldheight":100001,"newtip":"456","newheight":100002,"fork":"789","forkheight":100000,"depth":1,"disconnected":["123"],"connected":["abc","456"]}`)
+			},
+			staticNtfn: func() interface{} {
+
+				return json.NewChainReorganizedNtfn(json.ReorgDetails{
+					OldTip:       "123",
+					OldHeight:    100001,
+					NewTip:       "456",
+					NewHeight:    100002,
+					Fork:         "789",
+					ForkHeight:   100000,
+					Depth:        1,
+					Disconnected: []string{"123"},
+					Connected:    []string{"abc", "456"},
+				})
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"chainreorganized","params":[{"oldtip":"123","oldheight":100001,"newtip":"456","newheight":100002,"fork":"789","forkheight":100000,"depth":1,"disconnected":["123"],"connected":["abc","456"]}],"id":null}`,
+			unmarshalled: &json.ChainReorganizedNtfn{
+				Reorg: json.ReorgDetails{
+					OldTip:       "123",
+					OldHeight:    100001,
+					NewTip:       "456",
+					NewHeight:    100002,
+					Fork:         "789",
+					ForkHeight:   100000,
+					Depth:        1,
+					Disconnected: []string{"123"},
+					Connected:    []string{"abc", "456"},
+				},
+			},
+		},
+		{
+			name: "work",
+			newNtfn: func() (interface{}, error) {
+
+				return json.NewCmd("work", "connected", `{"bits":"1d00ffff","curtime":123456789,"height":100000,"previousblockhash":"123","transactions":[],"version":2}`)
+			},
+			staticNtfn: func() interface{} {
+
+				template := json.GetBlockTemplateResult{
+					Bits:         "1d00ffff",
+					CurTime:      123456789,
+					Height:       100000,
+					PreviousHash: "123",
+					Transactions: []json.GetBlockTemplateResultTx{},
+					Version:      2,
+				}
+				return json.NewWorkNtfn(json.WorkReasonConnected, template)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"work","params":["connected",{"bits":"1d00ffff","curtime":123456789,"height":100000,"previousblockhash":"123","transactions":[],"version":2}],"id":null}`,
+			unmarshalled: &json.WorkNtfn{
+				Reason: "connected",
+				Template: json.GetBlockTemplateResult{
+					Bits:         "1d00ffff",
+					CurTime:      123456789,
+					Height:       100000,
+					PreviousHash: "123",
+					Transactions: []json.GetBlockTemplateResultTx{},
+					Version:      2,
+				},
+			},
+		},
 	}
 	t.Logf("Running %d tests", len(tests))
 
@@ -31,6 +31,24 @@ func MethodUsageFlags(
 	}
 	return info.flags, nil
 }
+// MethodParamTypes returns the types of the parameters of the passed command method in order, and how many of them are required, so requests of the right shape can be built without knowing the command. The provided method must be associated with a registered type.  All commands provided by this package are registered by default.
+func MethodParamTypes(
+	method string) ([]reflect.Type, int, error) {
+	registerLock.RLock()
+	rtp, ok := methodToConcreteType[method]
+	info := methodToInfo[method]
+	registerLock.RUnlock()
+	if !ok {
+		str := fmt.Sprintf("%q is not registered", method)
+		return nil, 0, makeError(ErrUnregisteredMethod, str)
+	}
+	rt := rtp.Elem()
+	types := make([]reflect.Type, rt.NumField())
+	for i := range types {
+		types[i] = rt.Field(i).Type
+	}
+	return types, info.numReqParams, nil
+}
 // subStructUsage returns a string for use in the one-line usage for the given sub struct.  Note that this is specifically for fields which consist of structs (or an array/slice of structs) as opposed to the top-level command struct. Any fields that include a jsonrpcusage struct tag will use that instead of being automatically generated.
 func subStructUsage(
 	structType reflect.Type) string {
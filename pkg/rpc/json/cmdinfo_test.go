@@ -110,6 +110,26 @@ func TestMethodUsageFlags(
 		}
 	}
 }
+// TestMethodParamTypes tests the MethodParamTypes function to ensure it returns the types of the parameters in order and how many are required.
+func TestMethodParamTypes(
+	t *testing.T) {
+	t.Parallel()
+	if _, _, err := json.MethodParamTypes("bogusmethod"); err == nil ||
+		err.(json.Error).ErrorCode != json.ErrUnregisteredMethod {
+		t.Fatalf("unregistered method - got %v, want ErrUnregisteredMethod", err)
+	}
+	types, required, err := json.MethodParamTypes("getblock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []reflect.Type{reflect.TypeOf(""), reflect.TypeOf((*bool)(nil)), reflect.TypeOf((*bool)(nil))}
+	if !reflect.DeepEqual(types, want) {
+		t.Errorf("getblock parameter types - got %v, want %v", types, want)
+	}
+	if required != 1 {
+		t.Errorf("getblock required parameters - got %d, want 1", required)
+	}
+}
 // TestMethodUsageText tests the MethodUsageText function ensure it returns the expected text.
 func TestMethodUsageText(
 	t *testing.T) {
@@ -60,10 +60,9 @@ func resultStructHelp(
 	indent := strings.Repeat(" ", indentLevel)
 	typeName := strings.ToLower(rt.Name())
 	// Generate the help for each of the fields in the result struct.
-	numField := rt.NumField()
-	results := make([]string, 0, numField)
-	for i := 0; i < numField; i++ {
-		rtf := rt.Field(i)
+	fields := resultStructFields(rt)
+	results := make([]string, 0, len(fields))
+	for _, rtf := range fields {
 		// The field name to display is the json name when it's available, otherwise use the lowercase field name.
 		var fieldName string
 		if tag := rtf.Tag.Get("json"); tag != "" {
@@ -102,6 +101,20 @@ func resultStructHelp(
 	}
 	return results
 }
+// resultStructFields returns the fields of a result struct, with those of embedded structs in place of the embedded struct as they are marshalled.
+func resultStructFields(
+	rt reflect.Type) []reflect.StructField {
+	fields := make([]reflect.StructField, 0, rt.NumField())
+	for i := 0; i < rt.NumField(); i++ {
+		rtf := rt.Field(i)
+		if rtf.Anonymous && rtf.Type.Kind() == reflect.Struct && rtf.Tag.Get("json") == "" {
+			fields = append(fields, resultStructFields(rtf.Type)...)
+			continue
+		}
+		fields = append(fields, rtf)
+	}
+	return fields
+}
 // reflectTypeToJSONExample generates example usage in the format used by the help output.  It handles arrays, slices and structs recursively.  The output is returned as a slice of lines so the final help can be nicely aligned via a tab writer.  A bool is also returned which specifies whether or not the type results in a complex JSON object since they need to be handled differently.
 func reflectTypeToJSONExample(
 	xT descLookupFunc, rt reflect.Type, indentLevel int, fieldDescKey string) ([]string, bool) {
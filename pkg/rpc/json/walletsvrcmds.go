@@ -26,6 +26,23 @@ func NewAddWitnessAddressCmd(
 		Address: address,
 	}
 }
+// BumpFeeOpts models the options of the bumpfee JSON-RPC command.
+type BumpFeeOpts struct {
+	FeeRate *float64 `json:"feeRate,omitempty"` // In DUO/kB
+}
+// BumpFeeCmd defines the bumpfee JSON-RPC command.
+type BumpFeeCmd struct {
+	Txid    string
+	Options *BumpFeeOpts
+}
+// NewBumpFeeCmd returns a new instance which can be used to issue a bumpfee JSON-RPC command. The parameters which are pointers indicate they are optional.  Passing nil for optional parameters will use the default value.
+func NewBumpFeeCmd(
+	txid string, options *BumpFeeOpts) *BumpFeeCmd {
+	return &BumpFeeCmd{
+		Txid:    txid,
+		Options: options,
+	}
+}
 // CreateMultisigCmd defines the createmultisig JSON-RPC command.
 type CreateMultisigCmd struct {
 	NRequired int
@@ -290,6 +307,27 @@ func NewListSinceBlockCmd(
 		IncludeWatchOnly:    includeWatchOnly,
 	}
 }
+// FilterTransactionsCmd defines the filtertransactions JSON-RPC command. From and To are Unix times, and zero values of any of the parameters match every transaction.
+type FilterTransactionsCmd struct {
+	From      *int64   `jsonrpcdefault:"0"`
+	To        *int64   `jsonrpcdefault:"0"`
+	Label     *string  `jsonrpcdefault:"\"\""`
+	Address   *string  `jsonrpcdefault:"\"\""`
+	MinAmount *float64 `jsonrpcdefault:"0"`
+	MaxAmount *float64 `jsonrpcdefault:"0"`
+}
+// NewFilterTransactionsCmd returns a new instance which can be used to issue a filtertransactions JSON-RPC command. The parameters which are pointers indicate they are optional.  Passing nil for optional parameters will use the default value.
+func NewFilterTransactionsCmd(
+	from, to *int64, label, address *string, minAmount, maxAmount *float64) *FilterTransactionsCmd {
+	return &FilterTransactionsCmd{
+		From:      from,
+		To:        to,
+		Label:     label,
+		Address:   address,
+		MinAmount: minAmount,
+		MaxAmount: maxAmount,
+	}
+}
 // ListTransactionsCmd defines the listtransactions JSON-RPC command.
 type ListTransactionsCmd struct {
 	Account          *string
@@ -470,6 +508,70 @@ func NewSignRawTransactionCmd(
 		Flags:    flags,
 	}
 }
+// DecodePsbtCmd defines the decodepsbt JSON-RPC command.
+type DecodePsbtCmd struct {
+	Psbt string
+}
+// NewDecodePsbtCmd returns a new instance which can be used to issue a decodepsbt JSON-RPC command.
+func NewDecodePsbtCmd(
+	psbt string) *DecodePsbtCmd {
+	return &DecodePsbtCmd{
+		Psbt: psbt,
+	}
+}
+// FinalizePsbtCmd defines the finalizepsbt JSON-RPC command.
+type FinalizePsbtCmd struct {
+	Psbt    string
+	Extract *bool `jsonrpcdefault:"true"`
+}
+// NewFinalizePsbtCmd returns a new instance which can be used to issue a finalizepsbt JSON-RPC command. The parameters which are pointers indicate they are optional.  Passing nil for optional parameters will use the default value.
+func NewFinalizePsbtCmd(
+	psbt string, extract *bool) *FinalizePsbtCmd {
+	return &FinalizePsbtCmd{
+		Psbt:    psbt,
+		Extract: extract,
+	}
+}
+// WalletCreateFundedPsbtOpts models the options of the walletcreatefundedpsbt JSON-RPC command.
+type WalletCreateFundedPsbtOpts struct {
+	Account      *string  `json:"account,omitempty"`
+	MinConf      *int     `json:"minconf,omitempty"`
+	LockUnspents *bool    `json:"lockUnspents,omitempty"`
+	FeeRate      *float64 `json:"feeRate,omitempty"` // In DUO/kB
+}
+// WalletCreateFundedPsbtCmd defines the walletcreatefundedpsbt JSON-RPC command.
+type WalletCreateFundedPsbtCmd struct {
+	Inputs   []TransactionInput
+	Amounts  map[string]float64 `jsonrpcusage:"{\"address\":amount,...}"` // In DUO
+	LockTime *int64             `jsonrpcdefault:"0"`
+	Options  *WalletCreateFundedPsbtOpts
+}
+// NewWalletCreateFundedPsbtCmd returns a new instance which can be used to issue a walletcreatefundedpsbt JSON-RPC command. Amounts are in DUO. The parameters which are pointers indicate they are optional.  Passing nil for optional parameters will use the default value.
+func NewWalletCreateFundedPsbtCmd(
+	inputs []TransactionInput, amounts map[string]float64, lockTime *int64,
+	options *WalletCreateFundedPsbtOpts) *WalletCreateFundedPsbtCmd {
+	return &WalletCreateFundedPsbtCmd{
+		Inputs:   inputs,
+		Amounts:  amounts,
+		LockTime: lockTime,
+		Options:  options,
+	}
+}
+// WalletProcessPsbtCmd defines the walletprocesspsbt JSON-RPC command.
+type WalletProcessPsbtCmd struct {
+	Psbt        string
+	Sign        *bool   `jsonrpcdefault:"true"`
+	SighashType *string `jsonrpcdefault:"\"ALL\""`
+}
+// NewWalletProcessPsbtCmd returns a new instance which can be used to issue a walletprocesspsbt JSON-RPC command. The parameters which are pointers indicate they are optional.  Passing nil for optional parameters will use the default value.
+func NewWalletProcessPsbtCmd(
+	psbt string, sign *bool, sighashType *string) *WalletProcessPsbtCmd {
+	return &WalletProcessPsbtCmd{
+		Psbt:        psbt,
+		Sign:        sign,
+		SighashType: sighashType,
+	}
+}
 // WalletLockCmd defines the walletlock JSON-RPC command.
 type WalletLockCmd struct{}
 // NewWalletLockCmd returns a new instance which can be used to issue a walletlock JSON-RPC command.
@@ -507,11 +609,15 @@ func init() {
 	flags := UFWalletOnly
 	MustRegisterCmd("addmultisigaddress", (*AddMultisigAddressCmd)(nil), flags)
 	MustRegisterCmd("addwitnessaddress", (*AddWitnessAddressCmd)(nil), flags)
+	MustRegisterCmd("bumpfee", (*BumpFeeCmd)(nil), flags)
 	MustRegisterCmd("createmultisig", (*CreateMultisigCmd)(nil), flags)
+	MustRegisterCmd("decodepsbt", (*DecodePsbtCmd)(nil), flags)
 	MustRegisterCmd("dumpprivkey", (*DumpPrivKeyCmd)(nil), flags)
 	MustRegisterCmd("encryptwallet", (*EncryptWalletCmd)(nil), flags)
 	MustRegisterCmd("estimatefee", (*EstimateFeeCmd)(nil), flags)
 	MustRegisterCmd("estimatepriority", (*EstimatePriorityCmd)(nil), flags)
+	MustRegisterCmd("filtertransactions", (*FilterTransactionsCmd)(nil), flags)
+	MustRegisterCmd("finalizepsbt", (*FinalizePsbtCmd)(nil), flags)
 	MustRegisterCmd("getaccount", (*GetAccountCmd)(nil), flags)
 	MustRegisterCmd("getaccountaddress", (*GetAccountAddressCmd)(nil), flags)
 	MustRegisterCmd("getaddressesbyaccount", (*GetAddressesByAccountCmd)(nil), flags)
@@ -541,7 +647,9 @@ func init() {
 	MustRegisterCmd("settxfee", (*SetTxFeeCmd)(nil), flags)
 	MustRegisterCmd("signmessage", (*SignMessageCmd)(nil), flags)
 	MustRegisterCmd("signrawtransaction", (*SignRawTransactionCmd)(nil), flags)
+	MustRegisterCmd("walletcreatefundedpsbt", (*WalletCreateFundedPsbtCmd)(nil), flags)
 	MustRegisterCmd("walletlock", (*WalletLockCmd)(nil), flags)
 	MustRegisterCmd("walletpassphrase", (*WalletPassphraseCmd)(nil), flags)
 	MustRegisterCmd("walletpassphrasechange", (*WalletPassphraseChangeCmd)(nil), flags)
+	MustRegisterCmd("walletprocesspsbt", (*WalletProcessPsbtCmd)(nil), flags)
 }
@@ -74,6 +74,37 @@ func TestWalletSvrCmds(
 				Address: "1address",
 			},
 		},
+		{
+			name: "bumpfee",
+			newCmd: func() (interface{}, error) {
+
+				return json.NewCmd("bumpfee", "123")
+			},
+			staticCmd: func() interface{} {
+
+				return json.NewBumpFeeCmd("123", nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"bumpfee","params":["123"],"id":1}`,
+			unmarshalled: &json.BumpFeeCmd{
+				Txid: "123",
+			},
+		},
+		{
+			name: "bumpfee optional",
+			newCmd: func() (interface{}, error) {
+
+				return json.NewCmd("bumpfee", "123", `{"feeRate":0.0002}`)
+			},
+			staticCmd: func() interface{} {
+
+				return json.NewBumpFeeCmd("123", &json.BumpFeeOpts{FeeRate: json.Float64(0.0002)})
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"bumpfee","params":["123",{"feeRate":0.0002}],"id":1}`,
+			unmarshalled: &json.BumpFeeCmd{
+				Txid:    "123",
+				Options: &json.BumpFeeOpts{FeeRate: json.Float64(0.0002)},
+			},
+		},
 		{
 			name: "createmultisig",
 			newCmd: func() (interface{}, error) {
@@ -754,6 +785,46 @@ func TestWalletSvrCmds(
 				IncludeWatchOnly:    json.Bool(true),
 			},
 		},
+		{
+			name: "filtertransactions",
+			newCmd: func() (interface{}, error) {
+
+				return json.NewCmd("filtertransactions")
+			},
+			staticCmd: func() interface{} {
+
+				return json.NewFilterTransactionsCmd(nil, nil, nil, nil, nil, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"filtertransactions","params":[],"id":1}`,
+			unmarshalled: &json.FilterTransactionsCmd{
+				From:      json.Int64(0),
+				To:        json.Int64(0),
+				Label:     json.String(""),
+				Address:   json.String(""),
+				MinAmount: json.Float64(0),
+				MaxAmount: json.Float64(0),
+			},
+		},
+		{
+			name: "filtertransactions optional",
+			newCmd: func() (interface{}, error) {
+
+				return json.NewCmd("filtertransactions", 1500000000, 1600000000, "shop", "1Address", 0.5, 10.0)
+			},
+			staticCmd: func() interface{} {
+
+				return json.NewFilterTransactionsCmd(json.Int64(1500000000), json.Int64(1600000000), json.String("shop"), json.String("1Address"), json.Float64(0.5), json.Float64(10))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"filtertransactions","params":[1500000000,1600000000,"shop","1Address",0.5,10],"id":1}`,
+			unmarshalled: &json.FilterTransactionsCmd{
+				From:      json.Int64(1500000000),
+				To:        json.Int64(1600000000),
+				Label:     json.String("shop"),
+				Address:   json.String("1Address"),
+				MinAmount: json.Float64(0.5),
+				MaxAmount: json.Float64(10),
+			},
+		},
 		{
 			name: "listtransactions",
 			newCmd: func() (interface{}, error) {
@@ -1308,6 +1379,140 @@ func TestWalletSvrCmds(
 				Flags:    json.String("ALL"),
 			},
 		},
+		{
+			name: "decodepsbt",
+			newCmd: func() (interface{}, error) {
+
+				return json.NewCmd("decodepsbt", "cHNidP8=")
+			},
+			staticCmd: func() interface{} {
+
+				return json.NewDecodePsbtCmd("cHNidP8=")
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"decodepsbt","params":["cHNidP8="],"id":1}`,
+			unmarshalled: &json.DecodePsbtCmd{
+				Psbt: "cHNidP8=",
+			},
+		},
+		{
+			name: "finalizepsbt",
+			newCmd: func() (interface{}, error) {
+
+				return json.NewCmd("finalizepsbt", "cHNidP8=")
+			},
+			staticCmd: func() interface{} {
+
+				return json.NewFinalizePsbtCmd("cHNidP8=", nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"finalizepsbt","params":["cHNidP8="],"id":1}`,
+			unmarshalled: &json.FinalizePsbtCmd{
+				Psbt:    "cHNidP8=",
+				Extract: json.Bool(true),
+			},
+		},
+		{
+			name: "finalizepsbt optional",
+			newCmd: func() (interface{}, error) {
+
+				return json.NewCmd("finalizepsbt", "cHNidP8=", false)
+			},
+			staticCmd: func() interface{} {
+
+				return json.NewFinalizePsbtCmd("cHNidP8=", json.Bool(false))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"finalizepsbt","params":["cHNidP8=",false],"id":1}`,
+			unmarshalled: &json.FinalizePsbtCmd{
+				Psbt:    "cHNidP8=",
+				Extract: json.Bool(false),
+			},
+		},
+		{
+			name: "walletcreatefundedpsbt",
+			newCmd: func() (interface{}, error) {
+
+				return json.NewCmd("walletcreatefundedpsbt", `[{"txid":"123","vout":1}]`,
+					`{"456":0.0123}`)
+			},
+			staticCmd: func() interface{} {
+
+				txInputs := []json.TransactionInput{
+					{Txid: "123", Vout: 1},
+				}
+				amounts := map[string]float64{"456": .0123}
+				return json.NewWalletCreateFundedPsbtCmd(txInputs, amounts, nil, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"walletcreatefundedpsbt","params":[[{"txid":"123","vout":1}],{"456":0.0123}],"id":1}`,
+			unmarshalled: &json.WalletCreateFundedPsbtCmd{
+				Inputs:   []json.TransactionInput{{Txid: "123", Vout: 1}},
+				Amounts:  map[string]float64{"456": .0123},
+				LockTime: json.Int64(0),
+			},
+		},
+		{
+			name: "walletcreatefundedpsbt optional",
+			newCmd: func() (interface{}, error) {
+
+				return json.NewCmd("walletcreatefundedpsbt", `[]`, `{"456":0.0123}`, 12312333333,
+					`{"account":"savings","minconf":6,"lockUnspents":true,"feeRate":0.0002}`)
+			},
+			staticCmd: func() interface{} {
+
+				amounts := map[string]float64{"456": .0123}
+				options := json.WalletCreateFundedPsbtOpts{
+					Account:      json.String("savings"),
+					MinConf:      json.Int(6),
+					LockUnspents: json.Bool(true),
+					FeeRate:      json.Float64(0.0002),
+				}
+				return json.NewWalletCreateFundedPsbtCmd([]json.TransactionInput{}, amounts, json.Int64(12312333333), &options)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"walletcreatefundedpsbt","params":[[],{"456":0.0123},12312333333,{"account":"savings","minconf":6,"lockUnspents":true,"feeRate":0.0002}],"id":1}`,
+			unmarshalled: &json.WalletCreateFundedPsbtCmd{
+				Inputs:   []json.TransactionInput{},
+				Amounts:  map[string]float64{"456": .0123},
+				LockTime: json.Int64(12312333333),
+				Options: &json.WalletCreateFundedPsbtOpts{
+					Account:      json.String("savings"),
+					MinConf:      json.Int(6),
+					LockUnspents: json.Bool(true),
+					FeeRate:      json.Float64(0.0002),
+				},
+			},
+		},
+		{
+			name: "walletprocesspsbt",
+			newCmd: func() (interface{}, error) {
+
+				return json.NewCmd("walletprocesspsbt", "cHNidP8=")
+			},
+			staticCmd: func() interface{} {
+
+				return json.NewWalletProcessPsbtCmd("cHNidP8=", nil, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"walletprocesspsbt","params":["cHNidP8="],"id":1}`,
+			unmarshalled: &json.WalletProcessPsbtCmd{
+				Psbt:        "cHNidP8=",
+				Sign:        json.Bool(true),
+				SighashType: json.String("ALL"),
+			},
+		},
+		{
+			name: "walletprocesspsbt optional",
+			newCmd: func() (interface{}, error) {
+
+				return json.NewCmd("walletprocesspsbt", "cHNidP8=", false, "SINGLE|ANYONECANPAY")
+			},
+			staticCmd: func() interface{} {
+
+				return json.NewWalletProcessPsbtCmd("cHNidP8=", json.Bool(false), json.String("SINGLE|ANYONECANPAY"))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"walletprocesspsbt","params":["cHNidP8=",false,"SINGLE|ANYONECANPAY"],"id":1}`,
+			unmarshalled: &json.WalletProcessPsbtCmd{
+				Psbt:        "cHNidP8=",
+				Sign:        json.Bool(false),
+				SighashType: json.String("SINGLE|ANYONECANPAY"),
+			},
+		},
 		{
 			name: "walletlock",
 			newCmd: func() (interface{}, error) {
@@ -1,4 +1,10 @@
 package json
+// BumpFeeResult models the data from the bumpfee command.
+type BumpFeeResult struct {
+	Txid    string  `json:"txid"`
+	OrigFee float64 `json:"origfee"`
+	Fee     float64 `json:"fee"`
+}
 // GetTransactionDetailsResult models the details data from the gettransaction command. This models the "short" version of the ListTransactionsResult type, which excludes fields common to the transaction.  These common fields are instead part of the GetTransactionResult.
 type GetTransactionDetailsResult struct {
 	Account           string   `json:"account"`
@@ -127,9 +133,75 @@ type ValidateAddressWalletResult struct {
 	Hex          string   `json:"hex,omitempty"`
 	Script       string   `json:"script,omitempty"`
 	SigsRequired int32    `json:"sigsrequired,omitempty"`
+	AddressDetails
+}
+// GetSyncStatusResult models the data from the getsyncstatus command.
+type GetSyncStatusResult struct {
+	Backend       string `json:"backend"`
+	Synced        bool   `json:"synced"`
+	Height        int32  `json:"height"`
+	Headers       int32  `json:"headers"`
+	FilterHeaders int32  `json:"filterheaders"`
+	Peers         int32  `json:"peers"`
 }
 // GetBestBlockResult models the data from the getbestblock command.
 type GetBestBlockResult struct {
 	Hash   string `json:"hash"`
 	Height int32  `json:"height"`
 }
+// WalletCreateFundedPsbtResult models the data from the walletcreatefundedpsbt command.
+type WalletCreateFundedPsbtResult struct {
+	Psbt      string  `json:"psbt"`
+	Fee       float64 `json:"fee"`
+	ChangePos int     `json:"changepos"`
+}
+// WalletProcessPsbtResult models the data from the walletprocesspsbt command.
+type WalletProcessPsbtResult struct {
+	Psbt     string `json:"psbt"`
+	Complete bool   `json:"complete"`
+}
+// FinalizePsbtResult models the data from the finalizepsbt command.  Hex is set instead of Psbt when the transaction is complete and was extracted.
+type FinalizePsbtResult struct {
+	Psbt     string `json:"psbt,omitempty"`
+	Hex      string `json:"hex,omitempty"`
+	Complete bool   `json:"complete"`
+}
+// PsbtBip32DerivResult models the derivation of a key of an input or output of a partially signed transaction.
+type PsbtBip32DerivResult struct {
+	PubKey            string `json:"pubkey"`
+	MasterFingerprint string `json:"master_fingerprint"`
+	Path              string `json:"path"`
+}
+// PsbtWitnessUtxoResult models the output spent by a segwit input of a partially signed transaction.
+type PsbtWitnessUtxoResult struct {
+	Amount       float64            `json:"amount"`
+	ScriptPubKey ScriptPubKeyResult `json:"scriptPubKey"`
+}
+// PsbtInputResult models an input of a partially signed transaction in the decodepsbt command.
+type PsbtInputResult struct {
+	NonWitnessUtxo     *TxRawDecodeResult     `json:"non_witness_utxo,omitempty"`
+	WitnessUtxo        *PsbtWitnessUtxoResult `json:"witness_utxo,omitempty"`
+	PartialSignatures  map[string]string      `json:"partial_signatures,omitempty"`
+	Sighash            string                 `json:"sighash,omitempty"`
+	RedeemScript       *ScriptPubKeyResult    `json:"redeem_script,omitempty"`
+	WitnessScript      *ScriptPubKeyResult    `json:"witness_script,omitempty"`
+	Bip32Derivs        []PsbtBip32DerivResult `json:"bip32_derivs,omitempty"`
+	FinalScriptSig     *ScriptSig             `json:"final_scriptSig,omitempty"`
+	FinalScriptWitness []string               `json:"final_scriptwitness,omitempty"`
+	Unknown            map[string]string      `json:"unknown,omitempty"`
+}
+// PsbtOutputResult models an output of a partially signed transaction in the decodepsbt command.
+type PsbtOutputResult struct {
+	RedeemScript  *ScriptPubKeyResult    `json:"redeem_script,omitempty"`
+	WitnessScript *ScriptPubKeyResult    `json:"witness_script,omitempty"`
+	Bip32Derivs   []PsbtBip32DerivResult `json:"bip32_derivs,omitempty"`
+	Unknown       map[string]string      `json:"unknown,omitempty"`
+}
+// DecodePsbtResult models the data from the decodepsbt command.  Fee is only set when the outputs spent by every input are known.
+type DecodePsbtResult struct {
+	Tx      TxRawDecodeResult  `json:"tx"`
+	Unknown map[string]string  `json:"unknown"`
+	Inputs  []PsbtInputResult  `json:"inputs"`
+	Outputs []PsbtOutputResult `json:"outputs"`
+	Fee     *float64           `json:"fee,omitempty"`
+}
@@ -8,6 +8,8 @@ import (
 	js "encoding/json"
 	"errors"
 	"fmt"
+	"math"
+	"strings"
 	"sync"
 	"time"
 	chaincfg "git.parallelcoin.io/dev/9/pkg/chain/config"
@@ -66,8 +68,11 @@ var rpcHandlers = map[string]struct {
 }{
 	// Reference implementation wallet methods (implemented)
 	"addmultisigaddress":     {handler: addMultiSigAddress},
+	"bumpfee":                {handler: bumpFee},
 	"createmultisig":         {handler: createMultiSig},
+	"decodepsbt":             {handler: decodePsbt},
 	"dumpprivkey":            {handler: dumpPrivKey},
+	"finalizepsbt":           {handler: finalizePsbt},
 	"getaccount":             {handler: getAccount},
 	"getaccountaddress":      {handler: getAccountAddress},
 	"getaddressesbyaccount":  {handler: getAddressesByAccount},
@@ -99,9 +104,11 @@ var rpcHandlers = map[string]struct {
 	"signrawtransaction":     {handlerWithChain: signRawTransaction},
 	"validateaddress":        {handler: validateAddress},
 	"verifymessage":          {handler: verifyMessage},
+	"walletcreatefundedpsbt": {handler: walletCreateFundedPsbt},
 	"walletlock":             {handler: walletLock},
 	"walletpassphrase":       {handler: walletPassphrase},
 	"walletpassphrasechange": {handler: walletPassphraseChange},
+	"walletprocesspsbt":      {handler: walletProcessPsbt},
 	// Reference implementation methods (still unimplemented)
 	"backupwallet":         {handler: unimplemented, noHelp: true},
 	"dumpwallet":           {handler: unimplemented, noHelp: true},
@@ -114,8 +121,10 @@ var rpcHandlers = map[string]struct {
 	"move":          {handler: unsupported, noHelp: true},
 	"setaccount":    {handler: unsupported, noHelp: true},
 	// Extensions to the reference client JSON-RPC API
-	"createnewaccount": {handler: createNewAccount},
-	"getbestblock":     {handler: getBestBlock},
+	"createnewaccount":   {handler: createNewAccount},
+	"filtertransactions": {handler: filterTransactions},
+	"getbestblock":       {handler: getBestBlock},
+	"getsyncstatus":      {handler: getSyncStatus},
 	// This was an extension but the reference implementation added it as
 	// well, but with a different API (no account parameter).  It's listed
 	// here because it hasn't been update to use the reference
@@ -144,6 +153,10 @@ func unsupported(
 		Message: "Request unsupported by mod",
 	}
 }
+// rawRequester is a chain client that can pass JSON-RPC requests through to the chain server, as the RPC client and the in-process client can.
+type rawRequester interface {
+	RawRequest(method string, params []js.RawMessage) (js.RawMessage, error)
+}
 // lazyHandler is a closure over a requestHandler or passthrough request with
 // the RPC server's wallet and chain server variables as part of the closure
 // context.
@@ -155,7 +168,9 @@ type lazyHandler func() (interface{}, *json.RPCError)
 func lazyApplyHandler(
 	request *json.Request, w *wallet.Wallet, chainClient chain.Interface) lazyHandler {
 	handlerData, ok := rpcHandlers[request.Method]
-	if ok && handlerData.handlerWithChain != nil && w != nil && chainClient != nil {
+	// the handlers that need the chain use the RPC client, with other chain clients the wallet only handler or the passthrough runs instead
+	_, rpcChain := chainClient.(*chain.RPCClient)
+	if ok && handlerData.handlerWithChain != nil && w != nil && rpcChain {
 		return func() (interface{}, *json.RPCError) {
 			cmd, err := json.UnmarshalCmd(request)
 			if err != nil {
@@ -199,7 +214,7 @@ func lazyApplyHandler(
 			}
 		}
 		switch client := chainClient.(type) {
-		case *chain.RPCClient:
+		case rawRequester:
 			resp, err := client.RawRequest(request.Method,
 				request.Params)
 			if err != nil {
@@ -329,6 +344,50 @@ func addMultiSigAddress(
 	}
 	return p2shAddr.EncodeAddress(), nil
 }
+// bumpFee handles a bumpfee request by replacing an unconfirmed transaction
+// of the wallet with one paying a higher fee out of its change, which nodes
+// accepting BIP125 replacements relay and mine in its place.
+func bumpFee(
+	icmd interface{}, w *wallet.Wallet) (interface{}, error) {
+	cmd := icmd.(*json.BumpFeeCmd)
+	txHash, err := chainhash.NewHashFromStr(cmd.Txid)
+	if err != nil {
+		return nil, &json.RPCError{
+			Code:    json.ErrRPCDecodeHexString,
+			Message: "Transaction hash string decode failed: " + err.Error(),
+		}
+	}
+	var feeRate util.Amount
+	if cmd.Options != nil && cmd.Options.FeeRate != nil {
+		if feeRate, err = amountFromDUO(*cmd.Options.FeeRate); err != nil {
+			return nil, err
+		}
+		if feeRate <= 0 {
+			return nil, ErrNeedPositiveAmount
+		}
+	}
+	replacement, origFee, fee, err := w.BumpFee(txHash, feeRate)
+	if err != nil {
+		if waddrmgr.IsError(err, waddrmgr.ErrLocked) {
+			return nil, &ErrWalletUnlockNeeded
+		}
+		switch err {
+		case wallet.ErrTxNotFound:
+			return nil, &ErrNoTransactionInfo
+		case wallet.ErrTxMined, wallet.ErrTxNotReplaceable,
+			wallet.ErrTxNotOwned, wallet.ErrTxHasDescendants,
+			wallet.ErrNoChange, wallet.ErrFeeRateTooLow,
+			wallet.ErrChangeTooSmall:
+			return nil, InvalidParameterError{err}
+		}
+		return nil, err
+	}
+	return json.BumpFeeResult{
+		Txid:    replacement.String(),
+		OrigFee: origFee.ToDUO(),
+		Fee:     fee.ToDUO(),
+	}, nil
+}
 // createMultiSig handles an createmultisig request by returning a
 // multisig address for the given inputs.
 func createMultiSig(
@@ -439,6 +498,34 @@ func getBestBlock(
 	}
 	return result, nil
 }
+// getSyncStatus handles a getsyncstatus request by returning the chain backend
+// of the wallet and how far the backend and the wallet have synced.
+func getSyncStatus(
+	icmd interface{}, w *wallet.Wallet) (interface{}, error) {
+	result := &json.GetSyncStatusResult{
+		Backend: "none",
+		Synced:  w.ChainSynced(),
+		Height:  w.Manager.SyncedTo().Height,
+	}
+	switch c := w.ChainClient().(type) {
+	case nil:
+	case *chain.NeutrinoClient:
+		result.Backend = c.BackEnd()
+		if _, height, err := c.CS.BlockHeaders.ChainTip(); err == nil {
+			result.Headers = int32(height)
+		}
+		if _, height, err := c.CS.RegFilterHeaders.ChainTip(); err == nil {
+			result.FilterHeaders = int32(height)
+		}
+		result.Peers = c.CS.ConnectedCount()
+	default:
+		result.Backend = c.BackEnd()
+		if _, height, err := c.GetBestBlock(); err == nil {
+			result.Headers, result.FilterHeaders = height, height
+		}
+	}
+	return result, nil
+}
 // getBestBlockHash handles a getbestblockhash request by returning the hash
 // of the most recently processed block.
 func getBestBlockHash(
@@ -1201,6 +1288,40 @@ func listAllTransactions(
 	}
 	return w.ListAllTransactions()
 }
+// filterTransactions handles a filtertransactions request by returning the
+// wallet transactions in the same form as listtransactions that match all of
+// the filters given.  Sends have no account, so filtering by label only finds
+// received transactions.
+func filterTransactions(
+	icmd interface{}, w *wallet.Wallet) (interface{}, error) {
+	cmd := icmd.(*json.FilterTransactionsCmd)
+	txs, err := w.ListAllTransactions()
+	if err != nil {
+		return nil, err
+	}
+	return matchTransactions(txs, cmd), nil
+}
+// matchTransactions returns the transactions matching all of the filters of
+// cmd, zero values matching everything.
+func matchTransactions(
+	txs []json.ListTransactionsResult, cmd *json.FilterTransactionsCmd) []json.ListTransactionsResult {
+	label := strings.ToLower(*cmd.Label)
+	matched := []json.ListTransactionsResult{}
+	for _, tx := range txs {
+		amount := math.Abs(tx.Amount)
+		switch {
+		case *cmd.From != 0 && tx.Time < *cmd.From,
+			*cmd.To != 0 && tx.Time > *cmd.To,
+			label != "" && !strings.Contains(strings.ToLower(tx.Account), label),
+			*cmd.Address != "" && tx.Address != *cmd.Address,
+			amount < *cmd.MinAmount,
+			*cmd.MaxAmount != 0 && amount > *cmd.MaxAmount:
+			continue
+		}
+		matched = append(matched, tx)
+	}
+	return matched
+}
 // listUnspent handles the listunspent command.
 func listUnspent(
 	icmd interface{}, w *wallet.Wallet) (interface{}, error) {
@@ -1262,6 +1383,19 @@ func makeOutputs(
 	}
 	return outputs, nil
 }
+// amountFromDUO converts an amount of a send request as bitcoind does,
+// refusing amounts out of range or with more than 8 decimal places rather
+// than rounding them.
+func amountFromDUO(f float64) (util.Amount, error) {
+	amt, err := util.AmountFromDUO(f)
+	if err != nil {
+		return 0, &json.RPCError{
+			Code:    json.ErrRPCType,
+			Message: "Invalid amount: " + err.Error(),
+		}
+	}
+	return amt, nil
+}
 // sendPairs creates and sends payment transactions.
 // It returns the transaction hash in string format upon success
 // All errors are returned in json.RPCError format
@@ -1328,7 +1462,7 @@ func sendFrom(
 		return nil, ErrNeedPositiveMinconf
 	}
 	// Create map of address and amount pairs.
-	amt, err := util.NewAmount(cmd.Amount)
+	amt, err := amountFromDUO(cmd.Amount)
 	if err != nil {
 		return nil, err
 	}
@@ -1366,7 +1500,7 @@ func sendMany(
 	// Recreate address/amount pairs, using dcrutil.Amount.
 	pairs := make(map[string]util.Amount, len(cmd.Amounts))
 	for k, v := range cmd.Amounts {
-		amt, err := util.NewAmount(v)
+		amt, err := amountFromDUO(v)
 		if err != nil {
 			return nil, err
 		}
@@ -1390,7 +1524,7 @@ func sendToAddress(
 			Message: "Transaction comments are not yet supported",
 		}
 	}
-	amt, err := util.NewAmount(cmd.Amount)
+	amt, err := amountFromDUO(cmd.Amount)
 	if err != nil {
 		return nil, err
 	}
@@ -1441,6 +1575,15 @@ func signMessage(
 	}
 	return base64.StdEncoding.EncodeToString(sigbytes), nil
 }
+// sigHashTypes maps the names of sighash types in the RPC API to their types.
+var sigHashTypes = map[string]txscript.SigHashType{
+	"ALL":                 txscript.SigHashAll,
+	"NONE":                txscript.SigHashNone,
+	"SINGLE":              txscript.SigHashSingle,
+	"ALL|ANYONECANPAY":    txscript.SigHashAll | txscript.SigHashAnyOneCanPay,
+	"NONE|ANYONECANPAY":   txscript.SigHashNone | txscript.SigHashAnyOneCanPay,
+	"SINGLE|ANYONECANPAY": txscript.SigHashSingle | txscript.SigHashAnyOneCanPay,
+}
 // signRawTransaction handles the signrawtransaction command.
 func signRawTransaction(
 	icmd interface{}, w *wallet.Wallet, chainClient *chain.RPCClient) (interface{}, error) {
@@ -1455,21 +1598,8 @@ func signRawTransaction(
 		e := errors.New("TX decode failed")
 		return nil, DeserializationError{e}
 	}
-	var hashType txscript.SigHashType
-	switch *cmd.Flags {
-	case "ALL":
-		hashType = txscript.SigHashAll
-	case "NONE":
-		hashType = txscript.SigHashNone
-	case "SINGLE":
-		hashType = txscript.SigHashSingle
-	case "ALL|ANYONECANPAY":
-		hashType = txscript.SigHashAll | txscript.SigHashAnyOneCanPay
-	case "NONE|ANYONECANPAY":
-		hashType = txscript.SigHashNone | txscript.SigHashAnyOneCanPay
-	case "SINGLE|ANYONECANPAY":
-		hashType = txscript.SigHashSingle | txscript.SigHashAnyOneCanPay
-	default:
+	hashType, ok := sigHashTypes[*cmd.Flags]
+	if !ok {
 		e := errors.New("Invalid sighash parameter")
 		return nil, InvalidParameterError{e}
 	}
@@ -1613,6 +1743,9 @@ func validateAddress(
 	// "ismine", and we follow that behaviour.
 	result.Address = addr.EncodeAddress()
 	result.IsValid = true
+	if info, err := util.InspectAddress(cmd.Address, w.ChainParams()); err == nil {
+		result.AddressDetails = addressDetails(info)
+	}
 	ainfo, err := w.AddressInfo(addr)
 	if err != nil {
 		if waddrmgr.IsError(err, waddrmgr.ErrAddressNotFound) {
@@ -1666,6 +1799,26 @@ func validateAddress(
 	}
 	return result, nil
 }
+// addressDetails describes the address as the node's validateaddress does, so
+// the wallet's result is a superset of the chain server's.
+func addressDetails(
+	info *util.AddressInfo) json.AddressDetails {
+	d := json.AddressDetails{
+		Type:        info.Type,
+		Network:     info.Network,
+		ScriptClass: info.ScriptClass,
+		IsWitness:   info.IsWitness,
+	}
+	if script, err := txscript.PayToAddrScript(info.Address); err == nil {
+		d.ScriptPubKey = hex.EncodeToString(script)
+	}
+	if info.IsWitness {
+		version := int32(info.WitnessVersion)
+		d.WitnessVersion = &version
+		d.WitnessProgram = hex.EncodeToString(info.WitnessProgram)
+	}
+	return d
+}
 // verifyMessage handles the verifymessage command by verifying the provided
 // compact signature for the given address and message.
 func verifyMessage(
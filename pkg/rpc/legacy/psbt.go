@@ -0,0 +1,359 @@
+package legacyrpc
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	chaincfg "git.parallelcoin.io/dev/9/pkg/chain/config"
+	chainhash "git.parallelcoin.io/dev/9/pkg/chain/hash"
+	txrules "git.parallelcoin.io/dev/9/pkg/chain/tx/rules"
+	txscript "git.parallelcoin.io/dev/9/pkg/chain/tx/script"
+	"git.parallelcoin.io/dev/9/pkg/chain/wire"
+	"git.parallelcoin.io/dev/9/pkg/rpc/json"
+	"git.parallelcoin.io/dev/9/pkg/util"
+	"git.parallelcoin.io/dev/9/pkg/util/hdkeychain"
+	"git.parallelcoin.io/dev/9/pkg/util/psbt"
+	"git.parallelcoin.io/dev/9/pkg/wallet"
+	waddrmgr "git.parallelcoin.io/dev/9/pkg/wallet/addrmgr"
+)
+// walletCreateFundedPsbt handles the walletcreatefundedpsbt command by
+// creating a partially signed transaction paying to the amounts from the
+// inputs given and more of the account's outputs when they are needed.  The
+// wallet does not need to be unlocked, so watching-only wallets can create
+// transactions to pass to an offline signer.
+func walletCreateFundedPsbt(
+	icmd interface{}, w *wallet.Wallet) (interface{}, error) {
+	cmd := icmd.(*json.WalletCreateFundedPsbtCmd)
+	opts := cmd.Options
+	if opts == nil {
+		opts = &json.WalletCreateFundedPsbtOpts{}
+	}
+	accountName := "default"
+	if opts.Account != nil {
+		accountName = *opts.Account
+	}
+	account, err := w.AccountNumber(waddrmgr.KeyScopeBIP0044, accountName)
+	if err != nil {
+		return nil, err
+	}
+	minConf := int32(1)
+	if opts.MinConf != nil {
+		if *opts.MinConf < 0 {
+			return nil, ErrNeedPositiveMinconf
+		}
+		minConf = int32(*opts.MinConf)
+	}
+	feeRate := txrules.DefaultRelayFeePerKb
+	if opts.FeeRate != nil {
+		if feeRate, err = amountFromDUO(*opts.FeeRate); err != nil {
+			return nil, err
+		}
+	}
+	if *cmd.LockTime < 0 || *cmd.LockTime > math.MaxUint32 {
+		return nil, InvalidParameterError{errors.New("locktime out of range")}
+	}
+	inputs := make([]wire.OutPoint, 0, len(cmd.Inputs))
+	for _, input := range cmd.Inputs {
+		txHash, err := chainhash.NewHashFromStr(input.Txid)
+		if err != nil {
+			return nil, ParseError{err}
+		}
+		inputs = append(inputs, wire.OutPoint{Hash: *txHash, Index: input.Vout})
+	}
+	pairs := make(map[string]util.Amount, len(cmd.Amounts))
+	for k, v := range cmd.Amounts {
+		amt, err := amountFromDUO(v)
+		if err != nil {
+			return nil, err
+		}
+		if amt <= 0 {
+			return nil, ErrNeedPositiveAmount
+		}
+		pairs[k] = amt
+	}
+	outputs, err := makeOutputs(pairs, w.ChainParams())
+	if err != nil {
+		return nil, err
+	}
+	lockUnspents := opts.LockUnspents != nil && *opts.LockUnspents
+	packet, fee, changePos, err := w.FundPsbt(inputs, outputs,
+		uint32(*cmd.LockTime), account, minConf, feeRate, lockUnspents)
+	if err != nil {
+		return nil, err
+	}
+	b64, err := packet.B64Encode()
+	if err != nil {
+		return nil, err
+	}
+	return json.WalletCreateFundedPsbtResult{
+		Psbt:      b64,
+		Fee:       fee.ToDUO(),
+		ChangePos: changePos,
+	}, nil
+}
+// walletProcessPsbt handles the walletprocesspsbt command by adding what the
+// wallet knows of the inputs of a partially signed transaction and, unless
+// told not to, the signatures of its keys.
+func walletProcessPsbt(
+	icmd interface{}, w *wallet.Wallet) (interface{}, error) {
+	cmd := icmd.(*json.WalletProcessPsbtCmd)
+	packet, err := parsePsbt(cmd.Psbt)
+	if err != nil {
+		return nil, err
+	}
+	hashType, ok := sigHashTypes[*cmd.SighashType]
+	if !ok {
+		return nil, InvalidParameterError{errors.New("Invalid sighash parameter")}
+	}
+	complete, err := w.ProcessPsbt(packet, *cmd.Sign, hashType)
+	if err != nil {
+		if waddrmgr.IsError(err, waddrmgr.ErrLocked) {
+			return nil, &ErrWalletUnlockNeeded
+		}
+		return nil, err
+	}
+	b64, err := packet.B64Encode()
+	if err != nil {
+		return nil, err
+	}
+	return json.WalletProcessPsbtResult{
+		Psbt:     b64,
+		Complete: complete,
+	}, nil
+}
+// finalizePsbt handles the finalizepsbt command by finalizing the inputs of a
+// partially signed transaction that have all of their signatures, and
+// returning the transaction, if every input is final and it is to be
+// extracted, or else the packet.
+func finalizePsbt(
+	icmd interface{}, w *wallet.Wallet) (interface{}, error) {
+	cmd := icmd.(*json.FinalizePsbtCmd)
+	packet, err := parsePsbt(cmd.Psbt)
+	if err != nil {
+		return nil, err
+	}
+	if err = packet.MaybeFinalizeAll(); err != nil {
+		return nil, InvalidParameterError{err}
+	}
+	result := json.FinalizePsbtResult{Complete: packet.IsComplete()}
+	if result.Complete && *cmd.Extract {
+		tx, err := packet.Extract()
+		if err != nil {
+			return nil, err
+		}
+		var buf bytes.Buffer
+		if err = tx.Serialize(&buf); err != nil {
+			return nil, err
+		}
+		result.Hex = hex.EncodeToString(buf.Bytes())
+		return result, nil
+	}
+	if result.Psbt, err = packet.B64Encode(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+// decodePsbt handles the decodepsbt command by returning the transaction of a
+// partially signed transaction and everything the packet says about its
+// inputs and outputs.
+func decodePsbt(
+	icmd interface{}, w *wallet.Wallet) (interface{}, error) {
+	cmd := icmd.(*json.DecodePsbtCmd)
+	packet, err := parsePsbt(cmd.Psbt)
+	if err != nil {
+		return nil, err
+	}
+	params := w.ChainParams()
+	result := json.DecodePsbtResult{
+		Tx:      decodeTx(packet.UnsignedTx, params),
+		Unknown: unknownsResult(packet.Unknowns),
+		Inputs:  make([]json.PsbtInputResult, len(packet.Inputs)),
+		Outputs: make([]json.PsbtOutputResult, len(packet.Outputs)),
+	}
+	for i := range packet.Inputs {
+		in, r := &packet.Inputs[i], &result.Inputs[i]
+		if in.NonWitnessUtxo != nil {
+			tx := decodeTx(in.NonWitnessUtxo, params)
+			r.NonWitnessUtxo = &tx
+		}
+		if in.WitnessUtxo != nil {
+			r.WitnessUtxo = &json.PsbtWitnessUtxoResult{
+				Amount:       util.Amount(in.WitnessUtxo.Value).ToDUO(),
+				ScriptPubKey: scriptResult(in.WitnessUtxo.PkScript, params),
+			}
+		}
+		if len(in.PartialSigs) != 0 {
+			r.PartialSignatures = make(map[string]string, len(in.PartialSigs))
+			for _, sig := range in.PartialSigs {
+				r.PartialSignatures[hex.EncodeToString(sig.PubKey)] = hex.EncodeToString(sig.Signature)
+			}
+		}
+		if in.SighashType != 0 {
+			r.Sighash = sigHashName(in.SighashType)
+		}
+		r.RedeemScript = optionalScriptResult(in.RedeemScript, params)
+		r.WitnessScript = optionalScriptResult(in.WitnessScript, params)
+		r.Bip32Derivs = bip32DerivsResult(in.Bip32Derivation)
+		if in.FinalScriptSig != nil {
+			// The disassembled string will contain [error] inline if
+			// the script doesn't fully parse, so ignore the error here.
+			disbuf, _ := txscript.DisasmString(in.FinalScriptSig)
+			r.FinalScriptSig = &json.ScriptSig{
+				Asm: disbuf,
+				Hex: hex.EncodeToString(in.FinalScriptSig),
+			}
+		}
+		r.FinalScriptWitness = witnessToHex(in.FinalScriptWitness)
+		r.Unknown = unknownsResult(in.Unknowns)
+	}
+	for i := range packet.Outputs {
+		out, r := &packet.Outputs[i], &result.Outputs[i]
+		r.RedeemScript = optionalScriptResult(out.RedeemScript, params)
+		r.WitnessScript = optionalScriptResult(out.WitnessScript, params)
+		r.Bip32Derivs = bip32DerivsResult(out.Bip32Derivation)
+		r.Unknown = unknownsResult(out.Unknowns)
+	}
+	if fee, err := packet.Fee(); err == nil {
+		duo := fee.ToDUO()
+		result.Fee = &duo
+	}
+	return result, nil
+}
+// parsePsbt decodes a base64 encoded partially signed transaction.
+func parsePsbt(
+	b64 string) (*psbt.Packet, error) {
+	packet, err := psbt.NewFromRawBytes(strings.NewReader(b64), true)
+	if err != nil {
+		return nil, DeserializationError{fmt.Errorf("PSBT decode failed: %v", err)}
+	}
+	return packet, nil
+}
+// sigHashName returns the RPC name of a sighash type.
+func sigHashName(
+	hashType txscript.SigHashType) string {
+	for name, t := range sigHashTypes {
+		if t == hashType {
+			return name
+		}
+	}
+	return fmt.Sprintf("%#x", uint32(hashType))
+}
+// decodeTx returns the JSON object of a transaction in the format of the
+// decoderawtransaction command.
+func decodeTx(
+	tx *wire.MsgTx, params *chaincfg.Params) json.TxRawDecodeResult {
+	result := json.TxRawDecodeResult{
+		Txid:     tx.TxHash().String(),
+		Version:  tx.Version,
+		Locktime: tx.LockTime,
+		Vin:      make([]json.Vin, len(tx.TxIn)),
+		Vout:     make([]json.Vout, len(tx.TxOut)),
+	}
+	for i, txIn := range tx.TxIn {
+		// The disassembled string will contain [error] inline if the
+		// script doesn't fully parse, so ignore the error here.
+		disbuf, _ := txscript.DisasmString(txIn.SignatureScript)
+		result.Vin[i] = json.Vin{
+			Txid:     txIn.PreviousOutPoint.Hash.String(),
+			Vout:     txIn.PreviousOutPoint.Index,
+			Sequence: txIn.Sequence,
+			ScriptSig: &json.ScriptSig{
+				Asm: disbuf,
+				Hex: hex.EncodeToString(txIn.SignatureScript),
+			},
+			Witness: witnessToHex(txIn.Witness),
+		}
+	}
+	for i, txOut := range tx.TxOut {
+		result.Vout[i] = json.Vout{
+			Value:        util.Amount(txOut.Value).ToDUO(),
+			N:            uint32(i),
+			ScriptPubKey: scriptResult(txOut.PkScript, params),
+		}
+	}
+	return result
+}
+// scriptResult returns the JSON object describing a script.
+func scriptResult(
+	script []byte, params *chaincfg.Params) json.ScriptPubKeyResult {
+	// The disassembled string will contain [error] inline if the script
+	// doesn't fully parse, so ignore the error here.
+	disbuf, _ := txscript.DisasmString(script)
+	// Ignore the error here since an error means the script couldn't
+	// parse and there is no additional information about it anyways.
+	class, addrs, reqSigs, _ := txscript.ExtractPkScriptAddrs(script, params)
+	encodedAddrs := make([]string, len(addrs))
+	for i, addr := range addrs {
+		encodedAddrs[i] = addr.EncodeAddress()
+	}
+	return json.ScriptPubKeyResult{
+		Asm:       disbuf,
+		Hex:       hex.EncodeToString(script),
+		ReqSigs:   int32(reqSigs),
+		Type:      class.String(),
+		Addresses: encodedAddrs,
+	}
+}
+// optionalScriptResult returns the JSON object describing a script, or nil if
+// there is no script.
+func optionalScriptResult(
+	script []byte, params *chaincfg.Params) *json.ScriptPubKeyResult {
+	if script == nil {
+		return nil
+	}
+	result := scriptResult(script, params)
+	return &result
+}
+// bip32DerivsResult returns the JSON objects of key derivations, with their
+// paths written as in BIP32.
+func bip32DerivsResult(
+	derivations []*psbt.Bip32Derivation) []json.PsbtBip32DerivResult {
+	if len(derivations) == 0 {
+		return nil
+	}
+	result := make([]json.PsbtBip32DerivResult, len(derivations))
+	for i, d := range derivations {
+		var fingerprint [4]byte
+		binary.LittleEndian.PutUint32(fingerprint[:], d.MasterKeyFingerprint)
+		path := "m"
+		for _, index := range d.Bip32Path {
+			if index >= hdkeychain.HardenedKeyStart {
+				path += fmt.Sprintf("/%d'", index-hdkeychain.HardenedKeyStart)
+			} else {
+				path += fmt.Sprintf("/%d", index)
+			}
+		}
+		result[i] = json.PsbtBip32DerivResult{
+			PubKey:            hex.EncodeToString(d.PubKey),
+			MasterFingerprint: hex.EncodeToString(fingerprint[:]),
+			Path:              path,
+		}
+	}
+	return result
+}
+// unknownsResult returns the unknown keys of a map of a packet and their
+// values, hex encoded.
+func unknownsResult(
+	unknowns []psbt.Unknown) map[string]string {
+	result := make(map[string]string, len(unknowns))
+	for _, u := range unknowns {
+		result[hex.EncodeToString(u.Key)] = hex.EncodeToString(u.Value)
+	}
+	return result
+}
+// witnessToHex returns the items of a witness, hex encoded, or nil if there
+// are none so they can be omitted.
+func witnessToHex(
+	witness wire.TxWitness) []string {
+	if len(witness) == 0 {
+		return nil
+	}
+	result := make([]string, len(witness))
+	for i, item := range witness {
+		result[i] = hex.EncodeToString(item)
+	}
+	return result
+}
@@ -3,8 +3,11 @@ package legacyrpc
 func helpDescsEnUS() map[string]string {
 	return map[string]string{
 		"addmultisigaddress":      "addmultisigaddress nrequired [\"key\",...] (\"account\")\n\nGenerates and imports a multisig address and redeeming script to the 'imported' account.\n\nArguments:\n1. nrequired (numeric, required)         The number of signatures required to redeem outputs paid to this address\n2. keys      (array of string, required) Pubkeys and/or pay-to-pubkey-hash addresses to partially control the multisig address\n3. account   (string, optional)          DEPRECATED -- Unused (all imported addresses belong to the imported account)\n\nResult:\n\"value\" (string) The imported pay-to-script-hash address\n",
+		"bumpfee":                "bumpfee \"txid\" ({\"feerate\":feerate})\n\nReplaces an unconfirmed transaction of the wallet that signals BIP125 replace-by-fee with one paying a higher fee rate out of its change output.\nEvery input of the transaction must be the wallet's and no unconfirmed transaction of the wallet may spend its outputs.\n\nArguments:\n1. txid    (string, required) The hash of the transaction to replace\n2. options (object, optional) Options for the replacement\n{\n \"feeRate\": n.nnn, (numeric) The fee rate of the replacement valued in bitcoin per kilobyte (default=the fee rate of the transaction plus the minimum relay fee)\n}                  \n\nResult:\n{\n \"txid\": \"value\",  (string)  The hash of the replacement\n \"origfee\": n.nnn, (numeric) The fee of the replaced transaction valued in bitcoin\n \"fee\": n.nnn,     (numeric) The fee of the replacement valued in bitcoin\n}                  \n",
 		"createmultisig":          "createmultisig nrequired [\"key\",...]\n\nGenerate a multisig address and redeem script.\n\nArguments:\n1. nrequired (numeric, required)         The number of signatures required to redeem outputs paid to this address\n2. keys      (array of string, required) Pubkeys and/or pay-to-pubkey-hash addresses to partially control the multisig address\n\nResult:\n{\n \"address\": \"value\",      (string) The generated pay-to-script-hash address\n \"redeemScript\": \"value\", (string) The script required to redeem outputs paid to the multisig address\n}                         \n",
+		"decodepsbt":             "decodepsbt \"psbt\"\n\nReturns a JSON object describing a partially signed transaction (BIP174), its inputs and its outputs.\n\nArguments:\n1. psbt (string, required) The partially signed transaction encoded as a base64 string\n\nResult:\n{\n \"tx\": {                         (object)          The unsigned transaction as a JSON object\n  \"txid\": \"value\",               (string)          The hash of the transaction\n  \"version\": n,                  (numeric)         The transaction version\n  \"locktime\": n,                 (numeric)         The transaction lock time\n  \"vin\": [{                      (array of object) The transaction inputs as JSON objects\n   \"coinbase\": \"value\",          (string)          The hex-encoded bytes of the signature script (coinbase txns only)\n   \"txid\": \"value\",              (string)          The hash of the origin transaction (non-coinbase txns only)\n   \"vout\": n,                    (numeric)         The index of the output being redeemed from the origin transaction (non-coinbase txns only)\n   \"scriptSig\": {                (object)          The signature script used to redeem the origin transaction as a JSON object (non-coinbase txns only)\n    \"asm\": \"value\",              (string)          Disassembly of the script\n    \"hex\": \"value\",              (string)          Hex-encoded bytes of the script\n   },                                              \n   \"sequence\": n,                (numeric)         The script sequence number\n   \"txinwitness\": [\"value\",...], (array of string) The witness used to redeem the input encoded as a string array of its items\n  },...],                                          \n  \"vout\": [{                     (array of object) The transaction outputs as JSON objects\n   \"value\": n.nnn,               (numeric)         The amount valued in bitcoin\n   \"n\": n,                       (numeric)         The index of this transaction output\n   \"scriptPubKey\": {             (object)          The public key script used to pay coins as a JSON object\n    \"asm\": \"value\",              (string)          Disassembly of the script\n    \"hex\": \"value\",              (string)          Hex-encoded bytes of the script\n    \"reqSigs\": n,                (numeric)         The number of required signatures\n    \"type\": \"value\",             (string)          The type of the script (e.g. 'pubkeyhash')\n    \"addresses\": [\"value\",...],  (array of string) The bitcoin addresses associated with this script\n   },                                              \n  },...],                                          \n },                                                \n \"unknown\": {                    (object)          The global keys of types that are not known\n  \"key\": value, (object) The hex-encoded key as the key and the hex-encoded value as the value\n  ...\n }\n \"inputs\": [{                     (array of object) The inputs of the transaction as JSON objects\n  \"non_witness_utxo\": {           (object)          The transaction an input that is not segwit spends an output of, as a JSON object\n   \"txid\": \"value\",               (string)          The hash of the transaction\n   \"version\": n,                  (numeric)         The transaction version\n   \"locktime\": n,                 (numeric)         The transaction lock time\n   \"vin\": [{                      (array of object) The transaction inputs as JSON objects\n    \"coinbase\": \"value\",          (string)          The hex-encoded bytes of the signature script (coinbase txns only)\n    \"txid\": \"value\",              (string)          The hash of the origin transaction (non-coinbase txns only)\n    \"vout\": n,                    (numeric)         The index of the output being redeemed from the origin transaction (non-coinbase txns only)\n    \"scriptSig\": {                (object)          The signature script used to redeem the origin transaction as a JSON object (non-coinbase txns only)\n     \"asm\": \"value\",              (string)          Disassembly of the script\n     \"hex\": \"value\",              (string)          Hex-encoded bytes of the script\n    },                                              \n    \"sequence\": n,                (numeric)         The script sequence number\n    \"txinwitness\": [\"value\",...], (array of string) The witness used to redeem the input encoded as a string array of its items\n   },...],                                          \n   \"vout\": [{                     (array of object) The transaction outputs as JSON objects\n    \"value\": n.nnn,               (numeric)         The amount valued in bitcoin\n    \"n\": n,                       (numeric)         The index of this transaction output\n    \"scriptPubKey\": {             (object)          The public key script used to pay coins as a JSON object\n     \"asm\": \"value\",              (string)          Disassembly of the script\n     \"hex\": \"value\",              (string)          Hex-encoded bytes of the script\n     \"reqSigs\": n,                (numeric)         The number of required signatures\n     \"type\": \"value\",             (string)          The type of the script (e.g. 'pubkeyhash')\n     \"addresses\": [\"value\",...],  (array of string) The bitcoin addresses associated with this script\n    },                                              \n   },...],                                          \n  },                                                \n  \"witness_utxo\": {               (object)          The output a segwit input spends\n   \"amount\": n.nnn,               (numeric)         The value of the output valued in bitcoin\n   \"scriptPubKey\": {              (object)          The public key script of the output\n    \"asm\": \"value\",               (string)          Disassembly of the script\n    \"hex\": \"value\",               (string)          Hex-encoded bytes of the script\n    \"reqSigs\": n,                 (numeric)         The number of required signatures\n    \"type\": \"value\",              (string)          The type of the script (e.g. 'pubkeyhash')\n    \"addresses\": [\"value\",...],   (array of string) The bitcoin addresses associated with this script\n   },                                               \n  },                                                \n  \"partial_signatures\": {         (object)          The signatures of the input\n   \"pubkey\": signature, (object) The hex-encoded public key as the key and the hex-encoded signature as the value\n   ...\n  }\n  \"sighash\": \"value\",                   (string)          The sighash type the input must be signed with\n  \"redeem_script\": {                    (object)          The redeem script of a pay-to-script-hash output\n   \"asm\": \"value\",                      (string)          Disassembly of the script\n   \"hex\": \"value\",                      (string)          Hex-encoded bytes of the script\n   \"reqSigs\": n,                        (numeric)         The number of required signatures\n   \"type\": \"value\",                     (string)          The type of the script (e.g. 'pubkeyhash')\n   \"addresses\": [\"value\",...],          (array of string) The bitcoin addresses associated with this script\n  },                                                      \n  \"witness_script\": {                   (object)          The witness script of a pay-to-witness-script-hash output\n   \"asm\": \"value\",                      (string)          Disassembly of the script\n   \"hex\": \"value\",                      (string)          Hex-encoded bytes of the script\n   \"reqSigs\": n,                        (numeric)         The number of required signatures\n   \"type\": \"value\",                     (string)          The type of the script (e.g. 'pubkeyhash')\n   \"addresses\": [\"value\",...],          (array of string) The bitcoin addresses associated with this script\n  },                                                      \n  \"bip32_derivs\": [{                    (array of object) The derivations of the keys of the input\n   \"pubkey\": \"value\",                   (string)          The hex-encoded public key\n   \"master_fingerprint\": \"value\",       (string)          The hex-encoded fingerprint of the master key the key is derived from\n   \"path\": \"value\",                     (string)          The path the key is derived along\n  },...],                                                 \n  \"final_scriptSig\": {                  (object)          The final signature script of the input\n   \"asm\": \"value\",                      (string)          Disassembly of the script\n   \"hex\": \"value\",                      (string)          Hex-encoded bytes of the script\n  },                                                      \n  \"final_scriptwitness\": [\"value\",...], (array of string) The hex-encoded items of the final witness of the input\n  \"unknown\": {                          (object)          The keys of the input of types that are not known\n   \"key\": value, (object) The hex-encoded key as the key and the hex-encoded value as the value\n   ...\n  }\n },...],                                            \n \"outputs\": [{                    (array of object) The outputs of the transaction as JSON objects\n  \"redeem_script\": {              (object)          The redeem script of a pay-to-script-hash output\n   \"asm\": \"value\",                (string)          Disassembly of the script\n   \"hex\": \"value\",                (string)          Hex-encoded bytes of the script\n   \"reqSigs\": n,                  (numeric)         The number of required signatures\n   \"type\": \"value\",               (string)          The type of the script (e.g. 'pubkeyhash')\n   \"addresses\": [\"value\",...],    (array of string) The bitcoin addresses associated with this script\n  },                                                \n  \"witness_script\": {             (object)          The witness script of a pay-to-witness-script-hash output\n   \"asm\": \"value\",                (string)          Disassembly of the script\n   \"hex\": \"value\",                (string)          Hex-encoded bytes of the script\n   \"reqSigs\": n,                  (numeric)         The number of required signatures\n   \"type\": \"value\",               (string)          The type of the script (e.g. 'pubkeyhash')\n   \"addresses\": [\"value\",...],    (array of string) The bitcoin addresses associated with this script\n  },                                                \n  \"bip32_derivs\": [{              (array of object) The derivations of the keys of the output\n   \"pubkey\": \"value\",             (string)          The hex-encoded public key\n   \"master_fingerprint\": \"value\", (string)          The hex-encoded fingerprint of the master key the key is derived from\n   \"path\": \"value\",               (string)          The path the key is derived along\n  },...],                                           \n  \"unknown\": {                    (object)          The keys of the output of types that are not known\n   \"key\": value, (object) The hex-encoded key as the key and the hex-encoded value as the value\n   ...\n  }\n },...],                 \n \"fee\": n.nnn, (numeric) The fee of the transaction valued in bitcoin, if the outputs spent by every input are known\n}              \n",
 		"dumpprivkey":             "dumpprivkey \"address\"\n\nReturns the private key in WIF encoding that controls some wallet address.\n\nArguments:\n1. address (string, required) The address to return a private key for\n\nResult:\n\"value\" (string) The WIF-encoded private key\n",
+		"finalizepsbt":           "finalizepsbt \"psbt\" (extract=true)\n\nFinalizes the inputs of a partially signed transaction (BIP174) that have all of their signatures, and returns the transaction if every input is finalized, or else the partially signed transaction.\n\nArguments:\n1. psbt    (string, required)                The partially signed transaction encoded as a base64 string\n2. extract (boolean, optional, default=true) Return the transaction rather than the partially signed transaction when every input is finalized\n\nResult:\n{\n \"psbt\": \"value\",        (string)  The partially signed transaction encoded as a base64 string, unless the transaction was extracted\n \"hex\": \"value\",         (string)  The transaction encoded as a hexadecimal string, if it was extracted\n \"complete\": true|false, (boolean) Whether every input of the transaction is finalized\n}                        \n",
 		"getaccount":              "getaccount \"address\"\n\nDEPRECATED -- Lookup the account name that some wallet address belongs to.\n\nArguments:\n1. address (string, required) The address to query the account for\n\nResult:\n\"value\" (string) The name of the account that 'address' belongs to\n",
 		"getaccountaddress":       "getaccountaddress \"account\"\n\nDEPRECATED -- Returns the most recent external payment address for an account that has not been seen publicly.\nA new address is generated for the account if the most recently generated address has been seen on the blockchain or in mempool.\n\nArguments:\n1. account (string, required) The account of the returned address\n\nResult:\n\"value\" (string) The unused address for 'account'\n",
 		"getaddressesbyaccount":   "getaddressesbyaccount \"account\"\n\nDEPRECATED -- Returns all addresses strings controlled by a single account.\n\nArguments:\n1. account (string, required) Account name to fetch addresses for\n\nResult:\n[\"value\",...] (array of string) All addresses controlled by 'account'\n",
@@ -34,14 +37,18 @@ func helpDescsEnUS() map[string]string {
 		"settxfee":                "settxfee amount\n\nModify the increment used each time more fee is required for an authored transaction.\n\nArguments:\n1. amount (numeric, required) The new fee increment valued in bitcoin\n\nResult:\ntrue|false (boolean) The boolean 'true'\n",
 		"signmessage":             "signmessage \"address\" \"message\"\n\nSigns a message using the private key of a payment address.\n\nArguments:\n1. address (string, required) Payment address of private key used to sign the message with\n2. message (string, required) Message to sign\n\nResult:\n\"value\" (string) The signed message encoded as a base64 string\n",
 		"signrawtransaction":      "signrawtransaction \"rawtx\" ([{\"txid\":\"value\",\"vout\":n,\"scriptpubkey\":\"value\",\"redeemscript\":\"value\"},...] [\"privkey\",...] flags=\"ALL\")\n\nSigns transaction inputs using private keys from this wallet and request.\nThe valid flags options are ALL, NONE, SINGLE, ALL|ANYONECANPAY, NONE|ANYONECANPAY, and SINGLE|ANYONECANPAY.\n\nArguments:\n1. rawtx    (string, required)                Unsigned or partially unsigned transaction to sign encoded as a hexadecimal string\n2. inputs   (array of object, optional)       Additional data regarding inputs that this wallet may not be tracking\n3. privkeys (array of string, optional)       Additional WIF-encoded private keys to use when creating signatures\n4. flags    (string, optional, default=\"ALL\") Sighash flags\n\nResult:\n{\n \"hex\": \"value\",         (string)          The resulting transaction encoded as a hexadecimal string\n \"complete\": true|false, (boolean)         Whether all input signatures have been created\n \"errors\": [{            (array of object) Script verification errors (if exists)\n  \"txid\": \"value\",       (string)          The transaction hash of the referenced previous output\n  \"vout\": n,             (numeric)         The output index of the referenced previous output\n  \"scriptSig\": \"value\",  (string)          The hex-encoded signature script\n  \"sequence\": n,         (numeric)         Script sequence number\n  \"error\": \"value\",      (string)          Verification or signing error related to the input\n },...],                                   \n}                        \n",
-		"validateaddress":         "validateaddress \"address\"\n\nVerify that an address is valid.\nExtra details are returned if the address is controlled by this wallet.\nThe following fields are valid only when the address is controlled by this wallet (ismine=true): isscript, pubkey, iscompressed, account, addresses, hex, script, and sigsrequired.\nThe following fields are only valid when address has an associated public key: pubkey, iscompressed.\nThe following fields are only valid when address is a pay-to-script-hash address: addresses, hex, and script.\nIf the address is a multisig address controlled by this wallet, the multisig fields will be left unset if the wallet is locked since the redeem script cannot be decrypted.\n\nArguments:\n1. address (string, required) Address to validate\n\nResult:\n{\n \"isvalid\": true|false,      (boolean)         Whether or not the address is valid\n \"address\": \"value\",         (string)          The payment address (only when isvalid is true)\n \"ismine\": true|false,       (boolean)         Whether this address is controlled by the wallet (only when isvalid is true)\n \"iswatchonly\": true|false,  (boolean)         Unset\n \"isscript\": true|false,     (boolean)         Whether the payment address is a pay-to-script-hash address (only when isvalid is true)\n \"pubkey\": \"value\",          (string)          The associated public key of the payment address, if any (only when isvalid is true)\n \"iscompressed\": true|false, (boolean)         Whether the address was created by hashing a compressed public key, if any (only when isvalid is true)\n \"account\": \"value\",         (string)          The account this payment address belongs to (only when isvalid is true)\n \"addresses\": [\"value\",...], (array of string) All associated payment addresses of the script if address is a multisig address (only when isvalid is true)\n \"hex\": \"value\",             (string)          The redeem script \n \"script\": \"value\",          (string)          The class of redeem script for a multisig address\n \"sigsrequired\": n,          (numeric)         The number of required signatures to redeem outputs to the multisig address\n}                            \n",
+		"validateaddress":         "validateaddress \"address\"\n\nVerify that an address is valid.\nExtra details are returned if the address is controlled by this wallet.\nThe following fields are valid only when the address is controlled by this wallet (ismine=true): isscript, pubkey, iscompressed, account, addresses, hex, script, and sigsrequired.\nThe following fields are only valid when address has an associated public key: pubkey, iscompressed.\nThe following fields are only valid when address is a pay-to-script-hash address: addresses, hex, and script.\nIf the address is a multisig address controlled by this wallet, the multisig fields will be left unset if the wallet is locked since the redeem script cannot be decrypted.\n\nArguments:\n1. address (string, required) Address to validate\n\nResult:\n{\n \"isvalid\": true|false,      (boolean)         Whether or not the address is valid\n \"address\": \"value\",         (string)          The payment address (only when isvalid is true)\n \"ismine\": true|false,       (boolean)         Whether this address is controlled by the wallet (only when isvalid is true)\n \"iswatchonly\": true|false,  (boolean)         Unset\n \"isscript\": true|false,     (boolean)         Whether the payment address is a pay-to-script-hash address (only when isvalid is true)\n \"pubkey\": \"value\",          (string)          The associated public key of the payment address, if any (only when isvalid is true)\n \"iscompressed\": true|false, (boolean)         Whether the address was created by hashing a compressed public key, if any (only when isvalid is true)\n \"account\": \"value\",         (string)          The account this payment address belongs to (only when isvalid is true)\n \"addresses\": [\"value\",...], (array of string) All associated payment addresses of the script if address is a multisig address (only when isvalid is true)\n \"hex\": \"value\",             (string)          The redeem script \n \"script\": \"value\",          (string)          The class of redeem script for a multisig address\n \"sigsrequired\": n,          (numeric)         The number of required signatures to redeem outputs to the multisig address\n \"type\": \"value\",            (string)          The kind of address: p2pkh, p2sh, p2wpkh, p2wsh, p2tr or p2pk (only when isvalid is true)\n \"network\": \"value\",         (string)          The network the address is for, if it is for a known network (only when isvalid is true)\n \"scriptclass\": \"value\",     (string)          The class of the output script paying to the address (only when isvalid is true)\n \"scriptPubKey\": \"value\",    (string)          The hex-encoded output script paying to the address (only when isvalid is true)\n \"iswitness\": true|false,    (boolean)         Whether the address is a segwit address\n \"witness_version\": n,       (numeric)         The witness version of a segwit address\n \"witness_program\": \"value\", (string)          The hex-encoded witness program of a segwit address\n}                            \n",
 		"verifymessage":           "verifymessage \"address\" \"signature\" \"message\"\n\nVerify a message was signed with the associated private key of some address.\n\nArguments:\n1. address   (string, required) Address used to sign message\n2. signature (string, required) The signature to verify\n3. message   (string, required) The message to verify\n\nResult:\ntrue|false (boolean) Whether the message was signed with the private key of 'address'\n",
+		"walletcreatefundedpsbt": "walletcreatefundedpsbt [{\"txid\":\"value\",\"vout\":n},...] {\"address\":amount,...} (locktime=0 {\"account\":account,\"minconf\":minconf,\"lockunspents\":lockunspents,\"feerate\":feerate})\n\nCreates a partially signed transaction (BIP174) paying to the amounts, spending the inputs given and more unspent outputs of the account when they are not enough, with change to a new change address of the account.\nThe wallet does not need to be unlocked, so a watching-only wallet can create transactions for an offline signer.\n\nArguments:\n1. inputs (array of object, required) Unspent outputs of the wallet the transaction must spend, which may be empty\n[{\n \"txid\": \"value\", (string)  The transaction hash of the referenced output\n \"vout\": n,       (numeric) The output index of the referenced output\n},...]\n2. amounts (object, required) JSON object with the destination addresses as keys and amounts as values\n{\n \"address\": n.nnn, (object) The destination address as the key and the amount valued in bitcoin as the value\n ...\n}\n3. locktime (numeric, optional, default=0) Locktime value; a non-zero value will also locktime-activate the inputs\n4. options  (object, optional)             Options for choosing the inputs and fee\n{\n \"account\": \"value\",         (string)  The account to spend from and send change to (default=\"default\")\n \"minconf\": n,               (numeric) Minimum number of block confirmations of the outputs chosen to spend (default=1)\n \"lockUnspents\": true|false, (boolean) Lock the outputs spent so the wallet does not spend them again before the transaction is published (default=false)\n \"feeRate\": n.nnn,           (numeric) The fee rate valued in bitcoin per kilobyte (default=the minimum relay fee)\n}                            \n\nResult:\n{\n \"psbt\": \"value\", (string)  The partially signed transaction encoded as a base64 string\n \"fee\": n.nnn,    (numeric) The fee of the transaction valued in bitcoin\n \"changepos\": n,  (numeric) The index of the change output, or -1 if there is none\n}                 \n",
 		"walletlock":              "walletlock\n\nLock the wallet.\n\nArguments:\nNone\n\nResult:\nNothing\n",
 		"walletpassphrase":        "walletpassphrase \"passphrase\" timeout\n\nUnlock the wallet.\n\nArguments:\n1. passphrase (string, required)  The wallet passphrase\n2. timeout    (numeric, required) The number of seconds to wait before the wallet automatically locks\n\nResult:\nNothing\n",
 		"walletpassphrasechange":  "walletpassphrasechange \"oldpassphrase\" \"newpassphrase\"\n\nChange the wallet passphrase.\n\nArguments:\n1. oldpassphrase (string, required) The old wallet passphrase\n2. newpassphrase (string, required) The new wallet passphrase\n\nResult:\nNothing\n",
+		"walletprocesspsbt":      "walletprocesspsbt \"psbt\" (sign=true sighashtype=\"ALL\")\n\nAdds what the wallet knows of the outputs spent by the inputs of a partially signed transaction (BIP174) and the signatures of its keys, finalizing the inputs that then have all of their signatures.\nThe valid sighashtype options are ALL, NONE, SINGLE, ALL|ANYONECANPAY, NONE|ANYONECANPAY, and SINGLE|ANYONECANPAY.\n\nArguments:\n1. psbt        (string, required)                The partially signed transaction encoded as a base64 string\n2. sign        (boolean, optional, default=true) Sign the inputs the wallet has keys for, which requires the wallet to be unlocked\n3. sighashtype (string, optional, default=\"ALL\") Sighash flags of the signatures\n\nResult:\n{\n \"psbt\": \"value\",        (string)  The partially signed transaction encoded as a base64 string\n \"complete\": true|false, (boolean) Whether every input of the transaction is finalized\n}                        \n",
 		"createnewaccount":        "createnewaccount \"account\"\n\nCreates a new account.\nThe wallet must be unlocked for this request to succeed.\n\nArguments:\n1. account (string, required) Name of the new account\n\nResult:\nNothing\n",
 		"exportwatchingwallet":    "exportwatchingwallet (\"account\" download=false)\n\nCreates and returns a duplicate of the wallet database without any private keys to be used as a watching-only wallet.\n\nArguments:\n1. account  (string, optional)                 Unused (must be unset or \"*\")\n2. download (boolean, optional, default=false) Unused\n\nResult:\n\"value\" (string) The watching-only database encoded as a base64 string\n",
+		"filtertransactions":      "filtertransactions (from=0 to=0 label=\"\" address=\"\" minamount=0 maxamount=0)\n\nReturns a JSON array of objects in the same format as 'listtransactions' for the wallet transactions matching every filter given, newest first.\n\nArguments:\n1. from      (numeric, optional, default=0) Unix time of the earliest transactions to include, 0 for the first\n2. to        (numeric, optional, default=0) Unix time of the latest transactions to include, 0 for the newest\n3. label     (string, optional, default=\"\") Only include transactions received at addresses of an account with this text in its name, ignoring case\n4. address   (string, optional, default=\"\") Only include transaction outputs paying to this address\n5. minamount (numeric, optional, default=0) Smallest amount valued in bitcoin, ignoring its sign, of the transactions to include\n6. maxamount (numeric, optional, default=0) Largest amount valued in bitcoin, ignoring its sign, of the transactions to include, 0 for no limit\n\nResult:\n[{\n \"abandoned\": true|false,          (boolean)         Unset\n \"account\": \"value\",               (string)          DEPRECATED -- Unset\n \"address\": \"value\",               (string)          Payment address for a transaction output\n \"amount\": n.nnn,                  (numeric)         The value of the transaction output valued in bitcoin\n \"bip125-replaceable\": \"value\",    (string)          Unset\n \"blockhash\": \"value\",             (string)          The hash of the block this transaction is mined in, or the empty string if unmined\n \"blockindex\": n,                  (numeric)         Unset\n \"blocktime\": n,                   (numeric)         The Unix time of the block header this transaction is mined in, or 0 if unmined\n \"category\": \"value\",              (string)          The kind of transaction: \"send\" for sent transactions, \"immature\" for immature coinbase outputs, \"generate\" for mature coinbase outputs, or \"recv\" for all other received outputs.  Note: A single output may be included multiple times under different categories\n \"confirmations\": n,               (numeric)         The number of block confirmations of the transaction\n \"fee\": n.nnn,                     (numeric)         The total input value minus the total output value for sent transactions\n \"generated\": true|false,          (boolean)         Whether the transaction output is a coinbase output\n \"involveswatchonly\": true|false,  (boolean)         Unset\n \"time\": n,                        (numeric)         The earliest Unix time this transaction was known to exist\n \"timereceived\": n,                (numeric)         The earliest Unix time this transaction was known to exist\n \"trusted\": true|false,            (boolean)         Unset\n \"txid\": \"value\",                  (string)          The hash of the transaction\n \"vout\": n,                        (numeric)         The transaction output index\n \"walletconflicts\": [\"value\",...], (array of string) Unset\n \"comment\": \"value\",               (string)          Unset\n \"otheraccount\": \"value\",          (string)          Unset\n},...]\n",
 		"getbestblock":            "getbestblock\n\nReturns the hash and height of the newest block in the best chain that wallet has finished syncing with.\n\nArguments:\nNone\n\nResult:\n{\n \"hash\": \"value\", (string)  The hash of the block\n \"height\": n,     (numeric) The blockchain height of the block\n}                 \n",
+		"getsyncstatus":           "getsyncstatus\n\nReturns how the wallet follows the chain and how far it has synced.\n\nArguments:\nNone\n\nResult:\n{\n \"backend\": \"value\",   (string)  The chain backend: \"9\" for a full node over RPC, \"neutrino\" for light mode using compact block filters from peers, or \"none\" before connecting\n \"synced\": true|false, (boolean) Whether the wallet has caught up with the chain backend\n \"height\": n,          (numeric) The blockchain height of the newest block the wallet has processed\n \"headers\": n,         (numeric) The height of the newest block header the backend has\n \"filterheaders\": n,   (numeric) The height of the newest compact filter header in light mode, the same as headers otherwise\n \"peers\": n,           (numeric) The number of peers in light mode, 0 otherwise\n}                      \n",
 		"getunconfirmedbalance":   "getunconfirmedbalance (\"account\")\n\nCalculates the unspent output value of all unmined transaction outputs for an account.\n\nArguments:\n1. account (string, optional) The account to query the unconfirmed balance for (default=\"default\")\n\nResult:\nn.nnn (numeric) Total amount of all unmined unspent outputs of the account valued in bitcoin.\n",
 		"listaddresstransactions": "listaddresstransactions [\"address\",...] (\"account\")\n\nReturns a JSON array of objects containing verbose details for wallet transactions pertaining some addresses.\n\nArguments:\n1. addresses (array of string, required) Addresses to filter transaction results by\n2. account   (string, optional)          Unused (must be unset or \"*\")\n\nResult:\n[{\n \"abandoned\": true|false,          (boolean)         Unset\n \"account\": \"value\",               (string)          DEPRECATED -- Unset\n \"address\": \"value\",               (string)          Payment address for a transaction output\n \"amount\": n.nnn,                  (numeric)         The value of the transaction output valued in bitcoin\n \"bip125-replaceable\": \"value\",    (string)          Unset\n \"blockhash\": \"value\",             (string)          The hash of the block this transaction is mined in, or the empty string if unmined\n \"blockindex\": n,                  (numeric)         Unset\n \"blocktime\": n,                   (numeric)         The Unix time of the block header this transaction is mined in, or 0 if unmined\n \"category\": \"value\",              (string)          The kind of transaction: \"send\" for sent transactions, \"immature\" for immature coinbase outputs, \"generate\" for mature coinbase outputs, or \"recv\" for all other received outputs.  Note: A single output may be included multiple times under different categories\n \"confirmations\": n,               (numeric)         The number of block confirmations of the transaction\n \"fee\": n.nnn,                     (numeric)         The total input value minus the total output value for sent transactions\n \"generated\": true|false,          (boolean)         Whether the transaction output is a coinbase output\n \"involveswatchonly\": true|false,  (boolean)         Unset\n \"time\": n,                        (numeric)         The earliest Unix time this transaction was known to exist\n \"timereceived\": n,                (numeric)         The earliest Unix time this transaction was known to exist\n \"trusted\": true|false,            (boolean)         Unset\n \"txid\": \"value\",                  (string)          The hash of the transaction\n \"vout\": n,                        (numeric)         The transaction output index\n \"walletconflicts\": [\"value\",...], (array of string) Unset\n \"comment\": \"value\",               (string)          Unset\n \"otheraccount\": \"value\",          (string)          Unset\n},...]\n",
 		"listalltransactions":     "listalltransactions (\"account\")\n\nReturns a JSON array of objects in the same format as 'listtransactions' without limiting the number of returned objects.\n\nArguments:\n1. account (string, optional) Unused (must be unset or \"*\")\n\nResult:\n[{\n \"abandoned\": true|false,          (boolean)         Unset\n \"account\": \"value\",               (string)          DEPRECATED -- Unset\n \"address\": \"value\",               (string)          Payment address for a transaction output\n \"amount\": n.nnn,                  (numeric)         The value of the transaction output valued in bitcoin\n \"bip125-replaceable\": \"value\",    (string)          Unset\n \"blockhash\": \"value\",             (string)          The hash of the block this transaction is mined in, or the empty string if unmined\n \"blockindex\": n,                  (numeric)         Unset\n \"blocktime\": n,                   (numeric)         The Unix time of the block header this transaction is mined in, or 0 if unmined\n \"category\": \"value\",              (string)          The kind of transaction: \"send\" for sent transactions, \"immature\" for immature coinbase outputs, \"generate\" for mature coinbase outputs, or \"recv\" for all other received outputs.  Note: A single output may be included multiple times under different categories\n \"confirmations\": n,               (numeric)         The number of block confirmations of the transaction\n \"fee\": n.nnn,                     (numeric)         The total input value minus the total output value for sent transactions\n \"generated\": true|false,          (boolean)         Whether the transaction output is a coinbase output\n \"involveswatchonly\": true|false,  (boolean)         Unset\n \"time\": n,                        (numeric)         The earliest Unix time this transaction was known to exist\n \"timereceived\": n,                (numeric)         The earliest Unix time this transaction was known to exist\n \"trusted\": true|false,            (boolean)         Unset\n \"txid\": \"value\",                  (string)          The hash of the transaction\n \"vout\": n,                        (numeric)         The transaction output index\n \"walletconflicts\": [\"value\",...], (array of string) Unset\n \"comment\": \"value\",               (string)          Unset\n \"otheraccount\": \"value\",          (string)          Unset\n},...]\n",
@@ -52,4 +59,4 @@ func helpDescsEnUS() map[string]string {
 var localeHelpDescs = map[string]func() map[string]string{
 	"en_US": helpDescsEnUS,
 }
-var requestUsages = "addmultisigaddress nrequired [\"key\",...] (\"account\")\ncreatemultisig nrequired [\"key\",...]\ndumpprivkey \"address\"\ngetaccount \"address\"\ngetaccountaddress \"account\"\ngetaddressesbyaccount \"account\"\ngetbalance (\"account\" minconf=1)\ngetbestblockhash\ngetblockcount\ngetinfo\ngetnewaddress (\"account\")\ngetrawchangeaddress (\"account\")\ngetreceivedbyaccount \"account\" (minconf=1)\ngetreceivedbyaddress \"address\" (minconf=1)\ngettransaction \"txid\" (includewatchonly=false)\nhelp (\"command\")\nimportprivkey \"privkey\" (\"label\" rescan=true)\nkeypoolrefill (newsize=100)\nlistaccounts (minconf=1)\nlistlockunspent\nlistreceivedbyaccount (minconf=1 includeempty=false includewatchonly=false)\nlistreceivedbyaddress (minconf=1 includeempty=false includewatchonly=false)\nlistsinceblock (\"blockhash\" targetconfirmations=1 includewatchonly=false)\nlisttransactions (\"account\" count=10 from=0 includewatchonly=false)\nlistunspent (minconf=1 maxconf=9999999 [\"address\",...])\nlockunspent unlock [{\"txid\":\"value\",\"vout\":n},...]\nsendfrom \"fromaccount\" \"toaddress\" amount (minconf=1 \"comment\" \"commentto\")\nsendmany \"fromaccount\" {\"address\":amount,...} (minconf=1 \"comment\")\nsendtoaddress \"address\" amount (\"comment\" \"commentto\")\nsettxfee amount\nsignmessage \"address\" \"message\"\nsignrawtransaction \"rawtx\" ([{\"txid\":\"value\",\"vout\":n,\"scriptpubkey\":\"value\",\"redeemscript\":\"value\"},...] [\"privkey\",...] flags=\"ALL\")\nvalidateaddress \"address\"\nverifymessage \"address\" \"signature\" \"message\"\nwalletlock\nwalletpassphrase \"passphrase\" timeout\nwalletpassphrasechange \"oldpassphrase\" \"newpassphrase\"\ncreatenewaccount \"account\"\nexportwatchingwallet (\"account\" download=false)\ngetbestblock\ngetunconfirmedbalance (\"account\")\nlistaddresstransactions [\"address\",...] (\"account\")\nlistalltransactions (\"account\")\nrenameaccount \"oldaccount\" \"newaccount\"\nwalletislocked"
+var requestUsages = "addmultisigaddress nrequired [\"key\",...] (\"account\")\nbumpfee \"txid\" ({\"feerate\":feerate})\ncreatemultisig nrequired [\"key\",...]\ndecodepsbt \"psbt\"\ndumpprivkey \"address\"\nfinalizepsbt \"psbt\" (extract=true)\ngetaccount \"address\"\ngetaccountaddress \"account\"\ngetaddressesbyaccount \"account\"\ngetbalance (\"account\" minconf=1)\ngetbestblockhash\ngetblockcount\ngetinfo\ngetnewaddress (\"account\")\ngetrawchangeaddress (\"account\")\ngetreceivedbyaccount \"account\" (minconf=1)\ngetreceivedbyaddress \"address\" (minconf=1)\ngettransaction \"txid\" (includewatchonly=false)\nhelp (\"command\")\nimportprivkey \"privkey\" (\"label\" rescan=true)\nkeypoolrefill (newsize=100)\nlistaccounts (minconf=1)\nlistlockunspent\nlistreceivedbyaccount (minconf=1 includeempty=false includewatchonly=false)\nlistreceivedbyaddress (minconf=1 includeempty=false includewatchonly=false)\nlistsinceblock (\"blockhash\" targetconfirmations=1 includewatchonly=false)\nlisttransactions (\"account\" count=10 from=0 includewatchonly=false)\nlistunspent (minconf=1 maxconf=9999999 [\"address\",...])\nlockunspent unlock [{\"txid\":\"value\",\"vout\":n},...]\nsendfrom \"fromaccount\" \"toaddress\" amount (minconf=1 \"comment\" \"commentto\")\nsendmany \"fromaccount\" {\"address\":amount,...} (minconf=1 \"comment\")\nsendtoaddress \"address\" amount (\"comment\" \"commentto\")\nsettxfee amount\nsignmessage \"address\" \"message\"\nsignrawtransaction \"rawtx\" ([{\"txid\":\"value\",\"vout\":n,\"scriptpubkey\":\"value\",\"redeemscript\":\"value\"},...] [\"privkey\",...] flags=\"ALL\")\nvalidateaddress \"address\"\nverifymessage \"address\" \"signature\" \"message\"\nwalletcreatefundedpsbt [{\"txid\":\"value\",\"vout\":n},...] {\"address\":amount,...} (locktime=0 {\"account\":account,\"minconf\":minconf,\"lockunspents\":lockunspents,\"feerate\":feerate})\nwalletlock\nwalletpassphrase \"passphrase\" timeout\nwalletpassphrasechange \"oldpassphrase\" \"newpassphrase\"\nwalletprocesspsbt \"psbt\" (sign=true sighashtype=\"ALL\")\ncreatenewaccount \"account\"\nexportwatchingwallet (\"account\" download=false)\nfiltertransactions (from=0 to=0 label=\"\" address=\"\" minamount=0 maxamount=0)\ngetbestblock\ngetsyncstatus\ngetunconfirmedbalance (\"account\")\nlistaddresstransactions [\"address\",...] (\"account\")\nlistalltransactions (\"account\")\nrenameaccount \"oldaccount\" \"newaccount\"\nwalletislocked"
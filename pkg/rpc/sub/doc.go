@@ -5,4 +5,12 @@
 // Payload can be encrypted via AES-256 encryption using a pre-shared key known by both ends to function as both access control and security against eavesdropping and spoofing attacks.
 //
 // Authentication of data is done using an ED25119 EC key for which each known endpoint has shared the public key as part of the subscription request
+//
+// For data that must arrive, such as the messages between the miner dispatcher and its workers, a Session carries an ordered byte stream over the same FEC coding. Each write is split into groups that are acknowledged by the receiver once recovered, and the groups that lose more packets than the code can recover are sent again. Sessions are opened with DialSession and ListenSession, and implement net.Conn and net.Listener so they can replace TCP connections.
+//
+// Payloads larger than one message that are pushed without waiting for acknowledgement, such as block templates sent to a multicast group, are written to a StreamWriter, which sends them as a stream of FEC groups, and read from a StreamReader, which returns them in order and fails with ErrStreamGap if a group is lost beyond recovery. Short messages for every subscriber on a LAN are sent with an Announcer and received from ListenAnnouncements.
+//
+// On the receiving side sessions, stream readers and announcements drop duplicate shards and shards failing their checksum, and give up on groups that are not recovered within a timeout of their first shard. What became of the shards received is returned by their Received methods.
+//
+// The coding is checked by property tests of random loss and corruption, and fuzz.go is the entry point for go-fuzz.
 package sub
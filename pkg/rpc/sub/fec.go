@@ -2,8 +2,9 @@ package sub
 // Reed Solomon 9/3 forward error correction, intended to be sent as 9 pieces where 3 uncorrupted parts allows assembly of the message
 import (
 	"encoding/binary"
+	"fmt"
 	"hash/crc32"
-	"log"
+	"sync"
 	"github.com/vivint/infectious"
 )
 var (
@@ -16,25 +17,53 @@ var (
 		}
 		return fec
 	}()
+	// padPool is a free list of buffers for the padded data of encodeBatch, holding pointers to slices to avoid boxing allocations.
+	padPool = sync.Pool{
+		New: func() interface{} {
+			b := make([]byte, 0, maxMessageSize)
+			return &b
+		},
+	}
+	// shardPool is a free list of buffers for the shards made by encodeBatch, which are given back by releaseShards. New buffers hold a shard that fills a datagram of DefaultMTU.
+	shardPool = sync.Pool{
+		New: func() interface{} {
+			b := make([]byte, 0, DefaultMTU)
+			return &b
+		},
+	}
 )
 // padData appends a 2 byte length prefix, and pads to a multiple of rsTotal. An empty slice will be returned if the total length is greater than maxMessageSize.
 func padData(
 	data []byte) (out []byte) {
-	dataLen := len(data)
-	prefixBytes := make([]byte, 2)
-	binary.LittleEndian.PutUint16(prefixBytes, uint16(dataLen))
-	data = append(prefixBytes, data...)
-	dataLen = len(data)
+	return padTo(data, rsTotal)
+}
+// padTo appends a 2 byte length prefix, and pads to a multiple of the passed number of bytes. An empty slice will be returned if the total length is greater than maxMessageSize.
+func padTo(
+	data []byte, multiple int) (out []byte) {
+	return padInto(nil, data, multiple)
+}
+// padInto is padTo writing the padded data into buf, which is only allocated again when it is too small.
+func padInto(
+	buf, data []byte, multiple int) (out []byte) {
+	dataLen := 2 + len(data)
 	if dataLen > maxMessageSize {
 		return []byte{}
 	}
-	chunkLen := (dataLen) / rsTotal
-	chunkMod := (dataLen) % rsTotal
+	chunkLen := (dataLen) / multiple
+	chunkMod := (dataLen) % multiple
 	if chunkMod != 0 {
 		chunkLen++
 	}
-	padLen := rsTotal*chunkLen - dataLen
-	out = append(data, make([]byte, padLen)...)
+	size := multiple * chunkLen
+	if cap(buf) < size {
+		buf = make([]byte, size)
+	}
+	out = buf[:size]
+	binary.LittleEndian.PutUint16(out, uint16(len(data)))
+	n := 2 + copy(out[2:], data)
+	for i := n; i < size; i++ {
+		out[i] = 0
+	}
 	return
 }
 func rsEncode(
@@ -65,19 +94,153 @@ func rsDecode(
 	chunks [][]byte) (data []byte, err error) {
 	defer func() {
 		if r := recover(); r != nil {
-			log.Print("Recovered in f", r)
+			data, err = nil, fmt.Errorf("invalid shards: %v", r)
 		}
 	}()
-	var shares []infectious.Share
+	shares, err := toShares(chunks, rsTotal)
+	if err != nil {
+		return
+	}
+	return rsFEC.Decode(nil, shares)
+}
+// toShares returns the shares of chunks made by encode or rsEncode, with their shard numbers and data without the checksum, failing for chunks too short to hold them, numbers outside the code of total shards, and shares of different sizes, which the decoder does not check.
+func toShares(
+	chunks [][]byte, total int) (shares []infectious.Share, err error) {
 	for i := range chunks {
-		bodyLen := len(chunks[i])
-		body := chunks[i][:bodyLen-4]
-		share := infectious.Share{
+		if len(chunks[i]) < 1+1+4 {
+			return nil, fmt.Errorf("shard %d of %d bytes is too short", i, len(chunks[i]))
+		}
+		body := chunks[i][:len(chunks[i])-4]
+		if int(body[0]) >= total {
+			return nil, fmt.Errorf("shard number %d is not below %d", body[0], total)
+		}
+		if len(shares) > 0 && len(body)-1 != len(shares[0].Data) {
+			return nil, fmt.Errorf("shard %d has %d bytes of data, not %d", i, len(body)-1, len(shares[0].Data))
+		}
+		shares = append(shares, infectious.Share{
 			Number: int(body[0]),
 			Data:   body[1:],
-		}
-		shares = append(shares, share)
+		})
+	}
+	return
+}
+// fecCode is a Reed Solomon code where any required of total shards recover the data, with shards of at most shardSize bytes.
+type fecCode struct {
+	required  int
+	total     int
+	shardSize int
+	fec       *infectious.FEC
+}
+// newFECCode returns a code where any required of total shards of at most shardSize bytes recover the data.
+func newFECCode(
+	required, total, shardSize int) (*fecCode, error) {
+	fec, err := infectious.NewFEC(required, total)
+	if err != nil {
+		return nil, err
+	}
+	return &fecCode{
+		required:  required,
+		total:     total,
+		shardSize: shardSize,
+		fec:       fec,
+	}, nil
+}
+// payload returns the most data one group of the code carries, which is the data of the required shards less the length prefix, and no more than maxMessageSize allows.
+func (c *fecCode) payload() int {
+	size := c.required * c.shardSize
+	if size > maxMessageSize {
+		size = maxMessageSize
+	}
+	return size - 2
+}
+// payloadFor returns the most data one group of the code carries when its shards are no larger than maxShard, which is the payload of the code when its shards already fit.
+func (c *fecCode) payloadFor(
+	maxShard int) int {
+	if maxShard >= c.shardSize {
+		return c.payload()
+	}
+	return c.required*maxShard - 2
+}
+// encode returns the shards of the data, which must not be longer than the payload of the code, in the same format as rsEncode. Each shard holds the data padded with its length prefix divided by the required shards, so shorter data makes smaller shards.
+func (c *fecCode) encode(
+	data []byte) (chunks [][]byte) {
+	chunks = make([][]byte, c.total)
+	c.encodeTo(chunks, padTo(data, c.required), func(size int) []byte {
+		return make([]byte, 0, size)
+	})
+	return
+}
+// encodeBatch encodes each of the payloads as encode does, returning the shards of each. The padded data and the shards are held in buffers from pools, so encoding groups continually allocates little once the pools fill. The shards should be given back with releaseShards once they are sent, and must not be kept, as sessions keep theirs to send them again.
+func (c *fecCode) encodeBatch(
+	payloads [][]byte) (groups [][][]byte) {
+	padded := padPool.Get().(*[]byte)
+	defer padPool.Put(padded)
+	groups = make([][][]byte, len(payloads))
+	for i, data := range payloads {
+		*padded = padInto(*padded, data, c.required)
+		groups[i] = make([][]byte, c.total)
+		c.encodeTo(groups[i], *padded, getShard)
 	}
-	data, err = rsFEC.Decode(nil, shares)
 	return
 }
+// encodeTo encodes the padded data into chunks, one for each shard, each in a buffer of at least the passed size from alloc holding its number, data and checksum.
+func (c *fecCode) encodeTo(
+	chunks [][]byte, padded []byte, alloc func(size int) []byte) {
+	size := 1 + len(padded)/c.required + 4
+	output := func(s infectious.Share) {
+		// The data of the share is only valid during the call, so it is copied into the chunk.
+		chunk := append(append(alloc(size), byte(s.Number)), s.Data...)
+		var checkbytes [4]byte
+		binary.LittleEndian.PutUint32(checkbytes[:], crc32.Checksum(chunk, crcTable))
+		chunks[s.Number] = append(chunk, checkbytes[:]...)
+	}
+	if err := c.fec.Encode(padded, output); err != nil {
+		panic(err)
+	}
+}
+// getShard returns an empty buffer of at least the passed size from shardPool.
+func getShard(
+	size int) []byte {
+	b := *shardPool.Get().(*[]byte)
+	if cap(b) < size {
+		return make([]byte, 0, size)
+	}
+	return b[:0]
+}
+// releaseShards gives the buffers of the shards made by encodeBatch back to shardPool.
+func releaseShards(
+	groups [][][]byte) {
+	for _, chunks := range groups {
+		for i := range chunks {
+			b := chunks[i][:0]
+			shardPool.Put(&b)
+			chunks[i] = nil
+		}
+	}
+}
+// decode returns the padded data with its length prefix from at least required shards made by encode.
+func (c *fecCode) decode(
+	chunks [][]byte) (data []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			data, err = nil, fmt.Errorf("invalid shards: %v", r)
+		}
+	}()
+	shares, err := toShares(chunks, c.total)
+	if err != nil {
+		return
+	}
+	return c.fec.Decode(nil, shares)
+}
+// unpad returns the data of a group from the padded data decode returns, failing when its length prefix is longer than the padded data.
+func unpad(
+	padded []byte) (data []byte, ok bool) {
+	if len(padded) < 2 {
+		return
+	}
+	size := int(binary.LittleEndian.Uint16(padded))
+	if size+2 > len(padded) {
+		return
+	}
+	return padded[2 : size+2], true
+}
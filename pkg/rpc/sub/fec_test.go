@@ -1,5 +1,6 @@
 package sub
 import (
+	"bytes"
 	"encoding/binary"
 	"encoding/hex"
 	"testing"
@@ -51,3 +52,62 @@ func TestFECCodec(
 		t.Fatalf("FEC encode/decode failed:\ngot      '%s'\nexpected '%s'", dataString, resultString)
 	}
 }
+// TestFECCodes ensures each of the codes sessions adapt between recovers a full group from only its required shards, which fit the shard size, and encodes the same shards in a batch.
+func TestFECCodes(
+	t *testing.T) {
+	for _, c := range codeRates {
+		data := make([]byte, c.payload())
+		for i := range data {
+			data[i] = byte(i)
+		}
+		chunks := c.encode(data)
+		if len(chunks) != c.total {
+			t.Fatalf("code %d/%d made %d shards", c.required, c.total, len(chunks))
+		}
+		if size := len(chunks[0]) - 1 - 4; size > c.shardSize {
+			t.Fatalf("code %d/%d made shards of %d bytes, more than %d",
+				c.required, c.total, size, c.shardSize)
+		}
+		got, err := c.decode(chunks[c.total-c.required:])
+		if err != nil {
+			t.Fatalf("code %d/%d: unexpected decode error: %v", c.required, c.total, err)
+		}
+		dataLen := binary.LittleEndian.Uint16(got)
+		if hex.EncodeToString(got[2:dataLen+2]) != hex.EncodeToString(data) {
+			t.Fatalf("code %d/%d did not recover the data", c.required, c.total)
+		}
+		groups := c.encodeBatch([][]byte{data, data[:1]})
+		for i, want := range [][][]byte{chunks, c.encode(data[:1])} {
+			for j := range want {
+				if !bytes.Equal(groups[i][j], want[j]) {
+					t.Fatalf("code %d/%d encoded shard %d of group %d differently in a batch", c.required, c.total, j, i)
+				}
+			}
+		}
+		releaseShards(groups)
+	}
+}
+// TestShardSizes ensures the groups of every code sized for an MTU make datagrams that fit it, and that codes whose shards already fit are not limited.
+func TestShardSizes(
+	t *testing.T) {
+	for _, mtu := range []int{MinMTU, 1280, DefaultMTU, 9000} {
+		for _, c := range codeRates {
+			size := c.payloadFor(MaxShardSize(mtu))
+			if size > c.payload() {
+				t.Fatalf("code %d/%d carries %d bytes for MTU %d, more than its payload %d",
+					c.required, c.total, size, mtu, c.payload())
+			}
+			for _, chunk := range c.encode(make([]byte, size)) {
+				datagram := marshalDatagram(pktData, 1, 1,
+					append([]byte{byte(c.required), byte(c.total)}, chunk...))
+				if len(datagram)+udpOverhead > mtu {
+					t.Fatalf("code %d/%d made a datagram of %d bytes for MTU %d",
+						c.required, c.total, len(datagram)+udpOverhead, mtu)
+				}
+			}
+		}
+	}
+	if MaxShardSize(100) != MaxShardSize(MinMTU) {
+		t.Fatal("MTU below the minimum was not taken as the minimum")
+	}
+}
@@ -0,0 +1,71 @@
+package sub
+// Benchmarks of the FEC coding with shards that fill a datagram of DefaultMTU, reporting the throughput of the data carried. The targets on one core of a 2GHz x86-64 machine are at least 100MB/s for encoding and for decoding from the data shards, at least 50MB/s for rebuilding a group from its parity shards, and fewer allocations for encodeBatch than for encode once its pools are filled, as only the slices holding the shards are allocated.
+import (
+	"testing"
+)
+var (
+	// benchCode is a code whose shards fill a datagram of DefaultMTU.
+	benchCode = func() *fecCode {
+		c, err := newFECCode(2, 6, MaxShardSize(DefaultMTU))
+		if err != nil {
+			panic(err)
+		}
+		return c
+	}()
+	// benchBatch is the number of groups encoded together by BenchmarkFECEncodeBatch.
+	benchBatch = 16
+	benchData  = func() []byte {
+		data := make([]byte, benchCode.payload())
+		for i := range data {
+			data[i] = byte(i)
+		}
+		return data
+	}()
+	benchShards [][]byte
+)
+// BenchmarkFECEncode benchmarks encoding a full group.
+func BenchmarkFECEncode(
+	b *testing.B) {
+	b.SetBytes(int64(len(benchData)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		benchShards = benchCode.encode(benchData)
+	}
+}
+// BenchmarkFECEncodeBatch benchmarks encoding full groups in batches with buffers from the pools.
+func BenchmarkFECEncodeBatch(
+	b *testing.B) {
+	payloads := make([][]byte, benchBatch)
+	for i := range payloads {
+		payloads[i] = benchData
+	}
+	b.SetBytes(int64(len(benchData) * benchBatch))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		releaseShards(benchCode.encodeBatch(payloads))
+	}
+}
+// BenchmarkFECDecode benchmarks recovering a full group from its data shards.
+func BenchmarkFECDecode(
+	b *testing.B) {
+	shards := benchCode.encode(benchData)[:benchCode.required]
+	b.SetBytes(int64(len(benchData)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := benchCode.decode(shards); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+// BenchmarkFECReconstruct benchmarks rebuilding a full group from its parity shards alone.
+func BenchmarkFECReconstruct(
+	b *testing.B) {
+	shards := benchCode.encode(benchData)[benchCode.total-benchCode.required:]
+	b.SetBytes(int64(len(benchData)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := benchCode.decode(shards); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
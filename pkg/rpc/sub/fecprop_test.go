@@ -0,0 +1,110 @@
+package sub
+// Property tests of the FEC coding: any payload survives the loss of any shards up to the parity of its code, and shards corrupted or malformed beyond what the code recovers never give back wrong data or panic
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+	"time"
+)
+var (
+	// propRounds is the number of random payloads tried for each code.
+	propRounds = 200
+	// propCodes are the codes the properties are checked for.
+	propCodes = append(append([]*fecCode{}, codeRates...), announceCode)
+)
+// propData returns random data of a random length up to the payload of the code.
+func propData(
+	r *rand.Rand, c *fecCode) []byte {
+	data := make([]byte, r.Intn(c.payload()+1))
+	r.Read(data)
+	return data
+}
+// TestFECLoss ensures data is recovered from any required of its shards in any order, which is any loss up to the parity of the code.
+func TestFECLoss(
+	t *testing.T) {
+	seed := time.Now().UnixNano()
+	r := rand.New(rand.NewSource(seed))
+	for _, c := range propCodes {
+		for i := 0; i < propRounds; i++ {
+			data := propData(r, c)
+			shards := c.encode(data)
+			kept := r.Intn(c.total-c.required+1) + c.required
+			var survivors [][]byte
+			for _, j := range r.Perm(c.total)[:kept] {
+				survivors = append(survivors, shards[j])
+			}
+			padded, err := c.decode(survivors)
+			if err != nil {
+				t.Fatalf("seed %d: code %d/%d failed to decode %d bytes from %d shards: %v",
+					seed, c.required, c.total, len(data), kept, err)
+			}
+			if got, ok := unpad(padded); !ok || !bytes.Equal(got, data) {
+				t.Fatalf("seed %d: code %d/%d decoded %d bytes from %d shards wrongly",
+					seed, c.required, c.total, len(data), kept)
+			}
+		}
+	}
+}
+// TestFECCorruption ensures a receiver only recovers the data that was sent when shards are corrupted, recovering it when enough shards are intact and nothing otherwise, and that corrupt shards decoded without their checksums being checked give an error or data but no panic.
+func TestFECCorruption(
+	t *testing.T) {
+	seed := time.Now().UnixNano()
+	r := rand.New(rand.NewSource(seed))
+	for _, c := range propCodes {
+		for i := 0; i < propRounds; i++ {
+			data := propData(r, c)
+			shards := c.encode(data)
+			corrupt := r.Intn(c.total + 1)
+			for _, j := range r.Perm(c.total)[:corrupt] {
+				shards[j][r.Intn(len(shards[j]))] ^= byte(r.Intn(255) + 1)
+			}
+			// A corrupted shard number may collide with another shard, so the intact shards are counted by number.
+			intact := make(map[byte]struct{})
+			for _, shard := range shards {
+				if checkShard(shard) && int(shard[0]) < c.total {
+					intact[shard[0]] = struct{}{}
+				}
+			}
+			recv := newReceiver(groupTimeout)
+			var got []byte
+			var ok bool
+			for _, j := range r.Perm(c.total) {
+				if got, ok = recv.add(groupID{}, c, shards[j], time.Now()); ok {
+					break
+				}
+			}
+			switch {
+			case ok && !bytes.Equal(got, data):
+				t.Fatalf("seed %d: code %d/%d recovered wrong data with %d shards corrupted",
+					seed, c.required, c.total, corrupt)
+			case !ok && len(intact) >= c.required:
+				t.Fatalf("seed %d: code %d/%d did not recover data with %d shards intact",
+					seed, c.required, c.total, len(intact))
+			}
+			c.decode(shards)
+		}
+	}
+}
+// TestFECMalformed ensures shards that are not made by encode are refused by decode without panicking.
+func TestFECMalformed(
+	t *testing.T) {
+	c := codeRates[defaultCodeRate]
+	shards := c.encode([]byte("malformed"))
+	for name, chunks := range map[string][][]byte{
+		"empty":          {{}},
+		"checksum only":  {shards[0][:4]},
+		"no data":        {shards[0][:5]},
+		"number too big": {append([]byte{byte(c.total)}, shards[0][1:]...), shards[1], shards[2]},
+		"uneven":         {shards[0], shards[1][:len(shards[1])-1], shards[2]},
+	} {
+		if _, err := c.decode(chunks); err == nil {
+			t.Errorf("%s shards decoded without error", name)
+		}
+		if _, err := rsDecode(chunks); err == nil {
+			t.Errorf("%s shards decoded without error by rsDecode", name)
+		}
+	}
+	if _, ok := unpad([]byte{0xff, 0xff, 1, 2}); ok {
+		t.Error("padded data shorter than its length prefix was unpadded")
+	}
+}
@@ -0,0 +1,55 @@
+// +build gofuzz
+
+package sub
+// Entry point for go-fuzz of the FEC coding. Build it with go-fuzz-build git.parallelcoin.io/dev/9/pkg/rpc/sub and run it with go-fuzz -bin sub-fuzz.zip -workdir fuzz.
+import (
+	"bytes"
+	"time"
+)
+// Fuzz takes the first byte of the input to pick a code and which of its shards are lost, and the second to pick a shard to corrupt, and encodes the rest as the data of a group. The data must be recovered from the shards that are left when enough of them are intact, and nothing else may be recovered. The input is also decoded as if it were shards, which must not panic.
+func Fuzz(data []byte) int {
+	if len(data) < 2 {
+		return -1
+	}
+	c := codeRates[int(data[0]>>5)%len(codeRates)]
+	lost, corrupt, payload := data[0]&0x1f, int(data[1]), data[2:]
+	if len(payload) > c.payload() {
+		payload = payload[:c.payload()]
+	}
+	shards := c.encode(payload)
+	// Shards are lost from the end by the mask of the low bits of the first byte, and one shard is corrupted when the second byte names one.
+	var kept [][]byte
+	intact := 0
+	for i, shard := range shards {
+		if i >= c.total-5 && lost&(1<<uint(c.total-1-i)) != 0 {
+			continue
+		}
+		if i == corrupt {
+			shard = append([]byte{}, shard...)
+			shard[len(shard)/2] ^= 0x55
+		} else {
+			intact++
+		}
+		kept = append(kept, shard)
+	}
+	recv := newReceiver(groupTimeout)
+	var got []byte
+	var ok bool
+	for _, shard := range kept {
+		if got, ok = recv.add(groupID{}, c, shard, time.Now()); ok {
+			break
+		}
+	}
+	if ok && !bytes.Equal(got, payload) {
+		panic("recovered data that was not sent")
+	}
+	if !ok && intact >= c.required {
+		panic("data not recovered from enough intact shards")
+	}
+	c.decode([][]byte{data, data[1:], data[2:]})
+	rsDecode([][]byte{data})
+	if ok {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,35 @@
+package sub
+// Sizing shards so each fits a single datagram on the path to its receiver, without IP fragmentation, as losing any fragment loses the whole datagram
+import (
+	"net"
+)
+const (
+	// DefaultMTU is the MTU of a path until the PathMTU hook or SetMTU gives another, which is that of Ethernet.
+	DefaultMTU = 1500
+	// MinMTU is the smallest MTU a path is taken to have, the size of datagram every IPv4 host must accept.
+	MinMTU = 576
+	// udpOverhead is the size of the IPv4 and UDP headers of a datagram.
+	udpOverhead = 20 + 8
+	// shardOverhead is the size of what surrounds the data of a shard in a datagram: the type, id and sequence number of the session header, the flags and code of the group, the shard number and checksum of the shard, and the checksum of the datagram.
+	shardOverhead = 9 + 3 + 1 + 4 + 4
+)
+// PathMTU is the hook giving the MTU of the path to a remote address, or 0 when it is not known, for sessions and streams to size their shards when they start. It can be replaced to plug in path MTU discovery, such as reading the MTU the operating system has learned for a connected socket.
+var PathMTU = func(conn net.PacketConn, remote net.Addr) int {
+	return 0
+}
+// MaxShardSize returns the most data of a shard that fits a single datagram on a path with the passed MTU. MTUs below MinMTU are taken as MinMTU.
+func MaxShardSize(
+	mtu int) int {
+	if mtu < MinMTU {
+		mtu = MinMTU
+	}
+	return mtu - udpOverhead - shardOverhead
+}
+// pathMTU returns the MTU of the path to the remote address from the PathMTU hook, or DefaultMTU if it does not know it.
+func pathMTU(
+	conn net.PacketConn, remote net.Addr) int {
+	if mtu := PathMTU(conn, remote); mtu > 0 {
+		return mtu
+	}
+	return DefaultMTU
+}
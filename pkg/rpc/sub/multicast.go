@@ -0,0 +1,127 @@
+package sub
+// Announcement of short messages to every subscriber on a LAN at once by UDP multicast or broadcast, coded with more redundancy than sessions start with as there is no feedback from the receivers to adapt to
+import (
+	"fmt"
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+)
+var (
+	// announceCode is the code of announcements. Any 2 of 10 shards recover a message, so a subscriber losing most of the datagrams of a burst still gets it without a way to ask for it again. Its shards fit a datagram on a path of MinMTU, as the paths to the subscribers are not known.
+	announceCode = func() *fecCode {
+		c, err := newFECCode(2, 10, 512)
+		if err != nil {
+			panic(err)
+		}
+		return c
+	}()
+	// MaxAnnouncement is the largest message that can be announced.
+	MaxAnnouncement = announceCode.payload()
+)
+// Announcer sends messages to a multicast group or broadcast address. Each message is sent once as the shards of announceCode, with a sequence number so subscribers can tell them apart.
+type Announcer struct {
+	sync.Mutex
+	conn  *net.UDPConn
+	group *net.UDPAddr
+	id    uint32
+	seq   uint32
+}
+// NewAnnouncer returns an announcer sending to the passed multicast group or broadcast address. Multicast is sent with a time to live of 1 so it stays on the LAN.
+func NewAnnouncer(
+	group string) (*Announcer, error) {
+	addr, err := net.ResolveUDPAddr(uNet, group)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.ListenUDP(uNet, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Announcer{conn: conn, group: addr, id: rand.Uint32()}, nil
+}
+// Announce sends a message of up to MaxAnnouncement bytes to the group. Shards that fail to send count as lost.
+func (a *Announcer) Announce(
+	msg []byte) error {
+	if len(msg) > MaxAnnouncement {
+		return fmt.Errorf("announcement of %d bytes is larger than %d", len(msg), MaxAnnouncement)
+	}
+	a.Lock()
+	seq := a.seq
+	a.seq++
+	a.Unlock()
+	var err error
+	for _, shard := range announceCode.encode(msg) {
+		if _, e := a.conn.WriteToUDP(marshalDatagram(pktAnnounce, a.id, seq, shard), a.group); e != nil {
+			err = e
+		}
+	}
+	return err
+}
+// Close stops the announcer.
+func (a *Announcer) Close() error {
+	return a.conn.Close()
+}
+// Announcements receives the messages of the announcers sending to a multicast group or broadcast address.
+type Announcements struct {
+	sync.Mutex
+	conn *net.UDPConn
+	recv *receiver
+}
+// ListenAnnouncements returns a receiver of the announcements sent to the passed multicast group, or to its port when it is a broadcast or unicast address.
+func ListenAnnouncements(
+	group string) (*Announcements, error) {
+	addr, err := net.ResolveUDPAddr(uNet, group)
+	if err != nil {
+		return nil, err
+	}
+	var conn *net.UDPConn
+	if addr.IP.IsMulticast() {
+		conn, err = net.ListenMulticastUDP(uNet, nil, addr)
+	} else {
+		conn, err = net.ListenUDP(uNet, &net.UDPAddr{Port: addr.Port})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Announcements{conn: conn, recv: newReceiver(groupTimeout)}, nil
+}
+// Receive returns the next announcement recovered from its shards and the address of its sender. Each announcement is returned once, and ones not recovered within groupTimeout of their first shard are dropped.
+func (a *Announcements) Receive() (msg []byte, from net.Addr, err error) {
+	buf := make([]byte, defaultBufferSize)
+	for {
+		n, addr, err := a.conn.ReadFrom(buf)
+		if err != nil {
+			return nil, nil, err
+		}
+		typ, id, seq, shard, ok := parseDatagram(buf[:n])
+		if !ok || typ != pktAnnounce {
+			continue
+		}
+		if msg, ok = a.receive(groupID{addr.String(), id, seq}, shard, time.Now()); ok {
+			return msg, addr, nil
+		}
+	}
+}
+// receive adds a shard to its announcement, returning the announcement once it is recovered.
+func (a *Announcements) receive(
+	id groupID, shard []byte, now time.Time) ([]byte, bool) {
+	a.Lock()
+	defer a.Unlock()
+	a.recv.expire(now)
+	return a.recv.add(id, announceCode, shard, now)
+}
+// Received returns the counts of the shards and announcements received.
+func (a *Announcements) Received() ReceiverStats {
+	a.Lock()
+	defer a.Unlock()
+	return a.recv.stats
+}
+// Addr returns the address the announcements are received on.
+func (a *Announcements) Addr() net.Addr {
+	return a.conn.LocalAddr()
+}
+// Close stops receiving announcements, making Receive return an error.
+func (a *Announcements) Close() error {
+	return a.conn.Close()
+}
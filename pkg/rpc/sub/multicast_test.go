@@ -0,0 +1,58 @@
+package sub
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+// TestAnnouncements ensures announcements reach a receiver, are recovered from any two of their shards, are only returned once and are dropped when not recovered in time.
+func TestAnnouncements(
+	t *testing.T) {
+	r, err := ListenAnnouncements("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("ListenAnnouncements: unexpected error: %v", err)
+	}
+	defer r.Close()
+	a, err := NewAnnouncer(r.Addr().String())
+	if err != nil {
+		t.Fatalf("NewAnnouncer: unexpected error: %v", err)
+	}
+	defer a.Close()
+	if err := a.Announce(make([]byte, MaxAnnouncement+1)); err == nil {
+		t.Fatal("Announce: expected error for a message larger than MaxAnnouncement")
+	}
+	want := [][]byte{[]byte("first"), bytes.Repeat([]byte{7}, MaxAnnouncement)}
+	for _, msg := range want {
+		if err := a.Announce(msg); err != nil {
+			t.Fatalf("Announce: unexpected error: %v", err)
+		}
+	}
+	r.conn.SetReadDeadline(time.Now().Add(time.Second * 5))
+	for i := range want {
+		got, _, err := r.Receive()
+		if err != nil {
+			t.Fatalf("Receive: unexpected error: %v", err)
+		}
+		if !bytes.Equal(got, want[i]) {
+			t.Fatalf("announcement %d is not the message announced", i)
+		}
+	}
+	s := &Announcements{recv: newReceiver(groupTimeout)}
+	now := time.Now()
+	id := groupID{sender: "sender"}
+	shards := announceCode.encode([]byte("lossy"))
+	if _, ok := s.receive(id, shards[3], now); ok {
+		t.Fatal("announcement recovered from one shard")
+	}
+	if got, _ := s.receive(id, shards[7], now); string(got) != "lossy" {
+		t.Fatalf("announcement recovered from two shards as %q", got)
+	}
+	if _, ok := s.receive(id, shards[8], now); ok {
+		t.Fatal("announcement returned twice")
+	}
+	id.seq++
+	shards = announceCode.encode([]byte("late"))
+	s.receive(id, shards[0], now)
+	if _, ok := s.receive(id, shards[1], now.Add(groupTimeout)); ok {
+		t.Fatal("announcement not recovered within groupTimeout was recovered")
+	}
+}
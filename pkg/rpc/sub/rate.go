@@ -0,0 +1,97 @@
+package sub
+// Adapting the redundancy of the FEC code of each session to the loss measured on its link
+var (
+	// codeRates are the codes a session can send with, from the least to the most redundant. The less redundant codes have larger shards so a group still carries as much data, and the most redundant ones carry less so every shard fits in a datagram.
+	codeRates = func() (codes []*fecCode) {
+		for _, r := range [][3]int{
+			{6, 8, 512},
+			{4, 8, 768},
+			{3, 9, 1024},
+			{2, 8, 1024},
+			{2, 12, 1024},
+		} {
+			c, err := newFECCode(r[0], r[1], r[2])
+			if err != nil {
+				panic(err)
+			}
+			codes = append(codes, c)
+		}
+		return
+	}()
+	// defaultCodeRate is the index of the code sessions start with, which is the same as rsEncode.
+	defaultCodeRate = 2
+	// adaptEvery is the number of groups acknowledged between changes of the code.
+	adaptEvery = 16
+	// failWeight is the weight of each group in the moving average of the failure rate.
+	failWeight = 1.0 / 16
+	// raiseAbove is the failure rate above which a more redundant code is used, and lowerBelow the rate below which a less redundant one is.
+	raiseAbove = 0.1
+	lowerBelow = 0.01
+)
+// LinkStats describes the FEC code a session sends with and the loss measured on its link.
+type LinkStats struct {
+	// Required and Total are the number of shards that recover a group and that are sent for it.
+	Required int
+	Total    int
+	// ShardSize is the most data in a shard.
+	ShardSize int
+	// FailureRate is the moving average of the share of groups that were not recovered from the shards first sent and had to be sent again.
+	FailureRate float64
+}
+// linkRate chooses the code a session sends with from the failure rate of its groups.
+type linkRate struct {
+	level    int
+	failRate float64
+	groups   int
+}
+// newLinkRate returns a link rate starting with the default code.
+func newLinkRate() linkRate {
+	return linkRate{level: defaultCodeRate}
+}
+// code returns the code to send the next group with.
+func (r *linkRate) code() *fecCode {
+	return codeRates[r.level]
+}
+// record adds whether an acknowledged group had to be sent again to the failure rate, and every adaptEvery groups moves to a more redundant code if too many failed or to a less redundant one if almost none did. The rate restarts between the thresholds after a change so the new code is measured before the next.
+func (r *linkRate) record(
+	failed bool) {
+	var f float64
+	if failed {
+		f = 1
+	}
+	r.failRate += (f - r.failRate) * failWeight
+	r.groups++
+	if r.groups < adaptEvery {
+		return
+	}
+	r.groups = 0
+	switch {
+	case r.failRate > raiseAbove && r.level < len(codeRates)-1:
+		r.level++
+	case r.failRate < lowerBelow && r.level > 0:
+		r.level--
+	default:
+		return
+	}
+	r.failRate = (raiseAbove + lowerBelow) / 2
+}
+// stats returns the code in use and the failure rate.
+func (r *linkRate) stats() LinkStats {
+	c := r.code()
+	return LinkStats{
+		Required:    c.required,
+		Total:       c.total,
+		ShardSize:   c.shardSize,
+		FailureRate: r.failRate,
+	}
+}
+// findCode returns the code of codeRates with the passed numbers of required and total shards, or nil if there is none.
+func findCode(
+	required, total byte) *fecCode {
+	for _, c := range codeRates {
+		if c.required == int(required) && c.total == int(total) {
+			return c
+		}
+	}
+	return nil
+}
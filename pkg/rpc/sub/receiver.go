@@ -0,0 +1,116 @@
+package sub
+// Receive side assembly of FEC groups shared by sessions, streams and announcements: shards are grouped by their sender, the id of their session or stream and their sequence number, shards already received or failing their checksum are dropped, groups are decoded once they have enough shards, and groups that do not complete in time are expired
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"time"
+)
+var (
+	// groupTimeout is how long a receiver waits for a group to complete after its first shard arrives, and remembers a group after it completes to recognise its late shards. Sessions send a group again well before it expires, and its shards start a new group once it has.
+	groupTimeout = time.Second * 5
+)
+// ReceiverStats counts what became of the shards and groups received.
+type ReceiverStats struct {
+	// Shards is the number of shards received, of which Duplicates were already received, Corrupt failed their checksum and Late arrived for groups that were already recovered.
+	Shards     uint64
+	Duplicates uint64
+	Corrupt    uint64
+	Late       uint64
+	// Recovered is the number of groups decoded, of which Reconstructed were missing data shards and were rebuilt from parity shards.
+	Recovered     uint64
+	Reconstructed uint64
+	// Expired is the number of groups dropped because they did not complete in time.
+	Expired uint64
+}
+// groupID identifies a group by its sender, the id of its session or stream and its sequence number.
+type groupID struct {
+	sender string
+	id     uint32
+	seq    uint32
+}
+// receiver assembles groups from their shards.
+type receiver struct {
+	timeout    time.Duration
+	groups     map[groupID]*recvGroup
+	done       map[groupID]time.Time
+	lastExpire time.Time
+	stats      ReceiverStats
+}
+// newReceiver returns a receiver expiring groups after the passed timeout.
+func newReceiver(
+	timeout time.Duration) *receiver {
+	return &receiver{
+		timeout: timeout,
+		groups:  make(map[groupID]*recvGroup),
+		done:    make(map[groupID]time.Time),
+	}
+}
+// add adds a shard of the passed code to its group, returning the data of the group when the shard completes it. Shards of groups that are complete, duplicates, shards failing their checksum and shards of a different code than the rest of their group are dropped.
+func (r *receiver) add(
+	id groupID, code *fecCode, shard []byte, now time.Time) (data []byte, ok bool) {
+	r.stats.Shards++
+	if _, done := r.done[id]; done {
+		r.stats.Late++
+		return
+	}
+	if !checkShard(shard) || int(shard[0]) >= code.total {
+		r.stats.Corrupt++
+		return
+	}
+	g, exists := r.groups[id]
+	if !exists {
+		g = newRecvGroup(code)
+		g.started = now
+		r.groups[id] = g
+	}
+	if g.code != code {
+		return
+	}
+	if _, seen := g.seen[shard[0]]; seen {
+		r.stats.Duplicates++
+		return
+	}
+	if data, ok = g.add(shard); !ok {
+		return
+	}
+	r.stats.Recovered++
+	for i := 0; i < code.required; i++ {
+		if _, seen := g.seen[byte(i)]; !seen {
+			r.stats.Reconstructed++
+			break
+		}
+	}
+	delete(r.groups, id)
+	r.done[id] = now
+	return
+}
+// expire drops the groups that have not completed within the timeout, and forgets the groups that completed longer ago than it, returning the groups dropped. It only looks at the groups once a quarter of the timeout has passed since it last did, so it can be called before adding every shard.
+func (r *receiver) expire(
+	now time.Time) (expired []groupID) {
+	if now.Sub(r.lastExpire) < r.timeout/4 {
+		return
+	}
+	r.lastExpire = now
+	for id, g := range r.groups {
+		if now.Sub(g.started) >= r.timeout {
+			delete(r.groups, id)
+			r.stats.Expired++
+			expired = append(expired, id)
+		}
+	}
+	for id, t := range r.done {
+		if now.Sub(t) >= r.timeout {
+			delete(r.done, id)
+		}
+	}
+	return
+}
+// checkShard returns whether a shard made by encode holds a shard number and data and matches its checksum.
+func checkShard(
+	shard []byte) bool {
+	if len(shard) < 1+4 {
+		return false
+	}
+	body := shard[:len(shard)-4]
+	return crc32.Checksum(body, crcTable) == binary.LittleEndian.Uint32(shard[len(shard)-4:])
+}
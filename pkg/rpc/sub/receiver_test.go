@@ -0,0 +1,62 @@
+package sub
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+// TestReceiver ensures a receiver recovers groups from their data or parity shards, and counts duplicate, corrupt and late shards and expired groups without letting them through.
+func TestReceiver(
+	t *testing.T) {
+	r := newReceiver(time.Second)
+	code := codeRates[defaultCodeRate]
+	now := time.Now()
+	want := bytes.Repeat([]byte("receiver"), 40)
+	shards := code.encode(want)
+	first := groupID{sender: "sender", seq: 1}
+	if _, ok := r.add(first, code, shards[0], now); ok {
+		t.Fatal("group recovered from one shard")
+	}
+	if _, ok := r.add(first, code, shards[0], now); ok {
+		t.Fatal("group recovered from a duplicate shard")
+	}
+	corrupt := append([]byte{}, shards[1]...)
+	corrupt[2] ^= 0xff
+	if _, ok := r.add(first, code, corrupt, now); ok {
+		t.Fatal("group recovered with a corrupt shard")
+	}
+	var got []byte
+	var ok bool
+	// Skip the rest of the data shards so the group is rebuilt from its parity shards.
+	for i := code.required; i < code.total && !ok; i++ {
+		got, ok = r.add(first, code, shards[i], now)
+	}
+	if !ok || !bytes.Equal(got, want) {
+		t.Fatal("group was not recovered from its parity shards")
+	}
+	if _, ok := r.add(first, code, shards[1], now); ok {
+		t.Fatal("group returned twice")
+	}
+	second := groupID{sender: "sender", seq: 2}
+	r.add(second, code, shards[0], now)
+	expired := r.expire(now.Add(time.Second))
+	if len(expired) != 1 || expired[0] != second {
+		t.Fatalf("expired %v, expected only the incomplete group", expired)
+	}
+	want = []byte("again")
+	shards = code.encode(want)
+	// Once it is forgotten a group with the same id is assembled again.
+	ok = false
+	for i := 0; i < code.total && !ok; i++ {
+		got, ok = r.add(first, code, shards[i], now.Add(time.Second))
+	}
+	if !ok || !bytes.Equal(got, want) {
+		t.Fatal("group was not recovered again after it was forgotten")
+	}
+	stats := r.stats
+	if stats.Duplicates != 1 || stats.Corrupt != 1 || stats.Late != 1 || stats.Expired != 1 {
+		t.Fatalf("unexpected counts %+v", stats)
+	}
+	if stats.Recovered != 2 || stats.Reconstructed != 1 {
+		t.Fatalf("unexpected counts of groups %+v", stats)
+	}
+}
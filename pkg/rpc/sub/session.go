@@ -0,0 +1,668 @@
+package sub
+// Reliable sessions over the FEC coded UDP transport, with connection establishment, sequencing, acknowledgement of message groups and retransmission of groups that could not be recovered
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+)
+// The types of session datagrams, and of the datagrams of announcements and streams.
+const (
+	pktSyn byte = iota + 1
+	pktSynAck
+	pktData
+	pktAck
+	pktFin
+	pktAnnounce
+	pktStream
+)
+var (
+	// sessionHeader is the length of the type, session id and group sequence number at the start of a session datagram, which ends with a checksum of the rest.
+	sessionHeader = 9
+	// sessionWindow is the most groups a session sends before they are acknowledged, and the furthest ahead of the next group to read a received group can be.
+	sessionWindow = 64
+	// sessionRetries is how many times a group is sent again before the peer is given up on.
+	sessionRetries = 8
+	// finCopies is how many times the end of a session is sent, as it is not acknowledged.
+	finCopies = 4
+	// sessionBacklog is the number of new sessions a listener holds for Accept.
+	sessionBacklog = 16
+	// minRTO and maxRTO bound the time to wait for a group to be acknowledged before sending it again.
+	minRTO = time.Millisecond * 50
+	maxRTO = time.Second * 2
+	// crcTable is the table of the checksum of session datagrams.
+	crcTable = crc32.MakeTable(crc32.Castagnoli)
+	// ErrSessionClosed is returned when using a session or listener that is closed.
+	ErrSessionClosed = errors.New("session closed")
+	// ErrPeerGone is returned when a session is closed because the peer stopped acknowledging groups.
+	ErrPeerGone = errors.New("session peer stopped acknowledging")
+)
+// timeoutError is returned when a deadline of a session passes.
+type timeoutError struct{}
+func (timeoutError) Error() string   { return "session i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+var (
+	_ net.Conn     = (*Session)(nil)
+	_ net.Listener = (*Listener)(nil)
+)
+// Session is a reliable, ordered byte stream between two endpoints over UDP. Writes are split into FEC groups, each sent as several shards of which fewer recover it, so most losses cost no round trip. The code is chosen for each group from the failure rate of the link, see linkRate. The receiver acknowledges every group it recovers and the sender sends again the groups that are not acknowledged in time. It implements net.Conn so it can replace a TCP connection.
+type Session struct {
+	sync.Mutex
+	id      uint32
+	conn    net.PacketConn
+	remote  net.Addr
+	onClose func()
+	// Sending state.
+	nextSeq uint32
+	unacked map[uint32]*sentGroup
+	srtt    time.Duration
+	rto     time.Duration
+	rate    linkRate
+	mtu     int
+	// Receiving state.
+	nextRead uint32
+	receiver *receiver
+	ready    map[uint32][]byte
+	readBuf  []byte
+	// Deadlines, signals and the reason the session ended.
+	readDeadline  time.Time
+	writeDeadline time.Time
+	established   chan struct{}
+	readable      chan struct{}
+	writable      chan struct{}
+	closed        chan struct{}
+	remoteClosed  bool
+	err           error
+}
+// sentGroup is a group that is not acknowledged yet.
+type sentGroup struct {
+	// shards are the payloads of the data datagrams, each a shard preceded by the required and total number of shards of its code.
+	shards [][]byte
+	sent   time.Time
+	tries  int
+}
+// recvGroup is a group that does not have enough shards to be recovered yet.
+type recvGroup struct {
+	code    *fecCode
+	shards  [][]byte
+	seen    map[byte]struct{}
+	started time.Time
+}
+// newRecvGroup returns an empty group of the passed code.
+func newRecvGroup(
+	code *fecCode) *recvGroup {
+	return &recvGroup{code: code, seen: make(map[byte]struct{})}
+}
+// add adds a shard the receiver has checked to the group, and returns the data of the group once enough shards recover it.
+func (g *recvGroup) add(
+	shard []byte) (data []byte, ok bool) {
+	g.seen[shard[0]] = struct{}{}
+	g.shards = append(g.shards, append([]byte(nil), shard...))
+	if len(g.shards) < g.code.required {
+		return
+	}
+	padded, err := g.code.decode(g.shards)
+	if err != nil {
+		// Wait for more shards.
+		return
+	}
+	return unpad(padded)
+}
+// newSession returns a session with the peer at the passed address, sending on the passed connection.
+func newSession(
+	conn net.PacketConn, remote net.Addr, id uint32) *Session {
+	s := &Session{
+		id:          id,
+		conn:        conn,
+		remote:      remote,
+		unacked:     make(map[uint32]*sentGroup),
+		rto:         latencyMax,
+		rate:        newLinkRate(),
+		mtu:         pathMTU(conn, remote),
+		receiver:    newReceiver(groupTimeout),
+		ready:       make(map[uint32][]byte),
+		established: make(chan struct{}),
+		readable:    make(chan struct{}, 1),
+		writable:    make(chan struct{}, 1),
+		closed:      make(chan struct{}),
+	}
+	go s.resend()
+	return s
+}
+// DialSession opens a session with the listener at the passed address, returning an error if it does not answer before the timeout.
+func DialSession(
+	address string, timeout time.Duration) (*Session, error) {
+	raddr, err := net.ResolveUDPAddr(uNet, address)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.ListenUDP(uNet, nil)
+	if err != nil {
+		return nil, err
+	}
+	return dialSession(conn, raddr, timeout)
+}
+// dialSession opens a session with the listener at the passed address over a connection of its own, which is closed with the session.
+func dialSession(
+	conn net.PacketConn, raddr net.Addr, timeout time.Duration) (*Session, error) {
+	s := newSession(conn, raddr, rand.Uint32())
+	s.onClose = func() {
+		conn.Close()
+	}
+	go func() {
+		buf := make([]byte, defaultBufferSize)
+		for {
+			n, from, err := conn.ReadFrom(buf)
+			if err != nil {
+				s.close(ErrSessionClosed)
+				return
+			}
+			if from.String() != raddr.String() {
+				continue
+			}
+			typ, id, seq, payload, ok := parseDatagram(buf[:n])
+			if !ok || id != s.id {
+				continue
+			}
+			s.handle(typ, seq, payload)
+		}
+	}()
+	deadline := time.Now().Add(timeout)
+	retry := latencyMax
+	for {
+		s.send(pktSyn, 0, nil)
+		left := time.Until(deadline)
+		if left <= 0 {
+			s.close(ErrSessionClosed)
+			return nil, timeoutError{}
+		}
+		if retry > left {
+			retry = left
+		}
+		select {
+		case <-s.established:
+			return s, nil
+		case <-time.After(retry):
+		}
+		retry *= 2
+	}
+}
+// Read reads data received in the session, in the order it was written by the peer. It returns io.EOF once the peer has closed the session and all its data is read.
+func (s *Session) Read(b []byte) (n int, err error) {
+	for {
+		s.Lock()
+		if len(s.readBuf) > 0 {
+			n = copy(b, s.readBuf)
+			s.readBuf = s.readBuf[n:]
+			if len(s.readBuf) > 0 {
+				signal(s.readable)
+			}
+			s.Unlock()
+			return
+		}
+		if s.isClosed() {
+			err = s.err
+			s.Unlock()
+			return
+		}
+		if s.remoteClosed {
+			s.Unlock()
+			return 0, io.EOF
+		}
+		deadline := s.readDeadline
+		s.Unlock()
+		if err = wait(s.readable, s.closed, deadline); err != nil {
+			return
+		}
+	}
+}
+// Write sends the data to the peer in groups of up to the payload of the current code, limited so each shard fits a datagram within the MTU of the path. It blocks while sessionWindow groups are waiting to be acknowledged, and returns once the data is sent, not acknowledged.
+func (s *Session) Write(b []byte) (n int, err error) {
+	for n < len(b) {
+		s.Lock()
+		if s.isClosed() {
+			err = s.err
+			s.Unlock()
+			return
+		}
+		if s.remoteClosed {
+			s.Unlock()
+			return n, io.ErrClosedPipe
+		}
+		if len(s.unacked) >= sessionWindow {
+			deadline := s.writeDeadline
+			s.Unlock()
+			if err = wait(s.writable, s.closed, deadline); err != nil {
+				return
+			}
+			continue
+		}
+		code := s.rate.code()
+		end := n + code.payloadFor(MaxShardSize(s.mtu))
+		if end > len(b) {
+			end = len(b)
+		}
+		g := &sentGroup{shards: code.encode(b[n:end]), sent: time.Now(), tries: 1}
+		for i := range g.shards {
+			g.shards[i] = append([]byte{byte(code.required), byte(code.total)},
+				g.shards[i]...)
+		}
+		s.unacked[s.nextSeq] = g
+		s.sendGroup(s.nextSeq, g)
+		s.nextSeq++
+		if len(s.unacked) < sessionWindow {
+			signal(s.writable)
+		}
+		s.Unlock()
+		n = end
+	}
+	return
+}
+// Close waits for the data written to be acknowledged, or for the peer to be given up on, and ends the session. It returns the reason the session ended if it ended before.
+func (s *Session) Close() error {
+	for {
+		s.Lock()
+		done := len(s.unacked) == 0 || s.remoteClosed || s.isClosed()
+		s.Unlock()
+		if done {
+			break
+		}
+		wait(s.writable, s.closed, time.Time{})
+	}
+	if err := s.close(nil); err != nil {
+		// The session ended before, for the reason it holds.
+		s.Lock()
+		defer s.Unlock()
+		return s.err
+	}
+	return nil
+}
+// Link returns the code the session sends with and the failure rate of its link.
+func (s *Session) Link() LinkStats {
+	s.Lock()
+	defer s.Unlock()
+	return s.rate.stats()
+}
+// Received returns the counts of the shards and groups received in the session.
+func (s *Session) Received() ReceiverStats {
+	s.Lock()
+	defer s.Unlock()
+	return s.receiver.stats
+}
+// SetMTU sets the MTU of the path to the peer, such as when path MTU discovery learns it has changed, so later groups are sized for it.
+func (s *Session) SetMTU(mtu int) {
+	s.Lock()
+	defer s.Unlock()
+	s.mtu = mtu
+}
+// LocalAddr returns the local address of the session.
+func (s *Session) LocalAddr() net.Addr {
+	return s.conn.LocalAddr()
+}
+// RemoteAddr returns the address of the peer.
+func (s *Session) RemoteAddr() net.Addr {
+	return s.remote
+}
+// SetDeadline sets the time reads and writes fail with a timeout.
+func (s *Session) SetDeadline(t time.Time) error {
+	s.SetReadDeadline(t)
+	return s.SetWriteDeadline(t)
+}
+// SetReadDeadline sets the time reads fail with a timeout, or none if zero.
+func (s *Session) SetReadDeadline(t time.Time) error {
+	s.Lock()
+	s.readDeadline = t
+	s.Unlock()
+	signal(s.readable)
+	return nil
+}
+// SetWriteDeadline sets the time writes fail with a timeout, or none if zero.
+func (s *Session) SetWriteDeadline(t time.Time) error {
+	s.Lock()
+	s.writeDeadline = t
+	s.Unlock()
+	signal(s.writable)
+	return nil
+}
+// close ends the session for the passed reason, telling the peer when it is closed locally. Closing a session that is already closed returns ErrSessionClosed.
+func (s *Session) close(reason error) error {
+	s.Lock()
+	if s.isClosed() {
+		s.Unlock()
+		return ErrSessionClosed
+	}
+	if reason == nil {
+		reason = ErrSessionClosed
+		for i := 0; i < finCopies; i++ {
+			s.send(pktFin, 0, nil)
+		}
+	}
+	s.err = reason
+	close(s.closed)
+	onClose := s.onClose
+	s.Unlock()
+	if onClose != nil {
+		onClose()
+	}
+	return nil
+}
+// isClosed returns whether the session has ended. It must be called with the lock held.
+func (s *Session) isClosed() bool {
+	select {
+	case <-s.closed:
+		return true
+	default:
+		return false
+	}
+}
+// handle processes a datagram of the session.
+func (s *Session) handle(
+	typ byte, seq uint32, payload []byte) {
+	s.Lock()
+	defer s.Unlock()
+	if s.isClosed() {
+		return
+	}
+	switch typ {
+	case pktSyn:
+		// The acknowledgement of the session was lost.
+		s.send(pktSynAck, 0, nil)
+	case pktSynAck:
+		select {
+		case <-s.established:
+		default:
+			close(s.established)
+		}
+	case pktData:
+		s.receiveShard(seq, payload)
+	case pktAck:
+		s.acknowledged(seq)
+	case pktFin:
+		s.remoteClosed = true
+		signal(s.readable)
+		signal(s.writable)
+	}
+}
+// receiveShard adds a shard to its group, and once the group is recovered acknowledges it and makes the groups that are next in order readable. It must be called with the lock held.
+func (s *Session) receiveShard(
+	seq uint32, payload []byte) {
+	// A group that was already recovered is sent again when its acknowledgement is lost.
+	if _, ok := s.ready[seq]; ok || seq < s.nextRead {
+		s.send(pktAck, seq, nil)
+		return
+	}
+	if seq-s.nextRead >= uint32(sessionWindow) || len(payload) < 2 {
+		return
+	}
+	code := findCode(payload[0], payload[1])
+	if code == nil {
+		return
+	}
+	now := time.Now()
+	s.receiver.expire(now)
+	data, ok := s.receiver.add(groupID{seq: seq}, code, payload[2:], now)
+	if !ok {
+		return
+	}
+	s.ready[seq] = data
+	s.send(pktAck, seq, nil)
+	for {
+		msg, ok := s.ready[s.nextRead]
+		if !ok {
+			break
+		}
+		s.readBuf = append(s.readBuf, msg...)
+		delete(s.ready, s.nextRead)
+		s.nextRead++
+	}
+	if len(s.readBuf) > 0 {
+		signal(s.readable)
+	}
+}
+// acknowledged removes an acknowledged group from those waiting, measures the round trip time from groups that were sent once and records whether the group had to be sent again to adapt the code. It must be called with the lock held.
+func (s *Session) acknowledged(
+	seq uint32) {
+	g, ok := s.unacked[seq]
+	if !ok {
+		return
+	}
+	if g.tries == 1 {
+		rtt := time.Since(g.sent)
+		if s.srtt == 0 {
+			s.srtt = rtt
+		} else {
+			s.srtt = (7*s.srtt + rtt) / 8
+		}
+		s.rto = 2 * s.srtt
+		if s.rto < minRTO {
+			s.rto = minRTO
+		}
+		if s.rto > maxRTO {
+			s.rto = maxRTO
+		}
+	}
+	s.rate.record(g.tries > 1)
+	delete(s.unacked, seq)
+	signal(s.writable)
+}
+// resend sends the groups that are not acknowledged in time again, backing off for each try, until the session ends or the peer is given up on.
+func (s *Session) resend() {
+	ticker := time.NewTicker(minRTO)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.closed:
+			return
+		case now := <-ticker.C:
+			if err := s.resendDue(now); err != nil {
+				s.close(err)
+				return
+			}
+		}
+	}
+}
+// resendDue sends the groups whose acknowledgement is overdue again, returning ErrPeerGone when one has been sent too many times.
+func (s *Session) resendDue(
+	now time.Time) error {
+	s.Lock()
+	defer s.Unlock()
+	if s.remoteClosed {
+		return nil
+	}
+	for seq, g := range s.unacked {
+		rto := s.rto << uint(g.tries-1)
+		if rto > maxRTO {
+			rto = maxRTO
+		}
+		if now.Sub(g.sent) < rto {
+			continue
+		}
+		if g.tries > sessionRetries {
+			return ErrPeerGone
+		}
+		g.tries++
+		g.sent = now
+		s.sendGroup(seq, g)
+	}
+	return nil
+}
+// sendGroup sends all the shards of a group.
+func (s *Session) sendGroup(
+	seq uint32, g *sentGroup) {
+	for _, shard := range g.shards {
+		s.send(pktData, seq, shard)
+	}
+}
+// send sends a datagram to the peer. Errors are ignored as the datagram counts as lost.
+func (s *Session) send(
+	typ byte, seq uint32, payload []byte) {
+	s.conn.WriteTo(marshalDatagram(typ, s.id, seq, payload), s.remote)
+}
+// Listener accepts sessions dialed to a UDP address. It implements net.Listener.
+type Listener struct {
+	sync.Mutex
+	conn     net.PacketConn
+	sessions map[string]*Session
+	accept   chan *Session
+	closed   chan struct{}
+}
+// ListenSession returns a listener for sessions on the passed address.
+func ListenSession(
+	address string) (*Listener, error) {
+	addr, err := net.ResolveUDPAddr(uNet, address)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.ListenUDP(uNet, addr)
+	if err != nil {
+		return nil, err
+	}
+	return newListener(conn), nil
+}
+// newListener returns a listener for sessions on the passed connection.
+func newListener(
+	conn net.PacketConn) *Listener {
+	l := &Listener{
+		conn:     conn,
+		sessions: make(map[string]*Session),
+		accept:   make(chan *Session, sessionBacklog),
+		closed:   make(chan struct{}),
+	}
+	go l.read()
+	return l
+}
+// Accept waits for and returns the next session.
+func (l *Listener) Accept() (net.Conn, error) {
+	select {
+	case s := <-l.accept:
+		return s, nil
+	case <-l.closed:
+		return nil, ErrSessionClosed
+	}
+}
+// Close stops the listener and ends its sessions.
+func (l *Listener) Close() error {
+	l.Lock()
+	select {
+	case <-l.closed:
+		l.Unlock()
+		return ErrSessionClosed
+	default:
+	}
+	close(l.closed)
+	sessions := make([]*Session, 0, len(l.sessions))
+	for _, s := range l.sessions {
+		sessions = append(sessions, s)
+	}
+	l.Unlock()
+	for _, s := range sessions {
+		s.close(nil)
+	}
+	return l.conn.Close()
+}
+// Addr returns the address the listener receives on.
+func (l *Listener) Addr() net.Addr {
+	return l.conn.LocalAddr()
+}
+// read passes the datagrams received to their sessions, starting a session for every new session id and address.
+func (l *Listener) read() {
+	buf := make([]byte, defaultBufferSize)
+	for {
+		n, from, err := l.conn.ReadFrom(buf)
+		if err != nil {
+			l.Close()
+			return
+		}
+		typ, id, seq, payload, ok := parseDatagram(buf[:n])
+		if !ok {
+			continue
+		}
+		key := fmt.Sprintf("%s/%d", from, id)
+		l.Lock()
+		s, ok := l.sessions[key]
+		if !ok {
+			if typ != pktSyn {
+				l.Unlock()
+				continue
+			}
+			s = newSession(l.conn, from, id)
+			s.onClose = func() {
+				l.Lock()
+				delete(l.sessions, key)
+				l.Unlock()
+			}
+			l.sessions[key] = s
+			l.Unlock()
+			select {
+			case l.accept <- s:
+				s.send(pktSynAck, 0, nil)
+			default:
+				// The backlog is full, so the session is refused.
+				s.close(nil)
+			}
+			continue
+		}
+		l.Unlock()
+		s.handle(typ, seq, payload)
+	}
+}
+// marshalDatagram returns a session datagram with the passed header and payload followed by its checksum.
+func marshalDatagram(
+	typ byte, id, seq uint32, payload []byte) []byte {
+	d := make([]byte, sessionHeader, sessionHeader+len(payload)+4)
+	d[0] = typ
+	binary.LittleEndian.PutUint32(d[1:5], id)
+	binary.LittleEndian.PutUint32(d[5:9], seq)
+	d = append(d, payload...)
+	check := make([]byte, 4)
+	binary.LittleEndian.PutUint32(check, crc32.Checksum(d, crcTable))
+	return append(d, check...)
+}
+// parseDatagram returns the header and payload of a session datagram, which are not valid unless ok is true. The payload shares the memory of the datagram.
+func parseDatagram(
+	d []byte) (typ byte, id, seq uint32, payload []byte, ok bool) {
+	if len(d) < sessionHeader+4 {
+		return
+	}
+	body := d[:len(d)-4]
+	if crc32.Checksum(body, crcTable) != binary.LittleEndian.Uint32(d[len(d)-4:]) {
+		return
+	}
+	return body[0], binary.LittleEndian.Uint32(body[1:5]),
+		binary.LittleEndian.Uint32(body[5:9]), body[sessionHeader:], true
+}
+// signal wakes a goroutine waiting on the passed channel, which must have a buffer of one, without blocking.
+func signal(
+	c chan struct{}) {
+	select {
+	case c <- struct{}{}:
+	default:
+	}
+}
+// wait waits for a signal on the ready channel or the session to end, returning a timeout error if the deadline passes first.
+func wait(
+	ready, closed <-chan struct{}, deadline time.Time) error {
+	var timeout <-chan time.Time
+	if !deadline.IsZero() {
+		d := time.Until(deadline)
+		if d <= 0 {
+			return timeoutError{}
+		}
+		t := time.NewTimer(d)
+		defer t.Stop()
+		timeout = t.C
+	}
+	select {
+	case <-ready:
+	case <-closed:
+	case <-timeout:
+		return timeoutError{}
+	}
+	return nil
+}
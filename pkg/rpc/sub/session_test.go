@@ -0,0 +1,123 @@
+package sub
+import (
+	"bytes"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+// lossyConn drops all but one in every keep datagrams written on it.
+type lossyConn struct {
+	net.PacketConn
+	sync.Mutex
+	keep  int
+	count int
+}
+func (c *lossyConn) WriteTo(
+	b []byte, addr net.Addr) (int, error) {
+	c.Lock()
+	c.count++
+	drop := c.count%c.keep != 1
+	c.Unlock()
+	if drop {
+		return len(b), nil
+	}
+	return c.PacketConn.WriteTo(b, addr)
+}
+// testSession sends data larger than several groups from a dialed session to an accepted one, with the dialer keeping one in every keep datagrams it sends, and ensures it arrives whole and in order and the end of the session is seen.
+func testSession(
+	t *testing.T, keep int) {
+	l, err := ListenSession("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("ListenSession: unexpected error: %v", err)
+	}
+	defer l.Close()
+	conn, err := net.ListenUDP(uNet, nil)
+	if err != nil {
+		t.Fatalf("ListenUDP: unexpected error: %v", err)
+	}
+	var pc net.PacketConn = conn
+	if keep > 1 {
+		pc = &lossyConn{PacketConn: conn, keep: keep}
+	}
+	dialed, err := dialSession(pc, l.Addr(), time.Second*10)
+	if err != nil {
+		t.Fatalf("dialSession: unexpected error: %v", err)
+	}
+	accepted, err := l.Accept()
+	if err != nil {
+		t.Fatalf("Accept: unexpected error: %v", err)
+	}
+	data := make([]byte, codeRates[defaultCodeRate].payload()*5+123)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+	go func() {
+		if _, err := dialed.Write(data); err != nil {
+			t.Errorf("Write: unexpected error: %v", err)
+		}
+		if err := dialed.Close(); err != nil {
+			t.Errorf("Close: unexpected error: %v", err)
+		}
+	}()
+	accepted.SetReadDeadline(time.Now().Add(time.Second * 20))
+	got := make([]byte, len(data))
+	if _, err := io.ReadFull(accepted, got); err != nil {
+		t.Fatalf("ReadFull: unexpected error: %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Fatal("data read is not the data written")
+	}
+	if n, err := accepted.Read(got); err != io.EOF {
+		t.Fatalf("Read after the peer closed: got %d bytes and error %v, want io.EOF", n, err)
+	}
+	accepted.Close()
+}
+// TestSession ensures data is delivered over a session without loss, with more loss than the FEC groups recover which needs them sent again, and that reads time out.
+func TestSession(
+	t *testing.T) {
+	testSession(t, 1)
+	// Keeping one of every four datagrams leaves two or three shards of a group, so most groups are only recovered after being sent again.
+	testSession(t, 4)
+	l, err := ListenSession("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("ListenSession: unexpected error: %v", err)
+	}
+	defer l.Close()
+	s, err := DialSession(l.Addr().String(), time.Second*5)
+	if err != nil {
+		t.Fatalf("DialSession: unexpected error: %v", err)
+	}
+	defer s.Close()
+	s.SetReadDeadline(time.Now().Add(time.Millisecond * 50))
+	if _, err := s.Read(make([]byte, 1)); err == nil {
+		t.Fatal("Read: expected a timeout with nothing sent")
+	} else if ne, ok := err.(net.Error); !ok || !ne.Timeout() {
+		t.Fatalf("Read: expected a timeout error, got %v", err)
+	}
+}
+// TestLinkRate ensures the code becomes more redundant while groups keep failing and less redundant while they do not, staying within the codes.
+func TestLinkRate(
+	t *testing.T) {
+	r := newLinkRate()
+	if r.code() != codeRates[defaultCodeRate] {
+		t.Fatal("link rate does not start with the default code")
+	}
+	for i := 0; i < adaptEvery*len(codeRates)*2; i++ {
+		r.record(true)
+	}
+	if r.level != len(codeRates)-1 {
+		t.Fatalf("code is %d after failures, want the most redundant %d", r.level, len(codeRates)-1)
+	}
+	for i := 0; i < adaptEvery*len(codeRates)*4; i++ {
+		r.record(false)
+	}
+	if r.level != 0 {
+		t.Fatalf("code is %d without failures, want the least redundant 0", r.level)
+	}
+	if st := r.stats(); st.Required != codeRates[0].required || st.Total != codeRates[0].total {
+		t.Fatalf("stats show code %d/%d, want %d/%d", st.Required, st.Total,
+			codeRates[0].required, codeRates[0].total)
+	}
+}
@@ -0,0 +1,221 @@
+package sub
+// Byte streams of any length over the FEC coded UDP transport without acknowledgement, for pushing payloads larger than one message, such as full block templates or configuration, to one receiver or a multicast group
+import (
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+)
+const (
+	// streamEnd marks the last group of a stream, which carries no data.
+	streamEnd byte = 1
+)
+var (
+	// streamWindow is the most groups a stream reader holds waiting for an earlier group before the earlier one is given up as lost.
+	streamWindow = uint32(64)
+	// ErrStreamGap is returned by a stream reader when a group of the stream was lost beyond what its code recovers. Streams are not acknowledged, so the data can not be sent again.
+	ErrStreamGap = errors.New("stream group lost")
+	// ErrStreamClosed is returned when writing to a stream writer that is closed.
+	ErrStreamClosed = errors.New("stream closed")
+)
+// StreamWriter sends the data written to it as a stream of FEC groups to an address, which may be a multicast group. Groups are sized so each shard fits a datagram within the MTU of the path. Data is buffered until a whole group is written, or it is flushed or the writer closed. Each group is sent once with the default code of sessions, carrying its code like the groups of sessions, so receivers recover it from some of its shards.
+type StreamWriter struct {
+	sync.Mutex
+	conn   net.PacketConn
+	addr   net.Addr
+	id     uint32
+	seq    uint32
+	code   *fecCode
+	mtu    int
+	buf    []byte
+	closed bool
+}
+// NewStreamWriter returns a writer sending a new stream on the passed connection to the passed address.
+func NewStreamWriter(
+	conn net.PacketConn, addr net.Addr) *StreamWriter {
+	return &StreamWriter{
+		conn: conn,
+		addr: addr,
+		id:   rand.Uint32(),
+		code: codeRates[defaultCodeRate],
+		mtu:  pathMTU(conn, addr),
+	}
+}
+// SetMTU sets the MTU of the path to the receiver, so the groups sent after it are sized for it.
+func (w *StreamWriter) SetMTU(mtu int) {
+	w.Lock()
+	defer w.Unlock()
+	w.mtu = mtu
+}
+// Write buffers the data and sends every whole group of it.
+func (w *StreamWriter) Write(b []byte) (n int, err error) {
+	w.Lock()
+	defer w.Unlock()
+	if w.closed {
+		return 0, ErrStreamClosed
+	}
+	w.buf = append(w.buf, b...)
+	size := w.code.payloadFor(MaxShardSize(w.mtu))
+	var groups [][]byte
+	for len(w.buf) >= size {
+		groups = append(groups, w.buf[:size])
+		w.buf = w.buf[size:]
+	}
+	if err = w.sendGroups(0, groups); err != nil {
+		return
+	}
+	return len(b), nil
+}
+// Flush sends the data buffered as groups, without waiting for the last to fill.
+func (w *StreamWriter) Flush() error {
+	w.Lock()
+	defer w.Unlock()
+	return w.flush()
+}
+// flush sends the buffered data. It must be called with the lock held.
+func (w *StreamWriter) flush() error {
+	size := w.code.payloadFor(MaxShardSize(w.mtu))
+	var groups [][]byte
+	for len(w.buf) > 0 {
+		end := size
+		if end > len(w.buf) {
+			end = len(w.buf)
+		}
+		groups = append(groups, w.buf[:end])
+		w.buf = w.buf[end:]
+	}
+	w.buf = nil
+	return w.sendGroups(0, groups)
+}
+// Close sends the data buffered and the end of the stream. The connection is not closed.
+func (w *StreamWriter) Close() error {
+	w.Lock()
+	defer w.Unlock()
+	if w.closed {
+		return ErrStreamClosed
+	}
+	w.closed = true
+	if err := w.flush(); err != nil {
+		return err
+	}
+	return w.sendGroups(streamEnd, [][]byte{nil})
+}
+// sendGroups sends the shards of the next groups of the stream, each preceded by the flags of the group and the required and total number of shards of its code. Shards that fail to send count as lost, and the error of the last is returned. It must be called with the lock held.
+func (w *StreamWriter) sendGroups(
+	flags byte, data [][]byte) (err error) {
+	if len(data) == 0 {
+		return
+	}
+	groups := w.code.encodeBatch(data)
+	defer releaseShards(groups)
+	for _, shards := range groups {
+		seq := w.seq
+		w.seq++
+		for _, shard := range shards {
+			payload := append([]byte{flags, byte(w.code.required), byte(w.code.total)}, shard...)
+			if _, e := w.conn.WriteTo(marshalDatagram(pktStream, w.id, seq, payload), w.addr); e != nil {
+				err = e
+			}
+		}
+	}
+	return
+}
+// StreamReader reads the first stream that arrives on a connection, returning its data in order. Groups that arrive out of order are held until the groups before them are recovered, and the reader fails with ErrStreamGap once one is held back more than streamWindow groups or a group is incomplete after groupTimeout. Reads time out with the read deadline of the connection.
+type StreamReader struct {
+	conn    net.PacketConn
+	from    string
+	id      uint32
+	started bool
+	next    uint32
+	recv    *receiver
+	ready   map[uint32][]byte
+	end     uint32
+	ended   bool
+	buf     []byte
+	err     error
+}
+// NewStreamReader returns a reader of the next stream arriving on the passed connection.
+func NewStreamReader(
+	conn net.PacketConn) *StreamReader {
+	return &StreamReader{
+		conn:  conn,
+		recv:  newReceiver(groupTimeout),
+		ready: make(map[uint32][]byte),
+	}
+}
+// Read reads the data of the stream in order, returning io.EOF once the end of the stream is read.
+func (r *StreamReader) Read(b []byte) (n int, err error) {
+	datagram := make([]byte, defaultBufferSize)
+	for len(r.buf) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		if r.ended && r.next == r.end {
+			return 0, io.EOF
+		}
+		var from net.Addr
+		if n, from, err = r.conn.ReadFrom(datagram); err != nil {
+			return 0, err
+		}
+		typ, id, seq, payload, ok := parseDatagram(datagram[:n])
+		if !ok || typ != pktStream {
+			continue
+		}
+		if !r.started {
+			r.started, r.from, r.id = true, from.String(), id
+		}
+		if id == r.id && from.String() == r.from {
+			r.receive(seq, payload)
+		}
+	}
+	n = copy(b, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+// Received returns the counts of the shards and groups received in the stream.
+func (r *StreamReader) Received() ReceiverStats {
+	return r.recv.stats
+}
+// receive adds a shard to its group, and once the group is recovered adds it and the groups after it that are recovered to the data to read.
+func (r *StreamReader) receive(
+	seq uint32, payload []byte) {
+	if seq < r.next || len(payload) < 3 {
+		return
+	}
+	if _, ok := r.ready[seq]; ok {
+		return
+	}
+	if seq-r.next >= streamWindow {
+		r.err = ErrStreamGap
+		return
+	}
+	code := findCode(payload[1], payload[2])
+	if code == nil {
+		return
+	}
+	now := time.Now()
+	// A group that expires can not complete, as it is not sent again.
+	if len(r.recv.expire(now)) > 0 {
+		r.err = ErrStreamGap
+		return
+	}
+	data, ok := r.recv.add(groupID{seq: seq}, code, payload[3:], now)
+	if !ok {
+		return
+	}
+	if payload[0]&streamEnd != 0 {
+		r.ended, r.end = true, seq
+	}
+	r.ready[seq] = data
+	for {
+		data, ok := r.ready[r.next]
+		if !ok || (r.ended && r.next == r.end) {
+			break
+		}
+		r.buf = append(r.buf, data...)
+		delete(r.ready, r.next)
+		r.next++
+	}
+}
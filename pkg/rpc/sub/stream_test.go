@@ -0,0 +1,53 @@
+package sub
+import (
+	"bytes"
+	"io/ioutil"
+	"net"
+	"testing"
+	"time"
+)
+// TestStream ensures a stream of several groups arrives whole and in order when every other datagram is lost, and that a reader fails rather than waits when a group is lost for good.
+func TestStream(
+	t *testing.T) {
+	in, err := net.ListenUDP(uNet, &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("ListenUDP: unexpected error: %v", err)
+	}
+	defer in.Close()
+	out, err := net.ListenUDP(uNet, nil)
+	if err != nil {
+		t.Fatalf("ListenUDP: unexpected error: %v", err)
+	}
+	defer out.Close()
+	w := NewStreamWriter(&lossyConn{PacketConn: out, keep: 2}, in.LocalAddr())
+	data := make([]byte, codeRates[defaultCodeRate].payload()*3+321)
+	for i := range data {
+		data[i] = byte(i * 13)
+	}
+	if _, err := w.Write(data); err != nil {
+		t.Fatalf("Write: unexpected error: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: unexpected error: %v", err)
+	}
+	if _, err := w.Write(data); err != ErrStreamClosed {
+		t.Fatalf("Write after Close: got %v, want ErrStreamClosed", err)
+	}
+	in.SetReadDeadline(time.Now().Add(time.Second * 5))
+	got, err := ioutil.ReadAll(NewStreamReader(in))
+	if err != nil {
+		t.Fatalf("ReadAll: unexpected error: %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Fatalf("read %d bytes that are not the %d written", len(got), len(data))
+	}
+	// A group arriving a whole window after one that never does means the earlier one is lost.
+	r := NewStreamReader(nil)
+	code := codeRates[defaultCodeRate]
+	for _, shard := range code.encode([]byte("late")) {
+		r.receive(streamWindow, append([]byte{0, byte(code.required), byte(code.total)}, shard...))
+	}
+	if r.err != ErrStreamGap {
+		t.Fatalf("reader error is %v, want ErrStreamGap", r.err)
+	}
+}
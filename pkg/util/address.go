@@ -35,7 +35,7 @@ func encodeAddress(
 	// Format is 1 byte for a network and address class (i.e. P2PKH vs P2SH), 20 bytes for a RIPEMD160 hash, and 4 bytes of checksum.
 	return base58.CheckEncode(hash160[:ripemd160.Size], netID)
 }
-// encodeSegWitAddress creates a bech32 encoded address string representation from witness version and witness program.
+// encodeSegWitAddress creates a bech32 encoded address string representation from witness version and witness program, with the bech32 checksum for version 0 and the bech32m checksum of BIP 350 for later versions.
 func encodeSegWitAddress(
 	hrp string, witnessVersion byte, witnessProgram []byte) (string, error) {
 	// Group the address bytes into 5 bit groups, as this is what is used to encode each character in the address string.
@@ -47,7 +47,11 @@ func encodeSegWitAddress(
 	combined := make([]byte, len(converted)+1)
 	combined[0] = witnessVersion
 	copy(combined[1:], converted)
-	bech, err := bech32.Encode(hrp, combined)
+	encode := bech32.Encode
+	if witnessVersion != 0 {
+		encode = bech32.EncodeM
+	}
+	bech, err := encode(hrp, combined)
 	if err != nil {
 		return "", err
 	}
@@ -86,12 +90,18 @@ func DecodeAddress(
 			if err != nil {
 				return nil, err
 			}
-			// We currently only support P2WPKH and P2WSH, which is witness version 0.
-			if witnessVer != 0 {
+			// We currently support P2WPKH and P2WSH, which are witness version 0, and P2TR, which is witness version 1 with a 32 byte program.
+			if witnessVer > 1 {
 				return nil, UnsupportedWitnessVerError(witnessVer)
 			}
 			// The HRP is everything before the found '1'.
 			hrp := prefix[:len(prefix)-1]
+			if witnessVer == 1 {
+				if len(witnessProg) != 32 {
+					return nil, UnsupportedWitnessProgLenError(len(witnessProg))
+				}
+				return newAddressWitnessTaproot(hrp, witnessProg)
+			}
 			switch len(witnessProg) {
 			case 20:
 				return newAddressWitnessPubKeyHash(hrp, witnessProg)
@@ -136,11 +146,11 @@ func DecodeAddress(
 		return nil, errors.New("decoded address is of unknown size")
 	}
 }
-// decodeSegWitAddress parses a bech32 encoded segwit address string and returns the witness version and witness program byte representation.
+// decodeSegWitAddress parses a bech32 encoded segwit address string and returns the witness version and witness program byte representation. Version 0 addresses must have the bech32 checksum and later versions the bech32m checksum.
 func decodeSegWitAddress(
 	address string) (byte, []byte, error) {
 	// Decode the bech32 encoded address.
-	_, data, err := bech32.Decode(address)
+	_, data, checksum, err := bech32.DecodeGeneric(address)
 	if err != nil {
 		return 0, nil, err
 	}
@@ -153,6 +163,9 @@ func decodeSegWitAddress(
 	if version > 16 {
 		return 0, nil, fmt.Errorf("invalid witness version: %v", version)
 	}
+	if version == 0 && checksum != bech32.Version0 || version != 0 && checksum != bech32.VersionM {
+		return 0, nil, fmt.Errorf("invalid checksum type for witness version %v", version)
+	}
 	// The remaining characters of the address returned are grouped into words of 5 bits. In order to restore the original witness program bytes, we'll need to regroup into 8 bit words.
 	regrouped, err := bech32.ConvertBits(data[1:], 5, 8, false)
 	if err != nil {
@@ -464,3 +477,62 @@ func (a *AddressWitnessScriptHash) WitnessVersion() byte {
 func (a *AddressWitnessScriptHash) WitnessProgram() []byte {
 	return a.witnessProgram[:]
 }
+// AddressWitnessTaproot is an Address for a pay-to-taproot (P2TR) output, which is witness version 1 with a 32 byte x-only public key as its program. See BIP 341 for taproot outputs and BIP 350 for the bech32m encoding of their addresses: https://github.com/bitcoin/bips/blob/master/bip-0350.mediawiki
+type AddressWitnessTaproot struct {
+	hrp            string
+	witnessVersion byte
+	witnessProgram [32]byte
+}
+// NewAddressWitnessTaproot returns a new AddressWitnessTaproot.
+func NewAddressWitnessTaproot(
+	witnessProg []byte, net *chaincfg.Params) (*AddressWitnessTaproot, error) {
+	return newAddressWitnessTaproot(net.Bech32HRPSegwit, witnessProg)
+}
+// newAddressWitnessTaproot is an internal helper function to create an AddressWitnessTaproot with a known human-readable part, rather than looking it up through its parameters.
+func newAddressWitnessTaproot(
+	hrp string, witnessProg []byte) (*AddressWitnessTaproot, error) {
+	// Check for valid program length for witness version 1, which is 32 for P2TR.
+	if len(witnessProg) != 32 {
+		return nil, errors.New("witness program must be 32 " +
+			"bytes for p2tr")
+	}
+	addr := &AddressWitnessTaproot{
+		hrp:            strings.ToLower(hrp),
+		witnessVersion: 0x01,
+	}
+	copy(addr.witnessProgram[:], witnessProg)
+	return addr, nil
+}
+// EncodeAddress returns the bech32m string encoding of an AddressWitnessTaproot. Part of the Address interface.
+func (a *AddressWitnessTaproot) EncodeAddress() string {
+	str, err := encodeSegWitAddress(a.hrp, a.witnessVersion,
+		a.witnessProgram[:])
+	if err != nil {
+		return ""
+	}
+	return str
+}
+// ScriptAddress returns the witness program for this address. Part of the Address interface.
+func (a *AddressWitnessTaproot) ScriptAddress() []byte {
+	return a.witnessProgram[:]
+}
+// IsForNet returns whether or not the AddressWitnessTaproot is associated with the passed bitcoin network. Part of the Address interface.
+func (a *AddressWitnessTaproot) IsForNet(net *chaincfg.Params) bool {
+	return a.hrp == net.Bech32HRPSegwit
+}
+// String returns a human-readable string for the AddressWitnessTaproot. This is equivalent to calling EncodeAddress, but is provided so the type can be used as a fmt.Stringer. Part of the Address interface.
+func (a *AddressWitnessTaproot) String() string {
+	return a.EncodeAddress()
+}
+// Hrp returns the human-readable part of the bech32m encoded AddressWitnessTaproot.
+func (a *AddressWitnessTaproot) Hrp() string {
+	return a.hrp
+}
+// WitnessVersion returns the witness version of the AddressWitnessTaproot.
+func (a *AddressWitnessTaproot) WitnessVersion() byte {
+	return a.witnessVersion
+}
+// WitnessProgram returns the witness program of the AddressWitnessTaproot.
+func (a *AddressWitnessTaproot) WitnessProgram() []byte {
+	return a.witnessProgram[:]
+}
@@ -773,3 +773,107 @@ func TestAddresses(
 		}
 	}
 }
+// TestTaprootAddresses ensures taproot addresses decode from and encode to their bech32m form, that witness version 1 addresses with a bech32 checksum and version 0 addresses with a bech32m checksum are refused, and that the addresses created from output keys match the decoded ones.
+func TestTaprootAddresses(
+	t *testing.T) {
+	mainKey := [32]byte{
+		0x79, 0xbe, 0x66, 0x7e, 0xf9, 0xdc, 0xbb, 0xac,
+		0x55, 0xa0, 0x62, 0x95, 0xce, 0x87, 0x0b, 0x07,
+		0x02, 0x9b, 0xfc, 0xdb, 0x2d, 0xce, 0x28, 0xd9,
+		0x59, 0xf2, 0x81, 0x5b, 0x16, 0xf8, 0x17, 0x98}
+	testKey := [32]byte{
+		0x00, 0x00, 0x00, 0xc4, 0xa5, 0xca, 0xd4, 0x62,
+		0x21, 0xb2, 0xa1, 0x87, 0x90, 0x5e, 0x52, 0x66,
+		0x36, 0x2b, 0x99, 0xd5, 0xe9, 0x1c, 0x6c, 0xe2,
+		0x4d, 0x16, 0x5d, 0xab, 0x93, 0xe8, 0x64, 0x33}
+	tests := []struct {
+		name  string
+		addr  string
+		valid bool
+		key   [32]byte
+		net   *chaincfg.Params
+	}{
+		{
+			name:  "mainnet p2tr",
+			addr:  "bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqzk5jj0",
+			valid: true,
+			key:   mainKey,
+			net:   &chaincfg.MainNetParams,
+		},
+		{
+			name:  "mainnet p2tr upper case",
+			addr:  "BC1P0XLXVLHEMJA6C4DQV22UAPCTQUPFHLXM9H8Z3K2E72Q4K9HCZ7VQZK5JJ0",
+			valid: true,
+			key:   mainKey,
+			net:   &chaincfg.MainNetParams,
+		},
+		{
+			name:  "testnet p2tr",
+			addr:  "tb1pqqqqp399et2xygdj5xreqhjjvcmzhxw4aywxecjdzew6hylgvsesf3hn0c",
+			valid: true,
+			key:   testKey,
+			net:   &chaincfg.TestNet3Params,
+		},
+		{
+			name:  "mainnet witness v1 with bech32 checksum",
+			addr:  "bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqh2y7hd",
+			valid: false,
+			net:   &chaincfg.MainNetParams,
+		},
+		{
+			name:  "mainnet witness v0 with bech32m checksum",
+			addr:  "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kemeawh",
+			valid: false,
+			net:   &chaincfg.MainNetParams,
+		},
+	}
+	for _, test := range tests {
+		decoded, err := util.DecodeAddress(test.addr, test.net)
+		if (err == nil) != test.valid {
+			t.Errorf("%v: decoding test failed: %v", test.name, err)
+			continue
+		}
+		if !test.valid {
+			continue
+		}
+		addr, ok := decoded.(*util.AddressWitnessTaproot)
+		if !ok {
+			t.Errorf("%v: decoded to %T", test.name, decoded)
+			continue
+		}
+		encoded := strings.ToLower(test.addr)
+		if addr.EncodeAddress() != encoded || addr.String() != encoded {
+			t.Errorf("%v: encoded to %v, want %v", test.name,
+				addr.EncodeAddress(), encoded)
+		}
+		if !bytes.Equal(addr.ScriptAddress(), test.key[:]) ||
+			!bytes.Equal(addr.WitnessProgram(), test.key[:]) ||
+			!bytes.Equal(util.TstAddressSegwitSAddr(encoded), test.key[:]) {
+			t.Errorf("%v: witness program %x, want %x", test.name,
+				addr.WitnessProgram(), test.key)
+		}
+		if addr.WitnessVersion() != 1 {
+			t.Errorf("%v: witness version %d, want 1", test.name,
+				addr.WitnessVersion())
+		}
+		if addr.Hrp() != test.net.Bech32HRPSegwit || !addr.IsForNet(test.net) {
+			t.Errorf("%v: address is not for the expected network",
+				test.name)
+		}
+		created, err := util.NewAddressWitnessTaproot(test.key[:], test.net)
+		if err != nil {
+			t.Errorf("%v: creating the address failed: %v", test.name, err)
+			continue
+		}
+		want := util.TstAddressWitnessTaproot(1, test.key,
+			test.net.Bech32HRPSegwit)
+		if !reflect.DeepEqual(created, want) || !reflect.DeepEqual(addr, want) {
+			t.Errorf("%v: created address does not match the decoded one",
+				test.name)
+		}
+	}
+	if _, err := util.NewAddressWitnessTaproot(mainKey[:31],
+		&chaincfg.MainNetParams); err == nil {
+		t.Errorf("created a taproot address from a 31 byte key")
+	}
+}
@@ -0,0 +1,62 @@
+package util
+import (
+	chaincfg "git.parallelcoin.io/dev/9/pkg/chain/config"
+)
+// AddressInfo describes an address string, as InspectAddress finds it.
+type AddressInfo struct {
+	// Address is the decoded address.
+	Address Address
+	// Type is the kind of address: p2pkh, p2sh, p2wpkh, p2wsh, p2tr or p2pk for a public key.
+	Type string
+	// Network is the name of the network the address is for, preferring the default network where several use the same prefix, and empty if it is for none of the default networks.
+	Network string
+	// ScriptClass is the class of the output script paying to the address, named as txscript names them.
+	ScriptClass string
+	// IsWitness is true for segwit addresses, which have a WitnessVersion and WitnessProgram.
+	IsWitness      bool
+	WitnessVersion byte
+	WitnessProgram []byte
+	// Normalized is the address as it is written canonically: bech32 in lower case and public keys in lower case hex in the format they were given in. Other addresses are case sensitive and written as they were.
+	Normalized string
+}
+// defaultNets are the networks InspectAddress looks for an address's network among, after the default network it is given.
+var defaultNets = []*chaincfg.Params{
+	&chaincfg.MainNetParams,
+	&chaincfg.TestNet3Params,
+	&chaincfg.RegressionNetParams,
+	&chaincfg.SimNetParams,
+}
+// InspectAddress decodes any supported address string as DecodeAddress does and describes it, so callers need not switch on the address types themselves. Public keys, which don't encode a network, are taken to be for defaultNet.
+func InspectAddress(
+	addr string, defaultNet *chaincfg.Params) (*AddressInfo, error) {
+	a, err := DecodeAddress(addr, defaultNet)
+	if err != nil {
+		return nil, err
+	}
+	info := &AddressInfo{Address: a, Normalized: a.EncodeAddress()}
+	switch a := a.(type) {
+	case *AddressPubKeyHash:
+		info.Type, info.ScriptClass = "p2pkh", "pubkeyhash"
+	case *AddressScriptHash:
+		info.Type, info.ScriptClass = "p2sh", "scripthash"
+	case *AddressWitnessPubKeyHash:
+		info.Type, info.ScriptClass = "p2wpkh", "witness_v0_keyhash"
+		info.IsWitness, info.WitnessVersion, info.WitnessProgram = true, a.WitnessVersion(), a.WitnessProgram()
+	case *AddressWitnessScriptHash:
+		info.Type, info.ScriptClass = "p2wsh", "witness_v0_scripthash"
+		info.IsWitness, info.WitnessVersion, info.WitnessProgram = true, a.WitnessVersion(), a.WitnessProgram()
+	case *AddressWitnessTaproot:
+		info.Type, info.ScriptClass = "p2tr", "witness_v1_taproot"
+		info.IsWitness, info.WitnessVersion, info.WitnessProgram = true, a.WitnessVersion(), a.WitnessProgram()
+	case *AddressPubKey:
+		info.Type, info.ScriptClass = "p2pk", "pubkey"
+		info.Normalized = a.String()
+	}
+	for _, net := range append([]*chaincfg.Params{defaultNet}, defaultNets...) {
+		if net != nil && a.IsForNet(net) {
+			info.Network = net.Name
+			break
+		}
+	}
+	return info, nil
+}
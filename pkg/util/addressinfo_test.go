@@ -0,0 +1,47 @@
+package util_test
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+	chaincfg "git.parallelcoin.io/dev/9/pkg/chain/config"
+	"git.parallelcoin.io/dev/9/pkg/util"
+)
+func TestInspectAddress(
+	t *testing.T) {
+	hash := bytes.Repeat([]byte{0x42}, 20)
+	p2pkh, _ := util.NewAddressPubKeyHash(hash, &chaincfg.MainNetParams)
+	p2sh, _ := util.NewAddressScriptHashFromHash(hash, &chaincfg.TestNet3Params)
+	regtest, _ := util.NewAddressPubKeyHash(hash, &chaincfg.RegressionNetParams)
+	program, _ := hex.DecodeString("751e76e8199196d454941c45d1b3a323f1433bd6")
+	tests := []struct {
+		addr, typ, network, class, normalized string
+		program                               []byte
+	}{
+		{p2pkh.EncodeAddress(), "p2pkh", "mainnet", "pubkeyhash", p2pkh.EncodeAddress(), nil},
+		{p2sh.EncodeAddress(), "p2sh", "testnet", "scripthash", p2sh.EncodeAddress(), nil},
+		{regtest.EncodeAddress(), "p2pkh", "regtest", "pubkeyhash", regtest.EncodeAddress(), nil},
+		{"BC1QW508D6QEJXTDG4Y5R3ZARVARY0C5XW7KV8F3T4", "p2wpkh", "mainnet", "witness_v0_keyhash",
+			"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", program},
+		{"02192D74D0CB94344C9569C2E77901573D8D7903C3EBEC3A957724895DCA52C6B4", "p2pk", "mainnet", "pubkey",
+			"02192d74d0cb94344c9569c2e77901573d8d7903c3ebec3a957724895dca52c6b4", nil},
+	}
+	for _, test := range tests {
+		info, err := util.InspectAddress(test.addr, &chaincfg.MainNetParams)
+		if err != nil {
+			t.Errorf("%s: %v", test.addr, err)
+			continue
+		}
+		if info.Type != test.typ || info.Network != test.network || info.ScriptClass != test.class ||
+			info.Normalized != test.normalized {
+			t.Errorf("%s is %s on %s paid by %s written %s", test.addr, info.Type, info.Network, info.ScriptClass, info.Normalized)
+		}
+		if info.IsWitness != (test.program != nil) || !bytes.Equal(info.WitnessProgram, test.program) || info.WitnessVersion != 0 {
+			t.Errorf("%s has witness %v version %d program %x", test.addr, info.IsWitness, info.WitnessVersion, info.WitnessProgram)
+		}
+	}
+	for _, addr := range []string{"", "not an address", " " + p2pkh.EncodeAddress(), "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t5"} {
+		if _, err := util.InspectAddress(addr, &chaincfg.MainNetParams); err == nil {
+			t.Errorf("%q was inspected as an address", addr)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package util
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+)
+// AmountFormat is how amounts are written for people to read: the unit they are counted in, the separator put between each group of three digits of the whole part, if any, and the decimal point.
+type AmountFormat struct {
+	Unit  AmountUnit
+	Group string
+	Point string
+}
+// PlainFormat writes amounts in DUO with a decimal point and no grouping, as the RPC servers do.
+var PlainFormat = AmountFormat{Unit: AmountDUO, Point: "."}
+// amountUnits are the names ParseAmountUnit accepts, the labels of the units along with spellings without the Greek letter or capitals.
+var amountUnits = map[string]AmountUnit{
+	"MDUO":    AmountMegaDUO,
+	"kDUO":    AmountKiloDUO,
+	"DUO":     AmountDUO,
+	"duo":     AmountDUO,
+	"mDUO":    AmountMilliDUO,
+	"μDUO":    AmountMicroDUO,
+	"uDUO":    AmountMicroDUO,
+	"Satoshi": AmountSatoshi,
+	"satoshi": AmountSatoshi,
+	"sat":     AmountSatoshi,
+}
+// localeMarks are the grouping separator and decimal point most used with each language, those not listed writing amounts as in English. Languages grouping with a space use a no-break space so amounts are not wrapped.
+var localeMarks = map[string][2]string{
+	"bg": {"\u00a0", ","}, "cs": {"\u00a0", ","}, "da": {".", ","},
+	"de": {".", ","}, "el": {".", ","}, "es": {".", ","},
+	"fi": {"\u00a0", ","}, "fr": {"\u00a0", ","}, "hu": {"\u00a0", ","},
+	"id": {".", ","}, "it": {".", ","}, "nb": {"\u00a0", ","},
+	"nl": {".", ","}, "no": {"\u00a0", ","}, "pl": {"\u00a0", ","},
+	"pt": {".", ","}, "ro": {".", ","}, "ru": {"\u00a0", ","},
+	"sk": {"\u00a0", ","}, "sv": {"\u00a0", ","}, "tr": {".", ","},
+	"uk": {"\u00a0", ","}, "vi": {".", ","},
+}
+// AmountUnitNames lists the labels of the units amounts can be shown in, largest first.
+func AmountUnitNames() []string {
+	return []string{"MDUO", "kDUO", "DUO", "mDUO", "μDUO", "Satoshi"}
+}
+// ParseAmountUnit returns the unit with the label, which may be written without capitals as duo and satoshi, sat for Satoshi or uDUO for μDUO.
+func ParseAmountUnit(s string) (AmountUnit, error) {
+	if u, ok := amountUnits[strings.TrimSpace(s)]; ok {
+		return u, nil
+	}
+	return 0, errors.New("unknown unit " + s + ", use one of " + strings.Join(AmountUnitNames(), ", "))
+}
+// LocaleFormat is the format of amounts in the unit for a locale such as en, de_DE.UTF-8 or pt-BR, going by its language. Empty, C and POSIX locales have no grouping as in PlainFormat, and languages it does not know are written as in English.
+func LocaleFormat(locale string, u AmountUnit) AmountFormat {
+	f := AmountFormat{Unit: u, Group: ",", Point: "."}
+	lang := strings.ToLower(locale)
+	if i := strings.IndexAny(lang, "-_.@"); i >= 0 {
+		lang = lang[:i]
+	}
+	switch marks, ok := localeMarks[lang]; {
+	case lang == "" || lang == "c" || lang == "posix":
+		f.Group = ""
+	case ok:
+		f.Group, f.Point = marks[0], marks[1]
+	}
+	return f
+}
+// decimals is how many decimal places amounts in the unit have, all of those of a satoshi
+func (f AmountFormat) decimals() int {
+	if d := int(f.Unit) + 8; d > 0 {
+		return d
+	}
+	return 0
+}
+// FormatWith writes the amount in the format with all the decimal places of its unit and no label. Unlike Format it is exact, as it does not go through a floating point number.
+func (a Amount) FormatWith(f AmountFormat) string {
+	n := uint64(a)
+	sign := ""
+	if a < 0 {
+		n, sign = uint64(-a), "-"
+	}
+	if int(f.Unit)+8 < 0 {
+		n *= uint64(math.Pow10(-int(f.Unit) - 8))
+	}
+	d := f.decimals()
+	scale := uint64(math.Pow10(d))
+	whole := strconv.FormatUint(n/scale, 10)
+	if f.Group != "" {
+		var b strings.Builder
+		for i, c := range whole {
+			if i > 0 && (len(whole)-i)%3 == 0 {
+				b.WriteString(f.Group)
+			}
+			b.WriteRune(c)
+		}
+		whole = b.String()
+	}
+	if d == 0 {
+		return sign + whole
+	}
+	frac := strconv.FormatUint(n%scale, 10)
+	return sign + whole + f.Point + strings.Repeat("0", d-len(frac)) + frac
+}
+// ParseAmount reads an amount written in the format, strictly: an optional minus sign, the whole part in plain digits or in groups of three split by the format's separator, and optionally the decimal point and up to the unit's decimal places, which may be followed by a space and the unit's label. Amounts beyond MaxSatoshi are refused, as is anything else such as exponents or rounding away digits. A space also separates groups in formats grouping with a no-break space, as that is what people type.
+func ParseAmount(s string, f AmountFormat) (Amount, error) {
+	invalid := errors.New("invalid amount " + strconv.Quote(s))
+	s = strings.TrimSpace(s)
+	for _, space := range []string{" ", "\u00a0"} {
+		s = strings.TrimSuffix(s, space+f.Unit.String())
+	}
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+	whole, frac := s, ""
+	if i := strings.Index(s, f.Point); f.Point != "" && i >= 0 {
+		whole, frac = s[:i], s[i+len(f.Point):]
+		if frac == "" || len(frac) > f.decimals() || !digits(frac) {
+			return 0, invalid
+		}
+	}
+	if f.Group != "" {
+		if f.Group == "\u00a0" {
+			whole = strings.Replace(whole, " ", f.Group, -1)
+		}
+		groups := strings.Split(whole, f.Group)
+		for i, g := range groups {
+			if i > 0 && len(g) != 3 || i == 0 && len(groups) > 1 && (len(g) < 1 || len(g) > 3) {
+				return 0, invalid
+			}
+		}
+		whole = strings.Join(groups, "")
+	}
+	if whole == "" || !digits(whole) {
+		return 0, invalid
+	}
+	num := strings.TrimLeft(whole+frac+strings.Repeat("0", f.decimals()-len(frac)), "0")
+	if int(f.Unit)+8 < 0 {
+		num += strings.Repeat("0", -int(f.Unit)-8)
+	}
+	if num == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseUint(num, 10, 64)
+	if err != nil || len(num) > 16 || n > MaxSatoshi {
+		return 0, errors.New("amount " + strconv.Quote(s) + " is more than can ever exist")
+	}
+	if negative {
+		return -Amount(n), nil
+	}
+	return Amount(n), nil
+}
+// AmountFromDUO converts an amount of DUO, such as one given to an RPC server, refusing ones that are not a number, beyond MaxSatoshi or more precise than a satoshi rather than rounding them as NewAmount does.
+func AmountFromDUO(f float64) (Amount, error) {
+	a, err := NewAmount(f)
+	switch {
+	case err != nil:
+		return 0, err
+	case a > MaxSatoshi || a < -MaxSatoshi:
+		return 0, errors.New("amount out of range")
+	}
+	// the shortest decimal that is the float is what the client wrote
+	text := strconv.FormatFloat(f, 'f', -1, 64)
+	if i := strings.IndexByte(text, '.'); i >= 0 && len(text)-i-1 > 8 {
+		return 0, errors.New("amount has more than 8 decimal places")
+	}
+	return a, nil
+}
+// digits is true if s is only decimal digits
+func digits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,95 @@
+package util_test
+import (
+	"testing"
+	. "git.parallelcoin.io/dev/9/pkg/util"
+)
+func TestAmountFormat(
+	t *testing.T) {
+	tests := []struct {
+		amount Amount
+		format AmountFormat
+		text   string
+	}{
+		{0, PlainFormat, "0.00000000"},
+		{123456789012, PlainFormat, "1234.56789012"},
+		{-150000000, PlainFormat, "-1.50000000"},
+		{123456789012, LocaleFormat("en_US.UTF-8", AmountDUO), "1,234.56789012"},
+		{123456789012, LocaleFormat("de", AmountDUO), "1.234,56789012"},
+		{123456789012, LocaleFormat("fr-CH", AmountDUO), "1\u00a0234,56789012"},
+		{123456789012, LocaleFormat("", AmountMilliDUO), "1234567.89012"},
+		{123456789012, LocaleFormat("en", AmountSatoshi), "123,456,789,012"},
+		{MaxSatoshi, LocaleFormat("en", AmountMegaDUO), "21.00000000000000"},
+		{-100000, LocaleFormat("en", AmountKiloDUO), "-0.00000100000"},
+	}
+	for _, test := range tests {
+		text := test.amount.FormatWith(test.format)
+		if text != test.text {
+			t.Errorf("%d formatted as %q, want %q", int64(test.amount), text, test.text)
+			continue
+		}
+		a, err := ParseAmount(text, test.format)
+		if err != nil || a != test.amount {
+			t.Errorf("%q parsed as %d, %v", text, int64(a), err)
+		}
+	}
+}
+func TestParseAmount(
+	t *testing.T) {
+	de := LocaleFormat("de_DE", AmountDUO)
+	fr := LocaleFormat("fr", AmountDUO)
+	tests := []struct {
+		text   string
+		format AmountFormat
+		valid  bool
+		amount Amount
+	}{
+		{"1.5", PlainFormat, true, 150000000},
+		{" 1.5 DUO ", PlainFormat, true, 150000000},
+		{"12,345.6", LocaleFormat("en", AmountDUO), true, 1234560000000},
+		{"12345,6", de, true, 1234560000000},
+		{"12 345,6", fr, true, 1234560000000},
+		{"2 mDUO", LocaleFormat("en", AmountMilliDUO), true, 200000},
+		{"21000000", PlainFormat, true, MaxSatoshi},
+		{"21000000.00000001", PlainFormat, false, 0},
+		{"1.123456789", PlainFormat, false, 0},
+		{"1.5", de, false, 0},
+		{"1,23,456", LocaleFormat("en", AmountDUO), false, 0},
+		{",123", LocaleFormat("en", AmountDUO), false, 0},
+		{"1e5", PlainFormat, false, 0},
+		{"1.", PlainFormat, false, 0},
+		{".5", PlainFormat, false, 0},
+		{"-", PlainFormat, false, 0},
+		{"+1", PlainFormat, false, 0},
+		{"1.5", LocaleFormat("en", AmountSatoshi), false, 0},
+		{"99999999999999999999", PlainFormat, false, 0},
+	}
+	for _, test := range tests {
+		a, err := ParseAmount(test.text, test.format)
+		if (err == nil) != test.valid || a != test.amount {
+			t.Errorf("%q parsed as %d, %v", test.text, int64(a), err)
+		}
+	}
+}
+func TestAmountFromDUO(
+	t *testing.T) {
+	for f, valid := range map[float64]bool{
+		0.1:               true,
+		20999999.99999999: true,
+		21e6:              true,
+		21e6 + 1e-8:       false,
+		1.000000001:       false,
+		-0.00000001:       true,
+	} {
+		if _, err := AmountFromDUO(f); (err == nil) != valid {
+			t.Errorf("AmountFromDUO(%v) returned %v", f, err)
+		}
+	}
+	for _, name := range AmountUnitNames() {
+		if u, err := ParseAmountUnit(name); err != nil || u.String() != name {
+			t.Errorf("unit %s parsed as %v, %v", name, u, err)
+		}
+	}
+	if u, err := ParseAmountUnit("sat"); err != nil || u != AmountSatoshi {
+		t.Errorf("sat parsed as %v, %v", u, err)
+	}
+}
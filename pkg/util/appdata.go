@@ -4,11 +4,90 @@ import (
 	"os/user"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 	"unicode"
 )
+// XDGEnv overrides whether XDG is on when set to a boolean such as 1 or 0, for users who want the XDG layout on other POSIX systems or the old one on Linux.
+const XDGEnv = "POD_XDG"
+// XDG makes AppDataDir and AppConfigDir follow the XDG base directory specification on POSIX systems other than Mac OS and Plan 9, putting data in $XDG_DATA_HOME (~/.local/share) and configuration in $XDG_CONFIG_HOME (~/.config) instead of a dot directory in the home directory. It is on by default on Linux. Data already in the dot directory is used from there until MigrateAppDataDir moves it.
+var XDG = xdgDefault()
+// xdgDefault returns whether XDG is on, from XDGEnv if it is set and otherwise by the operating system
+func xdgDefault() bool {
+	if on, err := strconv.ParseBool(os.Getenv(XDGEnv)); err == nil {
+		return on
+	}
+	return runtime.GOOS == "linux"
+}
+// xdgOS reports whether the XDG layout applies to the operating system at all, which it does not on Windows, Mac OS and Plan 9, which have their own
+func xdgOS(
+	goos string) bool {
+	switch goos {
+	case "windows", "darwin", "plan9":
+		return false
+	}
+	return true
+}
+// homeDir returns the home directory of the user, from the Go standard lib or failing that the HOME environment variable
+func homeDir() string {
+	usr, err := user.Current()
+	if err == nil && usr.HomeDir != "" {
+		return usr.HomeDir
+	}
+	return os.Getenv("HOME")
+}
+// xdgDir returns the base directory in the named XDG variable, or def under the home directory if the variable is unset or not an absolute path, which the specification says to ignore
+func xdgDir(
+	env, def, home string) string {
+	if dir := os.Getenv(env); filepath.IsAbs(dir) {
+		return dir
+	}
+	return filepath.Join(home, def)
+}
+// appNameLower returns appName without any leading period and with its first character lowercase, as it is named in POSIX directories
+func appNameLower(
+	appName string) string {
+	appName = strings.TrimPrefix(appName, ".")
+	return string(unicode.ToLower(rune(appName[0]))) + appName[1:]
+}
 // appDataDir returns an operating system specific directory to be used for storing application data for an application.  See AppDataDir for more details.  This unexported version takes an operating system argument primarily to enable the testing package to properly test the function by forcing an operating system that is not the currently one.
 func appDataDir(
+	goos, appName string, roaming bool) string {
+	legacy := legacyAppDataDir(goos, appName, roaming)
+	if !XDG || !xdgOS(goos) || legacy == "." {
+		return legacy
+	}
+	home := homeDir()
+	if home == "" {
+		return legacy
+	}
+	dir := filepath.Join(xdgDir("XDG_DATA_HOME", filepath.Join(".local", "share"), home), appNameLower(appName))
+	// data that has not been migrated yet is used where it is
+	if !FileExists(dir) && FileExists(legacy) {
+		return legacy
+	}
+	return dir
+}
+// appConfigDir returns the directory for the configuration of an application, which is the XDG config directory when XDG applies and the data directory otherwise. See AppConfigDir for more details.
+func appConfigDir(
+	goos, appName string) string {
+	data := appDataDir(goos, appName, false)
+	if !XDG || !xdgOS(goos) || data == "." {
+		return data
+	}
+	home := homeDir()
+	if home == "" {
+		return data
+	}
+	dir := filepath.Join(xdgDir("XDG_CONFIG_HOME", ".config", home), appNameLower(appName))
+	// configuration that has not been migrated yet is used where it is
+	if legacy := legacyAppDataDir(goos, appName, false); !FileExists(dir) && data == legacy && FileExists(legacy) {
+		return legacy
+	}
+	return dir
+}
+// legacyAppDataDir returns the directory appDataDir returns when XDG is off: a dot directory in the home directory on POSIX systems.
+func legacyAppDataDir(
 	goos, appName string, roaming bool) string {
 	if appName == "" || appName == "." {
 		return "."
@@ -16,17 +95,9 @@ func appDataDir(
 	// The caller really shouldn't prepend the appName with a period, but if they do, handle it gracefully by trimming it.
 	appName = strings.TrimPrefix(appName, ".")
 	appNameUpper := string(unicode.ToUpper(rune(appName[0]))) + appName[1:]
-	appNameLower := string(unicode.ToLower(rune(appName[0]))) + appName[1:]
-	// Get the OS specific home directory via the Go standard lib.
-	var homeDir string
-	usr, err := user.Current()
-	if err == nil {
-		homeDir = usr.HomeDir
-	}
-	// Fall back to standard HOME environment variable that works for most POSIX OSes if the directory from the Go standard lib failed.
-	if err != nil || homeDir == "" {
-		homeDir = os.Getenv("HOME")
-	}
+	appNameLower := appNameLower(appName)
+	// Get the OS specific home directory via the Go standard lib, falling back to the HOME environment variable.
+	homeDir := homeDir()
 	switch goos {
 	// Attempt to use the LOCALAPPDATA or APPDATA environment variable on Windows.
 	case "windows":
@@ -58,7 +129,7 @@ func appDataDir(
 // AppDataDir returns an operating system specific directory to be used for storing application data for an application. The appName parameter is the name of the application the data directory is being requested for.  This function will prepend a period to the appName for POSIX style operating systems since that is standard practice.  An empty appName or one with a single dot is treated as requesting the current directory so only "." will be returned.  Further, the first character of appName will be made lowercase for POSIX style operating systems and uppercase for Mac and Windows since that is standard practice.
 // The roaming parameter only applies to Windows where it specifies the roaming application data profile (%APPDATA%) should be used instead of the local one (%LOCALAPPDATA%) that is used by default. Example results:
 //  dir := AppDataDir("myapp", false)
-//   POSIX (Linux/BSD): ~/.myapp
+//   POSIX (Linux/BSD): ~/.myapp, or with XDG $XDG_DATA_HOME/myapp, ~/.local/share/myapp by default
 //   Mac OS: $HOME/Library/Application Support/Myapp
 //   Windows: %LOCALAPPDATA%\Myapp
 //   Plan 9: $home/myapp
@@ -66,3 +137,67 @@ func AppDataDir(
 	appName string, roaming bool) string {
 	return appDataDir(runtime.GOOS, appName, roaming)
 }
+// AppConfigDir returns the directory to keep the configuration of an application in. With XDG on it is $XDG_CONFIG_HOME/appname, ~/.config/appname by default, unless the configuration is still in the legacy dot directory. Otherwise it is the same as AppDataDir.
+func AppConfigDir(
+	appName string) string {
+	return appConfigDir(runtime.GOOS, appName)
+}
+// ConfigFile returns the path of the configuration file of an application keeping its data in datadir. For the default data directory it is in AppConfigDir, and for any other the file named config in datadir, so instances given their own data directory keep their configuration with it.
+func ConfigFile(
+	appName, datadir string) string {
+	if filepath.Clean(CleanAndExpandPath(datadir, "")) == AppDataDir(appName, false) {
+		return filepath.Join(AppConfigDir(appName), "config")
+	}
+	return filepath.Join(datadir, "config")
+}
+// MigrateAppDataDir moves the data of an application from its legacy dot directory to the XDG data directory, when XDG is on and the data has not been moved yet. A symlink is left in place of the dot directory so scripts and tools using the old path keep working. The configuration file is moved on to the XDG config directory in the same way, with a symlink to it left in the data directory. It returns the directory the data was moved to, or "" if there was nothing to move. If the data cannot be moved, for example because the directories are on different filesystems, it stays where it is and AppDataDir keeps returning the dot directory.
+func MigrateAppDataDir(
+	appName string) (string, error) {
+	if !XDG || !xdgOS(runtime.GOOS) || appName == "" || appName == "." {
+		return "", nil
+	}
+	return migrateAppDataDir(homeDir(), appName)
+}
+// migrateAppDataDir does the work of MigrateAppDataDir for the given home directory
+func migrateAppDataDir(
+	home, appName string) (string, error) {
+	if home == "" {
+		return "", nil
+	}
+	name := appNameLower(appName)
+	legacy := filepath.Join(home, "."+name)
+	// a symlink is what a previous migration left behind
+	fi, err := os.Lstat(legacy)
+	if err != nil || !fi.IsDir() {
+		return "", nil
+	}
+	dir := filepath.Join(xdgDir("XDG_DATA_HOME", filepath.Join(".local", "share"), home), name)
+	if FileExists(dir) {
+		return "", nil
+	}
+	if err = os.MkdirAll(filepath.Dir(dir), 0700); err != nil {
+		return "", err
+	}
+	if err = os.Rename(legacy, dir); err != nil {
+		return "", err
+	}
+	if err = os.Symlink(dir, legacy); err != nil {
+		return dir, err
+	}
+	oldConfig := filepath.Join(dir, "config")
+	if fi, err = os.Lstat(oldConfig); err != nil || !fi.Mode().IsRegular() {
+		return dir, nil
+	}
+	configDir := filepath.Join(xdgDir("XDG_CONFIG_HOME", ".config", home), name)
+	config := filepath.Join(configDir, "config")
+	if configDir == dir || FileExists(config) {
+		return dir, nil
+	}
+	if err = os.MkdirAll(configDir, 0700); err != nil {
+		return dir, err
+	}
+	if err = os.Rename(oldConfig, config); err != nil {
+		return dir, err
+	}
+	return dir, os.Symlink(config, oldConfig)
+}
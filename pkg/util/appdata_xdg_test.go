@@ -0,0 +1,90 @@
+package util_test
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"git.parallelcoin.io/dev/9/pkg/util"
+)
+func TestXDGAppDataDir(
+	t *testing.T) {
+	tmp, err := ioutil.TempDir("", "xdg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	defer func(on bool) { util.XDG = on }(util.XDG)
+	defer os.Setenv("XDG_DATA_HOME", os.Getenv("XDG_DATA_HOME"))
+	defer os.Setenv("XDG_CONFIG_HOME", os.Getenv("XDG_CONFIG_HOME"))
+	data, config := filepath.Join(tmp, "data"), filepath.Join(tmp, "config")
+	os.Setenv("XDG_DATA_HOME", data)
+	os.Setenv("XDG_CONFIG_HOME", config)
+	util.XDG = true
+	// an app with no legacy dot directory in the real home directory
+	app := "Xdgtestapp" + filepath.Base(tmp)
+	name := "x" + app[1:]
+	if got, want := util.TstAppDataDir("linux", app, false), filepath.Join(data, name); got != want {
+		t.Errorf("data dir is %q, want %q", got, want)
+	}
+	if got := util.TstAppDataDir("darwin", app, false); got == filepath.Join(data, name) {
+		t.Errorf("XDG data dir %q used on Mac OS", got)
+	}
+	if got, want := util.ConfigFile(app, util.AppDataDir(app, false)), filepath.Join(config, name, "config"); got != want {
+		t.Errorf("config file is %q, want %q", got, want)
+	}
+	if got, want := util.ConfigFile(app, tmp), filepath.Join(tmp, "config"); got != want {
+		t.Errorf("config file for another data dir is %q, want %q", got, want)
+	}
+	// XDG_DATA_HOME must be absolute to be used
+	os.Setenv("XDG_DATA_HOME", "relative")
+	if got := util.TstAppDataDir("linux", app, false); filepath.Base(filepath.Dir(got)) != "share" {
+		t.Errorf("relative XDG_DATA_HOME used for data dir %q", got)
+	}
+	util.XDG = false
+	if got := util.TstAppDataDir("linux", app, false); filepath.Base(got) != "."+name {
+		t.Errorf("data dir with XDG off is %q", got)
+	}
+}
+func TestMigrateAppDataDir(
+	t *testing.T) {
+	tmp, err := ioutil.TempDir("", "xdg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	defer os.Setenv("XDG_DATA_HOME", os.Getenv("XDG_DATA_HOME"))
+	defer os.Setenv("XDG_CONFIG_HOME", os.Getenv("XDG_CONFIG_HOME"))
+	os.Unsetenv("XDG_DATA_HOME")
+	os.Unsetenv("XDG_CONFIG_HOME")
+	legacy := filepath.Join(tmp, ".app")
+	if err := os.MkdirAll(filepath.Join(legacy, "mainnet"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(legacy, "config"), []byte("{}"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	dir, err := util.TstMigrateAppDataDir(tmp, "App")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(tmp, ".local", "share", "app"); dir != want {
+		t.Fatalf("data moved to %q, want %q", dir, want)
+	}
+	if !util.FileExists(filepath.Join(dir, "mainnet")) {
+		t.Error("data not in the new data dir")
+	}
+	if target, err := os.Readlink(legacy); err != nil || target != dir {
+		t.Errorf("legacy dir links to %q, %v", target, err)
+	}
+	config := filepath.Join(tmp, ".config", "app", "config")
+	if b, err := ioutil.ReadFile(config); err != nil || string(b) != "{}" {
+		t.Errorf("config file not moved: %q, %v", b, err)
+	}
+	if target, err := os.Readlink(filepath.Join(dir, "config")); err != nil || target != config {
+		t.Errorf("old config file links to %q, %v", target, err)
+	}
+	// the symlink is left alone the next time
+	if dir, err = util.TstMigrateAppDataDir(tmp, "App"); dir != "" || err != nil {
+		t.Errorf("migrated again to %q, %v", dir, err)
+	}
+}
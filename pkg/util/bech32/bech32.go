@@ -5,18 +5,40 @@ import (
 )
 const charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
 var gen = []int{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
-// Decode decodes a bech32 encoded string, returning the human-readable part and the data part excluding the checksum.
+// Version is the variant of checksum a bech32 string has.
+type Version int
+const (
+	// Version0 is the original bech32 checksum of BIP 173, used by witness version 0 addresses.
+	Version0 Version = iota
+	// VersionM is the bech32m checksum of BIP 350, used by witness version 1 and later addresses.
+	VersionM
+)
+// checksumConsts are the constants the checksum polymod of each version is xored with.
+var checksumConsts = [...]int{Version0: 1, VersionM: 0x2bc830a3}
+// Decode decodes a bech32 encoded string, returning the human-readable part and the data part excluding the checksum. Strings with a bech32m checksum are refused, DecodeGeneric accepts both.
 func Decode(
 	bech string) (string, []byte, error) {
+	hrp, data, version, err := DecodeGeneric(bech)
+	if err != nil {
+		return "", nil, err
+	}
+	if version != Version0 {
+		return "", nil, fmt.Errorf("checksum failed, string has a bech32m checksum")
+	}
+	return hrp, data, nil
+}
+// DecodeGeneric decodes a string with either a bech32 or a bech32m checksum, returning the human-readable part, the data part excluding the checksum and which checksum it had.
+func DecodeGeneric(
+	bech string) (string, []byte, Version, error) {
 	// The maximum allowed length for a bech32 string is 90. It must also be at least 8 characters, since it needs a non-empty HRP, a separator, and a 6 character checksum.
 	if len(bech) < 8 || len(bech) > 90 {
-		return "", nil, fmt.Errorf("invalid bech32 string length %d",
+		return "", nil, 0, fmt.Errorf("invalid bech32 string length %d",
 			len(bech))
 	}
 	// Only	ASCII characters between 33 and 126 are allowed.
 	for i := 0; i < len(bech); i++ {
 		if bech[i] < 33 || bech[i] > 126 {
-			return "", nil, fmt.Errorf("invalid character in "+
+			return "", nil, 0, fmt.Errorf("invalid character in "+
 				"string: '%c'", bech[i])
 		}
 	}
@@ -24,7 +46,7 @@ func Decode(
 	lower := strings.ToLower(bech)
 	upper := strings.ToUpper(bech)
 	if bech != lower && bech != upper {
-		return "", nil, fmt.Errorf("string not all lowercase or all " +
+		return "", nil, 0, fmt.Errorf("string not all lowercase or all " +
 			"uppercase")
 	}
 	// We'll work with the lowercase string from now on.
@@ -32,7 +54,7 @@ func Decode(
 	// The string is invalid if the last '1' is non-existent, it is the first character of the string (no human-readable part) or one of the last 6 characters of the string (since checksum cannot contain '1'), or if the string is more than 90 characters in total.
 	one := strings.LastIndexByte(bech, '1')
 	if one < 1 || one+7 > len(bech) {
-		return "", nil, fmt.Errorf("invalid index of 1")
+		return "", nil, 0, fmt.Errorf("invalid index of 1")
 	}
 	// The human-readable part is everything before the last '1'.
 	hrp := bech[:one]
@@ -40,28 +62,39 @@ func Decode(
 	// Each character corresponds to the byte with value of the index in 'charset'.
 	decoded, err := toBytes(data)
 	if err != nil {
-		return "", nil, fmt.Errorf("failed converting data to bytes: "+
+		return "", nil, 0, fmt.Errorf("failed converting data to bytes: "+
 			"%v", err)
 	}
-	if !bech32VerifyChecksum(hrp, decoded) {
+	version, ok := bech32VerifyChecksum(hrp, decoded)
+	if !ok {
 		moreInfo := ""
 		checksum := bech[len(bech)-6:]
 		expected, err := toChars(bech32Checksum(hrp,
-			decoded[:len(decoded)-6]))
+			decoded[:len(decoded)-6], Version0))
 		if err == nil {
 			moreInfo = fmt.Sprintf("Expected %v, got %v.",
 				expected, checksum)
 		}
-		return "", nil, fmt.Errorf("checksum failed. " + moreInfo)
+		return "", nil, 0, fmt.Errorf("checksum failed. " + moreInfo)
 	}
 	// We exclude the last 6 bytes, which is the checksum.
-	return hrp, decoded[:len(decoded)-6], nil
+	return hrp, decoded[:len(decoded)-6], version, nil
 }
 // Encode encodes a byte slice into a bech32 string with the human-readable part hrb. Note that the bytes must each encode 5 bits (base32).
 func Encode(
 	hrp string, data []byte) (string, error) {
+	return encode(hrp, data, Version0)
+}
+// EncodeM encodes a byte slice into a string with a bech32m checksum, as Encode does with a bech32 one.
+func EncodeM(
+	hrp string, data []byte) (string, error) {
+	return encode(hrp, data, VersionM)
+}
+// encode encodes the data with the checksum of the version.
+func encode(
+	hrp string, data []byte, version Version) (string, error) {
 	// Calculate the checksum of the data and append it at the end.
-	checksum := bech32Checksum(hrp, data)
+	checksum := bech32Checksum(hrp, data, version)
 	combined := append(data, checksum...)
 	// The resulting bech32 string is the concatenation of the hrp, the separator 1, data and checksum. Everything after the separator is represented using the specified charset.
 	dataChars, err := toChars(combined)
@@ -148,9 +181,9 @@ func ConvertBits(
 	}
 	return regrouped, nil
 }
-// For more details on the checksum calculation, please refer to BIP 173.
+// For more details on the checksum calculation, please refer to BIP 173, and to BIP 350 for the bech32m constant.
 func bech32Checksum(
-	hrp string, data []byte) []byte {
+	hrp string, data []byte, version Version) []byte {
 	// Convert the bytes to list of integers, as this is needed for the checksum calculation.
 	integers := make([]int, len(data))
 	for i, b := range data {
@@ -158,7 +191,7 @@ func bech32Checksum(
 	}
 	values := append(bech32HrpExpand(hrp), integers...)
 	values = append(values, []int{0, 0, 0, 0, 0, 0}...)
-	polymod := bech32Polymod(values) ^ 1
+	polymod := bech32Polymod(values) ^ checksumConsts[version]
 	var res []byte
 	for i := 0; i < 6; i++ {
 		res = append(res, byte((polymod>>uint(5*(5-i)))&31))
@@ -193,13 +226,19 @@ func bech32HrpExpand(
 	}
 	return v
 }
-// For more details on the checksum verification, please refer to BIP 173.
+// For more details on the checksum verification, please refer to BIP 173 and BIP 350. It returns which version of checksum the data has, if either.
 func bech32VerifyChecksum(
-	hrp string, data []byte) bool {
+	hrp string, data []byte) (Version, bool) {
 	integers := make([]int, len(data))
 	for i, b := range data {
 		integers[i] = int(b)
 	}
 	concat := append(bech32HrpExpand(hrp), integers...)
-	return bech32Polymod(concat) == 1
+	polymod := bech32Polymod(concat)
+	for version, c := range checksumConsts {
+		if polymod == c {
+			return Version(version), true
+		}
+	}
+	return 0, false
 }
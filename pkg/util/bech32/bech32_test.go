@@ -75,3 +75,50 @@ func TestBech32(
 		}
 	}
 }
+
+func TestBech32m(
+
+	t *testing.T) {
+
+	tests := []string{
+		"A1LQFN3A",
+		"a1lqfn3a",
+		"abcdef1l7aum6echk45nj3s0wdvt2fg8x9yrzpqzd3ryx",
+		"split1checkupstagehandshakeupstreamerranterredcaperredlc445v",
+		"?1v759aa",
+	}
+
+	for _, str := range tests {
+
+		hrp, decoded, version, err := bech32.DecodeGeneric(str)
+
+		if err != nil {
+
+			t.Errorf("expected string to be valid bech32m: %v", err)
+			continue
+		}
+
+		if version != bech32.VersionM {
+
+			t.Errorf("%v decoded with checksum version %v", str, version)
+		}
+		// A bech32m checksum is not a bech32 one.
+
+		if _, _, err = bech32.Decode(str); err == nil {
+
+			t.Errorf("expected bech32 decoding to fail for %v", str)
+		}
+		encoded, err := bech32.EncodeM(hrp, decoded)
+
+		if err != nil {
+
+			t.Errorf("encoding failed: %v", err)
+		}
+
+		if encoded != strings.ToLower(str) {
+
+			t.Errorf("expected data to encode to %v, but got %v",
+				str, encoded)
+		}
+	}
+}
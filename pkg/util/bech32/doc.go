@@ -1,5 +1,6 @@
 /*
 Package bech32 provides a Go implementation of the bech32 format specified in BIP 173.
 Bech32 strings consist of a human-readable part (hrp), followed by the separator 1, then a checksummed data part encoded using the 32 characters "qpzry9x8gf2tvdw0s3jn54khce6mua7l". More info: https://github.com/bitcoin/bips/blob/master/bip-0173.mediawiki
+The bech32m variant of BIP 350, which differs only in the checksum and is used by witness version 1 and later addresses, is encoded with EncodeM and decoded along with bech32 by DecodeGeneric. More info: https://github.com/bitcoin/bips/blob/master/bip-0350.mediawiki
 */
 package bech32
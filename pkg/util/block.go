@@ -15,20 +15,75 @@ func (e OutOfRangeError) Error() string {
 	return string(e)
 }
 // Block defines a bitcoin block that provides easier and more efficient manipulation of raw blocks.  It also memoizes hashes for the block and its transactions on their first access so subsequent accesses don't have to repeat the relatively expensive hashing operations.
+// A lazily parsed block, made by NewBlockFromBytesLazy, only deserializes its serialized bytes into a MsgBlock when MsgBlock is first called. Until then its hash comes from the serialized header, and its transactions are lazily parsed transactions sharing the block's serialized bytes.
 type Block struct {
-	msgBlock                 *wire.MsgBlock  // Underlying MsgBlock
+	msgBlock                 *wire.MsgBlock  // Underlying MsgBlock, nil until needed for a lazily parsed block
+	txLocs                   []wire.TxLoc    // Where each transaction is in serializedBlock, for a lazily parsed block
+	txLayouts                []*txLayout     // Where the parts of each transaction are, for a lazily parsed block
 	serializedBlock          []byte          // Serialized bytes for the block
 	serializedBlockNoWitness []byte          // Serialized bytes for block w/o witness data
 	blockHash                *chainhash.Hash // Cached block hash
 	blockHeight              int32           // Height in the main block chain
 	transactions             []*Tx           // Transactions
 	txnsGenerated            bool            // ALL wrapped transactions generated
+	parseErr                 error           // Error deserializing the serialized bytes of a lazily parsed block
 }
-// MsgBlock returns the underlying wire.MsgBlock for the Block.
+// Parse deserializes the serialized bytes of a lazily parsed block into its MsgBlock if that has not been done yet, returning the error deserializing them if they do not.  The bytes are scanned when the block is made, which checks everything deserializing them does, so this only fails if they were modified since.
+func (b *Block) Parse() error {
+	if b.msgBlock != nil {
+		return nil
+	}
+	if b.parseErr != nil {
+		return b.parseErr
+	}
+	var msgBlock wire.MsgBlock
+	if err := msgBlock.Deserialize(bytes.NewReader(b.serializedBlock)); err != nil {
+		b.parseErr = err
+		return err
+	}
+	// Wrapped transactions already generated keep their own MsgTx, which the block takes so both refer to the same one.
+	for i, tx := range b.transactions {
+		if tx != nil {
+			if tx.msgTx == nil {
+				tx.setMsgTx(msgBlock.Transactions[i])
+			} else {
+				msgBlock.Transactions[i] = tx.msgTx
+			}
+		}
+	}
+	b.msgBlock = &msgBlock
+	return nil
+}
+// MsgBlock returns the underlying wire.MsgBlock for the Block.  For a lazily parsed block whose serialized bytes do not deserialize, which Parse reports, it returns a block with only the header, which fails validation.
 func (b *Block) MsgBlock() *wire.MsgBlock {
+	if err := b.Parse(); err != nil {
+		var header wire.BlockHeader
+		_ = header.Deserialize(bytes.NewReader(b.serializedBlock))
+		return &wire.MsgBlock{Header: header}
+	}
 	// Return the cached block.
 	return b.msgBlock
 }
+// numTx returns the number of transactions in the block.
+func (b *Block) numTx() int {
+	if b.msgBlock == nil {
+		return len(b.txLocs)
+	}
+	return len(b.msgBlock.Transactions)
+}
+// newTx returns a wrapped transaction for the transaction at the specified index, lazily parsed from the serialized block if the block is.
+func (b *Block) newTx(txNum int) *Tx {
+	var tx *Tx
+	if b.msgBlock == nil {
+		loc := b.txLocs[txNum]
+		end := loc.TxStart + loc.TxLen
+		tx = newLazyTx(b.serializedBlock[loc.TxStart:end:end], b.txLayouts[txNum])
+	} else {
+		tx = NewTx(b.msgBlock.Transactions[txNum])
+	}
+	tx.SetIndex(txNum)
+	return tx
+}
 // Bytes returns the serialized bytes for the Block.  This is equivalent to calling Serialize on the underlying wire.MsgBlock, however it caches the result so subsequent calls are more efficient.
 func (b *Block) Bytes() ([]byte, error) {
 	// Return the cached serialized bytes if it has already been generated.
@@ -54,7 +109,7 @@ func (b *Block) BytesNoWitness() ([]byte, error) {
 	}
 	// Serialize the MsgBlock.
 	var w bytes.Buffer
-	err := b.msgBlock.SerializeNoWitness(&w)
+	err := b.MsgBlock().SerializeNoWitness(&w)
 	if err != nil {
 		return nil, err
 	}
@@ -69,15 +124,20 @@ func (b *Block) Hash() *chainhash.Hash {
 	if b.blockHash != nil {
 		return b.blockHash
 	}
-	// Cache the block hash and return it.
-	hash := b.msgBlock.BlockHash()
+	// Cache the block hash and return it, hashing the serialized header when the block has not been deserialized.
+	var hash chainhash.Hash
+	if b.msgBlock == nil {
+		hash = chainhash.DoubleHashH(b.serializedBlock[:wire.MaxBlockHeaderPayload])
+	} else {
+		hash = b.msgBlock.BlockHash()
+	}
 	b.blockHash = &hash
 	return &hash
 }
 // Tx returns a wrapped transaction (util.Tx) for the transaction at the specified index in the Block.  The supplied index is 0 based.  That is to say, the first transaction in the block is txNum 0.  This is nearly equivalent to accessing the raw transaction (wire.MsgTx) from the underlying wire.MsgBlock, however the wrapped transaction has some helpful properties such as caching the hash so subsequent calls are more efficient.
 func (b *Block) Tx(txNum int) (*Tx, error) {
 	// Ensure the requested transaction is in range.
-	numTx := uint64(len(b.msgBlock.Transactions))
+	numTx := uint64(b.numTx())
 	if txNum < 0 || uint64(txNum) > numTx {
 		str := fmt.Sprintf("transaction index %d is out of range - max %d",
 			txNum, numTx-1)
@@ -92,8 +152,7 @@ func (b *Block) Tx(txNum int) (*Tx, error) {
 		return b.transactions[txNum], nil
 	}
 	// Generate and cache the wrapped transaction and return it.
-	newTx := NewTx(b.msgBlock.Transactions[txNum])
-	newTx.SetIndex(txNum)
+	newTx := b.newTx(txNum)
 	b.transactions[txNum] = newTx
 	return newTx, nil
 }
@@ -105,14 +164,12 @@ func (b *Block) Transactions() []*Tx {
 	}
 	// Generate slice to hold all of the wrapped transactions if needed.
 	if len(b.transactions) == 0 {
-		b.transactions = make([]*Tx, len(b.msgBlock.Transactions))
+		b.transactions = make([]*Tx, b.numTx())
 	}
 	// Generate and cache the wrapped transactions for all that haven't already been done.
 	for i, tx := range b.transactions {
 		if tx == nil {
-			newTx := NewTx(b.msgBlock.Transactions[i])
-			newTx.SetIndex(i)
-			b.transactions[i] = newTx
+			b.transactions[i] = b.newTx(i)
 		}
 	}
 	b.txnsGenerated = true
@@ -130,6 +187,10 @@ func (b *Block) TxHash(txNum int) (*chainhash.Hash, error) {
 }
 // TxLoc returns the offsets and lengths of each transaction in a raw block. It is used to allow fast indexing into transactions within the raw byte stream.
 func (b *Block) TxLoc() ([]wire.TxLoc, error) {
+	// A lazily parsed block found them when it was scanned.
+	if b.txLocs != nil {
+		return b.txLocs, nil
+	}
 	rawMsg, err := b.Bytes()
 	if err != nil {
 		return nil, err
@@ -158,9 +219,12 @@ func NewBlock(
 		blockHeight: BlockHeightUnknown,
 	}
 }
-// NewBlockFromBytes returns a new instance of a bitcoin block given the serialized bytes.  See Block.
+// NewBlockFromBytes returns a new instance of a bitcoin block given the serialized bytes, which is lazily parsed if LazyParse is set.  See Block.
 func NewBlockFromBytes(
 	serializedBlock []byte) (*Block, error) {
+	if LazyParse {
+		return NewBlockFromBytesLazy(serializedBlock)
+	}
 	br := bytes.NewReader(serializedBlock)
 	b, err := NewBlockFromReader(br)
 	if err != nil {
@@ -169,6 +233,21 @@ func NewBlockFromBytes(
 	b.serializedBlock = serializedBlock
 	return b, nil
 }
+// NewBlockFromBytesLazy returns a new lazily parsed bitcoin block given the serialized bytes, which are checked but not deserialized until MsgBlock is called.  The block and its transactions keep the bytes, so they must not be modified afterwards.  See Block.
+func NewBlockFromBytesLazy(
+	serializedBlock []byte) (*Block, error) {
+	s := scanner{b: serializedBlock}
+	txLocs, txLayouts, err := s.block()
+	if err != nil {
+		return nil, err
+	}
+	return &Block{
+		serializedBlock: serializedBlock[:s.pos:s.pos],
+		txLocs:          txLocs,
+		txLayouts:       txLayouts,
+		blockHeight:     BlockHeightUnknown,
+	}, nil
+}
 // NewBlockFromReader returns a new instance of a bitcoin block given a Reader to deserialize the block.  See Block.
 func NewBlockFromReader(
 	r io.Reader) (*Block, error) {
@@ -198,6 +198,71 @@ func TestNewBlockFromBlockAndBytes(
 			spew.Sdump(msgBlock), spew.Sdump(&Block100000))
 	}
 }
+// TestNewBlockFromBytesLazy tests a lazily parsed Block gives the same hashes, transactions and MsgBlock as a deserialized one.
+func TestNewBlockFromBytesLazy(
+	t *testing.T) {
+	var block100000Buf bytes.Buffer
+	if err := Block100000.Serialize(&block100000Buf); err != nil {
+		t.Fatalf("Serialize: %v", err)
+	}
+	b, err := util.NewBlockFromBytesLazy(block100000Buf.Bytes())
+	if err != nil {
+		t.Fatalf("NewBlockFromBytesLazy: %v", err)
+	}
+	if hash, want := b.Hash(), Block100000.BlockHash(); !hash.IsEqual(&want) {
+		t.Errorf("Hash: got %v, want %v", hash, want)
+	}
+	wantTxLocs, err := util.NewBlock(&Block100000).TxLoc()
+	if err != nil {
+		t.Fatalf("TxLoc: %v", err)
+	}
+	if txLocs, err := b.TxLoc(); err != nil || !reflect.DeepEqual(txLocs, wantTxLocs) {
+		t.Errorf("TxLoc: got %v, %v, want %v", txLocs, err, wantTxLocs)
+	}
+	tx1, err := b.Tx(1)
+	if err != nil {
+		t.Fatalf("Tx: %v", err)
+	}
+	msgTx1 := tx1.MsgTx()
+	for i, tx := range b.Transactions() {
+		if hash, want := tx.Hash(), Block100000.Transactions[i].TxHash(); !hash.IsEqual(&want) {
+			t.Errorf("Hash of tx %d: got %v, want %v", i, hash, want)
+		}
+		if tx.Index() != i {
+			t.Errorf("Index of tx %d: got %d", i, tx.Index())
+		}
+	}
+	msgBlock := b.MsgBlock()
+	if !reflect.DeepEqual(msgBlock, &Block100000) {
+		t.Errorf("MsgBlock: mismatched MsgBlock - got %v, want %v",
+			spew.Sdump(msgBlock), spew.Sdump(&Block100000))
+	}
+	// The block and its wrapped transactions share the same MsgTxs, whichever was deserialized first.
+	if msgBlock.Transactions[1] != msgTx1 {
+		t.Error("MsgBlock: transaction 1 is not the one its Tx deserialized")
+	}
+	tx0, _ := b.Tx(0)
+	if msgBlock.Transactions[0] != tx0.MsgTx() {
+		t.Error("MsgBlock: transaction 0 is not the one of its Tx")
+	}
+	// A truncated block fails to scan.
+	if _, err := util.NewBlockFromBytesLazy(block100000Buf.Bytes()[:block100000Buf.Len()-1]); err == nil {
+		t.Error("NewBlockFromBytesLazy: no error for a truncated block")
+	}
+	// Bytes modified after scanning are reported by Parse instead of panicking, leaving only the header.
+	corrupt := append([]byte{}, block100000Buf.Bytes()...)
+	b, err = util.NewBlockFromBytesLazy(corrupt)
+	if err != nil {
+		t.Fatalf("NewBlockFromBytesLazy: %v", err)
+	}
+	corrupt[wire.MaxBlockHeaderPayload] = 0xff
+	if err := b.Parse(); err == nil {
+		t.Error("Parse: no error for modified bytes")
+	}
+	if msgBlock := b.MsgBlock(); msgBlock.Header != Block100000.Header || len(msgBlock.Transactions) != 0 {
+		t.Errorf("MsgBlock: got %v for modified bytes, want only the header", spew.Sdump(msgBlock))
+	}
+}
 // TestBlockErrors tests the error paths for the Block API.
 func TestBlockErrors(
 	t *testing.T) {
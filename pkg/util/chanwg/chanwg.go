@@ -0,0 +1,78 @@
+// Package chanwg provides a WaitGroup whose waiters wait on a channel, so that a wait can be bounded by a timeout or a context, as shutdown paths need to be when a worker may never finish.
+package chanwg
+import (
+	"context"
+	"sync"
+	"time"
+)
+// WaitGroup waits for a collection of workers to finish like sync.WaitGroup, with waits that give up after a timeout or when a context is done. Its zero value is a WaitGroup with no workers, and it must not be copied after first use.
+type WaitGroup struct {
+	mtx   sync.Mutex
+	count int
+	// done is closed when the count drops to zero, and replaced when it rises from zero again.  It is nil until a worker is first added.
+	done chan struct{}
+}
+// closedChan is returned by wait when there are no workers, so waiting returns at once.
+var closedChan = make(chan struct{})
+func init() {
+	close(closedChan)
+}
+// Add adds delta, which may be negative, to the number of workers. It panics if the number would drop below zero, as a Done without its Add is a bug in the caller that would otherwise release waiters early.
+func (wg *WaitGroup) Add(delta int) {
+	wg.mtx.Lock()
+	defer wg.mtx.Unlock()
+	count := wg.count + delta
+	if count < 0 {
+		panic("chanwg: negative WaitGroup counter")
+	}
+	if wg.count == 0 && count > 0 {
+		wg.done = make(chan struct{})
+	}
+	if wg.count > 0 && count == 0 {
+		close(wg.done)
+	}
+	wg.count = count
+}
+// Done removes a finished worker. It panics if there are none.
+func (wg *WaitGroup) Done() {
+	wg.Add(-1)
+}
+// Count returns the number of workers that have not finished.
+func (wg *WaitGroup) Count() int {
+	wg.mtx.Lock()
+	defer wg.mtx.Unlock()
+	return wg.count
+}
+// wait returns the channel closed when the workers added so far have finished.
+func (wg *WaitGroup) wait() <-chan struct{} {
+	wg.mtx.Lock()
+	defer wg.mtx.Unlock()
+	if wg.count == 0 {
+		return closedChan
+	}
+	return wg.done
+}
+// Wait blocks until every worker has finished.
+func (wg *WaitGroup) Wait() {
+	<-wg.wait()
+}
+// WaitTimeout blocks until every worker has finished or d has passed, and returns whether they finished.
+func (wg *WaitGroup) WaitTimeout(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-wg.wait():
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+// WaitContext blocks until every worker has finished or the context is done, and returns the error of the context if the workers did not finish.
+func (wg *WaitGroup) WaitContext(ctx context.Context) error {
+	select {
+	case <-wg.wait():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,68 @@
+package chanwg
+import (
+	"context"
+	"testing"
+	"time"
+)
+func TestWait(t *testing.T) {
+	var wg WaitGroup
+	wg.Wait()
+	release := make(chan struct{})
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-release
+		}()
+	}
+	if wg.Count() != 3 {
+		t.Fatalf("count is %d, want 3", wg.Count())
+	}
+	if wg.WaitTimeout(10 * time.Millisecond) {
+		t.Fatal("the wait finished with the workers blocked")
+	}
+	close(release)
+	if !wg.WaitTimeout(5 * time.Second) {
+		t.Fatal("the wait did not finish after the workers did")
+	}
+	if wg.Count() != 0 {
+		t.Errorf("count is %d after the workers finished", wg.Count())
+	}
+	// the group is used again after it was waited on
+	wg.Add(1)
+	if wg.WaitTimeout(10 * time.Millisecond) {
+		t.Fatal("the wait finished with a worker added again")
+	}
+	wg.Done()
+	wg.Wait()
+}
+func TestWaitContext(t *testing.T) {
+	var wg WaitGroup
+	if err := wg.WaitContext(context.Background()); err != nil {
+		t.Fatalf("waiting with no workers: %v", err)
+	}
+	wg.Add(1)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := wg.WaitContext(ctx); err != context.Canceled {
+		t.Fatalf("waiting with a cancelled context returned %v", err)
+	}
+	wg.Done()
+	if err := wg.WaitContext(context.Background()); err != nil {
+		t.Fatalf("waiting after the worker finished: %v", err)
+	}
+}
+func TestDoneUnderflow(t *testing.T) {
+	var wg WaitGroup
+	wg.Add(1)
+	wg.Done()
+	defer func() {
+		if recover() == nil {
+			t.Error("a Done without its Add did not panic")
+		}
+		if wg.Count() != 0 {
+			t.Errorf("count is %d after the Done that panicked", wg.Count())
+		}
+	}()
+	wg.Done()
+}
@@ -2,9 +2,127 @@ package cl
 import (
 	"fmt"
 	"runtime"
+	"strings"
+	"sync/atomic"
 )
+const (
+	// NoCapture is the capture depth of levels whose entries get no location from Here
+	NoCapture = -1
+	// CaptureLocation is the capture depth of levels whose entries get the file, line and function of the caller of Here but no stack
+	CaptureLocation = 0
+)
+// captureDepth is the capture depth of each level, indexed by level: NoCapture, CaptureLocation or the number of stack frames to keep above the caller. It is read on every call to Here, so it is accessed atomically.
+var captureDepth = [...]int32{
+	_off:   NoCapture,
+	_fatal: 4,
+	_error: 4,
+	_warn:  CaptureLocation,
+	_info:  NoCapture,
+	_debug: NoCapture,
+	_trace: NoCapture,
+}
+// Location is where a log entry or error came from: the file, line and function of the code that captured it, and optionally the calls that led there, innermost first. A Location in a log Value is taken out of the printed values and attached to the Entry, its text going at the end of the line and its stack on the lines after.
+type Location struct {
+	File     string
+	Line     int
+	Function string
+	Stack    []Frame
+}
+// Frame is one call in the stack of a Location
+type Frame struct {
+	File     string
+	Line     int
+	Function string
+}
 // Ine (cl.Ine) returns caller location in source code
 var Ine = func() error {
-	_, file, line, _ := runtime.Caller(1)
-	return fmt.Errorf("[%s:%d]", file, line)
+	return capture(0)
+}
+// SetCapture sets how much Here captures for entries of the named level: NoCapture for nothing, so logging at the level pays no more than the level lookup, CaptureLocation for the caller's location, or a number of frames of stack above it as well. By default fatal and error entries get a shallow stack, warnings their location and the other levels nothing.
+func SetCapture(level string, depth int) {
+	if l, ok := Levels[level]; ok {
+		if depth < NoCapture {
+			depth = NoCapture
+		}
+		atomic.StoreInt32(&captureDepth[l], int32(depth))
+	}
+}
+// Here returns the location of its caller, with as much of the stack as SetCapture asks for the named level, for adding to a log Value of that level, as in log <- cl.Error{"failed", err, cl.Here("error")}. It returns nil, which log entries leave out, if the level captures nothing.
+func Here(level string) *Location {
+	l, ok := Levels[level]
+	if !ok {
+		return nil
+	}
+	depth := atomic.LoadInt32(&captureDepth[l])
+	if depth == NoCapture {
+		return nil
+	}
+	return capture(int(depth))
+}
+// capture returns the location of the caller of the function calling capture, with depth frames of stack above it
+func capture(depth int) *Location {
+	pcs := make([]uintptr, depth+1)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	loc := &Location{}
+	for i := 0; ; i++ {
+		f, more := frames.Next()
+		if i == 0 {
+			loc.File, loc.Line, loc.Function = f.File, f.Line, funcName(f.Function)
+		} else {
+			loc.Stack = append(loc.Stack, Frame{f.File, f.Line, funcName(f.Function)})
+		}
+		if !more {
+			break
+		}
+	}
+	return loc
+}
+// funcName shortens a function's full name to its package name and function, dropping the import path
+func funcName(name string) string {
+	return name[strings.LastIndexByte(name, '/')+1:]
+}
+// Error returns the file and line in brackets, as Ine has always written them
+func (l *Location) Error() string {
+	return fmt.Sprintf("[%s:%d]", l.File, l.Line)
+}
+// Long returns the file, line and function in brackets, followed by a line for each frame of the stack
+func (l *Location) Long() string {
+	s := fmt.Sprintf("[%s:%d %s]", l.File, l.Line, l.Function)
+	for _, f := range l.Stack {
+		s += fmt.Sprintf("\n\tcalled from %s:%d %s", f.File, f.Line, f.Function)
+	}
+	return s
+}
+// takeLocation removes any Locations from a log Value and returns the value without them and the first that is not nil
+func takeLocation(
+	i interface{}) (interface{}, *Location) {
+	var loc *Location
+	split := func(v Value) Value {
+		out := v[:0:0]
+		for _, x := range v {
+			if l, ok := x.(*Location); ok {
+				if loc == nil {
+					loc = l
+				}
+				continue
+			}
+			out = append(out, x)
+		}
+		return out
+	}
+	switch v := i.(type) {
+	case Fatal:
+		return Fatal(split(Value(v))), loc
+	case Error:
+		return Error(split(Value(v))), loc
+	case Warn:
+		return Warn(split(Value(v))), loc
+	case Info:
+		return Info(split(Value(v))), loc
+	case Debug:
+		return Debug(split(Value(v))), loc
+	case Trace:
+		return Trace(split(Value(v))), loc
+	}
+	return i, nil
 }
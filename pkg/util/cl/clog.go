@@ -1,6 +1,7 @@
 package cl
 import (
 	"fmt"
+	"strings"
 	"time"
 	"git.parallelcoin.io/dev/9/pkg/util/interrupt"
 	"github.com/mitchellh/colorstring"
@@ -204,11 +205,14 @@ func init() {
 					fmt.Println("received nil")
 					continue
 				}
+				i, loc := takeLocation(i)
 				color := Color
+				s = ""
 				if color {
 					s = colorstring.Color("[reset]")
 				}
-				t = time.Now().UTC().Format("06-01-02 15:04:05.000")
+				now := time.Now().UTC()
+				t = now.Format("06-01-02 15:04:05.000")
 				switch ii := i.(type) {
 				case Fatalc:
 					s += ii() + "\n"
@@ -271,20 +275,31 @@ func init() {
 						s += fmt.Sprintf(I, ii[1:]...) + "\n"
 					}
 				}
+				if loc != nil {
+					s = strings.TrimSuffix(s, "\n") + " " + loc.Long() + "\n"
+				}
+				entry := Entry{Time: now, Text: stripColor(s), Location: loc}
 				switch i.(type) {
 				case Ftl, Fatal, Fatalf, Fatalc:
 					s = ftlTag(color) + s
+					entry.Level = _fatal
 				case Err, Error, Errorf, Errorc:
 					s = errTag(color) + s
+					entry.Level = _error
 				case Wrn, Warn, Warnf, Warnc:
 					s = wrnTag(color) + s
+					entry.Level = _warn
 				case Inf, Info, Infof, Infoc:
 					s = infTag(color) + s
+					entry.Level = _info
 				case Dbg, Debug, Debugf, Debugc:
 					s = dbgTag(color) + s
+					entry.Level = _debug
 				case Trc, Trace, Tracef, Tracec:
 					s = trcTag(color) + s
+					entry.Level = _trace
 				}
+				History.Add(entry)
 				if color {
 					t = colorstring.Color("[light_gray]" + t + "[dark_gray]")
 				}
@@ -6,5 +6,7 @@
 //
 // This library provides a logging subsystem that works by pushing logging data into channels and allowing the cost of logging to be minimised especially in tight loop situations. To this end also there is a closure channel type that lets you defer the query of data for a log indefinitely if the log level is currenntly inactive.
 //
+// Where an entry came from can be added with Here, which captures the caller's location and a shallow stack for the levels SetCapture asks for, by default only errors and warnings, so the cost is not paid in tight loops logging at trace level. The location is attached to the history Entry and written at the end of the line.
+//
 // The main benefit of using channels to coordinate logging is that it allows logging to occupy a separate thread to execution, meaning issues involving blocking on the processing but especially output to pipes and tty devices never affects main loops directly.
 package cl
@@ -0,0 +1,101 @@
+package cl
+import (
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+// DefaultHistory is the number of entries kept by History
+const DefaultHistory = 1000
+// Entry is a log entry as it was written, without colour codes, for consumers other than Writer such as log viewers
+type Entry struct {
+	Time  time.Time
+	Level int
+	Text  string
+	// Location is where the entry was logged from, if it was given one with Here or Ine
+	Location *Location
+}
+// LevelName returns the name of the entry's level as used in Levels
+func (e Entry) LevelName() string {
+	return LevelName(e.Level)
+}
+// LevelName returns the name in Levels of a numeric level, or "off" if there is none
+func LevelName(level int) string {
+	for name, l := range Levels {
+		if l == level {
+			return name
+		}
+	}
+	return "off"
+}
+// Ring keeps the most recent log entries and passes new ones on to subscribers
+type Ring struct {
+	mutex   sync.Mutex
+	entries []Entry
+	next    int
+	full    bool
+	subs    map[chan Entry]struct{}
+}
+// History is the ring that receives every entry the logger writes
+var History = NewRing(DefaultHistory)
+// NewRing creates a ring keeping the last size entries
+func NewRing(size int) *Ring {
+	if size < 1 {
+		size = 1
+	}
+	return &Ring{
+		entries: make([]Entry, size),
+		subs:    make(map[chan Entry]struct{}),
+	}
+}
+// Add stores an entry, displacing the oldest if the ring is full, and sends it to the subscribers. A subscriber whose buffer is full misses the entry so a stalled reader never blocks logging.
+func (r *Ring) Add(e Entry) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.entries[r.next] = e
+	r.next++
+	if r.next == len(r.entries) {
+		r.next = 0
+		r.full = true
+	}
+	for ch := range r.subs {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+// Entries returns the stored entries, oldest first
+func (r *Ring) Entries() []Entry {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.entriesLocked()
+}
+func (r *Ring) entriesLocked() (out []Entry) {
+	if r.full {
+		out = append(out, r.entries[r.next:]...)
+	}
+	return append(out, r.entries[:r.next]...)
+}
+// Subscribe returns the stored entries and a channel, buffered to hold size entries, receiving every entry added after them, so a reader sees the log without gaps. Calling cancel ends the subscription and closes the channel.
+func (r *Ring) Subscribe(size int) (recent []Entry, ch <-chan Entry, cancel func()) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	c := make(chan Entry, size)
+	r.subs[c] = struct{}{}
+	var once sync.Once
+	cancel = func() {
+		once.Do(func() {
+			r.mutex.Lock()
+			delete(r.subs, c)
+			close(c)
+			r.mutex.Unlock()
+		})
+	}
+	return r.entriesLocked(), c, cancel
+}
+var colorCodes = regexp.MustCompile("\x1b\\[[0-9;]*m")
+// stripColor removes terminal colour codes and the trailing newline from a formatted entry
+func stripColor(s string) string {
+	return strings.TrimRight(colorCodes.ReplaceAllString(s, ""), "\n")
+}
@@ -0,0 +1,49 @@
+package clipboard
+import (
+	"encoding/base64"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+// MaxOSC52 is the longest OSC 52 sequence sent, as terminals ignore longer ones
+const MaxOSC52 = 100000
+// ErrUnavailable is returned when there is no clipboard command and the text is too long for OSC 52
+var ErrUnavailable = errors.New("clipboard: no clipboard command found and the text is too long for the terminal")
+// Terminal receives the OSC 52 sequences. It is the terminal the TUI apps draw on.
+var Terminal io.Writer = os.Stdout
+// Copy puts text on the clipboard. The terminal is sent an OSC 52 sequence, which terminals that support it put on the clipboard even over ssh, and the first clipboard command found for the platform is run as well. An error is only returned if neither way could be tried or the command failed.
+func Copy(text string) error {
+	osc := OSC52(text)
+	if len(osc) <= MaxOSC52 {
+		if _, err := io.WriteString(Terminal, osc); err != nil {
+			osc = ""
+		}
+	} else {
+		osc = ""
+	}
+	for _, command := range commands() {
+		if _, err := exec.LookPath(command[0]); err != nil {
+			continue
+		}
+		cmd := exec.Command(command[0], command[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		return cmd.Run()
+	}
+	if osc == "" {
+		return ErrUnavailable
+	}
+	return nil
+}
+// OSC52 returns the escape sequence that sets the clipboard to text, wrapped so tmux and screen pass it on to the terminal they run in
+func OSC52(text string) string {
+	seq := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\a"
+	switch {
+	case os.Getenv("TMUX") != "":
+		return "\x1bPtmux;" + strings.Replace(seq, "\x1b", "\x1b\x1b", -1) + "\x1b\\"
+	case strings.HasPrefix(os.Getenv("TERM"), "screen"):
+		return "\x1bP" + seq + "\x1b\\"
+	}
+	return seq
+}
@@ -0,0 +1,5 @@
+package clipboard
+// commands returns the clipboard commands to try, in order
+func commands() [][]string {
+	return [][]string{{"pbcopy"}}
+}
@@ -0,0 +1,56 @@
+package clipboard
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+func TestOSC52(t *testing.T) {
+	tmux, term := os.Getenv("TMUX"), os.Getenv("TERM")
+	defer func() {
+		os.Setenv("TMUX", tmux)
+		os.Setenv("TERM", term)
+	}()
+	os.Setenv("TMUX", "")
+	os.Setenv("TERM", "xterm-256color")
+	if got, want := OSC52("hello"), "\x1b]52;c;aGVsbG8=\a"; got != want {
+		t.Errorf("plain: got %q want %q", got, want)
+	}
+	os.Setenv("TERM", "screen")
+	if got, want := OSC52("hello"), "\x1bP\x1b]52;c;aGVsbG8=\a\x1b\\"; got != want {
+		t.Errorf("screen: got %q want %q", got, want)
+	}
+	os.Setenv("TMUX", "/tmp/tmux-0/default,1,0")
+	if got, want := OSC52("hello"), "\x1bPtmux;\x1b\x1b]52;c;aGVsbG8=\a\x1b\\"; got != want {
+		t.Errorf("tmux: got %q want %q", got, want)
+	}
+}
+func TestCopyWritesTerminal(t *testing.T) {
+	path, display, wayland := os.Getenv("PATH"), os.Getenv("DISPLAY"), os.Getenv("WAYLAND_DISPLAY")
+	terminal := Terminal
+	defer func() {
+		os.Setenv("PATH", path)
+		os.Setenv("DISPLAY", display)
+		os.Setenv("WAYLAND_DISPLAY", wayland)
+		Terminal = terminal
+	}()
+	// with no clipboard commands to find only the terminal is used
+	os.Setenv("PATH", "")
+	os.Setenv("DISPLAY", "")
+	os.Setenv("WAYLAND_DISPLAY", "")
+	var b bytes.Buffer
+	Terminal = &b
+	if err := Copy("address"); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != OSC52("address") {
+		t.Errorf("terminal got %q", b.String())
+	}
+	b.Reset()
+	long := make([]byte, MaxOSC52)
+	if err := Copy(string(long)); err != ErrUnavailable {
+		t.Errorf("too long: got %v want %v", err, ErrUnavailable)
+	}
+	if b.Len() != 0 {
+		t.Errorf("too long text was sent to the terminal")
+	}
+}
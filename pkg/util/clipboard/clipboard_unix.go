@@ -0,0 +1,18 @@
+// +build !darwin,!windows
+
+
+package clipboard
+import "os"
+// commands returns the clipboard commands to try, in order. Without a display only termux has a clipboard to reach; otherwise OSC 52 has to do.
+func commands() (c [][]string) {
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		c = append(c, []string{"wl-copy"})
+	}
+	if os.Getenv("DISPLAY") != "" {
+		c = append(c,
+			[]string{"xclip", "-selection", "clipboard"},
+			[]string{"xsel", "--clipboard", "--input"},
+		)
+	}
+	return append(c, []string{"termux-clipboard-set"})
+}
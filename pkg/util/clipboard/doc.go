@@ -0,0 +1,2 @@
+// Package clipboard copies text to the system clipboard from terminal apps, using the platform's clipboard command where there is one and the OSC 52 terminal escape sequence, which also reaches the clipboard of the machine the terminal runs on over ssh.
+package clipboard
@@ -0,0 +1,60 @@
+package ec
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+// BatchItem is a signature of a hash to check against a public key with VerifyBatch.
+type BatchItem struct {
+	Signature *Signature
+	Hash      []byte
+	PubKey    *PublicKey
+}
+// batchThreshold is the size of batch below which spreading it across cores costs more than it saves.
+const batchThreshold = 16
+// VerifyBatch checks a batch of signatures, such as all of those in a block, spread across every core. It returns the index of the first signature that is not valid, or -1 if they all are. Checking stops early once an invalid signature is found, though all of those before it are still checked so the index returned is the same however the work was split.
+func VerifyBatch(
+	items []BatchItem) int {
+	threads := runtime.NumCPU()
+	if len(items) < batchThreshold || threads == 1 {
+		for i := range items {
+			if !items[i].verify() {
+				return i
+			}
+		}
+		return -1
+	}
+	first := int64(len(items))
+	chunk := (len(items) + threads - 1) / threads
+	var wg sync.WaitGroup
+	for start := 0; start < len(items); start += chunk {
+		end := start + chunk
+		if end > len(items) {
+			end = len(items)
+		}
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			for i := start; i < end && int64(i) < atomic.LoadInt64(&first); i++ {
+				if items[i].verify() {
+					continue
+				}
+				for {
+					f := atomic.LoadInt64(&first)
+					if int64(i) >= f || atomic.CompareAndSwapInt64(&first, f, int64(i)) {
+						return
+					}
+				}
+			}
+		}(start, end)
+	}
+	wg.Wait()
+	if first == int64(len(items)) {
+		return -1
+	}
+	return int(first)
+}
+// verify checks the signature of the item, treating missing parts as invalid.
+func (b *BatchItem) verify() bool {
+	return b.Signature != nil && b.PubKey != nil && b.Signature.Verify(b.Hash, b.PubKey)
+}
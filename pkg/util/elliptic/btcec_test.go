@@ -720,6 +720,74 @@ func TestScalarMultRand(
 		}
 	}
 }
+// TestScalarMultSecret ensures the constant time scalar multiplications agree with the variable time ones, including for scalars that are zero, the group order or longer than it.
+func TestScalarMultSecret(
+
+	t *testing.T) {
+
+	s256 := S256()
+	scalars := [][]byte{{}, {0}, {1}, s256.N.Bytes(), new(big.Int).Sub(s256.N, one).Bytes(),
+		fromHex("d74bf844b0862475103d96a611cf2d898447e288d34b360bc885cb8ce7c00575").Bytes()}
+
+	for i := 0; i < 20; i++ {
+
+		data := make([]byte, 1+i*2)
+		if _, err := rand.Read(data); err != nil {
+
+			t.Fatalf("failed to read random data at %d", i)
+		}
+		scalars = append(scalars, data)
+	}
+	px, py := s256.ScalarBaseMult([]byte{7})
+
+	for _, k := range scalars {
+
+		x, y := s256.scalarBaseMultSecret(k)
+		xWant, yWant := s256.ScalarBaseMult(k)
+
+		if x.Cmp(xWant) != 0 || y.Cmp(yWant) != 0 {
+
+			t.Errorf("base mult of %X: got (%X, %X), want (%X, %X)", k, x, y, xWant, yWant)
+		}
+		x, y = s256.scalarMultSecret(px, py, k)
+		xWant, yWant = s256.ScalarMult(px, py, k)
+
+		if x.Cmp(xWant) != 0 || y.Cmp(yWant) != 0 {
+
+			t.Errorf("mult of %X: got (%X, %X), want (%X, %X)", k, x, y, xWant, yWant)
+		}
+	}
+}
+// TestInverseModN ensures the constant time inversion modulo the group order agrees with ModInverse, including for the smallest and largest values it inverts.
+func TestInverseModN(
+	t *testing.T) {
+
+	s256 := S256()
+	values := []*big.Int{one, two, new(big.Int).Sub(s256.N, one),
+		new(big.Int).Sub(s256.N, two), s256.halfOrder}
+
+	for i := 0; i < 50; i++ {
+
+		data := make([]byte, 32)
+		if _, err := rand.Read(data); err != nil {
+
+			t.Fatalf("failed to read random data at %d", i)
+		}
+		k := new(big.Int).SetBytes(data)
+		k.Mod(k, new(big.Int).Sub(s256.N, one)).Add(k, one)
+		values = append(values, k)
+	}
+
+	for _, k := range values {
+
+		want := new(big.Int).ModInverse(k, s256.N)
+
+		if got := s256.inverseModN(k); got.Cmp(want) != 0 {
+
+			t.Errorf("inverse of %X: got %X, want %X", k, got, want)
+		}
+	}
+}
 func TestSplitK(
 
 	t *testing.T) {
@@ -32,9 +32,16 @@ var (
 // GenerateSharedSecret generates a shared secret based on a private key and a
 // public key using Diffie-Hellman key exchange (ECDH) (RFC 4753).
 // RFC5903 Section 9 states we should only return x.
+// On secp256k1 the multiplication by the private key takes the same time
+// whatever the key is.
 func GenerateSharedSecret(
 	privkey *PrivateKey, pubkey *PublicKey) []byte {
-	x, _ := pubkey.Curve.ScalarMult(pubkey.X, pubkey.Y, privkey.D.Bytes())
+	d := paddedAppend(PrivKeyBytesLen, nil, privkey.D.Bytes())
+	if curve, ok := pubkey.Curve.(*KoblitzCurve); ok {
+		x, _ := curve.scalarMultSecret(pubkey.X, pubkey.Y, d)
+		return x.Bytes()
+	}
+	x, _ := pubkey.Curve.ScalarMult(pubkey.X, pubkey.Y, d)
 	return x.Bytes()
 }
 // Encrypt encrypts data for the target public key using AES-256-CBC. It also
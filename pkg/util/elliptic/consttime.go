@@ -0,0 +1,272 @@
+package ec
+import (
+	"crypto/subtle"
+	"math/big"
+	"math/bits"
+	"sync"
+)
+// The scalar multiplications in btcec.go skip work for zero digits and take shortcuts for special points, so the time they take depends on the scalar. That is fine for the public scalars of signature verification, but leaks secrets when the scalar is a private key or a nonce. The multiplications here are for secret scalars instead: they use the complete addition formulas, which have no special cases, go through every digit of the scalar and read their tables in full, selecting entries with masks rather than indexing them.
+// ctPoint is a point in homogeneous projective coordinates (X:Y:Z), where x = X/Z and y = Y/Z, and the point at infinity is (0:1:0).
+type ctPoint struct {
+	x, y, z fieldVal
+}
+// b3 is 3*b for the secp256k1 curve equation y² = x³ + 7, as used by the complete addition formulas.
+const b3 = 21
+// ctBaseTable holds, for each byte of a 32 byte big endian scalar, the multiples of the base point for the values of its high and low nibbles, built from the byte points on first use.
+var (
+	ctBaseTable     [32][2][16]ctPoint
+	ctBaseTableOnce sync.Once
+)
+// setInfinity sets p to the point at infinity.
+func (p *ctPoint) setInfinity() *ctPoint {
+	p.x.Zero()
+	p.y.SetInt(1)
+	p.z.Zero()
+	return p
+}
+// setJacobian sets p to the Jacobian point (x, y, z), which is (x*z : y : z³) in projective coordinates. The point at infinity must be set with setInfinity instead.
+func (p *ctPoint) setJacobian(x, y, z *fieldVal) *ctPoint {
+	var zz fieldVal
+	zz.SquareVal(z)
+	p.x.Mul2(x, z).Normalize()
+	p.y.Set(y).Normalize()
+	p.z.Mul2(&zz, z).Normalize()
+	return p
+}
+// cmov sets p to q if flag is 1 and leaves it as it is if flag is 0, taking the same time either way.
+func (p *ctPoint) cmov(q *ctPoint, flag int) {
+	mask := uint32(-flag)
+	for i := 0; i < fieldWords; i++ {
+		p.x.n[i] ^= mask & (p.x.n[i] ^ q.x.n[i])
+		p.y.n[i] ^= mask & (p.y.n[i] ^ q.y.n[i])
+		p.z.n[i] ^= mask & (p.z.n[i] ^ q.z.n[i])
+	}
+}
+// selectPoint sets p to table[index] by reading every entry of the table.
+func (p *ctPoint) selectPoint(table *[16]ctPoint, index byte) {
+	*p = table[0]
+	for i := 1; i < len(table); i++ {
+		p.cmov(&table[i], subtle.ConstantTimeByteEq(byte(i), index))
+	}
+}
+// add sets p to p1 + p2 using Algorithm 7 of Renes, Costello and Batina, "Complete addition formulas for prime order elliptic curves", for curves with a = 0. The formulas are the same for doubling and for the point at infinity, so p1 and p2 may be any points, and p may be either of them. Intermediate values are normalized wherever their magnitude would grow too large for the field arithmetic.
+func (p *ctPoint) add(p1, p2 *ctPoint) *ctPoint {
+	var t0, t1, t2, t3, t4, x3, y3, z3, n fieldVal
+	t0.Mul2(&p1.x, &p2.x)                    // t0 = X1*X2 (mag: 1)
+	t1.Mul2(&p1.y, &p2.y)                    // t1 = Y1*Y2 (mag: 1)
+	t2.Mul2(&p1.z, &p2.z)                    // t2 = Z1*Z2 (mag: 1)
+	t3.Add2(&p1.x, &p1.y)                    // t3 = X1+Y1 (mag: 2)
+	t4.Add2(&p2.x, &p2.y)                    // t4 = X2+Y2 (mag: 2)
+	t3.Mul(&t4)                              // t3 = t3*t4 (mag: 1)
+	t4.Add2(&t0, &t1)                        // t4 = t0+t1 (mag: 2)
+	t3.Add(n.NegateVal(&t4, 2))              // t3 = t3-t4 (mag: 4)
+	t4.Add2(&p1.y, &p1.z)                    // t4 = Y1+Z1 (mag: 2)
+	x3.Add2(&p2.y, &p2.z)                    // X3 = Y2+Z2 (mag: 2)
+	t4.Mul(&x3)                              // t4 = t4*X3 (mag: 1)
+	x3.Add2(&t1, &t2)                        // X3 = t1+t2 (mag: 2)
+	t4.Add(n.NegateVal(&x3, 2))              // t4 = t4-X3 (mag: 4)
+	x3.Add2(&p1.x, &p1.z)                    // X3 = X1+Z1 (mag: 2)
+	y3.Add2(&p2.x, &p2.z)                    // Y3 = X2+Z2 (mag: 2)
+	x3.Mul(&y3)                              // X3 = X3*Y3 (mag: 1)
+	y3.Add2(&t0, &t2)                        // Y3 = t0+t2 (mag: 2)
+	y3.NegateVal(&y3, 2).Add(&x3)            // Y3 = X3-Y3 (mag: 4)
+	x3.Add2(&t0, &t0)                        // X3 = t0+t0 (mag: 2)
+	t0.Add(&x3)                              // t0 = X3+t0 (mag: 3)
+	t2.MulInt(b3)                            // t2 = b3*t2 (mag: 21)
+	z3.Add2(&t1, &t2).Normalize()            // Z3 = t1+t2 (mag: 1)
+	t1.Add(n.NegateVal(&t2, 21)).Normalize() // t1 = t1-t2 (mag: 1)
+	y3.Normalize().MulInt(b3).Normalize()    // Y3 = b3*Y3 (mag: 1)
+	x3.Mul2(&t4, &y3)                        // X3 = t4*Y3 (mag: 1)
+	t2.Mul2(&t3, &t1)                        // t2 = t3*t1 (mag: 1)
+	x3.NegateVal(&x3, 1).Add(&t2)            // X3 = t2-X3 (mag: 3)
+	y3.Mul(&t0)                              // Y3 = Y3*t0 (mag: 1)
+	t1.Mul(&z3)                              // t1 = t1*Z3 (mag: 1)
+	y3.Add(&t1)                              // Y3 = t1+Y3 (mag: 2)
+	t0.Mul(&t3)                              // t0 = t0*t3 (mag: 1)
+	z3.Mul(&t4)                              // Z3 = Z3*t4 (mag: 1)
+	z3.Add(&t0)                              // Z3 = Z3+t0 (mag: 2)
+	p.x.Set(x3.Normalize())
+	p.y.Set(y3.Normalize())
+	p.z.Set(z3.Normalize())
+	return p
+}
+// toBigAffine returns p in affine coordinates as big integers, which are (0, 0) for the point at infinity as crypto/elliptic has it. The inverse is taken by exponentiation, which takes the same time for any value.
+func (p *ctPoint) toBigAffine() (*big.Int, *big.Int) {
+	var zInv, x, y fieldVal
+	zInv.Set(&p.z).Inverse()
+	x.Mul2(&p.x, &zInv).Normalize()
+	y.Mul2(&p.y, &zInv).Normalize()
+	return new(big.Int).SetBytes(x.Bytes()[:]), new(big.Int).SetBytes(y.Bytes()[:])
+}
+// secretScalar returns k as 32 big endian bytes, reducing it modulo the group order first if it is longer. Only the length of k determines which is done.
+func (curve *KoblitzCurve) secretScalar(k []byte) *[32]byte {
+	var s [32]byte
+	k = curve.moduloReduce(k)
+	copy(s[32-len(k):], k)
+	return &s
+}
+// initCTBaseTable fills ctBaseTable from the byte points, the multiples for a nibble being the byte point for the nibble in the high or the low half of the byte.
+func (curve *KoblitzCurve) initCTBaseTable() {
+	for i := range ctBaseTable {
+		for half, shift := range []uint{4, 0} {
+			ctBaseTable[i][half][0].setInfinity()
+			for j := 1; j < 16; j++ {
+				p := &curve.bytePoints[i][j<<shift]
+				ctBaseTable[i][half][j].setJacobian(&p[0], &p[1], &p[2])
+			}
+		}
+	}
+}
+// scalarBaseMultSecret returns k*G like ScalarBaseMult, taking the same time for any k of a given length, for when k is a private key or a nonce. It adds one table entry for each nibble of k, so it is around twice as slow as ScalarBaseMult.
+func (curve *KoblitzCurve) scalarBaseMultSecret(k []byte) (*big.Int, *big.Int) {
+	ctBaseTableOnce.Do(curve.initCTBaseTable)
+	s := curve.secretScalar(k)
+	var q, t ctPoint
+	q.setInfinity()
+	for i, b := range s {
+		t.selectPoint(&ctBaseTable[i][0], b>>4)
+		q.add(&q, &t)
+		t.selectPoint(&ctBaseTable[i][1], b&0xf)
+		q.add(&q, &t)
+	}
+	return q.toBigAffine()
+}
+// scalarMultSecret returns k*(Bx, By) like ScalarMult, taking the same time for any k of a given length, for when k is a private key. It uses a fixed window of four bits over a table of the first sixteen multiples of the point.
+func (curve *KoblitzCurve) scalarMultSecret(Bx, By *big.Int, k []byte) (*big.Int, *big.Int) {
+	s := curve.secretScalar(k)
+	var table [16]ctPoint
+	table[0].setInfinity()
+	bx, by := curve.bigAffineToField(Bx, By)
+	table[1].x.Set(bx).Normalize()
+	table[1].y.Set(by).Normalize()
+	table[1].z.SetInt(1)
+	for i := 2; i < len(table); i++ {
+		table[i].add(&table[i-1], &table[1])
+	}
+	var q, t ctPoint
+	q.setInfinity()
+	for _, b := range s {
+		for _, nibble := range []byte{b >> 4, b & 0xf} {
+			for i := 0; i < 4; i++ {
+				q.add(&q, &q)
+			}
+			t.selectPoint(&table, nibble)
+			q.add(&q, &t)
+		}
+	}
+	return q.toBigAffine()
+}
+// ctScalar is an integer modulo the group order N in Montgomery form, x*R mod N with R = 2^256, as four 64 bit limbs from the least significant.
+type ctScalar [4]uint64
+// ctScalarN, ctScalarNInv, ctScalarRR and ctScalarNMinus2 are the constants of the Montgomery arithmetic modulo the group order, computed from it on first use by initCTScalar.
+var (
+	ctScalarN       ctScalar
+	ctScalarNInv    uint64
+	ctScalarRR      ctScalar
+	ctScalarNMinus2 [32]byte
+	ctScalarOnce    sync.Once
+)
+// setBig sets s to the limbs of x, which must be less than 2^256, without converting it to Montgomery form.
+func (s *ctScalar) setBig(x *big.Int) *ctScalar {
+	var b [32]byte
+	copy(b[:], paddedAppend(32, nil, x.Bytes()))
+	for i := range s {
+		s[i] = uint64(b[31-8*i]) | uint64(b[30-8*i])<<8 | uint64(b[29-8*i])<<16 |
+			uint64(b[28-8*i])<<24 | uint64(b[27-8*i])<<32 | uint64(b[26-8*i])<<40 |
+			uint64(b[25-8*i])<<48 | uint64(b[24-8*i])<<56
+	}
+	return s
+}
+// big returns the limbs of s as a big integer, without converting it from Montgomery form.
+func (s *ctScalar) big() *big.Int {
+	var b [32]byte
+	for i := range s {
+		for j := 0; j < 8; j++ {
+			b[31-8*i-j] = byte(s[i] >> (8 * uint(j)))
+		}
+	}
+	return new(big.Int).SetBytes(b[:])
+}
+// initCTScalar computes the constants of the Montgomery arithmetic modulo the group order: its limbs, -N^-1 mod 2^64, R^2 mod N and the exponent N-2 of the inversion.
+func (curve *KoblitzCurve) initCTScalar() {
+	ctScalarN.setBig(curve.N)
+	// Newton's iteration doubles the number of correct low bits of the inverse each step, and N is odd so N is its own inverse modulo 8.
+	inv := ctScalarN[0]
+	for i := 0; i < 5; i++ {
+		inv *= 2 - ctScalarN[0]*inv
+	}
+	ctScalarNInv = -inv
+	rr := new(big.Int).Lsh(one, 512)
+	ctScalarRR.setBig(rr.Mod(rr, curve.N))
+	copy(ctScalarNMinus2[:], paddedAppend(32, nil, new(big.Int).Sub(curve.N, two).Bytes()))
+}
+// mul sets s to a*b*R^-1 mod N by Montgomery multiplication, taking the same time for any a and b less than N. s may be either of them.
+func (s *ctScalar) mul(a, b *ctScalar) *ctScalar {
+	var t [6]uint64
+	for i := 0; i < 4; i++ {
+		// t += a*b[i]
+		var c uint64
+		for j := 0; j < 4; j++ {
+			hi, lo := bits.Mul64(a[j], b[i])
+			var carry uint64
+			lo, carry = bits.Add64(lo, t[j], 0)
+			hi += carry
+			lo, carry = bits.Add64(lo, c, 0)
+			hi += carry
+			t[j], c = lo, hi
+		}
+		var carry uint64
+		t[4], carry = bits.Add64(t[4], c, 0)
+		t[5] = carry
+		// t = (t + m*N) / 2^64, with m chosen so the low limb is zero.
+		m := t[0] * ctScalarNInv
+		hi, lo := bits.Mul64(m, ctScalarN[0])
+		_, carry = bits.Add64(lo, t[0], 0)
+		c = hi + carry
+		for j := 1; j < 4; j++ {
+			hi, lo = bits.Mul64(m, ctScalarN[j])
+			lo, carry = bits.Add64(lo, t[j], 0)
+			hi += carry
+			lo, carry = bits.Add64(lo, c, 0)
+			hi += carry
+			t[j-1], c = lo, hi
+		}
+		t[3], carry = bits.Add64(t[4], c, 0)
+		t[4] = t[5] + carry
+	}
+	// t is less than 2N, so subtracting N once when it is not less than N reduces it, choosing the result with a mask.
+	var d ctScalar
+	var borrow uint64
+	for i := 0; i < 4; i++ {
+		d[i], borrow = bits.Sub64(t[i], ctScalarN[i], borrow)
+	}
+	_, borrow = bits.Sub64(t[4], 0, borrow)
+	mask := -borrow
+	for i := 0; i < 4; i++ {
+		s[i] = t[i]&mask | d[i]&^mask
+	}
+	return s
+}
+// inverseModN returns k^-1 mod N for a k between 1 and N-1, taking the same time for any k, for when k is a nonce. It raises k to N-2 by Fermat's little theorem with a fixed window of four bits, so the same squarings and multiplications are done in the same order whatever k is; the table is indexed by the digits of the exponent, which are public.
+func (curve *KoblitzCurve) inverseModN(k *big.Int) *big.Int {
+	ctScalarOnce.Do(curve.initCTScalar)
+	var x ctScalar
+	x.setBig(k).mul(&x, &ctScalarRR)
+	var table [16]ctScalar
+	table[0].setBig(one).mul(&table[0], &ctScalarRR)
+	for i := 1; i < len(table); i++ {
+		table[i].mul(&table[i-1], &x)
+	}
+	q := table[0]
+	for _, b := range ctScalarNMinus2 {
+		for _, nibble := range []byte{b >> 4, b & 0xf} {
+			for i := 0; i < 4; i++ {
+				q.mul(&q, &q)
+			}
+			q.mul(&q, &table[nibble])
+		}
+	}
+	var unit ctScalar
+	unit.setBig(one)
+	return q.mul(&q, &unit).big()
+}
@@ -3,6 +3,7 @@ import (
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
+	"io"
 	"math/big"
 )
 // PrivateKey wraps an ecdsa.PrivateKey as a convenience mainly for signing
@@ -10,11 +11,17 @@ import (
 // package.
 type PrivateKey ecdsa.PrivateKey
 // PrivKeyFromBytes returns a private and public key for `curve' based on the
-// private key passed as an argument as a byte slice.
+// private key passed as an argument as a byte slice. On secp256k1 the public
+// key is computed in the same time whatever the private key is.
 func PrivKeyFromBytes(
 	curve elliptic.Curve, pk []byte) (*PrivateKey,
 	*PublicKey) {
-	x, y := curve.ScalarBaseMult(pk)
+	var x, y *big.Int
+	if kc, ok := curve.(*KoblitzCurve); ok {
+		x, y = kc.scalarBaseMultSecret(pk)
+	} else {
+		x, y = curve.ScalarBaseMult(pk)
+	}
 	priv := &ecdsa.PrivateKey{
 		PublicKey: ecdsa.PublicKey{
 			Curve: curve,
@@ -26,14 +33,28 @@ func PrivKeyFromBytes(
 	return (*PrivateKey)(priv), (*PublicKey)(&priv.PublicKey)
 }
 // NewPrivateKey is a wrapper for ecdsa.GenerateKey that returns a PrivateKey
-// instead of the normal ecdsa.PrivateKey.
+// instead of the normal ecdsa.PrivateKey. On secp256k1 it picks the key the
+// same way, with 64 more random bits than the order so the key is uniform after
+// the reduction, but computes the public key with PrivKeyFromBytes.
 func NewPrivateKey(
 	curve elliptic.Curve) (*PrivateKey, error) {
-	key, err := ecdsa.GenerateKey(curve, rand.Reader)
-	if err != nil {
+	kc, ok := curve.(*KoblitzCurve)
+	if !ok {
+		key, err := ecdsa.GenerateKey(curve, rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		return (*PrivateKey)(key), nil
+	}
+	b := make([]byte, kc.byteSize+8)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
 		return nil, err
 	}
-	return (*PrivateKey)(key), nil
+	d := new(big.Int).SetBytes(b)
+	d.Mod(d, new(big.Int).Sub(kc.N, one))
+	d.Add(d, one)
+	key, _ := PrivKeyFromBytes(kc, paddedAppend(PrivKeyBytesLen, nil, d.Bytes()))
+	return key, nil
 }
 // PubKey returns the PublicKey corresponding to this private key.
 func (p *PrivateKey) PubKey() *PublicKey {
@@ -23,6 +23,8 @@ type Signature struct {
 var (
 	// Used in RFC6979 implementation when testing the nonce for correctness
 	one = big.NewInt(1)
+	// two is subtracted from the order for inverting nonces by Fermat's little theorem
+	two = big.NewInt(2)
 	// oneInitializer is used to fill a byte slice with byte 0x01.  It is provided here to avoid the need to create it multiple times.
 	oneInitializer = []byte{0x01}
 )
@@ -312,15 +314,15 @@ func RecoverCompact(
 	}
 	return key, ((signature[0] - 27) & 4) == 4, nil
 }
-// signRFC6979 generates a deterministic ECDSA signature according to RFC 6979 and BIP 62.
+// signRFC6979 generates a deterministic ECDSA signature according to RFC 6979 and BIP 62. R is computed from the nonce in the same time whatever the nonce is, and the nonce is inverted with the fixed window exponentiation of inverseModN rather than by ModInverse, whose steps depend on the value inverted.
 func signRFC6979(
 	privateKey *PrivateKey, hash []byte) (*Signature, error) {
 	privkey := privateKey.ToECDSA()
 	N := S256().N
 	halfOrder := S256().halfOrder
 	k := nonceRFC6979(privkey.D, hash)
-	inv := new(big.Int).ModInverse(k, N)
-	r, _ := privkey.Curve.ScalarBaseMult(k.Bytes())
+	inv := S256().inverseModN(k)
+	r, _ := S256().scalarBaseMultSecret(paddedAppend(PrivKeyBytesLen, nil, k.Bytes()))
 	if r.Cmp(N) == 1 {
 		r.Sub(r, N)
 	}
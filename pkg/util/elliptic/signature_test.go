@@ -736,3 +736,47 @@ func TestSignatureIsEqual(
 			"equal to %v", sig1, sig2)
 	}
 }
+// TestVerifyBatch ensures VerifyBatch finds the first invalid signature of a batch whether it checks it on one core or spread across them.
+func TestVerifyBatch(
+
+	t *testing.T) {
+
+	items := make([]BatchItem, 40)
+
+	for i := range items {
+
+		priv, err := NewPrivateKey(S256())
+		if err != nil {
+
+			t.Fatal(err)
+		}
+		hash := sha256.Sum256([]byte{byte(i)})
+		sig, err := priv.Sign(hash[:])
+		if err != nil {
+
+			t.Fatal(err)
+		}
+		items[i] = BatchItem{Signature: sig, Hash: hash[:], PubKey: priv.PubKey()}
+	}
+
+	for _, n := range []int{0, 5, len(items)} {
+
+		if i := VerifyBatch(items[:n]); i != -1 {
+
+			t.Errorf("batch of %d valid signatures failed at %d", n, i)
+		}
+	}
+	items[33].Hash = items[32].Hash
+	items[21].PubKey = items[20].PubKey
+
+	if i := VerifyBatch(items); i != 21 {
+
+		t.Errorf("batch failed at %d, want 21", i)
+	}
+	items[3].Signature = nil
+
+	if i := VerifyBatch(items[:10]); i != 3 {
+
+		t.Errorf("small batch failed at %d, want 3", i)
+	}
+}
@@ -9,6 +9,8 @@ The Master Node
 As previously mentioned, the extended keys are hierarchical meaning they are used to form a tree.  The root of that tree is called the master node and this package provides the NewMaster function to create it from a cryptographically random seed.  The GenerateSeed function is provided as a convenient way to create a random seed for use with the NewMaster function.
 Deriving Children
 Once you have created a tree root (or have deserialized an extended key as discussed later), the child extended keys can be derived by using the Child function.  The Child function supports deriving both normal (non-hardened) and hardened child extended keys.  In order to derive a hardened extended key, use the  HardenedKeyStart constant + the hardened key number as the index to the Child function.  This provides the ability to cascade the keys into a tree and hence generate the hierarchical deterministic key chains.
+Derivation Paths
+A key further down the tree can be derived in one step with the Derive function, given the Path of child indexes leading to it.  ParsePath reads paths as they are usually written, such as m/44'/0'/0'/0/1 for the first receiving address of the first BIP0044 account, and a Path's String function writes them back.
 Normal vs Hardened Child Extended Keys
 A private extended key can be used to derive both hardened and non-hardened (normal) child private and public extended keys.  A public extended key can only be used to derive non-hardened child public extended keys.  As enumerated in BIP0032 "knowledge of the extended public key plus any non-hardened private key descending from it is equivalent to knowing the extended private key (and thus every private and public key descending from it).  This means that extended public keys must be treated more carefully than regular public keys. It is also the reason for the existence of hardened keys, and why they are used for the account level in the tree. This way, a leak of an account-specific (or below) private key never risks compromising the master or other accounts."
 Neutering a Private Extended Key
@@ -0,0 +1,59 @@
+package hdkeychain
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+// Path is a list of child indexes to derive one after another from an extended key, those from HardenedKeyStart up being hardened. The wallet's BIP0044 keys are at m/44'/<coin type>'/<account>'/<branch>/<address index>.
+type Path []uint32
+// ErrInvalidPath describes an error in which a derivation path could not be parsed.
+var ErrInvalidPath = errors.New("invalid derivation path")
+// ParsePath parses a derivation path written as in [BIP32], such as m/44'/0'/0'/0/1, with the indexes separated by slashes and hardened ones marked with ' or h. The leading m, for the key the path is derived from, may be left out, and m alone is the empty path.
+func ParsePath(
+	s string) (Path, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "m")
+	if s == "" {
+		return Path{}, nil
+	}
+	if s[0] == '/' && len(s) > 1 {
+		s = s[1:]
+	}
+	parts := strings.Split(s, "/")
+	path := make(Path, 0, len(parts))
+	for _, part := range parts {
+		var hardened uint32
+		if strings.HasSuffix(part, "'") || strings.HasSuffix(part, "h") || strings.HasSuffix(part, "H") {
+			part, hardened = part[:len(part)-1], HardenedKeyStart
+		}
+		i, err := strconv.ParseUint(part, 10, 31)
+		if err != nil || part[0] == '+' {
+			return nil, ErrInvalidPath
+		}
+		path = append(path, uint32(i)+hardened)
+	}
+	return path, nil
+}
+// String returns the path as ParsePath reads it, starting with m and with hardened indexes marked with '.
+func (p Path) String() string {
+	var b strings.Builder
+	b.WriteString("m")
+	for _, i := range p {
+		b.WriteString("/")
+		b.WriteString(strconv.FormatUint(uint64(i&^HardenedKeyStart), 10))
+		if i >= HardenedKeyStart {
+			b.WriteString("'")
+		}
+	}
+	return b.String()
+}
+// Derive returns the descendant of the extended key along the path, deriving each child in turn as Child does. It returns Child's errors, so ErrInvalidChild if any key on the way is invalid, in which case the caller is expected to pick another path.
+func (k *ExtendedKey) Derive(
+	path Path) (*ExtendedKey, error) {
+	var err error
+	for _, i := range path {
+		if k, err = k.Child(i); err != nil {
+			return nil, err
+		}
+	}
+	return k, nil
+}
@@ -0,0 +1,92 @@
+package hdkeychain
+
+import (
+	"encoding/hex"
+	"reflect"
+	"testing"
+
+	chaincfg "git.parallelcoin.io/dev/9/pkg/chain/config"
+)
+
+// TestParsePath ensures paths are parsed as written in [BIP32] and written back the same way.
+func TestParsePath(
+
+	t *testing.T) {
+
+	tests := []struct {
+		in   string
+		path Path
+		out  string
+	}{
+		{"m", Path{}, "m"},
+		{"", Path{}, "m"},
+		{"m/0'/1/2h/2/1000000000", Path{HardenedKeyStart, 1, HardenedKeyStart + 2, 2, 1000000000}, "m/0'/1/2'/2/1000000000"},
+		{"44H/0'/0'", Path{HardenedKeyStart + 44, HardenedKeyStart, HardenedKeyStart}, "m/44'/0'/0'"},
+		{"m/2147483647'", Path{0xffffffff}, "m/2147483647'"},
+	}
+
+	for _, test := range tests {
+
+		path, err := ParsePath(test.in)
+
+		if err != nil || !reflect.DeepEqual(path, test.path) {
+
+			t.Errorf("%q parsed as %v, %v", test.in, path, err)
+			continue
+		}
+
+		if path.String() != test.out {
+
+			t.Errorf("%q written as %q, want %q", test.in, path.String(), test.out)
+		}
+	}
+
+	for _, in := range []string{"m/", "m//1", "m/1/", "m/x", "m/-1", "m/+1", "m/2147483648", "m/1''", "/"} {
+
+		if path, err := ParsePath(in); err == nil {
+
+			t.Errorf("%q parsed as %v", in, path)
+		}
+	}
+}
+
+// TestDerive ensures deriving along a path gives the same key as deriving each child in turn.
+func TestDerive(
+
+	t *testing.T) {
+
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	master, err := NewMaster(seed, &chaincfg.MainNetParams)
+
+	if err != nil {
+
+		t.Fatal(err)
+	}
+	path, _ := ParsePath("m/0'/1/2'/2/1000000000")
+	key, err := master.Derive(path)
+
+	if err != nil {
+
+		t.Fatal(err)
+	}
+	want := master
+
+	for _, i := range path {
+
+		if want, err = want.Child(i); err != nil {
+
+			t.Fatal(err)
+		}
+	}
+
+	if key.String() != want.String() || key.Depth() != uint8(len(path)) {
+
+		t.Errorf("derived %s at depth %d, want %s", key, key.Depth(), want)
+	}
+	pub, _ := master.Neuter()
+
+	if _, err := pub.Derive(path); err != ErrDeriveHardFromPublic {
+
+		t.Errorf("hardened derivation from a public key returned %v", err)
+	}
+}
@@ -52,6 +52,16 @@ func TstAddressWitnessScriptHash(
 		witnessProgram: program,
 	}
 }
+// TstAddressWitnessTaproot creates an AddressWitnessTaproot, initiating the fields as given.
+func TstAddressWitnessTaproot(
+	version byte, program [32]byte,
+	hrp string) *AddressWitnessTaproot {
+	return &AddressWitnessTaproot{
+		hrp:            hrp,
+		witnessVersion: version,
+		witnessProgram: program,
+	}
+}
 // TstAddressPubKey makes an AddressPubKey, setting the unexported fields with the parameters.
 func TstAddressPubKey(
 	serializedPubKey []byte, pubKeyFormat PubKeyFormat,
@@ -69,10 +79,10 @@ func TstAddressSAddr(
 	decoded := base58.Decode(addr)
 	return decoded[1 : 1+ripemd160.Size]
 }
-// TstAddressSegwitSAddr returns the expected witness program bytes for bech32 encoded P2WPKH and P2WSH bitcoin addresses.
+// TstAddressSegwitSAddr returns the expected witness program bytes for bech32 encoded P2WPKH and P2WSH and bech32m encoded P2TR bitcoin addresses.
 func TstAddressSegwitSAddr(
 	addr string) []byte {
-	_, data, err := bech32.Decode(addr)
+	_, data, _, err := bech32.DecodeGeneric(addr)
 	if err != nil {
 		return []byte{}
 	}
@@ -83,3 +93,8 @@ func TstAddressSegwitSAddr(
 	}
 	return data
 }
+// TstMigrateAppDataDir makes the internal migrateAppDataDir function available to the test package.
+func TstMigrateAppDataDir(
+	home, appName string) (string, error) {
+	return migrateAppDataDir(home, appName)
+}
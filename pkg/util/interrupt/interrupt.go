@@ -1,11 +1,16 @@
 package interrupt
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/signal"
 	"syscall"
 )
 var requested bool
+// root is cancelled when an interrupt is signaled or a shutdown requested, before any of the handlers run
+var root, cancelRoot = context.WithCancel(context.Background())
+// subsystemKey is the context key of the name of the subsystem a context was derived for
+type subsystemKey struct{}
 // InterruptChan is used to receive SIGINT (Ctrl+C) signals.
 var InterruptChan chan os.Signal
 // InterruptSignals is the list of signals that cause the interrupt
@@ -33,11 +38,13 @@ func Listener() {
 			fmt.Printf("received signal (%s) - shutting down...\n", sig)
 			_ = sig
 			requested = true
+			cancelRoot()
 			invokeCallbacks()
 			return
 		case <-ShutdownRequestChan:
 			fmt.Println("received shutdown request - shutting down...")
 			requested = true
+			cancelRoot()
 			invokeCallbacks()
 			return
 		case handler := <-AddHandlerChannel:
@@ -48,13 +55,30 @@ func Listener() {
 // AddHandler adds a handler to call when a SIGINT (Ctrl+C) is received.
 func AddHandler(
 	handler func()) {
-	// Create the channel and start the main interrupt handler which invokes all other callbacks and exits if not already done.
+	listen()
+	AddHandlerChannel <- handler
+}
+// listen creates the channel and starts the main interrupt handler which invokes all other callbacks and exits if not already done.
+func listen() {
 	if InterruptChan == nil {
 		InterruptChan = make(chan os.Signal, 1)
 		signal.Notify(InterruptChan, InterruptSignals...)
 		go Listener()
 	}
-	AddHandlerChannel <- handler
+}
+// Context returns the root context, which is cancelled on SIGINT (Ctrl+C) or when a shutdown is requested, so code can stop on ctx.Done() instead of registering a handler. It is cancelled before the handlers are run.
+func Context() context.Context {
+	listen()
+	return root
+}
+// Subsystem derives a context for a subsystem from the root context, carrying its name for SubsystemName. The subsystem cancels it when it stops on its own, and it is cancelled with the root context on shutdown.
+func Subsystem(name string) (context.Context, context.CancelFunc) {
+	return context.WithCancel(context.WithValue(Context(), subsystemKey{}, name))
+}
+// SubsystemName returns the name of the subsystem the context was derived for, or an empty string if it was not derived with Subsystem
+func SubsystemName(ctx context.Context) string {
+	name, _ := ctx.Value(subsystemKey{}).(string)
+	return name
 }
 // Request programatically requests a shutdown
 func Request() {
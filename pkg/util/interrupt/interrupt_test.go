@@ -0,0 +1,31 @@
+package interrupt
+import (
+	"context"
+	"testing"
+	"time"
+)
+func TestContext(t *testing.T) {
+	ctx := Context()
+	sub, cancel := Subsystem("test")
+	defer cancel()
+	other, cancelOther := Subsystem("other")
+	cancelOther()
+	if SubsystemName(sub) != "test" || SubsystemName(ctx) != "" {
+		t.Errorf("subsystem names are %q and %q", SubsystemName(sub), SubsystemName(ctx))
+	}
+	if other.Err() == nil || ctx.Err() != nil || sub.Err() != nil {
+		t.Fatal("cancelling a subsystem cancelled the others")
+	}
+	Request()
+	for _, c := range []context.Context{ctx, sub} {
+		select {
+		case <-c.Done():
+		case <-time.After(5 * time.Second):
+			t.Fatal("a shutdown request did not cancel the contexts")
+		}
+	}
+	<-HandlersDone
+	if !Requested() {
+		t.Error("shutdown is not requested")
+	}
+}
@@ -0,0 +1,229 @@
+package util
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+	chainhash "git.parallelcoin.io/dev/9/pkg/chain/hash"
+	"git.parallelcoin.io/dev/9/pkg/chain/wire"
+)
+// LazyParse makes NewBlockFromBytes and NewTxFromBytes return lazily parsed blocks and transactions, as NewBlockFromBytesLazy and NewTxFromBytesLazy do.
+var LazyParse = false
+const (
+	// maxWitnessItemsPerInput and maxWitnessItemSize are the limits wire puts on the witness of an input, which the scan enforces so that deserializing a scanned transaction cannot fail.
+	maxWitnessItemsPerInput = 500000
+	maxWitnessItemSize      = 11000
+	// minTxInSize and minTxOutSize are the smallest an input and an output can be serialized, for bounding their counts by the bytes there are left to hold them.
+	minTxInSize  = 32 + 4 + 1 + 4
+	minTxOutSize = 8 + 1
+	// minTxSize is the smallest a transaction can be serialized, with no inputs or outputs.
+	minTxSize = 4 + 1 + 1 + 4
+	// maxTxInPerMessage, maxTxOutPerMessage and maxTxPerBlock are the limits wire puts on the number of inputs and outputs of a transaction and of transactions in a block, which the scan enforces along with the bound from the bytes there are left.
+	maxTxInPerMessage  = wire.MaxMessagePayload/minTxInSize + 1
+	maxTxOutPerMessage = wire.MaxMessagePayload/minTxOutSize + 1
+	maxTxPerBlock      = wire.MaxBlockPayload/minTxSize + 1
+)
+// txLayout is where the parts of a serialized transaction are, found by scanning it without deserializing it, so that its hashes and scripts can be taken straight from the serialized bytes.
+type txLayout struct {
+	witness    bool     // The serialization has the witness marker, flag and witness data
+	hasWitness bool     // Some input has witness items
+	body       [2]int   // Start and end of the inputs and outputs with their counts, which with the version and lock time are the serialization without witness
+	sigScripts [][2]int // Start and end of the signature script of each input
+	pkScripts  [][2]int // Start and end of the public key script of each output
+}
+// txHash returns the hash of the transaction serialized in b without its witness, hashing the parts of b that make it up rather than serializing it again.
+func (l *txLayout) txHash(b []byte) chainhash.Hash {
+	if !l.witness {
+		return chainhash.DoubleHashH(b)
+	}
+	var first [sha256.Size]byte
+	h := sha256.New()
+	h.Write(b[:4])
+	h.Write(b[l.body[0]:l.body[1]])
+	h.Write(b[len(b)-4:])
+	return chainhash.Hash(sha256.Sum256(h.Sum(first[:0])))
+}
+// scanner reads through serialized transactions and blocks, keeping its position in b.
+type scanner struct {
+	b   []byte
+	pos int
+}
+// skip moves past n bytes.
+func (s *scanner) skip(n uint64) error {
+	if n > uint64(len(s.b)-s.pos) {
+		return io.ErrUnexpectedEOF
+	}
+	s.pos += int(n)
+	return nil
+}
+// left is the number of bytes after the position.
+func (s *scanner) left() uint64 {
+	return uint64(len(s.b) - s.pos)
+}
+// varInt reads a variable length integer, which must be canonically encoded as wire.ReadVarInt requires.
+func (s *scanner) varInt() (uint64, error) {
+	if s.pos >= len(s.b) {
+		return 0, io.ErrUnexpectedEOF
+	}
+	size := 1
+	switch s.b[s.pos] {
+	case 0xfd:
+		size = 3
+	case 0xfe:
+		size = 5
+	case 0xff:
+		size = 9
+	}
+	if len(s.b)-s.pos < size {
+		return 0, io.ErrUnexpectedEOF
+	}
+	var v uint64
+	switch size {
+	case 1:
+		v = uint64(s.b[s.pos])
+	case 3:
+		v = uint64(binary.LittleEndian.Uint16(s.b[s.pos+1:]))
+	case 5:
+		v = uint64(binary.LittleEndian.Uint32(s.b[s.pos+1:]))
+	case 9:
+		v = binary.LittleEndian.Uint64(s.b[s.pos+1:])
+	}
+	if wire.VarIntSerializeSize(v) != size {
+		return 0, fmt.Errorf("non-canonical varint %x encoded in %d bytes", v, size)
+	}
+	s.pos += size
+	return v, nil
+}
+// script moves past a length prefixed script of at most max bytes, returning where it starts and ends.
+func (s *scanner) script(max uint64, field string) ([2]int, error) {
+	n, err := s.varInt()
+	if err != nil {
+		return [2]int{}, err
+	}
+	if n > max {
+		return [2]int{}, fmt.Errorf("%s is larger than the max allowed size [count %d, max %d]", field, n, max)
+	}
+	start := s.pos
+	if err = s.skip(n); err != nil {
+		return [2]int{}, err
+	}
+	return [2]int{start, s.pos}, nil
+}
+// tx scans the transaction at the position, with positions in its layout relative to where it starts. It checks everything MsgTx.Deserialize does, so a transaction that scans deserializes without error.
+func (s *scanner) tx() (*txLayout, error) {
+	start := s.pos
+	l := &txLayout{}
+	if err := s.skip(4); err != nil {
+		return nil, err
+	}
+	l.body[0] = s.pos - start
+	count, err := s.varInt()
+	if err != nil {
+		return nil, err
+	}
+	// A count of zero is the witness marker, followed by the flag and then the real count.
+	if count == 0 {
+		if s.pos >= len(s.b) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		if s.b[s.pos] != 0x01 {
+			return nil, fmt.Errorf("witness tx but flag byte is %x", s.b[s.pos])
+		}
+		s.pos++
+		l.witness = true
+		l.body[0] = s.pos - start
+		if count, err = s.varInt(); err != nil {
+			return nil, err
+		}
+	}
+	if count > maxTxInPerMessage {
+		return nil, fmt.Errorf("too many input transactions to fit into max message size [count %d, max %d]", count, maxTxInPerMessage)
+	}
+	if count > s.left()/minTxInSize {
+		return nil, fmt.Errorf("%d inputs do not fit in the %d bytes left", count, s.left())
+	}
+	l.sigScripts = make([][2]int, count)
+	for i := range l.sigScripts {
+		if err = s.skip(36); err != nil {
+			return nil, err
+		}
+		if l.sigScripts[i], err = s.script(wire.MaxMessagePayload, "transaction input signature script"); err != nil {
+			return nil, err
+		}
+		if err = s.skip(4); err != nil {
+			return nil, err
+		}
+	}
+	if count, err = s.varInt(); err != nil {
+		return nil, err
+	}
+	if count > maxTxOutPerMessage {
+		return nil, fmt.Errorf("too many output transactions to fit into max message size [count %d, max %d]", count, maxTxOutPerMessage)
+	}
+	if count > s.left()/minTxOutSize {
+		return nil, fmt.Errorf("%d outputs do not fit in the %d bytes left", count, s.left())
+	}
+	l.pkScripts = make([][2]int, count)
+	for i := range l.pkScripts {
+		if err = s.skip(8); err != nil {
+			return nil, err
+		}
+		if l.pkScripts[i], err = s.script(wire.MaxMessagePayload, "transaction output public key script"); err != nil {
+			return nil, err
+		}
+	}
+	l.body[1] = s.pos - start
+	if l.witness {
+		for range l.sigScripts {
+			items, err := s.varInt()
+			if err != nil {
+				return nil, err
+			}
+			if items > maxWitnessItemsPerInput {
+				return nil, fmt.Errorf("too many witness items to fit into max message size [count %d, max %d]", items, maxWitnessItemsPerInput)
+			}
+			l.hasWitness = l.hasWitness || items > 0
+			for j := uint64(0); j < items; j++ {
+				if _, err = s.script(maxWitnessItemSize, "script witness item"); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	if err = s.skip(4); err != nil {
+		return nil, err
+	}
+	for _, scripts := range [][][2]int{l.sigScripts, l.pkScripts} {
+		for i := range scripts {
+			scripts[i][0] -= start
+			scripts[i][1] -= start
+		}
+	}
+	return l, nil
+}
+// block scans a serialized block, returning where each transaction is and their layouts.
+func (s *scanner) block() ([]wire.TxLoc, []*txLayout, error) {
+	if err := s.skip(wire.MaxBlockHeaderPayload); err != nil {
+		return nil, nil, err
+	}
+	count, err := s.varInt()
+	if err != nil {
+		return nil, nil, err
+	}
+	if count > maxTxPerBlock {
+		return nil, nil, fmt.Errorf("too many transactions to fit into a block [count %d, max %d]", count, maxTxPerBlock)
+	}
+	if count > s.left()/minTxSize {
+		return nil, nil, fmt.Errorf("%d transactions do not fit in the %d bytes left", count, s.left())
+	}
+	locs := make([]wire.TxLoc, count)
+	layouts := make([]*txLayout, count)
+	for i := range layouts {
+		start := s.pos
+		if layouts[i], err = s.tx(); err != nil {
+			return nil, nil, err
+		}
+		locs[i] = wire.TxLoc{TxStart: start, TxLen: s.pos - start}
+	}
+	return locs, layouts, nil
+}
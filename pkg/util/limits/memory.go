@@ -0,0 +1,72 @@
+package limits
+import (
+	"runtime/debug"
+)
+const (
+	// DefaultGCPercent is the garbage collection target percentage used when memory is scarce or its limit unknown. Block and transaction processing can cause bursty allocations, and this keeps the collector from overallocating during bursts.
+	DefaultGCPercent = 10
+	// DefaultDBCache is the largest database cache, and its size when the memory limit is unknown.
+	DefaultDBCache = 1024 * 1024 * 1024
+	minDBCache     = 32 * 1024 * 1024
+	// DefaultUtxoCache is the size of the chain's utxo cache when the memory limit is unknown.
+	DefaultUtxoCache = 256 * 1024 * 1024
+	minUtxoCache     = 32 * 1024 * 1024
+	maxUtxoCache     = 4 * 1024 * 1024 * 1024
+	minBallast     = 512 * 1024 * 1024
+	maxBallast     = 1024 * 1024 * 1024
+	roomyGC        = 4 * 1024 * 1024 * 1024
+)
+// MemoryPlan is how much memory the process gives to the database cache and how it paces garbage collection, sized to fit within a memory limit.
+type MemoryPlan struct {
+	// Limit is the memory the plan was made for in bytes, 0 if it is unknown.
+	Limit uint64
+	// GCPercent is the garbage collection target percentage.
+	GCPercent int
+	// Ballast is the size of an allocation kept for the life of the process so the heap only counts as grown, and is collected, once live data is a good part of it. Its pages are never written, so it takes no real memory, and with it the collector runs far less often for small heaps.
+	Ballast uint64
+	// DBCache is the size of the block database's write cache in bytes.
+	DBCache uint64
+	// UtxoCache is the size of the chain's cache of unspent outputs in bytes.
+	UtxoCache uint64
+}
+// ballast holds the plan's ballast once it is applied
+var ballast []byte
+// PlanMemory sizes the uses of memory to a limit in bytes, such as the one MemoryLimit finds. The database cache gets a quarter of it, up to DefaultDBCache, the utxo cache another quarter, up to 4GB, and limits of 512MB and more get a ballast of an eighth, up to 1GB. Limits of 4GB and more double the garbage collection target as collections are then cheap enough to be less frequent. An unknown limit, 0, gives the defaults with no ballast.
+func PlanMemory(limit uint64) MemoryPlan {
+	p := MemoryPlan{Limit: limit, GCPercent: DefaultGCPercent, DBCache: DefaultDBCache, UtxoCache: DefaultUtxoCache}
+	if limit == 0 {
+		return p
+	}
+	p.DBCache = limit / 4
+	switch {
+	case p.DBCache < minDBCache:
+		p.DBCache = minDBCache
+	case p.DBCache > DefaultDBCache:
+		p.DBCache = DefaultDBCache
+	}
+	p.UtxoCache = limit / 4
+	switch {
+	case p.UtxoCache < minUtxoCache:
+		p.UtxoCache = minUtxoCache
+	case p.UtxoCache > maxUtxoCache:
+		p.UtxoCache = maxUtxoCache
+	}
+	if limit >= minBallast {
+		p.Ballast = limit / 8
+		if p.Ballast > maxBallast {
+			p.Ballast = maxBallast
+		}
+	}
+	if limit >= roomyGC {
+		p.GCPercent = 2 * DefaultGCPercent
+	}
+	return p
+}
+// Apply sets the garbage collection target percentage and allocates the ballast of the plan, replacing any ballast applied before. The database and utxo cache sizes are for the caller to pass on when it opens the database and chain.
+func (p MemoryPlan) Apply() {
+	debug.SetGCPercent(p.GCPercent)
+	ballast = nil
+	if p.Ballast > 0 {
+		ballast = make([]byte, p.Ballast)
+	}
+}
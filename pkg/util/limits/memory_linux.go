@@ -0,0 +1,50 @@
+package limits
+import (
+	"bufio"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+)
+// cgroupLimitFiles are where the memory limit of the process's container is found, under cgroup v2 and v1
+var cgroupLimitFiles = []string{
+	"/sys/fs/cgroup/memory.max",
+	"/sys/fs/cgroup/memory/memory.limit_in_bytes",
+}
+// MemoryLimit returns the memory the process may use in bytes: the limit of its cgroup, as set for a container, or the total physical memory if that is less or there is no limit. It returns 0 if neither can be read.
+func MemoryLimit() uint64 {
+	limit := physicalMemory()
+	for _, f := range cgroupLimitFiles {
+		b, err := ioutil.ReadFile(f)
+		if err != nil {
+			continue
+		}
+		// no limit is written as max in v2 and as the largest page aligned int64 in v1, more than physical memory either way
+		n, err := strconv.ParseUint(strings.TrimSpace(string(b)), 10, 64)
+		if err == nil && n > 0 && (limit == 0 || n < limit) {
+			limit = n
+		}
+		break
+	}
+	return limit
+}
+// physicalMemory returns the total memory in /proc/meminfo in bytes, or 0 if it can't be read
+func physicalMemory() uint64 {
+	f, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) == 3 && fields[0] == "MemTotal:" && fields[2] == "kB" {
+			kb, err := strconv.ParseUint(fields[1], 10, 64)
+			if err != nil {
+				return 0
+			}
+			return kb * 1024
+		}
+	}
+	return 0
+}
@@ -0,0 +1,7 @@
+// +build !linux
+
+package limits
+// MemoryLimit returns 0 as the memory limit is only detected on Linux, so the defaults are used.
+func MemoryLimit() uint64 {
+	return 0
+}
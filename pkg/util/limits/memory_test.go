@@ -0,0 +1,35 @@
+package limits
+import (
+	"testing"
+)
+func TestPlanMemory(
+	t *testing.T) {
+	const mb = 1024 * 1024
+	tests := []struct {
+		limit     uint64
+		gcPercent int
+		ballast   uint64
+		dbCache   uint64
+		utxoCache uint64
+	}{
+		{0, DefaultGCPercent, 0, DefaultDBCache, DefaultUtxoCache},
+		{64 * mb, DefaultGCPercent, 0, minDBCache, minUtxoCache},
+		{256 * mb, DefaultGCPercent, 0, 64 * mb, 64 * mb},
+		{1024 * mb, DefaultGCPercent, 128 * mb, 256 * mb, 256 * mb},
+		{4096 * mb, 2 * DefaultGCPercent, 512 * mb, DefaultDBCache, 1024 * mb},
+		{64 * 1024 * mb, 2 * DefaultGCPercent, maxBallast, DefaultDBCache, maxUtxoCache},
+	}
+	for _, test := range tests {
+		p := PlanMemory(test.limit)
+		if p.Limit != test.limit || p.GCPercent != test.gcPercent || p.Ballast != test.ballast || p.DBCache != test.dbCache || p.UtxoCache != test.utxoCache {
+			t.Errorf("limit %d planned as %+v", test.limit, p)
+		}
+	}
+}
+func TestMemoryLimit(
+	t *testing.T) {
+	// the limit can't be known here, only that it is sane if found
+	if limit := MemoryLimit(); limit != 0 && limit < 1024*1024 {
+		t.Errorf("memory limit of %d bytes", limit)
+	}
+}
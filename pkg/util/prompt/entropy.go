@@ -0,0 +1,122 @@
+package prompt
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha512"
+	"fmt"
+	"math"
+	"strings"
+)
+// entropyKind is a way of writing down randomness the user gathered themselves: the symbols of its outcomes, how it is called in prompts and the chi-squared value above which counts of its outcomes are taken to be biased, the 0.1% critical value for its degrees of freedom.
+type entropyKind struct {
+	name     string
+	unit     string
+	symbols  string
+	critical float64
+}
+// entropyKinds are the kinds of entropy Seed accepts, by the name the user picks them with.
+var entropyKinds = map[string]entropyKind{
+	"dice": {"dice", "rolls of a six sided die (1-6)", "123456", 20.52},
+	"coin": {"coin", "coin flips (h for heads, t for tails)", "ht", 10.83},
+	"hex":  {"hex", "hexadecimal digits (0-9, a-f)", "0123456789abcdef", 37.70},
+}
+// minUserEntropyBits is how much entropy the user's own input should have to make a seed safe even if the system's randomness is not.
+const minUserEntropyBits = 128
+// parse reads the outcomes in s, ignoring case and any spaces, commas or dashes between them, and returns them as indexes into the symbols of the kind. It fails on anything else so typing mistakes are not silently dropped.
+func (k entropyKind) parse(s string) ([]int, error) {
+	var outcomes []int
+	for _, c := range strings.ToLower(s) {
+		if strings.ContainsRune(" \t\r\n,-", c) {
+			continue
+		}
+		i := strings.IndexRune(k.symbols, c)
+		if i < 0 {
+			return nil, fmt.Errorf("%q is not one of the %s", c, k.unit)
+		}
+		outcomes = append(outcomes, i)
+	}
+	return outcomes, nil
+}
+// bits is the entropy of the outcomes if each was uniformly random, which is all the user's input can have and more than it has if it is biased.
+func (k entropyKind) bits(outcomes []int) float64 {
+	return float64(len(outcomes)) * math.Log2(float64(len(k.symbols)))
+}
+// biased checks the counts of each outcome against the even counts a fair die, coin or hex source would give, with Pearson's chi-squared test. There must be at least five of each outcome expected for the test to mean anything, so fewer outcomes are never found biased.
+func (k entropyKind) biased(outcomes []int) bool {
+	n := float64(len(outcomes))
+	expected := n / float64(len(k.symbols))
+	if expected < 5 {
+		return false
+	}
+	counts := make([]float64, len(k.symbols))
+	for _, o := range outcomes {
+		counts[o]++
+	}
+	var chi2 float64
+	for _, c := range counts {
+		chi2 += (c - expected) * (c - expected) / expected
+	}
+	return chi2 > k.critical
+}
+// mixEntropy combines the system's random seed with the user's outcomes into a seed of the same length, by HMAC-SHA512 keyed with the system seed. Neither source can be worked out from the result, and it is unpredictable as long as either of them is.
+func mixEntropy(
+	systemSeed []byte, kind entropyKind, outcomes []int) []byte {
+	mac := hmac.New(sha512.New, systemSeed)
+	mac.Write([]byte(kind.name))
+	for _, o := range outcomes {
+		mac.Write([]byte{byte(o)})
+	}
+	return mac.Sum(nil)[:len(systemSeed)]
+}
+// promptUserEntropy asks the user for their own entropy to mix into the system's random seed, for when they don't trust the randomness of the machine the wallet is made on. Outcomes are read line by line until an empty line, then the user is warned if they are too few or look biased and asked to confirm them before they are mixed in. It returns the system seed unchanged if the user decides against it.
+func promptUserEntropy(
+	reader *bufio.Reader, systemSeed []byte) ([]byte, error) {
+	names := []string{"dice", "coin", "hex"}
+	for {
+		name, err := promptList(reader, "Which kind of entropy will you enter?", names, "dice")
+		if err != nil {
+			return nil, err
+		}
+		kind := entropyKinds[name]
+		fmt.Printf("\nEnter your %s, as many per line as you like, and an empty line when you are done.\nAbout %d are needed for %d bits of entropy.\n\n",
+			kind.unit, int(math.Ceil(minUserEntropyBits/math.Log2(float64(len(kind.symbols))))), minUserEntropyBits)
+		var outcomes []int
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return nil, err
+			}
+			if strings.TrimSpace(line) == "" {
+				break
+			}
+			o, err := kind.parse(line)
+			if err != nil {
+				fmt.Printf("%v, enter the line again\n", err)
+				continue
+			}
+			outcomes = append(outcomes, o...)
+		}
+		bits := kind.bits(outcomes)
+		fmt.Printf("\nYou entered %d %s, at most %.0f bits of entropy.\n", len(outcomes), kind.unit, bits)
+		if bits < minUserEntropyBits {
+			fmt.Printf("WARNING: that is less than the %d bits needed for your input alone to make the seed safe, so it still depends on the system's randomness.\n", minUserEntropyBits)
+		}
+		if kind.biased(outcomes) {
+			fmt.Println("WARNING: some outcomes came up much more often than others, so your source looks biased and has less entropy than it appears to.")
+		}
+		use, err := promptListBool(reader, "Mix this entropy into the wallet seed?", "yes")
+		if err != nil {
+			return nil, err
+		}
+		if use {
+			return mixEntropy(systemSeed, kind, outcomes), nil
+		}
+		again, err := promptListBool(reader, "Enter entropy again?", "yes")
+		if err != nil {
+			return nil, err
+		}
+		if !again {
+			return systemSeed, nil
+		}
+	}
+}
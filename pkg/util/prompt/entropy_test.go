@@ -0,0 +1,83 @@
+package prompt
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+)
+func TestEntropyParse(
+	t *testing.T) {
+	dice := entropyKinds["dice"]
+	outcomes, err := dice.parse("1 2,3-4\t5 66\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{0, 1, 2, 3, 4, 5, 5}; len(outcomes) != len(want) {
+		t.Fatalf("got %v, want %v", outcomes, want)
+	}
+	for _, bad := range []string{"7", "0", "1 2 x"} {
+		if _, err := dice.parse(bad); err == nil {
+			t.Errorf("%q parsed as dice rolls", bad)
+		}
+	}
+	if o, err := entropyKinds["hex"].parse("DeadBeef"); err != nil || len(o) != 8 || o[0] != 13 {
+		t.Errorf("hex parsed as %v, %v", o, err)
+	}
+	if o, err := entropyKinds["coin"].parse("HTth"); err != nil || len(o) != 4 || o[1] != 1 {
+		t.Errorf("coin flips parsed as %v, %v", o, err)
+	}
+}
+func TestEntropyBias(
+	t *testing.T) {
+	coin := entropyKinds["coin"]
+	fair, _ := coin.parse(strings.Repeat("ht", 50))
+	if coin.biased(fair) {
+		t.Error("alternating coin flips found biased")
+	}
+	heads, _ := coin.parse(strings.Repeat("h", 90) + strings.Repeat("t", 10))
+	if !coin.biased(heads) {
+		t.Error("90 heads of 100 flips not found biased")
+	}
+	few, _ := coin.parse("hhhhh")
+	if coin.biased(few) {
+		t.Error("too few flips to test found biased")
+	}
+	if bits := coin.bits(fair); bits != 100 {
+		t.Errorf("100 coin flips have %v bits", bits)
+	}
+}
+func TestMixEntropy(
+	t *testing.T) {
+	system := bytes.Repeat([]byte{1}, 32)
+	dice := entropyKinds["dice"]
+	a := mixEntropy(system, dice, []int{0, 1, 2})
+	if len(a) != len(system) {
+		t.Fatalf("mixed seed is %d bytes", len(a))
+	}
+	if bytes.Equal(a, mixEntropy(system, dice, []int{0, 1, 3})) {
+		t.Error("mixed seed does not depend on the user's entropy")
+	}
+	if bytes.Equal(a, mixEntropy(bytes.Repeat([]byte{2}, 32), dice, []int{0, 1, 2})) {
+		t.Error("mixed seed does not depend on the system's entropy")
+	}
+	if bytes.Equal(a, mixEntropy(system, entropyKinds["hex"], []int{0, 1, 2})) {
+		t.Error("mixed seed does not depend on the kind of entropy")
+	}
+}
+func TestPromptUserEntropy(
+	t *testing.T) {
+	system := bytes.Repeat([]byte{1}, 32)
+	input := "dice\n1 2 3\n7\n4 5 6\n\nyes\n"
+	seed, err := promptUserEntropy(bufio.NewReader(strings.NewReader(input)), system)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := mixEntropy(system, entropyKinds["dice"], []int{0, 1, 2, 3, 4, 5}); !bytes.Equal(seed, want) {
+		t.Errorf("got seed %x, want %x", seed, want)
+	}
+	input = "coin\nhtht\n\nno\nno\n"
+	seed, err = promptUserEntropy(bufio.NewReader(strings.NewReader(input)), system)
+	if err != nil || !bytes.Equal(seed, system) {
+		t.Errorf("declined entropy gave seed %x, %v", seed, err)
+	}
+}
@@ -0,0 +1,75 @@
+package prompt
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"github.com/btcsuite/golangcrypto/ssh/terminal"
+)
+const (
+	// AnswersEnv opts in to reading the answers to prompts from somewhere other than stdin, for creating wallets under orchestration tools. It is "fd:N" to read them from file descriptor N, or "env" to read them from the variable named by InputEnv, in either case one answer per line in the order the prompts are shown.
+	AnswersEnv = "POD_PROMPT_ANSWERS"
+	// InputEnv holds the answers to prompts, one per line, when AnswersEnv is "env". Other processes of the same user may be able to read the environment, so a file descriptor is the safer way to give passphrases.
+	InputEnv = "POD_PROMPT_INPUT"
+)
+var (
+	input     *bufio.Reader
+	inputTTY  bool
+	inputErr  error
+	inputOnce sync.Once
+)
+// Input returns the reader the answers to prompts come from, which is stdin unless AnswersEnv says otherwise. There is only one reader for the process, as any other reader of the same input would lose what this one has buffered.
+func Input() (*bufio.Reader, error) {
+	inputOnce.Do(func() {
+		input, inputTTY, inputErr = openInput(os.Getenv(AnswersEnv))
+	})
+	return input, inputErr
+}
+// Interactive returns whether the answers to prompts are typed at a terminal, rather than piped to stdin or given through AnswersEnv.
+func Interactive() bool {
+	_, err := Input()
+	return err == nil && inputTTY
+}
+// openInput opens the source of answers named by the value of AnswersEnv, returning whether it is a terminal.
+func openInput(
+	answers string) (*bufio.Reader, bool, error) {
+	switch {
+	case answers == "":
+		return bufio.NewReader(os.Stdin), terminal.IsTerminal(int(os.Stdin.Fd())), nil
+	case answers == "env":
+		// the last answer is ended like the others, as variables are usually set without a trailing newline
+		in := strings.TrimSuffix(os.Getenv(InputEnv), "\n") + "\n"
+		return bufio.NewReader(strings.NewReader(in)), false, nil
+	case strings.HasPrefix(answers, "fd:"):
+		fd, err := strconv.ParseUint(answers[3:], 10, 31)
+		if err != nil {
+			return nil, false, fmt.Errorf("%s: invalid file descriptor %q", AnswersEnv, answers[3:])
+		}
+		f := os.NewFile(uintptr(fd), "answers")
+		if f == nil {
+			return nil, false, fmt.Errorf("%s: invalid file descriptor %d", AnswersEnv, fd)
+		}
+		return bufio.NewReader(f), false, nil
+	}
+	return nil, false, errors.New(AnswersEnv + " must be fd:N or env, not " + strconv.Quote(answers))
+}
+// readPassword reads a passphrase without echoing it when the answers are typed at a terminal, and otherwise reads it as a line of the answers.
+func readPassword(
+	reader *bufio.Reader) ([]byte, error) {
+	if Interactive() {
+		pass, err := terminal.ReadPassword(int(os.Stdin.Fd()))
+		if err != nil {
+			return nil, err
+		}
+		fmt.Print("\n")
+		return pass, nil
+	}
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	return []byte(strings.TrimRight(line, "\r\n")), nil
+}
@@ -0,0 +1,24 @@
+package prompt
+import (
+	"os"
+	"testing"
+)
+func TestOpenInput(
+	t *testing.T) {
+	os.Setenv(InputEnv, "secret\nOK")
+	defer os.Unsetenv(InputEnv)
+	reader, tty, err := openInput("env")
+	if err != nil || tty {
+		t.Fatalf("env answers opened as terminal %v, %v", tty, err)
+	}
+	for _, want := range []string{"secret", "OK"} {
+		if pass, err := readPassword(reader); err != nil || string(pass) != want {
+			t.Errorf("read %q, %v, want %q", pass, err, want)
+		}
+	}
+	for _, bad := range []string{"stdin", "fd:", "fd:x", "fd:-1"} {
+		if _, _, err := openInput(bad); err == nil {
+			t.Errorf("%s=%s was accepted", AnswersEnv, bad)
+		}
+	}
+}
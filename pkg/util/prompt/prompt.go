@@ -5,16 +5,17 @@ import (
 	"bytes"
 	"encoding/hex"
 	"fmt"
-	"os"
 	"strings"
 	"git.parallelcoin.io/dev/9/pkg/util/hdkeychain"
 	"git.parallelcoin.io/dev/9/pkg/util/legacy/keystore"
-	"github.com/btcsuite/golangcrypto/ssh/terminal"
 )
 // ProvideSeed is used to prompt for the wallet seed which maybe required during
 // upgrades.
 func ProvideSeed() ([]byte, error) {
-	reader := bufio.NewReader(os.Stdin)
+	reader, err := Input()
+	if err != nil {
+		return nil, err
+	}
 	for {
 		fmt.Print("Enter existing wallet seed: ")
 		seedStr, err := reader.ReadString('\n')
@@ -36,14 +37,17 @@ func ProvideSeed() ([]byte, error) {
 // ProvidePrivPassphrase is used to prompt for the private passphrase which
 // maybe required during upgrades.
 func ProvidePrivPassphrase() ([]byte, error) {
+	reader, err := Input()
+	if err != nil {
+		return nil, err
+	}
 	prompt := "enter the private passphrase for your wallet: "
 	for {
 		fmt.Print(prompt)
-		pass, err := terminal.ReadPassword(int(os.Stdin.Fd()))
+		pass, err := readPassword(reader)
 		if err != nil {
 			return nil, err
 		}
-		fmt.Print("\n")
 		pass = bytes.TrimSpace(pass)
 		if len(pass) == 0 {
 			continue
@@ -98,18 +102,18 @@ func promptListBool(
 }
 // promptPass prompts the user for a passphrase with the given prefix.  The
 // function will ask the user to confirm the passphrase and will repeat the
-// prompts until they enter a matching response.
+// prompts until they enter a matching response.  The passphrase is read
+// without echo at a terminal and as a line of the answers otherwise.
 func promptPass(
 	reader *bufio.Reader, prefix string, confirm bool) ([]byte, error) {
 	// Prompt the user until they enter a passphrase.
 	prompt := fmt.Sprintf("%s: ", prefix)
 	for {
 		fmt.Print(prompt)
-		pass, err := terminal.ReadPassword(int(os.Stdin.Fd()))
+		pass, err := readPassword(reader)
 		if err != nil {
 			return nil, err
 		}
-		fmt.Print("\n")
 		pass = bytes.TrimSpace(pass)
 		if len(pass) == 0 {
 			continue
@@ -118,11 +122,10 @@ func promptPass(
 			return pass, nil
 		}
 		fmt.Print("Confirm passphrase: ")
-		confirm, err := terminal.ReadPassword(int(os.Stdin.Fd()))
+		confirm, err := readPassword(reader)
 		if err != nil {
 			return nil, err
 		}
-		fmt.Print("\n")
 		confirm = bytes.TrimSpace(confirm)
 		if !bytes.Equal(pass, confirm) {
 			fmt.Println("The entered passphrases do not match")
@@ -221,7 +224,8 @@ func PublicPass(
 	return pubPass, nil
 }
 // Seed prompts the user whether they want to use an existing wallet generation
-// seed.  When the user answers no, a seed will be generated and displayed to
+// seed.  When the user answers no, a seed will be generated, optionally mixed
+// with dice rolls, coin flips or hex digits the user enters, and displayed to
 // the user along with prompting them for confirmation.  When the user answers
 // yes, a the user is prompted for it.  All prompts are repeated until the user
 // enters a valid response.
@@ -238,6 +242,16 @@ func Seed(
 		if err != nil {
 			return nil, err
 		}
+		useEntropy, err := promptListBool(reader, "Do you want to add "+
+			"your own entropy from dice, coin flips or hex digits to the seed?", "no")
+		if err != nil {
+			return nil, err
+		}
+		if useEntropy {
+			if seed, err = promptUserEntropy(reader, seed); err != nil {
+				return nil, err
+			}
+		}
 		fmt.Println("\nYour wallet generation seed is:")
 		fmt.Printf("\n%x\n\n", seed)
 		fmt.Print("IMPORTANT: Keep the seed in a safe place as you will NOT be able to restore your wallet without it.\n\n")
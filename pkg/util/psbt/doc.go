@@ -0,0 +1,8 @@
+/*
+Package psbt implements the partially signed transactions of BIP174, which carry an unsigned transaction between the wallets and signers that each add what they know of it until it can be finalized and published.
+Roles
+A creator makes a Packet of an unsigned transaction with NewFromUnsignedTx. An updater, usually a wallet that knows the outputs the transaction spends, fills in the previous outputs and the redeem and witness scripts of its inputs, so a signer that has only the keys, such as an offline wallet, can sign them with AddPartialSig. A finalizer turns the partial signatures of each input into its signature script and witness with MaybeFinalize, and Extract returns the transaction that can then be published.
+Serialization
+Packets are serialized in the binary format of BIP174 with Serialize, or as the base64 text passed between RPC clients with B64Encode, and read with NewFromRawBytes. Key types this package does not know are kept as they were read and serialized again, so a packet can pass through it on the way to a signer that does know them.
+*/
+package psbt
@@ -0,0 +1,158 @@
+package psbt
+import (
+	"bytes"
+	"crypto/sha256"
+	txscript "git.parallelcoin.io/dev/9/pkg/chain/tx/script"
+	"git.parallelcoin.io/dev/9/pkg/chain/wire"
+	"git.parallelcoin.io/dev/9/pkg/util"
+)
+// MaybeFinalize sets the final signature script and witness of input i when it has the signatures and scripts to spend its utxo, returning whether the input is final. Pay to pubkey hash, pay to pubkey and multisig scripts are finalized bare, in pay to script hash and in pay to witness script hash, and pay to witness pubkey hash bare and in pay to script hash. An input that needs more signatures is left as it was.
+func (p *Packet) MaybeFinalize(
+	i int) (bool, error) {
+	utxo, err := p.Utxo(i)
+	if err != nil {
+		return false, err
+	}
+	pi := &p.Inputs[i]
+	if pi.IsFinal() {
+		return true, nil
+	}
+	script := utxo.PkScript
+	var redeemScript []byte
+	if txscript.IsPayToScriptHash(script) {
+		if pi.RedeemScript == nil {
+			return false, nil
+		}
+		if !bytes.Equal(util.Hash160(pi.RedeemScript), script[2:22]) {
+			return false, ErrScriptMismatch
+		}
+		redeemScript, script = pi.RedeemScript, pi.RedeemScript
+	}
+	var pushes [][]byte
+	var witness wire.TxWitness
+	switch {
+	case txscript.IsPayToWitnessPubKeyHash(script):
+		sig := pi.findSig(func(pubKey []byte) bool {
+			return bytes.Equal(util.Hash160(pubKey), script[2:22])
+		})
+		if sig == nil {
+			return false, nil
+		}
+		witness = wire.TxWitness{sig.Signature, sig.PubKey}
+	case txscript.IsPayToWitnessScriptHash(script):
+		if pi.WitnessScript == nil {
+			return false, nil
+		}
+		if hash := sha256.Sum256(pi.WitnessScript); !bytes.Equal(hash[:], script[2:34]) {
+			return false, ErrScriptMismatch
+		}
+		stack, err := pi.satisfy(pi.WitnessScript)
+		if stack == nil || err != nil {
+			return false, err
+		}
+		witness = append(stack, pi.WitnessScript)
+	default:
+		if pushes, err = pi.satisfy(script); pushes == nil || err != nil {
+			return false, err
+		}
+	}
+	if redeemScript != nil {
+		pushes = append(pushes, redeemScript)
+	}
+	if len(pushes) != 0 {
+		b := txscript.NewScriptBuilder()
+		for _, push := range pushes {
+			b.AddData(push)
+		}
+		if pi.FinalScriptSig, err = b.Script(); err != nil {
+			return false, err
+		}
+	}
+	pi.FinalScriptWitness = witness
+	pi.PartialSigs, pi.SighashType, pi.RedeemScript, pi.WitnessScript, pi.Bip32Derivation = nil, 0, nil, nil, nil
+	return true, nil
+}
+// MaybeFinalizeAll finalizes every input that has what it needs to be finalized, returning an error if an input can never be
+func (p *Packet) MaybeFinalizeAll() error {
+	for i := range p.Inputs {
+		if _, err := p.MaybeFinalize(i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+// Extract returns the transaction of a packet with every input finalized, with the final signature scripts and witnesses of the inputs
+func (p *Packet) Extract() (*wire.MsgTx, error) {
+	if !p.IsComplete() {
+		return nil, ErrIncompletePsbt
+	}
+	tx := p.UnsignedTx.Copy()
+	for i, in := range tx.TxIn {
+		in.SignatureScript = p.Inputs[i].FinalScriptSig
+		in.Witness = p.Inputs[i].FinalScriptWitness
+	}
+	return tx, nil
+}
+// findSig returns the partial signature of the input by a public key that matches, or nil
+func (pi *PInput) findSig(
+	match func(pubKey []byte) bool) *PartialSig {
+	for _, sig := range pi.PartialSigs {
+		if match(sig.PubKey) {
+			return sig
+		}
+	}
+	return nil
+}
+// satisfy returns the data pushed to spend a pay to pubkey hash, pay to pubkey or multisig script with the partial signatures of the input, or nil if it does not have enough of them
+func (pi *PInput) satisfy(
+	script []byte) ([][]byte, error) {
+	switch txscript.GetScriptClass(script) {
+	case txscript.PubKeyHashTy:
+		sig := pi.findSig(func(pubKey []byte) bool {
+			return bytes.Equal(util.Hash160(pubKey), script[3:23])
+		})
+		if sig == nil {
+			return nil, nil
+		}
+		return [][]byte{sig.Signature, sig.PubKey}, nil
+	case txscript.PubKeyTy:
+		pushed, err := txscript.PushedData(script)
+		if err != nil {
+			return nil, err
+		}
+		sig := pi.findSig(func(pubKey []byte) bool {
+			return bytes.Equal(pubKey, pushed[0])
+		})
+		if sig == nil {
+			return nil, nil
+		}
+		return [][]byte{sig.Signature}, nil
+	case txscript.MultiSigTy:
+		pubKeys, err := txscript.PushedData(script)
+		if err != nil {
+			return nil, err
+		}
+		_, required, err := txscript.CalcMultiSigStats(script)
+		if err != nil {
+			return nil, err
+		}
+		// The dummy item consumed by OP_CHECKMULTISIG comes first, and the signatures must be in the order of their keys in the script.
+		stack := [][]byte{nil}
+		for _, key := range pubKeys {
+			if len(stack) > required {
+				break
+			}
+			sig := pi.findSig(func(pubKey []byte) bool {
+				return bytes.Equal(pubKey, key)
+			})
+			if sig != nil {
+				stack = append(stack, sig.Signature)
+			}
+		}
+		if len(stack) <= required {
+			return nil, nil
+		}
+		return stack, nil
+	}
+	return nil, ErrUnsupportedScript
+}
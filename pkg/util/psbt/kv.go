@@ -0,0 +1,191 @@
+package psbt
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"git.parallelcoin.io/dev/9/pkg/chain/wire"
+	ec "git.parallelcoin.io/dev/9/pkg/util/elliptic"
+)
+// PartialSig is the signature of an input by one of the keys that can spend it
+type PartialSig struct {
+	PubKey    []byte
+	Signature []byte
+}
+// Bip32Derivation is the path a key is derived along from the master key with the fingerprint, so a signer can find the key of an input or tell an output pays back to it
+type Bip32Derivation struct {
+	PubKey               []byte
+	MasterKeyFingerprint uint32
+	Bip32Path            []uint32
+}
+// readMap reads the keys and values of a map of a packet up to the separator that ends it, passing the type, data and value of each key to fn
+func readMap(
+	r io.Reader, fn func(keyType byte, keyData, value []byte) error) error {
+	seen := make(map[string]struct{})
+	for {
+		key, err := wire.ReadVarBytes(r, 0, maxKVSize, "psbt key")
+		if err != nil {
+			return err
+		}
+		if len(key) == 0 {
+			return nil
+		}
+		if _, ok := seen[string(key)]; ok {
+			return ErrDuplicateKey
+		}
+		seen[string(key)] = struct{}{}
+		value, err := wire.ReadVarBytes(r, 0, maxKVSize, "psbt value")
+		if err != nil {
+			return err
+		}
+		if err = fn(key[0], key[1:], value); err != nil {
+			return err
+		}
+	}
+}
+// writeKV writes a key of the type and data, and its value
+func writeKV(
+	w io.Writer, keyType byte, keyData, value []byte) error {
+	key := append([]byte{keyType}, keyData...)
+	if err := wire.WriteVarBytes(w, 0, key); err != nil {
+		return err
+	}
+	return wire.WriteVarBytes(w, 0, value)
+}
+// writeSeparator writes the empty key that ends a map
+func writeSeparator(
+	w io.Writer) error {
+	_, err := w.Write([]byte{0x00})
+	return err
+}
+// newUnknown returns the unknown key of the type and data with its value
+func newUnknown(
+	keyType byte, keyData, value []byte) Unknown {
+	return Unknown{
+		Key:   append([]byte{keyType}, keyData...),
+		Value: value,
+	}
+}
+// writeUnknowns writes the unknown keys of a map as they were read
+func writeUnknowns(
+	w io.Writer, unknowns []Unknown) error {
+	for _, u := range unknowns {
+		if err := writeKV(w, u.Key[0], u.Key[1:], u.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+// checkPubKey returns an error if key is not a serialized public key
+func checkPubKey(
+	key []byte) error {
+	if _, err := ec.ParsePubKey(key, ec.S256()); err != nil {
+		return ErrInvalidPubKey
+	}
+	return nil
+}
+// checkSig returns an error if sig is not a DER signature followed by its sighash type
+func checkSig(
+	sig []byte) error {
+	if len(sig) < 2 {
+		return ErrInvalidSignature
+	}
+	if _, err := ec.ParseDERSignature(sig[:len(sig)-1], ec.S256()); err != nil {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+// parseTxOut parses the amount and script of a witness utxo
+func parseTxOut(
+	value []byte) (*wire.TxOut, error) {
+	if len(value) < 9 {
+		return nil, ErrInvalidValue
+	}
+	r := bytes.NewReader(value[8:])
+	script, err := wire.ReadVarBytes(r, 0, maxKVSize, "witness utxo script")
+	if err != nil || r.Len() != 0 {
+		return nil, ErrInvalidValue
+	}
+	return wire.NewTxOut(int64(binary.LittleEndian.Uint64(value)), script), nil
+}
+// serializeTxOut serializes the amount and script of a witness utxo
+func serializeTxOut(
+	out *wire.TxOut) ([]byte, error) {
+	var b bytes.Buffer
+	var amount [8]byte
+	binary.LittleEndian.PutUint64(amount[:], uint64(out.Value))
+	b.Write(amount[:])
+	if err := wire.WriteVarBytes(&b, 0, out.PkScript); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+// parseWitness parses the items of a final script witness
+func parseWitness(
+	value []byte) (wire.TxWitness, error) {
+	r := bytes.NewReader(value)
+	count, err := wire.ReadVarInt(r, 0)
+	if err != nil || count > uint64(len(value)) {
+		return nil, ErrInvalidValue
+	}
+	witness := make(wire.TxWitness, count)
+	for i := range witness {
+		if witness[i], err = wire.ReadVarBytes(r, 0, maxKVSize, "witness item"); err != nil {
+			return nil, ErrInvalidValue
+		}
+	}
+	if r.Len() != 0 {
+		return nil, ErrInvalidValue
+	}
+	return witness, nil
+}
+// serializeWitness serializes the items of a final script witness
+func serializeWitness(
+	witness wire.TxWitness) ([]byte, error) {
+	var b bytes.Buffer
+	if err := wire.WriteVarInt(&b, 0, uint64(len(witness))); err != nil {
+		return nil, err
+	}
+	for _, item := range witness {
+		if err := wire.WriteVarBytes(&b, 0, item); err != nil {
+			return nil, err
+		}
+	}
+	return b.Bytes(), nil
+}
+// parseBip32Derivation parses the derivation of the key from the fingerprint of its master key and the indexes of its path
+func parseBip32Derivation(
+	pubKey, value []byte) (*Bip32Derivation, error) {
+	if err := checkPubKey(pubKey); err != nil {
+		return nil, err
+	}
+	if len(value) < 4 || len(value)%4 != 0 {
+		return nil, ErrInvalidValue
+	}
+	d := &Bip32Derivation{
+		PubKey:               pubKey,
+		MasterKeyFingerprint: binary.LittleEndian.Uint32(value),
+	}
+	for i := 4; i < len(value); i += 4 {
+		d.Bip32Path = append(d.Bip32Path, binary.LittleEndian.Uint32(value[i:]))
+	}
+	return d, nil
+}
+// serialize returns the value of the derivation
+func (d *Bip32Derivation) serialize() []byte {
+	value := make([]byte, 4+4*len(d.Bip32Path))
+	binary.LittleEndian.PutUint32(value, d.MasterKeyFingerprint)
+	for i, index := range d.Bip32Path {
+		binary.LittleEndian.PutUint32(value[4+4*i:], index)
+	}
+	return value
+}
+// writeBip32Derivations writes the derivations as keys of the type
+func writeBip32Derivations(
+	w io.Writer, keyType byte, derivations []*Bip32Derivation) error {
+	for _, d := range derivations {
+		if err := writeKV(w, keyType, d.PubKey, d.serialize()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
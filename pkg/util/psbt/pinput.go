@@ -0,0 +1,190 @@
+package psbt
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	txscript "git.parallelcoin.io/dev/9/pkg/chain/tx/script"
+	"git.parallelcoin.io/dev/9/pkg/chain/wire"
+)
+const (
+	// the key types of the maps of inputs
+	nonWitnessUtxoType       = 0x00
+	witnessUtxoType          = 0x01
+	partialSigType           = 0x02
+	sighashType              = 0x03
+	redeemScriptType         = 0x04
+	witnessScriptType        = 0x05
+	inputBip32DerivationType = 0x06
+	finalScriptSigType       = 0x07
+	finalScriptWitnessType   = 0x08
+)
+// PInput is what a packet knows of one of the inputs of its transaction. NonWitnessUtxo is the transaction the input spends an output of, which signers of inputs that are not segwit need to know its value, and WitnessUtxo is the output a segwit input spends. Once the input is finalized only the utxos, the final signature script and witness and the unknown keys are kept.
+type PInput struct {
+	NonWitnessUtxo     *wire.MsgTx
+	WitnessUtxo        *wire.TxOut
+	PartialSigs        []*PartialSig
+	SighashType        txscript.SigHashType
+	RedeemScript       []byte
+	WitnessScript      []byte
+	Bip32Derivation    []*Bip32Derivation
+	FinalScriptSig     []byte
+	FinalScriptWitness wire.TxWitness
+	Unknowns           []Unknown
+}
+// IsFinal returns true if the input has its final signature script or witness
+func (pi *PInput) IsFinal() bool {
+	return len(pi.FinalScriptSig) != 0 || len(pi.FinalScriptWitness) != 0
+}
+// deserialize reads the map of the input from r
+func (pi *PInput) deserialize(r io.Reader) error {
+	return readMap(r, func(keyType byte, keyData, value []byte) error {
+		switch keyType {
+		case partialSigType:
+			if err := checkPubKey(keyData); err != nil {
+				return err
+			}
+			if err := checkSig(value); err != nil {
+				return err
+			}
+			pi.PartialSigs = append(pi.PartialSigs, &PartialSig{
+				PubKey:    keyData,
+				Signature: value,
+			})
+			return nil
+		case inputBip32DerivationType:
+			d, err := parseBip32Derivation(keyData, value)
+			if err != nil {
+				return err
+			}
+			pi.Bip32Derivation = append(pi.Bip32Derivation, d)
+			return nil
+		case nonWitnessUtxoType, witnessUtxoType, sighashType, redeemScriptType,
+			witnessScriptType, finalScriptSigType, finalScriptWitnessType:
+			if len(keyData) != 0 {
+				return ErrInvalidKeyData
+			}
+		default:
+			pi.Unknowns = append(pi.Unknowns, newUnknown(keyType, keyData, value))
+			return nil
+		}
+		var err error
+		switch keyType {
+		case nonWitnessUtxoType:
+			tx := &wire.MsgTx{}
+			if err = tx.Deserialize(bytes.NewReader(value)); err != nil {
+				return err
+			}
+			pi.NonWitnessUtxo = tx
+		case witnessUtxoType:
+			pi.WitnessUtxo, err = parseTxOut(value)
+		case sighashType:
+			if len(value) != 4 {
+				return ErrInvalidValue
+			}
+			pi.SighashType = txscript.SigHashType(binary.LittleEndian.Uint32(value))
+		case redeemScriptType:
+			pi.RedeemScript = value
+		case witnessScriptType:
+			pi.WitnessScript = value
+		case finalScriptSigType:
+			pi.FinalScriptSig = value
+		case finalScriptWitnessType:
+			pi.FinalScriptWitness, err = parseWitness(value)
+		}
+		return err
+	})
+}
+// serialize writes the map of the input to w
+func (pi *PInput) serialize(w io.Writer) error {
+	if pi.NonWitnessUtxo != nil {
+		var tx bytes.Buffer
+		if err := pi.NonWitnessUtxo.Serialize(&tx); err != nil {
+			return err
+		}
+		if err := writeKV(w, nonWitnessUtxoType, nil, tx.Bytes()); err != nil {
+			return err
+		}
+	}
+	if pi.WitnessUtxo != nil {
+		value, err := serializeTxOut(pi.WitnessUtxo)
+		if err != nil {
+			return err
+		}
+		if err = writeKV(w, witnessUtxoType, nil, value); err != nil {
+			return err
+		}
+	}
+	for _, sig := range pi.PartialSigs {
+		if err := writeKV(w, partialSigType, sig.PubKey, sig.Signature); err != nil {
+			return err
+		}
+	}
+	if pi.SighashType != 0 {
+		var value [4]byte
+		binary.LittleEndian.PutUint32(value[:], uint32(pi.SighashType))
+		if err := writeKV(w, sighashType, nil, value[:]); err != nil {
+			return err
+		}
+	}
+	if pi.RedeemScript != nil {
+		if err := writeKV(w, redeemScriptType, nil, pi.RedeemScript); err != nil {
+			return err
+		}
+	}
+	if pi.WitnessScript != nil {
+		if err := writeKV(w, witnessScriptType, nil, pi.WitnessScript); err != nil {
+			return err
+		}
+	}
+	if err := writeBip32Derivations(w, inputBip32DerivationType, pi.Bip32Derivation); err != nil {
+		return err
+	}
+	if pi.FinalScriptSig != nil {
+		if err := writeKV(w, finalScriptSigType, nil, pi.FinalScriptSig); err != nil {
+			return err
+		}
+	}
+	if pi.FinalScriptWitness != nil {
+		value, err := serializeWitness(pi.FinalScriptWitness)
+		if err != nil {
+			return err
+		}
+		if err = writeKV(w, finalScriptWitnessType, nil, value); err != nil {
+			return err
+		}
+	}
+	if err := writeUnknowns(w, pi.Unknowns); err != nil {
+		return err
+	}
+	return writeSeparator(w)
+}
+// AddPartialSig adds the signature of input i by the public key, which must be of the sighash type the input asks for if it asks for one
+func (p *Packet) AddPartialSig(
+	i int, pubKey, sig []byte) error {
+	if i < 0 || i >= len(p.Inputs) {
+		return ErrInvalidInputIndex
+	}
+	if err := checkPubKey(pubKey); err != nil {
+		return err
+	}
+	if err := checkSig(sig); err != nil {
+		return err
+	}
+	pi := &p.Inputs[i]
+	if pi.SighashType != 0 && txscript.SigHashType(sig[len(sig)-1]) != pi.SighashType {
+		return ErrSighashMismatch
+	}
+	for _, ps := range pi.PartialSigs {
+		if bytes.Equal(ps.PubKey, pubKey) {
+			if bytes.Equal(ps.Signature, sig) {
+				return nil
+			}
+			return ErrDuplicateKey
+		}
+	}
+	pi.PartialSigs = append(pi.PartialSigs, &PartialSig{
+		PubKey:    pubKey,
+		Signature: sig,
+	})
+	return nil
+}
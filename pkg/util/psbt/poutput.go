@@ -0,0 +1,62 @@
+package psbt
+import (
+	"io"
+)
+const (
+	// the key types of the maps of outputs
+	outputRedeemScriptType    = 0x00
+	outputWitnessScriptType   = 0x01
+	outputBip32DerivationType = 0x02
+)
+// POutput is what a packet knows of one of the outputs of its transaction, which lets a signer tell the outputs that pay back to its own keys
+type POutput struct {
+	RedeemScript    []byte
+	WitnessScript   []byte
+	Bip32Derivation []*Bip32Derivation
+	Unknowns        []Unknown
+}
+// deserialize reads the map of the output from r
+func (po *POutput) deserialize(r io.Reader) error {
+	return readMap(r, func(keyType byte, keyData, value []byte) error {
+		switch keyType {
+		case outputRedeemScriptType, outputWitnessScriptType:
+			if len(keyData) != 0 {
+				return ErrInvalidKeyData
+			}
+			if keyType == outputRedeemScriptType {
+				po.RedeemScript = value
+			} else {
+				po.WitnessScript = value
+			}
+		case outputBip32DerivationType:
+			d, err := parseBip32Derivation(keyData, value)
+			if err != nil {
+				return err
+			}
+			po.Bip32Derivation = append(po.Bip32Derivation, d)
+		default:
+			po.Unknowns = append(po.Unknowns, newUnknown(keyType, keyData, value))
+		}
+		return nil
+	})
+}
+// serialize writes the map of the output to w
+func (po *POutput) serialize(w io.Writer) error {
+	if po.RedeemScript != nil {
+		if err := writeKV(w, outputRedeemScriptType, nil, po.RedeemScript); err != nil {
+			return err
+		}
+	}
+	if po.WitnessScript != nil {
+		if err := writeKV(w, outputWitnessScriptType, nil, po.WitnessScript); err != nil {
+			return err
+		}
+	}
+	if err := writeBip32Derivations(w, outputBip32DerivationType, po.Bip32Derivation); err != nil {
+		return err
+	}
+	if err := writeUnknowns(w, po.Unknowns); err != nil {
+		return err
+	}
+	return writeSeparator(w)
+}
@@ -0,0 +1,235 @@
+package psbt
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"io"
+	"git.parallelcoin.io/dev/9/pkg/chain/wire"
+	"git.parallelcoin.io/dev/9/pkg/util"
+)
+const (
+	// maxKVSize is the largest key or value that is read from a packet, so a malformed packet can not make its reader allocate more
+	maxKVSize = 4000000
+	// globalUnsignedTxType is the key type of the unsigned transaction in the global map
+	globalUnsignedTxType = 0x00
+)
+// magic starts every serialized packet
+var magic = []byte{0x70, 0x73, 0x62, 0x74, 0xff}
+var (
+	// ErrInvalidMagic describes a packet that does not start with the magic bytes of BIP174
+	ErrInvalidMagic = errors.New("invalid psbt magic bytes")
+	// ErrInvalidPsbtFormat describes a packet whose maps do not match its transaction
+	ErrInvalidPsbtFormat = errors.New("invalid psbt format")
+	// ErrDuplicateKey describes a key that appears twice in a map of a packet
+	ErrDuplicateKey = errors.New("duplicate key in psbt")
+	// ErrInvalidKeyData describes a key of a known type with key data that does not fit it
+	ErrInvalidKeyData = errors.New("invalid key data in psbt")
+	// ErrInvalidValue describes a value that can not be parsed as the type of its key says
+	ErrInvalidValue = errors.New("invalid value in psbt")
+	// ErrInvalidRawTxSigned describes an unsigned transaction of a packet that has signature scripts or witnesses
+	ErrInvalidRawTxSigned = errors.New("the unsigned transaction of a psbt has signature scripts or witnesses")
+	// ErrNonWitnessUtxoMismatch describes an input with a previous transaction that is not the one it spends
+	ErrNonWitnessUtxoMismatch = errors.New("the non witness utxo of a psbt input is not the transaction it spends")
+	// ErrInvalidInputIndex describes an input index beyond the inputs of a packet
+	ErrInvalidInputIndex = errors.New("psbt has no input with that index")
+	// ErrMissingUtxo describes an input without the previous output it spends
+	ErrMissingUtxo = errors.New("psbt input has no utxo")
+	// ErrInvalidSignature describes a partial signature that is not a DER signature with a sighash type
+	ErrInvalidSignature = errors.New("invalid signature in psbt")
+	// ErrInvalidPubKey describes a key of a partial signature or derivation that is not a public key
+	ErrInvalidPubKey = errors.New("invalid public key in psbt")
+	// ErrSighashMismatch describes a partial signature with another sighash type than the one its input asks for
+	ErrSighashMismatch = errors.New("the sighash type of a signature is not that of its psbt input")
+	// ErrScriptMismatch describes a redeem or witness script that does not hash to the script it redeems
+	ErrScriptMismatch = errors.New("the redeem or witness script of a psbt input does not match the output it spends")
+	// ErrUnsupportedScript describes an input spending a script the finalizer can not make a signature script or witness for
+	ErrUnsupportedScript = errors.New("psbt input spends a script that can not be finalized")
+	// ErrIncompletePsbt describes a packet with inputs that are not finalized yet
+	ErrIncompletePsbt = errors.New("psbt has inputs that are not finalized")
+)
+// Unknown is a key and value of a type this package does not know, which is kept to be serialized again as BIP174 requires
+type Unknown struct {
+	Key   []byte
+	Value []byte
+}
+// Packet is a partially signed transaction: the unsigned transaction and, for each of its inputs and outputs, what the signers and finalizers need to know about them
+type Packet struct {
+	UnsignedTx *wire.MsgTx
+	Inputs     []PInput
+	Outputs    []POutput
+	Unknowns   []Unknown
+}
+// NewFromUnsignedTx returns a packet of a transaction with empty signature scripts and witnesses, and nothing known yet of its inputs and outputs
+func NewFromUnsignedTx(
+	tx *wire.MsgTx) (*Packet, error) {
+	if err := checkUnsigned(tx); err != nil {
+		return nil, err
+	}
+	return &Packet{
+		UnsignedTx: tx,
+		Inputs:     make([]PInput, len(tx.TxIn)),
+		Outputs:    make([]POutput, len(tx.TxOut)),
+	}, nil
+}
+// NewFromRawBytes reads a serialized packet from r, which is base64 encoded if b64 is true
+func NewFromRawBytes(
+	r io.Reader, b64 bool) (*Packet, error) {
+	if b64 {
+		r = base64.NewDecoder(base64.StdEncoding, r)
+	}
+	m := make([]byte, len(magic))
+	if _, err := io.ReadFull(r, m); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(m, magic) {
+		return nil, ErrInvalidMagic
+	}
+	p := &Packet{}
+	err := readMap(r, func(keyType byte, keyData, value []byte) error {
+		if keyType != globalUnsignedTxType {
+			p.Unknowns = append(p.Unknowns, newUnknown(keyType, keyData, value))
+			return nil
+		}
+		if len(keyData) != 0 {
+			return ErrInvalidKeyData
+		}
+		tx := &wire.MsgTx{}
+		if err := tx.DeserializeNoWitness(bytes.NewReader(value)); err != nil {
+			return err
+		}
+		p.UnsignedTx = tx
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if p.UnsignedTx == nil {
+		return nil, ErrInvalidPsbtFormat
+	}
+	p.Inputs = make([]PInput, len(p.UnsignedTx.TxIn))
+	for i := range p.Inputs {
+		if err = p.Inputs[i].deserialize(r); err != nil {
+			return nil, err
+		}
+	}
+	p.Outputs = make([]POutput, len(p.UnsignedTx.TxOut))
+	for i := range p.Outputs {
+		if err = p.Outputs[i].deserialize(r); err != nil {
+			return nil, err
+		}
+	}
+	if err = p.SanityCheck(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+// Serialize writes the packet to w in the binary format of BIP174
+func (p *Packet) Serialize(w io.Writer) error {
+	if _, err := w.Write(magic); err != nil {
+		return err
+	}
+	var tx bytes.Buffer
+	if err := p.UnsignedTx.SerializeNoWitness(&tx); err != nil {
+		return err
+	}
+	if err := writeKV(w, globalUnsignedTxType, nil, tx.Bytes()); err != nil {
+		return err
+	}
+	if err := writeUnknowns(w, p.Unknowns); err != nil {
+		return err
+	}
+	if err := writeSeparator(w); err != nil {
+		return err
+	}
+	for i := range p.Inputs {
+		if err := p.Inputs[i].serialize(w); err != nil {
+			return err
+		}
+	}
+	for i := range p.Outputs {
+		if err := p.Outputs[i].serialize(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+// B64Encode returns the packet serialized and base64 encoded, as it is passed between RPC clients
+func (p *Packet) B64Encode() (string, error) {
+	var b bytes.Buffer
+	if err := p.Serialize(&b); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(b.Bytes()), nil
+}
+// SanityCheck returns an error if the transaction of the packet is signed, the packet does not have a map for each of its inputs and outputs, or an input has a previous transaction that is not the one it spends
+func (p *Packet) SanityCheck() error {
+	if err := checkUnsigned(p.UnsignedTx); err != nil {
+		return err
+	}
+	if len(p.Inputs) != len(p.UnsignedTx.TxIn) || len(p.Outputs) != len(p.UnsignedTx.TxOut) {
+		return ErrInvalidPsbtFormat
+	}
+	for i, in := range p.Inputs {
+		if in.NonWitnessUtxo == nil {
+			continue
+		}
+		prevOut := p.UnsignedTx.TxIn[i].PreviousOutPoint
+		if in.NonWitnessUtxo.TxHash() != prevOut.Hash || int(prevOut.Index) >= len(in.NonWitnessUtxo.TxOut) {
+			return ErrNonWitnessUtxoMismatch
+		}
+	}
+	return nil
+}
+// IsComplete returns true if every input of the packet is finalized, so the transaction can be extracted
+func (p *Packet) IsComplete() bool {
+	for i := range p.Inputs {
+		if !p.Inputs[i].IsFinal() {
+			return false
+		}
+	}
+	return true
+}
+// Utxo returns the previous output input i spends, from its witness utxo or its non witness utxo
+func (p *Packet) Utxo(
+	i int) (*wire.TxOut, error) {
+	if i < 0 || i >= len(p.Inputs) {
+		return nil, ErrInvalidInputIndex
+	}
+	in := &p.Inputs[i]
+	switch {
+	case in.WitnessUtxo != nil:
+		return in.WitnessUtxo, nil
+	case in.NonWitnessUtxo != nil:
+		index := p.UnsignedTx.TxIn[i].PreviousOutPoint.Index
+		if int(index) >= len(in.NonWitnessUtxo.TxOut) {
+			return nil, ErrNonWitnessUtxoMismatch
+		}
+		return in.NonWitnessUtxo.TxOut[index], nil
+	}
+	return nil, ErrMissingUtxo
+}
+// Fee returns the fee of the transaction, the value of the outputs it spends less that of its outputs, which needs the utxo of every input
+func (p *Packet) Fee() (util.Amount, error) {
+	var fee int64
+	for i := range p.Inputs {
+		utxo, err := p.Utxo(i)
+		if err != nil {
+			return 0, err
+		}
+		fee += utxo.Value
+	}
+	for _, out := range p.UnsignedTx.TxOut {
+		fee -= out.Value
+	}
+	return util.Amount(fee), nil
+}
+// checkUnsigned returns an error if an input of tx has a signature script or witness
+func checkUnsigned(
+	tx *wire.MsgTx) error {
+	for _, in := range tx.TxIn {
+		if len(in.SignatureScript) != 0 || len(in.Witness) != 0 {
+			return ErrInvalidRawTxSigned
+		}
+	}
+	return nil
+}
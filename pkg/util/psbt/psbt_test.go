@@ -0,0 +1,232 @@
+package psbt
+import (
+	"bytes"
+	"crypto/sha256"
+	"strings"
+	"testing"
+	chaincfg "git.parallelcoin.io/dev/9/pkg/chain/config"
+	chainhash "git.parallelcoin.io/dev/9/pkg/chain/hash"
+	txscript "git.parallelcoin.io/dev/9/pkg/chain/tx/script"
+	"git.parallelcoin.io/dev/9/pkg/chain/wire"
+	"git.parallelcoin.io/dev/9/pkg/util"
+	ec "git.parallelcoin.io/dev/9/pkg/util/elliptic"
+)
+// newTestTx returns an unsigned transaction spending output index of prev to one output
+func newTestTx(
+	prev *wire.MsgTx, index uint32) *wire.MsgTx {
+	tx := wire.NewMsgTx(wire.TxVersion)
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{}, index), nil, nil))
+	if prev != nil {
+		tx.TxIn[0].PreviousOutPoint.Hash = prev.TxHash()
+	}
+	tx.AddTxOut(wire.NewTxOut(90000, []byte{txscript.OpTrue}))
+	return tx
+}
+// TestPacketSerialization ensures a packet with every known field and unknown keys in each of its maps serializes back to the same bytes, in binary and base64, and that malformed packets are not read
+func TestPacketSerialization(
+	t *testing.T) {
+	key, err := ec.NewPrivateKey(ec.S256())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubKey := key.PubKey().SerializeCompressed()
+	prev := wire.NewMsgTx(wire.TxVersion)
+	prev.AddTxIn(wire.NewTxIn(&wire.OutPoint{Index: 1}, []byte{txscript.OpTrue}, nil))
+	prev.AddTxOut(wire.NewTxOut(100000, []byte{txscript.OpTrue}))
+	p, err := NewFromUnsignedTx(newTestTx(prev, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := txscript.RawTxInSignature(p.UnsignedTx, 0, prev.TxOut[0].PkScript, txscript.SigHashAll, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	derivation := &Bip32Derivation{PubKey: pubKey, MasterKeyFingerprint: 0xdeadbeef, Bip32Path: []uint32{0x8000002c, 0x80000000, 0x80000000, 0, 5}}
+	p.Unknowns = []Unknown{{Key: []byte{0xfc, 1, 2}, Value: []byte{3}}}
+	p.Inputs[0] = PInput{
+		NonWitnessUtxo:  prev,
+		WitnessUtxo:     prev.TxOut[0],
+		SighashType:     txscript.SigHashAll,
+		RedeemScript:    []byte{txscript.OpTrue},
+		WitnessScript:   []byte{txscript.OpTrue, txscript.OpTrue},
+		Bip32Derivation: []*Bip32Derivation{derivation},
+		Unknowns:        []Unknown{{Key: []byte{0xfc}, Value: []byte{4, 5}}},
+	}
+	if err = p.AddPartialSig(0, pubKey, sig); err != nil {
+		t.Fatal(err)
+	}
+	if err = p.AddPartialSig(0, pubKey, append(sig[:len(sig)-1:len(sig)-1], byte(txscript.SigHashNone))); err != ErrSighashMismatch {
+		t.Errorf("a signature of another sighash type than the input was added: %v", err)
+	}
+	p.Outputs[0] = POutput{
+		RedeemScript:    []byte{txscript.OpTrue},
+		Bip32Derivation: []*Bip32Derivation{derivation},
+		Unknowns:        []Unknown{{Key: []byte{0xfc, 9}, Value: nil}},
+	}
+	var raw bytes.Buffer
+	if err = p.Serialize(&raw); err != nil {
+		t.Fatal(err)
+	}
+	read, err := NewFromRawBytes(bytes.NewReader(raw.Bytes()), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var again bytes.Buffer
+	if err = read.Serialize(&again); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(raw.Bytes(), again.Bytes()) {
+		t.Fatalf("the packet serialized as %x after it was read, not %x", again.Bytes(), raw.Bytes())
+	}
+	if len(read.Inputs[0].PartialSigs) != 1 || read.Inputs[0].Bip32Derivation[0].Bip32Path[4] != 5 || read.Inputs[0].WitnessUtxo.Value != 100000 {
+		t.Fatalf("the input read is %+v", read.Inputs[0])
+	}
+	b64, err := p.B64Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read, err = NewFromRawBytes(strings.NewReader(b64), true); err != nil {
+		t.Fatal(err)
+	}
+	if fee, err := read.Fee(); err != nil || fee != 10000 {
+		t.Errorf("the fee of the packet is %v, %v", fee, err)
+	}
+	// the last byte of a serialized packet ends the map of its last output, and the unknown key before it is repeated as a duplicate
+	unknown := []byte{2, 0xfc, 9, 0}
+	malformed := map[string][]byte{
+		"magic":     append([]byte("psbu"), raw.Bytes()[4:]...),
+		"duplicate": append(append(raw.Bytes()[:raw.Len()-1:raw.Len()-1], unknown...), 0),
+		"truncated": raw.Bytes()[:raw.Len()-1],
+	}
+	for name, b := range malformed {
+		if _, err = NewFromRawBytes(bytes.NewReader(b), false); err == nil {
+			t.Errorf("the %s packet was read", name)
+		}
+	}
+	signed := newTestTx(prev, 0)
+	signed.TxIn[0].SignatureScript = []byte{txscript.OpTrue}
+	if _, err = NewFromUnsignedTx(signed); err != ErrInvalidRawTxSigned {
+		t.Errorf("a packet was made of a signed transaction: %v", err)
+	}
+	p.Inputs[0].NonWitnessUtxo = signed
+	if err = p.SanityCheck(); err != ErrNonWitnessUtxoMismatch {
+		t.Errorf("an input with another previous transaction passed the sanity check: %v", err)
+	}
+}
+// TestFinalize ensures inputs of each kind of script the finalizer knows are finalized once they have enough signatures, and that the extracted transaction is valid
+func TestFinalize(
+	t *testing.T) {
+	params := &chaincfg.MainNetParams
+	keys := make([]*ec.PrivateKey, 3)
+	pubKeys := make([]*util.AddressPubKey, 3)
+	for i := range keys {
+		var err error
+		if keys[i], err = ec.NewPrivateKey(ec.S256()); err != nil {
+			t.Fatal(err)
+		}
+		if pubKeys[i], err = util.NewAddressPubKey(keys[i].PubKey().SerializeCompressed(), params); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pubKey := keys[0].PubKey().SerializeCompressed()
+	pkh, err := util.NewAddressPubKeyHash(util.Hash160(pubKey), params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wpkh, err := util.NewAddressWitnessPubKeyHash(util.Hash160(pubKey), params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	multiSig, err := txscript.MultiSigScript(pubKeys, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wshHash := sha256.Sum256(multiSig)
+	wsh, err := util.NewAddressWitnessScriptHash(wshHash[:], params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wpkhScript, err := txscript.PayToAddrScript(wpkh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name          string
+		addr          util.Address
+		redeemScript  []byte
+		witnessScript []byte
+		signers       int
+	}{
+		{name: "p2pkh", addr: pkh, signers: 1},
+		{name: "p2wpkh", addr: wpkh, signers: 1},
+		{name: "p2sh-p2wpkh", redeemScript: wpkhScript, signers: 1},
+		{name: "p2sh multisig", redeemScript: multiSig, signers: 2},
+		{name: "p2wsh multisig", addr: wsh, witnessScript: multiSig, signers: 2},
+	}
+	for _, test := range tests {
+		addr := test.addr
+		if addr == nil {
+			if addr, err = util.NewAddressScriptHash(test.redeemScript, params); err != nil {
+				t.Fatal(err)
+			}
+		}
+		pkScript, err := txscript.PayToAddrScript(addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		utxo := wire.NewTxOut(100000, pkScript)
+		p, err := NewFromUnsignedTx(newTestTx(nil, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		p.Inputs[0].WitnessUtxo = utxo
+		p.Inputs[0].RedeemScript = test.redeemScript
+		p.Inputs[0].WitnessScript = test.witnessScript
+		// The script signed is the one the input spends after its redeem script, or its witness script.
+		subScript, witness := pkScript, txscript.IsWitnessProgram(pkScript)
+		if test.redeemScript != nil {
+			subScript, witness = test.redeemScript, txscript.IsWitnessProgram(test.redeemScript)
+		}
+		if test.witnessScript != nil {
+			subScript = test.witnessScript
+		}
+		sigHashes := txscript.NewTxSigHashes(p.UnsignedTx)
+		for i := 0; i < test.signers; i++ {
+			var sig []byte
+			if witness {
+				sig, err = txscript.RawTxInWitnessSignature(p.UnsignedTx, sigHashes, 0, utxo.Value, subScript, txscript.SigHashAll, keys[i])
+			} else {
+				sig, err = txscript.RawTxInSignature(p.UnsignedTx, 0, subScript, txscript.SigHashAll, keys[i])
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if final, err := p.MaybeFinalize(0); final || err != nil {
+				t.Fatalf("%s: the input with %d of %d signatures was finalized: %v", test.name, i, test.signers, err)
+			}
+			if err = p.AddPartialSig(0, keys[i].PubKey().SerializeCompressed(), sig); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err = p.Extract(); err != ErrIncompletePsbt {
+			t.Errorf("%s: a transaction was extracted before it was finalized: %v", test.name, err)
+		}
+		if err = p.MaybeFinalizeAll(); err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if !p.IsComplete() || p.Inputs[0].PartialSigs != nil || p.Inputs[0].RedeemScript != nil {
+			t.Fatalf("%s: the input finalized is %+v", test.name, p.Inputs[0])
+		}
+		tx, err := p.Extract()
+		if err != nil {
+			t.Fatal(err)
+		}
+		vm, err := txscript.NewEngine(pkScript, tx, 0, txscript.StandardVerifyFlags, nil, nil, utxo.Value)
+		if err == nil {
+			err = vm.Execute()
+		}
+		if err != nil {
+			t.Errorf("%s: the extracted transaction is not valid: %v", test.name, err)
+		}
+	}
+}
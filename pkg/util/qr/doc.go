@@ -0,0 +1,2 @@
+// Package qr encodes short texts such as payment addresses and URIs as QR codes, in byte mode with the low error correction level and versions 1 to 10 (up to 271 bytes), and renders them for display on terminals or as SVG and PNG images. PaymentURI makes the BIP21 style URIs to encode for a payment to an address.
+package qr
@@ -0,0 +1,63 @@
+package qr
+// bitStream builds the data codewords of a version: the byte mode indicator, the length, the data, a terminator and padding
+func bitStream(v int, data []byte) []byte {
+	var bits []bool
+	put := func(value, n int) {
+		for i := n - 1; i >= 0; i-- {
+			bits = append(bits, value>>uint(i)&1 == 1)
+		}
+	}
+	put(4, 4)
+	put(len(data), countBits(v))
+	for _, b := range data {
+		put(int(b), 8)
+	}
+	total := dataCodewords(v) * 8
+	for i := 0; i < 4 && len(bits) < total; i++ {
+		bits = append(bits, false)
+	}
+	for len(bits)%8 != 0 {
+		bits = append(bits, false)
+	}
+	out := make([]byte, 0, dataCodewords(v))
+	for i := 0; i < len(bits); i += 8 {
+		var b byte
+		for j := 0; j < 8; j++ {
+			if bits[i+j] {
+				b |= 0x80 >> uint(j)
+			}
+		}
+		out = append(out, b)
+	}
+	for pad := byte(0xec); len(out) < cap(out); pad ^= 0xec ^ 0x11 {
+		out = append(out, pad)
+	}
+	return out
+}
+// interleave splits the data codewords into blocks, appends the error correction codewords of each block and interleaves them in the order they are placed in the symbol
+func interleave(v int, data []byte) (out []byte) {
+	ver := versions[v]
+	gen := generator(ver.ecPerBlock)
+	var blocks, ecc [][]byte
+	for _, g := range ver.blocks {
+		for i := 0; i < g[0]; i++ {
+			blocks = append(blocks, data[:g[1]])
+			ecc = append(ecc, remainder(data[:g[1]], gen))
+			data = data[g[1]:]
+		}
+	}
+	longest := len(blocks[len(blocks)-1])
+	for i := 0; i < longest; i++ {
+		for _, b := range blocks {
+			if i < len(b) {
+				out = append(out, b[i])
+			}
+		}
+	}
+	for i := 0; i < ver.ecPerBlock; i++ {
+		for _, e := range ecc {
+			out = append(out, e[i])
+		}
+	}
+	return
+}
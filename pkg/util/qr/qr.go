@@ -0,0 +1,111 @@
+package qr
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+)
+// ErrTooLong is returned when the text does not fit in the largest supported version
+var ErrTooLong = errors.New("qr: text too long to encode")
+// Code is an encoded QR symbol, a square of dark and light modules
+type Code struct {
+	// Size is the number of modules along each side
+	Size    int
+	Version int
+	modules []bool
+}
+// Dark reports whether the module at column x and row y is dark. Coordinates outside the symbol are light, as is the quiet zone around it.
+func (c *Code) Dark(x, y int) bool {
+	if x < 0 || y < 0 || x >= c.Size || y >= c.Size {
+		return false
+	}
+	return c.modules[y*c.Size+x]
+}
+// HalfBlocks renders the code with quiet modules of margin around it as lines of text, two module rows per line, using the upper and lower half block characters for dark modules. The lines must be displayed dark on light to scan, so on terminals set the colours explicitly rather than relying on the theme.
+func (c *Code) HalfBlocks(quiet int) (lines []string) {
+	for y := -quiet; y < c.Size+quiet; y += 2 {
+		var b strings.Builder
+		for x := -quiet; x < c.Size+quiet; x++ {
+			top, bottom := c.Dark(x, y), c.Dark(x, y+1)
+			switch {
+			case top && bottom:
+				b.WriteRune('█')
+			case top:
+				b.WriteRune('▀')
+			case bottom:
+				b.WriteRune('▄')
+			default:
+				b.WriteRune(' ')
+			}
+		}
+		lines = append(lines, b.String())
+	}
+	return
+}
+// SVG renders the code with quiet modules of margin around it as an SVG image one unit per module, dark modules drawn as a single path over a white background, to be scaled to any size by the page showing it
+func (c *Code) SVG(quiet int) string {
+	side := c.Size + 2*quiet
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" shape-rendering="crispEdges">`, side, side)
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="#fff"/><path fill="#000" d="`, side, side)
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if c.Dark(x, y) {
+				fmt.Fprintf(&b, "M%d %dh1v1h-1z", x+quiet, y+quiet)
+			}
+		}
+	}
+	b.WriteString(`"/></svg>`)
+	return b.String()
+}
+// PNG renders the code with quiet modules of margin around it as a black and white PNG image, each module a square of scale pixels, for saving or printing where an SVG won't do
+func (c *Code) PNG(scale, quiet int) ([]byte, error) {
+	if scale < 1 {
+		scale = 1
+	}
+	side := (c.Size + 2*quiet) * scale
+	img := image.NewPaletted(image.Rect(0, 0, side, side), color.Palette{color.White, color.Black})
+	for y := 0; y < side; y++ {
+		for x := 0; x < side; x++ {
+			if c.Dark(x/scale-quiet, y/scale-quiet) {
+				img.SetColorIndex(x, y, 1)
+			}
+		}
+	}
+	var b bytes.Buffer
+	if err := png.Encode(&b, img); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+// Encode encodes text in the smallest version it fits in, choosing the mask with the lowest penalty
+func Encode(text string) (*Code, error) {
+	data := []byte(text)
+	v := 1
+	for ; v < len(versions); v++ {
+		if len(data) <= capacity(v) {
+			break
+		}
+	}
+	if v == len(versions) {
+		return nil, ErrTooLong
+	}
+	codewords := interleave(v, bitStream(v, data))
+	var best *Code
+	bestPenalty := 0
+	for mask := 0; mask < 8; mask++ {
+		m := newMatrix(v)
+		m.drawFunctions()
+		m.drawCodewords(codewords)
+		m.applyMask(mask)
+		m.drawFormat(mask)
+		if p := m.penalty(); best == nil || p < bestPenalty {
+			best = &Code{Size: m.size, Version: v, modules: m.modules}
+			bestPenalty = p
+		}
+	}
+	return best, nil
+}
@@ -0,0 +1,282 @@
+package qr
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+)
+// decode reads a code back into its text, checking the format information and the error correction of every block
+func decode(t *testing.T, c *Code) string {
+	t.Helper()
+	bits := 0
+	for i := 0; i <= 5; i++ {
+		if c.Dark(8, i) {
+			bits |= 1 << uint(i)
+		}
+	}
+	for i, xy := range [][2]int{{8, 7}, {8, 8}, {7, 8}} {
+		if c.Dark(xy[0], xy[1]) {
+			bits |= 1 << uint(6+i)
+		}
+	}
+	for i := 9; i < 15; i++ {
+		if c.Dark(14-i, 8) {
+			bits |= 1 << uint(i)
+		}
+	}
+	format := bits ^ 0x5412
+	if format>>13 != 1 {
+		t.Fatalf("format %015b is not error correction level L", bits)
+	}
+	mask := format >> 10 & 7
+	m := newMatrix(c.Version)
+	m.drawFunctions()
+	copy(m.modules, c.modules)
+	m.applyMask(mask)
+	var codewords []byte
+	var b byte
+	n := 0
+	for right := m.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < m.size; vert++ {
+			y := vert
+			if (right+1)&2 == 0 {
+				y = m.size - 1 - vert
+			}
+			for j := 0; j < 2; j++ {
+				x := right - j
+				if m.function[y*m.size+x] {
+					continue
+				}
+				b <<= 1
+				if m.get(x, y) {
+					b |= 1
+				}
+				if n++; n%8 == 0 {
+					codewords = append(codewords, b)
+				}
+			}
+		}
+	}
+	ver := versions[c.Version]
+	var blocks [][]byte
+	for _, g := range ver.blocks {
+		for i := 0; i < g[0]; i++ {
+			blocks = append(blocks, make([]byte, 0, g[1]+ver.ecPerBlock))
+		}
+	}
+	longest := cap(blocks[len(blocks)-1]) - ver.ecPerBlock
+	for i := 0; i < longest; i++ {
+		for k := range blocks {
+			if i < cap(blocks[k])-ver.ecPerBlock {
+				blocks[k] = append(blocks[k], codewords[0])
+				codewords = codewords[1:]
+			}
+		}
+	}
+	for i := 0; i < ver.ecPerBlock; i++ {
+		for k := range blocks {
+			blocks[k] = append(blocks[k], codewords[0])
+			codewords = codewords[1:]
+		}
+	}
+	var data []byte
+	for k, block := range blocks {
+		// every root of the generator must be a root of the received block
+		for i := 0; i < ver.ecPerBlock; i++ {
+			var s byte
+			for _, cw := range block {
+				s = mul(s, exp[i]) ^ cw
+			}
+			if s != 0 {
+				t.Fatalf("block %d has syndrome %d = %d", k, i, s)
+			}
+		}
+		data = append(data, block[:len(block)-ver.ecPerBlock]...)
+	}
+	if data[0]>>4 != 4 {
+		t.Fatalf("mode %d is not byte mode", data[0]>>4)
+	}
+	if countBits(c.Version) == 8 {
+		length := int(data[0]&15)<<4 | int(data[1]>>4)
+		out := make([]byte, length)
+		for i := range out {
+			out[i] = data[1+i]<<4 | data[2+i]>>4
+		}
+		return string(out)
+	}
+	length := int(data[0]&15)<<12 | int(data[1])<<4 | int(data[2]>>4)
+	out := make([]byte, length)
+	for i := range out {
+		out[i] = data[2+i]<<4 | data[3+i]>>4
+	}
+	return string(out)
+}
+func TestRoundTrip(t *testing.T) {
+	texts := []string{
+		"",
+		"a",
+		"parallelcoin:aUXq9YJHnJYVTv7amUUm2zFhd3X6eDk8fv",
+		"parallelcoin:aUXq9YJHnJYVTv7amUUm2zFhd3X6eDk8fv?amount=12.5&label=test",
+		strings.Repeat("0123456789", 27),
+	}
+	for _, text := range texts {
+		c, err := Encode(text)
+		if err != nil {
+			t.Fatalf("%q: %v", text, err)
+		}
+		if c.Size != 17+4*c.Version {
+			t.Errorf("%q: size %d for version %d", text, c.Size, c.Version)
+		}
+		if got := decode(t, c); got != text {
+			t.Errorf("decoded %q, want %q", got, text)
+		}
+	}
+}
+func TestVersions(t *testing.T) {
+	for v := 1; v < len(versions); v++ {
+		c, err := Encode(strings.Repeat("x", capacity(v)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.Version != v {
+			t.Errorf("%d bytes encoded as version %d, want %d", capacity(v), c.Version, v)
+		}
+		if total := dataCodewords(v) + versions[v].ecPerBlock*c.blocks(); total != rawCodewords(v) {
+			t.Errorf("version %d has %d codewords, want %d", v, total, rawCodewords(v))
+		}
+	}
+	if _, err := Encode(strings.Repeat("x", capacity(len(versions)-1)+1)); err != ErrTooLong {
+		t.Errorf("got %v for too long text, want ErrTooLong", err)
+	}
+}
+// blocks returns the number of error correction blocks of the code's version
+func (c *Code) blocks() (n int) {
+	for _, g := range versions[c.Version].blocks {
+		n += g[0]
+	}
+	return
+}
+// rawCodewords counts the modules left for codewords after the function patterns of a version
+func rawCodewords(v int) int {
+	m := newMatrix(v)
+	m.drawFunctions()
+	n := 0
+	for _, f := range m.function {
+		if !f {
+			n++
+		}
+	}
+	return n / 8
+}
+func TestFormatBits(t *testing.T) {
+	// format information of level L with mask 0 from the standard
+	m := newMatrix(1)
+	m.drawFormat(0)
+	want := "111011111000100"
+	var got []byte
+	for i := 14; i >= 0; i-- {
+		x, y := 8, i
+		switch {
+		case i == 6:
+			y = 7
+		case i == 7:
+			y = 8
+		case i == 8:
+			x, y = 7, 8
+		case i > 8:
+			x, y = 14-i, 8
+		}
+		if m.get(x, y) {
+			got = append(got, '1')
+		} else {
+			got = append(got, '0')
+		}
+	}
+	if string(got) != want {
+		t.Errorf("format bits %s, want %s", got, want)
+	}
+}
+func TestHalfBlocks(t *testing.T) {
+	c, err := Encode("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := c.HalfBlocks(2)
+	if len(lines) != (c.Size+5)/2 {
+		t.Errorf("%d lines for size %d, want %d", len(lines), c.Size, (c.Size+5)/2)
+	}
+	// the top left finder pattern starts after the quiet zone
+	if !strings.HasPrefix(lines[1], "  █▀▀▀▀▀█") {
+		t.Errorf("line %q does not start with a finder pattern", lines[1])
+	}
+}
+func TestSVG(t *testing.T) {
+	c, err := Encode("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	svg := c.SVG(4)
+	if !strings.HasPrefix(svg, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 29 29"`) {
+		t.Errorf("svg %q does not start with a 29 unit view box", svg[:80])
+	}
+	// the top left module of the finder pattern is dark and inside the quiet zone
+	if !strings.Contains(svg, `d="M4 4h1v1h-1z`) {
+		t.Error("svg does not start its path with the top left finder module")
+	}
+	dark := 0
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if c.Dark(x, y) {
+				dark++
+			}
+		}
+	}
+	if got, want := strings.Count(svg, "h1v1h-1z"), dark; got != want {
+		t.Errorf("%d dark modules drawn, want %d", got, want)
+	}
+}
+func TestPNG(t *testing.T) {
+	c, err := Encode("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := c.PNG(3, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := img.Bounds().Dx(), (c.Size+8)*3; got != want || img.Bounds().Dy() != want {
+		t.Fatalf("image is %v, want %d pixels square", img.Bounds(), want)
+	}
+	for y := 0; y < img.Bounds().Dy(); y++ {
+		for x := 0; x < img.Bounds().Dx(); x++ {
+			r, _, _, _ := img.At(x, y).RGBA()
+			if dark := r == 0; dark != c.Dark(x/3-4, y/3-4) {
+				t.Fatalf("pixel %d,%d dark is %v", x, y, dark)
+			}
+		}
+	}
+}
+func TestPaymentURI(t *testing.T) {
+	for _, test := range []struct {
+		amount         float64
+		label, message string
+		want           string
+	}{
+		{0, "", "", "parallelcoin:PAddr"},
+		{1.5, "", "", "parallelcoin:PAddr?amount=1.5"},
+		{20, "", "", "parallelcoin:PAddr?amount=20"},
+		{0.00000001, "", "", "parallelcoin:PAddr?amount=0.00000001"},
+		{0.1, "Ann & Bob", "rent for may", "parallelcoin:PAddr?amount=0.1&label=Ann%20%26%20Bob&message=rent%20for%20may"},
+	} {
+		if got := PaymentURI("PAddr", test.amount, test.label, test.message); got != test.want {
+			t.Errorf("got %s, want %s", got, test.want)
+		}
+	}
+}
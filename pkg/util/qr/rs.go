@@ -0,0 +1,47 @@
+package qr
+// exp and log are the tables of GF(256) with the QR reducing polynomial x^8+x^4+x^3+x^2+1
+var exp, log [256]byte
+func init() {
+	x := 1
+	for i := 0; i < 255; i++ {
+		exp[i] = byte(x)
+		log[x] = byte(i)
+		x <<= 1
+		if x&0x100 != 0 {
+			x ^= 0x11d
+		}
+	}
+	exp[255] = exp[0]
+}
+func mul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return exp[(int(log[a])+int(log[b]))%255]
+}
+// generator returns the coefficients, highest degree first without the leading 1, of the Reed-Solomon generator polynomial with n roots
+func generator(n int) []byte {
+	g := []byte{1}
+	for i := 0; i < n; i++ {
+		next := make([]byte, len(g)+1)
+		for j, c := range g {
+			next[j] ^= c
+			next[j+1] ^= mul(c, exp[i])
+		}
+		g = next
+	}
+	return g[1:]
+}
+// remainder returns the error correction codewords of a block, the remainder of the block times x^n divided by the generator
+func remainder(data, gen []byte) []byte {
+	r := make([]byte, len(gen))
+	for _, d := range data {
+		factor := d ^ r[0]
+		copy(r, r[1:])
+		r[len(r)-1] = 0
+		for i, g := range gen {
+			r[i] ^= mul(g, factor)
+		}
+	}
+	return r
+}
@@ -0,0 +1,41 @@
+package qr
+// version holds the error correction layout of a version at the low error correction level
+type version struct {
+	// ecPerBlock is the number of error correction codewords in each block
+	ecPerBlock int
+	// blocks lists groups of blocks as the number of blocks and their count of data codewords
+	blocks [][2]int
+	// align is the position of the alignment pattern centres along each axis
+	align []int
+}
+var versions = []version{
+	{},
+	{7, [][2]int{{1, 19}}, nil},
+	{10, [][2]int{{1, 34}}, []int{6, 18}},
+	{15, [][2]int{{1, 55}}, []int{6, 22}},
+	{20, [][2]int{{1, 80}}, []int{6, 26}},
+	{26, [][2]int{{1, 108}}, []int{6, 30}},
+	{18, [][2]int{{2, 68}}, []int{6, 34}},
+	{20, [][2]int{{2, 78}}, []int{6, 22, 38}},
+	{24, [][2]int{{2, 97}}, []int{6, 24, 42}},
+	{30, [][2]int{{2, 116}}, []int{6, 26, 46}},
+	{18, [][2]int{{2, 68}, {2, 69}}, []int{6, 28, 50}},
+}
+// dataCodewords returns the number of data codewords of a version
+func dataCodewords(v int) (n int) {
+	for _, g := range versions[v].blocks {
+		n += g[0] * g[1]
+	}
+	return
+}
+// countBits is the width of the byte mode character count of a version
+func countBits(v int) int {
+	if v < 10 {
+		return 8
+	}
+	return 16
+}
+// capacity returns the number of bytes a version holds in byte mode
+func capacity(v int) int {
+	return (dataCodewords(v)*8 - 4 - countBits(v)) / 8
+}
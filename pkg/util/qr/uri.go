@@ -0,0 +1,29 @@
+package qr
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+// URIScheme is the scheme of payment URIs, which wallets scanning the code open as a payment to fill in
+const URIScheme = "parallelcoin"
+// PaymentURI makes a BIP21 style payment URI for an address, with the amount in DUO, a label for the payee and a message describing the payment added where they are given
+func PaymentURI(address string, amount float64, label, message string) string {
+	q := url.Values{}
+	if amount > 0 {
+		// eight decimal places is the smallest unit, without trailing zeros
+		a := strings.TrimRight(strconv.FormatFloat(amount, 'f', 8, 64), "0")
+		q.Set("amount", strings.TrimSuffix(a, "."))
+	}
+	if label != "" {
+		q.Set("label", label)
+	}
+	if message != "" {
+		q.Set("message", message)
+	}
+	uri := URIScheme + ":" + address
+	if len(q) > 0 {
+		// BIP21 wants spaces as %20, which wallets decode more reliably than +
+		uri += "?" + strings.Replace(q.Encode(), "+", "%20", -1)
+	}
+	return uri
+}
@@ -14,10 +14,6 @@
 
 package tcell
 
-import (
-	"github.com/mattn/go-runewidth"
-)
-
 type cell struct {
 	currMain  rune
 	currComb  []rune
@@ -41,7 +37,9 @@ type CellBuffer struct {
 }
 
 // SetContent sets the contents (primary rune, combining runes,
-// and style) for a cell at a given location.
+// and style) for a cell at a given location.  The combining runes
+// are the rest of the grapheme cluster, which may include joined
+// wide runes such as in emoji sequences, and are kept as given.
 func (cb *CellBuffer) SetContent(x int, y int,
 	mainc rune, combc []rune, style Style) {
 
@@ -49,20 +47,7 @@ func (cb *CellBuffer) SetContent(x int, y int,
 		c := &cb.cells[(y*cb.w)+x]
 
 		c.currComb = append([]rune{}, combc...)
-		i := 0
-		for i < len(c.currComb) {
-			r := c.currComb[i]
-			if runewidth.RuneWidth(r) != 0 {
-				// not a combining character, yank it
-				c.currComb = append(c.currComb[:i-1], c.currComb[i+1:]...)
-				continue
-			}
-			i++
-		}
-
-		if c.currMain != mainc {
-			c.width = runewidth.RuneWidth(mainc)
-		}
+		c.width = ClusterWidth(mainc, c.currComb)
 		c.currMain = mainc
 		c.currStyle = style
 	}
@@ -0,0 +1,116 @@
+// Copyright 2015 The TCell Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use file except in compliance with the License.
+// You may obtain a copy of the license at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tcell
+
+import (
+	"github.com/mattn/go-runewidth"
+)
+
+// ClusterWidth returns the number of cells a grapheme cluster takes up on
+// the screen, given its first rune and the runes combined with it.  The
+// width of the first rune which has one is used, except that a variation
+// selector asks for emoji (two cells) or text (one cell) presentation, and
+// a pair of regional indicators is shown as a two cell flag.
+func ClusterWidth(mainc rune, combc []rune) int {
+	width := RuneWidth(mainc)
+	for _, r := range combc {
+		if width > 0 {
+			break
+		}
+		width = RuneWidth(r)
+	}
+	for _, r := range combc {
+		switch {
+		case r == '\ufe0f':
+			return 2
+		case r == '\ufe0e':
+			return 1
+		case isRegionalIndicator(mainc) && isRegionalIndicator(r):
+			return 2
+		}
+	}
+	return width
+}
+
+// RuneWidth returns the number of cells a rune takes up on the screen.  It
+// is runewidth.RuneWidth with the emoji added to Unicode after its tables
+// were generated, which terminals show two cells wide, and with the emoji
+// that are shown as text unless a variation selector asks otherwise one
+// cell wide, as runewidth makes every emoji two.
+func RuneWidth(r rune) int {
+	switch {
+	case r == 0x1f93b, r == 0x1f946:
+		// the only symbols in these blocks shown as text
+	case r >= 0x1f90c && r <= 0x1f9ff, r >= 0x1fa70 && r <= 0x1faff:
+		return 2
+	case isTextEmoji(r):
+		if runewidth.DefaultCondition.EastAsianWidth && runewidth.IsAmbiguousWidth(r) {
+			return 2
+		}
+		return 1
+	}
+	return runewidth.RuneWidth(r)
+}
+
+// textEmoji are the ranges of the emoji whose default presentation is text,
+// those with Emoji_Presentation=No in the Unicode emoji data, above ASCII.
+var textEmoji = [][2]rune{
+	{0x00a9, 0x00a9}, {0x00ae, 0x00ae}, {0x203c, 0x203c}, {0x2049, 0x2049},
+	{0x2122, 0x2122}, {0x2139, 0x2139}, {0x2194, 0x2199}, {0x21a9, 0x21aa},
+	{0x2328, 0x2328}, {0x23cf, 0x23cf}, {0x23ed, 0x23ef}, {0x23f1, 0x23f2},
+	{0x23f8, 0x23fa}, {0x24c2, 0x24c2}, {0x25aa, 0x25ab}, {0x25b6, 0x25b6},
+	{0x25c0, 0x25c0}, {0x25fb, 0x25fc}, {0x2600, 0x2604}, {0x260e, 0x260e},
+	{0x2611, 0x2611}, {0x2618, 0x2618}, {0x261d, 0x261d}, {0x2620, 0x2620},
+	{0x2622, 0x2623}, {0x2626, 0x2626}, {0x262a, 0x262a}, {0x262e, 0x262f},
+	{0x2638, 0x263a}, {0x2640, 0x2640}, {0x2642, 0x2642}, {0x265f, 0x2660},
+	{0x2663, 0x2663}, {0x2665, 0x2666}, {0x2668, 0x2668}, {0x267b, 0x267b},
+	{0x267e, 0x267e}, {0x2692, 0x2692}, {0x2694, 0x2697}, {0x2699, 0x2699},
+	{0x269b, 0x269c}, {0x26a0, 0x26a0}, {0x26a7, 0x26a7}, {0x26b0, 0x26b1},
+	{0x26c8, 0x26c8}, {0x26cf, 0x26cf}, {0x26d1, 0x26d1}, {0x26d3, 0x26d3},
+	{0x26e9, 0x26e9}, {0x26f0, 0x26f1}, {0x26f4, 0x26f4}, {0x26f7, 0x26f9},
+	{0x2702, 0x2702}, {0x2708, 0x2709}, {0x270c, 0x270d}, {0x270f, 0x270f},
+	{0x2712, 0x2712}, {0x2714, 0x2714}, {0x2716, 0x2716}, {0x271d, 0x271d},
+	{0x2721, 0x2721}, {0x2733, 0x2734}, {0x2744, 0x2744}, {0x2747, 0x2747},
+	{0x2763, 0x2764}, {0x27a1, 0x27a1}, {0x2934, 0x2935}, {0x2b05, 0x2b07},
+	{0x3030, 0x3030}, {0x303d, 0x303d}, {0x3297, 0x3297}, {0x3299, 0x3299},
+	{0x1f170, 0x1f171}, {0x1f17e, 0x1f17f}, {0x1f202, 0x1f202}, {0x1f237, 0x1f237},
+	{0x1f321, 0x1f321}, {0x1f324, 0x1f32c}, {0x1f336, 0x1f336}, {0x1f37d, 0x1f37d},
+	{0x1f396, 0x1f397}, {0x1f399, 0x1f39b}, {0x1f39e, 0x1f39f}, {0x1f3cb, 0x1f3ce},
+	{0x1f3d4, 0x1f3df}, {0x1f3f3, 0x1f3f3}, {0x1f3f5, 0x1f3f5}, {0x1f3f7, 0x1f3f7},
+	{0x1f43f, 0x1f43f}, {0x1f441, 0x1f441}, {0x1f4fd, 0x1f4fd}, {0x1f549, 0x1f54a},
+	{0x1f56f, 0x1f570}, {0x1f573, 0x1f579}, {0x1f587, 0x1f587}, {0x1f58a, 0x1f58d},
+	{0x1f590, 0x1f590}, {0x1f5a5, 0x1f5a5}, {0x1f5a8, 0x1f5a8}, {0x1f5b1, 0x1f5b2},
+	{0x1f5bc, 0x1f5bc}, {0x1f5c2, 0x1f5c4}, {0x1f5d1, 0x1f5d3}, {0x1f5dc, 0x1f5de},
+	{0x1f5e1, 0x1f5e1}, {0x1f5e3, 0x1f5e3}, {0x1f5e8, 0x1f5e8}, {0x1f5ef, 0x1f5ef},
+	{0x1f5f3, 0x1f5f3}, {0x1f5fa, 0x1f5fa}, {0x1f6cb, 0x1f6cb}, {0x1f6cd, 0x1f6cf},
+	{0x1f6e0, 0x1f6e5}, {0x1f6e9, 0x1f6e9}, {0x1f6f0, 0x1f6f0}, {0x1f6f3, 0x1f6f3},
+}
+
+// isTextEmoji returns whether r is an emoji shown as text by default.
+func isTextEmoji(r rune) bool {
+	for _, span := range textEmoji {
+		if r < span[0] {
+			return false
+		}
+		if r <= span[1] {
+			return true
+		}
+	}
+	return false
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1f1e6 && r <= 0x1f1ff
+}
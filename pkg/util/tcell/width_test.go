@@ -0,0 +1,66 @@
+// Copyright 2015 The TCell Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use file except in compliance with the License.
+// You may obtain a copy of the license at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tcell
+
+import (
+	"testing"
+)
+
+func TestClusterWidth(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		cluster string
+		width   int
+	}{
+		{"ascii", "a", 1},
+		{"combining accent", "e\u0301", 1},
+		{"cjk", "漢", 2},
+		{"hangul", "한", 2},
+		{"emoji", "😀", 2},
+		{"newer emoji", "🥰", 2},
+		{"skin tone", "👍🏽", 2},
+		{"zwj family", "👩\u200d👩\u200d👧", 2},
+		{"text emoji", "❤", 1},
+		{"text emoji after ascii", "\u2122", 1},
+		{"emoji presentation", "❤\ufe0f", 2},
+		{"text presentation", "⌚\ufe0e", 1},
+		{"keycap", "1\ufe0f\u20e3", 2},
+		{"flag", "🇩🇪", 2},
+	} {
+		runes := []rune(test.cluster)
+		if w := ClusterWidth(runes[0], runes[1:]); w != test.width {
+			t.Errorf("%s: %q is %d cells wide, want %d", test.name, test.cluster, w, test.width)
+		}
+	}
+}
+
+func TestSetContentWidth(t *testing.T) {
+	var cb CellBuffer
+	cb.Resize(4, 1)
+	cb.SetContent(0, 0, '❤', nil, StyleDefault)
+	if _, _, _, w := cb.GetContent(0, 0); w != 1 {
+		t.Errorf("text heart is %d cells wide, want 1", w)
+	}
+	// the same main rune with a variation selector changes the width
+	cb.SetContent(0, 0, '❤', []rune{'\ufe0f'}, StyleDefault)
+	if _, _, _, w := cb.GetContent(0, 0); w != 2 {
+		t.Errorf("emoji heart is %d cells wide, want 2", w)
+	}
+	// the runes of a zero width joiner sequence are all kept
+	cb.SetContent(2, 0, '👩', []rune{'\u200d', '👧'}, StyleDefault)
+	if _, comb, _, w := cb.GetContent(2, 0); len(comb) != 2 || w != 2 {
+		t.Errorf("zwj sequence kept %q and is %d cells wide", comb, w)
+	}
+}
@@ -1,10 +1,37 @@
 package tview
 import (
 	"sync"
+	"time"
 	"git.parallelcoin.io/dev/9/pkg/util/tcell"
 )
 // The size of the event/update/redraw channels.
 const queueSize = 100
+// DoubleClickInterval is the longest time between two clicks of a mouse button
+// for them to be taken as a double click rather than two clicks.
+var DoubleClickInterval = 500 * time.Millisecond
+// MouseAction is one of the actions the mouse logically does, derived from the
+// changes of its position and buttons between two mouse events.
+type MouseAction int16
+// Available mouse actions.
+const (
+	MouseMove MouseAction = iota
+	MouseLeftDown
+	MouseLeftUp
+	MouseLeftClick
+	MouseLeftDoubleClick
+	MouseMiddleDown
+	MouseMiddleUp
+	MouseMiddleClick
+	MouseMiddleDoubleClick
+	MouseRightDown
+	MouseRightUp
+	MouseRightClick
+	MouseRightDoubleClick
+	MouseScrollUp
+	MouseScrollDown
+	MouseScrollLeft
+	MouseScrollRight
+)
 // Application represents the top node of an application.
 //
 // It is not strictly required to use this class as none of the other classes
@@ -33,6 +60,22 @@ type Application struct {
 	// event to be forwarded to the default input handler (nil if nothing should
 	// be forwarded).
 	inputCapture func(event *tcell.EventKey) *tcell.EventKey
+	// Whether or not mouse events are enabled.
+	enableMouse bool
+	// An optional capture function which receives a mouse event and its action
+	// and returns the event and action to be forwarded to the root primitive
+	// (a nil event if nothing should be forwarded).
+	mouseCapture func(event *tcell.EventMouse, action MouseAction) (*tcell.EventMouse, MouseAction)
+	// The primitive which captures the mouse events until it releases them,
+	// for example while a button is held down on it.
+	mouseCapturingPrimitive Primitive
+	// The last position of the mouse, the position at which a button was last
+	// pressed, and the buttons that were pressed in the last mouse event.
+	lastMouseX, lastMouseY int
+	mouseDownX, mouseDownY int
+	lastMouseButtons       tcell.ButtonMask
+	// The time of the last click, used to detect double clicks.
+	lastMouseClick time.Time
 	// An optional callback function which is invoked just before the root
 	// primitive is drawn.
 	beforeDraw func(screen tcell.Screen) bool
@@ -75,6 +118,38 @@ func (a *Application) SetInputCapture(capture func(event *tcell.EventKey) *tcell
 func (a *Application) GetInputCapture() func(event *tcell.EventKey) *tcell.EventKey {
 	return a.inputCapture
 }
+// SetMouseCapture sets a function which captures mouse events (consisting of
+// the original tcell mouse event and the semantic mouse action) before they are
+// forwarded to the appropriate mouse event handler. This function can then
+// choose to forward that event (or a different one) by returning it or stop
+// the event processing by returning a nil mouse event.
+func (a *Application) SetMouseCapture(capture func(event *tcell.EventMouse, action MouseAction) (*tcell.EventMouse, MouseAction)) *Application {
+	a.mouseCapture = capture
+	return a
+}
+// GetMouseCapture returns the function installed with SetMouseCapture() or nil
+// if no such function has been installed.
+func (a *Application) GetMouseCapture() func(event *tcell.EventMouse, action MouseAction) (*tcell.EventMouse, MouseAction) {
+	return a.mouseCapture
+}
+// EnableMouse sets whether mouse events are received. When they are, clicking
+// a primitive focuses it and selects what was clicked in it, and the mouse
+// wheel scrolls lists, tables, text views and tree views. Mouse events are
+// disabled by default, as the terminal then no longer lets the user select text
+// with the mouse.
+func (a *Application) EnableMouse(enable bool) *Application {
+	a.Lock()
+	a.enableMouse = enable
+	if a.screen != nil {
+		if enable {
+			a.screen.EnableMouse()
+		} else {
+			a.screen.DisableMouse()
+		}
+	}
+	a.Unlock()
+	return a
+}
 // SetScreen allows you to provide your own tcell.Screen object. For most
 // applications, this is not needed and you should be familiar with
 // tcell.Screen when using this function.
@@ -116,6 +191,9 @@ func (a *Application) Run() error {
 			return err
 		}
 	}
+	if a.enableMouse {
+		a.screen.EnableMouse()
+	}
 	// We catch panics to clean up because they mess up the terminal.
 	defer func() {
 		if p := recover(); p != nil {
@@ -164,6 +242,11 @@ func (a *Application) Run() error {
 			if err := screen.Init(); err != nil {
 				panic(err)
 			}
+			a.RLock()
+			if a.enableMouse {
+				screen.EnableMouse()
+			}
+			a.RUnlock()
 			a.draw()
 		}
 	}()
@@ -211,6 +294,15 @@ EventLoop:
 				}
 				screen.Clear()
 				a.draw()
+			case *tcell.EventMouse:
+				consumed, isMouseDownAction := a.fireMouseActions(event)
+				if consumed {
+					a.draw()
+				}
+				a.lastMouseButtons = event.Buttons()
+				if isMouseDownAction {
+					a.mouseDownX, a.mouseDownY = event.Position()
+				}
 			}
 		// If we have updates, now is the time to execute them.
 		case updater := <-a.updates:
@@ -222,6 +314,103 @@ EventLoop:
 	a.screen = nil
 	return nil
 }
+// fireMouseActions derives the mouse actions of a mouse event from the changes
+// since the last one and passes them to the root primitive, or to the primitive
+// capturing the mouse. It returns whether any of the actions were consumed and
+// whether any was a button being pressed.
+func (a *Application) fireMouseActions(event *tcell.EventMouse) (consumed, isMouseDownAction bool) {
+	a.RLock()
+	root := a.root
+	mouseCapture := a.mouseCapture
+	a.RUnlock()
+	// Follow-up actions of the same event go to the same primitive.
+	var targetPrimitive Primitive
+	fire := func(action MouseAction) {
+		switch action {
+		case MouseLeftDown, MouseMiddleDown, MouseRightDown:
+			isMouseDownAction = true
+		}
+		// Intercept the event.
+		if mouseCapture != nil {
+			event, action = mouseCapture(event, action)
+			if event == nil {
+				consumed = true
+				return // Don't forward event.
+			}
+		}
+		var primitive, capturingPrimitive Primitive
+		switch {
+		case a.mouseCapturingPrimitive != nil:
+			primitive = a.mouseCapturingPrimitive
+			targetPrimitive = a.mouseCapturingPrimitive
+		case targetPrimitive != nil:
+			primitive = targetPrimitive
+		default:
+			primitive = root
+		}
+		if primitive != nil {
+			if handler := primitive.MouseHandler(); handler != nil {
+				var wasConsumed bool
+				wasConsumed, capturingPrimitive = handler(action, event, func(p Primitive) {
+					a.SetFocus(p)
+				})
+				if wasConsumed {
+					consumed = true
+				}
+			}
+		}
+		a.mouseCapturingPrimitive = capturingPrimitive
+	}
+	x, y := event.Position()
+	buttons := event.Buttons()
+	clickMoved := x != a.mouseDownX || y != a.mouseDownY
+	buttonChanges := buttons ^ a.lastMouseButtons
+	if x != a.lastMouseX || y != a.lastMouseY {
+		fire(MouseMove)
+		a.lastMouseX, a.lastMouseY = x, y
+	}
+	for _, buttonEvent := range []struct {
+		button                  tcell.ButtonMask
+		down, up, click, dclick MouseAction
+	}{
+		{tcell.Button1, MouseLeftDown, MouseLeftUp, MouseLeftClick, MouseLeftDoubleClick},
+		{tcell.Button2, MouseMiddleDown, MouseMiddleUp, MouseMiddleClick, MouseMiddleDoubleClick},
+		{tcell.Button3, MouseRightDown, MouseRightUp, MouseRightClick, MouseRightDoubleClick},
+	} {
+		if buttonChanges&buttonEvent.button == 0 {
+			continue
+		}
+		if buttons&buttonEvent.button != 0 {
+			fire(buttonEvent.down)
+			continue
+		}
+		fire(buttonEvent.up)
+		if clickMoved {
+			continue // A drag is not a click.
+		}
+		if now := time.Now(); a.lastMouseClick.Add(DoubleClickInterval).Before(now) {
+			fire(buttonEvent.click)
+			a.lastMouseClick = now
+		} else {
+			fire(buttonEvent.dclick)
+			a.lastMouseClick = time.Time{} // A third click starts over.
+		}
+	}
+	for _, wheelEvent := range []struct {
+		button tcell.ButtonMask
+		action MouseAction
+	}{
+		{tcell.WheelUp, MouseScrollUp},
+		{tcell.WheelDown, MouseScrollDown},
+		{tcell.WheelLeft, MouseScrollLeft},
+		{tcell.WheelRight, MouseScrollRight},
+	} {
+		if buttons&wheelEvent.button != 0 {
+			fire(wheelEvent.action)
+		}
+	}
+	return consumed, isMouseDownAction
+}
 // Stop stops the application, causing Run() to return.
 func (a *Application) Stop() {
 	a.Lock()
@@ -19,8 +19,10 @@ func main() {
 		SetCurrentNode(root)
 
 	// A helper function which adds the files and directories of the given path
-	// to the given target node.
-	add := func(target *tview.TreeNode, path string) {
+	// to the given target node. Directories are loaded when they are first
+	// expanded.
+	var add func(target *tview.TreeNode, path string)
+	add = func(target *tview.TreeNode, path string) {
 		files, err := ioutil.ReadDir(path)
 		if err != nil {
 			panic(err)
@@ -30,7 +32,10 @@ func main() {
 				SetReference(filepath.Join(path, file.Name())).
 				SetSelectable(file.IsDir())
 			if file.IsDir() {
-				node.SetColor(tcell.ColorGreen)
+				node.SetColor(tcell.ColorGreen).
+					SetLoadFunc(func(node *tview.TreeNode) {
+						add(node, node.GetReference().(string))
+					})
 			}
 			target.AddChild(node)
 		}
@@ -39,21 +44,12 @@ func main() {
 	// Add the current directory to the root node.
 	add(root, rootDir)
 
-	// If a directory was selected, open it.
+	// If a directory was selected, open or close it.
 	tree.SetSelectedFunc(func(node *tview.TreeNode) {
-		reference := node.GetReference()
-		if reference == nil {
+		if node.GetReference() == nil {
 			return // Selecting the root node does nothing.
 		}
-		children := node.GetChildren()
-		if len(children) == 0 {
-			// Load and show files in this directory.
-			path := reference.(string)
-			add(node, path)
-		} else {
-			// Collapse if visible, expand if collapsed.
-			node.SetExpanded(!node.IsExpanded())
-		}
+		node.SetExpanded(!node.IsExpanded())
 	})
 
 	if err := tview.NewApplication().SetRoot(tree, true).Run(); err != nil {
@@ -7,19 +7,30 @@ The package implements the following widgets:
   - TextView: A scrollable window that display multi-colored text. Text may also
     be highlighted.
   - Table: A scrollable display of tabular data. Table cells, rows, or columns
-    may also be highlighted.
+    may also be highlighted, and rows can be sorted and filtered.
   - TreeView: A scrollable display for hierarchical data. Tree nodes can be
-    highlighted, collapsed, expanded, and more.
+    highlighted, collapsed, expanded, loaded when first expanded, and more.
   - List: A navigable text list with optional keyboard shortcuts.
-  - InputField: One-line input fields to enter text.
+  - InputField: One-line input fields to enter text, optionally checked by
+    validators (see Validator) which show errors inline.
   - DropDown: Drop-down selection fields.
   - Checkbox: Selectable checkbox for boolean values.
   - Button: Buttons which get activated when the user selects them.
   - Form: Forms composed of input fields, drop down selections, checkboxes, and
     buttons.
   - Modal: A centered window with a text message and one or more buttons.
+  - ProgressBar: A bar showing the progress of a task, or activity when the
+    amount of work is unknown, with colors changing at thresholds.
+  - Spinner: An animated indicator with a label showing that work is going on.
+  - StatusBar: A one-line bar with left, centered and right aligned text and
+    messages which disappear after a timeout.
+  - Gauge: A bar showing a value within a range, with the value printed over it.
+  - LogView: A scrolling, level-filtered display of cl logger entries which can
+    follow new entries as they are logged.
   - Grid: A grid based layout manager.
   - Flex: A Flexbox based layout manager.
+  - SplitPane: Two primitives split by a divider which can be moved with the
+    mouse or the keyboard.
   - Pages: A page based layout manager.
 The package also provides Application which is used to poll the event queue and
 draw widgets on screen.
@@ -132,6 +143,14 @@ All widgets also implement the Primitive interface. There is also the Focusable
 interface which is used to override functions in subclassing types.
 The tview package is based on https://git.parallelcoin.io/dev/9/pkg/util/tview. It uses types
 and constants from that package (e.g. colors and keyboard values).
-This package does not process mouse input (yet).
+Mouse Support
+Mouse events are disabled by default. Call Application.EnableMouse(true) to
+receive them. Clicking a primitive then gives it the focus and selects what was
+clicked in it, such as a list item, a table cell, or a tree node, and clicking a
+selected table cell or tree node again triggers its "selected" handler. The
+mouse wheel scrolls lists, tables, text views, tree views, and grids. Mouse
+events can be intercepted with Application.SetMouseCapture() and
+Box.SetMouseCapture(), and custom primitives handle them in their
+MouseHandler() function.
 */
 package tview
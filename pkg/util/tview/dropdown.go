@@ -299,24 +299,64 @@ func (d *DropDown) Draw(screen tcell.Screen) {
 		d.list.Draw(screen)
 	}
 }
-// InputHandler returns the handler for this primitive.
-func (d *DropDown) InputHandler() func(event *tcell.EventKey, setFocus func(p Primitive)) {
-	return d.WrapInputHandler(func(event *tcell.EventKey, setFocus func(p Primitive)) {
-		// A helper function which selects an item in the drop-down list based on
-		// the current prefix.
-		evalPrefix := func() {
+// evalPrefix selects an item in the drop-down list based on the current prefix.
+func (d *DropDown) evalPrefix() {
+	if len(d.prefix) > 0 {
+		for index, option := range d.options {
+			if strings.HasPrefix(strings.ToLower(option.Text), d.prefix) {
+				d.list.SetCurrentItem(index)
+				return
+			}
+		}
+		// Prefix does not match any item. Remove last rune.
+		r := []rune(d.prefix)
+		d.prefix = string(r[:len(r)-1])
+	}
+}
+// openList hands control over to the drop-down list until an option is
+// selected or the list is closed.
+func (d *DropDown) openList(setFocus func(Primitive)) {
+	d.open = true
+	optionBefore := d.currentOption
+	d.list.SetSelectedFunc(func(index int, mainText, secondaryText string, shortcut rune) {
+		// An option was selected. Close the list again.
+		d.closeList(setFocus)
+		d.currentOption = index
+		// Trigger "selected" event.
+		if d.selected != nil {
+			d.selected(d.options[d.currentOption].Text, d.currentOption)
+		}
+		if d.options[d.currentOption].Selected != nil {
+			d.options[d.currentOption].Selected()
+		}
+	}).SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyRune {
+			d.prefix += string(event.Rune())
+			d.evalPrefix()
+		} else if event.Key() == tcell.KeyBackspace || event.Key() == tcell.KeyBackspace2 {
 			if len(d.prefix) > 0 {
-				for index, option := range d.options {
-					if strings.HasPrefix(strings.ToLower(option.Text), d.prefix) {
-						d.list.SetCurrentItem(index)
-						return
-					}
-				}
-				// Prefix does not match any item. Remove last rune.
 				r := []rune(d.prefix)
 				d.prefix = string(r[:len(r)-1])
 			}
+			d.evalPrefix()
+		} else if event.Key() == tcell.KeyEscape {
+			d.currentOption = optionBefore
+			d.closeList(setFocus)
+		} else {
+			d.prefix = ""
 		}
+		return event
+	})
+	setFocus(d.list)
+}
+// closeList closes the drop-down list and returns the focus to the drop-down.
+func (d *DropDown) closeList(setFocus func(Primitive)) {
+	d.open = false
+	setFocus(d)
+}
+// InputHandler returns the handler for this primitive.
+func (d *DropDown) InputHandler() func(event *tcell.EventKey, setFocus func(p Primitive)) {
+	return d.WrapInputHandler(func(event *tcell.EventKey, setFocus func(p Primitive)) {
 		// Process key event.
 		switch key := event.Key(); key {
 		case tcell.KeyEnter, tcell.KeyRune, tcell.KeyDown:
@@ -324,43 +364,9 @@ func (d *DropDown) InputHandler() func(event *tcell.EventKey, setFocus func(p Pr
 			// If the first key was a letter already, it becomes part of the prefix.
 			if r := event.Rune(); key == tcell.KeyRune && r != ' ' {
 				d.prefix += string(r)
-				evalPrefix()
+				d.evalPrefix()
 			}
-			// Hand control over to the list.
-			d.open = true
-			optionBefore := d.currentOption
-			d.list.SetSelectedFunc(func(index int, mainText, secondaryText string, shortcut rune) {
-				// An option was selected. Close the list again.
-				d.open = false
-				setFocus(d)
-				d.currentOption = index
-				// Trigger "selected" event.
-				if d.selected != nil {
-					d.selected(d.options[d.currentOption].Text, d.currentOption)
-				}
-				if d.options[d.currentOption].Selected != nil {
-					d.options[d.currentOption].Selected()
-				}
-			}).SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-				if event.Key() == tcell.KeyRune {
-					d.prefix += string(event.Rune())
-					evalPrefix()
-				} else if event.Key() == tcell.KeyBackspace || event.Key() == tcell.KeyBackspace2 {
-					if len(d.prefix) > 0 {
-						r := []rune(d.prefix)
-						d.prefix = string(r[:len(r)-1])
-					}
-					evalPrefix()
-				} else if event.Key() == tcell.KeyEscape {
-					d.open = false
-					d.currentOption = optionBefore
-					setFocus(d)
-				} else {
-					d.prefix = ""
-				}
-				return event
-			})
-			setFocus(d.list)
+			d.openList(setFocus)
 		case tcell.KeyEscape, tcell.KeyTab, tcell.KeyBacktab:
 			if d.done != nil {
 				d.done(key)
@@ -371,6 +377,39 @@ func (d *DropDown) InputHandler() func(event *tcell.EventKey, setFocus func(p Pr
 		}
 	})
 }
+// MouseHandler returns the mouse handler for this primitive.
+func (d *DropDown) MouseHandler() func(action MouseAction, event *tcell.EventMouse, setFocus func(p Primitive)) (consumed bool, capture Primitive) {
+	return d.WrapMouseHandler(func(action MouseAction, event *tcell.EventMouse, setFocus func(p Primitive)) (consumed bool, capture Primitive) {
+		x, y := event.Position()
+		_, rectY, _, _ := d.GetInnerRect()
+		inRect := d.InRect(x, y) && y == rectY
+		if !d.open {
+			if !inRect {
+				return false, nil
+			}
+			// A click opens the list, which then captures the mouse as it
+			// may be drawn outside of the drop-down.
+			if action == MouseLeftClick {
+				setFocus(d)
+				d.prefix = ""
+				d.openList(setFocus)
+				return true, d
+			}
+			return action == MouseLeftDown, nil
+		}
+		// The list is open. Clicks on it select an option, and clicks anywhere
+		// else close it.
+		if d.list.InRect(x, y) {
+			passMouseEvent(d.list, action, event, setFocus)
+		} else if action == MouseLeftClick {
+			d.closeList(setFocus)
+		}
+		if d.open {
+			capture = d
+		}
+		return true, capture
+	})
+}
 // Focus is called by the application when the primitive receives focus.
 func (d *DropDown) Focus(delegate func(p Primitive)) {
 	d.Box.Focus(delegate)
@@ -1,5 +1,7 @@
 package tview
 import (
+	"fmt"
+
 	"git.parallelcoin.io/dev/9/pkg/util/tcell"
 )
 // DefaultFormFieldWidth is the default field screen width of form elements
@@ -272,6 +274,24 @@ func (f *Form) SetCancelFunc(callback func()) *Form {
 	f.cancel = callback
 	return f
 }
+// Validate validates all form items which support it (such as InputField, see
+// InputField.AddValidator) and returns the first error found, prefixed with the
+// item's label. The invalid item becomes the focused element, so it receives
+// focus the next time the form is focused. If all items are valid, nil is
+// returned.
+func (f *Form) Validate() error {
+	for index, item := range f.items {
+		validator, ok := item.(interface{ Validate() error })
+		if !ok {
+			continue
+		}
+		if err := validator.Validate(); err != nil {
+			f.focusedElement = index
+			return fmt.Errorf("%s %v", item.GetLabel(), err)
+		}
+	}
+	return nil
+}
 // Draw draws this primitive onto the screen.
 func (f *Form) Draw(screen tcell.Screen) {
 	f.Box.Draw(screen)
@@ -493,3 +513,36 @@ func (f *Form) HasFocus() bool {
 	}
 	return false
 }
+// MouseHandler returns the mouse handler for this primitive.
+func (f *Form) MouseHandler() func(action MouseAction, event *tcell.EventMouse, setFocus func(p Primitive)) (consumed bool, capture Primitive) {
+	return f.WrapMouseHandler(func(action MouseAction, event *tcell.EventMouse, setFocus func(p Primitive)) (consumed bool, capture Primitive) {
+		if !f.InRect(event.Position()) {
+			return false, nil
+		}
+		// Pass mouse events along to the first item or button that takes it.
+		// When it takes the focus, the form moves its own focus there, so the
+		// Tab key continues from the element that was clicked.
+		for index := 0; index < len(f.items)+len(f.buttons); index++ {
+			var element Primitive
+			if index < len(f.items) {
+				element = f.items[index]
+			} else {
+				element = f.buttons[index-len(f.items)]
+			}
+			consumed, capture = passMouseEvent(element, action, event, setFocus)
+			if !consumed {
+				continue
+			}
+			if element.GetFocusable().HasFocus() && f.focusedElement != index {
+				f.focusedElement = index
+				f.Focus(setFocus)
+			}
+			return
+		}
+		// Clicks on the rest of the form are consumed as well.
+		if action == MouseLeftClick {
+			consumed = true
+		}
+		return
+	})
+}
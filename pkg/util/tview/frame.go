@@ -134,3 +134,13 @@ func (f *Frame) HasFocus() bool {
 	}
 	return false
 }
+// MouseHandler returns the mouse handler for this primitive.
+func (f *Frame) MouseHandler() func(action MouseAction, event *tcell.EventMouse, setFocus func(p Primitive)) (consumed bool, capture Primitive) {
+	return f.WrapMouseHandler(func(action MouseAction, event *tcell.EventMouse, setFocus func(p Primitive)) (consumed bool, capture Primitive) {
+		if !f.InRect(event.Position()) {
+			return false, nil
+		}
+		// Pass mouse events on to the contained primitive.
+		return passMouseEvent(f.primitive, action, event, setFocus)
+	})
+}
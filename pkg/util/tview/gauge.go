@@ -0,0 +1,187 @@
+package tview
+import (
+	"fmt"
+	"git.parallelcoin.io/dev/9/pkg/util/tcell"
+)
+// Gauge displays a value within a range as a horizontal bar filled in
+// proportion to it, with the value printed over the bar, optionally preceded
+// by a label. It suits levels such as the height of the chain against the best
+// known height, or the use of a resource against its limit.
+//
+// The color of the bar can change as the value reaches thresholds (see
+// AddThreshold()), for example to show a level in green, yellow or red.
+type Gauge struct {
+	*Box
+	// The text to be displayed before the bar.
+	label string
+	// The screen width of the label area. A value of 0 means use the width of
+	// the label text.
+	labelWidth int
+	// The label color.
+	labelColor tcell.Color
+	// The value and the range it is shown within.
+	value, min, max float64
+	// The format the value is printed over the bar with, which is passed the
+	// value, the maximum and the percentage, or a function returning the text
+	// to print.
+	format     string
+	formatFunc func(value, min, max float64) string
+	// The color of the filled part of the bar when no threshold is reached,
+	// and of the empty part.
+	filledColor, emptyColor tcell.Color
+	// The color of the text over the bar.
+	textColor tcell.Color
+	// The colors of the filled part of the bar from each threshold on.
+	thresholds colorThresholds
+}
+// NewGauge returns a new gauge with a range of 0 to 100, showing its value as
+// a percentage.
+func NewGauge() *Gauge {
+	return &Gauge{
+		Box:         NewBox(),
+		labelColor:  Styles.SecondaryTextColor,
+		max:         100,
+		format:      "%[3]d%%",
+		filledColor: Styles.ContrastBackgroundColor,
+		emptyColor:  Styles.MoreContrastBackgroundColor,
+		textColor:   Styles.PrimaryTextColor,
+	}
+}
+// SetLabel sets the text to be displayed before the bar.
+func (g *Gauge) SetLabel(label string) *Gauge {
+	g.label = label
+	return g
+}
+// GetLabel returns the text to be displayed before the bar.
+func (g *Gauge) GetLabel() string {
+	return g.label
+}
+// SetLabelWidth sets the screen width of the label. A value of 0 will cause the
+// primitive to use the width of the label string.
+func (g *Gauge) SetLabelWidth(width int) *Gauge {
+	g.labelWidth = width
+	return g
+}
+// SetLabelColor sets the color of the label.
+func (g *Gauge) SetLabelColor(color tcell.Color) *Gauge {
+	g.labelColor = color
+	return g
+}
+// SetRange sets the values shown as an empty and a full bar.
+func (g *Gauge) SetRange(min, max float64) *Gauge {
+	g.min, g.max = min, max
+	return g
+}
+// GetRange returns the values shown as an empty and a full bar.
+func (g *Gauge) GetRange() (min, max float64) {
+	return g.min, g.max
+}
+// SetValue sets the value shown. It is limited to the range of the gauge when
+// the bar is drawn, but printed as it is.
+func (g *Gauge) SetValue(value float64) *Gauge {
+	g.value = value
+	return g
+}
+// GetValue returns the value shown.
+func (g *Gauge) GetValue() float64 {
+	return g.value
+}
+// GetFraction returns the share of the range below the value, from 0 to 1.
+func (g *Gauge) GetFraction() float64 {
+	if g.max <= g.min {
+		return 0
+	}
+	return clampFraction((g.value - g.min) / (g.max - g.min))
+}
+// SetFormat sets the format the value is printed over the bar with, using the
+// verbs of fmt. It is passed the value and the maximum as float64 and the
+// percentage as int, which can be picked by their index, for example
+// "%.0f of %.0f" or "%[3]d%%", the default. An empty format prints nothing.
+func (g *Gauge) SetFormat(format string) *Gauge {
+	g.format = format
+	g.formatFunc = nil
+	return g
+}
+// SetFormatFunc sets a function returning the text printed over the bar, in
+// place of the format.
+func (g *Gauge) SetFormatFunc(handler func(value, min, max float64) string) *Gauge {
+	g.formatFunc = handler
+	return g
+}
+// SetColors sets the color of the filled part of the bar, used when no
+// threshold is reached, of the empty part, and of the text over the bar.
+func (g *Gauge) SetColors(filled, empty, text tcell.Color) *Gauge {
+	g.filledColor, g.emptyColor, g.textColor = filled, empty, text
+	return g
+}
+// AddThreshold sets the color of the filled part of the bar once the value
+// reaches the passed one. The color of the highest threshold reached is used.
+func (g *Gauge) AddThreshold(value float64, color tcell.Color) *Gauge {
+	g.thresholds = g.thresholds.add(value, color)
+	return g
+}
+// ClearThresholds removes all thresholds.
+func (g *Gauge) ClearThresholds() *Gauge {
+	g.thresholds = nil
+	return g
+}
+// text returns the text printed over the bar.
+func (g *Gauge) text() string {
+	if g.formatFunc != nil {
+		return g.formatFunc(g.value, g.min, g.max)
+	}
+	if g.format == "" {
+		return ""
+	}
+	return fmt.Sprintf(g.format, g.value, g.max, int(g.GetFraction()*100))
+}
+// Draw draws this primitive onto the screen.
+func (g *Gauge) Draw(screen tcell.Screen) {
+	g.Box.Draw(screen)
+	x, y, width, height := g.GetInnerRect()
+	rightLimit := x + width
+	if height < 1 || rightLimit <= x {
+		return
+	}
+	// Draw label.
+	if g.labelWidth > 0 {
+		labelWidth := g.labelWidth
+		if labelWidth > rightLimit-x {
+			labelWidth = rightLimit - x
+		}
+		Print(screen, g.label, x, y, labelWidth, AlignLeft, g.labelColor)
+		x += labelWidth
+	} else {
+		_, drawnWidth := Print(screen, g.label, x, y, rightLimit-x, AlignLeft, g.labelColor)
+		x += drawnWidth
+	}
+	barWidth := rightLimit - x
+	if barWidth <= 0 {
+		return
+	}
+	// Draw the bar, then the text over it in the colors of the part of the bar
+	// below each rune.
+	fraction := g.GetFraction()
+	filled := int(fraction * float64(barWidth))
+	filledColor := g.thresholds.color(g.value, g.filledColor)
+	for i := 0; i < barWidth; i++ {
+		color := g.emptyColor
+		if i < filled {
+			color = filledColor
+		}
+		screen.SetContent(x+i, y, ' ', nil, tcell.StyleDefault.Background(color))
+	}
+	text := truncateWidth(g.text(), barWidth)
+	start := (barWidth - stringWidth(text)) / 2
+	iterateString(text, func(main rune, comb []rune, textPos, textWidth, screenPos, screenWidth int) bool {
+		if start+screenPos+screenWidth > barWidth {
+			return true
+		}
+		color := g.emptyColor
+		if start+screenPos < filled {
+			color = filledColor
+		}
+		screen.SetContent(x+start+screenPos, y, main, comb, tcell.StyleDefault.Background(color).Foreground(g.textColor))
+		return false
+	})
+}
@@ -589,3 +589,37 @@ func (g *Grid) Draw(screen tcell.Screen) {
 		}
 	}
 }
+// MouseHandler returns the mouse handler for this primitive.
+func (g *Grid) MouseHandler() func(action MouseAction, event *tcell.EventMouse, setFocus func(p Primitive)) (consumed bool, capture Primitive) {
+	return g.WrapMouseHandler(func(action MouseAction, event *tcell.EventMouse, setFocus func(p Primitive)) (consumed bool, capture Primitive) {
+		if !g.InRect(event.Position()) {
+			return false, nil
+		}
+		// Pass mouse events along to the first child item that takes it.
+		for _, item := range g.items {
+			if !item.visible || item.Item == nil {
+				continue
+			}
+			consumed, capture = passMouseEvent(item.Item, action, event, setFocus)
+			if consumed {
+				return
+			}
+		}
+		// Otherwise the wheel scrolls the grid.
+		switch action {
+		case MouseScrollUp:
+			g.rowOffset--
+			consumed = true
+		case MouseScrollDown:
+			g.rowOffset++
+			consumed = true
+		case MouseScrollLeft:
+			g.columnOffset--
+			consumed = true
+		case MouseScrollRight:
+			g.columnOffset++
+			consumed = true
+		}
+		return
+	})
+}
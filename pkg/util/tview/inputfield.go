@@ -3,7 +3,6 @@ import (
 	"math"
 	"regexp"
 	"strings"
-	"unicode/utf8"
 	"git.parallelcoin.io/dev/9/pkg/util/tcell"
 )
 // InputField is a one-line box (three lines if there is a title) where the
@@ -25,6 +24,10 @@ import (
 //   - Ctrl-W: Delete the last word before the cursor.
 //   - Ctrl-U: Delete the entire line.
 //
+// Validators added with AddValidator() check the text as it is typed. Invalid
+// text is drawn in the error color, followed by the error message, and Enter
+// is ignored until the text is valid.
+//
 // See https://git.parallelcoin.io/dev/9/pkg/util/tview/wiki/InputField for an example.
 type InputField struct {
 	*Box
@@ -55,8 +58,16 @@ type InputField struct {
 	cursorPos int
 	// The number of bytes of the text string skipped ahead while drawing.
 	offset int
+	// The x-coordinate of the input area the last time the field was drawn.
+	fieldX int
 	// An optional function which may reject the last character that was entered.
 	accept func(text string, ch rune) bool
+	// Validators run whenever the text changes. The first error is kept in
+	// validationError and displayed after the input area.
+	validators      []Validator
+	validationError error
+	// The color of the validation error message and of invalid text.
+	errorColor tcell.Color
 	// An optional function which is called when the input has changed.
 	changed func(text string)
 	// An optional function which is called when the user indicated that they
@@ -75,12 +86,14 @@ func NewInputField() *InputField {
 		fieldBackgroundColor: Styles.ContrastBackgroundColor,
 		fieldTextColor:       Styles.PrimaryTextColor,
 		placeholderTextColor: Styles.ContrastSecondaryTextColor,
+		errorColor:           tcell.ColorRed,
 	}
 }
 // SetText sets the current text of the input field.
 func (i *InputField) SetText(text string) *InputField {
 	i.text = text
 	i.cursorPos = len(text)
+	i.Validate()
 	if i.changed != nil {
 		i.changed(text)
 	}
@@ -130,6 +143,45 @@ func (i *InputField) SetPlaceholderTextColor(color tcell.Color) *InputField {
 	i.placeholderTextColor = color
 	return i
 }
+// SetErrorColor sets the color of the validation error message and of the
+// entered text while it is invalid.
+func (i *InputField) SetErrorColor(color tcell.Color) *InputField {
+	i.errorColor = color
+	return i
+}
+// AddValidator adds a function which checks the entered text each time it
+// changes. While any validator returns an error, the text is drawn in the error
+// color, the error message is shown after the input area, and the Enter key
+// does not finish editing. Tab, Backtab and Escape still leave the field.
+func (i *InputField) AddValidator(validator Validator) *InputField {
+	i.validators = append(i.validators, validator)
+	i.Validate()
+	return i
+}
+// ClearValidators removes all validators and any pending validation error.
+func (i *InputField) ClearValidators() *InputField {
+	i.validators = nil
+	i.validationError = nil
+	return i
+}
+// Validate runs the field's validators against the current text and returns
+// the first error, or nil if the text is valid. The result is also remembered
+// for display and can be retrieved with GetValidationError.
+func (i *InputField) Validate() error {
+	i.validationError = nil
+	for _, validator := range i.validators {
+		if err := validator(i.text); err != nil {
+			i.validationError = err
+			break
+		}
+	}
+	return i.validationError
+}
+// GetValidationError returns the error of the last validation, or nil if the
+// text was valid.
+func (i *InputField) GetValidationError() error {
+	return i.validationError
+}
 // SetFormAttributes sets attributes shared by all form items.
 func (i *InputField) SetFormAttributes(labelWidth int, labelColor, bgColor, fieldTextColor, fieldBgColor tcell.Color) FormItem {
 	i.labelWidth = labelWidth
@@ -209,6 +261,7 @@ func (i *InputField) Draw(screen tcell.Screen) {
 		x += drawnWidth
 	}
 	// Draw input area.
+	i.fieldX = x
 	fieldWidth := i.fieldWidth
 	if fieldWidth == 0 {
 		fieldWidth = math.MaxInt32
@@ -216,6 +269,20 @@ func (i *InputField) Draw(screen tcell.Screen) {
 	if rightLimit-x < fieldWidth {
 		fieldWidth = rightLimit - x
 	}
+	// A flexible input area makes room for the validation error message as
+	// long as at least half of it remains.
+	var message string
+	if i.validationError != nil {
+		message = " " + i.validationError.Error()
+		if messageWidth := stringWidth(message); i.fieldWidth == 0 && messageWidth <= fieldWidth/2 {
+			fieldWidth -= messageWidth
+		}
+	}
+	textColor := i.fieldTextColor
+	if message != "" {
+		textColor = i.errorColor
+		Print(screen, Escape(message), x+fieldWidth, y, rightLimit-x-fieldWidth, AlignLeft, i.errorColor)
+	}
 	fieldStyle := tcell.StyleDefault.Background(i.fieldBackgroundColor)
 	for index := 0; index < fieldWidth; index++ {
 		screen.SetContent(x+index, y, ' ', nil, fieldStyle)
@@ -229,15 +296,14 @@ func (i *InputField) Draw(screen tcell.Screen) {
 		i.offset = 0
 	} else {
 		// Draw entered text.
-		if i.maskCharacter > 0 {
-			text = strings.Repeat(string(i.maskCharacter), utf8.RuneCountInString(i.text))
-		}
+		var cursorPos int
+		text, cursorPos = i.maskedText(i.cursorPos)
 		if fieldWidth >= stringWidth(text) {
 			// We have enough space for the full text.
-			Print(screen, Escape(text), x, y, fieldWidth, AlignLeft, i.fieldTextColor)
+			Print(screen, Escape(text), x, y, fieldWidth, AlignLeft, textColor)
 			i.offset = 0
 			iterateString(text, func(main rune, comb []rune, textPos, textWidth, screenPos, screenWidth int) bool {
-				if textPos >= i.cursorPos {
+				if textPos >= cursorPos {
 					return true
 				}
 				cursorScreenPos += screenWidth
@@ -245,16 +311,16 @@ func (i *InputField) Draw(screen tcell.Screen) {
 			})
 		} else {
 			// The text doesn't fit. Where is the cursor?
-			if i.cursorPos < 0 {
-				i.cursorPos = 0
-			} else if i.cursorPos > len(text) {
-				i.cursorPos = len(text)
+			if cursorPos < 0 {
+				cursorPos = 0
+			} else if cursorPos > len(text) {
+				cursorPos = len(text)
 			}
 			// Shift the text so the cursor is inside the field.
 			var shiftLeft int
-			if i.offset > i.cursorPos {
-				i.offset = i.cursorPos
-			} else if subWidth := stringWidth(text[i.offset:i.cursorPos]); subWidth > fieldWidth-1 {
+			if i.offset > cursorPos {
+				i.offset = cursorPos
+			} else if subWidth := stringWidth(text[i.offset:cursorPos]); subWidth > fieldWidth-1 {
 				shiftLeft = subWidth - fieldWidth + 1
 			}
 			currentOffset := i.offset
@@ -264,7 +330,7 @@ func (i *InputField) Draw(screen tcell.Screen) {
 						i.offset = textPos + textWidth
 						shiftLeft -= screenWidth
 					} else {
-						if textPos+textWidth > i.cursorPos {
+						if textPos+textWidth > cursorPos {
 							return true
 						}
 						cursorScreenPos += screenWidth
@@ -272,7 +338,7 @@ func (i *InputField) Draw(screen tcell.Screen) {
 				}
 				return false
 			})
-			Print(screen, Escape(text[i.offset:]), x, y, fieldWidth, AlignLeft, i.fieldTextColor)
+			Print(screen, Escape(text[i.offset:]), x, y, fieldWidth, AlignLeft, textColor)
 		}
 	}
 	// Set cursor.
@@ -286,8 +352,11 @@ func (i *InputField) InputHandler() func(event *tcell.EventKey, setFocus func(p
 		// Trigger changed events.
 		currentText := i.text
 		defer func() {
-			if i.text != currentText && i.changed != nil {
-				i.changed(i.text)
+			if i.text != currentText {
+				i.Validate()
+				if i.changed != nil {
+					i.changed(i.text)
+				}
 			}
 		}()
 		// Movement functions.
@@ -388,6 +457,9 @@ func (i *InputField) InputHandler() func(event *tcell.EventKey, setFocus func(p
 		case tcell.KeyEnd, tcell.KeyCtrlE:
 			end()
 		case tcell.KeyEnter, tcell.KeyTab, tcell.KeyBacktab, tcell.KeyEscape: // We're done.
+			if key == tcell.KeyEnter && i.Validate() != nil {
+				return
+			}
 			if i.done != nil {
 				i.done(key)
 			}
@@ -397,3 +469,73 @@ func (i *InputField) InputHandler() func(event *tcell.EventKey, setFocus func(p
 		}
 	})
 }
+// MouseHandler returns the mouse handler for this primitive.
+func (i *InputField) MouseHandler() func(action MouseAction, event *tcell.EventMouse, setFocus func(p Primitive)) (consumed bool, capture Primitive) {
+	return i.WrapMouseHandler(func(action MouseAction, event *tcell.EventMouse, setFocus func(p Primitive)) (consumed bool, capture Primitive) {
+		x, y := event.Position()
+		_, rectY, _, _ := i.GetInnerRect()
+		if !i.InRect(x, y) || y != rectY {
+			return false, nil
+		}
+		// Process mouse event.
+		if action == MouseLeftClick {
+			setFocus(i)
+			i.moveCursorTo(x - i.fieldX)
+			consumed = true
+		}
+		return
+	})
+}
+// moveCursorTo moves the cursor to the character drawn at the given column of
+// the input area the last time the field was drawn, or to the end of the text
+// if the column is past it.
+func (i *InputField) moveCursorTo(column int) {
+	if column < 0 {
+		return
+	}
+	text, _ := i.maskedText(0)
+	offset := i.offset
+	if offset > len(text) {
+		offset = len(text)
+	}
+	pos := len(text)
+	iterateString(text[offset:], func(main rune, comb []rune, textPos, textWidth, screenPos, screenWidth int) bool {
+		if column < screenPos+screenWidth {
+			pos = offset + textPos
+			return true
+		}
+		return false
+	})
+	if i.maskCharacter > 0 {
+		// Find the same character in the unmasked text.
+		characters := pos / len(string(i.maskCharacter))
+		pos = len(i.text)
+		iterateString(i.text, func(main rune, comb []rune, textPos, textWidth, screenPos, screenWidth int) bool {
+			if characters == 0 {
+				pos = textPos
+				return true
+			}
+			characters--
+			return false
+		})
+	}
+	i.cursorPos = pos
+}
+// maskedText returns the text as it is drawn, with each character (grapheme
+// cluster) replaced by the mask character if one is set, and the position in
+// it of the given position in the field's text.
+func (i *InputField) maskedText(pos int) (text string, maskedPos int) {
+	if i.maskCharacter <= 0 {
+		return i.text, pos
+	}
+	mask := string(i.maskCharacter)
+	var b strings.Builder
+	iterateString(i.text, func(main rune, comb []rune, textPos, textWidth, screenPos, screenWidth int) bool {
+		if textPos < pos {
+			maskedPos += len(mask)
+		}
+		b.WriteString(mask)
+		return false
+	})
+	return b.String(), maskedPos
+}
@@ -0,0 +1,342 @@
+package tview
+import (
+	"sync"
+	"git.parallelcoin.io/dev/9/pkg/util/cl"
+	"git.parallelcoin.io/dev/9/pkg/util/tcell"
+)
+// logTags are the short level names shown in front of each log entry.
+var logTags = map[string]string{
+	"fatal": "FTL",
+	"error": "ERR",
+	"warn":  "WRN",
+	"info":  "INF",
+	"debug": "DBG",
+	"trace": "TRC",
+}
+// LogView is a scrolling display of entries of the cl logger. Each entry is
+// shown on one line with its time and a level tag colored by level. Entries
+// below a minimum level can be hidden.
+//
+// In follow mode, which is the default, the view keeps the newest entry at the
+// bottom. Scrolling up leaves follow mode, scrolling back to the bottom or
+// pressing End returns to it.
+//
+// Use Subscribe() to fill the view from a cl.Ring such as cl.History. The
+// following keys are handled:
+//
+//   - Up, k: Scroll up one line.
+//   - Down, j: Scroll down one line.
+//   - Page up, Page down: Scroll one page.
+//   - Home, g: Jump to the oldest entry.
+//   - End, G: Jump to the newest entry and follow new entries.
+//   - f: Toggle follow mode.
+//   - +, -: Show more or fewer levels.
+type LogView struct {
+	*Box
+	sync.Mutex
+	// The stored entries, oldest first.
+	entries []cl.Entry
+	// The maximum number of entries stored.
+	maxEntries int
+	// Entries with a level above this one (less severe) are hidden.
+	level int
+	// Whether the newest entry is kept in view.
+	follow bool
+	// The index of the first shown line among the entries passing the filter.
+	lineOffset int
+	// The height of the view the last time it was drawn.
+	pageSize int
+	// Whether to show the time of each entry.
+	showTime bool
+	// The layout of the time of each entry.
+	timeFormat string
+	// The colors of the time, the text and the tag of each level.
+	timeColor   tcell.Color
+	textColor   tcell.Color
+	levelColors map[string]tcell.Color
+	// Ends the subscription to a ring, if any.
+	unsubscribe func()
+}
+// NewLogView returns a new log view showing entries of all levels in follow
+// mode.
+func NewLogView() *LogView {
+	return &LogView{
+		Box:        NewBox(),
+		maxEntries: cl.DefaultHistory,
+		level:      cl.Levels["trace"],
+		follow:     true,
+		showTime:   true,
+		timeFormat: "15:04:05",
+		timeColor:  Styles.TertiaryTextColor,
+		textColor:  Styles.PrimaryTextColor,
+		levelColors: map[string]tcell.Color{
+			"fatal": tcell.ColorRed,
+			"error": tcell.ColorYellow,
+			"warn":  tcell.ColorGreen,
+			"info":  tcell.ColorAqua,
+			"debug": tcell.ColorBlue,
+			"trace": tcell.ColorFuchsia,
+		},
+	}
+}
+// SetMaxEntries sets the number of entries kept. The oldest entries are
+// discarded when more arrive.
+func (l *LogView) SetMaxEntries(max int) *LogView {
+	l.Lock()
+	defer l.Unlock()
+	if max < 1 {
+		max = 1
+	}
+	l.maxEntries = max
+	l.trim()
+	return l
+}
+// SetLevel sets the least severe level shown by its name in cl.Levels, for
+// example "info" to hide debug and trace entries. Unknown names are ignored.
+func (l *LogView) SetLevel(level string) *LogView {
+	l.Lock()
+	defer l.Unlock()
+	if i, ok := cl.Levels[level]; ok && i > cl.Levels["off"] {
+		l.level = i
+	}
+	return l
+}
+// GetLevel returns the name of the least severe level shown.
+func (l *LogView) GetLevel() string {
+	l.Lock()
+	defer l.Unlock()
+	return cl.LevelName(l.level)
+}
+// SetFollow turns follow mode on or off.
+func (l *LogView) SetFollow(follow bool) *LogView {
+	l.Lock()
+	defer l.Unlock()
+	l.follow = follow
+	return l
+}
+// IsFollowing returns whether the view is in follow mode.
+func (l *LogView) IsFollowing() bool {
+	l.Lock()
+	defer l.Unlock()
+	return l.follow
+}
+// SetShowTime sets whether the time of each entry is shown, and its layout
+// (see time.Time.Format).
+func (l *LogView) SetShowTime(show bool, format string) *LogView {
+	l.Lock()
+	defer l.Unlock()
+	l.showTime = show
+	l.timeFormat = format
+	return l
+}
+// SetTimeColor sets the color of the time of each entry.
+func (l *LogView) SetTimeColor(color tcell.Color) *LogView {
+	l.timeColor = color
+	return l
+}
+// SetTextColor sets the color of the log messages.
+func (l *LogView) SetTextColor(color tcell.Color) *LogView {
+	l.textColor = color
+	return l
+}
+// SetLevelColor sets the color of the tag of a level, given by its name in
+// cl.Levels.
+func (l *LogView) SetLevelColor(level string, color tcell.Color) *LogView {
+	l.Lock()
+	defer l.Unlock()
+	l.levelColors[level] = color
+	return l
+}
+// AddEntry appends an entry to the view. It is safe to call from any
+// goroutine, but the view is only redrawn when the application draws.
+func (l *LogView) AddEntry(entry cl.Entry) *LogView {
+	l.Lock()
+	defer l.Unlock()
+	l.entries = append(l.entries, entry)
+	l.trim()
+	return l
+}
+// Clear removes all entries.
+func (l *LogView) Clear() *LogView {
+	l.Lock()
+	defer l.Unlock()
+	l.entries = nil
+	l.lineOffset = 0
+	return l
+}
+// Subscribe fills the view with the entries stored in the ring and then adds
+// each new entry as it is logged, redrawing the application. A previous
+// subscription is ended.
+func (l *LogView) Subscribe(app *Application, ring *cl.Ring) *LogView {
+	l.Unsubscribe()
+	recent, entries, cancel := ring.Subscribe(l.maxEntries)
+	l.Lock()
+	l.entries = append(l.entries, recent...)
+	l.trim()
+	l.unsubscribe = cancel
+	l.Unlock()
+	go func() {
+		for entry := range entries {
+			entry := entry
+			app.QueueUpdateDraw(func() {
+				l.AddEntry(entry)
+			})
+		}
+	}()
+	return l
+}
+// Unsubscribe ends the subscription started by Subscribe(), if any. The
+// entries shown so far are kept.
+func (l *LogView) Unsubscribe() *LogView {
+	l.Lock()
+	cancel := l.unsubscribe
+	l.unsubscribe = nil
+	l.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+	return l
+}
+// trim discards the oldest entries beyond the maximum, keeping the shown lines
+// in place when not following.
+func (l *LogView) trim() {
+	if excess := len(l.entries) - l.maxEntries; excess > 0 {
+		l.entries = append([]cl.Entry(nil), l.entries[excess:]...)
+		l.lineOffset -= excess
+		if l.lineOffset < 0 {
+			l.lineOffset = 0
+		}
+	}
+}
+// visible returns the entries passing the level filter.
+func (l *LogView) visible() (out []cl.Entry) {
+	for _, entry := range l.entries {
+		if entry.Level > cl.Levels["off"] && entry.Level <= l.level {
+			out = append(out, entry)
+		}
+	}
+	return
+}
+// clampOffset keeps the line offset within the given number of lines, at the
+// bottom if following, and leaves follow mode when scrolled away from the
+// bottom or enters it when scrolled to the bottom.
+func (l *LogView) clampOffset(lines int, scrolled bool) {
+	bottom := lines - l.pageSize
+	if bottom < 0 {
+		bottom = 0
+	}
+	if l.follow && !scrolled {
+		l.lineOffset = bottom
+	}
+	if l.lineOffset > bottom {
+		l.lineOffset = bottom
+	}
+	if l.lineOffset < 0 {
+		l.lineOffset = 0
+	}
+	if scrolled {
+		l.follow = l.lineOffset == bottom
+	}
+}
+// Draw draws this primitive onto the screen.
+func (l *LogView) Draw(screen tcell.Screen) {
+	l.Box.Draw(screen)
+	l.Lock()
+	defer l.Unlock()
+	x, y, width, height := l.GetInnerRect()
+	l.pageSize = height
+	lines := l.visible()
+	l.clampOffset(len(lines), false)
+	for row := 0; row < height && l.lineOffset+row < len(lines); row++ {
+		entry := lines[l.lineOffset+row]
+		posX, maxWidth := x, width
+		show := func(text string, color tcell.Color) {
+			_, drawn := Print(screen, Escape(text), posX, y+row, maxWidth, AlignLeft, color)
+			posX += drawn + 1
+			maxWidth -= drawn + 1
+		}
+		if l.showTime {
+			show(entry.Time.Local().Format(l.timeFormat), l.timeColor)
+		}
+		name := entry.LevelName()
+		show(logTags[name], l.levelColors[name])
+		if maxWidth > 0 {
+			show(entry.Text, l.textColor)
+		}
+	}
+}
+// scroll moves the shown lines by the given number of lines, stopping at the
+// oldest and the newest entry.
+func (l *LogView) scroll(lines int) {
+	l.Lock()
+	defer l.Unlock()
+	l.lineOffset += lines
+	l.clampOffset(len(l.visible()), true)
+}
+// changeLevel shows more (positive) or fewer (negative) levels.
+func (l *LogView) changeLevel(delta int) {
+	l.Lock()
+	defer l.Unlock()
+	level := l.level + delta
+	if level > cl.Levels["off"] && level <= cl.Levels["trace"] {
+		l.level = level
+	}
+}
+// InputHandler returns the handler for this primitive.
+func (l *LogView) InputHandler() func(event *tcell.EventKey, setFocus func(p Primitive)) {
+	return l.WrapInputHandler(func(event *tcell.EventKey, setFocus func(p Primitive)) {
+		const far = 1 << 30
+		switch key := event.Key(); key {
+		case tcell.KeyUp:
+			l.scroll(-1)
+		case tcell.KeyDown:
+			l.scroll(1)
+		case tcell.KeyPgUp:
+			l.scroll(-l.pageSize)
+		case tcell.KeyPgDn:
+			l.scroll(l.pageSize)
+		case tcell.KeyHome:
+			l.scroll(-far)
+		case tcell.KeyEnd:
+			l.scroll(far)
+		case tcell.KeyRune:
+			switch event.Rune() {
+			case 'k':
+				l.scroll(-1)
+			case 'j':
+				l.scroll(1)
+			case 'g':
+				l.scroll(-far)
+			case 'G':
+				l.scroll(far)
+			case 'f':
+				l.SetFollow(!l.IsFollowing())
+			case '+':
+				l.changeLevel(1)
+			case '-':
+				l.changeLevel(-1)
+			}
+		}
+	})
+}
+// MouseHandler returns the mouse handler for this primitive.
+func (l *LogView) MouseHandler() func(action MouseAction, event *tcell.EventMouse, setFocus func(p Primitive)) (consumed bool, capture Primitive) {
+	return l.WrapMouseHandler(func(action MouseAction, event *tcell.EventMouse, setFocus func(p Primitive)) (consumed bool, capture Primitive) {
+		if !l.InRect(event.Position()) {
+			return false, nil
+		}
+		// Process mouse event.
+		switch action {
+		case MouseLeftClick:
+			setFocus(l)
+			consumed = true
+		case MouseScrollUp:
+			l.scroll(-1)
+			consumed = true
+		case MouseScrollDown:
+			l.scroll(1)
+			consumed = true
+		}
+		return
+	})
+}
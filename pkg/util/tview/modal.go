@@ -126,3 +126,15 @@ func (m *Modal) Draw(screen tcell.Screen) {
 	m.frame.SetRect(x, y, width, height)
 	m.frame.Draw(screen)
 }
+// MouseHandler returns the mouse handler for this primitive.
+func (m *Modal) MouseHandler() func(action MouseAction, event *tcell.EventMouse, setFocus func(p Primitive)) (consumed bool, capture Primitive) {
+	return m.WrapMouseHandler(func(action MouseAction, event *tcell.EventMouse, setFocus func(p Primitive)) (consumed bool, capture Primitive) {
+		// Pass mouse events on to the frame, and keep the clicks on the rest of
+		// the modal from reaching what is below it.
+		consumed, capture = passMouseEvent(m.frame, action, event, setFocus)
+		if !consumed && m.InRect(event.Position()) {
+			consumed = true
+		}
+		return
+	})
+}
@@ -242,3 +242,24 @@ func (p *Pages) Draw(screen tcell.Screen) {
 		page.Item.Draw(screen)
 	}
 }
+// MouseHandler returns the mouse handler for this primitive.
+func (p *Pages) MouseHandler() func(action MouseAction, event *tcell.EventMouse, setFocus func(p Primitive)) (consumed bool, capture Primitive) {
+	return p.WrapMouseHandler(func(action MouseAction, event *tcell.EventMouse, setFocus func(p Primitive)) (consumed bool, capture Primitive) {
+		if !p.InRect(event.Position()) {
+			return false, nil
+		}
+		// Pass mouse events along to the last visible page item that takes it,
+		// as it is drawn on top of the others.
+		for index := len(p.pages) - 1; index >= 0; index-- {
+			page := p.pages[index]
+			if !page.Visible {
+				continue
+			}
+			consumed, capture = passMouseEvent(page.Item, action, event, setFocus)
+			if consumed {
+				return
+			}
+		}
+		return
+	})
+}
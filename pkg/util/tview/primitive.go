@@ -28,6 +28,27 @@ type Primitive interface {
 	// subclass from Box, it is recommended that you wrap your handler using
 	// Box.WrapInputHandler() so you inherit that functionality.
 	InputHandler() func(event *tcell.EventKey, setFocus func(p Primitive))
+	// MouseHandler returns a handler which receives mouse events. It is called
+	// by the Application class, on the root primitive, and the handlers of
+	// primitives composed of others pass the events on to the handlers of the
+	// primitives they contain.
+	//
+	// A value of nil may also be returned to stop the downward propagation of
+	// mouse events.
+	//
+	// The handler will receive the mouse action, the mouse event, and a
+	// function that allows it to set the focus to a different primitive. It
+	// returns whether it consumed the event, and optionally a primitive which
+	// then receives all mouse events until its handler returns nil as the
+	// capturing primitive, for example while a button is held down on it.
+	//
+	// The Application's Draw() function will be called automatically after the
+	// handler returns if it consumed the event.
+	//
+	// The Box class provides functionality to intercept mouse events. If you
+	// subclass from Box, it is recommended that you wrap your handler using
+	// Box.WrapMouseHandler() so you inherit that functionality.
+	MouseHandler() func(action MouseAction, event *tcell.EventMouse, setFocus func(p Primitive)) (consumed bool, capture Primitive)
 	// Focus is called by the application when the primitive receives focus.
 	// Implementers may call delegate() to pass the focus on to another primitive.
 	Focus(delegate func(p Primitive))
@@ -36,3 +57,11 @@ type Primitive interface {
 	// GetFocusable returns the item's Focusable.
 	GetFocusable() Focusable
 }
+// passMouseEvent passes a mouse event to the mouse handler of a primitive, if it
+// has one.
+func passMouseEvent(p Primitive, action MouseAction, event *tcell.EventMouse, setFocus func(p Primitive)) (consumed bool, capture Primitive) {
+	if handler := p.MouseHandler(); handler != nil {
+		return handler(action, event, setFocus)
+	}
+	return false, nil
+}
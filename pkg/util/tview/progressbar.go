@@ -0,0 +1,268 @@
+package tview
+import (
+	"fmt"
+	"sort"
+	"git.parallelcoin.io/dev/9/pkg/util/tcell"
+)
+// colorThreshold is a color used for a bar once what it shows reaches a
+// threshold.
+type colorThreshold struct {
+	at    float64
+	color tcell.Color
+}
+// colorThresholds are the thresholds of a bar, sorted by where they are.
+type colorThresholds []colorThreshold
+// add adds a threshold, replacing the color of an existing one at the same
+// place.
+func (t colorThresholds) add(at float64, color tcell.Color) colorThresholds {
+	for i := range t {
+		if t[i].at == at {
+			t[i].color = color
+			return t
+		}
+	}
+	t = append(t, colorThreshold{at: at, color: color})
+	sort.Slice(t, func(i, j int) bool {
+		return t[i].at < t[j].at
+	})
+	return t
+}
+// color returns the color of the highest threshold the passed value reaches,
+// or the default color if it reaches none.
+func (t colorThresholds) color(value float64, defaultColor tcell.Color) tcell.Color {
+	color := defaultColor
+	for _, threshold := range t {
+		if value < threshold.at {
+			break
+		}
+		color = threshold.color
+	}
+	return color
+}
+// clampFraction limits a fraction to the range 0 to 1.
+func clampFraction(fraction float64) float64 {
+	switch {
+	case fraction < 0:
+		return 0
+	case fraction > 1:
+		return 1
+	}
+	return fraction
+}
+// ProgressBar displays the progress of a task as a horizontal bar, optionally
+// preceded by a label and followed by the percentage done.
+//
+// A progress bar is determinate when the amount of work is known: the bar
+// fills as its value approaches its maximum (see SetValue() and SetMax()). It
+// is indeterminate when the amount of work is not known: a block moves back
+// and forth across the bar each time Pulse() is called, which is typically
+// done from a ticker with Application.QueueUpdateDraw().
+//
+// The color of the bar can change as the progress reaches thresholds (see
+// AddThreshold()).
+type ProgressBar struct {
+	*Box
+	// The text to be displayed before the bar.
+	label string
+	// The screen width of the label area. A value of 0 means use the width of
+	// the label text.
+	labelWidth int
+	// The label color.
+	labelColor tcell.Color
+	// The progress and the value at which the task is complete.
+	value, max float64
+	// Whether the amount of work is unknown, and the animation step shown
+	// when it is.
+	indeterminate bool
+	pulse         int
+	// Whether the percentage done is shown after the bar.
+	showPercentage bool
+	// The runes of the filled and empty parts of the bar.
+	filledRune, emptyRune rune
+	// The color of the filled part of the bar when no threshold is reached,
+	// and of the empty part.
+	filledColor, emptyColor tcell.Color
+	// The colors of the filled part of the bar from each threshold on.
+	thresholds colorThresholds
+}
+// NewProgressBar returns a new determinate progress bar with a maximum of 100.
+func NewProgressBar() *ProgressBar {
+	return &ProgressBar{
+		Box:            NewBox(),
+		labelColor:     Styles.SecondaryTextColor,
+		max:            100,
+		showPercentage: true,
+		filledRune:     '█',
+		emptyRune:      '░',
+		filledColor:    Styles.TertiaryTextColor,
+		emptyColor:     Styles.GraphicsColor,
+	}
+}
+// SetLabel sets the text to be displayed before the bar.
+func (p *ProgressBar) SetLabel(label string) *ProgressBar {
+	p.label = label
+	return p
+}
+// GetLabel returns the text to be displayed before the bar.
+func (p *ProgressBar) GetLabel() string {
+	return p.label
+}
+// SetLabelWidth sets the screen width of the label. A value of 0 will cause the
+// primitive to use the width of the label string.
+func (p *ProgressBar) SetLabelWidth(width int) *ProgressBar {
+	p.labelWidth = width
+	return p
+}
+// SetLabelColor sets the color of the label.
+func (p *ProgressBar) SetLabelColor(color tcell.Color) *ProgressBar {
+	p.labelColor = color
+	return p
+}
+// SetMax sets the value at which the task is complete. Values of 0 or less
+// make the bar indeterminate.
+func (p *ProgressBar) SetMax(max float64) *ProgressBar {
+	p.max = max
+	return p
+}
+// GetMax returns the value at which the task is complete.
+func (p *ProgressBar) GetMax() float64 {
+	return p.max
+}
+// SetValue sets the progress of the task. It is limited to the range of 0 to
+// the maximum when drawn.
+func (p *ProgressBar) SetValue(value float64) *ProgressBar {
+	p.value = value
+	return p
+}
+// GetValue returns the progress of the task.
+func (p *ProgressBar) GetValue() float64 {
+	return p.value
+}
+// Add adds to the progress of the task.
+func (p *ProgressBar) Add(delta float64) *ProgressBar {
+	p.value += delta
+	return p
+}
+// GetFraction returns the share of the task that is done, from 0 to 1. It is 0
+// when the bar is indeterminate.
+func (p *ProgressBar) GetFraction() float64 {
+	if p.IsIndeterminate() {
+		return 0
+	}
+	return clampFraction(p.value / p.max)
+}
+// SetIndeterminate sets whether the amount of work is unknown, in which case
+// the bar shows activity rather than progress.
+func (p *ProgressBar) SetIndeterminate(indeterminate bool) *ProgressBar {
+	p.indeterminate = indeterminate
+	return p
+}
+// IsIndeterminate returns whether the bar shows activity rather than progress,
+// which is the case when it was set to be or it has no maximum.
+func (p *ProgressBar) IsIndeterminate() bool {
+	return p.indeterminate || p.max <= 0
+}
+// Pulse moves the block of an indeterminate bar by one step.
+func (p *ProgressBar) Pulse() *ProgressBar {
+	p.pulse++
+	return p
+}
+// SetShowPercentage sets whether the percentage done is shown after the bar of
+// a determinate progress bar.
+func (p *ProgressBar) SetShowPercentage(show bool) *ProgressBar {
+	p.showPercentage = show
+	return p
+}
+// SetRunes sets the runes used to draw the filled and empty parts of the bar.
+func (p *ProgressBar) SetRunes(filled, empty rune) *ProgressBar {
+	p.filledRune, p.emptyRune = filled, empty
+	return p
+}
+// SetColors sets the color of the filled part of the bar, used when no
+// threshold is reached, and of the empty part.
+func (p *ProgressBar) SetColors(filled, empty tcell.Color) *ProgressBar {
+	p.filledColor, p.emptyColor = filled, empty
+	return p
+}
+// AddThreshold sets the color of the filled part of the bar once the fraction
+// of the task that is done reaches the passed fraction, from 0 to 1. The color
+// of the highest threshold reached is used.
+//
+// For example, to show a bar that is red until half done, then yellow, and
+// green once complete:
+//
+//   bar.SetColors(tcell.ColorRed, tcell.ColorGray).
+//     AddThreshold(0.5, tcell.ColorYellow).
+//     AddThreshold(1, tcell.ColorGreen)
+func (p *ProgressBar) AddThreshold(fraction float64, color tcell.Color) *ProgressBar {
+	p.thresholds = p.thresholds.add(fraction, color)
+	return p
+}
+// ClearThresholds removes all thresholds.
+func (p *ProgressBar) ClearThresholds() *ProgressBar {
+	p.thresholds = nil
+	return p
+}
+// Draw draws this primitive onto the screen.
+func (p *ProgressBar) Draw(screen tcell.Screen) {
+	p.Box.Draw(screen)
+	x, y, width, height := p.GetInnerRect()
+	rightLimit := x + width
+	if height < 1 || rightLimit <= x {
+		return
+	}
+	// Draw label.
+	if p.labelWidth > 0 {
+		labelWidth := p.labelWidth
+		if labelWidth > rightLimit-x {
+			labelWidth = rightLimit - x
+		}
+		Print(screen, p.label, x, y, labelWidth, AlignLeft, p.labelColor)
+		x += labelWidth
+	} else {
+		_, drawnWidth := Print(screen, p.label, x, y, rightLimit-x, AlignLeft, p.labelColor)
+		x += drawnWidth
+	}
+	// Draw percentage.
+	indeterminate := p.IsIndeterminate()
+	fraction := p.GetFraction()
+	if p.showPercentage && !indeterminate {
+		percentage := fmt.Sprintf(" %3d%%", int(fraction*100))
+		if rightLimit-x > len(percentage) {
+			rightLimit -= len(percentage)
+			Print(screen, percentage, rightLimit, y, len(percentage), AlignLeft, p.labelColor)
+		}
+	}
+	barWidth := rightLimit - x
+	if barWidth <= 0 {
+		return
+	}
+	// Draw bar.
+	start, end := 0, int(fraction*float64(barWidth))
+	color := p.thresholds.color(fraction, p.filledColor)
+	if indeterminate {
+		// A block a fifth of the bar wide bounces between its ends.
+		block := barWidth / 5
+		if block < 1 {
+			block = 1
+		}
+		start = 0
+		if span := barWidth - block; span > 0 {
+			start = p.pulse % (2 * span)
+			if start > span {
+				start = 2*span - start
+			}
+		}
+		end = start + block
+		color = p.filledColor
+	}
+	filledStyle := tcell.StyleDefault.Background(p.backgroundColor).Foreground(color)
+	emptyStyle := tcell.StyleDefault.Background(p.backgroundColor).Foreground(p.emptyColor)
+	for i := 0; i < barWidth; i++ {
+		if i >= start && i < end {
+			screen.SetContent(x+i, y, p.filledRune, nil, filledStyle)
+		} else {
+			screen.SetContent(x+i, y, p.emptyRune, nil, emptyStyle)
+		}
+	}
+}
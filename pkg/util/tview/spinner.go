@@ -0,0 +1,155 @@
+package tview
+import (
+	"sync"
+	"time"
+	"git.parallelcoin.io/dev/9/pkg/util/tcell"
+)
+// Frames for spinners.
+var (
+	// SpinnerDots is a braille dot circling around, the default.
+	SpinnerDots = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+	// SpinnerLine is a turning line, for terminals without braille glyphs.
+	SpinnerLine = []string{"|", "/", "-", "\\"}
+)
+// Spinner shows an animation followed by a label while a long-running
+// operation is under way, to show that the application is busy. It draws only
+// the label, or nothing, while it is stopped.
+//
+// Start() animates the spinner in the given application until Stop() is
+// called. The methods of Spinner may be called from any goroutine.
+type Spinner struct {
+	*Box
+	sync.Mutex
+	// The frames of the animation and the index of the one shown.
+	frames []string
+	frame  int
+	// The time between two frames.
+	interval time.Duration
+	// The text shown after the animation.
+	label string
+	// The colors of the animation and the label.
+	spinnerColor, labelColor tcell.Color
+	// Whether the label is shown while the spinner is stopped.
+	showStopped bool
+	// Closed to end the animation, nil if it is not running.
+	stop chan struct{}
+}
+// NewSpinner returns a new, stopped spinner showing SpinnerDots.
+func NewSpinner() *Spinner {
+	return &Spinner{
+		Box:          NewBox(),
+		frames:       SpinnerDots,
+		interval:     100 * time.Millisecond,
+		spinnerColor: Styles.SecondaryTextColor,
+		labelColor:   Styles.PrimaryTextColor,
+	}
+}
+// SetFrames sets the frames of the animation, for example SpinnerLine.
+func (s *Spinner) SetFrames(frames ...string) *Spinner {
+	s.Lock()
+	defer s.Unlock()
+	if len(frames) > 0 {
+		s.frames, s.frame = frames, 0
+	}
+	return s
+}
+// SetInterval sets the time each frame is shown. It takes effect the next time
+// the spinner is started.
+func (s *Spinner) SetInterval(interval time.Duration) *Spinner {
+	s.Lock()
+	defer s.Unlock()
+	if interval > 0 {
+		s.interval = interval
+	}
+	return s
+}
+// SetLabel sets the text shown after the animation.
+func (s *Spinner) SetLabel(label string) *Spinner {
+	s.Lock()
+	defer s.Unlock()
+	s.label = label
+	return s
+}
+// GetLabel returns the text shown after the animation.
+func (s *Spinner) GetLabel() string {
+	s.Lock()
+	defer s.Unlock()
+	return s.label
+}
+// SetColors sets the colors of the animation and the label.
+func (s *Spinner) SetColors(spinner, label tcell.Color) *Spinner {
+	s.Lock()
+	defer s.Unlock()
+	s.spinnerColor, s.labelColor = spinner, label
+	return s
+}
+// SetShowStopped sets whether the label is shown while the spinner is
+// stopped. By default nothing is drawn then.
+func (s *Spinner) SetShowStopped(show bool) *Spinner {
+	s.Lock()
+	defer s.Unlock()
+	s.showStopped = show
+	return s
+}
+// Start starts the animation, redrawing the application at each frame. It
+// has no effect if the spinner is already running.
+func (s *Spinner) Start(app *Application) *Spinner {
+	s.Lock()
+	defer s.Unlock()
+	if s.stop != nil {
+		return s
+	}
+	stop := make(chan struct{})
+	s.stop, s.frame = stop, 0
+	go func(interval time.Duration) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				app.QueueUpdateDraw(func() {})
+				return
+			case <-ticker.C:
+				s.Lock()
+				s.frame = (s.frame + 1) % len(s.frames)
+				s.Unlock()
+				app.QueueUpdateDraw(func() {})
+			}
+		}
+	}(s.interval)
+	return s
+}
+// Stop stops the animation.
+func (s *Spinner) Stop() *Spinner {
+	s.Lock()
+	defer s.Unlock()
+	if s.stop != nil {
+		close(s.stop)
+		s.stop = nil
+	}
+	return s
+}
+// IsRunning returns whether the animation is running.
+func (s *Spinner) IsRunning() bool {
+	s.Lock()
+	defer s.Unlock()
+	return s.stop != nil
+}
+// Draw draws this primitive onto the screen.
+func (s *Spinner) Draw(screen tcell.Screen) {
+	s.Box.Draw(screen)
+	s.Lock()
+	defer s.Unlock()
+	x, y, width, height := s.GetInnerRect()
+	if width <= 0 || height <= 0 {
+		return
+	}
+	label := s.label
+	if s.stop != nil {
+		_, drawn := Print(screen, s.frames[s.frame%len(s.frames)]+" ", x, y, width, AlignLeft, s.spinnerColor)
+		x, width = x+drawn, width-drawn
+	} else if !s.showStopped {
+		return
+	}
+	Print(screen, label, x, y, width, AlignLeft, s.labelColor)
+}
@@ -0,0 +1,293 @@
+package tview
+import (
+	"git.parallelcoin.io/dev/9/pkg/util/tcell"
+)
+// SplitPane shows two primitives side by side (FlexColumn, the default) or
+// above each other (FlexRow), separated by a divider the user can move. The
+// position of the divider is kept as a share of the available space so the
+// layout scales with the terminal. Either pane may be nil, leaving its space
+// empty.
+//
+// The divider can be dragged with the mouse. Keys go to the focused primitive
+// and not to its containers, so to move the divider with the keyboard call
+// ResizeKey() from an input capture. It handles:
+//
+//   - Alt-Left, Alt-Right: Move the divider of panes side by side.
+//   - Alt-Up, Alt-Down: Move the divider of stacked panes.
+//
+// Split panes can be nested. ResizeKey() moves the divider of the innermost
+// split pane in the key's direction which contains the focused primitive. A
+// function set with SetChangedFunc() is called when the user has moved the
+// divider, for example to save the layout.
+type SplitPane struct {
+	*Box
+	// The two panes, the first being the left or top one.
+	first, second Primitive
+	// FlexColumn for panes side by side, FlexRow for stacked panes.
+	direction int
+	// The share of the space (without the divider) taken by the first pane.
+	position float64
+	// The smallest size of each pane, if there is space for it.
+	minSize int
+	// The colors of the divider, and of the divider while it is dragged.
+	dividerColor, dragColor tcell.Color
+	// Whether the divider is being dragged with the mouse.
+	dragging bool
+	// The size of the split space and the offset of the divider the last time
+	// the split pane was drawn.
+	size, divider int
+	// An optional function which gets called with the new position when the
+	// user has moved the divider.
+	changed func(position float64)
+}
+// NewSplitPane returns a new split pane showing the two primitives side by
+// side, each taking half of the space.
+func NewSplitPane(first, second Primitive) *SplitPane {
+	s := &SplitPane{
+		Box:          NewBox().SetBackgroundColor(tcell.ColorDefault),
+		first:        first,
+		second:       second,
+		direction:    FlexColumn,
+		position:     0.5,
+		minSize:      1,
+		dividerColor: Styles.GraphicsColor,
+		dragColor:    Styles.SecondaryTextColor,
+	}
+	s.focus = s
+	return s
+}
+// SetDirection sets whether the panes are shown side by side (FlexColumn) or
+// above each other (FlexRow).
+func (s *SplitPane) SetDirection(direction int) *SplitPane {
+	s.direction = direction
+	return s
+}
+// SetPanes replaces the two panes.
+func (s *SplitPane) SetPanes(first, second Primitive) *SplitPane {
+	s.first, s.second = first, second
+	return s
+}
+// GetPanes returns the two panes.
+func (s *SplitPane) GetPanes() (first, second Primitive) {
+	return s.first, s.second
+}
+// SetPosition sets the share of the space taken by the first pane, between 0
+// and 1. The minimum size of the panes is kept regardless.
+func (s *SplitPane) SetPosition(position float64) *SplitPane {
+	if position < 0 {
+		position = 0
+	} else if position > 1 {
+		position = 1
+	}
+	s.position = position
+	return s
+}
+// GetPosition returns the share of the space taken by the first pane.
+func (s *SplitPane) GetPosition() float64 {
+	return s.position
+}
+// SetMinSize sets the smallest width or height of each pane, if the split pane
+// is large enough. The default is 1.
+func (s *SplitPane) SetMinSize(size int) *SplitPane {
+	s.minSize = size
+	return s
+}
+// SetDividerColor sets the color of the divider and the color it has while it
+// is dragged with the mouse.
+func (s *SplitPane) SetDividerColor(color, dragColor tcell.Color) *SplitPane {
+	s.dividerColor, s.dragColor = color, dragColor
+	return s
+}
+// SetChangedFunc sets a function which is called with the new position when
+// the user has moved the divider with the mouse or with ResizeKey().
+func (s *SplitPane) SetChangedFunc(handler func(position float64)) *SplitPane {
+	s.changed = handler
+	return s
+}
+// Resize moves the divider by the given number of cells, towards the second
+// pane if positive. It has no effect before the split pane was drawn.
+func (s *SplitPane) Resize(cells int) *SplitPane {
+	if s.size > 0 {
+		s.SetPosition(float64(s.firstSize(s.size)+cells) / float64(s.size))
+	}
+	return s
+}
+// ResizeKey moves the divider if the event is one of the keys listed in the
+// SplitPane documentation and returns true if it did.
+func (s *SplitPane) ResizeKey(event *tcell.EventKey) bool {
+	if event.Modifiers()&tcell.ModAlt == 0 {
+		return false
+	}
+	switch event.Key() {
+	case tcell.KeyLeft:
+		return s.resizeFocused(FlexColumn, -1)
+	case tcell.KeyRight:
+		return s.resizeFocused(FlexColumn, 1)
+	case tcell.KeyUp:
+		return s.resizeFocused(FlexRow, -1)
+	case tcell.KeyDown:
+		return s.resizeFocused(FlexRow, 1)
+	}
+	return false
+}
+// resizeFocused moves the divider of the innermost split pane in the given
+// direction which contains the focused primitive.
+func (s *SplitPane) resizeFocused(direction, cells int) bool {
+	if !s.HasFocus() {
+		return false
+	}
+	for _, pane := range []Primitive{s.first, s.second} {
+		if inner, ok := pane.(*SplitPane); ok && inner.resizeFocused(direction, cells) {
+			return true
+		}
+	}
+	if direction != s.direction || s.size == 0 {
+		return false
+	}
+	s.Resize(cells)
+	if s.changed != nil {
+		s.changed(s.position)
+	}
+	return true
+}
+// firstSize returns the size of the first pane when the given space (without
+// the divider) is split.
+func (s *SplitPane) firstSize(size int) int {
+	first := int(float64(size)*s.position + 0.5)
+	min := s.minSize
+	if 2*min > size {
+		min = size / 2
+	}
+	if first < min {
+		first = min
+	} else if first > size-min {
+		first = size - min
+	}
+	return first
+}
+// Draw draws this primitive onto the screen.
+func (s *SplitPane) Draw(screen tcell.Screen) {
+	s.Box.Draw(screen)
+	x, y, width, height := s.GetInnerRect()
+	s.size = width - 1
+	if s.direction == FlexRow {
+		s.size = height - 1
+	}
+	if s.size < 0 {
+		s.size = 0
+	}
+	s.divider = s.firstSize(s.size)
+	// Lay out the panes and draw the divider.
+	style := tcell.StyleDefault.Background(s.backgroundColor).Foreground(s.dividerColor)
+	if s.dragging {
+		style = style.Foreground(s.dragColor)
+	}
+	if s.direction == FlexRow {
+		if s.first != nil {
+			s.first.SetRect(x, y, width, s.divider)
+		}
+		if s.second != nil {
+			s.second.SetRect(x, y+s.divider+1, width, s.size-s.divider)
+		}
+		for column := 0; column < width && s.divider < height; column++ {
+			screen.SetContent(x+column, y+s.divider, Borders.Horizontal, nil, style)
+		}
+	} else {
+		if s.first != nil {
+			s.first.SetRect(x, y, s.divider, height)
+		}
+		if s.second != nil {
+			s.second.SetRect(x+s.divider+1, y, s.size-s.divider, height)
+		}
+		for row := 0; row < height && s.divider < width; row++ {
+			screen.SetContent(x+s.divider, y+row, Borders.Vertical, nil, style)
+		}
+	}
+	// Draw the focused pane last so its cursor is shown.
+	for _, pane := range []Primitive{s.first, s.second} {
+		if pane == nil {
+			continue
+		}
+		if pane.GetFocusable().HasFocus() {
+			defer pane.Draw(screen)
+		} else {
+			pane.Draw(screen)
+		}
+	}
+}
+// Focus is called when this primitive receives focus. The first pane gets the
+// focus, or the second one if there is no first pane.
+func (s *SplitPane) Focus(delegate func(p Primitive)) {
+	if s.first != nil {
+		delegate(s.first)
+	} else if s.second != nil {
+		delegate(s.second)
+	}
+}
+// HasFocus returns whether or not this primitive has focus.
+func (s *SplitPane) HasFocus() bool {
+	for _, pane := range []Primitive{s.first, s.second} {
+		if pane != nil && pane.GetFocusable().HasFocus() {
+			return true
+		}
+	}
+	return false
+}
+// dragTo moves the divider to the given screen position.
+func (s *SplitPane) dragTo(x, y int) {
+	innerX, innerY, _, _ := s.GetInnerRect()
+	offset := x - innerX
+	if s.direction == FlexRow {
+		offset = y - innerY
+	}
+	if s.size > 0 {
+		s.SetPosition(float64(offset) / float64(s.size))
+	}
+}
+// onDivider returns whether the given screen position is on the divider.
+func (s *SplitPane) onDivider(x, y int) bool {
+	innerX, innerY, _, _ := s.GetInnerRect()
+	if s.direction == FlexRow {
+		return y == innerY+s.divider
+	}
+	return x == innerX+s.divider
+}
+// MouseHandler returns the mouse handler for this primitive.
+func (s *SplitPane) MouseHandler() func(action MouseAction, event *tcell.EventMouse, setFocus func(p Primitive)) (consumed bool, capture Primitive) {
+	return s.WrapMouseHandler(func(action MouseAction, event *tcell.EventMouse, setFocus func(p Primitive)) (consumed bool, capture Primitive) {
+		x, y := event.Position()
+		// While the divider is dragged, the split pane captures the mouse.
+		if s.dragging {
+			switch action {
+			case MouseMove:
+				s.dragTo(x, y)
+			case MouseLeftUp:
+				s.dragging = false
+				s.dragTo(x, y)
+				if s.changed != nil {
+					s.changed(s.position)
+				}
+				return true, nil
+			}
+			return true, s
+		}
+		if !s.InRect(x, y) {
+			return false, nil
+		}
+		if action == MouseLeftDown && s.onDivider(x, y) {
+			s.dragging = true
+			return true, s
+		}
+		// Pass other mouse events along to the pane below the mouse.
+		for _, pane := range []Primitive{s.first, s.second} {
+			if pane == nil {
+				continue
+			}
+			consumed, capture = passMouseEvent(pane, action, event, setFocus)
+			if consumed {
+				return
+			}
+		}
+		return
+	})
+}
@@ -0,0 +1,160 @@
+package tview
+import (
+	"sync"
+	"time"
+	"git.parallelcoin.io/dev/9/pkg/util/tcell"
+)
+// StatusBar is a one line bar with a left, a center and a right aligned text,
+// as found at the bottom of many applications. The texts may contain color
+// tags. If they don't all fit, the right text is kept and the center text is
+// left out first.
+//
+// ShowMessage() shows a message in place of the left and center texts, for
+// example to confirm that something was done, which goes away by itself after
+// a timeout. Set the application with SetApplication() so the bar is redrawn
+// when a message expires. The methods of StatusBar may be called from any
+// goroutine.
+type StatusBar struct {
+	*Box
+	sync.Mutex
+	// The three texts.
+	left, center, right string
+	// The color of the texts.
+	textColor tcell.Color
+	// The message shown in place of the left and center texts, if any.
+	message      string
+	messageColor tcell.Color
+	// The time the message goes away. It stays if this is zero.
+	expires time.Time
+	// If set, the application is made to redraw the bar when a message
+	// expires.
+	app *Application
+}
+// NewStatusBar returns a new, empty status bar.
+func NewStatusBar() *StatusBar {
+	return &StatusBar{
+		Box:       NewBox(),
+		textColor: Styles.PrimaryTextColor,
+	}
+}
+// SetText sets the left, center and right texts.
+func (s *StatusBar) SetText(left, center, right string) *StatusBar {
+	s.Lock()
+	defer s.Unlock()
+	s.left, s.center, s.right = left, center, right
+	return s
+}
+// SetLeft sets the left aligned text.
+func (s *StatusBar) SetLeft(text string) *StatusBar {
+	s.Lock()
+	defer s.Unlock()
+	s.left = text
+	return s
+}
+// SetCenter sets the centered text.
+func (s *StatusBar) SetCenter(text string) *StatusBar {
+	s.Lock()
+	defer s.Unlock()
+	s.center = text
+	return s
+}
+// SetRight sets the right aligned text.
+func (s *StatusBar) SetRight(text string) *StatusBar {
+	s.Lock()
+	defer s.Unlock()
+	s.right = text
+	return s
+}
+// GetText returns the left, center and right texts.
+func (s *StatusBar) GetText() (left, center, right string) {
+	s.Lock()
+	defer s.Unlock()
+	return s.left, s.center, s.right
+}
+// SetTextColor sets the color of the texts.
+func (s *StatusBar) SetTextColor(color tcell.Color) *StatusBar {
+	s.Lock()
+	defer s.Unlock()
+	s.textColor = color
+	return s
+}
+// SetApplication sets the application which is asked to redraw the bar when a
+// message expires. Without it, an expired message is removed the next time the
+// bar is drawn for another reason.
+func (s *StatusBar) SetApplication(app *Application) *StatusBar {
+	s.Lock()
+	defer s.Unlock()
+	s.app = app
+	return s
+}
+// ShowMessage shows a message in the given color in place of the left and
+// center texts. It goes away after the timeout, or stays until ClearMessage()
+// is called or another message is shown if the timeout is 0.
+func (s *StatusBar) ShowMessage(text string, color tcell.Color, timeout time.Duration) *StatusBar {
+	s.Lock()
+	defer s.Unlock()
+	s.message, s.messageColor = text, color
+	s.expires = time.Time{}
+	if timeout > 0 {
+		s.expires = time.Now().Add(timeout)
+		if app := s.app; app != nil {
+			time.AfterFunc(timeout, func() {
+				app.QueueUpdateDraw(func() {})
+			})
+		}
+	}
+	return s
+}
+// ClearMessage removes the message, showing the left and center texts again.
+func (s *StatusBar) ClearMessage() *StatusBar {
+	s.Lock()
+	defer s.Unlock()
+	s.message = ""
+	return s
+}
+// GetMessage returns the message shown, or an empty string if there is none.
+func (s *StatusBar) GetMessage() string {
+	s.Lock()
+	defer s.Unlock()
+	s.expire()
+	return s.message
+}
+// expire removes the message if its time is up.
+func (s *StatusBar) expire() {
+	if s.message != "" && !s.expires.IsZero() && !time.Now().Before(s.expires) {
+		s.message = ""
+	}
+}
+// Draw draws this primitive onto the screen.
+func (s *StatusBar) Draw(screen tcell.Screen) {
+	s.Box.Draw(screen)
+	s.Lock()
+	defer s.Unlock()
+	x, y, width, height := s.GetInnerRect()
+	if width <= 0 || height <= 0 {
+		return
+	}
+	s.expire()
+	// The right text comes first, the rest gets what is left of the line.
+	rightWidth := TaggedStringWidth(s.right)
+	if rightWidth > width {
+		rightWidth = width
+	}
+	Print(screen, s.right, x+width-rightWidth, y, rightWidth, AlignLeft, s.textColor)
+	space := width - rightWidth - 1
+	if space <= 0 {
+		return
+	}
+	if s.message != "" {
+		Print(screen, s.message, x, y, space, AlignLeft, s.messageColor)
+		return
+	}
+	_, leftWidth := Print(screen, s.left, x, y, space, AlignLeft, s.textColor)
+	// The center text is centered on the whole bar if it fits between the
+	// others.
+	centerWidth := TaggedStringWidth(s.center)
+	centerX := (width - centerWidth) / 2
+	if s.center != "" && centerX > leftWidth && centerX+centerWidth < space {
+		Print(screen, s.center, x+centerX, y, centerWidth, AlignLeft, s.textColor)
+	}
+}
@@ -1,6 +1,8 @@
 package tview
 import (
 	"sort"
+	"strconv"
+	"strings"
 	"git.parallelcoin.io/dev/9/pkg/util/tcell"
 	colorful "github.com/lucasb-eyer/go-colorful"
 )
@@ -162,6 +164,22 @@ func (c *TableCell) GetLastPosition() (x, y, width int) {
 // set, individual cells can be selected. The "selected" handler set via
 // SetSelectedFunc() is invoked when the user presses Enter on a selection.
 //
+// Sorting and Filtering
+//
+// Rows below the fixed rows can be sorted by a column with SortByColumn() and
+// filtered with SetFilter(), which hides rows whose cell in a column does not
+// contain the filter text. Both are applied again whenever the table is drawn
+// after its cells changed, so a table which is cleared and refilled keeps its
+// order and filters. Row indices always refer to the rows as they are shown:
+// sorting moves rows and filtered rows are taken out of the table until the
+// filters change.
+//
+// If SetSortable() is enabled, the user can sort the table with the keys below
+// or by clicking a header cell (the last fixed row). If SetFilterable() is
+// enabled, "/" opens a filter line at the bottom of the table for the selected
+// column, or the sort column if columns are not selectable. Typed text filters
+// the rows immediately, Enter closes the line and Escape removes the filter.
+//
 // Navigation
 //
 // If the table extends beyond the available space, it can be navigated with
@@ -175,6 +193,10 @@ func (c *TableCell) GetLastPosition() (x, y, width int) {
 //   - G, end: Move to the bottom.
 //   - Ctrl-F, page down: Move down by one page.
 //   - Ctrl-B, page up: Move up by one page.
+//   - s: Sort by the selected column, or the next column if columns are not
+//     selectable. Sorting by the same column again reverses the order.
+//   - S: Reverse the sort order.
+//   - /: Edit the filter of the selected or sort column.
 //
 // When there is no selection, this affects the entire table (except for fixed
 // rows and columns). When there is a selection, the user moves the selection.
@@ -207,8 +229,14 @@ type Table struct {
 	rowOffset, columnOffset int
 	// If set to true, the table's last row will always be visible.
 	trackEnd bool
+	// If set to true, the offsets are moved the next time the table is drawn
+	// so the selection is visible. It is not set when the table is scrolled
+	// with the mouse wheel, which may move the selection out of view.
+	clampToSelection bool
 	// The number of visible rows the last time the table was drawn.
 	visibleRows int
+	// The rows and columns drawn the last time the table was drawn.
+	drawnRows, drawnColumns []int
 	// The style of the selected rows. If this value is 0, selected rows are
 	// simply inverted.
 	selectedStyle tcell.Style
@@ -223,19 +251,48 @@ type Table struct {
 	// An optional function which gets called when the user presses Escape, Tab,
 	// or Backtab. Also when the user presses Enter if nothing is selectable.
 	done func(key tcell.Key)
+	// Whether the user can sort and filter the rows.
+	sortable, filterable bool
+	// The column the rows are sorted by (-1 if they are not sorted) and the
+	// order.
+	sortColumn    int
+	sortAscending bool
+	// An optional function which reports whether cell a sorts before cell b.
+	sortLess func(column int, a, b *TableCell) bool
+	// The filter text of each filtered column.
+	filters map[int]string
+	// The rows taken out of the table by the filters.
+	hiddenRows []hiddenRow
+	// The column whose filter the user is editing, or -1.
+	editingFilter int
+	// If set to true, the rows are sorted and filtered again the next time the
+	// table is drawn.
+	dirty bool
+}
+// hiddenRow is a row taken out of a table by a filter, with its index among
+// the rows below the fixed rows.
+type hiddenRow struct {
+	index int
+	cells []*TableCell
 }
 // NewTable returns a new table.
 func NewTable() *Table {
 	return &Table{
-		Box:          NewBox(),
-		bordersColor: Styles.GraphicsColor,
-		separator:    ' ',
-		lastColumn:   -1,
+		Box:              NewBox(),
+		bordersColor:     Styles.GraphicsColor,
+		separator:        ' ',
+		lastColumn:       -1,
+		clampToSelection: true,
+		sortColumn:       -1,
+		sortAscending:    true,
+		editingFilter:    -1,
 	}
 }
-// Clear removes all table data.
+// Clear removes all table data. The sort order and filters are kept and
+// applied to the cells set afterwards.
 func (t *Table) Clear() *Table {
 	t.cells = nil
+	t.hiddenRows = nil
 	t.lastColumn = -1
 	return t
 }
@@ -306,6 +363,7 @@ func (t *Table) GetSelection() (row, column int) {
 // ignored completely.
 func (t *Table) Select(row, column int) *Table {
 	t.selectedRow, t.selectedColumn = row, column
+	t.clampToSelection = true
 	return t
 }
 // SetOffset sets how many rows and columns should be skipped when drawing the
@@ -324,8 +382,9 @@ func (t *Table) GetOffset() (row, column int) {
 	return t.rowOffset, t.columnOffset
 }
 // SetSelectedFunc sets a handler which is called whenever the user presses the
-// Enter key on a selected cell/row/column. The handler receives the position of
-// the selection and its cell contents. If entire rows are selected, the column
+// Enter key on a selected cell/row/column, or clicks it with the mouse while it
+// is selected. The handler receives the position of the selection and its cell
+// contents. If entire rows are selected, the column
 // index is undefined. Likewise for entire columns.
 func (t *Table) SetSelectedFunc(handler func(row, column int)) *Table {
 	t.selected = handler
@@ -371,6 +430,7 @@ func (t *Table) SetCell(row, column int, cell *TableCell) *Table {
 	if column > t.lastColumn {
 		t.lastColumn = column
 	}
+	t.dirty = true
 	return t
 }
 // SetCellSimple calls SetCell() with the given text, left-aligned, in white.
@@ -396,6 +456,7 @@ func (t *Table) RemoveRow(row int) *Table {
 		return t
 	}
 	t.cells = append(t.cells[:row], t.cells[row+1:]...)
+	t.dirty = true
 	return t
 }
 // RemoveColumn removes the column at the given position from the table. If
@@ -419,6 +480,7 @@ func (t *Table) InsertRow(row int) *Table {
 	t.cells = append(t.cells, nil)       // Extend by one.
 	copy(t.cells[row+1:], t.cells[row:]) // Shift down.
 	t.cells[row] = nil                   // New row is uninitialized.
+	t.dirty = true
 	return t
 }
 // InsertColumn inserts a column before the column with the given index. Cells
@@ -436,6 +498,280 @@ func (t *Table) InsertColumn(column int) *Table {
 	}
 	return t
 }
+// SetSortable sets whether the user can sort the rows by a column, with the
+// "s" and "S" keys or by clicking a header cell.
+func (t *Table) SetSortable(sortable bool) *Table {
+	t.sortable = sortable
+	return t
+}
+// SetFilterable sets whether the user can filter the rows by opening a filter
+// line with the "/" key.
+func (t *Table) SetFilterable(filterable bool) *Table {
+	t.filterable = filterable
+	return t
+}
+// SetSortFunc sets a function which reports whether cell a sorts before cell b
+// in the given column. Cells which were never set are passed as empty cells.
+// If no function is set, cells whose references or texts are both numbers are
+// compared as numbers and all others by their text, ignoring case and color
+// tags.
+func (t *Table) SetSortFunc(less func(column int, a, b *TableCell) bool) *Table {
+	t.sortLess = less
+	t.dirty = true
+	return t
+}
+// SortByColumn sorts the rows below the fixed rows by the cells in the given
+// column. The order is kept when cells are changed later. A negative column
+// stops sorting; the rows stay in their current order.
+func (t *Table) SortByColumn(column int, ascending bool) *Table {
+	if column < 0 {
+		column = -1
+	}
+	t.sortColumn, t.sortAscending = column, ascending
+	t.arrange()
+	return t
+}
+// GetSortColumn returns the column the rows are sorted by, -1 if they are not
+// sorted, and whether the order is ascending.
+func (t *Table) GetSortColumn() (column int, ascending bool) {
+	return t.sortColumn, t.sortAscending
+}
+// SetFilter hides the rows below the fixed rows whose cell in the given column
+// does not contain the text, ignoring case and color tags. Filters of several
+// columns must all match. An empty text removes the filter of the column.
+func (t *Table) SetFilter(column int, text string) *Table {
+	if t.filters == nil {
+		t.filters = make(map[int]string)
+	}
+	if text == "" {
+		delete(t.filters, column)
+	} else {
+		t.filters[column] = text
+	}
+	t.arrange()
+	return t
+}
+// GetFilter returns the filter text of the given column.
+func (t *Table) GetFilter(column int) string {
+	return t.filters[column]
+}
+// ClearFilters removes the filters of all columns, putting back all hidden
+// rows.
+func (t *Table) ClearFilters() *Table {
+	t.filters = nil
+	t.editingFilter = -1
+	t.arrange()
+	return t
+}
+// arrange puts back the rows hidden by the filters, sorts the rows and then
+// takes out those that don't match the filters. A selected row stays selected
+// if it is still in the table.
+func (t *Table) arrange() {
+	t.dirty = false
+	for _, hidden := range t.hiddenRows {
+		row := t.fixedRows + hidden.index
+		if row > len(t.cells) {
+			row = len(t.cells)
+		}
+		t.cells = append(t.cells, nil)
+		copy(t.cells[row+1:], t.cells[row:])
+		t.cells[row] = hidden.cells
+	}
+	t.hiddenRows = nil
+	if t.fixedRows >= len(t.cells) || t.sortColumn < 0 && len(t.filters) == 0 {
+		return
+	}
+	var selected *TableCell
+	if t.rowsSelectable && t.selectedRow >= t.fixedRows && t.selectedRow < len(t.cells) && len(t.cells[t.selectedRow]) > 0 {
+		selected = t.cells[t.selectedRow][0]
+	}
+	rows := t.cells[t.fixedRows:]
+	if t.sortColumn >= 0 {
+		sort.SliceStable(rows, func(i, j int) bool {
+			a, b := cellIn(rows[i], t.sortColumn), cellIn(rows[j], t.sortColumn)
+			if !t.sortAscending {
+				a, b = b, a
+			}
+			if t.sortLess != nil {
+				return t.sortLess(t.sortColumn, a, b)
+			}
+			return cellLess(a, b)
+		})
+	}
+	if len(t.filters) > 0 {
+		kept := 0
+		for index, row := range rows {
+			if t.matches(row) {
+				rows[kept] = row
+				kept++
+			} else {
+				t.hiddenRows = append(t.hiddenRows, hiddenRow{index: index, cells: row})
+			}
+		}
+		t.cells = t.cells[:t.fixedRows+kept]
+	}
+	if selected == nil {
+		return
+	}
+	for row := t.fixedRows; row < len(t.cells); row++ {
+		if len(t.cells[row]) > 0 && t.cells[row][0] == selected {
+			t.selectedRow = row
+			t.clampToSelection = true
+			return
+		}
+	}
+	t.selectedRow = t.fixedRows
+	t.clampToSelection = true
+}
+// matches returns whether the cells of a row contain the texts of all filters.
+func (t *Table) matches(row []*TableCell) bool {
+	for column, text := range t.filters {
+		if !strings.Contains(strings.ToLower(cellIn(row, column).plainText()), strings.ToLower(text)) {
+			return false
+		}
+	}
+	return true
+}
+// cellIn returns the cell in the given column of a row, or an empty cell if it
+// was never set.
+func cellIn(row []*TableCell, column int) *TableCell {
+	if column < len(row) && row[column] != nil {
+		return row[column]
+	}
+	return &TableCell{}
+}
+// plainText returns the text of the cell without color tags.
+func (c *TableCell) plainText() string {
+	_, _, _, _, _, stripped, _ := decomposeString(c.Text, true, false)
+	return stripped
+}
+// number returns the cell's reference if it is a number, or else the number
+// its text starts with, if any.
+func (c *TableCell) number() (float64, bool) {
+	switch n := c.Reference.(type) {
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	fields := strings.Fields(c.plainText())
+	if len(fields) == 0 {
+		return 0, false
+	}
+	n, err := strconv.ParseFloat(strings.TrimSuffix(fields[0], "%"), 64)
+	return n, err == nil
+}
+// cellLess is the default sort order of cells: numbers in order of their value
+// and everything else in order of the text, ignoring case.
+func cellLess(a, b *TableCell) bool {
+	if x, ok := a.number(); ok {
+		if y, ok := b.number(); ok {
+			return x < y
+		}
+	}
+	return strings.ToLower(a.plainText()) < strings.ToLower(b.plainText())
+}
+// sortNext sorts by the selected column or, if columns are not selectable, the
+// column after the current sort column. If the rows are already sorted by that
+// column, the order is reversed.
+func (t *Table) sortNext() {
+	column := t.sortColumn + 1
+	if t.columnsSelectable {
+		column = t.selectedColumn
+	} else if column > t.lastColumn {
+		column = 0
+	}
+	if column == t.sortColumn {
+		t.SortByColumn(column, !t.sortAscending)
+	} else {
+		t.SortByColumn(column, true)
+	}
+}
+// IsFiltering returns whether the user is editing a filter, in which case the
+// table takes all keys typed.
+func (t *Table) IsFiltering() bool {
+	return t.editingFilter >= 0
+}
+// filterColumn returns the column the user filters: the selected column, or
+// the sort column if columns are not selectable.
+func (t *Table) filterColumn() int {
+	if t.columnsSelectable {
+		return t.selectedColumn
+	}
+	if t.sortColumn >= 0 {
+		return t.sortColumn
+	}
+	return 0
+}
+// editFilter handles a key while the user edits a filter.
+func (t *Table) editFilter(event *tcell.EventKey) {
+	text := t.filters[t.editingFilter]
+	switch event.Key() {
+	case tcell.KeyEnter:
+		t.editingFilter = -1
+		return
+	case tcell.KeyEscape:
+		text = ""
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		if runes := []rune(text); len(runes) > 0 {
+			text = string(runes[:len(runes)-1])
+		}
+	case tcell.KeyCtrlU:
+		text = ""
+	case tcell.KeyRune:
+		text += string(event.Rune())
+	default:
+		return
+	}
+	t.SetFilter(t.editingFilter, text)
+	if event.Key() == tcell.KeyEscape {
+		t.editingFilter = -1
+	}
+}
+// columnName returns the text of a column's header cell, or its number if the
+// table has no fixed rows.
+func (t *Table) columnName(column int) string {
+	if t.fixedRows > 0 && t.fixedRows <= len(t.cells) {
+		if name := cellIn(t.cells[t.fixedRows-1], column).plainText(); name != "" {
+			return name
+		}
+	}
+	return "column " + strconv.Itoa(column+1)
+}
+// drawFilters draws the filter line at the given position.
+func (t *Table) drawFilters(screen tcell.Screen, x, y, width int) {
+	var line string
+	if t.editingFilter >= 0 {
+		line = "/" + t.columnName(t.editingFilter) + ": " + t.filters[t.editingFilter]
+	} else {
+		columns := make([]int, 0, len(t.filters))
+		for column := range t.filters {
+			columns = append(columns, column)
+		}
+		sort.Ints(columns)
+		for i, column := range columns {
+			if i > 0 {
+				line += ", "
+			}
+			line += t.columnName(column) + ": " + t.filters[column]
+		}
+		line = "filter " + line
+	}
+	_, printed := Print(screen, Escape(line), x, y, width, AlignLeft, Styles.SecondaryTextColor)
+	if t.editingFilter >= 0 && t.HasFocus() && printed < width {
+		screen.ShowCursor(x+printed, y)
+	}
+}
 // GetRowCount returns the number of rows in the table.
 func (t *Table) GetRowCount() int {
 	return len(t.cells)
@@ -471,6 +807,13 @@ func (t *Table) Draw(screen tcell.Screen) {
 	t.Box.Draw(screen)
 	// What's our available screen space?
 	x, y, width, height := t.GetInnerRect()
+	if t.dirty {
+		t.arrange()
+	}
+	if t.filterable && (len(t.filters) > 0 || t.editingFilter >= 0) && height > 1 {
+		height--
+		t.drawFilters(screen, x, y+height, width)
+	}
 	if t.borders {
 		t.visibleRows = height / 2
 	} else {
@@ -504,7 +847,7 @@ func (t *Table) Draw(screen tcell.Screen) {
 		}
 	}
 	// Clamp row offsets.
-	if t.rowsSelectable {
+	if t.rowsSelectable && t.clampToSelection {
 		if t.selectedRow >= t.fixedRows && t.selectedRow < t.fixedRows+t.rowOffset {
 			t.rowOffset = t.selectedRow - t.fixedRows
 			t.trackEnd = false
@@ -542,7 +885,7 @@ func (t *Table) Draw(screen tcell.Screen) {
 	}
 	// Clamp column offset. (Only left side here. The right side is more
 	// difficult and we'll do it below.)
-	if t.columnsSelectable && t.selectedColumn >= t.fixedColumns && t.selectedColumn < t.fixedColumns+t.columnOffset {
+	if t.columnsSelectable && t.clampToSelection && t.selectedColumn >= t.fixedColumns && t.selectedColumn < t.fixedColumns+t.columnOffset {
 		t.columnOffset = t.selectedColumn - t.fixedColumns
 	}
 	if t.columnOffset < 0 {
@@ -618,7 +961,7 @@ ColumnLoop:
 		expansion := 0
 		for _, row := range rows {
 			if cell := getCell(row, column); cell != nil {
-				_, _, _, _, _, _, cellWidth := decomposeString(cell.Text, true, false)
+				_, _, _, _, _, _, cellWidth := decomposeString(t.cellText(row, column, cell), true, false)
 				if cell.MaxWidth > 0 && cell.MaxWidth < cellWidth {
 					cellWidth = cell.MaxWidth
 				}
@@ -642,6 +985,8 @@ ColumnLoop:
 		expansionTotal += expansion
 	}
 	t.columnOffset = skipped
+	t.clampToSelection = false
+	t.drawnRows, t.drawnColumns = rows, columns
 	// If we have space left, distribute it.
 	if tableWidth < width {
 		toDistribute := width - tableWidth
@@ -705,8 +1050,9 @@ ColumnLoop:
 				finalWidth = width - columnX - 1
 			}
 			cell.x, cell.y, cell.width = x+columnX+1, y+rowY, finalWidth
-			_, printed := printWithStyle(screen, cell.Text, x+columnX+1, y+rowY, finalWidth, cell.Align, tcell.StyleDefault.Foreground(cell.Color)|tcell.Style(cell.Attributes))
-			if TaggedStringWidth(cell.Text)-printed > 0 && printed > 0 {
+			text := t.cellText(row, column, cell)
+			_, printed := printWithStyle(screen, text, x+columnX+1, y+rowY, finalWidth, cell.Align, tcell.StyleDefault.Foreground(cell.Color)|tcell.Style(cell.Attributes))
+			if TaggedStringWidth(text)-printed > 0 && printed > 0 {
 				_, _, style, _ := screen.GetContent(x+columnX+1+finalWidth-1, y+rowY)
 				printWithStyle(screen, string(SemigraphicsHorizontalEllipsis), x+columnX+1+finalWidth-1, y+rowY, 1, AlignLeft, style)
 			}
@@ -846,6 +1192,10 @@ ColumnLoop:
 // InputHandler returns the handler for this primitive.
 func (t *Table) InputHandler() func(event *tcell.EventKey, setFocus func(p Primitive)) {
 	return t.WrapInputHandler(func(event *tcell.EventKey, setFocus func(p Primitive)) {
+		if t.editingFilter >= 0 {
+			t.editFilter(event)
+			return
+		}
 		key := event.Key()
 		if (!t.rowsSelectable && !t.columnsSelectable && key == tcell.KeyEnter) ||
 			key == tcell.KeyEscape ||
@@ -856,6 +1206,7 @@ func (t *Table) InputHandler() func(event *tcell.EventKey, setFocus func(p Primi
 			}
 			return
 		}
+		t.clampToSelection = true
 		// Movement functions.
 		previouslySelectedRow, previouslySelectedColumn := t.selectedRow, t.selectedColumn
 		var (
@@ -1006,6 +1357,18 @@ func (t *Table) InputHandler() func(event *tcell.EventKey, setFocus func(p Primi
 				left()
 			case 'l':
 				right()
+			case 's':
+				if t.sortable {
+					t.sortNext()
+				}
+			case 'S':
+				if t.sortable && t.sortColumn >= 0 {
+					t.SortByColumn(t.sortColumn, !t.sortAscending)
+				}
+			case '/':
+				if t.filterable {
+					t.editingFilter = t.filterColumn()
+				}
 			}
 		case tcell.KeyHome:
 			home()
@@ -1036,3 +1399,110 @@ func (t *Table) InputHandler() func(event *tcell.EventKey, setFocus func(p Primi
 		}
 	})
 }
+// cellText returns the text drawn for a cell. The header cell of the sort
+// column shows the sort order.
+func (t *Table) cellText(row, column int, cell *TableCell) string {
+	if column != t.sortColumn || row != t.fixedRows-1 {
+		return cell.Text
+	}
+	if t.sortAscending {
+		return cell.Text + " ▲"
+	}
+	return cell.Text + " ▼"
+}
+// cellAt returns the row and column of the cell drawn at the given position
+// the last time the table was drawn. The row is -1 if no row was drawn there,
+// and the column is -1 if no column was drawn there.
+func (t *Table) cellAt(x, y int) (row, column int) {
+	row, column = -1, -1
+	for _, drawnRow := range t.drawnRows {
+		for _, drawnColumn := range t.drawnColumns {
+			if drawnRow >= len(t.cells) || drawnColumn >= len(t.cells[drawnRow]) {
+				continue
+			}
+			cell := t.cells[drawnRow][drawnColumn]
+			if cell == nil || cell.y != y {
+				continue
+			}
+			row = drawnRow
+			// The separator to the right of a cell belongs to it.
+			if x >= cell.x && x <= cell.x+cell.width {
+				return row, drawnColumn
+			}
+		}
+		if row >= 0 {
+			return
+		}
+	}
+	return
+}
+// MouseHandler returns the mouse handler for this primitive.
+func (t *Table) MouseHandler() func(action MouseAction, event *tcell.EventMouse, setFocus func(p Primitive)) (consumed bool, capture Primitive) {
+	return t.WrapMouseHandler(func(action MouseAction, event *tcell.EventMouse, setFocus func(p Primitive)) (consumed bool, capture Primitive) {
+		x, y := event.Position()
+		if !t.InRect(x, y) {
+			return false, nil
+		}
+		// Process mouse event.
+		switch action {
+		case MouseLeftDown:
+			setFocus(t)
+			consumed = true
+		case MouseLeftClick, MouseLeftDoubleClick:
+			// A click selects the cell/row/column below the mouse, and a click
+			// on the selection triggers the "selected" handler.
+			setFocus(t)
+			consumed = true
+			// A click on a header cell sorts by its column.
+			if row, column := t.cellAt(x, y); t.sortable && row >= 0 && row == t.fixedRows-1 && column >= 0 {
+				if column == t.sortColumn {
+					t.SortByColumn(column, !t.sortAscending)
+				} else {
+					t.SortByColumn(column, true)
+				}
+				break
+			}
+			if !t.rowsSelectable && !t.columnsSelectable {
+				break
+			}
+			row, column := t.cellAt(x, y)
+			if row < 0 || column < 0 && t.columnsSelectable {
+				break
+			}
+			if column >= 0 && t.cells[row][column] != nil && t.cells[row][column].NotSelectable {
+				break
+			}
+			previouslySelectedRow, previouslySelectedColumn := t.selectedRow, t.selectedColumn
+			if t.rowsSelectable {
+				t.selectedRow = row
+			}
+			if t.columnsSelectable {
+				t.selectedColumn = column
+			}
+			t.clampToSelection = true
+			if t.rowsSelectable && previouslySelectedRow != t.selectedRow ||
+				t.columnsSelectable && previouslySelectedColumn != t.selectedColumn {
+				if t.selectionChanged != nil {
+					t.selectionChanged(t.selectedRow, t.selectedColumn)
+				}
+			} else if t.selected != nil {
+				t.selected(t.selectedRow, t.selectedColumn)
+			}
+		// The wheel scrolls the table without moving the selection.
+		case MouseScrollUp:
+			t.trackEnd = false
+			t.rowOffset--
+			consumed = true
+		case MouseScrollDown:
+			t.rowOffset++
+			consumed = true
+		case MouseScrollLeft:
+			t.columnOffset--
+			consumed = true
+		case MouseScrollRight:
+			t.columnOffset++
+			consumed = true
+		}
+		return
+	})
+}
@@ -8,7 +8,6 @@ import (
 	"unicode/utf8"
 	"git.parallelcoin.io/dev/9/pkg/util/tcell"
 	colorful "github.com/lucasb-eyer/go-colorful"
-	runewidth "github.com/mattn/go-runewidth"
 )
 var (
 	openColorRegex  = regexp.MustCompile(`\[([a-zA-Z]*|#[0-9a-zA-Z]*)$`)
@@ -521,7 +520,7 @@ func (t *TextView) reindexBuffer(width int) {
 		str = strippedStr
 		if t.wrap && len(str) > 0 {
 			for len(str) > 0 {
-				extract := runewidth.Truncate(str, width, "")
+				extract := truncateWidth(str, width)
 				if t.wordWrap && len(extract) < len(str) {
 					// Add any spaces from the next line.
 					if spaces := spacePattern.FindStringIndex(str[len(extract):]); spaces != nil && spaces[0] == 0 {
@@ -888,3 +887,39 @@ func (t *TextView) InputHandler() func(event *tcell.EventKey, setFocus func(p Pr
 		}
 	})
 }
+// MouseHandler returns the mouse handler for this primitive.
+func (t *TextView) MouseHandler() func(action MouseAction, event *tcell.EventMouse, setFocus func(p Primitive)) (consumed bool, capture Primitive) {
+	return t.WrapMouseHandler(func(action MouseAction, event *tcell.EventMouse, setFocus func(p Primitive)) (consumed bool, capture Primitive) {
+		if !t.InRect(event.Position()) {
+			return false, nil
+		}
+		// Process mouse event.
+		switch action {
+		case MouseLeftClick:
+			setFocus(t)
+			consumed = true
+		case MouseScrollUp:
+			if t.scrollable {
+				t.trackEnd = false
+				t.lineOffset--
+				consumed = true
+			}
+		case MouseScrollDown:
+			if t.scrollable {
+				t.lineOffset++
+				consumed = true
+			}
+		case MouseScrollLeft:
+			if t.scrollable {
+				t.columnOffset--
+				consumed = true
+			}
+		case MouseScrollRight:
+			if t.scrollable {
+				t.columnOffset++
+				consumed = true
+			}
+		}
+		return
+	})
+}
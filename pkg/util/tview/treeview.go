@@ -11,6 +11,7 @@ const (
 	treeDown
 	treePageUp
 	treePageDown
+	treeParent
 )
 // TreeNode represents one node in a tree view.
 type TreeNode struct {
@@ -30,6 +31,10 @@ type TreeNode struct {
 	indent int
 	// An optional function which is called when the user selects this node.
 	selected func()
+	// An optional function which adds this node's child nodes the first time it
+	// is expanded, and whether it was called.
+	load   func(node *TreeNode)
+	loaded bool
 	// Temporary member variables.
 	parent    *TreeNode // The parent node (nil for the root).
 	level     int       // The hierarchy level (0 for the root, 1 for its children, and so on).
@@ -116,13 +121,57 @@ func (n *TreeNode) SetSelectedFunc(handler func()) *TreeNode {
 	n.selected = handler
 	return n
 }
+// SetLoadFunc sets a function which adds this node's child nodes the first
+// time the node is expanded, so large or expensive trees can be built as the
+// user browses them. The node starts out collapsed. Until it is expanded, it is
+// treated as having children, so it can be expanded from the keyboard.
+func (n *TreeNode) SetLoadFunc(loader func(node *TreeNode)) *TreeNode {
+	n.load = loader
+	n.loaded = false
+	n.expanded = false
+	return n
+}
+// IsLoaded returns whether this node's child nodes are known, which is true
+// unless a load function was set with SetLoadFunc() and the node was not yet
+// expanded.
+func (n *TreeNode) IsLoaded() bool {
+	return n.load == nil || n.loaded
+}
+// Reload discards this node's child nodes and collapses it, so they are loaded
+// again the next time it is expanded. It has no effect if no load function was
+// set.
+func (n *TreeNode) Reload() *TreeNode {
+	if n.load != nil {
+		n.children = nil
+		n.loaded = false
+		n.expanded = false
+	}
+	return n
+}
+// loadChildren calls the load function of this node if it was not yet called.
+func (n *TreeNode) loadChildren() {
+	if n.load != nil && !n.loaded {
+		n.loaded = true
+		n.load(n)
+	}
+}
+// hasChildren returns whether this node has or may load child nodes.
+func (n *TreeNode) hasChildren() bool {
+	return len(n.children) > 0 || !n.IsLoaded()
+}
 // SetExpanded sets whether or not this node's child nodes should be displayed.
+// Expanding a node with a load function loads its children if necessary.
 func (n *TreeNode) SetExpanded(expanded bool) *TreeNode {
+	if expanded {
+		n.loadChildren()
+	}
 	n.expanded = expanded
 	return n
 }
-// Expand makes the child nodes of this node appear.
+// Expand makes the child nodes of this node appear, loading them first if a
+// load function was set.
 func (n *TreeNode) Expand() *TreeNode {
+	n.loadChildren()
 	n.expanded = true
 	return n
 }
@@ -131,10 +180,12 @@ func (n *TreeNode) Collapse() *TreeNode {
 	n.expanded = false
 	return n
 }
-// ExpandAll expands this node and all descendent nodes.
+// ExpandAll expands this node and all descendent nodes. This loads the child
+// nodes of every node with a load function, so it should not be used on trees
+// loaded lazily that have no end.
 func (n *TreeNode) ExpandAll() *TreeNode {
 	n.Walk(func(node, parent *TreeNode) bool {
-		node.expanded = true
+		node.Expand()
 		return true
 	})
 	return n
@@ -142,7 +193,7 @@ func (n *TreeNode) ExpandAll() *TreeNode {
 // CollapseAll collapses this node and all descendent nodes.
 func (n *TreeNode) CollapseAll() *TreeNode {
 	n.Walk(func(node, parent *TreeNode) bool {
-		n.expanded = false
+		node.expanded = false
 		return true
 	})
 	return n
@@ -184,8 +235,14 @@ func (n *TreeNode) SetIndent(indent int) *TreeNode {
 // Nodes can be selected by calling SetCurrentNode(). The user can navigate the
 // selection or the tree by using the following keys:
 //
-//   - j, down arrow, right arrow: Move (the selection) down by one node.
-//   - k, up arrow, left arrow: Move (the selection) up by one node.
+//   - j, down arrow, tab: Move (the selection) down by one node.
+//   - k, up arrow, backtab: Move (the selection) up by one node.
+//   - l, right arrow, +: Expand the selected node, or if it is already
+//     expanded, move down by one node.
+//   - h, left arrow, -: Collapse the selected node, or if it is already
+//     collapsed or has no children, move to its parent node.
+//   - space: Expand the selected node if it is collapsed and collapse it if it
+//     is expanded.
 //   - g, home: Move (the selection) to the top.
 //   - G, end: Move (the selection) to the bottom.
 //   - Ctrl-F, page down: Move (the selection) down by one page.
@@ -193,6 +250,11 @@ func (n *TreeNode) SetIndent(indent int) *TreeNode {
 //
 // Selected nodes can trigger the "selected" callback when the user hits Enter.
 //
+// Child nodes can be loaded when their parent is first expanded rather than
+// all up front, by setting a load function on the parent with
+// TreeNode.SetLoadFunc(). The "expanded" and "collapsed" callbacks tell the
+// application when the user opens and closes a node.
+//
 // The root node corresponds to level 0, its children correspond to level 1,
 // their children to level 2, and so on. Per default, the first level that is
 // displayed is 0, i.e. the root node. You can call SetTopLevel() to hide
@@ -219,6 +281,10 @@ type TreeView struct {
 	prefixes []string
 	// Vertical scroll offset.
 	offsetY int
+	// If set to true, the scroll offset is moved so the selection is visible
+	// the next time the tree is processed. It is not set when the tree is
+	// scrolled with the mouse wheel, which may move the selection out of view.
+	clampToSelection bool
 	// If set to true, all node texts will be aligned horizontally.
 	align bool
 	// If set to true, the tree structure is drawn using lines.
@@ -230,15 +296,20 @@ type TreeView struct {
 	changed func(node *TreeNode)
 	// An optional function which is called when a tree item was selected.
 	selected func(node *TreeNode)
+	// Optional functions which are called when the user expands or collapses a
+	// node.
+	expanded  func(node *TreeNode)
+	collapsed func(node *TreeNode)
 	// The visible nodes, top-down, as set by process().
 	nodes []*TreeNode
 }
 // NewTreeView returns a new tree view.
 func NewTreeView() *TreeView {
 	return &TreeView{
-		Box:           NewBox(),
-		graphics:      true,
-		graphicsColor: Styles.GraphicsColor,
+		Box:              NewBox(),
+		graphics:         true,
+		graphicsColor:    Styles.GraphicsColor,
+		clampToSelection: true,
 	}
 }
 // SetRoot sets the root node of the tree.
@@ -258,6 +329,7 @@ func (t *TreeView) GetRoot() *TreeNode {
 // This function does NOT trigger the "changed" callback.
 func (t *TreeView) SetCurrentNode(node *TreeNode) *TreeView {
 	t.currentNode = node
+	t.clampToSelection = true
 	return t
 }
 // GetCurrentNode returns the currently selected node or nil of no node is
@@ -315,6 +387,34 @@ func (t *TreeView) SetSelectedFunc(handler func(node *TreeNode)) *TreeView {
 	t.selected = handler
 	return t
 }
+// SetExpandedFunc sets the function which is called when the user expands a
+// node, after its child nodes were loaded.
+func (t *TreeView) SetExpandedFunc(handler func(node *TreeNode)) *TreeView {
+	t.expanded = handler
+	return t
+}
+// SetCollapsedFunc sets the function which is called when the user collapses a
+// node.
+func (t *TreeView) SetCollapsedFunc(handler func(node *TreeNode)) *TreeView {
+	t.collapsed = handler
+	return t
+}
+// expand expands the passed node on behalf of the user and triggers the
+// "expanded" callback.
+func (t *TreeView) expand(node *TreeNode) {
+	node.Expand()
+	if t.expanded != nil {
+		t.expanded(node)
+	}
+}
+// collapse collapses the passed node on behalf of the user and triggers the
+// "collapsed" callback.
+func (t *TreeView) collapse(node *TreeNode) {
+	node.Collapse()
+	if t.collapsed != nil {
+		t.collapsed(node)
+	}
+}
 // process builds the visible tree, populates the "nodes" slice, and processes
 // pending selection actions.
 func (t *TreeView) process() {
@@ -412,7 +512,7 @@ func (t *TreeView) process() {
 				}
 			}
 			newSelectedIndex = selectedIndex
-		case treePageUp:
+		case treePageDown:
 			if newSelectedIndex+height < len(t.nodes) {
 				newSelectedIndex += height
 			} else {
@@ -424,7 +524,7 @@ func (t *TreeView) process() {
 				}
 			}
 			newSelectedIndex = selectedIndex
-		case treePageDown:
+		case treePageUp:
 			if newSelectedIndex >= height {
 				newSelectedIndex -= height
 			} else {
@@ -436,6 +536,18 @@ func (t *TreeView) process() {
 				}
 			}
 			newSelectedIndex = selectedIndex
+		case treeParent:
+			for parent := t.nodes[selectedIndex].parent; parent != nil && parent.level >= t.topLevel; parent = parent.parent {
+				if !parent.selectable {
+					continue
+				}
+				for index, node := range t.nodes {
+					if node == parent {
+						newSelectedIndex = index
+						break MovementSwitch
+					}
+				}
+			}
 		}
 		t.currentNode = t.nodes[newSelectedIndex]
 		if newSelectedIndex != selectedIndex {
@@ -446,11 +558,13 @@ func (t *TreeView) process() {
 		}
 		selectedIndex = newSelectedIndex
 		// Move selection into viewport.
-		if selectedIndex-t.offsetY >= height {
-			t.offsetY = selectedIndex - height + 1
-		}
-		if selectedIndex < t.offsetY {
-			t.offsetY = selectedIndex
+		if t.clampToSelection {
+			if selectedIndex-t.offsetY >= height {
+				t.offsetY = selectedIndex - height + 1
+			}
+			if selectedIndex < t.offsetY {
+				t.offsetY = selectedIndex
+			}
 		}
 	} else {
 		// If selection is not visible or selectable, select the first candidate.
@@ -488,6 +602,8 @@ func (t *TreeView) Draw(screen tcell.Screen) {
 		t.offsetY--
 	case treeDown:
 		t.offsetY++
+	case treeParent:
+		// The selection was moved to the parent and brought into view.
 	case treeHome:
 		t.offsetY = 0
 	case treeEnd:
@@ -498,6 +614,7 @@ func (t *TreeView) Draw(screen tcell.Screen) {
 		t.offsetY += height
 	}
 	t.movement = treeNone
+	t.clampToSelection = false
 	// Fix invalid offsets.
 	if t.offsetY >= len(t.nodes)-height {
 		t.offsetY = len(t.nodes) - height
@@ -574,11 +691,16 @@ func (t *TreeView) InputHandler() func(event *tcell.EventKey, setFocus func(p Pr
 	return t.WrapInputHandler(func(event *tcell.EventKey, setFocus func(p Primitive)) {
 		// Because the tree is flattened into a list only at drawing time, we also
 		// postpone the (selection) movement to drawing time.
+		t.clampToSelection = true
 		switch key := event.Key(); key {
-		case tcell.KeyTab, tcell.KeyDown, tcell.KeyRight:
+		case tcell.KeyTab, tcell.KeyDown:
 			t.movement = treeDown
-		case tcell.KeyBacktab, tcell.KeyUp, tcell.KeyLeft:
+		case tcell.KeyBacktab, tcell.KeyUp:
 			t.movement = treeUp
+		case tcell.KeyRight:
+			t.expandOrDown()
+		case tcell.KeyLeft:
+			t.collapseOrParent()
 		case tcell.KeyHome:
 			t.movement = treeHome
 		case tcell.KeyEnd:
@@ -597,6 +719,18 @@ func (t *TreeView) InputHandler() func(event *tcell.EventKey, setFocus func(p Pr
 				t.movement = treeDown
 			case 'k':
 				t.movement = treeUp
+			case 'l', '+':
+				t.expandOrDown()
+			case 'h', '-':
+				t.collapseOrParent()
+			case ' ':
+				if node := t.currentNode; node != nil && node.hasChildren() {
+					if node.expanded {
+						t.collapse(node)
+					} else {
+						t.expand(node)
+					}
+				}
 			}
 		case tcell.KeyEnter:
 			if t.currentNode != nil {
@@ -611,3 +745,84 @@ func (t *TreeView) InputHandler() func(event *tcell.EventKey, setFocus func(p Pr
 		t.process()
 	})
 }
+// expandOrDown expands the selected node if it is collapsed and has or may load
+// children, and otherwise moves the selection down.
+func (t *TreeView) expandOrDown() {
+	if node := t.currentNode; node != nil && !node.expanded && node.hasChildren() {
+		t.expand(node)
+		return
+	}
+	t.movement = treeDown
+}
+// collapseOrParent collapses the selected node if it is expanded and has
+// children, and otherwise moves the selection to its parent.
+func (t *TreeView) collapseOrParent() {
+	if node := t.currentNode; node != nil && node.expanded && len(node.children) > 0 {
+		t.collapse(node)
+		return
+	}
+	t.movement = treeParent
+}
+// MouseHandler returns the mouse handler for this primitive.
+func (t *TreeView) MouseHandler() func(action MouseAction, event *tcell.EventMouse, setFocus func(p Primitive)) (consumed bool, capture Primitive) {
+	return t.WrapMouseHandler(func(action MouseAction, event *tcell.EventMouse, setFocus func(p Primitive)) (consumed bool, capture Primitive) {
+		x, y := event.Position()
+		if !t.InRect(x, y) {
+			return false, nil
+		}
+		// Process mouse event.
+		switch action {
+		case MouseLeftDown:
+			setFocus(t)
+			consumed = true
+		case MouseLeftClick, MouseLeftDoubleClick:
+			// A click on the graphics of a node expands or collapses it, a
+			// click on its text selects it, and a click on the selected node
+			// triggers the "selected" callbacks.
+			setFocus(t)
+			consumed = true
+			if t.root == nil {
+				break
+			}
+			t.process()
+			rectX, rectY, _, _ := t.GetInnerRect()
+			index := t.offsetY + y - rectY
+			if index < 0 || index >= len(t.nodes) {
+				break
+			}
+			node := t.nodes[index]
+			if x-rectX < node.textX {
+				if node.hasChildren() {
+					if node.expanded {
+						t.collapse(node)
+					} else {
+						t.expand(node)
+					}
+				}
+			} else if node.selectable {
+				if node != t.currentNode {
+					t.currentNode = node
+					if t.changed != nil {
+						t.changed(node)
+					}
+				} else {
+					if t.selected != nil {
+						t.selected(node)
+					}
+					if node.selected != nil {
+						node.selected()
+					}
+				}
+			}
+			t.process()
+		// The wheel scrolls the tree without moving the selection.
+		case MouseScrollUp:
+			t.offsetY--
+			consumed = true
+		case MouseScrollDown:
+			t.offsetY++
+			consumed = true
+		}
+		return
+	})
+}
@@ -5,7 +5,6 @@ import (
 	"sort"
 	"strconv"
 	"git.parallelcoin.io/dev/9/pkg/util/tcell"
-	runewidth "github.com/mattn/go-runewidth"
 	"github.com/rivo/uniseg"
 )
 // Text alignment within a box.
@@ -43,8 +42,6 @@ var (
 )
 // Package initialization.
 func init() {
-	// We'll use zero width joiners.
-	runewidth.ZeroWidthJoiner = true
 	// Initialize the predefined input field handlers.
 	InputFieldInteger = func(text string, ch rune) bool {
 		if text == "-" {
@@ -379,17 +376,34 @@ func TaggedStringWidth(text string) int {
 func stringWidth(text string) (width int) {
 	g := uniseg.NewGraphemes(text)
 	for g.Next() {
-		var chWidth int
-		for _, r := range g.Runes() {
-			chWidth = runewidth.RuneWidth(r)
-			if chWidth > 0 {
-				break // Our best guess at this point is to use the width of the first non-zero-width rune.
-			}
-		}
-		width += chWidth
+		width += clusterWidth(g.Runes())
 	}
 	return
 }
+// clusterWidth returns the number of cells a grapheme cluster takes up. This is
+// the width tcell gives the cell it is drawn into, so that CJK characters,
+// emoji sequences and flags are measured the same way they are drawn.
+func clusterWidth(runes []rune) int {
+	if len(runes) == 0 {
+		return 0
+	}
+	return tcell.ClusterWidth(runes[0], runes[1:])
+}
+// truncateWidth returns the longest start of the text which is at most the
+// given number of cells wide, without splitting grapheme clusters. At least
+// one cluster is returned for a non-empty text, even if it is wider, so that
+// callers splitting a text into lines always make progress.
+func truncateWidth(text string, width int) string {
+	end := 0
+	iterateString(text, func(main rune, comb []rune, textPos, textWidth, screenPos, screenWidth int) bool {
+		if screenPos+screenWidth > width && end > 0 {
+			return true
+		}
+		end = textPos + textWidth
+		return false
+	})
+	return text[:end]
+}
 // WordWrap splits a text such that each resulting line does not exceed the
 // given screen width. Possible split points are after any punctuation or
 // whitespace. Whitespace after split points will be dropped.
@@ -496,7 +510,7 @@ func iterateString(text string, callback func(main rune, comb []rune, textPos, t
 	for gr.Next() {
 		r := gr.Runes()
 		from, to := gr.Positions()
-		width := stringWidth(gr.Str())
+		width := clusterWidth(r)
 		var comb []rune
 		if len(r) > 1 {
 			comb = r[1:]
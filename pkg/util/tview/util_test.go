@@ -0,0 +1,140 @@
+package tview
+import (
+	"testing"
+	"git.parallelcoin.io/dev/9/pkg/util/tcell"
+)
+// newTestScreen returns a simulation screen of the given size.
+func newTestScreen(t *testing.T, width, height int) tcell.SimulationScreen {
+	screen := tcell.NewSimulationScreen("UTF-8")
+	if err := screen.Init(); err != nil {
+		t.Fatal(err)
+	}
+	screen.SetSize(width, height)
+	return screen
+}
+// row returns the runes drawn in a row of the screen, skipping the second
+// cell of wide characters.
+func row(screen tcell.Screen, y int) string {
+	width, _ := screen.Size()
+	var text []rune
+	for x := 0; x < width; {
+		main, comb, _, w := screen.GetContent(x, y)
+		text = append(append(text, main), comb...)
+		x += w
+	}
+	return string(text)
+}
+func TestStringWidth(t *testing.T) {
+	for _, test := range []struct {
+		text  string
+		width int
+	}{
+		{"abc", 3},
+		{"漢字", 4},
+		{"한국어", 6},
+		{"e\u0301", 1},
+		{"😀a", 3},
+		{"👩\u200d👩\u200d👧", 2},
+		{"❤\ufe0f", 2},
+		{"🇩🇪🇫🇷", 4},
+		{"1\ufe0f\u20e3", 2},
+	} {
+		if w := stringWidth(test.text); w != test.width {
+			t.Errorf("%q is %d cells wide, want %d", test.text, w, test.width)
+		}
+	}
+	if w := TaggedStringWidth("[red]漢[-]字"); w != 4 {
+		t.Errorf("tagged text is %d cells wide, want 4", w)
+	}
+}
+func TestTruncateWidth(t *testing.T) {
+	for _, test := range []struct {
+		text  string
+		width int
+		want  string
+	}{
+		{"abcdef", 3, "abc"},
+		{"漢字漢字", 5, "漢字"},
+		{"漢字", 1, "漢"},
+		{"👩\u200d👩\u200d👧x", 2, "👩\u200d👩\u200d👧"},
+		{"🇩🇪🇫🇷", 3, "🇩🇪"},
+		{"", 3, ""},
+	} {
+		if got := truncateWidth(test.text, test.width); got != test.want {
+			t.Errorf("truncating %q to %d gave %q, want %q", test.text, test.width, got, test.want)
+		}
+	}
+}
+func TestPrintWide(t *testing.T) {
+	screen := newTestScreen(t, 10, 1)
+	_, width := Print(screen, "漢🇩🇪a", 0, 0, 10, AlignLeft, tcell.ColorWhite)
+	if width != 5 {
+		t.Errorf("printed %d cells, want 5", width)
+	}
+	if main, _, _, _ := screen.GetContent(4, 0); main != 'a' {
+		t.Errorf("a was drawn at the wrong place: %q", row(screen, 0))
+	}
+	if main, comb, _, w := screen.GetContent(2, 0); main != '🇩' || len(comb) != 1 || w != 2 {
+		t.Errorf("flag drawn as %q %q %d", main, comb, w)
+	}
+}
+func TestTableWideColumns(t *testing.T) {
+	screen := newTestScreen(t, 20, 2)
+	table := NewTable()
+	table.SetCellSimple(0, 0, "漢字")
+	table.SetCellSimple(0, 1, "x")
+	table.SetCellSimple(1, 0, "❤\ufe0f")
+	table.SetCellSimple(1, 1, "y")
+	table.SetRect(0, 0, 20, 2)
+	table.Draw(screen)
+	// the first column is as wide as its widest cell, four cells
+	for y, want := range []rune{'x', 'y'} {
+		if main, _, _, _ := screen.GetContent(5, y); main != want {
+			t.Errorf("row %d: second column is misaligned: %q", y, row(screen, y))
+		}
+	}
+}
+func TestTextViewWrapWide(t *testing.T) {
+	screen := newTestScreen(t, 5, 3)
+	view := NewTextView().SetWrap(true).SetWordWrap(false)
+	view.SetText("漢字漢字漢字")
+	view.SetRect(0, 0, 5, 3)
+	view.Draw(screen)
+	for y := 0; y < 3; y++ {
+		if got := row(screen, y); got != "漢字 " {
+			t.Errorf("line %d is %q", y, got)
+		}
+	}
+}
+func TestInputFieldWide(t *testing.T) {
+	screen := newTestScreen(t, 10, 1)
+	field := NewInputField().SetFieldWidth(10)
+	field.SetText("漢字")
+	field.SetRect(0, 0, 10, 1)
+	field.Focus(nil)
+	field.Draw(screen)
+	if x, _, visible := screen.GetCursor(); !visible || x != 4 {
+		t.Errorf("cursor is at %d, want 4", x)
+	}
+	// the mouse puts the cursor before the character clicked
+	field.moveCursorTo(3)
+	if field.cursorPos != len("漢") {
+		t.Errorf("clicking the second character put the cursor at byte %d", field.cursorPos)
+	}
+}
+func TestInputFieldMask(t *testing.T) {
+	field := NewInputField().SetMaskCharacter('•')
+	field.SetText("he\u0301llo")
+	text, pos := field.maskedText(len("he\u0301"))
+	if text != "•••••" {
+		t.Errorf("masked text is %q", text)
+	}
+	if pos != len("••") {
+		t.Errorf("masked cursor is at byte %d, want %d", pos, len("••"))
+	}
+	field.offset = 0
+	field.moveCursorTo(2)
+	if field.cursorPos != len("he\u0301") {
+		t.Errorf("clicking the third mask put the cursor at byte %d", field.cursorPos)
+	}
+}
@@ -0,0 +1,114 @@
+package tview
+import (
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+)
+// Validator checks the text of an input field. It returns nil if the text is
+// acceptable, otherwise an error whose message is displayed next to the field.
+type Validator func(text string) error
+// hostnameRegexp matches a DNS host name made of dot-separated labels.
+var hostnameRegexp = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]*[a-zA-Z0-9])?(\.[a-zA-Z0-9]([a-zA-Z0-9-]*[a-zA-Z0-9])?)*\.?$`)
+// ValidateRegexp returns a validator which accepts text matching the given
+// regular expression. Other text is rejected with the given message.
+func ValidateRegexp(re *regexp.Regexp, message string) Validator {
+	return func(text string) error {
+		if !re.MatchString(text) {
+			return errors.New(message)
+		}
+		return nil
+	}
+}
+// ValidateIntRange returns a validator which accepts integers between min and
+// max, inclusive. Surrounding white space is ignored.
+func ValidateIntRange(min, max int64) Validator {
+	return func(text string) error {
+		n, err := strconv.ParseInt(strings.TrimSpace(text), 10, 64)
+		if err != nil {
+			return errors.New("not a whole number")
+		}
+		if n < min || n > max {
+			return fmt.Errorf("must be from %d to %d", min, max)
+		}
+		return nil
+	}
+}
+// ValidateFloatRange returns a validator which accepts decimal numbers between
+// min and max, inclusive. Surrounding white space is ignored.
+func ValidateFloatRange(min, max float64) Validator {
+	return func(text string) error {
+		f, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+		if err != nil {
+			return errors.New("not a number")
+		}
+		if f < min || f > max {
+			return fmt.Errorf("must be from %g to %g", min, max)
+		}
+		return nil
+	}
+}
+// ValidatePort returns a validator which accepts TCP/UDP port numbers, that
+// is, integers from 1 to 65535.
+func ValidatePort() Validator {
+	return func(text string) error {
+		n, err := strconv.ParseUint(strings.TrimSpace(text), 10, 16)
+		if err != nil || n == 0 {
+			return errors.New("not a port (1-65535)")
+		}
+		return nil
+	}
+}
+// ValidateAddress returns a validator which accepts network addresses of the
+// form "host:port", where host is a host name, an IPv4 address or a bracketed
+// IPv6 address. An empty host (":port") stands for all interfaces. If
+// requirePort is false, a bare host without a port is accepted as well.
+func ValidateAddress(requirePort bool) Validator {
+	return func(text string) error {
+		text = strings.TrimSpace(text)
+		host, port, err := net.SplitHostPort(text)
+		if err != nil {
+			if requirePort {
+				return errors.New("expected host:port")
+			}
+			host, port = strings.TrimSuffix(strings.TrimPrefix(text, "["), "]"), ""
+			if host == "" {
+				return errors.New("missing host")
+			}
+		}
+		if port != "" {
+			if err := ValidatePort()(port); err != nil {
+				return err
+			}
+		}
+		if host != "" && net.ParseIP(host) == nil && !hostnameRegexp.MatchString(host) {
+			return errors.New("not a valid host")
+		}
+		return nil
+	}
+}
+// ValidateOptional returns a validator which accepts empty text and otherwise
+// defers to the given validator. This is useful for fields which may be left
+// blank.
+func ValidateOptional(validator Validator) Validator {
+	return func(text string) error {
+		if strings.TrimSpace(text) == "" {
+			return nil
+		}
+		return validator(text)
+	}
+}
+// ValidateAll returns a validator which runs all given validators in order and
+// returns the first error encountered.
+func ValidateAll(validators ...Validator) Validator {
+	return func(text string) error {
+		for _, validator := range validators {
+			if err := validator(text); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
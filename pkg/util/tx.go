@@ -1,6 +1,7 @@
 package util
 import (
 	"bytes"
+	"fmt"
 	"io"
 	chainhash "git.parallelcoin.io/dev/9/pkg/chain/hash"
 	"git.parallelcoin.io/dev/9/pkg/chain/wire"
@@ -8,26 +9,103 @@ import (
 // TxIndexUnknown is the value returned for a transaction index that is unknown. This is typically because the transaction has not been inserted into a block yet.
 const TxIndexUnknown = -1
 // Tx defines a bitcoin transaction that provides easier and more efficient manipulation of raw transactions.  It also memoizes the hash for the transaction on its first access so subsequent accesses don't have to repeat the relatively expensive hashing operations.
+// A lazily parsed transaction, made by NewTxFromBytesLazy or taken from a lazily parsed block, keeps its serialized bytes and only deserializes them into a MsgTx when MsgTx is first called. Until then its hashes and scripts come straight from the serialized bytes, and once it is deserialized it drops them, as the MsgTx handed out may be modified.
+// The hashes of a transaction are memoized on their first access, so its MsgTx must not be modified after they have been used.
 type Tx struct {
-	msgTx         *wire.MsgTx     // Underlying MsgTx
+	msgTx         *wire.MsgTx     // Underlying MsgTx, nil until needed for a lazily parsed transaction
+	serializedTx  []byte          // Serialized bytes for the transaction
+	layout        *txLayout       // Where the parts of serializedTx are, for a lazily parsed transaction
 	txHash        *chainhash.Hash // Cached transaction hash
 	txHashWitness *chainhash.Hash // Cached transaction witness hash
 	txHasWitness  *bool           // If the transaction has witness data
 	txIndex       int             // Position within a block or TxIndexUnknown
+	parseErr      error           // Error deserializing the serialized bytes of a lazily parsed transaction
 }
-// MsgTx returns the underlying wire.MsgTx for the transaction.
+// Parse deserializes the serialized bytes of a lazily parsed transaction into its MsgTx if that has not been done yet, returning the error deserializing them if they do not.  The bytes are scanned when the transaction is made, which checks everything deserializing them does, so this only fails if they were modified since.
+func (t *Tx) Parse() error {
+	if t.msgTx != nil {
+		return nil
+	}
+	if t.parseErr != nil {
+		return t.parseErr
+	}
+	var msgTx wire.MsgTx
+	if err := msgTx.Deserialize(bytes.NewReader(t.serializedTx)); err != nil {
+		t.parseErr = err
+		return err
+	}
+	t.setMsgTx(&msgTx)
+	return nil
+}
+// setMsgTx sets the deserialized MsgTx of a lazily parsed transaction, dropping the serialized bytes and their layout, which no longer match the MsgTx once it is modified.
+func (t *Tx) setMsgTx(msgTx *wire.MsgTx) {
+	t.msgTx = msgTx
+	t.serializedTx = nil
+	t.layout = nil
+}
+// MsgTx returns the underlying wire.MsgTx for the transaction.  For a lazily parsed transaction whose serialized bytes do not deserialize, which Parse reports, it returns an empty transaction, which fails validation.
 func (t *Tx) MsgTx() *wire.MsgTx {
+	if err := t.Parse(); err != nil {
+		return &wire.MsgTx{}
+	}
 	// Return the cached transaction.
 	return t.msgTx
 }
+// Bytes returns the serialized bytes for the transaction.  This is equivalent to calling Serialize on the underlying wire.MsgTx, however a lazily parsed transaction returns its serialized bytes without serializing it again until it has been deserialized.  The result is not cached otherwise, as the MsgTx may have been modified.
+func (t *Tx) Bytes() ([]byte, error) {
+	// Return the serialized bytes of a transaction that has not been deserialized.
+	if t.msgTx == nil {
+		return t.serializedTx, nil
+	}
+	// Serialize the MsgTx.
+	w := bytes.NewBuffer(make([]byte, 0, t.msgTx.SerializeSize()))
+	err := t.msgTx.Serialize(w)
+	if err != nil {
+		return nil, err
+	}
+	return w.Bytes(), nil
+}
+// SignatureScript returns the signature script of the input at the specified index.  For a lazily parsed transaction it is a slice of the serialized bytes, which must not be modified.
+func (t *Tx) SignatureScript(index int) ([]byte, error) {
+	if t.msgTx == nil {
+		if index < 0 || index >= len(t.layout.sigScripts) {
+			return nil, OutOfRangeError(fmt.Sprintf("input index %d is out of range - max %d", index, len(t.layout.sigScripts)-1))
+		}
+		loc := t.layout.sigScripts[index]
+		return t.serializedTx[loc[0]:loc[1]:loc[1]], nil
+	}
+	if index < 0 || index >= len(t.msgTx.TxIn) {
+		return nil, OutOfRangeError(fmt.Sprintf("input index %d is out of range - max %d", index, len(t.msgTx.TxIn)-1))
+	}
+	return t.msgTx.TxIn[index].SignatureScript, nil
+}
+// PkScript returns the public key script of the output at the specified index.  For a lazily parsed transaction it is a slice of the serialized bytes, which must not be modified.
+func (t *Tx) PkScript(index int) ([]byte, error) {
+	if t.msgTx == nil {
+		if index < 0 || index >= len(t.layout.pkScripts) {
+			return nil, OutOfRangeError(fmt.Sprintf("output index %d is out of range - max %d", index, len(t.layout.pkScripts)-1))
+		}
+		loc := t.layout.pkScripts[index]
+		return t.serializedTx[loc[0]:loc[1]:loc[1]], nil
+	}
+	if index < 0 || index >= len(t.msgTx.TxOut) {
+		return nil, OutOfRangeError(fmt.Sprintf("output index %d is out of range - max %d", index, len(t.msgTx.TxOut)-1))
+	}
+	return t.msgTx.TxOut[index].PkScript, nil
+}
 // Hash returns the hash of the transaction.  This is equivalent to calling TxHash on the underlying wire.MsgTx, however it caches the result so subsequent calls are more efficient.
 func (t *Tx) Hash() *chainhash.Hash {
 	// Return the cached hash if it has already been generated.
 	if t.txHash != nil {
 		return t.txHash
 	}
-	// Cache the hash and return it.
-	hash := t.msgTx.TxHash()
+	// Cache the hash and return it, hashing the serialized bytes when the transaction has not been deserialized.
+	var hash chainhash.Hash
+	if t.msgTx == nil {
+		hash = t.layout.txHash(t.serializedTx)
+	} else {
+		hash = t.msgTx.TxHash()
+	}
 	t.txHash = &hash
 	return &hash
 }
@@ -37,20 +115,73 @@ func (t *Tx) WitnessHash() *chainhash.Hash {
 	if t.txHashWitness != nil {
 		return t.txHashWitness
 	}
-	// Cache the hash and return it.
-	hash := t.msgTx.WitnessHash()
+	// Cache the hash and return it, hashing the serialized bytes when the transaction has not been deserialized.
+	var hash chainhash.Hash
+	if t.msgTx == nil {
+		hash = chainhash.DoubleHashH(t.serializedTx)
+	} else {
+		hash = t.msgTx.WitnessHash()
+	}
 	t.txHashWitness = &hash
 	return &hash
 }
 // HasWitness returns false if none of the inputs within the transaction contain witness data, true false otherwise. This equivalent to calling HasWitness on the underlying wire.MsgTx, however it caches the result so subsequent calls are more efficient.
 func (t *Tx) HasWitness() bool {
-	if t.txHashWitness != nil {
+	if t.txHasWitness != nil {
 		return *t.txHasWitness
 	}
-	hasWitness := t.msgTx.HasWitness()
+	var hasWitness bool
+	if t.msgTx == nil {
+		hasWitness = t.layout.hasWitness
+	} else {
+		hasWitness = t.msgTx.HasWitness()
+	}
 	t.txHasWitness = &hasWitness
 	return hasWitness
 }
+// hashPreimage returns the serialization the hash of the transaction is of, without witness data, or with it if witness is set.  It is not cached, as it is only needed once for each hash.
+func (t *Tx) hashPreimage(witness bool) []byte {
+	if t.msgTx == nil {
+		b := t.serializedTx
+		if witness || !t.layout.witness {
+			return b
+		}
+		stripped := make([]byte, 0, 4+t.layout.body[1]-t.layout.body[0]+4)
+		stripped = append(stripped, b[:4]...)
+		stripped = append(stripped, b[t.layout.body[0]:t.layout.body[1]]...)
+		return append(stripped, b[len(b)-4:]...)
+	}
+	var w *bytes.Buffer
+	if witness {
+		w = bytes.NewBuffer(make([]byte, 0, t.msgTx.SerializeSize()))
+		_ = t.msgTx.Serialize(w)
+	} else {
+		w = bytes.NewBuffer(make([]byte, 0, t.msgTx.SerializeSizeStripped()))
+		_ = t.msgTx.SerializeNoWitness(w)
+	}
+	return w.Bytes()
+}
+// HashTxs works out the hashes of the transactions that don't have them cached yet, or their witness hashes if witness is set, all at once with chainhash.DoubleHashBatch, and caches them.  This is faster than getting them one by one from Hash or WitnessHash on CPUs with vector instructions.
+func HashTxs(
+	txs []*Tx, witness bool) {
+	var todo []*Tx
+	var preimages [][]byte
+	for _, t := range txs {
+		if (witness && t.txHashWitness == nil) || (!witness && t.txHash == nil) {
+			todo = append(todo, t)
+			preimages = append(preimages, t.hashPreimage(witness))
+		}
+	}
+	hashes := make([]chainhash.Hash, len(todo))
+	chainhash.DoubleHashBatch(hashes, preimages)
+	for i, t := range todo {
+		if witness {
+			t.txHashWitness = &hashes[i]
+		} else {
+			t.txHash = &hashes[i]
+		}
+	}
+}
 // Index returns the saved index of the transaction within a block.  This value will be TxIndexUnknown if it hasn't already explicitly been set.
 func (t *Tx) Index() int {
 	return t.txIndex
@@ -67,12 +198,34 @@ func NewTx(
 		txIndex: TxIndexUnknown,
 	}
 }
-// NewTxFromBytes returns a new instance of a bitcoin transaction given the serialized bytes.  See Tx.
+// NewTxFromBytes returns a new instance of a bitcoin transaction given the serialized bytes, which is lazily parsed if LazyParse is set.  See Tx.
 func NewTxFromBytes(
 	serializedTx []byte) (*Tx, error) {
+	if LazyParse {
+		return NewTxFromBytesLazy(serializedTx)
+	}
 	br := bytes.NewReader(serializedTx)
 	return NewTxFromReader(br)
 }
+// NewTxFromBytesLazy returns a new lazily parsed bitcoin transaction given the serialized bytes, which are checked but not deserialized until MsgTx is called.  The transaction keeps the bytes, so they must not be modified afterwards.  See Tx.
+func NewTxFromBytesLazy(
+	serializedTx []byte) (*Tx, error) {
+	s := scanner{b: serializedTx}
+	layout, err := s.tx()
+	if err != nil {
+		return nil, err
+	}
+	return newLazyTx(serializedTx[:s.pos:s.pos], layout), nil
+}
+// newLazyTx returns a lazily parsed transaction for the serialized bytes with the given layout.
+func newLazyTx(
+	serializedTx []byte, layout *txLayout) *Tx {
+	return &Tx{
+		serializedTx: serializedTx,
+		layout:       layout,
+		txIndex:      TxIndexUnknown,
+	}
+}
 // NewTxFromReader returns a new instance of a bitcoin transaction given a Reader to deserialize the transaction.  See Tx.
 func NewTxFromReader(
 	r io.Reader) (*Tx, error) {
@@ -5,6 +5,7 @@ import (
 	"reflect"
 	"testing"
 	chainhash "git.parallelcoin.io/dev/9/pkg/chain/hash"
+	"git.parallelcoin.io/dev/9/pkg/chain/wire"
 	"git.parallelcoin.io/dev/9/pkg/util"
 	"github.com/davecgh/go-spew/spew"
 )
@@ -63,6 +64,114 @@ func TestNewTxFromBytes(
 			spew.Sdump(msgTx), spew.Sdump(testTx))
 	}
 }
+// TestNewTxFromBytesLazy tests a lazily parsed Tx gives the same hashes, scripts and MsgTx as a deserialized one, with and without witness data.
+func TestNewTxFromBytesLazy(
+	t *testing.T) {
+	witnessTx := Block100000.Transactions[1].Copy()
+	witnessTx.TxIn[0].Witness = wire.TxWitness{{0x01, 0x02}, {}, {0x03}}
+	for i, testTx := range []*wire.MsgTx{Block100000.Transactions[1], witnessTx} {
+		var testTxBuf bytes.Buffer
+		if err := testTx.Serialize(&testTxBuf); err != nil {
+			t.Fatalf("Serialize: %v", err)
+		}
+		// Trailing bytes are not part of the transaction.
+		tx, err := util.NewTxFromBytesLazy(append(testTxBuf.Bytes(), 0xff))
+		if err != nil {
+			t.Fatalf("#%d NewTxFromBytesLazy: %v", i, err)
+		}
+		if b, _ := tx.Bytes(); !bytes.Equal(b, testTxBuf.Bytes()) {
+			t.Errorf("#%d Bytes: got %x, want %x", i, b, testTxBuf.Bytes())
+		}
+		if hash, want := tx.Hash(), testTx.TxHash(); !hash.IsEqual(&want) {
+			t.Errorf("#%d Hash: got %v, want %v", i, hash, want)
+		}
+		if hash, want := tx.WitnessHash(), testTx.WitnessHash(); !hash.IsEqual(&want) {
+			t.Errorf("#%d WitnessHash: got %v, want %v", i, hash, want)
+		}
+		if tx.HasWitness() != testTx.HasWitness() {
+			t.Errorf("#%d HasWitness: got %v", i, tx.HasWitness())
+		}
+		for j, txIn := range testTx.TxIn {
+			if script, err := tx.SignatureScript(j); err != nil || !bytes.Equal(script, txIn.SignatureScript) {
+				t.Errorf("#%d SignatureScript %d: got %x, %v", i, j, script, err)
+			}
+		}
+		for j, txOut := range testTx.TxOut {
+			if script, err := tx.PkScript(j); err != nil || !bytes.Equal(script, txOut.PkScript) {
+				t.Errorf("#%d PkScript %d: got %x, %v", i, j, script, err)
+			}
+		}
+		if _, err := tx.PkScript(len(testTx.TxOut)); err == nil {
+			t.Errorf("#%d PkScript: no error for an output out of range", i)
+		}
+		msgTx := tx.MsgTx()
+		if !reflect.DeepEqual(msgTx, testTx) {
+			t.Errorf("#%d MsgTx: mismatched MsgTx - got %v, want %v", i,
+				spew.Sdump(msgTx), spew.Sdump(testTx))
+		}
+		// Once deserialized, the bytes and scripts follow changes to the MsgTx.
+		msgTx.TxOut[0].PkScript = []byte{0x51}
+		var modifiedBuf bytes.Buffer
+		_ = msgTx.Serialize(&modifiedBuf)
+		if b, _ := tx.Bytes(); !bytes.Equal(b, modifiedBuf.Bytes()) {
+			t.Errorf("#%d Bytes: got %x after modifying the MsgTx, want %x", i, b, modifiedBuf.Bytes())
+		}
+		if script, _ := tx.PkScript(0); !bytes.Equal(script, []byte{0x51}) {
+			t.Errorf("#%d PkScript: got %x after modifying the MsgTx", i, script)
+		}
+		// Bytes modified after scanning are reported by Parse instead of panicking.
+		corrupt := append([]byte{}, testTxBuf.Bytes()...)
+		tx, err = util.NewTxFromBytesLazy(corrupt)
+		if err != nil {
+			t.Fatalf("#%d NewTxFromBytesLazy: %v", i, err)
+		}
+		// An input count of the largest 64 bit varint.
+		copy(corrupt[4:], bytes.Repeat([]byte{0xff}, 9))
+		if err := tx.Parse(); err == nil {
+			t.Errorf("#%d Parse: no error for modified bytes", i)
+		}
+		if msgTx := tx.MsgTx(); len(msgTx.TxIn) != 0 || len(msgTx.TxOut) != 0 {
+			t.Errorf("#%d MsgTx: got %v for modified bytes, want an empty transaction", i, spew.Sdump(msgTx))
+		}
+		// Every truncation fails to scan as it fails to deserialize.
+		for n := 0; n < testTxBuf.Len(); n++ {
+			if _, err := util.NewTxFromBytesLazy(testTxBuf.Bytes()[:n]); err == nil {
+				t.Errorf("#%d NewTxFromBytesLazy: no error for %d of %d bytes", i, n, testTxBuf.Len())
+				break
+			}
+		}
+	}
+}
+// TestHashTxs tests HashTxs caches the same hashes and witness hashes as Hash and WitnessHash give, for deserialized and lazily parsed transactions.
+func TestHashTxs(
+	t *testing.T) {
+	witnessTx := Block100000.Transactions[2].Copy()
+	witnessTx.TxIn[0].Witness = wire.TxWitness{{0x04, 0x05}}
+	var witnessTxBuf bytes.Buffer
+	if err := witnessTx.Serialize(&witnessTxBuf); err != nil {
+		t.Fatalf("Serialize: %v", err)
+	}
+	lazyTx, err := util.NewTxFromBytesLazy(witnessTxBuf.Bytes())
+	if err != nil {
+		t.Fatalf("NewTxFromBytesLazy: %v", err)
+	}
+	txs := []*util.Tx{lazyTx, util.NewTx(witnessTx)}
+	msgTxs := []*wire.MsgTx{witnessTx, witnessTx}
+	for _, msgTx := range Block100000.Transactions {
+		txs = append(txs, util.NewTx(msgTx))
+		msgTxs = append(msgTxs, msgTx)
+	}
+	util.HashTxs(txs, false)
+	util.HashTxs(txs, true)
+	for i, tx := range txs {
+		if hash, want := tx.Hash(), msgTxs[i].TxHash(); !hash.IsEqual(&want) {
+			t.Errorf("Hash of tx %d: got %v, want %v", i, hash, want)
+		}
+		if hash, want := tx.WitnessHash(), msgTxs[i].WitnessHash(); !hash.IsEqual(&want) {
+			t.Errorf("WitnessHash of tx %d: got %v, want %v", i, hash, want)
+		}
+	}
+}
 // TestTxErrors tests the error paths for the Tx API.
 func TestTxErrors(
 	t *testing.T) {
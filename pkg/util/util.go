@@ -35,8 +35,8 @@ func FileExists(filePath string) bool {
 }
 // CleanAndExpandPath expands environment variables and leading ~ in the passed path, cleans the result, and returns it.
 func CleanAndExpandPath(path, datadir string) string {
-	// Expand initial ~ to OS specific home directory.
-	homeDir := filepath.Dir(AppDataDir("9", false))
+	// Expand initial ~ to OS specific home directory, the one the data directory was in before XDG.
+	homeDir := filepath.Dir(legacyAppDataDir(runtime.GOOS, "9", false))
 	if strings.HasPrefix(path, "~") {
 		return strings.Replace(path, "~", homeDir, 1)
 	}
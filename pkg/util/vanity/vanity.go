@@ -0,0 +1,202 @@
+// Package vanity grinds keys on all processor cores until one has an address starting with a chosen prefix, and estimates how many keys that takes so progress can be shown. Prefixes starting with the human readable part of the network's segwit addresses and 1q are searched among version 0 pay to witness public key hash addresses, others among base58 pay to public key hash addresses. All keys are compressed.
+package vanity
+import (
+	"errors"
+	"math"
+	"math/big"
+	"strings"
+	"sync"
+	"sync/atomic"
+	chaincfg "git.parallelcoin.io/dev/9/pkg/chain/config"
+	"git.parallelcoin.io/dev/9/pkg/util"
+	ec "git.parallelcoin.io/dev/9/pkg/util/elliptic"
+)
+const (
+	base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+	bech32Charset  = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+	// bech32Chars is how many characters of a pay to witness public key hash address after the witness version encode the hash, the checksum following them
+	bech32Chars = 32
+)
+// ErrImpossible is the error for a prefix no address of the network starts with
+var ErrImpossible = errors.New("no address of this network starts with the prefix")
+// Match is a key whose address starts with the prefix searched for
+type Match struct {
+	WIF     *util.WIF
+	Address util.Address
+}
+// Normalize lowercases a bech32 prefix, which is case insensitive although addresses are written in lower case, and returns base58 prefixes as they are
+func Normalize(prefix string, params *chaincfg.Params) string {
+	if lower := strings.ToLower(prefix); SegWit(lower, params) {
+		return lower
+	}
+	return prefix
+}
+// Attempts is the number of keys expected to be tried before one has an address starting with the normalized prefix. It fails with ErrImpossible if no address of the network can start with it, such as a base58 prefix whose first character is not the one the network's addresses start with.
+func Attempts(prefix string, params *chaincfg.Params) (float64, error) {
+	if prefix == "" {
+		return 0, errors.New("the prefix is empty")
+	}
+	if SegWit(prefix, params) {
+		return segWitAttempts(prefix, params)
+	}
+	return base58Attempts(prefix, params.PubKeyHashAddrID)
+}
+// Search grinds random keys on threads goroutines until one has an address starting with the normalized prefix, returning nil if quit is closed first. The number of keys tried is added to tried as they are, for progress to be shown while it runs.
+func Search(prefix string, params *chaincfg.Params, threads int, quit <-chan struct{}, tried *uint64) (*Match, error) {
+	if _, err := Attempts(prefix, params); err != nil {
+		return nil, err
+	}
+	if threads < 1 {
+		threads = 1
+	}
+	segwit := SegWit(prefix, params)
+	done := make(chan struct{})
+	found := make(chan *Match, threads)
+	errs := make(chan error, threads)
+	var wg sync.WaitGroup
+	for i := 0; i < threads; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				m, err := try(prefix, params, segwit)
+				atomic.AddUint64(tried, 1)
+				switch {
+				case err != nil:
+					errs <- err
+					return
+				case m != nil:
+					found <- m
+					return
+				}
+			}
+		}()
+	}
+	defer wg.Wait()
+	defer close(done)
+	select {
+	case m := <-found:
+		return m, nil
+	case err := <-errs:
+		return nil, err
+	case <-quit:
+		return nil, nil
+	}
+}
+// try makes a key and returns it if its address starts with the prefix
+func try(prefix string, params *chaincfg.Params, segwit bool) (*Match, error) {
+	key, err := ec.NewPrivateKey(ec.S256())
+	if err != nil {
+		return nil, err
+	}
+	hash := util.Hash160(key.PubKey().SerializeCompressed())
+	var addr util.Address
+	if segwit {
+		addr, err = util.NewAddressWitnessPubKeyHash(hash, params)
+	} else {
+		addr, err = util.NewAddressPubKeyHash(hash, params)
+	}
+	if err != nil || !strings.HasPrefix(addr.EncodeAddress(), prefix) {
+		return nil, err
+	}
+	wif, err := util.NewWIF(key, params, true)
+	if err != nil {
+		return nil, err
+	}
+	return &Match{WIF: wif, Address: addr}, nil
+}
+// SegWit is true for prefixes searched among segwit addresses, those starting with the human readable part and separator of the network's bech32 addresses
+func SegWit(prefix string, params *chaincfg.Params) bool {
+	return params.Bech32HRPSegwit != "" && strings.HasPrefix(prefix, params.Bech32HRPSegwit+"1")
+}
+// segWitAttempts is 32 for each character following the witness version, as each is equally likely
+func segWitAttempts(prefix string, params *chaincfg.Params) (float64, error) {
+	rest := prefix[len(params.Bech32HRPSegwit)+1:]
+	if rest == "" {
+		return 1, nil
+	}
+	if rest[0] != bech32Charset[0] || len(rest)-1 > bech32Chars {
+		return 0, ErrImpossible
+	}
+	for _, c := range rest {
+		if !strings.ContainsRune(bech32Charset, c) {
+			return 0, errors.New("not a bech32 character: " + string(c))
+		}
+	}
+	return math.Pow(float64(len(bech32Charset)), float64(len(rest)-1)), nil
+}
+// base58Attempts counts the addresses starting with the prefix out of all those with the version byte. The address is the version followed by the 24 bytes of the hash and checksum read as one number, each leading zero byte being written as a 1, and as hashes are random every number from the version's is as likely.
+func base58Attempts(prefix string, version byte) (float64, error) {
+	for _, c := range prefix {
+		if !strings.ContainsRune(base58Alphabet, c) {
+			return 0, errors.New("not a base58 character: " + string(c))
+		}
+	}
+	one := big.NewInt(1)
+	lo := new(big.Int).Lsh(big.NewInt(int64(version)), 192)
+	hi := new(big.Int).Lsh(big.NewInt(int64(version)+1), 192)
+	hi.Sub(hi, one)
+	if version == 0 {
+		// the zero version is the leading 1, the rest very rarely having
+		// another zero byte to start with
+		if prefix[0] != base58Alphabet[0] {
+			return 0, ErrImpossible
+		}
+		prefix = prefix[1:]
+	}
+	value := new(big.Int)
+	for _, c := range prefix {
+		value.Mul(value, big.NewInt(58))
+		value.Add(value, big.NewInt(int64(strings.IndexRune(base58Alphabet, c))))
+	}
+	// the numbers of n digits starting with the prefix run from its value
+	// followed by zero digits to its value followed by the highest digits
+	count := new(big.Int)
+	digits := new(big.Int).Set(one)
+	for n := 1; digits.Cmp(hi) <= 0; n++ {
+		least := new(big.Int).Set(digits)
+		if n == 1 {
+			least.SetInt64(0)
+		}
+		most := new(big.Int).Mul(digits, big.NewInt(58))
+		most.Sub(most, one)
+		digits.Mul(digits, big.NewInt(58))
+		if len(prefix) > n {
+			continue
+		}
+		scale := new(big.Int).Exp(big.NewInt(58), big.NewInt(int64(n-len(prefix))), nil)
+		from := new(big.Int).Mul(value, scale)
+		to := new(big.Int).Add(from, scale)
+		to.Sub(to, one)
+		from = maxInt(from, maxInt(least, lo))
+		to = minInt(to, minInt(most, hi))
+		if from.Cmp(to) <= 0 {
+			count.Add(count, new(big.Int).Sub(to, from))
+			count.Add(count, one)
+		}
+	}
+	if count.Sign() == 0 {
+		return 0, ErrImpossible
+	}
+	all := new(big.Int).Sub(hi, lo)
+	all.Add(all, one)
+	attempts, _ := new(big.Rat).SetFrac(all, count).Float64()
+	return attempts, nil
+}
+func maxInt(a, b *big.Int) *big.Int {
+	if a.Cmp(b) > 0 {
+		return a
+	}
+	return b
+}
+func minInt(a, b *big.Int) *big.Int {
+	if a.Cmp(b) < 0 {
+		return a
+	}
+	return b
+}
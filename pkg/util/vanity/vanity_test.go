@@ -0,0 +1,55 @@
+package vanity
+import (
+	"math"
+	"strings"
+	"testing"
+	chaincfg "git.parallelcoin.io/dev/9/pkg/chain/config"
+)
+func TestAttempts(t *testing.T) {
+	for _, c := range []struct {
+		params   *chaincfg.Params
+		prefix   string
+		attempts float64
+		fails    bool
+	}{
+		// mainnet addresses all start with a followed by Q to o
+		{&chaincfg.MainNetParams, "a", 1, false},
+		{&chaincfg.MainNetParams, "aY", 23.33798591879242, false},
+		{&chaincfg.MainNetParams, "az", 0, true},
+		{&chaincfg.MainNetParams, "b", 0, true},
+		{&chaincfg.MainNetParams, "a0", 0, true},
+		{&chaincfg.MainNetParams, "bc1q", 1, false},
+		{&chaincfg.MainNetParams, "bc1qqp", 1024, false},
+		{&chaincfg.MainNetParams, "bc1p", 0, true},
+		{&chaincfg.MainNetParams, "bc1qb", 0, true},
+		{&chaincfg.RegressionNetParams, "1", 1, false},
+		{&chaincfg.RegressionNetParams, "12", 22.935606851227035, false},
+		{&chaincfg.RegressionNetParams, "a", 0, true},
+	} {
+		a, err := Attempts(c.prefix, c.params)
+		if (err != nil) != c.fails || math.Abs(a-c.attempts) > 1e-9*c.attempts {
+			t.Errorf("%s on %s takes %v attempts, %v, want %v", c.prefix, c.params.Name, a, err, c.attempts)
+		}
+	}
+}
+func TestSearch(t *testing.T) {
+	for _, prefix := range []string{"aY", Normalize("BC1QQ", &chaincfg.MainNetParams)} {
+		var tried uint64
+		m, err := Search(prefix, &chaincfg.MainNetParams, 2, nil, &tried)
+		if err != nil || m == nil {
+			t.Fatalf("search for %s failed: %v", prefix, err)
+		}
+		if !strings.HasPrefix(m.Address.EncodeAddress(), prefix) || tried == 0 {
+			t.Errorf("search for %s found %s after %d keys", prefix, m.Address.EncodeAddress(), tried)
+		}
+		if !m.WIF.IsForNet(&chaincfg.MainNetParams) || !m.WIF.CompressPubKey {
+			t.Errorf("search for %s found a key for another network", prefix)
+		}
+	}
+	quit := make(chan struct{})
+	close(quit)
+	var tried uint64
+	if m, err := Search("aYYYYYYYYY", &chaincfg.MainNetParams, 2, quit, &tried); m != nil || err != nil {
+		t.Errorf("stopped search returned %v %v", m, err)
+	}
+}
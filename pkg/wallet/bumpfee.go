@@ -0,0 +1,226 @@
+package wallet
+import (
+	"errors"
+	chainhash "git.parallelcoin.io/dev/9/pkg/chain/hash"
+	txauthor "git.parallelcoin.io/dev/9/pkg/chain/tx/author"
+	wtxmgr "git.parallelcoin.io/dev/9/pkg/chain/tx/mgr"
+	txrules "git.parallelcoin.io/dev/9/pkg/chain/tx/rules"
+	"git.parallelcoin.io/dev/9/pkg/chain/wire"
+	"git.parallelcoin.io/dev/9/pkg/util"
+	cl "git.parallelcoin.io/dev/9/pkg/util/cl"
+	walletdb "git.parallelcoin.io/dev/9/pkg/wallet/db"
+)
+// replaceableSequence is the sequence number of the inputs of transactions
+// that signal BIP125 replaceability.  It is the highest that signals it, so
+// the lock time of the transaction is still enforced.
+const replaceableSequence = wire.MaxTxInSequenceNum - 2
+var (
+	// ErrTxNotFound is returned by BumpFee when the wallet has no record
+	// of the transaction.
+	ErrTxNotFound = errors.New("transaction not found in the wallet")
+	// ErrTxMined is returned by BumpFee when the transaction has already
+	// been mined and can no longer be replaced.
+	ErrTxMined = errors.New("transaction has already been mined")
+	// ErrTxNotReplaceable is returned by BumpFee when the transaction does
+	// not signal BIP125 replaceability, so nodes would not accept a
+	// replacement for it.
+	ErrTxNotReplaceable = errors.New("transaction does not signal " +
+		"replaceability")
+	// ErrTxNotOwned is returned by BumpFee when the transaction spends
+	// outputs that are not the wallet's, which it can not sign for again.
+	ErrTxNotOwned = errors.New("transaction spends outputs that are not " +
+		"the wallet's")
+	// ErrTxHasDescendants is returned by BumpFee when unmined transactions
+	// of the wallet spend outputs of the transaction, as the replacement
+	// would invalidate them.
+	ErrTxHasDescendants = errors.New("transaction has descendants in the " +
+		"wallet")
+	// ErrNoChange is returned by BumpFee when the transaction does not
+	// have exactly one change output to take the higher fee from.
+	ErrNoChange = errors.New("transaction does not have a single change " +
+		"output")
+	// ErrFeeRateTooLow is returned by BumpFee when the requested fee rate
+	// is not higher than the one the transaction already pays.
+	ErrFeeRateTooLow = errors.New("fee rate is not higher than the fee " +
+		"rate of the transaction")
+	// ErrChangeTooSmall is returned by BumpFee when paying the higher fee
+	// would leave a change output that is dust.
+	ErrChangeTooSmall = errors.New("change output is too small to pay " +
+		"the higher fee")
+)
+// signalsReplacement returns whether a transaction signals BIP125
+// replaceability, which it does when any of its inputs has a sequence
+// number below the final two.
+func signalsReplacement(tx *wire.MsgTx) bool {
+	for _, txIn := range tx.TxIn {
+		if txIn.Sequence <= replaceableSequence {
+			return true
+		}
+	}
+	return false
+}
+// virtualSize returns the size of a transaction in virtual bytes, which is
+// its weight divided by four and rounded up.
+func virtualSize(tx *wire.MsgTx) int {
+	weight := tx.SerializeSizeStripped()*3 + tx.SerializeSize()
+	return (weight + 3) / 4
+}
+// bumpedFee returns the fee of a replacement of virtual size vsize for a
+// transaction paying oldFee at the fee rate feeSatPerKb.  BIP125 also has the
+// replacement pay for its own relay on top of the fee of the transaction it
+// replaces, so the fee is at least that.
+func bumpedFee(oldFee util.Amount, vsize int,
+	feeSatPerKb util.Amount) (util.Amount, error) {
+	if feeSatPerKb <= oldFee*1000/util.Amount(vsize) {
+		return 0, ErrFeeRateTooLow
+	}
+	fee := txrules.FeeForSerializeSize(feeSatPerKb, vsize)
+	minFee := oldFee + txrules.FeeForSerializeSize(
+		txrules.DefaultRelayFeePerKb, vsize)
+	if fee < minFee {
+		fee = minFee
+	}
+	return fee, nil
+}
+// BumpFee replaces an unmined transaction of the wallet that signals BIP125
+// replaceability with one that pays the fee rate feeSatPerKb, taking the
+// higher fee out of its change output.  When feeSatPerKb is zero the fee
+// rate of the transaction is raised by the default relay fee.  Every input
+// of the transaction must spend an output of the wallet, and the wallet must
+// be unlocked to sign the replacement.  Transactions that unmined
+// transactions of the wallet spend are not replaced, as that would
+// invalidate them.  The hash of the replacement is returned with the fees of
+// the replaced transaction and the replacement.
+func (w *Wallet) BumpFee(txHash *chainhash.Hash,
+	feeSatPerKb util.Amount) (*chainhash.Hash, util.Amount, util.Amount,
+	error) {
+	var details *wtxmgr.TxDetails
+	var prevScripts [][]byte
+	err := walletdb.View(w.db, func(dbtx walletdb.ReadTx) error {
+		txmgrNs := dbtx.ReadBucket(wtxmgrNamespaceKey)
+		var err error
+		details, err = w.TxStore.TxDetails(txmgrNs, txHash)
+		if err != nil {
+			return err
+		}
+		if details == nil {
+			return ErrTxNotFound
+		}
+		if details.Block.Height != -1 {
+			return ErrTxMined
+		}
+		if !signalsReplacement(&details.MsgTx) {
+			return ErrTxNotReplaceable
+		}
+		if len(details.Debits) != len(details.MsgTx.TxIn) {
+			return ErrTxNotOwned
+		}
+		unmined, err := w.TxStore.UnminedTxs(txmgrNs)
+		if err != nil {
+			return err
+		}
+		for _, tx := range unmined {
+			for _, txIn := range tx.TxIn {
+				if txIn.PreviousOutPoint.Hash == *txHash {
+					return ErrTxHasDescendants
+				}
+			}
+		}
+		prevScripts, err = w.TxStore.PreviousPkScripts(txmgrNs,
+			&details.TxRecord, nil)
+		return err
+	})
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if len(prevScripts) != len(details.MsgTx.TxIn) {
+		return nil, 0, 0, ErrTxNotOwned
+	}
+	changeIndex := -1
+	for _, credit := range details.Credits {
+		if !credit.Change {
+			continue
+		}
+		if changeIndex != -1 {
+			return nil, 0, 0, ErrNoChange
+		}
+		changeIndex = int(credit.Index)
+	}
+	if changeIndex == -1 {
+		return nil, 0, 0, ErrNoChange
+	}
+	prevValues := make([]util.Amount, len(details.MsgTx.TxIn))
+	var totalInput util.Amount
+	for _, debit := range details.Debits {
+		prevValues[debit.Index] = debit.Amount
+		totalInput += debit.Amount
+	}
+	oldFee := totalInput
+	for _, txOut := range details.MsgTx.TxOut {
+		oldFee -= util.Amount(txOut.Value)
+	}
+	// The replacement has the same inputs and outputs, so it is the same
+	// size but for a byte more or less in the length of a signature.
+	vsize := virtualSize(&details.MsgTx)
+	if feeSatPerKb == 0 {
+		feeSatPerKb = oldFee*1000/util.Amount(vsize) +
+			txrules.DefaultRelayFeePerKb
+	}
+	newFee, err := bumpedFee(oldFee, vsize, feeSatPerKb)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	replacement := details.MsgTx.Copy()
+	for _, txIn := range replacement.TxIn {
+		txIn.SignatureScript = nil
+		txIn.Witness = nil
+	}
+	change := replacement.TxOut[changeIndex]
+	change.Value -= int64(newFee - oldFee)
+	if change.Value < 0 || txrules.IsDustOutput(change, w.DustRelayFee()) {
+		return nil, 0, 0, ErrChangeTooSmall
+	}
+	tx := &txauthor.AuthoredTx{
+		Tx:              replacement,
+		PrevScripts:     prevScripts,
+		PrevInputValues: prevValues,
+		TotalInput:      totalInput,
+		ChangeIndex:     changeIndex,
+	}
+	err = walletdb.View(w.db, func(dbtx walletdb.ReadTx) error {
+		addrmgrNs := dbtx.ReadBucket(waddrmgrNamespaceKey)
+		return tx.AddAllInputScripts(secretSource{w.Manager, addrmgrNs})
+	})
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	err = validateMsgTx(tx.Tx, tx.PrevScripts, tx.PrevInputValues)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	// The replaced transaction is removed first, as the record of the
+	// outputs it spends is shared with the replacement.  If the replacement
+	// is not published, the replaced transaction is put back, which is all
+	// there is to restore as nothing of the wallet spends it.
+	err = walletdb.Update(w.db, func(dbtx walletdb.ReadWriteTx) error {
+		txmgrNs := dbtx.ReadWriteBucket(wtxmgrNamespaceKey)
+		return w.TxStore.RemoveUnminedTx(txmgrNs, &details.TxRecord)
+	})
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	replacementHash, err := w.publishTransaction(tx.Tx)
+	if err != nil {
+		dbErr := walletdb.Update(w.db, func(dbtx walletdb.ReadWriteTx) error {
+			return w.addRelevantTx(dbtx, &details.TxRecord, nil)
+		})
+		if dbErr != nil {
+			log <- cl.Error{"unable to restore replaced transaction",
+				txHash, dbErr}
+		}
+		return nil, 0, 0, err
+	}
+	log <- cl.Infof{"replaced transaction %v paying %v with %v paying %v",
+		txHash, oldFee, replacementHash, newFee}
+	return replacementHash, oldFee, newFee, nil
+}
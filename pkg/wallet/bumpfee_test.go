@@ -0,0 +1,56 @@
+package wallet
+import (
+	"testing"
+	txrules "git.parallelcoin.io/dev/9/pkg/chain/tx/rules"
+	"git.parallelcoin.io/dev/9/pkg/chain/wire"
+	"git.parallelcoin.io/dev/9/pkg/util"
+)
+// TestBumpedFee ensures a replacement pays the requested fee rate, at least
+// the fee of the transaction it replaces plus its own relay fee, and that a
+// fee rate no higher than that of the replaced transaction is refused.
+func TestBumpedFee(t *testing.T) {
+	tests := []struct {
+		name   string
+		oldFee util.Amount
+		vsize  int
+		rate   util.Amount
+		want   util.Amount
+		err    error
+	}{
+		{"rate", 1000, 250, 20000, 5000, nil},
+		{"relay fee", 1000, 250, 4001, 1250, nil},
+		{"same rate", 1000, 250, 4000, 0, ErrFeeRateTooLow},
+		{"lower rate", 1000, 250, 1000, 0, ErrFeeRateTooLow},
+	}
+	for _, test := range tests {
+		fee, err := bumpedFee(test.oldFee, test.vsize, test.rate)
+		if err != test.err || fee != test.want {
+			t.Errorf("%s: got fee %v, %v, want %v, %v", test.name, fee,
+				err, test.want, test.err)
+		}
+		if err == nil && fee-test.oldFee < txrules.FeeForSerializeSize(
+			txrules.DefaultRelayFeePerKb, test.vsize) {
+			t.Errorf("%s: fee %v does not pay for the relay of the "+
+				"replacement", test.name, fee)
+		}
+	}
+}
+// TestSignalsReplacement ensures a transaction signals replaceability when
+// any of its inputs has a sequence number below the final two.
+func TestSignalsReplacement(t *testing.T) {
+	tx := wire.NewMsgTx(wire.TxVersion)
+	tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{}, nil, nil))
+	tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Index: 1}, nil, nil))
+	if signalsReplacement(tx) {
+		t.Errorf("final inputs signal replaceability")
+	}
+	tx.TxIn[1].Sequence = wire.MaxTxInSequenceNum - 1
+	if signalsReplacement(tx) {
+		t.Errorf("inputs enabling the lock time signal replaceability")
+	}
+	tx.TxIn[1].Sequence = replaceableSequence
+	if !signalsReplacement(tx) {
+		t.Errorf("an input with sequence %d does not signal "+
+			"replaceability", replaceableSequence)
+	}
+}
@@ -0,0 +1,367 @@
+package chain
+import (
+	js "encoding/json"
+	"errors"
+	"sync"
+	"time"
+	chaincfg "git.parallelcoin.io/dev/9/pkg/chain/config"
+	chainhash "git.parallelcoin.io/dev/9/pkg/chain/hash"
+	wtxmgr "git.parallelcoin.io/dev/9/pkg/chain/tx/mgr"
+	txscript "git.parallelcoin.io/dev/9/pkg/chain/tx/script"
+	"git.parallelcoin.io/dev/9/pkg/chain/wire"
+	"git.parallelcoin.io/dev/9/pkg/util"
+	"git.parallelcoin.io/dev/9/pkg/util/cl"
+	waddrmgr "git.parallelcoin.io/dev/9/pkg/wallet/addrmgr"
+)
+// rescanProgressInterval is the number of blocks between the progress notifications of a rescan.
+const rescanProgressInterval = 1000
+// Backend is the chain of a full node running in the same process as the wallet.
+//
+// The subscription callbacks are called while the node is processing the block or transaction, so they must not block or call back into the backend.
+type Backend interface {
+	// BestBlock returns the hash and height of the tip of the main chain.
+	BestBlock() (*chainhash.Hash, int32)
+	// BlockHash returns the hash of the block at the height in the main chain.
+	BlockHash(height int32) (*chainhash.Hash, error)
+	// BlockHeight returns the height of the block with the hash in the main chain.
+	BlockHeight(hash *chainhash.Hash) (int32, error)
+	// Block returns the block with the hash.
+	Block(hash *chainhash.Hash) (*wire.MsgBlock, error)
+	// BlockHeader returns the header of the block with the hash.
+	BlockHeader(hash *chainhash.Hash) (*wire.BlockHeader, error)
+	// SendTransaction adds the transaction to the mempool of the node and relays it to its peers.
+	SendTransaction(tx *wire.MsgTx, allowHighFees bool) (*chainhash.Hash, error)
+	// SubscribeBlocks calls the callback with each block connected to or disconnected from the main chain.
+	SubscribeBlocks(callback func(block *wire.MsgBlock, height int32, connected bool))
+	// SubscribeTransactions calls the callback with each transaction accepted to the mempool.
+	SubscribeTransactions(callback func(tx *wire.MsgTx))
+	// RawRequest runs a JSON-RPC command of the node without the RPC server.
+	RawRequest(method string, params []js.RawMessage) (js.RawMessage, error)
+}
+// InProcessClient is an implementation of the chain.Interface interface that calls a full node running in the same process directly, rather than over RPC, so there is no JSON serialization, TLS or credentials between the wallet and its node.
+type InProcessClient struct {
+	backend     Backend
+	chainParams *chaincfg.Params
+	// watchMtx protects the addresses and outpoints the wallet asked to be notified of and whether it wants block notifications.
+	watchMtx         sync.Mutex
+	watchedAddrs     map[string]struct{}
+	watchedOutPoints map[wire.OutPoint]struct{}
+	notifyBlocks     bool
+	enqueueNotification chan interface{}
+	dequeueNotification chan interface{}
+	currentBlock        chan *waddrmgr.BlockStamp
+	quit    chan struct{}
+	wg      sync.WaitGroup
+	started bool
+	quitMtx sync.Mutex
+}
+// Enforce InProcessClient satisfies the chain.Interface interface.
+var _ Interface = (*InProcessClient)(nil)
+// NewInProcessClient returns a client of the chain of the backend. Once started it subscribes to the blocks and transactions of the backend, which has no way to unsubscribe, so only one should be started for each backend.
+func NewInProcessClient(
+	chainParams *chaincfg.Params, backend Backend) *InProcessClient {
+	c := &InProcessClient{
+		backend:             backend,
+		chainParams:         chainParams,
+		watchedAddrs:        make(map[string]struct{}),
+		watchedOutPoints:    make(map[wire.OutPoint]struct{}),
+		enqueueNotification: make(chan interface{}),
+		dequeueNotification: make(chan interface{}),
+		currentBlock:        make(chan *waddrmgr.BlockStamp),
+		quit:                make(chan struct{}),
+	}
+	return c
+}
+// BackEnd returns the name of the driver.
+func (c *InProcessClient) BackEnd() string {
+	return "inprocess"
+}
+// Start starts the goroutine handling notifications, and notifies the wallet it is connected.
+func (c *InProcessClient) Start() error {
+	c.quitMtx.Lock()
+	defer c.quitMtx.Unlock()
+	select {
+	case <-c.quit:
+		return errors.New("the client has been stopped")
+	default:
+	}
+	if c.started {
+		return nil
+	}
+	c.started = true
+	c.wg.Add(1)
+	go c.handler()
+	// the callbacks only block the node until the handler takes their notifications, so they are not subscribed before it runs
+	c.backend.SubscribeBlocks(c.onBlock)
+	c.backend.SubscribeTransactions(c.onTransaction)
+	go c.enqueue(ClientConnected{})
+	return nil
+}
+// Stop signals the shutdown of the goroutine started by Start.
+func (c *InProcessClient) Stop() {
+	c.quitMtx.Lock()
+	select {
+	case <-c.quit:
+	default:
+		close(c.quit)
+		if !c.started {
+			close(c.dequeueNotification)
+		}
+	}
+	c.quitMtx.Unlock()
+}
+// WaitForShutdown blocks until the client has been stopped and its handler has exited.
+func (c *InProcessClient) WaitForShutdown() {
+	<-c.quit
+	c.wg.Wait()
+}
+// Notifications returns a channel of the notifications of the chain. This channel must be continually read or the node stalls, as the notifications are sent from its block and transaction processing.
+func (c *InProcessClient) Notifications() <-chan interface{} {
+	return c.dequeueNotification
+}
+// BlockStamp returns the latest block notified by the client, or an error if the client has been shut down.
+func (c *InProcessClient) BlockStamp() (*waddrmgr.BlockStamp, error) {
+	select {
+	case bs := <-c.currentBlock:
+		return bs, nil
+	case <-c.quit:
+		return nil, errors.New("disconnected")
+	}
+}
+// GetBestBlock returns the hash and height of the best block of the node.
+func (c *InProcessClient) GetBestBlock() (*chainhash.Hash, int32, error) {
+	hash, height := c.backend.BestBlock()
+	return hash, height, nil
+}
+// GetBlock returns the block with the hash.
+func (c *InProcessClient) GetBlock(hash *chainhash.Hash) (*wire.MsgBlock, error) {
+	return c.backend.Block(hash)
+}
+// GetBlockHash returns the hash of the block at the height in the main chain.
+func (c *InProcessClient) GetBlockHash(height int64) (*chainhash.Hash, error) {
+	return c.backend.BlockHash(int32(height))
+}
+// GetBlockHeight returns the height of the block with the hash in the main chain. Like that of the NeutrinoClient, it stands in for GetBlockVerboseTxAsync for the wallet.
+func (c *InProcessClient) GetBlockHeight(hash *chainhash.Hash) (int32, error) {
+	return c.backend.BlockHeight(hash)
+}
+// GetBlockHeader returns the header of the block with the hash.
+func (c *InProcessClient) GetBlockHeader(hash *chainhash.Hash) (*wire.BlockHeader, error) {
+	return c.backend.BlockHeader(hash)
+}
+// SendRawTransaction adds the transaction to the mempool of the node and relays it.
+func (c *InProcessClient) SendRawTransaction(tx *wire.MsgTx, allowHighFees bool) (*chainhash.Hash, error) {
+	return c.backend.SendTransaction(tx, allowHighFees)
+}
+// RawRequest runs a JSON-RPC command of the node, so the wallet RPC server can pass through the commands it does not handle itself.
+func (c *InProcessClient) RawRequest(method string, params []js.RawMessage) (js.RawMessage, error) {
+	return c.backend.RawRequest(method, params)
+}
+// FilterBlocks scans the blocks contained in the FilterBlocksRequest for any addresses of interest, returning a FilterBlocksResponse for the first block containing a matching address, or nil if none do. The blocks are read from the node and filtered directly, as that costs little more than checking their compact filters would.
+func (c *InProcessClient) FilterBlocks(
+	req *FilterBlocksRequest) (*FilterBlocksResponse, error) {
+	blockFilterer := NewBlockFilterer(c.chainParams, req)
+	for i, blk := range req.Blocks {
+		block, err := c.backend.Block(&blk.Hash)
+		if err != nil {
+			return nil, err
+		}
+		if !blockFilterer.FilterBlock(block) {
+			continue
+		}
+		return &FilterBlocksResponse{
+			BatchIndex:         uint32(i),
+			BlockMeta:          blk,
+			FoundExternalAddrs: blockFilterer.FoundExternal,
+			FoundInternalAddrs: blockFilterer.FoundInternal,
+			FoundOutPoints:     blockFilterer.FoundOutPoints,
+			RelevantTxns:       blockFilterer.RelevantTxns,
+		}, nil
+	}
+	return nil, nil
+}
+// NotifyBlocks starts sending notifications of blocks connected to and disconnected from the main chain.
+func (c *InProcessClient) NotifyBlocks() error {
+	c.watchMtx.Lock()
+	c.notifyBlocks = true
+	c.watchMtx.Unlock()
+	return nil
+}
+// NotifyReceived adds the addresses to those whose transactions are notified, in the mempool and in blocks.
+func (c *InProcessClient) NotifyReceived(addrs []util.Address) error {
+	c.watchMtx.Lock()
+	for _, addr := range addrs {
+		c.watchedAddrs[addr.EncodeAddress()] = struct{}{}
+	}
+	c.watchMtx.Unlock()
+	return nil
+}
+// Rescan adds the addresses and outpoints to those whose transactions are notified, and notifies the relevant transactions of the blocks of the main chain from the block with the start hash to the tip, followed by RescanFinished. Like the RPC command it blocks until the rescan is done.
+func (c *InProcessClient) Rescan(startHash *chainhash.Hash, addrs []util.Address,
+	outPoints map[wire.OutPoint]util.Address) error {
+	c.NotifyReceived(addrs)
+	c.watchMtx.Lock()
+	for op := range outPoints {
+		c.watchedOutPoints[op] = struct{}{}
+	}
+	c.watchMtx.Unlock()
+	height, err := c.backend.BlockHeight(startHash)
+	if err != nil {
+		return err
+	}
+	var meta *wtxmgr.BlockMeta
+	for start := height; ; height++ {
+		// the chain may grow while it is being rescanned
+		if _, best := c.backend.BestBlock(); height > best {
+			break
+		}
+		hash, err := c.backend.BlockHash(height)
+		if err != nil {
+			return err
+		}
+		block, err := c.backend.Block(hash)
+		if err != nil {
+			return err
+		}
+		meta = &wtxmgr.BlockMeta{
+			Block: wtxmgr.Block{Hash: *hash, Height: height},
+			Time:  block.Header.Timestamp,
+		}
+		for _, tx := range block.Transactions {
+			if c.relevant(tx) && !c.notifyTx(tx, meta) {
+				return errors.New("disconnected")
+			}
+		}
+		if (height-start+1)%rescanProgressInterval == 0 &&
+			!c.enqueue(&RescanProgress{Hash: hash, Height: height, Time: meta.Time}) {
+			return errors.New("disconnected")
+		}
+	}
+	if meta == nil {
+		return errors.New("the rescan started after the tip of the chain")
+	}
+	if !c.enqueue(&RescanFinished{Hash: &meta.Hash, Height: meta.Height, Time: meta.Time}) {
+		return errors.New("disconnected")
+	}
+	return nil
+}
+// onBlock notifies the relevant transactions of a block connected to the main chain and then the block, or that a block was disconnected from it.
+func (c *InProcessClient) onBlock(block *wire.MsgBlock, height int32, connected bool) {
+	meta := wtxmgr.BlockMeta{
+		Block: wtxmgr.Block{Hash: block.BlockHash(), Height: height},
+		Time:  block.Header.Timestamp,
+	}
+	if connected {
+		for _, tx := range block.Transactions {
+			if c.relevant(tx) {
+				c.notifyTx(tx, &meta)
+			}
+		}
+	}
+	c.watchMtx.Lock()
+	notifyBlocks := c.notifyBlocks
+	c.watchMtx.Unlock()
+	if !notifyBlocks {
+		return
+	}
+	if connected {
+		c.enqueue(BlockConnected(meta))
+	} else {
+		c.enqueue(BlockDisconnected(meta))
+	}
+}
+// onTransaction notifies a transaction accepted to the mempool if it is relevant.
+func (c *InProcessClient) onTransaction(tx *wire.MsgTx) {
+	if c.relevant(tx) {
+		c.notifyTx(tx, nil)
+	}
+}
+// relevant returns whether the transaction spends a watched outpoint or pays to a watched address, adding the outputs that pay to watched addresses to the watched outpoints so their spends are found too.
+func (c *InProcessClient) relevant(tx *wire.MsgTx) bool {
+	c.watchMtx.Lock()
+	defer c.watchMtx.Unlock()
+	if len(c.watchedAddrs) == 0 && len(c.watchedOutPoints) == 0 {
+		return false
+	}
+	found := false
+	for _, in := range tx.TxIn {
+		if _, ok := c.watchedOutPoints[in.PreviousOutPoint]; ok {
+			found = true
+		}
+	}
+	var hash *chainhash.Hash
+	for i, out := range tx.TxOut {
+		_, addrs, _, err := txscript.ExtractPkScriptAddrs(out.PkScript, c.chainParams)
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			if _, ok := c.watchedAddrs[addr.EncodeAddress()]; !ok {
+				continue
+			}
+			if hash == nil {
+				h := tx.TxHash()
+				hash = &h
+			}
+			found = true
+			c.watchedOutPoints[*wire.NewOutPoint(hash, uint32(i))] = struct{}{}
+		}
+	}
+	return found
+}
+// notifyTx queues a RelevantTx notification of the transaction, mined in the block or unmined when it is nil, and returns false if the client was stopped.
+func (c *InProcessClient) notifyTx(tx *wire.MsgTx, block *wtxmgr.BlockMeta) bool {
+	rec, err := wtxmgr.NewTxRecordFromMsgTx(tx, time.Now())
+	if err != nil {
+		log <- cl.Error{"cannot create transaction record for relevant tx:", err}
+		return true
+	}
+	return c.enqueue(RelevantTx{TxRecord: rec, Block: block})
+}
+// enqueue passes a notification to the handler, and returns false if the client was stopped.
+func (c *InProcessClient) enqueue(n interface{}) bool {
+	select {
+	case c.enqueueNotification <- n:
+		return true
+	case <-c.quit:
+		return false
+	}
+}
+// handler maintains a queue of notifications and the current state (best block) of the chain.
+func (c *InProcessClient) handler() {
+	hash, height := c.backend.BestBlock()
+	bs := &waddrmgr.BlockStamp{Hash: *hash, Height: height}
+	var notifications []interface{}
+	var dequeue chan interface{}
+	var next interface{}
+out:
+	for {
+		select {
+		case n := <-c.enqueueNotification:
+			if len(notifications) == 0 {
+				next = n
+				dequeue = c.dequeueNotification
+			}
+			notifications = append(notifications, n)
+		case dequeue <- next:
+			if n, ok := next.(BlockConnected); ok {
+				bs = &waddrmgr.BlockStamp{
+					Height: n.Height,
+					Hash:   n.Hash,
+				}
+			}
+			notifications[0] = nil
+			notifications = notifications[1:]
+			if len(notifications) != 0 {
+				next = notifications[0]
+			} else {
+				dequeue = nil
+			}
+		case c.currentBlock <- bs:
+		case <-c.quit:
+			break out
+		}
+	}
+	close(c.dequeueNotification)
+	c.wg.Done()
+}
@@ -0,0 +1,152 @@
+package chain_test
+import (
+	js "encoding/json"
+	"errors"
+	"testing"
+	"time"
+	chaincfg "git.parallelcoin.io/dev/9/pkg/chain/config"
+	chainhash "git.parallelcoin.io/dev/9/pkg/chain/hash"
+	txscript "git.parallelcoin.io/dev/9/pkg/chain/tx/script"
+	"git.parallelcoin.io/dev/9/pkg/chain/wire"
+	"git.parallelcoin.io/dev/9/pkg/util"
+	chain "git.parallelcoin.io/dev/9/pkg/wallet/chain"
+)
+// fakeBackend is a chain of blocks in memory that calls its subscribers when told to
+type fakeBackend struct {
+	blocks  []*wire.MsgBlock
+	onBlock func(*wire.MsgBlock, int32, bool)
+	onTx    func(*wire.MsgTx)
+}
+func (f *fakeBackend) BestBlock() (*chainhash.Hash, int32) {
+	hash := f.blocks[len(f.blocks)-1].BlockHash()
+	return &hash, int32(len(f.blocks) - 1)
+}
+func (f *fakeBackend) BlockHash(height int32) (*chainhash.Hash, error) {
+	if height < 0 || int(height) >= len(f.blocks) {
+		return nil, errors.New("no block at height")
+	}
+	hash := f.blocks[height].BlockHash()
+	return &hash, nil
+}
+func (f *fakeBackend) BlockHeight(hash *chainhash.Hash) (int32, error) {
+	for i, block := range f.blocks {
+		if block.BlockHash() == *hash {
+			return int32(i), nil
+		}
+	}
+	return 0, errors.New("unknown block")
+}
+func (f *fakeBackend) Block(hash *chainhash.Hash) (*wire.MsgBlock, error) {
+	height, err := f.BlockHeight(hash)
+	if err != nil {
+		return nil, err
+	}
+	return f.blocks[height], nil
+}
+func (f *fakeBackend) BlockHeader(hash *chainhash.Hash) (*wire.BlockHeader, error) {
+	block, err := f.Block(hash)
+	if err != nil {
+		return nil, err
+	}
+	return &block.Header, nil
+}
+func (f *fakeBackend) SendTransaction(tx *wire.MsgTx, allowHighFees bool) (*chainhash.Hash, error) {
+	hash := tx.TxHash()
+	return &hash, nil
+}
+func (f *fakeBackend) SubscribeBlocks(callback func(*wire.MsgBlock, int32, bool)) {
+	f.onBlock = callback
+}
+func (f *fakeBackend) SubscribeTransactions(callback func(*wire.MsgTx)) {
+	f.onTx = callback
+}
+func (f *fakeBackend) RawRequest(method string, params []js.RawMessage) (js.RawMessage, error) {
+	return js.RawMessage(`1`), nil
+}
+// fakeBlock returns a block after prev with the transactions
+func fakeBlock(prev *wire.MsgBlock, txs ...*wire.MsgTx) *wire.MsgBlock {
+	block := &wire.MsgBlock{Header: wire.BlockHeader{Timestamp: time.Unix(1500000000, 0)}, Transactions: txs}
+	if prev != nil {
+		block.Header.PrevBlock = prev.BlockHash()
+		block.Header.Timestamp = prev.Header.Timestamp.Add(time.Minute)
+	}
+	return block
+}
+// nextNotification returns the next notification of the client, failing the test if there is none
+func nextNotification(t *testing.T, c *chain.InProcessClient) interface{} {
+	select {
+	case n := <-c.Notifications():
+		return n
+	case <-time.After(5 * time.Second):
+		t.Fatal("no notification")
+	}
+	return nil
+}
+// TestInProcessClient ensures the in-process client notifies the transactions paying to the addresses it was asked to watch and those spending their outputs, in blocks and in the mempool, and the blocks of the chain
+func TestInProcessClient(
+	t *testing.T) {
+	params := &chaincfg.MainNetParams
+	addr, err := util.NewAddressPubKeyHash(make([]byte, 20), params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	script, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pay := wire.NewMsgTx(wire.TxVersion)
+	pay.AddTxIn(wire.NewTxIn(&wire.OutPoint{Index: 0xffffffff}, nil, nil))
+	pay.AddTxOut(wire.NewTxOut(1000, script))
+	payHash := pay.TxHash()
+	spend := wire.NewMsgTx(wire.TxVersion)
+	spend.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&payHash, 0), nil, nil))
+	spend.AddTxOut(wire.NewTxOut(900, []byte{txscript.OpTrue}))
+	genesis := fakeBlock(nil)
+	backend := &fakeBackend{blocks: []*wire.MsgBlock{genesis, fakeBlock(genesis, pay)}}
+	c := chain.NewInProcessClient(params, backend)
+	if err = c.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Stop()
+	if _, ok := nextNotification(t, c).(chain.ClientConnected); !ok {
+		t.Fatal("the client did not notify it connected")
+	}
+	// the payment is found by a rescan, and the spend of its output as it enters the mempool
+	hash := genesis.BlockHash()
+	if err = c.Rescan(&hash, []util.Address{addr}, nil); err != nil {
+		t.Fatal(err)
+	}
+	n := nextNotification(t, c)
+	if rtx, ok := n.(chain.RelevantTx); !ok || rtx.TxRecord.Hash != payHash || rtx.Block == nil || rtx.Block.Height != 1 {
+		t.Fatalf("the rescan notified %#v, not the payment in block 1", n)
+	}
+	if f, ok := nextNotification(t, c).(*chain.RescanFinished); !ok || f.Height != 1 {
+		t.Fatalf("the rescan did not finish at block 1, got %#v", f)
+	}
+	backend.onTx(spend)
+	n = nextNotification(t, c)
+	if rtx, ok := n.(chain.RelevantTx); !ok || rtx.TxRecord.Hash != spend.TxHash() || rtx.Block != nil {
+		t.Fatalf("the mempool notified %#v, not the unmined spend", n)
+	}
+	// blocks are only notified once asked for, after their relevant transactions
+	block := fakeBlock(backend.blocks[1], spend)
+	backend.blocks = append(backend.blocks, block)
+	if err = c.NotifyBlocks(); err != nil {
+		t.Fatal(err)
+	}
+	backend.onBlock(block, 2, true)
+	n = nextNotification(t, c)
+	if rtx, ok := n.(chain.RelevantTx); !ok || rtx.TxRecord.Hash != spend.TxHash() || rtx.Block == nil || rtx.Block.Height != 2 {
+		t.Fatalf("block 2 notified %#v, not the spend mined in it", n)
+	}
+	if b, ok := nextNotification(t, c).(chain.BlockConnected); !ok || b.Height != 2 || b.Hash != block.BlockHash() {
+		t.Fatalf("block 2 was not notified connected, got %#v", b)
+	}
+	backend.onBlock(block, 2, false)
+	if b, ok := nextNotification(t, c).(chain.BlockDisconnected); !ok || b.Height != 2 {
+		t.Fatalf("block 2 was not notified disconnected, got %#v", b)
+	}
+	if bs, err := c.BlockStamp(); err != nil || bs.Height != 2 {
+		t.Fatalf("the block stamp is %+v, %v after block 2 was connected", bs, err)
+	}
+}
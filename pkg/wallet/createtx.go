@@ -102,24 +102,8 @@ func (w *Wallet) txToOutputs(outputs []*wire.TxOut, account uint32,
 			return err
 		}
 		inputSource := makeInputSource(eligible)
-		changeSource := func() ([]byte, error) {
-			// Derive the change output script.  As a hack to allow
-			// spending from the imported account, change addresses
-			// are created from account 0.
-			var changeAddr util.Address
-			var err error
-			if account == waddrmgr.ImportedAddrAccount {
-				changeAddr, err = w.newChangeAddress(addrmgrNs, 0)
-			} else {
-				changeAddr, err = w.newChangeAddress(addrmgrNs, account)
-			}
-			if err != nil {
-				return nil, err
-			}
-			return txscript.PayToAddrScript(changeAddr)
-		}
 		tx, err = txauthor.NewUnsignedTransaction(outputs, feeSatPerKb,
-			inputSource, changeSource)
+			w.DustRelayFee(), inputSource, w.changeSource(addrmgrNs, account))
 		if err != nil {
 			return err
 		}
@@ -129,6 +113,13 @@ func (w *Wallet) txToOutputs(outputs []*wire.TxOut, account uint32,
 		if tx.ChangeIndex >= 0 {
 			tx.RandomizeChangePosition()
 		}
+		// Signal replaceability before signing, as the sequence numbers
+		// of the inputs are signed.
+		if w.Replaceable() {
+			for _, txIn := range tx.Tx.TxIn {
+				txIn.Sequence = replaceableSequence
+			}
+		}
 		return tx.AddAllInputScripts(secretSource{w.Manager, addrmgrNs})
 	})
 	if err != nil {
@@ -148,6 +139,27 @@ func (w *Wallet) txToOutputs(outputs []*wire.TxOut, account uint32,
 	}
 	return tx, nil
 }
+// changeSource returns the source of change output scripts for transactions
+// spending from the account.
+func (w *Wallet) changeSource(addrmgrNs walletdb.ReadWriteBucket,
+	account uint32) txauthor.ChangeSource {
+	return func() ([]byte, error) {
+		// Derive the change output script.  As a hack to allow
+		// spending from the imported account, change addresses
+		// are created from account 0.
+		var changeAddr util.Address
+		var err error
+		if account == waddrmgr.ImportedAddrAccount {
+			changeAddr, err = w.newChangeAddress(addrmgrNs, 0)
+		} else {
+			changeAddr, err = w.newChangeAddress(addrmgrNs, account)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return txscript.PayToAddrScript(changeAddr)
+	}
+}
 func (w *Wallet) findEligibleOutputs(dbtx walletdb.ReadTx, account uint32, minconf int32, bs *waddrmgr.BlockStamp) ([]wtxmgr.Credit, error) {
 	addrmgrNs := dbtx.ReadBucket(waddrmgrNamespaceKey)
 	txmgrNs := dbtx.ReadBucket(wtxmgrNamespaceKey)
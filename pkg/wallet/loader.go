@@ -117,7 +117,7 @@ func (l *Loader) OpenExistingWallet(
 	dbPath := filepath.Join(l.dbDirPath, WalletDbName)
 	db, err := walletdb.Open("bdb", dbPath)
 	if err != nil {
-		log <- cl.Error{"failed to open database '" + l.dbDirPath + "':", err, cl.Ine()}
+		log <- cl.Error{"failed to open database '" + l.dbDirPath + "':", err, cl.Here("error")}
 		return nil, err
 	}
 	var cbs *waddrmgr.OpenCallbacks
@@ -0,0 +1,326 @@
+package wallet
+import (
+	"fmt"
+	txauthor "git.parallelcoin.io/dev/9/pkg/chain/tx/author"
+	wtxmgr "git.parallelcoin.io/dev/9/pkg/chain/tx/mgr"
+	txscript "git.parallelcoin.io/dev/9/pkg/chain/tx/script"
+	"git.parallelcoin.io/dev/9/pkg/chain/wire"
+	"git.parallelcoin.io/dev/9/pkg/util"
+	"git.parallelcoin.io/dev/9/pkg/util/psbt"
+	waddrmgr "git.parallelcoin.io/dev/9/pkg/wallet/addrmgr"
+	walletdb "git.parallelcoin.io/dev/9/pkg/wallet/db"
+)
+// FundPsbt creates a partially signed transaction paying to outputs.  Every
+// unspent wallet output in inputs is spent, and when they are not enough
+// more are chosen from the account's unspent outputs with at least minconf
+// confirmations, with change sent to a new change address of the account.
+// The previous outputs and redeem scripts the wallet knows of are added to
+// the packet so signers that only have the keys, such as an offline wallet,
+// can sign it.  The spent outputs are locked when lockInputs is true so the
+// wallet does not spend them again before the packet is signed and
+// published.  The fee of the transaction and the index of its change output,
+// or -1 without change, are returned with the packet.
+//
+// Unlike CreateSimpleTx, this does not need the private keys of the wallet,
+// so it works with watching-only wallets.
+func (w *Wallet) FundPsbt(inputs []wire.OutPoint, outputs []*wire.TxOut,
+	lockTime uint32, account uint32, minconf int32, feeSatPerKb util.Amount,
+	lockInputs bool) (*psbt.Packet, util.Amount, int, error) {
+	chainClient, err := w.requireChainClient()
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	var tx *txauthor.AuthoredTx
+	var packet *psbt.Packet
+	err = walletdb.Update(w.db, func(dbtx walletdb.ReadWriteTx) error {
+		addrmgrNs := dbtx.ReadWriteBucket(waddrmgrNamespaceKey)
+		txmgrNs := dbtx.ReadBucket(wtxmgrNamespaceKey)
+		bs, err := chainClient.BlockStamp()
+		if err != nil {
+			return err
+		}
+		unspent, err := w.TxStore.UnspentOutputs(txmgrNs)
+		if err != nil {
+			return err
+		}
+		credits := make(map[wire.OutPoint]wtxmgr.Credit, len(unspent))
+		for _, credit := range unspent {
+			credits[credit.OutPoint] = credit
+		}
+		given := make([]wtxmgr.Credit, 0, len(inputs))
+		for _, op := range inputs {
+			credit, ok := credits[op]
+			if !ok {
+				return fmt.Errorf("input %v is not an unspent output "+
+					"of the wallet", op)
+			}
+			// Outputs that were given are removed so they are not
+			// added twice.
+			delete(credits, op)
+			given = append(given, credit)
+		}
+		eligible, err := w.findEligibleOutputs(dbtx, account, minconf, bs)
+		if err != nil {
+			return err
+		}
+		more := eligible[:0]
+		for _, credit := range eligible {
+			if _, ok := credits[credit.OutPoint]; ok {
+				more = append(more, credit)
+			}
+		}
+		tx, err = txauthor.NewUnsignedTransaction(outputs, feeSatPerKb,
+			w.DustRelayFee(), givenInputSource(given, makeInputSource(more)),
+			w.changeSource(addrmgrNs, account))
+		if err != nil {
+			return err
+		}
+		if tx.ChangeIndex >= 0 {
+			tx.RandomizeChangePosition()
+		}
+		// The lock time is only enforced when an input does not have
+		// the final sequence number.
+		if lockTime != 0 {
+			tx.Tx.LockTime = lockTime
+			for _, txIn := range tx.Tx.TxIn {
+				txIn.Sequence = wire.MaxTxInSequenceNum - 1
+			}
+		}
+		packet, err = psbt.NewFromUnsignedTx(tx.Tx)
+		if err != nil {
+			return err
+		}
+		return w.updatePsbt(addrmgrNs, txmgrNs, packet)
+	})
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if lockInputs {
+		for _, txIn := range tx.Tx.TxIn {
+			w.LockOutpoint(txIn.PreviousOutPoint)
+		}
+	}
+	fee := tx.TotalInput
+	for _, txOut := range tx.Tx.TxOut {
+		fee -= util.Amount(txOut.Value)
+	}
+	return packet, fee, tx.ChangeIndex, nil
+}
+// givenInputSource returns an input source that always spends the given
+// credits, and adds inputs from more when they do not reach the target.
+func givenInputSource(given []wtxmgr.Credit,
+	more txauthor.InputSource) txauthor.InputSource {
+	return func(target util.Amount) (util.Amount, []*wire.TxIn,
+		[]util.Amount, [][]byte, error) {
+		total := util.Amount(0)
+		inputs := make([]*wire.TxIn, 0, len(given))
+		inputValues := make([]util.Amount, 0, len(given))
+		scripts := make([][]byte, 0, len(given))
+		for i := range given {
+			total += given[i].Amount
+			inputs = append(inputs, wire.NewTxIn(&given[i].OutPoint, nil, nil))
+			inputValues = append(inputValues, given[i].Amount)
+			scripts = append(scripts, given[i].PkScript)
+		}
+		moreTotal, moreInputs, moreValues, moreScripts, err := more(target - total)
+		if err != nil {
+			return 0, nil, nil, nil, err
+		}
+		return total + moreTotal, append(inputs, moreInputs...),
+			append(inputValues, moreValues...), append(scripts, moreScripts...), nil
+	}
+}
+// ProcessPsbt adds what the wallet knows of the outputs spent by the inputs
+// of a partially signed transaction to it and, when sign is true, the
+// signatures of the wallet's keys for the inputs it can spend, finalizing the
+// inputs that then have all of their signatures.  It returns whether every
+// input of the packet is final, so its transaction can be extracted and
+// published.
+//
+// A watching-only wallet only adds what it knows of the inputs, and other
+// wallets must be unlocked to sign.
+func (w *Wallet) ProcessPsbt(packet *psbt.Packet, sign bool,
+	hashType txscript.SigHashType) (bool, error) {
+	err := walletdb.View(w.db, func(dbtx walletdb.ReadTx) error {
+		addrmgrNs := dbtx.ReadBucket(waddrmgrNamespaceKey)
+		txmgrNs := dbtx.ReadBucket(wtxmgrNamespaceKey)
+		if err := w.updatePsbt(addrmgrNs, txmgrNs, packet); err != nil {
+			return err
+		}
+		if !sign || w.Manager.WatchOnly() {
+			return nil
+		}
+		return w.signPsbt(addrmgrNs, packet, hashType)
+	})
+	if err != nil {
+		return false, err
+	}
+	if sign {
+		if err = packet.MaybeFinalizeAll(); err != nil {
+			return false, err
+		}
+	}
+	return packet.IsComplete(), nil
+}
+// updatePsbt adds the outputs spent by the inputs of the packet that are
+// recorded by the wallet, and the redeem scripts of the pay-to-script-hash
+// outputs it has the scripts of.  Segwit inputs are given the output they
+// spend, and others the whole previous transaction, which their signers need
+// to be sure of the value they spend.
+func (w *Wallet) updatePsbt(addrmgrNs, txmgrNs walletdb.ReadBucket,
+	packet *psbt.Packet) error {
+	for i, txIn := range packet.UnsignedTx.TxIn {
+		in := &packet.Inputs[i]
+		if in.IsFinal() {
+			continue
+		}
+		prevOut := &txIn.PreviousOutPoint
+		var prevTx *wire.MsgTx
+		if in.NonWitnessUtxo == nil && in.WitnessUtxo == nil {
+			details, err := w.TxStore.TxDetails(txmgrNs, &prevOut.Hash)
+			if err != nil {
+				return err
+			}
+			// An input the wallet has no record of is left for
+			// another updater.
+			if details == nil || int(prevOut.Index) >= len(details.MsgTx.TxOut) {
+				continue
+			}
+			prevTx = &details.MsgTx
+			in.WitnessUtxo = prevTx.TxOut[prevOut.Index]
+		}
+		utxo, err := packet.Utxo(i)
+		if err != nil {
+			return err
+		}
+		script := utxo.PkScript
+		if in.RedeemScript == nil && txscript.IsPayToScriptHash(script) {
+			in.RedeemScript = w.redeemScript(addrmgrNs, script)
+		}
+		if in.RedeemScript != nil {
+			script = in.RedeemScript
+		}
+		if prevTx != nil && !txscript.IsWitnessProgram(script) {
+			in.NonWitnessUtxo, in.WitnessUtxo = prevTx, nil
+		}
+	}
+	return nil
+}
+// redeemScript returns the redeem script of a pay-to-script-hash output script
+// paying to the wallet, or nil when the wallet does not have it.  This is
+// either the script of a script address, which needs an unlocked wallet, or
+// the witness program of a nested pay-to-witness-pubkey-hash address.
+func (w *Wallet) redeemScript(addrmgrNs walletdb.ReadBucket,
+	pkScript []byte) []byte {
+	_, addrs, _, err := txscript.ExtractPkScriptAddrs(pkScript, w.chainParams)
+	if err != nil || len(addrs) != 1 {
+		return nil
+	}
+	ma, err := w.Manager.Address(addrmgrNs, addrs[0])
+	if err != nil {
+		return nil
+	}
+	switch ma := ma.(type) {
+	case waddrmgr.ManagedScriptAddress:
+		script, err := ma.Script()
+		if err != nil {
+			return nil
+		}
+		return script
+	case waddrmgr.ManagedPubKeyAddress:
+		if ma.AddrType() != waddrmgr.NestedWitnessPubKey {
+			return nil
+		}
+		witnessAddr, err := util.NewAddressWitnessPubKeyHash(
+			util.Hash160(ma.PubKey().SerializeCompressed()), w.chainParams)
+		if err != nil {
+			return nil
+		}
+		script, err := txscript.PayToAddrScript(witnessAddr)
+		if err != nil {
+			return nil
+		}
+		return script
+	}
+	return nil
+}
+// signPsbt adds the signatures of the wallet's keys to the inputs of the
+// packet that are not final and spend outputs it knows the scripts of.
+func (w *Wallet) signPsbt(addrmgrNs walletdb.ReadBucket, packet *psbt.Packet,
+	hashType txscript.SigHashType) error {
+	tx := packet.UnsignedTx
+	sigHashes := txscript.NewTxSigHashes(tx)
+	for i := range packet.Inputs {
+		in := &packet.Inputs[i]
+		utxo, err := packet.Utxo(i)
+		if in.IsFinal() || err != nil {
+			continue
+		}
+		if in.SighashType != 0 && in.SighashType != hashType {
+			return fmt.Errorf("input %d must be signed with sighash "+
+				"type %v", i, in.SighashType)
+		}
+		// SigHashSingle inputs can only be signed if there's a
+		// corresponding output.
+		if (hashType&txscript.SigHashSingle) == txscript.SigHashSingle &&
+			i >= len(tx.TxOut) {
+			continue
+		}
+		// The script signed is the one that is run after the redeem
+		// script or witness script of the input is revealed.
+		script := utxo.PkScript
+		if in.RedeemScript != nil {
+			script = in.RedeemScript
+		}
+		witness := txscript.IsWitnessProgram(script)
+		switch {
+		case txscript.IsPayToScriptHash(script):
+			continue
+		case txscript.IsPayToWitnessScriptHash(script):
+			if in.WitnessScript == nil {
+				continue
+			}
+			script = in.WitnessScript
+		}
+		_, addrs, _, err := txscript.ExtractPkScriptAddrs(script, w.chainParams)
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			ma, err := w.Manager.Address(addrmgrNs, addr)
+			if err != nil {
+				continue
+			}
+			pka, ok := ma.(waddrmgr.ManagedPubKeyAddress)
+			if !ok {
+				continue
+			}
+			key, err := pka.PrivKey()
+			if err != nil {
+				return err
+			}
+			// The key of a pay-to-pubkey or multisig script is the
+			// one serialized in the script.
+			pubKey := pka.PubKey().SerializeUncompressed()
+			if pk, ok := addr.(*util.AddressPubKey); ok {
+				pubKey = pk.ScriptAddress()
+			} else if pka.Compressed() {
+				pubKey = pka.PubKey().SerializeCompressed()
+			}
+			var sig []byte
+			if witness {
+				sig, err = txscript.RawTxInWitnessSignature(tx, sigHashes, i,
+					utxo.Value, script, hashType, key)
+			} else {
+				sig, err = txscript.RawTxInSignature(tx, i, script, hashType, key)
+			}
+			if err != nil {
+				return err
+			}
+			if err = packet.AddPartialSig(i, pubKey, sig); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
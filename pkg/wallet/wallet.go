@@ -67,6 +67,14 @@ type Wallet struct {
 	chainClientSyncMtx sync.Mutex
 	lockedOutpoints    map[wire.OutPoint]struct{}
 	recoveryWindow     uint32
+	// dustRelayFee is the fee rate used to decide whether an output
+	// created by the wallet is dust.
+	dustRelayFee   util.Amount
+	dustRelayFeeMu sync.Mutex
+	// replaceable is whether the transactions created by the wallet
+	// signal BIP125 replaceability.
+	replaceable   bool
+	replaceableMu sync.Mutex
 	// Channels for rescan processing.  Requests are added and merged with
 	// any waiting requests, before being sent to another goroutine to
 	// call the rescan RPC.
@@ -1885,6 +1893,12 @@ func (w *Wallet) GetTransactions(startBlock, endBlock *BlockIdentifier, cancel <
 				if err != nil {
 					return nil, err
 				}
+			case *chain.InProcessClient:
+				var err error
+				start, err = client.GetBlockHeight(startBlock.hash)
+				if err != nil {
+					return nil, err
+				}
 			case *chain.NeutrinoClient:
 				var err error
 				start, err = client.GetBlockHeight(startBlock.hash)
@@ -1904,6 +1918,12 @@ func (w *Wallet) GetTransactions(startBlock, endBlock *BlockIdentifier, cancel <
 			switch client := chainClient.(type) {
 			case *chain.RPCClient:
 				endResp = client.GetBlockVerboseTxAsync(endBlock.hash)
+			case *chain.InProcessClient:
+				var err error
+				end, err = client.GetBlockHeight(endBlock.hash)
+				if err != nil {
+					return nil, err
+				}
 			case *chain.NeutrinoClient:
 				var err error
 				end, err = client.GetBlockHeight(endBlock.hash)
@@ -2760,7 +2780,7 @@ func (w *Wallet) SendOutputs(outputs []*wire.TxOut, account uint32,
 	// Ensure the outputs to be created adhere to the network's consensus
 	// rules.
 	for _, output := range outputs {
-		if err := txrules.CheckOutput(output, satPerKb); err != nil {
+		if err := txrules.CheckOutput(output, w.DustRelayFee()); err != nil {
 			return nil, err
 		}
 	}
@@ -2774,6 +2794,37 @@ func (w *Wallet) SendOutputs(outputs []*wire.TxOut, account uint32,
 	}
 	return w.publishTransaction(createdTx.Tx)
 }
+// DustRelayFee returns the fee rate used to decide whether an output created
+// by the wallet is dust.
+func (w *Wallet) DustRelayFee() util.Amount {
+	w.dustRelayFeeMu.Lock()
+	defer w.dustRelayFeeMu.Unlock()
+	return w.dustRelayFee
+}
+// SetDustRelayFee sets the fee rate used to decide whether an output created
+// by the wallet is dust.  It should match the dust relay fee of the node the
+// wallet publishes transactions to, otherwise created transactions may be
+// rejected as non-standard.
+func (w *Wallet) SetDustRelayFee(dustRelayFee util.Amount) {
+	w.dustRelayFeeMu.Lock()
+	w.dustRelayFee = dustRelayFee
+	w.dustRelayFeeMu.Unlock()
+}
+// Replaceable returns whether the transactions created by the wallet signal
+// BIP125 replaceability, so their fee can be raised with BumpFee.
+func (w *Wallet) Replaceable() bool {
+	w.replaceableMu.Lock()
+	defer w.replaceableMu.Unlock()
+	return w.replaceable
+}
+// SetReplaceable sets whether the transactions created by the wallet signal
+// BIP125 replaceability.  Nodes that do not accept replacements still relay
+// and mine transactions that signal it.
+func (w *Wallet) SetReplaceable(replaceable bool) {
+	w.replaceableMu.Lock()
+	w.replaceable = replaceable
+	w.replaceableMu.Unlock()
+}
 // SignatureError records the underlying error when validating a transaction
 // input signature.
 type SignatureError struct {
@@ -3077,6 +3128,7 @@ func Open(
 		TxStore:             txMgr,
 		lockedOutpoints:     map[wire.OutPoint]struct{}{},
 		recoveryWindow:      recoveryWindow,
+		dustRelayFee:        txrules.DefaultDustRelayFeePerKb,
 		rescanAddJob:        make(chan *RescanJob),
 		rescanBatch:         make(chan *rescanBatch),
 		rescanNotifications: make(chan interface{}),